@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server"
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces the values of secrets in the effective config.
+const redactedValue = "<redacted>"
+
+// secretConfigKeys are the config keys whose values are redacted when the
+// effective config is printed. Keys of nested values, ex. the url of
+// webhooks, are matched by their own name.
+var secretConfigKeys = map[string]bool{
+	ADTokenFlag:                true,
+	ADWebhookPasswordFlag:      true,
+	APISecretFlag:              true,
+	BitbucketTokenFlag:         true,
+	BitbucketWebhookSecretFlag: true,
+	DatadogAPIKeyFlag:          true,
+	GHAppKeyFlag:               true,
+	GHTokenFlag:                true,
+	GHWebhookSecretFlag:        true,
+	GiteaTokenFlag:             true,
+	GiteaWebhookSecretFlag:     true,
+	GitlabTokenFlag:            true,
+	GitlabWebhookSecretFlag:    true,
+	RedisPassword:              true,
+	SlackTokenFlag:             true,
+	SMTPPasswordFlag:           true,
+	TFETokenFlag:               true,
+	VCSHTTPConfigFlag:          true,
+	WebhookHttpHeaders:         true,
+	WebPasswordFlag:            true,
+	// Slack, Teams and Datadog webhook URLs contain their credentials.
+	"url": true,
+}
+
+// configEnvFile returns the path of the overrides of env for configFile, ex.
+// config.prod.yaml for config.yaml and prod.
+func configEnvFile(configFile string, env string) string {
+	ext := filepath.Ext(configFile)
+	return strings.TrimSuffix(configFile, ext) + "." + env + ext
+}
+
+// printEffectiveConfig writes userConfig to w as a YAML config file, keyed by
+// flag names, with secrets redacted.
+func printEffectiveConfig(w io.Writer, userConfig server.UserConfig) error {
+	out, err := yaml.Marshal(effectiveConfig(reflect.ValueOf(userConfig)))
+	if err != nil {
+		return fmt.Errorf("rendering effective config: %w", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// effectiveConfig converts the struct v into a map keyed by the mapstructure
+// tags of its fields, recursing into structs and slices of structs. Secrets
+// that are set are replaced by redactedValue.
+func effectiveConfig(v reflect.Value) map[string]any {
+	config := make(map[string]any)
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		field := v.Field(i)
+		switch {
+		case secretConfigKeys[key]:
+			if field.IsZero() {
+				config[key] = field.Interface()
+			} else {
+				config[key] = redactedValue
+			}
+		case field.Kind() == reflect.Struct:
+			config[key] = effectiveConfig(field)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct:
+			items := make([]map[string]any, 0, field.Len())
+			for j := 0; j < field.Len(); j++ {
+				items = append(items, effectiveConfig(field.Index(j)))
+			}
+			config[key] = items
+		default:
+			config[key] = field.Interface()
+		}
+	}
+	return config
+}
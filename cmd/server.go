@@ -19,6 +19,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/moby/patternmatcher"
@@ -27,7 +28,12 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/runatlantis/atlantis/server"
+	cfg "github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/kubernetes"
+	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
+	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -54,10 +60,16 @@ const (
 	ADTokenFlag                      = "azuredevops-token" // nolint: gosec
 	ADUserFlag                       = "azuredevops-user"
 	ADHostnameFlag                   = "azuredevops-hostname"
+	ADWorkItemsFlag                  = "azuredevops-work-items"
+	ADWorkItemAppliedStateFlag       = "azuredevops-work-item-applied-state"
+	AggregateCommitStatusesFlag      = "aggregate-commit-statuses"
 	AllowCommandsFlag                = "allow-commands"
 	AllowForkPRsFlag                 = "allow-fork-prs"
+	ApplyWindowAdminsFlag            = "apply-window-admins"
 	AtlantisURLFlag                  = "atlantis-url"
+	AuthFailureHintFlag              = "auth-failure-hint"
 	AutoDiscoverModeFlag             = "autodiscover-mode"
+	AutoReplanDivergedFlag           = "auto-replan-diverged"
 	AutomergeFlag                    = "automerge"
 	ParallelPlanFlag                 = "parallel-plan"
 	ParallelApplyFlag                = "parallel-apply"
@@ -65,12 +77,20 @@ const (
 	AutoplanModulesFromProjects      = "autoplan-modules-from-projects"
 	AutoplanFileListFlag             = "autoplan-file-list"
 	BitbucketBaseURLFlag             = "bitbucket-base-url"
+	BitbucketLabelPatternFlag        = "bitbucket-label-pattern"
+	BitbucketMaxAttemptsFlag         = "bitbucket-max-attempts"
+	BitbucketMaxDelaySecondsFlag     = "bitbucket-max-delay-seconds"
 	BitbucketTokenFlag               = "bitbucket-token"
 	BitbucketUserFlag                = "bitbucket-user"
 	BitbucketWebhookSecretFlag       = "bitbucket-webhook-secret"
 	CheckoutDepthFlag                = "checkout-depth"
 	CheckoutStrategyFlag             = "checkout-strategy"
+	CloneCacheFlag                   = "clone-cache"
+	CommandLogHistorySizeFlag        = "command-log-history-size"
+	CommentTranslationsFileFlag      = "comment-translations-file"
 	ConfigFlag                       = "config"
+	ConfigEnvFlag                    = "config-env"
+	DatadogAPIKeyFlag                = "datadog-api-key"
 	DataDirFlag                      = "data-dir"
 	DefaultTFDistributionFlag        = "default-tf-distribution"
 	DefaultTFVersionFlag             = "default-tf-version"
@@ -83,13 +103,20 @@ const (
 	DisableUnlockLabelFlag           = "disable-unlock-label"
 	DiscardApprovalOnPlanFlag        = "discard-approval-on-plan"
 	EmojiReaction                    = "emoji-reaction"
+	EmojiReactionsFlag               = "emoji-reactions"
 	EnableDiffMarkdownFormat         = "enable-diff-markdown-format"
+	EnableApplyProvenanceFlag        = "enable-apply-provenance"
+	EnablePlanJSONAPIFlag            = "enable-plan-json-api"
 	EnablePolicyChecksFlag           = "enable-policy-checks"
 	EnableRegExpCmdFlag              = "enable-regexp-cmd"
+	EnableSBOMFlag                   = "enable-sbom"
+	EnableStateSnapshotsFlag         = "enable-state-snapshots"
 	ExecutableName                   = "executable-name"
 	FailOnPreWorkflowHookError       = "fail-on-pre-workflow-hook-error"
+	ForceUnlockStateAdminsFlag       = "force-unlock-state-admins"
 	HideUnchangedPlanComments        = "hide-unchanged-plan-comments"
 	GHHostnameFlag                   = "gh-hostname"
+	GHIssueOpsCommandsFlag           = "gh-issue-ops-commands"
 	GHTeamAllowlistFlag              = "gh-team-allowlist"
 	GHTokenFlag                      = "gh-token"
 	GHTokenFileFlag                  = "gh-token-file" // nolint: gosec
@@ -113,17 +140,29 @@ const (
 	GitlabUserFlag                   = "gitlab-user"
 	GitlabWebhookSecretFlag          = "gitlab-webhook-secret" // nolint: gosec
 	IncludeGitUntrackedFiles         = "include-git-untracked-files"
+	ImportReplanFlag                 = "import-replan"
+	JobLogHistoryDaysFlag            = "job-log-history-days"
 	APISecretFlag                    = "api-secret"
 	HidePrevPlanComments             = "hide-prev-plan-comments"
+	KubernetesNamespacesFlag         = "kubernetes-namespaces"
+	KubernetesOperatorFlag           = "kubernetes-operator"
 	QuietPolicyChecks                = "quiet-policy-checks"
+	LifecyclePluginsFlag             = "lifecycle-plugins"
 	LockingDBType                    = "locking-db-type"
 	LogLevelFlag                     = "log-level"
 	MarkdownTemplateOverridesDirFlag = "markdown-template-overrides-dir"
 	MaxCommentsPerCommand            = "max-comments-per-command"
+	MaxConcurrentClonesPerHostFlag   = "max-concurrent-clones-per-host"
 	ParallelPoolSize                 = "parallel-pool-size"
+	ParallelPlanSchedulingFlag       = "parallel-plan-scheduling"
+	ParallelRepoPoolSizeFlag         = "parallel-repo-pool-size"
 	StatsNamespace                   = "stats-namespace"
 	AllowDraftPRs                    = "allow-draft-prs"
+	PlanFreezeMessageFlag            = "plan-freeze-message"
 	PortFlag                         = "port"
+	PrintEffectiveConfigFlag         = "print-effective-config"
+	ProjectNameTemplateFlag          = "project-name-template"
+	ProjectStatusTemplateFlag        = "project-status-template"
 	RedisDB                          = "redis-db"
 	RedisHost                        = "redis-host"
 	RedisPassword                    = "redis-password"
@@ -132,7 +171,10 @@ const (
 	RedisInsecureSkipVerify          = "redis-insecure-skip-verify"
 	RepoConfigFlag                   = "repo-config"
 	RepoConfigJSONFlag               = "repo-config-json"
+	RepoConfigPathsFlag              = "repo-config-paths"
+	RepoConfigReloadSecondsFlag      = "repo-config-reload-seconds"
 	RepoAllowlistFlag                = "repo-allowlist"
+	RepoAllowlistRefreshSecondsFlag  = "repo-allowlist-refresh-seconds"
 	SilenceNoProjectsFlag            = "silence-no-projects"
 	SilenceForkPRErrorsFlag          = "silence-fork-pr-errors"
 	SilenceVCSStatusNoPlans          = "silence-vcs-status-no-plans"
@@ -140,21 +182,43 @@ const (
 	SilenceAllowlistErrorsFlag       = "silence-allowlist-errors"
 	SkipCloneNoChanges               = "skip-clone-no-changes"
 	SlackTokenFlag                   = "slack-token"
+	SMTPAddrFlag                     = "smtp-addr"
+	SMTPFromFlag                     = "smtp-from"
+	SMTPPasswordFlag                 = "smtp-password" // nolint: gosec
+	SMTPUsernameFlag                 = "smtp-username"
 	SSLCertFileFlag                  = "ssl-cert-file"
 	SSLKeyFileFlag                   = "ssl-key-file"
+	StalePlanDiscardDaysFlag         = "stale-plan-discard-days"
+	StalePlanReminderDaysFlag        = "stale-plan-reminder-days"
+	StateLockRetrySecondsFlag        = "state-lock-retry-seconds"
 	RestrictFileList                 = "restrict-file-list"
 	TFDistributionFlag               = "tf-distribution" // deprecated for DefaultTFDistributionFlag
 	TFDownloadFlag                   = "tf-download"
 	TFDownloadURLFlag                = "tf-download-url"
+	UsageHistoryDaysFlag             = "usage-history-days"
 	UseTFPluginCache                 = "use-tf-plugin-cache"
 	VarFileAllowlistFlag             = "var-file-allowlist"
 	VCSStatusName                    = "vcs-status-name"
+	VCSBreakerCooldownSecondsFlag    = "vcs-circuit-breaker-cooldown-seconds"
+	VCSBreakerThresholdFlag          = "vcs-circuit-breaker-threshold"
+	VCSCommentSplitFlag              = "vcs-comment-split"
+	VCSDebugLoggingFlag              = "vcs-debug-logging"
+	VCSDryRunFlag                    = "vcs-dry-run"
+	VCSHTTPConfigFlag                = "vcs-http-config"
+	VCSMaxRetriesFlag                = "vcs-max-retries"
+	WasmRuntimeFlag                  = "wasm-runtime"
 	IgnoreVCSStatusNames             = "ignore-vcs-status-names"
+	InstanceNameFlag                 = "instance-name"
 	TFEHostnameFlag                  = "tfe-hostname"
 	TFELocalExecutionModeFlag        = "tfe-local-execution-mode"
 	TFETokenFlag                     = "tfe-token"
+	TFModuleMirrorsFlag              = "tf-module-mirrors"
+	TFProviderMirrorURLFlag          = "tf-provider-mirror-url"
+	TFRegistryCredentialsFlag        = "tf-registry-credentials"
 	WriteGitCredsFlag                = "write-git-creds" // nolint: gosec
+	WebhookHistorySizeFlag           = "webhook-history-size"
 	WebhookHttpHeaders               = "webhook-http-headers"
+	WebhookRegistrationFlag          = "webhook-registration"
 	WebBasicAuthFlag                 = "web-basic-auth"
 	WebUsernameFlag                  = "web-username"
 	WebPasswordFlag                  = "web-password"
@@ -165,11 +229,13 @@ const (
 	DefaultADBasicPassword              = ""
 	DefaultADHostname                   = "dev.azure.com"
 	DefaultAutoDiscoverMode             = "auto"
-	DefaultAutoplanFileList             = "**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl,**/.terraform.lock.hcl"
+	DefaultAutoplanFileList             = "**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl,**/.terraform.lock.hcl,**/*.tfstack.hcl,**/*.tfdeploy.hcl"
 	DefaultAllowCommands                = "version,plan,apply,unlock,approve_policies"
 	DefaultCheckoutStrategy             = CheckoutStrategyBranch
 	DefaultCheckoutDepth                = 0
 	DefaultBitbucketBaseURL             = bitbucketcloud.BaseURL
+	DefaultBitbucketMaxAttempts         = 3
+	DefaultBitbucketMaxDelaySeconds     = 30
 	DefaultDataDir                      = "~/.atlantis"
 	DefaultEmojiReaction                = ""
 	DefaultExecutableName               = "atlantis"
@@ -183,6 +249,7 @@ const (
 	DefaultIgnoreVCSStatusNames         = ""
 	DefaultMaxCommentsPerCommand        = 100
 	DefaultParallelPoolSize             = 15
+	DefaultPlanFreezeMessage            = "**Error:** Plans are frozen. Comment `atlantis plan` again once the freeze is lifted."
 	DefaultStatsNamespace               = "atlantis"
 	DefaultPort                         = 4141
 	DefaultRedisDB                      = 0
@@ -194,6 +261,10 @@ const (
 	DefaultTFDownload                   = true
 	DefaultTFEHostname                  = "app.terraform.io"
 	DefaultVCSStatusName                = "atlantis"
+	DefaultVCSBreakerCooldownSeconds    = 60
+	DefaultRepoConfigGitReloadSeconds   = 60
+	DefaultRepoAllowlistRefreshSeconds  = 300
+	DefaultWasmRuntime                  = "wasmtime"
 	DefaultWebBasicAuth                 = false
 	DefaultWebUsername                  = "atlantis"
 	DefaultWebPassword                  = "atlantis"
@@ -222,13 +293,25 @@ var stringFlags = map[string]stringFlag{
 		description:  "Azure DevOps hostname to support cloud and self hosted instances.",
 		defaultValue: "dev.azure.com",
 	},
+	ADWorkItemAppliedStateFlag: {
+		description: fmt.Sprintf("State to move the work items linked to an Azure DevOps pull request to once all its projects are applied, ex. Resolved. Requires --%s.", ADWorkItemsFlag),
+	},
 	AllowCommandsFlag: {
 		description:  "Comma separated list of acceptable atlantis commands.",
 		defaultValue: DefaultAllowCommands,
 	},
+	ApplyWindowAdminsFlag: {
+		description: "Comma separated list of VCS usernames that are allowed to apply projects outside their apply windows with 'atlantis apply --override-apply-window', ex. 'alice,bob'." +
+			" Defaults to none, which means nobody can.",
+	},
 	AtlantisURLFlag: {
 		description: "URL that Atlantis can be reached at. Defaults to http://$(hostname):$port where $port is from --" + PortFlag + ". Supports a base path ex. https://example.com/basepath.",
 	},
+	AuthFailureHintFlag: {
+		description: "Go template of the hint posted with the output of plans and applies that fail because a provider rejected Atlantis's credentials, ex. expired AWS credentials." +
+			" Available variables are {{.Provider}}, {{.Reason}}, {{.Repo}} and {{.Project}}." +
+			" Defaults to \"{{.Reason}}. Contact your Atlantis administrators.\".",
+	},
 	AutoDiscoverModeFlag: {
 		description: "Auto discover mode controls whether projects in a repo are discovered by Atlantis. Defaults to 'auto' which " +
 			"means projects will be discovered when no explicit projects are defined in repo config. Also supports 'enabled' (always " +
@@ -261,6 +344,10 @@ var stringFlags = map[string]stringFlag{
 			" If using Bitbucket Cloud (bitbucket.org), do not set.",
 		defaultValue: DefaultBitbucketBaseURL,
 	},
+	BitbucketLabelPatternFlag: {
+		description: "Regular expression matching the labels of Bitbucket Server pull requests in their title and description, since Bitbucket Server doesn't have labels." +
+			" Its first group is the label. Defaults to matching tags like [label:no-autoplan].",
+	},
 	BitbucketWebhookSecretFlag: {
 		description: "Secret used to validate Bitbucket webhooks." +
 			" SECURITY WARNING: If not specified, Atlantis won't be able to validate that the incoming webhook call came from Bitbucket. " +
@@ -276,9 +363,20 @@ var stringFlags = map[string]stringFlag{
 			" after the pull request is merged.",
 		defaultValue: "branch",
 	},
+	CommentTranslationsFileFlag: {
+		description: "Path to a YAML file mapping English phrases of pull request comments and commit status descriptions to their translations, ex. 'Plan Failed: Plan fehlgeschlagen'." +
+			" Phrases are replaced wherever they appear, longest first.",
+	},
 	ConfigFlag: {
 		description: "Path to yaml config file where flag values can also be set.",
 	},
+	ConfigEnvFlag: {
+		description: "Environment whose overrides are layered on top of the --" + ConfigFlag + " file, ex. prod reads config.prod.yaml next to config.yaml." +
+			" Environment variables and flags still take precedence over both files.",
+	},
+	DatadogAPIKeyFlag: {
+		description: "API key for sending apply events to Datadog with webhooks of kind datadog.",
+	},
 	DataDirFlag: {
 		description:  "Path to directory to store Atlantis data.",
 		defaultValue: DefaultDataDir,
@@ -295,6 +393,15 @@ var stringFlags = map[string]stringFlag{
 		description:  "Emoji Reaction to use to react to comments.",
 		defaultValue: DefaultEmojiReaction,
 	},
+	EmojiReactionsFlag: {
+		description: "Comma separated list of emoji reactions added to the comment of a command as it progresses, ex. 'running:hourglass,success:rocket,failure:confused,apply.success:tada'." +
+			" Each entry is state:reaction for all commands or command.state:reaction for one command. States are queued, running, success and failure." +
+			" The queued reaction defaults to --" + EmojiReaction + ". Disabled by default.",
+	},
+	ForceUnlockStateAdminsFlag: {
+		description: "Comma separated list of VCS usernames that are allowed to run the 'force-unlock-state' command, ex. 'alice,bob'." +
+			" The command must also be enabled with --" + AllowCommandsFlag + ". Defaults to none, which means nobody can run it.",
+	},
 	ExecutableName: {
 		description:  "Comment command executable name.",
 		defaultValue: DefaultExecutableName,
@@ -303,6 +410,11 @@ var stringFlags = map[string]stringFlag{
 		description:  "Hostname of your Github Enterprise installation. If using github.com, no need to set.",
 		defaultValue: DefaultGHHostname,
 	},
+	GHIssueOpsCommandsFlag: {
+		description: "Comma separated list of commands that can be run from comments on GitHub issues (not pull requests) " +
+			"against the default branch, ex. 'plan,version'. Only read-only commands (" + strings.Join(server.IssueOpsAllowedCommands, ", ") + ") are supported " +
+			"and a project must be selected with -p or -d. Defaults to none, which disables issue-ops mode.",
+	},
 	GHTeamAllowlistFlag: {
 		description: "Comma separated list of key-value pairs representing the GitHub teams and the operations that " +
 			"the members of a particular team are allowed to perform. " +
@@ -312,7 +424,8 @@ var stringFlags = map[string]stringFlag{
 			"the 'ops' team the permissions to execute the 'apply' command, " +
 			"and allows the 'devops' team to perform any operation. If this argument is not provided, the default value (*:*) " +
 			"will be used and the default behavior will be to not check permissions " +
-			"and to allow users from any team to perform any operation.",
+			"and to allow users from any team to perform any operation. " +
+			"On Bitbucket Server the teams are the groups of the user, which requires the Bitbucket user to have the Admin global permission.",
 	},
 	GHUserFlag: {
 		description:  "GitHub username of API user.",
@@ -391,6 +504,16 @@ var stringFlags = map[string]stringFlag{
 	APISecretFlag: {
 		description: "Secret used to validate requests made to the /api/* endpoints",
 	},
+	KubernetesNamespacesFlag: {
+		description: "Comma separated list of the namespaces to read Atlantis' custom resources from when --" + KubernetesOperatorFlag + " is set," +
+			" each followed by the repos its AtlantisRepoConfig resources may configure, ex. 'team-a:github.com/org/team-a-*,platform:*'." +
+			" Required with --" + KubernetesOperatorFlag + ".",
+	},
+	LifecyclePluginsFlag: {
+		description: "Comma separated list of paths to executables that are run at the pre-plan, post-plan, pre-apply, post-apply and pull-closed events." +
+			" Each is passed the event as its argument and a JSON payload describing the pull request and project on stdin." +
+			" Plugins can veto a plan or apply by exiting non-zero or writing {\"veto\": true} to stdout, and annotate the comment with {\"annotation\": \"...\"}.",
+	},
 	LockingDBType: {
 		description:  "The locking database type to use for storing plan and apply locks.",
 		defaultValue: DefaultLockingDBType,
@@ -414,11 +537,18 @@ var stringFlags = map[string]stringFlag{
 		description: "The Redis Password for when using a Locking DB type of 'redis'.",
 	},
 	RepoConfigFlag: {
-		description: "Path to a repo config file, used to customize how Atlantis runs on each repo. See runatlantis.io/docs for more details.",
+		description: "Path to a repo config file, used to customize how Atlantis runs on each repo. See runatlantis.io/docs for more details." +
+			" Can also be a file in a git repo, ex. " + cfg.GitSourcePrefix + "https://github.com/org/repo.git//repos.yaml?ref=main, which is pulled every --" +
+			RepoConfigReloadSecondsFlag + ".",
 	},
 	RepoConfigJSONFlag: {
 		description: "Specify repo config as a JSON string. Useful if you don't want to write a config file to disk.",
 	},
+	RepoConfigPathsFlag: {
+		description: "Comma separated list of paths, relative to the repo root, that are searched in order for the repo-level config file." +
+			" For example: '.atlantis/atlantis.yaml,.config/atlantis.yaml,atlantis.yaml'. Defaults to 'atlantis.yaml'." +
+			" A repo_config_file set in the server-side repo config takes precedence.",
+	},
 	RepoAllowlistFlag: {
 		description: "Comma separated list of repositories that Atlantis will operate on. " +
 			"The format is {hostname}/{owner}/{repo}, ex. github.com/runatlantis/atlantis. '*' matches any characters until the next comma. Examples: " +
@@ -428,6 +558,18 @@ var stringFlags = map[string]stringFlag{
 	SlackTokenFlag: {
 		description: "API token for Slack notifications.",
 	},
+	SMTPAddrFlag: {
+		description: "host:port of the SMTP server used to send email notifications, ex. smtp.example.com:587.",
+	},
+	SMTPFromFlag: {
+		description: fmt.Sprintf("Address email notifications are sent from. Required with --%s.", SMTPAddrFlag),
+	},
+	SMTPPasswordFlag: {
+		description: fmt.Sprintf("Password for --%s.", SMTPUsernameFlag),
+	},
+	SMTPUsernameFlag: {
+		description: "Username to authenticate to the SMTP server with. If not set, emails are sent without authentication.",
+	},
 	SSLCertFileFlag: {
 		description: "File containing x509 Certificate used for serving HTTPS. If the cert is signed by a CA, the file should be the concatenation of the server's certificate, any intermediates, and the CA's certificate.",
 	},
@@ -451,6 +593,19 @@ var stringFlags = map[string]stringFlag{
 			" Only set if using TFC/E as a remote backend." +
 			" Should be specified via the ATLANTIS_TFE_TOKEN environment variable for security.",
 	},
+	TFModuleMirrorsFlag: {
+		description: "JSON object mapping Terraform registry hostnames to the URL of a modules API proxying them," +
+			` ex. {"registry.terraform.io": "https://mirror.example.com/v1/modules/"}.` +
+			" Atlantis runs terraform with a generated CLI config that downloads the registry's modules from the mirror.",
+	},
+	TFProviderMirrorURLFlag: {
+		description: "URL of a provider network mirror. Atlantis runs terraform with a generated CLI config that installs all providers from it.",
+	},
+	TFRegistryCredentialsFlag: {
+		description: "JSON object mapping Terraform registry hostnames to where to read their API token from, either env:NAME or file:PATH," +
+			` ex. {"app.terraform.io": "file:/etc/atlantis/tfc-token"}.` +
+			" Tokens are read before each command, passed to terraform as TF_TOKEN_* env vars and masked in its output.",
+	},
 	DefaultTFDistributionFlag: {
 		description:  fmt.Sprintf("Which TF distribution to use. Can be set to %s or %s.", TFDistributionTerraform, TFDistributionOpenTofu),
 		defaultValue: DefaultTFDistribution,
@@ -469,16 +624,55 @@ var stringFlags = map[string]stringFlag{
 			" Currently only implemented for GitHub.",
 		defaultValue: DefaultIgnoreVCSStatusNames,
 	},
+	InstanceNameFlag: {
+		description: "Name of this Atlantis instance when several instances share repos, ex. prod." +
+			" The instance only responds to comments addressed to it, only runs commands on the projects of the repo config that list it in their instances" +
+			" and defaults --" + ExecutableName + " and --" + VCSStatusName + " to atlantis-<name>.",
+	},
 	VCSStatusName: {
 		description:  "Name used to identify Atlantis for pull request statuses.",
 		defaultValue: DefaultVCSStatusName,
 	},
+	PlanFreezeMessageFlag: {
+		description:  "Comment posted instead of plans while plans are frozen by a freeze without a message.",
+		defaultValue: DefaultPlanFreezeMessage,
+	},
+	ProjectNameTemplateFlag: {
+		description: "Go template used to name projects that don't have a name in the repo config." +
+			" Available variables are {{.Repo}}, {{.RepoName}}, {{.Dir}}, {{.DirBase}}, {{.DirParts}} and {{.Workspace}}," +
+			" and {{lastDirs n .Dir}} keeps the last n elements of the dir. Generated names must be unique within a pull request." +
+			" If not set, unnamed projects are identified by their dir and workspace.",
+	},
+	ProjectStatusTemplateFlag: {
+		description: "Go template used to name the pull request status of each project." +
+			" Available variables are {{.StatusName}}, {{.Command}}, {{.Repo}}, {{.ProjectName}}, {{.Dir}}, {{.Workspace}} and {{.Project}}." +
+			" Defaults to \"{{.StatusName}}/{{.Command}}: {{.Project}}\".",
+	},
+	VCSHTTPConfigFlag: {
+		description: "HTTP proxy and TLS settings for each VCS host provided as a JSON string. The map key is the VCS host type" +
+			" (Github, Gitlab, BitbucketCloud, BitbucketServer, AzureDevops or Gitea) and the value can set proxy-url, ca-bundle, tls-min-version, client-cert and client-key." +
+			" For example: `{\"BitbucketServer\":{\"proxy-url\":\"http://proxy.corp:3128\",\"ca-bundle\":\"/etc/ssl/corp-ca.pem\"}}`.",
+	},
+	VCSCommentSplitFlag: {
+		description: "How long comments are split for each VCS host provided as a JSON string. The map key is the VCS host type" +
+			" and the value can set max-size, sep-start and sep-end, ex. `{\"BitbucketServer\":{\"max-size\":30000}}`." +
+			" Comments are split at the start of a resource or line where possible.",
+	},
+	WasmRuntimeFlag: {
+		description: "Path to the wasmtime compatible WASI runtime used to run wasm workflow steps." +
+			" The runtime sandboxes each module so it can only access the project dir and the environment variables the step declares.",
+		defaultValue: DefaultWasmRuntime,
+	},
 	WebhookHttpHeaders: {
 		description: "Additional headers added to each HTTP POST payload when using HTTP webhooks provided as a JSON string." +
 			" The map key is the header name and the value is the header value (string) or values (array of string)." +
 			" For example: `{\"Authorization\":\"Bearer some-token\",\"X-Custom-Header\":[\"value1\",\"value2\"]}`.",
 		defaultValue: "",
 	},
+	WebhookRegistrationFlag: {
+		description: "Check at startup that the repos and orgs in --" + RepoAllowlistFlag + " have webhooks that send events to Atlantis." +
+			" One of 'report' (log discrepancies) or 'sync' (also create and update webhooks). Currently only implemented for GitHub.",
+	},
 	WebUsernameFlag: {
 		description:  "Username used for Web Basic Authentication on Atlantis HTTP Middleware",
 		defaultValue: DefaultWebUsername,
@@ -490,10 +684,23 @@ var stringFlags = map[string]stringFlag{
 }
 
 var boolFlags = map[string]boolFlag{
+	ADWorkItemsFlag: {
+		description:  "Comment apply results on the Azure Boards work items linked to Azure DevOps pull requests.",
+		defaultValue: false,
+	},
+	AggregateCommitStatusesFlag: {
+		description:  "Only post one combined pull request status per command instead of one status per project. Useful if branch protection limits the number of required statuses.",
+		defaultValue: false,
+	},
 	AllowForkPRsFlag: {
 		description:  "Allow Atlantis to run on pull requests from forks. A security issue for public repos.",
 		defaultValue: false,
 	},
+	CloneCacheFlag: {
+		description: "Keep a bare clone of each repo in the data dir that's fetched incrementally and used as a reference when cloning pull requests," +
+			" so only the objects it doesn't have are downloaded.",
+		defaultValue: false,
+	},
 	AutoplanModules: {
 		description:  "Automatically plan projects that have a changed module from the local repository.",
 		defaultValue: false,
@@ -502,6 +709,10 @@ var boolFlags = map[string]boolFlag{
 		description:  "Automatically merge pull requests when all plans are successfully applied.",
 		defaultValue: false,
 	},
+	AutoReplanDivergedFlag: {
+		description:  "Automatically plan projects again, with the updated base branch merged in, when their apply fails the undiverged requirement.",
+		defaultValue: false,
+	},
 	DisableApplyAllFlag: {
 		description:  "Disable \"atlantis apply\" command without any flags (i.e. apply all). A specific project/workspace/directory has to be specified for applies.",
 		defaultValue: false,
@@ -521,14 +732,30 @@ var boolFlags = map[string]boolFlag{
 		description:  "Enables the discarding of approval if a new plan has been executed. Currently only Github is supported",
 		defaultValue: false,
 	},
+	EnablePlanJSONAPIFlag: {
+		description:  "Store the terraform show -json output of each plan and serve it from the authenticated /api/plans endpoint for external tools. Requires --api-secret.",
+		defaultValue: false,
+	},
 	EnablePolicyChecksFlag: {
 		description:  "Enable atlantis to run user defined policy checks.  This is explicitly disabled for TFE/TFC backends since plan files are inaccessible.",
 		defaultValue: false,
 	},
+	EnableApplyProvenanceFlag: {
+		description:  "Record the provenance of each apply (planfile hash, applier, policy results and job URL) and, when a pull request is automerged, set a commit status on the merge commit linking to the record. Records are stored in the data dir and returned by the /api/provenance endpoint.",
+		defaultValue: false,
+	},
 	EnableRegExpCmdFlag: {
 		description:  "Enable Atlantis to use regular expressions on plan/apply commands when \"-p\" flag is passed with it.",
 		defaultValue: false,
 	},
+	EnableSBOMFlag: {
+		description:  "Generate a software bill of materials (SBOM) of the Terraform version, providers and modules used by each apply. SBOMs are stored in the data dir and listed by the /api/sboms endpoint.",
+		defaultValue: false,
+	},
+	EnableStateSnapshotsFlag: {
+		description:  "Record a snapshot of the Terraform state and the applied plan after each successful apply so projects can be rolled back with the rollback command. Snapshots are stored in the data dir.",
+		defaultValue: false,
+	},
 	EnableDiffMarkdownFormat: {
 		description:  "Enable Atlantis to format Terraform plan output into a markdown-diff friendly format for color-coding purposes.",
 		defaultValue: false,
@@ -547,13 +774,27 @@ var boolFlags = map[string]boolFlag{
 	},
 	HidePrevPlanComments: {
 		description: "Hide previous plan comments to reduce clutter in the PR. " +
-			"VCS support is limited to: GitHub.",
+			"Comments are minimized on GitHub, collapsed on GitLab and Gitea, deleted on Bitbucket and their threads are closed on Azure DevOps.",
 		defaultValue: false,
 	},
 	IncludeGitUntrackedFiles: {
 		description:  "Include git untracked files in the Atlantis modified file scope.",
 		defaultValue: false,
 	},
+	ImportReplanFlag: {
+		description: "Plan the project again after a successful atlantis import and comment the updated plan with the import output." +
+			" Warns if the imported resources are still planned to be created.",
+		defaultValue: false,
+	},
+	KubernetesOperatorFlag: {
+		description: "Merge AtlantisRepoConfig and AtlantisPolicySet Kubernetes custom resources into the server-side repo config." +
+			" Resources are read with the pod's service account every --" + RepoConfigReloadSecondsFlag + " and their status reports whether they were applied.",
+		defaultValue: false,
+	},
+	PrintEffectiveConfigFlag: {
+		description:  "Print the configuration merged from the config files, environment variables and flags, with secrets redacted, and exit without starting the server.",
+		defaultValue: false,
+	},
 	ParallelPlanFlag: {
 		description:  "Run plan operations in parallel.",
 		defaultValue: false,
@@ -562,6 +803,11 @@ var boolFlags = map[string]boolFlag{
 		description:  "Run apply operations in parallel.",
 		defaultValue: false,
 	},
+	ParallelPlanSchedulingFlag: {
+		description: "Schedule parallel plans: projects plan after the projects they depend on and the fastest projects to plan last time start first." +
+			" Requires parallel plans to be enabled.",
+		defaultValue: false,
+	},
 	QuietPolicyChecks: {
 		description:  "Exclude policy check comments from pull requests unless there's an actual error from conftest. This also excludes warnings.",
 		defaultValue: false,
@@ -627,6 +873,16 @@ var boolFlags = map[string]boolFlag{
 		description:  "Enable websocket origin check",
 		defaultValue: false,
 	},
+	VCSDryRunFlag: {
+		description: "Read from VCS hosts as usual but log comments, commit statuses, merges and other writes instead of sending them." +
+			" Useful to trial a new config or Atlantis version against production webhooks. Terraform still runs, so consider disabling apply with --allow-commands.",
+		defaultValue: false,
+	},
+	VCSDebugLoggingFlag: {
+		description: "Log the requests to and responses from VCS hosts with credentials redacted. Can be toggled at runtime" +
+			" through the /api/vcs-debug-logging endpoint.",
+		defaultValue: false,
+	},
 	HideUnchangedPlanComments: {
 		description:  "Remove no-changes plan comments from the pull request.",
 		defaultValue: false,
@@ -637,16 +893,40 @@ var boolFlags = map[string]boolFlag{
 	},
 }
 var intFlags = map[string]intFlag{
+	BitbucketMaxAttemptsFlag: {
+		description: "Number of times Bitbucket Server requests answered with 429 Too Many Requests are attempted, backing off exponentially between attempts." +
+			" Set to 1 to disable retries.",
+		defaultValue: DefaultBitbucketMaxAttempts,
+	},
+	BitbucketMaxDelaySecondsFlag: {
+		description:  "Longest delay in seconds between the attempts of a Bitbucket Server request, including the delays asked by Retry-After headers.",
+		defaultValue: DefaultBitbucketMaxDelaySeconds,
+	},
 	CheckoutDepthFlag: {
 		description: fmt.Sprintf("Used only if --%s=%s.", CheckoutStrategyFlag, CheckoutStrategyMerge) +
 			" How many commits to include in each of base and feature branches when cloning repository." +
 			" If merge base is further behind than this number of commits from any of branches heads, full fetch will be performed.",
 		defaultValue: DefaultCheckoutDepth,
 	},
+	CommandLogHistorySizeFlag: {
+		description: "Number of recent commands whose logs are kept in memory so they can be fetched by pull request or trace ID with the /api/logs endpoint." +
+			" Defaults to 0, which disables the endpoint.",
+		defaultValue: 0,
+	},
+	JobLogHistoryDaysFlag: {
+		description: "Number of days the output of completed jobs is kept in the data directory so it can be searched on the /jobs/search page and with the /api/jobs endpoint." +
+			" Defaults to 0, which disables keeping it.",
+		defaultValue: 0,
+	},
 	MaxCommentsPerCommand: {
 		description:  "If non-zero, the maximum number of comments to split command output into before truncating.",
 		defaultValue: DefaultMaxCommentsPerCommand,
 	},
+	MaxConcurrentClonesPerHostFlag: {
+		description: "Max number of git clones and fetches that run against each VCS host at the same time. The others wait until one finishes." +
+			" Defaults to 0, which means no limit.",
+		defaultValue: 0,
+	},
 	GiteaPageSizeFlag: {
 		description:  "Optional value that specifies the number of results per page to expect from Gitea.",
 		defaultValue: DefaultGiteaPageSize,
@@ -655,6 +935,11 @@ var intFlags = map[string]intFlag{
 		description:  "Max size of the wait group that runs parallel plans and applies (if enabled).",
 		defaultValue: DefaultParallelPoolSize,
 	},
+	ParallelRepoPoolSizeFlag: {
+		description: "Max number of projects of a repo that plan at the same time across all pull requests when --" + ParallelPlanSchedulingFlag + " is set." +
+			" Defaults to 0, which means no limit.",
+		defaultValue: 0,
+	},
 	PortFlag: {
 		description:  "Port to bind to.",
 		defaultValue: DefaultPort,
@@ -667,6 +952,56 @@ var intFlags = map[string]intFlag{
 		description:  "The Redis Port for when using a Locking DB type of 'redis'.",
 		defaultValue: DefaultRedisPort,
 	},
+	RepoConfigReloadSecondsFlag: {
+		description: "How often in seconds to check the --" + RepoConfigFlag + " file for changes and reload it without restarting the server." +
+			" An invalid file is rejected and the current config is kept. Defaults to 0, which disables reloading, or to 60 if --" +
+			RepoConfigFlag + " is a git repo or --" + KubernetesOperatorFlag + " is set.",
+		defaultValue: 0,
+	},
+	RepoAllowlistRefreshSecondsFlag: {
+		description: "How often in seconds to discover the repos matched by the topic rules of --" + RepoAllowlistFlag +
+			", ex. github.com/myorg/topic:terraform.",
+		defaultValue: DefaultRepoAllowlistRefreshSeconds,
+	},
+	StalePlanDiscardDaysFlag: {
+		description: "Number of days after the stale plan reminder to discard the plans of a pull request and release its locks." +
+			" Defaults to 0, which never discards them. Requires --" + StalePlanReminderDaysFlag + ".",
+		defaultValue: 0,
+	},
+	StalePlanReminderDaysFlag: {
+		description: "Number of days the plans of a pull request can hold locks without being planned again or applied before Atlantis comments a reminder on it." +
+			" Defaults to 0, which disables reminders.",
+		defaultValue: 0,
+	},
+	StateLockRetrySecondsFlag: {
+		description: "How long in seconds to keep retrying Terraform commands that fail because the Terraform state is locked by another operation." +
+			" Retries back off exponentially. Defaults to 0, which means the command fails immediately and the lock holder is reported.",
+		defaultValue: 0,
+	},
+	VCSBreakerCooldownSecondsFlag: {
+		description:  "How long in seconds to stop calling a VCS host after its circuit breaker opens.",
+		defaultValue: DefaultVCSBreakerCooldownSeconds,
+	},
+	UsageHistoryDaysFlag: {
+		description: "Number of days the plans, applies and policy checks run on projects are kept in the data directory for the usage reports of the /api/usage endpoint." +
+			" Defaults to 0, which disables usage reports.",
+		defaultValue: 0,
+	},
+	VCSBreakerThresholdFlag: {
+		description: "Number of consecutive failed calls to a VCS host after which its circuit breaker opens. While it's open, calls to the host fail" +
+			" immediately and /healthz reports the host as degraded. Defaults to 0, which disables the circuit breaker.",
+		defaultValue: 0,
+	},
+	VCSMaxRetriesFlag: {
+		description: "Number of times to retry failed reads from a VCS host, ex. fetching the modified files of a pull request." +
+			" Retries back off exponentially. Writes like comments aren't retried.",
+		defaultValue: 0,
+	},
+	WebhookHistorySizeFlag: {
+		description: "Number of received webhooks to keep for each repo so they can be inspected and replayed with the /api/webhooks endpoints." +
+			" Secret headers are redacted. Defaults to 0, which disables the webhook history.",
+		defaultValue: 0,
+	},
 }
 
 var int64Flags = map[string]int64Flag{
@@ -829,6 +1164,18 @@ func (s *ServerCmd) preRun() error {
 			return errors.Wrapf(err, "invalid config: reading %s", configFile)
 		}
 	}
+	// If passed an environment then layer its overrides on top.
+	configEnv := s.Viper.GetString(ConfigEnvFlag)
+	if configEnv != "" {
+		if configFile == "" {
+			return fmt.Errorf("--%s requires --%s", ConfigEnvFlag, ConfigFlag)
+		}
+		envFile := configEnvFile(configFile, configEnv)
+		s.Viper.SetConfigFile(envFile)
+		if err := s.Viper.MergeInConfig(); err != nil {
+			return errors.Wrapf(err, "invalid config: reading %s", envFile)
+		}
+	}
 	return nil
 }
 
@@ -862,6 +1209,13 @@ func (s *ServerCmd) run() error {
 	s.securityWarnings(&userConfig)
 	s.trimAtSymbolFromUsers(&userConfig)
 
+	if s.Viper.GetBool(PrintEffectiveConfigFlag) {
+		if s.SilenceOutput {
+			return nil
+		}
+		return printEffectiveConfig(os.Stdout, userConfig)
+	}
+
 	// Config looks good. Start the server.
 	server, err := s.ServerCreator.NewServer(userConfig, server.Config{
 		AllowForkPRsFlag:          AllowForkPRsFlag,
@@ -916,6 +1270,9 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.EmojiReaction == "" {
 		c.EmojiReaction = DefaultEmojiReaction
 	}
+	if c.InstanceName != "" && !v.IsSet(ExecutableName) {
+		c.ExecutableName = DefaultExecutableName + "-" + c.InstanceName
+	}
 	if c.ExecutableName == "" {
 		c.ExecutableName = DefaultExecutableName
 	}
@@ -934,6 +1291,9 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.ParallelPoolSize == 0 {
 		c.ParallelPoolSize = DefaultParallelPoolSize
 	}
+	if c.PlanFreezeMessage == "" {
+		c.PlanFreezeMessage = DefaultPlanFreezeMessage
+	}
 	if c.StatsNamespace == "" {
 		c.StatsNamespace = DefaultStatsNamespace
 	}
@@ -946,6 +1306,21 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.RedisPort == 0 {
 		c.RedisPort = DefaultRedisPort
 	}
+	if c.BitbucketMaxAttempts == 0 {
+		c.BitbucketMaxAttempts = DefaultBitbucketMaxAttempts
+	}
+	if c.BitbucketMaxDelaySeconds == 0 {
+		c.BitbucketMaxDelaySeconds = DefaultBitbucketMaxDelaySeconds
+	}
+	if c.VCSBreakerCooldownSeconds == 0 {
+		c.VCSBreakerCooldownSeconds = DefaultVCSBreakerCooldownSeconds
+	}
+	if c.RepoAllowlistRefreshSeconds == 0 {
+		c.RepoAllowlistRefreshSeconds = DefaultRepoAllowlistRefreshSeconds
+	}
+	if c.RepoConfigReloadSeconds == 0 && (cfg.IsGitSource(c.RepoConfig) || c.KubernetesOperator) {
+		c.RepoConfigReloadSeconds = DefaultRepoConfigGitReloadSeconds
+	}
 	if c.TFDistribution != "" && c.DefaultTFDistribution == "" {
 		c.DefaultTFDistribution = c.TFDistribution
 	}
@@ -955,6 +1330,9 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.TFDownloadURL == "" {
 		c.TFDownloadURL = DefaultTFDownloadURL
 	}
+	if c.InstanceName != "" && !v.IsSet(VCSStatusName) {
+		c.VCSStatusName = DefaultVCSStatusName + "-" + c.InstanceName
+	}
 	if c.VCSStatusName == "" {
 		c.VCSStatusName = DefaultVCSStatusName
 	}
@@ -964,6 +1342,9 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.TFEHostname == "" {
 		c.TFEHostname = DefaultTFEHostname
 	}
+	if c.WasmRuntime == "" {
+		c.WasmRuntime = DefaultWasmRuntime
+	}
 	if c.WebUsername == "" {
 		c.WebUsername = DefaultWebUsername
 	}
@@ -992,10 +1373,96 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 			CheckoutStrategyBranch, CheckoutStrategyMerge)
 	}
 
+	if userConfig.InstanceName != "" && !valid.InstanceNameRegex.MatchString(userConfig.InstanceName) {
+		return fmt.Errorf("invalid --%s %q: must contain only lowercase letters, digits and dashes", InstanceNameFlag, userConfig.InstanceName)
+	}
+
+	if userConfig.MaxConcurrentClonesPerHost < 0 {
+		return fmt.Errorf("--%s must be 0 or greater", MaxConcurrentClonesPerHostFlag)
+	}
+
+	if userConfig.ParallelRepoPoolSize < 0 {
+		return fmt.Errorf("--%s must be 0 or greater", ParallelRepoPoolSizeFlag)
+	}
+	if userConfig.ParallelRepoPoolSize > 0 && !userConfig.ParallelPlanScheduling {
+		return fmt.Errorf("--%s requires --%s", ParallelRepoPoolSizeFlag, ParallelPlanSchedulingFlag)
+	}
+
+	if userConfig.AzureDevopsAppliedState != "" && !userConfig.AzureDevopsWorkItems {
+		return fmt.Errorf("--%s requires --%s", ADWorkItemAppliedStateFlag, ADWorkItemsFlag)
+	}
+
+	if (userConfig.SMTPAddr == "") != (userConfig.SMTPFrom == "") {
+		return fmt.Errorf("--%s and --%s are both required for email notifications", SMTPAddrFlag, SMTPFromFlag)
+	}
+
 	if (userConfig.SSLKeyFile == "") != (userConfig.SSLCertFile == "") {
 		return fmt.Errorf("--%s and --%s are both required for ssl", SSLKeyFileFlag, SSLCertFileFlag)
 	}
 
+	if userConfig.StateLockRetrySeconds < 0 {
+		return fmt.Errorf("--%s must not be negative", StateLockRetrySecondsFlag)
+	}
+
+	for flag, value := range map[string]int{
+		BitbucketMaxAttemptsFlag:      userConfig.BitbucketMaxAttempts,
+		BitbucketMaxDelaySecondsFlag:  userConfig.BitbucketMaxDelaySeconds,
+		CommandLogHistorySizeFlag:     userConfig.CommandLogHistorySize,
+		JobLogHistoryDaysFlag:         userConfig.JobLogHistoryDays,
+		StalePlanDiscardDaysFlag:      userConfig.StalePlanDiscardDays,
+		StalePlanReminderDaysFlag:     userConfig.StalePlanReminderDays,
+		UsageHistoryDaysFlag:          userConfig.UsageHistoryDays,
+		VCSBreakerCooldownSecondsFlag: userConfig.VCSBreakerCooldownSeconds,
+		VCSBreakerThresholdFlag:       userConfig.VCSBreakerThreshold,
+		VCSMaxRetriesFlag:             userConfig.VCSMaxRetries,
+		WebhookHistorySizeFlag:        userConfig.WebhookHistorySize,
+	} {
+		if value < 0 {
+			return fmt.Errorf("--%s must not be negative", flag)
+		}
+	}
+
+	if userConfig.StalePlanDiscardDays > 0 && userConfig.StalePlanReminderDays == 0 {
+		return fmt.Errorf("--%s requires --%s", StalePlanDiscardDaysFlag, StalePlanReminderDaysFlag)
+	}
+
+	if userConfig.ProjectNameTemplate != "" {
+		if _, err := events.NewProjectNameTemplate(userConfig.ProjectNameTemplate); err != nil {
+			return fmt.Errorf("invalid --%s: %w", ProjectNameTemplateFlag, err)
+		}
+	}
+
+	if userConfig.BitbucketLabelPattern != "" {
+		if _, err := bitbucketserver.NewLabelPattern(userConfig.BitbucketLabelPattern); err != nil {
+			return fmt.Errorf("invalid --%s: %w", BitbucketLabelPatternFlag, err)
+		}
+	}
+
+	if userConfig.AuthFailureHint != "" {
+		if _, err := events.NewAuthFailureHint(userConfig.AuthFailureHint); err != nil {
+			return fmt.Errorf("invalid --%s: %w", AuthFailureHintFlag, err)
+		}
+	}
+
+	if userConfig.ProjectStatusTemplate != "" {
+		if _, err := template.New(ProjectStatusTemplateFlag).Parse(userConfig.ProjectStatusTemplate); err != nil {
+			return fmt.Errorf("invalid --%s: %w", ProjectStatusTemplateFlag, err)
+		}
+	}
+
+	switch userConfig.WebhookRegistration {
+	case "", events.WebhookRegistrationReport, events.WebhookRegistrationSync:
+	default:
+		return fmt.Errorf("invalid --%s %q, must be %q or %q", WebhookRegistrationFlag, userConfig.WebhookRegistration, events.WebhookRegistrationReport, events.WebhookRegistrationSync)
+	}
+	if userConfig.WebhookRegistration != "" && userConfig.GithubUser == "" && userConfig.GithubAppID == 0 {
+		return fmt.Errorf("--%s requires GitHub credentials", WebhookRegistrationFlag)
+	}
+
+	if userConfig.EnablePlanJSONAPI && userConfig.APISecret == "" {
+		return fmt.Errorf("--%s requires --%s to be set", EnablePlanJSONAPIFlag, APISecretFlag)
+	}
+
 	// The following combinations are valid.
 	// 1. github user and (token or token file)
 	// 2. github app ID and (key file set or key set)
@@ -1054,6 +1521,42 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return fmt.Errorf("cannot use --%s and --%s at the same time", RepoConfigFlag, RepoConfigJSONFlag)
 	}
 
+	if userConfig.RepoAllowlistRefreshSeconds < 0 {
+		return fmt.Errorf("--%s must not be negative", RepoAllowlistRefreshSecondsFlag)
+	}
+
+	if userConfig.RepoConfigReloadSeconds < 0 {
+		return fmt.Errorf("--%s must not be negative", RepoConfigReloadSecondsFlag)
+	}
+	if userConfig.RepoConfigReloadSeconds > 0 && userConfig.RepoConfig == "" && !userConfig.KubernetesOperator {
+		return fmt.Errorf("--%s requires --%s", RepoConfigReloadSecondsFlag, RepoConfigFlag)
+	}
+	if _, err := events.ParseCommentReactions(userConfig.EmojiReactions); err != nil {
+		return errors.Wrapf(err, "invalid --%s", EmojiReactionsFlag)
+	}
+	if userConfig.KubernetesOperator && (userConfig.RepoConfigJSON != "" || cfg.IsGitSource(userConfig.RepoConfig)) {
+		return fmt.Errorf("--%s can only be used with a --%s file", KubernetesOperatorFlag, RepoConfigFlag)
+	}
+	if userConfig.KubernetesOperator {
+		if userConfig.KubernetesNamespaces == "" {
+			return fmt.Errorf("--%s requires --%s", KubernetesOperatorFlag, KubernetesNamespacesFlag)
+		}
+		if _, err := kubernetes.ParseNamespaces(userConfig.KubernetesNamespaces); err != nil {
+			return errors.Wrapf(err, "invalid --%s", KubernetesNamespacesFlag)
+		}
+	}
+	if cfg.IsGitSource(userConfig.RepoConfig) {
+		if _, err := cfg.ParseGitSource(userConfig.RepoConfig); err != nil {
+			return fmt.Errorf("invalid --%s: %w", RepoConfigFlag, err)
+		}
+	}
+
+	for _, path := range userConfig.ToRepoConfigPaths() {
+		if strings.HasPrefix(path, "/") || strings.Contains(path, "../") || strings.Contains(path, "..\\") {
+			return fmt.Errorf("--%s must only contain paths relative to the repo root, got %q", RepoConfigPathsFlag, path)
+		}
+	}
+
 	// Warn if any tokens have newlines.
 	for name, token := range map[string]string{
 		GHTokenFlag:                userConfig.GithubToken,
@@ -1084,10 +1587,30 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return errors.Wrapf(err, "invalid --%s", AllowCommandsFlag)
 	}
 
+	if _, err := userConfig.ToIssueOpsCommandNames(); err != nil {
+		return errors.Wrapf(err, "invalid --%s", GHIssueOpsCommandsFlag)
+	}
+
 	if _, err := userConfig.ToWebhookHttpHeaders(); err != nil {
 		return errors.Wrapf(err, "invalid --%s", WebhookHttpHeaders)
 	}
 
+	if _, err := userConfig.ToVCSHTTPConfigs(); err != nil {
+		return errors.Wrapf(err, "invalid --%s", VCSHTTPConfigFlag)
+	}
+
+	if _, err := userConfig.ToTFModuleMirrors(); err != nil {
+		return errors.Wrapf(err, "invalid --%s", TFModuleMirrorsFlag)
+	}
+
+	if err := userConfig.ValidateTFProviderMirrorURL(); err != nil {
+		return errors.Wrapf(err, "invalid --%s", TFProviderMirrorURLFlag)
+	}
+
+	if _, err := userConfig.ToTFRegistryCredentials(); err != nil {
+		return errors.Wrapf(err, "invalid --%s", TFRegistryCredentialsFlag)
+	}
+
 	return nil
 }
 
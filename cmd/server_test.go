@@ -15,6 +15,7 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"cmp"
 	"fmt"
 	"os"
@@ -60,21 +61,34 @@ var testFlags = map[string]interface{}{
 	ADUserFlag:                       "ad-user",
 	ADWebhookPasswordFlag:            "ad-wh-pass",
 	ADWebhookUserFlag:                "ad-wh-user",
+	ADWorkItemAppliedStateFlag:       "Resolved",
+	ADWorkItemsFlag:                  true,
 	AtlantisURLFlag:                  "url",
 	AutoplanModules:                  false,
 	AutoplanModulesFromProjects:      "",
 	AllowCommandsFlag:                "version,plan,apply,unlock,import,approve_policies",
+	AggregateCommitStatusesFlag:      true,
 	AllowForkPRsFlag:                 true,
+	ApplyWindowAdminsFlag:            "alice,bob",
+	AuthFailureHintFlag:              "{{.Reason}}. Contact #platform.",
 	APISecretFlag:                    "",
 	AutoDiscoverModeFlag:             "auto",
 	AutomergeFlag:                    true,
+	AutoReplanDivergedFlag:           true,
 	AutoplanFileListFlag:             "**/*.tf,**/*.yml",
 	BitbucketBaseURLFlag:             "https://bitbucket-base-url.com",
+	BitbucketLabelPatternFlag:        `#(\w+)`,
+	BitbucketMaxAttemptsFlag:         5,
+	BitbucketMaxDelaySecondsFlag:     10,
 	BitbucketTokenFlag:               "bitbucket-token",
 	BitbucketUserFlag:                "bitbucket-user",
 	BitbucketWebhookSecretFlag:       "bitbucket-secret",
 	CheckoutStrategyFlag:             CheckoutStrategyMerge,
 	CheckoutDepthFlag:                0,
+	CloneCacheFlag:                   true,
+	CommandLogHistorySizeFlag:        0,
+	CommentTranslationsFileFlag:      "/path/to/translations.yaml",
+	DatadogAPIKeyFlag:                "datadog-api-key",
 	DataDirFlag:                      "/path",
 	DefaultTFDistributionFlag:        "terraform",
 	DefaultTFVersionFlag:             "v0.11.0",
@@ -84,10 +98,13 @@ var testFlags = map[string]interface{}{
 	DisableGlobalApplyLockFlag:       false,
 	DiscardApprovalOnPlanFlag:        true,
 	EmojiReaction:                    "eyes",
+	EmojiReactionsFlag:               "success:rocket,apply.failure:confused",
 	ExecutableName:                   "atlantis",
 	FailOnPreWorkflowHookError:       false,
+	ForceUnlockStateAdminsFlag:       "alice,bob",
 	GHAllowMergeableBypassApply:      false,
 	GHHostnameFlag:                   "ghhostname",
+	GHIssueOpsCommandsFlag:           "plan,version",
 	GHTeamAllowlistFlag:              "",
 	GHTokenFlag:                      "token",
 	GHTokenFileFlag:                  "",
@@ -112,16 +129,26 @@ var testFlags = map[string]interface{}{
 	HideUnchangedPlanComments:        false,
 	HidePrevPlanComments:             false,
 	IncludeGitUntrackedFiles:         false,
+	ImportReplanFlag:                 true,
+	KubernetesNamespacesFlag:         "atlantis:*",
+	KubernetesOperatorFlag:           false,
+	LifecyclePluginsFlag:             "/plugins/a,/plugins/b",
 	LockingDBType:                    "boltdb",
 	LogLevelFlag:                     "debug",
 	MarkdownTemplateOverridesDirFlag: "/path2",
 	MaxCommentsPerCommand:            10,
+	MaxConcurrentClonesPerHostFlag:   4,
 	StatsNamespace:                   "atlantis",
 	AllowDraftPRs:                    true,
+	PlanFreezeMessageFlag:            "plans are frozen",
 	PortFlag:                         8181,
+	ProjectNameTemplateFlag:          "{{ lastDirs 2 .Dir }}-{{ .Workspace }}",
+	ProjectStatusTemplateFlag:        "{{.StatusName}}/{{.Command}}: {{.Repo}} {{.Project}}",
 	ParallelPoolSize:                 100,
 	ParallelPlanFlag:                 true,
 	ParallelApplyFlag:                true,
+	ParallelPlanSchedulingFlag:       true,
+	ParallelRepoPoolSizeFlag:         2,
 	QuietPolicyChecks:                false,
 	RedisHost:                        "",
 	RedisInsecureSkipVerify:          false,
@@ -132,6 +159,8 @@ var testFlags = map[string]interface{}{
 	RepoAllowlistFlag:                "github.com/runatlantis/atlantis",
 	RepoConfigFlag:                   "",
 	RepoConfigJSONFlag:               "",
+	RepoConfigPathsFlag:              ".atlantis/atlantis.yaml,atlantis.yaml",
+	RepoConfigReloadSecondsFlag:      0,
 	SilenceNoProjectsFlag:            false,
 	SilenceVCSStatusNoProjectsFlag:   false,
 	SilenceForkPRErrorsFlag:          true,
@@ -139,20 +168,44 @@ var testFlags = map[string]interface{}{
 	SilenceVCSStatusNoPlans:          true,
 	SkipCloneNoChanges:               true,
 	SlackTokenFlag:                   "slack-token",
+	SMTPAddrFlag:                     "smtp.example.com:587",
+	SMTPFromFlag:                     "atlantis@example.com",
+	SMTPPasswordFlag:                 "smtp-password",
+	SMTPUsernameFlag:                 "smtp-username",
 	SSLCertFileFlag:                  "cert-file",
 	SSLKeyFileFlag:                   "key-file",
+	StalePlanDiscardDaysFlag:         3,
+	StalePlanReminderDaysFlag:        7,
+	StateLockRetrySecondsFlag:        60,
+	VCSBreakerCooldownSecondsFlag:    30,
+	VCSBreakerThresholdFlag:          5,
+	VCSCommentSplitFlag:              `{"Gitea":{"max-size":50000}}`,
+	VCSDebugLoggingFlag:              true,
+	VCSDryRunFlag:                    true,
+	VCSHTTPConfigFlag:                `{"Github":{"tls-min-version":"1.3"}}`,
+	VCSMaxRetriesFlag:                2,
+	UsageHistoryDaysFlag:             365,
+	WasmRuntimeFlag:                  "/usr/local/bin/wasmtime",
 	RestrictFileList:                 false,
 	TFDistributionFlag:               "terraform",
 	TFDownloadFlag:                   true,
 	TFDownloadURLFlag:                "https://my-hostname.com",
 	TFEHostnameFlag:                  "my-hostname",
+	TFModuleMirrorsFlag:              `{"registry.terraform.io": "https://mirror.example.com/v1/modules/"}`,
+	TFProviderMirrorURLFlag:          "https://mirror.example.com/providers/",
+	TFRegistryCredentialsFlag:        `{"app.terraform.io": "env:TFC_TOKEN"}`,
 	TFELocalExecutionModeFlag:        true,
 	TFETokenFlag:                     "my-token",
 	UseTFPluginCache:                 true,
 	VarFileAllowlistFlag:             "/path",
 	VCSStatusName:                    "my-status",
 	IgnoreVCSStatusNames:             "",
+	InstanceNameFlag:                 "prod",
+	JobLogHistoryDaysFlag:            30,
+	RepoAllowlistRefreshSecondsFlag:  600,
+	WebhookHistorySizeFlag:           0,
 	WebhookHttpHeaders:               `{"Authorization":"Bearer some-token","X-Custom-Header":["value1","value2"]}`,
+	WebhookRegistrationFlag:          "report",
 	WebBasicAuthFlag:                 false,
 	WebPasswordFlag:                  "atlantis",
 	WebUsernameFlag:                  "atlantis",
@@ -161,8 +214,12 @@ var testFlags = map[string]interface{}{
 	DisableAutoplanFlag:              true,
 	DisableAutoplanLabelFlag:         "no-auto-plan",
 	DisableUnlockLabelFlag:           "do-not-unlock",
+	EnableApplyProvenanceFlag:        false,
+	EnablePlanJSONAPIFlag:            false,
 	EnablePolicyChecksFlag:           false,
 	EnableRegExpCmdFlag:              false,
+	EnableSBOMFlag:                   false,
+	EnableStateSnapshotsFlag:         false,
 	EnableDiffMarkdownFormat:         false,
 }
 
@@ -199,7 +256,8 @@ func TestExecute_Defaults(t *testing.T) {
 		VarFileAllowlistFlag:             dataDir,
 	}
 	strIgnore := map[string]bool{
-		"config": true,
+		"config":     true,
+		"config-env": true,
 	}
 	for flag, cfg := range stringFlags {
 		t.Log(flag)
@@ -211,8 +269,14 @@ func TestExecute_Defaults(t *testing.T) {
 			Equals(t, cfg.defaultValue, configVal(t, passedConfig, flag))
 		}
 	}
+	boolIgnore := map[string]bool{
+		PrintEffectiveConfigFlag: true,
+	}
 	for flag, cfg := range boolFlags {
 		t.Log(flag)
+		if boolIgnore[flag] {
+			continue
+		}
 		Equals(t, cfg.defaultValue, configVal(t, passedConfig, flag))
 	}
 	for flag, cfg := range intFlags {
@@ -344,8 +408,8 @@ func TestAllFlagsDocumented(t *testing.T) {
 	}
 
 	for _, documentedFlag := range documentedFlags {
-		// --help and --config are documented but don't have a setting on userConfig
-		if documentedFlag == "help" || documentedFlag == "config" {
+		// --help, --config, --config-env and --print-effective-config are documented but don't have a setting on userConfig
+		if documentedFlag == "help" || documentedFlag == "config" || documentedFlag == "config-env" || documentedFlag == "print-effective-config" {
 			continue
 		}
 		_, found := slices.BinarySearch(userConfigKeys, documentedFlag)
@@ -437,6 +501,64 @@ func TestExecute_InvalidConfig(t *testing.T) {
 	Assert(t, strings.Contains(err.Error(), "unmarshal errors"), "should be an unmarshal error")
 }
 
+func TestExecute_ConfigEnv(t *testing.T) {
+	t.Log("The overrides of --config-env should be layered on top of the config file and below env vars.")
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	Ok(t, os.WriteFile(configFile, []byte("gh-user: user\ngh-token: token\nrepo-allowlist: '*'\nport: 8080\nlog-level: debug\n"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(dir, "config.prod.yaml"), []byte("port: 9090\nlog-level: warn\n"), 0600))
+	t.Setenv("ATLANTIS_LOG_LEVEL", "error")
+
+	c := setup(map[string]interface{}{
+		ConfigFlag:    configFile,
+		ConfigEnvFlag: "prod",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, "user", passedConfig.GithubUser)
+	Equals(t, 9090, passedConfig.Port)
+	Equals(t, "error", passedConfig.LogLevel)
+}
+
+func TestExecute_ConfigEnvErrors(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		ConfigEnvFlag: "prod",
+	}, t)
+	ErrEquals(t, "--config-env requires --config", c.Execute())
+
+	configFile := tempFile(t, "")
+	defer os.Remove(configFile) // nolint: errcheck
+	c = setupWithDefaults(map[string]interface{}{
+		ConfigFlag:    configFile,
+		ConfigEnvFlag: "prod",
+	}, t)
+	envFile := strings.TrimSuffix(configFile, ".yaml") + ".prod.yaml"
+	ErrEquals(t, fmt.Sprintf("invalid config: reading %s: open %s: no such file or directory", envFile, envFile), c.Execute())
+}
+
+func TestPrintEffectiveConfig(t *testing.T) {
+	var out bytes.Buffer
+	Ok(t, printEffectiveConfig(&out, server.UserConfig{
+		GithubUser:  "user",
+		GithubToken: "token",
+		Port:        4141,
+		Webhooks: []server.WebhookConfig{
+			{Event: "apply", Kind: "slack", Channel: "infra"},
+			{Event: "apply", Kind: "http", URL: "https://example.com/hook?token=secret"},
+		},
+	}))
+	var config map[string]any
+	Ok(t, yaml.Unmarshal(out.Bytes(), &config))
+	Equals(t, "user", config[GHUserFlag])
+	Equals(t, "<redacted>", config[GHTokenFlag])
+	Equals(t, "", config[GHWebhookSecretFlag])
+	Equals(t, 4141, config[PortFlag])
+	webhooks := config["webhooks"].([]any)
+	Equals(t, 2, len(webhooks))
+	Equals(t, "infra", webhooks[0].(map[string]any)["channel"])
+	Equals(t, "", webhooks[0].(map[string]any)["url"])
+	Equals(t, "<redacted>", webhooks[1].(map[string]any)["url"])
+}
+
 // Should error if the repo allowlist contained a scheme.
 func TestExecute_RepoAllowlistScheme(t *testing.T) {
 	c := setup(map[string]interface{}{
@@ -971,6 +1093,17 @@ func TestExecute_BitbucketServerBaseURLPort(t *testing.T) {
 	Equals(t, "http://mydomain.com:7990", passedConfig.BitbucketBaseURL)
 }
 
+func TestExecute_BitbucketLabelPattern(t *testing.T) {
+	c := setup(map[string]interface{}{
+		BitbucketUserFlag:         "user",
+		BitbucketTokenFlag:        "token",
+		RepoAllowlistFlag:         "*",
+		BitbucketBaseURLFlag:      "http://mydomain.com:7990",
+		BitbucketLabelPatternFlag: "#label",
+	}, t)
+	ErrEquals(t, "invalid --bitbucket-label-pattern: must have a group capturing the label", c.Execute())
+}
+
 // Can't use both --repo-config and --repo-config-json.
 func TestExecute_RepoCfgFlags(t *testing.T) {
 	c := setup(map[string]interface{}{
@@ -984,6 +1117,31 @@ func TestExecute_RepoCfgFlags(t *testing.T) {
 	ErrEquals(t, "cannot use --repo-config and --repo-config-json at the same time", err)
 }
 
+// Repo config paths must be relative to the repo root.
+func TestExecute_RepoConfigPathsAbsolute(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GHUserFlag:          "user",
+		GHTokenFlag:         "token",
+		RepoAllowlistFlag:   "github.com",
+		RepoConfigPathsFlag: "atlantis.yaml,/etc/atlantis.yaml",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--repo-config-paths must only contain paths relative to the repo root, got \"/etc/atlantis.yaml\"", err)
+}
+
+// Reloading the repo config requires a repo config file.
+func TestExecute_RepoConfigReloadWithoutRepoConfig(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GHUserFlag:                  "user",
+		GHTokenFlag:                 "token",
+		RepoAllowlistFlag:           "github.com",
+		RepoConfigJSONFlag:          "{}",
+		RepoConfigReloadSecondsFlag: 30,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--repo-config-reload-seconds requires --repo-config", err)
+}
+
 // Can't use both --tfe-hostname flag without --tfe-token.
 func TestExecute_TFEHostnameOnly(t *testing.T) {
 	c := setup(map[string]interface{}{
@@ -1110,6 +1268,98 @@ func TestExecute_ValidateDefaultTFDistribution(t *testing.T) {
 	}
 }
 
+func TestExecute_ValidatePlanJSONAPI(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		EnablePlanJSONAPIFlag: true,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--enable-plan-json-api requires --api-secret to be set", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		EnablePlanJSONAPIFlag: true,
+		APISecretFlag:         "secret",
+	}, t)
+	Ok(t, c.Execute())
+}
+
+func TestExecute_ValidateParallelRepoPoolSize(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		ParallelRepoPoolSizeFlag: 2,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--parallel-repo-pool-size requires --parallel-plan-scheduling", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		ParallelPlanSchedulingFlag: true,
+		ParallelRepoPoolSizeFlag:   -1,
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--parallel-repo-pool-size must be 0 or greater", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		ParallelPlanSchedulingFlag: true,
+		ParallelRepoPoolSizeFlag:   2,
+	}, t)
+	Ok(t, c.Execute())
+}
+
+func TestExecute_ValidateMaxConcurrentClonesPerHost(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		MaxConcurrentClonesPerHostFlag: -1,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--max-concurrent-clones-per-host must be 0 or greater", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		MaxConcurrentClonesPerHostFlag: 2,
+	}, t)
+	Ok(t, c.Execute())
+}
+
+func TestExecute_ValidateKubernetesNamespaces(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		KubernetesOperatorFlag: true,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--kubernetes-operator requires --kubernetes-namespaces", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		KubernetesOperatorFlag:   true,
+		KubernetesNamespacesFlag: "atlantis",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "invalid --kubernetes-namespaces: invalid namespace \"atlantis\": must be of the form namespace:repo-pattern, ex. atlantis:github.com/org/*", err)
+
+	c = setupWithDefaults(map[string]interface{}{
+		KubernetesOperatorFlag:   true,
+		KubernetesNamespacesFlag: "atlantis:github.com/org/*",
+	}, t)
+	Ok(t, c.Execute())
+}
+
+func TestExecute_InstanceName(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		InstanceNameFlag: "prod",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, "atlantis-prod", passedConfig.ExecutableName)
+	Equals(t, "atlantis-prod", passedConfig.VCSStatusName)
+
+	c = setupWithDefaults(map[string]interface{}{
+		InstanceNameFlag: "prod",
+		ExecutableName:   "atlantis",
+		VCSStatusName:    "atlantis/prod",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, "atlantis", passedConfig.ExecutableName)
+	Equals(t, "atlantis/prod", passedConfig.VCSStatusName)
+
+	c = setupWithDefaults(map[string]interface{}{
+		InstanceNameFlag: "Prod_1",
+	}, t)
+	ErrEquals(t, `invalid --instance-name "Prod_1": must contain only lowercase letters, digits and dashes`, c.Execute())
+}
+
 func setup(flags map[string]interface{}, t *testing.T) *cobra.Command {
 	vipr := viper.New()
 	for k, v := range flags {
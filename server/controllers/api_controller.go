@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 	tally "github.com/uber-go/tally/v4"
 )
@@ -37,6 +41,56 @@ type APIController struct {
 	WorkingDir                     events.WorkingDir                     `validate:"required"`
 	WorkingDirLocker               events.WorkingDirLocker               `validate:"required"`
 	CommitStatusUpdater            events.CommitStatusUpdater            `validate:"required"`
+	// SBOMStore is nil if SBOMs aren't enabled.
+	SBOMStore events.SBOMStore
+	// ProvenanceStore is nil if apply provenance isn't enabled.
+	ProvenanceStore events.ProvenanceStore
+	// PlanJSONStore is nil if the plan JSON API isn't enabled.
+	PlanJSONStore events.PlanJSONStore
+	// VCSDebugLogging toggles logging of VCS API calls.
+	VCSDebugLogging *vcs.DebugLogging
+	// RepoConfigReloader is nil if reloading the server-side repo config isn't
+	// enabled.
+	RepoConfigReloader RepoConfigReloader
+	// Webhooks is nil unless received webhooks are kept.
+	Webhooks Webhooks
+	// LogStore is nil unless the logs of commands are kept.
+	LogStore *logging.LogStore
+	// JobLogStore is nil unless the output of completed jobs is kept.
+	JobLogStore jobs.JobLogStore
+	// UsageLog is nil unless usage reports are enabled.
+	UsageLog events.UsageLog
+	// PlanFreezer manages the freezes that disable plans.
+	PlanFreezer locking.PlanFreezer
+	// ChangeSets stores the change sets whose pull requests are planned and
+	// applied together.
+	ChangeSets events.ChangeSetStore
+	// ProjectReplanner replans a project on all the open pull requests.
+	ProjectReplanner *events.ProjectReplanner
+	// GithubStatusChecks is nil unless GitHub is configured.
+	GithubStatusChecks GithubStatusChecksLister
+	// StatusName is the name Atlantis' commit statuses start with.
+	StatusName string
+	// GlobalCfgStore holds the server-side repo config, read for the GitHub
+	// status mode of each repo.
+	GlobalCfgStore *valid.GlobalCfgStore
+}
+
+// GithubStatusChecksLister lists the checks required by the branch protection
+// of GitHub repos.
+type GithubStatusChecksLister interface {
+	ListRequiredStatusChecks(logger logging.SimpleLogging, repo models.Repo) ([]vcs.RequiredStatusCheck, error)
+}
+
+// Webhooks lists and replays the webhooks Atlantis received.
+type Webhooks interface {
+	ListWebhooks(repo string) []events_controllers.ReceivedWebhook
+	Replay(id string) (int, string, error)
+}
+
+// RepoConfigReloader reloads the server-side repo config.
+type RepoConfigReloader interface {
+	Reload() error
 }
 
 type APIRequest struct {
@@ -49,6 +103,8 @@ type APIRequest struct {
 		Directory string
 		Workspace string
 	}
+	// Labels selects the projects with every label, ex. {"tier": "prod"}.
+	Labels map[string]string
 }
 
 func (a *APIRequest) getCommands(ctx *command.Context, cmdBuilder func(*command.Context, *events.CommentCommand) ([]command.ProjectContext, error)) ([]command.ProjectContext, []*events.CommentCommand, error) {
@@ -65,6 +121,11 @@ func (a *APIRequest) getCommands(ctx *command.Context, cmdBuilder func(*command.
 			Workspace:  path.Workspace,
 		})
 	}
+	if len(a.Labels) > 0 {
+		cc = append(cc, &events.CommentCommand{
+			Labels: a.Labels,
+		})
+	}
 
 	cmds := make([]command.ProjectContext, 0)
 	for _, commentCommand := range cc {
@@ -209,25 +270,319 @@ func (a *APIController) ListLocks(w http.ResponseWriter, r *http.Request) {
 	a.respond(w, logging.Warn, http.StatusOK, "%s", string(response))
 }
 
+type ListSBOMsResult struct {
+	SBOMs []models.SBOM
+}
+
+// ListSBOMs returns the SBOMs of the applies for the pull request in the
+// repository and pull query parameters.
+func (a *APIController) ListSBOMs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.SBOMStore == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since SBOMs are disabled"))
+		return
+	}
+	repository := r.URL.Query().Get("repository")
+	if repository == "" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("missing repository query parameter"))
+		return
+	}
+	pullNum, err := strconv.Atoi(r.URL.Query().Get("pull"))
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid pull query parameter: %q", r.URL.Query().Get("pull")))
+		return
+	}
+
+	sboms, err := a.SBOMStore.List(repository, pullNum)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response, err := json.Marshal(ListSBOMsResult{SBOMs: sboms})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// GetProvenance returns the provenance record of the automerged pull request
+// in the repository and pull query parameters. Its SHA-256 digest is in the
+// description of the provenance commit status of the merge commit.
+func (a *APIController) GetProvenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.ProvenanceStore == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since apply provenance is disabled"))
+		return
+	}
+	repository := r.URL.Query().Get("repository")
+	if repository == "" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("missing repository query parameter"))
+		return
+	}
+	pullNum, err := strconv.Atoi(r.URL.Query().Get("pull"))
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid pull query parameter: %q", r.URL.Query().Get("pull")))
+		return
+	}
+
+	provenance, err := a.ProvenanceStore.Get(repository, pullNum)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if provenance == nil {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no provenance recorded for %s#%d", repository, pullNum))
+		return
+	}
+
+	response, err := json.Marshal(provenance)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+type ListCommandLogsResult struct {
+	Commands []logging.CommandLogs
+}
+
+// ListCommandLogs returns the logs of the recent commands run on the pull
+// request in the repository and pull query parameters, newest first, or of the
+// command in the trace_id query parameter. The command query parameter
+// optionally filters the commands by name, ex. plan.
+func (a *APIController) ListCommandLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.LogStore == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since keeping command logs is disabled"))
+		return
+	}
+	query := r.URL.Query()
+	traceID := query.Get("trace_id")
+	repository := query.Get("repository")
+	var pullNum int
+	if traceID == "" {
+		if repository == "" {
+			a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("missing repository or trace_id query parameter"))
+			return
+		}
+		var err error
+		pullNum, err = strconv.Atoi(query.Get("pull"))
+		if err != nil {
+			a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid pull query parameter: %q", query.Get("pull")))
+			return
+		}
+	}
+
+	response, err := json.Marshal(ListCommandLogsResult{
+		Commands: a.LogStore.Find(repository, pullNum, query.Get("command"), traceID),
+	})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+type SearchJobLogsResult struct {
+	Jobs []jobs.JobLog
+}
+
+// SearchJobLogs returns the completed jobs matching the repo, project, step,
+// text, from, to and limit query parameters, the most recently completed
+// first. See parseJobLogQuery.
+func (a *APIController) SearchJobLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.JobLogStore == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since keeping job output is disabled"))
+		return
+	}
+	query, err := parseJobLogQuery(r.URL.Query())
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	found, err := a.JobLogStore.Search(query)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response, err := json.Marshal(SearchJobLogsResult{Jobs: found})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+type UsageReportResult struct {
+	From    time.Time
+	To      time.Time
+	GroupBy []string
+	Rows    []events.UsageReportRow
+}
+
+// UsageReport aggregates the plans, applies and policy checks that started
+// between the from and to query parameters by the dimensions of the group_by
+// query parameter, ex. repo,week. The report is CSV if the format query
+// parameter is csv. See parseJobLogTime for the format of the times.
+func (a *APIController) UsageReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.UsageLog == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since usage reports are disabled"))
+		return
+	}
+	query := r.URL.Query()
+	groupBy, err := events.ParseUsageGroupBy(query.Get("group_by"))
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid group_by query parameter: %w", err))
+		return
+	}
+	from, err := parseJobLogTime(query.Get("from"), false)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid from query parameter: %w", err))
+		return
+	}
+	to, err := parseJobLogTime(query.Get("to"), true)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid to query parameter: %w", err))
+		return
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid format query parameter: %q", format))
+		return
+	}
+
+	records, err := a.UsageLog.List(from, to)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	report := events.BuildUsageReport(records, groupBy)
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="atlantis-usage.csv"`)
+		if err := events.WriteUsageReportCSV(w, report, groupBy); err != nil {
+			a.Logger.Err("writing usage report: %s", err)
+		}
+		return
+	}
+	response, err := json.Marshal(UsageReportResult{From: from, To: to, GroupBy: groupBy, Rows: report})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+type ListPlansResult struct {
+	Plans []models.PlanJSON
+}
+
+// ListPlans returns the terraform show -json output of the latest plan of
+// each project of the pull request in the repository and pull query
+// parameters. The project, directory and workspace query parameters
+// optionally filter the projects.
+func (a *APIController) ListPlans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.PlanJSONStore == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since the plan JSON API is disabled"))
+		return
+	}
+	query := r.URL.Query()
+	repository := query.Get("repository")
+	if repository == "" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("missing repository query parameter"))
+		return
+	}
+	pullNum, err := strconv.Atoi(query.Get("pull"))
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid pull query parameter: %q", query.Get("pull")))
+		return
+	}
+
+	plans, err := a.PlanJSONStore.List(repository, pullNum)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	labels, err := valid.ParseLabelSelector(query["label"])
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	result := ListPlansResult{}
+	for _, plan := range plans {
+		if query.Has("project") && plan.ProjectName != query.Get("project") {
+			continue
+		}
+		if query.Has("directory") && plan.RepoRelDir != strings.TrimRight(query.Get("directory"), "/") {
+			continue
+		}
+		if query.Has("workspace") && plan.Workspace != query.Get("workspace") {
+			continue
+		}
+		if !valid.MatchLabels(plan.Labels, labels) {
+			continue
+		}
+		result.Plans = append(result.Plans, plan)
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
 func (a *APIController) apiSetup(ctx *command.Context) error {
 	pull := ctx.Pull
 	baseRepo := ctx.Pull.BaseRepo
 	headRepo := ctx.HeadRepo
 
-	unlockFn, err := a.WorkingDirLocker.TryLock(baseRepo.FullName, pull.Num, events.DefaultWorkspace, events.DefaultRepoRelDir)
-	if err != nil {
-		return err
-	}
+	unlockFn := a.WorkingDirLocker.LockWorkspace(baseRepo.FullName, pull.Num, events.DefaultWorkspace)
 	ctx.Log.Debug("got workspace lock")
 	defer unlockFn()
 
 	// ensure workingDir is present
-	_, _, err = a.WorkingDir.Clone(ctx.Log, headRepo, pull, events.DefaultWorkspace)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	_, _, err := a.WorkingDir.Clone(ctx.Log, headRepo, pull, events.DefaultWorkspace)
+	return err
 }
 
 func (a *APIController) apiPlan(request *APIRequest, ctx *command.Context) (*command.Result, error) {
@@ -286,15 +641,539 @@ func (a *APIController) apiApply(request *APIRequest, ctx *command.Context) (*co
 	return &command.Result{ProjectResults: projectResults}, nil
 }
 
-func (a *APIController) apiParseAndValidate(r *http.Request) (*APIRequest, *command.Context, int, error) {
+// apiAuthenticate returns an error and the status code to respond with if
+// the API is disabled or the request doesn't have the secret token.
+type VCSDebugLoggingRequest struct {
+	Enabled bool
+}
+
+type VCSDebugLoggingResult struct {
+	Enabled bool
+}
+
+// GetVCSDebugLogging returns whether VCS API calls are being logged.
+func (a *APIController) GetVCSDebugLogging(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	a.respondVCSDebugLogging(w)
+}
+
+// SetVCSDebugLogging turns logging of VCS API calls on or off. It lasts
+// until Atlantis restarts.
+func (a *APIController) SetVCSDebugLogging(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	var request VCSDebugLoggingRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+		return
+	}
+	a.VCSDebugLogging.SetEnabled(request.Enabled)
+	a.Logger.Info("VCS debug logging enabled: %t", request.Enabled)
+	a.respondVCSDebugLogging(w)
+}
+
+type ListWebhooksResult struct {
+	Webhooks []events_controllers.ReceivedWebhook
+}
+
+// ListWebhooks returns the webhooks received for a repository, newest first.
+// Headers that carry secrets are redacted.
+func (a *APIController) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Webhooks == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since the webhook history is disabled"))
+		return
+	}
+	response, err := json.Marshal(ListWebhooksResult{Webhooks: a.Webhooks.ListWebhooks(r.URL.Query().Get("repository"))})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+type ReplayWebhookRequest struct {
+	ID string `validate:"required"`
+}
+
+type ReplayWebhookResult struct {
+	StatusCode int
+	Response   string
+}
+
+// ReplayWebhook sends a received webhook to Atlantis again, as if the VCS
+// host had redelivered it, and returns Atlantis' response.
+func (a *APIController) ReplayWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Webhooks == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since the webhook history is disabled"))
+		return
+	}
+	var request ReplayWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+		return
+	}
+	if err := validator.New().Struct(request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("request is missing the ID of the webhook to replay"))
+		return
+	}
+	code, body, err := a.Webhooks.Replay(request.ID)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	response, err := json.Marshal(ReplayWebhookResult{StatusCode: code, Response: body})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Info, http.StatusOK, "%s", string(response))
+}
+
+type RepoConfigReloadResult struct {
+	Reloaded bool
+}
+
+// ReloadRepoConfig reloads the server-side repo config without waiting for
+// the next periodic check. If the config is invalid, the current one is kept.
+func (a *APIController) ReloadRepoConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.RepoConfigReloader == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since reloading the repo config is disabled"))
+		return
+	}
+	if err := a.RepoConfigReloader.Reload(); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	a.Logger.Info("reloaded server-side repo config by API request")
+	response, err := json.Marshal(RepoConfigReloadResult{Reloaded: true})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// PlanFreezeRequest selects the scope of a plan freeze. Plans are frozen
+// globally if Repository is empty, for the repository if Project and Dir are
+// empty or otherwise for its projects with the name Project or in Dir.
+type PlanFreezeRequest struct {
+	Repository string
+	Project    string
+	Dir        string
+	// Message is commented instead of plans. It defaults to
+	// --plan-freeze-message.
+	Message string
+}
+
+type ListPlanFreezesResult struct {
+	Freezes []locking.PlanFreeze
+}
+
+type PlanUnfreezeResult struct {
+	Deleted bool
+}
+
+// ListPlanFreezes returns the active plan freezes, oldest first.
+func (a *APIController) ListPlanFreezes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	freezes, err := a.PlanFreezer.List()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response, err := json.Marshal(ListPlanFreezesResult{Freezes: freezes})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// FreezePlans disables plan commands and autoplans in the scope of the
+// request until the freeze is deleted. It replaces any freeze with the same
+// scope.
+func (a *APIController) FreezePlans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	var request PlanFreezeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+		return
+	}
+	freeze, err := a.PlanFreezer.Freeze(locking.PlanFreeze{
+		Repo:    request.Repository,
+		Project: request.Project,
+		Dir:     request.Dir,
+		Message: request.Message,
+	})
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	a.Logger.Info("froze plans for repo: %q, project: %q, dir: %q by API request", freeze.Repo, freeze.Project, freeze.Dir)
+	response, err := json.Marshal(freeze)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// UnfreezePlans deletes the plan freeze with the scope of the request. Freezes
+// with a wider or narrower scope are kept.
+func (a *APIController) UnfreezePlans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	var request PlanFreezeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+		return
+	}
+	deleted, err := a.PlanFreezer.Unfreeze(request.Repository, request.Project, request.Dir)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if deleted {
+		a.Logger.Info("unfroze plans for repo: %q, project: %q, dir: %q by API request", request.Repository, request.Project, request.Dir)
+	}
+	response, err := json.Marshal(PlanUnfreezeResult{Deleted: deleted})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// ChangeSetRequest creates a change set from pull requests that are applied
+// in the order they're listed.
+type ChangeSetRequest struct {
+	Name         string
+	PullRequests []ChangeSetPullRequest
+}
+
+// ChangeSetPullRequest is a pull request of a ChangeSetRequest.
+type ChangeSetPullRequest struct {
+	Repository string
+	// Type is the VCS host of the repository, ex. Github.
+	Type string
+	PR   int
+}
+
+type ListChangeSetsResult struct {
+	ChangeSets []models.ChangeSet
+}
+
+type DeleteChangeSetResult struct {
+	Deleted bool
+}
+
+// ListChangeSets returns the change sets, oldest first.
+func (a *APIController) ListChangeSets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	changeSets, err := a.ChangeSets.List()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response, err := json.Marshal(ListChangeSetsResult{ChangeSets: changeSets})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// CreateChangeSet creates the change set of the request, replacing any change
+// set with the same name. Plans and applies of all projects of its pull
+// requests then run on all of them.
+func (a *APIController) CreateChangeSet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	var request ChangeSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+		return
+	}
+	changeSet := models.ChangeSet{Name: request.Name}
+	for _, pull := range request.PullRequests {
+		member := models.ChangeSetMember{Repository: pull.Repository, Type: pull.Type, PullNum: pull.PR}
+		if code, err := a.checkChangeSetMember(member); err != nil {
+			a.apiReportError(w, code, err)
+			return
+		}
+		changeSet.Members = append(changeSet.Members, member)
+	}
+	changeSet, err := a.ChangeSets.Save(changeSet)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	a.Logger.Info("created change set %q with %d pull requests by API request", changeSet.Name, len(changeSet.Members))
+	response, err := json.Marshal(changeSet)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// DeleteChangeSet deletes the change set named by the name query parameter.
+// Its pull requests are then planned and applied on their own again.
+func (a *APIController) DeleteChangeSet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("missing name query parameter"))
+		return
+	}
+	deleted, err := a.ChangeSets.Delete(name)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if deleted {
+		a.Logger.Info("deleted change set %q by API request", name)
+	}
+	response, err := json.Marshal(DeleteChangeSetResult{Deleted: deleted})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// ReplanRequest replans a project of a repository on all the open pull
+// requests that modify it. The project is selected by name or by directory
+// and, optionally, workspace.
+type ReplanRequest struct {
+	Repository string `validate:"required"`
+	Project    string
+	Directory  string
+	Workspace  string
+}
+
+type ReplanResult struct {
+	// PullRequests are the pull requests a plan was queued for, or skipped.
+	PullRequests []events.ReplannedPull
+}
+
+// Replan queues plans of a project on all the open pull requests that modify
+// it, ex. after a provider upgrade or a policy change. The plans run in the
+// background and are rate limited.
+func (a *APIController) Replan(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.ProjectReplanner == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since replanning is disabled"))
+		return
+	}
+	var request ReplanRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+		return
+	}
+	if err := validator.New().Struct(request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("request is missing the repository"))
+		return
+	}
+	if (request.Project == "") == (request.Directory == "") {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("request must set one of project or directory"))
+		return
+	}
+	if request.Project != "" && request.Workspace != "" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("workspace can't be set with project"))
+		return
+	}
+
+	pulls, err := a.ProjectReplanner.Replan(events.ReplanTarget{
+		Repository:  request.Repository,
+		ProjectName: request.Project,
+		RepoRelDir:  request.Directory,
+		Workspace:   request.Workspace,
+	})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response, err := json.Marshal(ReplanResult{PullRequests: pulls})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Info, http.StatusOK, "%s", string(response))
+}
+
+type GithubStatusMigrationResult struct {
+	Repository string
+	// Mode is the github_status_mode of the repo.
+	Mode string
+	// Checks are the checks required by the branch protection of the repo
+	// that reference Atlantis' statuses.
+	Checks []vcs.RequiredStatusCheck
+}
+
+// GithubStatusMigration reports the checks required by the branch protection
+// of a GitHub repo that reference Atlantis' statuses, so they can be updated
+// before the repo is switched from commit statuses to check runs.
+func (a *APIController) GithubStatusMigration(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticate(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.GithubStatusChecks == nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("ignoring request since GitHub is not configured"))
+		return
+	}
+	repository := r.URL.Query().Get("repository")
+	if repository == "" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("request is missing the repository query parameter"))
+		return
+	}
+	cloneURL, err := a.VCSClient.GetCloneURL(a.Logger, models.Github, repository)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	repo, err := a.Parser.ParseAPIPlanRequest(models.Github, repository, cloneURL)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+		return
+	}
+	if !a.RepoAllowlistChecker.IsAllowlisted(repo.FullName, repo.VCSHost.Hostname) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("repo %s not allowlisted", repository))
+		return
+	}
+
+	checks, err := a.GithubStatusChecks.ListRequiredStatusChecks(a.Logger, repo)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := GithubStatusMigrationResult{
+		Repository: repo.FullName,
+		Mode:       a.GlobalCfgStore.Load().GithubStatusMode(repo.ID()),
+		Checks:     []vcs.RequiredStatusCheck{},
+	}
+	for _, check := range checks {
+		if strings.HasPrefix(check.Context, a.StatusName+"/") {
+			result.Checks = append(result.Checks, check)
+		}
+	}
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// checkChangeSetMember returns an error if the repo of member isn't
+// allowlisted.
+func (a *APIController) checkChangeSetMember(member models.ChangeSetMember) (int, error) {
+	vcsHostType, err := models.NewVCSHostType(member.Type)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("pull request %s: %w", member, err)
+	}
+	cloneURL, err := a.VCSClient.GetCloneURL(a.Logger, vcsHostType, member.Repository)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	repo, err := a.Parser.ParseAPIPlanRequest(vcsHostType, member.Repository, cloneURL)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err)
+	}
+	if !a.RepoAllowlistChecker.IsAllowlisted(repo.FullName, repo.VCSHost.Hostname) {
+		return http.StatusForbidden, fmt.Errorf("repo %s not allowlisted", member.Repository)
+	}
+	return http.StatusOK, nil
+}
+
+func (a *APIController) respondVCSDebugLogging(w http.ResponseWriter) {
+	response, err := json.Marshal(VCSDebugLoggingResult{Enabled: a.VCSDebugLogging.Enabled()})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+func (a *APIController) apiAuthenticate(r *http.Request) (int, error) {
 	if len(a.APISecret) == 0 {
-		return nil, nil, http.StatusBadRequest, fmt.Errorf("ignoring request since API is disabled")
+		return http.StatusBadRequest, fmt.Errorf("ignoring request since API is disabled")
 	}
 
 	// Validate the secret token
 	secret := r.Header.Get(atlantisTokenHeader)
 	if secret != string(a.APISecret) {
-		return nil, nil, http.StatusUnauthorized, fmt.Errorf("header %s did not match expected secret", atlantisTokenHeader)
+		return http.StatusUnauthorized, fmt.Errorf("header %s did not match expected secret", atlantisTokenHeader)
+	}
+	return http.StatusOK, nil
+}
+
+func (a *APIController) apiParseAndValidate(r *http.Request) (*APIRequest, *command.Context, int, error) {
+	if code, err := a.apiAuthenticate(r); err != nil {
+		return nil, nil, code, err
 	}
 
 	// Parse the JSON payload
@@ -309,6 +1188,11 @@ func (a *APIController) apiParseAndValidate(r *http.Request) (*APIRequest, *comm
 	if err = validator.New().Struct(request); err != nil {
 		return nil, nil, http.StatusBadRequest, fmt.Errorf("request %q is missing fields", string(bytes))
 	}
+	for key, value := range request.Labels {
+		if !valid.LabelRegex.MatchString(key) || !valid.LabelRegex.MatchString(value) {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("invalid label %s=%s: keys and values must contain only letters, digits, dots, underscores and dashes", key, value)
+		}
+	}
 
 	VCSHostType, err := models.NewVCSHostType(request.Type)
 	if err != nil {
@@ -3,20 +3,28 @@ package controllers_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/controllers"
+	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/locking"
 	. "github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	. "github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
 	. "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/metrics"
 	. "github.com/runatlantis/atlantis/testing"
@@ -268,8 +276,8 @@ func setup(t *testing.T) (controllers.APIController, *MockProjectCommandBuilder,
 	Ok(t, err)
 
 	workingDirLocker := NewMockWorkingDirLocker()
-	When(workingDirLocker.TryLock(Any[string](), Any[int](), Eq(events.DefaultWorkspace), Eq(events.DefaultRepoRelDir))).
-		ThenReturn(func() {}, nil)
+	When(workingDirLocker.LockWorkspace(Any[string](), Any[int](), Eq(events.DefaultWorkspace))).
+		ThenReturn(func() {})
 
 	projectCommandBuilder := NewMockProjectCommandBuilder()
 	When(projectCommandBuilder.BuildPlanCommands(Any[*command.Context](), Any[*events.CommentCommand]())).
@@ -317,6 +325,597 @@ func setup(t *testing.T) (controllers.APIController, *MockProjectCommandBuilder,
 		WorkingDir:                     workingDir,
 		WorkingDirLocker:               workingDirLocker,
 		CommitStatusUpdater:            commitStatusUpdater,
+		VCSDebugLogging:                &vcs.DebugLogging{},
+		GlobalCfgStore:                 valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})),
 	}
 	return ac, projectCommandBuilder, projectCommandRunner
 }
+
+func TestAPIController_ListSBOMs(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.SBOMStore = &events.FileSBOMStore{Dir: t.TempDir()}
+	sbom := models.SBOM{
+		JobID:        "job-id",
+		RepoFullName: "owner/repo",
+		PullNum:      123,
+		AppliedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Tool:         models.SBOMTool{Name: "terraform", Version: "1.7.0"},
+	}
+	Ok(t, ac.SBOMStore.Save(sbom))
+
+	req, _ := http.NewRequest("GET", "/api/sboms?repository=owner/repo&pull=123", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.ListSBOMs(w, req)
+	response, _ := io.ReadAll(w.Result().Body)
+	var result controllers.ListSBOMsResult
+	err := json.Unmarshal(response, &result)
+	Ok(t, err)
+	Equals(t, controllers.ListSBOMsResult{SBOMs: []models.SBOM{sbom}}, result)
+}
+
+func TestAPIController_ListSBOMsErrors(t *testing.T) {
+	ac, _, _ := setup(t)
+
+	req, _ := http.NewRequest("GET", "/api/sboms?repository=owner/repo&pull=123", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.ListSBOMs(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "ignoring request since SBOMs are disabled")
+
+	ac.SBOMStore = &events.FileSBOMStore{Dir: t.TempDir()}
+	req, _ = http.NewRequest("GET", "/api/sboms?repository=owner/repo", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListSBOMs(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "invalid pull query parameter")
+}
+
+func TestAPIController_ListSBOMsUnauthorized(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.SBOMStore = &events.FileSBOMStore{Dir: t.TempDir()}
+
+	req, _ := http.NewRequest("GET", "/api/sboms?repository=owner/repo&pull=123", nil)
+	w := httptest.NewRecorder()
+	ac.ListSBOMs(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "did not match expected secret")
+}
+
+func TestAPIController_GetProvenance(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.ProvenanceStore = &events.FileProvenanceStore{Dir: t.TempDir()}
+
+	req, _ := http.NewRequest("GET", "/api/provenance?repository=owner/repo&pull=123", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.GetProvenance(w, req)
+	ResponseContains(t, w, http.StatusNotFound, "no provenance recorded for owner/repo#123")
+
+	provenance := models.Provenance{
+		RepoFullName: "owner/repo",
+		PullNum:      123,
+		HeadCommit:   "head",
+		MergeCommit:  "merge",
+		MergedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Applies:      []models.ApplyProvenance{{JobID: "job-id", PlanSHA256: "abc"}},
+	}
+	Ok(t, ac.ProvenanceStore.Save(provenance))
+
+	w = httptest.NewRecorder()
+	ac.GetProvenance(w, req)
+	response, _ := io.ReadAll(w.Result().Body)
+	var result models.Provenance
+	Ok(t, json.Unmarshal(response, &result))
+	Equals(t, provenance, result)
+}
+
+func TestAPIController_GetProvenanceUnauthorized(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.ProvenanceStore = &events.FileProvenanceStore{Dir: t.TempDir()}
+
+	req, _ := http.NewRequest("GET", "/api/provenance?repository=owner/repo&pull=123", nil)
+	w := httptest.NewRecorder()
+	ac.GetProvenance(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "did not match expected secret")
+}
+
+func TestAPIController_ListPlans(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.PlanJSONStore = &events.FilePlanJSONStore{Dir: t.TempDir()}
+	plan := models.PlanJSON{
+		RepoFullName: "owner/repo",
+		PullNum:      123,
+		RepoRelDir:   "dir",
+		Workspace:    "default",
+		ProjectName:  "project",
+		PlannedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Plan:         json.RawMessage(`{"format_version":"1.2"}`),
+	}
+	Ok(t, ac.PlanJSONStore.Save(plan))
+	Ok(t, ac.PlanJSONStore.Save(models.PlanJSON{
+		RepoFullName: "owner/repo",
+		PullNum:      123,
+		RepoRelDir:   "other",
+		Workspace:    "default",
+		Plan:         json.RawMessage(`{}`),
+	}))
+
+	req, _ := http.NewRequest("GET", "/api/plans?repository=owner/repo&pull=123&project=project", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.ListPlans(w, req)
+	response, _ := io.ReadAll(w.Result().Body)
+	var result controllers.ListPlansResult
+	err := json.Unmarshal(response, &result)
+	Ok(t, err)
+	Equals(t, controllers.ListPlansResult{Plans: []models.PlanJSON{plan}}, result)
+}
+
+func TestAPIController_ListPlansByLabel(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.PlanJSONStore = &events.FilePlanJSONStore{Dir: t.TempDir()}
+	plan := models.PlanJSON{
+		RepoFullName: "owner/repo",
+		PullNum:      123,
+		RepoRelDir:   "prod",
+		Workspace:    "default",
+		Labels:       map[string]string{"tier": "prod", "team": "platform"},
+		PlannedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Plan:         json.RawMessage(`{}`),
+	}
+	Ok(t, ac.PlanJSONStore.Save(plan))
+	Ok(t, ac.PlanJSONStore.Save(models.PlanJSON{
+		RepoFullName: "owner/repo",
+		PullNum:      123,
+		RepoRelDir:   "staging",
+		Workspace:    "default",
+		Labels:       map[string]string{"tier": "staging", "team": "platform"},
+		Plan:         json.RawMessage(`{}`),
+	}))
+
+	req, _ := http.NewRequest("GET", "/api/plans?repository=owner/repo&pull=123&label=team=platform&label=tier=prod", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.ListPlans(w, req)
+	response, _ := io.ReadAll(w.Result().Body)
+	var result controllers.ListPlansResult
+	Ok(t, json.Unmarshal(response, &result))
+	Equals(t, controllers.ListPlansResult{Plans: []models.PlanJSON{plan}}, result)
+
+	req, _ = http.NewRequest("GET", "/api/plans?repository=owner/repo&pull=123&label=tier", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListPlans(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "invalid label")
+}
+
+func TestAPIController_ListPlansUnauthorized(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.PlanJSONStore = &events.FilePlanJSONStore{Dir: t.TempDir()}
+
+	req, _ := http.NewRequest("GET", "/api/plans?repository=owner/repo&pull=123", nil)
+	w := httptest.NewRecorder()
+	ac.ListPlans(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "did not match expected secret")
+}
+
+func TestAPIController_VCSDebugLogging(t *testing.T) {
+	ac, _, _ := setup(t)
+
+	body, _ := json.Marshal(controllers.VCSDebugLoggingRequest{Enabled: true})
+	req, _ := http.NewRequest("PUT", "/api/vcs-debug-logging", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.SetVCSDebugLogging(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"Enabled":true}`)
+	Equals(t, true, ac.VCSDebugLogging.Enabled())
+
+	req, _ = http.NewRequest("GET", "/api/vcs-debug-logging", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.GetVCSDebugLogging(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"Enabled":true}`)
+}
+
+func TestAPIController_VCSDebugLoggingUnauthorized(t *testing.T) {
+	ac, _, _ := setup(t)
+
+	body, _ := json.Marshal(controllers.VCSDebugLoggingRequest{Enabled: true})
+	req, _ := http.NewRequest("PUT", "/api/vcs-debug-logging", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	ac.SetVCSDebugLogging(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "did not match expected secret")
+	Equals(t, false, ac.VCSDebugLogging.Enabled())
+}
+
+type fakeRepoConfigReloader struct {
+	err error
+}
+
+func (f fakeRepoConfigReloader) Reload() error {
+	return f.err
+}
+
+func TestAPIController_ReloadRepoConfig(t *testing.T) {
+	ac, _, _ := setup(t)
+
+	req, _ := http.NewRequest("POST", "/api/repo-config/reload", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.ReloadRepoConfig(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "reloading the repo config is disabled")
+
+	ac.RepoConfigReloader = fakeRepoConfigReloader{err: errors.New("repos.yaml is invalid")}
+	w = httptest.NewRecorder()
+	ac.ReloadRepoConfig(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "repos.yaml is invalid")
+
+	ac.RepoConfigReloader = fakeRepoConfigReloader{}
+	w = httptest.NewRecorder()
+	ac.ReloadRepoConfig(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"Reloaded":true}`)
+}
+
+type fakeWebhooks struct {
+	hooks []events_controllers.ReceivedWebhook
+}
+
+func (f fakeWebhooks) ListWebhooks(repo string) []events_controllers.ReceivedWebhook {
+	var hooks []events_controllers.ReceivedWebhook
+	for _, hook := range f.hooks {
+		if repo == "" || hook.Repo == repo {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+func (f fakeWebhooks) Replay(id string) (int, string, error) {
+	for _, hook := range f.hooks {
+		if hook.ID == id {
+			return hook.StatusCode, hook.Response, nil
+		}
+	}
+	return 0, "", fmt.Errorf("no webhook with id %q", id)
+}
+
+func TestAPIController_Webhooks(t *testing.T) {
+	ac, _, _ := setup(t)
+
+	req, _ := http.NewRequest("GET", "/api/webhooks", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.ListWebhooks(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "webhook history is disabled")
+
+	ac.Webhooks = fakeWebhooks{hooks: []events_controllers.ReceivedWebhook{
+		{ID: "00000002", Repo: "owner/repo", StatusCode: http.StatusOK, Response: "Processing..."},
+		{ID: "00000001", Repo: "owner/other"},
+	}}
+	req, _ = http.NewRequest("GET", "/api/webhooks?repository=owner/repo", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListWebhooks(w, req)
+	ResponseContains(t, w, http.StatusOK, `"ID":"00000002"`)
+	var result controllers.ListWebhooksResult
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &result))
+	Equals(t, 1, len(result.Webhooks))
+
+	body, _ := json.Marshal(controllers.ReplayWebhookRequest{ID: "00000002"})
+	req, _ = http.NewRequest("POST", "/api/webhooks/replay", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ReplayWebhook(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"StatusCode":200,"Response":"Processing..."}`)
+
+	req, _ = http.NewRequest("POST", "/api/webhooks/replay", bytes.NewBufferString(`{}`))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ReplayWebhook(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "request is missing the ID of the webhook to replay")
+}
+
+func TestAPIController_WebhooksUnauthorized(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.Webhooks = fakeWebhooks{}
+
+	req, _ := http.NewRequest("POST", "/api/webhooks/replay", bytes.NewBufferString(`{"ID": "00000001"}`))
+	w := httptest.NewRecorder()
+	ac.ReplayWebhook(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "did not match expected secret")
+}
+
+func TestAPIController_ListCommandLogs(t *testing.T) {
+	ac, _, _ := setup(t)
+
+	req, _ := http.NewRequest("GET", "/api/logs?repository=owner/repo&pull=1", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.ListCommandLogs(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "keeping command logs is disabled")
+
+	ac.LogStore = logging.NewLogStore(10)
+	logger, err := logging.NewStructuredLoggerWithLogStore(logging.Info, ac.LogStore)
+	Ok(t, err)
+	logger.WithHistory(logging.RepoKey, "owner/repo", logging.PullKey, "1", logging.CommandKey, "plan", logging.TraceIDKey, "trace-1").Info("running plan")
+
+	w = httptest.NewRecorder()
+	ac.ListCommandLogs(w, req)
+	ResponseContains(t, w, http.StatusOK, `"TraceID":"trace-1"`)
+	var result controllers.ListCommandLogsResult
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &result))
+	Equals(t, 1, len(result.Commands))
+	Equals(t, "running plan", result.Commands[0].Entries[0].Message)
+
+	req, _ = http.NewRequest("GET", "/api/logs?trace_id=trace-2", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListCommandLogs(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"Commands":null}`)
+
+	req, _ = http.NewRequest("GET", "/api/logs?repository=owner/repo", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListCommandLogs(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, `invalid pull query parameter: \"\"`)
+}
+
+func TestAPIController_SearchJobLogs(t *testing.T) {
+	ac, _, _ := setup(t)
+
+	req, _ := http.NewRequest("GET", "/api/jobs?repo=owner/repo&text=destroy", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.SearchJobLogs(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "keeping job output is disabled")
+
+	store, err := jobs.NewFileJobLogStore(t.TempDir(), 0)
+	Ok(t, err)
+	Ok(t, store.Save(jobs.JobLog{
+		JobID:    "job-1",
+		PullInfo: jobs.PullInfo{RepoFullName: "owner/repo", PullNum: 1, Path: "."},
+		JobStep:  "apply",
+		EndTime:  time.Now(),
+		Lines:    []string{"aws_s3_bucket.a: Destroying...", "Apply complete!"},
+	}))
+	ac.JobLogStore = store
+
+	w = httptest.NewRecorder()
+	ac.SearchJobLogs(w, req)
+	ResponseContains(t, w, http.StatusOK, `"JobID":"job-1"`)
+	var result controllers.SearchJobLogsResult
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &result))
+	Equals(t, 1, len(result.Jobs))
+	Equals(t, []string{"aws_s3_bucket.a: Destroying..."}, result.Jobs[0].Lines)
+
+	req, _ = http.NewRequest("GET", "/api/jobs?from=yesterday", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.SearchJobLogs(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, `invalid from query parameter: \"yesterday\" is neither a date nor an RFC 3339 time`)
+
+	req, _ = http.NewRequest("GET", "/api/jobs", nil)
+	w = httptest.NewRecorder()
+	ac.SearchJobLogs(w, req)
+	Equals(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestAPIController_UsageReport(t *testing.T) {
+	ac, _, _ := setup(t)
+
+	req, _ := http.NewRequest("GET", "/api/usage?group_by=repo", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.UsageReport(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "usage reports are disabled")
+
+	usageLog := &events.FileUsageLog{Path: filepath.Join(t.TempDir(), "usage.jsonl")}
+	Ok(t, usageLog.Record(events.UsageRecord{Time: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC), Repo: "owner/repo", Command: "plan", Result: events.UsageResultSuccess, Duration: time.Second}))
+	Ok(t, usageLog.Record(events.UsageRecord{Time: time.Date(2025, 2, 20, 0, 0, 0, 0, time.UTC), Repo: "owner/repo", Command: "apply", Result: events.UsageResultFailure}))
+	ac.UsageLog = usageLog
+
+	w = httptest.NewRecorder()
+	ac.UsageReport(w, req)
+	Equals(t, http.StatusOK, w.Code)
+	var result controllers.UsageReportResult
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &result))
+	Equals(t, []events.UsageReportRow{
+		{Repo: "owner/repo", Plans: 1, MeanPlanDurationSeconds: 1, Applies: 1, ApplyFailures: 1, ApplyFailureRate: 1},
+	}, result.Rows)
+
+	req, _ = http.NewRequest("GET", "/api/usage?group_by=repo&to=2025-02-15T00:00:00Z&format=csv", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.UsageReport(w, req)
+	Equals(t, "text/csv", w.Header().Get("Content-Type"))
+	ResponseContains(t, w, http.StatusOK, "repo,plans,plan_failures,plan_failure_rate,mean_plan_duration_seconds,applies,apply_failures,apply_failure_rate,policy_checks,policy_failures,policy_failure_rate\n"+
+		"owner/repo,1,0,0.000,1.000,0,0,0.000,0,0,0.000\n")
+
+	req, _ = http.NewRequest("GET", "/api/usage?group_by=user", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.UsageReport(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, `invalid group_by query parameter: invalid dimension \"user\"`)
+}
+
+func TestAPIController_PlanFreezes(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.PlanFreezer = locking.NewPlanFreezer(newTestRecordBackend(t))
+
+	body, _ := json.Marshal(controllers.PlanFreezeRequest{Repository: "owner/repo", Project: "infra", Message: "incident"})
+	req, _ := http.NewRequest("POST", "/api/plan-freezes", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.FreezePlans(w, req)
+	ResponseContains(t, w, http.StatusOK, `"Project":"infra"`)
+
+	body, _ = json.Marshal(controllers.PlanFreezeRequest{Project: "infra"})
+	req, _ = http.NewRequest("POST", "/api/plan-freezes", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.FreezePlans(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "project or dir requires repo")
+
+	req, _ = http.NewRequest("GET", "/api/plan-freezes", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListPlanFreezes(w, req)
+	ResponseContains(t, w, http.StatusOK, "")
+	var result controllers.ListPlanFreezesResult
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &result))
+	Equals(t, 1, len(result.Freezes))
+	Equals(t, "owner/repo", result.Freezes[0].Repo)
+	Equals(t, "incident", result.Freezes[0].Message)
+
+	body, _ = json.Marshal(controllers.PlanFreezeRequest{Repository: "owner/repo", Project: "infra"})
+	req, _ = http.NewRequest("DELETE", "/api/plan-freezes", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.UnfreezePlans(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"Deleted":true}`)
+
+	req, _ = http.NewRequest("DELETE", "/api/plan-freezes", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.UnfreezePlans(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"Deleted":false}`)
+}
+
+func TestAPIController_PlanFreezesUnauthorized(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.PlanFreezer = locking.NewPlanFreezer(newTestRecordBackend(t))
+
+	req, _ := http.NewRequest("POST", "/api/plan-freezes", bytes.NewBufferString("{}"))
+	w := httptest.NewRecorder()
+	ac.FreezePlans(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "did not match expected secret")
+	freezes, err := ac.PlanFreezer.List()
+	Ok(t, err)
+	Equals(t, 0, len(freezes))
+}
+
+func TestAPIController_ChangeSets(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.ChangeSets = events.NewChangeSetStore(newTestRecordBackend(t))
+
+	body, _ := json.Marshal(controllers.ChangeSetRequest{
+		Name: "network",
+		PullRequests: []controllers.ChangeSetPullRequest{
+			{Repository: "owner/network", Type: "Github", PR: 1},
+			{Repository: "owner/app", Type: "Github", PR: 2},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/api/changesets", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.CreateChangeSet(w, req)
+	ResponseContains(t, w, http.StatusOK, `"Name":"network"`)
+
+	body, _ = json.Marshal(controllers.ChangeSetRequest{
+		Name:         "single",
+		PullRequests: []controllers.ChangeSetPullRequest{{Repository: "owner/app", Type: "Github", PR: 3}},
+	})
+	req, _ = http.NewRequest("POST", "/api/changesets", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.CreateChangeSet(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "change set needs at least two pull requests")
+
+	req, _ = http.NewRequest("GET", "/api/changesets", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListChangeSets(w, req)
+	ResponseContains(t, w, http.StatusOK, "")
+	var result controllers.ListChangeSetsResult
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &result))
+	Equals(t, 1, len(result.ChangeSets))
+	Equals(t, "owner/app#2", result.ChangeSets[0].Members[1].String())
+
+	req, _ = http.NewRequest("DELETE", "/api/changesets?name=network", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.DeleteChangeSet(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"Deleted":true}`)
+}
+
+func TestAPIController_Replan(t *testing.T) {
+	ac, _, _ := setup(t)
+	backend := NewMockBackend()
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github}}
+	When(backend.ListPullStatuses()).ThenReturn([]models.PullStatus{
+		{Pull: models.PullRequest{Num: 1, URL: "url", BaseRepo: repo}, Projects: []models.ProjectStatus{{ProjectName: "infra"}}},
+	}, nil)
+	runner := NewMockCommandRunner()
+	ac.ProjectReplanner = &events.ProjectReplanner{
+		PullStatusLister: backend,
+		CommandRunner:    runner,
+		Logger:           logging.NewNoopLogger(t),
+	}
+
+	body, _ := json.Marshal(controllers.ReplanRequest{Repository: "owner/repo", Project: "infra"})
+	req, _ := http.NewRequest("POST", "/api/replan", bytes.NewBuffer(body))
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.Replan(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"PullRequests":[{"Num":1,"URL":"url"}]}`)
+	runner.VerifyWasCalledEventually(Once(), time.Second).RunCommentCommand(
+		Eq(repo), Any[*models.Repo](), Any[*models.PullRequest](), Any[models.User](), Eq(1), Any[*events.CommentCommand]())
+
+	for _, request := range []controllers.ReplanRequest{
+		{Repository: "owner/repo"},
+		{Repository: "owner/repo", Project: "infra", Directory: "infra"},
+	} {
+		body, _ = json.Marshal(request)
+		req, _ = http.NewRequest("POST", "/api/replan", bytes.NewBuffer(body))
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w = httptest.NewRecorder()
+		ac.Replan(w, req)
+		ResponseContains(t, w, http.StatusBadRequest, "request must set one of project or directory")
+	}
+
+	req, _ = http.NewRequest("POST", "/api/replan", bytes.NewBufferString("{}"))
+	w = httptest.NewRecorder()
+	ac.Replan(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "did not match expected secret")
+}
+
+type fakeStatusChecksLister struct {
+	checks []vcs.RequiredStatusCheck
+}
+
+func (f fakeStatusChecksLister) ListRequiredStatusChecks(_ logging.SimpleLogging, _ models.Repo) ([]vcs.RequiredStatusCheck, error) {
+	return f.checks, nil
+}
+
+func TestAPIController_GithubStatusMigration(t *testing.T) {
+	ac, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "/api/github/status-migration?repository=owner/repo", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.GithubStatusMigration(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "GitHub is not configured")
+
+	parser := NewMockEventParsing()
+	When(parser.ParseAPIPlanRequest(Eq(models.Github), Eq("owner/repo"), Any[string]())).
+		ThenReturn(models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo", VCSHost: models.VCSHost{Hostname: "github.com", Type: models.Github}}, nil)
+	ac.Parser = parser
+	ac.StatusName = "atlantis"
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	globalCfg.Repos[0].GithubStatusMode = valid.GithubStatusModeBoth
+	ac.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
+	ac.GithubStatusChecks = fakeStatusChecksLister{checks: []vcs.RequiredStatusCheck{
+		{Branch: "main", Context: "atlantis/plan"},
+		{Branch: "main", Context: "ci/build"},
+	}}
+
+	w = httptest.NewRecorder()
+	ac.GithubStatusMigration(w, req)
+	ResponseContains(t, w, http.StatusOK, "")
+	var result controllers.GithubStatusMigrationResult
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &result))
+	Equals(t, controllers.GithubStatusMigrationResult{
+		Repository: "owner/repo",
+		Mode:       valid.GithubStatusModeBoth,
+		Checks:     []vcs.RequiredStatusCheck{{Branch: "main", Context: "atlantis/plan"}},
+	}, result)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
@@ -67,6 +68,12 @@ type VCSEventsController struct {
 	ApplyDisabled  bool
 	EmojiReaction  string
 	ExecutableName string
+	// CommentReactions are the reactions added to the comment of a command
+	// once it's received. EmojiReaction is used if they have none.
+	CommentReactions events.CommentReactions
+	// IssueOpsCommands are the commands that can be run from comments on
+	// GitHub issues. If empty, comments on issues are ignored.
+	IssueOpsCommands []command.Name
 	// GithubWebhookSecret is the secret added to this webhook via the GitHub
 	// UI that identifies this call as coming from GitHub. If empty, no
 	// request validation is done.
@@ -101,10 +108,26 @@ type VCSEventsController struct {
 	AzureDevopsWebhookBasicPassword []byte
 	AzureDevopsRequestValidator     AzureDevopsRequestValidator `validate:"required"`
 	GiteaWebhookSecret              []byte
+	// WebhookHistory is nil unless received webhooks are kept so they can be
+	// inspected and replayed.
+	WebhookHistory *WebhookHistory
+	// ClosedPullDestroyer destroys the preview environments and workspaces
+	// of closed pull requests before they're cleaned up. If nil, they're
+	// cleaned up right away.
+	ClosedPullDestroyer events.ClosedPullDestroyer
 }
 
 // Post handles POST webhook requests.
 func (e *VCSEventsController) Post(w http.ResponseWriter, r *http.Request) {
+	// Replays aren't recorded again.
+	if e.WebhookHistory != nil && !isReplay(r) {
+		e.WebhookHistory.record(w, r, e.post)
+		return
+	}
+	e.post(w, r)
+}
+
+func (e *VCSEventsController) post(w http.ResponseWriter, r *http.Request) {
 	if r.Header.Get(giteaHeader) != "" {
 		if !e.supportsHost(models.Gitea) {
 			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
@@ -174,7 +197,7 @@ type HTTPResponse struct {
 
 func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Request) {
 	// Validate the request against the optional webhook secret.
-	payload, err := e.GithubRequestValidator.Validate(r, e.GithubWebhookSecret)
+	payload, err := e.GithubRequestValidator.Validate(r, webhookSecret(r, e.GithubWebhookSecret))
 	if err != nil {
 		e.respond(w, logging.Warn, http.StatusBadRequest, "%s", err.Error())
 		return
@@ -230,8 +253,8 @@ func (e *VCSEventsController) handleBitbucketCloudPost(w http.ResponseWriter, r
 		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
 		return
 	}
-	if len(e.BitbucketWebhookSecret) > 0 {
-		if err := bitbucketcloud.ValidateSignature(body, sig, e.BitbucketWebhookSecret); err != nil {
+	if secret := webhookSecret(r, e.BitbucketWebhookSecret); len(secret) > 0 {
+		if err := bitbucketcloud.ValidateSignature(body, sig, secret); err != nil {
 			e.respond(w, logging.Warn, http.StatusBadRequest, "%s", errors.Wrap(err, "request did not pass validation").Error())
 			return
 		}
@@ -266,8 +289,8 @@ func (e *VCSEventsController) handleBitbucketServerPost(w http.ResponseWriter, r
 		e.respond(w, logging.Info, http.StatusOK, "Successfully received %s event %s=%s", eventType, bitbucketServerRequestIDHeader, reqID)
 		return
 	}
-	if len(e.BitbucketWebhookSecret) > 0 {
-		if err := bitbucketserver.ValidateSignature(body, sig, e.BitbucketWebhookSecret); err != nil {
+	if secret := webhookSecret(r, e.BitbucketWebhookSecret); len(secret) > 0 {
+		if err := bitbucketserver.ValidateSignature(body, sig, secret); err != nil {
 			e.respond(w, logging.Warn, http.StatusBadRequest, "%s", errors.Wrap(err, "request did not pass validation").Error())
 			return
 		}
@@ -288,7 +311,7 @@ func (e *VCSEventsController) handleBitbucketServerPost(w http.ResponseWriter, r
 
 func (e *VCSEventsController) handleAzureDevopsPost(w http.ResponseWriter, r *http.Request) {
 	// Validate the request against the optional basic auth username and password.
-	payload, err := e.AzureDevopsRequestValidator.Validate(r, e.AzureDevopsWebhookBasicUser, e.AzureDevopsWebhookBasicPassword)
+	payload, err := e.AzureDevopsRequestValidator.Validate(r, webhookSecret(r, e.AzureDevopsWebhookBasicUser), webhookSecret(r, e.AzureDevopsWebhookBasicPassword))
 	if err != nil {
 		e.respond(w, logging.Warn, http.StatusUnauthorized, "%s", err.Error())
 		return
@@ -326,8 +349,8 @@ func (e *VCSEventsController) handleGiteaPost(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if len(e.GiteaWebhookSecret) > 0 {
-		if err := gitea.ValidateSignature(body, signature, e.GiteaWebhookSecret); err != nil {
+	if secret := webhookSecret(r, e.GiteaWebhookSecret); len(secret) > 0 {
+		if err := gitea.ValidateSignature(body, signature, secret); err != nil {
 			e.respond(w, logging.Warn, http.StatusBadRequest, "%s", errors.Wrap(err, "request did not pass validation").Error())
 			return
 		}
@@ -427,6 +450,12 @@ func (e *VCSEventsController) HandleGithubCommentEvent(event *github.IssueCommen
 
 	comment := event.GetComment()
 
+	// Comments on issues are only handled differently when issue-ops mode is
+	// enabled. Otherwise they're processed as pull request comments as before.
+	if len(e.IssueOpsCommands) > 0 && !event.GetIssue().IsPullRequest() {
+		return e.handleIssueCommentEvent(logger, baseRepo, event.GetRepo().GetDefaultBranch(), user, pullNum, comment.GetBody(), comment.GetID(), models.Github)
+	}
+
 	// We pass in nil for maybeHeadRepo because the head repo data isn't
 	// available in the GithubIssueComment event.
 	return e.handleCommentEvent(logger, baseRepo, nil, nil, user, pullNum, comment.GetBody(), comment.GetID(), models.Github)
@@ -599,6 +628,26 @@ func (e *VCSEventsController) handlePullRequestEvent(logger logging.SimpleLoggin
 			body: "Processing...",
 		}
 	case models.ClosedPullEvent:
+		if e.ClosedPullDestroyer != nil && e.ClosedPullDestroyer.HasWorkspacesToDestroy(pull) {
+			// Destroying takes longer than the VCS host waits for a response
+			// so the pull request is cleaned up asynchronously once its
+			// workspaces are destroyed.
+			logger.Info("Pull request closed, destroying workspaces...")
+			destroyAndCleanUp := func() {
+				e.ClosedPullDestroyer.DestroyWorkspaces(baseRepo, headRepo, pull, user)
+				if err := e.PullCleaner.CleanUpPull(logger, baseRepo, pull); err != nil {
+					logger.Err("cleaning up pull request: %s", err)
+				}
+			}
+			if !e.TestingMode {
+				go destroyAndCleanUp()
+			} else {
+				destroyAndCleanUp()
+			}
+			return HTTPResponse{
+				body: "Destroying workspaces...",
+			}
+		}
 		// If the pull request was closed, we delete locks.
 		logger.Info("Pull request closed, cleaning up...")
 		if err := e.PullCleaner.CleanUpPull(logger, baseRepo, pull); err != nil {
@@ -625,7 +674,7 @@ func (e *VCSEventsController) handlePullRequestEvent(logger logging.SimpleLoggin
 }
 
 func (e *VCSEventsController) handleGitlabPost(w http.ResponseWriter, r *http.Request) {
-	event, err := e.GitlabRequestParserValidator.ParseAndValidate(r, e.GitlabWebhookSecret)
+	event, err := e.GitlabRequestParserValidator.ParseAndValidate(r, webhookSecret(r, e.GitlabWebhookSecret))
 	if err != nil {
 		e.respond(w, logging.Warn, http.StatusBadRequest, "%s", err.Error())
 		return
@@ -710,8 +759,8 @@ func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, b
 	}
 
 	// It's a comment we're going to react to so add a reaction.
-	if e.EmojiReaction != "" {
-		err := e.VCSClient.ReactToComment(logger, baseRepo, pullNum, commentID, e.EmojiReaction)
+	if reaction := e.queuedReaction(parseResult.Command); reaction != "" {
+		err := e.VCSClient.ReactToComment(logger, baseRepo, pullNum, commentID, reaction)
 		if err != nil {
 			logger.Warn("Failed to react to comment: %s", err)
 		}
@@ -735,6 +784,7 @@ func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, b
 	} else {
 		logger.Info("Running comment command '%v' for user '%v'.", parseResult.Command.Name, user.Username)
 	}
+	parseResult.Command.CommentID = commentID
 	if !e.TestingMode {
 		// Respond with success and then actually execute the command asynchronously.
 		// We use a goroutine so that this function returns and the connection is
@@ -750,6 +800,99 @@ func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, b
 	}
 }
 
+// handleIssueCommentEvent handles comments on issues (not pull requests) when
+// issue-ops mode is enabled. Only the configured issue-ops commands are
+// allowed and they must target a specific project.
+func (e *VCSEventsController) handleIssueCommentEvent(logger logging.SimpleLogging, baseRepo models.Repo, defaultBranch string, user models.User, issueNum int, comment string, commentID int64, vcsHost models.VCSHostType) HTTPResponse {
+	logger = logger.WithHistory(
+		"repo", baseRepo.FullName,
+		"issue", issueNum,
+	)
+
+	parseResult := e.CommentParser.Parse(comment, vcsHost)
+	if parseResult.Ignore {
+		return HTTPResponse{
+			body: "Ignoring non-command comment on issue",
+		}
+	}
+
+	if !e.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
+		e.commentNotAllowlisted(baseRepo, issueNum)
+
+		err := errors.New("Repo not allowlisted")
+
+		return HTTPResponse{
+			body: err.Error(),
+			err: HTTPError{
+				err:        err,
+				code:       http.StatusForbidden,
+				isSilenced: e.SilenceAllowlistErrors,
+			},
+		}
+	}
+
+	if reaction := e.queuedReaction(parseResult.Command); reaction != "" {
+		err := e.VCSClient.ReactToComment(logger, baseRepo, issueNum, commentID, reaction)
+		if err != nil {
+			logger.Warn("Failed to react to comment: %s", err)
+		}
+	}
+
+	response := parseResult.CommentResponse
+	if response == "" {
+		response = e.validateIssueOpsCommand(parseResult.Command)
+	}
+	if response != "" {
+		if err := e.VCSClient.CreateComment(logger, baseRepo, issueNum, response, ""); err != nil {
+			logger.Err("Unable to comment on issue: %s", err)
+		}
+		return HTTPResponse{
+			body: "Commenting back on issue",
+		}
+	}
+
+	logger.Info("Running issue-ops command '%v' for user '%v'.", parseResult.Command.Name, user.Username)
+	parseResult.Command.CommentID = commentID
+	if !e.TestingMode {
+		go e.CommandRunner.RunIssueCommentCommand(baseRepo, defaultBranch, user, issueNum, parseResult.Command)
+	} else {
+		e.CommandRunner.RunIssueCommentCommand(baseRepo, defaultBranch, user, issueNum, parseResult.Command)
+	}
+
+	return HTTPResponse{
+		body: "Processing...",
+	}
+}
+
+// queuedReaction returns the reaction added to a received comment of cmd,
+// which is nil if the comment doesn't run a command, ex. atlantis help.
+func (e *VCSEventsController) queuedReaction(cmd *events.CommentCommand) string {
+	if reaction := e.CommentReactions.Reaction(cmd, events.ReactionQueued); reaction != "" {
+		return reaction
+	}
+	return e.EmojiReaction
+}
+
+// validateIssueOpsCommand returns a comment explaining why cmd can't be run
+// from an issue, or an empty string if it can.
+func (e *VCSEventsController) validateIssueOpsCommand(cmd *events.CommentCommand) string {
+	var allowed []string
+	isAllowed := false
+	for _, name := range e.IssueOpsCommands {
+		allowed = append(allowed, name.String())
+		if name == cmd.Name {
+			isAllowed = true
+		}
+	}
+	if !isAllowed {
+		return fmt.Sprintf("```\nError: command %q can't be run from an issue.\nAvailable issue-ops commands: %s\n```", cmd.Name.String(), strings.Join(allowed, ", "))
+	}
+	if cmd.ProjectName == "" && cmd.RepoRelDir == "" {
+		return fmt.Sprintf("```\nError: issue-ops commands must select a project with -p or -d, ex. '%s %s -p myproject'.\n```", e.ExecutableName, cmd.Name.String())
+	}
+	return ""
+}
+
 // HandleGitlabMergeRequestEvent will delete any locks associated with the pull
 // request if the event is a merge request closed event. It's exported to make
 // testing easier.
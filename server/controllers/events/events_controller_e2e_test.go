@@ -1389,6 +1389,8 @@ func setupE2E(t *testing.T, repoDir string, opt setupOption) (events_controllers
 		globalCfg, err = parser.ParseGlobalCfg(expCfgPath, globalCfg)
 		Ok(t, err)
 	}
+	globalCfgStore := valid.NewGlobalCfgStore(globalCfg)
+	e2eStatusUpdater.GlobalCfgStore = globalCfgStore
 	drainer := &events.Drainer{}
 
 	parallelPoolSize := 1
@@ -1404,7 +1406,7 @@ func setupE2E(t *testing.T, repoDir string, opt setupOption) (events_controllers
 	preWorkflowHookURLGenerator := mocks.NewMockPreWorkflowHookURLGenerator()
 	preWorkflowHooksCommandRunner := &events.DefaultPreWorkflowHooksCommandRunner{
 		VCSClient:             e2eVCSClient,
-		GlobalCfg:             globalCfg,
+		GlobalCfgStore:        globalCfgStore,
 		WorkingDirLocker:      locker,
 		WorkingDir:            workingDir,
 		PreWorkflowHookRunner: mockPreWorkflowHookRunner,
@@ -1416,7 +1418,7 @@ func setupE2E(t *testing.T, repoDir string, opt setupOption) (events_controllers
 	postWorkflowHookURLGenerator := mocks.NewMockPostWorkflowHookURLGenerator()
 	postWorkflowHooksCommandRunner := &events.DefaultPostWorkflowHooksCommandRunner{
 		VCSClient:              e2eVCSClient,
-		GlobalCfg:              globalCfg,
+		GlobalCfgStore:         globalCfgStore,
 		WorkingDirLocker:       locker,
 		WorkingDir:             workingDir,
 		PostWorkflowHookRunner: mockPostWorkflowHookRunner,
@@ -1432,7 +1434,7 @@ func setupE2E(t *testing.T, repoDir string, opt setupOption) (events_controllers
 		e2eVCSClient,
 		workingDir,
 		locker,
-		globalCfg,
+		globalCfgStore,
 		&events.DefaultPendingPlanFinder{},
 		commentParser,
 		false,
@@ -1501,7 +1503,8 @@ func setupE2E(t *testing.T, repoDir string, opt setupOption) (events_controllers
 		Webhooks:         &mockWebhookSender{},
 		WorkingDirLocker: locker,
 		CommandRequirementHandler: &events.DefaultCommandRequirementHandler{
-			WorkingDir: workingDir,
+			WorkingDir:     workingDir,
+			GlobalCfgStore: globalCfgStore,
 		},
 	}
 
@@ -1512,6 +1515,7 @@ func setupE2E(t *testing.T, repoDir string, opt setupOption) (events_controllers
 	pullUpdater := &events.PullUpdater{
 		HidePrevPlanComments: false,
 		VCSClient:            e2eVCSClient,
+		GlobalCfgStore:       globalCfgStore,
 		MarkdownRenderer: events.NewMarkdownRenderer(
 			false,                            // gitlabSupportsCommonMark
 			false,                            // disableApplyAll
@@ -1563,6 +1567,7 @@ func setupE2E(t *testing.T, repoDir string, opt setupOption) (events_controllers
 		discardApprovalOnPlan,
 		e2ePullReqStatusFetcher,
 	)
+	planCommandRunner.GlobalCfgStore = globalCfgStore
 
 	applyCommandRunner := events.NewApplyCommandRunner(
 		e2eVCSClient,
@@ -1637,7 +1642,7 @@ func setupE2E(t *testing.T, repoDir string, opt setupOption) (events_controllers
 		GithubPullGetter:               e2eGithubGetter,
 		GitlabMergeRequestGetter:       e2eGitlabGetter,
 		Logger:                         logger,
-		GlobalCfg:                      globalCfg,
+		GlobalCfgStore:                 globalCfgStore,
 		StatsScope:                     statsScope,
 		AllowForkPRs:                   allowForkPRs,
 		AllowForkPRsFlag:               "allow-fork-prs",
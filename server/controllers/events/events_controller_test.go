@@ -425,6 +425,69 @@ func TestPost_GithubCommentSuccess(t *testing.T) {
 	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
 }
 
+func TestPost_GithubIssueCommentIssueOps(t *testing.T) {
+	t.Log("when issue-ops is enabled and the comment is on an issue we run the issue command against the default branch")
+	e, v, _, _, p, cr, _, _, cp := setup(t)
+	e.IssueOpsCommands = []command.Name{command.Plan}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(githubHeader, "issue_comment")
+	event := `{"action": "created", "issue": {"number": 2}, "repository": {"default_branch": "main"}}`
+	When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+	baseRepo := models.Repo{}
+	user := models.User{}
+	cmd := events.CommentCommand{Name: command.Plan, ProjectName: "staging"}
+	When(p.ParseGithubIssueCommentEvent(Any[logging.SimpleLogging](), Any[*github.IssueCommentEvent]())).ThenReturn(baseRepo, user, 2, nil)
+	When(cp.Parse("", models.Github)).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Processing...")
+
+	cr.VerifyWasCalledOnce().RunIssueCommentCommand(baseRepo, "main", user, 2, &cmd)
+	cr.VerifyWasCalled(Never()).RunCommentCommand(Any[models.Repo](), Any[*models.Repo](), Any[*models.PullRequest](), Any[models.User](), Any[int](), Any[*events.CommentCommand]())
+}
+
+func TestPost_GithubIssueCommentIssueOpsRequiresProject(t *testing.T) {
+	t.Log("when an issue-ops command doesn't select a project we comment back with an error")
+	e, v, _, _, p, cr, _, vcsClient, cp := setup(t)
+	e.IssueOpsCommands = []command.Name{command.Plan}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(githubHeader, "issue_comment")
+	event := `{"action": "created", "issue": {"number": 2}, "repository": {"default_branch": "main"}}`
+	When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+	baseRepo := models.Repo{}
+	cmd := events.CommentCommand{Name: command.Plan}
+	When(p.ParseGithubIssueCommentEvent(Any[logging.SimpleLogging](), Any[*github.IssueCommentEvent]())).ThenReturn(baseRepo, models.User{}, 2, nil)
+	When(cp.Parse("", models.Github)).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Commenting back on issue")
+
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(baseRepo), Eq(2),
+		Eq("```\nError: issue-ops commands must select a project with -p or -d, ex. 'atlantis plan -p myproject'.\n```"), Eq(""))
+	cr.VerifyWasCalled(Never()).RunIssueCommentCommand(Any[models.Repo](), Any[string](), Any[models.User](), Any[int](), Any[*events.CommentCommand]())
+}
+
+func TestPost_GithubIssueCommentIssueOpsCommandNotAllowed(t *testing.T) {
+	t.Log("when a command isn't enabled for issue-ops we comment back with an error")
+	e, v, _, _, p, cr, _, vcsClient, cp := setup(t)
+	e.IssueOpsCommands = []command.Name{command.Plan, command.Version}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(githubHeader, "issue_comment")
+	event := `{"action": "created", "issue": {"number": 2}}`
+	When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+	baseRepo := models.Repo{}
+	cmd := events.CommentCommand{Name: command.Apply, ProjectName: "staging"}
+	When(p.ParseGithubIssueCommentEvent(Any[logging.SimpleLogging](), Any[*github.IssueCommentEvent]())).ThenReturn(baseRepo, models.User{}, 2, nil)
+	When(cp.Parse("", models.Github)).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Commenting back on issue")
+
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(baseRepo), Eq(2),
+		Eq("```\nError: command \"apply\" can't be run from an issue.\nAvailable issue-ops commands: plan, version\n```"), Eq(""))
+	cr.VerifyWasCalled(Never()).RunIssueCommentCommand(Any[models.Repo](), Any[string](), Any[models.User](), Any[int](), Any[*events.CommentCommand]())
+}
+
 func TestPost_GithubCommentReaction(t *testing.T) {
 	t.Log("when the event is a github comment with a valid command we call the ReactToComment handler")
 	e, v, _, _, p, _, _, vcsClient, cp := setup(t)
@@ -445,6 +508,29 @@ func TestPost_GithubCommentReaction(t *testing.T) {
 	vcsClient.VerifyWasCalledOnce().ReactToComment(Any[logging.SimpleLogging](), Eq(baseRepo), Eq(1), Eq(int64(1)), Eq("eyes"))
 }
 
+func TestPost_GithubCommentReactionForCommand(t *testing.T) {
+	t.Log("when a queued reaction is configured for the command it's used instead of the emoji reaction")
+	e, v, _, _, p, _, _, vcsClient, cp := setup(t)
+	reactions, err := events.ParseCommentReactions("apply.queued:hourglass")
+	Ok(t, err)
+	e.CommentReactions = reactions
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(githubHeader, "issue_comment")
+	testComment := "atlantis apply"
+	event := fmt.Sprintf(`{"action": "created", "comment": {"body": "%v", "id": 1}}`, testComment)
+	When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+	baseRepo := models.Repo{}
+	user := models.User{}
+	cmd := events.CommentCommand{Name: command.Apply}
+	When(p.ParseGithubIssueCommentEvent(Any[logging.SimpleLogging](), Any[*github.IssueCommentEvent]())).ThenReturn(baseRepo, user, 1, nil)
+	When(cp.Parse(testComment, models.Github)).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Processing...")
+
+	vcsClient.VerifyWasCalledOnce().ReactToComment(Any[logging.SimpleLogging](), Eq(baseRepo), Eq(1), Eq(int64(1)), Eq("hourglass"))
+}
+
 func TestPost_GilabCommentReaction(t *testing.T) {
 	t.Log("when the event is a gitlab comment with a valid command we call the ReactToComment handler")
 	e, _, gl, _, _, _, _, vcsClient, cp := setup(t)
@@ -921,6 +1007,7 @@ func TestPost_BBServerPullClosed(t *testing.T) {
 					HeadBranch: "decline-me",
 					BaseBranch: "main",
 					Author:     "admin",
+					Title:      "Commit message",
 					State:      models.OpenPullState,
 					BaseRepo:   expRepo,
 				})
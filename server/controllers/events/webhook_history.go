@@ -0,0 +1,293 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedHeaders carry webhook secrets or signatures derived from them.
+var redactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"X-Gitlab-Token",
+	"X-Hub-Signature",
+	"X-Hub-Signature-256",
+	giteaSignatureHeader,
+	"X-Gogs-Signature",
+}
+
+const redactedValue = "<redacted>"
+
+// maxRecordedResponse is the maximum number of bytes of the response to a
+// webhook that are recorded.
+const maxRecordedResponse = 4096
+
+// ReceivedWebhook is a webhook request Atlantis received and its response.
+// Headers that carry secrets are redacted.
+type ReceivedWebhook struct {
+	ID         string
+	ReceivedAt time.Time
+	// Repo is the full name of the repo the webhook is for, or empty if it
+	// couldn't be determined from the payload.
+	Repo       string
+	Headers    map[string]string
+	Body       string
+	StatusCode int
+	Response   string
+}
+
+// WebhookHistory keeps the last webhooks received for each repo so they can
+// be inspected and replayed.
+type WebhookHistory struct {
+	// Size is the number of webhooks kept for each repo.
+	Size int
+
+	mu     sync.Mutex
+	nextID int
+	byRepo map[string][]ReceivedWebhook
+}
+
+// NewWebhookHistory returns a history that keeps the last size webhooks of
+// each repo.
+func NewWebhookHistory(size int) *WebhookHistory {
+	return &WebhookHistory{Size: size, byRepo: make(map[string][]ReceivedWebhook)}
+}
+
+// List returns the webhooks received for repo, newest first. If repo is
+// empty, the webhooks of all repos are returned.
+func (h *WebhookHistory) List(repo string) []ReceivedWebhook {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var hooks []ReceivedWebhook
+	for r, received := range h.byRepo {
+		if repo == "" || r == repo {
+			hooks = append(hooks, received...)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		if !hooks[i].ReceivedAt.Equal(hooks[j].ReceivedAt) {
+			return hooks[i].ReceivedAt.After(hooks[j].ReceivedAt)
+		}
+		return hooks[i].ID > hooks[j].ID
+	})
+	return hooks
+}
+
+// Get returns the webhook with id.
+func (h *WebhookHistory) Get(id string) (ReceivedWebhook, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, received := range h.byRepo {
+		for _, hook := range received {
+			if hook.ID == id {
+				return hook, true
+			}
+		}
+	}
+	return ReceivedWebhook{}, false
+}
+
+func (h *WebhookHistory) add(hook ReceivedWebhook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	// Pad the ID so IDs sort in the order they were received.
+	hook.ID = fmt.Sprintf("%08d", h.nextID)
+	received := append(h.byRepo[hook.Repo], hook)
+	if len(received) > h.Size {
+		received = received[len(received)-h.Size:]
+	}
+	h.byRepo[hook.Repo] = received
+}
+
+// record handles r with handle and adds it and its response to the history.
+func (h *WebhookHistory) record(w http.ResponseWriter, r *http.Request, handle http.HandlerFunc) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Unable to read body: %s", err), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorder := &responseRecorder{w: w}
+	handle(recorder, r)
+
+	headers := make(map[string]string)
+	for name := range r.Header {
+		headers[name] = r.Header.Get(name)
+	}
+	for _, name := range redactedHeaders {
+		if _, ok := headers[http.CanonicalHeaderKey(name)]; ok {
+			headers[http.CanonicalHeaderKey(name)] = redactedValue
+		}
+	}
+	h.add(ReceivedWebhook{
+		ReceivedAt: time.Now(),
+		Repo:       webhookRepo(body),
+		Headers:    headers,
+		Body:       string(body),
+		StatusCode: recorder.statusCode(),
+		Response:   strings.TrimSpace(recorder.body.String()),
+	})
+}
+
+// Replay sends the webhook with id to the controller again and returns the
+// status code and body of the response. The webhook's signature isn't checked
+// since it was redacted, and was checked when the webhook was received.
+func (e *VCSEventsController) Replay(id string) (int, string, error) {
+	if e.WebhookHistory == nil {
+		return 0, "", fmt.Errorf("webhook history is disabled")
+	}
+	hook, ok := e.WebhookHistory.Get(id)
+	if !ok {
+		return 0, "", fmt.Errorf("no webhook with id %q", id)
+	}
+	ctx := context.WithValue(context.Background(), replayContextKey{}, true)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/events", strings.NewReader(hook.Body))
+	if err != nil {
+		return 0, "", err
+	}
+	for name, value := range hook.Headers {
+		if value != redactedValue {
+			r.Header.Set(name, value)
+		}
+	}
+	e.Logger.Info("replaying webhook %s for repo %q", id, hook.Repo)
+	recorder := &responseRecorder{}
+	e.Post(recorder, r)
+	return recorder.statusCode(), strings.TrimSpace(recorder.body.String()), nil
+}
+
+// replayContextKey marks a request as the replay of a stored webhook.
+type replayContextKey struct{}
+
+func isReplay(r *http.Request) bool {
+	replay, _ := r.Context().Value(replayContextKey{}).(bool)
+	return replay
+}
+
+// webhookSecret returns secret, or nil if r is a replay so its redacted
+// signature isn't checked.
+func webhookSecret(r *http.Request, secret []byte) []byte {
+	if isReplay(r) {
+		return nil
+	}
+	return secret
+}
+
+// webhookRepo returns the full name of the repo of a webhook payload from
+// any of the supported VCS hosts, or an empty string.
+func webhookRepo(body []byte) string {
+	var payload struct {
+		// GitHub, Gitea and Bitbucket Cloud.
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		// GitLab.
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+		// Bitbucket Server.
+		PullRequest struct {
+			ToRef struct {
+				Repository struct {
+					Slug    string `json:"slug"`
+					Project struct {
+						Key string `json:"key"`
+					} `json:"project"`
+				} `json:"repository"`
+			} `json:"toRef"`
+		} `json:"pullRequest"`
+		// Azure DevOps.
+		Resource struct {
+			Repository struct {
+				Name    string `json:"name"`
+				Project struct {
+					Name string `json:"name"`
+				} `json:"project"`
+			} `json:"repository"`
+		} `json:"resource"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	switch {
+	case payload.Repository.FullName != "":
+		return payload.Repository.FullName
+	case payload.Project.PathWithNamespace != "":
+		return payload.Project.PathWithNamespace
+	case payload.PullRequest.ToRef.Repository.Slug != "":
+		return payload.PullRequest.ToRef.Repository.Project.Key + "/" + payload.PullRequest.ToRef.Repository.Slug
+	case payload.Resource.Repository.Name != "":
+		return payload.Resource.Repository.Project.Name + "/" + payload.Resource.Repository.Name
+	}
+	return ""
+}
+
+// responseRecorder records the status code and the start of the body of a
+// response. If w is set, the response is also written to it.
+type responseRecorder struct {
+	w      http.ResponseWriter
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	if r.w != nil {
+		return r.w.Header()
+	}
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	if r.w != nil {
+		r.w.WriteHeader(code)
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	if remaining := maxRecordedResponse - r.body.Len(); remaining > 0 {
+		r.body.Write(b[:min(len(b), remaining)])
+	}
+	if r.w != nil {
+		return r.w.Write(b)
+	}
+	return len(b), nil
+}
+
+func (r *responseRecorder) statusCode() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
+
+// ListWebhooks returns the webhooks received for repo, newest first, or
+// nothing if the webhook history is disabled.
+func (e *VCSEventsController) ListWebhooks(repo string) []ReceivedWebhook {
+	if e.WebhookHistory == nil {
+		return nil
+	}
+	return e.WebhookHistory.List(repo)
+}
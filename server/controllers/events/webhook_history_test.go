@@ -0,0 +1,63 @@
+package events_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPost_WebhookHistory(t *testing.T) {
+	e, v, _, _, _, _, _, _, _ := setup(t)
+	e.WebhookHistory = events_controllers.NewWebhookHistory(2)
+	payload := []byte(`{"repository": {"full_name": "owner/repo"}}`)
+	When(v.Validate(Any[*http.Request](), Any[[]byte]())).ThenReturn(payload, nil)
+
+	for _, delivery := range []string{"1", "2", "3"} {
+		req, _ := http.NewRequest("POST", "/events", bytes.NewBuffer(payload))
+		req.Header.Set(githubHeader, "value")
+		req.Header.Set("X-Github-Delivery", delivery)
+		req.Header.Set("X-Hub-Signature-256", "sha256=signature")
+		w := httptest.NewRecorder()
+		e.Post(w, req)
+		ResponseContains(t, w, http.StatusOK, "Ignoring unsupported event")
+	}
+	gitlabPayload := []byte(`{"project": {"path_with_namespace": "group/project"}}`)
+	req, _ := http.NewRequest("POST", "/events", bytes.NewBuffer(gitlabPayload))
+	e.Post(httptest.NewRecorder(), req)
+
+	// Only the last 2 webhooks of each repo are kept.
+	hooks := e.ListWebhooks("owner/repo")
+	Equals(t, 2, len(hooks))
+	Equals(t, "3", hooks[0].Headers["X-Github-Delivery"])
+	Equals(t, "2", hooks[1].Headers["X-Github-Delivery"])
+	Equals(t, "<redacted>", hooks[0].Headers["X-Hub-Signature-256"])
+	Equals(t, string(payload), hooks[0].Body)
+	Equals(t, http.StatusOK, hooks[0].StatusCode)
+	Equals(t, "Ignoring unsupported event X-Github-Delivery=3", hooks[0].Response)
+	Equals(t, 3, len(e.ListWebhooks("")))
+	Equals(t, "group/project", e.ListWebhooks("group/project")[0].Repo)
+
+	// The replay isn't checked against the secret since its signature was
+	// redacted, and isn't recorded again.
+	code, response, err := e.Replay(hooks[1].ID)
+	Ok(t, err)
+	Equals(t, http.StatusOK, code)
+	Equals(t, "Ignoring unsupported event X-Github-Delivery=2", response)
+	v.VerifyWasCalledOnce().Validate(Any[*http.Request](), Eq[[]byte](nil))
+	Equals(t, 2, len(e.ListWebhooks("owner/repo")))
+
+	_, _, err = e.Replay("missing")
+	ErrEquals(t, `no webhook with id "missing"`, err)
+}
+
+func TestReplay_HistoryDisabled(t *testing.T) {
+	e, _, _, _, _, _, _, _, _ := setup(t)
+	_, _, err := e.Replay("00000001")
+	ErrEquals(t, "webhook history is disabled", err)
+	Equals(t, 0, len(e.ListWebhooks("")))
+}
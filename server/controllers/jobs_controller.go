@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/runatlantis/atlantis/server/controllers/web_templates"
 	"github.com/runatlantis/atlantis/server/controllers/websocket"
 	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/metrics"
 	tally "github.com/uber-go/tally/v4"
@@ -35,6 +38,38 @@ type JobsController struct {
 	WsMux                    *websocket.Multiplexor       `validate:"required"`
 	KeyGenerator             JobIDKeyGenerator
 	StatsScope               tally.Scope `validate:"required"`
+	// JobLogStore is nil unless the output of completed jobs is kept.
+	JobLogStore          jobs.JobLogStore
+	JobLogSearchTemplate web_templates.TemplateWriter
+	// JobBoard is nil if the running jobs can't be listed.
+	JobBoard            jobs.JobBoard
+	RunningJobsTemplate web_templates.TemplateWriter
+	WsWriter            *websocket.Writer
+}
+
+// runningJobsInterval is how often the running jobs are sent to the running
+// jobs page.
+const runningJobsInterval = time.Second
+
+// runningJob is a running job as sent to the running jobs page.
+type runningJob struct {
+	JobID           string `json:"job_id"`
+	Repo            string `json:"repo"`
+	PullNum         int    `json:"pull_num"`
+	Project         string `json:"project"`
+	Path            string `json:"path"`
+	Workspace       string `json:"workspace"`
+	Step            string `json:"step"`
+	Description     string `json:"description"`
+	StartedAt       string `json:"started_at"`
+	DurationSeconds int    `json:"duration_seconds"`
+	// IdleSeconds is how long ago the job last wrote output.
+	IdleSeconds int    `json:"idle_seconds"`
+	Lines       int    `json:"lines"`
+	LastLine    string `json:"last_line"`
+	JobURL      string `json:"job_url"`
+	Cancelable  bool   `json:"cancelable"`
+	Canceled    bool   `json:"canceled"`
 }
 
 func (j *JobsController) getProjectJobs(w http.ResponseWriter, r *http.Request) error {
@@ -87,6 +122,207 @@ func (j *JobsController) GetProjectJobsWS(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// SearchJobLogs renders the completed jobs matching the query parameters.
+// See parseJobLogQuery.
+func (j *JobsController) SearchJobLogs(w http.ResponseWriter, r *http.Request) {
+	if j.JobLogStore == nil {
+		j.respond(w, logging.Info, http.StatusNotFound, "Keeping job output is disabled, see --job-log-history-days")
+		return
+	}
+	values := r.URL.Query()
+	query, err := parseJobLogQuery(values)
+	if err != nil {
+		j.respond(w, logging.Warn, http.StatusBadRequest, "%s", err)
+		return
+	}
+	found, err := j.JobLogStore.Search(query)
+	if err != nil {
+		j.respond(w, logging.Error, http.StatusInternalServerError, "Searching job output failed: %s", err)
+		return
+	}
+
+	viewData := web_templates.JobLogSearchData{
+		AtlantisVersion: j.AtlantisVersion,
+		CleanedBasePath: j.AtlantisURL.Path,
+		Repo:            values.Get("repo"),
+		Project:         values.Get("project"),
+		Step:            values.Get("step"),
+		Text:            values.Get("text"),
+		From:            values.Get("from"),
+		To:              values.Get("to"),
+	}
+	for _, log := range found {
+		viewData.Results = append(viewData.Results, web_templates.JobLogSearchResult{
+			JobLogPath:     fmt.Sprintf("/jobs/history/%s", url.PathEscape(log.JobID)),
+			RepoFullName:   log.RepoFullName,
+			PullNum:        log.PullNum,
+			ProjectName:    log.ProjectName,
+			Path:           log.Path,
+			Workspace:      log.Workspace,
+			JobStep:        log.JobStep,
+			JobDescription: log.JobDescription,
+			HeadCommit:     log.HeadCommit,
+			TimeFormatted:  log.EndTime.Format("2006-01-02 15:04:05"),
+			MatchingLines:  matchingLines(log, query),
+		})
+	}
+	if err := j.JobLogSearchTemplate.Execute(w, viewData); err != nil {
+		j.Logger.Err(err.Error())
+	}
+}
+
+// GetJobLog writes the output of a completed job as text.
+func (j *JobsController) GetJobLog(w http.ResponseWriter, r *http.Request) {
+	jobID, err := j.KeyGenerator.Generate(r)
+	if err != nil {
+		j.respond(w, logging.Error, http.StatusBadRequest, "%s", err.Error())
+		return
+	}
+	if j.JobLogStore == nil {
+		j.respond(w, logging.Info, http.StatusNotFound, "Keeping job output is disabled, see --job-log-history-days")
+		return
+	}
+	log, ok, err := j.JobLogStore.Get(jobID)
+	if err != nil {
+		j.respond(w, logging.Error, http.StatusInternalServerError, "Reading job output failed: %s", err)
+		return
+	}
+	if !ok {
+		j.respond(w, logging.Info, http.StatusNotFound, "No output found for job %q", jobID)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "# %s #%d %s %s %s completed at %s\n", log.RepoFullName, log.PullNum, log.Path, log.Workspace, log.JobStep, log.EndTime.Format(time.RFC3339))
+	for _, line := range log.Lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// GetRunningJobs renders the running jobs page.
+func (j *JobsController) GetRunningJobs(w http.ResponseWriter, _ *http.Request) {
+	if j.JobBoard == nil {
+		j.respond(w, logging.Info, http.StatusNotFound, "The job board is disabled since the output of jobs isn't streamed")
+		return
+	}
+	viewData := web_templates.RunningJobsData{
+		AtlantisVersion: j.AtlantisVersion,
+		CleanedBasePath: j.AtlantisURL.Path,
+	}
+	if err := j.RunningJobsTemplate.Execute(w, viewData); err != nil {
+		j.Logger.Err(err.Error())
+	}
+}
+
+// GetRunningJobsWS streams the running jobs to the running jobs page.
+func (j *JobsController) GetRunningJobsWS(w http.ResponseWriter, r *http.Request) {
+	if j.JobBoard == nil {
+		j.respond(w, logging.Info, http.StatusNotFound, "The job board is disabled since the output of jobs isn't streamed")
+		return
+	}
+	err := j.WsWriter.WriteSnapshots(w, r, runningJobsInterval, func() interface{} {
+		return runningJobs(j.JobBoard.RunningJobs(), time.Now())
+	})
+	if err != nil {
+		j.Logger.Warn("streaming running jobs: %s", err)
+	}
+}
+
+// CancelJob cancels a running job.
+func (j *JobsController) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := j.KeyGenerator.Generate(r)
+	if err != nil {
+		j.respond(w, logging.Error, http.StatusBadRequest, "%s", err.Error())
+		return
+	}
+	if j.JobBoard == nil {
+		j.respond(w, logging.Info, http.StatusNotFound, "The job board is disabled since the output of jobs isn't streamed")
+		return
+	}
+	if !j.JobBoard.Cancel(jobID) {
+		j.respond(w, logging.Info, http.StatusNotFound, "Job %q isn't running", jobID)
+		return
+	}
+	j.respond(w, logging.Warn, http.StatusOK, "%s canceled job %s", requestActor(r), jobID)
+}
+
+// runningJobs converts the running jobs to the jobs sent to the running jobs
+// page.
+func runningJobs(running []jobs.RunningJob, now time.Time) []runningJob {
+	converted := make([]runningJob, 0, len(running))
+	for _, job := range running {
+		converted = append(converted, runningJob{
+			JobID:           job.JobID,
+			Repo:            job.RepoFullName,
+			PullNum:         job.PullNum,
+			Project:         job.ProjectName,
+			Path:            job.Path,
+			Workspace:       job.Workspace,
+			Step:            job.JobStep,
+			Description:     job.JobDescription,
+			StartedAt:       job.StartTime.Format("2006-01-02 15:04:05"),
+			DurationSeconds: int(now.Sub(job.StartTime).Seconds()),
+			IdleSeconds:     int(now.Sub(job.LastOutputTime).Seconds()),
+			Lines:           job.Lines,
+			LastLine:        job.LastLine,
+			JobURL:          fmt.Sprintf("/jobs/%s", url.PathEscape(job.JobID)),
+			Cancelable:      job.Cancelable,
+			Canceled:        job.Canceled,
+		})
+	}
+	return converted
+}
+
+// matchingLines returns the lines of log shown in the search results. They're
+// only shown if the query has text since otherwise every line matches.
+func matchingLines(log jobs.JobLog, query jobs.JobLogQuery) []string {
+	if query.Text == "" {
+		return nil
+	}
+	return log.Lines
+}
+
+// parseJobLogQuery parses the query parameters of a job search: repo,
+// project, step, text, from, to and limit. from and to are RFC 3339 times or
+// dates, in which case to includes the whole day.
+func parseJobLogQuery(values url.Values) (jobs.JobLogQuery, error) {
+	query := jobs.JobLogQuery{
+		Repo:    values.Get("repo"),
+		Project: values.Get("project"),
+		Step:    values.Get("step"),
+		Text:    values.Get("text"),
+	}
+	var err error
+	if query.From, err = parseJobLogTime(values.Get("from"), false); err != nil {
+		return query, fmt.Errorf("invalid from query parameter: %w", err)
+	}
+	if query.To, err = parseJobLogTime(values.Get("to"), true); err != nil {
+		return query, fmt.Errorf("invalid to query parameter: %w", err)
+	}
+	if limit := values.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 0 {
+			return query, fmt.Errorf("invalid limit query parameter: %q", limit)
+		}
+	}
+	return query, nil
+}
+
+func parseJobLogTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("%q is neither a date nor an RFC 3339 time", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
 func (j *JobsController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
 	response := fmt.Sprintf(format, args...)
 	j.Logger.Log(lvl, response)
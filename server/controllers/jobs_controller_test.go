@@ -0,0 +1,143 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func jobLogsController(t *testing.T) controllers.JobsController {
+	store, err := jobs.NewFileJobLogStore(t.TempDir(), 0)
+	Ok(t, err)
+	Ok(t, store.Save(jobs.JobLog{
+		JobID:      "job-1",
+		PullInfo:   jobs.PullInfo{RepoFullName: "owner/repo", PullNum: 7, ProjectName: "db", Path: "db", Workspace: "default"},
+		HeadCommit: "0123456789abcdef",
+		JobStep:    "apply",
+		EndTime:    time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Lines:      []string{"aws_db_instance.main: Destroying...", "Apply complete! Resources: 0 added, 0 changed, 1 destroyed."},
+	}))
+	return controllers.JobsController{
+		AtlantisURL:          &url.URL{Path: "/atlantis"},
+		Logger:               logging.NewNoopLogger(t),
+		JobLogStore:          store,
+		JobLogSearchTemplate: web_templates.JobLogSearchTemplate,
+	}
+}
+
+func TestJobsController_SearchJobLogs(t *testing.T) {
+	jc := jobLogsController(t)
+
+	req, _ := http.NewRequest("GET", "/jobs/search?repo=owner/repo&project=db&text=destroying&to=2026-10-01", nil)
+	w := httptest.NewRecorder()
+	jc.SearchJobLogs(w, req)
+	Equals(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	for _, exp := range []string{`href="/atlantis/jobs/history/job-1"`, "aws_db_instance.main: Destroying...", "<code>0123456</code>"} {
+		Assert(t, strings.Contains(body, exp), "expected %q in %s", exp, body)
+	}
+
+	req, _ = http.NewRequest("GET", "/jobs/search?from=2026-10-02", nil)
+	w = httptest.NewRecorder()
+	jc.SearchJobLogs(w, req)
+	ResponseContains(t, w, http.StatusOK, "No jobs found.")
+
+	req, _ = http.NewRequest("GET", "/jobs/search?limit=-1", nil)
+	w = httptest.NewRecorder()
+	jc.SearchJobLogs(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, `invalid limit query parameter: "-1"`)
+
+	jc.JobLogStore = nil
+	w = httptest.NewRecorder()
+	jc.SearchJobLogs(w, req)
+	ResponseContains(t, w, http.StatusNotFound, "Keeping job output is disabled")
+}
+
+func TestJobsController_GetJobLog(t *testing.T) {
+	jc := jobLogsController(t)
+
+	req, _ := http.NewRequest("GET", "/jobs/history/job-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"job-id": "job-1"})
+	w := httptest.NewRecorder()
+	jc.GetJobLog(w, req)
+	ResponseContains(t, w, http.StatusOK, "# owner/repo #7 db default apply completed at 2026-10-01T12:00:00Z\n"+
+		"aws_db_instance.main: Destroying...\n"+
+		"Apply complete! Resources: 0 added, 0 changed, 1 destroyed.\n")
+
+	req = mux.SetURLVars(req, map[string]string{"job-id": "job-2"})
+	w = httptest.NewRecorder()
+	jc.GetJobLog(w, req)
+	ResponseContains(t, w, http.StatusNotFound, `No output found for job "job-2"`)
+}
+
+// staticJobBoard is a job board of running jobs that records the canceled
+// ones.
+type staticJobBoard struct {
+	running  []jobs.RunningJob
+	canceled []string
+}
+
+func (s *staticJobBoard) RunningJobs() []jobs.RunningJob {
+	return s.running
+}
+
+func (s *staticJobBoard) Cancel(jobID string) bool {
+	for _, job := range s.running {
+		if job.JobID == jobID {
+			s.canceled = append(s.canceled, jobID)
+			return true
+		}
+	}
+	return false
+}
+
+func TestJobsController_GetRunningJobs(t *testing.T) {
+	jc := controllers.JobsController{
+		AtlantisURL:         &url.URL{Path: "/atlantis"},
+		Logger:              logging.NewNoopLogger(t),
+		JobBoard:            &staticJobBoard{},
+		RunningJobsTemplate: web_templates.RunningJobsTemplate,
+	}
+
+	req, _ := http.NewRequest("GET", "/jobs/running", nil)
+	w := httptest.NewRecorder()
+	jc.GetRunningJobs(w, req)
+	ResponseContains(t, w, http.StatusOK, `/atlantis/static/js/jquery-3.5.1.min.js`)
+
+	jc.JobBoard = nil
+	w = httptest.NewRecorder()
+	jc.GetRunningJobs(w, req)
+	ResponseContains(t, w, http.StatusNotFound, "The job board is disabled")
+}
+
+func TestJobsController_CancelJob(t *testing.T) {
+	board := &staticJobBoard{running: []jobs.RunningJob{{JobID: "job-1"}}}
+	jc := controllers.JobsController{
+		Logger:   logging.NewNoopLogger(t),
+		JobBoard: board,
+	}
+
+	req, _ := http.NewRequest("POST", "/jobs/job-1/cancel", nil)
+	req.SetBasicAuth("oncall", "password")
+	req = mux.SetURLVars(req, map[string]string{"job-id": "job-1"})
+	w := httptest.NewRecorder()
+	jc.CancelJob(w, req)
+	ResponseContains(t, w, http.StatusOK, "oncall canceled job job-1")
+	Equals(t, []string{"job-1"}, board.canceled)
+
+	req = mux.SetURLVars(req, map[string]string{"job-id": "job-2"})
+	w = httptest.NewRecorder()
+	jc.CancelJob(w, req)
+	ResponseContains(t, w, http.StatusNotFound, `Job "job-2" isn't running`)
+	Equals(t, []string{"job-1"}, board.canceled)
+}
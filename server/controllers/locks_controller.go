@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/controllers/web_templates"
 
@@ -28,6 +30,22 @@ type LocksController struct {
 	WorkingDirLocker   events.WorkingDirLocker      `validate:"required"`
 	Backend            locking.Backend              `validate:"required"`
 	DeleteLockCommand  events.DeleteLockCommand     `validate:"required"`
+	PlanFreezer        locking.PlanFreezer          `validate:"required"`
+	// AuditLog records who deleted each lock. If it's nil, deletes are only
+	// logged.
+	AuditLog events.LockAuditLog
+}
+
+// DeleteLocksRequest is the body of the bulk unlock request.
+type DeleteLocksRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// DeleteLocksResponse is the result of the bulk unlock request.
+type DeleteLocksResponse struct {
+	Deleted  []string          `json:"deleted"`
+	NotFound []string          `json:"not_found"`
+	Errors   map[string]string `json:"errors"`
 }
 
 // LockApply handles creating a global apply lock.
@@ -54,6 +72,35 @@ func (l *LocksController) UnlockApply(w http.ResponseWriter, _ *http.Request) {
 	l.respond(w, logging.Info, http.StatusOK, "Deleted apply lock")
 }
 
+// FreezePlans handles freezing plans globally.
+// If plans are already frozen globally the freeze is replaced.
+func (l *LocksController) FreezePlans(w http.ResponseWriter, _ *http.Request) {
+	freeze, err := l.PlanFreezer.Freeze(locking.PlanFreeze{})
+	if err != nil {
+		l.respond(w, logging.Error, http.StatusInternalServerError, "freezing plans failed with: %s", err)
+		return
+	}
+
+	l.respond(w, logging.Info, http.StatusOK, "Plans are frozen since %s", freeze.Time.Format("2006-01-02 15:04:05"))
+}
+
+// UnfreezePlans handles deleting the plan freeze with the scope of the repo,
+// project and dir query parameters. Without them the global freeze is deleted.
+func (l *LocksController) UnfreezePlans(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	deleted, err := l.PlanFreezer.Unfreeze(query.Get("repo"), query.Get("project"), query.Get("dir"))
+	if err != nil {
+		l.respond(w, logging.Error, http.StatusInternalServerError, "deleting plan freeze failed with: %s", err)
+		return
+	}
+	if !deleted {
+		l.respond(w, logging.Info, http.StatusNotFound, "No plan freeze found")
+		return
+	}
+
+	l.respond(w, logging.Info, http.StatusOK, "Deleted plan freeze")
+}
+
 // GetLock is the GET /locks/{id} route. It renders the lock detail view.
 func (l *LocksController) GetLock(w http.ResponseWriter, r *http.Request) {
 	id, ok := mux.Vars(r)["id"]
@@ -111,7 +158,7 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lock, err := l.DeleteLockCommand.DeleteLock(l.Logger, idUnencoded)
+	lock, err := l.deleteLock(idUnencoded, requestActor(r))
 	if err != nil {
 		l.respond(w, logging.Error, http.StatusInternalServerError, "deleting lock failed with: '%s'", err)
 		return
@@ -121,11 +168,81 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 		l.respond(w, logging.Info, http.StatusNotFound, "No lock found at id '%s'", idUnencoded)
 		return
 	}
+	l.respond(w, logging.Info, http.StatusOK, "Deleted lock id '%s'", id)
+}
+
+// DeleteLocks is the POST /locks/unlock route. It deletes each lock in the
+// request like DeleteLock does and responds with what happened to each one.
+func (l *LocksController) DeleteLocks(w http.ResponseWriter, r *http.Request) {
+	var request DeleteLocksRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		l.respond(w, logging.Warn, http.StatusBadRequest, "Failed to parse request: %s", err)
+		return
+	}
+	if len(request.IDs) == 0 {
+		l.respond(w, logging.Warn, http.StatusBadRequest, "No lock ids in request")
+		return
+	}
+
+	actor := requestActor(r)
+	response := DeleteLocksResponse{
+		Deleted:  []string{},
+		NotFound: []string{},
+		Errors:   map[string]string{},
+	}
+	for _, id := range request.IDs {
+		lock, err := l.deleteLock(id, actor)
+		switch {
+		case err != nil:
+			response.Errors[id] = err.Error()
+		case lock == nil:
+			response.NotFound = append(response.NotFound, id)
+		default:
+			response.Deleted = append(response.Deleted, id)
+		}
+	}
+	l.Logger.Info("%s deleted %d of %d locks", actor, len(response.Deleted), len(request.IDs))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		l.Logger.Err("encoding response: %s", err)
+	}
+}
+
+// deleteLock deletes the lock at id, comments back on the pull request that
+// the lock has been deleted and records that actor deleted it. The returned
+// lock is nil if there was no lock at id.
+func (l *LocksController) deleteLock(id string, actor string) (*models.ProjectLock, error) {
+	lock, err := l.DeleteLockCommand.DeleteLock(l.Logger, id)
+	if err != nil || lock == nil {
+		return lock, err
+	}
+
+	l.Logger.Info("lock id '%s' held by pull %s#%d was deleted by %s", id, lock.Project.RepoFullName, lock.Pull.Num, actor)
+	if l.AuditLog != nil {
+		err := l.AuditLog.Record(events.LockAuditEntry{
+			Time:         time.Now(),
+			LockID:       id,
+			RepoFullName: lock.Project.RepoFullName,
+			PullNum:      lock.Pull.Num,
+			ProjectName:  lock.Project.ProjectName,
+			Path:         lock.Project.Path,
+			Workspace:    lock.Workspace,
+			LockedBy:     lock.Pull.Author,
+			Command:      lock.Command,
+			Actor:        actor,
+		})
+		if err != nil {
+			l.Logger.Err("unable to record deleting lock id '%s' in the audit log: %s", id, err)
+		}
+	}
 
 	// NOTE: Because BaseRepo was added to the PullRequest model later, previous
 	// installations of Atlantis will have locks in their DB that do not have
 	// this field on PullRequest. We skip commenting in this case.
-	if lock.Pull.BaseRepo != (models.Repo{}) {
+	if locking.IsLockKey(lock.Project) {
+		l.Logger.Debug("skipping commenting on pull request since lock id '%s' is a lock key", id)
+	} else if lock.Pull.BaseRepo != (models.Repo{}) {
 		if err := l.Backend.UpdateProjectStatus(lock.Pull, lock.Workspace, lock.Project.Path, models.DiscardedPlanStatus); err != nil {
 			l.Logger.Err("unable to update project status: %s", err)
 		}
@@ -139,7 +256,16 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 	} else {
 		l.Logger.Debug("skipping commenting on pull request and deleting workspace because BaseRepo field is empty")
 	}
-	l.respond(w, logging.Info, http.StatusOK, "Deleted lock id '%s'", id)
+	return lock, nil
+}
+
+// requestActor returns who made r: the web username if basic auth is
+// enabled, otherwise the address the request came from.
+func requestActor(r *http.Request) string {
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return user
+	}
+	return r.RemoteAddr
 }
 
 // respond is a helper function to respond and log the response. lvl is the log
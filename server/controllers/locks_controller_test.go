@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -107,6 +108,45 @@ func TestUnlockApply(t *testing.T) {
 	})
 }
 
+// newTestRecordBackend returns a BoltDB in a temp dir to store records in.
+func newTestRecordBackend(t *testing.T) *db.BoltDB {
+	boltDB, err := db.New(t.TempDir())
+	Ok(t, err)
+	t.Cleanup(func() {
+		boltDB.Close()
+	})
+	return boltDB
+}
+
+func TestPlanFreeze(t *testing.T) {
+	lc := controllers.LocksController{
+		Logger:      logging.NewNoopLogger(t),
+		PlanFreezer: locking.NewPlanFreezer(newTestRecordBackend(t)),
+	}
+
+	req, _ := http.NewRequest("POST", "/plan-freezes", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	lc.FreezePlans(w, req)
+	ResponseContains(t, w, http.StatusOK, "Plans are frozen since")
+	freezes, err := lc.PlanFreezer.List()
+	Ok(t, err)
+	Equals(t, 1, len(freezes))
+	Assert(t, freezes[0].IsGlobal(), "expected a global freeze")
+
+	req, _ = http.NewRequest("DELETE", "/plan-freezes?repo=owner/repo", bytes.NewBuffer(nil))
+	w = httptest.NewRecorder()
+	lc.UnfreezePlans(w, req)
+	ResponseContains(t, w, http.StatusNotFound, "No plan freeze found")
+
+	req, _ = http.NewRequest("DELETE", "/plan-freezes", bytes.NewBuffer(nil))
+	w = httptest.NewRecorder()
+	lc.UnfreezePlans(w, req)
+	ResponseContains(t, w, http.StatusOK, "Deleted plan freeze")
+	freezes, err = lc.PlanFreezer.List()
+	Ok(t, err)
+	Equals(t, 0, len(freezes))
+}
+
 func TestGetLockRoute_NoLockID(t *testing.T) {
 	t.Log("If there is no lock ID in the request then we should get a 400")
 	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
@@ -405,3 +445,61 @@ func TestDeleteLock_CommentSuccess(t *testing.T) {
 		Eq("**Warning**: The plan for dir: `path` workspace: `workspace` was **discarded** via the Atlantis UI.\n\n"+
 			"To `apply` this plan you must run `plan` again."), Eq(""))
 }
+
+func TestDeleteLocks(t *testing.T) {
+	RegisterMockTestingT(t)
+	cp := vcsmocks.NewMockClient()
+	dlc := mocks2.NewMockDeleteLockCommand()
+	backend, err := db.New(t.TempDir())
+	Ok(t, err)
+	pull := models.PullRequest{
+		BaseRepo: models.Repo{FullName: "owner/repo"},
+		Num:      1,
+		Author:   "author",
+	}
+	When(dlc.DeleteLock(Any[logging.SimpleLogging](), Eq("owner/repo/path/default"))).ThenReturn(&models.ProjectLock{
+		Pull:      pull,
+		Workspace: "default",
+		Command:   "plan",
+		Project: models.Project{
+			Path:         "path",
+			RepoFullName: "owner/repo",
+		},
+	}, nil)
+	When(dlc.DeleteLock(Any[logging.SimpleLogging](), Eq("owner/repo/gone/default"))).ThenReturn(nil, nil)
+	When(dlc.DeleteLock(Any[logging.SimpleLogging](), Eq("owner/repo/err/default"))).ThenReturn(nil, errors.New("err"))
+	auditLog := &events.FileLockAuditLog{Path: filepath.Join(t.TempDir(), "lock-audit.jsonl")}
+	lc := controllers.LocksController{
+		DeleteLockCommand: dlc,
+		Logger:            logging.NewNoopLogger(t),
+		VCSClient:         cp,
+		Backend:           backend,
+		WorkingDir:        mocks2.NewMockWorkingDir(),
+		WorkingDirLocker:  events.NewDefaultWorkingDirLocker(),
+		AuditLog:          auditLog,
+	}
+	req, _ := http.NewRequest("POST", "/locks/unlock", bytes.NewBufferString(`{"ids": ["owner/repo/path/default", "owner/repo/gone/default", "owner/repo/err/default"]}`))
+	req.SetBasicAuth("admin", "password")
+	w := httptest.NewRecorder()
+	lc.DeleteLocks(w, req)
+	ResponseContains(t, w, http.StatusOK, `{"deleted":["owner/repo/path/default"],"not_found":["owner/repo/gone/default"],"errors":{"owner/repo/err/default":"err"}}`)
+	cp.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(pull.BaseRepo), Eq(pull.Num), Any[string](), Eq(""))
+
+	entries, err := auditLog.Recent(10)
+	Ok(t, err)
+	Equals(t, 1, len(entries))
+	Equals(t, "owner/repo/path/default", entries[0].LockID)
+	Equals(t, "admin", entries[0].Actor)
+	Equals(t, "author", entries[0].LockedBy)
+	Equals(t, "plan", entries[0].Command)
+}
+
+func TestDeleteLocks_NoIDs(t *testing.T) {
+	lc := controllers.LocksController{
+		Logger: logging.NewNoopLogger(t),
+	}
+	req, _ := http.NewRequest("POST", "/locks/unlock", bytes.NewBufferString(`{"ids": []}`))
+	w := httptest.NewRecorder()
+	lc.DeleteLocks(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "No lock ids in request")
+}
@@ -36,6 +36,8 @@ var templateFileNames = map[string]string{
 	"lock":               "lock.html.tmpl",
 	"project-jobs":       "project-jobs.html.tmpl",
 	"project-jobs-error": "project-jobs-error.html.tmpl",
+	"job-log-search":     "job-log-search.html.tmpl",
+	"running-jobs":       "running-jobs.html.tmpl",
 	"github-app":         "github-app.html.tmpl",
 }
 
@@ -49,13 +51,39 @@ type TemplateWriter interface {
 
 // LockIndexData holds the fields needed to display the index view for locks.
 type LockIndexData struct {
-	LockPath      string
+	LockPath     string
+	LockID       string
+	RepoFullName string
+	PullNum      int
+	PullURL      string
+	ProjectName  string
+	Path         string
+	Workspace    string
+	// LockedBy is the author of the pull request and User the user that ran
+	// the command that created the lock.
+	LockedBy string
+	User     string
+	// Command is the command that created the lock. It's empty for locks
+	// created before it was recorded.
+	Command       string
+	Time          time.Time
+	TimeFormatted string
+	// Age is how long ago the lock was created, ex. 3h.
+	Age string
+	// Labels are the labels of the locked project when it was last planned
+	// or applied, ex. tier: prod.
+	Labels map[string]string
+}
+
+// LockAuditIndexData holds the fields to display a deleted lock in the index
+// view.
+type LockAuditIndexData struct {
 	RepoFullName  string
 	PullNum       int
 	Path          string
 	Workspace     string
 	LockedBy      string
-	Time          time.Time
+	Actor         string
 	TimeFormatted string
 }
 
@@ -67,13 +95,30 @@ type ApplyLockData struct {
 	TimeFormatted          string
 }
 
+// PlanFreezeIndexData holds the fields to display a plan freeze in the index
+// view.
+type PlanFreezeIndexData struct {
+	Repo          string
+	Project       string
+	Dir           string
+	Message       string
+	TimeFormatted string
+}
+
 // IndexData holds the data for rendering the index page
 type IndexData struct {
 	Locks            []LockIndexData
+	LockAudit        []LockAuditIndexData
 	PullToJobMapping []jobs.PullInfoWithJobIDs
-
-	ApplyLock       ApplyLockData
-	AtlantisVersion string
+	// JobLogSearchEnabled is true if the output of completed jobs is kept.
+	JobLogSearchEnabled bool
+	// JobBoardEnabled is true if the running jobs can be listed.
+	JobBoardEnabled bool
+
+	ApplyLock           ApplyLockData
+	PlanFreezes         []PlanFreezeIndexData
+	PlansFrozenGlobally bool
+	AtlantisVersion     string
 	// CleanedBasePath is the path Atlantis is accessible at externally. If
 	// not using a path-based proxy, this will be an empty string. Never ends
 	// in a '/' (hence "cleaned").
@@ -117,6 +162,47 @@ type ProjectJobsError struct {
 
 var ProjectJobsErrorTemplate = templates.Lookup(templateFileNames["project-jobs-error"])
 
+// JobLogSearchData holds the search and the results of the job output search
+// page.
+type JobLogSearchData struct {
+	AtlantisVersion string
+	CleanedBasePath string
+	Repo            string
+	Project         string
+	Step            string
+	Text            string
+	From            string
+	To              string
+	Results         []JobLogSearchResult
+}
+
+// JobLogSearchResult is a completed job matching a search.
+type JobLogSearchResult struct {
+	JobLogPath     string
+	RepoFullName   string
+	PullNum        int
+	ProjectName    string
+	Path           string
+	Workspace      string
+	JobStep        string
+	JobDescription string
+	HeadCommit     string
+	TimeFormatted  string
+	// MatchingLines are the lines of output containing the searched text.
+	MatchingLines []string
+}
+
+var JobLogSearchTemplate = templates.Lookup(templateFileNames["job-log-search"])
+
+// RunningJobsData holds the data of the running jobs page. The jobs are
+// streamed over a websocket.
+type RunningJobsData struct {
+	AtlantisVersion string
+	CleanedBasePath string
+}
+
+var RunningJobsTemplate = templates.Lookup(templateFileNames["running-jobs"])
+
 // GithubSetupData holds the data for rendering the github app setup page
 type GithubSetupData struct {
 	Target          string
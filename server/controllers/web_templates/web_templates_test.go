@@ -14,12 +14,31 @@ func TestIndexTemplate(t *testing.T) {
 		Locks: []LockIndexData{
 			{
 				LockPath:      "lock path",
+				LockID:        "lock id",
 				RepoFullName:  "repo full name",
 				PullNum:       1,
+				PullURL:       "pull url",
+				ProjectName:   "project name",
 				Path:          "path",
 				Workspace:     "workspace",
+				LockedBy:      "author",
+				User:          "user",
+				Command:       "plan",
 				Time:          time.Now(),
 				TimeFormatted: "2006-01-02 15:04:05",
+				Age:           "3h",
+				Labels:        map[string]string{"tier": "prod"},
+			},
+		},
+		LockAudit: []LockAuditIndexData{
+			{
+				RepoFullName:  "repo full name",
+				PullNum:       1,
+				Path:          "path",
+				Workspace:     "workspace",
+				LockedBy:      "author",
+				Actor:         "admin",
+				TimeFormatted: "2006-01-02 15:04:05",
 			},
 		},
 		ApplyLock: ApplyLockData{
@@ -27,6 +46,14 @@ func TestIndexTemplate(t *testing.T) {
 			Time:          time.Now(),
 			TimeFormatted: "2006-01-02 15:04:05",
 		},
+		PlanFreezes: []PlanFreezeIndexData{
+			{
+				Repo:          "repo full name",
+				Project:       "project name",
+				Message:       "message",
+				TimeFormatted: "2006-01-02 15:04:05",
+			},
+		},
 		AtlantisVersion: "v0.0.0",
 		CleanedBasePath: "/path",
 		PullToJobMapping: []jobs.PullInfoWithJobIDs{
@@ -81,6 +108,39 @@ func TestProjectJobsErrorTemplate(t *testing.T) {
 	Ok(t, err)
 }
 
+func TestJobLogSearchTemplate(t *testing.T) {
+	err := JobLogSearchTemplate.Execute(io.Discard, JobLogSearchData{
+		AtlantisVersion: "v0.0.0",
+		CleanedBasePath: "/path",
+		Repo:            "repo full name",
+		Step:            "apply",
+		Text:            "destroy",
+		Results: []JobLogSearchResult{
+			{
+				JobLogPath:    "/jobs/history/job id",
+				RepoFullName:  "repo full name",
+				PullNum:       1,
+				ProjectName:   "project name",
+				Path:          "path",
+				Workspace:     "workspace",
+				JobStep:       "apply",
+				HeadCommit:    "0123456789",
+				TimeFormatted: "2006-01-02 15:04:05",
+				MatchingLines: []string{"destroy"},
+			},
+		},
+	})
+	Ok(t, err)
+}
+
+func TestRunningJobsTemplate(t *testing.T) {
+	err := RunningJobsTemplate.Execute(io.Discard, RunningJobsData{
+		AtlantisVersion: "v0.0.0",
+		CleanedBasePath: "/path",
+	})
+	Ok(t, err)
+}
+
 func TestGithubAppSetupTemplate(t *testing.T) {
 	err := GithubAppSetupTemplate.Execute(io.Discard, GithubSetupData{
 		Target:          "target",
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
@@ -12,7 +13,6 @@ func NewWriter(log logging.SimpleLogging, checkOrigin bool) *Writer {
 	upgrader := websocket.Upgrader{
 		CheckOrigin: checkOriginFunc(checkOrigin),
 	}
-	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
 	return &Writer{
 		upgrader: upgrader,
 		log:      log,
@@ -45,3 +45,38 @@ func (w *Writer) Write(rw http.ResponseWriter, r *http.Request, input chan strin
 	}
 	return nil
 }
+
+// WriteSnapshots writes the JSON encoding of snapshot() as a text message
+// every interval until the client disconnects.
+func (w *Writer) WriteSnapshots(rw http.ResponseWriter, r *http.Request, interval time.Duration, snapshot func() interface{}) error {
+	conn, err := w.upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		return errors.Wrap(err, "upgrading websocket connection")
+	}
+	defer conn.Close() // nolint: errcheck
+
+	// Clients don't send messages, reading only notices them disconnecting.
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := conn.WriteJSON(snapshot()); err != nil {
+			w.log.Warn("Failed to write ws message: %s", err)
+			return err
+		}
+		select {
+		case <-disconnected:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
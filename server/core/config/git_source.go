@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitSourcePrefix prefixes a --repo-config value that points at a file in a
+// git repo, ex. git::https://github.com/org/atlantis-config.git//repos.yaml?ref=main.
+const GitSourcePrefix = "git::"
+
+// GitSource is a server-side repo config file that's kept in a git repo.
+type GitSource struct {
+	// URL is the clone URL of the repo. It may contain credentials.
+	URL string
+	// Path is the path of the config file relative to the repo root.
+	Path string
+	// Ref is the branch or tag to check out. If empty, the default branch
+	// is checked out.
+	Ref string
+}
+
+// IsGitSource returns true if repoConfig points at a file in a git repo.
+func IsGitSource(repoConfig string) bool {
+	return strings.HasPrefix(repoConfig, GitSourcePrefix)
+}
+
+// ParseGitSource parses a --repo-config value of the form
+// git::<clone url>//<path>?ref=<ref>. Like Terraform module sources, the path
+// of the file in the repo follows a double slash and the ref is optional.
+func ParseGitSource(repoConfig string) (GitSource, error) {
+	u, err := url.Parse(strings.TrimPrefix(repoConfig, GitSourcePrefix))
+	if err != nil {
+		return GitSource{}, fmt.Errorf("parsing git URL: %w", err)
+	}
+	if u.Scheme == "" {
+		return GitSource{}, errors.New("git URL must include a scheme, ex. https:// or ssh://")
+	}
+	repoPath, file, found := strings.Cut(u.Path, "//")
+	if !found || file == "" {
+		return GitSource{}, fmt.Errorf("git URL must include the path of the config file in the repo after a double slash, ex. %shttps://github.com/org/repo.git//repos.yaml?ref=main", GitSourcePrefix)
+	}
+	if filepath.IsAbs(file) || strings.HasPrefix(filepath.Clean(file), "..") {
+		return GitSource{}, fmt.Errorf("path of the config file must be relative to the repo root, got %q", file)
+	}
+	query := u.Query()
+	ref := query.Get("ref")
+	query.Del("ref")
+	u.Path = repoPath
+	u.RawPath = ""
+	u.RawQuery = query.Encode()
+	return GitSource{
+		URL:  u.String(),
+		Path: filepath.Clean(file),
+		Ref:  ref,
+	}, nil
+}
+
+// Sync clones the repo into dir or, if it was already cloned, fetches the
+// latest commit of the ref and checks it out. It returns the path of the
+// config file.
+func (g GitSource) Sync(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+			return "", err
+		}
+		args := []string{"clone", "--depth=1"}
+		if g.Ref != "" {
+			args = append(args, "--branch", g.Ref)
+		}
+		if err := g.git("", append(args, "--", g.URL, dir)...); err != nil {
+			return "", err
+		}
+	} else {
+		ref := g.Ref
+		if ref == "" {
+			ref = "HEAD"
+		}
+		if err := g.git(dir, "fetch", "--depth=1", "origin", ref); err != nil {
+			return "", err
+		}
+		if err := g.git(dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, g.Path), nil
+}
+
+func (g GitSource) git(dir string, args ...string) error {
+	cmd := exec.Command("git", args...) // nolint: gosec
+	cmd.Dir = dir
+	// Fail instead of waiting for credentials that will never be entered.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		// Don't leak credentials in the URL.
+		redact := func(s string) string { return s }
+		if u, err := url.Parse(g.URL); err == nil && u.User != nil {
+			redact = func(s string) string { return strings.ReplaceAll(s, g.URL, u.Redacted()) }
+		}
+		return fmt.Errorf("running git %s: %s: %s", redact(strings.Join(args, " ")), redact(strings.TrimSpace(string(output))), err)
+	}
+	return nil
+}
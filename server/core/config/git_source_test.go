@@ -0,0 +1,93 @@
+package config_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	cases := []struct {
+		repoConfig string
+		exp        config.GitSource
+		expErr     string
+	}{
+		{
+			repoConfig: "git::https://github.com/org/atlantis-config.git//repos.yaml?ref=main",
+			exp:        config.GitSource{URL: "https://github.com/org/atlantis-config.git", Path: "repos.yaml", Ref: "main"},
+		},
+		{
+			repoConfig: "git::ssh://git@github.com/org/atlantis-config.git//prod/repos.yaml",
+			exp:        config.GitSource{URL: "ssh://git@github.com/org/atlantis-config.git", Path: "prod/repos.yaml"},
+		},
+		{
+			repoConfig: "git::https://github.com/org/atlantis-config.git",
+			expErr:     "git URL must include the path of the config file in the repo after a double slash, ex. git::https://github.com/org/repo.git//repos.yaml?ref=main",
+		},
+		{
+			repoConfig: "git::github.com/org/atlantis-config.git//repos.yaml",
+			expErr:     "git URL must include a scheme, ex. https:// or ssh://",
+		},
+		{
+			repoConfig: "git::https://github.com/org/atlantis-config.git//../repos.yaml",
+			expErr:     "path of the config file must be relative to the repo root, got \"../repos.yaml\"",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.repoConfig, func(t *testing.T) {
+			Equals(t, true, config.IsGitSource(c.repoConfig))
+			source, err := config.ParseGitSource(c.repoConfig)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.exp, source)
+		})
+	}
+}
+
+func TestGitSource_Sync(t *testing.T) {
+	originDir := t.TempDir()
+	runGit(t, originDir, "init", "--initial-branch=main")
+	commitFile(t, originDir, "repos.yaml", "repos: []\n")
+
+	source, err := config.ParseGitSource("git::file://" + originDir + "//repos.yaml?ref=main")
+	Ok(t, err)
+	cloneDir := filepath.Join(t.TempDir(), "repo-config")
+
+	path, err := source.Sync(cloneDir)
+	Ok(t, err)
+	Equals(t, filepath.Join(cloneDir, "repos.yaml"), path)
+	contents, err := os.ReadFile(path)
+	Ok(t, err)
+	Equals(t, "repos: []\n", string(contents))
+
+	commitFile(t, originDir, "repos.yaml", "repos:\n- id: github.com/owner/repo\n")
+	_, err = source.Sync(cloneDir)
+	Ok(t, err)
+	contents, err = os.ReadFile(path)
+	Ok(t, err)
+	Equals(t, "repos:\n- id: github.com/owner/repo\n", string(contents))
+
+	source.Ref = "missing"
+	_, err = source.Sync(cloneDir)
+	ErrContains(t, "running git fetch --depth=1 origin missing", err)
+}
+
+func commitFile(t *testing.T, dir string, file string, contents string) {
+	Ok(t, os.WriteFile(filepath.Join(dir, file), []byte(contents), 0600))
+	runGit(t, dir, "add", file)
+	runGit(t, dir, "-c", "user.name=atlantis", "-c", "user.email=atlantis@runatlantis.io", "commit", "-m", "update "+file)
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	Assert(t, err == nil, "running git %v: %s", args, output)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/metrics"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// GlobalCfgReloader reloads the server-side repo config file into a
+// GlobalCfgStore when it or the files of its local policy sets change. A
+// config that fails to parse or validate, or that changes a key only read on
+// startup, is rejected and the current config is kept.
+type GlobalCfgReloader struct {
+	// Path is the path to the server-side repo config file.
+	Path string
+	// DefaultCfg is the config the file is merged onto, the same one the
+	// server was started with.
+	DefaultCfg      valid.GlobalCfg
+	ParserValidator *ParserValidator
+	Store           *valid.GlobalCfgStore
+	Logger          logging.SimpleLogging
+	Scope           tally.Scope
+	// GitSource is nil unless the config file is kept in a git repo, in which
+	// case the repo is synced into GitDir before each check and Path is the
+	// path of the file in the clone.
+	GitSource *GitSource
+	GitDir    string
+
+	mu sync.Mutex
+	// checksum is the checksum of the file and policy set content that was
+	// last loaded or rejected.
+	checksum []byte
+}
+
+// NewGlobalCfgReloader returns a reloader for the config file at path, whose
+// content has already been loaded into store.
+func NewGlobalCfgReloader(path string, defaultCfg valid.GlobalCfg, store *valid.GlobalCfgStore, logger logging.SimpleLogging, scope tally.Scope) *GlobalCfgReloader {
+	r := &GlobalCfgReloader{
+		Path:            path,
+		DefaultCfg:      defaultCfg,
+		ParserValidator: &ParserValidator{},
+		Store:           store,
+		Logger:          logger,
+		Scope:           scope.SubScope("repo_config_reload"),
+	}
+	if data, err := os.ReadFile(path); err == nil { // nolint: gosec
+		r.checksum = r.sourcesChecksum(data)
+	}
+	return r
+}
+
+// Run reloads the config if the file's content changed since it was last
+// loaded. It's meant to be run periodically by the scheduled executor.
+func (r *GlobalCfgReloader) Run() {
+	if err := r.reload(false); err != nil {
+		r.Logger.Err("reloading %s: %s", r.Path, err)
+	}
+}
+
+// Reload reloads the config even if the file didn't change.
+func (r *GlobalCfgReloader) Reload() error {
+	return r.reload(true)
+}
+
+func (r *GlobalCfgReloader) reload(force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.GitSource != nil {
+		if _, err := r.GitSource.Sync(r.GitDir); err != nil {
+			r.Scope.Counter(metrics.ExecutionFailureMetric).Inc(1)
+			return fmt.Errorf("syncing git repo: %w", err)
+		}
+	}
+	data, err := os.ReadFile(r.Path) // nolint: gosec
+	if err != nil {
+		r.Scope.Counter(metrics.ExecutionFailureMetric).Inc(1)
+		return fmt.Errorf("unable to read %s file: %w", r.Path, err)
+	}
+	sum := r.sourcesChecksum(data)
+	if !force && bytes.Equal(sum, r.checksum) {
+		return nil
+	}
+	// Remember the checksum even if the config is rejected so an invalid
+	// file is only reported once.
+	r.checksum = sum
+
+	cfg, err := r.ParserValidator.parseGlobalCfgData(r.Path, data, r.DefaultCfg)
+	if err != nil {
+		r.Scope.Counter(metrics.ExecutionFailureMetric).Inc(1)
+		return fmt.Errorf("keeping the current config since %s is invalid: %w", r.Path, err)
+	}
+	if err := r.Store.Reload(cfg); err != nil {
+		r.Scope.Counter(metrics.ExecutionFailureMetric).Inc(1)
+		return fmt.Errorf("keeping the current config: %w", err)
+	}
+	r.Scope.Counter(metrics.ExecutionSuccessMetric).Inc(1)
+	r.Logger.Info("reloaded server-side repo config from %s", r.Path)
+	return nil
+}
+
+// sourcesChecksum returns the checksum of the config file content data and
+// of the files of the current config's local policy sets, so editing a policy
+// reloads and validates the config like editing the file does.
+func (r *GlobalCfgReloader) sourcesChecksum(data []byte) []byte {
+	hash := sha256.New()
+	hash.Write(data)
+	for _, policySet := range r.Store.Load().PolicySets.PolicySets {
+		if policySet.Source != valid.LocalPolicySet {
+			continue
+		}
+		// Missing files are skipped, validating the config reports them.
+		_ = filepath.WalkDir(policySet.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			content, err := os.ReadFile(path) // nolint: gosec
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(hash, "%s\x00%d\x00", path, len(content))
+			hash.Write(content)
+			return nil
+		})
+	}
+	return hash.Sum(nil)
+}
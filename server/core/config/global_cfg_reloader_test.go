@@ -0,0 +1,92 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+func TestGlobalCfgReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repos.yaml")
+	Ok(t, os.WriteFile(path, []byte("repos:\n- id: github.com/owner/repo\n"), 0600))
+	initialCfg, err := (&config.ParserValidator{}).ParseGlobalCfg(path, globalCfg)
+	Ok(t, err)
+
+	store := valid.NewGlobalCfgStore(initialCfg)
+	scope := tally.NewTestScope("test", nil)
+	reloader := config.NewGlobalCfgReloader(path, globalCfg, store, logging.NewNoopLogger(t), scope)
+	counter := func(name string) int64 {
+		if c, ok := scope.Snapshot().Counters()["test.repo_config_reload."+name+"+"]; ok {
+			return c.Value()
+		}
+		return 0
+	}
+
+	// Nothing is reloaded while the file is unchanged.
+	reloader.Run()
+	Equals(t, int64(0), counter("execution_success"))
+
+	Ok(t, os.WriteFile(path, []byte("repos:\n- id: github.com/owner/repo\n  apply_requirements: [approved]\n"), 0600))
+	reloader.Run()
+	Equals(t, int64(1), counter("execution_success"))
+	Equals(t, []string{"approved"}, store.Load().MatchingRepo("github.com/owner/repo").ApplyRequirements)
+
+	// An invalid file is rejected once and the current config is kept.
+	Ok(t, os.WriteFile(path, []byte("repos:\n- id: github.com/owner/repo\n  apply_requirements: [unknown]\n"), 0600))
+	reloader.Run()
+	reloader.Run()
+	Equals(t, int64(1), counter("execution_failure"))
+	Equals(t, []string{"approved"}, store.Load().MatchingRepo("github.com/owner/repo").ApplyRequirements)
+
+	// Reload reports the error even though the file didn't change.
+	ErrContains(t, "keeping the current config", reloader.Reload())
+	Equals(t, int64(2), counter("execution_failure"))
+}
+
+func TestGlobalCfgReloader_RejectsMetricsChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repos.yaml")
+	Ok(t, os.WriteFile(path, []byte("metrics:\n  prometheus:\n    endpoint: /metrics\n"), 0600))
+	initialCfg, err := (&config.ParserValidator{}).ParseGlobalCfg(path, globalCfg)
+	Ok(t, err)
+	store := valid.NewGlobalCfgStore(initialCfg)
+	reloader := config.NewGlobalCfgReloader(path, globalCfg, store, logging.NewNoopLogger(t), tally.NewTestScope("test", nil))
+
+	Ok(t, os.WriteFile(path, []byte("metrics:\n  prometheus:\n    endpoint: /stats\nrepos:\n- id: github.com/owner/repo\n  apply_requirements: [approved]\n"), 0600))
+	ErrContains(t, "the metrics key can't be changed without restarting the server", reloader.Reload())
+	Equals(t, "/metrics", store.Load().Metrics.Prometheus.Endpoint)
+	Equals(t, 0, len(store.Load().MatchingRepo("github.com/owner/repo").ApplyRequirements))
+}
+
+func TestGlobalCfgReloader_WatchesPolicySets(t *testing.T) {
+	dir := t.TempDir()
+	policyDir := filepath.Join(dir, "policies")
+	Ok(t, os.Mkdir(policyDir, 0700))
+	Ok(t, os.WriteFile(filepath.Join(policyDir, "policy.rego"), []byte("package main\n"), 0600))
+	path := filepath.Join(dir, "repos.yaml")
+	Ok(t, os.WriteFile(path, []byte("policies:\n  policy_sets:\n  - name: policy\n    source: local\n    path: "+policyDir+"\n"), 0600))
+	initialCfg, err := (&config.ParserValidator{}).ParseGlobalCfg(path, globalCfg)
+	Ok(t, err)
+
+	store := valid.NewGlobalCfgStore(initialCfg)
+	scope := tally.NewTestScope("test", nil)
+	reloader := config.NewGlobalCfgReloader(path, globalCfg, store, logging.NewNoopLogger(t), scope)
+	success := func() int64 {
+		if c, ok := scope.Snapshot().Counters()["test.repo_config_reload.execution_success+"]; ok {
+			return c.Value()
+		}
+		return 0
+	}
+
+	reloader.Run()
+	Equals(t, int64(0), success())
+
+	Ok(t, os.WriteFile(filepath.Join(policyDir, "policy.rego"), []byte("package main\n\ndeny[msg] { false }\n"), 0600))
+	reloader.Run()
+	Equals(t, int64(1), success())
+}
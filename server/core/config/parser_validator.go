@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -20,7 +21,12 @@ import (
 
 // ParserValidator parses and validates server-side repo config files and
 // repo-level atlantis.yaml files.
-type ParserValidator struct{}
+type ParserValidator struct {
+	// InstanceName is the name of this Atlantis instance when several share
+	// repos. Projects of repo configs that are handled by other instances
+	// are left out.
+	InstanceName string
+}
 
 // HasRepoCfg returns true if there is a repo config (atlantis.yaml) file
 // for the repo at absRepoDir.
@@ -40,11 +46,26 @@ func (p *ParserValidator) HasRepoCfg(absRepoDir, repoConfigFile string) (bool, e
 	return err == nil, err
 }
 
+// FindRepoCfg returns the first of repoConfigFiles that exists in absRepoDir
+// and true. If none of them exist, it returns the first one and false.
+func (p *ParserValidator) FindRepoCfg(absRepoDir string, repoConfigFiles []string) (string, bool, error) {
+	for _, repoConfigFile := range repoConfigFiles {
+		exists, err := p.HasRepoCfg(absRepoDir, repoConfigFile)
+		if err != nil || exists {
+			return repoConfigFile, exists, err
+		}
+	}
+	return repoConfigFiles[0], false, nil
+}
+
 // ParseRepoCfg returns the parsed and validated atlantis.yaml config for the
 // repo at absRepoDir.
 // If there was no config file, it will return an os.IsNotExist(error).
 func (p *ParserValidator) ParseRepoCfg(absRepoDir string, globalCfg valid.GlobalCfg, repoID string, branch string) (valid.RepoCfg, error) {
-	repoConfigFile := globalCfg.RepoConfigFile(repoID)
+	repoConfigFile, _, err := p.FindRepoCfg(absRepoDir, globalCfg.RepoConfigFiles(repoID))
+	if err != nil {
+		return valid.RepoCfg{}, err
+	}
 	configFile := p.repoCfgPath(absRepoDir, repoConfigFile)
 	configData, err := os.ReadFile(configFile) // nolint: gosec
 
@@ -88,11 +109,19 @@ func (p *ParserValidator) ParseRepoCfgData(repoCfgData []byte, globalCfg valid.G
 	}
 	validConfig.Projects = validConfig.Projects[:i]
 
+	// Only keep the projects handled by this instance.
+	validConfig.Projects = slices.DeleteFunc(validConfig.Projects, func(project valid.Project) bool {
+		return !project.HandledBy(p.InstanceName)
+	})
+
 	// We do the project name validation after we get the valid config because
 	// we need the defaults of dir and workspace to be populated.
 	if err := p.validateProjectNames(validConfig); err != nil {
 		return valid.RepoCfg{}, err
 	}
+	if err := validConfig.ValidateCanaries(); err != nil {
+		return valid.RepoCfg{}, err
+	}
 	if validConfig.Version == 2 {
 		// The only difference between v2 and v3 is how we parse custom run
 		// commands.
@@ -113,8 +142,38 @@ func (p *ParserValidator) ParseGlobalCfg(configFile string, defaultCfg valid.Glo
 	if err != nil {
 		return valid.GlobalCfg{}, fmt.Errorf("unable to read %s file: %w", configFile, err)
 	}
+	return p.parseGlobalCfgData(configFile, configData, defaultCfg)
+}
+
+// parseGlobalCfgData parses the content configData of the server-side repo
+// config file configFile.
+func (p *ParserValidator) parseGlobalCfgData(configFile string, configData []byte, defaultCfg valid.GlobalCfg) (valid.GlobalCfg, error) {
+	rawCfg, err := p.decodeGlobalCfg(configFile, configData)
+	if err != nil {
+		return valid.GlobalCfg{}, err
+	}
+	return p.validateRawGlobalCfg(rawCfg, defaultCfg, "yaml")
+}
+
+// ParseRawGlobalCfg returns the unvalidated server-side repo config file at
+// configFile so it can be extended before it's validated with
+// ValidateRawGlobalCfg.
+func (p *ParserValidator) ParseRawGlobalCfg(configFile string) (raw.GlobalCfg, error) {
+	configData, err := os.ReadFile(configFile) // nolint: gosec
+	if err != nil {
+		return raw.GlobalCfg{}, fmt.Errorf("unable to read %s file: %w", configFile, err)
+	}
+	return p.decodeGlobalCfg(configFile, configData)
+}
+
+// ValidateRawGlobalCfg validates rawCfg and merges defaultCfg into it.
+func (p *ParserValidator) ValidateRawGlobalCfg(rawCfg raw.GlobalCfg, defaultCfg valid.GlobalCfg) (valid.GlobalCfg, error) {
+	return p.validateRawGlobalCfg(rawCfg, defaultCfg, "yaml")
+}
+
+func (p *ParserValidator) decodeGlobalCfg(configFile string, configData []byte) (raw.GlobalCfg, error) {
 	if len(configData) == 0 {
-		return valid.GlobalCfg{}, fmt.Errorf("file %s was empty", configFile)
+		return raw.GlobalCfg{}, fmt.Errorf("file %s was empty", configFile)
 	}
 
 	var rawCfg raw.GlobalCfg
@@ -122,12 +181,11 @@ func (p *ParserValidator) ParseGlobalCfg(configFile string, defaultCfg valid.Glo
 	decoder := yaml.NewDecoder(bytes.NewReader(configData))
 	decoder.KnownFields(true)
 
-	err = decoder.Decode(&rawCfg)
+	err := decoder.Decode(&rawCfg)
 	if err != nil && !errors.Is(err, io.EOF) {
-		return valid.GlobalCfg{}, err
+		return raw.GlobalCfg{}, err
 	}
-
-	return p.validateRawGlobalCfg(rawCfg, defaultCfg, "yaml")
+	return rawCfg, nil
 }
 
 // ParseGlobalCfgJSON parses a json string cfgJSON into global config.
@@ -9,11 +9,13 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/core/config"
 	"github.com/runatlantis/atlantis/server/core/config/raw"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -49,6 +51,38 @@ func TestHasRepoCfg_InvalidFileExtension(t *testing.T) {
 	ErrContains(t, "found \"atlantis.yml\" as config file; rename using the .yaml extension", err)
 }
 
+func TestFindRepoCfg(t *testing.T) {
+	tmpDir := t.TempDir()
+	Ok(t, os.MkdirAll(filepath.Join(tmpDir, ".config"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(tmpDir, ".config", "atlantis.yaml"), nil, 0600))
+	Ok(t, os.WriteFile(filepath.Join(tmpDir, "atlantis.yaml"), nil, 0600))
+
+	r := config.ParserValidator{}
+	file, exists, err := r.FindRepoCfg(tmpDir, []string{".atlantis/atlantis.yaml", ".config/atlantis.yaml", "atlantis.yaml"})
+	Ok(t, err)
+	Equals(t, true, exists)
+	Equals(t, ".config/atlantis.yaml", file)
+
+	file, exists, err = r.FindRepoCfg(tmpDir, []string{".atlantis/atlantis.yaml", "other.yaml"})
+	Ok(t, err)
+	Equals(t, false, exists)
+	Equals(t, ".atlantis/atlantis.yaml", file)
+}
+
+func TestParseRepoCfg_RepoConfigFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	Ok(t, os.MkdirAll(filepath.Join(tmpDir, ".atlantis"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(tmpDir, ".atlantis", "atlantis.yaml"), []byte("version: 3\nprojects:\n- dir: nested\n"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(tmpDir, "atlantis.yaml"), []byte("version: 3\nprojects:\n- dir: root\n"), 0600))
+
+	args := globalCfgArgs
+	args.RepoConfigFiles = []string{".atlantis/atlantis.yaml", "atlantis.yaml"}
+	r := config.ParserValidator{}
+	repoCfg, err := r.ParseRepoCfg(tmpDir, valid.NewGlobalCfgFromArgs(args), "", "")
+	Ok(t, err)
+	Equals(t, "nested", repoCfg.Projects[0].Dir)
+}
+
 func TestParseRepoCfg_DirDoesNotExist(t *testing.T) {
 	r := config.ParserValidator{}
 	_, err := r.ParseRepoCfg("/not/exist", globalCfg, "", "")
@@ -753,6 +787,20 @@ projects:
 				Workflows: map[string]valid.Workflow{},
 			},
 		},
+		{
+			description: "two canaries in the same dir",
+			input: `
+version: 3
+projects:
+- dir: .
+  workspace: us-east-1
+  canary: {}
+- dir: .
+  workspace: eu-west-1
+  canary:
+    soak_time: 1h`,
+			expErr: "dir \".\" has more than one canary: the projects of workspace \"us-east-1\" and of workspace \"eu-west-1\"",
+		},
 		{
 			description: "if steps are set then we parse them properly",
 			input: `
@@ -1170,6 +1218,42 @@ workflows:
 	ErrEquals(t, "repo config not allowed to set 'workflow' key: server-side config needs 'allowed_overrides: [workflow]'", err)
 }
 
+func TestParseRepoCfg_InstanceName(t *testing.T) {
+	repoCfg := `
+version: 3
+projects:
+- name: prod
+  dir: prod
+  instances: [prod]
+- name: sandbox
+  dir: sandbox
+  instances: [sandbox]
+- name: shared
+  dir: shared`
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowAllRepoSettings: true})
+
+	cases := []struct {
+		instanceName string
+		expProjects  []string
+	}{
+		{"", []string{"prod", "sandbox", "shared"}},
+		{"prod", []string{"prod", "shared"}},
+		{"sandbox", []string{"sandbox", "shared"}},
+	}
+	for _, c := range cases {
+		t.Run(c.instanceName, func(t *testing.T) {
+			p := config.ParserValidator{InstanceName: c.instanceName}
+			cfg, err := p.ParseRepoCfgData([]byte(repoCfg), globalCfg, "repo_id", "main")
+			Ok(t, err)
+			var names []string
+			for _, project := range cfg.Projects {
+				names = append(names, project.GetName())
+			}
+			Equals(t, c.expProjects, names)
+		})
+	}
+}
+
 func TestParseGlobalCfg_NotExist(t *testing.T) {
 	r := config.ParserValidator{}
 	globalCfgArgs := valid.GlobalCfgArgs{}
@@ -1313,7 +1397,25 @@ func TestParseGlobalCfg(t *testing.T) {
 			input: `repos:
 - id: /.*/
   allowed_overrides: [invalid]`,
-			expErr: "repos: (0: (allowed_overrides: \"invalid\" is not a valid override, only \"plan_requirements\", \"apply_requirements\", \"import_requirements\", \"workflow\", \"delete_source_branch_on_merge\", \"repo_locking\", \"repo_locks\", \"policy_check\", \"custom_policy_check\", and \"silence_pr_comments\" are supported.).).",
+			expErr: "repos: (0: (allowed_overrides: \"invalid\" is not a valid override, only \"plan_requirements\", \"apply_requirements\", \"import_requirements\", \"workflow\", \"delete_source_branch_on_merge\", \"repo_locking\", \"repo_locks\", \"policy_check\", \"custom_policy_check\", \"silence_pr_comments\", \"notifications\", \"extra_args\", \"preview_environment\", \"terraform_distribution\", and \"terraform_version\" are supported.).).",
+		},
+		"invalid terraform_distribution": {
+			input: `repos:
+- id: /.*/
+  terraform_distribution: terraformx`,
+			expErr: "repos: (0: (terraform_distribution: 'terraformx' is not a valid terraform_distribution, only 'terraform' and 'opentofu' are supported.).).",
+		},
+		"invalid terraform_version": {
+			input: `repos:
+- id: /.*/
+  terraform_version: latest`,
+			expErr: "repos: (0: (terraform_version: version \"latest\" could not be parsed: Malformed version: latest.).).",
+		},
+		"invalid github_status_mode": {
+			input: `repos:
+- id: /.*/
+  github_status_mode: check_runs`,
+			expErr: "repos: (0: (github_status_mode: \"check_runs\" is not supported, only statuses, checks, both are supported.).).",
 		},
 		"invalid plan_requirement": {
 			input: `repos:
@@ -1327,6 +1429,63 @@ func TestParseGlobalCfg(t *testing.T) {
   apply_requirements: [invalid]`,
 			expErr: "repos: (0: (apply_requirements: \"invalid\" is not a valid apply_requirement, only \"approved\", \"mergeable\" and \"undiverged\" are supported.).).",
 		},
+		"undefined apply requirement plugin": {
+			input: `repos:
+- id: /.*/
+  apply_requirements: [approved, plugin:cab-approved]`,
+			expErr: "apply requirement plugin \"cab-approved\" is not defined",
+		},
+		"invalid apply requirement plugin": {
+			input: `apply_requirement_plugins:
+  cab-approved:
+    command: /bin/check-cab
+    url: https://cab.example.com/check
+repos:
+- id: /.*/
+  apply_requirements: [plugin:cab-approved]`,
+			expErr: "apply_requirement_plugins: (cab-approved: (command: only one of command and url can be set.).).",
+		},
+		"apply requirement plugins": {
+			input: `apply_requirement_plugins:
+  cab-approved:
+    url: https://cab.example.com/check
+    headers:
+      Authorization: Bearer token
+    timeout: 10s
+  freeze:
+    command: /bin/check-freeze
+repos:
+- id: /.*/
+  apply_requirements: [approved, plugin:cab-approved, plugin:freeze]`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						IDRegex:           regexp.MustCompile(".*"),
+						ApplyRequirements: []string{"approved", "plugin:cab-approved", "plugin:freeze"},
+					},
+				},
+				Workflows: map[string]valid.Workflow{
+					"default": defaultCfg.Workflows["default"],
+				},
+				ApplyRequirementPlugins: map[string]valid.ApplyRequirementPlugin{
+					"cab-approved": {
+						Name:    "cab-approved",
+						URL:     "https://cab.example.com/check",
+						Headers: map[string]string{"Authorization": "Bearer token"},
+						Timeout: 10 * time.Second,
+					},
+					"freeze": {
+						Name:    "freeze",
+						Command: "/bin/check-freeze",
+						Timeout: valid.DefaultApplyRequirementPluginTimeout,
+					},
+				},
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
 		"invalid import_requirement": {
 			input: `repos:
 - id: /.*/
@@ -1720,6 +1879,69 @@ workflows:
 }
 
 // Test that if we pass in JSON strings everything should parse fine.
+func TestParseGlobalCfg_CaptureGroups(t *testing.T) {
+	cfg := `
+repos:
+- id: /github.com/org/team-(?P<team>[a-z]+)-infra/
+  workflow: $team
+  allowed_workflows: [$team]
+  allowed_overrides: [workflow]
+  repo_config_file: teams/${team}.yaml
+workflows:
+  platform:
+    plan:
+      steps: [init]
+  data:
+    plan:
+      steps: [plan]
+`
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "conf.yaml")
+	Ok(t, os.WriteFile(path, []byte(cfg), 0600))
+
+	r := config.ParserValidator{}
+	globalCfg, err := r.ParseGlobalCfg(path, valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}))
+	Ok(t, err)
+
+	logger := logging.NewNoopLogger(t)
+	for team, expSteps := range map[string]string{"platform": "init", "data": "plan"} {
+		repoID := "github.com/org/team-" + team + "-infra"
+		merged := globalCfg.MergeProjectCfg(logger, repoID, valid.Project{Dir: "."}, valid.RepoCfg{})
+		Equals(t, team, merged.Workflow.Name)
+		Equals(t, expSteps, merged.Workflow.Plan.Steps[0].StepName)
+		Equals(t, "teams/"+team+".yaml", globalCfg.RepoConfigFile(repoID))
+	}
+
+	// Workflows named after teams without a workflow fall back to the default.
+	merged := globalCfg.MergeProjectCfg(logger, "github.com/org/team-other-infra", valid.Project{Dir: "."}, valid.RepoCfg{})
+	Equals(t, valid.DefaultWorkflowName, merged.Workflow.Name)
+
+	// The expanded allowed_workflows is used to validate repo configs.
+	for workflow, expErr := range map[string]string{"platform": "", "data": "workflow 'data' is not allowed for this repo"} {
+		err = globalCfg.ValidateRepoCfg(valid.RepoCfg{Projects: []valid.Project{{Dir: ".", WorkflowName: &workflow}}}, "github.com/org/team-platform-infra")
+		if expErr == "" {
+			Ok(t, err)
+		} else {
+			ErrEquals(t, expErr, err)
+		}
+	}
+}
+
+func TestParseGlobalCfg_CaptureGroupsRequireRegexID(t *testing.T) {
+	cfg := `
+repos:
+- id: github.com/org/repo
+  workflow: $team
+`
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "conf.yaml")
+	Ok(t, os.WriteFile(path, []byte(cfg), 0600))
+
+	r := config.ParserValidator{}
+	_, err := r.ParseGlobalCfg(path, valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}))
+	ErrContains(t, `"$team" references a capture group but id "github.com/org/repo" isn't a regex`, err)
+}
+
 func TestParserValidator_ParseGlobalCfgJSON(t *testing.T) {
 	customWorkflow := valid.Workflow{
 		Name: "custom",
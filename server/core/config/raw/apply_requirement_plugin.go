@@ -0,0 +1,86 @@
+package raw
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// ApplyRequirementPlugin is the raw schema of an apply requirement plugin.
+type ApplyRequirementPlugin struct {
+	Command string            `yaml:"command,omitempty" json:"command,omitempty"`
+	URL     string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Timeout string            `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+func (p ApplyRequirementPlugin) Validate() error {
+	commandValid := func(value interface{}) error {
+		command := value.(string)
+		if command == "" && p.URL == "" {
+			return errors.New("command or url must be set")
+		}
+		if command != "" && p.URL != "" {
+			return errors.New("only one of command and url can be set")
+		}
+		return nil
+	}
+	urlValid := func(value interface{}) error {
+		rawURL := value.(string)
+		if rawURL == "" {
+			return nil
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.New("must be an http or https URL")
+		}
+		return nil
+	}
+	headersValid := func(value interface{}) error {
+		if len(value.(map[string]string)) > 0 && p.URL == "" {
+			return errors.New("can only be set with url")
+		}
+		return nil
+	}
+	timeoutValid := func(value interface{}) error {
+		timeout := value.(string)
+		if timeout == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Command, validation.By(commandValid)),
+		validation.Field(&p.URL, validation.By(urlValid)),
+		validation.Field(&p.Headers, validation.By(headersValid)),
+		validation.Field(&p.Timeout, validation.By(timeoutValid)),
+	)
+}
+
+func (p ApplyRequirementPlugin) ToValid(name string) valid.ApplyRequirementPlugin {
+	v := valid.ApplyRequirementPlugin{
+		Name:    name,
+		Command: p.Command,
+		URL:     p.URL,
+		Headers: p.Headers,
+		Timeout: valid.DefaultApplyRequirementPluginTimeout,
+	}
+	// We've already validated the timeout so the error can be ignored.
+	if timeout, err := time.ParseDuration(p.Timeout); err == nil {
+		v.Timeout = timeout
+	}
+	return v
+}
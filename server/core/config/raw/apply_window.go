@@ -0,0 +1,27 @@
+package raw
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+type ApplyWindow struct {
+	Schedule string `yaml:"schedule" json:"schedule"`
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+}
+
+func (a ApplyWindow) Validate() error {
+	scheduleValid := func(value interface{}) error {
+		_, err := valid.NewApplyWindow(a.Schedule, a.Timezone)
+		return err
+	}
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Schedule, validation.Required, validation.By(scheduleValid)),
+	)
+}
+
+func (a ApplyWindow) ToValid() valid.ApplyWindow {
+	// Safe to ignore the error because we test it in Validate().
+	w, _ := valid.NewApplyWindow(a.Schedule, a.Timezone)
+	return w
+}
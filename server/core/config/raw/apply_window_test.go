@@ -0,0 +1,57 @@
+package raw_test
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestApplyWindow_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.ApplyWindow
+		expErr      string
+	}{
+		{
+			description: "business hours",
+			input:       raw.ApplyWindow{Schedule: "* 9-16 * * 1-5", Timezone: "Europe/Berlin"},
+		},
+		{
+			description: "steps and lists",
+			input:       raw.ApplyWindow{Schedule: "*/15 0,12 1-15/2 * 0"},
+		},
+		{
+			description: "schedule empty",
+			input:       raw.ApplyWindow{},
+			expErr:      "schedule: cannot be blank.",
+		},
+		{
+			description: "too few fields",
+			input:       raw.ApplyWindow{Schedule: "* 9-16 *"},
+			expErr:      "schedule: schedule \"* 9-16 *\" must have 5 fields: minute hour day-of-month month day-of-week.",
+		},
+		{
+			description: "out of range",
+			input:       raw.ApplyWindow{Schedule: "* 9-24 * * *"},
+			expErr:      "schedule: schedule \"* 9-24 * * *\": \"9-24\" is out of range 0-23.",
+		},
+		{
+			description: "invalid timezone",
+			input:       raw.ApplyWindow{Schedule: "* * * * *", Timezone: "Mars/Olympus"},
+			expErr:      "schedule: invalid timezone \"Mars/Olympus\": unknown time zone Mars/Olympus.",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
+		})
+	}
+}
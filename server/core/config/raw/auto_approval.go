@@ -0,0 +1,89 @@
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/utils"
+)
+
+type AutoApproval struct {
+	Rules      []AutoApprovalRule `yaml:"rules" json:"rules"`
+	ApplyAfter string             `yaml:"apply_after,omitempty" json:"apply_after,omitempty"`
+}
+
+type AutoApprovalRule struct {
+	Name          string   `yaml:"name" json:"name"`
+	Actions       []string `yaml:"actions,omitempty" json:"actions,omitempty"`
+	ResourceTypes []string `yaml:"resource_types,omitempty" json:"resource_types,omitempty"`
+	OnlyTags      bool     `yaml:"only_tags,omitempty" json:"only_tags,omitempty"`
+}
+
+func (a AutoApproval) ToValid() *valid.AutoApproval {
+	v := valid.AutoApproval{}
+	for _, rule := range a.Rules {
+		v.Rules = append(v.Rules, valid.AutoApprovalRule{
+			Name:          rule.Name,
+			Actions:       rule.Actions,
+			ResourceTypes: rule.ResourceTypes,
+			OnlyTags:      rule.OnlyTags,
+		})
+	}
+	if a.ApplyAfter != "" {
+		// Safe to ignore the error because we test it in Validate().
+		v.ApplyAfter, _ = time.ParseDuration(a.ApplyAfter)
+	}
+	return &v
+}
+
+func (a AutoApproval) Validate() error {
+	rulesValid := func(value interface{}) error {
+		names := make(map[string]bool)
+		for i, rule := range value.([]AutoApprovalRule) {
+			if rule.Name == "" {
+				return fmt.Errorf("rule %d: name is required", i)
+			}
+			if names[rule.Name] {
+				return fmt.Errorf("rule %d: name %q is used by another rule", i, rule.Name)
+			}
+			names[rule.Name] = true
+			for _, action := range rule.Actions {
+				if !utils.SlicesContains(valid.RiskActions, action) {
+					return fmt.Errorf("rule %d: %q is not a valid action, only %s are supported", i, action, strings.Join(valid.RiskActions, ", "))
+				}
+				if rule.OnlyTags && action != valid.RiskActionUpdate {
+					return fmt.Errorf("rule %d: only_tags only matches updates, not %s", i, action)
+				}
+			}
+			for _, pattern := range rule.ResourceTypes {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("rule %d: %q is not a valid pattern: %w", i, pattern, err)
+				}
+			}
+		}
+		return nil
+	}
+	applyAfterValid := func(value interface{}) error {
+		applyAfter := value.(string)
+		if applyAfter == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(applyAfter)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Rules, validation.Required, validation.By(rulesValid)),
+		validation.Field(&a.ApplyAfter, validation.By(applyAfterValid)),
+	)
+}
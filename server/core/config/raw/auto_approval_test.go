@@ -0,0 +1,100 @@
+package raw_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAutoApproval_UnmarshalYAML(t *testing.T) {
+	var a raw.AutoApproval
+	Ok(t, unmarshalString(`
+rules:
+- name: dns
+  resource_types: ["aws_route53_record"]
+- name: tags
+  actions: [update]
+  only_tags: true
+apply_after: 1h
+`, &a))
+	Equals(t, raw.AutoApproval{
+		Rules: []raw.AutoApprovalRule{
+			{Name: "dns", ResourceTypes: []string{"aws_route53_record"}},
+			{Name: "tags", Actions: []string{"update"}, OnlyTags: true},
+		},
+		ApplyAfter: "1h",
+	}, a)
+}
+
+func TestAutoApproval_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.AutoApproval
+		errContains *string
+	}{
+		{
+			description: "valid",
+			input: raw.AutoApproval{
+				Rules:      []raw.AutoApprovalRule{{Name: "tags", Actions: []string{"update"}, OnlyTags: true}},
+				ApplyAfter: "30m",
+			},
+		},
+		{
+			description: "no rules",
+			input:       raw.AutoApproval{},
+			errContains: String("rules: cannot be blank"),
+		},
+		{
+			description: "no name",
+			input:       raw.AutoApproval{Rules: []raw.AutoApprovalRule{{}}},
+			errContains: String("rule 0: name is required"),
+		},
+		{
+			description: "duplicate name",
+			input:       raw.AutoApproval{Rules: []raw.AutoApprovalRule{{Name: "dns"}, {Name: "dns"}}},
+			errContains: String(`rule 1: name "dns" is used by another rule`),
+		},
+		{
+			description: "invalid action",
+			input:       raw.AutoApproval{Rules: []raw.AutoApprovalRule{{Name: "dns", Actions: []string{"destroy"}}}},
+			errContains: String(`rule 0: "destroy" is not a valid action`),
+		},
+		{
+			description: "only tags with create",
+			input:       raw.AutoApproval{Rules: []raw.AutoApprovalRule{{Name: "tags", Actions: []string{"create"}, OnlyTags: true}}},
+			errContains: String("rule 0: only_tags only matches updates, not create"),
+		},
+		{
+			description: "invalid pattern",
+			input:       raw.AutoApproval{Rules: []raw.AutoApprovalRule{{Name: "dns", ResourceTypes: []string{"aws_["}}}},
+			errContains: String(`rule 0: "aws_[" is not a valid pattern`),
+		},
+		{
+			description: "negative apply_after",
+			input:       raw.AutoApproval{Rules: []raw.AutoApprovalRule{{Name: "dns"}}, ApplyAfter: "-1h"},
+			errContains: String("apply_after: must be positive"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestAutoApproval_ToValid(t *testing.T) {
+	Equals(t, &valid.AutoApproval{
+		Rules:      []valid.AutoApprovalRule{{Name: "dns", ResourceTypes: []string{"aws_route53_record"}}},
+		ApplyAfter: 90 * time.Minute,
+	}, raw.AutoApproval{
+		Rules:      []raw.AutoApprovalRule{{Name: "dns", ResourceTypes: []string{"aws_route53_record"}}},
+		ApplyAfter: "1h30m",
+	}.ToValid())
+}
@@ -13,8 +13,9 @@ import (
 var DefaultAutoDiscoverMode = valid.AutoDiscoverAutoMode
 
 type AutoDiscover struct {
-	Mode        *valid.AutoDiscoverMode `yaml:"mode,omitempty"`
-	IgnorePaths []string                `yaml:"ignore_paths,omitempty"`
+	Mode             *valid.AutoDiscoverMode `yaml:"mode,omitempty"`
+	IgnorePaths      []string                `yaml:"ignore_paths,omitempty"`
+	SharedModuleDirs []string                `yaml:"shared_module_dirs,omitempty"`
 }
 
 func (a AutoDiscover) ToValid() *valid.AutoDiscover {
@@ -27,6 +28,7 @@ func (a AutoDiscover) ToValid() *valid.AutoDiscover {
 	}
 
 	v.IgnorePaths = a.IgnorePaths
+	v.SharedModuleDirs = a.SharedModuleDirs
 
 	return &v
 }
@@ -57,6 +59,7 @@ func (a AutoDiscover) Validate() error {
 		// If a.Mode is nil, this should still pass validation.
 		validation.Field(&a.Mode, validation.In(valid.AutoDiscoverAutoMode, valid.AutoDiscoverDisabledMode, valid.AutoDiscoverEnabledMode)),
 		validation.Field(&a.IgnorePaths, validation.By(ignoreValid)),
+		validation.Field(&a.SharedModuleDirs, validation.By(ignoreValid)),
 	)
 	return res
 }
@@ -29,10 +29,13 @@ func TestAutoDiscover_UnmarshalYAML(t *testing.T) {
 mode: enabled
 ignore_paths:
   - foobar
+shared_module_dirs:
+  - vendor/**
 `,
 			exp: raw.AutoDiscover{
-				Mode:        &autoDiscoverEnabled,
-				IgnorePaths: []string{"foobar"},
+				Mode:             &autoDiscoverEnabled,
+				IgnorePaths:      []string{"foobar"},
+				SharedModuleDirs: []string{"vendor/**"},
 			},
 		},
 	}
@@ -151,6 +154,24 @@ func TestAutoDiscover_Validate(t *testing.T) {
 			},
 			errContains: String("invalid pattern: foo["),
 		},
+		{
+			description: "shared module dirs set with leading slash",
+			input: raw.AutoDiscover{
+				SharedModuleDirs: []string{
+					"/vendor",
+				},
+			},
+			errContains: String("pattern must not begin with a slash '/'"),
+		},
+		{
+			description: "shared module dirs set to valid pattern",
+			input: raw.AutoDiscover{
+				SharedModuleDirs: []string{
+					"vendor/**",
+				},
+			},
+			errContains: nil,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
@@ -186,6 +207,9 @@ func TestAutoDiscover_ToValid(t *testing.T) {
 					"foo",
 					"bar/*",
 				},
+				SharedModuleDirs: []string{
+					"vendor/**",
+				},
 			},
 			exp: &valid.AutoDiscover{
 				Mode: valid.AutoDiscoverEnabledMode,
@@ -193,6 +217,9 @@ func TestAutoDiscover_ToValid(t *testing.T) {
 					"foo",
 					"bar/*",
 				},
+				SharedModuleDirs: []string{
+					"vendor/**",
+				},
 			},
 		},
 	}
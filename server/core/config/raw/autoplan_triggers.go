@@ -0,0 +1,52 @@
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+type AutoplanTriggers struct {
+	SkipLabels        []string `yaml:"skip_labels,omitempty" json:"skip_labels,omitempty"`
+	SkipTitlePatterns []string `yaml:"skip_title_patterns,omitempty" json:"skip_title_patterns,omitempty"`
+	MaxProjects       *int     `yaml:"max_projects,omitempty" json:"max_projects,omitempty"`
+}
+
+func (a AutoplanTriggers) ToValid() *valid.AutoplanTriggers {
+	v := valid.AutoplanTriggers{
+		SkipLabels: a.SkipLabels,
+	}
+	for _, pattern := range a.SkipTitlePatterns {
+		// Patterns are checked by Validate.
+		v.SkipTitlePatterns = append(v.SkipTitlePatterns, regexp.MustCompile(pattern))
+	}
+	if a.MaxProjects != nil {
+		v.MaxProjects = *a.MaxProjects
+	}
+	return &v
+}
+
+func (a AutoplanTriggers) Validate() error {
+	patternsValid := func(value interface{}) error {
+		for _, pattern := range value.([]string) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%q is not a valid regex: %w", pattern, err)
+			}
+		}
+		return nil
+	}
+	maxProjectsValid := func(value interface{}) error {
+		maxProjects := value.(*int)
+		if maxProjects != nil && *maxProjects < 1 {
+			return errors.New("must be at least 1")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.SkipTitlePatterns, validation.By(patternsValid)),
+		validation.Field(&a.MaxProjects, validation.By(maxProjectsValid)),
+	)
+}
@@ -0,0 +1,98 @@
+package raw_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAutoplanTriggers_UnmarshalYAML(t *testing.T) {
+	maxProjects := 5
+	cases := []struct {
+		description string
+		input       string
+		exp         raw.AutoplanTriggers
+	}{
+		{
+			description: "omit unset fields",
+			input:       "",
+			exp:         raw.AutoplanTriggers{},
+		},
+		{
+			description: "all fields set",
+			input: `
+skip_labels: [no-autoplan]
+skip_title_patterns: ["^docs:"]
+max_projects: 5
+`,
+			exp: raw.AutoplanTriggers{
+				SkipLabels:        []string{"no-autoplan"},
+				SkipTitlePatterns: []string{"^docs:"},
+				MaxProjects:       &maxProjects,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var a raw.AutoplanTriggers
+			err := unmarshalString(c.input, &a)
+			Ok(t, err)
+			Equals(t, c.exp, a)
+		})
+	}
+}
+
+func TestAutoplanTriggers_Validate(t *testing.T) {
+	zero := 0
+	cases := []struct {
+		description string
+		input       raw.AutoplanTriggers
+		errContains *string
+	}{
+		{
+			description: "no fields set",
+			input:       raw.AutoplanTriggers{},
+			errContains: nil,
+		},
+		{
+			description: "invalid title pattern",
+			input: raw.AutoplanTriggers{
+				SkipTitlePatterns: []string{"docs("},
+			},
+			errContains: String(`"docs(" is not a valid regex`),
+		},
+		{
+			description: "max projects too low",
+			input: raw.AutoplanTriggers{
+				MaxProjects: &zero,
+			},
+			errContains: String("max_projects: must be at least 1"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestAutoplanTriggers_ToValid(t *testing.T) {
+	maxProjects := 5
+	Equals(t, &valid.AutoplanTriggers{
+		SkipLabels:        []string{"no-autoplan"},
+		SkipTitlePatterns: []*regexp.Regexp{regexp.MustCompile("^docs:")},
+		MaxProjects:       5,
+	}, raw.AutoplanTriggers{
+		SkipLabels:        []string{"no-autoplan"},
+		SkipTitlePatterns: []string{"^docs:"},
+		MaxProjects:       &maxProjects,
+	}.ToValid())
+}
@@ -0,0 +1,43 @@
+package raw
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// Canary is the raw schema of a project's canary config.
+type Canary struct {
+	SoakTime string `yaml:"soak_time,omitempty"`
+}
+
+func (c Canary) Validate() error {
+	soakTimeValid := func(value interface{}) error {
+		soakTime := value.(string)
+		if soakTime == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(soakTime)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.SoakTime, validation.By(soakTimeValid)),
+	)
+}
+
+func (c Canary) ToValid() valid.Canary {
+	var v valid.Canary
+	if c.SoakTime != "" {
+		// Safe to ignore the error because we test it in Validate().
+		v.SoakTime, _ = time.ParseDuration(c.SoakTime)
+	}
+	return v
+}
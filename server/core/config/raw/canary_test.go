@@ -0,0 +1,53 @@
+package raw_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCanary_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.Canary
+		errContains *string
+	}{
+		{
+			description: "no soak time",
+			input:       raw.Canary{},
+			errContains: nil,
+		},
+		{
+			description: "soak time",
+			input:       raw.Canary{SoakTime: "30m"},
+			errContains: nil,
+		},
+		{
+			description: "invalid soak time",
+			input:       raw.Canary{SoakTime: "half an hour"},
+			errContains: String("soak_time: time: invalid duration"),
+		},
+		{
+			description: "negative soak time",
+			input:       raw.Canary{SoakTime: "-1h"},
+			errContains: String("soak_time: must be positive"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestCanary_ToValid(t *testing.T) {
+	Equals(t, valid.Canary{}, raw.Canary{}.ToValid())
+	Equals(t, valid.Canary{SoakTime: 30 * time.Minute}, raw.Canary{SoakTime: "30m"}.ToValid())
+}
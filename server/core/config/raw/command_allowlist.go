@@ -0,0 +1,67 @@
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/utils"
+)
+
+// CommandAllowlistRule restricts commands run on projects to users and teams.
+type CommandAllowlistRule struct {
+	Commands []string `yaml:"commands" json:"commands"`
+	Dirs     []string `yaml:"dirs,omitempty" json:"dirs,omitempty"`
+	Users    []string `yaml:"users,omitempty" json:"users,omitempty"`
+	Teams    []string `yaml:"teams,omitempty" json:"teams,omitempty"`
+}
+
+func (r CommandAllowlistRule) Validate() error {
+	commandsValid := func(value interface{}) error {
+		for _, cmd := range value.([]string) {
+			if !utils.SlicesContains(valid.CommandAllowlistCommands, cmd) {
+				return fmt.Errorf("%q is not a supported command, only %s are supported", cmd, strings.Join(valid.CommandAllowlistCommands, ", "))
+			}
+		}
+		return nil
+	}
+	dirsValid := func(value interface{}) error {
+		for _, pattern := range value.([]string) {
+			if !doublestar.ValidatePattern(pattern) {
+				return fmt.Errorf("%q is not a valid pattern", pattern)
+			}
+		}
+		return nil
+	}
+	allowedValid := func(value interface{}) error {
+		if len(r.Users) == 0 && len(r.Teams) == 0 {
+			return errors.New("must list at least one user or team")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Commands, validation.Required, validation.By(commandsValid)),
+		validation.Field(&r.Dirs, validation.By(dirsValid)),
+		validation.Field(&r.Users, validation.By(allowedValid)),
+	)
+}
+
+func (r CommandAllowlistRule) ToValid() valid.CommandAllowlistRule {
+	return valid.CommandAllowlistRule{
+		Commands: r.Commands,
+		Dirs:     r.Dirs,
+		Allowed:  PolicyOwners{Users: r.Users, Teams: r.Teams}.ToValid(),
+	}
+}
+
+// commandAllowlistToValid converts the rules of a command allowlist.
+func commandAllowlistToValid(rules []CommandAllowlistRule) []valid.CommandAllowlistRule {
+	var v []valid.CommandAllowlistRule
+	for _, rule := range rules {
+		v = append(v, rule.ToValid())
+	}
+	return v
+}
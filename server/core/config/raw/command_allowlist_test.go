@@ -0,0 +1,42 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCommandAllowlistRule_UnmarshalYAML(t *testing.T) {
+	var r raw.CommandAllowlistRule
+	Ok(t, unmarshalString(`
+commands: [apply, state]
+dirs: [prod/**]
+users: [alice]
+teams: [sre]
+`, &r))
+	Equals(t, raw.CommandAllowlistRule{
+		Commands: []string{"apply", "state"},
+		Dirs:     []string{"prod/**"},
+		Users:    []string{"alice"},
+		Teams:    []string{"sre"},
+	}, r)
+}
+
+func TestCommandAllowlistRule_Validate(t *testing.T) {
+	Ok(t, raw.CommandAllowlistRule{Commands: []string{"apply"}, Teams: []string{"sre"}}.Validate())
+	Ok(t, raw.CommandAllowlistRule{Commands: []string{"plan", "state"}, Dirs: []string{"prod/**", "*/db"}, Users: []string{"alice"}}.Validate())
+	ErrContains(t, "commands: cannot be blank", raw.CommandAllowlistRule{Users: []string{"alice"}}.Validate())
+	ErrContains(t, `"unlock" is not a supported command`, raw.CommandAllowlistRule{Commands: []string{"unlock"}, Users: []string{"alice"}}.Validate())
+	ErrContains(t, `"prod/[" is not a valid pattern`, raw.CommandAllowlistRule{Commands: []string{"apply"}, Dirs: []string{"prod/["}, Users: []string{"alice"}}.Validate())
+	ErrContains(t, "must list at least one user or team", raw.CommandAllowlistRule{Commands: []string{"apply"}}.Validate())
+}
+
+func TestCommandAllowlistRule_ToValid(t *testing.T) {
+	Equals(t, valid.CommandAllowlistRule{
+		Commands: []string{"apply"},
+		Dirs:     []string{"prod/**"},
+		Allowed:  valid.PolicyOwners{Users: []string{"alice"}, Teams: []string{"sre"}},
+	}, raw.CommandAllowlistRule{Commands: []string{"apply"}, Dirs: []string{"prod/**"}, Users: []string{"alice"}, Teams: []string{"sre"}}.ToValid())
+}
@@ -0,0 +1,33 @@
+package raw
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+type CommentTemplates struct {
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+	Footer string `yaml:"footer,omitempty" json:"footer,omitempty"`
+}
+
+func (c CommentTemplates) ToValid() *valid.CommentTemplates {
+	return &valid.CommentTemplates{
+		Header: c.Header,
+		Footer: c.Footer,
+	}
+}
+
+func (c CommentTemplates) Validate() error {
+	if c.Header == "" && c.Footer == "" {
+		return errors.New("header or footer must be set")
+	}
+	if _, err := valid.ParseCommentTemplate("header", c.Header); err != nil {
+		return fmt.Errorf("header is not a valid template: %w", err)
+	}
+	if _, err := valid.ParseCommentTemplate("footer", c.Footer); err != nil {
+		return fmt.Errorf("footer is not a valid template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCommentTemplates_UnmarshalYAML(t *testing.T) {
+	var c raw.CommentTemplates
+	Ok(t, unmarshalString(`
+header: "Change ticket: {{ .Pull.Num }}"
+footer: "[Runbook](https://wiki.example.com/{{ .Repo.Name }})"
+`, &c))
+	Equals(t, raw.CommentTemplates{
+		Header: "Change ticket: {{ .Pull.Num }}",
+		Footer: "[Runbook](https://wiki.example.com/{{ .Repo.Name }})",
+	}, c)
+}
+
+func TestCommentTemplates_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.CommentTemplates
+		errContains *string
+	}{
+		{
+			description: "footer set",
+			input: raw.CommentTemplates{
+				Footer: `On call: {{ (fetchJSON "https://oncall.example.com").name | default "nobody" }}`,
+			},
+			errContains: nil,
+		},
+		{
+			description: "nothing set",
+			input:       raw.CommentTemplates{},
+			errContains: String("header or footer must be set"),
+		},
+		{
+			description: "invalid header",
+			input: raw.CommentTemplates{
+				Header: "{{ .Pull.Num ",
+			},
+			errContains: String("header is not a valid template"),
+		},
+		{
+			description: "unknown function",
+			input: raw.CommentTemplates{
+				Footer: "{{ oncall }}",
+			},
+			errContains: String(`footer is not a valid template: template: footer:1: function "oncall" not defined`),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestCommentTemplates_ToValid(t *testing.T) {
+	Equals(t, &valid.CommentTemplates{Header: "header", Footer: "footer"}, raw.CommentTemplates{Header: "header", Footer: "footer"}.ToValid())
+}
@@ -0,0 +1,40 @@
+package raw
+
+import (
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+type CostBudget struct {
+	MaxMonthlyIncrease *float64 `yaml:"max_monthly_increase,omitempty" json:"max_monthly_increase,omitempty"`
+	MaxPercentIncrease *float64 `yaml:"max_percent_increase,omitempty" json:"max_percent_increase,omitempty"`
+	OverrideUsers      []string `yaml:"override_users,omitempty" json:"override_users,omitempty"`
+	OverrideTeams      []string `yaml:"override_teams,omitempty" json:"override_teams,omitempty"`
+}
+
+func (c CostBudget) ToValid() *valid.CostBudget {
+	return &valid.CostBudget{
+		MaxMonthlyIncrease: c.MaxMonthlyIncrease,
+		MaxPercentIncrease: c.MaxPercentIncrease,
+		OverrideUsers:      c.OverrideUsers,
+		OverrideTeams:      c.OverrideTeams,
+	}
+}
+
+func (c CostBudget) Validate() error {
+	if c.MaxMonthlyIncrease == nil && c.MaxPercentIncrease == nil {
+		return errors.New("at least one of max_monthly_increase and max_percent_increase must be set")
+	}
+	nonNegative := func(value interface{}) error {
+		if limit := value.(*float64); limit != nil && *limit < 0 {
+			return errors.New("must not be negative")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxMonthlyIncrease, validation.By(nonNegative)),
+		validation.Field(&c.MaxPercentIncrease, validation.By(nonNegative)),
+	)
+}
@@ -0,0 +1,72 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCostBudget_UnmarshalYAML(t *testing.T) {
+	maxMonthly := 500.0
+	maxPercent := 20.0
+	var c raw.CostBudget
+	Ok(t, unmarshalString(`
+max_monthly_increase: 500
+max_percent_increase: 20
+override_users: [alice]
+override_teams: [finops]
+`, &c))
+	Equals(t, raw.CostBudget{
+		MaxMonthlyIncrease: &maxMonthly,
+		MaxPercentIncrease: &maxPercent,
+		OverrideUsers:      []string{"alice"},
+		OverrideTeams:      []string{"finops"},
+	}, c)
+}
+
+func TestCostBudget_Validate(t *testing.T) {
+	maxMonthly := 500.0
+	negative := -1.0
+	cases := []struct {
+		description string
+		input       raw.CostBudget
+		errContains *string
+	}{
+		{
+			description: "max monthly increase set",
+			input:       raw.CostBudget{MaxMonthlyIncrease: &maxMonthly},
+		},
+		{
+			description: "no max",
+			input:       raw.CostBudget{OverrideTeams: []string{"finops"}},
+			errContains: String("at least one of max_monthly_increase and max_percent_increase must be set"),
+		},
+		{
+			description: "negative max",
+			input:       raw.CostBudget{MaxPercentIncrease: &negative},
+			errContains: String("max_percent_increase: must not be negative"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestCostBudget_ToValid(t *testing.T) {
+	maxPercent := 20.0
+	Equals(t, &valid.CostBudget{
+		MaxPercentIncrease: &maxPercent,
+		OverrideUsers:      []string{"alice"},
+	}, raw.CostBudget{
+		MaxPercentIncrease: &maxPercent,
+		OverrideUsers:      []string{"alice"},
+	}.ToValid())
+}
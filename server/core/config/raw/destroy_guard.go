@@ -0,0 +1,38 @@
+package raw
+
+import (
+	"fmt"
+	"path"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+type DestroyGuard struct {
+	ResourceTypes    []string `yaml:"resource_types" json:"resource_types"`
+	RequireOtherUser *bool    `yaml:"require_other_user,omitempty" json:"require_other_user,omitempty"`
+}
+
+func (d DestroyGuard) ToValid() *valid.DestroyGuard {
+	v := valid.DestroyGuard{
+		ResourceTypes: d.ResourceTypes,
+	}
+	if d.RequireOtherUser != nil {
+		v.RequireOtherUser = *d.RequireOtherUser
+	}
+	return &v
+}
+
+func (d DestroyGuard) Validate() error {
+	patternsValid := func(value interface{}) error {
+		for _, pattern := range value.([]string) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%q is not a valid pattern: %w", pattern, err)
+			}
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.ResourceTypes, validation.Required, validation.By(patternsValid)),
+	)
+}
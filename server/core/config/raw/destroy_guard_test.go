@@ -0,0 +1,116 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDestroyGuard_UnmarshalYAML(t *testing.T) {
+	requireOtherUser := true
+	cases := []struct {
+		description string
+		input       string
+		exp         raw.DestroyGuard
+	}{
+		{
+			description: "omit unset fields",
+			input:       "",
+			exp:         raw.DestroyGuard{},
+		},
+		{
+			description: "all fields set",
+			input: `
+resource_types: [aws_db_instance, "aws_rds_*"]
+require_other_user: true
+`,
+			exp: raw.DestroyGuard{
+				ResourceTypes:    []string{"aws_db_instance", "aws_rds_*"},
+				RequireOtherUser: &requireOtherUser,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var d raw.DestroyGuard
+			err := unmarshalString(c.input, &d)
+			Ok(t, err)
+			Equals(t, c.exp, d)
+		})
+	}
+}
+
+func TestDestroyGuard_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.DestroyGuard
+		errContains *string
+	}{
+		{
+			description: "resource types set",
+			input: raw.DestroyGuard{
+				ResourceTypes: []string{"aws_db_instance", "aws_rds_*"},
+			},
+			errContains: nil,
+		},
+		{
+			description: "no resource types",
+			input:       raw.DestroyGuard{},
+			errContains: String("resource_types: cannot be blank"),
+		},
+		{
+			description: "invalid pattern",
+			input: raw.DestroyGuard{
+				ResourceTypes: []string{"aws_db_["},
+			},
+			errContains: String(`"aws_db_[" is not a valid pattern`),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestDestroyGuard_ToValid(t *testing.T) {
+	requireOtherUser := true
+	cases := []struct {
+		description string
+		input       raw.DestroyGuard
+		exp         *valid.DestroyGuard
+	}{
+		{
+			description: "defaults",
+			input: raw.DestroyGuard{
+				ResourceTypes: []string{"aws_db_instance"},
+			},
+			exp: &valid.DestroyGuard{
+				ResourceTypes: []string{"aws_db_instance"},
+			},
+		},
+		{
+			description: "require other user",
+			input: raw.DestroyGuard{
+				ResourceTypes:    []string{"aws_db_instance"},
+				RequireOtherUser: &requireOtherUser,
+			},
+			exp: &valid.DestroyGuard{
+				ResourceTypes:    []string{"aws_db_instance"},
+				RequireOtherUser: true,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, c.input.ToValid())
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package raw
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+type DestroyOnClose struct {
+	Workspaces []string `yaml:"workspaces" json:"workspaces"`
+}
+
+func (d DestroyOnClose) ToValid() *valid.DestroyOnClose {
+	return &valid.DestroyOnClose{
+		Workspaces: d.Workspaces,
+	}
+}
+
+func (d DestroyOnClose) Validate() error {
+	patternsValid := func(value interface{}) error {
+		for _, pattern := range value.([]string) {
+			// Without a placeholder the pattern would match the workspaces
+			// of every pull request, ex. default.
+			if !strings.Contains(pattern, valid.PullNumPlaceholder) && !strings.Contains(pattern, valid.HeadBranchPlaceholder) {
+				return fmt.Errorf("%q must contain %s or %s", pattern, valid.PullNumPlaceholder, valid.HeadBranchPlaceholder)
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%q is not a valid pattern: %w", pattern, err)
+			}
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Workspaces, validation.Required, validation.By(patternsValid)),
+	)
+}
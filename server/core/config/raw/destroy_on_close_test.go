@@ -0,0 +1,70 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDestroyOnClose_UnmarshalYAML(t *testing.T) {
+	var d raw.DestroyOnClose
+	Ok(t, unmarshalString(`
+workspaces: ["pr-{pull_num}", "{head_branch}-*"]
+`, &d))
+	Equals(t, raw.DestroyOnClose{Workspaces: []string{"pr-{pull_num}", "{head_branch}-*"}}, d)
+}
+
+func TestDestroyOnClose_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.DestroyOnClose
+		errContains *string
+	}{
+		{
+			description: "workspaces set",
+			input: raw.DestroyOnClose{
+				Workspaces: []string{"pr-{pull_num}", "{head_branch}-*"},
+			},
+			errContains: nil,
+		},
+		{
+			description: "no workspaces",
+			input:       raw.DestroyOnClose{},
+			errContains: String("workspaces: cannot be blank"),
+		},
+		{
+			description: "no placeholder",
+			input: raw.DestroyOnClose{
+				Workspaces: []string{"pr-*"},
+			},
+			errContains: String(`"pr-*" must contain {pull_num} or {head_branch}`),
+		},
+		{
+			description: "invalid pattern",
+			input: raw.DestroyOnClose{
+				Workspaces: []string{"pr-{pull_num}-["},
+			},
+			errContains: String(`"pr-{pull_num}-[" is not a valid pattern`),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestDestroyOnClose_ToValid(t *testing.T) {
+	d := raw.DestroyOnClose{Workspaces: []string{"pr-{pull_num}"}}.ToValid()
+	Equals(t, &valid.DestroyOnClose{Workspaces: []string{"pr-{pull_num}"}}, d)
+
+	Equals(t, true, d.Matches("pr-12", 12, "feature"))
+	Equals(t, false, d.Matches("pr-1", 12, "feature"))
+	Equals(t, false, d.Matches("default", 12, "feature"))
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation"
+	version "github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/utils"
 )
@@ -18,29 +20,55 @@ type GlobalCfg struct {
 	PolicySets PolicySets          `yaml:"policies" json:"policies"`
 	Metrics    Metrics             `yaml:"metrics" json:"metrics"`
 	TeamAuthz  TeamAuthz           `yaml:"team_authz" json:"team_authz"`
+	// PinningPolicy configures the pin_check step.
+	PinningPolicy *PinningPolicy `yaml:"pinning_policy,omitempty" json:"pinning_policy,omitempty"`
+	// ApplyRequirementPlugins are the apply requirement plugins, by name.
+	ApplyRequirementPlugins map[string]ApplyRequirementPlugin `yaml:"apply_requirement_plugins,omitempty" json:"apply_requirement_plugins,omitempty"`
 }
 
 // Repo is the raw schema for repos in the server-side repo config.
 type Repo struct {
-	ID                        string         `yaml:"id" json:"id"`
-	Branch                    string         `yaml:"branch" json:"branch"`
-	RepoConfigFile            string         `yaml:"repo_config_file" json:"repo_config_file"`
-	PlanRequirements          []string       `yaml:"plan_requirements" json:"plan_requirements"`
-	ApplyRequirements         []string       `yaml:"apply_requirements" json:"apply_requirements"`
-	ImportRequirements        []string       `yaml:"import_requirements" json:"import_requirements"`
-	PreWorkflowHooks          []WorkflowHook `yaml:"pre_workflow_hooks" json:"pre_workflow_hooks"`
-	Workflow                  *string        `yaml:"workflow,omitempty" json:"workflow,omitempty"`
-	PostWorkflowHooks         []WorkflowHook `yaml:"post_workflow_hooks" json:"post_workflow_hooks"`
-	AllowedWorkflows          []string       `yaml:"allowed_workflows,omitempty" json:"allowed_workflows,omitempty"`
-	AllowedOverrides          []string       `yaml:"allowed_overrides" json:"allowed_overrides"`
-	AllowCustomWorkflows      *bool          `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
-	DeleteSourceBranchOnMerge *bool          `yaml:"delete_source_branch_on_merge,omitempty" json:"delete_source_branch_on_merge,omitempty"`
-	RepoLocking               *bool          `yaml:"repo_locking,omitempty" json:"repo_locking,omitempty"`
-	RepoLocks                 *RepoLocks     `yaml:"repo_locks,omitempty" json:"repo_locks,omitempty"`
-	PolicyCheck               *bool          `yaml:"policy_check,omitempty" json:"policy_check,omitempty"`
-	CustomPolicyCheck         *bool          `yaml:"custom_policy_check,omitempty" json:"custom_policy_check,omitempty"`
-	AutoDiscover              *AutoDiscover  `yaml:"autodiscover,omitempty" json:"autodiscover,omitempty"`
-	SilencePRComments         []string       `yaml:"silence_pr_comments,omitempty" json:"silence_pr_comments,omitempty"`
+	ID                        string                 `yaml:"id" json:"id"`
+	Branch                    string                 `yaml:"branch" json:"branch"`
+	RepoConfigFile            string                 `yaml:"repo_config_file" json:"repo_config_file"`
+	PlanRequirements          []string               `yaml:"plan_requirements" json:"plan_requirements"`
+	ApplyRequirements         []string               `yaml:"apply_requirements" json:"apply_requirements"`
+	ImportRequirements        []string               `yaml:"import_requirements" json:"import_requirements"`
+	PreWorkflowHooks          []WorkflowHook         `yaml:"pre_workflow_hooks" json:"pre_workflow_hooks"`
+	Workflow                  *string                `yaml:"workflow,omitempty" json:"workflow,omitempty"`
+	PostWorkflowHooks         []WorkflowHook         `yaml:"post_workflow_hooks" json:"post_workflow_hooks"`
+	PostPlanHooks             []WorkflowHook         `yaml:"post_plan_hooks,omitempty" json:"post_plan_hooks,omitempty"`
+	PostApplyHooks            []WorkflowHook         `yaml:"post_apply_hooks,omitempty" json:"post_apply_hooks,omitempty"`
+	AllowedWorkflows          []string               `yaml:"allowed_workflows,omitempty" json:"allowed_workflows,omitempty"`
+	AllowedOverrides          []string               `yaml:"allowed_overrides" json:"allowed_overrides"`
+	AllowCustomWorkflows      *bool                  `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
+	DeleteSourceBranchOnMerge *bool                  `yaml:"delete_source_branch_on_merge,omitempty" json:"delete_source_branch_on_merge,omitempty"`
+	RepoLocking               *bool                  `yaml:"repo_locking,omitempty" json:"repo_locking,omitempty"`
+	RepoLocks                 *RepoLocks             `yaml:"repo_locks,omitempty" json:"repo_locks,omitempty"`
+	PolicyCheck               *bool                  `yaml:"policy_check,omitempty" json:"policy_check,omitempty"`
+	CustomPolicyCheck         *bool                  `yaml:"custom_policy_check,omitempty" json:"custom_policy_check,omitempty"`
+	AutoDiscover              *AutoDiscover          `yaml:"autodiscover,omitempty" json:"autodiscover,omitempty"`
+	SilencePRComments         []string               `yaml:"silence_pr_comments,omitempty" json:"silence_pr_comments,omitempty"`
+	AllowTarget               *bool                  `yaml:"allow_target,omitempty" json:"allow_target,omitempty"`
+	AllowStateRead            *bool                  `yaml:"allow_state_read,omitempty" json:"allow_state_read,omitempty"`
+	DestroyGuard              *DestroyGuard          `yaml:"destroy_guard,omitempty" json:"destroy_guard,omitempty"`
+	CostBudget                *CostBudget            `yaml:"cost_budget,omitempty" json:"cost_budget,omitempty"`
+	RiskScoring               *RiskScoring           `yaml:"risk_scoring,omitempty" json:"risk_scoring,omitempty"`
+	AutoApproval              *AutoApproval          `yaml:"auto_approval,omitempty" json:"auto_approval,omitempty"`
+	Rollback                  *Rollback              `yaml:"rollback,omitempty" json:"rollback,omitempty"`
+	DestroyOnClose            *DestroyOnClose        `yaml:"destroy_on_close,omitempty" json:"destroy_on_close,omitempty"`
+	CommentLayout             string                 `yaml:"comment_layout,omitempty" json:"comment_layout,omitempty"`
+	CommentTemplates          *CommentTemplates      `yaml:"comment_templates,omitempty" json:"comment_templates,omitempty"`
+	GithubStatusMode          string                 `yaml:"github_status_mode,omitempty" json:"github_status_mode,omitempty"`
+	TerraformDistribution     *string                `yaml:"terraform_distribution,omitempty" json:"terraform_distribution,omitempty"`
+	TerraformVersion          *string                `yaml:"terraform_version,omitempty" json:"terraform_version,omitempty"`
+	CodeOwners                *bool                  `yaml:"codeowners,omitempty" json:"codeowners,omitempty"`
+	SuggestRepoConfig         *bool                  `yaml:"suggest_repo_config,omitempty" json:"suggest_repo_config,omitempty"`
+	InlineReviewComments      *bool                  `yaml:"inline_review_comments,omitempty" json:"inline_review_comments,omitempty"`
+	AutoplanTriggers          *AutoplanTriggers      `yaml:"autoplan_triggers,omitempty" json:"autoplan_triggers,omitempty"`
+	CommandAllowlist          []CommandAllowlistRule `yaml:"command_allowlist,omitempty" json:"command_allowlist,omitempty"`
+	AllowedExtraArgs          []string               `yaml:"allowed_extra_args,omitempty" json:"allowed_extra_args,omitempty"`
+	DeniedExtraArgs           []string               `yaml:"denied_extra_args,omitempty" json:"denied_extra_args,omitempty"`
 }
 
 func (g GlobalCfg) Validate() error {
@@ -48,19 +76,36 @@ func (g GlobalCfg) Validate() error {
 		validation.Field(&g.Repos),
 		validation.Field(&g.Workflows),
 		validation.Field(&g.Metrics),
+		validation.Field(&g.PinningPolicy),
+		validation.Field(&g.ApplyRequirementPlugins),
 	)
 	if err != nil {
 		return err
 	}
 
+	// Check that all apply requirement plugins referenced by repos are
+	// defined.
+	for _, repo := range g.Repos {
+		for _, req := range repo.ApplyRequirements {
+			name, ok := valid.ApplyRequirementPluginName(req)
+			if !ok {
+				continue
+			}
+			if _, found := g.ApplyRequirementPlugins[name]; !found {
+				return fmt.Errorf("apply requirement plugin %q is not defined", name)
+			}
+		}
+	}
+
 	// Check that all workflows referenced by repos are actually defined.
 	for _, repo := range g.Repos {
 		if repo.Workflow == nil {
 			continue
 		}
 		name := *repo.Workflow
-		if name == valid.DefaultWorkflowName {
-			// The 'default' workflow will always be defined.
+		if name == valid.DefaultWorkflowName || repo.referencesCaptureGroup(name) {
+			// The 'default' workflow will always be defined and names
+			// referencing capture groups are looked up for each repo.
 			continue
 		}
 		found := false
@@ -81,8 +126,9 @@ func (g GlobalCfg) Validate() error {
 			continue
 		}
 		for _, name := range repo.AllowedWorkflows {
-			if name == valid.DefaultWorkflowName {
-				// The 'default' workflow will always be defined.
+			if name == valid.DefaultWorkflowName || repo.referencesCaptureGroup(name) {
+				// The 'default' workflow will always be defined and names
+				// referencing capture groups are looked up for each repo.
 				continue
 			}
 			found := false
@@ -120,6 +166,9 @@ func (g GlobalCfg) Validate() error {
 
 func (g GlobalCfg) ToValid(defaultCfg valid.GlobalCfg) valid.GlobalCfg {
 	workflows := make(map[string]valid.Workflow)
+	// Copy the default repos since the default one may be modified below and
+	// defaultCfg is reused when the config is reloaded.
+	defaultCfg.Repos = slices.Clone(defaultCfg.Repos)
 
 	// assumes: globalcfg is always initialized with one repo .*
 	globalPlanReqs := defaultCfg.Repos[0].PlanRequirements
@@ -157,15 +206,37 @@ func (g GlobalCfg) ToValid(defaultCfg valid.GlobalCfg) valid.GlobalCfg {
 	}
 	repos = append(defaultCfg.Repos, repos...)
 
+	var pinningPolicy *valid.PinningPolicy
+	if g.PinningPolicy != nil {
+		pinningPolicy = g.PinningPolicy.ToValid()
+	}
+
+	var applyRequirementPlugins map[string]valid.ApplyRequirementPlugin
+	for name, plugin := range g.ApplyRequirementPlugins {
+		if applyRequirementPlugins == nil {
+			applyRequirementPlugins = make(map[string]valid.ApplyRequirementPlugin)
+		}
+		applyRequirementPlugins[name] = plugin.ToValid(name)
+	}
+
 	return valid.GlobalCfg{
-		Repos:      repos,
-		Workflows:  workflows,
-		PolicySets: g.PolicySets.ToValid(),
-		Metrics:    g.Metrics.ToValid(),
-		TeamAuthz:  g.TeamAuthz.ToValid(),
+		Repos:         repos,
+		Workflows:     workflows,
+		PolicySets:    g.PolicySets.ToValid(),
+		Metrics:       g.Metrics.ToValid(),
+		TeamAuthz:     g.TeamAuthz.ToValid(),
+		PinningPolicy: pinningPolicy,
+
+		ApplyRequirementPlugins: applyRequirementPlugins,
 	}
 }
 
+// referencesCaptureGroup returns true if value references a capture group of
+// the repo's regex id, ex. $team or ${1}, so it's expanded for each repo.
+func (r Repo) referencesCaptureGroup(value string) bool {
+	return r.HasRegexID() && strings.Contains(value, "$")
+}
+
 // HasRegexID returns true if r is configured with a regex id instead of an
 // exact match id.
 func (r Repo) HasRegexID() bool {
@@ -206,6 +277,28 @@ func (r Repo) Validate() error {
 		return nil
 	}
 
+	// captureGroupsValid checks that values only reference capture groups
+	// when the id is a regex.
+	captureGroupsValid := func(value interface{}) error {
+		var values []string
+		switch v := value.(type) {
+		case string:
+			values = []string{v}
+		case *string:
+			if v != nil {
+				values = []string{*v}
+			}
+		case []string:
+			values = v
+		}
+		for _, v := range values {
+			if strings.Contains(v, "$") && !r.HasRegexID() {
+				return fmt.Errorf("%q references a capture group but id %q isn't a regex", v, r.ID)
+			}
+		}
+		return nil
+	}
+
 	repoConfigFileValid := func(value interface{}) error {
 		repoConfigFile := value.(string)
 		if repoConfigFile == "" {
@@ -223,8 +316,12 @@ func (r Repo) Validate() error {
 	overridesValid := func(value interface{}) error {
 		overrides := value.([]string)
 		for _, o := range overrides {
-			if o != valid.PlanRequirementsKey && o != valid.ApplyRequirementsKey && o != valid.ImportRequirementsKey && o != valid.WorkflowKey && o != valid.DeleteSourceBranchOnMergeKey && o != valid.RepoLockingKey && o != valid.RepoLocksKey && o != valid.PolicyCheckKey && o != valid.CustomPolicyCheckKey && o != valid.SilencePRCommentsKey {
-				return fmt.Errorf("%q is not a valid override, only %q, %q, %q, %q, %q, %q, %q, %q, %q, and %q are supported", o, valid.PlanRequirementsKey, valid.ApplyRequirementsKey, valid.ImportRequirementsKey, valid.WorkflowKey, valid.DeleteSourceBranchOnMergeKey, valid.RepoLockingKey, valid.RepoLocksKey, valid.PolicyCheckKey, valid.CustomPolicyCheckKey, valid.SilencePRCommentsKey)
+			if r.referencesCaptureGroup(o) {
+				// Checked once it's expanded for each repo.
+				continue
+			}
+			if o != valid.PlanRequirementsKey && o != valid.ApplyRequirementsKey && o != valid.ImportRequirementsKey && o != valid.WorkflowKey && o != valid.DeleteSourceBranchOnMergeKey && o != valid.RepoLockingKey && o != valid.RepoLocksKey && o != valid.PolicyCheckKey && o != valid.CustomPolicyCheckKey && o != valid.SilencePRCommentsKey && o != valid.NotificationsKey && o != valid.ExtraArgsKey && o != valid.PreviewEnvironmentKey && o != valid.TerraformDistributionKey && o != valid.TerraformVersionKey {
+				return fmt.Errorf("%q is not a valid override, only %q, %q, %q, %q, %q, %q, %q, %q, %q, %q, %q, %q, %q, %q, and %q are supported", o, valid.PlanRequirementsKey, valid.ApplyRequirementsKey, valid.ImportRequirementsKey, valid.WorkflowKey, valid.DeleteSourceBranchOnMergeKey, valid.RepoLockingKey, valid.RepoLocksKey, valid.PolicyCheckKey, valid.CustomPolicyCheckKey, valid.SilencePRCommentsKey, valid.NotificationsKey, valid.ExtraArgsKey, valid.PreviewEnvironmentKey, valid.TerraformDistributionKey, valid.TerraformVersionKey)
 			}
 		}
 		return nil
@@ -257,18 +354,125 @@ func (r Repo) Validate() error {
 		return nil
 	}
 
+	destroyGuardValid := func(value interface{}) error {
+		destroyGuard := value.(*DestroyGuard)
+		if destroyGuard != nil {
+			return destroyGuard.Validate()
+		}
+		return nil
+	}
+
+	autoplanTriggersValid := func(value interface{}) error {
+		autoplanTriggers := value.(*AutoplanTriggers)
+		if autoplanTriggers != nil {
+			return autoplanTriggers.Validate()
+		}
+		return nil
+	}
+
+	costBudgetValid := func(value interface{}) error {
+		costBudget := value.(*CostBudget)
+		if costBudget != nil {
+			return costBudget.Validate()
+		}
+		return nil
+	}
+
+	riskScoringValid := func(value interface{}) error {
+		riskScoring := value.(*RiskScoring)
+		if riskScoring != nil {
+			return riskScoring.Validate()
+		}
+		return nil
+	}
+
+	autoApprovalValid := func(value interface{}) error {
+		autoApproval := value.(*AutoApproval)
+		if autoApproval != nil {
+			return autoApproval.Validate()
+		}
+		return nil
+	}
+
+	rollbackValid := func(value interface{}) error {
+		rollback := value.(*Rollback)
+		if rollback != nil {
+			return rollback.Validate()
+		}
+		return nil
+	}
+
+	commentLayoutValid := func(value interface{}) error {
+		layout := value.(string)
+		if layout != "" && !utils.SlicesContains(valid.CommentLayouts, layout) {
+			return fmt.Errorf("%q is not supported, only %s are supported", layout, strings.Join(valid.CommentLayouts, ", "))
+		}
+		return nil
+	}
+
+	githubStatusModeValid := func(value interface{}) error {
+		mode := value.(string)
+		if mode != "" && !utils.SlicesContains(valid.GithubStatusModes, mode) {
+			return fmt.Errorf("%q is not supported, only %s are supported", mode, strings.Join(valid.GithubStatusModes, ", "))
+		}
+		return nil
+	}
+
+	destroyOnCloseValid := func(value interface{}) error {
+		destroyOnClose := value.(*DestroyOnClose)
+		if destroyOnClose != nil {
+			return destroyOnClose.Validate()
+		}
+		return nil
+	}
+
+	commentTemplatesValid := func(value interface{}) error {
+		commentTemplates := value.(*CommentTemplates)
+		if commentTemplates != nil {
+			return commentTemplates.Validate()
+		}
+		return nil
+	}
+
+	// extraArgsFlagsValid checks that the allowed and denied extra args are
+	// flag names without values.
+	extraArgsFlagsValid := func(value interface{}) error {
+		for _, flag := range value.([]string) {
+			if !strings.HasPrefix(flag, "-") || strings.Contains(flag, "=") {
+				return fmt.Errorf("%q must be a flag name, ex. -refresh", flag)
+			}
+		}
+		return nil
+	}
+
 	return validation.ValidateStruct(&r,
 		validation.Field(&r.ID, validation.Required, validation.By(idValid)),
 		validation.Field(&r.Branch, validation.By(branchValid)),
-		validation.Field(&r.RepoConfigFile, validation.By(repoConfigFileValid)),
-		validation.Field(&r.AllowedOverrides, validation.By(overridesValid)),
+		validation.Field(&r.RepoConfigFile, validation.By(repoConfigFileValid), validation.By(captureGroupsValid)),
+		validation.Field(&r.AllowedOverrides, validation.By(overridesValid), validation.By(captureGroupsValid)),
+		validation.Field(&r.AllowedWorkflows, validation.By(captureGroupsValid)),
 		validation.Field(&r.PlanRequirements, validation.By(validPlanReq)),
 		validation.Field(&r.ApplyRequirements, validation.By(validApplyReq)),
 		validation.Field(&r.ImportRequirements, validation.By(validImportReq)),
-		validation.Field(&r.Workflow, validation.By(workflowExists)),
+		validation.Field(&r.Workflow, validation.By(workflowExists), validation.By(captureGroupsValid)),
 		validation.Field(&r.DeleteSourceBranchOnMerge, validation.By(deleteSourceBranchOnMergeValid)),
 		validation.Field(&r.AutoDiscover, validation.By(autoDiscoverValid)),
 		validation.Field(&r.RepoLocks, validation.By(repoLocksValid)),
+		validation.Field(&r.DestroyGuard, validation.By(destroyGuardValid)),
+		validation.Field(&r.CostBudget, validation.By(costBudgetValid)),
+		validation.Field(&r.RiskScoring, validation.By(riskScoringValid)),
+		validation.Field(&r.AutoApproval, validation.By(autoApprovalValid)),
+		validation.Field(&r.Rollback, validation.By(rollbackValid)),
+		validation.Field(&r.DestroyOnClose, validation.By(destroyOnCloseValid)),
+		validation.Field(&r.CommentLayout, validation.By(commentLayoutValid)),
+		validation.Field(&r.CommentTemplates, validation.By(commentTemplatesValid)),
+		validation.Field(&r.GithubStatusMode, validation.By(githubStatusModeValid)),
+		validation.Field(&r.CommandAllowlist),
+		validation.Field(&r.AutoplanTriggers, validation.By(autoplanTriggersValid)),
+		validation.Field(&r.TerraformDistribution, validation.By(validDistribution)),
+		validation.Field(&r.TerraformVersion, validation.By(VersionValidator)),
+		validation.Field(&r.AllowedExtraArgs, validation.By(extraArgsFlagsValid)),
+		validation.Field(&r.DeniedExtraArgs, validation.By(extraArgsFlagsValid)),
 	)
 }
 
@@ -291,7 +495,10 @@ func (r Repo) ToValid(workflows map[string]valid.Workflow, globalPlanReqs []stri
 	}
 
 	var workflow *valid.Workflow
-	if r.Workflow != nil {
+	var workflowTemplate string
+	if r.Workflow != nil && r.referencesCaptureGroup(*r.Workflow) {
+		workflowTemplate = *r.Workflow
+	} else if r.Workflow != nil {
 		// This key is guaranteed to exist because we test for it in
 		// ParserValidator.validateRepoWorkflows.
 		ptr := workflows[*r.Workflow]
@@ -312,6 +519,16 @@ func (r Repo) ToValid(workflows map[string]valid.Workflow, globalPlanReqs []stri
 		}
 	}
 
+	var postPlanHooks []*valid.WorkflowHook
+	for _, hook := range r.PostPlanHooks {
+		postPlanHooks = append(postPlanHooks, hook.ToValid())
+	}
+
+	var postApplyHooks []*valid.WorkflowHook
+	for _, hook := range r.PostApplyHooks {
+		postApplyHooks = append(postApplyHooks, hook.ToValid())
+	}
+
 	var mergedPlanReqs []string
 	mergedPlanReqs = append(mergedPlanReqs, r.PlanRequirements...)
 	var mergedApplyReqs []string
@@ -373,6 +590,52 @@ OuterGlobalImportReqs:
 		repoLocks = r.RepoLocks.ToValid()
 	}
 
+	var destroyGuard *valid.DestroyGuard
+	if r.DestroyGuard != nil {
+		destroyGuard = r.DestroyGuard.ToValid()
+	}
+
+	var autoplanTriggers *valid.AutoplanTriggers
+	if r.AutoplanTriggers != nil {
+		autoplanTriggers = r.AutoplanTriggers.ToValid()
+	}
+
+	var costBudget *valid.CostBudget
+	if r.CostBudget != nil {
+		costBudget = r.CostBudget.ToValid()
+	}
+
+	var riskScoring *valid.RiskScoring
+	if r.RiskScoring != nil {
+		riskScoring = r.RiskScoring.ToValid()
+	}
+
+	var autoApproval *valid.AutoApproval
+	if r.AutoApproval != nil {
+		autoApproval = r.AutoApproval.ToValid()
+	}
+
+	var rollback *valid.Rollback
+	if r.Rollback != nil {
+		rollback = r.Rollback.ToValid()
+	}
+
+	var destroyOnClose *valid.DestroyOnClose
+	if r.DestroyOnClose != nil {
+		destroyOnClose = r.DestroyOnClose.ToValid()
+	}
+
+	var commentTemplates *valid.CommentTemplates
+	if r.CommentTemplates != nil {
+		commentTemplates = r.CommentTemplates.ToValid()
+	}
+
+	var terraformVersion *version.Version
+	if r.TerraformVersion != nil {
+		// Safe to ignore the error because we test it in Validate().
+		terraformVersion, _ = version.NewVersion(*r.TerraformVersion)
+	}
+
 	return valid.Repo{
 		ID:                        id,
 		IDRegex:                   idRegex,
@@ -384,6 +647,8 @@ OuterGlobalImportReqs:
 		PreWorkflowHooks:          preWorkflowHooks,
 		Workflow:                  workflow,
 		PostWorkflowHooks:         postWorkflowHooks,
+		PostPlanHooks:             postPlanHooks,
+		PostApplyHooks:            postApplyHooks,
 		AllowedWorkflows:          r.AllowedWorkflows,
 		AllowedOverrides:          r.AllowedOverrides,
 		AllowCustomWorkflows:      r.AllowCustomWorkflows,
@@ -394,5 +659,26 @@ OuterGlobalImportReqs:
 		CustomPolicyCheck:         r.CustomPolicyCheck,
 		AutoDiscover:              autoDiscover,
 		SilencePRComments:         r.SilencePRComments,
+		AllowTarget:               r.AllowTarget,
+		AllowStateRead:            r.AllowStateRead,
+		DestroyGuard:              destroyGuard,
+		CostBudget:                costBudget,
+		RiskScoring:               riskScoring,
+		AutoApproval:              autoApproval,
+		Rollback:                  rollback,
+		DestroyOnClose:            destroyOnClose,
+		CommentLayout:             r.CommentLayout,
+		CommentTemplates:          commentTemplates,
+		GithubStatusMode:          r.GithubStatusMode,
+		TerraformDistribution:     r.TerraformDistribution,
+		TerraformVersion:          terraformVersion,
+		CodeOwners:                r.CodeOwners,
+		SuggestRepoConfig:         r.SuggestRepoConfig,
+		InlineReviewComments:      r.InlineReviewComments,
+		AutoplanTriggers:          autoplanTriggers,
+		CommandAllowlist:          commandAllowlistToValid(r.CommandAllowlist),
+		AllowedExtraArgs:          r.AllowedExtraArgs,
+		DeniedExtraArgs:           r.DeniedExtraArgs,
+		WorkflowTemplate:          workflowTemplate,
 	}
 }
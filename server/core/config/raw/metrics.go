@@ -1,6 +1,11 @@
 package raw
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
@@ -9,6 +14,61 @@ import (
 type Metrics struct {
 	Statsd     *Statsd     `yaml:"statsd" json:"statsd"`
 	Prometheus *Prometheus `yaml:"prometheus" json:"prometheus"`
+	OTLP       *OTLP       `yaml:"otlp" json:"otlp"`
+	Datadog    *Datadog    `yaml:"datadog" json:"datadog"`
+	// ProjectLabels are the keys of the project labels added as tags to the
+	// metrics of project commands, ex. tier.
+	ProjectLabels []string `yaml:"project_labels" json:"project_labels"`
+}
+
+// Datadog configures sending metrics with tags to a DogStatsD agent.
+type Datadog struct {
+	Host string            `yaml:"host" json:"host"`
+	Port string            `yaml:"port" json:"port"`
+	Tags map[string]string `yaml:"tags" json:"tags"`
+}
+
+func (d *Datadog) Validate() error {
+	return validation.ValidateStruct(d,
+		validation.Field(&d.Host, validation.Required),
+		validation.Field(&d.Port, validation.Required),
+		validation.Field(&d.Host, is.Host),
+		validation.Field(&d.Port, is.Int))
+}
+
+// OTLP configures exporting metrics to an OpenTelemetry collector with
+// OTLP/HTTP.
+type OTLP struct {
+	Endpoint string            `yaml:"endpoint" json:"endpoint"`
+	Headers  map[string]string `yaml:"headers" json:"headers"`
+	Interval string            `yaml:"interval" json:"interval"`
+}
+
+func (o *OTLP) Validate() error {
+	return validation.ValidateStruct(o,
+		validation.Field(&o.Endpoint, validation.Required, validation.By(func(value interface{}) error {
+			// is.RequestURL accepts host:port since the host parses as a scheme.
+			u, err := url.Parse(value.(string))
+			if err != nil {
+				return err
+			}
+			if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.New("must be an http or https URL")
+			}
+			return nil
+		})),
+		validation.Field(&o.Interval, validation.By(func(value interface{}) error {
+			if interval := value.(string); interval != "" {
+				d, err := time.ParseDuration(interval)
+				if err != nil {
+					return err
+				}
+				if d <= 0 {
+					return errors.New("must be positive")
+				}
+			}
+			return nil
+		})))
 }
 
 type Prometheus struct {
@@ -36,11 +96,29 @@ func (m Metrics) Validate() error {
 	res := validation.ValidateStruct(&m,
 		validation.Field(&m.Statsd, validation.NilOrNotEmpty),
 		validation.Field(&m.Prometheus, validation.NilOrNotEmpty),
+		validation.Field(&m.OTLP, validation.NilOrNotEmpty),
+		validation.Field(&m.Datadog, validation.NilOrNotEmpty),
+		validation.Field(&m.ProjectLabels, validation.By(func(value interface{}) error {
+			for _, key := range value.([]string) {
+				if !valid.LabelRegex.MatchString(key) {
+					return fmt.Errorf("%q is not a valid label key: must contain only letters, digits, dots, underscores and dashes", key)
+				}
+			}
+			return nil
+		})),
 	)
 	return res
 }
 
 func (m Metrics) ToValid() valid.Metrics {
+	v := m.sinkToValid()
+	v.ProjectLabels = m.ProjectLabels
+	return v
+}
+
+// sinkToValid returns the valid config of the metrics sink. Only one sink is
+// used if several are configured.
+func (m Metrics) sinkToValid() valid.Metrics {
 	// we've already validated at this point
 	if m.Statsd != nil {
 		return valid.Metrics{
@@ -57,5 +135,25 @@ func (m Metrics) ToValid() valid.Metrics {
 			},
 		}
 	}
+	if m.Datadog != nil {
+		return valid.Metrics{
+			Datadog: &valid.Datadog{
+				Host: m.Datadog.Host,
+				Port: m.Datadog.Port,
+				Tags: m.Datadog.Tags,
+			},
+		}
+	}
+	if m.OTLP != nil {
+		// Already validated.
+		interval, _ := time.ParseDuration(m.OTLP.Interval)
+		return valid.Metrics{
+			OTLP: &valid.OTLP{
+				Endpoint: m.OTLP.Endpoint,
+				Headers:  m.OTLP.Headers,
+				Interval: interval,
+			},
+		}
+	}
 	return valid.Metrics{}
 }
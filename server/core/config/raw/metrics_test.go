@@ -79,6 +79,35 @@ func TestMetrics_Validate_Success(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "success with datadog config",
+			subject: raw.Metrics{
+				Datadog: &raw.Datadog{
+					Host: "localhost",
+					Port: "8125",
+					Tags: map[string]string{"env": "prod"},
+				},
+			},
+		},
+		{
+			description: "success with otlp config",
+			subject: raw.Metrics{
+				OTLP: &raw.OTLP{
+					Endpoint: "http://otel-collector:4318",
+					Headers:  map[string]string{"Api-Key": "secret"},
+					Interval: "30s",
+				},
+			},
+		},
+		{
+			description: "success with project labels",
+			subject: raw.Metrics{
+				Prometheus: &raw.Prometheus{
+					Endpoint: "/metrics",
+				},
+				ProjectLabels: []string{"tier", "team"},
+			},
+		},
 		{
 			description: "success with both configs",
 			subject: raw.Metrics{
@@ -138,6 +167,37 @@ func TestMetrics_Validate_Error(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "missing datadog port",
+			subject: raw.Metrics{
+				Datadog: &raw.Datadog{
+					Host: "localhost",
+				},
+			},
+		},
+		{
+			description: "otlp endpoint without scheme",
+			subject: raw.Metrics{
+				OTLP: &raw.OTLP{
+					Endpoint: "otel-collector:4318",
+				},
+			},
+		},
+		{
+			description: "invalid project label",
+			subject: raw.Metrics{
+				ProjectLabels: []string{"tier=prod"},
+			},
+		},
+		{
+			description: "invalid otlp interval",
+			subject: raw.Metrics{
+				OTLP: &raw.OTLP{
+					Endpoint: "http://otel-collector:4318",
+					Interval: "30",
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -0,0 +1,74 @@
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// Notification is the raw schema of a project's notification.
+type Notification struct {
+	Kind    string   `yaml:"kind" json:"kind"`
+	Channel string   `yaml:"channel,omitempty" json:"channel,omitempty"`
+	URL     string   `yaml:"url,omitempty" json:"url,omitempty"`
+	To      []string `yaml:"to,omitempty" json:"to,omitempty"`
+}
+
+func (n Notification) ToValid() valid.Notification {
+	return valid.Notification{
+		Kind:    n.Kind,
+		Channel: n.Channel,
+		URL:     n.URL,
+		To:      n.To,
+	}
+}
+
+func (n Notification) Validate() error {
+	channelValid := func(value interface{}) error {
+		if n.Kind == valid.SlackNotificationKind && value.(string) == "" {
+			return fmt.Errorf("must be set for %s notifications", n.Kind)
+		}
+		return nil
+	}
+	urlValid := func(value interface{}) error {
+		rawURL := value.(string)
+		if n.Kind != valid.HttpNotificationKind && n.Kind != valid.TeamsNotificationKind {
+			return nil
+		}
+		if rawURL == "" {
+			return fmt.Errorf("must be set for %s notifications", n.Kind)
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.New("must be an http or https URL")
+		}
+		return nil
+	}
+	toValid := func(value interface{}) error {
+		addresses := value.([]string)
+		if n.Kind == valid.EmailNotificationKind && len(addresses) == 0 {
+			return fmt.Errorf("must be set for %s notifications", n.Kind)
+		}
+		for _, address := range addresses {
+			if _, err := mail.ParseAddress(address); err != nil {
+				return fmt.Errorf("%q is not a valid email address: %w", address, err)
+			}
+		}
+		return nil
+	}
+
+	kinds := []interface{}{valid.SlackNotificationKind, valid.HttpNotificationKind, valid.TeamsNotificationKind, valid.EmailNotificationKind}
+	return validation.ValidateStruct(&n,
+		validation.Field(&n.Kind, validation.Required, validation.In(kinds...)),
+		validation.Field(&n.Channel, validation.By(channelValid)),
+		validation.Field(&n.URL, validation.By(urlValid)),
+		validation.Field(&n.To, validation.By(toValid)),
+	)
+}
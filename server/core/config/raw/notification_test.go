@@ -0,0 +1,120 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestNotification_UnmarshalYAML(t *testing.T) {
+	cases := []struct {
+		description string
+		input       string
+		exp         raw.Notification
+	}{
+		{
+			description: "slack",
+			input: `
+kind: slack
+channel: my-channel
+`,
+			exp: raw.Notification{
+				Kind:    "slack",
+				Channel: "my-channel",
+			},
+		},
+		{
+			description: "email",
+			input: `
+kind: email
+to: [team@example.com, oncall@example.com]
+`,
+			exp: raw.Notification{
+				Kind: "email",
+				To:   []string{"team@example.com", "oncall@example.com"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var n raw.Notification
+			err := unmarshalString(c.input, &n)
+			Ok(t, err)
+			Equals(t, c.exp, n)
+		})
+	}
+}
+
+func TestNotification_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.Notification
+		errContains *string
+	}{
+		{
+			description: "slack",
+			input:       raw.Notification{Kind: "slack", Channel: "my-channel"},
+		},
+		{
+			description: "slack without channel",
+			input:       raw.Notification{Kind: "slack"},
+			errContains: String("channel: must be set for slack notifications"),
+		},
+		{
+			description: "http",
+			input:       raw.Notification{Kind: "http", URL: "https://example.com/hooks"},
+		},
+		{
+			description: "teams without url",
+			input:       raw.Notification{Kind: "teams"},
+			errContains: String("url: must be set for teams notifications"),
+		},
+		{
+			description: "http with invalid scheme",
+			input:       raw.Notification{Kind: "http", URL: "file:///etc/passwd"},
+			errContains: String("url: must be an http or https URL"),
+		},
+		{
+			description: "email",
+			input:       raw.Notification{Kind: "email", To: []string{"team@example.com"}},
+		},
+		{
+			description: "email without recipients",
+			input:       raw.Notification{Kind: "email"},
+			errContains: String("to: must be set for email notifications"),
+		},
+		{
+			description: "email with invalid recipient",
+			input:       raw.Notification{Kind: "email", To: []string{"not-an-address"}},
+			errContains: String(`"not-an-address" is not a valid email address`),
+		},
+		{
+			description: "unsupported kind",
+			input:       raw.Notification{Kind: "pager"},
+			errContains: String("kind: must be a valid value"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestNotification_ToValid(t *testing.T) {
+	n := raw.Notification{
+		Kind:    "slack",
+		Channel: "my-channel",
+	}
+	Equals(t, valid.Notification{
+		Kind:    valid.SlackNotificationKind,
+		Channel: "my-channel",
+	}, n.ToValid())
+}
@@ -0,0 +1,81 @@
+package raw
+
+import (
+	"fmt"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+type PinningPolicy struct {
+	Mode                 *valid.PinningPolicyMode `yaml:"mode,omitempty" json:"mode,omitempty"`
+	RequirePinnedModules *bool                    `yaml:"require_pinned_modules,omitempty" json:"require_pinned_modules,omitempty"`
+	RequireLockFile      *bool                    `yaml:"require_lock_file,omitempty" json:"require_lock_file,omitempty"`
+	AllowedProviders     []AllowedProvider        `yaml:"allowed_providers,omitempty" json:"allowed_providers,omitempty"`
+}
+
+type AllowedProvider struct {
+	Source  string `yaml:"source" json:"source"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+func (p PinningPolicy) Validate() error {
+	return validation.ValidateStruct(&p,
+		// If p.Mode is nil, this should still pass validation.
+		validation.Field(&p.Mode, validation.In(valid.PinningPolicyFailMode, valid.PinningPolicyWarnMode)),
+		validation.Field(&p.AllowedProviders),
+	)
+}
+
+func (p PinningPolicy) ToValid() *valid.PinningPolicy {
+	v := valid.DefaultPinningPolicy
+	if p.Mode != nil {
+		v.Mode = *p.Mode
+	}
+	if p.RequirePinnedModules != nil {
+		v.RequirePinnedModules = *p.RequirePinnedModules
+	}
+	if p.RequireLockFile != nil {
+		v.RequireLockFile = *p.RequireLockFile
+	}
+	for _, a := range p.AllowedProviders {
+		v.AllowedProviders = append(v.AllowedProviders, a.ToValid())
+	}
+	return &v
+}
+
+func (a AllowedProvider) Validate() error {
+	validSource := func(value interface{}) error {
+		parts := strings.Split(value.(string), "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return fmt.Errorf("%q is not a provider source address, ex. hashicorp/aws", value)
+		}
+		return nil
+	}
+	validVersion := func(value interface{}) error {
+		if value.(string) == "" {
+			return nil
+		}
+		if _, err := version.NewConstraint(value.(string)); err != nil {
+			return fmt.Errorf("version %q could not be parsed: %w", value, err)
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Source, validation.Required, validation.By(validSource)),
+		validation.Field(&a.Version, validation.By(validVersion)),
+	)
+}
+
+func (a AllowedProvider) ToValid() valid.AllowedProvider {
+	v := valid.AllowedProvider{
+		Source: valid.NormalizeProviderSource(a.Source),
+	}
+	// We've already validated the constraint so the error can be ignored.
+	if a.Version != "" {
+		v.VersionConstraints, _ = version.NewConstraint(a.Version)
+	}
+	return v
+}
@@ -0,0 +1,97 @@
+package raw_test
+
+import (
+	"testing"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPinningPolicy_Validate(t *testing.T) {
+	warn := valid.PinningPolicyWarnMode
+	randomMode := valid.PinningPolicyMode("random_string")
+	cases := []struct {
+		description string
+		input       raw.PinningPolicy
+		expErr      string
+	}{
+		{
+			description: "nothing set",
+			input:       raw.PinningPolicy{},
+		},
+		{
+			description: "all fields set",
+			input: raw.PinningPolicy{
+				Mode: &warn,
+				AllowedProviders: []raw.AllowedProvider{
+					{Source: "hashicorp/aws", Version: "~> 5.0"},
+					{Source: "registry.opentofu.org/hashicorp/google"},
+				},
+			},
+		},
+		{
+			description: "invalid mode",
+			input: raw.PinningPolicy{
+				Mode: &randomMode,
+			},
+			expErr: "must be a valid value",
+		},
+		{
+			description: "invalid source",
+			input: raw.PinningPolicy{
+				AllowedProviders: []raw.AllowedProvider{{Source: "aws"}},
+			},
+			expErr: "\"aws\" is not a provider source address, ex. hashicorp/aws",
+		},
+		{
+			description: "invalid version",
+			input: raw.PinningPolicy{
+				AllowedProviders: []raw.AllowedProvider{{Source: "hashicorp/aws", Version: "five"}},
+			},
+			expErr: "version \"five\" could not be parsed",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+		})
+	}
+}
+
+func TestPinningPolicy_ToValid(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		Equals(t, &valid.DefaultPinningPolicy, raw.PinningPolicy{}.ToValid())
+	})
+
+	t.Run("all fields set", func(t *testing.T) {
+		warn := valid.PinningPolicyWarnMode
+		disabled := false
+		constraints, err := version.NewConstraint("~> 5.0")
+		Ok(t, err)
+		Equals(t, &valid.PinningPolicy{
+			Mode:                 valid.PinningPolicyWarnMode,
+			RequirePinnedModules: false,
+			RequireLockFile:      false,
+			AllowedProviders: []valid.AllowedProvider{
+				{Source: "registry.terraform.io/hashicorp/aws", VersionConstraints: constraints},
+				{Source: "registry.opentofu.org/hashicorp/google"},
+			},
+		}, raw.PinningPolicy{
+			Mode:                 &warn,
+			RequirePinnedModules: &disabled,
+			RequireLockFile:      &disabled,
+			AllowedProviders: []raw.AllowedProvider{
+				{Source: "Hashicorp/AWS", Version: "~> 5.0"},
+				{Source: "registry.opentofu.org/hashicorp/google"},
+			},
+		}.ToValid())
+	})
+}
@@ -0,0 +1,16 @@
+package raw
+
+import (
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// PreviewEnvironment is the raw schema of a project's preview environment.
+type PreviewEnvironment struct {
+	URLOutput string `yaml:"url_output,omitempty"`
+}
+
+func (p PreviewEnvironment) ToValid() valid.PreviewEnvironment {
+	return valid.PreviewEnvironment{
+		URLOutput: p.URLOutput,
+	}
+}
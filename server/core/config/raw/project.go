@@ -6,11 +6,13 @@ import (
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	version "github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/utils"
 )
 
 const (
@@ -21,25 +23,66 @@ const (
 )
 
 type Project struct {
-	Name                      *string    `yaml:"name,omitempty"`
-	Branch                    *string    `yaml:"branch,omitempty"`
-	Dir                       *string    `yaml:"dir,omitempty"`
-	Workspace                 *string    `yaml:"workspace,omitempty"`
-	Workflow                  *string    `yaml:"workflow,omitempty"`
-	TerraformDistribution     *string    `yaml:"terraform_distribution,omitempty"`
-	TerraformVersion          *string    `yaml:"terraform_version,omitempty"`
-	Autoplan                  *Autoplan  `yaml:"autoplan,omitempty"`
-	PlanRequirements          []string   `yaml:"plan_requirements,omitempty"`
-	ApplyRequirements         []string   `yaml:"apply_requirements,omitempty"`
-	ImportRequirements        []string   `yaml:"import_requirements,omitempty"`
-	DependsOn                 []string   `yaml:"depends_on,omitempty"`
-	DeleteSourceBranchOnMerge *bool      `yaml:"delete_source_branch_on_merge,omitempty"`
-	RepoLocking               *bool      `yaml:"repo_locking,omitempty"`
-	RepoLocks                 *RepoLocks `yaml:"repo_locks,omitempty"`
-	ExecutionOrderGroup       *int       `yaml:"execution_order_group,omitempty"`
-	PolicyCheck               *bool      `yaml:"policy_check,omitempty"`
-	CustomPolicyCheck         *bool      `yaml:"custom_policy_check,omitempty"`
-	SilencePRComments         []string   `yaml:"silence_pr_comments,omitempty"`
+	Name                      *string        `yaml:"name,omitempty"`
+	Branch                    *string        `yaml:"branch,omitempty"`
+	Dir                       *string        `yaml:"dir,omitempty"`
+	Workspace                 *string        `yaml:"workspace,omitempty"`
+	Workflow                  *string        `yaml:"workflow,omitempty"`
+	TerraformDistribution     *string        `yaml:"terraform_distribution,omitempty"`
+	TerraformVersion          *string        `yaml:"terraform_version,omitempty"`
+	Autoplan                  *Autoplan      `yaml:"autoplan,omitempty"`
+	PlanRequirements          []string       `yaml:"plan_requirements,omitempty"`
+	ApplyRequirements         []string       `yaml:"apply_requirements,omitempty"`
+	ImportRequirements        []string       `yaml:"import_requirements,omitempty"`
+	DependsOn                 []string       `yaml:"depends_on,omitempty"`
+	DeleteSourceBranchOnMerge *bool          `yaml:"delete_source_branch_on_merge,omitempty"`
+	RepoLocking               *bool          `yaml:"repo_locking,omitempty"`
+	RepoLocks                 *RepoLocks     `yaml:"repo_locks,omitempty"`
+	ExecutionOrderGroup       *int           `yaml:"execution_order_group,omitempty"`
+	PolicyCheck               *bool          `yaml:"policy_check,omitempty"`
+	CustomPolicyCheck         *bool          `yaml:"custom_policy_check,omitempty"`
+	SilencePRComments         []string       `yaml:"silence_pr_comments,omitempty"`
+	Notifications             []Notification `yaml:"notifications,omitempty"`
+	Owners                    *PolicyOwners  `yaml:"owners,omitempty"`
+	// CommandAllowlist restricts commands run on the project to users and
+	// teams.
+	CommandAllowlist []CommandAllowlistRule `yaml:"command_allowlist,omitempty"`
+	// ExtraArgs are the default extra arguments of the built-in steps of the
+	// project's workflow, keyed by step name.
+	ExtraArgs map[string][]string `yaml:"extra_args,omitempty"`
+	// PRMetadataVars passes the pull request's metadata to terraform as
+	// variables, either as TF_VAR_ env vars (env) or in a tfvars file (tfvars).
+	PRMetadataVars *string `yaml:"pr_metadata_vars,omitempty"`
+	// ShareOutputs stores the outputs of the project after each apply so the
+	// projects depending on it can read them with dependency_outputs.
+	ShareOutputs *bool `yaml:"share_outputs,omitempty"`
+	// DependencyOutputs passes the shared outputs of the projects in
+	// depends_on to terraform as variables, either as TF_VAR_ env vars (env)
+	// or in a tfvars file (tfvars).
+	DependencyOutputs *string `yaml:"dependency_outputs,omitempty"`
+	// ApplyWindows are the periods the project may be applied in.
+	ApplyWindows []ApplyWindow `yaml:"apply_windows,omitempty"`
+	// Tool is the tool the project is planned and applied with, terraform by
+	// default.
+	Tool *string `yaml:"tool,omitempty"`
+	// PreviewEnvironment stamps the project out in a workspace of its own for
+	// every pull request.
+	PreviewEnvironment *PreviewEnvironment `yaml:"preview_environment,omitempty"`
+	// Silence silences the plan and apply comments of the project. It's a
+	// shorthand for silencing every stage with silence_pr_comments.
+	Silence *bool `yaml:"silence,omitempty"`
+	// Instances are the names of the Atlantis instances that run commands on
+	// the project when several instances share the repo.
+	Instances []string `yaml:"instances,omitempty"`
+	// LockKeys are shared locks held while the project is applied, so
+	// projects sharing a key are applied one at a time across repos.
+	LockKeys []string `yaml:"lock_keys,omitempty"`
+	// Canary makes the project the canary of the other projects in its dir,
+	// which are applied once it's promoted.
+	Canary *Canary `yaml:"canary,omitempty"`
+	// Labels are arbitrary key/value pairs describing the project, ex.
+	// tier: prod. Commands can be run on the projects with a label.
+	Labels map[string]string `yaml:"labels,omitempty"`
 }
 
 func (p Project) Validate() error {
@@ -85,6 +128,119 @@ func (p Project) Validate() error {
 		return nil
 	}
 
+	ownersValid := func(value interface{}) error {
+		owners := value.(*PolicyOwners)
+		if owners != nil && len(owners.Users) == 0 && len(owners.Teams) == 0 {
+			return errors.New("must list at least one user or team")
+		}
+		return nil
+	}
+
+	commandAllowlistValid := func(value interface{}) error {
+		for i, rule := range value.([]CommandAllowlistRule) {
+			if len(rule.Dirs) > 0 {
+				return fmt.Errorf("rule %d: dirs can only be set in the server-side config", i)
+			}
+		}
+		return nil
+	}
+
+	extraArgsValid := func(value interface{}) error {
+		extraArgs := value.(map[string][]string)
+		for step, args := range extraArgs {
+			if !utils.SlicesContains(valid.ExtraArgsSteps, step) {
+				return fmt.Errorf("%q is not a supported step, only %s are supported", step, strings.Join(valid.ExtraArgsSteps, ", "))
+			}
+			for _, arg := range args {
+				if !strings.HasPrefix(arg, "-") {
+					return fmt.Errorf("%q is not a flag: %s args must start with '-'", arg, step)
+				}
+			}
+		}
+		return nil
+	}
+
+	prMetadataVarsValid := func(value interface{}) error {
+		mode := value.(*string)
+		if mode != nil && *mode != valid.PRMetadataVarsEnv && *mode != valid.PRMetadataVarsTFVars {
+			return fmt.Errorf("%q is not supported, only %q and %q are supported", *mode, valid.PRMetadataVarsEnv, valid.PRMetadataVarsTFVars)
+		}
+		return nil
+	}
+
+	dependencyOutputsValid := func(value interface{}) error {
+		mode := value.(*string)
+		if mode == nil {
+			return nil
+		}
+		if *mode != valid.PRMetadataVarsEnv && *mode != valid.PRMetadataVarsTFVars {
+			return fmt.Errorf("%q is not supported, only %q and %q are supported", *mode, valid.PRMetadataVarsEnv, valid.PRMetadataVarsTFVars)
+		}
+		if len(p.DependsOn) == 0 {
+			return errors.New("requires depends_on: the outputs are the ones of the projects it depends on")
+		}
+		return nil
+	}
+
+	toolValid := func(value interface{}) error {
+		tool := value.(*string)
+		if tool != nil && !utils.SlicesContains(valid.ToolNames(), *tool) {
+			return fmt.Errorf("%q is not supported, only %s are supported", *tool, strings.Join(valid.ToolNames(), ", "))
+		}
+		return nil
+	}
+
+	canaryValid := func(value interface{}) error {
+		if value.(*Canary) != nil && p.PreviewEnvironment != nil {
+			return errors.New("can't be set with preview_environment")
+		}
+		return nil
+	}
+
+	previewEnvironmentValid := func(value interface{}) error {
+		if value.(*PreviewEnvironment) != nil && p.Workspace != nil {
+			return errors.New("can't be set with workspace: the workspace is named after the pull request")
+		}
+		return nil
+	}
+
+	silenceValid := func(value interface{}) error {
+		if value.(*bool) != nil && p.SilencePRComments != nil {
+			return fmt.Errorf("can't be set with %s", valid.SilencePRCommentsKey)
+		}
+		return nil
+	}
+
+	instancesValid := func(value interface{}) error {
+		for _, instance := range value.([]string) {
+			if !valid.InstanceNameRegex.MatchString(instance) {
+				return fmt.Errorf("%q is not a valid instance name: must contain only lowercase letters, digits and dashes", instance)
+			}
+		}
+		return nil
+	}
+
+	lockKeysValid := func(value interface{}) error {
+		for _, key := range value.([]string) {
+			if !valid.LockKeyRegex.MatchString(key) {
+				return fmt.Errorf("%q is not a valid lock key: must contain only letters, digits, dots, underscores and dashes", key)
+			}
+		}
+		return nil
+	}
+
+	labelsValid := func(value interface{}) error {
+		for key, val := range value.(map[string]string) {
+			if !valid.LabelRegex.MatchString(key) {
+				return fmt.Errorf("%q is not a valid label key: must contain only letters, digits, dots, underscores and dashes", key)
+			}
+			if !valid.LabelRegex.MatchString(val) {
+				return fmt.Errorf("%q is not a valid value of label %q: must contain only letters, digits, dots, underscores and dashes", val, key)
+			}
+		}
+		return nil
+	}
+
 	return validation.ValidateStruct(&p,
 		validation.Field(&p.Dir, validation.Required, validation.By(hasDotDot)),
 		validation.Field(&p.PlanRequirements, validation.By(validPlanReq)),
@@ -95,6 +251,20 @@ func (p Project) Validate() error {
 		validation.Field(&p.DependsOn, validation.By(DependsOn)),
 		validation.Field(&p.Name, validation.By(validName)),
 		validation.Field(&p.Branch, validation.By(branchValid)),
+		validation.Field(&p.Notifications),
+		validation.Field(&p.Owners, validation.By(ownersValid)),
+		validation.Field(&p.CommandAllowlist, validation.By(commandAllowlistValid)),
+		validation.Field(&p.ExtraArgs, validation.By(extraArgsValid)),
+		validation.Field(&p.PRMetadataVars, validation.By(prMetadataVarsValid)),
+		validation.Field(&p.DependencyOutputs, validation.By(dependencyOutputsValid)),
+		validation.Field(&p.ApplyWindows),
+		validation.Field(&p.Tool, validation.By(toolValid)),
+		validation.Field(&p.PreviewEnvironment, validation.By(previewEnvironmentValid)),
+		validation.Field(&p.Silence, validation.By(silenceValid)),
+		validation.Field(&p.Instances, validation.By(instancesValid)),
+		validation.Field(&p.LockKeys, validation.By(lockKeysValid)),
+		validation.Field(&p.Canary, validation.By(canaryValid)),
+		validation.Field(&p.Labels, validation.By(labelsValid)),
 	)
 }
 
@@ -131,6 +301,12 @@ func (p Project) ToValid() valid.Project {
 	} else {
 		v.Autoplan = p.Autoplan.ToValid()
 	}
+	if p.Tool != nil {
+		v.Tool = *p.Tool
+		if tool, ok := valid.Tools[*p.Tool]; ok && (p.Autoplan == nil || p.Autoplan.WhenModified == nil) {
+			v.Autoplan.WhenModified = tool.WhenModified
+		}
+	}
 
 	// There are no default apply/import requirements.
 	v.PlanRequirements = p.PlanRequirements
@@ -168,6 +344,50 @@ func (p Project) ToValid() valid.Project {
 	if p.SilencePRComments != nil {
 		v.SilencePRComments = p.SilencePRComments
 	}
+	if p.Silence != nil && *p.Silence {
+		v.SilencePRComments = slices.Clone(valid.AllowedSilencePRComments)
+	}
+
+	v.Instances = p.Instances
+	v.LockKeys = p.LockKeys
+	v.Labels = p.Labels
+
+	for _, n := range p.Notifications {
+		v.Notifications = append(v.Notifications, n.ToValid())
+	}
+
+	if p.Owners != nil {
+		owners := p.Owners.ToValid()
+		v.Owners = &owners
+	}
+
+	v.CommandAllowlist = commandAllowlistToValid(p.CommandAllowlist)
+
+	v.ExtraArgs = p.ExtraArgs
+
+	if p.PRMetadataVars != nil {
+		v.PRMetadataVars = *p.PRMetadataVars
+	}
+	if p.ShareOutputs != nil {
+		v.ShareOutputs = *p.ShareOutputs
+	}
+	if p.DependencyOutputs != nil {
+		v.DependencyOutputs = *p.DependencyOutputs
+	}
+
+	for _, w := range p.ApplyWindows {
+		v.ApplyWindows = append(v.ApplyWindows, w.ToValid())
+	}
+
+	if p.PreviewEnvironment != nil {
+		previewEnvironment := p.PreviewEnvironment.ToValid()
+		v.PreviewEnvironment = &previewEnvironment
+	}
+
+	if p.Canary != nil {
+		canary := p.Canary.ToValid()
+		v.Canary = &canary
+	}
 
 	return v
 }
@@ -194,6 +414,12 @@ func validPlanReq(value interface{}) error {
 func validApplyReq(value interface{}) error {
 	reqs := value.([]string)
 	for _, r := range reqs {
+		if name, ok := valid.ApplyRequirementPluginName(r); ok {
+			if name == "" {
+				return fmt.Errorf("%q is not a valid apply_requirement, the name of the plugin is missing", r)
+			}
+			continue
+		}
 		if r != ApprovedRequirement && r != MergeableRequirement && r != UnDivergedRequirement {
 			return fmt.Errorf("%q is not a valid apply_requirement, only %q, %q and %q are supported", r, ApprovedRequirement, MergeableRequirement, UnDivergedRequirement)
 		}
@@ -0,0 +1,48 @@
+package raw
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// ProjectResolver is the raw schema for the repo-level project_resolver key.
+type ProjectResolver struct {
+	Command string `yaml:"command"`
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+func (p ProjectResolver) Validate() error {
+	timeoutValid := func(value interface{}) error {
+		timeout := value.(string)
+		if timeout == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Command, validation.Required),
+		validation.Field(&p.Timeout, validation.By(timeoutValid)),
+	)
+}
+
+func (p ProjectResolver) ToValid() *valid.ProjectResolver {
+	timeout := valid.DefaultProjectResolverTimeout
+	if p.Timeout != "" {
+		// Safe to ignore the error because we test it in Validate().
+		timeout, _ = time.ParseDuration(p.Timeout)
+	}
+	return &valid.ProjectResolver{
+		Command: p.Command,
+		Timeout: timeout,
+	}
+}
@@ -0,0 +1,77 @@
+package raw_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProjectResolver_UnmarshalYAML(t *testing.T) {
+	var p raw.ProjectResolver
+	err := unmarshalString(`
+command: ./scripts/changed-projects.sh
+timeout: 2m
+`, &p)
+	Ok(t, err)
+	Equals(t, raw.ProjectResolver{
+		Command: "./scripts/changed-projects.sh",
+		Timeout: "2m",
+	}, p)
+}
+
+func TestProjectResolver_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.ProjectResolver
+		errContains *string
+	}{
+		{
+			description: "command set",
+			input:       raw.ProjectResolver{Command: "./resolve.sh"},
+			errContains: nil,
+		},
+		{
+			description: "command and timeout set",
+			input:       raw.ProjectResolver{Command: "./resolve.sh", Timeout: "30s"},
+			errContains: nil,
+		},
+		{
+			description: "command missing",
+			input:       raw.ProjectResolver{Timeout: "30s"},
+			errContains: String("command: cannot be blank"),
+		},
+		{
+			description: "invalid timeout",
+			input:       raw.ProjectResolver{Command: "./resolve.sh", Timeout: "soon"},
+			errContains: String("timeout: time: invalid duration"),
+		},
+		{
+			description: "negative timeout",
+			input:       raw.ProjectResolver{Command: "./resolve.sh", Timeout: "-1m"},
+			errContains: String("timeout: must be positive"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+func TestProjectResolver_ToValid(t *testing.T) {
+	Equals(t, &valid.ProjectResolver{
+		Command: "./resolve.sh",
+		Timeout: valid.DefaultProjectResolverTimeout,
+	}, raw.ProjectResolver{Command: "./resolve.sh"}.ToValid())
+	Equals(t, &valid.ProjectResolver{
+		Command: "./resolve.sh",
+		Timeout: 90 * time.Second,
+	}, raw.ProjectResolver{Command: "./resolve.sh", Timeout: "90s"}.ToValid())
+}
@@ -315,6 +315,215 @@ func TestProject_Validate(t *testing.T) {
 			},
 			expErr: `name: "namewith\\" is not allowed: must contain only URL safe characters.`,
 		},
+		{
+			description: "owners set",
+			input: raw.Project{
+				Dir: String("."),
+				Owners: &raw.PolicyOwners{
+					Teams: []string{"platform"},
+				},
+			},
+			expErr: "",
+		},
+		{
+			description: "owners empty",
+			input: raw.Project{
+				Dir:    String("."),
+				Owners: &raw.PolicyOwners{},
+			},
+			expErr: "owners: must list at least one user or team.",
+		},
+		{
+			description: "command allowlist set",
+			input: raw.Project{
+				Dir: String("."),
+				CommandAllowlist: []raw.CommandAllowlistRule{
+					{Commands: []string{"apply"}, Teams: []string{"sre"}},
+				},
+			},
+			expErr: "",
+		},
+		{
+			description: "command allowlist with dirs",
+			input: raw.Project{
+				Dir: String("."),
+				CommandAllowlist: []raw.CommandAllowlistRule{
+					{Commands: []string{"apply"}, Dirs: []string{"prod/**"}, Teams: []string{"sre"}},
+				},
+			},
+			expErr: "command_allowlist: rule 0: dirs can only be set in the server-side config.",
+		},
+		{
+			description: "extra args set",
+			input: raw.Project{
+				Dir: String("."),
+				ExtraArgs: map[string][]string{
+					"plan": {"-refresh=false", "-parallelism=30"},
+				},
+			},
+			expErr: "",
+		},
+		{
+			description: "extra args of unsupported step",
+			input: raw.Project{
+				Dir: String("."),
+				ExtraArgs: map[string][]string{
+					"show": {"-json"},
+				},
+			},
+			expErr: "extra_args: \"show\" is not a supported step, only init, plan, apply, import are supported.",
+		},
+		{
+			description: "extra args not a flag",
+			input: raw.Project{
+				Dir: String("."),
+				ExtraArgs: map[string][]string{
+					"plan": {"main.tf"},
+				},
+			},
+			expErr: "extra_args: \"main.tf\" is not a flag: plan args must start with '-'.",
+		},
+		{
+			description: "pr metadata vars",
+			input: raw.Project{
+				Dir:            String("."),
+				PRMetadataVars: String("tfvars"),
+			},
+			expErr: "",
+		},
+		{
+			description: "unsupported pr metadata vars",
+			input: raw.Project{
+				Dir:            String("."),
+				PRMetadataVars: String("file"),
+			},
+			expErr: "pr_metadata_vars: \"file\" is not supported, only \"env\" and \"tfvars\" are supported.",
+		},
+		{
+			description: "dependency outputs",
+			input: raw.Project{
+				Dir:               String("."),
+				DependsOn:         []string{"network"},
+				DependencyOutputs: String("env"),
+			},
+			expErr: "",
+		},
+		{
+			description: "unsupported dependency outputs",
+			input: raw.Project{
+				Dir:               String("."),
+				DependsOn:         []string{"network"},
+				DependencyOutputs: String("file"),
+			},
+			expErr: "dependency_outputs: \"file\" is not supported, only \"env\" and \"tfvars\" are supported.",
+		},
+		{
+			description: "dependency outputs without depends_on",
+			input: raw.Project{
+				Dir:               String("."),
+				DependencyOutputs: String("tfvars"),
+			},
+			expErr: "dependency_outputs: requires depends_on: the outputs are the ones of the projects it depends on.",
+		},
+		{
+			description: "tool",
+			input: raw.Project{
+				Dir:  String("."),
+				Tool: String("kustomize"),
+			},
+			expErr: "",
+		},
+		{
+			description: "unsupported tool",
+			input: raw.Project{
+				Dir:  String("."),
+				Tool: String("pulumi"),
+			},
+			expErr: "tool: \"pulumi\" is not supported, only terraform, ansible, helmfile, kustomize, stacks are supported.",
+		},
+		{
+			description: "preview environment",
+			input: raw.Project{
+				Dir:                String("."),
+				PreviewEnvironment: &raw.PreviewEnvironment{URLOutput: "url"},
+			},
+			expErr: "",
+		},
+		{
+			description: "preview environment with workspace",
+			input: raw.Project{
+				Dir:                String("."),
+				Workspace:          String("staging"),
+				PreviewEnvironment: &raw.PreviewEnvironment{},
+			},
+			expErr: "preview_environment: can't be set with workspace: the workspace is named after the pull request.",
+		},
+		{
+			description: "canary with preview environment",
+			input: raw.Project{
+				Dir:                String("."),
+				Canary:             &raw.Canary{},
+				PreviewEnvironment: &raw.PreviewEnvironment{},
+			},
+			expErr: "canary: can't be set with preview_environment.",
+		},
+		{
+			description: "silence with silence_pr_comments",
+			input: raw.Project{
+				Dir:               String("."),
+				Silence:           Bool(true),
+				SilencePRComments: []string{"apply"},
+			},
+			expErr: "silence: can't be set with silence_pr_comments.",
+		},
+		{
+			description: "instances",
+			input: raw.Project{
+				Dir:       String("."),
+				Instances: []string{"prod", "bu-1"},
+			},
+			expErr: "",
+		},
+		{
+			description: "invalid instance",
+			input: raw.Project{
+				Dir:       String("."),
+				Instances: []string{"Prod"},
+			},
+			expErr: `instances: "Prod" is not a valid instance name: must contain only lowercase letters, digits and dashes.`,
+		},
+		{
+			description: "lock keys",
+			input: raw.Project{
+				Dir:      String("."),
+				LockKeys: []string{"network-core", "dns_v2.prod"},
+			},
+			expErr: "",
+		},
+		{
+			description: "invalid lock key",
+			input: raw.Project{
+				Dir:      String("."),
+				LockKeys: []string{"network/core"},
+			},
+			expErr: `lock_keys: "network/core" is not a valid lock key: must contain only letters, digits, dots, underscores and dashes.`,
+		},
+		{
+			description: "labels",
+			input: raw.Project{
+				Dir:    String("."),
+				Labels: map[string]string{"tier": "prod", "team": "platform-core"},
+			},
+			expErr: "",
+		},
+		{
+			description: "invalid label value",
+			input: raw.Project{
+				Dir:    String("."),
+				Labels: map[string]string{"tier": "prod us"},
+			},
+			expErr: `labels: "prod us" is not a valid value of label "tier": must contain only letters, digits, dots, underscores and dashes.`,
+		},
 	}
 	validation.ErrorTag = "yaml"
 	for _, c := range cases {
@@ -508,6 +717,28 @@ func TestProject_ToValid(t *testing.T) {
 			},
 		},
 
+		{
+			description: "owners set",
+			input: raw.Project{
+				Dir: String("."),
+				Owners: &raw.PolicyOwners{
+					Users: []string{"alice"},
+					Teams: []string{"platform"},
+				},
+			},
+			exp: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+				Autoplan: valid.Autoplan{
+					WhenModified: raw.DefaultAutoPlanWhenModified,
+					Enabled:      true,
+				},
+				Owners: &valid.PolicyOwners{
+					Users: []string{"alice"},
+					Teams: []string{"platform"},
+				},
+			},
+		},
 		{
 			description: "workspace set to empty string",
 			input: raw.Project{
@@ -530,3 +761,43 @@ func TestProject_ToValid(t *testing.T) {
 		})
 	}
 }
+
+// Projects of other tools default to the tool's autoplan patterns.
+func TestProject_ToValid_Tool(t *testing.T) {
+	v := raw.Project{Dir: String("."), Tool: String("helmfile")}.ToValid()
+	Equals(t, "helmfile", v.Tool)
+	Equals(t, valid.Tools[valid.ToolHelmfile].WhenModified, v.Autoplan.WhenModified)
+	Equals(t, true, v.Autoplan.Enabled)
+
+	v = raw.Project{
+		Dir:      String("."),
+		Tool:     String("helmfile"),
+		Autoplan: &raw.Autoplan{WhenModified: []string{"releases/*.yaml"}},
+	}.ToValid()
+	Equals(t, []string{"releases/*.yaml"}, v.Autoplan.WhenModified)
+
+	v = raw.Project{Dir: String("."), Tool: String("terraform")}.ToValid()
+	Equals(t, raw.DefaultAutoPlanWhenModified, v.Autoplan.WhenModified)
+}
+
+// Preview environments are put in the workspace of the pull request once the
+// repo config is parsed for one.
+func TestProject_ToValid_PreviewEnvironment(t *testing.T) {
+	repoCfg := valid.RepoCfg{Projects: []valid.Project{
+		raw.Project{Dir: String("app"), PreviewEnvironment: &raw.PreviewEnvironment{URLOutput: "url"}}.ToValid(),
+		raw.Project{Dir: String("shared")}.ToValid(),
+	}}
+	Equals(t, &valid.PreviewEnvironment{URLOutput: "url"}, repoCfg.Projects[0].PreviewEnvironment)
+
+	repoCfg.UsePreviewWorkspaces(12)
+	Equals(t, "pr-12", repoCfg.Projects[0].Workspace)
+	Equals(t, "default", repoCfg.Projects[1].Workspace)
+}
+
+func TestProject_ToValid_Silence(t *testing.T) {
+	v := raw.Project{Dir: String("."), Silence: Bool(true)}.ToValid()
+	Equals(t, []string{"plan", "apply"}, v.SilencePRComments)
+
+	v = raw.Project{Dir: String("."), Silence: Bool(false)}.ToValid()
+	Equals(t, 0, len(v.SilencePRComments))
+}
@@ -29,6 +29,8 @@ type RepoCfg struct {
 	AbortOnExecutionOrderFail *bool               `yaml:"abort_on_execution_order_fail,omitempty"`
 	RepoLocks                 *RepoLocks          `yaml:"repo_locks,omitempty"`
 	SilencePRComments         []string            `yaml:"silence_pr_comments,omitempty"`
+	ImpactPreview             *bool               `yaml:"impact_preview,omitempty"`
+	ProjectResolver           *ProjectResolver    `yaml:"project_resolver,omitempty"`
 }
 
 func (r RepoCfg) Validate() error {
@@ -46,6 +48,7 @@ func (r RepoCfg) Validate() error {
 		validation.Field(&r.Version, validation.By(equals2)),
 		validation.Field(&r.Projects),
 		validation.Field(&r.Workflows),
+		validation.Field(&r.ProjectResolver),
 	)
 }
 
@@ -83,6 +86,16 @@ func (r RepoCfg) ToValid() valid.RepoCfg {
 	if r.RepoLocks != nil {
 		repoLocks = r.RepoLocks.ToValid()
 	}
+
+	var projectResolver *valid.ProjectResolver
+	if r.ProjectResolver != nil {
+		projectResolver = r.ProjectResolver.ToValid()
+	}
+
+	impactPreview := false
+	if r.ImpactPreview != nil {
+		impactPreview = *r.ImpactPreview
+	}
 	return valid.RepoCfg{
 		Version:                   *r.Version,
 		Projects:                  validProjects,
@@ -98,5 +111,7 @@ func (r RepoCfg) ToValid() valid.RepoCfg {
 		AbortOnExecutionOrderFail: abortOnExecutionOrderFail,
 		RepoLocks:                 repoLocks,
 		SilencePRComments:         r.SilencePRComments,
+		ImpactPreview:             impactPreview,
+		ProjectResolver:           projectResolver,
 	}
 }
@@ -325,6 +325,33 @@ func TestConfig_ToValid(t *testing.T) {
 				Workflows:                 map[string]valid.Workflow{},
 			},
 		},
+		{
+			description: "impact_preview true",
+			input: raw.RepoCfg{
+				Version:       Int(3),
+				ImpactPreview: Bool(true),
+			},
+			exp: valid.RepoCfg{
+				Version:       3,
+				ImpactPreview: true,
+				Workflows:     map[string]valid.Workflow{},
+			},
+		},
+		{
+			description: "project_resolver included",
+			input: raw.RepoCfg{
+				Version:         Int(3),
+				ProjectResolver: &raw.ProjectResolver{Command: "./resolve.sh"},
+			},
+			exp: valid.RepoCfg{
+				Version: 3,
+				ProjectResolver: &valid.ProjectResolver{
+					Command: "./resolve.sh",
+					Timeout: valid.DefaultProjectResolverTimeout,
+				},
+				Workflows: map[string]valid.Workflow{},
+			},
+		},
 		{
 			description: "autodiscover omitted",
 			input: raw.RepoCfg{
@@ -0,0 +1,87 @@
+package raw
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/utils"
+)
+
+type RiskScoring struct {
+	Rules         []RiskRule      `yaml:"rules,omitempty" json:"rules,omitempty"`
+	ProviderScore int             `yaml:"provider_score,omitempty" json:"provider_score,omitempty"`
+	Thresholds    []RiskThreshold `yaml:"thresholds" json:"thresholds"`
+}
+
+type RiskRule struct {
+	Actions       []string `yaml:"actions,omitempty" json:"actions,omitempty"`
+	ResourceTypes []string `yaml:"resource_types,omitempty" json:"resource_types,omitempty"`
+	Score         int      `yaml:"score" json:"score"`
+}
+
+type RiskThreshold struct {
+	Score     int          `yaml:"score" json:"score"`
+	Name      string       `yaml:"name,omitempty" json:"name,omitempty"`
+	Approvals int          `yaml:"approvals,omitempty" json:"approvals,omitempty"`
+	Reviewers PolicyOwners `yaml:"reviewers,omitempty" json:"reviewers,omitempty"`
+}
+
+func (r RiskScoring) ToValid() *valid.RiskScoring {
+	v := valid.RiskScoring{ProviderScore: r.ProviderScore}
+	for _, rule := range r.Rules {
+		v.Rules = append(v.Rules, valid.RiskRule{
+			Actions:       rule.Actions,
+			ResourceTypes: rule.ResourceTypes,
+			Score:         rule.Score,
+		})
+	}
+	for _, threshold := range r.Thresholds {
+		v.Thresholds = append(v.Thresholds, valid.RiskThreshold{
+			Score:     threshold.Score,
+			Name:      threshold.Name,
+			Approvals: threshold.Approvals,
+			Reviewers: threshold.Reviewers.ToValid(),
+		})
+	}
+	sort.SliceStable(v.Thresholds, func(i, j int) bool {
+		return v.Thresholds[i].Score < v.Thresholds[j].Score
+	})
+	return &v
+}
+
+func (r RiskScoring) Validate() error {
+	rulesValid := func(value interface{}) error {
+		for i, rule := range value.([]RiskRule) {
+			for _, action := range rule.Actions {
+				if !utils.SlicesContains(valid.RiskActions, action) {
+					return fmt.Errorf("rule %d: %q is not a valid action, only %s are supported", i, action, strings.Join(valid.RiskActions, ", "))
+				}
+			}
+			for _, pattern := range rule.ResourceTypes {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("rule %d: %q is not a valid pattern: %w", i, pattern, err)
+				}
+			}
+		}
+		return nil
+	}
+	thresholdsValid := func(value interface{}) error {
+		for i, threshold := range value.([]RiskThreshold) {
+			if threshold.Approvals < 0 {
+				return fmt.Errorf("threshold %d: approvals must not be negative", i)
+			}
+			if threshold.Approvals == 0 && len(threshold.Reviewers.Users) == 0 && len(threshold.Reviewers.Teams) == 0 {
+				return fmt.Errorf("threshold %d: must require approvals or reviewers", i)
+			}
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Rules, validation.By(rulesValid)),
+		validation.Field(&r.Thresholds, validation.Required, validation.By(thresholdsValid)),
+	)
+}
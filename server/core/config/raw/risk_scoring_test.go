@@ -0,0 +1,106 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRiskScoring_UnmarshalYAML(t *testing.T) {
+	var r raw.RiskScoring
+	Ok(t, unmarshalString(`
+rules:
+- actions: [delete]
+  score: 10
+- resource_types: ["aws_iam_*"]
+  score: 3
+provider_score: 1
+thresholds:
+- score: 20
+  name: high
+  approvals: 2
+  reviewers:
+    teams: [platform]
+`, &r))
+	Equals(t, raw.RiskScoring{
+		Rules: []raw.RiskRule{
+			{Actions: []string{"delete"}, Score: 10},
+			{ResourceTypes: []string{"aws_iam_*"}, Score: 3},
+		},
+		ProviderScore: 1,
+		Thresholds: []raw.RiskThreshold{
+			{Score: 20, Name: "high", Approvals: 2, Reviewers: raw.PolicyOwners{Teams: []string{"platform"}}},
+		},
+	}, r)
+}
+
+func TestRiskScoring_Validate(t *testing.T) {
+	thresholds := []raw.RiskThreshold{{Score: 10, Approvals: 2}}
+	cases := []struct {
+		description string
+		input       raw.RiskScoring
+		errContains *string
+	}{
+		{
+			description: "valid",
+			input: raw.RiskScoring{
+				Rules:      []raw.RiskRule{{Actions: []string{"replace"}, ResourceTypes: []string{"aws_db_*"}, Score: 5}},
+				Thresholds: thresholds,
+			},
+		},
+		{
+			description: "no thresholds",
+			input:       raw.RiskScoring{Rules: []raw.RiskRule{{Score: 1}}},
+			errContains: String("thresholds: cannot be blank"),
+		},
+		{
+			description: "invalid action",
+			input: raw.RiskScoring{
+				Rules:      []raw.RiskRule{{Actions: []string{"destroy"}, Score: 5}},
+				Thresholds: thresholds,
+			},
+			errContains: String(`rule 0: "destroy" is not a valid action, only create, update, delete, replace are supported`),
+		},
+		{
+			description: "invalid pattern",
+			input: raw.RiskScoring{
+				Rules:      []raw.RiskRule{{ResourceTypes: []string{"aws_["}, Score: 5}},
+				Thresholds: thresholds,
+			},
+			errContains: String(`rule 0: "aws_[" is not a valid pattern`),
+		},
+		{
+			description: "threshold without escalation",
+			input:       raw.RiskScoring{Thresholds: []raw.RiskThreshold{{Score: 10, Name: "high"}}},
+			errContains: String("threshold 0: must require approvals or reviewers"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.errContains == nil {
+				Ok(t, c.input.Validate())
+			} else {
+				ErrContains(t, *c.errContains, c.input.Validate())
+			}
+		})
+	}
+}
+
+// Thresholds are sorted by score so the highest one reached can be found.
+func TestRiskScoring_ToValid(t *testing.T) {
+	Equals(t, &valid.RiskScoring{
+		Rules: []valid.RiskRule{{Actions: []string{"delete"}, Score: 10}},
+		Thresholds: []valid.RiskThreshold{
+			{Score: 10, Approvals: 1},
+			{Score: 20, Approvals: 2, Reviewers: valid.PolicyOwners{Users: []string{"alice"}}},
+		},
+	}, raw.RiskScoring{
+		Rules: []raw.RiskRule{{Actions: []string{"delete"}, Score: 10}},
+		Thresholds: []raw.RiskThreshold{
+			{Score: 20, Approvals: 2, Reviewers: raw.PolicyOwners{Users: []string{"alice"}}},
+			{Score: 10, Approvals: 1},
+		},
+	}.ToValid())
+}
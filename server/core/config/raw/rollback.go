@@ -0,0 +1,28 @@
+package raw
+
+import (
+	"errors"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+type Rollback struct {
+	Approvals *int `yaml:"approvals,omitempty" json:"approvals,omitempty"`
+}
+
+func (r Rollback) ToValid() *valid.Rollback {
+	approvals := valid.DefaultRollbackApprovals
+	if r.Approvals != nil {
+		approvals = *r.Approvals
+	}
+	return &valid.Rollback{
+		Approvals: approvals,
+	}
+}
+
+func (r Rollback) Validate() error {
+	if r.Approvals != nil && *r.Approvals < 1 {
+		return errors.New("approvals must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRollback_UnmarshalYAML(t *testing.T) {
+	approvals := 3
+	var r raw.Rollback
+	Ok(t, unmarshalString(`
+approvals: 3
+`, &r))
+	Equals(t, raw.Rollback{Approvals: &approvals}, r)
+}
+
+func TestRollback_Validate(t *testing.T) {
+	one := 1
+	zero := 0
+	Ok(t, raw.Rollback{}.Validate())
+	Ok(t, raw.Rollback{Approvals: &one}.Validate())
+	ErrContains(t, "approvals must be at least 1", raw.Rollback{Approvals: &zero}.Validate())
+}
+
+func TestRollback_ToValid(t *testing.T) {
+	approvals := 3
+	Equals(t, &valid.Rollback{Approvals: valid.DefaultRollbackApprovals}, raw.Rollback{}.ToValid())
+	Equals(t, &valid.Rollback{Approvals: 3}, raw.Rollback{Approvals: &approvals}.ToValid())
+}
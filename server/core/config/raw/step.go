@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -13,23 +14,29 @@ import (
 )
 
 const (
-	ExtraArgsKey        = "extra_args"
-	NameArgKey          = "name"
-	CommandArgKey       = "command"
-	ValueArgKey         = "value"
-	OutputArgKey        = "output"
-	RunStepName         = "run"
-	PlanStepName        = "plan"
-	ShowStepName        = "show"
-	PolicyCheckStepName = "policy_check"
-	ApplyStepName       = "apply"
-	InitStepName        = "init"
-	EnvStepName         = "env"
-	MultiEnvStepName    = "multienv"
-	ImportStepName      = "import"
-	StateRmStepName     = "state_rm"
-	ShellArgKey         = "shell"
-	ShellArgsArgKey     = "shellArgs"
+	ExtraArgsKey            = "extra_args"
+	NameArgKey              = "name"
+	CommandArgKey           = "command"
+	ValueArgKey             = "value"
+	OutputArgKey            = "output"
+	RunStepName             = "run"
+	PlanStepName            = "plan"
+	ShowStepName            = "show"
+	PolicyCheckStepName     = "policy_check"
+	ApplyStepName           = "apply"
+	InitStepName            = "init"
+	EnvStepName             = "env"
+	MultiEnvStepName        = "multienv"
+	ImportStepName          = "import"
+	StateRmStepName         = "state_rm"
+	PinCheckStepName        = "pin_check"
+	ProvidersSchemaStepName = "providers_schema"
+	WasmStepName            = "wasm"
+	ModuleArgKey            = "module"
+	ArgsArgKey              = "args"
+	EnvArgKey               = "env"
+	ShellArgKey             = "shell"
+	ShellArgsArgKey         = "shellArgs"
 )
 
 /*
@@ -59,6 +66,11 @@ Step represents a single action/command to perform. In YAML, it can be set as
   - run:
     command: my custom command
     output: hide
+  - wasm:
+    module: checks/validate.wasm
+    args: [--strict]
+    env: [AWS_REGION]
+    output: hide
 
 3. A map for a built-in command and extra_args:
   - plan:
@@ -113,7 +125,9 @@ func (s Step) validStepName(stepName string) bool {
 		stepName == ShowStepName ||
 		stepName == PolicyCheckStepName ||
 		stepName == ImportStepName ||
-		stepName == StateRmStepName
+		stepName == StateRmStepName ||
+		stepName == PinCheckStepName ||
+		stepName == ProvidersSchemaStepName
 }
 
 func (s Step) Validate() error {
@@ -195,6 +209,10 @@ func (s Step) Validate() error {
 		// Sort so tests can be deterministic.
 		sort.Strings(argKeys)
 
+		if stepName == WasmStepName {
+			return validateWasmStep(args)
+		}
+
 		// Validate keys common for all the steps.
 		if utils.SlicesContains(argKeys, ShellArgKey) && !utils.SlicesContains(argKeys, CommandArgKey) {
 			return fmt.Errorf("workflow steps only support %q key in combination with %q key",
@@ -317,6 +335,64 @@ func (s Step) Validate() error {
 	return errors.New("step element is empty")
 }
 
+// validateWasmStep validates the args of a wasm step.
+func validateWasmStep(args map[string]interface{}) error {
+	var argKeys []string
+	for k := range args {
+		argKeys = append(argKeys, k)
+	}
+	// Sort so tests can be deterministic.
+	sort.Strings(argKeys)
+
+	for _, k := range argKeys {
+		switch k {
+		case ModuleArgKey:
+			module, ok := args[k].(string)
+			if !ok || module == "" {
+				return fmt.Errorf("%q step %q option must be a non-empty string", WasmStepName, ModuleArgKey)
+			}
+			// The module is run with access to the project dir so it must
+			// come from the repo, not from the Atlantis server.
+			if !filepath.IsLocal(module) {
+				return fmt.Errorf("%q step %q option must be a path relative to the project dir that doesn't leave it, found %q", WasmStepName, ModuleArgKey, module)
+			}
+		case ArgsArgKey, EnvArgKey:
+			list, ok := args[k].([]interface{})
+			if !ok {
+				return fmt.Errorf("%q step %q option must be a list of strings, found %v", WasmStepName, k, args[k])
+			}
+			for _, e := range list {
+				if _, ok := e.(string); !ok {
+					return fmt.Errorf("%q step %q option must contain only strings, found %v", WasmStepName, k, e)
+				}
+			}
+		case OutputArgKey:
+			if v, ok := args[k].(string); !ok || !(v == valid.PostProcessRunOutputShow || v == valid.PostProcessRunOutputHide) {
+				return fmt.Errorf("wasm step %q option must be %q or %q",
+					OutputArgKey, valid.PostProcessRunOutputShow, valid.PostProcessRunOutputHide)
+			}
+		default:
+			return fmt.Errorf("%q steps only support keys %q, %q, %q and %q, found key %q",
+				WasmStepName, ModuleArgKey, ArgsArgKey, EnvArgKey, OutputArgKey, k)
+		}
+	}
+	if _, ok := args[ModuleArgKey]; !ok {
+		return fmt.Errorf("%q step must have a %q key set", WasmStepName, ModuleArgKey)
+	}
+	return nil
+}
+
+// toStrings converts a list parsed from YAML or JSON into a slice of strings.
+// It must only be called after validation.
+func toStrings(list interface{}) []string {
+	elems, _ := list.([]interface{})
+	var strs []string
+	for _, e := range elems {
+		strs = append(strs, e.(string))
+	}
+	return strs
+}
+
 func (s Step) ToValid() valid.Step {
 	// This will trigger in case #1 (see Step docs).
 	if s.Key != nil {
@@ -349,7 +425,12 @@ func (s Step) ToValid() valid.Step {
 					ShellArgs: []string{"-c"},
 				}
 			}
-			if step.StepName == RunStepName && step.Output == "" {
+			if module, ok := stepArgs[ModuleArgKey].(string); ok {
+				step.WasmModule = module
+				step.WasmArgs = toStrings(stepArgs[ArgsArgKey])
+				step.WasmEnv = toStrings(stepArgs[EnvArgKey])
+			}
+			if (step.StepName == RunStepName || step.StepName == WasmStepName) && step.Output == "" {
 				step.Output = valid.PostProcessRunOutputShow
 			}
 
@@ -479,6 +479,75 @@ func TestStep_Validate(t *testing.T) {
 			},
 			expErr: "\"run\" step \"shellArgs\" option must contain only strings, found 42\n",
 		},
+		{
+			description: "wasm step with no module",
+			input: raw.Step{
+				CommandMap: WasmType{
+					"wasm": {
+						"args": []interface{}{"-v"},
+					},
+				},
+			},
+			expErr: "\"wasm\" step must have a \"module\" key set",
+		},
+		{
+			description: "wasm step with an absolute module",
+			input: raw.Step{
+				CommandMap: WasmType{
+					"wasm": {
+						"module": "/usr/local/bin/check.wasm",
+					},
+				},
+			},
+			expErr: "\"wasm\" step \"module\" option must be a path relative to the project dir that doesn't leave it, found \"/usr/local/bin/check.wasm\"",
+		},
+		{
+			description: "wasm step with a module outside the project dir",
+			input: raw.Step{
+				CommandMap: WasmType{
+					"wasm": {
+						"module": "checks/../../check.wasm",
+					},
+				},
+			},
+			expErr: "\"wasm\" step \"module\" option must be a path relative to the project dir that doesn't leave it, found \"checks/../../check.wasm\"",
+		},
+		{
+			description: "wasm step with env not a list",
+			input: raw.Step{
+				CommandMap: WasmType{
+					"wasm": {
+						"module": "check.wasm",
+						"env":    "AWS_REGION",
+					},
+				},
+			},
+			expErr: "\"wasm\" step \"env\" option must be a list of strings, found AWS_REGION",
+		},
+		{
+			description: "wasm step with shell",
+			input: raw.Step{
+				CommandMap: WasmType{
+					"wasm": {
+						"module": "check.wasm",
+						"shell":  "bash",
+					},
+				},
+			},
+			expErr: "\"wasm\" steps only support keys \"module\", \"args\", \"env\" and \"output\", found key \"shell\"",
+		},
+		{
+			description: "wasm step with strip_refreshing output",
+			input: raw.Step{
+				CommandMap: WasmType{
+					"wasm": {
+						"module": "check.wasm",
+						"output": "strip_refreshing",
+					},
+				},
+			},
+			expErr: "wasm step \"output\" option must be \"show\" or \"hide\"",
+		},
 		{
 			// For atlantis.yaml v2, this wouldn't parse, but now there should
 			// be no error.
@@ -695,6 +764,25 @@ func TestStep_ToValid(t *testing.T) {
 				Output:     "hide",
 			},
 		},
+		{
+			description: "wasm step",
+			input: raw.Step{
+				CommandMap: WasmType{
+					"wasm": {
+						"module": "checks/validate.wasm",
+						"args":   []interface{}{"--strict"},
+						"env":    []interface{}{"AWS_REGION"},
+					},
+				},
+			},
+			exp: valid.Step{
+				StepName:   "wasm",
+				WasmModule: "checks/validate.wasm",
+				WasmArgs:   []string{"--strict"},
+				WasmEnv:    []string{"AWS_REGION"},
+				Output:     "show",
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
@@ -707,3 +795,4 @@ type MapType map[string]map[string][]string
 type EnvType map[string]map[string]interface{}
 type RunType map[string]map[string]interface{}
 type MultiEnvType map[string]map[string]interface{}
+type WasmType map[string]map[string]interface{}
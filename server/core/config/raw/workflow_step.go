@@ -79,6 +79,8 @@ func (s WorkflowHook) ToValid() *valid.WorkflowHook {
 			Shell:           s.StringVal["shell"],
 			ShellArgs:       s.StringVal["shellArgs"],
 			Commands:        s.StringVal["commands"],
+			// YAML booleans are unmarshalled as "true" or "false".
+			CommentOnFailure: s.StringVal["comment_on_failure"] == "true",
 		}
 	}
 
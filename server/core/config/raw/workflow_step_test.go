@@ -138,6 +138,20 @@ func TestWorkflowHook_ToValid(t *testing.T) {
 				RunCommand: "my 'run command'",
 			},
 		},
+		{
+			description: "run step commented on failure",
+			input: raw.WorkflowHook{
+				StringVal: map[string]string{
+					"run":                "my 'run command'",
+					"comment_on_failure": "true",
+				},
+			},
+			exp: &valid.WorkflowHook{
+				StepName:         "run",
+				RunCommand:       "my 'run command'",
+				CommentOnFailure: true,
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
@@ -0,0 +1,35 @@
+package valid
+
+import (
+	"strings"
+	"time"
+)
+
+// ApplyRequirementPluginPrefix prefixes the apply requirements checked by
+// apply requirement plugins, ex. plugin:cab-approved.
+const ApplyRequirementPluginPrefix = "plugin:"
+
+// DefaultApplyRequirementPluginTimeout is how long an apply requirement
+// plugin can take to respond if it doesn't configure a timeout.
+const DefaultApplyRequirementPluginTimeout = 30 * time.Second
+
+// ApplyRequirementPlugin checks an apply requirement Atlantis doesn't know
+// about, ex. that the change ticket was approved in an external system. It's
+// either an executable or an HTTP endpoint.
+type ApplyRequirementPlugin struct {
+	Name string
+	// Command is the executable run with the name of the requirement as its
+	// only argument.
+	Command string
+	// URL is the endpoint the requirement is POSTed to.
+	URL string
+	// Headers are added to the requests to URL.
+	Headers map[string]string
+	Timeout time.Duration
+}
+
+// ApplyRequirementPluginName returns the name of the plugin that checks req
+// and whether req is checked by a plugin at all.
+func ApplyRequirementPluginName(req string) (string, bool) {
+	return strings.CutPrefix(req, ApplyRequirementPluginPrefix)
+}
@@ -0,0 +1,162 @@
+package valid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ApplyWindow is a period during which a project may be applied. Schedule is
+// a cron expression, minute hour day-of-month month day-of-week, matching the
+// minutes of the window, ex. "* 9-16 * * 1-5" for business hours.
+type ApplyWindow struct {
+	Schedule string
+	// Timezone is the IANA name of the timezone the schedule is in. If empty,
+	// UTC is used.
+	Timezone string
+
+	location *time.Location
+	fields   [5]cronField
+}
+
+// cronField is the set of values matched by a field of a cron expression.
+type cronField struct {
+	values map[int]bool
+	// any is true if the field is *, which matters for the day fields.
+	any bool
+}
+
+// cronFieldRanges are the min and max values of each field.
+var cronFieldRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// NewApplyWindow parses schedule in timezone.
+func NewApplyWindow(schedule string, timezone string) (ApplyWindow, error) {
+	w := ApplyWindow{Schedule: schedule, Timezone: timezone, location: time.UTC}
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return w, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+		w.location = location
+	}
+
+	parts := strings.Fields(schedule)
+	if len(parts) != 5 {
+		return w, fmt.Errorf("schedule %q must have 5 fields: minute hour day-of-month month day-of-week", schedule)
+	}
+	for i, part := range parts {
+		field, err := parseCronField(part, cronFieldRanges[i][0], cronFieldRanges[i][1])
+		if err != nil {
+			return w, fmt.Errorf("schedule %q: %w", schedule, err)
+		}
+		w.fields[i] = field
+	}
+	// Both 0 and 7 are Sunday.
+	if w.fields[4].values[7] {
+		w.fields[4].values[0] = true
+	}
+	return w, nil
+}
+
+// parseCronField parses a comma separated list of *, values, ranges and
+// steps, ex. 1-5 or */15.
+func parseCronField(field string, lowest int, highest int) (cronField, error) {
+	parsed := cronField{values: make(map[int]bool), any: field == "*"}
+	if lowest == 0 && highest == 6 {
+		// Allow 7 for Sunday.
+		highest = 7
+	}
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return parsed, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		start, end := lowest, highest
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = strconv.Atoi(startPart); err != nil {
+				return parsed, fmt.Errorf("invalid value %q", startPart)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endPart); err != nil {
+					return parsed, fmt.Errorf("invalid value %q", endPart)
+				}
+			} else if hasStep {
+				end = highest
+			}
+		}
+		if start < lowest || end > highest || start > end {
+			return parsed, fmt.Errorf("%q is out of range %d-%d", item, lowest, highest)
+		}
+		for v := start; v <= end; v += step {
+			parsed.values[v] = true
+		}
+	}
+	return parsed, nil
+}
+
+// Location returns the timezone of the schedule.
+func (w ApplyWindow) Location() *time.Location {
+	if w.location == nil {
+		return time.UTC
+	}
+	return w.location
+}
+
+// Contains returns true if t is within the window.
+func (w ApplyWindow) Contains(t time.Time) bool {
+	t = t.In(w.Location())
+	if !w.fields[0].values[t.Minute()] || !w.fields[1].values[t.Hour()] || !w.fields[3].values[int(t.Month())] {
+		return false
+	}
+	dom := w.fields[2].values[t.Day()]
+	dow := w.fields[4].values[int(t.Weekday())]
+	// Like cron, if both day fields are restricted either one may match.
+	if !w.fields[2].any && !w.fields[4].any {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// String returns the schedule and its timezone.
+func (w ApplyWindow) String() string {
+	return fmt.Sprintf("%s %s", w.Schedule, w.Location())
+}
+
+// maxApplyWindowSearch is how far ahead NextApplyWindow looks.
+const maxApplyWindowSearch = 366 * 24 * time.Hour
+
+// InApplyWindow returns true if there are no windows or now is within one of
+// them.
+func InApplyWindow(windows []ApplyWindow, now time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if w.Contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextApplyWindow returns the start of the next minute after now within one
+// of windows. It returns false if there's none within a year.
+func NextApplyWindow(windows []ApplyWindow, now time.Time) (time.Time, bool) {
+	start := now.Truncate(time.Minute).Add(time.Minute)
+	for t := start; t.Sub(start) < maxApplyWindowSearch; t = t.Add(time.Minute) {
+		if InApplyWindow(windows, t) {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
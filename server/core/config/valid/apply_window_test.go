@@ -0,0 +1,63 @@
+package valid_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestApplyWindow_Contains(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	Ok(t, err)
+	w, err := valid.NewApplyWindow("* 9-16 * * 1-5", "Europe/Berlin")
+	Ok(t, err)
+
+	cases := []struct {
+		description string
+		t           time.Time
+		exp         bool
+	}{
+		{"monday morning", time.Date(2026, 10, 19, 9, 0, 0, 0, berlin), true},
+		{"monday evening", time.Date(2026, 10, 19, 17, 0, 0, 0, berlin), false},
+		{"friday before close", time.Date(2026, 10, 23, 16, 59, 0, 0, berlin), true},
+		{"saturday", time.Date(2026, 10, 24, 12, 0, 0, 0, berlin), false},
+		{"monday morning in utc", time.Date(2026, 10, 19, 7, 30, 0, 0, time.UTC), true},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, w.Contains(c.t))
+		})
+	}
+}
+
+// Like cron, if both the day of month and the day of week are restricted
+// either one may match.
+func TestApplyWindow_ContainsDayFields(t *testing.T) {
+	w, err := valid.NewApplyWindow("* * 1 * 7", "")
+	Ok(t, err)
+	Assert(t, w.Contains(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)), "exp the 1st to match")
+	Assert(t, w.Contains(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)), "exp sunday to match")
+	Assert(t, !w.Contains(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)), "exp monday the 19th not to match")
+}
+
+func TestNextApplyWindow(t *testing.T) {
+	w, err := valid.NewApplyWindow("* 9-16 * * 1-5", "")
+	Ok(t, err)
+	windows := []valid.ApplyWindow{w}
+
+	// Saturday afternoon.
+	now := time.Date(2026, 10, 17, 15, 4, 30, 0, time.UTC)
+	Assert(t, !valid.InApplyWindow(windows, now), "exp saturday to be outside the window")
+	next, ok := valid.NextApplyWindow(windows, now)
+	Assert(t, ok, "exp a next window")
+	Equals(t, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), next)
+
+	Assert(t, valid.InApplyWindow(nil, now), "exp no windows to always be open")
+
+	never, err := valid.NewApplyWindow("* * 31 2 *", "")
+	Ok(t, err)
+	_, ok = valid.NextApplyWindow([]valid.ApplyWindow{never}, now)
+	Assert(t, !ok, "exp no next window")
+}
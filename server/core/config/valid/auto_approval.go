@@ -0,0 +1,61 @@
+package valid
+
+import (
+	"path"
+	"slices"
+	"time"
+)
+
+// DefaultAutoApprovalActions are the actions auto-approval rules match if
+// they don't set any, so plans deleting or replacing resources are never
+// low risk unless a rule says so.
+var DefaultAutoApprovalActions = []string{RiskActionCreate, RiskActionUpdate}
+
+// AutoApproval lets low-risk plans be applied without the approved
+// requirement. A plan is low risk if every resource change it makes matches
+// one of the rules.
+type AutoApproval struct {
+	Rules []AutoApprovalRule
+	// ApplyAfter is how long after they're planned low-risk plans are
+	// applied automatically. If zero, they're only applied with a comment.
+	ApplyAfter time.Duration
+}
+
+// AutoApprovalRule matches the resource changes that are low risk.
+type AutoApprovalRule struct {
+	// Name identifies the rule in comments and the audit log.
+	Name string
+	// Actions are the risk actions matched. If empty,
+	// DefaultAutoApprovalActions are matched.
+	Actions []string
+	// ResourceTypes are glob patterns of the resource types matched, ex.
+	// aws_route53_record. If empty, any type matches.
+	ResourceTypes []string
+	// OnlyTags restricts the rule to updates that only change the tags of
+	// resources.
+	OnlyTags bool
+}
+
+// Matches returns true if a change of a resource of type resourceType with
+// action matches the rule. onlyTags is true if the change only updates the
+// resource's tags.
+func (r AutoApprovalRule) Matches(resourceType string, action string, onlyTags bool) bool {
+	actions := r.Actions
+	if len(actions) == 0 {
+		actions = DefaultAutoApprovalActions
+	}
+	if !slices.Contains(actions, action) || (r.OnlyTags && (action != RiskActionUpdate || !onlyTags)) {
+		return false
+	}
+	if len(r.ResourceTypes) == 0 {
+		return true
+	}
+	for _, pattern := range r.ResourceTypes {
+		// Patterns are checked when the config is parsed so the error can be
+		// ignored.
+		if matched, _ := path.Match(pattern, resourceType); matched {
+			return true
+		}
+	}
+	return false
+}
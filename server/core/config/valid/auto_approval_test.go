@@ -0,0 +1,22 @@
+package valid_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAutoApprovalRule_Matches(t *testing.T) {
+	rule := valid.AutoApprovalRule{ResourceTypes: []string{"aws_route53_*"}}
+	Equals(t, true, rule.Matches("aws_route53_record", valid.RiskActionCreate, false))
+	Equals(t, false, rule.Matches("aws_s3_bucket", valid.RiskActionCreate, false))
+	// Rules without actions don't match deletes or replaces.
+	Equals(t, false, rule.Matches("aws_route53_record", valid.RiskActionDelete, false))
+	Equals(t, true, valid.AutoApprovalRule{Actions: []string{valid.RiskActionDelete}}.Matches("aws_route53_record", valid.RiskActionDelete, false))
+
+	tags := valid.AutoApprovalRule{OnlyTags: true}
+	Equals(t, true, tags.Matches("aws_s3_bucket", valid.RiskActionUpdate, true))
+	Equals(t, false, tags.Matches("aws_s3_bucket", valid.RiskActionUpdate, false))
+	Equals(t, false, tags.Matches("aws_s3_bucket", valid.RiskActionCreate, false))
+}
@@ -12,4 +12,7 @@ const (
 type AutoDiscover struct {
 	Mode        AutoDiscoverMode
 	IgnorePaths []string
+	// SharedModuleDirs are patterns matching directories, such as vendored
+	// module copies, that hold shared modules rather than projects.
+	SharedModuleDirs []string
 }
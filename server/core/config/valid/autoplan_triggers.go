@@ -0,0 +1,52 @@
+package valid
+
+import "regexp"
+
+// AutoplanTriggers suppresses autoplans of pull requests that don't need
+// them or that would plan too many projects at once. Those pull requests can
+// still be planned by commenting.
+type AutoplanTriggers struct {
+	// SkipLabels are the labels of pull requests that aren't autoplanned.
+	SkipLabels []string
+	// SkipTitlePatterns match the titles of pull requests that aren't
+	// autoplanned, ex. ^docs:.
+	SkipTitlePatterns []*regexp.Regexp
+	// MaxProjects is how many projects a pull request can modify and still be
+	// autoplanned. 0 means there's no limit.
+	MaxProjects int
+}
+
+// SkipLabel returns the first of labels that suppresses autoplans, if any.
+func (a *AutoplanTriggers) SkipLabel(labels []string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	for _, skipLabel := range a.SkipLabels {
+		for _, label := range labels {
+			if label == skipLabel {
+				return label, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SkipTitlePattern returns the first pattern matching title that suppresses
+// autoplans, if any.
+func (a *AutoplanTriggers) SkipTitlePattern(title string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	for _, pattern := range a.SkipTitlePatterns {
+		if pattern.MatchString(title) {
+			return pattern.String(), true
+		}
+	}
+	return "", false
+}
+
+// TooManyProjects returns true if a pull request modifying numProjects
+// projects shouldn't be autoplanned.
+func (a *AutoplanTriggers) TooManyProjects(numProjects int) bool {
+	return a != nil && a.MaxProjects > 0 && numProjects > a.MaxProjects
+}
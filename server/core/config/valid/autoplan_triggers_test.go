@@ -0,0 +1,39 @@
+package valid_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAutoplanTriggers(t *testing.T) {
+	var unset *valid.AutoplanTriggers
+	_, skipped := unset.SkipLabel([]string{"wip"})
+	Equals(t, false, skipped)
+	_, skipped = unset.SkipTitlePattern("docs: fix typo")
+	Equals(t, false, skipped)
+	Equals(t, false, unset.TooManyProjects(100))
+
+	triggers := &valid.AutoplanTriggers{
+		SkipLabels:        []string{"wip", "no-autoplan"},
+		SkipTitlePatterns: []*regexp.Regexp{regexp.MustCompile(`^docs:`), regexp.MustCompile(`\[skip plan\]`)},
+		MaxProjects:       3,
+	}
+	label, skipped := triggers.SkipLabel([]string{"bug", "no-autoplan"})
+	Equals(t, true, skipped)
+	Equals(t, "no-autoplan", label)
+	_, skipped = triggers.SkipLabel([]string{"bug"})
+	Equals(t, false, skipped)
+
+	pattern, skipped := triggers.SkipTitlePattern("Rename bucket [skip plan]")
+	Equals(t, true, skipped)
+	Equals(t, `\[skip plan\]`, pattern)
+	_, skipped = triggers.SkipTitlePattern("Add docs: bucket")
+	Equals(t, false, skipped)
+
+	Equals(t, false, triggers.TooManyProjects(3))
+	Equals(t, true, triggers.TooManyProjects(4))
+	Equals(t, false, (&valid.AutoplanTriggers{}).TooManyProjects(100))
+}
@@ -0,0 +1,58 @@
+package valid
+
+import (
+	"fmt"
+	"time"
+)
+
+// Canary makes a project the canary of the other projects in its dir, ex.
+// the same module applied in one workspace per region. The canary is applied
+// first and the others wait until it's promoted.
+type Canary struct {
+	// SoakTime is how long the canary runs before the other projects are
+	// applied automatically. If zero, they wait until someone comments
+	// atlantis apply --promote-canary.
+	SoakTime time.Duration
+}
+
+// ProjectCanary is the canary of the dir of a project.
+type ProjectCanary struct {
+	Canary
+	// ProjectName and Workspace identify the canary project in the dir.
+	ProjectName string
+	Workspace   string
+}
+
+// CanaryOf returns the canary of the projects in dir or nil if none of them
+// is a canary.
+func (r RepoCfg) CanaryOf(dir string) *ProjectCanary {
+	for _, p := range r.Projects {
+		if p.Dir == dir && p.Canary != nil {
+			return &ProjectCanary{Canary: *p.Canary, ProjectName: p.GetName(), Workspace: p.Workspace}
+		}
+	}
+	return nil
+}
+
+// ValidateCanaries returns an error if a dir has more than one canary.
+func (r RepoCfg) ValidateCanaries() error {
+	canaries := make(map[string]string)
+	for _, p := range r.Projects {
+		if p.Canary == nil {
+			continue
+		}
+		if other, ok := canaries[p.Dir]; ok {
+			return fmt.Errorf("dir %q has more than one canary: the projects %s and %s", p.Dir, other, describeProject(p))
+		}
+		canaries[p.Dir] = describeProject(p)
+	}
+	return nil
+}
+
+// describeProject names p in errors.
+func describeProject(p Project) string {
+	if p.Name != nil {
+		return fmt.Sprintf("%q", *p.Name)
+	}
+	return fmt.Sprintf("of workspace %q", p.Workspace)
+}
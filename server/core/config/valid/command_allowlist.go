@@ -0,0 +1,59 @@
+package valid
+
+import (
+	"path"
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// CommandAllowlistCommands are the project commands a command allowlist can
+// restrict.
+var CommandAllowlistCommands = []string{"plan", "apply", "import", "state", "approve_policies", "force-unlock-state", "rollback", "version"}
+
+// CommandAllowlistRule restricts commands run on projects to users and teams.
+type CommandAllowlistRule struct {
+	// Commands are the commands the rule restricts, ex. apply.
+	Commands []string
+	// Dirs are glob patterns of the dirs of the projects the rule applies
+	// to, ex. prod/**. If empty, it applies to every project.
+	Dirs []string
+	// Allowed are the users and teams allowed to run the commands.
+	Allowed PolicyOwners
+}
+
+// Matches returns true if the rule restricts cmd on a project in dir.
+func (r CommandAllowlistRule) Matches(cmd string, dir string) bool {
+	if !slices.Contains(r.Commands, cmd) {
+		return false
+	}
+	if len(r.Dirs) == 0 {
+		return true
+	}
+	dir = path.Clean(dir)
+	for _, pattern := range r.Dirs {
+		// Patterns are validated when the config is parsed.
+		if doublestar.MatchUnvalidated(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandAllowlistFor returns the users and teams allowed to run cmd on a
+// project in dir according to rules, the union of the rules that match. It
+// returns nil if no rule restricts cmd on the project.
+func CommandAllowlistFor(rules []CommandAllowlistRule, cmd string, dir string) *PolicyOwners {
+	var allowed *PolicyOwners
+	for _, rule := range rules {
+		if !rule.Matches(cmd, dir) {
+			continue
+		}
+		if allowed == nil {
+			allowed = &PolicyOwners{}
+		}
+		allowed.Users = append(allowed.Users, rule.Allowed.Users...)
+		allowed.Teams = append(allowed.Teams, rule.Allowed.Teams...)
+	}
+	return allowed
+}
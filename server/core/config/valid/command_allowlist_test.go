@@ -0,0 +1,41 @@
+package valid_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCommandAllowlistRule_Matches(t *testing.T) {
+	rule := valid.CommandAllowlistRule{Commands: []string{"apply", "state"}, Dirs: []string{"prod/**", "*/db"}}
+	Assert(t, rule.Matches("apply", "prod"), "expected prod to match")
+	Assert(t, rule.Matches("state", "prod/vpc/"), "expected prod/vpc to match")
+	Assert(t, rule.Matches("apply", "staging/db"), "expected staging/db to match")
+	Assert(t, !rule.Matches("plan", "prod"), "expected plan not to match")
+	Assert(t, !rule.Matches("apply", "staging/vpc"), "expected staging/vpc not to match")
+
+	allDirs := valid.CommandAllowlistRule{Commands: []string{"apply"}}
+	Assert(t, allDirs.Matches("apply", "."), "expected rule without dirs to match the root")
+}
+
+func TestCommandAllowlistFor(t *testing.T) {
+	rules := []valid.CommandAllowlistRule{
+		{Commands: []string{"apply"}, Dirs: []string{"prod/**"}, Allowed: valid.PolicyOwners{Teams: []string{"sre"}}},
+		{Commands: []string{"apply", "state"}, Allowed: valid.PolicyOwners{Users: []string{"alice"}}},
+	}
+	Equals(t, &valid.PolicyOwners{Users: []string{"alice"}, Teams: []string{"sre"}}, valid.CommandAllowlistFor(rules, "apply", "prod/vpc"))
+	Equals(t, &valid.PolicyOwners{Users: []string{"alice"}}, valid.CommandAllowlistFor(rules, "apply", "staging"))
+	Equals(t, (*valid.PolicyOwners)(nil), valid.CommandAllowlistFor(rules, "plan", "prod/vpc"))
+	Equals(t, (*valid.PolicyOwners)(nil), valid.CommandAllowlistFor(nil, "apply", "prod/vpc"))
+}
+
+func TestGlobalCfg_CommandAllowlist(t *testing.T) {
+	global := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	Equals(t, []valid.CommandAllowlistRule(nil), global.CommandAllowlist("github.com/owner/repo"))
+
+	rules := []valid.CommandAllowlistRule{{Commands: []string{"apply"}, Allowed: valid.PolicyOwners{Teams: []string{"sre"}}}}
+	global.Repos = append(global.Repos, valid.Repo{ID: "github.com/owner/repo", CommandAllowlist: rules})
+	Equals(t, rules, global.CommandAllowlist("github.com/owner/repo"))
+	Equals(t, []valid.CommandAllowlistRule(nil), global.CommandAllowlist("github.com/owner/other"))
+}
@@ -0,0 +1,52 @@
+package valid
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// commentTemplateFetchTimeout is how long fetchJSON waits for a response.
+const commentTemplateFetchTimeout = 10 * time.Second
+
+// CommentTemplates are Go templates rendered above and below every comment
+// with the results of a command, ex. to link runbooks or a change ticket.
+type CommentTemplates struct {
+	Header string
+	Footer string
+}
+
+// ParseCommentTemplate parses text as a comment template. Besides the sprig
+// functions, templates can call fetchJSON with a URL to GET it and decode
+// its JSON body, ex. to look up who's on call.
+func ParseCommentTemplate(name string, text string) (*template.Template, error) {
+	client := &http.Client{Timeout: commentTemplateFetchTimeout}
+	return template.New(name).
+		Funcs(sprig.TxtFuncMap()).
+		Funcs(template.FuncMap{
+			"fetchJSON": func(url string) (interface{}, error) {
+				return fetchJSON(client, url)
+			},
+		}).
+		Parse(text)
+}
+
+func fetchJSON(client *http.Client, url string) (interface{}, error) {
+	resp, err := client.Get(url) // nolint: noctx
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s responded with status %d", url, resp.StatusCode)
+	}
+	var body interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding response of %s: %w", url, err)
+	}
+	return body, nil
+}
@@ -0,0 +1,21 @@
+package valid
+
+// CostBudget blocks applies of plans whose cost estimate increases the
+// monthly cost by more than the allowed amount or percentage.
+type CostBudget struct {
+	// MaxMonthlyIncrease is the largest allowed increase of the monthly cost
+	// in the currency of the estimate. It's nil if it isn't limited.
+	MaxMonthlyIncrease *float64
+	// MaxPercentIncrease is the largest allowed increase of the monthly cost
+	// as a percentage of the current cost. It's nil if it isn't limited.
+	MaxPercentIncrease *float64
+	// OverrideUsers and OverrideTeams may apply plans over budget, or approve
+	// the pull request so they can be applied.
+	OverrideUsers []string
+	OverrideTeams []string
+}
+
+// Overriders returns the users and teams that may override the budget.
+func (c CostBudget) Overriders() PolicyOwners {
+	return PolicyOwners{Users: c.OverrideUsers, Teams: c.OverrideTeams}
+}
@@ -0,0 +1,27 @@
+package valid
+
+import "path"
+
+// DestroyGuard requires an explicit confirmation before applying plans that
+// delete or replace resources of the protected types, ex. databases.
+type DestroyGuard struct {
+	// ResourceTypes are the protected resource types. They're glob patterns,
+	// ex. aws_db_* or google_sql_database_instance.
+	ResourceTypes []string
+	// RequireOtherUser is true if the confirmation must come from a user
+	// other than the pull request author.
+	RequireOtherUser bool
+}
+
+// Protects returns true if resources of type resourceType are protected by
+// the guard.
+func (d DestroyGuard) Protects(resourceType string) bool {
+	for _, pattern := range d.ResourceTypes {
+		// Patterns are checked when the config is parsed so the error can be
+		// ignored.
+		if matched, _ := path.Match(pattern, resourceType); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package valid
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// PullNumPlaceholder and HeadBranchPlaceholder are replaced in the workspace
+// patterns of DestroyOnClose by the number and head branch of the pull
+// request.
+const (
+	PullNumPlaceholder    = "{pull_num}"
+	HeadBranchPlaceholder = "{head_branch}"
+)
+
+// DestroyOnClose destroys the workspaces of a pull request when it's closed,
+// ex. the workspaces of workspace-per-branch patterns.
+type DestroyOnClose struct {
+	// Workspaces are glob patterns of the workspaces to destroy, ex.
+	// pr-{pull_num}-*. They contain at least one placeholder so only the
+	// workspaces of the pull request match.
+	Workspaces []string
+}
+
+// Matches returns true if workspace belongs to the pull request pullNum
+// whose head branch is headBranch and should be destroyed.
+func (d DestroyOnClose) Matches(workspace string, pullNum int, headBranch string) bool {
+	replacer := strings.NewReplacer(PullNumPlaceholder, strconv.Itoa(pullNum), HeadBranchPlaceholder, headBranch)
+	for _, pattern := range d.Workspaces {
+		// Patterns are checked when the config is parsed so the error can be
+		// ignored.
+		if matched, _ := path.Match(replacer.Replace(pattern), workspace); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -28,9 +29,50 @@ const PolicyCheckKey = "policy_check"
 const CustomPolicyCheckKey = "custom_policy_check"
 const AutoDiscoverKey = "autodiscover"
 const SilencePRCommentsKey = "silence_pr_comments"
+const NotificationsKey = "notifications"
+const AllowTargetKey = "allow_target"
+const AllowStateReadKey = "allow_state_read"
+const CodeOwnersKey = "codeowners"
+const ExtraArgsKey = "extra_args"
+const PreviewEnvironmentKey = "preview_environment"
+const TerraformDistributionKey = "terraform_distribution"
+const TerraformVersionKey = "terraform_version"
+const ProjectResolverKey = "project_resolver"
 
 var AllowedSilencePRComments = []string{"plan", "apply"}
 
+// Comment layouts lay out the results of commands run on several projects.
+// Combined renders them in one comment, Collapsed in one comment with each
+// project collapsed, FailuresExpanded likewise but with failed projects
+// expanded and PerProject in one comment per project.
+const (
+	CommentLayoutCombined         = "combined"
+	CommentLayoutCollapsed        = "collapsed"
+	CommentLayoutFailuresExpanded = "failures_expanded"
+	CommentLayoutPerProject       = "per_project"
+)
+
+var CommentLayouts = []string{CommentLayoutCombined, CommentLayoutCollapsed, CommentLayoutFailuresExpanded, CommentLayoutPerProject}
+
+// GitHub status modes set how the status of commands is reported on GitHub.
+// Statuses reports them as commit statuses, Checks as check runs and Both as
+// both, with the same names, to migrate required checks from one to the other.
+const (
+	GithubStatusModeStatuses = "statuses"
+	GithubStatusModeChecks   = "checks"
+	GithubStatusModeBoth     = "both"
+)
+
+var GithubStatusModes = []string{GithubStatusModeStatuses, GithubStatusModeChecks, GithubStatusModeBoth}
+
+// ExtraArgsSteps are the built-in steps projects can set default extra
+// arguments for.
+var ExtraArgsSteps = []string{"init", "plan", "apply", "import"}
+
+// ManagedExtraArgs are the flags Atlantis sets itself so they can't be set as
+// default extra arguments by repo config.
+var ManagedExtraArgs = []string{"-out", "-input", "-no-color", "-chdir", "-target", "-state", "-state-out", "-auto-approve"}
+
 // DefaultAtlantisFile is the default name of the config file for each repo.
 const DefaultAtlantisFile = "atlantis.yaml"
 
@@ -48,11 +90,29 @@ type GlobalCfg struct {
 	PolicySets PolicySets
 	Metrics    Metrics
 	TeamAuthz  TeamAuthz
+	// PinningPolicy configures the pin_check step. If nil the
+	// DefaultPinningPolicy is used.
+	PinningPolicy *PinningPolicy
+	// ApplyRequirementPlugins are the apply requirement plugins, by name.
+	ApplyRequirementPlugins map[string]ApplyRequirementPlugin
 }
 
 type Metrics struct {
 	Statsd     *Statsd
 	Prometheus *Prometheus
+	OTLP       *OTLP
+	Datadog    *Datadog
+	// ProjectLabels are the keys of the project labels added as tags to the
+	// metrics of project commands.
+	ProjectLabels []string
+}
+
+// Datadog sends metrics with their tags to a DogStatsD agent.
+type Datadog struct {
+	Host string
+	Port string
+	// Tags are added to every metric, ex. env.
+	Tags map[string]string
 }
 
 type Statsd struct {
@@ -64,6 +124,22 @@ type Prometheus struct {
 	Endpoint string
 }
 
+// OTLP exports metrics to an OpenTelemetry collector.
+type OTLP struct {
+	// Endpoint is the base URL of the collector's OTLP/HTTP receiver, ex.
+	// http://otel-collector:4318.
+	Endpoint string
+	// Headers are added to each export request, ex. for authentication.
+	Headers map[string]string
+	// Interval is how often metrics are exported. If zero,
+	// DefaultOTLPInterval is used.
+	Interval time.Duration
+}
+
+// DefaultOTLPInterval is how often metrics are exported to an OpenTelemetry
+// collector by default.
+const DefaultOTLPInterval = 60 * time.Second
+
 // Repo is the final parsed version of server-side repo config.
 type Repo struct {
 	// ID is the exact match id of this config.
@@ -80,6 +156,8 @@ type Repo struct {
 	PreWorkflowHooks          []*WorkflowHook
 	Workflow                  *Workflow
 	PostWorkflowHooks         []*WorkflowHook
+	PostPlanHooks             []*WorkflowHook
+	PostApplyHooks            []*WorkflowHook
 	AllowedWorkflows          []string
 	AllowedOverrides          []string
 	AllowCustomWorkflows      *bool
@@ -90,6 +168,70 @@ type Repo struct {
 	CustomPolicyCheck         *bool
 	AutoDiscover              *AutoDiscover
 	SilencePRComments         []string
+	// AllowTarget is true if users may run plan and apply with --target.
+	AllowTarget *bool
+	// AllowStateRead is true if users may run state list and state show.
+	AllowStateRead *bool
+	// DestroyGuard requires a confirmation before applying plans that delete
+	// or replace protected resources.
+	DestroyGuard *DestroyGuard
+	// CostBudget blocks applies of plans whose cost estimate increases the
+	// monthly cost too much.
+	CostBudget *CostBudget
+	// RiskScoring scores plans and requires more approvals to apply risky
+	// ones.
+	RiskScoring *RiskScoring
+	// AutoApproval lets low-risk plans be applied without approval.
+	AutoApproval *AutoApproval
+	// Rollback allows the rollback command and sets the approvals needed to
+	// apply its plans.
+	Rollback *Rollback
+	// DestroyOnClose destroys the workspaces of pull requests when they're
+	// closed.
+	DestroyOnClose *DestroyOnClose
+	// CommentLayout is how the results of commands run on several projects
+	// are laid out in comments. If empty, they're combined in one comment.
+	CommentLayout string
+	// CommentTemplates are rendered above and below the comments with the
+	// results of commands.
+	CommentTemplates *CommentTemplates
+	// GithubStatusMode is how the status of commands is reported on GitHub.
+	// If empty, they're reported as commit statuses.
+	GithubStatusMode string
+	// TerraformDistribution and TerraformVersion pin the distribution and
+	// version projects are planned and applied with. Repo config can only
+	// set them if allowed to override them.
+	TerraformDistribution *string
+	TerraformVersion      *version.Version
+	// CodeOwners is true if plan and apply of projects without owners in the
+	// repo config must be run or approved by their owners in the CODEOWNERS
+	// file of the base branch.
+	CodeOwners *bool
+	// SuggestRepoConfig is true if autoplans of pull requests of repos
+	// without a repo config comment an atlantis.yaml capturing the projects
+	// discovered in the repo.
+	SuggestRepoConfig *bool
+	// InlineReviewComments is true if plans that delete or replace resources
+	// comment on the lines of the pull request's diff that declare them.
+	InlineReviewComments *bool
+	// AutoplanTriggers suppresses autoplans of some pull requests.
+	AutoplanTriggers *AutoplanTriggers
+	// CommandAllowlist restricts commands run on the projects of the repo to
+	// users and teams.
+	CommandAllowlist []CommandAllowlistRule
+	// AllowedExtraArgs are the only flags repo config may set as default
+	// extra arguments. If nil any flag that isn't denied is allowed.
+	AllowedExtraArgs []string
+	// DeniedExtraArgs are flags repo config may not set as default extra
+	// arguments in addition to ManagedExtraArgs.
+	DeniedExtraArgs []string
+	// RepoConfigFiles are the paths searched in order for the repo config
+	// if RepoConfigFile isn't set. Only set on the default repo config.
+	RepoConfigFiles []string
+	// WorkflowTemplate is the name of the workflow if it references capture
+	// groups of IDRegex. The workflow is looked up by the expanded name for
+	// each repo so Workflow is nil.
+	WorkflowTemplate string
 }
 
 type MergedProjectCfg struct {
@@ -115,6 +257,34 @@ type MergedProjectCfg struct {
 	PolicyCheck               bool
 	CustomPolicyCheck         bool
 	SilencePRComments         []string
+	PostPlanHooks             []*WorkflowHook
+	PostApplyHooks            []*WorkflowHook
+	DestroyGuard              *DestroyGuard
+	CostBudget                *CostBudget
+	RiskScoring               *RiskScoring
+	AutoApproval              *AutoApproval
+	Rollback                  *Rollback
+	Notifications             []Notification
+	Owners                    *PolicyOwners
+	CodeOwners                bool
+	InlineReviewComments      bool
+	// CommandAllowlist is the command allowlist of the server-side config
+	// and ProjectCommandAllowlist the one of the project in the repo
+	// config. A command must be allowed by both.
+	CommandAllowlist        []CommandAllowlistRule
+	ProjectCommandAllowlist []CommandAllowlistRule
+	ExtraArgs               map[string][]string
+	PRMetadataVars          string
+	ShareOutputs            bool
+	DependencyOutputs       string
+	ApplyWindows            []ApplyWindow
+	Tool                    string
+	PreviewEnvironment      *PreviewEnvironment
+	LockKeys                []string
+	Labels                  map[string]string
+	// Canary is the canary of the project's dir, which may be the project
+	// itself. It's nil if the dir has none.
+	Canary *ProjectCanary
 }
 
 // WorkflowHook is a map of custom run commands to run before or after workflows.
@@ -125,6 +295,9 @@ type WorkflowHook struct {
 	Shell           string
 	ShellArgs       string
 	Commands        string
+	// CommentOnFailure is true if the output of the hook is commented on the
+	// pull request when it fails.
+	CommentOnFailure bool
 }
 
 // DefaultApplyStage is the Atlantis default apply stage.
@@ -184,8 +357,50 @@ var DefaultStateRmStage = Stage{
 	},
 }
 
+// DefaultStateListStage is the Atlantis default state list stage. It isn't
+// configurable in workflows.
+var DefaultStateListStage = Stage{
+	Steps: []Step{
+		{
+			StepName: "init",
+		},
+		{
+			StepName: "state_list",
+		},
+	},
+}
+
+// DefaultStateShowStage is the Atlantis default state show stage. It isn't
+// configurable in workflows.
+var DefaultStateShowStage = Stage{
+	Steps: []Step{
+		{
+			StepName: "init",
+		},
+		{
+			StepName: "state_show",
+		},
+	},
+}
+
+// DefaultForceUnlockStateStage is the Atlantis default force-unlock-state
+// stage. It isn't configurable in workflows.
+var DefaultForceUnlockStateStage = Stage{
+	Steps: []Step{
+		{
+			StepName: "init",
+		},
+		{
+			StepName: "force_unlock",
+		},
+	},
+}
+
 type GlobalCfgArgs struct {
 	RepoConfigFile string
+	// RepoConfigFiles are the paths searched in order for the repo config of
+	// repos that don't set a repo_config_file.
+	RepoConfigFiles []string
 	// No longer a user option as of https://github.com/runatlantis/atlantis/pull/3911,
 	// but useful for tests to set to true to not require enumeration of allowed settings
 	// on the repo side
@@ -222,7 +437,7 @@ func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
 	autoDiscover := AutoDiscover{Mode: AutoDiscoverAutoMode}
 	var silencePRComments []string
 	if args.AllowAllRepoSettings {
-		allowedOverrides = []string{PlanRequirementsKey, ApplyRequirementsKey, ImportRequirementsKey, WorkflowKey, DeleteSourceBranchOnMergeKey, RepoLockingKey, RepoLocksKey, PolicyCheckKey, SilencePRCommentsKey}
+		allowedOverrides = []string{PlanRequirementsKey, ApplyRequirementsKey, ImportRequirementsKey, WorkflowKey, DeleteSourceBranchOnMergeKey, RepoLockingKey, RepoLocksKey, PolicyCheckKey, SilencePRCommentsKey, NotificationsKey, ExtraArgsKey}
 		allowCustomWorkflows = true
 	}
 
@@ -232,6 +447,7 @@ func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
 				IDRegex:                   regexp.MustCompile(".*"),
 				BranchRegex:               regexp.MustCompile(".*"),
 				RepoConfigFile:            args.RepoConfigFile,
+				RepoConfigFiles:           args.RepoConfigFiles,
 				PlanRequirements:          commandReqs,
 				ApplyRequirements:         commandReqs,
 				ImportRequirements:        commandReqs,
@@ -266,6 +482,32 @@ func (r Repo) IDMatches(otherID string) bool {
 	return r.IDRegex.MatchString(otherID)
 }
 
+// ExpandID replaces $1, ${1}, $name and ${name} in value with the capture
+// groups of IDRegex matched against repoID. value is returned as is if this
+// config has an exact match id or repoID doesn't match.
+func (r Repo) ExpandID(repoID string, value string) string {
+	if r.IDRegex == nil || !strings.Contains(value, "$") {
+		return value
+	}
+	match := r.IDRegex.FindStringSubmatchIndex(repoID)
+	if match == nil {
+		return value
+	}
+	return string(r.IDRegex.ExpandString(nil, value, repoID, match))
+}
+
+// expandIDs calls ExpandID on each of values.
+func (r Repo) expandIDs(repoID string, values []string) []string {
+	if values == nil {
+		return nil
+	}
+	expanded := make([]string, len(values))
+	for i, v := range values {
+		expanded[i] = r.ExpandID(repoID, v)
+	}
+	return expanded
+}
+
 // BranchMatches returns true if the branch other matches a branch regex (if preset).
 func (r Repo) BranchMatches(other string) bool {
 	if r.BranchRegex == nil {
@@ -287,6 +529,7 @@ func (r Repo) IDString() string {
 func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, proj Project, rCfg RepoCfg) MergedProjectCfg {
 	log.Debug("MergeProjectCfg started")
 	planReqs, applyReqs, importReqs, workflow, allowedOverrides, allowCustomWorkflows, deleteSourceBranchOnMerge, repoLocks, policyCheck, customPolicyCheck, _, silencePRComments := g.getMatchingCfg(log, repoID)
+	postPlanHooks, postApplyHooks := g.projectHooks(repoID)
 	// If repos are allowed to override certain keys then override them.
 	for _, key := range allowedOverrides {
 		switch key {
@@ -391,6 +634,12 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		log.Debug("MergeProjectCfg completed")
 	}
 
+	// Projects of other tools use the tool's workflow instead of the default
+	// workflow.
+	workflow = ToolWorkflow(proj.Tool, workflow)
+
+	terraformDistribution, terraformVersion := g.mergeTerraformPin(log, repoID, proj, allowedOverrides)
+
 	log.Debug("final settings: %s: [%s], %s: [%s], %s: [%s], %s: %s, %s: %t, %s: %s, %s: %t, %s: %t, %s: [%s]",
 		PlanRequirementsKey, strings.Join(planReqs, ","),
 		ApplyRequirementsKey, strings.Join(applyReqs, ","),
@@ -413,8 +662,8 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		DependsOn:                 proj.DependsOn,
 		Name:                      proj.GetName(),
 		AutoplanEnabled:           proj.Autoplan.Enabled,
-		TerraformDistribution:     proj.TerraformDistribution,
-		TerraformVersion:          proj.TerraformVersion,
+		TerraformDistribution:     terraformDistribution,
+		TerraformVersion:          terraformVersion,
 		RepoCfgVersion:            rCfg.Version,
 		PolicySets:                g.PolicySets,
 		DeleteSourceBranchOnMerge: deleteSourceBranchOnMerge,
@@ -423,6 +672,29 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		PolicyCheck:               policyCheck,
 		CustomPolicyCheck:         customPolicyCheck,
 		SilencePRComments:         silencePRComments,
+		PostPlanHooks:             postPlanHooks,
+		PostApplyHooks:            postApplyHooks,
+		DestroyGuard:              g.DestroyGuard(repoID),
+		CostBudget:                g.CostBudget(repoID),
+		RiskScoring:               g.RiskScoring(repoID),
+		AutoApproval:              g.AutoApproval(repoID),
+		Rollback:                  g.Rollback(repoID),
+		Notifications:             proj.Notifications,
+		Owners:                    proj.Owners,
+		CodeOwners:                g.CodeOwners(repoID),
+		InlineReviewComments:      g.InlineReviewComments(repoID),
+		CommandAllowlist:          g.CommandAllowlist(repoID),
+		ProjectCommandAllowlist:   proj.CommandAllowlist,
+		ExtraArgs:                 proj.ExtraArgs,
+		PRMetadataVars:            proj.PRMetadataVars,
+		ShareOutputs:              proj.ShareOutputs,
+		DependencyOutputs:         proj.DependencyOutputs,
+		ApplyWindows:              proj.ApplyWindows,
+		Tool:                      proj.Tool,
+		PreviewEnvironment:        proj.PreviewEnvironment,
+		LockKeys:                  proj.LockKeys,
+		Labels:                    proj.Labels,
+		Canary:                    rCfg.CanaryOf(proj.Dir),
 	}
 }
 
@@ -431,6 +703,8 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 func (g GlobalCfg) DefaultProjCfg(log logging.SimpleLogging, repoID string, repoRelDir string, workspace string) MergedProjectCfg {
 	log.Debug("building config based on server-side config")
 	planReqs, applyReqs, importReqs, workflow, _, _, deleteSourceBranchOnMerge, repoLocks, policyCheck, customPolicyCheck, _, silencePRComments := g.getMatchingCfg(log, repoID)
+	postPlanHooks, postApplyHooks := g.projectHooks(repoID)
+	terraformDistribution, terraformVersion := g.TerraformPin(repoID)
 	return MergedProjectCfg{
 		PlanRequirements:          planReqs,
 		ApplyRequirements:         applyReqs,
@@ -440,15 +714,78 @@ func (g GlobalCfg) DefaultProjCfg(log logging.SimpleLogging, repoID string, repo
 		Workspace:                 workspace,
 		Name:                      "",
 		AutoplanEnabled:           DefaultAutoPlanEnabled,
-		TerraformDistribution:     nil,
-		TerraformVersion:          nil,
+		TerraformDistribution:     terraformDistribution,
+		TerraformVersion:          terraformVersion,
 		PolicySets:                g.PolicySets,
 		DeleteSourceBranchOnMerge: deleteSourceBranchOnMerge,
 		RepoLocks:                 repoLocks,
 		PolicyCheck:               policyCheck,
 		CustomPolicyCheck:         customPolicyCheck,
 		SilencePRComments:         silencePRComments,
+		PostPlanHooks:             postPlanHooks,
+		PostApplyHooks:            postApplyHooks,
+		DestroyGuard:              g.DestroyGuard(repoID),
+		CostBudget:                g.CostBudget(repoID),
+		RiskScoring:               g.RiskScoring(repoID),
+		AutoApproval:              g.AutoApproval(repoID),
+		Rollback:                  g.Rollback(repoID),
+		CodeOwners:                g.CodeOwners(repoID),
+		InlineReviewComments:      g.InlineReviewComments(repoID),
+		CommandAllowlist:          g.CommandAllowlist(repoID),
+	}
+}
+
+// mergeTerraformPin returns the terraform distribution and version of proj.
+// The ones pinned by the server-side config win over the project's unless the
+// repo is allowed to override them.
+func (g GlobalCfg) mergeTerraformPin(log logging.SimpleLogging, repoID string, proj Project, allowedOverrides []string) (*string, *version.Version) {
+	distribution, tfVersion := proj.TerraformDistribution, proj.TerraformVersion
+	pinnedDistribution, pinnedVersion := g.TerraformPin(repoID)
+	if pinnedDistribution != nil && (distribution == nil || !utils.SlicesContains(allowedOverrides, TerraformDistributionKey)) {
+		if distribution != nil && *distribution != *pinnedDistribution {
+			log.Info("using %s %q pinned by server-side config instead of %q: server-side config needs '%s: [%s]' to override it", TerraformDistributionKey, *pinnedDistribution, *distribution, AllowedOverridesKey, TerraformDistributionKey)
+		}
+		distribution = pinnedDistribution
+	}
+	if pinnedVersion != nil && (tfVersion == nil || !utils.SlicesContains(allowedOverrides, TerraformVersionKey)) {
+		if tfVersion != nil && !tfVersion.Equal(pinnedVersion) {
+			log.Info("using %s %q pinned by server-side config instead of %q: server-side config needs '%s: [%s]' to override it", TerraformVersionKey, pinnedVersion, tfVersion, AllowedOverridesKey, TerraformVersionKey)
+		}
+		tfVersion = pinnedVersion
+	}
+	return distribution, tfVersion
+}
+
+// TerraformPin returns the terraform distribution and version pinned by the
+// server-side config of the repo with id repoID, or nil for the ones that
+// aren't. Like the other keys, later matching repos override earlier ones.
+func (g GlobalCfg) TerraformPin(repoID string) (*string, *version.Version) {
+	var distribution *string
+	var tfVersion *version.Version
+	for _, repo := range g.Repos {
+		if !repo.IDMatches(repoID) {
+			continue
+		}
+		if repo.TerraformDistribution != nil {
+			distribution = repo.TerraformDistribution
+		}
+		if repo.TerraformVersion != nil {
+			tfVersion = repo.TerraformVersion
+		}
+	}
+	return distribution, tfVersion
+}
+
+// projectHooks returns the post plan and post apply hooks of all repo configs
+// matching repoID in order.
+func (g GlobalCfg) projectHooks(repoID string) (postPlanHooks []*WorkflowHook, postApplyHooks []*WorkflowHook) {
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			postPlanHooks = append(postPlanHooks, repo.PostPlanHooks...)
+			postApplyHooks = append(postApplyHooks, repo.PostApplyHooks...)
+		}
 	}
+	return postPlanHooks, postApplyHooks
 }
 
 // RepoAutoDiscoverCfg returns the AutoDiscover config from the global config
@@ -479,7 +816,7 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 	for _, repo := range g.Repos {
 		if repo.IDMatches(repoID) {
 			if repo.AllowedOverrides != nil {
-				allowedOverrides = repo.AllowedOverrides
+				allowedOverrides = repo.expandIDs(repoID, repo.AllowedOverrides)
 			}
 		}
 	}
@@ -508,6 +845,20 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 		if p.CustomPolicyCheck != nil && !utils.SlicesContains(allowedOverrides, CustomPolicyCheckKey) {
 			return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: [%s]'", CustomPolicyCheckKey, AllowedOverridesKey, CustomPolicyCheckKey)
 		}
+		if p.Notifications != nil && !utils.SlicesContains(allowedOverrides, NotificationsKey) {
+			return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: [%s]'", NotificationsKey, AllowedOverridesKey, NotificationsKey)
+		}
+		if p.PreviewEnvironment != nil && !utils.SlicesContains(allowedOverrides, PreviewEnvironmentKey) {
+			return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: [%s]'", PreviewEnvironmentKey, AllowedOverridesKey, PreviewEnvironmentKey)
+		}
+		if p.ExtraArgs != nil {
+			if !utils.SlicesContains(allowedOverrides, ExtraArgsKey) {
+				return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: [%s]'", ExtraArgsKey, AllowedOverridesKey, ExtraArgsKey)
+			}
+			if err := g.validateExtraArgs(p.ExtraArgs, repoID); err != nil {
+				return err
+			}
+		}
 		if p.SilencePRComments != nil {
 			if !utils.SlicesContains(allowedOverrides, SilencePRCommentsKey) {
 				return fmt.Errorf(
@@ -544,6 +895,12 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 		return fmt.Errorf("repo config not allowed to define custom workflows: server-side config needs '%s: true'", AllowCustomWorkflowsKey)
 	}
 
+	// A project resolver runs arbitrary commands from the repo, just like
+	// custom workflows do.
+	if rCfg.ProjectResolver != nil && !allowCustomWorkflows {
+		return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: true'", ProjectResolverKey, AllowCustomWorkflowsKey)
+	}
+
 	// Check if the repo has set a workflow name that doesn't exist.
 	for _, p := range rCfg.Projects {
 		if p.WorkflowName != nil {
@@ -554,13 +911,25 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 		}
 	}
 
+	// Check that the apply requirement plugins the repo references are
+	// defined in the server-side config.
+	for _, p := range rCfg.Projects {
+		for _, req := range p.ApplyRequirements {
+			if name, ok := ApplyRequirementPluginName(req); ok {
+				if _, found := g.ApplyRequirementPlugins[name]; !found {
+					return fmt.Errorf("apply requirement plugin %q is not defined in the server-side config", name)
+				}
+			}
+		}
+	}
+
 	// Check workflow is allowed
 	var allowedWorkflows []string
 	for _, repo := range g.Repos {
 		if repo.IDMatches(repoID) {
 
 			if repo.AllowedWorkflows != nil {
-				allowedWorkflows = repo.AllowedWorkflows
+				allowedWorkflows = repo.expandIDs(repoID, repo.AllowedWorkflows)
 			}
 		}
 	}
@@ -633,14 +1002,22 @@ func (g GlobalCfg) getMatchingCfg(log logging.SimpleLogging, repoID string) (pla
 						importReqs = repo.ImportRequirements
 					}
 				case WorkflowKey:
-					if repo.Workflow != nil {
+					if repo.WorkflowTemplate != "" {
+						name := repo.ExpandID(repoID, repo.WorkflowTemplate)
+						if w, ok := g.Workflows[name]; ok {
+							toLog[WorkflowKey] = traceF(i, repo.IDString(), WorkflowKey, name)
+							workflow = w
+						} else {
+							log.Warn("workflow %q set for repo %s by repos[%d] is not defined, ignoring it", name, repoID, i)
+						}
+					} else if repo.Workflow != nil {
 						toLog[WorkflowKey] = traceF(i, repo.IDString(), WorkflowKey, repo.Workflow.Name)
 						workflow = *repo.Workflow
 					}
 				case AllowedOverridesKey:
 					if repo.AllowedOverrides != nil {
-						toLog[AllowedOverridesKey] = traceF(i, repo.IDString(), AllowedOverridesKey, repo.AllowedOverrides)
-						allowedOverrides = repo.AllowedOverrides
+						allowedOverrides = repo.expandIDs(repoID, repo.AllowedOverrides)
+						toLog[AllowedOverridesKey] = traceF(i, repo.IDString(), AllowedOverridesKey, allowedOverrides)
 					}
 				case AllowCustomWorkflowsKey:
 					if repo.AllowCustomWorkflows != nil {
@@ -696,6 +1073,248 @@ func (g GlobalCfg) getMatchingCfg(log logging.SimpleLogging, repoID string) (pla
 	return
 }
 
+// AllowTarget returns true if plans and applies for the repo with id repoID
+// may be limited to specific resources with --target. Like the other keys,
+// later matching repos override earlier ones.
+func (g GlobalCfg) AllowTarget(repoID string) bool {
+	allowTarget := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.AllowTarget != nil {
+			allowTarget = *repo.AllowTarget
+		}
+	}
+	return allowTarget
+}
+
+// validateExtraArgs returns an error if extraArgs set a flag that Atlantis
+// manages, that the repo's server-side config denies or, if it lists the
+// allowed flags, that isn't allowed.
+func (g GlobalCfg) validateExtraArgs(extraArgs map[string][]string, repoID string) error {
+	var allowed, denied []string
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.AllowedExtraArgs != nil {
+				allowed = repo.AllowedExtraArgs
+			}
+			if repo.DeniedExtraArgs != nil {
+				denied = repo.DeniedExtraArgs
+			}
+		}
+	}
+
+	for _, step := range ExtraArgsSteps {
+		for _, arg := range extraArgs[step] {
+			flag, _, _ := strings.Cut(arg, "=")
+			if utils.SlicesContains(ManagedExtraArgs, flag) {
+				return fmt.Errorf("repo config '%s' key can't set %s: it's set by Atlantis", ExtraArgsKey, flag)
+			}
+			if utils.SlicesContains(denied, flag) {
+				return fmt.Errorf("repo config '%s' key can't set %s: it's denied by the server-side config", ExtraArgsKey, flag)
+			}
+			if allowed != nil && !utils.SlicesContains(allowed, flag) {
+				return fmt.Errorf("repo config '%s' key can't set %s: server-side config needs 'allowed_extra_args: [%s]'", ExtraArgsKey, flag, flag)
+			}
+		}
+	}
+	return nil
+}
+
+// AllowStateRead returns true if the state of the projects of the repo with
+// id repoID may be read with state list and state show. Like the other keys,
+// later matching repos override earlier ones.
+func (g GlobalCfg) AllowStateRead(repoID string) bool {
+	allowStateRead := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.AllowStateRead != nil {
+			allowStateRead = *repo.AllowStateRead
+		}
+	}
+	return allowStateRead
+}
+
+// DestroyGuard returns the destroy guard of the repo with id repoID or nil
+// if there isn't one. Like the other keys, later matching repos override
+// earlier ones.
+func (g GlobalCfg) DestroyGuard(repoID string) *DestroyGuard {
+	var destroyGuard *DestroyGuard
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.DestroyGuard != nil {
+			destroyGuard = repo.DestroyGuard
+		}
+	}
+	return destroyGuard
+}
+
+// CostBudget returns the cost budget of the repo with id repoID or nil if
+// there isn't one. Like the other keys, later matching repos override earlier
+// ones.
+func (g GlobalCfg) CostBudget(repoID string) *CostBudget {
+	var costBudget *CostBudget
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.CostBudget != nil {
+			costBudget = repo.CostBudget
+		}
+	}
+	return costBudget
+}
+
+// Rollback returns the rollback config of the repo with id repoID or nil if
+// the rollback command isn't allowed. Like the other keys, later matching
+// repos override earlier ones.
+func (g GlobalCfg) Rollback(repoID string) *Rollback {
+	var rollback *Rollback
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.Rollback != nil {
+			rollback = repo.Rollback
+		}
+	}
+	return rollback
+}
+
+// DestroyOnClose returns the workspaces to destroy when pull requests of the
+// repo with id repoID are closed or nil if there aren't any. Like the other
+// keys, later matching repos override earlier ones.
+func (g GlobalCfg) DestroyOnClose(repoID string) *DestroyOnClose {
+	var destroyOnClose *DestroyOnClose
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.DestroyOnClose != nil {
+			destroyOnClose = repo.DestroyOnClose
+		}
+	}
+	return destroyOnClose
+}
+
+// CommentLayout returns how the results of commands run on several projects
+// of the repo with id repoID are laid out in comments. Like the other keys,
+// later matching repos override earlier ones.
+func (g GlobalCfg) CommentLayout(repoID string) string {
+	layout := CommentLayoutCombined
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.CommentLayout != "" {
+			layout = repo.CommentLayout
+		}
+	}
+	return layout
+}
+
+// GithubStatusMode returns how the status of commands is reported for the
+// GitHub repo with id repoID. Like the other keys, later matching repos
+// override earlier ones.
+func (g GlobalCfg) GithubStatusMode(repoID string) string {
+	mode := GithubStatusModeStatuses
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.GithubStatusMode != "" {
+			mode = repo.GithubStatusMode
+		}
+	}
+	return mode
+}
+
+// CommandAllowlist returns the command allowlist of the repo with id repoID
+// or nil if it doesn't restrict commands. Like the other keys, later matching
+// repos override earlier ones.
+func (g GlobalCfg) CommandAllowlist(repoID string) []CommandAllowlistRule {
+	var rules []CommandAllowlistRule
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.CommandAllowlist != nil {
+			rules = repo.CommandAllowlist
+		}
+	}
+	return rules
+}
+
+// CommentTemplates returns the comment templates of the repo with id repoID
+// or nil if there aren't any. Like the other keys, later matching repos
+// override earlier ones.
+func (g GlobalCfg) CommentTemplates(repoID string) *CommentTemplates {
+	var commentTemplates *CommentTemplates
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.CommentTemplates != nil {
+			commentTemplates = repo.CommentTemplates
+		}
+	}
+	return commentTemplates
+}
+
+// RiskScoring returns the risk scoring of the repo with id repoID or nil if
+// there isn't one. Like the other keys, later matching repos override earlier
+// ones.
+func (g GlobalCfg) RiskScoring(repoID string) *RiskScoring {
+	var riskScoring *RiskScoring
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.RiskScoring != nil {
+			riskScoring = repo.RiskScoring
+		}
+	}
+	return riskScoring
+}
+
+// AutoApproval returns the auto-approval rules of the repo with id repoID or
+// nil if there aren't any. Like the other keys, later matching repos override
+// earlier ones.
+func (g GlobalCfg) AutoApproval(repoID string) *AutoApproval {
+	var autoApproval *AutoApproval
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.AutoApproval != nil {
+			autoApproval = repo.AutoApproval
+		}
+	}
+	return autoApproval
+}
+
+// CodeOwners returns true if the CODEOWNERS file decides who may plan and
+// apply the projects of the repo with id repoID. Like the other keys, later
+// matching repos override earlier ones.
+func (g GlobalCfg) CodeOwners(repoID string) bool {
+	codeOwners := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.CodeOwners != nil {
+			codeOwners = *repo.CodeOwners
+		}
+	}
+	return codeOwners
+}
+
+// InlineReviewComments returns true if the deletes and replacements of plans
+// of the repo with id repoID are commented on the lines of the diff that
+// declare the resources. Like the other keys, later matching repos override
+// earlier ones.
+func (g GlobalCfg) InlineReviewComments(repoID string) bool {
+	inlineReviewComments := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.InlineReviewComments != nil {
+			inlineReviewComments = *repo.InlineReviewComments
+		}
+	}
+	return inlineReviewComments
+}
+
+// SuggestRepoConfig returns true if an atlantis.yaml is suggested for the
+// repo with id repoID when it doesn't have one. Like the other keys, later
+// matching repos override earlier ones.
+func (g GlobalCfg) SuggestRepoConfig(repoID string) bool {
+	suggest := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.SuggestRepoConfig != nil {
+			suggest = *repo.SuggestRepoConfig
+		}
+	}
+	return suggest
+}
+
+// AutoplanTriggers returns the autoplan triggers of the repo with id repoID
+// or nil if there aren't any. Like the other keys, later matching repos
+// override earlier ones.
+func (g GlobalCfg) AutoplanTriggers(repoID string) *AutoplanTriggers {
+	var autoplanTriggers *AutoplanTriggers
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.AutoplanTriggers != nil {
+			autoplanTriggers = repo.AutoplanTriggers
+		}
+	}
+	return autoplanTriggers
+}
+
 // MatchingRepo returns an instance of Repo which matches a given repoID.
 // If multiple repos match, return the last one for consistency with getMatchingCfg.
 func (g GlobalCfg) MatchingRepo(repoID string) *Repo {
@@ -711,9 +1330,20 @@ func (g GlobalCfg) MatchingRepo(repoID string) *Repo {
 // RepoConfigFile returns a repository specific file path
 // If not defined, return atlantis.yaml as default
 func (g GlobalCfg) RepoConfigFile(repoID string) string {
+	return g.RepoConfigFiles(repoID)[0]
+}
+
+// RepoConfigFiles returns the paths searched for the repo config in priority
+// order. A repository specific file path is the only path searched, otherwise
+// the paths of the default repo config are searched, then atlantis.yaml if
+// there are none.
+func (g GlobalCfg) RepoConfigFiles(repoID string) []string {
 	repo := g.MatchingRepo(repoID)
 	if repo != nil && repo.RepoConfigFile != "" {
-		return repo.RepoConfigFile
+		return []string{repo.ExpandID(repoID, repo.RepoConfigFile)}
+	}
+	if len(g.Repos) > 0 && len(g.Repos[0].RepoConfigFiles) > 0 {
+		return g.Repos[0].RepoConfigFiles
 	}
-	return DefaultAtlantisFile
+	return []string{DefaultAtlantisFile}
 }
@@ -0,0 +1,41 @@
+package valid
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// GlobalCfgStore holds the current server-side repo config so it can be
+// swapped while the server is running.
+type GlobalCfgStore struct {
+	cfg atomic.Pointer[GlobalCfg]
+}
+
+// NewGlobalCfgStore returns a store holding cfg.
+func NewGlobalCfgStore(cfg GlobalCfg) *GlobalCfgStore {
+	s := &GlobalCfgStore{}
+	s.Store(cfg)
+	return s
+}
+
+// Load returns the current config.
+func (s *GlobalCfgStore) Load() GlobalCfg {
+	return *s.cfg.Load()
+}
+
+// Store replaces the current config with cfg.
+func (s *GlobalCfgStore) Store(cfg GlobalCfg) {
+	s.cfg.Store(&cfg)
+}
+
+// Reload replaces the current config with cfg. The metrics key is only read
+// on startup, since the metrics reporters and their tags are set up then, so
+// a cfg that changes it is rejected and the current config is kept.
+func (s *GlobalCfgStore) Reload(cfg GlobalCfg) error {
+	if !reflect.DeepEqual(s.Load().Metrics, cfg.Metrics) {
+		return fmt.Errorf("the metrics key can't be changed without restarting the server")
+	}
+	s.Store(cfg)
+	return nil
+}
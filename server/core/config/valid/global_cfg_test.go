@@ -132,7 +132,7 @@ func TestNewGlobalCfg(t *testing.T) {
 
 			if c.allowAllRepoSettings {
 				exp.Repos[0].AllowCustomWorkflows = Bool(true)
-				exp.Repos[0].AllowedOverrides = []string{"plan_requirements", "apply_requirements", "import_requirements", "workflow", "delete_source_branch_on_merge", "repo_locking", "repo_locks", "policy_check", "silence_pr_comments"}
+				exp.Repos[0].AllowedOverrides = []string{"plan_requirements", "apply_requirements", "import_requirements", "workflow", "delete_source_branch_on_merge", "repo_locking", "repo_locks", "policy_check", "silence_pr_comments", "notifications", "extra_args"}
 			}
 			if c.policyCheckEnabled {
 				exp.Repos[0].PlanRequirements = append(exp.Repos[0].PlanRequirements, "policies_passed")
@@ -196,6 +196,29 @@ func TestGlobalCfg_ValidateRepoCfg(t *testing.T) {
 			repoID: "github.com/owner/repo",
 			expErr: "workflow 'forbidden' is not allowed for this repo",
 		},
+		"repo uses apply requirement plugin that isn't defined": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+						AllowAllRepoSettings: true,
+					}).Repos[0],
+				},
+				ApplyRequirementPlugins: map[string]valid.ApplyRequirementPlugin{
+					"cab-approved": {Name: "cab-approved", Command: "/bin/check-cab"},
+				},
+			},
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:               ".",
+						Workspace:         "default",
+						ApplyRequirements: []string{"plugin:cab-approved", "plugin:freeze"},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "apply requirement plugin \"freeze\" is not defined in the server-side config",
+		},
 		"repo uses workflow that is defined server side but not allowed (without custom workflows)": {
 			gCfg: valid.GlobalCfg{
 				Repos: []valid.Repo{
@@ -388,6 +411,26 @@ func TestGlobalCfg_ValidateRepoCfg(t *testing.T) {
 			repoID: "github.com/owner/repo",
 			expErr: "",
 		},
+		"project resolver not allowed": {
+			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+				AllowAllRepoSettings: false,
+			}),
+			rCfg: valid.RepoCfg{
+				ProjectResolver: &valid.ProjectResolver{Command: "./resolve.sh"},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "repo config not allowed to set 'project_resolver' key: server-side config needs 'allow_custom_workflows: true'",
+		},
+		"project resolver allowed": {
+			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+				AllowAllRepoSettings: true,
+			}),
+			rCfg: valid.RepoCfg{
+				ProjectResolver: &valid.ProjectResolver{Command: "./resolve.sh"},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "",
+		},
 		"repo uses custom workflow defined on repo": {
 			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
 				AllowAllRepoSettings: true,
@@ -491,6 +534,102 @@ func TestGlobalCfg_ValidateRepoCfg(t *testing.T) {
 			repoID: "github.com/owner/repo",
 			expErr: "repo config not allowed to set 'import_requirements' key: server-side config needs 'allowed_overrides: [import_requirements]'",
 		},
+		"repo sets extra args without the override": {
+			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+				AllowAllRepoSettings: false,
+			}),
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:       ".",
+						Workspace: "default",
+						ExtraArgs: map[string][]string{"plan": {"-refresh=false"}},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "repo config not allowed to set 'extra_args' key: server-side config needs 'allowed_overrides: [extra_args]'",
+		},
+		"repo sets extra args": {
+			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+				AllowAllRepoSettings: true,
+			}),
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:       ".",
+						Workspace: "default",
+						ExtraArgs: map[string][]string{"plan": {"-refresh=false", "-parallelism=30"}},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "",
+		},
+		"repo sets extra args managed by atlantis": {
+			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+				AllowAllRepoSettings: true,
+			}),
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:       ".",
+						Workspace: "default",
+						ExtraArgs: map[string][]string{"plan": {"-out=other.tfplan"}},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "repo config 'extra_args' key can't set -out: it's set by Atlantis",
+		},
+		"repo sets denied extra args": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+						AllowAllRepoSettings: true,
+					}).Repos[0],
+					{
+						ID:              "github.com/owner/repo",
+						DeniedExtraArgs: []string{"-lock"},
+					},
+				},
+			},
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:       ".",
+						Workspace: "default",
+						ExtraArgs: map[string][]string{"apply": {"-lock=false"}},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "repo config 'extra_args' key can't set -lock: it's denied by the server-side config",
+		},
+		"repo sets extra args that aren't allowed": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+						AllowAllRepoSettings: true,
+					}).Repos[0],
+					{
+						ID:               "github.com/owner/repo",
+						AllowedExtraArgs: []string{"-refresh", "-parallelism"},
+					},
+				},
+			},
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:       ".",
+						Workspace: "default",
+						ExtraArgs: map[string][]string{"plan": {"-refresh=false", "-lock-timeout=5m"}},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "repo config 'extra_args' key can't set -lock-timeout: server-side config needs 'allowed_extra_args: [-lock-timeout]'",
+		},
 		"repo workflow doesn't exist": {
 			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
 				AllowAllRepoSettings: true,
@@ -1152,6 +1291,146 @@ func TestGlobalCfg_MatchingRepo(t *testing.T) {
 	}
 }
 
+func TestGlobalCfg_AllowTarget(t *testing.T) {
+	allowed := true
+	denied := false
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+			},
+			{
+				IDRegex:     regexp.MustCompile("^github.com/owner/.*$"),
+				AllowTarget: &allowed,
+			},
+			{
+				ID:          "github.com/owner/locked",
+				AllowTarget: &denied,
+			},
+		},
+	}
+
+	cases := map[string]bool{
+		"github.com/other/repo":   false,
+		"github.com/owner/repo":   true,
+		"github.com/owner/locked": false,
+	}
+	for repoID, exp := range cases {
+		t.Run(repoID, func(t *testing.T) {
+			Equals(t, exp, gCfg.AllowTarget(repoID))
+		})
+	}
+}
+
+func TestGlobalCfg_AllowStateRead(t *testing.T) {
+	allowed := true
+	denied := false
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+			},
+			{
+				IDRegex:        regexp.MustCompile("^github.com/owner/.*$"),
+				AllowStateRead: &allowed,
+			},
+			{
+				ID:             "github.com/owner/secret",
+				AllowStateRead: &denied,
+			},
+		},
+	}
+
+	cases := map[string]bool{
+		"github.com/other/repo":   false,
+		"github.com/owner/repo":   true,
+		"github.com/owner/secret": false,
+	}
+	for repoID, exp := range cases {
+		t.Run(repoID, func(t *testing.T) {
+			Equals(t, exp, gCfg.AllowStateRead(repoID))
+		})
+	}
+}
+
+func TestGlobalCfg_DestroyGuard(t *testing.T) {
+	databases := &valid.DestroyGuard{ResourceTypes: []string{"aws_db_*"}}
+	buckets := &valid.DestroyGuard{ResourceTypes: []string{"aws_s3_bucket"}, RequireOtherUser: true}
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+			},
+			{
+				IDRegex:      regexp.MustCompile("^github.com/owner/.*$"),
+				DestroyGuard: databases,
+			},
+			{
+				ID:           "github.com/owner/storage",
+				DestroyGuard: buckets,
+			},
+		},
+	}
+
+	cases := map[string]*valid.DestroyGuard{
+		"github.com/other/repo":    nil,
+		"github.com/owner/repo":    databases,
+		"github.com/owner/storage": buckets,
+	}
+	for repoID, exp := range cases {
+		t.Run(repoID, func(t *testing.T) {
+			Equals(t, exp, gCfg.DestroyGuard(repoID))
+		})
+	}
+}
+
+func TestDestroyGuard_Protects(t *testing.T) {
+	guard := valid.DestroyGuard{ResourceTypes: []string{"aws_db_*", "google_sql_database_instance"}}
+	cases := map[string]bool{
+		"aws_db_instance":              true,
+		"aws_db_cluster_snapshot":      true,
+		"google_sql_database_instance": true,
+		"google_sql_database":          false,
+		"aws_instance":                 false,
+	}
+	for resourceType, exp := range cases {
+		t.Run(resourceType, func(t *testing.T) {
+			Equals(t, exp, guard.Protects(resourceType))
+		})
+	}
+}
+
+func TestGlobalCfg_RepoConfigFiles(t *testing.T) {
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex:         regexp.MustCompile(".*"),
+				RepoConfigFiles: []string{".atlantis/atlantis.yaml", "atlantis.yaml"},
+			},
+			{
+				ID:             "github.com/owner/custom",
+				RepoConfigFile: "infra/atlantis.yaml",
+			},
+		},
+	}
+
+	Equals(t, []string{".atlantis/atlantis.yaml", "atlantis.yaml"}, gCfg.RepoConfigFiles("github.com/owner/repo"))
+	Equals(t, ".atlantis/atlantis.yaml", gCfg.RepoConfigFile("github.com/owner/repo"))
+	Equals(t, []string{"infra/atlantis.yaml"}, gCfg.RepoConfigFiles("github.com/owner/custom"))
+	Equals(t, []string{valid.DefaultAtlantisFile}, valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}).RepoConfigFiles("github.com/owner/repo"))
+}
+
+func TestRepo_ExpandID(t *testing.T) {
+	repo := valid.Repo{IDRegex: regexp.MustCompile(`^github.com/(\w+)/team-(?P<team>\w+)-infra$`)}
+	repoID := "github.com/org/team-platform-infra"
+
+	Equals(t, "platform", repo.ExpandID(repoID, "$team"))
+	Equals(t, "org-platform", repo.ExpandID(repoID, "${1}-${team}"))
+	Equals(t, "static", repo.ExpandID(repoID, "static"))
+	Equals(t, "$team", repo.ExpandID("github.com/org/other", "$team"))
+	Equals(t, "$team", valid.Repo{ID: repoID}.ExpandID(repoID, "$team"))
+}
+
 func TestGlobalCfg_PolicyCheckOverride(t *testing.T) {
 	var emptyPolicySets valid.PolicySets
 
@@ -1403,3 +1682,140 @@ func String(v string) *string { return &v }
 // Bool is a helper routine that allocates a new bool value
 // to store v and returns a pointer to it.
 func Bool(v bool) *bool { return &v }
+
+func TestGlobalCfg_ProjectHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	Ok(t, os.WriteFile(path, []byte(`
+repos:
+- id: /.*/
+  post_apply_hooks:
+  - run: ./invalidate-cache.sh
+- id: github.com/owner/repo
+  post_plan_hooks:
+  - run: ./plan-report.sh
+    description: Plan report
+  post_apply_hooks:
+  - run: ./update-cmdb.sh
+    shell: bash
+`), 0600))
+	global, err := (&config.ParserValidator{}).ParseGlobalCfg(path, valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}))
+	Ok(t, err)
+
+	invalidateCache := &valid.WorkflowHook{StepName: "run", RunCommand: "./invalidate-cache.sh"}
+	planReport := &valid.WorkflowHook{StepName: "run", RunCommand: "./plan-report.sh", StepDescription: "Plan report"}
+	updateCMDB := &valid.WorkflowHook{StepName: "run", RunCommand: "./update-cmdb.sh", Shell: "bash"}
+
+	merged := global.MergeProjectCfg(logging.NewNoopLogger(t), "github.com/owner/repo", valid.Project{Dir: ".", Workspace: "default"}, valid.RepoCfg{})
+	Equals(t, []*valid.WorkflowHook{planReport}, merged.PostPlanHooks)
+	Equals(t, []*valid.WorkflowHook{invalidateCache, updateCMDB}, merged.PostApplyHooks)
+
+	merged = global.DefaultProjCfg(logging.NewNoopLogger(t), "github.com/owner/other", ".", "default")
+	Equals(t, 0, len(merged.PostPlanHooks))
+	Equals(t, []*valid.WorkflowHook{invalidateCache}, merged.PostApplyHooks)
+}
+
+// Projects of other tools use the tool's workflow instead of the default
+// workflow, but not instead of workflows they set.
+func TestGlobalCfg_MergeProjectCfg_Tool(t *testing.T) {
+	global := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowAllRepoSettings: true})
+	custom := valid.Workflow{Name: "custom", Plan: valid.Stage{Steps: []valid.Step{{StepName: "run", RunCommand: "make diff"}}}}
+	repoCfg := valid.RepoCfg{Workflows: map[string]valid.Workflow{"custom": custom}}
+	log := logging.NewNoopLogger(t)
+
+	merged := global.MergeProjectCfg(log, "github.com/owner/repo", valid.Project{Dir: ".", Workspace: "default", Tool: valid.ToolKustomize}, repoCfg)
+	Equals(t, valid.Tools[valid.ToolKustomize].Workflow, merged.Workflow)
+	Equals(t, valid.ToolKustomize, merged.Tool)
+
+	merged = global.MergeProjectCfg(log, "github.com/owner/repo", valid.Project{Dir: ".", Workspace: "default", Tool: valid.ToolTerraform}, repoCfg)
+	Equals(t, valid.DefaultWorkflowName, merged.Workflow.Name)
+
+	customName := "custom"
+	merged = global.MergeProjectCfg(log, "github.com/owner/repo", valid.Project{Dir: ".", Workspace: "default", Tool: valid.ToolHelmfile, WorkflowName: &customName}, repoCfg)
+	Equals(t, custom, merged.Workflow)
+}
+
+func TestGlobalCfg_MergeProjectCfg_TerraformPin(t *testing.T) {
+	opentofu := "opentofu"
+	terraform := "terraform"
+	pinned := version.Must(version.NewVersion("1.8.2"))
+	projectVersion := version.Must(version.NewVersion("1.6.0"))
+	log := logging.NewNoopLogger(t)
+
+	global := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	global.Repos = append(global.Repos, valid.Repo{
+		IDRegex:               regexp.MustCompile("github.com/owner/.*"),
+		TerraformDistribution: &opentofu,
+		TerraformVersion:      pinned,
+	})
+
+	// Projects without a version get the pinned one.
+	merged := global.MergeProjectCfg(log, "github.com/owner/repo", valid.Project{Dir: ".", Workspace: "default"}, valid.RepoCfg{})
+	Equals(t, &opentofu, merged.TerraformDistribution)
+	Equals(t, pinned, merged.TerraformVersion)
+
+	// The pin wins over the project's settings if they can't be overridden.
+	project := valid.Project{Dir: ".", Workspace: "default", TerraformDistribution: &terraform, TerraformVersion: projectVersion}
+	merged = global.MergeProjectCfg(log, "github.com/owner/repo", project, valid.RepoCfg{})
+	Equals(t, &opentofu, merged.TerraformDistribution)
+	Equals(t, pinned, merged.TerraformVersion)
+
+	global.Repos[len(global.Repos)-1].AllowedOverrides = []string{valid.TerraformVersionKey}
+	merged = global.MergeProjectCfg(log, "github.com/owner/repo", project, valid.RepoCfg{})
+	Equals(t, &opentofu, merged.TerraformDistribution)
+	Equals(t, projectVersion, merged.TerraformVersion)
+
+	// Other repos and projects without a repo config.
+	merged = global.MergeProjectCfg(log, "github.com/other/repo", project, valid.RepoCfg{})
+	Equals(t, &terraform, merged.TerraformDistribution)
+	Equals(t, projectVersion, merged.TerraformVersion)
+	merged = global.DefaultProjCfg(log, "github.com/owner/repo", ".", "default")
+	Equals(t, &opentofu, merged.TerraformDistribution)
+	Equals(t, pinned, merged.TerraformVersion)
+}
+
+func TestGlobalCfg_GithubStatusMode(t *testing.T) {
+	global := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	Equals(t, valid.GithubStatusModeStatuses, global.GithubStatusMode("github.com/owner/repo"))
+
+	global.Repos = append(global.Repos,
+		valid.Repo{
+			IDRegex:          regexp.MustCompile("github.com/owner/.*"),
+			GithubStatusMode: valid.GithubStatusModeBoth,
+		},
+		valid.Repo{
+			ID:               "github.com/owner/migrated",
+			GithubStatusMode: valid.GithubStatusModeChecks,
+		},
+	)
+	Equals(t, valid.GithubStatusModeBoth, global.GithubStatusMode("github.com/owner/repo"))
+	Equals(t, valid.GithubStatusModeChecks, global.GithubStatusMode("github.com/owner/migrated"))
+	Equals(t, valid.GithubStatusModeStatuses, global.GithubStatusMode("github.com/other/repo"))
+}
+
+func TestGlobalCfg_SuggestRepoConfig(t *testing.T) {
+	global := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	Equals(t, false, global.SuggestRepoConfig("github.com/owner/repo"))
+
+	enabled, disabled := true, false
+	global.Repos = append(global.Repos,
+		valid.Repo{IDRegex: regexp.MustCompile("github.com/owner/.*"), SuggestRepoConfig: &enabled},
+		valid.Repo{ID: "github.com/owner/configured", SuggestRepoConfig: &disabled},
+	)
+	Equals(t, true, global.SuggestRepoConfig("github.com/owner/repo"))
+	Equals(t, false, global.SuggestRepoConfig("github.com/owner/configured"))
+	Equals(t, false, global.SuggestRepoConfig("github.com/other/repo"))
+}
+
+func TestGlobalCfg_InlineReviewComments(t *testing.T) {
+	global := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	Equals(t, false, global.InlineReviewComments("github.com/owner/repo"))
+
+	enabled, disabled := true, false
+	global.Repos = append(global.Repos,
+		valid.Repo{IDRegex: regexp.MustCompile("github.com/owner/.*"), InlineReviewComments: &enabled},
+		valid.Repo{ID: "github.com/owner/quiet", InlineReviewComments: &disabled},
+	)
+	Equals(t, true, global.InlineReviewComments("github.com/owner/repo"))
+	Equals(t, false, global.InlineReviewComments("github.com/owner/quiet"))
+	Equals(t, false, global.InlineReviewComments("github.com/other/repo"))
+}
@@ -0,0 +1,18 @@
+package valid
+
+import (
+	"regexp"
+	"slices"
+)
+
+// InstanceNameRegex matches the names of Atlantis instances, ex. prod. They
+// name the executable and the commit statuses of the instance so they're
+// limited to lowercase letters, digits and dashes.
+var InstanceNameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// HandledBy returns true if the Atlantis instance named instanceName runs
+// commands on the project. Projects that don't list instances are handled by
+// every instance and instances without a name handle every project.
+func (p Project) HandledBy(instanceName string) bool {
+	return instanceName == "" || len(p.Instances) == 0 || slices.Contains(p.Instances, instanceName)
+}
@@ -0,0 +1,43 @@
+package valid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LabelRegex matches the keys and values of project labels, ex. tier and
+// prod. They're used in comments, metric tags and query parameters so they're
+// limited to letters, digits, dots, underscores and dashes.
+var LabelRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// MatchLabels returns true if labels has every label of selector with the
+// same value. Every project matches an empty selector.
+func MatchLabels(labels map[string]string, selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabelSelector parses labels written as key=value, ex. tier=prod, into
+// a selector. It returns nil if labels is empty.
+func ParseLabelSelector(labels []string) (map[string]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	selector := make(map[string]string, len(labels))
+	for _, label := range labels {
+		key, value, ok := strings.Cut(label, "=")
+		if !ok || !LabelRegex.MatchString(key) || !LabelRegex.MatchString(value) {
+			return nil, fmt.Errorf("invalid label: %q must be key=value with only letters, digits, dots, underscores and dashes", label)
+		}
+		if existing, ok := selector[key]; ok && existing != value {
+			return nil, fmt.Errorf("invalid label: %q conflicts with %s=%s", label, key, existing)
+		}
+		selector[key] = value
+	}
+	return selector, nil
+}
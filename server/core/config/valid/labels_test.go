@@ -0,0 +1,35 @@
+package valid_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"tier": "prod", "team": "platform"}
+	Assert(t, valid.MatchLabels(labels, nil), "expected labels to match an empty selector")
+	Assert(t, valid.MatchLabels(labels, map[string]string{"tier": "prod"}), "expected labels to match tier=prod")
+	Assert(t, valid.MatchLabels(labels, map[string]string{"tier": "prod", "team": "platform"}), "expected labels to match every label")
+	Assert(t, !valid.MatchLabels(labels, map[string]string{"tier": "staging"}), "expected labels not to match tier=staging")
+	Assert(t, !valid.MatchLabels(labels, map[string]string{"tier": "prod", "env": "eu"}), "expected labels not to match a missing label")
+	Assert(t, !valid.MatchLabels(nil, map[string]string{"tier": "prod"}), "expected no labels not to match tier=prod")
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	selector, err := valid.ParseLabelSelector([]string{"tier=prod", "team=platform", "tier=prod"})
+	Ok(t, err)
+	Equals(t, map[string]string{"tier": "prod", "team": "platform"}, selector)
+
+	selector, err = valid.ParseLabelSelector(nil)
+	Ok(t, err)
+	Equals(t, map[string]string(nil), selector)
+
+	_, err = valid.ParseLabelSelector([]string{"tier"})
+	ErrContains(t, "invalid label", err)
+	_, err = valid.ParseLabelSelector([]string{"tier=prod/eu"})
+	ErrContains(t, "invalid label", err)
+	_, err = valid.ParseLabelSelector([]string{"tier=prod", "tier=staging"})
+	ErrContains(t, `"tier=staging" conflicts with tier=prod`, err)
+}
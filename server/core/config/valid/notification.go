@@ -0,0 +1,22 @@
+package valid
+
+const (
+	SlackNotificationKind = "slack"
+	HttpNotificationKind  = "http"
+	TeamsNotificationKind = "teams"
+	EmailNotificationKind = "email"
+)
+
+// Notification is where the apply results of a project are sent, in addition
+// to the webhooks configured on the server.
+type Notification struct {
+	// Kind is slack, http, teams or email.
+	Kind string
+	// Channel is the Slack channel. It only applies to slack notifications.
+	Channel string
+	// URL is the webhook URL. It only applies to http and teams
+	// notifications.
+	URL string
+	// To are the email addresses. It only applies to email notifications.
+	To []string
+}
@@ -0,0 +1,62 @@
+package valid
+
+import (
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+)
+
+// DefaultProviderRegistryHost is the registry host of provider sources that
+// don't specify one, ex. hashicorp/aws.
+const DefaultProviderRegistryHost = "registry.terraform.io"
+
+// PinningPolicyMode enum
+type PinningPolicyMode string
+
+const (
+	// PinningPolicyFailMode fails the pin_check step on violations.
+	PinningPolicyFailMode PinningPolicyMode = "fail"
+	// PinningPolicyWarnMode only reports violations in the step output.
+	PinningPolicyWarnMode PinningPolicyMode = "warn"
+)
+
+// DefaultPinningPolicy is used by the pin_check step when the server-side
+// config doesn't define a pinning_policy.
+var DefaultPinningPolicy = PinningPolicy{
+	Mode:                 PinningPolicyFailMode,
+	RequirePinnedModules: true,
+	RequireLockFile:      true,
+}
+
+// PinningPolicy configures the pin_check step which checks that modules and
+// providers are pinned to known versions.
+type PinningPolicy struct {
+	Mode PinningPolicyMode
+	// RequirePinnedModules is true if remote module sources must specify a
+	// version or ref.
+	RequirePinnedModules bool
+	// RequireLockFile is true if .terraform.lock.hcl must exist and lock
+	// every required provider.
+	RequireLockFile bool
+	// AllowedProviders, if set, are the only providers that can be used.
+	AllowedProviders []AllowedProvider
+}
+
+// AllowedProvider is a provider in the pinning policy allowlist.
+type AllowedProvider struct {
+	// Source is the fully qualified provider source address, ex.
+	// registry.terraform.io/hashicorp/aws.
+	Source string
+	// VersionConstraints, if set, must be satisfied by the locked version.
+	VersionConstraints version.Constraints
+}
+
+// NormalizeProviderSource returns the fully qualified form of a provider
+// source address, ex. hashicorp/aws => registry.terraform.io/hashicorp/aws.
+func NormalizeProviderSource(source string) string {
+	source = strings.ToLower(source)
+	if strings.Count(source, "/") == 1 {
+		return DefaultProviderRegistryHost + "/" + source
+	}
+	return source
+}
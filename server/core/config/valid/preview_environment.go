@@ -0,0 +1,29 @@
+package valid
+
+import "fmt"
+
+// PreviewEnvironment makes a project a preview environment: it's stamped out
+// in a workspace of its own for every pull request, applied after every plan
+// and destroyed when the pull request is closed.
+type PreviewEnvironment struct {
+	// URLOutput is the name of the Terraform output holding the URL of the
+	// environment, which is posted to the pull request after every apply. If
+	// empty, no URL is posted.
+	URLOutput string
+}
+
+// PreviewWorkspace returns the workspace of the preview environments of the
+// pull request pullNum.
+func PreviewWorkspace(pullNum int) string {
+	return fmt.Sprintf("pr-%d", pullNum)
+}
+
+// UsePreviewWorkspaces puts the preview environment projects of r in the
+// workspace of the pull request pullNum.
+func (r *RepoCfg) UsePreviewWorkspaces(pullNum int) {
+	for i := range r.Projects {
+		if r.Projects[i].PreviewEnvironment != nil {
+			r.Projects[i].Workspace = PreviewWorkspace(pullNum)
+		}
+	}
+}
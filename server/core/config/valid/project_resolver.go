@@ -0,0 +1,15 @@
+package valid
+
+import "time"
+
+// DefaultProjectResolverTimeout is how long a project resolver command may run
+// when the repo config doesn't set a timeout.
+const DefaultProjectResolverTimeout = 5 * time.Minute
+
+// ProjectResolver is a command, run in the cloned repo, that receives the files
+// modified in a pull request and returns the projects to plan. It replaces
+// when_modified matching and autodiscovery for the repo.
+type ProjectResolver struct {
+	Command string
+	Timeout time.Duration
+}
@@ -31,6 +31,12 @@ type RepoCfg struct {
 	AllowedRegexpPrefixes     []string
 	AbortOnExecutionOrderFail bool
 	SilencePRComments         []string
+	// ImpactPreview is true if projects that consume the outputs of modified
+	// projects are also planned to preview the impact of the pull request.
+	ImpactPreview bool
+	// ProjectResolver, if set, determines the projects to plan instead of
+	// when_modified matching and autodiscovery.
+	ProjectResolver *ProjectResolver
 }
 
 func (r RepoCfg) FindProjectsByDirWorkspace(repoRelDir string, workspace string) []Project {
@@ -177,8 +183,62 @@ type Project struct {
 	PolicyCheck               *bool
 	CustomPolicyCheck         *bool
 	SilencePRComments         []string
+	// Notifications are where the project's apply results are sent.
+	Notifications []Notification
+	// Owners are the users and teams that must run or approve plan and
+	// apply for this project. If nil, anyone allowed to run commands can.
+	Owners *PolicyOwners
+	// CommandAllowlist restricts commands run on the project to users and
+	// teams, on top of the server-side command allowlist.
+	CommandAllowlist []CommandAllowlistRule
+	// ExtraArgs are the default extra arguments of the built-in steps of the
+	// project's workflow, keyed by step name, ex. -refresh=false for plan.
+	ExtraArgs map[string][]string
+	// PRMetadataVars is how the pull request's metadata is passed to
+	// terraform as variables, PRMetadataVarsEnv or PRMetadataVarsTFVars. If
+	// empty it isn't passed.
+	PRMetadataVars string
+	// ShareOutputs is true if the outputs of the project are stored after
+	// each apply for the projects depending on it.
+	ShareOutputs bool
+	// DependencyOutputs is how the shared outputs of the projects in
+	// DependsOn are passed to terraform as variables, PRMetadataVarsEnv or
+	// PRMetadataVarsTFVars. If empty they aren't passed.
+	DependencyOutputs string
+	// ApplyWindows are the periods the project may be applied in. If empty
+	// it may be applied at any time.
+	ApplyWindows []ApplyWindow
+	// Tool is the tool the project is planned and applied with. If empty,
+	// it's planned and applied with terraform.
+	Tool string
+	// PreviewEnvironment makes the project a preview environment of every
+	// pull request. It's nil if the project isn't one.
+	PreviewEnvironment *PreviewEnvironment
+	// Instances are the names of the Atlantis instances that run commands
+	// on the project. If empty, every instance does.
+	Instances []string
+	// LockKeys are shared locks held while the project is applied, so
+	// projects sharing a key are applied one at a time across repos.
+	LockKeys []string
+	// Canary makes the project the canary of the other projects in its dir.
+	// It's nil if the project isn't one.
+	Canary *Canary
+	// Labels are arbitrary key/value pairs describing the project, ex.
+	// tier: prod.
+	Labels map[string]string
 }
 
+// LockKeyRegex matches lock keys, ex. network-core.
+var LockKeyRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+const (
+	// PRMetadataVarsEnv passes the pull request's metadata as TF_VAR_ env vars.
+	PRMetadataVarsEnv = "env"
+	// PRMetadataVarsTFVars passes the pull request's metadata in a generated
+	// tfvars file.
+	PRMetadataVarsTFVars = "tfvars"
+)
+
 // GetName returns the name of the project or an empty string if there is no
 // project name.
 func (p Project) GetName() string {
@@ -231,6 +291,14 @@ type Step struct {
 	EnvVarValue string
 	// The Shell to use for RunCommand execution.
 	RunShell *CommandShell
+	// WasmModule is the path of the module run by a wasm step, relative to
+	// the project dir.
+	WasmModule string
+	// WasmArgs are the arguments passed to the wasm module.
+	WasmArgs []string
+	// WasmEnv are the names of the environment variables the wasm module can
+	// read. No other environment variables are passed to it.
+	WasmEnv []string
 }
 
 type Workflow struct {
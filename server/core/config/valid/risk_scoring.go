@@ -0,0 +1,82 @@
+package valid
+
+import (
+	"path"
+	"slices"
+)
+
+// Risk actions are the kinds of resource changes risk rules can match.
+const (
+	RiskActionCreate  = "create"
+	RiskActionUpdate  = "update"
+	RiskActionDelete  = "delete"
+	RiskActionReplace = "replace"
+)
+
+// RiskActions are the supported risk actions.
+var RiskActions = []string{RiskActionCreate, RiskActionUpdate, RiskActionDelete, RiskActionReplace}
+
+// RiskScoring scores plans by the resources they change and requires more
+// approvals before applying plans over the thresholds.
+type RiskScoring struct {
+	Rules []RiskRule
+	// ProviderScore is added for each provider whose resources the plan
+	// changes.
+	ProviderScore int
+	// Thresholds are sorted by ascending score.
+	Thresholds []RiskThreshold
+}
+
+// RiskRule adds Score for each resource change that matches it.
+type RiskRule struct {
+	// Actions are the risk actions matched. If empty, any change matches.
+	Actions []string
+	// ResourceTypes are glob patterns of the resource types matched, ex.
+	// aws_iam_*. If empty, any type matches.
+	ResourceTypes []string
+	Score         int
+}
+
+// RiskThreshold escalates the approvals required to apply plans with a risk
+// score of at least Score.
+type RiskThreshold struct {
+	Score int
+	// Name describes the risk level, ex. high.
+	Name string
+	// Approvals is the number of approvals required.
+	Approvals int
+	// Reviewers are the users and teams one of the approvals must come from.
+	// If empty, any approval counts.
+	Reviewers PolicyOwners
+}
+
+// Matches returns true if a change of a resource of type resourceType with
+// action matches the rule.
+func (r RiskRule) Matches(resourceType string, action string) bool {
+	if len(r.Actions) > 0 && !slices.Contains(r.Actions, action) {
+		return false
+	}
+	if len(r.ResourceTypes) == 0 {
+		return true
+	}
+	for _, pattern := range r.ResourceTypes {
+		// Patterns are checked when the config is parsed so the error can be
+		// ignored.
+		if matched, _ := path.Match(pattern, resourceType); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Threshold returns the highest threshold score reaches or nil if it's below
+// all of them.
+func (r RiskScoring) Threshold(score int) *RiskThreshold {
+	var threshold *RiskThreshold
+	for i := range r.Thresholds {
+		if score >= r.Thresholds[i].Score {
+			threshold = &r.Thresholds[i]
+		}
+	}
+	return threshold
+}
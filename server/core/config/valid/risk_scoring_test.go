@@ -0,0 +1,32 @@
+package valid_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRiskRule_Matches(t *testing.T) {
+	rule := valid.RiskRule{
+		Actions:       []string{valid.RiskActionDelete, valid.RiskActionReplace},
+		ResourceTypes: []string{"aws_iam_*"},
+	}
+	Equals(t, true, rule.Matches("aws_iam_role", valid.RiskActionDelete))
+	Equals(t, false, rule.Matches("aws_iam_role", valid.RiskActionUpdate))
+	Equals(t, false, rule.Matches("aws_s3_bucket", valid.RiskActionDelete))
+	// Rules without actions or resource types match any change.
+	Equals(t, true, valid.RiskRule{}.Matches("aws_s3_bucket", valid.RiskActionCreate))
+}
+
+func TestRiskScoring_Threshold(t *testing.T) {
+	scoring := valid.RiskScoring{
+		Thresholds: []valid.RiskThreshold{
+			{Score: 10, Name: "medium"},
+			{Score: 20, Name: "high"},
+		},
+	}
+	Equals(t, (*valid.RiskThreshold)(nil), scoring.Threshold(9))
+	Equals(t, "medium", scoring.Threshold(10).Name)
+	Equals(t, "high", scoring.Threshold(25).Name)
+}
@@ -0,0 +1,13 @@
+package valid
+
+// DefaultRollbackApprovals is how many approvals a pull request needs to
+// apply a rollback plan if the server-side repo config doesn't say.
+const DefaultRollbackApprovals = 2
+
+// Rollback allows planning a return to the state recorded before the last
+// apply of a project with the rollback command.
+type Rollback struct {
+	// Approvals is how many approvals the pull request needs to apply a
+	// rollback plan, regardless of the apply requirements.
+	Approvals int
+}
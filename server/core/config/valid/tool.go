@@ -0,0 +1,109 @@
+package valid
+
+import "sort"
+
+// Tool names. Projects use ToolTerraform unless they set another tool.
+const (
+	ToolTerraform = "terraform"
+	ToolHelmfile  = "helmfile"
+	ToolKustomize = "kustomize"
+	ToolAnsible   = "ansible"
+	ToolStacks    = "stacks"
+)
+
+// Tool is a kind of project Atlantis can plan and apply other than terraform.
+type Tool struct {
+	// Workflow is the default workflow of the tool's projects. It replaces
+	// the default workflow but not workflows projects set themselves.
+	Workflow Workflow
+	// WhenModified are the default autoplan when_modified patterns of the
+	// tool's projects.
+	WhenModified []string
+}
+
+// Tools are the supported tools other than terraform, keyed by name. Their
+// plan steps save the planfile so apply and the pending plan finder work like
+// they do for terraform projects.
+var Tools = map[string]Tool{
+	ToolHelmfile: {
+		Workflow: Workflow{
+			Name: ToolHelmfile,
+			Plan: Stage{Steps: []Step{
+				{StepName: "run", RunCommand: `helmfile --no-color diff --context 3 && touch "$PLANFILE"`},
+			}},
+			Apply: Stage{Steps: []Step{
+				{StepName: "run", RunCommand: `helmfile --no-color apply --suppress-diff && rm -f "$PLANFILE"`},
+			}},
+		},
+		WhenModified: []string{"**/helmfile*.yaml", "**/helmfile*.yaml.gotmpl", "**/values*.yaml", "**/*.yaml.gotmpl"},
+	},
+	ToolKustomize: {
+		Workflow: Workflow{
+			Name: ToolKustomize,
+			// The rendered manifests are saved as the planfile so apply
+			// applies exactly what was diffed. kubectl diff exits with 1 if
+			// there are differences.
+			Plan: Stage{Steps: []Step{
+				{StepName: "run", RunCommand: `kubectl kustomize . > "$PLANFILE" && { kubectl diff -f "$PLANFILE"; rc=$?; [ $rc -eq 0 ] && echo "No differences from the cluster."; [ $rc -le 1 ]; }`},
+			}},
+			Apply: Stage{Steps: []Step{
+				{StepName: "run", RunCommand: `kubectl apply -f "$PLANFILE" && rm -f "$PLANFILE"`},
+			}},
+		},
+		WhenModified: []string{"**/kustomization.yaml", "**/kustomization.yml", "**/*.yaml", "**/*.yml"},
+	},
+	ToolAnsible: {
+		Workflow: Workflow{
+			Name: ToolAnsible,
+			// The playbook is playbook.yml in the project dir unless the
+			// ANSIBLE_PLAYBOOK env var is set. The inventory and other
+			// settings are read from ansible.cfg.
+			Plan: Stage{Steps: []Step{
+				{StepName: "run", RunCommand: `ANSIBLE_NOCOLOR=1 ansible-playbook --check --diff "${ANSIBLE_PLAYBOOK:-playbook.yml}" && touch "$PLANFILE"`},
+			}},
+			Apply: Stage{Steps: []Step{
+				{StepName: "run", RunCommand: `ANSIBLE_NOCOLOR=1 ansible-playbook --diff "${ANSIBLE_PLAYBOOK:-playbook.yml}" && rm -f "$PLANFILE"`},
+			}},
+		},
+		WhenModified: []string{"**/*.yml", "**/*.yaml", "**/*.j2", "**/ansible.cfg", "**/inventory/**"},
+	},
+	ToolStacks: {
+		Workflow: Workflow{
+			Name: ToolStacks,
+			// Each deployment of a stack is a project whose workspace is the
+			// deployment's name. The stacks CLI is tfstacks unless the
+			// TF_STACKS_CLI env var is set.
+			Plan: Stage{Steps: []Step{
+				{StepName: "run", RunCommand: `${TF_STACKS_CLI:-tfstacks} init && ${TF_STACKS_CLI:-tfstacks} plan -deployment="$WORKSPACE" && touch "$PLANFILE"`},
+			}},
+			Apply: Stage{Steps: []Step{
+				{StepName: "run", RunCommand: `${TF_STACKS_CLI:-tfstacks} apply -deployment="$WORKSPACE" && rm -f "$PLANFILE"`},
+			}},
+		},
+		WhenModified: []string{"**/*.tfstack.hcl", "**/*.tfdeploy.hcl", "**/.terraform.lock.hcl"},
+	},
+}
+
+// ToolWorkflow returns the workflow of tool's projects whose workflow would
+// otherwise be workflow.
+func ToolWorkflow(tool string, workflow Workflow) Workflow {
+	if t, ok := Tools[tool]; ok && workflow.Name == DefaultWorkflowName {
+		return t.Workflow
+	}
+	return workflow
+}
+
+// ToolNames returns the names of all supported tools, including terraform.
+func ToolNames() []string {
+	names := []string{ToolTerraform}
+	for name := range Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// UsesTerraform returns true if projects of tool are terraform projects.
+func UsesTerraform(tool string) bool {
+	return tool == "" || tool == ToolTerraform
+}
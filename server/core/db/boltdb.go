@@ -28,6 +28,7 @@ const (
 	locksBucketName       = "runLocks"
 	pullsBucketName       = "pulls"
 	globalLocksBucketName = "globalLocks"
+	recordsBucketName     = "records"
 	pullKeySeparator      = "::"
 )
 
@@ -56,6 +57,9 @@ func New(dataDir string) (*BoltDB, error) {
 		if _, err = tx.CreateBucketIfNotExists([]byte(globalLocksBucketName)); err != nil {
 			return errors.Wrapf(err, "creating bucket %q", globalLocksBucketName)
 		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(recordsBucketName)); err != nil {
+			return errors.Wrapf(err, "creating bucket %q", recordsBucketName)
+		}
 		return nil
 	})
 	if err != nil {
@@ -359,6 +363,9 @@ func (b *BoltDB) UpdatePullWithResults(pull models.PullRequest, newResults []com
 						res.ProjectName == proj.ProjectName {
 
 						proj.Status = res.PlanStatus()
+						if res.Labels != nil {
+							proj.Labels = res.Labels
+						}
 
 						// Updating only policy sets which are included in results; keeping the rest.
 						if len(proj.PolicyStatus) > 0 {
@@ -415,6 +422,27 @@ func (b *BoltDB) GetPullStatus(pull models.PullRequest) (*models.PullStatus, err
 	return s, nil
 }
 
+// ListPullStatuses returns the statuses of all pull requests. Statuses are
+// deleted when their pull request is closed so these are the open pulls.
+func (b *BoltDB) ListPullStatuses() ([]models.PullStatus, error) {
+	var statuses []models.PullStatus
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.pullsBucketName)
+		return bucket.ForEach(func(k, _ []byte) error {
+			s, err := b.getPullFromBucket(bucket, k)
+			if err != nil {
+				return err
+			}
+			statuses = append(statuses, *s)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "DB transaction failed")
+	}
+	return statuses, nil
+}
+
 // DeletePullStatus deletes the status for pull.
 func (b *BoltDB) DeletePullStatus(pull models.PullRequest) error {
 	key, err := b.pullKey(pull)
@@ -516,9 +544,43 @@ func (b *BoltDB) projectResultToProject(p command.ProjectResult) models.ProjectS
 		ProjectName:  p.ProjectName,
 		PolicyStatus: p.PolicyStatus(),
 		Status:       p.PlanStatus(),
+		Labels:       p.Labels,
 	}
 }
 
+// GetRecords returns the JSON encoded records named name or nil if there
+// aren't any.
+func (b *BoltDB) GetRecords(name string) ([]byte, error) {
+	var records []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(recordsBucketName))
+		if bucket == nil {
+			return nil
+		}
+		// The value is only valid during the transaction so copy it.
+		records = bytes.Clone(bucket.Get([]byte(name)))
+		return nil
+	})
+	return records, errors.Wrap(err, "DB transaction failed")
+}
+
+// UpdateRecords replaces the records named name with the records update
+// returns for the current ones.
+func (b *BoltDB) UpdateRecords(name string, update func(current []byte) ([]byte, error)) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(recordsBucketName))
+		if err != nil {
+			return err
+		}
+		records, err := update(bytes.Clone(bucket.Get([]byte(name))))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(name), records)
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
 func (b *BoltDB) Close() error {
 	return b.db.Close()
 }
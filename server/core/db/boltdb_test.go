@@ -537,6 +537,38 @@ func TestPullStatus_UpdateDeleteGet(t *testing.T) {
 	b.Close()
 }
 
+func TestPullStatus_List(t *testing.T) {
+	b := newTestDB2(t)
+	defer b.Close() // nolint: errcheck
+
+	statuses, err := b.ListPullStatuses()
+	Ok(t, err)
+	Equals(t, 0, len(statuses))
+
+	repo := models.Repo{
+		FullName: "runatlantis/atlantis",
+		VCSHost: models.VCSHost{
+			Hostname: "github.com",
+			Type:     models.Github,
+		},
+	}
+	for _, num := range []int{1, 2, 3} {
+		_, err = b.UpdatePullWithResults(
+			models.PullRequest{Num: num, BaseRepo: repo},
+			[]command.ProjectResult{{RepoRelDir: ".", Workspace: "default"}})
+		Ok(t, err)
+	}
+	Ok(t, b.DeletePullStatus(models.PullRequest{Num: 2, BaseRepo: repo}))
+
+	statuses, err = b.ListPullStatuses()
+	Ok(t, err)
+	var nums []int
+	for _, s := range statuses {
+		nums = append(nums, s.Pull.Num)
+	}
+	Equals(t, []int{1, 3}, nums)
+}
+
 // Test we can create a status, update a specific project's status within that
 // pull status, and when we getCommandLock all the project statuses, that specific project
 // should be updated.
@@ -602,6 +634,51 @@ func TestPullStatus_UpdateProject(t *testing.T) {
 	b.Close()
 }
 
+// Test that the labels of the projects are stored and kept when a project is
+// updated by a result without them.
+func TestPullStatus_UpdateLabels(t *testing.T) {
+	b := newTestDB2(t)
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+		},
+	}
+	labels := map[string]string{"tier": "prod"}
+	_, err := b.UpdatePullWithResults(
+		pull,
+		[]command.ProjectResult{
+			{
+				Command:     command.Plan,
+				RepoRelDir:  ".",
+				Workspace:   "default",
+				PlanSuccess: &models.PlanSuccess{},
+				Labels:      labels,
+			},
+		})
+	Ok(t, err)
+
+	status, err := b.UpdatePullWithResults(
+		pull,
+		[]command.ProjectResult{
+			{
+				Command:    command.Apply,
+				RepoRelDir: ".",
+				Workspace:  "default",
+				Error:      errors.New("err"),
+			},
+		})
+	Ok(t, err)
+	Equals(t, labels, status.Projects[0].Labels)
+
+	maybeStatus, err := b.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, labels, maybeStatus.Projects[0].Labels)
+	b.Close()
+}
+
 // Test that if we update an existing pull status and our new status is for a
 // different HeadSHA, that we just overwrite the old status.
 func TestPullStatus_UpdateNewCommit(t *testing.T) {
@@ -0,0 +1,167 @@
+package kubernetes
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// serviceAccountDir is where Kubernetes mounts the credentials of the pod's
+// service account.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Client reads Atlantis' custom resources from the Kubernetes API and updates
+// their status. It talks to the API directly since it only needs a few
+// calls.
+type Client struct {
+	// Host is the URL of the Kubernetes API, ex. https://10.0.0.1:443.
+	Host string
+	// TokenFile is the path to the service account token. It's read on each
+	// request since Kubernetes rotates it.
+	TokenFile  string
+	HTTPClient *http.Client
+}
+
+// NewInClusterClient returns a client that authenticates with the service
+// account of the pod Atlantis runs in.
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set, is Atlantis running in Kubernetes?")
+	}
+	caCert, err := os.ReadFile(path.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("reading service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("service account CA contains no certificates")
+	}
+	return &Client{
+		Host:      "https://" + net.JoinHostPort(host, port),
+		TokenFile: path.Join(serviceAccountDir, "token"),
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+			},
+		},
+	}, nil
+}
+
+// ListRepoConfigs returns the AtlantisRepoConfig resources in namespace, or
+// in all namespaces if namespace is empty.
+func (c *Client) ListRepoConfigs(namespace string) ([]AtlantisRepoConfig, error) {
+	var items []AtlantisRepoConfig
+	err := c.list(RepoConfigsResource, namespace, func(data []byte) error {
+		var page struct {
+			Items []AtlantisRepoConfig `json:"items"`
+		}
+		err := json.Unmarshal(data, &page)
+		items = append(items, page.Items...)
+		return err
+	})
+	return items, err
+}
+
+// ListPolicySets is like ListRepoConfigs for AtlantisPolicySet resources.
+func (c *Client) ListPolicySets(namespace string) ([]AtlantisPolicySet, error) {
+	var items []AtlantisPolicySet
+	err := c.list(PolicySetsResource, namespace, func(data []byte) error {
+		var page struct {
+			Items []AtlantisPolicySet `json:"items"`
+		}
+		err := json.Unmarshal(data, &page)
+		items = append(items, page.Items...)
+		return err
+	})
+	return items, err
+}
+
+// UpdateStatus sets the status of the resource with meta.
+func (c *Client) UpdateStatus(resource string, meta ObjectMeta, status ResourceStatus) error {
+	body, err := json.Marshal(map[string]ResourceStatus{"status": status})
+	if err != nil {
+		return err
+	}
+	apiPath := fmt.Sprintf("%s/%s/status", c.resourcePath(resource, meta.Namespace), url.PathEscape(meta.Name))
+	_, err = c.do(http.MethodPatch, apiPath, "application/merge-patch+json", body)
+	return err
+}
+
+// list calls addPage with each page of the resources in namespace.
+func (c *Client) list(resource string, namespace string, addPage func(data []byte) error) error {
+	continueToken := ""
+	for {
+		query := url.Values{"limit": {"500"}}
+		if continueToken != "" {
+			query.Set("continue", continueToken)
+		}
+		data, err := c.do(http.MethodGet, c.resourcePath(resource, namespace)+"?"+query.Encode(), "", nil)
+		if err != nil {
+			return err
+		}
+		if err := addPage(data); err != nil {
+			return fmt.Errorf("parsing %s: %w", resource, err)
+		}
+		var page struct {
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("parsing %s: %w", resource, err)
+		}
+		if page.Metadata.Continue == "" {
+			return nil
+		}
+		continueToken = page.Metadata.Continue
+	}
+}
+
+func (c *Client) resourcePath(resource string, namespace string) string {
+	if namespace == "" {
+		return fmt.Sprintf("/apis/%s/%s/%s", Group, Version, resource)
+	}
+	return fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s", Group, Version, url.PathEscape(namespace), resource)
+}
+
+func (c *Client) do(method string, apiPath string, contentType string, body []byte) ([]byte, error) {
+	token, err := os.ReadFile(c.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading service account token: %w", err)
+	}
+	req, err := http.NewRequest(method, c.Host+apiPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s returned %d: %s", method, apiPath, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
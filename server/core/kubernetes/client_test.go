@@ -0,0 +1,79 @@
+package kubernetes_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/kubernetes"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestClient_ListRepoConfigs(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Query().Get("continue") {
+		case "":
+			Equals(t, "/apis/atlantis.runatlantis.io/v1alpha1/namespaces/atlantis/atlantisrepoconfigs", r.URL.Path)
+			w.Write([]byte(`{"metadata": {"continue": "page2"}, "items": [{"metadata": {"name": "infra", "namespace": "atlantis", "generation": 2}, ` + // nolint: errcheck
+				`"spec": {"id": "github.com/org/infra", "apply_requirements": ["approved"]}}]}`))
+		case "page2":
+			w.Write([]byte(`{"metadata": {}, "items": [{"metadata": {"name": "apps", "namespace": "atlantis"}, "spec": {"id": "/github.com/org/app-.*/"}}]}`)) // nolint: errcheck
+		}
+	}))
+	defer testServer.Close()
+	client := newTestClient(t, testServer)
+
+	repoConfigs, err := client.ListRepoConfigs("atlantis")
+	Ok(t, err)
+	Equals(t, 2, len(repoConfigs))
+	Equals(t, kubernetes.ObjectMeta{Name: "infra", Namespace: "atlantis", Generation: 2}, repoConfigs[0].Metadata)
+	Equals(t, "github.com/org/infra", repoConfigs[0].Spec.ID)
+	Equals(t, []string{"approved"}, repoConfigs[0].Spec.ApplyRequirements)
+	Equals(t, "/github.com/org/app-.*/", repoConfigs[1].Spec.ID)
+}
+
+func TestClient_UpdateStatus(t *testing.T) {
+	var gotMethod, gotPath, gotContentType, gotBody string
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotContentType = r.Method, r.URL.Path, r.Header.Get("Content-Type")
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		gotBody = string(body)
+		w.Write([]byte(`{}`)) // nolint: errcheck
+	}))
+	defer testServer.Close()
+	client := newTestClient(t, testServer)
+
+	err := client.UpdateStatus(kubernetes.PolicySetsResource, kubernetes.ObjectMeta{Name: "null-resources", Namespace: "atlantis", Generation: 3},
+		kubernetes.ResourceStatus{Message: "name: is required.", ObservedGeneration: 3})
+	Ok(t, err)
+	Equals(t, "PATCH", gotMethod)
+	Equals(t, "/apis/atlantis.runatlantis.io/v1alpha1/namespaces/atlantis/atlantispolicysets/null-resources/status", gotPath)
+	Equals(t, "application/merge-patch+json", gotContentType)
+	Equals(t, `{"status":{"ready":false,"message":"name: is required.","observedGeneration":3}}`, gotBody)
+}
+
+func TestClient_Error(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"reason": "Forbidden"}`, http.StatusForbidden)
+	}))
+	defer testServer.Close()
+	client := newTestClient(t, testServer)
+
+	_, err := client.ListPolicySets("")
+	ErrEquals(t, `GET /apis/atlantis.runatlantis.io/v1alpha1/atlantispolicysets?limit=500 returned 403: {"reason": "Forbidden"}`, err)
+}
+
+func newTestClient(t *testing.T, testServer *httptest.Server) *kubernetes.Client {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	Ok(t, os.WriteFile(tokenFile, []byte("token\n"), 0600))
+	return &kubernetes.Client{
+		Host:       testServer.URL,
+		TokenFile:  tokenFile,
+		HTTPClient: testServer.Client(),
+	}
+}
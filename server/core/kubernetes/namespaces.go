@@ -0,0 +1,51 @@
+package kubernetes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ParseNamespaces parses a list of namespace:repo-pattern entries separated by
+// commas, ex. team-a:github.com/org/team-a-*,platform:*, into the repo
+// patterns of each namespace. A namespace may be listed several times to
+// allow it several patterns.
+func ParseNamespaces(namespaces string) (map[string][]string, error) {
+	parsed := make(map[string][]string)
+	for _, entry := range strings.Split(namespaces, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		namespace, pattern, ok := strings.Cut(entry, ":")
+		if !ok || namespace == "" || pattern == "" {
+			return nil, fmt.Errorf("invalid namespace %q: must be of the form namespace:repo-pattern, ex. atlantis:github.com/org/*", entry)
+		}
+		parsed[namespace] = append(parsed[namespace], pattern)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("at least one namespace must be allowed")
+	}
+	return parsed, nil
+}
+
+// repoIDAllowed returns true if one of patterns matches id, the id of an
+// AtlantisRepoConfig. Patterns are repo IDs where * matches any characters,
+// like the repo allowlist. An id that's a regex, ex. /.*/, can match repos
+// outside of the patterns so only the * pattern allows it.
+func repoIDAllowed(patterns []string, id string) bool {
+	isRegex := len(id) > 1 && strings.HasPrefix(id, "/") && strings.HasSuffix(id, "/")
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+		if isRegex {
+			continue
+		}
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(pattern)), `\*`, ".*") + "$"
+		if regexp.MustCompile(expr).MatchString(strings.ToLower(id)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package kubernetes
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/metrics"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// ResourceClient reads Atlantis' custom resources and updates their status.
+type ResourceClient interface {
+	ListRepoConfigs(namespace string) ([]AtlantisRepoConfig, error)
+	ListPolicySets(namespace string) ([]AtlantisPolicySet, error)
+	UpdateStatus(resource string, meta ObjectMeta, status ResourceStatus) error
+}
+
+// Operator merges AtlantisRepoConfig and AtlantisPolicySet resources into the
+// server-side repo config. Repos from resources are added after the repos of
+// the config file so they take precedence, and policy sets are added to its
+// policy sets. A resource that's invalid is skipped and the error is reported
+// in its status.
+type Operator struct {
+	Client ResourceClient
+	// Namespaces are the namespaces to read resources from, mapped to the
+	// patterns of the repo IDs their AtlantisRepoConfig resources may
+	// configure. Resources of other repos are skipped.
+	Namespaces map[string][]string
+	// RepoConfig is the path to the server-side repo config file that
+	// resources are merged into. It's reread on each sync. If empty,
+	// resources are merged into an empty config.
+	RepoConfig      string
+	DefaultCfg      valid.GlobalCfg
+	ParserValidator *config.ParserValidator
+	Store           *valid.GlobalCfgStore
+	Logger          logging.SimpleLogging
+	Scope           tally.Scope
+
+	mu sync.Mutex
+}
+
+// Run syncs the config. It's meant to be run periodically by the scheduled
+// executor.
+func (o *Operator) Run() {
+	if err := o.Sync(); err != nil {
+		o.Logger.Err("syncing Kubernetes custom resources: %s", err)
+	}
+}
+
+// Reload syncs the config right away.
+func (o *Operator) Reload() error {
+	return o.Sync()
+}
+
+// Sync merges the current resources into the config file, stores the result
+// and updates the status of the resources. If the merged config is invalid,
+// the current config is kept.
+func (o *Operator) Sync() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	err := o.sync()
+	if err != nil {
+		o.Scope.Counter(metrics.ExecutionFailureMetric).Inc(1)
+		return err
+	}
+	o.Scope.Counter(metrics.ExecutionSuccessMetric).Inc(1)
+	return nil
+}
+
+func (o *Operator) sync() error {
+	var base raw.GlobalCfg
+	if o.RepoConfig != "" {
+		var err error
+		if base, err = o.ParserValidator.ParseRawGlobalCfg(o.RepoConfig); err != nil {
+			return err
+		}
+	}
+	var repoConfigs []AtlantisRepoConfig
+	var policySets []AtlantisPolicySet
+	for namespace := range o.Namespaces {
+		namespaceRepoConfigs, err := o.Client.ListRepoConfigs(namespace)
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", RepoConfigsResource, err)
+		}
+		repoConfigs = append(repoConfigs, namespaceRepoConfigs...)
+		namespacePolicySets, err := o.Client.ListPolicySets(namespace)
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", PolicySetsResource, err)
+		}
+		policySets = append(policySets, namespacePolicySets...)
+	}
+	// Sort the resources so repos are matched in a stable order.
+	sort.Slice(repoConfigs, func(i, j int) bool {
+		return resourceKey(repoConfigs[i].Metadata) < resourceKey(repoConfigs[j].Metadata)
+	})
+	sort.Slice(policySets, func(i, j int) bool {
+		return resourceKey(policySets[i].Metadata) < resourceKey(policySets[j].Metadata)
+	})
+
+	merged := base
+	merged.Repos = append([]raw.Repo{}, base.Repos...)
+	merged.PolicySets.PolicySets = append([]raw.PolicySet{}, base.PolicySets.PolicySets...)
+	repoErrs := make([]error, len(repoConfigs))
+	for i, r := range repoConfigs {
+		if !repoIDAllowed(o.Namespaces[r.Metadata.Namespace], r.Spec.ID) {
+			repoErrs[i] = fmt.Errorf("namespace %q isn't allowed to configure repo %q", r.Metadata.Namespace, r.Spec.ID)
+			continue
+		}
+		// Validate each repo on its own, added to the config file like it
+		// was edited, so one invalid resource doesn't reject the others.
+		single := base
+		single.Repos = append(slices.Clone(base.Repos), r.Spec)
+		if _, repoErrs[i] = o.ParserValidator.ValidateRawGlobalCfg(single, o.DefaultCfg); repoErrs[i] == nil {
+			merged.Repos = append(merged.Repos, r.Spec)
+		}
+	}
+	policySetErrs := make([]error, len(policySets))
+	for i, p := range policySets {
+		single := base
+		single.PolicySets.PolicySets = append(slices.Clone(base.PolicySets.PolicySets), p.Spec)
+		if _, policySetErrs[i] = o.ParserValidator.ValidateRawGlobalCfg(single, o.DefaultCfg); policySetErrs[i] == nil {
+			merged.PolicySets.PolicySets = append(merged.PolicySets.PolicySets, p.Spec)
+		}
+	}
+
+	cfg, mergeErr := o.ParserValidator.ValidateRawGlobalCfg(merged, o.DefaultCfg)
+	if mergeErr == nil {
+		mergeErr = o.Store.Reload(cfg)
+	}
+	if mergeErr == nil {
+		o.Logger.Debug("synced %d %s and %d %s", len(repoConfigs), RepoConfigsResource, len(policySets), PolicySetsResource)
+	}
+
+	for i, r := range repoConfigs {
+		o.updateStatus(RepoConfigsResource, r.Metadata, r.Status, repoErrs[i], mergeErr)
+	}
+	for i, p := range policySets {
+		o.updateStatus(PolicySetsResource, p.Metadata, p.Status, policySetErrs[i], mergeErr)
+	}
+	if mergeErr != nil {
+		return fmt.Errorf("keeping the current config since the merged config is invalid: %w", mergeErr)
+	}
+	return nil
+}
+
+// updateStatus reports err, the validation error of the resource, or
+// mergeErr if the merged config was rejected. The status is only updated if
+// it changed.
+func (o *Operator) updateStatus(resource string, meta ObjectMeta, current ResourceStatus, err error, mergeErr error) {
+	status := ResourceStatus{Ready: true, ObservedGeneration: meta.Generation}
+	switch {
+	case err != nil:
+		status = ResourceStatus{Message: err.Error(), ObservedGeneration: meta.Generation}
+	case mergeErr != nil:
+		status = ResourceStatus{Message: fmt.Sprintf("server-side repo config was rejected: %s", mergeErr), ObservedGeneration: meta.Generation}
+	}
+	if status == current {
+		return
+	}
+	if err != nil {
+		o.Logger.Warn("skipping %s %s: %s", resource, resourceKey(meta), err)
+	}
+	if updateErr := o.Client.UpdateStatus(resource, meta, status); updateErr != nil {
+		o.Logger.Err("updating status of %s %s: %s", resource, resourceKey(meta), updateErr)
+	}
+}
+
+func resourceKey(meta ObjectMeta) string {
+	return meta.Namespace + "/" + meta.Name
+}
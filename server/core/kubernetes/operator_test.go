@@ -0,0 +1,135 @@
+package kubernetes_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/kubernetes"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+type fakeResourceClient struct {
+	repoConfigs []kubernetes.AtlantisRepoConfig
+	policySets  []kubernetes.AtlantisPolicySet
+	listErr     error
+	statuses    map[string]kubernetes.ResourceStatus
+}
+
+func (f *fakeResourceClient) ListRepoConfigs(namespace string) ([]kubernetes.AtlantisRepoConfig, error) {
+	var repoConfigs []kubernetes.AtlantisRepoConfig
+	for _, r := range f.repoConfigs {
+		if r.Metadata.Namespace == namespace {
+			repoConfigs = append(repoConfigs, r)
+		}
+	}
+	return repoConfigs, f.listErr
+}
+
+func (f *fakeResourceClient) ListPolicySets(namespace string) ([]kubernetes.AtlantisPolicySet, error) {
+	var policySets []kubernetes.AtlantisPolicySet
+	for _, p := range f.policySets {
+		if p.Metadata.Namespace == namespace {
+			policySets = append(policySets, p)
+		}
+	}
+	return policySets, f.listErr
+}
+
+func (f *fakeResourceClient) UpdateStatus(resource string, meta kubernetes.ObjectMeta, status kubernetes.ResourceStatus) error {
+	f.statuses[resource+"/"+meta.Name] = status
+	return nil
+}
+
+func TestOperator_Sync(t *testing.T) {
+	repoConfig := filepath.Join(t.TempDir(), "repos.yaml")
+	Ok(t, os.WriteFile(repoConfig, []byte("repos:\n- id: /.*/\n  apply_requirements: [mergeable]\nworkflows:\n  custom: {}\n"), 0600))
+	customWorkflow, missingWorkflow := "custom", "missing"
+	client := &fakeResourceClient{
+		repoConfigs: []kubernetes.AtlantisRepoConfig{
+			{
+				Metadata: kubernetes.ObjectMeta{Name: "infra", Namespace: "atlantis", Generation: 1},
+				Spec:     raw.Repo{ID: "github.com/org/infra", ApplyRequirements: []string{"approved"}, Workflow: &customWorkflow},
+			},
+			{
+				Metadata: kubernetes.ObjectMeta{Name: "broken", Namespace: "atlantis", Generation: 4},
+				Spec:     raw.Repo{ID: "github.com/org/broken", Workflow: &missingWorkflow},
+			},
+			{
+				Metadata: kubernetes.ObjectMeta{Name: "everything", Namespace: "atlantis", Generation: 1},
+				Spec:     raw.Repo{ID: "/.*/", ApplyRequirements: []string{}},
+			},
+			{
+				Metadata: kubernetes.ObjectMeta{Name: "other-org", Namespace: "atlantis", Generation: 1},
+				Spec:     raw.Repo{ID: "github.com/other/infra", ApplyRequirements: []string{}},
+			},
+			{
+				Metadata: kubernetes.ObjectMeta{Name: "untrusted", Namespace: "untrusted", Generation: 1},
+				Spec:     raw.Repo{ID: "github.com/org/untrusted", ApplyRequirements: []string{}},
+			},
+		},
+		policySets: []kubernetes.AtlantisPolicySet{
+			{
+				Metadata: kubernetes.ObjectMeta{Name: "null-resources", Namespace: "atlantis", Generation: 2},
+				Spec:     raw.PolicySet{Name: "null_resource_warning", Path: "policies/null_resource_warning", Source: "local"},
+			},
+		},
+		statuses: map[string]kubernetes.ResourceStatus{},
+	}
+	defaultCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	store := valid.NewGlobalCfgStore(defaultCfg)
+	operator := &kubernetes.Operator{
+		Client:          client,
+		Namespaces:      map[string][]string{"atlantis": {"github.com/org/*"}},
+		RepoConfig:      repoConfig,
+		DefaultCfg:      defaultCfg,
+		ParserValidator: &config.ParserValidator{},
+		Store:           store,
+		Logger:          logging.NewNoopLogger(t),
+		Scope:           tally.NewTestScope("test", nil),
+	}
+
+	Ok(t, operator.Sync())
+	cfg := store.Load()
+	Equals(t, []string{"approved"}, cfg.MatchingRepo("github.com/org/infra").ApplyRequirements)
+	Equals(t, "custom", cfg.MatchingRepo("github.com/org/infra").Workflow.Name)
+	// The invalid resource is skipped so the repo matches the file's config.
+	Equals(t, []string{"mergeable"}, cfg.MatchingRepo("github.com/org/broken").ApplyRequirements)
+	// Resources can only configure the repos their namespace is allowed to
+	// and resources of other namespaces are ignored.
+	Equals(t, []string{"mergeable"}, cfg.MatchingRepo("github.com/other/infra").ApplyRequirements)
+	Equals(t, []string{"mergeable"}, cfg.MatchingRepo("github.com/org/untrusted").ApplyRequirements)
+	Equals(t, "null_resource_warning", cfg.PolicySets.PolicySets[0].Name)
+	Equals(t, map[string]kubernetes.ResourceStatus{
+		"atlantisrepoconfigs/infra":         {Ready: true, ObservedGeneration: 1},
+		"atlantisrepoconfigs/broken":        {Message: "workflow \"missing\" is not defined", ObservedGeneration: 4},
+		"atlantisrepoconfigs/everything":    {Message: "namespace \"atlantis\" isn't allowed to configure repo \"/.*/\"", ObservedGeneration: 1},
+		"atlantisrepoconfigs/other-org":     {Message: "namespace \"atlantis\" isn't allowed to configure repo \"github.com/other/infra\"", ObservedGeneration: 1},
+		"atlantispolicysets/null-resources": {Ready: true, ObservedGeneration: 2},
+	}, client.statuses)
+
+	// The current config is kept if the resources can't be listed.
+	client.listErr = errors.New("forbidden")
+	ErrEquals(t, "listing atlantisrepoconfigs: forbidden", operator.Sync())
+	Equals(t, []string{"approved"}, store.Load().MatchingRepo("github.com/org/infra").ApplyRequirements)
+}
+
+func TestParseNamespaces(t *testing.T) {
+	namespaces, err := kubernetes.ParseNamespaces("team-a:github.com/org/team-a-*, team-a:github.com/org/shared,platform:*")
+	Ok(t, err)
+	Equals(t, map[string][]string{
+		"team-a":   {"github.com/org/team-a-*", "github.com/org/shared"},
+		"platform": {"*"},
+	}, namespaces)
+
+	_, err = kubernetes.ParseNamespaces("team-a")
+	ErrEquals(t, "invalid namespace \"team-a\": must be of the form namespace:repo-pattern, ex. atlantis:github.com/org/*", err)
+	_, err = kubernetes.ParseNamespaces("")
+	ErrEquals(t, "at least one namespace must be allowed", err)
+}
@@ -0,0 +1,49 @@
+// Package kubernetes configures Atlantis from Kubernetes custom resources.
+package kubernetes
+
+import "github.com/runatlantis/atlantis/server/core/config/raw"
+
+const (
+	// Group and Version are the API group and version of Atlantis' custom
+	// resources.
+	Group   = "atlantis.runatlantis.io"
+	Version = "v1alpha1"
+
+	// RepoConfigsResource and PolicySetsResource are the plural names of the
+	// custom resources, used in their API paths.
+	RepoConfigsResource = "atlantisrepoconfigs"
+	PolicySetsResource  = "atlantispolicysets"
+)
+
+// ObjectMeta is the metadata of a custom resource that Atlantis uses.
+type ObjectMeta struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Generation int64  `json:"generation"`
+}
+
+// ResourceStatus reports whether a custom resource was applied to the
+// server-side repo config.
+type ResourceStatus struct {
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the resource the status
+	// refers to.
+	ObservedGeneration int64 `json:"observedGeneration"`
+}
+
+// AtlantisRepoConfig configures the repos that match its id. Its spec has the
+// same keys as a repo in the server-side repo config file.
+type AtlantisRepoConfig struct {
+	Metadata ObjectMeta     `json:"metadata"`
+	Spec     raw.Repo       `json:"spec"`
+	Status   ResourceStatus `json:"status"`
+}
+
+// AtlantisPolicySet adds a policy set. Its spec has the same keys as a policy
+// set in the server-side repo config file.
+type AtlantisPolicySet struct {
+	Metadata ObjectMeta     `json:"metadata"`
+	Spec     raw.PolicySet  `json:"spec"`
+	Status   ResourceStatus `json:"status"`
+}
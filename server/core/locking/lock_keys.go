@@ -0,0 +1,38 @@
+package locking
+
+import (
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// lockKeyRepoPrefix prefixes the repo of the locks of lock keys. Lock keys
+// aren't bound to a repo, so each one is stored in the backend as the lock of
+// a pseudo repo named after it. '@' can't be part of the owner of a real repo.
+const lockKeyRepoPrefix = "@lock-keys/"
+
+// LockKeyWorkspace is the workspace of the locks of lock keys.
+const LockKeyWorkspace = "default"
+
+// LockKeyProject returns the project the lock of key is stored as. holder is
+// the name of the project acquiring the key, kept so the lock shows who holds
+// it.
+func LockKeyProject(key string, holder string) models.Project {
+	return models.Project{
+		ProjectName:  holder,
+		RepoFullName: lockKeyRepoPrefix + key,
+		Path:         ".",
+	}
+}
+
+// IsLockKey returns true if p is the project of the lock of a lock key rather
+// than a Terraform project.
+func IsLockKey(p models.Project) bool {
+	return strings.HasPrefix(p.RepoFullName, lockKeyRepoPrefix)
+}
+
+// LockKeyName returns the lock key of p, a project returned by
+// LockKeyProject.
+func LockKeyName(p models.Project) string {
+	return strings.TrimPrefix(p.RepoFullName, lockKeyRepoPrefix)
+}
@@ -37,6 +37,7 @@ type Backend interface {
 	UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error)
 	UpdateProjectStatus(pull models.PullRequest, workspace string, repoRelDir string, newStatus models.ProjectPlanStatus) error
 	GetPullStatus(pull models.PullRequest) (*models.PullStatus, error)
+	ListPullStatuses() ([]models.PullStatus, error)
 	DeletePullStatus(pull models.PullRequest) error
 	UpdatePullWithResults(pull models.PullRequest, newResults []command.ProjectResult) (models.PullStatus, error)
 
@@ -63,7 +64,7 @@ type Client struct {
 //go:generate pegomock generate --package mocks -o mocks/mock_locker.go Locker
 
 type Locker interface {
-	TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User) (TryLockResponse, error)
+	TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User, cmdName command.Name) (TryLockResponse, error)
 	Unlock(key string) (*models.ProjectLock, error)
 	List() (map[string]models.ProjectLock, error)
 	UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error)
@@ -80,14 +81,16 @@ func NewClient(backend Backend) *Client {
 // keyRegex matches and captures {repoFullName}/{path}/{workspace} where path can have multiple /'s in it.
 var keyRegex = regexp.MustCompile(`^(.*?\/.*?)\/(.*)\/(.*)$`)
 
-// TryLock attempts to acquire a lock to a project and workspace.
-func (c *Client) TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User) (TryLockResponse, error) {
+// TryLock attempts to acquire a lock to a project and workspace for the
+// command cmdName.
+func (c *Client) TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User, cmdName command.Name) (TryLockResponse, error) {
 	lock := models.ProjectLock{
 		Workspace: workspace,
 		Time:      time.Now().Local(),
 		Project:   p,
 		User:      user,
 		Pull:      pull,
+		Command:   cmdName.String(),
 	}
 	lockAcquired, currLock, err := c.backend.TryLock(lock)
 	if err != nil {
@@ -166,7 +169,7 @@ func NewNoOpLocker() *NoOpLocker {
 }
 
 // TryLock attempts to acquire a lock to a project and workspace.
-func (c *NoOpLocker) TryLock(p models.Project, workspace string, _ models.PullRequest, _ models.User) (TryLockResponse, error) {
+func (c *NoOpLocker) TryLock(p models.Project, workspace string, _ models.PullRequest, _ models.User, _ command.Name) (TryLockResponse, error) {
 	return TryLockResponse{true, models.ProjectLock{}, c.key(p, workspace)}, nil
 }
 
@@ -42,7 +42,7 @@ func TestTryLock_Err(t *testing.T) {
 	When(backend.TryLock(Any[models.ProjectLock]())).ThenReturn(false, models.ProjectLock{}, errExpected)
 	t.Log("when the backend returns an error, TryLock should return that error")
 	l := locking.NewClient(backend)
-	_, err := l.TryLock(project, workspace, pull, user)
+	_, err := l.TryLock(project, workspace, pull, user, command.Plan)
 	Equals(t, err, err)
 }
 
@@ -52,9 +52,11 @@ func TestTryLock_Success(t *testing.T) {
 	backend := mocks.NewMockBackend()
 	When(backend.TryLock(Any[models.ProjectLock]())).ThenReturn(true, currLock, nil)
 	l := locking.NewClient(backend)
-	r, err := l.TryLock(project, workspace, pull, user)
+	r, err := l.TryLock(project, workspace, pull, user, command.Plan)
 	Ok(t, err)
 	Equals(t, locking.TryLockResponse{LockAcquired: true, CurrLock: currLock, LockKey: "owner/repo/path/workspace"}, r)
+	lock := backend.VerifyWasCalledOnce().TryLock(Any[models.ProjectLock]()).GetCapturedArguments()
+	Equals(t, "plan", lock.Command)
 }
 
 func TestUnlock_InvalidKey(t *testing.T) {
@@ -149,7 +151,7 @@ func TestTryLock_NoOpLocker(t *testing.T) {
 	RegisterMockTestingT(t)
 	currLock := models.ProjectLock{}
 	l := locking.NewNoOpLocker()
-	r, err := l.TryLock(project, workspace, pull, user)
+	r, err := l.TryLock(project, workspace, pull, user, command.Plan)
 	Ok(t, err)
 	Equals(t, locking.TryLockResponse{LockAcquired: true, CurrLock: currLock, LockKey: "owner/repo/path/workspace"}, r)
 }
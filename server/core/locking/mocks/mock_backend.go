@@ -117,6 +117,25 @@ func (mock *MockBackend) List() ([]models.ProjectLock, error) {
 	return _ret0, _ret1
 }
 
+func (mock *MockBackend) ListPullStatuses() ([]models.PullStatus, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockBackend().")
+	}
+	_params := []pegomock.Param{}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ListPullStatuses", _params, []reflect.Type{reflect.TypeOf((*[]models.PullStatus)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 []models.PullStatus
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].([]models.PullStatus)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
 func (mock *MockBackend) LockCommand(cmdName command.Name, lockTime time.Time) (*command.Lock, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockBackend().")
@@ -422,6 +441,23 @@ func (c *MockBackend_List_OngoingVerification) GetCapturedArguments() {
 func (c *MockBackend_List_OngoingVerification) GetAllCapturedArguments() {
 }
 
+func (verifier *VerifierMockBackend) ListPullStatuses() *MockBackend_ListPullStatuses_OngoingVerification {
+	_params := []pegomock.Param{}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListPullStatuses", _params, verifier.timeout)
+	return &MockBackend_ListPullStatuses_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockBackend_ListPullStatuses_OngoingVerification struct {
+	mock              *MockBackend
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockBackend_ListPullStatuses_OngoingVerification) GetCapturedArguments() {
+}
+
+func (c *MockBackend_ListPullStatuses_OngoingVerification) GetAllCapturedArguments() {
+}
+
 func (verifier *VerifierMockBackend) LockCommand(cmdName command.Name, lockTime time.Time) *MockBackend_LockCommand_OngoingVerification {
 	_params := []pegomock.Param{cmdName, lockTime}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "LockCommand", _params, verifier.timeout)
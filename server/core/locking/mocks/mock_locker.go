@@ -6,6 +6,7 @@ package mocks
 import (
 	pegomock "github.com/petergtz/pegomock/v4"
 	locking "github.com/runatlantis/atlantis/server/core/locking"
+	command "github.com/runatlantis/atlantis/server/events/command"
 	models "github.com/runatlantis/atlantis/server/events/models"
 	"reflect"
 	"time"
@@ -64,11 +65,11 @@ func (mock *MockLocker) List() (map[string]models.ProjectLock, error) {
 	return _ret0, _ret1
 }
 
-func (mock *MockLocker) TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User) (locking.TryLockResponse, error) {
+func (mock *MockLocker) TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User, cmdName command.Name) (locking.TryLockResponse, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockLocker().")
 	}
-	_params := []pegomock.Param{p, workspace, pull, user}
+	_params := []pegomock.Param{p, workspace, pull, user, cmdName}
 	_result := pegomock.GetGenericMockFrom(mock).Invoke("TryLock", _params, []reflect.Type{reflect.TypeOf((*locking.TryLockResponse)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
 	var _ret0 locking.TryLockResponse
 	var _ret1 error
@@ -204,8 +205,8 @@ func (c *MockLocker_List_OngoingVerification) GetCapturedArguments() {
 func (c *MockLocker_List_OngoingVerification) GetAllCapturedArguments() {
 }
 
-func (verifier *VerifierMockLocker) TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User) *MockLocker_TryLock_OngoingVerification {
-	_params := []pegomock.Param{p, workspace, pull, user}
+func (verifier *VerifierMockLocker) TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User, cmdName command.Name) *MockLocker_TryLock_OngoingVerification {
+	_params := []pegomock.Param{p, workspace, pull, user, cmdName}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "TryLock", _params, verifier.timeout)
 	return &MockLocker_TryLock_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -215,12 +216,12 @@ type MockLocker_TryLock_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *MockLocker_TryLock_OngoingVerification) GetCapturedArguments() (models.Project, string, models.PullRequest, models.User) {
-	p, workspace, pull, user := c.GetAllCapturedArguments()
-	return p[len(p)-1], workspace[len(workspace)-1], pull[len(pull)-1], user[len(user)-1]
+func (c *MockLocker_TryLock_OngoingVerification) GetCapturedArguments() (models.Project, string, models.PullRequest, models.User, command.Name) {
+	p, workspace, pull, user, cmdName := c.GetAllCapturedArguments()
+	return p[len(p)-1], workspace[len(workspace)-1], pull[len(pull)-1], user[len(user)-1], cmdName[len(cmdName)-1]
 }
 
-func (c *MockLocker_TryLock_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Project, _param1 []string, _param2 []models.PullRequest, _param3 []models.User) {
+func (c *MockLocker_TryLock_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Project, _param1 []string, _param2 []models.PullRequest, _param3 []models.User, _param4 []command.Name) {
 	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(_params) > 0 {
 		if len(_params) > 0 {
@@ -247,6 +248,12 @@ func (c *MockLocker_TryLock_OngoingVerification) GetAllCapturedArguments() (_par
 				_param3[u] = param.(models.User)
 			}
 		}
+		if len(_params) > 4 {
+			_param4 = make([]command.Name, len(c.methodInvocations))
+			for u, param := range _params[4] {
+				_param4[u] = param.(command.Name)
+			}
+		}
 	}
 	return
 }
@@ -0,0 +1,124 @@
+package locking
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+)
+
+// PlanFreezer manages plan freezes. While a freeze is active, plan commands
+// and autoplans in its scope are rejected with the freeze's message.
+type PlanFreezer interface {
+	// Freeze creates freeze, replacing any freeze with the same scope.
+	Freeze(freeze PlanFreeze) (PlanFreeze, error)
+	// Unfreeze deletes the freeze with the scope of repo, project and dir. It
+	// returns false if there was no such freeze.
+	Unfreeze(repo string, project string, dir string) (bool, error)
+	// List returns the active freezes, oldest first.
+	List() ([]PlanFreeze, error)
+}
+
+// PlanFreeze disables plans globally if Repo is empty, for a repo if Project
+// and Dir are empty or otherwise for the projects of a repo with the name
+// Project or in Dir.
+type PlanFreeze struct {
+	Repo    string
+	Project string
+	Dir     string
+	// Message is commented on pull requests instead of plans. If empty, the
+	// default freeze message is used.
+	Message string
+	Time    time.Time
+}
+
+// IsGlobal returns true if the freeze applies to all repos.
+func (f PlanFreeze) IsGlobal() bool {
+	return f.Repo == ""
+}
+
+// IsForProject returns true if the freeze only applies to some projects of
+// its repo.
+func (f PlanFreeze) IsForProject() bool {
+	return f.Project != "" || f.Dir != ""
+}
+
+// Matches returns true if the freeze applies to the project with the name
+// project in dir of repo. If project and dir are empty, only global and repo
+// freezes match.
+func (f PlanFreeze) Matches(repo string, project string, dir string) bool {
+	if f.IsGlobal() {
+		return true
+	}
+	if f.Repo != repo {
+		return false
+	}
+	if !f.IsForProject() {
+		return true
+	}
+	if f.Project != "" {
+		return f.Project == project
+	}
+	return dir != "" && f.Dir == filepath.Clean(dir)
+}
+
+func (f PlanFreeze) sameScope(repo string, project string, dir string) bool {
+	return f.Repo == repo && f.Project == project && f.Dir == dir
+}
+
+// FindPlanFreeze returns the first freeze of freezes that matches repo,
+// project and dir or nil if there isn't one.
+func FindPlanFreeze(freezes []PlanFreeze, repo string, project string, dir string) *PlanFreeze {
+	for _, f := range freezes {
+		if f.Matches(repo, project, dir) {
+			return &f
+		}
+	}
+	return nil
+}
+
+// DefaultPlanFreezer stores plan freezes in the locking backend so they
+// survive restarts and are shared by the Atlantis instances.
+type DefaultPlanFreezer struct {
+	freezes *RecordStore[PlanFreeze]
+}
+
+// NewPlanFreezer returns a PlanFreezer that stores freezes in backend.
+func NewPlanFreezer(backend RecordBackend) *DefaultPlanFreezer {
+	return &DefaultPlanFreezer{freezes: NewRecordStore[PlanFreeze](backend, "plan-freezes")}
+}
+
+func (p *DefaultPlanFreezer) Freeze(freeze PlanFreeze) (PlanFreeze, error) {
+	if freeze.Repo == "" && freeze.IsForProject() {
+		return freeze, errors.New("project or dir requires repo")
+	}
+	if freeze.Dir != "" {
+		freeze.Dir = filepath.Clean(freeze.Dir)
+	}
+	if freeze.Time.IsZero() {
+		freeze.Time = time.Now()
+	}
+	// A freeze replaces the one with the same scope but is listed last.
+	return freeze, p.freezes.Update(func(freezes []PlanFreeze) ([]PlanFreeze, error) {
+		var updated []PlanFreeze
+		for _, f := range freezes {
+			if !f.sameScope(freeze.Repo, freeze.Project, freeze.Dir) {
+				updated = append(updated, f)
+			}
+		}
+		return append(updated, freeze), nil
+	})
+}
+
+func (p *DefaultPlanFreezer) Unfreeze(repo string, project string, dir string) (bool, error) {
+	if dir != "" {
+		dir = filepath.Clean(dir)
+	}
+	deleted, err := p.freezes.DeleteFunc(func(f PlanFreeze) bool {
+		return f.sameScope(repo, project, dir)
+	})
+	return deleted > 0, err
+}
+
+func (p *DefaultPlanFreezer) List() ([]PlanFreeze, error) {
+	return p.freezes.List()
+}
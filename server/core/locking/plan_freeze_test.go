@@ -0,0 +1,83 @@
+package locking_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/locking"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPlanFreezer(t *testing.T) {
+	backend := newTestBoltDB(t)
+	planFreezer := locking.NewPlanFreezer(backend)
+
+	freezes, err := planFreezer.List()
+	Ok(t, err)
+	Equals(t, 0, len(freezes))
+
+	_, err = planFreezer.Freeze(locking.PlanFreeze{Repo: "owner/repo", Message: "first"})
+	Ok(t, err)
+	freeze, err := planFreezer.Freeze(locking.PlanFreeze{Repo: "owner/repo", Dir: "./modules/../infra/"})
+	Ok(t, err)
+	Equals(t, "infra", freeze.Dir)
+	Assert(t, !freeze.Time.IsZero(), "expected the time of the freeze to be set")
+
+	// A freeze with the same scope is replaced.
+	_, err = planFreezer.Freeze(locking.PlanFreeze{Repo: "owner/repo", Message: "second"})
+	Ok(t, err)
+
+	// Freezes are read from the backend by a new freezer.
+	freezes, err = locking.NewPlanFreezer(backend).List()
+	Ok(t, err)
+	Equals(t, 2, len(freezes))
+	Equals(t, "infra", freezes[0].Dir)
+	Equals(t, "second", freezes[1].Message)
+
+	deleted, err := planFreezer.Unfreeze("owner/repo", "", "infra/")
+	Ok(t, err)
+	Assert(t, deleted, "expected the freeze to be deleted")
+	deleted, err = planFreezer.Unfreeze("owner/repo", "", "infra")
+	Ok(t, err)
+	Assert(t, !deleted, "expected no freeze to be deleted")
+	freezes, err = planFreezer.List()
+	Ok(t, err)
+	Equals(t, 1, len(freezes))
+}
+
+func TestPlanFreezer_ProjectRequiresRepo(t *testing.T) {
+	planFreezer := locking.NewPlanFreezer(newTestBoltDB(t))
+	_, err := planFreezer.Freeze(locking.PlanFreeze{Project: "infra"})
+	ErrEquals(t, "project or dir requires repo", err)
+}
+
+func TestFindPlanFreeze(t *testing.T) {
+	now := time.Now()
+	global := locking.PlanFreeze{Time: now}
+	repo := locking.PlanFreeze{Repo: "owner/repo", Time: now}
+	project := locking.PlanFreeze{Repo: "owner/repo", Project: "infra", Time: now}
+	dir := locking.PlanFreeze{Repo: "owner/repo", Dir: "modules/vpc", Time: now}
+
+	cases := []struct {
+		Description string
+		Freezes     []locking.PlanFreeze
+		Repo        string
+		Project     string
+		Dir         string
+		Exp         *locking.PlanFreeze
+	}{
+		{"no freezes", nil, "owner/repo", "", "", nil},
+		{"global freeze", []locking.PlanFreeze{global}, "owner/other", "infra", "infra", &global},
+		{"repo freeze", []locking.PlanFreeze{repo}, "owner/repo", "", "", &repo},
+		{"repo freeze of another repo", []locking.PlanFreeze{repo}, "owner/other", "", "", nil},
+		{"project freeze without a project", []locking.PlanFreeze{project, dir}, "owner/repo", "", "", nil},
+		{"project freeze", []locking.PlanFreeze{project, dir}, "owner/repo", "infra", ".", &project},
+		{"project freeze of another project", []locking.PlanFreeze{project}, "owner/repo", "vpc", "modules/vpc", nil},
+		{"dir freeze", []locking.PlanFreeze{project, dir}, "owner/repo", "vpc", "./modules/vpc", &dir},
+	}
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			Equals(t, c.Exp, locking.FindPlanFreeze(c.Freezes, c.Repo, c.Project, c.Dir))
+		})
+	}
+}
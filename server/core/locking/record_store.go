@@ -0,0 +1,99 @@
+package locking
+
+import (
+	"encoding/json"
+)
+
+// RecordBackend stores lists of records by name, ex. the plan freezes. The
+// BoltDB and Redis backends implement it so records are shared by the
+// Atlantis instances using the same Redis.
+type RecordBackend interface {
+	// GetRecords returns the JSON encoded records named name or nil if there
+	// aren't any.
+	GetRecords(name string) ([]byte, error)
+	// UpdateRecords replaces the records named name with the records update
+	// returns for the current ones, as one transaction.
+	UpdateRecords(name string, update func(current []byte) ([]byte, error)) error
+}
+
+// RecordStore stores a list of records of type T, ex. plan freezes, in a
+// RecordBackend. Records are kept in the order they were added.
+type RecordStore[T any] struct {
+	backend RecordBackend
+	name    string
+}
+
+// NewRecordStore returns a store of the records named name in backend.
+func NewRecordStore[T any](backend RecordBackend, name string) *RecordStore[T] {
+	return &RecordStore[T]{backend: backend, name: name}
+}
+
+// List returns the records.
+func (s *RecordStore[T]) List() ([]T, error) {
+	contents, err := s.backend.GetRecords(s.name)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecords[T](contents)
+}
+
+// Update replaces the records with the ones update returns for the current
+// ones. If update returns an error nothing is written and the error is
+// returned.
+func (s *RecordStore[T]) Update(update func(records []T) ([]T, error)) error {
+	return s.backend.UpdateRecords(s.name, func(current []byte) ([]byte, error) {
+		records, err := decodeRecords[T](current)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := update(records)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(updated)
+	})
+}
+
+// Put replaces the first record same returns true for with record or adds
+// record if there isn't one.
+func (s *RecordStore[T]) Put(record T, same func(T) bool) error {
+	return s.Update(func(records []T) ([]T, error) {
+		for i, r := range records {
+			if same(r) {
+				records[i] = record
+				return records, nil
+			}
+		}
+		return append(records, record), nil
+	})
+}
+
+// DeleteFunc deletes the records del returns true for. It returns the
+// number of deleted records.
+func (s *RecordStore[T]) DeleteFunc(del func(T) bool) (int, error) {
+	var deleted int
+	err := s.Update(func(records []T) ([]T, error) {
+		deleted = 0
+		var kept []T
+		for _, r := range records {
+			if del(r) {
+				deleted++
+				continue
+			}
+			kept = append(kept, r)
+		}
+		return kept, nil
+	})
+	return deleted, err
+}
+
+func decodeRecords[T any](contents []byte) ([]T, error) {
+	if len(contents) == 0 {
+		return nil, nil
+	}
+	var records []T
+	if err := json.Unmarshal(contents, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,119 @@
+package locking_test
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/core/redis"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type testRecord struct {
+	Key   string
+	Value int
+}
+
+func newTestBoltDB(t *testing.T) locking.RecordBackend {
+	boltDB, err := db.New(t.TempDir())
+	Ok(t, err)
+	t.Cleanup(func() { boltDB.Close() }) // nolint: errcheck
+	return boltDB
+}
+
+func newTestRedisDB(t *testing.T) locking.RecordBackend {
+	mr := miniredis.RunT(t)
+	r, err := redis.New(mr.Host(), mr.Server().Addr().Port, "", false, false, 0)
+	Ok(t, err)
+	return r
+}
+
+func TestRecordStore(t *testing.T) {
+	sameKey := func(key string) func(testRecord) bool {
+		return func(r testRecord) bool { return r.Key == key }
+	}
+	cases := []struct {
+		description string
+		// run changes the store, which starts with the records a=1 and b=2.
+		run        func(t *testing.T, store *locking.RecordStore[testRecord]) error
+		expRecords []testRecord
+	}{
+		{
+			description: "list",
+			run:         func(*testing.T, *locking.RecordStore[testRecord]) error { return nil },
+			expRecords:  []testRecord{{"a", 1}, {"b", 2}},
+		},
+		{
+			description: "put replaces the same record",
+			run: func(_ *testing.T, store *locking.RecordStore[testRecord]) error {
+				return store.Put(testRecord{"a", 3}, sameKey("a"))
+			},
+			expRecords: []testRecord{{"a", 3}, {"b", 2}},
+		},
+		{
+			description: "put adds a new record last",
+			run: func(_ *testing.T, store *locking.RecordStore[testRecord]) error {
+				return store.Put(testRecord{"c", 3}, sameKey("c"))
+			},
+			expRecords: []testRecord{{"a", 1}, {"b", 2}, {"c", 3}},
+		},
+		{
+			description: "delete",
+			run: func(t *testing.T, store *locking.RecordStore[testRecord]) error {
+				deleted, err := store.DeleteFunc(sameKey("a"))
+				Equals(t, 1, deleted)
+				return err
+			},
+			expRecords: []testRecord{{"b", 2}},
+		},
+		{
+			description: "delete nothing",
+			run: func(t *testing.T, store *locking.RecordStore[testRecord]) error {
+				deleted, err := store.DeleteFunc(sameKey("c"))
+				Equals(t, 0, deleted)
+				return err
+			},
+			expRecords: []testRecord{{"a", 1}, {"b", 2}},
+		},
+		{
+			description: "failed update writes nothing",
+			run: func(t *testing.T, store *locking.RecordStore[testRecord]) error {
+				err := store.Update(func([]testRecord) ([]testRecord, error) {
+					return nil, errors.New("conflict")
+				})
+				ErrEquals(t, "conflict", errors.Cause(err))
+				return nil
+			},
+			expRecords: []testRecord{{"a", 1}, {"b", 2}},
+		},
+	}
+	backends := map[string]func(t *testing.T) locking.RecordBackend{
+		"boltdb": newTestBoltDB,
+		"redis":  newTestRedisDB,
+	}
+	for backendName, newBackend := range backends {
+		for _, c := range cases {
+			t.Run(backendName+"/"+c.description, func(t *testing.T) {
+				backend := newBackend(t)
+				store := locking.NewRecordStore[testRecord](backend, "test")
+				records, err := store.List()
+				Ok(t, err)
+				Equals(t, 0, len(records))
+				Ok(t, store.Put(testRecord{"a", 1}, sameKey("a")))
+				Ok(t, store.Put(testRecord{"b", 2}, sameKey("b")))
+
+				Ok(t, c.run(t, store))
+
+				// Records are read from the backend by a new store.
+				records, err = locking.NewRecordStore[testRecord](backend, "test").List()
+				Ok(t, err)
+				Equals(t, c.expRecords, records)
+				other, err := locking.NewRecordStore[testRecord](backend, "other").List()
+				Ok(t, err)
+				Equals(t, 0, len(other))
+			})
+		}
+	}
+}
@@ -24,6 +24,12 @@ type RedisDB struct { // nolint: revive
 
 const (
 	pullKeySeparator = "::"
+	// recordsKeyPrefix prefixes the keys of the records stored with
+	// UpdateRecords.
+	recordsKeyPrefix = "records::"
+	// maxRecordsUpdateRetries is how many times UpdateRecords retries when
+	// another instance updated the same records first.
+	maxRecordsUpdateRetries = 10
 )
 
 func New(hostname string, port int, password string, tlsEnabled bool, insecureSkipVerify bool, db int) (*RedisDB, error) {
@@ -289,6 +295,26 @@ func (r *RedisDB) GetPullStatus(pull models.PullRequest) (*models.PullStatus, er
 	return pullStatus, nil
 }
 
+// ListPullStatuses returns the statuses of all pull requests. Statuses are
+// deleted when their pull request is closed so these are the open pulls.
+func (r *RedisDB) ListPullStatuses() ([]models.PullStatus, error) {
+	var statuses []models.PullStatus
+	iter := r.client.Scan(ctx, 0, fmt.Sprintf("*%s*%s*", pullKeySeparator, pullKeySeparator), 0).Iterator()
+	for iter.Next(ctx) {
+		pullStatus, err := r.getPull(iter.Val())
+		if err != nil {
+			return nil, err
+		}
+		if pullStatus != nil {
+			statuses = append(statuses, *pullStatus)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+	return statuses, nil
+}
+
 func (r *RedisDB) DeletePullStatus(pull models.PullRequest) error {
 	key, err := r.pullKey(pull)
 	if err != nil {
@@ -343,6 +369,9 @@ func (r *RedisDB) UpdatePullWithResults(pull models.PullRequest, newResults []co
 					res.ProjectName == proj.ProjectName {
 
 					proj.Status = res.PlanStatus()
+					if res.Labels != nil {
+						proj.Labels = res.Labels
+					}
 
 					// Updating only policy sets which are included in results; keeping the rest.
 					if len(proj.PolicyStatus) > 0 {
@@ -405,6 +434,48 @@ func (r *RedisDB) writePull(key string, pull models.PullStatus) error {
 	return nil
 }
 
+// GetRecords returns the JSON encoded records named name or nil if there
+// aren't any.
+func (r *RedisDB) GetRecords(name string) ([]byte, error) {
+	records, err := r.client.Get(ctx, recordsKeyPrefix+name).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+	return records, nil
+}
+
+// UpdateRecords replaces the records named name with the records update
+// returns for the current ones. The key is watched so the update is retried
+// if another Atlantis instance changes the records in between.
+func (r *RedisDB) UpdateRecords(name string, update func(current []byte) ([]byte, error)) error {
+	key := recordsKeyPrefix + name
+	txf := func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, key).Bytes()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		records, err := update(current)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, records, 0)
+			return nil
+		})
+		return err
+	}
+	for i := 0; i < maxRecordsUpdateRetries; i++ {
+		err := r.client.Watch(ctx, txf, key)
+		if err != redis.TxFailedErr {
+			return errors.Wrap(err, "db transaction failed")
+		}
+	}
+	return errors.New("db transaction failed: records were changed concurrently too many times")
+}
+
 func (r *RedisDB) deletePull(key string) error {
 	err := r.client.Del(ctx, key).Err()
 	if err != nil {
@@ -441,5 +512,6 @@ func (r *RedisDB) projectResultToProject(p command.ProjectResult) models.Project
 		ProjectName:  p.ProjectName,
 		PolicyStatus: p.PolicyStatus(),
 		Status:       p.PlanStatus(),
+		Labels:       p.Labels,
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -39,6 +40,21 @@ func (a *ApplyStepRunner) Run(ctx command.ProjectContext, extraArgs []string, pa
 		return "", errors.Wrap(err, "unable to read planfile")
 	}
 
+	// The planfile already limits the apply to the targets it was created
+	// with, but if the user passed --target we make sure they match so a
+	// partial apply is never a surprise.
+	targetsPath := filepath.Join(path, GetPlanTargetsFilename(ctx.Workspace, ctx.ProjectName))
+	planTargets, err := readPlanTargets(targetsPath)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read plan targets")
+	}
+	if len(ctx.Targets) > 0 && !slices.Equal(sortedCopy(ctx.Targets), sortedCopy(planTargets)) {
+		return "", fmt.Errorf("cannot apply with targets [%s] because the plan was created with targets [%s]. Instead, run plan with the targets you want to apply", strings.Join(ctx.Targets, ", "), strings.Join(planTargets, ", "))
+	}
+	if len(planTargets) > 0 {
+		ctx.Log.Info("applying targeted plan, only [%s] will be applied", strings.Join(planTargets, ", "))
+	}
+
 	ctx.Log.Info("starting apply")
 	var out string
 	tfDistribution := a.DefaultTFDistribution
@@ -52,7 +68,8 @@ func (a *ApplyStepRunner) Run(ctx command.ProjectContext, extraArgs []string, pa
 
 	// TODO: Leverage PlanTypeStepRunnerDelegate here
 	if IsRemotePlan(contents) {
-		args := append(append([]string{"apply", "-input=false", "-no-color"}, extraArgs...), ctx.EscapedCommentArgs...)
+		// Remote applies re-run the plan so they need the plan's targets.
+		args := append(append(append([]string{"apply", "-input=false", "-no-color"}, extraArgs...), ctx.EscapedCommentArgs...), targetArgs(planTargets)...)
 		out, err = a.runRemoteApply(ctx, args, path, planPath, tfDistribution, tfVersion, envs)
 		if err == nil {
 			out = a.cleanRemoteApplyOutput(out)
@@ -70,6 +87,9 @@ func (a *ApplyStepRunner) Run(ctx command.ProjectContext, extraArgs []string, pa
 		if removeErr := utils.RemoveIgnoreNonExistent(planPath); removeErr != nil {
 			ctx.Log.Warn("failed to delete planfile after successful apply: %s", removeErr)
 		}
+		if removeErr := utils.RemoveIgnoreNonExistent(targetsPath); removeErr != nil {
+			ctx.Log.Warn("failed to delete plan targets after successful apply: %s", removeErr)
+		}
 	}
 	return out, err
 }
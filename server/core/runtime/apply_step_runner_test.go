@@ -489,3 +489,60 @@ var noConfirmationOut = `
 
 Error: Apply discarded.
 `
+
+func TestApplyStepRunner_TargetedPlan(t *testing.T) {
+	cases := []struct {
+		description string
+		targets     []string
+		expErr      string
+	}{
+		{
+			description: "no targets on apply",
+		},
+		{
+			description: "same targets in a different order",
+			targets:     []string{"null_resource.b", "null_resource.a"},
+		},
+		{
+			description: "different targets",
+			targets:     []string{"null_resource.a"},
+			expErr:      "cannot apply with targets [null_resource.a] because the plan was created with targets [null_resource.a, null_resource.b]. Instead, run plan with the targets you want to apply",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			planPath := filepath.Join(tmpDir, "workspace.tfplan")
+			targetsPath := filepath.Join(tmpDir, "workspace.tfplan.targets")
+			Ok(t, os.WriteFile(planPath, nil, 0600))
+			Ok(t, os.WriteFile(targetsPath, []byte(`["null_resource.a","null_resource.b"]`), 0600))
+			ctx := command.ProjectContext{
+				Log:        logging.NewNoopLogger(t),
+				Workspace:  "workspace",
+				RepoRelDir: ".",
+				Targets:    c.targets,
+			}
+
+			RegisterMockTestingT(t)
+			terraform := tfclientmocks.NewMockClient()
+			tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+			o := runtime.ApplyStepRunner{
+				TerraformExecutor:     terraform,
+				DefaultTFDistribution: tfDistribution,
+			}
+			When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+				ThenReturn("output", nil)
+
+			output, err := o.Run(ctx, nil, tmpDir, map[string]string(nil))
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				terraform.VerifyWasCalled(Never()).RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())
+				return
+			}
+			Ok(t, err)
+			Equals(t, "output", output)
+			_, err = os.Stat(targetsPath)
+			Assert(t, os.IsNotExist(err), "plan targets should be deleted")
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package runtime
+
+import (
+	"path/filepath"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+type forceUnlockStateStepRunner struct {
+	terraformExecutor     TerraformExec
+	defaultTFDistribution terraform.Distribution
+	defaultTFVersion      *version.Version
+}
+
+func NewForceUnlockStateStepRunner(terraformExecutor TerraformExec, defaultTfDistribution terraform.Distribution, defaultTfVersion *version.Version) Runner {
+	runner := &forceUnlockStateStepRunner{
+		terraformExecutor:     terraformExecutor,
+		defaultTFDistribution: defaultTfDistribution,
+		defaultTFVersion:      defaultTfVersion,
+	}
+	return NewWorkspaceStepRunnerDelegate(terraformExecutor, defaultTfDistribution, defaultTfVersion, runner)
+}
+
+func (p *forceUnlockStateStepRunner) Run(ctx command.ProjectContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	tfDistribution := p.defaultTFDistribution
+	tfVersion := p.defaultTFVersion
+	if ctx.TerraformDistribution != nil {
+		tfDistribution = terraform.NewDistribution(*ctx.TerraformDistribution)
+	}
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+
+	// -force skips the interactive confirmation, the lock ID is the
+	// only comment argument.
+	forceUnlockCmd := []string{"force-unlock", "-force"}
+	forceUnlockCmd = append(forceUnlockCmd, extraArgs...)
+	forceUnlockCmd = append(forceUnlockCmd, ctx.EscapedCommentArgs...)
+	return p.terraformExecutor.RunCommandWithVersion(ctx, filepath.Clean(path), forceUnlockCmd, envs, tfDistribution, tfVersion, ctx.Workspace)
+}
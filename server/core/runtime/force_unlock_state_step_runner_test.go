@@ -0,0 +1,40 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestForceUnlockStateStepRunner_Run_Success(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	tmpDir := t.TempDir()
+
+	context := command.ProjectContext{
+		Log:                logger,
+		EscapedCommentArgs: []string{"4c9f6d21-5b0e-2b5a-8f0a-3a4b7c1e9d10"},
+		Workspace:          "default",
+	}
+
+	RegisterMockTestingT(t)
+	terraform := tfclientmocks.NewMockClient()
+	tfVersion, _ := version.NewVersion("1.5.7")
+	mockDownloader := mocks.NewMockDownloader()
+	tfDistribution := tf.NewDistributionTerraformWithDownloader(mockDownloader)
+	s := NewForceUnlockStateStepRunner(terraform, tfDistribution, tfVersion)
+
+	When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+		ThenReturn("Terraform state has been successfully unlocked!", nil)
+	output, err := s.Run(context, []string{}, tmpDir, map[string]string(nil))
+	Ok(t, err)
+	Equals(t, "Terraform state has been successfully unlocked!", output)
+	commands := []string{"force-unlock", "-force", "4c9f6d21-5b0e-2b5a-8f0a-3a4b7c1e9d10"}
+	terraform.VerifyWasCalledOnce().RunCommandWithVersion(context, tmpDir, commands, map[string]string(nil), tfDistribution, tfVersion, "default")
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"bufio"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"sync"
@@ -35,6 +36,8 @@ type ShellCommandRunner struct {
 	streamOutput  bool
 	cmd           *exec.Cmd
 	shell         *valid.CommandShell
+	// masked are replaced with <redacted> in the output.
+	masked []string
 }
 
 func NewShellCommandRunner(
@@ -68,6 +71,24 @@ func NewShellCommandRunner(
 	}
 }
 
+// MaskValues replaces values with <redacted> in the command's output, ex.
+// secrets passed in its environment. Empty values are ignored.
+func (s *ShellCommandRunner) MaskValues(values ...string) {
+	for _, value := range values {
+		if value != "" {
+			s.masked = append(s.masked, value)
+		}
+	}
+}
+
+// mask replaces the masked values in message.
+func (s *ShellCommandRunner) mask(message string) string {
+	for _, value := range s.masked {
+		message = strings.ReplaceAll(message, value, "<redacted>")
+	}
+	return message
+}
+
 func (s *ShellCommandRunner) Run(ctx command.ProjectContext) (string, error) {
 	_, outCh := s.RunCommandAsync(ctx)
 
@@ -119,6 +140,17 @@ func (s *ShellCommandRunner) RunCommandAsync(ctx command.ProjectContext) (chan<-
 			outCh <- Line{Err: err}
 			return
 		}
+		if canceler, ok := s.outputHandler.(jobs.JobCanceler); ok && s.streamOutput && ctx.JobID != "" {
+			// Terraform stops gracefully when interrupted, releasing the
+			// state lock.
+			deregister := canceler.RegisterCancel(ctx.JobID, func() {
+				ctx.Log.Info("canceling '%s %q' in '%s'", s.shell.String(), s.command, s.workingDir)
+				if err := s.cmd.Process.Signal(os.Interrupt); err != nil {
+					ctx.Log.Warn("unable to interrupt '%s %q': %s", s.shell.String(), s.command, err)
+				}
+			})
+			defer deregister()
+		}
 
 		// If we get anything on inCh, write it to stdin.
 		// This function will exit when inCh is closed which we do in our defer.
@@ -142,7 +174,7 @@ func (s *ShellCommandRunner) RunCommandAsync(ctx command.ProjectContext) (chan<-
 			scanner.Buffer(buf, BufioScannerBufferSize)
 
 			for scanner.Scan() {
-				message := scanner.Text()
+				message := s.mask(scanner.Text())
 				outCh <- Line{Line: message}
 				if s.streamOutput {
 					s.outputHandler.Send(ctx, message, false)
@@ -153,7 +185,7 @@ func (s *ShellCommandRunner) RunCommandAsync(ctx command.ProjectContext) (chan<-
 		go func() {
 			scanner := bufio.NewScanner(stderr)
 			for scanner.Scan() {
-				message := scanner.Text()
+				message := s.mask(scanner.Text())
 				outCh <- Line{Line: message}
 				if s.streamOutput {
 					s.outputHandler.Send(ctx, message, false)
@@ -78,3 +78,25 @@ func TestShellCommandRunner_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestShellCommandRunner_MaskValues(t *testing.T) {
+	RegisterMockTestingT(t)
+	log := logmocks.NewMockSimpleLogging()
+	When(log.With(Any[string](), Any[interface{}]())).ThenReturn(log)
+	ctx := command.ProjectContext{
+		Log:        log,
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	projectCmdOutputHandler := mocks.NewMockProjectCommandOutputHandler()
+	cwd, err := os.Getwd()
+	Ok(t, err)
+
+	runner := models.NewShellCommandRunner(nil, "echo token=$TOKEN; >&2 echo $TOKEN", []string{"TOKEN=s3cr3t"}, cwd, true, projectCmdOutputHandler)
+	runner.MaskValues("s3cr3t", "")
+	output, err := runner.Run(ctx)
+	Ok(t, err)
+	Assert(t, !strings.Contains(output, "s3cr3t"), "exp token to be masked in %q", output)
+	projectCmdOutputHandler.VerifyWasCalledOnce().Send(ctx, "token=<redacted>", false)
+	projectCmdOutputHandler.VerifyWasCalledOnce().Send(ctx, "<redacted>", false)
+}
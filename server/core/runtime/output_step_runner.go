@@ -0,0 +1,59 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+func NewOutputStepRunner(executor TerraformExec, defaultTfDistribution terraform.Distribution, defaultTFVersion *version.Version) Runner {
+	return &outputStepRunner{
+		terraformExecutor:     executor,
+		defaultTfDistribution: defaultTfDistribution,
+		defaultTFVersion:      defaultTFVersion,
+	}
+}
+
+// outputStepRunner runs terraform output after an apply and outputs it to a
+// json file
+type outputStepRunner struct {
+	terraformExecutor     TerraformExec
+	defaultTfDistribution terraform.Distribution
+	defaultTFVersion      *version.Version
+}
+
+func (p *outputStepRunner) Run(ctx command.ProjectContext, _ []string, path string, envs map[string]string) (string, error) {
+	tfDistribution := p.defaultTfDistribution
+	tfVersion := p.defaultTFVersion
+	if ctx.TerraformDistribution != nil {
+		tfDistribution = terraform.NewDistribution(*ctx.TerraformDistribution)
+	}
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+
+	outputsResultFile := filepath.Join(path, ctx.GetOutputsResultFileName())
+
+	output, err := p.terraformExecutor.RunCommandWithVersion(
+		ctx,
+		path,
+		[]string{"output", "-json"},
+		envs,
+		tfDistribution,
+		tfVersion,
+		ctx.Workspace,
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "running terraform output")
+	}
+
+	if err := os.WriteFile(outputsResultFile, []byte(output), 0600); err != nil {
+		return "", errors.Wrap(err, "writing terraform output result")
+	}
+
+	return output, nil
+}
@@ -0,0 +1,58 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestOutputStepRunner(t *testing.T) {
+	RegisterMockTestingT(t)
+	path := t.TempDir()
+	envs := map[string]string{"key": "val"}
+	tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+	tfVersion, _ := version.NewVersion("1.5.0")
+	ctx := command.ProjectContext{
+		Workspace:   "default",
+		ProjectName: "test",
+		Log:         logging.NewNoopLogger(t),
+	}
+	mockExecutor := tfclientmocks.NewMockClient()
+	subject := NewOutputStepRunner(mockExecutor, tfDistribution, tfVersion)
+
+	t.Run("success", func(t *testing.T) {
+		When(mockExecutor.RunCommandWithVersion(
+			ctx, path, []string{"output", "-json"}, envs, tfDistribution, tfVersion, ctx.Workspace,
+		)).ThenReturn(`{"bucket":{"value":"tf-state"}}`, nil)
+
+		out, err := subject.Run(ctx, nil, path, envs)
+		Ok(t, err)
+		Equals(t, `{"bucket":{"value":"tf-state"}}`, out)
+		contents, err := os.ReadFile(filepath.Join(path, "test-default-outputs.json"))
+		Ok(t, err)
+		Equals(t, out, string(contents))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		ctx := ctx
+		ctx.Workspace = "staging"
+		When(mockExecutor.RunCommandWithVersion(
+			ctx, path, []string{"output", "-json"}, envs, tfDistribution, tfVersion, ctx.Workspace,
+		)).ThenReturn("", errors.New("no state"))
+
+		_, err := subject.Run(ctx, nil, path, envs)
+		ErrEquals(t, "running terraform output: no state", err)
+		_, err = os.Stat(filepath.Join(path, "test-staging-outputs.json"))
+		Assert(t, os.IsNotExist(err), "expected no outputs file")
+	})
+}
@@ -0,0 +1,247 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// lockFileName is the name of the Terraform dependency lock file.
+const lockFileName = ".terraform.lock.hcl"
+
+var (
+	// registryModuleSourceRegex matches module registry sources, ex.
+	// terraform-aws-modules/vpc/aws or app.terraform.io/org/vpc/aws//modules/x.
+	registryModuleSourceRegex = regexp.MustCompile(`^([a-zA-Z0-9.\-]+(:[0-9]+)?/)?[a-zA-Z0-9_\-]+/[a-zA-Z0-9_\-]+/[a-zA-Z0-9_\-]+(//.*)?$`)
+	// refQueryRegex matches the ref query parameter of a VCS module source.
+	refQueryRegex = regexp.MustCompile(`[?&]ref=[^&]+`)
+	// vcsModuleSourcePrefixes are the prefixes of module sources that are
+	// fetched from a VCS and must be pinned with ?ref=.
+	vcsModuleSourcePrefixes = []string{"git::", "hg::", "git@", "github.com/", "bitbucket.org/"}
+)
+
+// PinCheckStepRunner runs the built-in pin_check step. It checks that the
+// project's module sources are pinned to a version or ref, that its providers
+// are locked in .terraform.lock.hcl, and that they're on the approved list.
+// It must run before init since init creates and updates the lock file.
+type PinCheckStepRunner struct {
+	// GlobalCfgStore holds the server-side repo config whose pinning policy
+	// is checked.
+	GlobalCfgStore *valid.GlobalCfgStore
+}
+
+func NewPinCheckStepRunner(globalCfgStore *valid.GlobalCfgStore) *PinCheckStepRunner {
+	return &PinCheckStepRunner{
+		GlobalCfgStore: globalCfgStore,
+	}
+}
+
+// pinChecker checks Terraform configs against a pinning policy.
+type pinChecker struct {
+	Policy valid.PinningPolicy
+}
+
+func (p *PinCheckStepRunner) Run(ctx command.ProjectContext, _ []string, path string, _ map[string]string) (string, error) {
+	checker := &pinChecker{Policy: valid.DefaultPinningPolicy}
+	if policy := p.GlobalCfgStore.Load().PinningPolicy; policy != nil {
+		checker.Policy = *policy
+	}
+	violations, err := checker.check(path)
+	if err != nil {
+		return "", err
+	}
+	if len(violations) == 0 {
+		return "", nil
+	}
+
+	report := fmt.Sprintf("pinning policy violations:\n* %s", strings.Join(violations, "\n* "))
+	if checker.Policy.Mode == valid.PinningPolicyWarnMode {
+		ctx.Log.Warn("found %d pinning policy violation(s)", len(violations))
+		return report, nil
+	}
+	return "", errors.New(report)
+}
+
+// check returns the pinning policy violations of the Terraform config in path.
+func (p *pinChecker) check(path string) ([]string, error) {
+	var violations []string
+	providers := make(map[string]bool)
+	if err := p.checkModule(path, ".", make(map[string]bool), providers, &violations); err != nil {
+		return nil, err
+	}
+
+	var sources []string
+	for source := range providers {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+
+	locks, err := readLockFile(filepath.Join(path, lockFileName))
+	if os.IsNotExist(err) {
+		if p.Policy.RequireLockFile && len(sources) > 0 {
+			violations = append(violations, fmt.Sprintf("%s is missing, commit the lock file created by terraform init", lockFileName))
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, source := range sources {
+		lock, locked := locks[source]
+		if p.Policy.RequireLockFile && locks != nil {
+			if !locked {
+				violations = append(violations, fmt.Sprintf("provider %q is not locked in %s", source, lockFileName))
+			} else if !lock.coversAllPlatforms() {
+				violations = append(violations, fmt.Sprintf("the lock for provider %q only has h1: checksums, which may not cover the platform Atlantis runs on, run terraform providers lock with a -platform for each platform", source))
+			}
+		}
+		if len(p.Policy.AllowedProviders) > 0 {
+			if v := p.checkAllowedProvider(source, lock, locked); v != "" {
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// checkModule records the providers required by the module in dir and adds
+// violations for unpinned module calls. Local modules are checked
+// recursively. relDir is dir relative to the project and is used in messages.
+func (p *pinChecker) checkModule(dir string, relDir string, visited map[string]bool, providers map[string]bool, violations *[]string) error {
+	if visited[dir] {
+		return nil
+	}
+	visited[dir] = true
+
+	module, diags := tfconfig.LoadModule(dir)
+	if diags.HasErrors() {
+		return errors.Wrapf(diags.Err(), "loading terraform config in %q", relDir)
+	}
+
+	for name, req := range module.RequiredProviders {
+		source := req.Source
+		if source == "" {
+			// Providers without a source default to the hashicorp namespace.
+			source = "hashicorp/" + name
+		}
+		providers[valid.NormalizeProviderSource(source)] = true
+	}
+
+	var names []string
+	for name := range module.ModuleCalls {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		call := module.ModuleCalls[name]
+		if isLocalModuleSource(call.Source) {
+			childRelDir := filepath.Join(relDir, call.Source)
+			if err := p.checkModule(filepath.Join(dir, call.Source), childRelDir, visited, providers, violations); err != nil {
+				return err
+			}
+			continue
+		}
+		if p.Policy.RequirePinnedModules && !isPinnedModuleSource(call.Source, call.Version) {
+			*violations = append(*violations, fmt.Sprintf("module %q in %q uses unpinned source %q, set a version or ?ref=", name, relDir, call.Source))
+		}
+	}
+	return nil
+}
+
+// checkAllowedProvider returns a violation if the provider isn't on the
+// approved list or its locked version doesn't satisfy the approved versions.
+func (p *pinChecker) checkAllowedProvider(source string, lock lockedProvider, locked bool) string {
+	i := slices.IndexFunc(p.Policy.AllowedProviders, func(a valid.AllowedProvider) bool {
+		return a.Source == source
+	})
+	if i < 0 {
+		return fmt.Sprintf("provider %q is not on the approved providers list", source)
+	}
+	constraints := p.Policy.AllowedProviders[i].VersionConstraints
+	if constraints == nil || !locked {
+		return ""
+	}
+	v, err := version.NewVersion(lock.Version)
+	if err != nil {
+		return fmt.Sprintf("provider %q has an invalid locked version %q", source, lock.Version)
+	}
+	if !constraints.Check(v) {
+		return fmt.Sprintf("provider %q version %s is not an approved version (%s)", source, lock.Version, constraints)
+	}
+	return ""
+}
+
+func isLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// isPinnedModuleSource returns true if a remote module source is pinned. VCS
+// sources must set a ref and registry sources a version. Other sources, ex.
+// archives over HTTP, can't be pinned so they're allowed.
+func isPinnedModuleSource(source string, moduleVersion string) bool {
+	for _, prefix := range vcsModuleSourcePrefixes {
+		if strings.HasPrefix(source, prefix) {
+			return refQueryRegex.MatchString(source)
+		}
+	}
+	if registryModuleSourceRegex.MatchString(source) {
+		return moduleVersion != ""
+	}
+	return true
+}
+
+// lockFile is the schema of .terraform.lock.hcl.
+type lockFile struct {
+	Providers []lockedProvider `hcl:"provider,block"`
+	Remain    hcl.Body         `hcl:",remain"`
+}
+
+type lockedProvider struct {
+	Source      string   `hcl:"source,label"`
+	Version     string   `hcl:"version"`
+	Constraints *string  `hcl:"constraints"`
+	Hashes      []string `hcl:"hashes,optional"`
+}
+
+// coversAllPlatforms returns true if the lock has zh: checksums, which
+// Terraform records for every platform the registry publishes. Locks with
+// only h1: checksums, ex. from a plugin cache or mirror, only cover the
+// platforms they were created on. The lock file doesn't record which
+// platforms those are so they can't be checked against the platform Atlantis
+// runs on.
+func (l lockedProvider) coversAllPlatforms() bool {
+	return slices.ContainsFunc(l.Hashes, func(h string) bool {
+		return strings.HasPrefix(h, "zh:")
+	})
+}
+
+// readLockFile returns the locked providers keyed by source address.
+func readLockFile(path string) (map[string]lockedProvider, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	file, diags := hclparse.NewParser().ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, errors.Wrapf(diags, "parsing %s", lockFileName)
+	}
+	var lf lockFile
+	if diags := gohcl.DecodeBody(file.Body, nil, &lf); diags.HasErrors() {
+		return nil, errors.Wrapf(diags, "parsing %s", lockFileName)
+	}
+	locks := make(map[string]lockedProvider)
+	for _, l := range lf.Providers {
+		locks[valid.NormalizeProviderSource(l.Source)] = l
+	}
+	return locks, nil
+}
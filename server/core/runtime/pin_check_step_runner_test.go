@@ -0,0 +1,198 @@
+package runtime_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const pinCheckMainTF = `
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+
+module "registry_pinned" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+
+module "git_pinned" {
+  source = "git::https://example.com/vpc.git?ref=v1.2.0"
+}
+
+module "local" {
+  source = "./modules/local"
+}
+`
+
+const pinCheckLockFile = `
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:abc=",
+    "zh:0123",
+  ]
+}
+`
+
+func TestPinCheckStepRunner_Run(t *testing.T) {
+	cases := []struct {
+		description string
+		files       map[string]string
+		policy      *valid.PinningPolicy
+		expOut      string
+		expErr      string
+	}{
+		{
+			description: "everything pinned",
+			files: map[string]string{
+				"main.tf":               pinCheckMainTF,
+				".terraform.lock.hcl":   pinCheckLockFile,
+				"modules/local/main.tf": `resource "aws_s3_bucket" "a" {}`,
+			},
+		},
+		{
+			description: "unpinned modules in root and local modules",
+			files: map[string]string{
+				"main.tf": pinCheckMainTF + `
+module "registry_unpinned" {
+  source = "terraform-aws-modules/s3-bucket/aws"
+}
+`,
+				".terraform.lock.hcl": pinCheckLockFile,
+				"modules/local/main.tf": `
+module "github_unpinned" {
+  source = "github.com/org/module"
+}
+`,
+			},
+			expErr: "pinning policy violations:\n" +
+				"* module \"github_unpinned\" in \"modules/local\" uses unpinned source \"github.com/org/module\", set a version or ?ref=\n" +
+				"* module \"registry_unpinned\" in \".\" uses unpinned source \"terraform-aws-modules/s3-bucket/aws\", set a version or ?ref=",
+		},
+		{
+			description: "missing lock file",
+			files: map[string]string{
+				"main.tf":               pinCheckMainTF,
+				"modules/local/main.tf": `resource "aws_s3_bucket" "a" {}`,
+			},
+			expErr: "pinning policy violations:\n* .terraform.lock.hcl is missing, commit the lock file created by terraform init",
+		},
+		{
+			description: "provider missing from the lock file",
+			files: map[string]string{
+				"main.tf":               pinCheckMainTF,
+				".terraform.lock.hcl":   pinCheckLockFile,
+				"modules/local/main.tf": `resource "random_id" "a" {}`,
+			},
+			expErr: "pinning policy violations:\n* provider \"registry.terraform.io/hashicorp/random\" is not locked in .terraform.lock.hcl",
+		},
+		{
+			description: "lock file without zh hashes",
+			files: map[string]string{
+				"main.tf": pinCheckMainTF,
+				".terraform.lock.hcl": `
+provider "registry.terraform.io/hashicorp/aws" {
+  version = "5.31.0"
+  hashes  = ["h1:abc="]
+}
+`,
+				"modules/local/main.tf": `resource "aws_s3_bucket" "a" {}`,
+			},
+			expErr: "pinning policy violations:\n* the lock for provider \"registry.terraform.io/hashicorp/aws\" only has h1: checksums, which may not cover the platform Atlantis runs on, run terraform providers lock with a -platform for each platform",
+		},
+		{
+			description: "provider not approved",
+			files: map[string]string{
+				"main.tf":               pinCheckMainTF,
+				".terraform.lock.hcl":   pinCheckLockFile,
+				"modules/local/main.tf": `resource "aws_s3_bucket" "a" {}`,
+			},
+			policy: &valid.PinningPolicy{
+				Mode:             valid.PinningPolicyFailMode,
+				AllowedProviders: []valid.AllowedProvider{{Source: "registry.terraform.io/hashicorp/google"}},
+			},
+			expErr: "pinning policy violations:\n* provider \"registry.terraform.io/hashicorp/aws\" is not on the approved providers list",
+		},
+		{
+			description: "provider version not approved",
+			files: map[string]string{
+				"main.tf":               pinCheckMainTF,
+				".terraform.lock.hcl":   pinCheckLockFile,
+				"modules/local/main.tf": `resource "aws_s3_bucket" "a" {}`,
+			},
+			policy: &valid.PinningPolicy{
+				Mode: valid.PinningPolicyFailMode,
+				AllowedProviders: []valid.AllowedProvider{{
+					Source:             "registry.terraform.io/hashicorp/aws",
+					VersionConstraints: runtime.MustConstraint("< 5.30"),
+				}},
+			},
+			expErr: "pinning policy violations:\n* provider \"registry.terraform.io/hashicorp/aws\" version 5.31.0 is not an approved version (< 5.30)",
+		},
+		{
+			description: "warn mode",
+			files: map[string]string{
+				"main.tf":               pinCheckMainTF,
+				"modules/local/main.tf": `resource "aws_s3_bucket" "a" {}`,
+			},
+			policy: &valid.PinningPolicy{
+				Mode:            valid.PinningPolicyWarnMode,
+				RequireLockFile: true,
+			},
+			expOut: "pinning policy violations:\n* .terraform.lock.hcl is missing, commit the lock file created by terraform init",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for name, contents := range c.files {
+				path := filepath.Join(tmpDir, name)
+				Ok(t, os.MkdirAll(filepath.Dir(path), 0700))
+				Ok(t, os.WriteFile(path, []byte(contents), 0600))
+			}
+
+			runner := runtime.NewPinCheckStepRunner(valid.NewGlobalCfgStore(valid.GlobalCfg{PinningPolicy: c.policy}))
+			out, err := runner.Run(command.ProjectContext{Log: logging.NewNoopLogger(t)}, nil, tmpDir, nil)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expOut, out)
+		})
+	}
+}
+
+func TestPinCheckStepRunner_ReloadedPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(`
+module "unpinned" {
+  source = "git::https://example.com/vpc.git"
+}
+`), 0600))
+
+	store := valid.NewGlobalCfgStore(valid.GlobalCfg{})
+	runner := runtime.NewPinCheckStepRunner(store)
+	ctx := command.ProjectContext{Log: logging.NewNoopLogger(t)}
+	_, err := runner.Run(ctx, nil, tmpDir, nil)
+	ErrEquals(t, "pinning policy violations:\n* module \"unpinned\" in \".\" uses unpinned source \"git::https://example.com/vpc.git\", set a version or ?ref=", err)
+
+	store.Store(valid.GlobalCfg{PinningPolicy: &valid.PinningPolicy{Mode: valid.PinningPolicyWarnMode}})
+	out, err := runner.Run(ctx, nil, tmpDir, nil)
+	Ok(t, err)
+	Equals(t, "", out)
+}
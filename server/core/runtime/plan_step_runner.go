@@ -12,6 +12,7 @@ import (
 	"github.com/runatlantis/atlantis/server/core/terraform"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/utils"
 )
 
 const (
@@ -65,9 +66,33 @@ func (p *planStepRunner) Run(ctx command.ProjectContext, extraArgs []string, pat
 	if err != nil {
 		return output, err
 	}
+	if err := p.recordTargets(ctx, path, tfDistribution, tfVersion, planFile, envs); err != nil {
+		return output, err
+	}
 	return p.fmtPlanOutput(output, tfVersion), nil
 }
 
+// recordTargets validates a targeted plan against the planfile and records
+// its targets so apply can check it's applying the same targets. If the
+// targets don't match any resources the planfile is deleted so it can't be
+// applied.
+func (p *planStepRunner) recordTargets(ctx command.ProjectContext, path string, tfDistribution terraform.Distribution, tfVersion *version.Version, planFile string, envs map[string]string) error {
+	targetsFile := filepath.Join(path, GetPlanTargetsFilename(ctx.Workspace, ctx.ProjectName))
+	if len(ctx.Targets) > 0 {
+		showOutput, err := p.TerraformExecutor.RunCommandWithVersion(ctx, filepath.Clean(path), []string{"show", "-json", fmt.Sprintf("%q", planFile)}, envs, tfDistribution, tfVersion, ctx.Workspace)
+		if err != nil {
+			return errors.Wrap(err, "running terraform show to validate targets")
+		}
+		if err := validatePlanTargets(showOutput, ctx.Targets); err != nil {
+			if removeErr := utils.RemoveIgnoreNonExistent(planFile); removeErr != nil {
+				ctx.Log.Warn("failed to delete planfile after target validation failed: %s", removeErr)
+			}
+			return err
+		}
+	}
+	return errors.Wrap(writePlanTargets(targetsFile, ctx.Targets), "recording plan targets")
+}
+
 // isRemoteOpsErr returns true if there was an error caused due to this
 // project using TFE remote operations.
 func (p *planStepRunner) isRemoteOpsErr(output string, err error) bool {
@@ -84,6 +109,7 @@ func (p *planStepRunner) remotePlan(ctx command.ProjectContext, extraArgs []stri
 		{"plan", "-input=false", "-refresh", "-no-color"},
 		extraArgs,
 		ctx.EscapedCommentArgs,
+		targetArgs(ctx.Targets),
 	}
 	args := p.flatten(argList)
 	output, err := p.runRemotePlan(ctx, args, path, tfDistribution, tfVersion, envs)
@@ -107,6 +133,12 @@ func (p *planStepRunner) remotePlan(ctx command.ProjectContext, extraArgs []stri
 	if err != nil {
 		return output, errors.Wrap(err, "unable to create planfile for remote ops")
 	}
+	// The fake planfile can't be validated with terraform show so remote
+	// targets are only recorded.
+	targetsFile := filepath.Join(path, GetPlanTargetsFilename(ctx.Workspace, ctx.ProjectName))
+	if err := writePlanTargets(targetsFile, ctx.Targets); err != nil {
+		return output, errors.Wrap(err, "recording plan targets")
+	}
 
 	return p.fmtPlanOutput(output, tfVersion), nil
 }
@@ -131,6 +163,7 @@ func (p *planStepRunner) buildPlanCmd(ctx command.ProjectContext, extraArgs []st
 		tfVars,
 		extraArgs,
 		ctx.EscapedCommentArgs,
+		targetArgs(ctx.Targets),
 		envFileArgs,
 	}
 
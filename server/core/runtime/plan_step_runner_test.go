@@ -697,3 +697,61 @@ Terraform will perform the following actions:
 
 
 Plan: 0 to add, 0 to change, 1 to destroy.`
+
+func TestRun_TargetedPlan(t *testing.T) {
+	cases := []struct {
+		description string
+		showOutput  string
+		expErr      string
+	}{
+		{
+			description: "target matches a resource",
+			showOutput:  `{"resource_changes":[{"address":"null_resource.a"},{"address":"null_resource.b"}]}`,
+		},
+		{
+			description: "target matches no resources",
+			showOutput:  `{"resource_changes":[{"address":"null_resource.b"}]}`,
+			expErr:      "target(s) null_resource.a did not match any resource in the plan",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			terraform := tfclientmocks.NewMockClient()
+			commitStatusUpdater := runtimemocks.NewMockStatusUpdater()
+			asyncTfExec := runtimemocks.NewMockAsyncTFExec()
+			mockDownloader := mocks.NewMockDownloader()
+			tfDistribution := tf.NewDistributionTerraformWithDownloader(mockDownloader)
+			tfVersion, _ := version.NewVersion("1.5.0")
+			tmpDir := t.TempDir()
+			planPath := filepath.Join(tmpDir, "default.tfplan")
+			Ok(t, os.WriteFile(planPath, nil, 0600))
+
+			s := runtime.NewPlanStepRunner(terraform, tfDistribution, tfVersion, commitStatusUpdater, asyncTfExec)
+			ctx := command.ProjectContext{
+				Log:        logging.NewNoopLogger(t),
+				Workspace:  "default",
+				RepoRelDir: ".",
+				Targets:    []string{"null_resource.a"},
+			}
+			expPlanArgs := []string{"plan", "-input=false", "-refresh", "-out", fmt.Sprintf("%q", planPath), `-target=\n\u\l\l\_\r\e\s\o\u\r\c\e\.\a`}
+			expShowArgs := []string{"show", "-json", fmt.Sprintf("%q", planPath)}
+			When(terraform.RunCommandWithVersion(ctx, tmpDir, []string{"workspace", "show"}, map[string]string(nil), tfDistribution, tfVersion, "default")).ThenReturn("default\n", nil)
+			When(terraform.RunCommandWithVersion(ctx, tmpDir, expPlanArgs, map[string]string(nil), tfDistribution, tfVersion, "default")).ThenReturn("output", nil)
+			When(terraform.RunCommandWithVersion(ctx, tmpDir, expShowArgs, map[string]string(nil), tfDistribution, tfVersion, "default")).ThenReturn(c.showOutput, nil)
+
+			_, err := s.Run(ctx, nil, tmpDir, map[string]string(nil))
+			targetsPath := filepath.Join(tmpDir, "default.tfplan.targets")
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				_, err = os.Stat(planPath)
+				Assert(t, os.IsNotExist(err), "planfile should be deleted")
+				return
+			}
+			Ok(t, err)
+			contents, err := os.ReadFile(targetsPath)
+			Ok(t, err)
+			Equals(t, `["null_resource.a"]`, string(contents))
+		})
+	}
+}
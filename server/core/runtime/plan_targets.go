@@ -0,0 +1,110 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/utils"
+)
+
+// planTargetsSuffix is appended to the planfile name to get the name of the
+// file that records which targets the plan was created with.
+const planTargetsSuffix = ".targets"
+
+// GetPlanTargetsFilename returns the filename (not the path) of the file that
+// records the targets of a targeted plan given a workspace and project name.
+func GetPlanTargetsFilename(workspace string, projName string) string {
+	return GetPlanFilename(workspace, projName) + planTargetsSuffix
+}
+
+// targetArgs returns a -target flag for each target. The targets are escaped
+// because the command is run through a shell and addresses can contain
+// quotes and brackets, ex. module.app["blue"].
+func targetArgs(targets []string) []string {
+	var args []string
+	for _, target := range targets {
+		var escaped string
+		for i := range target {
+			escaped += "\\" + string(target[i])
+		}
+		args = append(args, "-target="+escaped)
+	}
+	return args
+}
+
+// writePlanTargets records targets next to the planfile so apply can verify
+// it's applying the plan the user asked for. If targets is empty, any file
+// left over from a previous targeted plan is removed.
+func writePlanTargets(targetsPath string, targets []string) error {
+	if len(targets) == 0 {
+		return utils.RemoveIgnoreNonExistent(targetsPath)
+	}
+	contents, err := json.Marshal(targets)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(targetsPath, contents, 0600)
+}
+
+// readPlanTargets returns the targets the plan was created with or nil if it
+// wasn't a targeted plan.
+func readPlanTargets(targetsPath string) ([]string, error) {
+	contents, err := os.ReadFile(targetsPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var targets []string
+	if err := json.Unmarshal(contents, &targets); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", targetsPath)
+	}
+	return targets, nil
+}
+
+// sortedCopy returns a sorted copy of targets so they can be compared
+// regardless of the order they were passed in.
+func sortedCopy(targets []string) []string {
+	sorted := slices.Clone(targets)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// planResourceChange is a resource change in the terraform show -json output.
+type planResourceChange struct {
+	Address string `json:"address"`
+}
+
+// validatePlanTargets returns an error if any of the targets don't match a
+// resource in showOutput, the output of terraform show -json on the plan.
+// Terraform only warns about targets that don't exist, which makes a typo
+// look like a plan with no changes.
+func validatePlanTargets(showOutput string, targets []string) error {
+	var plan struct {
+		ResourceChanges []planResourceChange `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(showOutput), &plan); err != nil {
+		return errors.Wrap(err, "parsing plan json")
+	}
+
+	var unmatched []string
+	for _, target := range targets {
+		matched := slices.ContainsFunc(plan.ResourceChanges, func(rc planResourceChange) bool {
+			return rc.Address == target ||
+				strings.HasPrefix(rc.Address, target+".") ||
+				strings.HasPrefix(rc.Address, target+"[")
+		})
+		if !matched {
+			unmatched = append(unmatched, target)
+		}
+	}
+	if len(unmatched) > 0 {
+		return fmt.Errorf("target(s) %s did not match any resource in the plan", strings.Join(unmatched, ", "))
+	}
+	return nil
+}
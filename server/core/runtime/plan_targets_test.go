@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestValidatePlanTargets(t *testing.T) {
+	showOutput := `{"resource_changes":[{"address":"aws_instance.web"},{"address":"module.app[\"blue\"].aws_instance.web[0]"}]}`
+	cases := []struct {
+		description string
+		targets     []string
+		expErr      string
+	}{
+		{
+			description: "exact address",
+			targets:     []string{"aws_instance.web"},
+		},
+		{
+			description: "module",
+			targets:     []string{"module.app"},
+		},
+		{
+			description: "module instance",
+			targets:     []string{`module.app["blue"]`},
+		},
+		{
+			description: "resource prefix isn't a match",
+			targets:     []string{"aws_instance.we"},
+			expErr:      "target(s) aws_instance.we did not match any resource in the plan",
+		},
+		{
+			description: "some unmatched",
+			targets:     []string{"aws_instance.web", "aws_s3_bucket.logs", "module.db"},
+			expErr:      "target(s) aws_s3_bucket.logs, module.db did not match any resource in the plan",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := validatePlanTargets(showOutput, c.targets)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+		})
+	}
+}
+
+func TestTargetArgs(t *testing.T) {
+	Equals(t, []string{`-target=\a\.\b`, `-target=\m\[\"\x\"\]`}, targetArgs([]string{"a.b", `m["x"]`}))
+	Equals(t, []string(nil), targetArgs(nil))
+}
+
+func TestWriteReadPlanTargets(t *testing.T) {
+	targetsPath := filepath.Join(t.TempDir(), "default.tfplan.targets")
+
+	targets, err := readPlanTargets(targetsPath)
+	Ok(t, err)
+	Equals(t, []string(nil), targets)
+
+	Ok(t, writePlanTargets(targetsPath, []string{"aws_instance.web", `module.app["blue"]`}))
+	targets, err = readPlanTargets(targetsPath)
+	Ok(t, err)
+	Equals(t, []string{"aws_instance.web", `module.app["blue"]`}, targets)
+
+	// An untargeted plan removes the targets of the previous plan.
+	Ok(t, writePlanTargets(targetsPath, nil))
+	targets, err = readPlanTargets(targetsPath)
+	Ok(t, err)
+	Equals(t, []string(nil), targets)
+}
@@ -50,7 +50,9 @@ type ConftestTestCommandArgs struct {
 	PolicyArgs []Arg
 	ExtraArgs  []string
 	InputFile  string
-	Command    string
+	// DataFiles are passed to conftest with --data so policies can use them.
+	DataFiles []string
+	Command   string
 }
 
 func (c ConftestTestCommandArgs) build() ([]string, error) {
@@ -68,6 +70,9 @@ func (c ConftestTestCommandArgs) build() ([]string, error) {
 
 	// add hardcoded options
 	commandArgs = append(commandArgs, c.InputFile, "--no-color")
+	for _, f := range c.DataFiles {
+		commandArgs = append(commandArgs, "--data", f)
+	}
 
 	// add extra args provided through server config
 	commandArgs = append(commandArgs, c.ExtraArgs...)
@@ -188,6 +193,13 @@ func (c *ConfTestExecutorWorkflow) Run(ctx command.ProjectContext, executablePat
 	ctx.Log.Debug("policy sets, %s ", ctx.PolicySets)
 
 	inputFile := filepath.Join(workdir, ctx.GetShowResultFileName())
+	// The providers schema is written by the providers_schema step, if the
+	// workflow runs it, and is available to policies as
+	// data.provider_schemas.
+	var dataFiles []string
+	if schemaFile := filepath.Join(workdir, ctx.GetProvidersSchemaFileName()); fileExists(schemaFile) {
+		dataFiles = append(dataFiles, schemaFile)
+	}
 	var policySetResults []models.PolicySetResult
 	var combinedErr error
 
@@ -204,6 +216,7 @@ func (c *ConfTestExecutorWorkflow) Run(ctx command.ProjectContext, executablePat
 			PolicyArgs: []Arg{NewPolicyArg(path)},
 			ExtraArgs:  extraArgs,
 			InputFile:  inputFile,
+			DataFiles:  dataFiles,
 			Command:    executablePath,
 		}
 
@@ -341,3 +354,8 @@ func getPlatform() string {
 		return ""
 	}
 }
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
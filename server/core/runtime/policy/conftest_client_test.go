@@ -3,6 +3,7 @@ package policy
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -319,4 +320,25 @@ func TestRun(t *testing.T) {
 		Assert(t, err != nil, "error is expected")
 
 	})
+
+	t.Run("success with providers schema", func(t *testing.T) {
+		workdir := t.TempDir()
+		schemaFile := filepath.Join(workdir, "testproj-default-providers-schema.json")
+		Ok(t, os.WriteFile(schemaFile, []byte(`{"provider_schemas":{}}`), 0600))
+
+		expectedArgsPolicy1 := []string{executablePath, "test", "-p", localPolicySetPath1, filepath.Join(workdir, "testproj-default.json"), "--no-color", "--data", schemaFile}
+		expectedArgsPolicy2 := []string{executablePath, "test", "-p", localPolicySetPath2, filepath.Join(workdir, "testproj-default.json"), "--no-color", "--data", schemaFile}
+
+		When(mockResolver.Resolve(policySet1)).ThenReturn(localPolicySetPath1, nil)
+		When(mockResolver.Resolve(policySet2)).ThenReturn(localPolicySetPath2, nil)
+
+		When(mockExec.CombinedOutput(expectedArgsPolicy1, envs, workdir)).ThenReturn("Success", nil)
+		When(mockExec.CombinedOutput(expectedArgsPolicy2, envs, workdir)).ThenReturn("Success", nil)
+
+		_, err := subject.Run(ctx, executablePath, envs, workdir, nil)
+		Ok(t, err)
+
+		mockExec.VerifyWasCalledOnce().CombinedOutput(expectedArgsPolicy1, envs, workdir)
+		mockExec.VerifyWasCalledOnce().CombinedOutput(expectedArgsPolicy2, envs, workdir)
+	})
 }
@@ -1,7 +1,10 @@
 package runtime
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,6 +14,11 @@ import (
 	"github.com/runatlantis/atlantis/server/jobs"
 )
 
+// WorkflowHookOutputVarsFile is the name of the file in the repo dir that pre
+// workflow hooks write output variables to, one KEY=value per line. The
+// variables are passed to the steps of the workflows as env vars.
+const WorkflowHookOutputVarsFile = "OUTPUT_VARS_FILE"
+
 //go:generate pegomock generate --package mocks -o mocks/mock_pre_workflows_hook_runner.go PreWorkflowHookRunner
 type PreWorkflowHookRunner interface {
 	Run(ctx models.WorkflowHookCommandContext, command string, shell string, shellArgs string, path string) (string, string, error)
@@ -44,6 +52,7 @@ func (wh DefaultPreWorkflowHookRunner) Run(ctx models.WorkflowHookCommandContext
 		"USER_NAME":          ctx.User.Username,
 		"OUTPUT_STATUS_FILE": outputFilePath,
 		"COMMAND_NAME":       ctx.CommandName,
+		"OUTPUT_VARS_FILE":   filepath.Join(path, WorkflowHookOutputVarsFile),
 	}
 
 	finalEnvVars := baseEnvVars
@@ -52,10 +61,7 @@ func (wh DefaultPreWorkflowHookRunner) Run(ctx models.WorkflowHookCommandContext
 	}
 
 	cmd.Env = finalEnvVars
-	out, err := cmd.CombinedOutput()
-
-	outString := strings.ReplaceAll(string(out), "\n", "\r\n")
-	wh.OutputHandler.SendWorkflowHook(ctx, outString, false)
+	out, err := wh.runStreaming(ctx, cmd)
 	wh.OutputHandler.SendWorkflowHook(ctx, "\n", true)
 
 	if err != nil {
@@ -80,3 +86,70 @@ func (wh DefaultPreWorkflowHookRunner) Run(ctx models.WorkflowHookCommandContext
 	ctx.Log.Info("Successfully ran '%s' in '%s'", shell+" "+shellArgs+" "+command, path)
 	return string(out), strings.Trim(string(customStatusOut), "\n"), nil
 }
+
+// runStreaming runs cmd and sends each line of its combined output to the job
+// log of the hook while it runs. It returns the output once cmd exits.
+func (wh DefaultPreWorkflowHookRunner) runStreaming(ctx models.WorkflowHookCommandContext, cmd *exec.Cmd) ([]byte, error) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close() // nolint: errcheck
+		waitErr <- err
+	}()
+
+	var out bytes.Buffer
+	reader := bufio.NewReader(pr)
+	for {
+		line, readErr := reader.ReadString('\n')
+		out.WriteString(line)
+		if line != "" {
+			wh.OutputHandler.SendWorkflowHook(ctx, strings.TrimSuffix(line, "\n"), false)
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	return out.Bytes(), <-waitErr
+}
+
+// ClearWorkflowHookOutputVars deletes the output variables written by the pre
+// workflow hooks of a previous command in repoDir.
+func ClearWorkflowHookOutputVars(repoDir string) error {
+	err := os.Remove(filepath.Join(repoDir, WorkflowHookOutputVarsFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// ReadWorkflowHookOutputVars returns the output variables written by pre
+// workflow hooks in repoDir. Empty lines and lines starting with # are
+// ignored. If a variable is set more than once, the last value wins.
+func ReadWorkflowHookOutputVars(repoDir string) (map[string]string, error) {
+	contents, err := os.ReadFile(filepath.Join(repoDir, WorkflowHookOutputVarsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string)
+	for i, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("invalid line %d of %s, expected KEY=value", i+1, WorkflowHookOutputVarsFile)
+		}
+		vars[key] = val
+	}
+	return vars, nil
+}
@@ -209,8 +209,45 @@ func TestPreWorkflowHookRunner_Run(t *testing.T) {
 			// temp dir.
 			Equals(t, c.ExpDescription, desc)
 			expOut := strings.Replace(c.ExpOut, "$DIR", tmpDir, -1)
-			projectCmdOutputHandler.VerifyWasCalledOnce().SendWorkflowHook(
-				Any[models.WorkflowHookCommandContext](), Eq(expOut), Eq(false))
+			// The output is sent to the job log line by line.
+			_, lines, _ := projectCmdOutputHandler.VerifyWasCalled(AtLeast(0)).SendWorkflowHook(
+				Any[models.WorkflowHookCommandContext](), Any[string](), Eq(false)).GetAllCapturedArguments()
+			Equals(t, strings.TrimSuffix(expOut, "\r\n"), strings.Join(lines, "\r\n"))
 		})
 	}
 }
+
+func TestPreWorkflowHookRunner_OutputVars(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmpDir := t.TempDir()
+	r := runtime.DefaultPreWorkflowHookRunner{
+		OutputHandler: jobmocks.NewMockProjectCommandOutputHandler(),
+	}
+	ctx := models.WorkflowHookCommandContext{
+		Log:         logging.NewNoopLogger(t),
+		CommandName: "plan",
+	}
+
+	vars, err := runtime.ReadWorkflowHookOutputVars(tmpDir)
+	Ok(t, err)
+	Equals(t, 0, len(vars))
+
+	_, _, err = r.Run(ctx, `printf '# backend\nBUCKET=tf-state\n\nREGION=us-east-1\n' >> $OUTPUT_VARS_FILE`, "sh", "-c", tmpDir)
+	Ok(t, err)
+	_, _, err = r.Run(ctx, `echo REGION=eu-west-1=a >> $OUTPUT_VARS_FILE`, "sh", "-c", tmpDir)
+	Ok(t, err)
+	vars, err = runtime.ReadWorkflowHookOutputVars(tmpDir)
+	Ok(t, err)
+	Equals(t, map[string]string{"BUCKET": "tf-state", "REGION": "eu-west-1=a"}, vars)
+
+	_, _, err = r.Run(ctx, `echo 'NOT A VAR' >> $OUTPUT_VARS_FILE`, "sh", "-c", tmpDir)
+	Ok(t, err)
+	_, err = runtime.ReadWorkflowHookOutputVars(tmpDir)
+	ErrEquals(t, "invalid line 6 of OUTPUT_VARS_FILE, expected KEY=value", err)
+
+	Ok(t, runtime.ClearWorkflowHookOutputVars(tmpDir))
+	Ok(t, runtime.ClearWorkflowHookOutputVars(tmpDir))
+	vars, err = runtime.ReadWorkflowHookOutputVars(tmpDir)
+	Ok(t, err)
+	Equals(t, 0, len(vars))
+}
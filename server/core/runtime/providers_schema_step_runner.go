@@ -0,0 +1,125 @@
+package runtime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// NewProvidersSchemaStepRunner returns a runner for the providers_schema
+// step. Schemas are cached in cacheDir, if set, so projects that use the
+// same provider versions only generate them once.
+func NewProvidersSchemaStepRunner(terraformExecutor TerraformExec, defaultTfDistribution terraform.Distribution, defaultTfVersion *version.Version, cacheDir string) Runner {
+	return &providersSchemaStepRunner{
+		terraformExecutor:     terraformExecutor,
+		defaultTFDistribution: defaultTfDistribution,
+		defaultTFVersion:      defaultTfVersion,
+		cacheDir:              cacheDir,
+	}
+}
+
+// providersSchemaStepRunner runs terraform providers schema -json and writes
+// its output next to the plan so that policy checks can use it. Generating
+// the schemas of large providers takes a while so they're cached by the
+// Terraform version and the contents of .terraform.lock.hcl. A change to the
+// lock file changes the cache key, so the schemas are generated again.
+// Projects without a lock file aren't cached since their provider versions
+// aren't known.
+type providersSchemaStepRunner struct {
+	terraformExecutor     TerraformExec
+	defaultTFDistribution terraform.Distribution
+	defaultTFVersion      *version.Version
+	cacheDir              string
+}
+
+func (p *providersSchemaStepRunner) Run(ctx command.ProjectContext, _ []string, path string, envs map[string]string) (string, error) {
+	tfDistribution := p.defaultTFDistribution
+	tfVersion := p.defaultTFVersion
+	if ctx.TerraformDistribution != nil {
+		tfDistribution = terraform.NewDistribution(*ctx.TerraformDistribution)
+	}
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+	schemaFile := filepath.Join(path, ctx.GetProvidersSchemaFileName())
+
+	cacheFile, err := p.cacheFile(path, tfDistribution, tfVersion)
+	if err != nil {
+		return "", err
+	}
+	if cacheFile != "" {
+		if schema, err := os.ReadFile(cacheFile); err == nil {
+			ctx.Log.Debug("using cached providers schema %s", cacheFile)
+			return "", p.writeSchema(schemaFile, schema)
+		}
+	}
+
+	out, err := p.terraformExecutor.RunCommandWithVersion(ctx, filepath.Clean(path), []string{"providers", "schema", "-json"}, envs, tfDistribution, tfVersion, ctx.Workspace)
+	if err != nil {
+		return "", errors.Wrap(err, "running terraform providers schema")
+	}
+	if err := p.writeSchema(schemaFile, []byte(out)); err != nil {
+		return "", err
+	}
+	if cacheFile != "" {
+		if err := p.cacheSchema(cacheFile, []byte(out)); err != nil {
+			// The schema was generated so the step doesn't fail.
+			ctx.Log.Warn("caching providers schema: %s", err)
+		}
+	}
+	return "", nil
+}
+
+// cacheFile returns the path the schema of the project in path is cached at,
+// or "" if it can't be cached.
+func (p *providersSchemaStepRunner) cacheFile(path string, tfDistribution terraform.Distribution, tfVersion *version.Version) (string, error) {
+	if p.cacheDir == "" || tfVersion == nil {
+		return "", nil
+	}
+	lockFile, err := os.ReadFile(filepath.Join(path, lockFileName))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "reading %s", lockFileName)
+	}
+	h := sha256.New()
+	h.Write([]byte(tfDistribution.BinName() + "\x00" + tfVersion.String() + "\x00")) // nolint: errcheck
+	h.Write(lockFile)                                                                // nolint: errcheck
+	return filepath.Join(p.cacheDir, hex.EncodeToString(h.Sum(nil))+".json"), nil
+}
+
+func (p *providersSchemaStepRunner) writeSchema(schemaFile string, schema []byte) error {
+	if err := os.WriteFile(schemaFile, schema, 0600); err != nil {
+		return errors.Wrap(err, "writing providers schema")
+	}
+	return nil
+}
+
+// cacheSchema writes schema to cacheFile. It's written to a temporary file
+// first so that projects reading the cache concurrently never see a partial
+// schema.
+func (p *providersSchemaStepRunner) cacheSchema(cacheFile string, schema []byte) error {
+	if err := os.MkdirAll(p.cacheDir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(p.cacheDir, "schema-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	if _, err := tmp.Write(schema); err != nil {
+		tmp.Close() // nolint: errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cacheFile)
+}
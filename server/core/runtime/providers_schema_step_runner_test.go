@@ -0,0 +1,109 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProvidersSchemaStepRunner(t *testing.T) {
+	envs := map[string]string{"key": "val"}
+	tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+	tfVersion, _ := version.NewVersion("1.5.0")
+	ctx := command.ProjectContext{
+		Workspace:   "default",
+		ProjectName: "test",
+		Log:         logging.NewNoopLogger(t),
+	}
+	schemaArgs := []string{"providers", "schema", "-json"}
+	lockFile := `provider "registry.terraform.io/hashicorp/aws" {
+  version = "5.0.0"
+}
+`
+
+	readSchema := func(t *testing.T, path string) string {
+		schema, err := os.ReadFile(filepath.Join(path, "test-default-providers-schema.json"))
+		Ok(t, err)
+		return string(schema)
+	}
+
+	t.Run("caches by lock file", func(t *testing.T) {
+		RegisterMockTestingT(t)
+		mockExecutor := tfclientmocks.NewMockClient()
+		subject := NewProvidersSchemaStepRunner(mockExecutor, tfDistribution, tfVersion, t.TempDir())
+
+		path1, path2 := t.TempDir(), t.TempDir()
+		for _, path := range []string{path1, path2} {
+			Ok(t, os.WriteFile(filepath.Join(path, lockFileName), []byte(lockFile), 0600))
+			When(mockExecutor.RunCommandWithVersion(
+				ctx, path, schemaArgs, envs, tfDistribution, tfVersion, ctx.Workspace,
+			)).ThenReturn(`{"provider_schemas":{}}`, nil)
+		}
+
+		out, err := subject.Run(ctx, nil, path1, envs)
+		Ok(t, err)
+		Equals(t, "", out)
+		Equals(t, `{"provider_schemas":{}}`, readSchema(t, path1))
+
+		// The second project locks the same providers so its schema comes
+		// from the cache.
+		_, err = subject.Run(ctx, nil, path2, envs)
+		Ok(t, err)
+		Equals(t, `{"provider_schemas":{}}`, readSchema(t, path2))
+		mockExecutor.VerifyWasCalled(Never()).RunCommandWithVersion(
+			Any[command.ProjectContext](), Eq(path2), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())
+
+		// Changing the lock file invalidates the cache.
+		Ok(t, os.WriteFile(filepath.Join(path2, lockFileName), []byte(lockFile+"# upgraded\n"), 0600))
+		_, err = subject.Run(ctx, nil, path2, envs)
+		Ok(t, err)
+		mockExecutor.VerifyWasCalledOnce().RunCommandWithVersion(
+			Any[command.ProjectContext](), Eq(path2), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())
+	})
+
+	t.Run("no lock file", func(t *testing.T) {
+		RegisterMockTestingT(t)
+		mockExecutor := tfclientmocks.NewMockClient()
+		cacheDir := t.TempDir()
+		subject := NewProvidersSchemaStepRunner(mockExecutor, tfDistribution, tfVersion, cacheDir)
+		path := t.TempDir()
+		When(mockExecutor.RunCommandWithVersion(
+			ctx, path, schemaArgs, envs, tfDistribution, tfVersion, ctx.Workspace,
+		)).ThenReturn(`{"provider_schemas":{}}`, nil)
+
+		_, err := subject.Run(ctx, nil, path, envs)
+		Ok(t, err)
+		Equals(t, `{"provider_schemas":{}}`, readSchema(t, path))
+		cached, err := os.ReadDir(cacheDir)
+		Ok(t, err)
+		Equals(t, 0, len(cached))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		RegisterMockTestingT(t)
+		mockExecutor := tfclientmocks.NewMockClient()
+		cacheDir := t.TempDir()
+		subject := NewProvidersSchemaStepRunner(mockExecutor, tfDistribution, tfVersion, cacheDir)
+		path := t.TempDir()
+		Ok(t, os.WriteFile(filepath.Join(path, lockFileName), []byte(lockFile), 0600))
+		When(mockExecutor.RunCommandWithVersion(
+			ctx, path, schemaArgs, envs, tfDistribution, tfVersion, ctx.Workspace,
+		)).ThenReturn("", errors.New("not initialized"))
+
+		_, err := subject.Run(ctx, nil, path, envs)
+		ErrEquals(t, "running terraform providers schema: not initialized", err)
+		cached, err := os.ReadDir(cacheDir)
+		Ok(t, err)
+		Equals(t, 0, len(cached))
+	})
+}
@@ -66,6 +66,8 @@ func (r *RunStepRunner) Run(
 		"PLANFILE":                        filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectName)),
 		"SHOWFILE":                        filepath.Join(path, ctx.GetShowResultFileName()),
 		"POLICYCHECKFILE":                 filepath.Join(path, ctx.GetPolicyCheckResultFileName()),
+		"COST_ESTIMATE_FILE":              filepath.Join(path, ctx.GetCostEstimateFileName()),
+		"PROVIDERS_SCHEMA_FILE":           filepath.Join(path, ctx.GetProvidersSchemaFileName()),
 		"PROJECT_NAME":                    ctx.ProjectName,
 		"PULL_AUTHOR":                     ctx.Pull.Author,
 		"PULL_NUM":                        fmt.Sprintf("%d", ctx.Pull.Num),
@@ -0,0 +1,112 @@
+package runtime
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+const (
+	// stateLockErr is output by Terraform when it can't acquire the lock
+	// on the state because another operation holds it.
+	stateLockErr = "Error acquiring the state lock"
+	// stateLockInitialBackoff is how long we wait before the first retry.
+	stateLockInitialBackoff = 5 * time.Second
+	// stateLockMaxBackoff caps how long we wait between retries.
+	stateLockMaxBackoff = 60 * time.Second
+)
+
+var stateLockInfoRegex = regexp.MustCompile(`(?m)^\s*(ID|Path|Operation|Who|Version|Created):\s*(.*?)\s*$`)
+
+// StateLockInfo is the information about the current holder of a state lock
+// that Terraform outputs when it fails to acquire the lock.
+type StateLockInfo struct {
+	ID        string
+	Path      string
+	Operation string
+	Who       string
+	Version   string
+	Created   string
+}
+
+// String returns a human readable description of the lock holder.
+func (s StateLockInfo) String() string {
+	return fmt.Sprintf("the Terraform state is locked by %q (lock ID: %q, operation: %q, created: %q)", s.Who, s.ID, s.Operation, s.Created)
+}
+
+// ParseStateLockError returns the lock holder information if output contains
+// a Terraform state lock error or nil if it does not.
+func ParseStateLockError(output string) *StateLockInfo {
+	if !strings.Contains(output, stateLockErr) {
+		return nil
+	}
+	info := &StateLockInfo{}
+	for _, match := range stateLockInfoRegex.FindAllStringSubmatch(output, -1) {
+		switch match[1] {
+		case "ID":
+			info.ID = match[2]
+		case "Path":
+			info.Path = match[2]
+		case "Operation":
+			info.Operation = match[2]
+		case "Who":
+			info.Who = match[2]
+		case "Version":
+			info.Version = match[2]
+		case "Created":
+			info.Created = match[2]
+		}
+	}
+	return info
+}
+
+// StateLockRetryExec wraps a TerraformExec and retries commands that fail
+// because the Terraform state is locked, backing off exponentially until
+// RetryTimeout has elapsed. If the command still fails, the lock holder is
+// added to the error so it's surfaced in the pull request comment.
+type StateLockRetryExec struct {
+	TerraformExec
+	RetryTimeout time.Duration
+	// sleep is used to wait between retries, it's overridden in tests.
+	sleep func(time.Duration)
+}
+
+func NewStateLockRetryExec(terraformExecutor TerraformExec, retryTimeout time.Duration) *StateLockRetryExec {
+	return &StateLockRetryExec{
+		TerraformExec: terraformExecutor,
+		RetryTimeout:  retryTimeout,
+		sleep:         time.Sleep,
+	}
+}
+
+func (s *StateLockRetryExec) RunCommandWithVersion(ctx command.ProjectContext, path string, args []string, envs map[string]string, d terraform.Distribution, v *version.Version, workspace string) (string, error) {
+	deadline := time.Now().Add(s.RetryTimeout)
+	backoff := stateLockInitialBackoff
+	for {
+		out, err := s.TerraformExec.RunCommandWithVersion(ctx, path, args, envs, d, v, workspace)
+		if err == nil {
+			return out, nil
+		}
+		lockInfo := ParseStateLockError(out)
+		if lockInfo == nil {
+			return out, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return out, errors.Wrapf(err, "%s, if the lock is stale it can be released with the %s command", lockInfo, command.ForceUnlockState.String())
+		}
+		if backoff > remaining {
+			backoff = remaining
+		}
+		ctx.Log.Info("%s, retrying in %s", lockInfo, backoff)
+		s.sleep(backoff)
+		backoff = min(backoff*2, stateLockMaxBackoff)
+	}
+}
@@ -0,0 +1,103 @@
+package runtime
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const stateLockOutput = `
+Error: Error acquiring the state lock
+
+Error message: ConditionalCheckFailedException: The conditional request failed
+Lock Info:
+  ID:        4c9f6d21-5b0e-2b5a-8f0a-3a4b7c1e9d10
+  Path:      my-bucket/env/prod/terraform.tfstate
+  Operation: OperationTypeApply
+  Who:       runner@atlantis-0
+  Version:   1.5.7
+  Created:   2024-01-02 03:04:05.123456789 +0000 UTC
+  Info:
+
+Terraform acquires a state lock to protect the state from being written
+by multiple users at the same time.
+`
+
+func TestParseStateLockError(t *testing.T) {
+	Equals(t, (*StateLockInfo)(nil), ParseStateLockError("Error: Invalid resource type"))
+
+	info := ParseStateLockError(stateLockOutput)
+	Equals(t, &StateLockInfo{
+		ID:        "4c9f6d21-5b0e-2b5a-8f0a-3a4b7c1e9d10",
+		Path:      "my-bucket/env/prod/terraform.tfstate",
+		Operation: "OperationTypeApply",
+		Who:       "runner@atlantis-0",
+		Version:   "1.5.7",
+		Created:   "2024-01-02 03:04:05.123456789 +0000 UTC",
+	}, info)
+}
+
+func TestStateLockRetryExec_NoRetry(t *testing.T) {
+	RegisterMockTestingT(t)
+	terraform := tfclientmocks.NewMockClient()
+	ctx := command.ProjectContext{Log: logging.NewNoopLogger(t)}
+	tfVersion, _ := version.NewVersion("1.5.7")
+	tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+
+	When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+		ThenReturn(stateLockOutput, errors.New("exit status 1"))
+
+	e := NewStateLockRetryExec(terraform, 0)
+	e.sleep = func(time.Duration) { t.Fatal("should not sleep when retries are disabled") }
+	out, err := e.RunCommandWithVersion(ctx, "/path", []string{"plan"}, nil, tfDistribution, tfVersion, "default")
+	Equals(t, stateLockOutput, out)
+	ErrContains(t, `the Terraform state is locked by "runner@atlantis-0" (lock ID: "4c9f6d21-5b0e-2b5a-8f0a-3a4b7c1e9d10"`, err)
+	ErrContains(t, "force-unlock-state", err)
+	terraform.VerifyWasCalledOnce().RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())
+}
+
+func TestStateLockRetryExec_RetriesUntilUnlocked(t *testing.T) {
+	RegisterMockTestingT(t)
+	terraform := tfclientmocks.NewMockClient()
+	ctx := command.ProjectContext{Log: logging.NewNoopLogger(t)}
+	tfVersion, _ := version.NewVersion("1.5.7")
+	tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+
+	When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+		ThenReturn(stateLockOutput, errors.New("exit status 1")).
+		ThenReturn(stateLockOutput, errors.New("exit status 1")).
+		ThenReturn("No changes.", nil)
+
+	var sleeps []time.Duration
+	e := NewStateLockRetryExec(terraform, time.Hour)
+	e.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	out, err := e.RunCommandWithVersion(ctx, "/path", []string{"plan"}, nil, tfDistribution, tfVersion, "default")
+	Ok(t, err)
+	Equals(t, "No changes.", out)
+	Equals(t, []time.Duration{5 * time.Second, 10 * time.Second}, sleeps)
+}
+
+func TestStateLockRetryExec_OtherErrorsNotRetried(t *testing.T) {
+	RegisterMockTestingT(t)
+	terraform := tfclientmocks.NewMockClient()
+	ctx := command.ProjectContext{Log: logging.NewNoopLogger(t)}
+	tfVersion, _ := version.NewVersion("1.5.7")
+	tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+
+	When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+		ThenReturn("Error: Invalid resource type", errors.New("exit status 1"))
+
+	e := NewStateLockRetryExec(terraform, time.Hour)
+	e.sleep = func(time.Duration) { t.Fatal("should not retry errors that aren't state lock errors") }
+	_, err := e.RunCommandWithVersion(ctx, "/path", []string{"plan"}, nil, tfDistribution, tfVersion, "default")
+	ErrEquals(t, "exit status 1", err)
+}
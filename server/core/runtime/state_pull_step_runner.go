@@ -0,0 +1,46 @@
+package runtime
+
+import (
+	"path/filepath"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// NewStatePullStepRunner returns a runner for terraform state pull. Its
+// output is the project's state so it must never be commented.
+func NewStatePullStepRunner(terraformExecutor TerraformExec, defaultTfDistribution terraform.Distribution, defaultTfVersion *version.Version) Runner {
+	return &statePullStepRunner{
+		terraformExecutor:     terraformExecutor,
+		defaultTFDistribution: defaultTfDistribution,
+		defaultTFVersion:      defaultTfVersion,
+	}
+}
+
+// statePullStepRunner runs terraform state pull after an apply or before a
+// rollback. Like outputStepRunner it runs after the project's steps have
+// selected the workspace.
+type statePullStepRunner struct {
+	terraformExecutor     TerraformExec
+	defaultTFDistribution terraform.Distribution
+	defaultTFVersion      *version.Version
+}
+
+func (p *statePullStepRunner) Run(ctx command.ProjectContext, _ []string, path string, envs map[string]string) (string, error) {
+	tfDistribution := p.defaultTFDistribution
+	tfVersion := p.defaultTFVersion
+	if ctx.TerraformDistribution != nil {
+		tfDistribution = terraform.NewDistribution(*ctx.TerraformDistribution)
+	}
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+
+	out, err := p.terraformExecutor.RunCommandWithVersion(ctx, filepath.Clean(path), []string{"state", "pull"}, envs, tfDistribution, tfVersion, ctx.Workspace)
+	if err != nil {
+		return "", errors.Wrap(err, "running terraform state pull")
+	}
+	return out, nil
+}
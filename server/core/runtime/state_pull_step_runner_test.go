@@ -0,0 +1,50 @@
+package runtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestStatePullStepRunner(t *testing.T) {
+	RegisterMockTestingT(t)
+	path := t.TempDir()
+	envs := map[string]string{"key": "val"}
+	tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+	tfVersion, _ := version.NewVersion("1.5.0")
+	ctx := command.ProjectContext{
+		Workspace: "default",
+		Log:       logging.NewNoopLogger(t),
+	}
+	mockExecutor := tfclientmocks.NewMockClient()
+	subject := NewStatePullStepRunner(mockExecutor, tfDistribution, tfVersion)
+
+	t.Run("success", func(t *testing.T) {
+		When(mockExecutor.RunCommandWithVersion(
+			ctx, path, []string{"state", "pull"}, envs, tfDistribution, tfVersion, ctx.Workspace,
+		)).ThenReturn(`{"version":4,"serial":3}`, nil)
+
+		out, err := subject.Run(ctx, nil, path, envs)
+		Ok(t, err)
+		Equals(t, `{"version":4,"serial":3}`, out)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		ctx := ctx
+		ctx.Workspace = "staging"
+		When(mockExecutor.RunCommandWithVersion(
+			ctx, path, []string{"state", "pull"}, envs, tfDistribution, tfVersion, ctx.Workspace,
+		)).ThenReturn("", errors.New("no backend"))
+
+		_, err := subject.Run(ctx, nil, path, envs)
+		ErrEquals(t, "running terraform state pull: no backend", err)
+	})
+}
@@ -0,0 +1,118 @@
+package runtime
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// sensitiveAttributeRegex matches the names of the attributes whose values are
+// redacted from the output of state show. Terraform only hides the attributes
+// providers mark as sensitive so this catches the ones they don't.
+var sensitiveAttributeRegex = regexp.MustCompile(`(?i)(password|passwd|secret|token|private_key|access_key|api_key|credential|connection_string)`)
+
+// stateShowAttributeRegex matches an attribute or map key of state show
+// output, ex. `    password = "hunter2"` or `"API_TOKEN" = "abc"`.
+var stateShowAttributeRegex = regexp.MustCompile(`^(\s*)("?)([\w./-]+)("?)(\s*=\s*)(.*)$`)
+
+const redactedStateValue = "(redacted)"
+
+type stateReadStepRunner struct {
+	subCommand            string
+	terraformExecutor     TerraformExec
+	defaultTFDistribution terraform.Distribution
+	defaultTFVersion      *version.Version
+}
+
+// NewStateListStepRunner returns a runner for terraform state list. The
+// comment arguments are passed as address filters.
+func NewStateListStepRunner(terraformExecutor TerraformExec, defaultTfDistribution terraform.Distribution, defaultTfVersion *version.Version) Runner {
+	return newStateReadStepRunner("list", terraformExecutor, defaultTfDistribution, defaultTfVersion)
+}
+
+// NewStateShowStepRunner returns a runner for terraform state show. Values of
+// attributes that look sensitive are redacted from its output.
+func NewStateShowStepRunner(terraformExecutor TerraformExec, defaultTfDistribution terraform.Distribution, defaultTfVersion *version.Version) Runner {
+	return newStateReadStepRunner("show", terraformExecutor, defaultTfDistribution, defaultTfVersion)
+}
+
+func newStateReadStepRunner(subCommand string, terraformExecutor TerraformExec, defaultTfDistribution terraform.Distribution, defaultTfVersion *version.Version) Runner {
+	runner := &stateReadStepRunner{
+		subCommand:            subCommand,
+		terraformExecutor:     terraformExecutor,
+		defaultTFDistribution: defaultTfDistribution,
+		defaultTFVersion:      defaultTfVersion,
+	}
+	return NewWorkspaceStepRunnerDelegate(terraformExecutor, defaultTfDistribution, defaultTfVersion, runner)
+}
+
+func (p *stateReadStepRunner) Run(ctx command.ProjectContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	tfDistribution := p.defaultTFDistribution
+	tfVersion := p.defaultTFVersion
+	if ctx.TerraformDistribution != nil {
+		tfDistribution = terraform.NewDistribution(*ctx.TerraformDistribution)
+	}
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+
+	stateCmd := []string{"state", p.subCommand}
+	stateCmd = append(stateCmd, extraArgs...)
+	stateCmd = append(stateCmd, ctx.EscapedCommentArgs...)
+	out, err := p.terraformExecutor.RunCommandWithVersion(ctx, filepath.Clean(path), stateCmd, envs, tfDistribution, tfVersion, ctx.Workspace)
+	if err != nil {
+		return out, err
+	}
+	if p.subCommand == "show" {
+		out = redactStateShowOutput(out)
+	}
+	if strings.TrimSpace(out) == "" {
+		return "No resources found in the state.", nil
+	}
+	return out, nil
+}
+
+// redactStateShowOutput replaces the values of the attributes of the output of
+// terraform state show whose names look sensitive with (redacted). Multi-line
+// values, ex. heredocs, maps and lists, are replaced as a whole.
+func redactStateShowOutput(out string) string {
+	lines := strings.Split(out, "\n")
+	var redacted []string
+	for i := 0; i < len(lines); i++ {
+		match := stateShowAttributeRegex.FindStringSubmatch(lines[i])
+		if match == nil || !sensitiveAttributeRegex.MatchString(match[3]) {
+			redacted = append(redacted, lines[i])
+			continue
+		}
+		indent, value := match[1], strings.TrimSpace(match[6])
+		if value == "null" || value == "(sensitive value)" {
+			redacted = append(redacted, lines[i])
+			continue
+		}
+		redacted = append(redacted, strings.Join(match[1:6], "")+redactedStateValue)
+
+		// Skip the remaining lines of multi-line values.
+		var isEnd func(line string) bool
+		switch {
+		case strings.HasPrefix(value, "<<"):
+			marker := strings.TrimLeft(value, "<-")
+			isEnd = func(line string) bool { return strings.TrimSpace(line) == marker }
+		case value == "{" || value == "[":
+			closing := indent + map[string]string{"{": "}", "[": "]"}[value]
+			isEnd = func(line string) bool { return strings.TrimRight(line, ",") == closing }
+		default:
+			continue
+		}
+		for i+1 < len(lines) {
+			i++
+			if isEnd(lines[i]) {
+				break
+			}
+		}
+	}
+	return strings.Join(redacted, "\n")
+}
@@ -0,0 +1,77 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// WasmProjectDir is where the project dir is mounted inside the module's
+// sandbox.
+const WasmProjectDir = "/project"
+
+// WasmStepRunner runs wasm steps. The module is executed by a WASI runtime
+// that sandboxes it so it can only access the project dir, mounted at
+// WasmProjectDir, and the environment variables the step declares. Unlike
+// run steps, it can't read the rest of the filesystem, Atlantis' credentials
+// or start other processes.
+type WasmStepRunner struct {
+	// RuntimePath is the path of the wasmtime compatible runtime binary, set
+	// with --wasm-runtime.
+	RuntimePath string
+}
+
+// Run runs the wasm module with args in path. Declared environment variables
+// are read from envs, which are set by earlier env and multienv steps, and
+// then from Atlantis' environment.
+func (r *WasmStepRunner) Run(
+	ctx command.ProjectContext,
+	module string,
+	args []string,
+	declaredEnv []string,
+	path string,
+	envs map[string]string,
+	postProcessOutput valid.PostProcessRunOutputOption,
+) (string, error) {
+	// Modules are validated to be in the repo when the config is parsed.
+	modulePath := filepath.Join(path, module)
+	if _, err := os.Stat(modulePath); err != nil {
+		return "", fmt.Errorf("wasm module %q not found: %w", module, err)
+	}
+
+	runtimeArgs := []string{"run", "--dir", path + "::" + WasmProjectDir}
+	for _, name := range declaredEnv {
+		value, ok := envs[name]
+		if !ok {
+			value, ok = os.LookupEnv(name)
+		}
+		if ok {
+			runtimeArgs = append(runtimeArgs, "--env", name+"="+value)
+		}
+	}
+	runtimeArgs = append(runtimeArgs, modulePath)
+	runtimeArgs = append(runtimeArgs, args...)
+
+	cmd := exec.Command(r.RuntimePath, runtimeArgs...) // #nosec
+	cmd.Dir = path
+	// The runtime only needs enough of an environment to start. It doesn't
+	// pass its environment on to the module.
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "HOME=" + os.Getenv("HOME")}
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		err = fmt.Errorf("%s: running wasm module %q in %q: \n%s", err, module, path, output)
+		ctx.Log.Debug("error: %s", err)
+		return "", err
+	}
+
+	if postProcessOutput == valid.PostProcessRunOutputHide {
+		return "", nil
+	}
+	return output, nil
+}
@@ -0,0 +1,81 @@
+package runtime_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeWasmRuntime writes a script that prints the arguments it's run with in
+// place of a real WASI runtime.
+func fakeWasmRuntime(t *testing.T, exitCode int) string {
+	path := filepath.Join(t.TempDir(), "wasmtime")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\"\nexit %d\n", exitCode)
+	Ok(t, os.WriteFile(path, []byte(script), 0700)) // nolint: gosec
+	return path
+}
+
+func TestWasmStepRunner_Run(t *testing.T) {
+	projectDir := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(projectDir, "check.wasm"), []byte("\x00asm"), 0600))
+	t.Setenv("SERVER_VAR", "from-server")
+	t.Setenv("UNDECLARED_VAR", "secret")
+
+	cases := []struct {
+		description string
+		module      string
+		exitCode    int
+		output      valid.PostProcessRunOutputOption
+		expOut      string
+		expErr      string
+	}{
+		{
+			description: "runs module in sandbox",
+			module:      "check.wasm",
+			output:      valid.PostProcessRunOutputShow,
+			expOut: fmt.Sprintf("run --dir %s::/project --env STEP_VAR=from-step --env SERVER_VAR=from-server %s --strict",
+				projectDir, filepath.Join(projectDir, "check.wasm")),
+		},
+		{
+			description: "hides output",
+			module:      "check.wasm",
+			output:      valid.PostProcessRunOutputHide,
+			expOut:      "",
+		},
+		{
+			description: "module not found",
+			module:      "missing.wasm",
+			output:      valid.PostProcessRunOutputShow,
+			expErr:      "wasm module \"missing.wasm\" not found",
+		},
+		{
+			description: "module fails",
+			module:      "check.wasm",
+			exitCode:    1,
+			output:      valid.PostProcessRunOutputShow,
+			expErr:      "exit status 1: running wasm module \"check.wasm\"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r := runtime.WasmStepRunner{RuntimePath: fakeWasmRuntime(t, c.exitCode)}
+			ctx := command.ProjectContext{Log: logging.NewNoopLogger(t)}
+			envs := map[string]string{"STEP_VAR": "from-step"}
+			out, err := r.Run(ctx, c.module, []string{"--strict"}, []string{"STEP_VAR", "SERVER_VAR", "NOT_SET"}, projectDir, envs, c.output)
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expOut, out)
+		})
+	}
+}
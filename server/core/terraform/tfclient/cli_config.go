@@ -0,0 +1,71 @@
+package tfclient
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CLIConfigFilename is the name of the Terraform CLI config file Atlantis
+// generates in its data dir.
+const CLIConfigFilename = "terraform.rc"
+
+// CLIConfig is the Terraform CLI config Atlantis generates so providers and
+// registry modules are downloaded through mirrors instead of from their
+// registries on every init.
+type CLIConfig struct {
+	// ProviderMirrorURL is the URL of a provider network mirror. If set, all
+	// providers are installed from it.
+	ProviderMirrorURL string
+	// ModuleMirrors maps registry hostnames, ex. registry.terraform.io, to the
+	// URL of the modules API that proxies them.
+	ModuleMirrors map[string]string
+	// TFEHostname and TFEToken are the Terraform Cloud/Enterprise credentials
+	// that would otherwise be in ~/.terraformrc, which terraform doesn't read
+	// when it's given a config file.
+	TFEHostname string
+	TFEToken    string
+}
+
+// IsEmpty returns true if there are no mirrors to configure.
+func (c CLIConfig) IsEmpty() bool {
+	return c.ProviderMirrorURL == "" && len(c.ModuleMirrors) == 0
+}
+
+// Render returns the contents of the CLI config file.
+func (c CLIConfig) Render() string {
+	var blocks []string
+	if c.TFEToken != "" {
+		blocks = append(blocks, fmt.Sprintf(rcFileContents, c.TFEHostname, c.TFEToken))
+	}
+	if c.ProviderMirrorURL != "" {
+		blocks = append(blocks, fmt.Sprintf("provider_installation {\n  network_mirror {\n    url = %q\n  }\n}", c.ProviderMirrorURL))
+	}
+
+	var hosts []string
+	for host := range c.ModuleMirrors {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		// Overriding a host's services replaces its service discovery so its
+		// providers API has to be set again.
+		blocks = append(blocks, fmt.Sprintf("host %q {\n  services = {\n    \"modules.v1\"   = %q\n    \"providers.v1\" = %q\n  }\n}",
+			host, c.ModuleMirrors[host], fmt.Sprintf("https://%s/v1/providers/", host)))
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// UseCLIConfig writes cfg to path and runs terraform with it. Terraform
+// then ignores ~/.terraformrc. The TF_CLI_CONFIG_FILE environment variable of
+// the Atlantis process still takes precedence.
+func (c *DefaultClient) UseCLIConfig(path string, cfg CLIConfig) error {
+	if err := os.WriteFile(path, []byte(cfg.Render()), 0600); err != nil {
+		return errors.Wrapf(err, "writing terraform CLI config to %s", path)
+	}
+	c.cliConfigFile = path
+	return nil
+}
@@ -0,0 +1,107 @@
+package tfclient
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// envSecretPrefix prefixes secret sources read from an env var of the
+	// Atlantis process.
+	envSecretPrefix = "env:"
+	// fileSecretPrefix prefixes secret sources read from a file, ex. a mounted
+	// Kubernetes secret.
+	fileSecretPrefix = "file:"
+	// maskedSecret replaces secrets in terraform's output.
+	maskedSecret = "<redacted>"
+)
+
+// ValidateSecretSource returns an error if source isn't env:NAME or
+// file:PATH.
+func ValidateSecretSource(source string) error {
+	switch {
+	case strings.HasPrefix(source, envSecretPrefix) && len(source) > len(envSecretPrefix):
+		return nil
+	case strings.HasPrefix(source, fileSecretPrefix) && len(source) > len(fileSecretPrefix):
+		return nil
+	default:
+		return fmt.Errorf("%q must be %sNAME or %sPATH", source, envSecretPrefix, fileSecretPrefix)
+	}
+}
+
+// readSecret reads the secret from source. Surrounding whitespace is
+// trimmed since secret files usually end with a newline.
+func readSecret(source string) (string, error) {
+	var secret string
+	switch {
+	case strings.HasPrefix(source, envSecretPrefix):
+		name := strings.TrimPrefix(source, envSecretPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("env var %s is not set", name)
+		}
+		secret = value
+	case strings.HasPrefix(source, fileSecretPrefix):
+		contents, err := os.ReadFile(strings.TrimPrefix(source, fileSecretPrefix))
+		if err != nil {
+			return "", err
+		}
+		secret = string(contents)
+	default:
+		return "", ValidateSecretSource(source)
+	}
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", fmt.Errorf("%s is empty", source)
+	}
+	return secret, nil
+}
+
+// registryTokenEnvVar returns the env var terraform reads the API token of
+// the registry at hostname from. Periods are encoded as underscores and
+// dashes as double underscores.
+func registryTokenEnvVar(hostname string) string {
+	hostname = strings.ReplaceAll(hostname, "-", "__")
+	return "TF_TOKEN_" + strings.ReplaceAll(hostname, ".", "_")
+}
+
+// registryTokenEnvVars reads the registry tokens and returns the env vars to
+// run terraform with and the tokens to mask in its output.
+func (c *DefaultClient) registryTokenEnvVars() ([]string, []string, error) {
+	var hostnames []string
+	for hostname := range c.registryCredentials {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	var envVars, secrets []string
+	for _, hostname := range hostnames {
+		token, err := readSecret(c.registryCredentials[hostname])
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "reading token of registry %s", hostname)
+		}
+		envVars = append(envVars, fmt.Sprintf("%s=%s", registryTokenEnvVar(hostname), token))
+		secrets = append(secrets, token)
+	}
+	return envVars, secrets, nil
+}
+
+// UseRegistryCredentials runs terraform with the API tokens of registries.
+// credentials maps registry hostnames to the source of their token, either
+// env:NAME or file:PATH, which is read before each command so rotated tokens
+// are picked up. The tokens are masked in terraform's output.
+func (c *DefaultClient) UseRegistryCredentials(credentials map[string]string) {
+	c.registryCredentials = credentials
+}
+
+// maskSecrets replaces secrets in output.
+func maskSecrets(output string, secrets []string) string {
+	for _, secret := range secrets {
+		output = strings.ReplaceAll(output, secret, maskedSecret)
+	}
+	return output
+}
@@ -85,6 +85,14 @@ type DefaultClient struct {
 	// usePluginCache determines whether or not to set the TF_PLUGIN_CACHE_DIR env var
 	usePluginCache bool
 
+	// registryCredentials maps registry hostnames to the sources of their
+	// API tokens.
+	registryCredentials map[string]string
+
+	// cliConfigFile is the Terraform CLI config file generated by Atlantis, if
+	// any, set in the TF_CLI_CONFIG_FILE env var.
+	cliConfigFile string
+
 	projectCmdOutputHandler jobs.ProjectCommandOutputHandler
 }
 
@@ -367,7 +375,7 @@ func (c *DefaultClient) RunCommandWithVersion(ctx command.ProjectContext, path s
 		output = ansi.Strip(output)
 		return fmt.Sprintf("%s\n", output), err
 	}
-	tfCmd, cmd, err := c.prepExecCmd(ctx.Log, d, v, workspace, path, args)
+	tfCmd, cmd, secrets, err := c.prepExecCmd(ctx.Log, d, v, workspace, path, args)
 	if err != nil {
 		return "", err
 	}
@@ -383,30 +391,31 @@ func (c *DefaultClient) RunCommandWithVersion(ctx command.ProjectContext, path s
 	if err != nil {
 		err = errors.Wrapf(err, "running '%s' in '%s'", tfCmd, path)
 		log.Err(err.Error())
-		return ansi.Strip(string(out)), err
+		return maskSecrets(ansi.Strip(string(out)), secrets), err
 	}
 	log.Info("Successfully ran '%s' in '%s'", tfCmd, path)
 
-	return ansi.Strip(string(out)), nil
+	return maskSecrets(ansi.Strip(string(out)), secrets), nil
 }
 
 // prepExecCmd builds a ready to execute command based on the version of terraform
 // v, and args. It returns a printable representation of the command that will
-// be run and the actual command.
-func (c *DefaultClient) prepExecCmd(log logging.SimpleLogging, d terraform.Distribution, v *version.Version, workspace string, path string, args []string) (string, *exec.Cmd, error) {
-	tfCmd, envVars, err := c.prepCmd(log, d, v, workspace, path, args)
+// be run, the actual command and the secrets to mask in its output.
+func (c *DefaultClient) prepExecCmd(log logging.SimpleLogging, d terraform.Distribution, v *version.Version, workspace string, path string, args []string) (string, *exec.Cmd, []string, error) {
+	tfCmd, envVars, secrets, err := c.prepCmd(log, d, v, workspace, path, args)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	cmd := exec.Command("sh", "-c", tfCmd)
 	cmd.Dir = path
 	cmd.Env = envVars
-	return tfCmd, cmd, nil
+	return tfCmd, cmd, secrets, nil
 }
 
 // prepCmd prepares a shell command (to be interpreted with `sh -c <cmd>`) and set of environment
-// variables for running terraform.
-func (c *DefaultClient) prepCmd(log logging.SimpleLogging, d terraform.Distribution, v *version.Version, workspace string, path string, args []string) (string, []string, error) {
+// variables for running terraform. It also returns the secrets in the environment
+// variables that must be masked in the output.
+func (c *DefaultClient) prepCmd(log logging.SimpleLogging, d terraform.Distribution, v *version.Version, workspace string, path string, args []string) (string, []string, []string, error) {
 
 	if v == nil {
 		v = c.defaultVersion
@@ -422,7 +431,7 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, d terraform.Distribut
 		binPath, err = ensureVersion(log, d, c.versions, v, c.binDir, c.downloadBaseURL, c.downloadAllowed)
 		c.versionsLock.Unlock()
 		if err != nil {
-			return "", nil, err
+			return "", nil, nil, err
 		}
 	}
 
@@ -439,11 +448,19 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, d terraform.Distribut
 	if c.usePluginCache {
 		envVars = append(envVars, fmt.Sprintf("TF_PLUGIN_CACHE_DIR=%s", c.terraformPluginCacheDir))
 	}
+	if c.cliConfigFile != "" {
+		envVars = append(envVars, fmt.Sprintf("TF_CLI_CONFIG_FILE=%s", c.cliConfigFile))
+	}
+	tokenEnvVars, secrets, err := c.registryTokenEnvVars()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	envVars = append(envVars, tokenEnvVars...)
 	// Append current Atlantis process's environment variables, ex.
 	// AWS_ACCESS_KEY.
 	envVars = append(envVars, os.Environ()...)
 	tfCmd := fmt.Sprintf("%s %s", binPath, strings.Join(args, " "))
-	return tfCmd, envVars, nil
+	return tfCmd, envVars, secrets, nil
 }
 
 // RunCommandAsync runs terraform with args. It immediately returns an
@@ -453,7 +470,7 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, d terraform.Distribut
 // If any error is passed on the out channel, there will be no
 // further output (so callers are free to exit).
 func (c *DefaultClient) RunCommandAsync(ctx command.ProjectContext, path string, args []string, customEnvVars map[string]string, d terraform.Distribution, v *version.Version, workspace string) (chan<- string, <-chan models.Line) {
-	cmd, envVars, err := c.prepCmd(ctx.Log, d, v, workspace, path, args)
+	cmd, envVars, secrets, err := c.prepCmd(ctx.Log, d, v, workspace, path, args)
 	if err != nil {
 		// The signature of `RunCommandAsync` doesn't provide for returning an immediate error, only one
 		// once reading the output. Since we won't be spawning a process, simulate that by sending the
@@ -473,6 +490,7 @@ func (c *DefaultClient) RunCommandAsync(ctx command.ProjectContext, path string,
 	}
 
 	runner := models.NewShellCommandRunner(nil, cmd, envVars, path, true, c.projectCmdOutputHandler)
+	runner.MaskValues(secrets...)
 	inCh, outCh := runner.RunCommandAsync(ctx)
 	return inCh, outCh
 }
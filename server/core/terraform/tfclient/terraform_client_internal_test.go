@@ -130,6 +130,129 @@ func TestDefaultClient_RunCommandWithVersion_EnvVars(t *testing.T) {
 	Equals(t, exp, out)
 }
 
+func TestCLIConfig_Render(t *testing.T) {
+	cfg := CLIConfig{
+		ProviderMirrorURL: "https://mirror.example.com/providers/",
+		ModuleMirrors: map[string]string{
+			"registry.terraform.io": "https://mirror.example.com/terraform/modules/",
+			"registry.opentofu.org": "https://mirror.example.com/opentofu/modules/",
+		},
+		TFEHostname: "app.terraform.io",
+		TFEToken:    "token",
+	}
+	Equals(t, `credentials "app.terraform.io" {
+  token = "token"
+}
+
+provider_installation {
+  network_mirror {
+    url = "https://mirror.example.com/providers/"
+  }
+}
+
+host "registry.opentofu.org" {
+  services = {
+    "modules.v1"   = "https://mirror.example.com/opentofu/modules/"
+    "providers.v1" = "https://registry.opentofu.org/v1/providers/"
+  }
+}
+
+host "registry.terraform.io" {
+  services = {
+    "modules.v1"   = "https://mirror.example.com/terraform/modules/"
+    "providers.v1" = "https://registry.terraform.io/v1/providers/"
+  }
+}
+`, cfg.Render())
+	Assert(t, !cfg.IsEmpty(), "exp not empty")
+	Assert(t, CLIConfig{TFEToken: "token"}.IsEmpty(), "exp empty without mirrors")
+}
+
+// Test that terraform is run with the generated CLI config.
+func TestDefaultClient_UseCLIConfig(t *testing.T) {
+	v, err := version.NewVersion("0.11.11")
+	Ok(t, err)
+	tmp := t.TempDir()
+	// The env var of the Atlantis process takes precedence so make sure it's
+	// not set, t.Setenv restores it after the test.
+	t.Setenv("TF_CLI_CONFIG_FILE", "")
+	os.Unsetenv("TF_CLI_CONFIG_FILE") // nolint: errcheck
+	ctx := command.ProjectContext{
+		Log:       logging.NewNoopLogger(t),
+		Workspace: "default",
+	}
+	client := &DefaultClient{
+		defaultVersion:          v,
+		overrideTF:              "echo",
+		projectCmdOutputHandler: jobmocks.NewMockProjectCommandOutputHandler(),
+	}
+
+	cfgPath := filepath.Join(tmp, CLIConfigFilename)
+	Ok(t, client.UseCLIConfig(cfgPath, CLIConfig{ProviderMirrorURL: "https://mirror.example.com/"}))
+	contents, err := os.ReadFile(cfgPath)
+	Ok(t, err)
+	Assert(t, strings.Contains(string(contents), "network_mirror"), "exp network mirror in %s", contents)
+
+	distribution := terraform.NewDistributionTerraformWithDownloader(terraform_mocks.NewMockDownloader())
+	out, err := client.RunCommandWithVersion(ctx, tmp, []string{"TF_CLI_CONFIG_FILE=$TF_CLI_CONFIG_FILE"}, map[string]string{}, distribution, nil, "workspace")
+	Ok(t, err)
+	Equals(t, fmt.Sprintf("TF_CLI_CONFIG_FILE=%s\n", cfgPath), out)
+}
+
+func TestRegistryTokenEnvVar(t *testing.T) {
+	Equals(t, "TF_TOKEN_app_terraform_io", registryTokenEnvVar("app.terraform.io"))
+	Equals(t, "TF_TOKEN_my__registry_example_com", registryTokenEnvVar("my-registry.example.com"))
+}
+
+func TestReadSecret(t *testing.T) {
+	t.Setenv("ATLANTIS_TEST_TOKEN", "env-token")
+	t.Setenv("ATLANTIS_TEST_EMPTY_TOKEN", " ")
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	Ok(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0600))
+
+	secret, err := readSecret("env:ATLANTIS_TEST_TOKEN")
+	Ok(t, err)
+	Equals(t, "env-token", secret)
+
+	secret, err = readSecret("file:" + tokenFile)
+	Ok(t, err)
+	Equals(t, "file-token", secret)
+
+	_, err = readSecret("env:ATLANTIS_TEST_EMPTY_TOKEN")
+	ErrEquals(t, "env:ATLANTIS_TEST_EMPTY_TOKEN is empty", err)
+
+	_, err = readSecret("token")
+	ErrEquals(t, "\"token\" must be env:NAME or file:PATH", err)
+}
+
+// Test that registry tokens are passed as env vars and masked in the output.
+func TestDefaultClient_UseRegistryCredentials(t *testing.T) {
+	v, err := version.NewVersion("0.11.11")
+	Ok(t, err)
+	tmp := t.TempDir()
+	t.Setenv("ATLANTIS_TEST_TOKEN", "s3cr3t")
+	ctx := command.ProjectContext{
+		Log:       logging.NewNoopLogger(t),
+		Workspace: "default",
+	}
+	client := &DefaultClient{
+		defaultVersion:          v,
+		overrideTF:              "echo",
+		projectCmdOutputHandler: jobmocks.NewMockProjectCommandOutputHandler(),
+	}
+	client.UseRegistryCredentials(map[string]string{"app.terraform.io": "env:ATLANTIS_TEST_TOKEN"})
+
+	distribution := terraform.NewDistributionTerraformWithDownloader(terraform_mocks.NewMockDownloader())
+	out, err := client.RunCommandWithVersion(ctx, tmp, []string{"token=$TF_TOKEN_app_terraform_io"}, map[string]string{}, distribution, nil, "workspace")
+	Ok(t, err)
+	Equals(t, "token=<redacted>\n", out)
+
+	// Rotated tokens are picked up and unreadable ones fail the command.
+	os.Unsetenv("ATLANTIS_TEST_TOKEN") // nolint: errcheck
+	_, err = client.RunCommandWithVersion(ctx, tmp, []string{"init"}, map[string]string{}, distribution, nil, "workspace")
+	ErrEquals(t, "reading token of registry app.terraform.io: env var ATLANTIS_TEST_TOKEN is not set", err)
+}
+
 // Test that it returns an error on error.
 func TestDefaultClient_RunCommandWithVersion_Error(t *testing.T) {
 	v, err := version.NewVersion("0.11.11")
@@ -1,6 +1,8 @@
 package events
 
 import (
+	"fmt"
+
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -61,6 +63,23 @@ type ApplyCommandRunner struct {
 	// are found
 	silenceVCSStatusNoProjects bool
 	SilencePRComments          []string
+	// AutoReplanDiverged is true if projects whose apply failed the
+	// undiverged requirement are planned again by Replanner.
+	AutoReplanDiverged bool
+	Replanner          CommentCommandRunner
+	// WorkItemUpdater posts apply results on the work items linked to Azure
+	// DevOps pull requests. It's nil if that's disabled.
+	WorkItemUpdater *WorkItemUpdater
+	// RequirementHandler and WorkingDir check the apply requirements of change
+	// set members before any of them is applied.
+	RequirementHandler CommandRequirementHandler
+	WorkingDir         WorkingDir
+	// CanaryStore stores the canaries applied in pull requests. If it's
+	// nil, projects aren't held behind canaries.
+	CanaryStore CanaryStore
+	// CommentUpdaters update the canary progress comments, by VCS host type.
+	// Other hosts get a new comment on every update.
+	CommentUpdaters map[models.VCSHostType]vcs.CommentUpdater
 }
 
 func (a *ApplyCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
@@ -118,6 +137,19 @@ func (a *ApplyCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 		a.pullUpdater.updatePull(ctx, cmd, command.Result{Error: err})
 		return
 	}
+	skipReviewRequirements(cmd, projectCmds)
+	markAutoApplied(cmd, projectCmds)
+
+	projectCmds, heldCmds := a.holdForCanaries(ctx, cmd, projectCmds)
+	if len(projectCmds) == 0 && (len(heldCmds) > 0 || cmd.canaryPromotion != "") {
+		ctx.Log.Info("all projects are waiting for their canary")
+		var pullStatus models.PullStatus
+		if ctx.PullStatus != nil {
+			pullStatus = *ctx.PullStatus
+		}
+		a.recordCanaries(ctx, nil, heldCmds, command.Result{}, pullStatus)
+		return
+	}
 
 	// If there are no projects to apply, don't respond to the PR and ignore
 	if len(projectCmds) == 0 && a.SilenceNoProjects {
@@ -154,13 +186,24 @@ func (a *ApplyCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 		return
 	}
 
+	runnerFunc := a.prjCmdRunner.Apply
+	if cmd.ContinueOnError {
+		// Keep applying later execution order groups after a failure but
+		// skip the projects that depend on a failed project.
+		projectCmds = runAfterDependencies(projectCmds)
+		for i := range projectCmds {
+			projectCmds[i].AbortOnExecutionOrderFail = false
+		}
+		runnerFunc = skipFailedDependents(runnerFunc)
+	}
+
 	// Only run commands in parallel if enabled
 	var result command.Result
 	if a.isParallelEnabled(projectCmds) {
 		ctx.Log.Info("Running applies in parallel")
-		result = runProjectCmdsParallelGroups(ctx, projectCmds, a.prjCmdRunner.Apply, a.parallelPoolSize)
+		result = runProjectCmdsParallelGroups(ctx, projectCmds, runnerFunc, a.parallelPoolSize)
 	} else {
-		result = runProjectCmds(projectCmds, a.prjCmdRunner.Apply)
+		result = runProjectCmds(projectCmds, runnerFunc)
 	}
 	ctx.CommandHasErrors = result.HasErrors()
 
@@ -176,10 +219,105 @@ func (a *ApplyCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 	}
 
 	a.updateCommitStatus(ctx, pullStatus)
+	a.recordCanaries(ctx, projectCmds, heldCmds, result, pullStatus)
+
+	if a.WorkItemUpdater != nil && baseRepo.VCSHost.Type == models.AzureDevops {
+		a.WorkItemUpdater.UpdateWorkItems(ctx, result, pullStatus)
+	}
 
 	if a.autoMerger.automergeEnabled(projectCmds) && !cmd.AutoMergeDisabled {
 		a.autoMerger.automerge(ctx, pullStatus, a.autoMerger.deleteSourceBranchOnMergeEnabled(projectCmds), cmd.AutoMergeMethod)
 	}
+
+	if a.AutoReplanDiverged && a.Replanner != nil {
+		a.replanDiverged(ctx, result)
+	}
+}
+
+// replanDiverged plans the projects of result again whose apply failed the
+// undiverged requirement. Planning merges the updated base branch into the
+// working dir first so the new plans can be applied.
+func (a *ApplyCommandRunner) replanDiverged(ctx *command.Context, result command.Result) {
+	var diverged []command.ProjectResult
+	for _, r := range result.ProjectResults {
+		if r.Failure == DivergedApplyFailure {
+			diverged = append(diverged, r)
+		}
+	}
+	if len(diverged) == 0 {
+		return
+	}
+
+	ctx.Log.Info("planning %d projects again since the base branch has diverged", len(diverged))
+	if err := a.vcsClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, autoReplanDivergedComment, command.Apply.String()); err != nil {
+		ctx.Log.Err("unable to comment on pull request: %s", err)
+	}
+	for _, r := range diverged {
+		planCmd := &CommentCommand{Name: command.Plan, ProjectName: r.ProjectName}
+		if r.ProjectName == "" {
+			planCmd.RepoRelDir = r.RepoRelDir
+			planCmd.Workspace = r.Workspace
+		}
+		a.Replanner.Run(ctx, planCmd)
+	}
+}
+
+// CheckApplyRequirements returns a failure if applying the plans of the pull
+// request of ctx with cmd would be rejected, without applying them. It checks
+// that every project was planned successfully at the head commit and meets
+// its apply requirements.
+func (a *ApplyCommandRunner) CheckApplyRequirements(ctx *command.Context, cmd *CommentCommand) (string, error) {
+	if locked, err := a.IsLocked(); err != nil {
+		ctx.Log.Warn("checking global apply lock: %s", err)
+	} else if locked {
+		return "Apply is disabled globally.", nil
+	}
+	if a.DisableApplyAll && !cmd.IsForSpecificProject() {
+		return "Applying all projects is disabled.", nil
+	}
+
+	pullStatus, err := a.Backend.GetPullStatus(ctx.Pull)
+	if err != nil {
+		return "", err
+	}
+	if pullStatus == nil || pullStatus.Pull.HeadCommit != ctx.Pull.HeadCommit {
+		return "It wasn't planned at its latest commit.", nil
+	}
+	for _, project := range pullStatus.Projects {
+		switch project.Status {
+		case models.ErroredPlanStatus, models.ErroredPolicyCheckStatus, models.DiscardedPlanStatus:
+			return fmt.Sprintf("Project %s has status %q.", projectStatusName(project), project.Status.String()), nil
+		}
+	}
+
+	ctx.PullRequestStatus, err = a.pullReqStatusFetcher.FetchPullStatus(ctx.Log, ctx.Pull)
+	if err != nil {
+		return "", err
+	}
+	projectCmds, err := a.prjCmdBuilder.BuildApplyCommands(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+	for _, projectCmd := range projectCmds {
+		repoDir, err := a.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, projectCmd.Workspace)
+		if err != nil {
+			return "", err
+		}
+		failure, err := a.RequirementHandler.ValidateApplyProject(repoDir, projectCmd)
+		if failure != "" || err != nil {
+			return failure, err
+		}
+	}
+	return "", nil
+}
+
+// projectStatusName returns the name of project or its dir and workspace if
+// it doesn't have one.
+func projectStatusName(project models.ProjectStatus) string {
+	if project.ProjectName != "" {
+		return project.ProjectName
+	}
+	return fmt.Sprintf("dir: %s workspace: %s", project.RepoRelDir, project.Workspace)
 }
 
 func (a *ApplyCommandRunner) IsLocked() (bool, error) {
@@ -226,5 +364,9 @@ func (a *ApplyCommandRunner) updateCommitStatus(ctx *command.Context, pullStatus
 var applyAllDisabledComment = "**Error:** Running `atlantis apply` without flags is disabled." +
 	" You must specify which project to apply via the `-d <dir>`, `-w <workspace>` or `-p <project name>` flags."
 
+// autoReplanDivergedComment is posted before projects that failed to apply
+// because the base branch diverged are planned again.
+var autoReplanDivergedComment = "The base branch has been updated since these projects were planned. Planning them again with the updated base branch, review the new plans and run `atlantis apply` again."
+
 // applyDisabledComment is posted when apply commands are disabled globally and an apply command is issued.
 var applyDisabledComment = "**Error:** Running `atlantis apply` is disabled."
@@ -554,3 +554,58 @@ func TestApplyCommandRunner_ExecutionOrder(t *testing.T) {
 		})
 	}
 }
+
+type recordingCommentCommandRunner struct {
+	cmds []*events.CommentCommand
+}
+
+func (r *recordingCommentCommandRunner) Run(_ *command.Context, cmd *events.CommentCommand) {
+	r.cmds = append(r.cmds, cmd)
+}
+
+func TestApplyCommandRunner_AutoReplanDiverged(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+	vcsClient := setup(t)
+	replanner := &recordingCommentCommandRunner{}
+	applyCommandRunner.AutoReplanDiverged = true
+	applyCommandRunner.Replanner = replanner
+
+	scopeNull, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+	modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, State: models.OpenPullState, Num: testdata.Pull.Num}
+	cmd := &events.CommentCommand{Name: command.Apply}
+	ctx := &command.Context{
+		User:     testdata.User,
+		Log:      logger,
+		Scope:    scopeNull,
+		Pull:     modelPull,
+		HeadRepo: testdata.GithubRepo,
+		Trigger:  command.CommentTrigger,
+	}
+	projectCmds := []command.ProjectContext{
+		{ProjectName: "diverged", RepoRelDir: "a", Workspace: "default"},
+		{RepoRelDir: "b", Workspace: "staging"},
+		{ProjectName: "applied", RepoRelDir: "c", Workspace: "default"},
+	}
+	When(projectCommandBuilder.BuildApplyCommands(ctx, cmd)).ThenReturn(projectCmds, nil)
+	When(projectCommandRunner.Apply(projectCmds[0])).ThenReturn(command.ProjectResult{
+		Command: command.Apply, ProjectName: "diverged", RepoRelDir: "a", Workspace: "default", Failure: events.DivergedApplyFailure,
+	})
+	When(projectCommandRunner.Apply(projectCmds[1])).ThenReturn(command.ProjectResult{
+		Command: command.Apply, RepoRelDir: "b", Workspace: "staging", Failure: events.DivergedApplyFailure,
+	})
+	When(projectCommandRunner.Apply(projectCmds[2])).ThenReturn(command.ProjectResult{
+		Command: command.Apply, ProjectName: "applied", RepoRelDir: "c", Workspace: "default", ApplySuccess: "Great success!",
+	})
+
+	applyCommandRunner.Run(ctx, cmd)
+
+	Equals(t, []*events.CommentCommand{
+		{Name: command.Plan, ProjectName: "diverged"},
+		{Name: command.Plan, RepoRelDir: "b", Workspace: "staging"},
+	}, replanner.cmds)
+	vcsClient.VerifyWasCalledOnce().CreateComment(
+		Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull.Num),
+		Eq("The base branch has been updated since these projects were planned. Planning them again with the updated base branch, review the new plans and run `atlantis apply` again."),
+		Eq("apply"))
+}
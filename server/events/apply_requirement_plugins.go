@@ -0,0 +1,144 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// ApplyRequirementPayload is the JSON context passed to apply requirement
+// plugins, on stdin for commands and as the request body for URLs.
+type ApplyRequirementPayload struct {
+	Requirement string `json:"requirement"`
+	Repo        string `json:"repo"`
+	PullNum     int    `json:"pull_num"`
+	PullURL     string `json:"pull_url"`
+	PullAuthor  string `json:"pull_author"`
+	HeadCommit  string `json:"head_commit"`
+	BaseBranch  string `json:"base_branch"`
+	User        string `json:"user"`
+	ProjectName string `json:"project_name,omitempty"`
+	RepoRelDir  string `json:"repo_rel_dir"`
+	Workspace   string `json:"workspace"`
+	Approved    bool   `json:"approved"`
+	Mergeable   bool   `json:"mergeable"`
+}
+
+// ApplyRequirementResponse is the JSON apply requirement plugins respond
+// with.
+type ApplyRequirementResponse struct {
+	Pass bool `json:"pass"`
+	// Message is shown on the pull request when the requirement isn't met.
+	Message string `json:"message"`
+}
+
+// checkApplyRequirementPlugin checks the apply requirement of plugin for the
+// project of ctx. The apply is blocked if the plugin fails so that an outage
+// of the external system doesn't let unapproved changes through.
+func checkApplyRequirementPlugin(ctx command.ProjectContext, repoDir string, plugin valid.ApplyRequirementPlugin) (failure string) {
+	payload := ApplyRequirementPayload{
+		Requirement: plugin.Name,
+		Repo:        ctx.Pull.BaseRepo.FullName,
+		PullNum:     ctx.Pull.Num,
+		PullURL:     ctx.Pull.URL,
+		PullAuthor:  ctx.Pull.Author,
+		HeadCommit:  ctx.Pull.HeadCommit,
+		BaseBranch:  ctx.Pull.BaseBranch,
+		User:        ctx.User.Username,
+		ProjectName: ctx.ProjectName,
+		RepoRelDir:  ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		Approved:    ctx.PullReqStatus.ApprovalStatus.IsApproved,
+		Mergeable:   ctx.PullReqStatus.Mergeable,
+	}
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Sprintf("Apply requirement plugin %q failed: %s", plugin.Name, err)
+	}
+
+	timeout := plugin.Timeout
+	if timeout == 0 {
+		timeout = valid.DefaultApplyRequirementPluginTimeout
+	}
+	reqCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var resp ApplyRequirementResponse
+	if plugin.URL != "" {
+		resp, err = postApplyRequirement(reqCtx, plugin, input)
+	} else {
+		resp, err = runApplyRequirement(reqCtx, plugin, filepath.Join(repoDir, ctx.RepoRelDir), input)
+	}
+	if reqCtx.Err() != nil {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		ctx.Log.Err("apply requirement plugin %q failed: %s", plugin.Name, err)
+		return fmt.Sprintf("Apply requirement plugin %q failed: %s", plugin.Name, err)
+	}
+	if !resp.Pass {
+		if resp.Message == "" {
+			return fmt.Sprintf("Apply requirement %q is not met.", plugin.Name)
+		}
+		return fmt.Sprintf("Apply requirement %q is not met: %s", plugin.Name, resp.Message)
+	}
+	return ""
+}
+
+// runApplyRequirement runs the command of plugin in dir, the project's
+// directory, with the requirement's name as its only argument.
+func runApplyRequirement(ctx context.Context, plugin valid.ApplyRequirementPlugin, dir string, input []byte) (ApplyRequirementResponse, error) {
+	var resp ApplyRequirementResponse
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Name) // #nosec
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return resp, fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return resp, errors.Wrap(err, "parsing response")
+	}
+	return resp, nil
+}
+
+// postApplyRequirement POSTs input to the URL of plugin.
+func postApplyRequirement(ctx context.Context, plugin valid.ApplyRequirementPlugin, input []byte) (ApplyRequirementResponse, error) {
+	var resp ApplyRequirementResponse
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, plugin.URL, bytes.NewReader(input))
+	if err != nil {
+		return resp, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range plugin.Headers {
+		req.Header.Set(k, v)
+	}
+	httpResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close() // nolint: errcheck
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return resp, fmt.Errorf("%s returned %d: %s", plugin.URL, httpResp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return resp, errors.Wrap(err, "parsing response")
+	}
+	return resp, nil
+}
@@ -0,0 +1,138 @@
+package events_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func applyRequirementPluginCtx(t *testing.T, reqs ...string) (command.ProjectContext, string) {
+	repoDir := t.TempDir()
+	Ok(t, os.Mkdir(filepath.Join(repoDir, "dir"), 0700))
+	ctx := lifecyclePluginCtx(t)
+	ctx.ApplyRequirements = reqs
+	return ctx, repoDir
+}
+
+func TestApplyRequirementPlugins_Command(t *testing.T) {
+	cases := map[string]struct {
+		script     string
+		expFailure string
+	}{
+		"pass": {
+			script: `echo '{"pass": true}'`,
+		},
+		"not met": {
+			script:     `echo '{"pass": false, "message": "change ticket CHG-1 is not approved"}'`,
+			expFailure: `Apply requirement "cab-approved" is not met: change ticket CHG-1 is not approved`,
+		},
+		"not met without message": {
+			script:     `echo '{"pass": false}'`,
+			expFailure: `Apply requirement "cab-approved" is not met.`,
+		},
+		"fails": {
+			script:     "echo unreachable >&2; exit 1",
+			expFailure: `Apply requirement plugin "cab-approved" failed: exit status 1: unreachable`,
+		},
+		"invalid response": {
+			script:     "echo ok",
+			expFailure: `Apply requirement plugin "cab-approved" failed: parsing response: invalid character 'o' looking for beginning of value`,
+		},
+		"gets context": {
+			script: `input=$(cat)
+case "$1 $(pwd) $input" in
+  cab-approved*/dir*'"repo":"owner/repo"'*'"user":"alice"'*) echo '{"pass": true}' ;;
+  *) echo '{"pass": false, "message": "unexpected context"}' ;;
+esac`,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			handler := &events.DefaultCommandRequirementHandler{
+				GlobalCfgStore: valid.NewGlobalCfgStore(valid.GlobalCfg{
+					ApplyRequirementPlugins: map[string]valid.ApplyRequirementPlugin{
+						"cab-approved": {Name: "cab-approved", Command: writePlugin(t, "cab", c.script)},
+					},
+				}),
+			}
+			ctx, repoDir := applyRequirementPluginCtx(t, "plugin:cab-approved")
+			failure, err := handler.ValidateApplyProject(repoDir, ctx)
+			Ok(t, err)
+			Equals(t, c.expFailure, failure)
+		})
+	}
+}
+
+func TestApplyRequirementPlugins_CommandTimesOut(t *testing.T) {
+	handler := &events.DefaultCommandRequirementHandler{
+		GlobalCfgStore: valid.NewGlobalCfgStore(valid.GlobalCfg{
+			ApplyRequirementPlugins: map[string]valid.ApplyRequirementPlugin{
+				"slow": {Name: "slow", Command: writePlugin(t, "slow", "exec sleep 5"), Timeout: 10 * time.Millisecond},
+			},
+		}),
+	}
+	ctx, repoDir := applyRequirementPluginCtx(t, "plugin:slow")
+	failure, err := handler.ValidateApplyProject(repoDir, ctx)
+	Ok(t, err)
+	Equals(t, `Apply requirement plugin "slow" failed: timed out after 10ms`, failure)
+}
+
+func TestApplyRequirementPlugins_URL(t *testing.T) {
+	var payload events.ApplyRequirementPayload
+	var authorization string
+	pass := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		Ok(t, json.NewDecoder(r.Body).Decode(&payload))
+		json.NewEncoder(w).Encode(events.ApplyRequirementResponse{Pass: pass, Message: "frozen"}) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	handler := &events.DefaultCommandRequirementHandler{
+		GlobalCfgStore: valid.NewGlobalCfgStore(valid.GlobalCfg{
+			ApplyRequirementPlugins: map[string]valid.ApplyRequirementPlugin{
+				"cab-approved": {Name: "cab-approved", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}},
+			},
+		}),
+	}
+	ctx, repoDir := applyRequirementPluginCtx(t, raw.ApprovedRequirement, "plugin:cab-approved")
+	ctx.PullReqStatus.ApprovalStatus.IsApproved = true
+
+	failure, err := handler.ValidateApplyProject(repoDir, ctx)
+	Ok(t, err)
+	Equals(t, "", failure)
+	Equals(t, "Bearer token", authorization)
+	Equals(t, events.ApplyRequirementPayload{
+		Requirement: "cab-approved",
+		Repo:        "owner/repo",
+		PullNum:     1,
+		User:        "alice",
+		ProjectName: "project",
+		RepoRelDir:  "dir",
+		Workspace:   "default",
+		Approved:    true,
+	}, payload)
+
+	pass = false
+	failure, err = handler.ValidateApplyProject(repoDir, ctx)
+	Ok(t, err)
+	Equals(t, `Apply requirement "cab-approved" is not met: frozen`, failure)
+}
+
+func TestApplyRequirementPlugins_NotDefined(t *testing.T) {
+	handler := &events.DefaultCommandRequirementHandler{GlobalCfgStore: valid.NewGlobalCfgStore(valid.GlobalCfg{})}
+	ctx, repoDir := applyRequirementPluginCtx(t, "plugin:removed")
+	failure, err := handler.ValidateApplyProject(repoDir, ctx)
+	Ok(t, err)
+	Equals(t, `Apply requirement plugin "removed" is not defined.`, failure)
+}
@@ -0,0 +1,40 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/utils"
+)
+
+// checkApplyWindow returns a failure if now is outside the project's apply
+// windows, unless an apply window admin applied with --override-apply-window.
+func (p *DefaultProjectCommandRunner) checkApplyWindow(ctx command.ProjectContext, now time.Time) string {
+	if valid.InApplyWindow(ctx.ApplyWindows, now) {
+		return ""
+	}
+
+	if ctx.OverrideApplyWindow {
+		if utils.SlicesContains(p.ApplyWindowAdmins, ctx.User.Username) {
+			ctx.Log.Info("applying outside apply windows, overridden by %s", ctx.User.Username)
+			return ""
+		}
+		return fmt.Sprintf("User @%s is not allowed to apply outside apply windows with --%s.", ctx.User.Username, overrideApplyWindowFlagLong)
+	}
+
+	var windows []string
+	for _, w := range ctx.ApplyWindows {
+		windows = append(windows, fmt.Sprintf("`%s`", w))
+	}
+	failure := fmt.Sprintf("Outside apply window, this project can only be applied during %s", strings.Join(windows, ", "))
+	if next, ok := valid.NextApplyWindow(ctx.ApplyWindows, now); ok {
+		failure += fmt.Sprintf(", next window at %s", next.In(ctx.ApplyWindows[0].Location()).Format("2006-01-02 15:04 MST"))
+	}
+	if len(p.ApplyWindowAdmins) > 0 {
+		failure += fmt.Sprintf(". Apply window admins can apply anyway with `%s --%s`", ctx.ApplyCmd, overrideApplyWindowFlagLong)
+	}
+	return failure + "."
+}
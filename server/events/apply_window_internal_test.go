@@ -0,0 +1,84 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCheckApplyWindow(t *testing.T) {
+	businessHours, err := valid.NewApplyWindow("* 9-16 * * 1-5", "Europe/Berlin")
+	Ok(t, err)
+	// Saturday noon in Berlin.
+	saturday := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	// Monday morning in Berlin.
+	monday := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		description string
+		windows     []valid.ApplyWindow
+		now         time.Time
+		override    bool
+		user        string
+		admins      []string
+		expFailure  string
+	}{
+		{
+			description: "no windows",
+			now:         saturday,
+		},
+		{
+			description: "inside window",
+			windows:     []valid.ApplyWindow{businessHours},
+			now:         monday,
+		},
+		{
+			description: "outside window",
+			windows:     []valid.ApplyWindow{businessHours},
+			now:         saturday,
+			expFailure:  "Outside apply window, this project can only be applied during `* 9-16 * * 1-5 Europe/Berlin`, next window at 2026-10-19 09:00 CEST.",
+		},
+		{
+			description: "outside window with admins",
+			windows:     []valid.ApplyWindow{businessHours},
+			now:         saturday,
+			admins:      []string{"alice"},
+			expFailure:  "Outside apply window, this project can only be applied during `* 9-16 * * 1-5 Europe/Berlin`, next window at 2026-10-19 09:00 CEST. Apply window admins can apply anyway with `atlantis apply -p project --override-apply-window`.",
+		},
+		{
+			description: "overridden by admin",
+			windows:     []valid.ApplyWindow{businessHours},
+			now:         saturday,
+			override:    true,
+			user:        "alice",
+			admins:      []string{"alice"},
+		},
+		{
+			description: "overridden by non admin",
+			windows:     []valid.ApplyWindow{businessHours},
+			now:         saturday,
+			override:    true,
+			user:        "bob",
+			admins:      []string{"alice"},
+			expFailure:  "User @bob is not allowed to apply outside apply windows with --override-apply-window.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			runner := &DefaultProjectCommandRunner{ApplyWindowAdmins: c.admins}
+			ctx := command.ProjectContext{
+				Log:                 logging.NewNoopLogger(t),
+				ApplyCmd:            "atlantis apply -p project",
+				ApplyWindows:        c.windows,
+				OverrideApplyWindow: c.override,
+				User:                models.User{Username: c.user},
+			}
+			Equals(t, c.expFailure, runner.checkApplyWindow(ctx, c.now))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"text/template"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// DefaultAuthFailureHint is the hint posted for provider authentication
+// failures if --auth-failure-hint isn't set.
+const DefaultAuthFailureHint = "{{.Reason}}. Contact your Atlantis administrators."
+
+// AuthFailureHintData is the data available to the auth failure hint
+// template.
+type AuthFailureHintData struct {
+	// Provider is the provider that rejected the credentials, ex. AWS.
+	Provider string
+	// Reason describes the failure, ex. Atlantis's AWS credentials have
+	// expired.
+	Reason string
+	// Repo is the full name of the repo, ex. owner/repo.
+	Repo string
+	// Project is the name of the project or <dir>/<workspace> if it doesn't
+	// have a name.
+	Project string
+}
+
+// authFailurePattern matches the output of a provider authentication failure.
+type authFailurePattern struct {
+	provider string
+	reason   string
+	regexp   *regexp.Regexp
+}
+
+// authFailurePatterns are the errors of common provider authentication
+// failures, checked in order.
+var authFailurePatterns = []authFailurePattern{
+	{
+		provider: "AWS",
+		reason:   "Atlantis's AWS credentials have expired",
+		regexp:   regexp.MustCompile(`ExpiredToken|RequestExpired|security token included in the request is expired|token has expired`),
+	},
+	{
+		provider: "AWS",
+		reason:   "Atlantis's AWS credentials are invalid",
+		regexp:   regexp.MustCompile(`InvalidClientTokenId|SignatureDoesNotMatch|UnrecognizedClientException|security token included in the request is invalid`),
+	},
+	{
+		provider: "AWS",
+		reason:   "Atlantis has no AWS credentials",
+		regexp:   regexp.MustCompile(`NoCredentialProviders|[Nn]o valid credential sources|failed to refresh cached credentials|no EC2 IMDS role found`),
+	},
+	{
+		provider: "Google Cloud",
+		reason:   "Atlantis has no Google Cloud credentials",
+		regexp:   regexp.MustCompile(`could not find default credentials|GOOGLE_APPLICATION_CREDENTIALS`),
+	},
+	{
+		provider: "Google Cloud",
+		reason:   "Atlantis's Google Cloud credentials have expired or been revoked",
+		regexp:   regexp.MustCompile(`invalid_grant|oauth2: cannot fetch token`),
+	},
+	{
+		provider: "Azure",
+		reason:   "Atlantis's Azure credentials are missing or have expired",
+		regexp:   regexp.MustCompile(`run 'az login'|AADSTS700082|AADSTS70043|AADSTS7000222`),
+	},
+}
+
+// detectAuthFailure returns the provider authentication failure output shows
+// or nil if it doesn't show one.
+func detectAuthFailure(output string) *command.AuthFailure {
+	for _, pattern := range authFailurePatterns {
+		if pattern.regexp.MatchString(output) {
+			return &command.AuthFailure{Provider: pattern.provider, Reason: pattern.reason}
+		}
+	}
+	return nil
+}
+
+// NewAuthFailureHint parses hint, the template of the hint posted for
+// provider authentication failures, with AuthFailureHintData.
+func NewAuthFailureHint(hint string) (*template.Template, error) {
+	return template.New("auth-failure-hint").Option("missingkey=error").Parse(hint)
+}
+
+// annotateAuthFailure sets the auth failure of result if its error or failure
+// is a provider authentication failure, with the hint to post.
+func (p *DefaultProjectCommandRunner) annotateAuthFailure(ctx command.ProjectContext, result *command.ProjectResult) {
+	output := result.Failure
+	if result.Error != nil {
+		output = result.Error.Error()
+	}
+	if output == "" {
+		return
+	}
+	authFailure := detectAuthFailure(output)
+	if authFailure == nil {
+		return
+	}
+
+	project := ctx.ProjectName
+	if project == "" {
+		project = fmt.Sprintf("%s/%s", ctx.RepoRelDir, ctx.Workspace)
+	}
+	hint := p.AuthFailureHint
+	if hint == nil {
+		hint = template.Must(NewAuthFailureHint(DefaultAuthFailureHint))
+	}
+	var buf bytes.Buffer
+	if err := hint.Execute(&buf, AuthFailureHintData{
+		Provider: authFailure.Provider,
+		Reason:   authFailure.Reason,
+		Repo:     ctx.BaseRepo.FullName,
+		Project:  project,
+	}); err != nil {
+		ctx.Log.Err("rendering auth failure hint: %s", err)
+		authFailure.Hint = authFailure.Reason + "."
+	} else {
+		authFailure.Hint = buf.String()
+	}
+	ctx.Log.Warn("%s authentication failure: %s", authFailure.Provider, authFailure.Reason)
+	result.AuthFailure = authFailure
+}
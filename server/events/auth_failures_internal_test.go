@@ -0,0 +1,116 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"text/template"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDetectAuthFailure(t *testing.T) {
+	cases := []struct {
+		output      string
+		expProvider string
+		expReason   string
+	}{
+		{
+			output:      "Error: error configuring Terraform AWS Provider: ExpiredToken: The security token included in the request is expired",
+			expProvider: "AWS",
+			expReason:   "Atlantis's AWS credentials have expired",
+		},
+		{
+			output:      "Error: InvalidClientTokenId: The security token included in the request is invalid.",
+			expProvider: "AWS",
+			expReason:   "Atlantis's AWS credentials are invalid",
+		},
+		{
+			output:      "Error: configuring Terraform AWS Provider: no valid credential sources for Terraform AWS Provider found.",
+			expProvider: "AWS",
+			expReason:   "Atlantis has no AWS credentials",
+		},
+		{
+			output:      "Error: Attempted to load application default credentials since neither `credentials` nor `access_token` was set in the provider block. No credentials loaded. To use your gcloud credentials, run 'gcloud auth application-default login'. Original error: google: could not find default credentials.",
+			expProvider: "Google Cloud",
+			expReason:   "Atlantis has no Google Cloud credentials",
+		},
+		{
+			output:      "oauth2: cannot fetch token: 400 Bad Request\nResponse: {\"error\": \"invalid_grant\"}",
+			expProvider: "Google Cloud",
+			expReason:   "Atlantis's Google Cloud credentials have expired or been revoked",
+		},
+		{
+			output:      "Error: building account: could not acquire access token to parse claims: Please run 'az login' to setup account.",
+			expProvider: "Azure",
+			expReason:   "Atlantis's Azure credentials are missing or have expired",
+		},
+		{
+			output: "Error: Unsupported argument\n\nAn argument named \"tokens\" is not expected here.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.output, func(t *testing.T) {
+			authFailure := detectAuthFailure(c.output)
+			if c.expProvider == "" {
+				Assert(t, authFailure == nil, "exp no auth failure, got %v", authFailure)
+				return
+			}
+			Assert(t, authFailure != nil, "exp an auth failure")
+			Equals(t, c.expProvider, authFailure.Provider)
+			Equals(t, c.expReason, authFailure.Reason)
+		})
+	}
+}
+
+func TestAnnotateAuthFailure(t *testing.T) {
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		BaseRepo:   models.Repo{FullName: "owner/repo"},
+		RepoRelDir: "network",
+		Workspace:  "default",
+	}
+	hint, err := NewAuthFailureHint("{{.Reason}} while running {{.Project}} of {{.Repo}}; contact #platform.")
+	Ok(t, err)
+
+	cases := []struct {
+		description string
+		hint        *template.Template
+		result      command.ProjectResult
+		expHint     string
+	}{
+		{
+			description: "default hint",
+			result:      command.ProjectResult{Error: errors.New("exit status 1\nError: ExpiredToken: token expired")},
+			expHint:     "Atlantis's AWS credentials have expired. Contact your Atlantis administrators.",
+		},
+		{
+			description: "custom hint",
+			hint:        hint,
+			result:      command.ProjectResult{Error: errors.New("exit status 1\nError: ExpiredToken: token expired")},
+			expHint:     "Atlantis's AWS credentials have expired while running network/default of owner/repo; contact #platform.",
+		},
+		{
+			description: "other error",
+			result:      command.ProjectResult{Error: errors.New("exit status 1\nError: Invalid reference")},
+		},
+		{
+			description: "success",
+			result:      command.ProjectResult{ApplySuccess: "Apply complete! ExpiredToken"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			runner := &DefaultProjectCommandRunner{AuthFailureHint: c.hint}
+			runner.annotateAuthFailure(ctx, &c.result)
+			if c.expHint == "" {
+				Assert(t, c.result.AuthFailure == nil, "exp no auth failure, got %v", c.result.AuthFailure)
+				return
+			}
+			Assert(t, c.result.AuthFailure != nil, "exp an auth failure")
+			Equals(t, c.expHint, c.result.AuthFailure.Hint)
+		})
+	}
+}
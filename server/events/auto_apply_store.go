@@ -0,0 +1,63 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// AutoApplyStore stores the low-risk plans waiting to be applied
+// automatically.
+type AutoApplyStore interface {
+	// Save stores autoApply, replacing the one of the same project of the
+	// pull request.
+	Save(autoApply models.AutoApply) error
+	// Delete deletes the auto-apply of the project at dir and workspace
+	// named projectName of the pull request pullNum of the repo
+	// repoFullName, if there's one.
+	Delete(repoFullName string, pullNum int, dir string, workspace string, projectName string) error
+	// List returns all the auto-applies.
+	List() ([]models.AutoApply, error)
+	// DeleteForPull deletes the auto-applies of the pull request pullNum of
+	// the repo repoFullName once it's closed.
+	DeleteForPull(repoFullName string, pullNum int) error
+}
+
+// DefaultAutoApplyStore stores auto-applies in the locking backend so they
+// survive restarts and are shared by the Atlantis instances.
+type DefaultAutoApplyStore struct {
+	autoApplies *locking.RecordStore[models.AutoApply]
+}
+
+// NewAutoApplyStore returns an AutoApplyStore that stores auto-applies in
+// backend.
+func NewAutoApplyStore(backend locking.RecordBackend) *DefaultAutoApplyStore {
+	return &DefaultAutoApplyStore{autoApplies: locking.NewRecordStore[models.AutoApply](backend, "auto-applies")}
+}
+
+func sameAutoApply(a models.AutoApply, repoFullName string, pullNum int, dir string, workspace string, projectName string) bool {
+	return a.Repository == repoFullName && a.PullNum == pullNum && a.RepoRelDir == dir && a.Workspace == workspace && a.ProjectName == projectName
+}
+
+func (f *DefaultAutoApplyStore) Save(autoApply models.AutoApply) error {
+	return f.autoApplies.Put(autoApply, func(a models.AutoApply) bool {
+		return sameAutoApply(a, autoApply.Repository, autoApply.PullNum, autoApply.RepoRelDir, autoApply.Workspace, autoApply.ProjectName)
+	})
+}
+
+func (f *DefaultAutoApplyStore) Delete(repoFullName string, pullNum int, dir string, workspace string, projectName string) error {
+	_, err := f.autoApplies.DeleteFunc(func(a models.AutoApply) bool {
+		return sameAutoApply(a, repoFullName, pullNum, dir, workspace, projectName)
+	})
+	return err
+}
+
+func (f *DefaultAutoApplyStore) List() ([]models.AutoApply, error) {
+	return f.autoApplies.List()
+}
+
+func (f *DefaultAutoApplyStore) DeleteForPull(repoFullName string, pullNum int) error {
+	_, err := f.autoApplies.DeleteFunc(func(a models.AutoApply) bool {
+		return a.Repository == repoFullName && a.PullNum == pullNum
+	})
+	return err
+}
@@ -0,0 +1,38 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAutoApplyStore(t *testing.T) {
+	store := events.NewAutoApplyStore(newTestRecordBackend(t))
+	applyAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	dns := models.AutoApply{Repository: "owner/repo", PullNum: 1, RepoRelDir: "dns", Workspace: "default", Rules: []string{"dns"}, ApplyAt: applyAt}
+	tags := models.AutoApply{Repository: "owner/repo", PullNum: 1, RepoRelDir: "app", Workspace: "default", ProjectName: "app", Rules: []string{"tags"}, ApplyAt: applyAt}
+	other := models.AutoApply{Repository: "owner/repo", PullNum: 2, RepoRelDir: "dns", Workspace: "default", Rules: []string{"dns"}, ApplyAt: applyAt}
+	Ok(t, store.Save(dns))
+	Ok(t, store.Save(tags))
+	Ok(t, store.Save(other))
+
+	// Saving the same project replaces it.
+	dns.ApplyAt = applyAt.Add(time.Hour)
+	Ok(t, store.Save(dns))
+	autoApplies, err := store.List()
+	Ok(t, err)
+	Equals(t, []models.AutoApply{dns, tags, other}, autoApplies)
+
+	Ok(t, store.Delete("owner/repo", 1, "app", "default", "app"))
+	autoApplies, err = store.List()
+	Ok(t, err)
+	Equals(t, []models.AutoApply{dns, other}, autoApplies)
+
+	Ok(t, store.DeleteForPull("owner/repo", 1))
+	autoApplies, err = store.List()
+	Ok(t, err)
+	Equals(t, []models.AutoApply{other}, autoApplies)
+}
@@ -0,0 +1,288 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// tagAttributes are the attributes holding the tags of resources.
+var tagAttributes = []string{"tags", "tags_all"}
+
+// autoApprovalResourceChange is a resource change in the terraform show
+// -json output.
+type autoApprovalResourceChange struct {
+	Mode   string `json:"mode"`
+	Type   string `json:"type"`
+	Change struct {
+		Actions      []string       `json:"actions"`
+		Before       map[string]any `json:"before"`
+		After        map[string]any `json:"after"`
+		AfterUnknown map[string]any `json:"after_unknown"`
+	} `json:"change"`
+}
+
+// onlyTagsChanged returns true if the change only updates tag attributes.
+func (rc autoApprovalResourceChange) onlyTagsChanged() bool {
+	before, after := make(map[string]any), make(map[string]any)
+	for k, v := range rc.Change.Before {
+		if !slices.Contains(tagAttributes, k) {
+			before[k] = v
+		}
+	}
+	for k, v := range rc.Change.After {
+		if !slices.Contains(tagAttributes, k) {
+			after[k] = v
+		}
+	}
+	for k, v := range rc.Change.AfterUnknown {
+		if !slices.Contains(tagAttributes, k) && !isKnown(v) {
+			return false
+		}
+	}
+	return reflect.DeepEqual(before, after)
+}
+
+// isKnown returns true if v, a value of after_unknown, doesn't mark any value
+// as unknown.
+func isKnown(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return !v
+	case map[string]any:
+		for _, e := range v {
+			if !isKnown(e) {
+				return false
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if !isKnown(e) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lowRiskRules returns the names of the rules matched by the resource changes
+// of a plan and true if every change matched one, ie. the plan is low risk.
+// Plans that don't change any resource aren't low risk since there's nothing
+// to approve. planJSON is the output of terraform show -json on the planfile.
+func lowRiskRules(autoApproval valid.AutoApproval, planJSON string) ([]string, bool, error) {
+	var plan struct {
+		ResourceChanges []autoApprovalResourceChange `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return nil, false, fmt.Errorf("parsing plan json: %w", err)
+	}
+	var rules []string
+	changes := 0
+	for _, rc := range plan.ResourceChanges {
+		action := riskAction(rc.Change.Actions)
+		if rc.Mode != "managed" || action == "" {
+			continue
+		}
+		changes++
+		onlyTags := action == valid.RiskActionUpdate && rc.onlyTagsChanged()
+		i := slices.IndexFunc(autoApproval.Rules, func(rule valid.AutoApprovalRule) bool {
+			return rule.Matches(rc.Type, action, onlyTags)
+		})
+		if i == -1 {
+			return nil, false, nil
+		}
+		if name := autoApproval.Rules[i].Name; !slices.Contains(rules, name) {
+			rules = append(rules, name)
+		}
+	}
+	return rules, changes > 0, nil
+}
+
+// projectLowRiskRules returns the auto-approval rules matched by the
+// project's plan and true if it's low risk. Remote operations and projects
+// of other tools than terraform don't have a planfile to show so they're
+// never low risk.
+func (p *DefaultProjectCommandRunner) projectLowRiskRules(ctx command.ProjectContext, absPath string) ([]string, bool, error) {
+	if ctx.AutoApproval == nil || !valid.UsesTerraform(ctx.Tool) {
+		return nil, false, nil
+	}
+	out, err := p.showPlan(ctx, absPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("showing plan to check if it's low risk: %w", err)
+	}
+	if out == "" {
+		ctx.Log.Warn("unable to check if the plan is low risk because it can't be shown")
+		return nil, false, nil
+	}
+	return lowRiskRules(*ctx.AutoApproval, out)
+}
+
+// planAutoApproval returns how the project's plan is auto-approved or nil if
+// it isn't low risk.
+func (p *DefaultProjectCommandRunner) planAutoApproval(ctx command.ProjectContext, absPath string) (*models.PlanAutoApproval, error) {
+	rules, lowRisk, err := p.projectLowRiskRules(ctx, absPath)
+	if err != nil || !lowRisk {
+		return nil, err
+	}
+	approval := &models.PlanAutoApproval{Rules: rules}
+	if ctx.AutoApproval.ApplyAfter > 0 {
+		approval.ApplyAt = time.Now().Add(ctx.AutoApproval.ApplyAfter)
+	}
+	return approval, nil
+}
+
+// autoApprove returns ctx approved if its apply requires an approval the
+// pull request doesn't have and its plan is low risk, along with the
+// auto-approval rules it matched. The plan is checked again since the rules
+// may have changed since it was planned. The rules of auto-applies are
+// returned too so that every auto-approval is recorded by
+// recordAutoApproval.
+func (p *DefaultProjectCommandRunner) autoApprove(ctx command.ProjectContext, absPath string) (command.ProjectContext, []string, error) {
+	needsApproval := slices.Contains(ctx.ApplyRequirements, raw.ApprovedRequirement) && !ctx.PullReqStatus.ApprovalStatus.IsApproved
+	if !needsApproval && !ctx.AutoApplied {
+		return ctx, nil, nil
+	}
+	rules, lowRisk, err := p.projectLowRiskRules(ctx, absPath)
+	if err != nil || !lowRisk {
+		return ctx, nil, err
+	}
+	ctx.Log.Info("plan is low risk according to the auto-approval rules %v", rules)
+	if needsApproval {
+		ctx.PullReqStatus.ApprovalStatus.IsApproved = true
+	}
+	return ctx, rules, nil
+}
+
+// recordAutoApproval records in the audit log that the apply of ctx was
+// auto-approved by rules. It does nothing if rules is empty, ie. the apply
+// wasn't auto-approved.
+func (p *DefaultProjectCommandRunner) recordAutoApproval(ctx command.ProjectContext, rules []string) error {
+	if len(rules) == 0 || p.AutoApprovalAuditLog == nil {
+		return nil
+	}
+	err := p.AutoApprovalAuditLog.Record(AutoApprovalAuditEntry{
+		Time:         time.Now(),
+		RepoFullName: ctx.Pull.BaseRepo.FullName,
+		PullNum:      ctx.Pull.Num,
+		HeadCommit:   ctx.Pull.HeadCommit,
+		ProjectName:  ctx.ProjectName,
+		Path:         ctx.RepoRelDir,
+		Workspace:    ctx.Workspace,
+		Rules:        rules,
+		User:         ctx.User.Username,
+		AutoApplied:  ctx.AutoApplied,
+	})
+	if err != nil {
+		return fmt.Errorf("recording auto-approval: %w", err)
+	}
+	return nil
+}
+
+// markAutoApplied marks projectCmds as auto-applied if cmd was run by the
+// AutoApplier.
+func markAutoApplied(cmd *CommentCommand, projectCmds []command.ProjectContext) {
+	if !cmd.autoApply {
+		return
+	}
+	for i := range projectCmds {
+		projectCmds[i].AutoApplied = true
+	}
+}
+
+// scheduleAutoApplies stores the low-risk plans of result that are applied
+// automatically once their delay elapses. Projects planned again without a
+// delay aren't applied automatically anymore.
+func (p *PlanCommandRunner) scheduleAutoApplies(ctx *command.Context, result command.Result) {
+	if p.AutoApplyStore == nil || result.PlansDeleted {
+		return
+	}
+	for _, r := range result.ProjectResults {
+		if r.PlanSuccess == nil || r.PlanSuccess.AutoApproval == nil || r.PlanSuccess.AutoApproval.ApplyAt.IsZero() {
+			if err := p.AutoApplyStore.Delete(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, r.RepoRelDir, r.Workspace, r.ProjectName); err != nil {
+				ctx.Log.Err("deleting auto-apply: %s", err)
+			}
+			continue
+		}
+		err := p.AutoApplyStore.Save(models.AutoApply{
+			Repository:  ctx.Pull.BaseRepo.FullName,
+			PullNum:     ctx.Pull.Num,
+			RepoRelDir:  r.RepoRelDir,
+			Workspace:   r.Workspace,
+			ProjectName: r.ProjectName,
+			Rules:       r.PlanSuccess.AutoApproval.Rules,
+			ApplyAt:     r.PlanSuccess.AutoApproval.ApplyAt,
+			Pull:        ctx.Pull,
+			HeadRepo:    ctx.HeadRepo,
+			User:        ctx.User,
+		})
+		if err != nil {
+			ctx.Log.Err("scheduling auto-apply: %s", err)
+		}
+	}
+}
+
+// AutoApplier applies the low-risk plans whose delay elapsed. It runs
+// periodically.
+type AutoApplier struct {
+	AutoApplyStore    AutoApplyStore
+	CommandRunner     CommandRunner
+	PullStatusFetcher PullStatusFetcher
+	Logger            logging.SimpleLogging
+}
+
+func (a *AutoApplier) Run() {
+	autoApplies, err := a.AutoApplyStore.List()
+	if err != nil {
+		a.Logger.Err("listing auto-applies: %s", err)
+		return
+	}
+	now := time.Now()
+	for _, autoApply := range autoApplies {
+		if now.Before(autoApply.ApplyAt) {
+			continue
+		}
+		// The auto-apply is deleted first so a failing apply isn't retried
+		// on every run. The plan can then be applied with a comment.
+		if err := a.AutoApplyStore.Delete(autoApply.Repository, autoApply.PullNum, autoApply.RepoRelDir, autoApply.Workspace, autoApply.ProjectName); err != nil {
+			a.Logger.Err("deleting auto-apply: %s", err)
+			continue
+		}
+		if !a.stillPlanned(autoApply) {
+			a.Logger.Info("not applying dir %s workspace %s of %s#%d automatically since it's not planned at the same commit anymore", autoApply.RepoRelDir, autoApply.Workspace, autoApply.Repository, autoApply.PullNum)
+			continue
+		}
+		a.Logger.Info("applying the low-risk plan of dir %s workspace %s of %s#%d", autoApply.RepoRelDir, autoApply.Workspace, autoApply.Repository, autoApply.PullNum)
+		cmd := projectCommentCommand(command.Apply, autoApply.ProjectName, autoApply.RepoRelDir, autoApply.Workspace)
+		cmd.autoApply = true
+		pull, headRepo := autoApply.Pull, autoApply.HeadRepo
+		a.CommandRunner.RunCommentCommand(pull.BaseRepo, &headRepo, &pull, autoApply.User, autoApply.PullNum, cmd)
+	}
+}
+
+// stillPlanned returns true if the project of autoApply is still planned at
+// the commit it was planned at, ie. it wasn't applied, discarded or planned
+// again since.
+func (a *AutoApplier) stillPlanned(autoApply models.AutoApply) bool {
+	pullStatus, err := a.PullStatusFetcher.GetPullStatus(autoApply.Pull)
+	if err != nil {
+		a.Logger.Err("fetching pull status: %s", err)
+		return false
+	}
+	if pullStatus == nil || pullStatus.Pull.HeadCommit != autoApply.Pull.HeadCommit {
+		return false
+	}
+	for _, project := range pullStatus.Projects {
+		if project.RepoRelDir == autoApply.RepoRelDir && project.Workspace == autoApply.Workspace && project.ProjectName == autoApply.ProjectName {
+			return project.Status == models.PlannedPlanStatus || project.Status == models.PassedPolicyCheckStatus
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AutoApprovalAuditEntry records a low-risk plan applied without approval.
+type AutoApprovalAuditEntry struct {
+	Time         time.Time `json:"time"`
+	RepoFullName string    `json:"repo"`
+	PullNum      int       `json:"pull_num"`
+	HeadCommit   string    `json:"head_commit"`
+	ProjectName  string    `json:"project_name,omitempty"`
+	Path         string    `json:"path"`
+	Workspace    string    `json:"workspace"`
+	// Rules are the names of the auto-approval rules the plan matched.
+	Rules []string `json:"rules"`
+	// User is the user who applied the plan or, if AutoApplied, who planned
+	// it.
+	User string `json:"user"`
+	// AutoApplied is true if Atlantis applied the plan on its own once its
+	// delay elapsed.
+	AutoApplied bool `json:"auto_applied,omitempty"`
+}
+
+// AutoApprovalAuditLog records the plans applied because they're low risk.
+type AutoApprovalAuditLog interface {
+	Record(entry AutoApprovalAuditEntry) error
+}
+
+// FileAutoApprovalAuditLog appends entries as JSON lines to the file at Path.
+// Like FileLockAuditLog, the file is never truncated so it can be shipped to
+// a log pipeline.
+type FileAutoApprovalAuditLog struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileAutoApprovalAuditLog) Record(entry AutoApprovalAuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "opening auto-approval audit log")
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close() // nolint: errcheck
+		return errors.Wrap(err, "writing auto-approval audit log")
+	}
+	return file.Close()
+}
@@ -0,0 +1,193 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var testAutoApproval = valid.AutoApproval{
+	Rules: []valid.AutoApprovalRule{
+		{Name: "dns", ResourceTypes: []string{"aws_route53_record"}},
+		{Name: "tags", OnlyTags: true},
+	},
+}
+
+const lowRiskPlanJSON = `{
+	"resource_changes": [
+		{"mode": "managed", "type": "aws_route53_record", "change": {"actions": ["create"], "before": null, "after": {"name": "www"}, "after_unknown": {"id": true}}},
+		{"mode": "managed", "type": "aws_s3_bucket", "change": {"actions": ["update"], "before": {"bucket": "logs", "tags": {}}, "after": {"bucket": "logs", "tags": {"team": "infra"}}, "after_unknown": {"tags_all": true, "versioning": [{}]}}},
+		{"mode": "managed", "type": "aws_iam_role", "change": {"actions": ["no-op"]}},
+		{"mode": "data", "type": "aws_iam_policy_document", "change": {"actions": ["read"]}}
+	]
+}`
+
+func TestLowRiskRules(t *testing.T) {
+	cases := []struct {
+		description string
+		planJSON    string
+		expRules    []string
+		expLowRisk  bool
+	}{
+		{
+			description: "every change matches",
+			planJSON:    lowRiskPlanJSON,
+			expRules:    []string{"dns", "tags"},
+			expLowRisk:  true,
+		},
+		{
+			description: "update of more than tags",
+			planJSON: `{"resource_changes": [
+				{"mode": "managed", "type": "aws_s3_bucket", "change": {"actions": ["update"], "before": {"bucket": "logs", "tags": {}}, "after": {"bucket": "other", "tags": {"team": "infra"}}}}
+			]}`,
+		},
+		{
+			description: "update with unknown values",
+			planJSON: `{"resource_changes": [
+				{"mode": "managed", "type": "aws_s3_bucket", "change": {"actions": ["update"], "before": {"tags": {}}, "after": {"tags": {"team": "infra"}}, "after_unknown": {"arn": true}}}
+			]}`,
+		},
+		{
+			description: "delete",
+			planJSON: `{"resource_changes": [
+				{"mode": "managed", "type": "aws_route53_record", "change": {"actions": ["delete"], "before": {"name": "www"}, "after": null}}
+			]}`,
+		},
+		{
+			description: "no changes",
+			planJSON:    `{"resource_changes": [{"mode": "managed", "type": "aws_iam_role", "change": {"actions": ["no-op"]}}]}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			rules, lowRisk, err := lowRiskRules(testAutoApproval, c.planJSON)
+			Ok(t, err)
+			Equals(t, c.expLowRisk, lowRisk)
+			if c.expLowRisk {
+				Equals(t, c.expRules, rules)
+			}
+		})
+	}
+
+	_, _, err := lowRiskRules(testAutoApproval, "not json")
+	ErrContains(t, "parsing plan json", err)
+}
+
+// recordingAuditLog records the auto-approvals.
+type recordingAuditLog struct {
+	entries []AutoApprovalAuditEntry
+}
+
+func (r *recordingAuditLog) Record(entry AutoApprovalAuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestAutoApprove(t *testing.T) {
+	cases := []struct {
+		description  string
+		planJSON     string
+		requirements []string
+		approved     bool
+		autoApplied  bool
+		expApproved  bool
+		expRecorded  bool
+	}{
+		{
+			description:  "low risk",
+			planJSON:     lowRiskPlanJSON,
+			requirements: []string{"approved"},
+			expApproved:  true,
+			expRecorded:  true,
+		},
+		{
+			description:  "already approved",
+			planJSON:     lowRiskPlanJSON,
+			requirements: []string{"approved"},
+			approved:     true,
+			expApproved:  true,
+		},
+		{
+			description: "approval not required but auto-applied",
+			planJSON:    lowRiskPlanJSON,
+			autoApplied: true,
+			expRecorded: true,
+		},
+		{
+			description:  "not low risk",
+			planJSON:     `{"resource_changes": [{"mode": "managed", "type": "aws_route53_record", "change": {"actions": ["delete"]}}]}`,
+			requirements: []string{"approved"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			auditLog := &recordingAuditLog{}
+			runner := &DefaultProjectCommandRunner{ShowStepRunner: staticShowRunner{out: c.planJSON}, AutoApprovalAuditLog: auditLog}
+			ctx := command.ProjectContext{
+				Log:               logging.NewNoopLogger(t),
+				AutoApproval:      &testAutoApproval,
+				ApplyRequirements: c.requirements,
+				AutoApplied:       c.autoApplied,
+				PullReqStatus:     models.PullReqStatus{ApprovalStatus: models.ApprovalStatus{IsApproved: c.approved}},
+				RepoRelDir:        "dns",
+				Workspace:         "default",
+			}
+			ctx, rules, err := runner.autoApprove(ctx, t.TempDir())
+			Ok(t, err)
+			Equals(t, c.expApproved, ctx.PullReqStatus.ApprovalStatus.IsApproved)
+			Equals(t, 0, len(auditLog.entries))
+			Ok(t, runner.recordAutoApproval(ctx, rules))
+			Equals(t, c.expRecorded, len(auditLog.entries) == 1)
+			if c.expRecorded {
+				Equals(t, []string{"dns", "tags"}, auditLog.entries[0].Rules)
+				Equals(t, c.autoApplied, auditLog.entries[0].AutoApplied)
+			}
+		})
+	}
+}
+
+// staticPullStatusFetcher returns the same pull status for any pull request.
+type staticPullStatusFetcher struct {
+	status *models.PullStatus
+}
+
+func (s staticPullStatusFetcher) GetPullStatus(_ models.PullRequest) (*models.PullStatus, error) {
+	return s.status, nil
+}
+
+func TestAutoApplier_Run(t *testing.T) {
+	store := NewAutoApplyStore(newTestRecordBackend(t))
+	pull := models.PullRequest{Num: 1, HeadCommit: "abc123", BaseRepo: models.Repo{FullName: "owner/repo"}}
+	due := models.AutoApply{Repository: "owner/repo", PullNum: 1, RepoRelDir: "dns", Workspace: "default", ApplyAt: time.Now().Add(-time.Minute), Pull: pull}
+	waiting := models.AutoApply{Repository: "owner/repo", PullNum: 1, RepoRelDir: "app", Workspace: "default", ApplyAt: time.Now().Add(time.Hour), Pull: pull}
+	applied := models.AutoApply{Repository: "owner/repo", PullNum: 1, RepoRelDir: "db", Workspace: "default", ApplyAt: time.Now().Add(-time.Minute), Pull: pull}
+	for _, autoApply := range []models.AutoApply{due, waiting, applied} {
+		Ok(t, store.Save(autoApply))
+	}
+
+	runner := &recordingCommandRunner{}
+	applier := &AutoApplier{
+		AutoApplyStore: store,
+		CommandRunner:  runner,
+		PullStatusFetcher: staticPullStatusFetcher{status: &models.PullStatus{
+			Pull: pull,
+			Projects: []models.ProjectStatus{
+				{RepoRelDir: "dns", Workspace: "default", Status: models.PlannedPlanStatus},
+				{RepoRelDir: "app", Workspace: "default", Status: models.PlannedPlanStatus},
+				{RepoRelDir: "db", Workspace: "default", Status: models.AppliedPlanStatus},
+			},
+		}},
+		Logger: logging.NewNoopLogger(t),
+	}
+	applier.Run()
+	Equals(t, []CommentCommand{{Name: command.Apply, RepoRelDir: "dns", Workspace: "default", AutoMergeDisabled: true, autoApply: true}}, runner.cmds)
+
+	autoApplies, err := store.List()
+	Ok(t, err)
+	Equals(t, []models.AutoApply{waiting}, autoApplies)
+}
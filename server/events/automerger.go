@@ -2,6 +2,7 @@ package events
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -11,6 +12,16 @@ import (
 type AutoMerger struct {
 	VCSClient       vcs.Client
 	GlobalAutomerge bool
+	// ProvenanceStore, if set, records the provenance of the applies of each
+	// automerged pull request. A commit status linking to the record is set on
+	// the merge commit.
+	ProvenanceStore ProvenanceStore
+	// GithubPullGetter looks up the merge commit of GitHub pull requests.
+	GithubPullGetter GithubPullGetter
+	// AtlantisURL is the URL the provenance API endpoint is served at.
+	AtlantisURL string
+	// StatusName is the name used to identify Atlantis in commit statuses.
+	StatusName string
 }
 
 func (c *AutoMerger) automerge(ctx *command.Context, pullStatus models.PullStatus, deleteSourceBranchOnMerge bool, mergeMethod string) {
@@ -42,7 +53,59 @@ func (c *AutoMerger) automerge(ctx *command.Context, pullStatus models.PullStatu
 		if commentErr := c.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, failureComment, command.Apply.String()); commentErr != nil {
 			ctx.Log.Err("failed to comment about automerge failing: %s", err)
 		}
+		return
 	}
+	c.recordProvenance(ctx, pullStatus)
+}
+
+// recordProvenance saves the provenance record of the merged pull request and
+// sets a commit status on the merge commit linking to it. The description of
+// the status holds the digest of the record so it can be verified. Where the
+// merge commit isn't known the status is set on the head commit.
+func (c *AutoMerger) recordProvenance(ctx *command.Context, pullStatus models.PullStatus) {
+	if c.ProvenanceStore == nil {
+		return
+	}
+	applies, err := c.ProvenanceStore.ListApplies(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	if err != nil {
+		ctx.Log.Err("listing apply provenance: %s", err)
+		return
+	}
+	mergeCommit := c.mergeCommit(ctx)
+	provenance := buildProvenance(ctx.Pull, pullStatus, applies, mergeCommit, ctx.User.Username, time.Now())
+	if err := c.ProvenanceStore.Save(provenance); err != nil {
+		ctx.Log.Err("saving provenance: %s", err)
+		return
+	}
+	digest, err := provenanceDigest(provenance)
+	if err != nil {
+		ctx.Log.Err("computing provenance digest: %s", err)
+		return
+	}
+
+	statusPull := ctx.Pull
+	if mergeCommit != "" {
+		statusPull.HeadCommit = mergeCommit
+	}
+	description := fmt.Sprintf("%d applies recorded, sha256:%s", len(provenance.Applies), digest)
+	src := fmt.Sprintf("%s/provenance", c.StatusName)
+	if err := c.VCSClient.UpdateStatus(ctx.Log, ctx.Pull.BaseRepo, statusPull, models.SuccessCommitStatus, src, description, provenanceURL(c.AtlantisURL, ctx.Pull)); err != nil {
+		ctx.Log.Err("setting provenance commit status: %s", err)
+	}
+}
+
+// mergeCommit returns the commit the pull request was merged as or an empty
+// string if the VCS host doesn't report it.
+func (c *AutoMerger) mergeCommit(ctx *command.Context) string {
+	if ctx.Pull.BaseRepo.VCSHost.Type != models.Github || c.GithubPullGetter == nil {
+		return ""
+	}
+	ghPull, err := c.GithubPullGetter.GetPullRequest(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num)
+	if err != nil {
+		ctx.Log.Warn("looking up merge commit: %s", err)
+		return ""
+	}
+	return ghPull.GetMergeCommitSHA()
 }
 
 // automergeEnabled returns true if automerging is enabled in this context.
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+//go:generate pegomock generate github.com/runatlantis/atlantis/server/events --package mocks -o mocks/mock_azuredevops_work_item_client.go AzureDevopsWorkItemClient
+
+// AzureDevopsWorkItemClient makes API calls to the Azure Boards work items
+// linked to pull requests.
+type AzureDevopsWorkItemClient interface {
+	// GetPullWorkItemIDs returns the ids of the work items linked to the pull
+	// request.
+	GetPullWorkItemIDs(logger logging.SimpleLogging, repo models.Repo, num int) ([]int, error)
+	// CreateWorkItemComment posts an HTML comment on the work item.
+	CreateWorkItemComment(logger logging.SimpleLogging, repo models.Repo, id int, comment string) error
+	// UpdateWorkItemState moves the work item to state.
+	UpdateWorkItemState(logger logging.SimpleLogging, repo models.Repo, id int, state string) error
+}
+
+// WorkItemUpdater posts apply results on the Azure Boards work items linked
+// to Azure DevOps pull requests.
+type WorkItemUpdater struct {
+	Client AzureDevopsWorkItemClient
+	// AppliedState is the state the work items are moved to once every
+	// project of the pull request is applied. If empty they aren't moved.
+	AppliedState string
+}
+
+// UpdateWorkItems comments result on the work items linked to the pull
+// request and moves them to AppliedState if every project in pullStatus is
+// applied. Errors are logged since the apply itself is done.
+func (w *WorkItemUpdater) UpdateWorkItems(ctx *command.Context, result command.Result, pullStatus models.PullStatus) {
+	if len(result.ProjectResults) == 0 {
+		return
+	}
+	ids, err := w.Client.GetPullWorkItemIDs(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num)
+	if err != nil {
+		ctx.Log.Warn("unable to get work items linked to pull request: %s", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	comment := workItemComment(ctx, result)
+	moveToState := w.AppliedState != "" && !result.HasErrors() &&
+		pullStatus.StatusCount(models.AppliedPlanStatus)+pullStatus.StatusCount(models.PlannedNoChangesPlanStatus) == len(pullStatus.Projects)
+	for _, id := range ids {
+		if err := w.Client.CreateWorkItemComment(ctx.Log, ctx.Pull.BaseRepo, id, comment); err != nil {
+			ctx.Log.Warn("unable to comment on work item %d: %s", id, err)
+		}
+		if moveToState {
+			if err := w.Client.UpdateWorkItemState(ctx.Log, ctx.Pull.BaseRepo, id, w.AppliedState); err != nil {
+				ctx.Log.Warn("unable to move work item %d to %q: %s", id, w.AppliedState, err)
+			}
+		}
+	}
+}
+
+// workItemComment returns the HTML comment with the apply results of each
+// project.
+func workItemComment(ctx *command.Context, result command.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<p>Atlantis apply of <a href=\"%s\">pull request %d</a> by %s:</p><ul>",
+		html.EscapeString(ctx.Pull.URL), ctx.Pull.Num, html.EscapeString(ctx.User.Username))
+	for _, r := range result.ProjectResults {
+		name := fmt.Sprintf("dir: %s workspace: %s", r.RepoRelDir, r.Workspace)
+		if r.ProjectName != "" {
+			name = fmt.Sprintf("project: %s %s", r.ProjectName, name)
+		}
+		status := "applied"
+		if !r.IsSuccessful() {
+			status = "failed"
+		}
+		fmt.Fprintf(&b, "<li><code>%s</code>: %s</li>", html.EscapeString(name), status)
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
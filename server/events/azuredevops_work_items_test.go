@@ -0,0 +1,70 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+func TestWorkItemUpdater_UpdateWorkItems(t *testing.T) {
+	repo := models.Repo{FullName: "owner/project/repo"}
+	applied := command.ProjectResult{RepoRelDir: "networking", Workspace: "default", ProjectName: "vpc", ApplySuccess: "Apply complete!"}
+	failed := command.ProjectResult{RepoRelDir: "billing", Workspace: "default", Error: errors.New("error")}
+	expComment := `<p>Atlantis apply of <a href="https://dev.azure.com/owner/project/_git/repo/pullrequest/1">pull request 1</a> by lkysow:</p><ul>` +
+		`<li><code>project: vpc dir: networking workspace: default</code>: applied</li>`
+	cases := []struct {
+		description string
+		results     []command.ProjectResult
+		pullStatus  models.PullStatus
+		expComment  string
+		expMoved    bool
+	}{
+		{
+			description: "all projects applied",
+			results:     []command.ProjectResult{applied},
+			pullStatus:  models.PullStatus{Projects: []models.ProjectStatus{{Status: models.AppliedPlanStatus}}},
+			expComment:  expComment + "</ul>",
+			expMoved:    true,
+		},
+		{
+			description: "apply failed",
+			results:     []command.ProjectResult{applied, failed},
+			pullStatus:  models.PullStatus{Projects: []models.ProjectStatus{{Status: models.AppliedPlanStatus}, {Status: models.ErroredApplyStatus}}},
+			expComment:  expComment + "<li><code>dir: billing workspace: default</code>: failed</li></ul>",
+		},
+		{
+			description: "projects left to apply",
+			results:     []command.ProjectResult{applied},
+			pullStatus:  models.PullStatus{Projects: []models.ProjectStatus{{Status: models.AppliedPlanStatus}, {Status: models.PlannedPlanStatus}}},
+			expComment:  expComment + "</ul>",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			client := mocks.NewMockAzureDevopsWorkItemClient()
+			When(client.GetPullWorkItemIDs(Any[logging.SimpleLogging](), Eq(repo), Eq(1))).ThenReturn([]int{12}, nil)
+			updater := events.WorkItemUpdater{Client: client, AppliedState: "Resolved"}
+			ctx := &command.Context{
+				Log:  logging.NewNoopLogger(t),
+				Pull: models.PullRequest{Num: 1, BaseRepo: repo, URL: "https://dev.azure.com/owner/project/_git/repo/pullrequest/1"},
+				User: models.User{Username: "lkysow"},
+			}
+
+			updater.UpdateWorkItems(ctx, command.Result{ProjectResults: c.results}, c.pullStatus)
+
+			client.VerifyWasCalledOnce().CreateWorkItemComment(Any[logging.SimpleLogging](), Eq(repo), Eq(12), Eq(c.expComment))
+			if c.expMoved {
+				client.VerifyWasCalledOnce().UpdateWorkItemState(Any[logging.SimpleLogging](), Eq(repo), Eq(12), Eq("Resolved"))
+			} else {
+				client.VerifyWasCalled(Never()).UpdateWorkItemState(Any[logging.SimpleLogging](), Any[models.Repo](), Any[int](), Any[string]())
+			}
+		})
+	}
+}
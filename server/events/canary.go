@@ -0,0 +1,242 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// canaryProjectStatus returns the status of the canary of pc in pullStatus
+// or nil if the pull request doesn't change the canary.
+func canaryProjectStatus(pullStatus *models.PullStatus, pc command.ProjectContext) *models.ProjectStatus {
+	if pullStatus == nil {
+		return nil
+	}
+	for i, p := range pullStatus.Projects {
+		if p.RepoRelDir == pc.RepoRelDir && p.Workspace == pc.Canary.Workspace && p.ProjectName == pc.Canary.ProjectName {
+			return &pullStatus.Projects[i]
+		}
+	}
+	return nil
+}
+
+// holdForCanaries splits projectCmds into the projects to apply and the
+// projects held until the canary of their dir is applied and promoted. The
+// canary of a dir is applied on its own first. The other projects are held
+// until then and until its soak time elapses or the apply was commented with
+// --promote-canary. If the pull request doesn't change the canary, they
+// aren't held.
+func (a *ApplyCommandRunner) holdForCanaries(ctx *command.Context, cmd *CommentCommand, projectCmds []command.ProjectContext) (run []command.ProjectContext, held []command.ProjectContext) {
+	if a.CanaryStore == nil {
+		return projectCmds, nil
+	}
+
+	// A promotion doesn't apply the canary again even if it's still planned.
+	canaryApplied := make(map[string]bool)
+	for _, pc := range projectCmds {
+		if pc.IsCanary() && cmd.canaryPromotion == "" {
+			canaryApplied[pc.RepoRelDir] = true
+		}
+	}
+
+	for _, pc := range projectCmds {
+		if cmd.canaryPromotion != "" && (pc.Canary == nil || pc.IsCanary() || pc.RepoRelDir != cmd.canaryPromotion) {
+			// The promotion of a dir only applies its held projects.
+			continue
+		}
+		if pc.Canary == nil || pc.IsCanary() {
+			run = append(run, pc)
+			continue
+		}
+		if canaryApplied[pc.RepoRelDir] {
+			held = append(held, pc)
+			continue
+		}
+		status := canaryProjectStatus(ctx.PullStatus, pc)
+		if status == nil {
+			run = append(run, pc)
+			continue
+		}
+		if status.Status != models.AppliedPlanStatus {
+			held = append(held, pc)
+			continue
+		}
+		promoted := cmd.PromoteCanary || cmd.canaryPromotion != ""
+		if !promoted {
+			canaryRun, err := a.CanaryStore.Get(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, pc.RepoRelDir)
+			if err != nil {
+				ctx.Log.Err("reading the canary of dir %s: %s", pc.RepoRelDir, err)
+			}
+			promoted = canaryRun != nil && (canaryRun.Promoted || (canaryRun.SoakTime > 0 && !time.Now().Before(canaryRun.PromoteAt())))
+		}
+		if promoted {
+			run = append(run, pc)
+		} else {
+			held = append(held, pc)
+		}
+	}
+	return run, held
+}
+
+// recordCanaries records the canaries applied by result and the dirs whose
+// other projects it applied, and updates the progress comment of each dir
+// whose projects were applied or held.
+func (a *ApplyCommandRunner) recordCanaries(ctx *command.Context, run []command.ProjectContext, held []command.ProjectContext, result command.Result, pullStatus models.PullStatus) {
+	if a.CanaryStore == nil {
+		return
+	}
+
+	// The dirs whose canary was applied or whose projects were promoted or
+	// held, with one of their projects.
+	dirs := make(map[string]command.ProjectContext)
+	var order []string
+	for _, pc := range append(append([]command.ProjectContext{}, run...), held...) {
+		if pc.Canary == nil || dirs[pc.RepoRelDir].Canary != nil {
+			continue
+		}
+		if pc.IsCanary() || canaryProjectStatus(&pullStatus, pc) != nil {
+			dirs[pc.RepoRelDir] = pc
+			order = append(order, pc.RepoRelDir)
+		}
+	}
+
+	for _, dir := range order {
+		canary := dirs[dir].Canary
+		canaryRun, err := a.CanaryStore.Get(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, dir)
+		if err != nil {
+			ctx.Log.Err("reading the canary of dir %s: %s", dir, err)
+			continue
+		}
+		if canaryRun == nil {
+			canaryRun = &models.CanaryRun{
+				Repository:  ctx.Pull.BaseRepo.FullName,
+				PullNum:     ctx.Pull.Num,
+				RepoRelDir:  dir,
+				ProjectName: canary.ProjectName,
+				Workspace:   canary.Workspace,
+			}
+		}
+		for _, r := range result.ProjectResults {
+			if r.RepoRelDir != dir || !r.IsSuccessful() {
+				continue
+			}
+			if r.ProjectName == canary.ProjectName && r.Workspace == canary.Workspace {
+				// The canary was applied again so its soak starts over.
+				canaryRun.AppliedAt = time.Now()
+				canaryRun.SoakTime = canary.SoakTime
+				canaryRun.Promoted = false
+				canaryRun.Pull = ctx.Pull
+				canaryRun.HeadRepo = ctx.HeadRepo
+				canaryRun.User = ctx.User
+			} else {
+				canaryRun.Promoted = true
+			}
+		}
+		a.updateCanaryComment(ctx, canaryRun, pullStatus)
+		if err := a.CanaryStore.Save(*canaryRun); err != nil {
+			ctx.Log.Err("saving the canary of dir %s: %s", dir, err)
+		}
+	}
+}
+
+// canaryProgressData is the data of the canary progress comment.
+type canaryProgressData struct {
+	models.CanaryRun
+	// Applied is true if the canary was applied.
+	Applied  bool
+	Projects []canaryProgressProject
+	// PromoteAt is when the other projects are applied automatically,
+	// formatted, or empty if they wait for a comment.
+	PromoteAt  string
+	PromoteCmd string
+}
+
+type canaryProgressProject struct {
+	models.ProjectStatus
+	Canary bool
+}
+
+// updateCanaryComment posts the progress of the projects of the dir of
+// canaryRun, updating the comment posted before if the VCS host supports it.
+func (a *ApplyCommandRunner) updateCanaryComment(ctx *command.Context, canaryRun *models.CanaryRun, pullStatus models.PullStatus) {
+	data := canaryProgressData{
+		CanaryRun:  *canaryRun,
+		Applied:    !canaryRun.AppliedAt.IsZero(),
+		PromoteCmd: fmt.Sprintf("atlantis apply --%s", promoteCanaryFlagLong),
+	}
+	if promoteAt := canaryRun.PromoteAt(); data.Applied && !promoteAt.IsZero() {
+		data.PromoteAt = promoteAt.UTC().Format("2006-01-02 15:04 MST")
+	}
+	for _, p := range pullStatus.Projects {
+		if p.RepoRelDir != canaryRun.RepoRelDir {
+			continue
+		}
+		project := canaryProgressProject{
+			ProjectStatus: p,
+			Canary:        p.ProjectName == canaryRun.ProjectName && p.Workspace == canaryRun.Workspace,
+		}
+		if project.Canary {
+			data.Projects = append([]canaryProgressProject{project}, data.Projects...)
+		} else {
+			data.Projects = append(data.Projects, project)
+		}
+	}
+	comment := a.pullUpdater.MarkdownRenderer.renderCanaryProgress(data)
+
+	updater, ok := a.CommentUpdaters[ctx.Pull.BaseRepo.VCSHost.Type]
+	if !ok {
+		if err := a.vcsClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment, command.Apply.String()); err != nil {
+			ctx.Log.Err("unable to comment on pull request: %s", err)
+		}
+		return
+	}
+	if canaryRun.CommentID != 0 {
+		err := updater.UpdateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, canaryRun.CommentID, comment)
+		if err == nil {
+			return
+		}
+		ctx.Log.Warn("unable to update the canary progress comment, posting a new one: %s", err)
+	}
+	commentID, err := updater.CreateUpdatableComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment)
+	if err != nil {
+		ctx.Log.Err("unable to comment on pull request: %s", err)
+		return
+	}
+	canaryRun.CommentID = commentID
+}
+
+// CanaryPromoter applies the projects held behind canaries whose soak time
+// elapsed. It runs periodically.
+type CanaryPromoter struct {
+	CanaryStore   CanaryStore
+	CommandRunner CommandRunner
+	Logger        logging.SimpleLogging
+}
+
+func (c *CanaryPromoter) Run() {
+	runs, err := c.CanaryStore.List()
+	if err != nil {
+		c.Logger.Err("listing canaries: %s", err)
+		return
+	}
+	now := time.Now()
+	for _, run := range runs {
+		if run.Promoted || run.SoakTime == 0 || now.Before(run.PromoteAt()) {
+			continue
+		}
+		// The run is marked promoted first so a failing apply isn't retried
+		// on every run. The projects can then be applied with a comment.
+		run.Promoted = true
+		if err := c.CanaryStore.Save(run); err != nil {
+			c.Logger.Err("saving the canary of dir %s of %s#%d: %s", run.RepoRelDir, run.Repository, run.PullNum, err)
+			continue
+		}
+		c.Logger.Info("applying the projects of dir %s of %s#%d since its canary soaked for %s", run.RepoRelDir, run.Repository, run.PullNum, run.SoakTime)
+		cmd := CommentCommand{Name: command.Apply, canaryPromotion: run.RepoRelDir}
+		pull, headRepo := run.Pull, run.HeadRepo
+		c.CommandRunner.RunCommentCommand(pull.BaseRepo, &headRepo, &pull, run.User, run.PullNum, &cmd)
+	}
+}
@@ -0,0 +1,143 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// newTestRecordBackend returns a BoltDB in a temp dir to store records in.
+func newTestRecordBackend(t *testing.T) *db.BoltDB {
+	boltDB, err := db.New(t.TempDir())
+	Ok(t, err)
+	t.Cleanup(func() {
+		boltDB.Close()
+	})
+	return boltDB
+}
+
+func TestHoldForCanaries(t *testing.T) {
+	canary := &valid.ProjectCanary{Canary: valid.Canary{SoakTime: time.Hour}, Workspace: "us-east-1"}
+	usEast := command.ProjectContext{RepoRelDir: "network", Workspace: "us-east-1", Canary: canary}
+	euWest := command.ProjectContext{RepoRelDir: "network", Workspace: "eu-west-1", Canary: canary}
+	app := command.ProjectContext{RepoRelDir: "app", Workspace: "default"}
+	pull := models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}}
+	status := func(canaryStatus models.ProjectPlanStatus) *models.PullStatus {
+		return &models.PullStatus{Projects: []models.ProjectStatus{
+			{RepoRelDir: "network", Workspace: "us-east-1", Status: canaryStatus},
+			{RepoRelDir: "network", Workspace: "eu-west-1", Status: models.PlannedPlanStatus},
+		}}
+	}
+
+	cases := []struct {
+		description string
+		pullStatus  *models.PullStatus
+		cmd         CommentCommand
+		canaryRun   *models.CanaryRun
+		projectCmds []command.ProjectContext
+		expRun      []command.ProjectContext
+		expHeld     []command.ProjectContext
+	}{
+		{
+			description: "the canary is applied first",
+			pullStatus:  status(models.PlannedPlanStatus),
+			projectCmds: []command.ProjectContext{usEast, euWest, app},
+			expRun:      []command.ProjectContext{usEast, app},
+			expHeld:     []command.ProjectContext{euWest},
+		},
+		{
+			description: "held until the canary is applied",
+			pullStatus:  status(models.ErroredApplyStatus),
+			projectCmds: []command.ProjectContext{euWest},
+			expHeld:     []command.ProjectContext{euWest},
+		},
+		{
+			description: "held while the canary soaks",
+			pullStatus:  status(models.AppliedPlanStatus),
+			canaryRun:   &models.CanaryRun{AppliedAt: time.Now(), SoakTime: time.Hour},
+			projectCmds: []command.ProjectContext{euWest},
+			expHeld:     []command.ProjectContext{euWest},
+		},
+		{
+			description: "applied once the canary soaked",
+			pullStatus:  status(models.AppliedPlanStatus),
+			canaryRun:   &models.CanaryRun{AppliedAt: time.Now().Add(-2 * time.Hour), SoakTime: time.Hour},
+			projectCmds: []command.ProjectContext{euWest},
+			expRun:      []command.ProjectContext{euWest},
+		},
+		{
+			description: "applied when promoted with a comment",
+			pullStatus:  status(models.AppliedPlanStatus),
+			cmd:         CommentCommand{PromoteCanary: true},
+			canaryRun:   &models.CanaryRun{AppliedAt: time.Now(), SoakTime: time.Hour},
+			projectCmds: []command.ProjectContext{euWest},
+			expRun:      []command.ProjectContext{euWest},
+		},
+		{
+			description: "not held if the pull request doesn't change the canary",
+			pullStatus:  &models.PullStatus{},
+			projectCmds: []command.ProjectContext{euWest},
+			expRun:      []command.ProjectContext{euWest},
+		},
+		{
+			description: "a promotion only applies the held projects of its dir",
+			pullStatus:  status(models.AppliedPlanStatus),
+			cmd:         CommentCommand{canaryPromotion: "network"},
+			projectCmds: []command.ProjectContext{usEast, euWest, app},
+			expRun:      []command.ProjectContext{euWest},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			store := NewCanaryStore(newTestRecordBackend(t))
+			if c.canaryRun != nil {
+				c.canaryRun.Repository, c.canaryRun.PullNum, c.canaryRun.RepoRelDir = "owner/repo", 1, "network"
+				Ok(t, store.Save(*c.canaryRun))
+			}
+			a := &ApplyCommandRunner{CanaryStore: store}
+			ctx := &command.Context{Log: logging.NewNoopLogger(t), Pull: pull, PullStatus: c.pullStatus}
+			run, held := a.holdForCanaries(ctx, &c.cmd, c.projectCmds)
+			Equals(t, c.expRun, run)
+			Equals(t, c.expHeld, held)
+		})
+	}
+}
+
+// recordingCommandRunner records the comment commands it's asked to run.
+type recordingCommandRunner struct {
+	CommandRunner
+	cmds []CommentCommand
+}
+
+func (r *recordingCommandRunner) RunCommentCommand(_ models.Repo, _ *models.Repo, _ *models.PullRequest, _ models.User, _ int, cmd *CommentCommand) {
+	r.cmds = append(r.cmds, *cmd)
+}
+
+func TestCanaryPromoter_Run(t *testing.T) {
+	store := NewCanaryStore(newTestRecordBackend(t))
+	soaked := models.CanaryRun{Repository: "owner/repo", PullNum: 1, RepoRelDir: "network", AppliedAt: time.Now().Add(-2 * time.Hour), SoakTime: time.Hour}
+	soaking := models.CanaryRun{Repository: "owner/repo", PullNum: 1, RepoRelDir: "app", AppliedAt: time.Now(), SoakTime: time.Hour}
+	manual := models.CanaryRun{Repository: "owner/repo", PullNum: 2, RepoRelDir: "network", AppliedAt: time.Now().Add(-2 * time.Hour)}
+	for _, run := range []models.CanaryRun{soaked, soaking, manual} {
+		Ok(t, store.Save(run))
+	}
+
+	runner := &recordingCommandRunner{}
+	promoter := &CanaryPromoter{CanaryStore: store, CommandRunner: runner, Logger: logging.NewNoopLogger(t)}
+	promoter.Run()
+	Equals(t, []CommentCommand{{Name: command.Apply, canaryPromotion: "network"}}, runner.cmds)
+
+	run, err := store.Get("owner/repo", 1, "network")
+	Ok(t, err)
+	Assert(t, run.Promoted, "expected the soaked canary to be promoted")
+
+	// Promoted canaries aren't promoted again.
+	promoter.Run()
+	Equals(t, 1, len(runner.cmds))
+}
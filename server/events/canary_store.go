@@ -0,0 +1,62 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// CanaryStore stores the canaries applied in pull requests until the other
+// projects of their dir are promoted.
+type CanaryStore interface {
+	// Save stores run, replacing the run of the same dir of the pull request.
+	Save(run models.CanaryRun) error
+	// Get returns the run of the canary of dir in the pull request pullNum
+	// of the repo repoFullName or nil if it wasn't applied.
+	Get(repoFullName string, pullNum int, dir string) (*models.CanaryRun, error)
+	// List returns all the runs.
+	List() ([]models.CanaryRun, error)
+	// DeleteForPull deletes the runs of the pull request pullNum of the repo
+	// repoFullName once it's closed.
+	DeleteForPull(repoFullName string, pullNum int) error
+}
+
+// DefaultCanaryStore stores canary runs in the locking backend so soak
+// times survive restarts and are shared by the Atlantis instances.
+type DefaultCanaryStore struct {
+	runs *locking.RecordStore[models.CanaryRun]
+}
+
+// NewCanaryStore returns a CanaryStore that stores canary runs in backend.
+func NewCanaryStore(backend locking.RecordBackend) *DefaultCanaryStore {
+	return &DefaultCanaryStore{runs: locking.NewRecordStore[models.CanaryRun](backend, "canary-runs")}
+}
+
+func (f *DefaultCanaryStore) Save(run models.CanaryRun) error {
+	return f.runs.Put(run, func(r models.CanaryRun) bool {
+		return r.Repository == run.Repository && r.PullNum == run.PullNum && r.RepoRelDir == run.RepoRelDir
+	})
+}
+
+func (f *DefaultCanaryStore) Get(repoFullName string, pullNum int, dir string) (*models.CanaryRun, error) {
+	runs, err := f.runs.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range runs {
+		if r.Repository == repoFullName && r.PullNum == pullNum && r.RepoRelDir == dir {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *DefaultCanaryStore) List() ([]models.CanaryRun, error) {
+	return f.runs.List()
+}
+
+func (f *DefaultCanaryStore) DeleteForPull(repoFullName string, pullNum int) error {
+	_, err := f.runs.DeleteFunc(func(r models.CanaryRun) bool {
+		return r.Repository == repoFullName && r.PullNum == pullNum
+	})
+	return err
+}
@@ -0,0 +1,41 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCanaryStore(t *testing.T) {
+	store := events.NewCanaryStore(newTestRecordBackend(t))
+	appliedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	network := models.CanaryRun{Repository: "owner/repo", PullNum: 1, RepoRelDir: "network", Workspace: "us-east-1", AppliedAt: appliedAt, SoakTime: time.Hour}
+	app := models.CanaryRun{Repository: "owner/repo", PullNum: 1, RepoRelDir: "app", Workspace: "staging"}
+	other := models.CanaryRun{Repository: "owner/repo", PullNum: 2, RepoRelDir: "network", Workspace: "us-east-1"}
+	Ok(t, store.Save(network))
+	Ok(t, store.Save(app))
+	Ok(t, store.Save(other))
+
+	run, err := store.Get("owner/repo", 1, "network")
+	Ok(t, err)
+	Equals(t, &network, run)
+	Equals(t, appliedAt.Add(time.Hour), run.PromoteAt())
+	run, err = store.Get("owner/repo", 1, "db")
+	Ok(t, err)
+	Assert(t, run == nil, "expected no canary for dir db")
+
+	// Saving a run of the same dir replaces it.
+	network.Promoted = true
+	Ok(t, store.Save(network))
+	runs, err := store.List()
+	Ok(t, err)
+	Equals(t, []models.CanaryRun{network, app, other}, runs)
+
+	Ok(t, store.DeleteForPull("owner/repo", 1))
+	runs, err = store.List()
+	Ok(t, err)
+	Equals(t, []models.CanaryRun{other}, runs)
+}
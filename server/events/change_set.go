@@ -0,0 +1,298 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ApplyRequirementsChecker checks whether the plans of a pull request can be
+// applied without applying them.
+type ApplyRequirementsChecker interface {
+	// CheckApplyRequirements returns a failure if applying the pull request
+	// of ctx with cmd would be rejected.
+	CheckApplyRequirements(ctx *command.Context, cmd *CommentCommand) (string, error)
+}
+
+// changeSetMemberRun is the pull request of a change set member that a
+// command runs on.
+type changeSetMemberRun struct {
+	member   models.ChangeSetMember
+	repo     models.Repo
+	headRepo *models.Repo
+	pull     *models.PullRequest
+	// status is what's shown for the member in the change set comment.
+	status string
+}
+
+// findChangeSet returns the change set that cmd runs on or nil if it only runs
+// on the pull request of ctx. Only plans and applies of all projects run on
+// change sets.
+func (c *DefaultCommandRunner) findChangeSet(ctx *command.Context, cmd *CommentCommand) *models.ChangeSet {
+	if c.ChangeSets == nil || cmd.inChangeSet || cmd.IsForSpecificProject() {
+		return nil
+	}
+	if cmd.Name != command.Plan && cmd.Name != command.Apply {
+		return nil
+	}
+	changeSet, err := c.ChangeSets.Find(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	if err != nil {
+		ctx.Log.Err("finding change set: %s", err)
+		return nil
+	}
+	return changeSet
+}
+
+// runChangeSetCommand runs cmd on every member of changeSet in order and
+// comments the combined result on each of them. Applies only start once all
+// members can be applied and stop at the first member that fails.
+func (c *DefaultCommandRunner) runChangeSetCommand(ctx *command.Context, cmd *CommentCommand, changeSet models.ChangeSet) {
+	ctx.Log.Info("running %s on change set %q", cmd.Name.String(), changeSet.Name)
+	runs := c.changeSetMemberRuns(ctx, changeSet)
+
+	if cmd.Name == command.Apply && !c.checkChangeSetApply(ctx, cmd, runs) {
+		c.commentChangeSet(ctx, runs, renderChangeSet(changeSet, "can't be applied until all its pull requests can be. None of them were applied.", runs))
+		return
+	}
+
+	stopped := false
+	for i := range runs {
+		run := &runs[i]
+		if run.status != "" {
+			continue
+		}
+		if stopped {
+			run.status = "not applied"
+			continue
+		}
+		memberCmd := *cmd
+		memberCmd.inChangeSet = true
+		if run.pull == nil {
+			// Only the comment that triggered the command gets a reaction.
+			memberCmd.CommentID = 0
+		}
+		c.RunCommentCommand(run.repo, run.headRepo, run.pull, ctx.User, run.member.PullNum, &memberCmd)
+
+		var ok bool
+		run.status, ok = c.changeSetMemberStatus(run, cmd.Name)
+		if cmd.Name == command.Apply && !ok {
+			stopped = true
+		}
+	}
+
+	summary := fmt.Sprintf("ran %s on all its pull requests.", cmd.Name.String())
+	if stopped {
+		summary = "stopped applying at the first pull request that failed."
+	}
+	c.commentChangeSet(ctx, runs, renderChangeSet(changeSet, summary, runs))
+}
+
+// changeSetMemberRuns returns the runs of the members of changeSet. The repos
+// of members that can't be found have their error as status.
+func (c *DefaultCommandRunner) changeSetMemberRuns(ctx *command.Context, changeSet models.ChangeSet) []changeSetMemberRun {
+	runs := make([]changeSetMemberRun, len(changeSet.Members))
+	for i, member := range changeSet.Members {
+		runs[i].member = member
+		if member.Is(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num) {
+			runs[i].repo = ctx.Pull.BaseRepo
+			runs[i].headRepo = &ctx.HeadRepo
+			runs[i].pull = &ctx.Pull
+			continue
+		}
+		if member.Type == models.BitbucketCloud.String() || member.Type == models.BitbucketServer.String() {
+			// Bitbucket pull requests can't be fetched so they're only run
+			// by comments on them.
+			runs[i].status = "Bitbucket pull requests of a change set are only run by comments on them."
+			continue
+		}
+		repo, err := c.changeSetRepo(member)
+		if err != nil {
+			ctx.Log.Err("finding repo of change set member %s: %s", member, err)
+			runs[i].status = fmt.Sprintf("Error: %s", err)
+			continue
+		}
+		runs[i].repo = repo
+	}
+	return runs
+}
+
+// changeSetRepo returns the repo of member.
+func (c *DefaultCommandRunner) changeSetRepo(member models.ChangeSetMember) (models.Repo, error) {
+	vcsHostType, err := models.NewVCSHostType(member.Type)
+	if err != nil {
+		return models.Repo{}, err
+	}
+	cloneURL, err := c.VCSClient.GetCloneURL(c.Logger, vcsHostType, member.Repository)
+	if err != nil {
+		return models.Repo{}, err
+	}
+	return c.EventParser.ParseAPIPlanRequest(vcsHostType, member.Repository, cloneURL)
+}
+
+// checkChangeSetApply returns true if every member of the change set can be
+// applied. Members that were already applied, ex. before an earlier change
+// set apply stopped, are skipped. The status of the other runs is set to
+// what blocks them.
+func (c *DefaultCommandRunner) checkChangeSetApply(ctx *command.Context, cmd *CommentCommand, runs []changeSetMemberRun) bool {
+	ok := true
+	for i := range runs {
+		run := &runs[i]
+		if run.status != "" {
+			ok = false
+			continue
+		}
+		pullStatus, err := c.PullStatusFetcher.GetPullStatus(models.PullRequest{Num: run.member.PullNum, BaseRepo: run.repo})
+		if err != nil {
+			ctx.Log.Warn("fetching pull status of change set member %s: %s", run.member, err)
+		}
+		if pullStatus != nil && allProjectsApplied(*pullStatus) {
+			run.status = "already applied"
+			continue
+		}
+		failure, err := c.checkChangeSetMemberApply(ctx, cmd, run, pullStatus)
+		if err != nil {
+			failure = fmt.Sprintf("Error: %s", err)
+		}
+		if failure != "" {
+			run.status = failure
+			ok = false
+		}
+	}
+	if ok {
+		// The checks only set the status of blocked members.
+		for i := range runs {
+			if runs[i].status != "already applied" {
+				runs[i].status = ""
+			}
+		}
+		return true
+	}
+	for i := range runs {
+		if runs[i].status == "" {
+			runs[i].status = "ready"
+		}
+	}
+	return false
+}
+
+// checkChangeSetMemberApply returns a failure if the member of run can't be
+// applied by the user of ctx.
+func (c *DefaultCommandRunner) checkChangeSetMemberApply(ctx *command.Context, cmd *CommentCommand, run *changeSetMemberRun, pullStatus *models.PullStatus) (string, error) {
+	user := ctx.User
+	if c.TeamAllowlistChecker != nil && c.TeamAllowlistChecker.HasRules() {
+		if err := c.fetchUserTeams(ctx.Log, run.repo, &user); err != nil {
+			return "", err
+		}
+		allowed, err := c.checkUserPermissions(run.repo, user, command.Apply.String())
+		if err != nil {
+			return "", err
+		}
+		if !allowed {
+			return fmt.Sprintf("User @%s doesn't have permission to apply it.", user.Username), nil
+		}
+	}
+
+	headRepo, pull := ctx.HeadRepo, ctx.Pull
+	if run.pull == nil {
+		var err error
+		headRepo, pull, err = c.ensureValidRepoMetadata(run.repo, nil, nil, user, run.member.PullNum, ctx.Log)
+		if err != nil {
+			return "", err
+		}
+	}
+	if pull.State != models.OpenPullState {
+		return "It's closed.", nil
+	}
+	memberCtx := &command.Context{
+		User:       user,
+		Log:        ctx.Log,
+		Pull:       pull,
+		PullStatus: pullStatus,
+		HeadRepo:   headRepo,
+		Scope:      ctx.Scope,
+		Trigger:    ctx.Trigger,
+	}
+	memberCmd := *cmd
+	memberCmd.inChangeSet = true
+	return c.ApplyRequirementsChecker.CheckApplyRequirements(memberCtx, &memberCmd)
+}
+
+// changeSetMemberStatus returns the status of the member of run after cmdName
+// ran on it and whether it succeeded.
+func (c *DefaultCommandRunner) changeSetMemberStatus(run *changeSetMemberRun, cmdName command.Name) (string, bool) {
+	pullStatus, err := c.PullStatusFetcher.GetPullStatus(models.PullRequest{Num: run.member.PullNum, BaseRepo: run.repo})
+	if err != nil {
+		return fmt.Sprintf("Error: %s", err), false
+	}
+	if pullStatus == nil || len(pullStatus.Projects) == 0 {
+		return "no projects", true
+	}
+	if cmdName == command.Apply {
+		return countProjectStatuses(*pullStatus), allProjectsApplied(*pullStatus)
+	}
+	for _, project := range pullStatus.Projects {
+		switch project.Status {
+		case models.ErroredPlanStatus, models.ErroredPolicyCheckStatus:
+			return countProjectStatuses(*pullStatus), false
+		}
+	}
+	return countProjectStatuses(*pullStatus), true
+}
+
+// commentChangeSet comments comment on every member of the change set whose
+// repo was found.
+func (c *DefaultCommandRunner) commentChangeSet(ctx *command.Context, runs []changeSetMemberRun, comment string) {
+	for _, run := range runs {
+		if run.repo.FullName == "" {
+			continue
+		}
+		if err := c.VCSClient.CreateComment(ctx.Log, run.repo, run.member.PullNum, comment, ""); err != nil {
+			ctx.Log.Err("unable to comment on change set member %s: %s", run.member, err)
+		}
+	}
+}
+
+// allProjectsApplied returns true if pullStatus has projects and all of them
+// were applied.
+func allProjectsApplied(pullStatus models.PullStatus) bool {
+	for _, project := range pullStatus.Projects {
+		if project.Status != models.AppliedPlanStatus {
+			return false
+		}
+	}
+	return len(pullStatus.Projects) > 0
+}
+
+// countProjectStatuses returns how many projects of pullStatus have each
+// status, ex. "2 applied, 1 apply_errored".
+func countProjectStatuses(pullStatus models.PullStatus) string {
+	var order []models.ProjectPlanStatus
+	counts := make(map[models.ProjectPlanStatus]int)
+	for _, project := range pullStatus.Projects {
+		if counts[project.Status] == 0 {
+			order = append(order, project.Status)
+		}
+		counts[project.Status]++
+	}
+	var parts []string
+	for _, status := range order {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[status], status.String()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderChangeSet renders the comment about a command on changeSet with the
+// status of each member.
+func renderChangeSet(changeSet models.ChangeSet, summary string, runs []changeSetMemberRun) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Change set `%s`** %s\n\n", changeSet.Name, summary)
+	b.WriteString("| # | Pull request | Status |\n")
+	b.WriteString("|---|--------------|--------|\n")
+	for i, run := range runs {
+		status := strings.ReplaceAll(strings.TrimSpace(run.status), "\n", " ")
+		fmt.Fprintf(&b, "| %d | %s | %s |\n", i+1, run.member, strings.ReplaceAll(status, "|", "\\|"))
+	}
+	return b.String()
+}
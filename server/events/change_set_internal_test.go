@@ -0,0 +1,26 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRenderChangeSet(t *testing.T) {
+	changeSet := models.ChangeSet{Name: "network"}
+	runs := []changeSetMemberRun{
+		{member: models.ChangeSetMember{Repository: "owner/network", PullNum: 1}, status: countProjectStatuses(models.PullStatus{
+			Projects: []models.ProjectStatus{{Status: models.AppliedPlanStatus}, {Status: models.AppliedPlanStatus}},
+		})},
+		{member: models.ChangeSetMember{Repository: "owner/app", PullNum: 2}, status: "Error: a | b"},
+		{member: models.ChangeSetMember{Repository: "owner/db", PullNum: 3}, status: "not applied"},
+	}
+	Equals(t, "**Change set `network`** stopped applying at the first pull request that failed.\n\n"+
+		"| # | Pull request | Status |\n"+
+		"|---|--------------|--------|\n"+
+		"| 1 | owner/network#1 | 2 applied |\n"+
+		"| 2 | owner/app#2 | Error: a \\| b |\n"+
+		"| 3 | owner/db#3 | not applied |\n",
+		renderChangeSet(changeSet, "stopped applying at the first pull request that failed.", runs))
+}
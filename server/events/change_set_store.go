@@ -0,0 +1,119 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ChangeSetStore stores the change sets grouping pull requests.
+type ChangeSetStore interface {
+	// Save creates changeSet, replacing any change set with the same name.
+	// A pull request can only be a member of one change set.
+	Save(changeSet models.ChangeSet) (models.ChangeSet, error)
+	// Delete deletes the change set named name. It returns false if there was
+	// no such change set.
+	Delete(name string) (bool, error)
+	// List returns the change sets, oldest first.
+	List() ([]models.ChangeSet, error)
+	// Find returns the change set the pull request pullNum of the repo
+	// repoFullName is a member of or nil if it isn't in one.
+	Find(repoFullName string, pullNum int) (*models.ChangeSet, error)
+}
+
+// DefaultChangeSetStore stores change sets in the locking backend so they
+// survive restarts and are shared by the Atlantis instances.
+type DefaultChangeSetStore struct {
+	changeSets *locking.RecordStore[models.ChangeSet]
+}
+
+// NewChangeSetStore returns a ChangeSetStore that stores change sets in
+// backend.
+func NewChangeSetStore(backend locking.RecordBackend) *DefaultChangeSetStore {
+	return &DefaultChangeSetStore{changeSets: locking.NewRecordStore[models.ChangeSet](backend, "change-sets")}
+}
+
+func (f *DefaultChangeSetStore) Save(changeSet models.ChangeSet) (models.ChangeSet, error) {
+	if err := validateChangeSet(changeSet); err != nil {
+		return changeSet, err
+	}
+	if changeSet.CreatedAt.IsZero() {
+		changeSet.CreatedAt = time.Now()
+	}
+	return changeSet, f.changeSets.Update(func(changeSets []models.ChangeSet) ([]models.ChangeSet, error) {
+		var updated []models.ChangeSet
+		for _, c := range changeSets {
+			if c.Name == changeSet.Name {
+				continue
+			}
+			for _, member := range changeSet.Members {
+				if findChangeSetMember(c, member.Repository, member.PullNum) >= 0 {
+					return nil, fmt.Errorf("%s is already a member of change set %q", member, c.Name)
+				}
+			}
+			updated = append(updated, c)
+		}
+		return append(updated, changeSet), nil
+	})
+}
+
+func (f *DefaultChangeSetStore) Delete(name string) (bool, error) {
+	deleted, err := f.changeSets.DeleteFunc(func(c models.ChangeSet) bool {
+		return c.Name == name
+	})
+	return deleted > 0, err
+}
+
+func (f *DefaultChangeSetStore) List() ([]models.ChangeSet, error) {
+	return f.changeSets.List()
+}
+
+func (f *DefaultChangeSetStore) Find(repoFullName string, pullNum int) (*models.ChangeSet, error) {
+	changeSets, err := f.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range changeSets {
+		if findChangeSetMember(c, repoFullName, pullNum) >= 0 {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// validateChangeSet returns an error if changeSet has no name, less than two
+// members or the same member twice.
+func validateChangeSet(changeSet models.ChangeSet) error {
+	if changeSet.Name == "" {
+		return errors.New("change set needs a name")
+	}
+	if len(changeSet.Members) < 2 {
+		return errors.New("change set needs at least two pull requests")
+	}
+	for i, member := range changeSet.Members {
+		if member.Repository == "" || member.PullNum <= 0 {
+			return fmt.Errorf("change set member %d needs a repository and pull request number", i+1)
+		}
+		if _, err := models.NewVCSHostType(member.Type); err != nil {
+			return fmt.Errorf("change set member %s: %w", member, err)
+		}
+		if findChangeSetMember(models.ChangeSet{Members: changeSet.Members[:i]}, member.Repository, member.PullNum) >= 0 {
+			return fmt.Errorf("%s is in the change set twice", member)
+		}
+	}
+	return nil
+}
+
+// findChangeSetMember returns the index of the pull request pullNum of the
+// repo repoFullName in the members of changeSet or -1 if it isn't a member.
+func findChangeSetMember(changeSet models.ChangeSet, repoFullName string, pullNum int) int {
+	for i, member := range changeSet.Members {
+		if member.Is(repoFullName, pullNum) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,94 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// newTestRecordBackend returns a BoltDB in a temp dir to store records in.
+func newTestRecordBackend(t *testing.T) *db.BoltDB {
+	boltDB, err := db.New(t.TempDir())
+	Ok(t, err)
+	t.Cleanup(func() {
+		boltDB.Close()
+	})
+	return boltDB
+}
+
+func TestChangeSetStore_SaveFindDelete(t *testing.T) {
+	store := events.NewChangeSetStore(newTestRecordBackend(t))
+	changeSet := models.ChangeSet{
+		Name: "network",
+		Members: []models.ChangeSetMember{
+			{Repository: "owner/network", Type: "Github", PullNum: 1},
+			{Repository: "owner/app", Type: "Github", PullNum: 2},
+		},
+	}
+	saved, err := store.Save(changeSet)
+	Ok(t, err)
+	Assert(t, !saved.CreatedAt.IsZero(), "expected CreatedAt to be set")
+
+	found, err := store.Find("owner/app", 2)
+	Ok(t, err)
+	Assert(t, found != nil, "expected to find the change set")
+	Equals(t, changeSet.Members, found.Members)
+
+	found, err = store.Find("owner/app", 1)
+	Ok(t, err)
+	Assert(t, found == nil, "expected no change set")
+
+	// A pull request can only be in one change set.
+	_, err = store.Save(models.ChangeSet{
+		Name: "other",
+		Members: []models.ChangeSetMember{
+			{Repository: "owner/app", Type: "Github", PullNum: 2},
+			{Repository: "owner/db", Type: "Github", PullNum: 3},
+		},
+	})
+	ErrContains(t, `owner/app#2 is already a member of change set "network"`, err)
+
+	deleted, err := store.Delete("network")
+	Ok(t, err)
+	Assert(t, deleted, "expected the change set to be deleted")
+	deleted, err = store.Delete("network")
+	Ok(t, err)
+	Assert(t, !deleted, "expected no change set to delete")
+	changeSets, err := store.List()
+	Ok(t, err)
+	Equals(t, 0, len(changeSets))
+}
+
+func TestChangeSetStore_SaveInvalid(t *testing.T) {
+	store := events.NewChangeSetStore(newTestRecordBackend(t))
+	cases := map[string]struct {
+		changeSet models.ChangeSet
+		expErr    string
+	}{
+		"no name": {
+			changeSet: models.ChangeSet{Members: []models.ChangeSetMember{{Repository: "a", Type: "Github", PullNum: 1}, {Repository: "b", Type: "Github", PullNum: 1}}},
+			expErr:    "change set needs a name",
+		},
+		"one member": {
+			changeSet: models.ChangeSet{Name: "x", Members: []models.ChangeSetMember{{Repository: "a", Type: "Github", PullNum: 1}}},
+			expErr:    "change set needs at least two pull requests",
+		},
+		"bad type": {
+			changeSet: models.ChangeSet{Name: "x", Members: []models.ChangeSetMember{{Repository: "a", Type: "Github", PullNum: 1}, {Repository: "b", Type: "Svn", PullNum: 1}}},
+			expErr:    `change set member b#1: "Svn" is not a valid type`,
+		},
+		"duplicate": {
+			changeSet: models.ChangeSet{Name: "x", Members: []models.ChangeSetMember{{Repository: "a", Type: "Github", PullNum: 1}, {Repository: "a", Type: "Github", PullNum: 1}}},
+			expErr:    "a#1 is in the change set twice",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := store.Save(c.changeSet)
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+}
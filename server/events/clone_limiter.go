@@ -0,0 +1,101 @@
+package events
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/logging"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// CloneLimiter limits how many git clones and fetches run against each VCS
+// host at the same time so a burst of plans doesn't saturate it. The clones
+// over the limit wait until one of the running clones of the host finishes.
+type CloneLimiter struct {
+	// MaxPerHost is the max number of clones and fetches of a VCS host that
+	// run at the same time. If 0 there's no limit.
+	MaxPerHost int
+
+	scope tally.Scope
+	mux   sync.Mutex
+	// hostSlots limit the clones running for each host to MaxPerHost.
+	hostSlots map[string]chan struct{}
+	// waiting counts the clones queued for each host.
+	waiting map[string]int
+}
+
+// defaultCloneHost is the pool of the clones and fetches whose VCS host isn't
+// known, e.g. because the clone URL can't be parsed.
+const defaultCloneHost = "default"
+
+// cloneHost returns the host the clones and fetches of cloneURL are limited
+// by. Every caller must key the limiter with it so the clones and fetches of a
+// host share one pool.
+func cloneHost(cloneURL string) string {
+	u, err := url.Parse(strings.TrimSpace(cloneURL))
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// NewCloneLimiter is a constructor.
+func NewCloneLimiter(maxPerHost int, scope tally.Scope) *CloneLimiter {
+	if scope == nil {
+		scope = tally.NoopScope
+	}
+	return &CloneLimiter{
+		MaxPerHost: maxPerHost,
+		scope:      scope.SubScope("git_clone"),
+		hostSlots:  make(map[string]chan struct{}),
+		waiting:    make(map[string]int),
+	}
+}
+
+// Acquire waits until a clone or fetch of host can start and returns the
+// function to call once it's done. Clones of an empty host share the default
+// pool. A nil limiter doesn't limit anything.
+func (l *CloneLimiter) Acquire(logger logging.SimpleLogging, host string) func() {
+	if l == nil || l.MaxPerHost <= 0 {
+		return func() {}
+	}
+	if host == "" {
+		host = defaultCloneHost
+	}
+	l.mux.Lock()
+	slots, ok := l.hostSlots[host]
+	if !ok {
+		slots = make(chan struct{}, l.MaxPerHost)
+		l.hostSlots[host] = slots
+	}
+	l.mux.Unlock()
+	scope := l.scope.Tagged(map[string]string{"host": host})
+
+	waitTime := scope.Timer("wait_time").Start()
+	select {
+	case slots <- struct{}{}:
+	default:
+		logger.Info("waiting for one of the %d running clones of %s to finish", l.MaxPerHost, host)
+		l.updateWaiting(scope, host, 1)
+		slots <- struct{}{}
+		l.updateWaiting(scope, host, -1)
+	}
+	waitTime.Stop()
+	scope.Gauge("running").Update(float64(len(slots)))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-slots
+			scope.Gauge("running").Update(float64(len(slots)))
+		})
+	}
+}
+
+func (l *CloneLimiter) updateWaiting(scope tally.Scope, host string, delta int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.waiting[host] += delta
+	scope.Gauge("queued").Update(float64(l.waiting[host]))
+}
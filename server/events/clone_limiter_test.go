@@ -0,0 +1,68 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+func TestCloneLimiter_QueuesClonesOverTheLimit(t *testing.T) {
+	scope := tally.NewTestScope("test", nil)
+	limiter := events.NewCloneLimiter(1, scope)
+	logger := logging.NewNoopLogger(t)
+
+	release := limiter.Acquire(logger, "bitbucket.example.com")
+	// Other hosts have their own limit.
+	limiter.Acquire(logger, "github.com")()
+
+	acquired := make(chan struct{})
+	go func() {
+		limiter.Acquire(logger, "bitbucket.example.com")()
+		close(acquired)
+	}()
+
+	queued := func() float64 {
+		gauge, ok := scope.Snapshot().Gauges()["test.git_clone.queued+host=bitbucket.example.com"]
+		if !ok {
+			return 0
+		}
+		return gauge.Value()
+	}
+	for i := 0; queued() != 1; i++ {
+		if i == 100 {
+			t.Fatal("expected the second clone to be queued")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-acquired:
+		t.Fatal("expected the second clone to wait for the first")
+	default:
+	}
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the second clone to start once the first finished")
+	}
+	Equals(t, float64(0), queued())
+	Equals(t, float64(0), scope.Snapshot().Gauges()["test.git_clone.running+host=bitbucket.example.com"].Value())
+	_, ok := scope.Snapshot().Timers()["test.git_clone.wait_time+host=bitbucket.example.com"]
+	Assert(t, ok, "expected the wait time to be recorded")
+}
+
+func TestCloneLimiter_NoLimit(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	var nilLimiter *events.CloneLimiter
+	nilLimiter.Acquire(logger, "github.com")()
+
+	limiter := events.NewCloneLimiter(0, nil)
+	for i := 0; i < 10; i++ {
+		defer limiter.Acquire(logger, "github.com")()
+	}
+}
@@ -15,6 +15,10 @@ const (
 
 	// Commands that are triggered by comments (ie. atlantis plan)
 	CommentTrigger
+
+	// Commands that are triggered by comments on issues rather than pull
+	// requests (ie. atlantis plan -p project in issue-ops mode)
+	IssueTrigger
 )
 
 // Context represents the context of a command that should be executed
@@ -42,6 +46,13 @@ type Context struct {
 	// ClearPolicyApproval is true if approval should be cleared on specified policies.
 	ClearPolicyApproval bool
 
+	// ConfirmDestroy is true if the apply was confirmed with --confirm-destroy.
+	ConfirmDestroy bool
+
+	// OverrideApplyWindow is true if the apply was run with
+	// --override-apply-window.
+	OverrideApplyWindow bool
+
 	Trigger Trigger
 
 	// API is true if plan/apply by API endpoints
@@ -52,4 +63,12 @@ type Context struct {
 
 	// Set true if there were any errors during the command execution
 	CommandHasErrors bool
+
+	// HookOutputVars are the output variables set by the pre workflow hooks
+	// of this command.
+	HookOutputVars map[string]string
+
+	// RepoCfgReview is the review of the repo config modified by the pull
+	// request, set when the projects of a plan are built.
+	RepoCfgReview *RepoCfgReview
 }
@@ -30,6 +30,14 @@ const (
 	Import
 	// State is a command to run terraform state rm
 	State
+	// ForceUnlockState is a command to run terraform force-unlock
+	ForceUnlockState
+	// Rollback is a command to plan a return to the previous state snapshot
+	Rollback
+	// Silence is a command to stop commenting the results of a project on a pull request
+	Silence
+	// Ping is a command to smoke test the webhook, comment and commit status integrations
+	Ping
 	// Adding more? Don't forget to update String() below
 )
 
@@ -47,12 +55,16 @@ var AllCommentCommands = []Name{
 	ApprovePolicies,
 	Import,
 	State,
+	ForceUnlockState,
+	Rollback,
+	Silence,
+	Ping,
 }
 
 // TitleString returns the string representation in title form.
-// ie. policy_check becomes Policy Check
+// ie. policy_check becomes Policy Check and force-unlock-state becomes Force Unlock State
 func (c Name) TitleString() string {
-	return cases.Title(language.English).String(strings.ReplaceAll(strings.ToLower(c.String()), "_", " "))
+	return cases.Title(language.English).String(strings.NewReplacer("_", " ", "-", " ").Replace(strings.ToLower(c.String())))
 }
 
 // String returns the string representation of c.
@@ -74,6 +86,14 @@ func (c Name) String() string {
 		return "import"
 	case State:
 		return "state"
+	case ForceUnlockState:
+		return "force-unlock-state"
+	case Rollback:
+		return "rollback"
+	case Silence:
+		return "silence"
+	case Ping:
+		return "ping"
 	}
 	return ""
 }
@@ -84,7 +104,9 @@ func (c Name) DefaultUsage() string {
 	case Import:
 		return "import ADDRESS ID"
 	case State:
-		return "state [rm ADDRESS...]"
+		return "state [rm ADDRESS...|list [ADDRESS...]|show ADDRESS]"
+	case ForceUnlockState:
+		return "force-unlock-state LOCK_ID"
 	default:
 		return c.String()
 	}
@@ -94,7 +116,7 @@ func (c Name) DefaultUsage() string {
 func (c Name) SubCommands() []string {
 	switch c {
 	case State:
-		return []string{"rm"}
+		return []string{"rm", "list", "show"}
 	default:
 		return nil
 	}
@@ -106,10 +128,17 @@ func (c Name) CommandArgCount(subCommand string) (*ArgCount, error) {
 	case Import:
 		return &ArgCount{2, 2}, nil // "atlantis import ADDRESS ID"
 	case State:
-		if subCommand == "rm" {
+		switch subCommand {
+		case "rm":
 			return &ArgCount{1, -1}, nil // "atlantis state rm ADDRESS..."
+		case "list":
+			return &ArgCount{0, -1}, nil // "atlantis state list [ADDRESS...]"
+		case "show":
+			return &ArgCount{1, 1}, nil // "atlantis state show ADDRESS"
 		}
 		return nil, fmt.Errorf("command arg count unknown sub command: %s", subCommand)
+	case ForceUnlockState:
+		return &ArgCount{1, 1}, nil // "atlantis force-unlock-state LOCK_ID"
 	default:
 		return &ArgCount{0, 0}, nil // other command doesn't require any args
 	}
@@ -149,6 +178,14 @@ func ParseCommandName(name string) (Name, error) {
 		return Import, nil
 	case "state":
 		return State, nil
+	case "force-unlock-state":
+		return ForceUnlockState, nil
+	case "rollback":
+		return Rollback, nil
+	case "silence":
+		return Silence, nil
+	case "ping":
+		return Ping, nil
 	}
 	return -1, fmt.Errorf("unknown command name: %s", name)
 }
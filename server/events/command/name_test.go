@@ -18,6 +18,7 @@ func TestName_TitleString(t *testing.T) {
 	}{
 		{command.Apply, "Apply"},
 		{command.PolicyCheck, "Policy Check"},
+		{command.ForceUnlockState, "Force Unlock State"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.want, func(t *testing.T) {
@@ -41,6 +42,10 @@ func TestName_String(t *testing.T) {
 		{command.Version, "version"},
 		{command.Import, "import"},
 		{command.State, "state"},
+		{command.ForceUnlockState, "force-unlock-state"},
+		{command.Rollback, "rollback"},
+		{command.Silence, "silence"},
+		{command.Ping, "ping"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.want, func(t *testing.T) {
@@ -63,7 +68,8 @@ func TestName_DefaultUsage(t *testing.T) {
 		{command.ApprovePolicies, "approve_policies"},
 		{command.Version, "version"},
 		{command.Import, "import ADDRESS ID"},
-		{command.State, "state [rm ADDRESS...]"},
+		{command.State, "state [rm ADDRESS...|list [ADDRESS...]|show ADDRESS]"},
+		{command.ForceUnlockState, "force-unlock-state LOCK_ID"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.c.String(), func(t *testing.T) {
@@ -86,7 +92,7 @@ func TestName_SubCommands(t *testing.T) {
 		{c: command.ApprovePolicies},
 		{c: command.Version},
 		{c: command.Import},
-		{c: command.State, want: []string{"rm"}},
+		{c: command.State, want: []string{"rm", "list", "show"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.c.String(), func(t *testing.T) {
@@ -112,7 +118,10 @@ func TestName_CommandArgCount(t *testing.T) {
 		{c: command.Version, want: &command.ArgCount{}},
 		{c: command.Import, want: &command.ArgCount{Min: 2, Max: 2}},
 		{c: command.State, subCommand: "rm", want: &command.ArgCount{Min: 1, Max: -1}},
+		{c: command.State, subCommand: "list", want: &command.ArgCount{Min: 0, Max: -1}},
+		{c: command.State, subCommand: "show", want: &command.ArgCount{Min: 1, Max: 1}},
 		{c: command.State, subCommand: "unknown", wantErr: true},
+		{c: command.ForceUnlockState, want: &command.ArgCount{Min: 1, Max: 1}},
 	}
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s %s", tt.c, tt.subCommand), func(t *testing.T) {
@@ -179,6 +188,10 @@ func TestParseCommandName(t *testing.T) {
 		{command.Version, "version"},
 		{command.Import, "import"},
 		{command.State, "state"},
+		{command.ForceUnlockState, "force-unlock-state"},
+		{command.Rollback, "rollback"},
+		{command.Silence, "silence"},
+		{command.Ping, "ping"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -53,6 +53,24 @@ type ProjectContext struct {
 	// by adding a \ before each character so that they can be used within
 	// sh -c safely, i.e. sh -c "terraform plan $(touch bad)".
 	EscapedCommentArgs []string
+	// ExtraArgs are the default extra arguments of the built-in steps from
+	// the repo config, keyed by step name. They're escaped like
+	// EscapedCommentArgs and passed before them.
+	ExtraArgs map[string][]string
+	// PRMetadataVars is how the pull request's metadata is passed to
+	// terraform as variables, valid.PRMetadataVarsEnv or
+	// valid.PRMetadataVarsTFVars. If empty it isn't passed.
+	PRMetadataVars string
+	// ShareOutputs is true if the outputs of the project are stored after
+	// each apply for the projects depending on it.
+	ShareOutputs bool
+	// DependencyOutputs is how the shared outputs of the projects in
+	// DependsOn are passed to terraform as variables, like PRMetadataVars.
+	// If empty they aren't passed.
+	DependencyOutputs string
+	// HookOutputVars are the output variables set by pre workflow hooks. They
+	// are passed to the steps of the workflow as env vars.
+	HookOutputVars map[string]string
 	// HeadRepo is the repository that is getting merged into the BaseRepo.
 	// If the pull request branch is from the same repository then HeadRepo will
 	// be the same as BaseRepo.
@@ -93,6 +111,13 @@ type ProjectContext struct {
 	// Steps are the sequence of commands we need to run for this project and this
 	// stage.
 	Steps []valid.Step
+	// PostPlanHooks and PostApplyHooks are run in the project's directory
+	// after its plan or apply steps, whether they succeeded or not.
+	PostPlanHooks  []*valid.WorkflowHook
+	PostApplyHooks []*valid.WorkflowHook
+	// Notifications are where the project's apply results are sent in
+	// addition to the server's webhooks.
+	Notifications []valid.Notification
 	// TerraformDistribution is the distribution of terraform we should use when
 	// executing commands for this project. This can be set to nil in which case
 	// we will use the default Atlantis terraform distribution.
@@ -105,6 +130,60 @@ type ProjectContext struct {
 	User models.User
 	// Verbose is true when the user would like verbose output.
 	Verbose bool
+	// Targets are the resource addresses the plan or apply is limited to. If
+	// empty then the whole project is planned or applied.
+	Targets []string
+	// DestroyGuard requires applies of plans that delete or replace protected
+	// resources to be confirmed. It's nil if the repo doesn't have one.
+	DestroyGuard *valid.DestroyGuard
+	// ConfirmDestroy is true if the user confirmed the apply with
+	// --confirm-destroy.
+	ConfirmDestroy bool
+	// CostBudget blocks applies of plans whose cost estimate increases the
+	// monthly cost too much. It's nil if the repo doesn't have one.
+	CostBudget *valid.CostBudget
+	// RiskScoring scores the plan and escalates the approvals required to
+	// apply it. It's nil if the repo doesn't have one.
+	RiskScoring *valid.RiskScoring
+	// AutoApproval lets low-risk plans be applied without approval. It's nil
+	// if the repo doesn't have auto-approval rules.
+	AutoApproval *valid.AutoApproval
+	// AutoApplied is true if Atlantis applies the project on its own because
+	// its low-risk plan wasn't applied within the auto-approval's delay.
+	AutoApplied bool
+	// Rollback sets the approvals needed to apply rollback plans. It's nil if
+	// the repo doesn't allow the rollback command.
+	Rollback *valid.Rollback
+	// ApplyWindows are the periods the project may be applied in. If empty it
+	// may be applied at any time.
+	ApplyWindows []valid.ApplyWindow
+	// LockKeys are shared locks held while the project is applied.
+	LockKeys []string
+	// Labels are the labels of the project, ex. tier: prod.
+	Labels map[string]string
+	// Canary is the canary of the project's dir, which may be the project
+	// itself. It's nil if the dir has none.
+	Canary *valid.ProjectCanary
+	// OverrideApplyWindow is true if the apply was run with
+	// --override-apply-window.
+	OverrideApplyWindow bool
+	// Tool is the tool the project is planned and applied with. If empty,
+	// it's a terraform project.
+	Tool string
+	// Owners are the users and teams that must run or approve plan and apply
+	// for this project. It's nil if the repo config doesn't set them.
+	Owners *valid.PolicyOwners
+	// CodeOwners is true if the owners of the project are read from the
+	// CODEOWNERS file when Owners isn't set.
+	CodeOwners bool
+	// InlineReviewComments is true if the resources the plan deletes or
+	// replaces are commented on the lines of the diff that declare them.
+	InlineReviewComments bool
+	// CommandAllowlist and ProjectCommandAllowlist restrict the commands run
+	// on the project to users and teams, from the server-side config and the
+	// repo config respectively.
+	CommandAllowlist        []valid.CommandAllowlistRule
+	ProjectCommandAllowlist []valid.CommandAllowlistRule
 	// Workspace is the Terraform workspace this project is in. It will always
 	// be set.
 	Workspace string
@@ -130,6 +209,13 @@ type ProjectContext struct {
 	// Allows custom policy check tools outside of Conftest to run in checks
 	CustomPolicyCheck bool
 	SilencePRComments []string
+	// ImpactPreview is true if the project isn't modified by the pull request
+	// but consumes the outputs of a project that is. It's only planned to
+	// preview the impact of the pull request and its plan can't be applied.
+	ImpactPreview bool
+	// PreviewEnvironment makes the project a preview environment of the pull
+	// request. It's nil if the project isn't one.
+	PreviewEnvironment *valid.PreviewEnvironment
 
 	// TeamAllowlistChecker is used to check authorization on a project-level
 	TeamAllowlistChecker TeamAllowlistChecker
@@ -163,6 +249,16 @@ func (p ProjectContext) GetShowResultFileName() string {
 	return fmt.Sprintf("%s-%s.json", projName, p.Workspace)
 }
 
+// GetOutputsResultFileName returns the filename (not the path) to store the
+// tf output result after an apply.
+func (p ProjectContext) GetOutputsResultFileName() string {
+	if p.ProjectName == "" {
+		return fmt.Sprintf("%s-outputs.json", p.Workspace)
+	}
+	projName := strings.Replace(p.ProjectName, "/", planfileSlashReplace, -1)
+	return fmt.Sprintf("%s-%s-outputs.json", projName, p.Workspace)
+}
+
 // GetPolicyCheckResultFileName returns the filename (not the path) to store the result from conftest_client.
 func (p ProjectContext) GetPolicyCheckResultFileName() string {
 	if p.ProjectName == "" {
@@ -172,6 +268,26 @@ func (p ProjectContext) GetPolicyCheckResultFileName() string {
 	return fmt.Sprintf("%s-%s-policyout.json", projName, p.Workspace)
 }
 
+// GetProvidersSchemaFileName returns the filename (not the path) to store
+// the result of the providers_schema step.
+func (p ProjectContext) GetProvidersSchemaFileName() string {
+	if p.ProjectName == "" {
+		return fmt.Sprintf("%s-providers-schema.json", p.Workspace)
+	}
+	projName := strings.Replace(p.ProjectName, "/", planfileSlashReplace, -1)
+	return fmt.Sprintf("%s-%s-providers-schema.json", projName, p.Workspace)
+}
+
+// GetCostEstimateFileName returns the filename (not the path) a custom run
+// step writes the cost estimate of the plan to, in Infracost's JSON format.
+func (p ProjectContext) GetCostEstimateFileName() string {
+	if p.ProjectName == "" {
+		return fmt.Sprintf("%s-cost.json", p.Workspace)
+	}
+	projName := strings.Replace(p.ProjectName, "/", planfileSlashReplace, -1)
+	return fmt.Sprintf("%s-%s-cost.json", projName, p.Workspace)
+}
+
 // Gets a unique identifier for the current pull request as a single string
 func (p ProjectContext) PullInfo() string {
 	normalizedOwner := strings.ReplaceAll(p.BaseRepo.Owner, "/", "-")
@@ -212,3 +328,8 @@ func (p ProjectContext) PolicyCleared() bool {
 	}
 	return passing
 }
+
+// IsCanary returns true if the project is the canary of its dir.
+func (p ProjectContext) IsCanary() bool {
+	return p.Canary != nil && p.Canary.ProjectName == p.ProjectName && p.Canary.Workspace == p.Workspace
+}
@@ -18,8 +18,31 @@ type ProjectResult struct {
 	VersionSuccess     string
 	ImportSuccess      *models.ImportSuccess
 	StateRmSuccess     *models.StateRmSuccess
-	ProjectName        string
-	SilencePRComments  []string
+	// StateReadSuccess is the output of a successful terraform state list or
+	// state show.
+	StateReadSuccess string
+	// ForceUnlockStateSuccess is the output of a successful terraform force-unlock.
+	ForceUnlockStateSuccess string
+	ProjectName             string
+	SilencePRComments       []string
+	// Annotations is markdown added by lifecycle plugins that's rendered
+	// after the project's output.
+	Annotations []string
+	// AuthFailure is set if the command failed because the provider rejected
+	// the credentials Atlantis runs it with.
+	AuthFailure *AuthFailure
+	// Labels are the labels of the project, ex. tier: prod.
+	Labels map[string]string
+}
+
+// AuthFailure is a provider authentication failure.
+type AuthFailure struct {
+	// Provider is the provider that rejected the credentials, ex. AWS.
+	Provider string
+	// Reason describes the failure.
+	Reason string
+	// Hint is posted with the output of the command.
+	Hint string
 }
 
 // CommitStatus returns the vcs commit status of this project result.
@@ -53,7 +76,7 @@ func (p ProjectResult) PolicyStatus() []models.PolicySetStatus {
 func (p ProjectResult) PlanStatus() models.ProjectPlanStatus {
 	switch p.Command {
 
-	case Plan:
+	case Plan, Rollback:
 		if p.Error != nil {
 			return models.ErroredPlanStatus
 		} else if p.Failure != "" {
@@ -9,6 +9,30 @@ type Result struct {
 	// deleted. This happens if automerging is enabled and one project has an
 	// error since automerging requires all plans to succeed.
 	PlansDeleted bool
+	// ImpactPreviews are the results of the plans of the projects that were
+	// only planned to preview the impact of the pull request. They're not
+	// part of ProjectResults so they don't count towards the command's
+	// status.
+	ImpactPreviews []ProjectResult
+	// RepoCfgReview reviews the repo config modified by the pull request. It's
+	// nil unless the command is a plan of a pull request modifying it.
+	RepoCfgReview *RepoCfgReview
+}
+
+// RepoCfgReview is the validation result of the repo config modified by a
+// pull request and how it changes the projects.
+type RepoCfgReview struct {
+	// File is the modified repo config file, ex. atlantis.yaml.
+	File string
+	// Error is why the modified repo config is invalid or not allowed by the
+	// server-side repo config. If it's set, nothing was planned.
+	Error string
+	// Compared is false if the repo config of the base branch couldn't be
+	// read, so Changes is empty.
+	Compared bool
+	// Changes describe how the modified repo config changes the projects,
+	// ex. "project `app` added".
+	Changes []string
 }
 
 // HasErrors returns true if there were any errors during the execution,
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// checkCommandAllowlist returns a failure if the command allowlist of the
+// server-side config or of the project restricts the command to users and
+// teams that don't include the user who ran it. Both allowlists must allow
+// the command, so the repo config can only restrict commands further.
+func (p *DefaultProjectCommandRunner) checkCommandAllowlist(ctx command.ProjectContext) (string, error) {
+	cmdName := ctx.CommandName.String()
+	for _, rules := range [][]valid.CommandAllowlistRule{ctx.CommandAllowlist, ctx.ProjectCommandAllowlist} {
+		allowed := valid.CommandAllowlistFor(rules, cmdName, ctx.RepoRelDir)
+		if allowed == nil {
+			continue
+		}
+		isAllowed, err := p.isProjectOwner(ctx, *allowed, ctx.User)
+		if err != nil {
+			return "", err
+		}
+		if !isAllowed {
+			return commandNotAllowedFailure(ctx.User.Username, cmdName, *allowed), nil
+		}
+	}
+	return "", nil
+}
+
+// commandNotAllowedFailure is the failure commented when user isn't allowed
+// to run cmdName.
+func commandNotAllowedFailure(user string, cmdName string, allowed valid.PolicyOwners) string {
+	var names []string
+	for _, u := range allowed.Users {
+		names = append(names, "@"+u)
+	}
+	names = append(names, allowed.Teams...)
+	return fmt.Sprintf("@%s is not allowed to run %s on this project, only %s can.", user, cmdName, strings.Join(names, ", "))
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// CommandDeduplicator tracks the comment commands that are queued or running
+// so identical commands commented on the same pull request, ex. when a user
+// comments `atlantis plan` twice or a bot echoes a command, only run once.
+type CommandDeduplicator struct {
+	mux sync.Mutex
+	// inFlight are the commands that haven't finished, by commandKey.
+	inFlight map[string]InFlightCommand
+	// now is overridden in tests.
+	now func() time.Time
+}
+
+// InFlightCommand is a command that is queued or running.
+type InFlightCommand struct {
+	// User is the user who commented the command.
+	User models.User
+	// CommentID is the ID of the comment the command was parsed from.
+	CommentID int64
+	StartedAt time.Time
+}
+
+// NewCommandDeduplicator is a constructor.
+func NewCommandDeduplicator() *CommandDeduplicator {
+	return &CommandDeduplicator{
+		inFlight: make(map[string]InFlightCommand),
+		now:      time.Now,
+	}
+}
+
+// Start marks cmd as in flight on pull. If an identical command is already in
+// flight on the same commit of pull, it returns that command and false.
+// Otherwise the returned func must be called once cmd finishes.
+func (d *CommandDeduplicator) Start(pull models.PullRequest, user models.User, cmd *CommentCommand) (func(), InFlightCommand, bool) {
+	key := commandKey(pull, cmd)
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if existing, ok := d.inFlight[key]; ok {
+		return nil, existing, false
+	}
+	d.inFlight[key] = InFlightCommand{
+		User:      user,
+		CommentID: cmd.CommentID,
+		StartedAt: d.now(),
+	}
+	return func() {
+		d.mux.Lock()
+		defer d.mux.Unlock()
+		delete(d.inFlight, key)
+	}, InFlightCommand{}, true
+}
+
+// DuplicateComment is the comment made on a pull request when a command is
+// skipped because an identical one is in flight.
+func (d *CommandDeduplicator) DuplicateComment(cmd *CommentCommand, inFlight InFlightCommand) string {
+	target := "this pull request"
+	switch {
+	case cmd.ProjectName != "":
+		target = fmt.Sprintf("project `%s`", cmd.ProjectName)
+	case cmd.RepoRelDir != "":
+		target = fmt.Sprintf("dir `%s`", cmd.RepoRelDir)
+	}
+	return fmt.Sprintf("Skipping this `%s` command since an identical one for %s, commented by @%s %s ago, is still queued or running. Its results will be commented once it finishes.",
+		cmd.CommandName(), target, inFlight.User.Username, d.now().Sub(inFlight.StartedAt).Round(time.Second))
+}
+
+// commandKey identifies cmd on the head commit of pull. Every option of the
+// command is part of the key, except the comment it was parsed from.
+func commandKey(pull models.PullRequest, cmd *CommentCommand) string {
+	c := *cmd
+	c.CommentID = 0
+	return fmt.Sprintf("%s#%d@%s:%#v", pull.BaseRepo.FullName, pull.Num, pull.HeadCommit, c)
+}
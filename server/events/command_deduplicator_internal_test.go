@@ -0,0 +1,61 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCommandDeduplicator_Start(t *testing.T) {
+	d := NewCommandDeduplicator()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	pull := models.PullRequest{Num: 1, HeadCommit: "abc", BaseRepo: models.Repo{FullName: "owner/repo"}}
+	alice := models.User{Username: "alice"}
+	bob := models.User{Username: "bob"}
+
+	done, _, ok := d.Start(pull, alice, &CommentCommand{Name: command.Plan, ProjectName: "app", CommentID: 1})
+	Assert(t, ok, "expected first command to start")
+
+	// The same command from another comment is a duplicate.
+	now = now.Add(42 * time.Second)
+	dupCmd := &CommentCommand{Name: command.Plan, ProjectName: "app", CommentID: 2}
+	_, inFlight, ok := d.Start(pull, bob, dupCmd)
+	Assert(t, !ok, "expected identical command to be skipped")
+	Equals(t, InFlightCommand{User: alice, CommentID: 1, StartedAt: now.Add(-42 * time.Second)}, inFlight)
+	Equals(t, "Skipping this `plan` command since an identical one for project `app`, commented by @alice 42s ago, is still queued or running. Its results will be commented once it finishes.",
+		d.DuplicateComment(dupCmd, inFlight))
+
+	// Commands that differ in any option, the pull or the commit aren't.
+	otherCmds := []struct {
+		pull models.PullRequest
+		cmd  *CommentCommand
+	}{
+		{pull, &CommentCommand{Name: command.Apply, ProjectName: "app"}},
+		{pull, &CommentCommand{Name: command.Plan, ProjectName: "db"}},
+		{pull, &CommentCommand{Name: command.Plan, ProjectName: "app", Flags: []string{"-refresh=false"}}},
+		{models.PullRequest{Num: 2, HeadCommit: "abc", BaseRepo: pull.BaseRepo}, &CommentCommand{Name: command.Plan, ProjectName: "app"}},
+		{models.PullRequest{Num: 1, HeadCommit: "def", BaseRepo: pull.BaseRepo}, &CommentCommand{Name: command.Plan, ProjectName: "app"}},
+	}
+	for _, c := range otherCmds {
+		otherDone, _, ok := d.Start(c.pull, bob, c.cmd)
+		Assert(t, ok, "expected %v on %v to start", c.cmd, c.pull)
+		otherDone()
+	}
+
+	// Once the command finishes it can run again.
+	done()
+	_, _, ok = d.Start(pull, bob, dupCmd)
+	Assert(t, ok, "expected command to start once the identical one finished")
+}
+
+func TestCommandDeduplicator_DuplicateComment(t *testing.T) {
+	d := NewCommandDeduplicator()
+	inFlight := InFlightCommand{User: models.User{Username: "alice"}, StartedAt: time.Now()}
+	Assert(t, strings.Contains(d.DuplicateComment(&CommentCommand{Name: command.Apply}, inFlight), "`apply` command since an identical one for this pull request"), "unexpected comment")
+	Assert(t, strings.Contains(d.DuplicateComment(&CommentCommand{Name: command.Plan, RepoRelDir: "infra"}, inFlight), "for dir `infra`"), "unexpected comment")
+}
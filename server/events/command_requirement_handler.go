@@ -9,6 +9,10 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 )
 
+// DivergedApplyFailure is the failure of an apply when the undiverged
+// requirement isn't met.
+const DivergedApplyFailure = "Default branch must be rebased onto pull request before running apply. Comment `atlantis plan` to merge the updated default branch and plan again."
+
 //go:generate pegomock generate --package mocks -o mocks/mock_command_requirement_handler.go CommandRequirementHandler
 type CommandRequirementHandler interface {
 	ValidateProjectDependencies(ctx command.ProjectContext) (string, error)
@@ -19,6 +23,9 @@ type CommandRequirementHandler interface {
 
 type DefaultCommandRequirementHandler struct {
 	WorkingDir WorkingDir
+	// GlobalCfgStore holds the server-side repo config, read for the apply
+	// requirement plugins.
+	GlobalCfgStore *valid.GlobalCfgStore
 }
 
 func (a *DefaultCommandRequirementHandler) ValidatePlanProject(repoDir string, ctx command.ProjectContext) (failure string, err error) {
@@ -61,7 +68,21 @@ func (a *DefaultCommandRequirementHandler) ValidateApplyProject(repoDir string,
 			}
 		case raw.UnDivergedRequirement:
 			if a.WorkingDir.HasDiverged(ctx.Log, repoDir) {
-				return "Default branch must be rebased onto pull request before running apply.", nil
+				return DivergedApplyFailure, nil
+			}
+		default:
+			name, ok := valid.ApplyRequirementPluginName(req)
+			if !ok {
+				continue
+			}
+			plugin, ok := a.GlobalCfgStore.Load().ApplyRequirementPlugins[name]
+			if !ok {
+				// The config was reloaded without the plugin since the
+				// project's requirements were read.
+				return fmt.Sprintf("Apply requirement plugin %q is not defined.", name), nil
+			}
+			if failure := checkApplyRequirementPlugin(ctx, repoDir, plugin); failure != "" {
+				return failure, nil
 			}
 		}
 	}
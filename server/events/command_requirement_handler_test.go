@@ -187,7 +187,7 @@ func TestAggregateApplyRequirements_ValidateApplyProject(t *testing.T) {
 			setup: func(workingDir *mocks.MockWorkingDir) {
 				When(workingDir.HasDiverged(Any[logging.SimpleLogging](), Any[string]())).ThenReturn(true)
 			},
-			wantFailure: "Default branch must be rebased onto pull request before running apply.",
+			wantFailure: "Default branch must be rebased onto pull request before running apply. Comment `atlantis plan` to merge the updated default branch and plan again.",
 			wantErr:     assert.NoError,
 		},
 	}
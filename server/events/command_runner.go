@@ -19,6 +19,7 @@ import (
 
 	"github.com/drmaxgit/go-azuredevops/azuredevops"
 	"github.com/google/go-github/v68/github"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events/command"
@@ -46,6 +47,10 @@ type CommandRunner interface {
 	// and then calling the appropriate services to finish executing the command.
 	RunCommentCommand(baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *CommentCommand)
 	RunAutoplanCommand(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User)
+	// RunIssueCommentCommand runs a command that was commented on an issue
+	// rather than a pull request. The command runs against defaultBranch and
+	// its results are posted back to the issue.
+	RunIssueCommentCommand(baseRepo models.Repo, defaultBranch string, user models.User, issueNum int, cmd *CommentCommand)
 }
 
 //go:generate pegomock generate github.com/runatlantis/atlantis/server/events --package mocks -o mocks/mock_github_pull_getter.go GithubPullGetter
@@ -56,6 +61,14 @@ type GithubPullGetter interface {
 	GetPullRequest(logger logging.SimpleLogging, repo models.Repo, pullNum int) (*github.PullRequest, error)
 }
 
+//go:generate pegomock generate github.com/runatlantis/atlantis/server/events --package mocks -o mocks/mock_github_branch_getter.go GithubBranchGetter
+
+// GithubBranchGetter makes API calls to get branches.
+type GithubBranchGetter interface {
+	// GetBranchHeadCommit gets the SHA of the commit at the head of branch.
+	GetBranchHeadCommit(logger logging.SimpleLogging, repo models.Repo, branch string) (string, error)
+}
+
 //go:generate pegomock generate github.com/runatlantis/atlantis/server/events --package mocks -o mocks/mock_azuredevops_pull_getter.go AzureDevopsPullGetter
 
 // AzureDevopsPullGetter makes API calls to get pull requests.
@@ -96,6 +109,7 @@ func buildCommentCommandRunner(
 type DefaultCommandRunner struct {
 	VCSClient                vcs.Client `validate:"required"`
 	GithubPullGetter         GithubPullGetter
+	GithubBranchGetter       GithubBranchGetter
 	AzureDevopsPullGetter    AzureDevopsPullGetter
 	GitlabMergeRequestGetter GitlabMergeRequestGetter
 	GiteaPullGetter          *gitea.GiteaClient
@@ -106,7 +120,7 @@ type DefaultCommandRunner struct {
 	// User config option: Fail and do not run the Atlantis command request if any of the pre workflow hooks error
 	FailOnPreWorkflowHookError bool
 	Logger                     logging.SimpleLogging `validate:"required"`
-	GlobalCfg                  valid.GlobalCfg       `validate:"required"`
+	GlobalCfgStore             *valid.GlobalCfgStore `validate:"required"`
 	StatsScope                 tally.Scope           `validate:"required"`
 	// User config option: controls whether to operate on pull requests from forks.
 	AllowForkPRs bool
@@ -131,6 +145,21 @@ type DefaultCommandRunner struct {
 	TeamAllowlistChecker           command.TeamAllowlistChecker          `validate:"required"`
 	VarFileAllowlistChecker        *VarFileAllowlistChecker              `validate:"required"`
 	CommitStatusUpdater            CommitStatusUpdater                   `validate:"required"`
+	// IssueOpsCleaner deletes the locks and plans created by issue-ops
+	// commands once they finish since they can never be applied.
+	IssueOpsCleaner PullCleaner
+	// ChangeSets, if set, holds the change sets whose pull requests are
+	// planned and applied together.
+	ChangeSets ChangeSetStore
+	// ApplyRequirementsChecker checks that every pull request of a change set
+	// can be applied before any of them is.
+	ApplyRequirementsChecker ApplyRequirementsChecker
+	// Deduplicator, if set, skips comment commands identical to one that is
+	// still queued or running on the same pull request.
+	Deduplicator *CommandDeduplicator
+	// CommentReactions are the reactions added to the comment of a command
+	// once it starts running.
+	CommentReactions CommentReactions
 }
 
 // RunAutoplanCommand runs plan and policy_checks when a pull request is opened or updated.
@@ -143,7 +172,7 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 	}
 	defer c.Drainer.OpDone()
 
-	log := c.buildLogger(baseRepo.FullName, pull.Num)
+	log := c.buildLogger(baseRepo.FullName, pull.Num, command.Autoplan.String())
 	defer c.logPanics(baseRepo, pull.Num, log)
 	status, err := c.PullStatusFetcher.GetPullStatus(pull)
 
@@ -188,13 +217,21 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 	if c.DisableAutoplan {
 		return
 	}
-	if len(c.DisableAutoplanLabel) > 0 {
+	autoplanTriggers := c.GlobalCfgStore.Load().AutoplanTriggers(baseRepo.ID())
+	if pattern, ok := autoplanTriggers.SkipTitlePattern(pull.Title); ok {
+		ctx.Log.Info("Pull/merge request title matches '%s' so not running autoplan.", pattern)
+		return
+	}
+	if len(c.DisableAutoplanLabel) > 0 || (autoplanTriggers != nil && len(autoplanTriggers.SkipLabels) > 0) {
 		labels, err := c.VCSClient.GetPullLabels(ctx.Log, baseRepo, pull)
 		if err != nil {
 			ctx.Log.Err("Unable to get VCS pull/merge request labels: %s. Proceeding with autoplan.", err)
 		} else if utils.SlicesContains(labels, c.DisableAutoplanLabel) {
 			ctx.Log.Info("Pull/merge request has disable auto plan label '%s' so not running autoplan.", c.DisableAutoplanLabel)
 			return
+		} else if label, ok := autoplanTriggers.SkipLabel(labels); ok {
+			ctx.Log.Info("Pull/merge request has label '%s' which skips autoplan so not running autoplan.", label)
+			return
 		}
 	}
 
@@ -293,7 +330,11 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 	}
 	defer c.Drainer.OpDone()
 
-	log := c.buildLogger(baseRepo.FullName, pullNum)
+	var cmdName string
+	if cmd != nil {
+		cmdName = cmd.Name.String()
+	}
+	log := c.buildLogger(baseRepo.FullName, pullNum, cmdName)
 	defer c.logPanics(baseRepo, pullNum, log)
 
 	scope := c.StatsScope.SubScope("comment")
@@ -353,6 +394,8 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		Trigger:              command.CommentTrigger,
 		PolicySet:            cmd.PolicySet,
 		ClearPolicyApproval:  cmd.ClearPolicyApproval,
+		ConfirmDestroy:       cmd.ConfirmDestroy,
+		OverrideApplyWindow:  cmd.OverrideApplyWindow,
 		TeamAllowlistChecker: c.TeamAllowlistChecker,
 	}
 
@@ -360,6 +403,31 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		return
 	}
 
+	if c.Deduplicator != nil {
+		done, inFlight, ok := c.Deduplicator.Start(pull, user, cmd)
+		if !ok {
+			log.Info("skipping command identical to one commented by %s that is still in flight", inFlight.User.Username)
+			if err := c.VCSClient.CreateComment(log, baseRepo, pullNum, c.Deduplicator.DuplicateComment(cmd, inFlight), cmd.Name.String()); err != nil {
+				log.Err("unable to comment on pull request: %s", err)
+			}
+			return
+		}
+		defer done()
+	}
+	c.CommentReactions.React(log, c.VCSClient, baseRepo, pullNum, cmd, ReactionRunning)
+
+	if changeSet := c.findChangeSet(ctx, cmd); changeSet != nil {
+		c.runChangeSetCommand(ctx, cmd, *changeSet)
+		return
+	}
+
+	// Ping smoke tests the integrations without cloning the repo, so it
+	// doesn't run the workflow hooks.
+	if cmd.Name == command.Ping {
+		buildCommentCommandRunner(c, command.Ping).Run(ctx, cmd)
+		return
+	}
+
 	// Update the combined plan or apply commit status to pending
 	switch cmd.Name {
 	case command.Plan:
@@ -403,6 +471,107 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 	c.PostWorkflowHooksCommandRunner.RunPostHooks(ctx, cmd) // nolint: errcheck
 }
 
+// RunIssueCommentCommand executes a command commented on an issue in issue-ops
+// mode. There is no pull request so the command runs against a pull request
+// model built from the repo's default branch which is cleaned up afterwards.
+func (c *DefaultCommandRunner) RunIssueCommentCommand(baseRepo models.Repo, defaultBranch string, user models.User, issueNum int, cmd *CommentCommand) {
+	if opStarted := c.Drainer.StartOp(); !opStarted {
+		if commentErr := c.VCSClient.CreateComment(c.Logger, baseRepo, issueNum, ShutdownComment, ""); commentErr != nil {
+			c.Logger.Log(logging.Error, "unable to comment that Atlantis is shutting down: %s", commentErr)
+		}
+		return
+	}
+	defer c.Drainer.OpDone()
+
+	log := c.Logger.WithHistory(
+		logging.RepoKey, baseRepo.FullName,
+		"issue", strconv.Itoa(issueNum),
+		logging.CommandKey, cmd.Name.String(),
+		logging.TraceIDKey, uuid.NewString(),
+	)
+	defer c.logPanics(baseRepo, issueNum, log)
+
+	scope := c.StatsScope.SubScope("issue_comment").SubScope(cmd.Name.String())
+	timer := scope.Timer(metrics.ExecutionTimeMetric).Start()
+	defer timer.Stop()
+
+	if c.TeamAllowlistChecker != nil && c.TeamAllowlistChecker.HasRules() {
+		err := c.fetchUserTeams(log, baseRepo, &user)
+		if err != nil {
+			log.Err("Unable to fetch user teams: %s", err)
+			return
+		}
+
+		ok, err := c.checkUserPermissions(baseRepo, user, cmd.Name.String())
+		if err != nil {
+			log.Err("Unable to check user permissions: %s", err)
+			return
+		}
+		if !ok {
+			c.commentUserDoesNotHavePermissions(baseRepo, issueNum, user, cmd)
+			return
+		}
+	}
+
+	// Commit statuses and plans are keyed by the head commit so the command
+	// runs against the commit the default branch points to now.
+	headCommit, err := c.getGithubBranchHeadCommit(log, baseRepo, defaultBranch)
+	if err != nil {
+		log.Err(err.Error())
+		if commentErr := c.VCSClient.CreateComment(log, baseRepo, issueNum, fmt.Sprintf("`Error: %s`", err), ""); commentErr != nil {
+			log.Err("unable to comment: %s", commentErr)
+		}
+		return
+	}
+
+	// The issue number is used as the pull number so that comments are
+	// posted back to the issue. GitHub issues and pull requests share the
+	// same number sequence so this can't collide with a real pull request.
+	pull := models.PullRequest{
+		Num:        issueNum,
+		HeadCommit: headCommit,
+		HeadBranch: defaultBranch,
+		BaseBranch: defaultBranch,
+		Author:     user.Username,
+		State:      models.OpenPullState,
+		BaseRepo:   baseRepo,
+		IsIssue:    true,
+	}
+	ctx := &command.Context{
+		User:                 user,
+		Log:                  log,
+		Pull:                 pull,
+		HeadRepo:             baseRepo,
+		Scope:                scope,
+		Trigger:              command.IssueTrigger,
+		TeamAllowlistChecker: c.TeamAllowlistChecker,
+	}
+	if !c.validateCtxAndComment(ctx, cmd.Name) {
+		return
+	}
+
+	log.Info("Running issue-ops command '%s' against branch %q", cmd.Name, defaultBranch)
+	c.CommentReactions.React(log, c.VCSClient, baseRepo, issueNum, cmd, ReactionRunning)
+	if err := c.PreWorkflowHooksCommandRunner.RunPreHooks(ctx, cmd); err != nil {
+		if c.FailOnPreWorkflowHookError {
+			log.Err("'fail-on-pre-workflow-hook-error' set, so not running %s command.", cmd.Name.String())
+			return
+		}
+		log.Err("'fail-on-pre-workflow-hook-error' not set so running %s command.", cmd.Name.String())
+	}
+
+	cmdRunner := buildCommentCommandRunner(c, cmd.CommandName())
+	cmdRunner.Run(ctx, cmd)
+
+	c.PostWorkflowHooksCommandRunner.RunPostHooks(ctx, cmd) // nolint: errcheck
+
+	if c.IssueOpsCleaner != nil {
+		if err := c.IssueOpsCleaner.CleanUpPull(log, baseRepo, pull); err != nil {
+			log.Err("cleaning up after issue-ops command: %s", err)
+		}
+	}
+}
+
 func (c *DefaultCommandRunner) getGithubData(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
 	if c.GithubPullGetter == nil {
 		return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support GitHub")
@@ -418,6 +587,17 @@ func (c *DefaultCommandRunner) getGithubData(logger logging.SimpleLogging, baseR
 	return pull, headRepo, nil
 }
 
+func (c *DefaultCommandRunner) getGithubBranchHeadCommit(logger logging.SimpleLogging, baseRepo models.Repo, branch string) (string, error) {
+	if c.GithubBranchGetter == nil {
+		return "", errors.New("Atlantis not configured to support GitHub")
+	}
+	headCommit, err := c.GithubBranchGetter.GetBranchHeadCommit(logger, baseRepo, branch)
+	if err != nil {
+		return "", errors.Wrapf(err, "making branch API call to GitHub for branch %q", branch)
+	}
+	return headCommit, nil
+}
+
 func (c *DefaultCommandRunner) getGiteaData(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
 	if c.GiteaPullGetter == nil {
 		return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support Gitea")
@@ -460,11 +640,14 @@ func (c *DefaultCommandRunner) getAzureDevopsData(logger logging.SimpleLogging,
 	return pull, headRepo, nil
 }
 
-func (c *DefaultCommandRunner) buildLogger(repoFullName string, pullNum int) logging.SimpleLogging {
-
+// buildLogger returns the logger of a command. Its trace ID correlates all the
+// logs of this run of the command.
+func (c *DefaultCommandRunner) buildLogger(repoFullName string, pullNum int, cmdName string) logging.SimpleLogging {
 	return c.Logger.WithHistory(
-		"repo", repoFullName,
-		"pull", strconv.Itoa(pullNum),
+		logging.RepoKey, repoFullName,
+		logging.PullKey, strconv.Itoa(pullNum),
+		logging.CommandKey, cmdName,
+		logging.TraceIDKey, uuid.NewString(),
 	)
 }
 
@@ -539,7 +722,8 @@ func (c *DefaultCommandRunner) validateCtxAndComment(ctx *command.Context, comma
 		return false
 	}
 
-	repo := c.GlobalCfg.MatchingRepo(ctx.Pull.BaseRepo.ID())
+	globalCfg := c.GlobalCfgStore.Load()
+	repo := globalCfg.MatchingRepo(ctx.Pull.BaseRepo.ID())
 	if !repo.BranchMatches(ctx.Pull.BaseBranch) {
 		ctx.Log.Info("command was run on a pull request which doesn't match base branches")
 		// just ignore it to allow us to use any git workflows without malicious intentions.
@@ -43,6 +43,7 @@ var projectCommandRunner *mocks.MockProjectCommandRunner
 var eventParsing *mocks.MockEventParsing
 var azuredevopsGetter *mocks.MockAzureDevopsPullGetter
 var githubGetter *mocks.MockGithubPullGetter
+var githubBranchGetter *mocks.MockGithubBranchGetter
 var gitlabGetter *mocks.MockGitlabMergeRequestGetter
 var ch events.DefaultCommandRunner
 var workingDir events.WorkingDir
@@ -108,6 +109,7 @@ func setup(t *testing.T, options ...func(testConfig *TestConfig)) *vcsmocks.Mock
 	eventParsing = mocks.NewMockEventParsing()
 	vcsClient := vcsmocks.NewMockClient()
 	githubGetter = mocks.NewMockGithubPullGetter()
+	githubBranchGetter = mocks.NewMockGithubBranchGetter()
 	gitlabGetter = mocks.NewMockGitlabMergeRequestGetter()
 	azuredevopsGetter = mocks.NewMockAzureDevopsPullGetter()
 	logger := logging.NewNoopLogger(t)
@@ -126,10 +128,13 @@ func setup(t *testing.T, options ...func(testConfig *TestConfig)) *vcsmocks.Mock
 		Backend: testConfig.backend,
 	}
 
+	globalCfgStore := valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}))
+
 	pullUpdater = &events.PullUpdater{
 		HidePrevPlanComments: false,
 		VCSClient:            vcsClient,
 		MarkdownRenderer:     events.NewMarkdownRenderer(false, false, false, false, false, false, "", "atlantis", false, false),
+		GlobalCfgStore:       globalCfgStore,
 	}
 
 	autoMerger = &events.AutoMerger{
@@ -167,6 +172,7 @@ func setup(t *testing.T, options ...func(testConfig *TestConfig)) *vcsmocks.Mock
 		testConfig.discardApprovalOnPlan,
 		pullReqStatusFetcher,
 	)
+	planCommandRunner.GlobalCfgStore = globalCfgStore
 
 	applyCommandRunner = events.NewApplyCommandRunner(
 		vcsClient,
@@ -236,7 +242,6 @@ func setup(t *testing.T, options ...func(testConfig *TestConfig)) *vcsmocks.Mock
 
 	When(postWorkflowHooksCommandRunner.RunPostHooks(Any[*command.Context](), Any[*events.CommentCommand]())).ThenReturn(nil)
 
-	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
 	scope, _, _ := metrics.NewLoggingScope(logger, "atlantis")
 
 	ch = events.DefaultCommandRunner{
@@ -245,11 +250,12 @@ func setup(t *testing.T, options ...func(testConfig *TestConfig)) *vcsmocks.Mock
 		EventParser:                    eventParsing,
 		FailOnPreWorkflowHookError:     false,
 		GithubPullGetter:               githubGetter,
+		GithubBranchGetter:             githubBranchGetter,
 		GitlabMergeRequestGetter:       gitlabGetter,
 		AzureDevopsPullGetter:          azuredevopsGetter,
 		Logger:                         logger,
 		StatsScope:                     scope,
-		GlobalCfg:                      globalCfg,
+		GlobalCfgStore:                 globalCfgStore,
 		AllowForkPRs:                   false,
 		AllowForkPRsFlag:               "allow-fork-prs-flag",
 		Drainer:                        drainer,
@@ -579,6 +585,44 @@ func TestRunCommentCommand_DisableAutoplanLabel_PullNotLabeled(t *testing.T) {
 	vcsClient.VerifyWasCalledOnce().GetPullLabels(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull))
 }
 
+func TestRunAutoplanCommand_AutoplanTriggersSkipTitle(t *testing.T) {
+	t.Log("if the pull request title matches a skip title pattern, auto plans are skipped")
+	vcsClient := setup(t)
+	modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, BaseBranch: "main", Title: "docs: fix typo"}
+	globalCfg := ch.GlobalCfgStore.Load()
+	globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
+		IDRegex: regexp.MustCompile(".*"),
+		AutoplanTriggers: &valid.AutoplanTriggers{
+			SkipTitlePatterns: []*regexp.Regexp{regexp.MustCompile("^docs:")},
+		},
+	})
+	ch.GlobalCfgStore.Store(globalCfg)
+
+	ch.RunAutoplanCommand(testdata.GithubRepo, testdata.GithubRepo, modelPull, testdata.User)
+	projectCommandBuilder.VerifyWasCalled(Never()).BuildAutoplanCommands(Any[*command.Context]())
+	vcsClient.VerifyWasCalled(Never()).GetPullLabels(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest]())
+}
+
+func TestRunAutoplanCommand_AutoplanTriggersSkipLabel(t *testing.T) {
+	t.Log("if the pull request has a skip label, auto plans are skipped")
+	vcsClient := setup(t)
+	modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, BaseBranch: "main", Title: "Add bucket"}
+	globalCfg := ch.GlobalCfgStore.Load()
+	globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
+		IDRegex: regexp.MustCompile(".*"),
+		AutoplanTriggers: &valid.AutoplanTriggers{
+			SkipLabels:        []string{"wip"},
+			SkipTitlePatterns: []*regexp.Regexp{regexp.MustCompile("^docs:")},
+		},
+	})
+	ch.GlobalCfgStore.Store(globalCfg)
+	When(ch.VCSClient.GetPullLabels(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull))).ThenReturn([]string{"need-help", "wip"}, nil)
+
+	ch.RunAutoplanCommand(testdata.GithubRepo, testdata.GithubRepo, modelPull, testdata.User)
+	projectCommandBuilder.VerifyWasCalled(Never()).BuildAutoplanCommands(Any[*command.Context]())
+	vcsClient.VerifyWasCalledOnce().GetPullLabels(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull))
+}
+
 func TestRunCommentCommand_ClosedPull(t *testing.T) {
 	t.Log("if a command is run on a closed pull request atlantis should" +
 		" comment saying that this is not allowed")
@@ -599,10 +643,12 @@ func TestRunCommentCommand_MatchedBranch(t *testing.T) {
 	t.Log("if a command is run on a pull request which matches base branches run plan successfully")
 	vcsClient := setup(t)
 
-	ch.GlobalCfg.Repos = append(ch.GlobalCfg.Repos, valid.Repo{
+	globalCfg := ch.GlobalCfgStore.Load()
+	globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
 		IDRegex:     regexp.MustCompile(".*"),
 		BranchRegex: regexp.MustCompile("^main$"),
 	})
+	ch.GlobalCfgStore.Store(globalCfg)
 	var pull github.PullRequest
 	modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, BaseBranch: "main"}
 	When(githubGetter.GetPullRequest(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(testdata.Pull.Num))).ThenReturn(&pull, nil)
@@ -617,10 +663,12 @@ func TestRunCommentCommand_UnmatchedBranch(t *testing.T) {
 	t.Log("if a command is run on a pull request which doesn't match base branches do not comment with error")
 	vcsClient := setup(t)
 
-	ch.GlobalCfg.Repos = append(ch.GlobalCfg.Repos, valid.Repo{
+	globalCfg := ch.GlobalCfgStore.Load()
+	globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
 		IDRegex:     regexp.MustCompile(".*"),
 		BranchRegex: regexp.MustCompile("^main$"),
 	})
+	ch.GlobalCfgStore.Store(globalCfg)
 	var pull github.PullRequest
 	modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, BaseBranch: "foo"}
 	When(githubGetter.GetPullRequest(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(testdata.Pull.Num))).ThenReturn(&pull, nil)
@@ -772,6 +820,47 @@ func TestRunUnlockCommandDoesntRetrieveLabelsIfDisableUnlockLabelNotSet(t *testi
 	vcsClient.VerifyWasCalled(Never()).GetPullLabels(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull))
 }
 
+func TestRunIssueCommentCommand_HeadCommit(t *testing.T) {
+	t.Log("issue-ops commands should run against the head commit of the default branch")
+
+	setup(t)
+	When(githubBranchGetter.GetBranchHeadCommit(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq("main"))).ThenReturn("abc123", nil)
+
+	ch.RunIssueCommentCommand(testdata.GithubRepo, "main", testdata.User, 5, &events.CommentCommand{Name: command.Version, ProjectName: "staging"})
+
+	ctx, _ := projectCommandBuilder.VerifyWasCalledOnce().BuildVersionCommands(Any[*command.Context](), Any[*events.CommentCommand]()).GetCapturedArguments()
+	Equals(t, "abc123", ctx.Pull.HeadCommit)
+	Equals(t, "main", ctx.Pull.HeadBranch)
+	Equals(t, true, ctx.Pull.IsIssue)
+}
+
+func TestRunIssueCommentCommand_HeadCommitErr(t *testing.T) {
+	t.Log("if the head commit of the default branch can't be found atlantis should comment back and not run the command")
+
+	vcsClient := setup(t)
+	When(githubBranchGetter.GetBranchHeadCommit(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq("main"))).ThenReturn("", errors.New("err"))
+
+	ch.RunIssueCommentCommand(testdata.GithubRepo, "main", testdata.User, 5, &events.CommentCommand{Name: command.Version, ProjectName: "staging"})
+
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(5),
+		Eq("`Error: making branch API call to GitHub for branch \"main\": err`"), Eq(""))
+	projectCommandBuilder.VerifyWasCalled(Never()).BuildVersionCommands(Any[*command.Context](), Any[*events.CommentCommand]())
+}
+
+func TestRunIssueCommentCommand_RunningReaction(t *testing.T) {
+	t.Log("once an issue-ops command starts running atlantis should react to its comment with the running reaction")
+
+	vcsClient := setup(t)
+	reactions, err := events.ParseCommentReactions("running:hourglass")
+	Ok(t, err)
+	ch.CommentReactions = reactions
+	When(githubBranchGetter.GetBranchHeadCommit(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq("main"))).ThenReturn("abc123", nil)
+
+	ch.RunIssueCommentCommand(testdata.GithubRepo, "main", testdata.User, 5, &events.CommentCommand{Name: command.Version, ProjectName: "staging", CommentID: 7})
+
+	vcsClient.VerifyWasCalledOnce().ReactToComment(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(5), Eq(int64(7)), Eq("hourglass"))
+}
+
 func TestRunAutoplanCommand_DeletePlans(t *testing.T) {
 	setup(t)
 	tmp := t.TempDir()
@@ -15,6 +15,7 @@ package events
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
@@ -24,6 +25,7 @@ import (
 	"text/template"
 
 	"github.com/google/shlex"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/utils"
@@ -43,10 +45,24 @@ const (
 	autoMergeDisabledFlagShort   = ""
 	autoMergeMethodFlagLong      = "auto-merge-method"
 	autoMergeMethodFlagShort     = ""
+	continueOnErrorFlagLong      = "continue-on-error"
+	continueOnErrorFlagShort     = ""
 	verboseFlagLong              = "verbose"
 	verboseFlagShort             = ""
 	clearPolicyApprovalFlagLong  = "clear-policy-approval"
 	clearPolicyApprovalFlagShort = ""
+	targetFlagLong               = "target"
+	targetFlagShort              = ""
+	confirmDestroyFlagLong       = "confirm-destroy"
+	confirmDestroyFlagShort      = ""
+	overrideApplyWindowFlagLong  = "override-apply-window"
+	overrideApplyWindowFlagShort = ""
+	promoteCanaryFlagLong        = "promote-canary"
+	promoteCanaryFlagShort       = ""
+	undoFlagLong                 = "undo"
+	undoFlagShort                = ""
+	labelFlagLong                = "label"
+	labelFlagShort               = ""
 )
 
 // multiLineRegex is used to ignore multi-line comments since those aren't valid
@@ -56,6 +72,16 @@ const (
 // and pasting GitHub comments.
 var multiLineRegex = regexp.MustCompile(`.*\r?\n[^\r\n]+`)
 
+// bulkImportRegex matches a command followed by a fenced block. It's only
+// valid for import, where the block lists the resources to import, one
+// ADDRESS ID pair per line.
+var bulkImportRegex = regexp.MustCompile("(?s)^([^\r\n]+)\r?\n\\s*```[^\r\n]*\r?\n(.*?)\r?\n?```$")
+
+// targetAddressRegex matches Terraform resource and module addresses, ex.
+// module.app["blue"].aws_instance.web[0]. It's deliberately strict since the
+// targets are passed to Terraform on the command line.
+var targetAddressRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_\-]*(\[("[a-zA-Z0-9_\-./]*"|[0-9]+)\])?(\.[a-zA-Z_][a-zA-Z0-9_\-]*(\[("[a-zA-Z0-9_\-./]*"|[0-9]+)\])?)*$`)
+
 //go:generate pegomock generate --package mocks -o mocks/mock_comment_parsing.go CommentParsing
 
 // CommentParsing handles parsing pull request comments.
@@ -86,6 +112,11 @@ type CommentParser struct {
 	AzureDevopsUser string
 	ExecutableName  string
 	AllowCommands   []command.Name
+	// InstanceName is the name of this Atlantis instance when several
+	// instances share repos. Comments that aren't addressed to it are then
+	// ignored instead of answered with suggestions, since they may be
+	// addressed to another instance.
+	InstanceName string
 }
 
 // NewCommentParser returns a CommentParser
@@ -143,8 +174,17 @@ type CommentParseResult struct {
 // - atlantis version
 // - atlantis approve_policies
 // - atlantis import ADDRESS ID
+//
+// Import also accepts the resources to import in a fenced block after the
+// command instead of ADDRESS and ID, one ADDRESS ID pair per line.
 func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) CommentParseResult {
 	comment := strings.TrimSpace(rawComment)
+	var bulkImport bool
+	var bulkImportBlock string
+	if match := bulkImportRegex.FindStringSubmatch(comment); match != nil {
+		bulkImport = true
+		comment, bulkImportBlock = strings.TrimSpace(match[1]), match[2]
+	}
 	comment = strings.Trim(comment, "`")
 
 	if multiLineRegex.MatchString(comment) {
@@ -162,12 +202,12 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 	executableName := strings.ToLower(args[0])
 
 	// Helpfully warn the user if they're using "terraform" instead of "atlantis"
-	if executableName == "terraform" && e.ExecutableName != "terraform" {
+	if executableName == "terraform" && e.ExecutableName != "terraform" && e.InstanceName == "" {
 		return CommentParseResult{CommentResponse: fmt.Sprintf(DidYouMeanAtlantisComment, e.ExecutableName, "terraform")}
 	}
 
 	// Helpfully warn the user that the command might be misspelled
-	if utils.IsSimilarWord(executableName, e.ExecutableName) {
+	if utils.IsSimilarWord(executableName, e.ExecutableName) && e.InstanceName == "" {
 		return CommentParseResult{CommentResponse: fmt.Sprintf(DidYouMeanAtlantisComment, e.ExecutableName, args[0])}
 	}
 
@@ -210,6 +250,12 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 	// Lowercase it to avoid autocorrect issues with browsers.
 	cmd := strings.ToLower(args[1])
 
+	// Only import accepts a fenced block so other commands followed by one
+	// are multi-line comments.
+	if bulkImport && cmd != command.Import.String() {
+		return CommentParseResult{Ignore: true}
+	}
+
 	// Help output.
 	if e.stringInSlice(cmd, []string{"help", "-h", "--help"}) {
 		return CommentParseResult{CommentResponse: e.HelpComment()}
@@ -230,8 +276,15 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 	var policySet string
 	var clearPolicyApproval bool
 	var verbose bool
+	var targets []string
+	var labels []string
 	var autoMergeDisabled bool
 	var autoMergeMethod string
+	var continueOnError bool
+	var confirmDestroy bool
+	var overrideApplyWindow bool
+	var promoteCanary bool
+	var undo bool
 	var flagSet *pflag.FlagSet
 	var name command.Name
 
@@ -244,6 +297,8 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before planning.")
 		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to run plan in relative to root of repo, ex. 'child/dir'.")
 		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Which project to run plan for. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
+		flagSet.StringArrayVarP(&targets, targetFlagLong, targetFlagShort, nil, "Limit the plan to this resource address, can be repeated. Must be allowed by the server-side repo config.")
+		flagSet.StringArrayVarP(&labels, labelFlagLong, labelFlagShort, nil, "Plan the projects with this label, ex. tier=prod, can be repeated. Cannot be used at same time as project, workspace or dir flags.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	case command.Apply.String():
 		name = command.Apply
@@ -254,6 +309,12 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Apply the plan for this project. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
 		flagSet.BoolVarP(&autoMergeDisabled, autoMergeDisabledFlagLong, autoMergeDisabledFlagShort, false, "Disable automerge after apply.")
 		flagSet.StringVarP(&autoMergeMethod, autoMergeMethodFlagLong, autoMergeMethodFlagShort, "", "Specifies the merge method for the VCS if automerge is enabled. (Currently only implemented for GitHub)")
+		flagSet.StringArrayVarP(&targets, targetFlagLong, targetFlagShort, nil, "Only apply plans that were created with exactly these targets, can be repeated.")
+		flagSet.StringArrayVarP(&labels, labelFlagLong, labelFlagShort, nil, "Apply the plans of the projects with this label, ex. tier=prod, can be repeated. Cannot be used at same time as project, workspace or dir flags.")
+		flagSet.BoolVarP(&confirmDestroy, confirmDestroyFlagLong, confirmDestroyFlagShort, false, "Confirm applying plans that destroy or replace resources protected by the server-side repo config.")
+		flagSet.BoolVarP(&overrideApplyWindow, overrideApplyWindowFlagLong, overrideApplyWindowFlagShort, false, "Apply projects outside their apply windows. Only allowed for apply window admins.")
+		flagSet.BoolVarP(&promoteCanary, promoteCanaryFlagLong, promoteCanaryFlagShort, false, "Promote applied canaries and apply the projects waiting for them.")
+		flagSet.BoolVarP(&continueOnError, continueOnErrorFlagLong, continueOnErrorFlagShort, false, "Keep applying projects after one fails.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	case command.ApprovePolicies.String():
 		name = command.ApprovePolicies
@@ -292,11 +353,39 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to run state command in relative to root of repo, ex. 'child/dir'.")
 		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Which project to run state command for. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case command.ForceUnlockState.String():
+		name = command.ForceUnlockState
+		flagSet = pflag.NewFlagSet(command.ForceUnlockState.String(), pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before releasing the state lock.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to release the state lock for relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Which project to release the state lock for. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
+		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case command.Rollback.String():
+		name = command.Rollback
+		flagSet = pflag.NewFlagSet(command.Rollback.String(), pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before planning the rollback.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to plan the rollback in relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Which project to plan the rollback for. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
+		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case command.Silence.String():
+		name = command.Silence
+		flagSet = pflag.NewFlagSet(command.Silence.String(), pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Silence the projects in this Terraform workspace.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Silence the projects in this directory, relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Silence this project. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
+		flagSet.BoolVarP(&undo, undoFlagLong, undoFlagShort, false, "Stop silencing the project.")
+	case command.Ping.String():
+		name = command.Ping
+		flagSet = pflag.NewFlagSet(command.Ping.String(), pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
 	default:
 		return CommentParseResult{CommentResponse: fmt.Sprintf("Error: unknown command %q – this is a bug", cmd)}
 	}
 
-	subName, extraArgs, errResult := e.parseArgs(name, args, flagSet)
+	subName, extraArgs, errResult := e.parseArgs(name, args, flagSet, bulkImport)
 	if errResult != "" {
 		return CommentParseResult{CommentResponse: errResult}
 	}
@@ -335,12 +424,44 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		}
 	}
 
+	for _, target := range targets {
+		if !targetAddressRegex.MatchString(target) {
+			return CommentParseResult{CommentResponse: e.errMarkdown(fmt.Sprintf("invalid target: %q is not a resource address", target), cmd, flagSet)}
+		}
+	}
+
+	labelSelector, err := valid.ParseLabelSelector(labels)
+	if err != nil {
+		return CommentParseResult{CommentResponse: e.errMarkdown(err.Error(), cmd, flagSet)}
+	}
+	if len(labelSelector) > 0 && (project != "" || workspace != "" || dir != "") {
+		err := fmt.Sprintf("cannot use --%s at same time as -%s/--%s, -%s/--%s or -%s/--%s", labelFlagLong, projectFlagShort, projectFlagLong, dirFlagShort, dirFlagLong, workspaceFlagShort, workspaceFlagLong)
+		return CommentParseResult{CommentResponse: e.errMarkdown(err, cmd, flagSet)}
+	}
+
+	var importResources [][]string
+	if bulkImport {
+		importResources, err = parseImportResources(bulkImportBlock)
+		if err != nil {
+			return CommentParseResult{CommentResponse: e.errMarkdown(err.Error(), cmd, flagSet)}
+		}
+	}
+
+	commentCmd := NewCommentCommand(dir, extraArgs, name, subName, verbose, autoMergeDisabled, autoMergeMethod, workspace, project, policySet, clearPolicyApproval)
+	commentCmd.Targets = targets
+	commentCmd.Labels = labelSelector
+	commentCmd.ContinueOnError = continueOnError
+	commentCmd.ConfirmDestroy = confirmDestroy
+	commentCmd.OverrideApplyWindow = overrideApplyWindow
+	commentCmd.PromoteCanary = promoteCanary
+	commentCmd.Undo = undo
+	commentCmd.ImportResources = importResources
 	return CommentParseResult{
-		Command: NewCommentCommand(dir, extraArgs, name, subName, verbose, autoMergeDisabled, autoMergeMethod, workspace, project, policySet, clearPolicyApproval),
+		Command: commentCmd,
 	}
 }
 
-func (e *CommentParser) parseArgs(name command.Name, args []string, flagSet *pflag.FlagSet) (string, []string, string) {
+func (e *CommentParser) parseArgs(name command.Name, args []string, flagSet *pflag.FlagSet, bulkImport bool) (string, []string, string) {
 	// Now parse the flags.
 	// It's safe to use [2:] because we know there's at least 2 elements in args.
 	err := flagSet.Parse(args[2:])
@@ -381,6 +502,10 @@ func (e *CommentParser) parseArgs(name command.Name, args []string, flagSet *pfl
 	if err != nil {
 		return "", nil, e.errMarkdown(err.Error(), name.String(), flagSet)
 	}
+	if bulkImport {
+		// The resources to import are listed in the fenced block instead.
+		commandArgCount = &command.ArgCount{Min: 0, Max: 0}
+	}
 	if !commandArgCount.IsMatchCount(len(commandArgs)) {
 		return "", nil, e.errMarkdown(fmt.Sprintf("unknown argument(s) – %s", strings.Join(commandArgs, " ")), name.DefaultUsage(), flagSet)
 	}
@@ -403,6 +528,31 @@ func (e *CommentParser) parseArgs(name command.Name, args []string, flagSet *pfl
 	return subCommand, extraArgs, ""
 }
 
+// parseImportResources parses the ADDRESS ID pairs of a bulk import. Lines
+// are split like the comment so addresses with spaces or quotes can be
+// quoted. Blank lines and lines starting with # are skipped.
+func parseImportResources(block string) ([][]string, error) {
+	var resources [][]string
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields, err := shlex.Split(line)
+		if err != nil {
+			return nil, fmt.Errorf("parsing import resource %q: %w", line, err)
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid import resource %q: expected ADDRESS ID", line)
+		}
+		resources = append(resources, fields)
+	}
+	if len(resources) == 0 {
+		return nil, errors.New("no resources to import in the fenced block")
+	}
+	return resources, nil
+}
+
 // BuildPlanComment builds a plan comment for the specified args.
 func (e *CommentParser) BuildPlanComment(repoRelDir string, workspace string, project string, commentArgs []string) string {
 	flags := e.buildFlags(repoRelDir, workspace, project, false, "")
@@ -509,23 +659,31 @@ func (e *CommentParser) HelpComment() string {
 	buf := &bytes.Buffer{}
 	var tmpl = template.Must(template.New("").Parse(helpCommentTemplate))
 	if err := tmpl.Execute(buf, struct {
-		ExecutableName       string
-		AllowVersion         bool
-		AllowPlan            bool
-		AllowApply           bool
-		AllowUnlock          bool
-		AllowApprovePolicies bool
-		AllowImport          bool
-		AllowState           bool
+		ExecutableName        string
+		AllowVersion          bool
+		AllowPlan             bool
+		AllowApply            bool
+		AllowUnlock           bool
+		AllowApprovePolicies  bool
+		AllowImport           bool
+		AllowState            bool
+		AllowForceUnlockState bool
+		AllowRollback         bool
+		AllowSilence          bool
+		AllowPing             bool
 	}{
-		ExecutableName:       e.ExecutableName,
-		AllowVersion:         e.isAllowedCommand(command.Version.String()),
-		AllowPlan:            e.isAllowedCommand(command.Plan.String()),
-		AllowApply:           e.isAllowedCommand(command.Apply.String()),
-		AllowUnlock:          e.isAllowedCommand(command.Unlock.String()),
-		AllowApprovePolicies: e.isAllowedCommand(command.ApprovePolicies.String()),
-		AllowImport:          e.isAllowedCommand(command.Import.String()),
-		AllowState:           e.isAllowedCommand(command.State.String()),
+		ExecutableName:        e.ExecutableName,
+		AllowVersion:          e.isAllowedCommand(command.Version.String()),
+		AllowPlan:             e.isAllowedCommand(command.Plan.String()),
+		AllowApply:            e.isAllowedCommand(command.Apply.String()),
+		AllowUnlock:           e.isAllowedCommand(command.Unlock.String()),
+		AllowApprovePolicies:  e.isAllowedCommand(command.ApprovePolicies.String()),
+		AllowImport:           e.isAllowedCommand(command.Import.String()),
+		AllowState:            e.isAllowedCommand(command.State.String()),
+		AllowForceUnlockState: e.isAllowedCommand(command.ForceUnlockState.String()),
+		AllowRollback:         e.isAllowedCommand(command.Rollback.String()),
+		AllowSilence:          e.isAllowedCommand(command.Silence.String()),
+		AllowPing:             e.isAllowedCommand(command.Ping.String()),
 	}); err != nil {
 		return fmt.Sprintf("Failed to render template, this is a bug: %v", err)
 	}
@@ -585,6 +743,31 @@ Commands:
   state rm ADDRESS...
            Runs 'terraform state rm' for the passed address resource.
            To remove a specific project resource, use the -d, -w and -p flags.
+  state list [ADDRESS...]
+           Runs 'terraform state list' to list the resources in the state.
+  state show ADDRESS
+           Runs 'terraform state show' for the passed address resource.
+           Sensitive attributes are redacted. Both must be allowed by the
+           server-side repo config.
+{{- end }}
+{{- if .AllowForceUnlockState }}
+  force-unlock-state LOCK_ID
+           Runs 'terraform force-unlock' to release a stale Terraform state lock.
+           Use the -d, -w and -p flags to select the project.
+{{- end }}
+{{- if .AllowRollback }}
+  rollback Plans a return to the state recorded before the last apply.
+           Use the -d, -w and -p flags to select the project. Must be
+           enabled by the server-side repo config.
+{{- end }}
+{{- if .AllowSilence }}
+  silence  Stops commenting the results of a project on this pull request.
+           Commit statuses and job logs are still updated. Use the -d, -w
+           and -p flags to select the project and --undo to unsilence it.
+{{- end }}
+{{- if .AllowPing }}
+  ping     Comments back and sets a commit status without running Terraform.
+           Use it to check the webhooks and credentials of a repo.
 {{- end }}
   help     View help.
 
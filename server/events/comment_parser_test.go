@@ -114,6 +114,32 @@ func TestParse_ExecutableName(t *testing.T) {
 	}
 }
 
+func TestParse_InstanceName(t *testing.T) {
+	cases := []struct {
+		comment   string
+		expIgnore bool
+	}{
+		{"atlantis-prod plan", false},
+		{"atlantis-sandbox plan", true},
+		{"atlantis-prd plan", true},
+		{"atlantis plan", true},
+		{"terraform plan", true},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			var commentParser = events.CommentParser{
+				GithubUser:     "github-user",
+				ExecutableName: "atlantis-prod",
+				InstanceName:   "prod",
+				AllowCommands:  command.AllCommentCommands,
+			}
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, c.expIgnore, r.Ignore)
+			Equals(t, "", r.CommentResponse)
+		})
+	}
+}
+
 func TestParse_HelpResponse(t *testing.T) {
 	allowCommandsCases := [][]command.Name{
 		command.AllCommentCommands,
@@ -329,8 +355,8 @@ func TestParse_SubcommandUsage(t *testing.T) {
 		{"atlantis approve_policies --help", "approve_policies"},
 		{"atlantis import -h", "import ADDRESS ID"},
 		{"atlantis import --help", "import ADDRESS ID"},
-		{"atlantis state -h", "state [rm ADDRESS...]"},
-		{"atlantis state --help", "state [rm ADDRESS...]"},
+		{"atlantis state -h", "state [rm ADDRESS...|list [ADDRESS...]|show ADDRESS]"},
+		{"atlantis state --help", "state [rm ADDRESS...|list [ADDRESS...]|show ADDRESS]"},
 	}
 	for _, c := range tests {
 		r := commentParser.Parse(c.input, models.Github)
@@ -383,6 +409,68 @@ func TestParse_InvalidFlags(t *testing.T) {
 	}
 }
 
+func TestParse_StateReadSubCommands(t *testing.T) {
+	cases := []struct {
+		comment      string
+		expSubName   string
+		expFlags     []string
+		expErrSubstr string
+	}{
+		{comment: "atlantis state list", expSubName: "list"},
+		{comment: "atlantis state -p proj list module.app", expSubName: "list", expFlags: []string{"module.app"}},
+		{comment: "atlantis state show aws_instance.web", expSubName: "show", expFlags: []string{"aws_instance.web"}},
+		{comment: "atlantis state show", expErrSubstr: "unknown argument(s)"},
+		{comment: "atlantis state show aws_instance.a aws_instance.b", expErrSubstr: "unknown argument(s)"},
+		{comment: "atlantis state mv a b", expErrSubstr: "invalid subcommand mv (not rm, list, show)"},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			if c.expErrSubstr != "" {
+				Assert(t, strings.Contains(r.CommentResponse, c.expErrSubstr),
+					"expected CommentResponse %q to contain %q", r.CommentResponse, c.expErrSubstr)
+				return
+			}
+			Equals(t, "", r.CommentResponse)
+			Equals(t, command.State, r.Command.Name)
+			Equals(t, c.expSubName, r.Command.SubName)
+			Equals(t, c.expFlags, r.Command.Flags)
+		})
+	}
+}
+
+func TestParse_BulkImport(t *testing.T) {
+	comment := "atlantis import -p proj -- -var foo=bar\n```\naws_instance.a i-123\n# skipped\n\n'aws_instance.b[\"key\"]' i-456\n```"
+	r := commentParser.Parse(comment, models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, command.Import, r.Command.Name)
+	Equals(t, "proj", r.Command.ProjectName)
+	Equals(t, []string{"-var", "foo=bar"}, r.Command.Flags)
+	Equals(t, [][]string{{"aws_instance.a", "i-123"}, {`aws_instance.b["key"]`, "i-456"}}, r.Command.ImportResources)
+
+	cases := []struct {
+		comment      string
+		expErrSubstr string
+		expIgnore    bool
+	}{
+		{comment: "atlantis import aws_instance.a i-123\n```\naws_instance.b i-456\n```", expErrSubstr: "unknown argument(s)"},
+		{comment: "atlantis import\n```\naws_instance.a\n```", expErrSubstr: "invalid import resource \"aws_instance.a\": expected ADDRESS ID"},
+		{comment: "atlantis import\n```\n\n```", expErrSubstr: "no resources to import"},
+		{comment: "atlantis plan\n```\naws_instance.a i-123\n```", expIgnore: true},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			if c.expIgnore {
+				Assert(t, r.Ignore, "expected comment to be ignored")
+				return
+			}
+			Assert(t, strings.Contains(r.CommentResponse, c.expErrSubstr),
+				"expected CommentResponse %q to contain %q", r.CommentResponse, c.expErrSubstr)
+		})
+	}
+}
+
 func TestParse_RelativeDirPath(t *testing.T) {
 	t.Log("if -d is used with a relative path, should return an error")
 	comments := []string{
@@ -487,6 +575,200 @@ func TestParse_UsingProjectAtSameTimeAsWorkspaceOrDir(t *testing.T) {
 	}
 }
 
+func TestParse_Targets(t *testing.T) {
+	cases := []struct {
+		comment    string
+		expTargets []string
+	}{
+		{
+			"atlantis plan --target aws_instance.web",
+			[]string{"aws_instance.web"},
+		},
+		{
+			`atlantis plan -d dir --target aws_instance.web --target 'module.app["blue"].aws_instance.web[0]'`,
+			[]string{"aws_instance.web", `module.app["blue"].aws_instance.web[0]`},
+		},
+		{
+			"atlantis apply -p project --target module.app",
+			[]string{"module.app"},
+		},
+		{
+			"atlantis plan",
+			nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, "", r.CommentResponse)
+			Equals(t, c.expTargets, r.Command.Targets)
+		})
+	}
+}
+
+func TestParse_Labels(t *testing.T) {
+	cases := []struct {
+		comment   string
+		expLabels map[string]string
+	}{
+		{
+			"atlantis plan --label tier=prod",
+			map[string]string{"tier": "prod"},
+		},
+		{
+			"atlantis apply --label tier=prod --label team=platform",
+			map[string]string{"tier": "prod", "team": "platform"},
+		},
+		{
+			"atlantis plan",
+			nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, "", r.CommentResponse)
+			Equals(t, c.expLabels, r.Command.Labels)
+			Equals(t, c.expLabels != nil, r.Command.IsForSpecificProject())
+		})
+	}
+
+	for comment, exp := range map[string]string{
+		"atlantis plan --label tier":                       "invalid label",
+		"atlantis plan --label 'tier=prod us'":             "invalid label",
+		"atlantis plan --label tier=prod --label tier=dev": "conflicts with tier=prod",
+		"atlantis apply -p project --label tier=prod":      "cannot use --label at same time as",
+		"atlantis plan -d dir --label tier=prod":           "cannot use --label at same time as",
+		"atlantis approve_policies --label tier=prod":      "unknown flag: --label",
+	} {
+		t.Run(comment, func(t *testing.T) {
+			r := commentParser.Parse(comment, models.Github)
+			Assert(t, strings.Contains(r.CommentResponse, exp),
+				"For comment %q expected CommentResponse %q to contain %q", comment, r.CommentResponse, exp)
+		})
+	}
+}
+
+func TestParse_ContinueOnError(t *testing.T) {
+	cases := []struct {
+		comment string
+		exp     bool
+	}{
+		{"atlantis apply --continue-on-error", true},
+		{"atlantis apply -p project --continue-on-error", true},
+		{"atlantis apply", false},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, "", r.CommentResponse)
+			Equals(t, c.exp, r.Command.ContinueOnError)
+		})
+	}
+
+	r := commentParser.Parse("atlantis plan --continue-on-error", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "unknown flag: --continue-on-error"),
+		"expected unknown flag error, got %q", r.CommentResponse)
+}
+
+func TestParse_ConfirmDestroy(t *testing.T) {
+	cases := []struct {
+		comment string
+		exp     bool
+	}{
+		{"atlantis apply --confirm-destroy", true},
+		{"atlantis apply -p project --confirm-destroy", true},
+		{"atlantis apply", false},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, "", r.CommentResponse)
+			Equals(t, c.exp, r.Command.ConfirmDestroy)
+		})
+	}
+
+	r := commentParser.Parse("atlantis plan --confirm-destroy", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "unknown flag: --confirm-destroy"),
+		"expected unknown flag error, got %q", r.CommentResponse)
+}
+
+func TestParse_OverrideApplyWindow(t *testing.T) {
+	r := commentParser.Parse("atlantis apply -p project --override-apply-window", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, true, r.Command.OverrideApplyWindow)
+
+	r = commentParser.Parse("atlantis plan --override-apply-window", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "unknown flag: --override-apply-window"),
+		"expected unknown flag error, got %q", r.CommentResponse)
+}
+
+func TestParse_PromoteCanary(t *testing.T) {
+	r := commentParser.Parse("atlantis apply --promote-canary", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, true, r.Command.PromoteCanary)
+
+	r = commentParser.Parse("atlantis plan --promote-canary", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "unknown flag: --promote-canary"),
+		"expected unknown flag error, got %q", r.CommentResponse)
+}
+
+func TestParse_Rollback(t *testing.T) {
+	r := commentParser.Parse("atlantis rollback -d dir -w staging", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, command.Rollback, r.Command.Name)
+	Equals(t, "dir", r.Command.RepoRelDir)
+	Equals(t, "staging", r.Command.Workspace)
+
+	r = commentParser.Parse("atlantis rollback --target aws_instance.web", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "unknown flag: --target"),
+		"expected unknown flag error, got %q", r.CommentResponse)
+}
+
+func TestParse_Silence(t *testing.T) {
+	r := commentParser.Parse("atlantis silence -p lockfiles", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, command.Silence, r.Command.Name)
+	Equals(t, "lockfiles", r.Command.ProjectName)
+	Equals(t, false, r.Command.Undo)
+
+	r = commentParser.Parse("atlantis silence -d network --undo", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, "network", r.Command.RepoRelDir)
+	Equals(t, true, r.Command.Undo)
+
+	r = commentParser.Parse("atlantis plan --undo", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "unknown flag: --undo"),
+		"expected unknown flag error, got %q", r.CommentResponse)
+}
+
+func TestParse_Ping(t *testing.T) {
+	r := commentParser.Parse("atlantis ping", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, command.Ping, r.Command.Name)
+
+	r = commentParser.Parse("atlantis ping -d dir", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "unknown shorthand flag: 'd'"),
+		"expected unknown flag error, got %q", r.CommentResponse)
+}
+
+func TestParse_InvalidTargets(t *testing.T) {
+	cases := []string{
+		"atlantis plan --target 'aws_instance.web;rm'",
+		"atlantis plan --target aws_instance.web$(id)",
+		"atlantis apply --target .aws_instance",
+		"atlantis plan --target 'module.app[\"blue\"'",
+	}
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			r := commentParser.Parse(c, models.Github)
+			exp := "Error: invalid target"
+			Assert(t, strings.Contains(r.CommentResponse, exp),
+				"For comment %q expected CommentResponse %q to contain %q", c, r.CommentResponse, exp)
+		})
+	}
+}
+
 func TestParse_Parsing(t *testing.T) {
 	cases := []struct {
 		flags        string
@@ -707,7 +989,7 @@ func TestParse_Parsing(t *testing.T) {
 	}
 
 	for _, test := range cases {
-		for _, cmdName := range []string{"plan", "apply", "import 'some[\"addr\"]' id", "state rm 'some[\"addr\"]'"} {
+		for _, cmdName := range []string{"plan", "apply", "import 'some[\"addr\"]' id", "state rm 'some[\"addr\"]'", "force-unlock-state 1234-abcd"} {
 			comment := fmt.Sprintf("atlantis %s %s", cmdName, test.flags)
 			t.Run(comment, func(t *testing.T) {
 				r := commentParser.Parse(comment, models.Github)
@@ -745,6 +1027,14 @@ func TestParse_Parsing(t *testing.T) {
 					Assert(t, r.Command.SubName == "rm", "did not parse comment %q as state rm subcommand", comment)
 					Assert(t, expExtraArgs == actExtraArgs, "exp extra args to equal %v but got %v for comment %q", expExtraArgs, actExtraArgs, comment)
 				}
+				if strings.HasPrefix(cmdName, "force-unlock-state") {
+					expExtraArgs := "1234-abcd" // force-unlock-state use default args with the lock ID
+					if test.expExtraArgs != "" {
+						expExtraArgs = fmt.Sprintf("%s %s", test.expExtraArgs, expExtraArgs)
+					}
+					Assert(t, r.Command.Name == command.ForceUnlockState, "did not parse comment %q as force-unlock-state command", comment)
+					Assert(t, expExtraArgs == actExtraArgs, "exp extra args to equal %v but got %v for comment %q", expExtraArgs, actExtraArgs, comment)
+				}
 			})
 		}
 	}
@@ -925,6 +1215,23 @@ Commands:
   state rm ADDRESS...
            Runs 'terraform state rm' for the passed address resource.
            To remove a specific project resource, use the -d, -w and -p flags.
+  state list [ADDRESS...]
+           Runs 'terraform state list' to list the resources in the state.
+  state show ADDRESS
+           Runs 'terraform state show' for the passed address resource.
+           Sensitive attributes are redacted. Both must be allowed by the
+           server-side repo config.
+  force-unlock-state LOCK_ID
+           Runs 'terraform force-unlock' to release a stale Terraform state lock.
+           Use the -d, -w and -p flags to select the project.
+  rollback Plans a return to the state recorded before the last apply.
+           Use the -d, -w and -p flags to select the project. Must be
+           enabled by the server-side repo config.
+  silence  Stops commenting the results of a project on this pull request.
+           Commit statuses and job logs are still updated. Use the -d, -w
+           and -p flags to select the project and --undo to unsilence it.
+  ping     Comments back and sets a commit status without running Terraform.
+           Use it to check the webhooks and credentials of a repo.
   help     View help.
 
 Flags:
@@ -1048,13 +1355,18 @@ func TestParse_VCSUsername(t *testing.T) {
 }
 
 var PlanUsage = `Usage of plan:
-  -d, --dir string         Which directory to run plan in relative to root of repo,
-                           ex. 'child/dir'.
-  -p, --project string     Which project to run plan for. Refers to the name of the
-                           project configured in a repo config file. Cannot be used
-                           at same time as workspace or dir flags.
-      --verbose            Append Atlantis log to comment.
-  -w, --workspace string   Switch to this Terraform workspace before planning.
+  -d, --dir string           Which directory to run plan in relative to root of
+                             repo, ex. 'child/dir'.
+      --label stringArray    Plan the projects with this label, ex. tier=prod, can
+                             be repeated. Cannot be used at same time as project,
+                             workspace or dir flags.
+  -p, --project string       Which project to run plan for. Refers to the name of
+                             the project configured in a repo config file. Cannot be
+                             used at same time as workspace or dir flags.
+      --target stringArray   Limit the plan to this resource address, can be
+                             repeated. Must be allowed by the server-side repo config.
+      --verbose              Append Atlantis log to comment.
+  -w, --workspace string     Switch to this Terraform workspace before planning.
 `
 
 var ApplyUsage = `Usage of apply:
@@ -1062,12 +1374,24 @@ var ApplyUsage = `Usage of apply:
       --auto-merge-method string   Specifies the merge method for the VCS if
                                    automerge is enabled. (Currently only implemented
                                    for GitHub)
+      --confirm-destroy            Confirm applying plans that destroy or replace
+                                   resources protected by the server-side repo config.
+      --continue-on-error          Keep applying projects after one fails.
   -d, --dir string                 Apply the plan for this directory, relative to
                                    root of repo, ex. 'child/dir'.
+      --label stringArray          Apply the plans of the projects with this label,
+                                   ex. tier=prod, can be repeated. Cannot be used at
+                                   same time as project, workspace or dir flags.
+      --override-apply-window      Apply projects outside their apply windows. Only
+                                   allowed for apply window admins.
   -p, --project string             Apply the plan for this project. Refers to the
                                    name of the project configured in a repo config
                                    file. Cannot be used at same time as workspace or
                                    dir flags.
+      --promote-canary             Promote applied canaries and apply the projects
+                                   waiting for them.
+      --target stringArray         Only apply plans that were created with exactly
+                                   these targets, can be repeated.
       --verbose                    Append Atlantis log to comment.
   -w, --workspace string           Apply the plan for this Terraform workspace.
 `
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// The states of a comment command that can be reacted to on its comment.
+const (
+	// ReactionQueued is the state of a command once its comment is received.
+	ReactionQueued = "queued"
+	// ReactionRunning is the state of a command once it passed the
+	// permission checks and starts running.
+	ReactionRunning = "running"
+	// ReactionSuccess is the state of a command that succeeded.
+	ReactionSuccess = "success"
+	// ReactionFailure is the state of a command that errored or failed.
+	ReactionFailure = "failure"
+)
+
+var reactionStates = []string{ReactionQueued, ReactionRunning, ReactionSuccess, ReactionFailure}
+
+// CommentReactions are the reactions added to the comment that triggered a
+// command as the command progresses. A reaction can be set for a state of all
+// commands and overridden for a state of one command.
+type CommentReactions struct {
+	// reactions maps a state, ex. success, or a command and a state, ex.
+	// apply.success, to its reaction.
+	reactions map[string]string
+}
+
+// ParseCommentReactions parses a comma separated list of state:reaction and
+// command.state:reaction entries, ex.
+// running:hourglass,success:rocket,failure:confused,apply.success:tada.
+func ParseCommentReactions(reactions string) (CommentReactions, error) {
+	parsed := CommentReactions{reactions: make(map[string]string)}
+	for _, entry := range strings.Split(reactions, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, reaction, ok := strings.Cut(entry, ":")
+		if !ok || reaction == "" {
+			return CommentReactions{}, fmt.Errorf("invalid reaction %q: must be of the form state:reaction or command.state:reaction, ex. apply.success:rocket", entry)
+		}
+		state := key
+		if cmdName, cmdState, ok := strings.Cut(key, "."); ok {
+			if _, err := command.ParseCommandName(cmdName); err != nil {
+				return CommentReactions{}, fmt.Errorf("invalid reaction %q: %w", entry, err)
+			}
+			state = cmdState
+		}
+		if !slices.Contains(reactionStates, state) {
+			return CommentReactions{}, fmt.Errorf("invalid reaction %q: state must be one of %s", entry, strings.Join(reactionStates, ", "))
+		}
+		parsed.reactions[key] = reaction
+	}
+	return parsed, nil
+}
+
+// Reaction returns the reaction for state of cmd or "" if there's none. If
+// cmd is nil, ex. for a comment that doesn't run a command, the reaction for
+// all commands is returned.
+func (r CommentReactions) Reaction(cmd *CommentCommand, state string) string {
+	if cmd != nil {
+		if reaction, ok := r.reactions[cmd.Name.String()+"."+state]; ok {
+			return reaction
+		}
+	}
+	return r.reactions[state]
+}
+
+// React adds the reaction for state to the comment cmd was parsed from.
+// Commands without a comment, ex. those run by a change set, are skipped.
+func (r CommentReactions) React(logger logging.SimpleLogging, vcsClient vcs.Client, repo models.Repo, pullNum int, cmd *CommentCommand, state string) {
+	if cmd.CommentID == 0 {
+		return
+	}
+	reaction := r.Reaction(cmd, state)
+	if reaction == "" {
+		return
+	}
+	if err := vcsClient.ReactToComment(logger, repo, pullNum, cmd.CommentID, reaction); err != nil {
+		logger.Warn("Failed to react to comment: %s", err)
+	}
+}
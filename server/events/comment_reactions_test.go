@@ -0,0 +1,48 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestParseCommentReactions(t *testing.T) {
+	reactions, err := events.ParseCommentReactions("queued:eyes, running:hourglass,success:rocket,apply.success:tada,plan.failure:-1")
+	Ok(t, err)
+	plan := &events.CommentCommand{Name: command.Plan}
+	apply := &events.CommentCommand{Name: command.Apply}
+	cases := []struct {
+		cmd         *events.CommentCommand
+		state       string
+		expReaction string
+	}{
+		{nil, events.ReactionQueued, "eyes"},
+		{plan, events.ReactionQueued, "eyes"},
+		{apply, events.ReactionRunning, "hourglass"},
+		{plan, events.ReactionSuccess, "rocket"},
+		{apply, events.ReactionSuccess, "tada"},
+		{plan, events.ReactionFailure, "-1"},
+		{apply, events.ReactionFailure, ""},
+	}
+	for _, c := range cases {
+		Equals(t, c.expReaction, reactions.Reaction(c.cmd, c.state))
+	}
+}
+
+func TestParseCommentReactions_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"success":             `invalid reaction "success": must be of the form state:reaction or command.state:reaction, ex. apply.success:rocket`,
+		"success:":            `invalid reaction "success:": must be of the form state:reaction or command.state:reaction, ex. apply.success:rocket`,
+		"done:rocket":         `invalid reaction "done:rocket": state must be one of queued, running, success, failure`,
+		"deploy.success:tada": `invalid reaction "deploy.success:tada": unknown command name: deploy`,
+		"apply.done:tada":     `invalid reaction "apply.done:tada": state must be one of queued, running, success, failure`,
+	}
+	for reactions, expErr := range cases {
+		t.Run(reactions, func(t *testing.T) {
+			_, err := events.ParseCommentReactions(reactions)
+			ErrEquals(t, expErr, err)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// commentTemplateData is the data comment templates are rendered with.
+type commentTemplateData struct {
+	// Command is the name of the command, ex. plan.
+	Command    string
+	SubCommand string
+	Repo       models.Repo
+	Pull       models.PullRequest
+	User       models.User
+	Projects   []commentTemplateProject
+	// Succeeded is true if the command succeeded for every project.
+	Succeeded bool
+}
+
+type commentTemplateProject struct {
+	Name       string
+	RepoRelDir string
+	Workspace  string
+	Succeeded  bool
+}
+
+// addCommentTemplates renders the comment templates of the repo of ctx
+// above and below comment. Templates that fail to render are logged and left
+// out so the results are still commented.
+func addCommentTemplates(ctx *command.Context, commentTemplates *valid.CommentTemplates, cmd PullCommand, res command.Result, comment string) string {
+	if commentTemplates == nil {
+		return comment
+	}
+	data := commentTemplateData{
+		Command:    cmd.CommandName().String(),
+		SubCommand: cmd.SubCommandName(),
+		Repo:       ctx.Pull.BaseRepo,
+		Pull:       ctx.Pull,
+		User:       ctx.User,
+		Succeeded:  !res.HasErrors(),
+	}
+	for _, result := range res.ProjectResults {
+		data.Projects = append(data.Projects, commentTemplateProject{
+			Name:       result.ProjectName,
+			RepoRelDir: result.RepoRelDir,
+			Workspace:  result.Workspace,
+			Succeeded:  result.IsSuccessful(),
+		})
+	}
+
+	parts := []string{
+		renderCommentTemplate(ctx, "header", commentTemplates.Header, data),
+		comment,
+		renderCommentTemplate(ctx, "footer", commentTemplates.Footer, data),
+	}
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "\n\n")
+}
+
+func renderCommentTemplate(ctx *command.Context, name string, text string, data commentTemplateData) string {
+	if text == "" {
+		return ""
+	}
+	tmpl, err := valid.ParseCommentTemplate(name, text)
+	if err != nil {
+		ctx.Log.Warn("unable to parse comment %s: %s", name, err)
+		return ""
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		ctx.Log.Warn("unable to render comment %s: %s", name, err)
+		return ""
+	}
+	return strings.TrimSpace(buf.String())
+}
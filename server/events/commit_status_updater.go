@@ -14,8 +14,11 @@
 package events
 
 import (
+	"bytes"
 	"fmt"
+	"text/template"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -46,6 +49,44 @@ type DefaultCommitStatusUpdater struct {
 	Client vcs.Client
 	// StatusName is the name used to identify Atlantis when creating PR statuses.
 	StatusName string
+	// ProjectStatusTemplate generates the names of project statuses from
+	// ProjectStatusTemplateData. If it's nil, they're named
+	// <StatusName>/<command>: <project>.
+	ProjectStatusTemplate *template.Template
+	// AggregateStatuses disables project statuses so only the combined status
+	// of each command is posted. Some hosts cap the number of statuses that
+	// branch protection can require.
+	AggregateStatuses bool
+	// CheckRunClient reports statuses as check runs for GitHub repos whose
+	// github_status_mode is checks or both. If nil, only commit statuses are
+	// posted.
+	CheckRunClient GithubCheckRunClient
+	// GlobalCfgStore holds the server-side repo config, read for the GitHub
+	// status mode of each repo.
+	GlobalCfgStore *valid.GlobalCfgStore
+}
+
+// GithubCheckRunClient reports the status of commands as GitHub check runs.
+type GithubCheckRunClient interface {
+	UpdateCheckRun(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, name string, description string, url string) error
+}
+
+// ProjectStatusTemplateData is the data available to the project status
+// template.
+type ProjectStatusTemplateData struct {
+	StatusName string
+	// Command is the name of the command, ex. plan.
+	Command string
+	// Repo is the full name of the base repo, ex. owner/repo.
+	Repo        string
+	ProjectName string
+	Dir         string
+	Workspace   string
+	// Project is the project name, or <dir>/<workspace> if the project
+	// doesn't have a name.
+	Project string
+	// Labels are the labels of the project, ex. tier: prod.
+	Labels map[string]string
 }
 
 // ensure DefaultCommitStatusUpdater implements runtime.StatusUpdater interface
@@ -63,7 +104,7 @@ func (d *DefaultCommitStatusUpdater) UpdateCombined(logger logging.SimpleLogging
 	case models.SuccessCommitStatus:
 		descripWords = genProjectStatusDescription(cmdName.String(), "succeeded.")
 	}
-	return d.Client.UpdateStatus(logger, repo, pull, status, src, descripWords, "")
+	return d.updateStatus(logger, repo, pull, status, src, descripWords, "")
 }
 
 func (d *DefaultCommitStatusUpdater) UpdateCombinedCount(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, status models.CommitStatus, cmdName command.Name, numSuccess int, numTotal int) error {
@@ -79,15 +120,17 @@ func (d *DefaultCommitStatusUpdater) UpdateCombinedCount(logger logging.SimpleLo
 		cmdVerb = "applied"
 	}
 
-	return d.Client.UpdateStatus(logger, repo, pull, status, src, fmt.Sprintf("%d/%d projects %s successfully.", numSuccess, numTotal, cmdVerb), "")
+	return d.updateStatus(logger, repo, pull, status, src, fmt.Sprintf("%d/%d projects %s successfully.", numSuccess, numTotal, cmdVerb), "")
 }
 
 func (d *DefaultCommitStatusUpdater) UpdateProject(ctx command.ProjectContext, cmdName command.Name, status models.CommitStatus, url string, result *command.ProjectResult) error {
-	projectID := ctx.ProjectName
-	if projectID == "" {
-		projectID = fmt.Sprintf("%s/%s", ctx.RepoRelDir, ctx.Workspace)
+	if d.AggregateStatuses {
+		return nil
+	}
+	src, err := d.projectStatusName(ctx, cmdName)
+	if err != nil {
+		return err
 	}
-	src := fmt.Sprintf("%s/%s: %s", d.StatusName, cmdName.String(), projectID)
 	var descripWords string
 	switch status {
 	case models.PendingCommitStatus:
@@ -101,7 +144,59 @@ func (d *DefaultCommitStatusUpdater) UpdateProject(ctx command.ProjectContext, c
 			descripWords = genProjectStatusDescription(cmdName.String(), "succeeded.")
 		}
 	}
-	return d.Client.UpdateStatus(ctx.Log, ctx.BaseRepo, ctx.Pull, status, src, descripWords, url)
+	return d.updateStatus(ctx.Log, ctx.BaseRepo, ctx.Pull, status, src, descripWords, url)
+}
+
+// projectStatusName returns the name of the status of a project.
+func (d *DefaultCommitStatusUpdater) projectStatusName(ctx command.ProjectContext, cmdName command.Name) (string, error) {
+	projectID := ctx.ProjectName
+	if projectID == "" {
+		projectID = fmt.Sprintf("%s/%s", ctx.RepoRelDir, ctx.Workspace)
+	}
+	if d.ProjectStatusTemplate == nil {
+		return fmt.Sprintf("%s/%s: %s", d.StatusName, cmdName.String(), projectID), nil
+	}
+
+	var buf bytes.Buffer
+	err := d.ProjectStatusTemplate.Execute(&buf, ProjectStatusTemplateData{
+		StatusName:  d.StatusName,
+		Command:     cmdName.String(),
+		Repo:        ctx.BaseRepo.FullName,
+		ProjectName: ctx.ProjectName,
+		Dir:         ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		Project:     projectID,
+		Labels:      ctx.Labels,
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing project status template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// updateStatus updates the commit status unless pull is an issue in issue-ops
+// mode, in which case there is no head commit to attach the status to. On
+// GitHub the status is reported as a commit status, a check run or both
+// depending on the repo's github_status_mode.
+func (d *DefaultCommitStatusUpdater) updateStatus(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
+	if pull.IsIssue {
+		logger.Debug("not updating %s status since this is an issue", src)
+		return nil
+	}
+	if repo.VCSHost.Type != models.Github || d.CheckRunClient == nil {
+		return d.Client.UpdateStatus(logger, repo, pull, state, src, description, url)
+	}
+
+	mode := d.GlobalCfgStore.Load().GithubStatusMode(repo.ID())
+	if mode != valid.GithubStatusModeChecks {
+		if err := d.Client.UpdateStatus(logger, repo, pull, state, src, description, url); err != nil {
+			return err
+		}
+	}
+	if mode != valid.GithubStatusModeStatuses {
+		return d.CheckRunClient.UpdateCheckRun(logger, repo, pull, state, src, description, url)
+	}
+	return nil
 }
 
 func genProjectStatusDescription(cmdName, description string) string {
@@ -133,5 +228,5 @@ func (d *DefaultCommitStatusUpdater) updateWorkflowHook(log logging.SimpleLoggin
 		}
 	}
 
-	return d.Client.UpdateStatus(log, pull.BaseRepo, pull, status, src, descripWords, url)
+	return d.updateStatus(log, pull.BaseRepo, pull, status, src, descripWords, url)
 }
@@ -16,8 +16,10 @@ package events_test
 import (
 	"fmt"
 	"testing"
+	"text/template"
 
 	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -264,3 +266,109 @@ func TestDefaultCommitStatusUpdater_UpdateProjectCustomStatusName(t *testing.T)
 	client.VerifyWasCalledOnce().UpdateStatus(Any[logging.SimpleLogging](), Eq(models.Repo{}), Eq(models.PullRequest{}),
 		Eq(models.SuccessCommitStatus), Eq("custom/apply: ./default"), Eq("Apply succeeded."), Eq("url"))
 }
+
+func TestDefaultCommitStatusUpdater_UpdateProjectStatusTemplate(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockClient()
+	s := events.DefaultCommitStatusUpdater{
+		Client:                client,
+		StatusName:            "atlantis",
+		ProjectStatusTemplate: template.Must(template.New("").Parse("{{.StatusName}}/{{.Command}}: {{.Repo}} {{.Dir}} ({{.Workspace}})")),
+	}
+	err := s.UpdateProject(command.ProjectContext{
+		BaseRepo:   models.Repo{FullName: "owner/repo"},
+		RepoRelDir: "dir",
+		Workspace:  "staging",
+	}, command.Plan, models.PendingCommitStatus, "url", nil)
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(Any[logging.SimpleLogging](), Eq(models.Repo{FullName: "owner/repo"}), Eq(models.PullRequest{}),
+		Eq(models.PendingCommitStatus), Eq("atlantis/plan: owner/repo dir (staging)"), Eq("Plan in progress..."), Eq("url"))
+}
+
+func TestDefaultCommitStatusUpdater_UpdateProjectStatusTemplateLabels(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockClient()
+	s := events.DefaultCommitStatusUpdater{
+		Client:                client,
+		StatusName:            "atlantis",
+		ProjectStatusTemplate: template.Must(template.New("").Parse(`{{.StatusName}}/{{.Command}}: {{.Project}} [{{index .Labels "tier"}}]{{index .Labels "team"}}`)),
+	}
+	err := s.UpdateProject(command.ProjectContext{
+		ProjectName: "network",
+		Labels:      map[string]string{"tier": "prod"},
+	}, command.Plan, models.PendingCommitStatus, "url", nil)
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Eq(models.PendingCommitStatus), Eq("atlantis/plan: network [prod]"), Eq("Plan in progress..."), Eq("url"))
+}
+
+func TestDefaultCommitStatusUpdater_AggregateStatuses(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockClient()
+	s := events.DefaultCommitStatusUpdater{Client: client, StatusName: "atlantis", AggregateStatuses: true}
+	err := s.UpdateProject(command.ProjectContext{
+		RepoRelDir: ".",
+		Workspace:  "default",
+	}, command.Plan, models.PendingCommitStatus, "url", nil)
+	Ok(t, err)
+	client.VerifyWasCalled(Never()).UpdateStatus(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Any[models.CommitStatus](), Any[string](), Any[string](), Any[string]())
+
+	// The combined status is still posted.
+	err = s.UpdateCombined(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{}, models.PendingCommitStatus, command.Plan)
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Eq(models.PendingCommitStatus), Eq("atlantis/plan"), Eq("Plan in progress..."), Eq(""))
+}
+
+type fakeCheckRunClient struct {
+	names []string
+}
+
+func (f *fakeCheckRunClient) UpdateCheckRun(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest, _ models.CommitStatus, name string, _ string, _ string) error {
+	f.names = append(f.names, name)
+	return nil
+}
+
+func TestDefaultCommitStatusUpdater_GithubStatusMode(t *testing.T) {
+	githubRepo := models.Repo{
+		FullName: "owner/repo",
+		VCSHost:  models.VCSHost{Type: models.Github, Hostname: "github.com"},
+	}
+	gitlabRepo := models.Repo{
+		FullName: "owner/repo",
+		VCSHost:  models.VCSHost{Type: models.Gitlab, Hostname: "gitlab.com"},
+	}
+	cases := []struct {
+		mode         string
+		repo         models.Repo
+		expStatuses  int
+		expCheckRuns []string
+	}{
+		{mode: "", repo: githubRepo, expStatuses: 1},
+		{mode: valid.GithubStatusModeStatuses, repo: githubRepo, expStatuses: 1},
+		{mode: valid.GithubStatusModeChecks, repo: githubRepo, expCheckRuns: []string{"atlantis/plan"}},
+		{mode: valid.GithubStatusModeBoth, repo: githubRepo, expStatuses: 1, expCheckRuns: []string{"atlantis/plan"}},
+		{mode: valid.GithubStatusModeChecks, repo: gitlabRepo, expStatuses: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.mode+" "+c.repo.VCSHost.Hostname, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			client := mocks.NewMockClient()
+			checkRuns := &fakeCheckRunClient{}
+			globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+			globalCfg.Repos[0].GithubStatusMode = c.mode
+			s := events.DefaultCommitStatusUpdater{
+				Client:         client,
+				StatusName:     "atlantis",
+				CheckRunClient: checkRuns,
+				GlobalCfgStore: valid.NewGlobalCfgStore(globalCfg),
+			}
+			err := s.UpdateCombined(logging.NewNoopLogger(t), c.repo, models.PullRequest{}, models.PendingCommitStatus, command.Plan)
+			Ok(t, err)
+			client.VerifyWasCalled(Times(c.expStatuses)).UpdateStatus(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+				Any[models.CommitStatus](), Any[string](), Any[string](), Any[string]())
+			Equals(t, c.expCheckRuns, checkRuns.names)
+		})
+	}
+}
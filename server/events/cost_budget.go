@@ -0,0 +1,135 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// costEstimate is the part of Infracost's JSON output the cost budget is
+// checked against. Costs are decimal strings and are null if unknown.
+type costEstimate struct {
+	Currency             string  `json:"currency"`
+	TotalMonthlyCost     *string `json:"totalMonthlyCost"`
+	PastTotalMonthlyCost *string `json:"pastTotalMonthlyCost"`
+	DiffTotalMonthlyCost *string `json:"diffTotalMonthlyCost"`
+}
+
+// parseCost returns the value of a cost or 0 if it's unknown.
+func parseCost(name string, cost *string) (float64, error) {
+	if cost == nil || *cost == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(*cost, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s %q: %w", name, *cost, err)
+	}
+	return value, nil
+}
+
+// costBudgetBreaches returns how the estimate exceeds budget or nil if it's
+// within budget. The percentage isn't checked if there's no past cost.
+func costBudgetBreaches(budget valid.CostBudget, estimateJSON []byte) ([]string, error) {
+	var estimate costEstimate
+	if err := json.Unmarshal(estimateJSON, &estimate); err != nil {
+		return nil, fmt.Errorf("parsing cost estimate: %w", err)
+	}
+	past, err := parseCost("pastTotalMonthlyCost", estimate.PastTotalMonthlyCost)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := parseCost("diffTotalMonthlyCost", estimate.DiffTotalMonthlyCost)
+	if err != nil {
+		return nil, err
+	}
+	if estimate.DiffTotalMonthlyCost == nil {
+		total, err := parseCost("totalMonthlyCost", estimate.TotalMonthlyCost)
+		if err != nil {
+			return nil, err
+		}
+		diff = total - past
+	}
+	currency := estimate.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	var breaches []string
+	if budget.MaxMonthlyIncrease != nil && diff > *budget.MaxMonthlyIncrease {
+		breaches = append(breaches, fmt.Sprintf("the monthly cost increases by %.2f %s, more than the allowed %.2f %s", diff, currency, *budget.MaxMonthlyIncrease, currency))
+	}
+	if budget.MaxPercentIncrease != nil && past > 0 {
+		if percent := diff / past * 100; percent > *budget.MaxPercentIncrease {
+			breaches = append(breaches, fmt.Sprintf("the monthly cost increases by %.1f%% from %.2f %s, more than the allowed %.1f%%", percent, past, currency, *budget.MaxPercentIncrease))
+		}
+	}
+	return breaches, nil
+}
+
+// isCostBudgetOverrider returns true if the user applying the project, or the
+// user who approved the pull request, may override the cost budget.
+func (p *DefaultProjectCommandRunner) isCostBudgetOverrider(ctx command.ProjectContext) (bool, error) {
+	overriders := ctx.CostBudget.Overriders()
+	if len(overriders.Users) == 0 && len(overriders.Teams) == 0 {
+		return false, nil
+	}
+	isOverrider, err := p.isProjectOwner(ctx, overriders, ctx.User)
+	if err != nil || isOverrider {
+		return isOverrider, err
+	}
+	approval := ctx.PullReqStatus.ApprovalStatus
+	if approval.IsApproved && approval.ApprovedBy != "" {
+		return p.isProjectOwner(ctx, overriders, models.User{Username: approval.ApprovedBy})
+	}
+	return false, nil
+}
+
+// checkCostBudget returns a failure if the project has a cost budget and the
+// cost estimate written by the plan exceeds it, unless the apply was run or
+// the pull request approved by one of the budget's overriders. Projects
+// without an estimate fail so the budget can't be bypassed by skipping it.
+func (p *DefaultProjectCommandRunner) checkCostBudget(ctx command.ProjectContext, absPath string) (string, error) {
+	if ctx.CostBudget == nil {
+		return "", nil
+	}
+	estimateJSON, err := os.ReadFile(filepath.Join(absPath, ctx.GetCostEstimateFileName()))
+	if errors.Is(err, os.ErrNotExist) {
+		return "This project has a cost budget but its plan has no cost estimate. The plan workflow must write an Infracost JSON estimate to `$COST_ESTIMATE_FILE`.", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading cost estimate: %w", err)
+	}
+	breaches, err := costBudgetBreaches(*ctx.CostBudget, estimateJSON)
+	if err != nil || len(breaches) == 0 {
+		return "", err
+	}
+
+	isOverrider, err := p.isCostBudgetOverrider(ctx)
+	if err != nil {
+		return "", err
+	}
+	if isOverrider {
+		ctx.Log.Info("applying plan over the cost budget, %s, overridden by %s", strings.Join(breaches, " and "), ctx.User.Username)
+		return "", nil
+	}
+
+	failure := fmt.Sprintf("This plan exceeds the cost budget: %s", strings.Join(breaches, " and "))
+	overriders := ctx.CostBudget.Overriders()
+	var names []string
+	for _, user := range overriders.Users {
+		names = append(names, "@"+user)
+	}
+	names = append(names, overriders.Teams...)
+	if len(names) > 0 {
+		failure += fmt.Sprintf(". To apply it anyway, one of %s must run apply or approve the pull request", strings.Join(names, ", "))
+	}
+	return failure + ".", nil
+}
@@ -0,0 +1,134 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCostBudgetBreaches(t *testing.T) {
+	maxMonthly := 100.0
+	maxPercent := 50.0
+	budget := valid.CostBudget{MaxMonthlyIncrease: &maxMonthly, MaxPercentIncrease: &maxPercent}
+	cases := []struct {
+		description string
+		estimate    string
+		exp         []string
+	}{
+		{
+			description: "within budget",
+			estimate:    `{"currency":"USD","totalMonthlyCost":"250","pastTotalMonthlyCost":"200","diffTotalMonthlyCost":"50"}`,
+		},
+		{
+			description: "cost decreases",
+			estimate:    `{"currency":"USD","totalMonthlyCost":"0","pastTotalMonthlyCost":"1000","diffTotalMonthlyCost":"-1000"}`,
+		},
+		{
+			description: "both exceeded",
+			estimate:    `{"currency":"EUR","totalMonthlyCost":"350","pastTotalMonthlyCost":"200","diffTotalMonthlyCost":"150"}`,
+			exp: []string{
+				"the monthly cost increases by 150.00 EUR, more than the allowed 100.00 EUR",
+				"the monthly cost increases by 75.0% from 200.00 EUR, more than the allowed 50.0%",
+			},
+		},
+		{
+			description: "percentage skipped without past cost",
+			estimate:    `{"totalMonthlyCost":"80","pastTotalMonthlyCost":"0","diffTotalMonthlyCost":"80"}`,
+		},
+		{
+			description: "diff computed from totals",
+			estimate:    `{"totalMonthlyCost":"120","pastTotalMonthlyCost":null}`,
+			exp:         []string{"the monthly cost increases by 120.00 USD, more than the allowed 100.00 USD"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			breaches, err := costBudgetBreaches(budget, []byte(c.estimate))
+			Ok(t, err)
+			Equals(t, c.exp, breaches)
+		})
+	}
+
+	_, err := costBudgetBreaches(budget, []byte(`{"diffTotalMonthlyCost":"lots"}`))
+	ErrContains(t, `parsing diffTotalMonthlyCost "lots"`, err)
+}
+
+func TestCheckCostBudget(t *testing.T) {
+	maxMonthly := 100.0
+	budget := &valid.CostBudget{MaxMonthlyIncrease: &maxMonthly, OverrideUsers: []string{"alice"}}
+	overBudget := `{"currency":"USD","totalMonthlyCost":"300","pastTotalMonthlyCost":"100","diffTotalMonthlyCost":"200"}`
+	cases := []struct {
+		description string
+		budget      *valid.CostBudget
+		estimate    string
+		user        string
+		approvedBy  string
+		expFailure  string
+	}{
+		{
+			description: "no budget",
+			user:        "bob",
+		},
+		{
+			description: "no estimate",
+			budget:      budget,
+			user:        "bob",
+			expFailure:  "This project has a cost budget but its plan has no cost estimate. The plan workflow must write an Infracost JSON estimate to `$COST_ESTIMATE_FILE`.",
+		},
+		{
+			description: "over budget",
+			budget:      budget,
+			estimate:    overBudget,
+			user:        "bob",
+			expFailure:  "This plan exceeds the cost budget: the monthly cost increases by 200.00 USD, more than the allowed 100.00 USD. To apply it anyway, one of @alice must run apply or approve the pull request.",
+		},
+		{
+			description: "over budget without overriders",
+			budget:      &valid.CostBudget{MaxMonthlyIncrease: &maxMonthly},
+			estimate:    overBudget,
+			user:        "alice",
+			expFailure:  "This plan exceeds the cost budget: the monthly cost increases by 200.00 USD, more than the allowed 100.00 USD.",
+		},
+		{
+			description: "applied by overrider",
+			budget:      budget,
+			estimate:    overBudget,
+			user:        "alice",
+		},
+		{
+			description: "approved by overrider",
+			budget:      budget,
+			estimate:    overBudget,
+			user:        "bob",
+			approvedBy:  "alice",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			ctx := command.ProjectContext{
+				Log:         logging.NewNoopLogger(t),
+				Workspace:   "default",
+				ProjectName: "network",
+				CostBudget:  c.budget,
+				User:        models.User{Username: c.user},
+				PullReqStatus: models.PullReqStatus{
+					ApprovalStatus: models.ApprovalStatus{IsApproved: c.approvedBy != "", ApprovedBy: c.approvedBy},
+				},
+			}
+			dir := t.TempDir()
+			if c.estimate != "" {
+				Ok(t, os.WriteFile(filepath.Join(dir, ctx.GetCostEstimateFileName()), []byte(c.estimate), 0600))
+			}
+			runner := &DefaultProjectCommandRunner{}
+			failure, err := runner.checkCostBudget(ctx, dir)
+			Ok(t, err)
+			Equals(t, c.expFailure, failure)
+		})
+	}
+}
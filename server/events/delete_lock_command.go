@@ -31,6 +31,10 @@ func (l *DefaultDeleteLockCommand) DeleteLock(logger logging.SimpleLogging, id s
 	if lock == nil {
 		return nil, nil
 	}
+	// Lock keys don't have plans.
+	if locking.IsLockKey(lock.Project) {
+		return lock, nil
+	}
 
 	removeErr := l.WorkingDir.DeletePlan(logger, lock.Pull.BaseRepo, lock.Pull, lock.Workspace, lock.Project.Path, lock.Project.ProjectName)
 	if removeErr != nil {
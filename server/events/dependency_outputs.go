@@ -0,0 +1,176 @@
+package events
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// DependencyOutputsFilename is the tfvars file the shared outputs of the
+// projects a project depends on are written to in the project's dir when the
+// project sets dependency_outputs: tfvars. Terraform loads it automatically.
+const DependencyOutputsFilename = "atlantis_dependency_outputs.auto.tfvars.json"
+
+// minMaskedLength is the length below which sensitive values aren't masked
+// in the output of steps, since masking short values would mask unrelated
+// text. Sensitive booleans and numbers aren't masked for the same reason.
+const minMaskedLength = 4
+
+// SharedOutputsStore stores the outputs of the projects that share them with
+// the projects depending on them.
+type SharedOutputsStore interface {
+	// Save stores outputs, the output of terraform output -json after an
+	// apply of the project projectName of the repo repoFullName, replacing
+	// the previous ones.
+	Save(repoFullName string, projectName string, outputs []byte) error
+	// Get returns the outputs of the project or nil if it hasn't shared any.
+	Get(repoFullName string, projectName string) ([]byte, error)
+}
+
+// FileSharedOutputsStore stores the outputs of each project as a JSON file in
+// Dir. They're kept across pull requests since they're the outputs of the
+// latest apply.
+type FileSharedOutputsStore struct {
+	Dir string
+}
+
+func (f *FileSharedOutputsStore) Save(repoFullName string, projectName string, outputs []byte) error {
+	repoDir, err := storeRepoDir(f.Dir, repoFullName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(repoDir, 0700); err != nil {
+		return errors.Wrap(err, "creating shared outputs dir")
+	}
+	return os.WriteFile(filepath.Join(repoDir, url.PathEscape(projectName)+".json"), outputs, 0600)
+}
+
+func (f *FileSharedOutputsStore) Get(repoFullName string, projectName string) ([]byte, error) {
+	repoDir, err := storeRepoDir(f.Dir, repoFullName)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := os.ReadFile(filepath.Join(repoDir, url.PathEscape(projectName)+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return outputs, err
+}
+
+// terraformOutput is an output of terraform output -json.
+type terraformOutput struct {
+	Sensitive bool            `json:"sensitive"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// invalidVarNameChars matches the characters that can't be part of the name
+// of a terraform variable.
+var invalidVarNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// dependencyOutputVarName is the name of the variable the output named output
+// of the project projectName is passed as, ex. network_vpc_id.
+func dependencyOutputVarName(projectName string, output string) string {
+	return invalidVarNameChars.ReplaceAllString(projectName+"_"+output, "_")
+}
+
+// addDependencyOutputs passes the shared outputs of the projects ctx depends
+// on to terraform if the project opted in, like addPRMetadataVars. It returns
+// the values of the sensitive outputs to mask in the output of the steps.
+// Dependencies that haven't shared outputs are skipped.
+func addDependencyOutputs(ctx command.ProjectContext, store SharedOutputsStore, absPath string, envs map[string]string) ([]string, error) {
+	if ctx.DependencyOutputs == "" || store == nil {
+		return nil, nil
+	}
+
+	vars := make(map[string]json.RawMessage)
+	var sensitive []string
+	for _, dependency := range ctx.DependsOn {
+		contents, err := store.Get(ctx.BaseRepo.FullName, dependency)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading the shared outputs of %s", dependency)
+		}
+		if contents == nil {
+			ctx.Log.Warn("project %s hasn't shared outputs, it must set share_outputs and be applied", dependency)
+			continue
+		}
+		var outputs map[string]terraformOutput
+		if err := json.Unmarshal(contents, &outputs); err != nil {
+			return nil, errors.Wrapf(err, "parsing the shared outputs of %s", dependency)
+		}
+		for name, output := range outputs {
+			vars[dependencyOutputVarName(dependency, name)] = output.Value
+			if output.Sensitive && !isScalarJSON(output.Value) {
+				sensitive = append(sensitive, outputVarValue(output.Value))
+			}
+		}
+	}
+
+	if ctx.DependencyOutputs == valid.PRMetadataVarsTFVars {
+		contents, err := json.MarshalIndent(vars, "", "  ")
+		if err != nil {
+			return nil, errors.Wrap(err, "encoding dependency outputs")
+		}
+		return sensitive, errors.Wrap(os.WriteFile(filepath.Join(absPath, DependencyOutputsFilename), contents, 0600), "writing dependency outputs")
+	}
+
+	for name, value := range vars {
+		envs["TF_VAR_"+name] = outputVarValue(value)
+	}
+	return sensitive, nil
+}
+
+// outputVarValue returns value as a TF_VAR_ env var value. Strings are
+// passed as is and other values as JSON, which is a subset of HCL.
+func outputVarValue(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	return string(value)
+}
+
+// isScalarJSON returns true if value is a JSON boolean, number or null.
+func isScalarJSON(value json.RawMessage) bool {
+	trimmed := strings.TrimSpace(string(value))
+	return trimmed == "" || !strings.ContainsAny(trimmed[:1], `"[{`)
+}
+
+// maskSensitiveOutputs replaces the values of sensitive outputs in out.
+func maskSensitiveOutputs(out string, sensitive []string) string {
+	for _, value := range sensitive {
+		if len(value) >= minMaskedLength {
+			out = strings.ReplaceAll(out, value, "(sensitive value)")
+		}
+	}
+	return out
+}
+
+// saveSharedOutputs stores the outputs of an applied project that shares
+// them. The apply has already changed infrastructure so errors are logged
+// instead of failing it.
+func (p *DefaultProjectCommandRunner) saveSharedOutputs(ctx command.ProjectContext, absPath string) {
+	if !ctx.ShareOutputs || p.SharedOutputsStore == nil || p.OutputStepRunner == nil || !valid.UsesTerraform(ctx.Tool) {
+		return
+	}
+	if ctx.ProjectName == "" {
+		ctx.Log.Warn("not sharing outputs of the project at dir %s: share_outputs requires the project to be named", ctx.RepoRelDir)
+		return
+	}
+	outputs, err := p.OutputStepRunner.Run(ctx, nil, absPath, map[string]string{})
+	if err != nil {
+		ctx.Log.Err("getting outputs to share: %s", err)
+		return
+	}
+	if err := p.SharedOutputsStore.Save(ctx.BaseRepo.FullName, ctx.ProjectName, []byte(outputs)); err != nil {
+		ctx.Log.Err("saving shared outputs: %s", err)
+		return
+	}
+	ctx.Log.Info("shared the outputs of project %s", ctx.ProjectName)
+}
@@ -0,0 +1,108 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const networkOutputs = `{
+  "vpc_id": {"sensitive": false, "type": "string", "value": "vpc-123"},
+  "subnet_ids": {"sensitive": false, "type": ["list", "string"], "value": ["subnet-a", "subnet-b"]},
+  "db_password": {"sensitive": true, "type": "string", "value": "hunter2-secret"},
+  "db_port": {"sensitive": true, "type": "number", "value": 5432}
+}`
+
+func dependencyOutputsCtx(t *testing.T, mode string) command.ProjectContext {
+	return command.ProjectContext{
+		Log:               logging.NewNoopLogger(t),
+		BaseRepo:          models.Repo{FullName: "owner/repo"},
+		ProjectName:       "app",
+		DependsOn:         []string{"network", "dns/prod"},
+		DependencyOutputs: mode,
+	}
+}
+
+func TestFileSharedOutputsStore(t *testing.T) {
+	store := &FileSharedOutputsStore{Dir: t.TempDir()}
+
+	outputs, err := store.Get("owner/repo", "dns/prod")
+	Ok(t, err)
+	Assert(t, outputs == nil, "expected no outputs before they're shared")
+
+	Ok(t, store.Save("owner/repo", "dns/prod", []byte(`{"a":1}`)))
+	Ok(t, store.Save("owner/repo", "dns/prod", []byte(`{"b":2}`)))
+	outputs, err = store.Get("owner/repo", "dns/prod")
+	Ok(t, err)
+	Equals(t, `{"b":2}`, string(outputs))
+
+	Assert(t, store.Save("../repo", "dns", nil) != nil, "expected an error for a repo escaping the dir")
+}
+
+func TestAddDependencyOutputs_Env(t *testing.T) {
+	store := &FileSharedOutputsStore{Dir: t.TempDir()}
+	Ok(t, store.Save("owner/repo", "network", []byte(networkOutputs)))
+	Ok(t, store.Save("owner/repo", "dns/prod", []byte(`{"zone_id": {"sensitive": false, "value": "Z123"}}`)))
+
+	envs := map[string]string{}
+	sensitive, err := addDependencyOutputs(dependencyOutputsCtx(t, valid.PRMetadataVarsEnv), store, t.TempDir(), envs)
+	Ok(t, err)
+	Equals(t, map[string]string{
+		"TF_VAR_network_vpc_id":      "vpc-123",
+		"TF_VAR_network_subnet_ids":  `["subnet-a", "subnet-b"]`,
+		"TF_VAR_network_db_password": "hunter2-secret",
+		"TF_VAR_network_db_port":     "5432",
+		"TF_VAR_dns_prod_zone_id":    "Z123",
+	}, envs)
+	Equals(t, []string{"hunter2-secret"}, sensitive)
+}
+
+func TestAddDependencyOutputs_TFVars(t *testing.T) {
+	store := &FileSharedOutputsStore{Dir: t.TempDir()}
+	Ok(t, store.Save("owner/repo", "network", []byte(networkOutputs)))
+
+	// dns/prod didn't share outputs so it's skipped.
+	dir := t.TempDir()
+	envs := map[string]string{}
+	_, err := addDependencyOutputs(dependencyOutputsCtx(t, valid.PRMetadataVarsTFVars), store, dir, envs)
+	Ok(t, err)
+	Equals(t, 0, len(envs))
+
+	contents, err := os.ReadFile(filepath.Join(dir, DependencyOutputsFilename))
+	Ok(t, err)
+	var vars map[string]interface{}
+	Ok(t, json.Unmarshal(contents, &vars))
+	Equals(t, map[string]interface{}{
+		"network_vpc_id":      "vpc-123",
+		"network_subnet_ids":  []interface{}{"subnet-a", "subnet-b"},
+		"network_db_password": "hunter2-secret",
+		"network_db_port":     float64(5432),
+	}, vars)
+}
+
+// Projects that didn't opt in don't get any variables.
+func TestAddDependencyOutputs_Disabled(t *testing.T) {
+	store := &FileSharedOutputsStore{Dir: t.TempDir()}
+	Ok(t, store.Save("owner/repo", "network", []byte(networkOutputs)))
+
+	dir := t.TempDir()
+	envs := map[string]string{}
+	sensitive, err := addDependencyOutputs(dependencyOutputsCtx(t, ""), store, dir, envs)
+	Ok(t, err)
+	Equals(t, 0, len(envs))
+	Equals(t, 0, len(sensitive))
+	_, err = os.Stat(filepath.Join(dir, DependencyOutputsFilename))
+	Assert(t, os.IsNotExist(err), "expected no tfvars file")
+}
+
+func TestMaskSensitiveOutputs(t *testing.T) {
+	Equals(t, `+ password = "(sensitive value)" + name = "abc"`,
+		maskSensitiveOutputs(`+ password = "hunter2-secret" + name = "abc"`, []string{"hunter2-secret", "abc"}))
+}
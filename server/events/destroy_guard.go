@@ -0,0 +1,85 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// destroyGuardResourceChange is a resource change in the terraform show -json
+// output.
+type destroyGuardResourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Change  struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+// findProtectedDestroys returns the addresses of the resources protected by
+// guard that are deleted or replaced by the plan. planJSON is the output of
+// terraform show -json on the planfile.
+func findProtectedDestroys(guard valid.DestroyGuard, planJSON string) ([]string, error) {
+	var plan struct {
+		ResourceChanges []destroyGuardResourceChange `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return nil, fmt.Errorf("parsing plan json: %w", err)
+	}
+	var addresses []string
+	for _, rc := range plan.ResourceChanges {
+		// Replacements have both the delete and create actions.
+		if rc.Mode == "managed" && slices.Contains(rc.Change.Actions, "delete") && guard.Protects(rc.Type) {
+			addresses = append(addresses, rc.Address)
+		}
+	}
+	return addresses, nil
+}
+
+// planProtectedDestroys returns the protected resources deleted or replaced by
+// the project's plan or nil if the project doesn't have a destroy guard.
+// Remote operations and projects of other tools than terraform don't have a
+// planfile to show so they can't be guarded.
+func (p *DefaultProjectCommandRunner) planProtectedDestroys(ctx command.ProjectContext, absPath string) ([]string, error) {
+	if ctx.DestroyGuard == nil || !valid.UsesTerraform(ctx.Tool) {
+		return nil, nil
+	}
+	out, err := p.showPlan(ctx, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("showing plan to check the destroy guard: %w", err)
+	}
+	if out == "" {
+		ctx.Log.Warn("unable to check the destroy guard because the plan can't be shown")
+		return nil, nil
+	}
+	return findProtectedDestroys(*ctx.DestroyGuard, out)
+}
+
+// checkDestroyGuard returns a failure if the project's plan deletes or replaces
+// protected resources and the apply wasn't confirmed with --confirm-destroy,
+// or was confirmed by the pull request author when the guard requires another
+// user.
+func (p *DefaultProjectCommandRunner) checkDestroyGuard(ctx command.ProjectContext, absPath string) (string, error) {
+	addresses, err := p.planProtectedDestroys(ctx, absPath)
+	if err != nil || len(addresses) == 0 {
+		return "", err
+	}
+	protected := fmt.Sprintf("`%s`", strings.Join(addresses, "`, `"))
+	if !ctx.ConfirmDestroy {
+		failure := fmt.Sprintf("This plan destroys or replaces the protected resources %s. To apply it anyway, comment `%s --%s`", protected, ctx.ApplyCmd, confirmDestroyFlagLong)
+		if ctx.DestroyGuard.RequireOtherUser {
+			failure += fmt.Sprintf(". The confirmation must come from someone other than the pull request author @%s", ctx.Pull.Author)
+		}
+		return failure + ".", nil
+	}
+	if ctx.DestroyGuard.RequireOtherUser && strings.EqualFold(ctx.User.Username, ctx.Pull.Author) {
+		return fmt.Sprintf("This plan destroys or replaces the protected resources %s. The --%s confirmation must come from someone other than the pull request author @%s.", protected, confirmDestroyFlagLong, ctx.Pull.Author), nil
+	}
+	ctx.Log.Info("applying plan that destroys or replaces protected resources %s, confirmed by %s", strings.Join(addresses, ", "), ctx.User.Username)
+	return "", nil
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ClosedPullDestroyer destroys the workspaces of closed pull requests: their
+// preview environments and the workspaces matching the destroy_on_close
+// patterns of their repo.
+type ClosedPullDestroyer interface {
+	// HasWorkspacesToDestroy returns true if pull has workspaces that may
+	// still be up.
+	HasWorkspacesToDestroy(pull models.PullRequest) bool
+	// DestroyWorkspaces plans and applies the destruction of the workspaces
+	// of pull.
+	DestroyWorkspaces(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User)
+}
+
+func (c *DefaultCommandRunner) HasWorkspacesToDestroy(pull models.PullRequest) bool {
+	pullStatus, err := c.PullStatusFetcher.GetPullStatus(pull)
+	if err != nil {
+		c.Logger.Err("fetching pull status of %s#%d: %s", pull.BaseRepo.FullName, pull.Num, err)
+		return false
+	}
+	return len(c.projectsToDestroy(pull, pullStatus)) > 0
+}
+
+func (c *DefaultCommandRunner) DestroyWorkspaces(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) {
+	if opStarted := c.Drainer.StartOp(); !opStarted {
+		c.Logger.Warn("not destroying the workspaces of %s#%d since Atlantis is shutting down", baseRepo.FullName, pull.Num)
+		return
+	}
+	defer c.Drainer.OpDone()
+
+	log := c.buildLogger(baseRepo.FullName, pull.Num, "destroy")
+	defer c.logPanics(baseRepo, pull.Num, log)
+
+	pullStatus, err := c.PullStatusFetcher.GetPullStatus(pull)
+	if err != nil {
+		log.Err("fetching pull status: %s", err)
+		return
+	}
+	ctx := &command.Context{
+		User:       user,
+		Log:        log,
+		Scope:      c.StatsScope.SubScope("destroy"),
+		Pull:       pull,
+		HeadRepo:   headRepo,
+		PullStatus: pullStatus,
+		Trigger:    command.CommentTrigger,
+	}
+	for _, project := range c.projectsToDestroy(pull, pullStatus) {
+		log.Info("destroying dir '%s' workspace '%s'", project.RepoRelDir, project.Workspace)
+		planCmd := projectCommentCommand(command.Plan, project.ProjectName, project.RepoRelDir, project.Workspace)
+		planCmd.Flags = []string{"-destroy"}
+		planCmd.destroyOnClose = true
+		buildCommentCommandRunner(c, command.Plan).Run(ctx, planCmd)
+		// The apply checks the policies of the plan that was just run.
+		if pullStatus, err := c.PullStatusFetcher.GetPullStatus(pull); err != nil {
+			log.Err("fetching pull status: %s", err)
+		} else {
+			ctx.PullStatus = pullStatus
+		}
+		applyCmd := projectCommentCommand(command.Apply, project.ProjectName, project.RepoRelDir, project.Workspace)
+		applyCmd.destroyOnClose = true
+		buildCommentCommandRunner(c, command.Apply).Run(ctx, applyCmd)
+	}
+}
+
+func (c *DefaultCommandRunner) projectsToDestroy(pull models.PullRequest, pullStatus *models.PullStatus) []models.ProjectStatus {
+	globalCfg := c.GlobalCfgStore.Load()
+	return closedPullProjects(pull, pullStatus, globalCfg.DestroyOnClose(pull.BaseRepo.ID()))
+}
+
+// closedPullProjects returns the projects of pullStatus to destroy now that
+// pull is closed: the projects in its preview workspace and, if
+// destroyOnClose isn't nil, in the workspaces matching its patterns.
+func closedPullProjects(pull models.PullRequest, pullStatus *models.PullStatus, destroyOnClose *valid.DestroyOnClose) []models.ProjectStatus {
+	if pullStatus == nil {
+		return nil
+	}
+	var projects []models.ProjectStatus
+	for _, project := range pullStatus.Projects {
+		if project.Workspace == valid.PreviewWorkspace(pull.Num) ||
+			destroyOnClose != nil && destroyOnClose.Matches(project.Workspace, pull.Num, pull.HeadBranch) {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
@@ -0,0 +1,24 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestClosedPullProjects(t *testing.T) {
+	pull := models.PullRequest{Num: 12, HeadBranch: "feature"}
+	pullStatus := &models.PullStatus{Projects: []models.ProjectStatus{
+		{RepoRelDir: "app", Workspace: "pr-12", Status: models.AppliedPlanStatus},
+		{RepoRelDir: "shared", Workspace: "default", Status: models.PlannedPlanStatus},
+		{RepoRelDir: "other", Workspace: "pr-1", Status: models.AppliedPlanStatus},
+		{RepoRelDir: "test", Workspace: "feature-test", Status: models.AppliedPlanStatus},
+	}}
+	Equals(t, []models.ProjectStatus{pullStatus.Projects[0]}, closedPullProjects(pull, pullStatus, nil))
+	Equals(t, 0, len(closedPullProjects(pull, nil, nil)))
+
+	destroyOnClose := &valid.DestroyOnClose{Workspaces: []string{"{head_branch}-*"}}
+	Equals(t, []models.ProjectStatus{pullStatus.Projects[0], pullStatus.Projects[3]}, closedPullProjects(pull, pullStatus, destroyOnClose))
+}
@@ -144,13 +144,55 @@ type CommentCommand struct {
 	PolicySet string
 	// ClearPolicyApproval is true if approvals should be cleared out for specified policies.
 	ClearPolicyApproval bool
+	// Targets are the resource addresses passed with --target to limit a plan
+	// or apply to, ex. atlantis plan --target aws_instance.web.
+	Targets []string
+	// ContinueOnError is true if an apply should keep applying the remaining
+	// projects and execution order groups after a project fails.
+	ContinueOnError bool
+	// ConfirmDestroy is true if the user confirmed applying plans that
+	// destroy or replace resources protected by the destroy guard.
+	ConfirmDestroy bool
+	// OverrideApplyWindow is true if an admin asked to apply projects outside
+	// their apply windows.
+	OverrideApplyWindow bool
+	// PromoteCanary is true if the projects waiting for their canary should
+	// be applied without waiting for the canary's soak time.
+	PromoteCanary bool
+	// Undo is true if a silence command should stop silencing the project.
+	Undo bool
+	// Labels selects the projects a plan or apply runs on by their labels,
+	// ex. tier: prod. Projects must have every label.
+	Labels map[string]string
+	// ImportResources are the ADDRESS ID pairs of a bulk import, listed in a
+	// fenced block after the import command. If set, Flags only holds the
+	// extra args.
+	ImportResources [][]string
+	// CommentID is the ID of the comment the command was parsed from. It's
+	// used to react to the comment once the command finishes.
+	CommentID int64
+	// inChangeSet is true if the command is run on a member of a change set
+	// by a command on the whole change set.
+	inChangeSet bool
+	// previewEnvironment is true if the command deploys or destroys a preview
+	// environment. Such commands skip the requirements that need a review.
+	previewEnvironment bool
+	// destroyOnClose is true if the command destroys a workspace of a closed
+	// pull request. Such commands skip the requirements that need a review.
+	destroyOnClose bool
+	// canaryPromotion is the dir whose projects are applied because its
+	// canary's soak time elapsed. The command only applies them.
+	canaryPromotion string
+	// autoApply is true if the command applies a low-risk plan whose
+	// auto-approval delay elapsed.
+	autoApply bool
 }
 
-// IsForSpecificProject returns true if the command is for a specific dir, workspace
-// or project name. Otherwise it's a command like "atlantis plan" or "atlantis
-// apply".
+// IsForSpecificProject returns true if the command is for a specific dir, workspace,
+// project name or the projects with some labels. Otherwise it's a command like
+// "atlantis plan" or "atlantis apply".
 func (c CommentCommand) IsForSpecificProject() bool {
-	return c.RepoRelDir != "" || c.Workspace != "" || c.ProjectName != ""
+	return c.RepoRelDir != "" || c.Workspace != "" || c.ProjectName != "" || len(c.Labels) > 0
 }
 
 // Dir returns the dir of this command.
@@ -472,6 +514,7 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 		HeadBranch: *event.PullRequest.Source.Branch.Name,
 		BaseBranch: *event.PullRequest.Destination.Branch.Name,
 		Author:     *event.Actor.AccountID,
+		Title:      stringValue(event.PullRequest.Title),
 		State:      prState,
 		BaseRepo:   baseRepo,
 	}
@@ -621,6 +664,7 @@ func (e *EventParser) ParseGithubPull(logger logging.SimpleLogging, pull *github
 
 	pullModel = models.PullRequest{
 		Author:     authorUsername,
+		Title:      pull.GetTitle(),
 		HeadBranch: headBranch,
 		HeadCommit: commit,
 		URL:        url,
@@ -689,6 +733,7 @@ func (e *EventParser) ParseGitlabMergeRequestEvent(event gitlab.MergeEvent) (pul
 	pull = models.PullRequest{
 		URL:        event.ObjectAttributes.URL,
 		Author:     event.User.Username,
+		Title:      event.ObjectAttributes.Title,
 		Num:        event.ObjectAttributes.IID,
 		HeadCommit: event.ObjectAttributes.LastCommit.ID,
 		HeadBranch: event.ObjectAttributes.SourceBranch,
@@ -783,6 +828,7 @@ func (e *EventParser) ParseGitlabMergeRequest(mr *gitlab.MergeRequest, baseRepo
 	return models.PullRequest{
 		URL:        mr.WebURL,
 		Author:     mr.Author.Username,
+		Title:      mr.Title,
 		Num:        mr.IID,
 		HeadCommit: mr.SHA,
 		HeadBranch: mr.SourceBranch,
@@ -839,7 +885,7 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 	}
 
 	headRepoSlug := *event.PullRequest.FromRef.Repository.Slug
-	headRepoFullname := fmt.Sprintf("%s/%s", *event.PullRequest.FromRef.Repository.Project.Name, headRepoSlug)
+	headRepoFullname := fmt.Sprintf("%s/%s", bitbucketServerRepoOwner(event.PullRequest.FromRef.Repository.Project), headRepoSlug)
 	headRepoCloneURL := fmt.Sprintf("%s/scm/%s/%s.git", e.BitbucketServerURL, strings.ToLower(*event.PullRequest.FromRef.Repository.Project.Key), headRepoSlug)
 	headRepo, err = models.NewRepo(
 		models.BitbucketServer,
@@ -852,7 +898,7 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 	}
 
 	baseRepoSlug := *event.PullRequest.ToRef.Repository.Slug
-	baseRepoFullname := fmt.Sprintf("%s/%s", *event.PullRequest.ToRef.Repository.Project.Name, baseRepoSlug)
+	baseRepoFullname := fmt.Sprintf("%s/%s", bitbucketServerRepoOwner(event.PullRequest.ToRef.Repository.Project), baseRepoSlug)
 	baseRepoCloneURL := fmt.Sprintf("%s/scm/%s/%s.git", e.BitbucketServerURL, strings.ToLower(*event.PullRequest.ToRef.Repository.Project.Key), baseRepoSlug)
 	baseRepo, err = models.NewRepo(
 		models.BitbucketServer,
@@ -867,10 +913,11 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 	pull = models.PullRequest{
 		Num:        *event.PullRequest.ID,
 		HeadCommit: *event.PullRequest.FromRef.LatestCommit,
-		URL:        fmt.Sprintf("%s/projects/%s/repos/%s/pull-requests/%d", e.BitbucketServerURL, *event.PullRequest.ToRef.Repository.Project.Key, *event.PullRequest.ToRef.Repository.Slug, *event.PullRequest.ID),
+		URL:        fmt.Sprintf("%s/%s/repos/%s/pull-requests/%d", e.BitbucketServerURL, bitbucketServerProjectPath(event.PullRequest.ToRef.Repository.Project), *event.PullRequest.ToRef.Repository.Slug, *event.PullRequest.ID),
 		HeadBranch: *event.PullRequest.FromRef.DisplayID,
 		BaseBranch: *event.PullRequest.ToRef.DisplayID,
 		Author:     *event.Actor.Username,
+		Title:      stringValue(event.PullRequest.Title),
 		State:      prState,
 		BaseRepo:   baseRepo,
 	}
@@ -880,6 +927,25 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 	return
 }
 
+// bitbucketServerRepoOwner returns the owner of the repos of project. The
+// repos of personal projects are owned by the project key, ex. ~jdoe, since
+// the name of a personal project is the display name of its user.
+func bitbucketServerRepoOwner(project *bitbucketserver.Project) string {
+	if project.IsPersonal() {
+		return strings.ToLower(*project.Key)
+	}
+	return *project.Name
+}
+
+// bitbucketServerProjectPath returns the path of the web UI pages of project,
+// ex. projects/AT or users/jdoe for a personal project.
+func bitbucketServerProjectPath(project *bitbucketserver.Project) string {
+	if project.IsPersonal() {
+		return "users/" + strings.ToLower(strings.TrimPrefix(*project.Key, bitbucketserver.PersonalProjectKeyPrefix))
+	}
+	return "projects/" + *project.Key
+}
+
 // ParseBitbucketServerPullEvent parses a pull request event from Bitbucket
 // Server.
 // See EventParsing for return value docs.
@@ -989,6 +1055,7 @@ func (e *EventParser) ParseAzureDevopsPull(pull *azuredevops.GitPullRequest) (pu
 
 	pullModel = models.PullRequest{
 		Author: authorUsername,
+		Title:  pull.GetTitle(),
 		// Change webhook refs from "refs/heads/<branch>" to "<branch>"
 		HeadBranch: strings.Replace(headBranch, "refs/heads/", "", 1),
 		HeadCommit: commit,
@@ -1068,6 +1135,12 @@ func (e *EventParser) ParseGiteaPullRequestEvent(event giteasdk.PullRequest) (mo
 		pullEventType = models.OtherPullEvent
 	}
 
+	// Gitea and Forgejo mark work in progress pull requests with a title
+	// prefix. Like GitHub drafts, they're ignored unless closed.
+	if isGiteaWorkInProgress(event.Title) && event.State != giteasdk.StateClosed && !e.AllowDraftPRs {
+		pullEventType = models.OtherPullEvent
+	}
+
 	// Parse the base repository.
 	baseRepo, err := models.NewRepo(
 		models.Gitea,
@@ -1100,6 +1173,7 @@ func (e *EventParser) ParseGiteaPullRequestEvent(event giteasdk.PullRequest) (mo
 		HeadBranch: (*event.Head).Ref,
 		BaseBranch: event.Base.Ref,
 		Author:     event.Poster.UserName,
+		Title:      event.Title,
 		BaseRepo:   baseRepo,
 	}
 
@@ -1110,6 +1184,22 @@ func (e *EventParser) ParseGiteaPullRequestEvent(event giteasdk.PullRequest) (mo
 	return pull, pullEventType, baseRepo, headRepo, user, nil
 }
 
+// giteaWorkInProgressPrefixes are the default title prefixes Gitea and Forgejo
+// use to mark pull requests as work in progress.
+var giteaWorkInProgressPrefixes = []string{"WIP:", "[WIP]"}
+
+// isGiteaWorkInProgress returns true if title starts with one of
+// giteaWorkInProgressPrefixes, ignoring case and leading whitespace.
+func isGiteaWorkInProgress(title string) bool {
+	title = strings.ToUpper(strings.TrimSpace(title))
+	for _, prefix := range giteaWorkInProgressPrefixes {
+		if strings.HasPrefix(title, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // ParseGiteaPull parses the response from the Gitea API endpoint (not
 // from a webhook) that returns a pull request.
 // See EventParsing for return value docs.
@@ -1162,6 +1252,7 @@ func (e *EventParser) ParseGiteaPull(pull *giteasdk.PullRequest) (pullModel mode
 
 	pullModel = models.PullRequest{
 		Author:     authorUsername,
+		Title:      pull.Title,
 		HeadBranch: headBranch,
 		HeadCommit: commit,
 		URL:        url,
@@ -1172,3 +1263,11 @@ func (e *EventParser) ParseGiteaPull(pull *giteasdk.PullRequest) (pullModel mode
 	}
 	return
 }
+
+// stringValue returns the string s points to or "" if s is nil.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
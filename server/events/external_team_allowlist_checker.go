@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/runtime"
-
+	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 )
 
@@ -61,3 +62,42 @@ func (checker *ExternalTeamAllowlistChecker) checkOutputResults(output string) b
 	lastLine := lines[len(lines)-1]
 	return strings.EqualFold(lastLine, "pass")
 }
+
+// GlobalCfgTeamAllowlistChecker checks commands with the team_authz command of
+// the current server-side repo config so a reload can change it. If the
+// config doesn't set one, Fallback is used.
+type GlobalCfgTeamAllowlistChecker struct {
+	// GlobalCfgStore holds the server-side repo config, read for the
+	// team_authz command.
+	GlobalCfgStore              *valid.GlobalCfgStore
+	Fallback                    command.TeamAllowlistChecker
+	ExternalTeamAllowlistRunner runtime.ExternalTeamAllowlistRunner
+}
+
+func (checker *GlobalCfgTeamAllowlistChecker) HasRules() bool {
+	return checker.current().HasRules()
+}
+
+func (checker *GlobalCfgTeamAllowlistChecker) IsCommandAllowedForTeam(ctx models.TeamAllowlistCheckerContext, team string, command string) bool {
+	return checker.current().IsCommandAllowedForTeam(ctx, team, command)
+}
+
+func (checker *GlobalCfgTeamAllowlistChecker) IsCommandAllowedForAnyTeam(ctx models.TeamAllowlistCheckerContext, teams []string, command string) bool {
+	return checker.current().IsCommandAllowedForAnyTeam(ctx, teams, command)
+}
+
+func (checker *GlobalCfgTeamAllowlistChecker) AllTeams() []string {
+	return checker.current().AllTeams()
+}
+
+func (checker *GlobalCfgTeamAllowlistChecker) current() command.TeamAllowlistChecker {
+	teamAuthz := checker.GlobalCfgStore.Load().TeamAuthz
+	if teamAuthz.Command == "" {
+		return checker.Fallback
+	}
+	return &ExternalTeamAllowlistChecker{
+		Command:                     teamAuthz.Command,
+		ExtraArgs:                   teamAuthz.Args,
+		ExternalTeamAllowlistRunner: checker.ExternalTeamAllowlistRunner,
+	}
+}
@@ -3,6 +3,8 @@ package events_test
 import (
 	"testing"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 
@@ -75,3 +77,29 @@ func TestIsCommandAllowedForAnyTeam(t *testing.T) {
 		Equals(t, false, res)
 	})
 }
+
+func TestGlobalCfgTeamAllowlistChecker_Reload(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := models.TeamAllowlistCheckerContext{
+		Log:      logging.NewNoopLogger(t),
+		BaseRepo: models.Repo{FullName: "owner/repo", Owner: "owner"},
+	}
+	runner := runtime_mocks.NewMockExternalTeamAllowlistRunner()
+	When(runner.Run(Any[models.TeamAllowlistCheckerContext](), Any[string](), Any[string](),
+		Any[string]())).ThenReturn("pass\n", nil)
+	fallback, err := command.NewTeamAllowlistChecker("devs:plan")
+	Ok(t, err)
+
+	store := valid.NewGlobalCfgStore(valid.GlobalCfg{})
+	checker := &events.GlobalCfgTeamAllowlistChecker{
+		GlobalCfgStore:              store,
+		Fallback:                    fallback,
+		ExternalTeamAllowlistRunner: runner,
+	}
+	Equals(t, false, checker.IsCommandAllowedForTeam(ctx, "ops", "plan"))
+	Equals(t, []string{"devs"}, checker.AllTeams())
+
+	store.Store(valid.GlobalCfg{TeamAuthz: valid.TeamAuthz{Command: "authz", Args: []string{"--check"}}})
+	Equals(t, true, checker.IsCommandAllowedForTeam(ctx, "ops", "plan"))
+	runner.VerifyWasCalledOnce().Run(Any[models.TeamAllowlistCheckerContext](), Eq("sh"), Eq("-c"), Eq("authz --check plan owner/repo owner/ops"))
+}
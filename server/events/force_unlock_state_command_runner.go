@@ -0,0 +1,60 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/utils"
+)
+
+func NewForceUnlockStateCommandRunner(
+	pullUpdater *PullUpdater,
+	prjCmdBuilder ProjectStateCommandBuilder,
+	prjCmdRunner ProjectStateCommandRunner,
+	admins []string,
+) *ForceUnlockStateCommandRunner {
+	return &ForceUnlockStateCommandRunner{
+		pullUpdater:   pullUpdater,
+		prjCmdBuilder: prjCmdBuilder,
+		prjCmdRunner:  prjCmdRunner,
+		admins:        admins,
+	}
+}
+
+// ForceUnlockStateCommandRunner runs terraform force-unlock to release a
+// stale Terraform state lock. Because releasing a lock that is still in use
+// can corrupt the state, only admins can run it.
+type ForceUnlockStateCommandRunner struct {
+	pullUpdater   *PullUpdater
+	prjCmdBuilder ProjectStateCommandBuilder
+	prjCmdRunner  ProjectStateCommandRunner
+	// admins are the usernames allowed to run force-unlock-state.
+	admins []string
+}
+
+func (v *ForceUnlockStateCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
+	if !utils.SlicesContains(v.admins, ctx.User.Username) {
+		ctx.Log.Info("user %q is not allowed to run %s", ctx.User.Username, command.ForceUnlockState)
+		v.pullUpdater.updatePull(ctx, cmd, command.Result{
+			Failure: fmt.Sprintf("User @%s is not allowed to run %s, only Atlantis admins can release Terraform state locks.", ctx.User.Username, command.ForceUnlockState),
+		})
+		return
+	}
+
+	projectCmds, err := v.prjCmdBuilder.BuildForceUnlockStateCommands(ctx, cmd)
+	if err != nil {
+		ctx.Log.Warn("Error %s", err)
+	}
+
+	var result command.Result
+	if len(projectCmds) > 1 {
+		// A lock ID belongs to a single state, so releasing it across
+		// projects would at best fail and at worst release the wrong lock.
+		result = command.Result{
+			Failure: "force-unlock-state cannot run on multiple projects. please specify one project.",
+		}
+	} else {
+		result = runProjectCmds(projectCmds, v.prjCmdRunner.ForceUnlockState)
+	}
+	v.pullUpdater.updatePull(ctx, cmd, result)
+}
@@ -0,0 +1,79 @@
+package events_test
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/testdata"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/metrics"
+)
+
+func TestForceUnlockStateCommandRunner_Run(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+
+	tests := []struct {
+		name          string
+		admins        []string
+		projectCmds   []command.ProjectContext
+		expComment    string
+		expRunProject bool
+	}{
+		{
+			name:        "user is not an admin",
+			admins:      []string{"someone-else"},
+			projectCmds: []command.ProjectContext{{}},
+			expComment:  "**Force Unlock State Failed**: User @lkysow is not allowed to run force-unlock-state, only Atlantis admins can release Terraform state locks.",
+		},
+		{
+			name:        "failure with multiple projects",
+			admins:      []string{"lkysow"},
+			projectCmds: []command.ProjectContext{{}, {}},
+			expComment:  "**Force Unlock State Failed**: force-unlock-state cannot run on multiple projects. please specify one project.",
+		},
+		{
+			name:          "admin runs force-unlock-state on a single project",
+			admins:        []string{"lkysow"},
+			projectCmds:   []command.ProjectContext{{CommandName: command.ForceUnlockState}},
+			expRunProject: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcsClient := setup(t)
+
+			scopeNull, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+			modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, State: models.OpenPullState, Num: testdata.Pull.Num}
+			ctx := &command.Context{
+				User:     testdata.User,
+				Log:      logger,
+				Scope:    scopeNull,
+				Pull:     modelPull,
+				HeadRepo: testdata.GithubRepo,
+				Trigger:  command.CommentTrigger,
+			}
+			cmd := &events.CommentCommand{Name: command.ForceUnlockState, ProjectName: "project1"}
+
+			When(projectCommandBuilder.BuildForceUnlockStateCommands(ctx, cmd)).ThenReturn(tt.projectCmds, nil)
+			When(projectCommandRunner.ForceUnlockState(Any[command.ProjectContext]())).ThenReturn(command.ProjectResult{
+				Command:                 command.ForceUnlockState,
+				ForceUnlockStateSuccess: "Terraform state has been successfully unlocked!",
+			})
+
+			runner := events.NewForceUnlockStateCommandRunner(pullUpdater, projectCommandBuilder, projectCommandRunner, tt.admins)
+			runner.Run(ctx, cmd)
+
+			if tt.expRunProject {
+				projectCommandRunner.VerifyWasCalledOnce().ForceUnlockState(tt.projectCmds[0])
+				return
+			}
+			projectCommandRunner.VerifyWasCalled(Never()).ForceUnlockState(Any[command.ProjectContext]())
+			vcsClient.VerifyWasCalledOnce().CreateComment(
+				Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull.Num), Eq(tt.expComment), Eq("force-unlock-state"))
+		})
+	}
+}
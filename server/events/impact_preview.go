@@ -0,0 +1,230 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// remoteStateFileSchema matches the blocks of Terraform files that configure
+// where a project's state is stored and which states it reads.
+var remoteStateFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+	},
+}
+
+var backendBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "backend", LabelNames: []string{"type"}},
+	},
+}
+
+var remoteStateDataSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "backend"},
+		{Name: "config"},
+	},
+}
+
+// stateBackend is where a state is stored. Config only has the attributes
+// whose values are literal strings.
+type stateBackend struct {
+	Type   string
+	Config map[string]string
+}
+
+// projectStates are the backend of a project and the states it reads with
+// terraform_remote_state data sources.
+type projectStates struct {
+	backend      stateBackend
+	remoteStates []stateBackend
+}
+
+// impactPreviewProject is a project whose consumers are looked for.
+type impactPreviewProject struct {
+	name string
+	dir  string
+}
+
+// findImpactPreviewProjects returns the projects of repoCfg that aren't in
+// modified but consume the outputs of one of them, directly or through other
+// such projects. A project consumes the outputs of another if it depends on
+// it or reads its state with a terraform_remote_state data source.
+func findImpactPreviewProjects(log logging.SimpleLogging, repoDir string, repoCfg valid.RepoCfg, modified []valid.MergedProjectCfg) []valid.Project {
+	states := make(map[string]*projectStates)
+	dirStates := func(dir string) *projectStates {
+		if s, ok := states[dir]; ok {
+			return s
+		}
+		s, err := parseProjectStates(filepath.Join(repoDir, dir))
+		if err != nil {
+			// A project whose files can't be parsed is only matched by its
+			// dependencies.
+			log.Warn("looking for the remote states read in '%s': %s", dir, err)
+		}
+		states[dir] = s
+		return s
+	}
+
+	var queue []impactPreviewProject
+	isModified := make([]bool, len(repoCfg.Projects))
+	for _, m := range modified {
+		queue = append(queue, impactPreviewProject{name: m.Name, dir: filepath.Clean(m.RepoRelDir)})
+		for i, proj := range repoCfg.Projects {
+			if filepath.Clean(proj.Dir) == filepath.Clean(m.RepoRelDir) && proj.Workspace == m.Workspace {
+				isModified[i] = true
+			}
+		}
+	}
+
+	var consumers []valid.Project
+	for len(queue) > 0 {
+		upstream := queue[0]
+		queue = queue[1:]
+		for i, proj := range repoCfg.Projects {
+			if isModified[i] {
+				continue
+			}
+			dir := filepath.Clean(proj.Dir)
+			dependsOn := upstream.name != "" && slices.Contains(proj.DependsOn, upstream.name)
+			if !dependsOn && !dirStates(dir).readsStateOf(dir, dirStates(upstream.dir), upstream.dir) {
+				continue
+			}
+			log.Debug("project at dir '%s' workspace '%s' consumes the outputs of the project at dir '%s'", proj.Dir, proj.Workspace, upstream.dir)
+			isModified[i] = true
+			consumers = append(consumers, proj)
+			queue = append(queue, impactPreviewProject{name: proj.GetName(), dir: dir})
+		}
+	}
+	return consumers
+}
+
+// readsStateOf returns true if the project in dir reads the state of the
+// project in upstreamDir. Dirs are relative to the repo root.
+func (p *projectStates) readsStateOf(dir string, upstream *projectStates, upstreamDir string) bool {
+	if p == nil || upstream == nil || dir == upstreamDir {
+		return false
+	}
+	for _, remote := range p.remoteStates {
+		if remote.Type != upstream.backend.Type {
+			continue
+		}
+		if remote.Type == "local" {
+			if localStatePath(dir, remote) == localStatePath(upstreamDir, upstream.backend) {
+				return true
+			}
+			continue
+		}
+		matched := false
+		for key, value := range remote.Config {
+			upstreamValue, ok := upstream.backend.Config[key]
+			if !ok {
+				continue
+			}
+			if upstreamValue != value {
+				matched = false
+				break
+			}
+			matched = true
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// localStatePath returns the path of the state stored by the local backend
+// relative to the repo root.
+func localStatePath(dir string, backend stateBackend) string {
+	path, ok := backend.Config["path"]
+	if !ok {
+		path = "terraform.tfstate"
+	}
+	return filepath.Join(dir, path)
+}
+
+// parseProjectStates parses the Terraform files in absDir for the backend
+// of the project and the remote states it reads. The project uses the local
+// backend if it doesn't configure one.
+func parseProjectStates(absDir string) (*projectStates, error) {
+	states := &projectStates{backend: stateBackend{Type: "local", Config: map[string]string{}}}
+	entries, err := os.ReadDir(absDir)
+	if err != nil {
+		return states, err
+	}
+	parser := hclparse.NewParser()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tf") {
+			continue
+		}
+		file, diags := parser.ParseHCLFile(filepath.Join(absDir, entry.Name()))
+		if diags.HasErrors() {
+			return states, diags
+		}
+		content, _, _ := file.Body.PartialContent(remoteStateFileSchema)
+		for _, block := range content.Blocks {
+			switch {
+			case block.Type == "terraform":
+				terraform, _, _ := block.Body.PartialContent(backendBlockSchema)
+				for _, backend := range terraform.Blocks {
+					attrs, _ := backend.Body.JustAttributes()
+					states.backend = stateBackend{Type: backend.Labels[0], Config: literalStrings(attrs)}
+				}
+			case block.Labels[0] == "terraform_remote_state":
+				remoteState, _, _ := block.Body.PartialContent(remoteStateDataSchema)
+				remote := stateBackend{Config: map[string]string{}}
+				if attr, ok := remoteState.Attributes["backend"]; ok {
+					remote.Type, _ = literalString(attr.Expr)
+				}
+				if attr, ok := remoteState.Attributes["config"]; ok {
+					pairs, _ := hcl.ExprMap(attr.Expr)
+					for _, pair := range pairs {
+						key := hcl.ExprAsKeyword(pair.Key)
+						if key == "" {
+							key, _ = literalString(pair.Key)
+						}
+						if value, ok := literalString(pair.Value); ok && key != "" {
+							remote.Config[key] = value
+						}
+					}
+				}
+				if remote.Type != "" {
+					states.remoteStates = append(states.remoteStates, remote)
+				}
+			}
+		}
+	}
+	return states, nil
+}
+
+// literalStrings returns the attributes of attrs whose values are strings
+// that don't reference variables.
+func literalStrings(attrs hcl.Attributes) map[string]string {
+	strs := make(map[string]string)
+	for name, attr := range attrs {
+		if value, ok := literalString(attr.Expr); ok {
+			strs[name] = value
+		}
+	}
+	return strs
+}
+
+// literalString returns the value of expr if it's a string that doesn't
+// reference variables.
+func literalString(expr hcl.Expression) (string, bool) {
+	var value string
+	if diags := gohcl.DecodeExpression(expr, nil, &value); diags.HasErrors() {
+		return "", false
+	}
+	return value, true
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFindImpactPreviewProjects(t *testing.T) {
+	repoDir := t.TempDir()
+	files := map[string]string{
+		"network/main.tf": `
+terraform {
+  backend "s3" {
+    bucket = "states"
+    key    = "network.tfstate"
+  }
+}`,
+		"app/main.tf": `
+data "terraform_remote_state" "network" {
+  backend = "s3"
+  config = {
+    bucket = "states"
+    key    = "network.tfstate"
+  }
+}`,
+		"other/main.tf": `
+data "terraform_remote_state" "network" {
+  backend = "s3"
+  config = {
+    bucket = "states"
+    key    = "other.tfstate"
+  }
+}`,
+		"local/main.tf": `
+terraform {
+  backend "local" {
+    path = "state/local.tfstate"
+  }
+}`,
+		"local-consumer/main.tf": `
+data "terraform_remote_state" "local" {
+  backend = "local"
+  config = {
+    path = "../local/state/local.tfstate"
+  }
+}`,
+		"dashboards/main.tf": ``,
+	}
+	for path, contents := range files {
+		Ok(t, os.MkdirAll(filepath.Join(repoDir, filepath.Dir(path)), 0700))
+		Ok(t, os.WriteFile(filepath.Join(repoDir, path), []byte(contents), 0600))
+	}
+	name := func(n string) *string { return &n }
+	repoCfg := valid.RepoCfg{
+		Projects: []valid.Project{
+			{Dir: "network", Workspace: "default", Name: name("network")},
+			{Dir: "app", Workspace: "default", Name: name("app")},
+			{Dir: "other", Workspace: "default"},
+			{Dir: "local", Workspace: "default"},
+			{Dir: "local-consumer", Workspace: "default"},
+			// dashboards consumes network through app.
+			{Dir: "dashboards", Workspace: "default", DependsOn: []string{"app"}},
+		},
+	}
+
+	consumers := findImpactPreviewProjects(logging.NewNoopLogger(t), repoDir, repoCfg, []valid.MergedProjectCfg{
+		{RepoRelDir: "network", Workspace: "default", Name: "network"},
+	})
+	Equals(t, []valid.Project{repoCfg.Projects[1], repoCfg.Projects[5]}, consumers)
+
+	consumers = findImpactPreviewProjects(logging.NewNoopLogger(t), repoDir, repoCfg, []valid.MergedProjectCfg{
+		{RepoRelDir: "local", Workspace: "default"},
+	})
+	Equals(t, []valid.Project{repoCfg.Projects[4]}, consumers)
+
+	consumers = findImpactPreviewProjects(logging.NewNoopLogger(t), repoDir, repoCfg, []valid.MergedProjectCfg{
+		{RepoRelDir: "dashboards", Workspace: "default"},
+	})
+	Equals(t, 0, len(consumers))
+}
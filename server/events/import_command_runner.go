@@ -1,6 +1,10 @@
 package events
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 )
@@ -27,6 +31,12 @@ type ImportCommandRunner struct {
 	prjCmdBuilder        ProjectImportCommandBuilder
 	prjCmdRunner         ProjectImportCommandRunner
 	SilenceNoProjects    bool
+	// Replan is true if the project is planned again after a successful
+	// import so the comment shows the updated plan.
+	Replan             bool
+	PlanCommandBuilder ProjectPlanCommandBuilder
+	PlanCommandRunner  ProjectPlanCommandRunner
+	DBUpdater          *DBUpdater
 }
 
 func (v *ImportCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
@@ -45,15 +55,40 @@ func (v *ImportCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 		ctx.Log.Warn("unable to get pull request status: %s. Continuing with mergeable and approved assumed false", err)
 	}
 
-	var projectCmds []command.ProjectContext
-	projectCmds, err = v.prjCmdBuilder.BuildImportCommands(ctx, cmd)
+	var result *command.Result
+	var addresses []string
+	if len(cmd.ImportResources) > 0 {
+		result = v.runBulkImport(ctx, cmd)
+		for _, resource := range cmd.ImportResources {
+			addresses = append(addresses, resource[0])
+		}
+	} else {
+		result = v.runImport(ctx, cmd)
+		// ADDRESS and ID are the last args, after any extra args.
+		if len(cmd.Flags) >= 2 {
+			addresses = []string{cmd.Flags[len(cmd.Flags)-2]}
+		}
+	}
+	if result == nil {
+		return
+	}
+	if v.Replan {
+		v.replan(ctx, result, addresses)
+	}
+	v.pullUpdater.updatePull(ctx, cmd, *result)
+}
+
+// runImport imports the resource for the project of cmd. It returns nil if
+// there's no project and the result shouldn't be commented.
+func (v *ImportCommandRunner) runImport(ctx *command.Context, cmd *CommentCommand) *command.Result {
+	projectCmds, err := v.prjCmdBuilder.BuildImportCommands(ctx, cmd)
 	if err != nil {
 		ctx.Log.Warn("Error %s", err)
 	}
 
 	if len(projectCmds) == 0 && v.SilenceNoProjects {
 		ctx.Log.Info("determined there was no project to run import in.")
-		return
+		return nil
 	}
 	var result command.Result
 	if len(projectCmds) > 1 {
@@ -65,5 +100,73 @@ func (v *ImportCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 	} else {
 		result = runProjectCmds(projectCmds, v.prjCmdRunner.Import)
 	}
-	v.pullUpdater.updatePull(ctx, cmd, result)
+	return &result
+}
+
+// runBulkImport imports the resources of cmd one at a time and combines their
+// outputs. It stops at the first resource that fails to import.
+func (v *ImportCommandRunner) runBulkImport(ctx *command.Context, cmd *CommentCommand) *command.Result {
+	var outputs []string
+	var result *command.Result
+	for i, resource := range cmd.ImportResources {
+		resourceCmd := *cmd
+		resourceCmd.Flags = append(slices.Clone(cmd.Flags), resource...)
+		result = v.runImport(ctx, &resourceCmd)
+		if result == nil {
+			return nil
+		}
+		if len(result.ProjectResults) != 1 || result.ProjectResults[0].ImportSuccess == nil {
+			if i > 0 && len(result.ProjectResults) == 1 {
+				result.ProjectResults[0].Annotations = append(result.ProjectResults[0].Annotations,
+					fmt.Sprintf("%d of %d resources were imported before `%s` failed.", i, len(cmd.ImportResources), strings.Join(resource, " ")))
+			}
+			return result
+		}
+		outputs = append(outputs, strings.TrimSpace(result.ProjectResults[0].ImportSuccess.Output))
+	}
+	result.ProjectResults[0].ImportSuccess.Output = strings.Join(outputs, "\n\n")
+	return result
+}
+
+// replan plans the project again after a successful import and adds the plan
+// to the import result. Imported addresses that the plan still creates are
+// added to UnmatchedImports.
+func (v *ImportCommandRunner) replan(ctx *command.Context, result *command.Result, addresses []string) {
+	if len(result.ProjectResults) != 1 || result.ProjectResults[0].ImportSuccess == nil {
+		return
+	}
+	importResult := result.ProjectResults[0]
+	planCmd := &CommentCommand{
+		Name:        command.Plan,
+		RepoRelDir:  importResult.RepoRelDir,
+		Workspace:   importResult.Workspace,
+		ProjectName: importResult.ProjectName,
+	}
+	if importResult.ProjectName != "" {
+		planCmd.RepoRelDir, planCmd.Workspace = "", ""
+	}
+	projectCmds, err := v.PlanCommandBuilder.BuildPlanCommands(ctx, planCmd)
+	if err != nil || len(projectCmds) != 1 {
+		ctx.Log.Warn("unable to plan %s again after import: %v", planCmd, err)
+		importResult.ImportSuccess.PlanFailure = "Unable to plan the project again."
+		return
+	}
+
+	planResult := v.PlanCommandRunner.Plan(projectCmds[0])
+	if _, err := v.DBUpdater.updateDB(ctx, ctx.Pull, []command.ProjectResult{planResult}); err != nil {
+		ctx.Log.Err("writing results: %s", err)
+	}
+	switch {
+	case planResult.Error != nil:
+		importResult.ImportSuccess.PlanFailure = planResult.Error.Error()
+	case planResult.Failure != "":
+		importResult.ImportSuccess.PlanFailure = planResult.Failure
+	case planResult.PlanSuccess != nil:
+		importResult.ImportSuccess.Plan = planResult.PlanSuccess
+		for _, address := range addresses {
+			if strings.Contains(planResult.PlanSuccess.TerraformOutput, fmt.Sprintf("# %s will be created", address)) {
+				importResult.ImportSuccess.UnmatchedImports = append(importResult.ImportSuccess.UnmatchedImports, address)
+			}
+		}
+	}
 }
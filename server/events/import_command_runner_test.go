@@ -1,6 +1,7 @@
 package events_test
 
 import (
+	"strings"
 	"testing"
 
 	. "github.com/petergtz/pegomock/v4"
@@ -88,3 +89,70 @@ func TestImportCommandRunner_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestImportCommandRunner_BulkImportReplan(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+	vcsClient := setup(t)
+	importCommandRunner.Replan = true
+	importCommandRunner.PlanCommandBuilder = projectCommandBuilder
+	importCommandRunner.PlanCommandRunner = projectCommandRunner
+	importCommandRunner.DBUpdater = dbUpdater
+
+	scopeNull, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+	modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, State: models.OpenPullState, Num: testdata.Pull.Num}
+	ctx := &command.Context{
+		User:     testdata.User,
+		Log:      logger,
+		Scope:    scopeNull,
+		Pull:     modelPull,
+		HeadRepo: testdata.GithubRepo,
+		Trigger:  command.CommentTrigger,
+	}
+	cmd := &events.CommentCommand{
+		Name:            command.Import,
+		ProjectName:     "proj",
+		Flags:           []string{"-var", "foo=bar"},
+		ImportResources: [][]string{{"aws_instance.a", "i-123"}, {"aws_instance.b", "i-456"}},
+	}
+	importCtx := command.ProjectContext{CommandName: command.Import, ProjectName: "proj", RepoRelDir: "dir", Workspace: "default"}
+	planCtx := command.ProjectContext{CommandName: command.Plan, ProjectName: "proj", RepoRelDir: "dir", Workspace: "default"}
+
+	When(pullReqStatusFetcher.FetchPullStatus(logger, modelPull)).ThenReturn(models.PullReqStatus{}, nil)
+	When(projectCommandBuilder.BuildImportCommands(Any[*command.Context](), Any[*events.CommentCommand]())).
+		ThenReturn([]command.ProjectContext{importCtx}, nil)
+	When(projectCommandRunner.Import(importCtx)).ThenReturn(command.ProjectResult{
+		Command:       command.Import,
+		ProjectName:   "proj",
+		RepoRelDir:    "dir",
+		Workspace:     "default",
+		ImportSuccess: &models.ImportSuccess{Output: "Import prepared!", RePlanCmd: "atlantis plan -p proj"},
+	})
+	When(projectCommandBuilder.BuildPlanCommands(ctx, &events.CommentCommand{Name: command.Plan, ProjectName: "proj"})).
+		ThenReturn([]command.ProjectContext{planCtx}, nil)
+	When(projectCommandRunner.Plan(planCtx)).ThenReturn(command.ProjectResult{
+		Command:     command.Plan,
+		ProjectName: "proj",
+		RepoRelDir:  "dir",
+		Workspace:   "default",
+		PlanSuccess: &models.PlanSuccess{
+			TerraformOutput: "  # aws_instance.b will be created\nPlan: 1 to add, 0 to change, 0 to destroy.",
+			RePlanCmd:       "atlantis plan -p proj",
+			ApplyCmd:        "atlantis apply -p proj",
+		},
+	})
+
+	importCommandRunner.Run(ctx, cmd)
+
+	_, importCmds := projectCommandBuilder.VerifyWasCalled(Times(2)).
+		BuildImportCommands(Any[*command.Context](), Any[*events.CommentCommand]()).GetAllCapturedArguments()
+	Equals(t, []string{"-var", "foo=bar", "aws_instance.a", "i-123"}, importCmds[0].Flags)
+	Equals(t, []string{"-var", "foo=bar", "aws_instance.b", "i-456"}, importCmds[1].Flags)
+
+	_, _, _, comment, _ := vcsClient.VerifyWasCalledOnce().CreateComment(
+		Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull.Num), Any[string](), Eq("import")).GetCapturedArguments()
+	Assert(t, strings.Contains(comment, "Import prepared!\n\nImport prepared!"), "expected combined import output in %q", comment)
+	Assert(t, strings.Contains(comment, "The plan still creates `aws_instance.b`."), "expected unmatched import warning in %q", comment)
+	Assert(t, !strings.Contains(comment, "`aws_instance.a`"), "expected aws_instance.a to be matched in %q", comment)
+	Assert(t, strings.Contains(comment, "atlantis apply -p proj"), "expected updated plan in %q", comment)
+}
@@ -58,6 +58,33 @@ func (b *InstrumentedProjectCommandBuilder) BuildStateRmCommands(ctx *command.Co
 	)
 }
 
+func (b *InstrumentedProjectCommandBuilder) BuildStateReadCommands(ctx *command.Context, comment *CommentCommand) ([]command.ProjectContext, error) {
+	return b.buildAndEmitStats(
+		"state "+comment.SubName,
+		func() ([]command.ProjectContext, error) {
+			return b.ProjectCommandBuilder.BuildStateReadCommands(ctx, comment)
+		},
+	)
+}
+
+func (b *InstrumentedProjectCommandBuilder) BuildForceUnlockStateCommands(ctx *command.Context, comment *CommentCommand) ([]command.ProjectContext, error) {
+	return b.buildAndEmitStats(
+		"force-unlock-state",
+		func() ([]command.ProjectContext, error) {
+			return b.ProjectCommandBuilder.BuildForceUnlockStateCommands(ctx, comment)
+		},
+	)
+}
+
+func (b *InstrumentedProjectCommandBuilder) BuildRollbackCommands(ctx *command.Context, comment *CommentCommand) ([]command.ProjectContext, error) {
+	return b.buildAndEmitStats(
+		"rollback",
+		func() ([]command.ProjectContext, error) {
+			return b.ProjectCommandBuilder.BuildRollbackCommands(ctx, comment)
+		},
+	)
+}
+
 func (b *InstrumentedProjectCommandBuilder) buildAndEmitStats(
 	command string,
 	execute func() ([]command.ProjectContext, error),
@@ -1,6 +1,9 @@
 package events
 
 import (
+	"strings"
+	"time"
+
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/metrics"
 	tally "github.com/uber-go/tally/v4"
@@ -13,11 +16,21 @@ type IntrumentedCommandRunner interface {
 	ApprovePolicies(ctx command.ProjectContext) command.ProjectResult
 	Import(ctx command.ProjectContext) command.ProjectResult
 	StateRm(ctx command.ProjectContext) command.ProjectResult
+	StateList(ctx command.ProjectContext) command.ProjectResult
+	StateShow(ctx command.ProjectContext) command.ProjectResult
+	ForceUnlockState(ctx command.ProjectContext) command.ProjectResult
+	Rollback(ctx command.ProjectContext) command.ProjectResult
 }
 
 type InstrumentedProjectCommandRunner struct {
 	projectCommandRunner ProjectCommandRunner
 	scope                tally.Scope
+	// UsageLog, if set, records each command for usage reports.
+	UsageLog UsageLog
+	// MetricsLabels are the keys of the project labels added as tags to the
+	// metrics, ex. tier is added as label_tier. Projects without a label get
+	// an empty tag so every metric has the same tags.
+	MetricsLabels []string
 }
 
 func NewInstrumentedProjectCommandRunner(scope tally.Scope, projectCommandRunner ProjectCommandRunner) *InstrumentedProjectCommandRunner {
@@ -35,27 +48,65 @@ func NewInstrumentedProjectCommandRunner(scope tally.Scope, projectCommandRunner
 }
 
 func (p *InstrumentedProjectCommandRunner) Plan(ctx command.ProjectContext) command.ProjectResult {
-	return RunAndEmitStats(ctx, p.projectCommandRunner.Plan, p.scope)
+	return p.run(ctx, p.projectCommandRunner.Plan)
 }
 
 func (p *InstrumentedProjectCommandRunner) PolicyCheck(ctx command.ProjectContext) command.ProjectResult {
-	return RunAndEmitStats(ctx, p.projectCommandRunner.PolicyCheck, p.scope)
+	return p.run(ctx, p.projectCommandRunner.PolicyCheck)
 }
 
 func (p *InstrumentedProjectCommandRunner) Apply(ctx command.ProjectContext) command.ProjectResult {
-	return RunAndEmitStats(ctx, p.projectCommandRunner.Apply, p.scope)
+	return p.run(ctx, p.projectCommandRunner.Apply)
 }
 
 func (p *InstrumentedProjectCommandRunner) ApprovePolicies(ctx command.ProjectContext) command.ProjectResult {
-	return RunAndEmitStats(ctx, p.projectCommandRunner.ApprovePolicies, p.scope)
+	return p.run(ctx, p.projectCommandRunner.ApprovePolicies)
 }
 
 func (p *InstrumentedProjectCommandRunner) Import(ctx command.ProjectContext) command.ProjectResult {
-	return RunAndEmitStats(ctx, p.projectCommandRunner.Import, p.scope)
+	return p.run(ctx, p.projectCommandRunner.Import)
 }
 
 func (p *InstrumentedProjectCommandRunner) StateRm(ctx command.ProjectContext) command.ProjectResult {
-	return RunAndEmitStats(ctx, p.projectCommandRunner.StateRm, p.scope)
+	return p.run(ctx, p.projectCommandRunner.StateRm)
+}
+
+func (p *InstrumentedProjectCommandRunner) StateList(ctx command.ProjectContext) command.ProjectResult {
+	return p.run(ctx, p.projectCommandRunner.StateList)
+}
+
+func (p *InstrumentedProjectCommandRunner) StateShow(ctx command.ProjectContext) command.ProjectResult {
+	return p.run(ctx, p.projectCommandRunner.StateShow)
+}
+
+func (p *InstrumentedProjectCommandRunner) ForceUnlockState(ctx command.ProjectContext) command.ProjectResult {
+	return p.run(ctx, p.projectCommandRunner.ForceUnlockState)
+}
+
+// run runs the command, emits its stats and records it in the usage log.
+func (p *InstrumentedProjectCommandRunner) run(ctx command.ProjectContext, execute func(ctx command.ProjectContext) command.ProjectResult) command.ProjectResult {
+	start := time.Now()
+	scope := p.scope
+	if len(p.MetricsLabels) > 0 {
+		tags := make(map[string]string, len(p.MetricsLabels))
+		for _, key := range p.MetricsLabels {
+			tags[labelTagName(key)] = ctx.Labels[key]
+		}
+		scope = scope.Tagged(tags)
+	}
+	result := RunAndEmitStats(ctx, execute, scope)
+	if p.UsageLog != nil {
+		if err := p.UsageLog.Record(NewUsageRecord(ctx, result, start)); err != nil {
+			ctx.Log.Err("recording usage: %s", err)
+		}
+	}
+	return result
+}
+
+// labelTagName returns the name of the tag of the project label key. Dots and
+// dashes aren't valid in Prometheus label names so they're replaced.
+func labelTagName(key string) string {
+	return "label_" + strings.NewReplacer(".", "_", "-", "_").Replace(key)
 }
 
 func RunAndEmitStats(ctx command.ProjectContext, execute func(ctx command.ProjectContext) command.ProjectResult, scope tally.Scope) command.ProjectResult {
@@ -73,6 +124,10 @@ func RunAndEmitStats(ctx command.ProjectContext, execute func(ctx command.Projec
 
 	result := execute(ctx)
 
+	if result.AuthFailure != nil {
+		scope.Tagged(map[string]string{"provider": result.AuthFailure.Provider}).Counter(metrics.ExecutionAuthFailureMetric).Inc(1)
+	}
+
 	if result.Error != nil {
 		executionError.Inc(1)
 		logger.Err("Error running %s operation: %s", commandName, result.Error.Error())
@@ -91,3 +146,7 @@ func RunAndEmitStats(ctx command.ProjectContext, execute func(ctx command.Projec
 	return result
 
 }
+
+func (p *InstrumentedProjectCommandRunner) Rollback(ctx command.ProjectContext) command.ProjectResult {
+	return p.run(ctx, p.projectCommandRunner.Rollback)
+}
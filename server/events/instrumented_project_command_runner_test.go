@@ -0,0 +1,47 @@
+package events_test
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/metrics"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+func TestInstrumentedProjectCommandRunner_MetricsLabels(t *testing.T) {
+	RegisterMockTestingT(t)
+	projectCommandRunner := mocks.NewMockProjectCommandRunner()
+	When(projectCommandRunner.Plan(Any[command.ProjectContext]())).ThenReturn(command.ProjectResult{PlanSuccess: &models.PlanSuccess{}})
+	scope := tally.NewTestScope("test", nil)
+	runner := events.NewInstrumentedProjectCommandRunner(scope, projectCommandRunner)
+	runner.MetricsLabels = []string{"tier", "cost-center"}
+
+	runner.Plan(command.ProjectContext{
+		CommandName: command.Plan,
+		ProjectName: "network",
+		Labels:      map[string]string{"tier": "prod", "team": "platform"},
+		Log:         logging.NewNoopLogger(t),
+	})
+
+	var found bool
+	for _, counter := range scope.Snapshot().Counters() {
+		if counter.Name() != "test.project.plan."+metrics.ExecutionSuccessMetric {
+			continue
+		}
+		found = true
+		Equals(t, int64(1), counter.Value())
+		Equals(t, "prod", counter.Tags()["label_tier"])
+		tag, ok := counter.Tags()["label_cost_center"]
+		Assert(t, ok, "expected the label_cost_center tag")
+		Equals(t, "", tag)
+		_, ok = counter.Tags()["label_team"]
+		Assert(t, !ok, "expected no tag for labels that aren't configured")
+	}
+	Assert(t, found, "expected the plan success counter")
+}
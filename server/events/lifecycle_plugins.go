@@ -0,0 +1,207 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// LifecycleEvent is a point in the lifecycle of a pull request where
+// lifecycle plugins are run.
+type LifecycleEvent string
+
+const (
+	PrePlanLifecycleEvent    LifecycleEvent = "pre-plan"
+	PostPlanLifecycleEvent   LifecycleEvent = "post-plan"
+	PreApplyLifecycleEvent   LifecycleEvent = "pre-apply"
+	PostApplyLifecycleEvent  LifecycleEvent = "post-apply"
+	PullClosedLifecycleEvent LifecycleEvent = "pull-closed"
+
+	// DefaultLifecyclePluginTimeout is how long a plugin can run before
+	// it's killed.
+	DefaultLifecyclePluginTimeout = time.Minute
+)
+
+// canVeto returns true if plugins can stop the run at this event.
+func (e LifecycleEvent) canVeto() bool {
+	return e == PrePlanLifecycleEvent || e == PreApplyLifecycleEvent
+}
+
+// LifecyclePayload is the JSON context passed to plugins on stdin. Project
+// fields are empty for the pull-closed event and result fields are only set
+// for post-* events.
+type LifecyclePayload struct {
+	Event       LifecycleEvent `json:"event"`
+	Repo        string         `json:"repo"`
+	PullNum     int            `json:"pull_num"`
+	PullURL     string         `json:"pull_url"`
+	HeadCommit  string         `json:"head_commit"`
+	BaseBranch  string         `json:"base_branch"`
+	User        string         `json:"user,omitempty"`
+	ProjectName string         `json:"project_name,omitempty"`
+	RepoRelDir  string         `json:"repo_rel_dir,omitempty"`
+	Workspace   string         `json:"workspace,omitempty"`
+	Success     *bool          `json:"success,omitempty"`
+	Failure     string         `json:"failure,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// LifecyclePluginResponse is the JSON a plugin can write to stdout. Writing
+// nothing is the same as an empty response.
+type LifecyclePluginResponse struct {
+	// Veto stops the plan or apply. It's ignored for other events.
+	Veto bool `json:"veto"`
+	// Reason is shown on the pull request when the run is vetoed.
+	Reason string `json:"reason"`
+	// Annotation is markdown added to the project's comment.
+	Annotation string `json:"annotation"`
+}
+
+// LifecyclePluginResult is the combined result of running every plugin.
+type LifecyclePluginResult struct {
+	// Veto is the reason the run was vetoed or empty if it wasn't.
+	Veto        string
+	Annotations []string
+}
+
+// LifecyclePlugins runs executables at lifecycle events. Each plugin is run
+// with the event as its only argument and a LifecyclePayload on stdin, in
+// the order they're configured. At pre-plan and pre-apply, the first plugin
+// to veto or fail stops the run and the rest aren't run.
+type LifecyclePlugins struct {
+	Paths   []string
+	Timeout time.Duration
+}
+
+// Run runs the plugins for event.
+func (l *LifecyclePlugins) Run(logger logging.SimpleLogging, payload LifecyclePayload) LifecyclePluginResult {
+	var result LifecyclePluginResult
+	for _, path := range l.Paths {
+		name := filepath.Base(path)
+		resp, err := l.runPlugin(path, payload)
+		if err != nil {
+			if payload.Event.canVeto() {
+				result.Veto = fmt.Sprintf("Lifecycle plugin %q failed: %s", name, err)
+				return result
+			}
+			logger.Err("lifecycle plugin %q failed at %s: %s", name, payload.Event, err)
+			continue
+		}
+		if resp.Annotation != "" {
+			result.Annotations = append(result.Annotations, resp.Annotation)
+		}
+		if resp.Veto && payload.Event.canVeto() {
+			result.Veto = fmt.Sprintf("Lifecycle plugin %q vetoed the %s: %s", name, strings.TrimPrefix(string(payload.Event), "pre-"), resp.Reason)
+			return result
+		}
+	}
+	return result
+}
+
+func (l *LifecyclePlugins) runPlugin(path string, payload LifecyclePayload) (LifecyclePluginResponse, error) {
+	var resp LifecyclePluginResponse
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return resp, err
+	}
+
+	timeout := l.Timeout
+	if timeout == 0 {
+		timeout = DefaultLifecyclePluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, string(payload.Event)) // #nosec
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return resp, fmt.Errorf("timed out after %s", timeout)
+		}
+		return resp, fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return resp, nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return resp, errors.Wrap(err, "parsing response")
+	}
+	return resp, nil
+}
+
+// LifecyclePluginProjectCommandRunner runs lifecycle plugins before and
+// after each project's plan and apply.
+type LifecyclePluginProjectCommandRunner struct {
+	ProjectCommandRunner
+	Plugins *LifecyclePlugins
+}
+
+func (p *LifecyclePluginProjectCommandRunner) Plan(ctx command.ProjectContext) command.ProjectResult {
+	return p.run(ctx, command.Plan, PrePlanLifecycleEvent, PostPlanLifecycleEvent, p.ProjectCommandRunner.Plan)
+}
+
+func (p *LifecyclePluginProjectCommandRunner) Apply(ctx command.ProjectContext) command.ProjectResult {
+	return p.run(ctx, command.Apply, PreApplyLifecycleEvent, PostApplyLifecycleEvent, p.ProjectCommandRunner.Apply)
+}
+
+func (p *LifecyclePluginProjectCommandRunner) run(ctx command.ProjectContext, cmdName command.Name, pre LifecycleEvent, post LifecycleEvent, execute func(command.ProjectContext) command.ProjectResult) command.ProjectResult {
+	preResult := p.Plugins.Run(ctx.Log, projectLifecyclePayload(ctx, pre))
+	if preResult.Veto != "" {
+		return command.ProjectResult{
+			Command:           cmdName,
+			Failure:           preResult.Veto,
+			RepoRelDir:        ctx.RepoRelDir,
+			Workspace:         ctx.Workspace,
+			ProjectName:       ctx.ProjectName,
+			SilencePRComments: ctx.SilencePRComments,
+			Annotations:       preResult.Annotations,
+		}
+	}
+
+	result := execute(ctx)
+
+	payload := projectLifecyclePayload(ctx, post)
+	success := result.IsSuccessful()
+	payload.Success = &success
+	payload.Failure = result.Failure
+	if result.Error != nil {
+		payload.Error = result.Error.Error()
+	}
+	postResult := p.Plugins.Run(ctx.Log, payload)
+	result.Annotations = append(result.Annotations, preResult.Annotations...)
+	result.Annotations = append(result.Annotations, postResult.Annotations...)
+	return result
+}
+
+func projectLifecyclePayload(ctx command.ProjectContext, event LifecycleEvent) LifecyclePayload {
+	payload := pullLifecyclePayload(ctx.Pull, event)
+	payload.User = ctx.User.Username
+	payload.ProjectName = ctx.ProjectName
+	payload.RepoRelDir = ctx.RepoRelDir
+	payload.Workspace = ctx.Workspace
+	return payload
+}
+
+func pullLifecyclePayload(pull models.PullRequest, event LifecycleEvent) LifecyclePayload {
+	return LifecyclePayload{
+		Event:      event,
+		Repo:       pull.BaseRepo.FullName,
+		PullNum:    pull.Num,
+		PullURL:    pull.URL,
+		HeadCommit: pull.HeadCommit,
+		BaseBranch: pull.BaseBranch,
+	}
+}
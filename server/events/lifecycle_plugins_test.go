@@ -0,0 +1,130 @@
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// writePlugin writes a shell script plugin to a temp dir and returns its path.
+func writePlugin(t *testing.T, name string, script string) string {
+	path := filepath.Join(t.TempDir(), name)
+	Ok(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0700)) // nolint: gosec
+	return path
+}
+
+func lifecyclePluginCtx(t *testing.T) command.ProjectContext {
+	return command.ProjectContext{
+		Log:         logging.NewNoopLogger(t),
+		Pull:        models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+		User:        models.User{Username: "alice"},
+		ProjectName: "project",
+		RepoRelDir:  "dir",
+		Workspace:   "default",
+	}
+}
+
+func TestLifecyclePlugins_Run(t *testing.T) {
+	cases := map[string]struct {
+		script         string
+		event          events.LifecycleEvent
+		expVeto        string
+		expAnnotations []string
+	}{
+		"no output": {
+			script: "exit 0",
+			event:  events.PrePlanLifecycleEvent,
+		},
+		"annotation": {
+			script:         `echo '{"annotation": "checked by plugin"}'`,
+			event:          events.PostPlanLifecycleEvent,
+			expAnnotations: []string{"checked by plugin"},
+		},
+		"veto": {
+			script:  `echo '{"veto": true, "reason": "frozen"}'`,
+			event:   events.PreApplyLifecycleEvent,
+			expVeto: `Lifecycle plugin "plugin" vetoed the apply: frozen`,
+		},
+		"veto ignored after apply": {
+			script: `echo '{"veto": true, "reason": "frozen"}'`,
+			event:  events.PostApplyLifecycleEvent,
+		},
+		"non-zero exit before plan": {
+			script:  "echo nope >&2; exit 1",
+			event:   events.PrePlanLifecycleEvent,
+			expVeto: `Lifecycle plugin "plugin" failed: exit status 1: nope`,
+		},
+		"non-zero exit after plan": {
+			script: "exit 1",
+			event:  events.PostPlanLifecycleEvent,
+		},
+		"receives event and payload": {
+			script: `read payload; [ "$1" = "pre-plan" ] && echo "$payload" | grep -q '"project_name":"project"' || exit 1`,
+			event:  events.PrePlanLifecycleEvent,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			plugins := &events.LifecyclePlugins{Paths: []string{writePlugin(t, "plugin", c.script)}}
+			payload := events.LifecyclePayload{Event: c.event, ProjectName: "project"}
+			result := plugins.Run(logging.NewNoopLogger(t), payload)
+			Equals(t, c.expVeto, result.Veto)
+			Equals(t, c.expAnnotations, result.Annotations)
+		})
+	}
+}
+
+func TestLifecyclePlugins_VetoStopsLaterPlugins(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	plugins := &events.LifecyclePlugins{Paths: []string{
+		writePlugin(t, "veto", `echo '{"veto": true, "reason": "no"}'`),
+		writePlugin(t, "touch", "touch "+marker),
+	}}
+	result := plugins.Run(logging.NewNoopLogger(t), events.LifecyclePayload{Event: events.PrePlanLifecycleEvent})
+	Assert(t, strings.Contains(result.Veto, "no"), "expected veto, got %q", result.Veto)
+	_, err := os.Stat(marker)
+	Assert(t, os.IsNotExist(err), "expected second plugin not to run")
+}
+
+func TestLifecyclePluginProjectCommandRunner_Veto(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockRunner := mocks.NewMockProjectCommandRunner()
+	runner := &events.LifecyclePluginProjectCommandRunner{
+		ProjectCommandRunner: mockRunner,
+		Plugins: &events.LifecyclePlugins{Paths: []string{
+			writePlugin(t, "freeze", `[ "$1" = "pre-plan" ] && echo '{"veto": true, "reason": "frozen"}'; exit 0`),
+		}},
+	}
+
+	result := runner.Plan(lifecyclePluginCtx(t))
+	Equals(t, `Lifecycle plugin "freeze" vetoed the plan: frozen`, result.Failure)
+	Equals(t, command.Plan, result.Command)
+	Equals(t, "project", result.ProjectName)
+	mockRunner.VerifyWasCalled(Never()).Plan(Any[command.ProjectContext]())
+}
+
+func TestLifecyclePluginProjectCommandRunner_Annotations(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockRunner := mocks.NewMockProjectCommandRunner()
+	runner := &events.LifecyclePluginProjectCommandRunner{
+		ProjectCommandRunner: mockRunner,
+		Plugins: &events.LifecyclePlugins{Paths: []string{
+			writePlugin(t, "annotate", `echo "{\"annotation\": \"$1\"}"`),
+		}},
+	}
+	When(mockRunner.Apply(Any[command.ProjectContext]())).ThenReturn(command.ProjectResult{ApplySuccess: "success"})
+
+	result := runner.Apply(lifecyclePluginCtx(t))
+	Equals(t, "success", result.ApplySuccess)
+	Equals(t, []string{"pre-apply", "post-apply"}, result.Annotations)
+}
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LockAuditEntry records who deleted a lock.
+type LockAuditEntry struct {
+	Time         time.Time `json:"time"`
+	LockID       string    `json:"lock_id"`
+	RepoFullName string    `json:"repo"`
+	PullNum      int       `json:"pull_num"`
+	ProjectName  string    `json:"project_name,omitempty"`
+	Path         string    `json:"path"`
+	Workspace    string    `json:"workspace"`
+	// LockedBy is the author of the pull request that held the lock.
+	LockedBy string `json:"locked_by"`
+	// Command is the command that created the lock.
+	Command string `json:"command,omitempty"`
+	// Actor is the web username that deleted the lock or, without basic
+	// auth, the address the request came from.
+	Actor string `json:"actor"`
+}
+
+// LockAuditLog records the locks deleted through the UI.
+type LockAuditLog interface {
+	Record(entry LockAuditEntry) error
+	// Recent returns up to n entries, newest first.
+	Recent(n int) ([]LockAuditEntry, error)
+}
+
+// FileLockAuditLog appends entries as JSON lines to the file at Path. The
+// file is never truncated so it can be shipped to a log pipeline.
+type FileLockAuditLog struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileLockAuditLog) Record(entry LockAuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "opening lock audit log")
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close() // nolint: errcheck
+		return errors.Wrap(err, "writing lock audit log")
+	}
+	return file.Close()
+}
+
+func (f *FileLockAuditLog) Recent(n int) ([]LockAuditEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.Open(f.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening lock audit log")
+	}
+	defer file.Close() // nolint: errcheck
+
+	var entries []LockAuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry LockAuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrap(err, "parsing lock audit log")
+		}
+		entries = append(entries, entry)
+		if len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading lock audit log")
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
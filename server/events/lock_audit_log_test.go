@@ -0,0 +1,40 @@
+package events_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFileLockAuditLog(t *testing.T) {
+	auditLog := &events.FileLockAuditLog{Path: filepath.Join(t.TempDir(), "lock-audit.jsonl")}
+	entries, err := auditLog.Recent(10)
+	Ok(t, err)
+	Equals(t, 0, len(entries))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := 1; i <= 3; i++ {
+		Ok(t, auditLog.Record(events.LockAuditEntry{
+			Time:         now,
+			LockID:       "owner/repo/./default",
+			RepoFullName: "owner/repo",
+			PullNum:      i,
+			Path:         ".",
+			Workspace:    "default",
+			LockedBy:     "author",
+			Command:      "plan",
+			Actor:        "admin",
+		}))
+	}
+
+	entries, err = auditLog.Recent(2)
+	Ok(t, err)
+	Equals(t, 2, len(entries))
+	Equals(t, 3, entries[0].PullNum)
+	Equals(t, 2, entries[1].PullNum)
+	Equals(t, now, entries[0].Time)
+	Equals(t, "admin", entries[0].Actor)
+}
@@ -21,20 +21,20 @@ import (
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 var (
-	planCommandTitle            = command.Plan.TitleString()
-	applyCommandTitle           = command.Apply.TitleString()
-	policyCheckCommandTitle     = command.PolicyCheck.TitleString()
-	approvePoliciesCommandTitle = command.ApprovePolicies.TitleString()
-	versionCommandTitle         = command.Version.TitleString()
-	importCommandTitle          = command.Import.TitleString()
-	stateCommandTitle           = command.State.TitleString()
+	planCommandTitle             = command.Plan.TitleString()
+	applyCommandTitle            = command.Apply.TitleString()
+	policyCheckCommandTitle      = command.PolicyCheck.TitleString()
+	approvePoliciesCommandTitle  = command.ApprovePolicies.TitleString()
+	versionCommandTitle          = command.Version.TitleString()
+	importCommandTitle           = command.Import.TitleString()
+	stateCommandTitle            = command.State.TitleString()
+	forceUnlockStateCommandTitle = command.ForceUnlockState.TitleString()
 	// maxUnwrappedLines is the maximum number of lines the Terraform output
 	// can be before we wrap it in an expandable template.
 	maxUnwrappedLines = 12
@@ -75,6 +75,9 @@ type commonData struct {
 	HideUnchangedPlanComments bool
 	QuietPolicyChecks         bool
 	VcsRequestType            string
+	// ContinueOnError is true if the apply kept going after a project
+	// failed, in which case the summary lists the status of each project.
+	ContinueOnError bool
 }
 
 // errData is data about an error response.
@@ -122,6 +125,12 @@ type planSuccessData struct {
 	PlanStats                models.PlanSuccessStats
 }
 
+type importSuccessData struct {
+	models.ImportSuccess
+	// RenderedPlan is the rendered plan of the project after the import.
+	RenderedPlan string
+}
+
 type policyCheckResultsData struct {
 	models.PolicyCheckResults
 	PreConftestOutput     string
@@ -177,7 +186,14 @@ func NewMarkdownRenderer(
 // Render formats the data into a markdown string.
 // nolint: interfacer
 func (m *MarkdownRenderer) Render(ctx *command.Context, res command.Result, cmd PullCommand) string {
-	commandStr := cases.Title(language.English).String(strings.Replace(cmd.CommandName().String(), "_", " ", -1))
+	return m.RenderLayout(ctx, res, cmd, valid.CommentLayoutCombined)
+}
+
+// RenderLayout formats the data into a markdown string, collapsing the
+// results of each project if there are several and layout is
+// valid.CommentLayoutCollapsed or valid.CommentLayoutFailuresExpanded.
+func (m *MarkdownRenderer) RenderLayout(ctx *command.Context, res command.Result, cmd PullCommand, layout string) string {
+	commandStr := cmd.CommandName().TitleString()
 	var vcsRequestType string
 	if ctx.Pull.BaseRepo.VCSHost.Type == models.Gitlab {
 		vcsRequestType = "Merge Request"
@@ -200,19 +216,102 @@ func (m *MarkdownRenderer) Render(ctx *command.Context, res command.Result, cmd
 		QuietPolicyChecks:         m.quietPolicyChecks,
 		VcsRequestType:            vcsRequestType,
 	}
+	if commentCmd, ok := cmd.(*CommentCommand); ok {
+		common.ContinueOnError = commentCmd.ContinueOnError
+	}
 
 	templates := m.markdownTemplates
 
+	// The review of the modified repo config is its own section before the
+	// results.
+	var review string
+	if res.RepoCfgReview != nil {
+		review = m.renderTemplateTrimSpace(templates.Lookup("repoCfgReview"), repoCfgReviewData{*res.RepoCfgReview, vcsRequestType}) + "\n\n"
+	}
+
 	if res.Error != nil {
-		return m.renderTemplateTrimSpace(templates.Lookup("unwrappedErrWithLog"), errData{res.Error.Error(), "", common})
+		return review + m.renderTemplateTrimSpace(templates.Lookup("unwrappedErrWithLog"), errData{res.Error.Error(), "", common})
 	}
 	if res.Failure != "" {
-		return m.renderTemplateTrimSpace(templates.Lookup("failureWithLog"), failureData{res.Failure, "", common})
+		return review + m.renderTemplateTrimSpace(templates.Lookup("failureWithLog"), failureData{res.Failure, "", common})
+	}
+	if cmd.CommandName() == command.Rollback {
+		// A rollback plan is applied like any other plan so it's rendered
+		// like one, with the instructions to apply it.
+		common.Command = planCommandTitle
+	}
+	rendered := review + m.renderProjectResults(ctx, res.ProjectResults, common, layout)
+	if len(res.ImpactPreviews) > 0 {
+		rendered += "\n\n" + m.renderImpactPreviews(ctx, res.ImpactPreviews, common)
+	}
+	return rendered
+}
+
+// repoCfgReviewData is the data of the repoCfgReview template.
+type repoCfgReviewData struct {
+	command.RepoCfgReview
+	VcsRequestType string
+}
+
+// renderImpactPreviews renders the plans of the projects that were only
+// planned to preview the impact of the pull request.
+func (m *MarkdownRenderer) renderImpactPreviews(ctx *command.Context, results []command.ProjectResult, common commonData) string {
+	vcsHost := ctx.Pull.BaseRepo.VCSHost.Type
+	templates := m.markdownTemplates
+
+	var resultsTmplData []projectResultTmplData
+	for _, result := range results {
+		resultData := projectResultTmplData{
+			Workspace:    result.Workspace,
+			RepoRelDir:   result.RepoRelDir,
+			ProjectName:  result.ProjectName,
+			IsSuccessful: result.IsSuccessful(),
+		}
+		switch {
+		case result.Error != nil:
+			tmpl := templates.Lookup("unwrappedErr")
+			if m.shouldUseWrappedTmpl(vcsHost, result.Error.Error()) {
+				tmpl = templates.Lookup("wrappedErr")
+			}
+			resultData.Rendered = m.renderTemplateTrimSpace(tmpl, errData{result.Error.Error(), "", common})
+		case result.Failure != "":
+			resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("failure"), failureData{result.Failure, "", common})
+		case result.PlanSuccess != nil:
+			output := strings.TrimSpace(result.PlanSuccess.TerraformOutput)
+			data := struct {
+				TerraformOutput string
+				PlanSummary     string
+			}{output, result.PlanSuccess.Summary()}
+			if m.shouldUseWrappedTmpl(vcsHost, output) {
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("impactPreviewPlanWrapped"), data)
+			} else {
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("impactPreviewPlanUnwrapped"), data)
+			}
+		}
+		resultsTmplData = append(resultsTmplData, resultData)
 	}
-	return m.renderProjectResults(ctx, res.ProjectResults, common)
+	return m.renderTemplateTrimSpace(templates.Lookup("impactPreview"), resultData{resultsTmplData, common})
 }
 
-func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []command.ProjectResult, common commonData) string {
+// renderPlanSuccess renders the output of a successful plan.
+func (m *MarkdownRenderer) renderPlanSuccess(vcsHost models.VCSHostType, common commonData, planSuccess *models.PlanSuccess) string {
+	planSuccess.TerraformOutput = strings.TrimSpace(planSuccess.TerraformOutput)
+	data := planSuccessData{
+		PlanSuccess:              *planSuccess,
+		PlanWasDeleted:           common.PlansDeleted,
+		DisableApply:             common.DisableApply,
+		DisableRepoLocking:       common.DisableRepoLocking,
+		EnableDiffMarkdownFormat: common.EnableDiffMarkdownFormat,
+		PlanStats:                planSuccess.Stats(),
+	}
+	if m.shouldUseWrappedTmpl(vcsHost, planSuccess.TerraformOutput) {
+		data.PlanSummary = planSuccess.Summary()
+		return m.renderTemplateTrimSpace(m.markdownTemplates.Lookup("planSuccessWrapped"), data)
+	}
+	return m.renderTemplateTrimSpace(m.markdownTemplates.Lookup("planSuccessUnwrapped"), data)
+}
+
+func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []command.ProjectResult, common commonData, layout string) string {
 	vcsHost := ctx.Pull.BaseRepo.VCSHost.Type
 
 	var resultsTmplData []projectResultTmplData
@@ -236,21 +335,7 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 			IsSuccessful: result.IsSuccessful(),
 		}
 		if result.PlanSuccess != nil {
-			result.PlanSuccess.TerraformOutput = strings.TrimSpace(result.PlanSuccess.TerraformOutput)
-			data := planSuccessData{
-				PlanSuccess:              *result.PlanSuccess,
-				PlanWasDeleted:           common.PlansDeleted,
-				DisableApply:             common.DisableApply,
-				DisableRepoLocking:       common.DisableRepoLocking,
-				EnableDiffMarkdownFormat: common.EnableDiffMarkdownFormat,
-				PlanStats:                result.PlanSuccess.Stats(),
-			}
-			if m.shouldUseWrappedTmpl(vcsHost, result.PlanSuccess.TerraformOutput) {
-				data.PlanSummary = result.PlanSuccess.Summary()
-				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("planSuccessWrapped"), data)
-			} else {
-				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("planSuccessUnwrapped"), data)
-			}
+			resultData.Rendered = m.renderPlanSuccess(vcsHost, common, result.PlanSuccess)
 			resultData.NoChanges = result.PlanSuccess.NoChanges()
 			if result.PlanSuccess.NoChanges() {
 				numPlansWithNoChanges++
@@ -310,10 +395,14 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 			numVersionSuccesses++
 		} else if result.ImportSuccess != nil {
 			result.ImportSuccess.Output = strings.TrimSpace(result.ImportSuccess.Output)
+			data := importSuccessData{ImportSuccess: *result.ImportSuccess}
+			if result.ImportSuccess.Plan != nil {
+				data.RenderedPlan = m.renderPlanSuccess(vcsHost, common, result.ImportSuccess.Plan)
+			}
 			if m.shouldUseWrappedTmpl(vcsHost, result.ImportSuccess.Output) {
-				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("importSuccessWrapped"), result.ImportSuccess)
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("importSuccessWrapped"), data)
 			} else {
-				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("importSuccessUnwrapped"), result.ImportSuccess)
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("importSuccessUnwrapped"), data)
 			}
 		} else if result.StateRmSuccess != nil {
 			result.StateRmSuccess.Output = strings.TrimSpace(result.StateRmSuccess.Output)
@@ -322,6 +411,20 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 			} else {
 				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("stateRmSuccessUnwrapped"), result.StateRmSuccess)
 			}
+		} else if result.StateReadSuccess != "" {
+			output := strings.TrimSpace(result.StateReadSuccess)
+			if m.shouldUseWrappedTmpl(vcsHost, output) {
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("stateReadSuccessWrapped"), struct{ Output string }{output})
+			} else {
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("stateReadSuccessUnwrapped"), struct{ Output string }{output})
+			}
+		} else if result.ForceUnlockStateSuccess != "" {
+			output := strings.TrimSpace(result.ForceUnlockStateSuccess)
+			if m.shouldUseWrappedTmpl(vcsHost, output) {
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("forceUnlockStateSuccessWrapped"), struct{ Output string }{output})
+			} else {
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("forceUnlockStateSuccessUnwrapped"), struct{ Output string }{output})
+			}
 			// Error out if no template was found, only if there are no errors or failures.
 			// This is because some errors and failures rely on additional context rendered by templates, but not all errors or failures.
 		} else if !(result.Error != nil || result.Failure != "") {
@@ -343,9 +446,25 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 				numApplyFailures++
 			}
 		}
+		if result.AuthFailure != nil {
+			resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("authFailure"), result.AuthFailure) + "\n\n" + resultData.Rendered
+		}
+		if len(result.Annotations) > 0 {
+			resultData.Rendered += "\n\n" + strings.Join(result.Annotations, "\n\n")
+		}
 		resultsTmplData = append(resultsTmplData, resultData)
 	}
 
+	if len(resultsTmplData) > 1 && m.supportsFolding(vcsHost) && (layout == valid.CommentLayoutCollapsed || layout == valid.CommentLayoutFailuresExpanded) {
+		for i, resultData := range resultsTmplData {
+			data := struct {
+				projectResultTmplData
+				Open bool
+			}{resultData, layout == valid.CommentLayoutFailuresExpanded && !resultData.IsSuccessful}
+			resultsTmplData[i].Rendered = m.renderTemplateTrimSpace(templates.Lookup("collapsedProjectResult"), data)
+		}
+	}
+
 	var tmpl *template.Template
 	switch {
 	case len(resultsTmplData) == 1 && common.Command == planCommandTitle && numPlanSuccesses > 0:
@@ -368,9 +487,13 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 		switch common.SubCommand {
 		case "rm":
 			tmpl = templates.Lookup("singleProjectStateRm")
+		case "list", "show":
+			tmpl = templates.Lookup("singleProjectStateRead")
 		default:
 			return fmt.Sprintf("no template matched–this is a bug: command=%s, subcommand=%s", common.Command, common.SubCommand)
 		}
+	case len(resultsTmplData) == 1 && common.Command == forceUnlockStateCommandTitle:
+		tmpl = templates.Lookup("singleProjectForceUnlockState")
 	case common.Command == planCommandTitle:
 		tmpl = templates.Lookup("multiProjectPlan")
 	case common.Command == policyCheckCommandTitle:
@@ -395,9 +518,13 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 		switch common.SubCommand {
 		case "rm":
 			tmpl = templates.Lookup("multiProjectStateRm")
+		case "list", "show":
+			tmpl = templates.Lookup("multiProjectStateRead")
 		default:
 			return fmt.Sprintf("no template matched–this is a bug: command=%s, subcommand=%s", common.Command, common.SubCommand)
 		}
+	case common.Command == forceUnlockStateCommandTitle:
+		tmpl = templates.Lookup("multiProjectForceUnlockState")
 	default:
 		return fmt.Sprintf("no template matched–this is a bug: command=%s", common.Command)
 	}
@@ -417,6 +544,11 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 // load. Some VCS providers or versions of VCS providers don't support this
 // syntax.
 func (m *MarkdownRenderer) shouldUseWrappedTmpl(vcsHost models.VCSHostType, output string) bool {
+	return m.supportsFolding(vcsHost) && strings.Count(output, "\n") > maxUnwrappedLines
+}
+
+// supportsFolding returns true if output can be collapsed on vcsHost.
+func (m *MarkdownRenderer) supportsFolding(vcsHost models.VCSHostType) bool {
 	if m.disableMarkdownFolding {
 		return false
 	}
@@ -426,11 +558,13 @@ func (m *MarkdownRenderer) shouldUseWrappedTmpl(vcsHost models.VCSHostType, outp
 		return false
 	}
 
-	if vcsHost == models.Gitlab && !m.gitlabSupportsCommonMark {
-		return false
-	}
+	return vcsHost != models.Gitlab || m.gitlabSupportsCommonMark
+}
 
-	return strings.Count(output, "\n") > maxUnwrappedLines
+// renderCanaryProgress renders the progress of the projects of a dir with a
+// canary.
+func (m *MarkdownRenderer) renderCanaryProgress(data canaryProgressData) string {
+	return m.renderTemplateTrimSpace(m.markdownTemplates.Lookup("canaryProgress"), data)
 }
 
 func (m *MarkdownRenderer) renderTemplateTrimSpace(tmpl *template.Template, data interface{}) string {
@@ -19,7 +19,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -562,6 +564,55 @@ $$$
 
 :put_litter_in_its_place: A plan file was discarded. Re-plan would be required before applying.
 
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  atlantis plan -d path -w workspace
+  $$$
+`,
+		},
+		{
+			"single successful import planned again",
+			command.Import,
+			"",
+			[]command.ProjectResult{
+				{
+					ImportSuccess: &models.ImportSuccess{
+						Output:    "import-output",
+						RePlanCmd: "atlantis plan -d path -w workspace",
+						Plan: &models.PlanSuccess{
+							TerraformOutput: "terraform-output",
+							LockURL:         "lock-url",
+							RePlanCmd:       "atlantis plan -d path -w workspace",
+							ApplyCmd:        "atlantis apply -d path -w workspace",
+						},
+						UnmatchedImports: []string{"aws_instance.b"},
+					},
+					Workspace:   "workspace",
+					RepoRelDir:  "path",
+					ProjectName: "projectname",
+				},
+			},
+			models.Github,
+			`
+Ran Import for project: $projectname$ dir: $path$ workspace: $workspace$
+
+$$$diff
+import-output
+$$$
+
+:warning: The plan still creates $aws_instance.b$. Check the imported addresses match the configuration.
+
+:repeat: The project was planned again after the import:
+
+$$$diff
+terraform-output
+$$$
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  atlantis apply -d path -w workspace
+  $$$
+* :put_litter_in_its_place: To **delete** this plan and lock, click [here](lock-url)
 * :repeat: To **plan** this project again, comment:
   $$$shell
   atlantis plan -d path -w workspace
@@ -2128,6 +2179,607 @@ func TestRenderProjectResults_DisableFolding(t *testing.T) {
 	Equals(t, false, strings.Contains(rendered, "\n<details>"))
 }
 
+func TestRenderProjectResults_TargetedPlan(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	exp := ":dart: This is a **targeted plan**, applying it is a **partial apply** of only `aws_instance.web`, `module.app`.\n\n```diff\nterraform-output\n```"
+
+	for _, targets := range [][]string{nil, {"aws_instance.web", "module.app"}} {
+		res := command.Result{
+			ProjectResults: []command.ProjectResult{
+				{
+					PlanSuccess: &models.PlanSuccess{
+						TerraformOutput: "terraform-output",
+						LockURL:         "lock-url",
+						RePlanCmd:       "atlantis plan -d path -w workspace",
+						ApplyCmd:        "atlantis apply -d path -w workspace",
+						Targets:         targets,
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+		}
+		rendered := mr.Render(ctx, res, cmd)
+		Equals(t, targets != nil, strings.Contains(rendered, exp))
+		Equals(t, targets != nil, strings.Contains(rendered, "targeted plan"))
+	}
+}
+
+func TestRenderProjectResults_ProtectedDestroys(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	exp := ":warning: This plan **destroys or replaces protected resources** `aws_db_instance.db`, `aws_s3_bucket.logs`. Applying it must be confirmed with `--confirm-destroy`.\n\n```diff\nterraform-output\n```"
+
+	for _, addresses := range [][]string{nil, {"aws_db_instance.db", "aws_s3_bucket.logs"}} {
+		res := command.Result{
+			ProjectResults: []command.ProjectResult{
+				{
+					PlanSuccess: &models.PlanSuccess{
+						TerraformOutput:   "terraform-output",
+						LockURL:           "lock-url",
+						RePlanCmd:         "atlantis plan -d path -w workspace",
+						ApplyCmd:          "atlantis apply -d path -w workspace",
+						ProtectedDestroys: addresses,
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+		}
+		rendered := mr.Render(ctx, res, cmd)
+		Equals(t, addresses != nil, strings.Contains(rendered, exp))
+		Equals(t, addresses != nil, strings.Contains(rendered, "protected resources"))
+	}
+}
+
+func TestRenderProjectResults_PlanRisk(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	cases := []struct {
+		risk *models.PlanRisk
+		exp  string
+	}{
+		{
+			risk: &models.PlanRisk{Score: 3},
+			exp:  ":bar_chart: This plan has a risk score of **3**.\n\n```diff",
+		},
+		{
+			risk: &models.PlanRisk{Score: 12, Level: "medium", Approvals: 1},
+			exp:  ":bar_chart: This plan has a risk score of **12** (medium). Applying it requires 1 approval.\n\n```diff",
+		},
+		{
+			risk: &models.PlanRisk{Score: 25, Level: "high", Approvals: 2, Reviewers: []string{"@alice", "platform"}},
+			exp:  ":bar_chart: This plan has a risk score of **25** (high). Applying it requires 2 approvals, including one from @alice, platform.\n\n```diff",
+		},
+	}
+	for _, c := range cases {
+		res := command.Result{
+			ProjectResults: []command.ProjectResult{
+				{
+					PlanSuccess: &models.PlanSuccess{
+						TerraformOutput: "terraform-output",
+						LockURL:         "lock-url",
+						RePlanCmd:       "atlantis plan -d path -w workspace",
+						ApplyCmd:        "atlantis apply -d path -w workspace",
+						Risk:            c.risk,
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+		}
+		rendered := mr.Render(ctx, res, cmd)
+		Assert(t, strings.Contains(rendered, c.exp), "exp %q in %q", c.exp, rendered)
+	}
+}
+
+func TestRenderProjectResults_PlanAutoApproval(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	cases := []struct {
+		autoApproval *models.PlanAutoApproval
+		exp          string
+	}{
+		{
+			autoApproval: &models.PlanAutoApproval{Rules: []string{"dns"}},
+			exp:          ":white_check_mark: This plan is low risk according to the auto-approval rule `dns` so it can be applied without approval.\n\n```diff",
+		},
+		{
+			autoApproval: &models.PlanAutoApproval{Rules: []string{"dns", "tags"}, ApplyAt: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)},
+			exp:          ":white_check_mark: This plan is low risk according to the auto-approval rules `dns`, `tags` so it can be applied without approval. It will be applied automatically at 2024-05-01 12:30 UTC unless it's planned again.\n\n```diff",
+		},
+	}
+	for _, c := range cases {
+		res := command.Result{
+			ProjectResults: []command.ProjectResult{
+				{
+					PlanSuccess: &models.PlanSuccess{
+						TerraformOutput: "terraform-output",
+						LockURL:         "lock-url",
+						RePlanCmd:       "atlantis plan -d path -w workspace",
+						ApplyCmd:        "atlantis apply -d path -w workspace",
+						AutoApproval:    c.autoApproval,
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+		}
+		rendered := mr.Render(ctx, res, cmd)
+		Assert(t, strings.Contains(rendered, c.exp), "exp %q in %q", c.exp, rendered)
+	}
+}
+
+func TestRenderProjectResults_PlanDelta(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	cases := []struct {
+		delta *models.PlanDelta
+		exp   string
+	}{
+		{
+			delta: &models.PlanDelta{
+				PreviousCommit: "abc1234567",
+				Added:          []models.PlannedResourceChange{{Address: "aws_instance.web", Action: "replace"}},
+				Removed: []models.PlannedResourceChange{
+					{Address: "aws_instance.web", Action: "update"},
+					{Address: "aws_s3_bucket.logs", Action: "create"},
+				},
+			},
+			exp: ":mag: **Changes since the last plan** at `abc1234`:\n* :heavy_plus_sign: `aws_instance.web` (replace)\n* :heavy_minus_sign: `aws_instance.web` (update)\n* :heavy_minus_sign: `aws_s3_bucket.logs` (create)\n\n```diff",
+		},
+		{
+			delta: &models.PlanDelta{PreviousCommit: "abc1234567"},
+			exp:   ":mag: The resource changes are the same as in the last plan at `abc1234`.\n\n```diff",
+		},
+	}
+	for _, c := range cases {
+		res := command.Result{
+			ProjectResults: []command.ProjectResult{
+				{
+					PlanSuccess: &models.PlanSuccess{
+						TerraformOutput: "terraform-output",
+						LockURL:         "lock-url",
+						RePlanCmd:       "atlantis plan -d path -w workspace",
+						ApplyCmd:        "atlantis apply -d path -w workspace",
+						Delta:           c.delta,
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+		}
+		rendered := mr.Render(ctx, res, cmd)
+		Assert(t, strings.Contains(rendered, c.exp), "exp %q in %q", c.exp, rendered)
+	}
+}
+
+func TestRenderProjectResults_ImpactPreview(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			{
+				PlanSuccess: &models.PlanSuccess{
+					TerraformOutput: "terraform-output",
+					LockURL:         "lock-url",
+					RePlanCmd:       "atlantis plan -d network",
+					ApplyCmd:        "atlantis apply -d network",
+				},
+				Workspace:  "default",
+				RepoRelDir: "network",
+			},
+		},
+		ImpactPreviews: []command.ProjectResult{
+			{
+				PlanSuccess: &models.PlanSuccess{TerraformOutput: "app-output"},
+				Workspace:   "default",
+				RepoRelDir:  "app",
+				ProjectName: "app",
+			},
+			{
+				Failure:    "locked",
+				Workspace:  "default",
+				RepoRelDir: "db",
+			},
+		},
+	}
+	rendered := mr.Render(ctx, res, cmd)
+	exp := "### Impact preview\n\n" +
+		"These projects aren't modified by this Pull Request but consume the outputs of projects that are. They were planned with the current outputs of those projects to preview the impact, and their plans can't be applied.\n\n" +
+		"#### 1. project: `app` dir: `app` workspace: `default`\n" +
+		"```diff\napp-output\n```\n\n" +
+		"#### 2. dir: `db` workspace: `default`\n" +
+		"**Plan Failed**: locked"
+	Assert(t, strings.HasSuffix(rendered, exp), "exp %q at the end of %q", exp, rendered)
+	Assert(t, strings.Contains(rendered, "atlantis apply -d network"), "exp apply command in %q", rendered)
+	Assert(t, !strings.Contains(rendered, "atlantis apply -d app"), "exp no apply command for preview in %q", rendered)
+}
+
+func TestRenderProjectResults_RepoCfgReview(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	cases := []struct {
+		description string
+		res         command.Result
+		exp         string
+	}{
+		{
+			description: "changes",
+			res: command.Result{
+				RepoCfgReview: &command.RepoCfgReview{
+					File:     "atlantis.yaml",
+					Compared: true,
+					Changes:  []string{"project `app` added", "project `db` removed"},
+				},
+				ProjectResults: []command.ProjectResult{
+					{
+						PlanSuccess: &models.PlanSuccess{TerraformOutput: "app-output"},
+						Workspace:   "default",
+						RepoRelDir:  "app",
+						ProjectName: "app",
+					},
+				},
+			},
+			exp: "### `atlantis.yaml` changes\n\n" +
+				":white_check_mark: The `atlantis.yaml` modified by this Pull Request is valid and allowed by the server-side repo config, so it was used to plan. The projects it adds or changes were planned too:\n\n" +
+				"* project `app` added\n" +
+				"* project `db` removed\n\n" +
+				"Ran Plan for project: `app` dir: `app` workspace: `default`",
+		},
+		{
+			description: "no changes",
+			res: command.Result{
+				RepoCfgReview: &command.RepoCfgReview{File: "atlantis.yaml", Compared: true},
+			},
+			exp: "### `atlantis.yaml` changes\n\n" +
+				":white_check_mark: The `atlantis.yaml` modified by this Pull Request is valid and allowed by the server-side repo config, so it was used to plan. It doesn't change the projects.\n\n",
+		},
+		{
+			description: "not compared",
+			res: command.Result{
+				RepoCfgReview: &command.RepoCfgReview{File: "atlantis.yaml"},
+			},
+			exp: "### `atlantis.yaml` changes\n\n" +
+				":white_check_mark: The `atlantis.yaml` modified by this Pull Request is valid and allowed by the server-side repo config, so it was used to plan. The `atlantis.yaml` of the base branch couldn't be read so its changes aren't listed.\n\n",
+		},
+		{
+			description: "invalid",
+			res: command.Result{
+				RepoCfgReview: &command.RepoCfgReview{File: "atlantis.yaml", Error: "workflow \"custom\" is not allowed"},
+				Error:         errors.New("parsing atlantis.yaml: workflow \"custom\" is not allowed"),
+			},
+			exp: "### `atlantis.yaml` changes\n\n" +
+				":x: The `atlantis.yaml` modified by this Pull Request is invalid or not allowed by the server-side repo config, so nothing was planned:\n" +
+				"```\nworkflow \"custom\" is not allowed\n```\n\n" +
+				"**Plan Error**",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			rendered := mr.Render(ctx, c.res, cmd)
+			Assert(t, strings.HasPrefix(rendered, c.exp), "exp %q at the start of %q", c.exp, rendered)
+		})
+	}
+}
+
+func TestRenderProjectResults_AuthFailure(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			{
+				RepoRelDir: "network",
+				Workspace:  "default",
+				Error:      errors.New("exit status 1: Error: ExpiredToken: The security token included in the request is expired"),
+				AuthFailure: &command.AuthFailure{
+					Provider: "AWS",
+					Reason:   "Atlantis's AWS credentials have expired",
+					Hint:     "Atlantis's AWS credentials have expired. Contact #platform.",
+				},
+			},
+		},
+	}
+	rendered := mr.Render(ctx, res, &events.CommentCommand{Name: command.Plan})
+	exp := "Ran Plan for dir: `network` workspace: `default`\n\n" +
+		":closed_lock_with_key: **AWS authentication failed**: Atlantis's AWS credentials have expired. Contact #platform.\n\n" +
+		"**Plan Error**"
+	Assert(t, strings.HasPrefix(rendered, exp), "exp %q at the start of %q", exp, rendered)
+}
+
+func TestRenderProjectResults_CommentLayout(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Apply,
+	}
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			{
+				RepoRelDir:   "network",
+				Workspace:    "default",
+				ApplySuccess: "network-output",
+			},
+			{
+				RepoRelDir: "app",
+				Workspace:  "default",
+				Error:      errors.New("app-error"),
+			},
+		},
+	}
+
+	rendered := mr.RenderLayout(ctx, res, cmd, valid.CommentLayoutCombined)
+	Equals(t, false, strings.Contains(rendered, "<details"))
+
+	rendered = mr.RenderLayout(ctx, res, cmd, valid.CommentLayoutCollapsed)
+	Assert(t, strings.Contains(rendered, "<details><summary>Succeeded</summary>\n\n```diff\nnetwork-output\n```\n</details>"), "exp network collapsed in %q", rendered)
+	Assert(t, strings.Contains(rendered, "<details><summary>Failed</summary>"), "exp app collapsed in %q", rendered)
+
+	rendered = mr.RenderLayout(ctx, res, cmd, valid.CommentLayoutFailuresExpanded)
+	Assert(t, strings.Contains(rendered, "<details><summary>Succeeded</summary>"), "exp network collapsed in %q", rendered)
+	Assert(t, strings.Contains(rendered, "<details open><summary>Failed</summary>"), "exp app expanded in %q", rendered)
+
+	// A single project isn't collapsed.
+	res.ProjectResults = res.ProjectResults[:1]
+	rendered = mr.RenderLayout(ctx, res, cmd, valid.CommentLayoutCollapsed)
+	Equals(t, false, strings.Contains(rendered, "<details"))
+}
+
+func TestRenderProjectResults_ContinueOnError(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t).WithHistory(),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			{
+				Workspace:    "default",
+				RepoRelDir:   "network",
+				ProjectName:  "network",
+				ApplySuccess: "success",
+			},
+			{
+				Workspace:   "default",
+				RepoRelDir:  "app",
+				ProjectName: "app",
+				Failure:     "failure",
+			},
+		},
+	}
+	exp := "2 projects, 1 successful, 1 failed, 0 errored\n\n" +
+		"* :white_check_mark: project: `network` dir: `network` workspace: `default`\n" +
+		"* :x: project: `app` dir: `app` workspace: `default`"
+
+	for _, continueOnError := range []bool{false, true} {
+		cmd := &events.CommentCommand{
+			Name:            command.Apply,
+			ContinueOnError: continueOnError,
+		}
+		rendered := mr.Render(ctx, res, cmd)
+		Equals(t, continueOnError, strings.Contains(rendered, exp))
+	}
+}
+
 // Test that if the output is longer than 12 lines, it gets wrapped on the right
 // VCS hosts during an error.
 func TestRenderProjectResults_WrappedErr(t *testing.T) {
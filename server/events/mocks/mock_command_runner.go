@@ -42,6 +42,14 @@ func (mock *MockCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 	pegomock.GetGenericMockFrom(mock).Invoke("RunCommentCommand", _params, []reflect.Type{})
 }
 
+func (mock *MockCommandRunner) RunIssueCommentCommand(baseRepo models.Repo, defaultBranch string, user models.User, issueNum int, cmd *events.CommentCommand) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockCommandRunner().")
+	}
+	_params := []pegomock.Param{baseRepo, defaultBranch, user, issueNum, cmd}
+	pegomock.GetGenericMockFrom(mock).Invoke("RunIssueCommentCommand", _params, []reflect.Type{})
+}
+
 func (mock *MockCommandRunner) VerifyWasCalledOnce() *VerifierMockCommandRunner {
 	return &VerifierMockCommandRunner{
 		mock:                   mock,
@@ -184,3 +192,56 @@ func (c *MockCommandRunner_RunCommentCommand_OngoingVerification) GetAllCaptured
 	}
 	return
 }
+
+func (verifier *VerifierMockCommandRunner) RunIssueCommentCommand(baseRepo models.Repo, defaultBranch string, user models.User, issueNum int, cmd *events.CommentCommand) *MockCommandRunner_RunIssueCommentCommand_OngoingVerification {
+	_params := []pegomock.Param{baseRepo, defaultBranch, user, issueNum, cmd}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RunIssueCommentCommand", _params, verifier.timeout)
+	return &MockCommandRunner_RunIssueCommentCommand_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockCommandRunner_RunIssueCommentCommand_OngoingVerification struct {
+	mock              *MockCommandRunner
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockCommandRunner_RunIssueCommentCommand_OngoingVerification) GetCapturedArguments() (models.Repo, string, models.User, int, *events.CommentCommand) {
+	baseRepo, defaultBranch, user, issueNum, cmd := c.GetAllCapturedArguments()
+	return baseRepo[len(baseRepo)-1], defaultBranch[len(defaultBranch)-1], user[len(user)-1], issueNum[len(issueNum)-1], cmd[len(cmd)-1]
+}
+
+func (c *MockCommandRunner_RunIssueCommentCommand_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []string, _param2 []models.User, _param3 []int, _param4 []*events.CommentCommand) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.Repo, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.Repo)
+			}
+		}
+		if len(_params) > 1 {
+			_param1 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[1] {
+				_param1[u] = param.(string)
+			}
+		}
+		if len(_params) > 2 {
+			_param2 = make([]models.User, len(c.methodInvocations))
+			for u, param := range _params[2] {
+				_param2[u] = param.(models.User)
+			}
+		}
+		if len(_params) > 3 {
+			_param3 = make([]int, len(c.methodInvocations))
+			for u, param := range _params[3] {
+				_param3[u] = param.(int)
+			}
+		}
+		if len(_params) > 4 {
+			_param4 = make([]*events.CommentCommand, len(c.methodInvocations))
+			for u, param := range _params[4] {
+				_param4[u] = param.(*events.CommentCommand)
+			}
+		}
+	}
+	return
+}
@@ -55,6 +55,21 @@ func (mock *MockProjectCommandRunner) ApprovePolicies(ctx command.ProjectContext
 	return _ret0
 }
 
+func (mock *MockProjectCommandRunner) ForceUnlockState(ctx command.ProjectContext) command.ProjectResult {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockProjectCommandRunner().")
+	}
+	_params := []pegomock.Param{ctx}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ForceUnlockState", _params, []reflect.Type{reflect.TypeOf((*command.ProjectResult)(nil)).Elem()})
+	var _ret0 command.ProjectResult
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(command.ProjectResult)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockProjectCommandRunner) Import(ctx command.ProjectContext) command.ProjectResult {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockProjectCommandRunner().")
@@ -70,6 +85,21 @@ func (mock *MockProjectCommandRunner) Import(ctx command.ProjectContext) command
 	return _ret0
 }
 
+func (mock *MockProjectCommandRunner) Rollback(ctx command.ProjectContext) command.ProjectResult {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockProjectCommandRunner().")
+	}
+	_params := []pegomock.Param{ctx}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("Rollback", _params, []reflect.Type{reflect.TypeOf((*command.ProjectResult)(nil)).Elem()})
+	var _ret0 command.ProjectResult
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(command.ProjectResult)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockProjectCommandRunner) Plan(ctx command.ProjectContext) command.ProjectResult {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockProjectCommandRunner().")
@@ -100,6 +130,21 @@ func (mock *MockProjectCommandRunner) PolicyCheck(ctx command.ProjectContext) co
 	return _ret0
 }
 
+func (mock *MockProjectCommandRunner) StateList(ctx command.ProjectContext) command.ProjectResult {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockProjectCommandRunner().")
+	}
+	_params := []pegomock.Param{ctx}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("StateList", _params, []reflect.Type{reflect.TypeOf((*command.ProjectResult)(nil)).Elem()})
+	var _ret0 command.ProjectResult
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(command.ProjectResult)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockProjectCommandRunner) StateRm(ctx command.ProjectContext) command.ProjectResult {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockProjectCommandRunner().")
@@ -115,6 +160,21 @@ func (mock *MockProjectCommandRunner) StateRm(ctx command.ProjectContext) comman
 	return _ret0
 }
 
+func (mock *MockProjectCommandRunner) StateShow(ctx command.ProjectContext) command.ProjectResult {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockProjectCommandRunner().")
+	}
+	_params := []pegomock.Param{ctx}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("StateShow", _params, []reflect.Type{reflect.TypeOf((*command.ProjectResult)(nil)).Elem()})
+	var _ret0 command.ProjectResult
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(command.ProjectResult)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockProjectCommandRunner) Version(ctx command.ProjectContext) command.ProjectResult {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockProjectCommandRunner().")
@@ -225,22 +285,67 @@ func (c *MockProjectCommandRunner_ApprovePolicies_OngoingVerification) GetAllCap
 	return
 }
 
+func (verifier *VerifierMockProjectCommandRunner) ForceUnlockState(ctx command.ProjectContext) *MockProjectCommandRunner_ForceUnlockState_OngoingVerification {
+	_params := []pegomock.Param{ctx}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ForceUnlockState", _params, verifier.timeout)
+	return &MockProjectCommandRunner_ForceUnlockState_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockProjectCommandRunner_ForceUnlockState_OngoingVerification struct {
+	mock              *MockProjectCommandRunner
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockProjectCommandRunner_ForceUnlockState_OngoingVerification) GetCapturedArguments() command.ProjectContext {
+	ctx := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1]
+}
+
+func (c *MockProjectCommandRunner_ForceUnlockState_OngoingVerification) GetAllCapturedArguments() (_param0 []command.ProjectContext) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]command.ProjectContext, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(command.ProjectContext)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockProjectCommandRunner) Import(ctx command.ProjectContext) *MockProjectCommandRunner_Import_OngoingVerification {
 	_params := []pegomock.Param{ctx}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Import", _params, verifier.timeout)
 	return &MockProjectCommandRunner_Import_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
 
+func (verifier *VerifierMockProjectCommandRunner) Rollback(ctx command.ProjectContext) *MockProjectCommandRunner_Rollback_OngoingVerification {
+	_params := []pegomock.Param{ctx}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Rollback", _params, verifier.timeout)
+	return &MockProjectCommandRunner_Rollback_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
 type MockProjectCommandRunner_Import_OngoingVerification struct {
 	mock              *MockProjectCommandRunner
 	methodInvocations []pegomock.MethodInvocation
 }
 
+type MockProjectCommandRunner_Rollback_OngoingVerification struct {
+	mock              *MockProjectCommandRunner
+	methodInvocations []pegomock.MethodInvocation
+}
+
 func (c *MockProjectCommandRunner_Import_OngoingVerification) GetCapturedArguments() command.ProjectContext {
 	ctx := c.GetAllCapturedArguments()
 	return ctx[len(ctx)-1]
 }
 
+func (c *MockProjectCommandRunner_Rollback_OngoingVerification) GetCapturedArguments() command.ProjectContext {
+	ctx := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1]
+}
+
 func (c *MockProjectCommandRunner_Import_OngoingVerification) GetAllCapturedArguments() (_param0 []command.ProjectContext) {
 	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(_params) > 0 {
@@ -254,6 +359,19 @@ func (c *MockProjectCommandRunner_Import_OngoingVerification) GetAllCapturedArgu
 	return
 }
 
+func (c *MockProjectCommandRunner_Rollback_OngoingVerification) GetAllCapturedArguments() (_param0 []command.ProjectContext) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]command.ProjectContext, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(command.ProjectContext)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockProjectCommandRunner) Plan(ctx command.ProjectContext) *MockProjectCommandRunner_Plan_OngoingVerification {
 	_params := []pegomock.Param{ctx}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Plan", _params, verifier.timeout)
@@ -312,6 +430,35 @@ func (c *MockProjectCommandRunner_PolicyCheck_OngoingVerification) GetAllCapture
 	return
 }
 
+func (verifier *VerifierMockProjectCommandRunner) StateList(ctx command.ProjectContext) *MockProjectCommandRunner_StateList_OngoingVerification {
+	_params := []pegomock.Param{ctx}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "StateList", _params, verifier.timeout)
+	return &MockProjectCommandRunner_StateList_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockProjectCommandRunner_StateList_OngoingVerification struct {
+	mock              *MockProjectCommandRunner
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockProjectCommandRunner_StateList_OngoingVerification) GetCapturedArguments() command.ProjectContext {
+	ctx := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1]
+}
+
+func (c *MockProjectCommandRunner_StateList_OngoingVerification) GetAllCapturedArguments() (_param0 []command.ProjectContext) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]command.ProjectContext, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(command.ProjectContext)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockProjectCommandRunner) StateRm(ctx command.ProjectContext) *MockProjectCommandRunner_StateRm_OngoingVerification {
 	_params := []pegomock.Param{ctx}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "StateRm", _params, verifier.timeout)
@@ -341,6 +488,35 @@ func (c *MockProjectCommandRunner_StateRm_OngoingVerification) GetAllCapturedArg
 	return
 }
 
+func (verifier *VerifierMockProjectCommandRunner) StateShow(ctx command.ProjectContext) *MockProjectCommandRunner_StateShow_OngoingVerification {
+	_params := []pegomock.Param{ctx}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "StateShow", _params, verifier.timeout)
+	return &MockProjectCommandRunner_StateShow_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockProjectCommandRunner_StateShow_OngoingVerification struct {
+	mock              *MockProjectCommandRunner
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockProjectCommandRunner_StateShow_OngoingVerification) GetCapturedArguments() command.ProjectContext {
+	ctx := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1]
+}
+
+func (c *MockProjectCommandRunner_StateShow_OngoingVerification) GetAllCapturedArguments() (_param0 []command.ProjectContext) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]command.ProjectContext, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(command.ProjectContext)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockProjectCommandRunner) Version(ctx command.ProjectContext) *MockProjectCommandRunner_Version_OngoingVerification {
 	_params := []pegomock.Param{ctx}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Version", _params, verifier.timeout)
@@ -6,6 +6,7 @@ package mocks
 import (
 	pegomock "github.com/petergtz/pegomock/v4"
 	events "github.com/runatlantis/atlantis/server/events"
+	command "github.com/runatlantis/atlantis/server/events/command"
 	models "github.com/runatlantis/atlantis/server/events/models"
 	logging "github.com/runatlantis/atlantis/server/logging"
 	"reflect"
@@ -27,11 +28,11 @@ func NewMockProjectLocker(options ...pegomock.Option) *MockProjectLocker {
 func (mock *MockProjectLocker) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
 func (mock *MockProjectLocker) FailHandler() pegomock.FailHandler      { return mock.fail }
 
-func (mock *MockProjectLocker) TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, repoLocking bool) (*events.TryLockResponse, error) {
+func (mock *MockProjectLocker) TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, repoLocking bool, cmdName command.Name) (*events.TryLockResponse, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockProjectLocker().")
 	}
-	_params := []pegomock.Param{log, pull, user, workspace, project, repoLocking}
+	_params := []pegomock.Param{log, pull, user, workspace, project, repoLocking, cmdName}
 	_result := pegomock.GetGenericMockFrom(mock).Invoke("TryLock", _params, []reflect.Type{reflect.TypeOf((**events.TryLockResponse)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
 	var _ret0 *events.TryLockResponse
 	var _ret1 error
@@ -46,6 +47,25 @@ func (mock *MockProjectLocker) TryLock(log logging.SimpleLogging, pull models.Pu
 	return _ret0, _ret1
 }
 
+func (mock *MockProjectLocker) TryLockKeys(log logging.SimpleLogging, pull models.PullRequest, user models.User, project models.Project, keys []string, cmdName command.Name) (*events.TryLockResponse, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockProjectLocker().")
+	}
+	_params := []pegomock.Param{log, pull, user, project, keys, cmdName}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("TryLockKeys", _params, []reflect.Type{reflect.TypeOf((**events.TryLockResponse)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 *events.TryLockResponse
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(*events.TryLockResponse)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
 func (mock *MockProjectLocker) VerifyWasCalledOnce() *VerifierMockProjectLocker {
 	return &VerifierMockProjectLocker{
 		mock:                   mock,
@@ -83,8 +103,8 @@ type VerifierMockProjectLocker struct {
 	timeout                time.Duration
 }
 
-func (verifier *VerifierMockProjectLocker) TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, repoLocking bool) *MockProjectLocker_TryLock_OngoingVerification {
-	_params := []pegomock.Param{log, pull, user, workspace, project, repoLocking}
+func (verifier *VerifierMockProjectLocker) TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, repoLocking bool, cmdName command.Name) *MockProjectLocker_TryLock_OngoingVerification {
+	_params := []pegomock.Param{log, pull, user, workspace, project, repoLocking, cmdName}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "TryLock", _params, verifier.timeout)
 	return &MockProjectLocker_TryLock_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -94,12 +114,12 @@ type MockProjectLocker_TryLock_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *MockProjectLocker_TryLock_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, models.PullRequest, models.User, string, models.Project, bool) {
-	log, pull, user, workspace, project, repoLocking := c.GetAllCapturedArguments()
-	return log[len(log)-1], pull[len(pull)-1], user[len(user)-1], workspace[len(workspace)-1], project[len(project)-1], repoLocking[len(repoLocking)-1]
+func (c *MockProjectLocker_TryLock_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, models.PullRequest, models.User, string, models.Project, bool, command.Name) {
+	log, pull, user, workspace, project, repoLocking, cmdName := c.GetAllCapturedArguments()
+	return log[len(log)-1], pull[len(pull)-1], user[len(user)-1], workspace[len(workspace)-1], project[len(project)-1], repoLocking[len(repoLocking)-1], cmdName[len(cmdName)-1]
 }
 
-func (c *MockProjectLocker_TryLock_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []models.PullRequest, _param2 []models.User, _param3 []string, _param4 []models.Project, _param5 []bool) {
+func (c *MockProjectLocker_TryLock_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []models.PullRequest, _param2 []models.User, _param3 []string, _param4 []models.Project, _param5 []bool, _param6 []command.Name) {
 	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(_params) > 0 {
 		if len(_params) > 0 {
@@ -138,6 +158,71 @@ func (c *MockProjectLocker_TryLock_OngoingVerification) GetAllCapturedArguments(
 				_param5[u] = param.(bool)
 			}
 		}
+		if len(_params) > 6 {
+			_param6 = make([]command.Name, len(c.methodInvocations))
+			for u, param := range _params[6] {
+				_param6[u] = param.(command.Name)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockProjectLocker) TryLockKeys(log logging.SimpleLogging, pull models.PullRequest, user models.User, project models.Project, keys []string, cmdName command.Name) *MockProjectLocker_TryLockKeys_OngoingVerification {
+	_params := []pegomock.Param{log, pull, user, project, keys, cmdName}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "TryLockKeys", _params, verifier.timeout)
+	return &MockProjectLocker_TryLockKeys_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockProjectLocker_TryLockKeys_OngoingVerification struct {
+	mock              *MockProjectLocker
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockProjectLocker_TryLockKeys_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, models.PullRequest, models.User, models.Project, []string, command.Name) {
+	log, pull, user, project, keys, cmdName := c.GetAllCapturedArguments()
+	return log[len(log)-1], pull[len(pull)-1], user[len(user)-1], project[len(project)-1], keys[len(keys)-1], cmdName[len(cmdName)-1]
+}
+
+func (c *MockProjectLocker_TryLockKeys_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []models.PullRequest, _param2 []models.User, _param3 []models.Project, _param4 [][]string, _param5 []command.Name) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]logging.SimpleLogging, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(logging.SimpleLogging)
+			}
+		}
+		if len(_params) > 1 {
+			_param1 = make([]models.PullRequest, len(c.methodInvocations))
+			for u, param := range _params[1] {
+				_param1[u] = param.(models.PullRequest)
+			}
+		}
+		if len(_params) > 2 {
+			_param2 = make([]models.User, len(c.methodInvocations))
+			for u, param := range _params[2] {
+				_param2[u] = param.(models.User)
+			}
+		}
+		if len(_params) > 3 {
+			_param3 = make([]models.Project, len(c.methodInvocations))
+			for u, param := range _params[3] {
+				_param3[u] = param.(models.Project)
+			}
+		}
+		if len(_params) > 4 {
+			_param4 = make([][]string, len(c.methodInvocations))
+			for u, param := range _params[4] {
+				_param4[u] = param.([]string)
+			}
+		}
+		if len(_params) > 5 {
+			_param5 = make([]command.Name, len(c.methodInvocations))
+			for u, param := range _params[5] {
+				_param5[u] = param.(command.Name)
+			}
+		}
 	}
 	return
 }
@@ -62,6 +62,36 @@ func (mock *MockWorkingDirLocker) TryLockPull(repoFullName string, pullNum int)
 	return _ret0, _ret1
 }
 
+func (mock *MockWorkingDirLocker) LockWorkspace(repoFullName string, pullNum int, workspace string) func() {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockWorkingDirLocker().")
+	}
+	_params := []pegomock.Param{repoFullName, pullNum, workspace}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("LockWorkspace", _params, []reflect.Type{reflect.TypeOf((*func())(nil)).Elem()})
+	var _ret0 func()
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(func())
+		}
+	}
+	return _ret0
+}
+
+func (mock *MockWorkingDirLocker) RLockWorkspace(repoFullName string, pullNum int, workspace string) func() {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockWorkingDirLocker().")
+	}
+	_params := []pegomock.Param{repoFullName, pullNum, workspace}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("RLockWorkspace", _params, []reflect.Type{reflect.TypeOf((*func())(nil)).Elem()})
+	var _ret0 func()
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(func())
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockWorkingDirLocker) VerifyWasCalledOnce() *VerifierMockWorkingDirLocker {
 	return &VerifierMockWorkingDirLocker{
 		mock:                   mock,
@@ -180,3 +210,85 @@ func (c *MockWorkingDirLocker_TryLockPull_OngoingVerification) GetAllCapturedArg
 	}
 	return
 }
+
+func (verifier *VerifierMockWorkingDirLocker) LockWorkspace(repoFullName string, pullNum int, workspace string) *MockWorkingDirLocker_LockWorkspace_OngoingVerification {
+	_params := []pegomock.Param{repoFullName, pullNum, workspace}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "LockWorkspace", _params, verifier.timeout)
+	return &MockWorkingDirLocker_LockWorkspace_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockWorkingDirLocker_LockWorkspace_OngoingVerification struct {
+	mock              *MockWorkingDirLocker
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockWorkingDirLocker_LockWorkspace_OngoingVerification) GetCapturedArguments() (string, int, string) {
+	repoFullName, pullNum, workspace := c.GetAllCapturedArguments()
+	return repoFullName[len(repoFullName)-1], pullNum[len(pullNum)-1], workspace[len(workspace)-1]
+}
+
+func (c *MockWorkingDirLocker_LockWorkspace_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []int, _param2 []string) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(string)
+			}
+		}
+		if len(_params) > 1 {
+			_param1 = make([]int, len(c.methodInvocations))
+			for u, param := range _params[1] {
+				_param1[u] = param.(int)
+			}
+		}
+		if len(_params) > 2 {
+			_param2 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[2] {
+				_param2[u] = param.(string)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockWorkingDirLocker) RLockWorkspace(repoFullName string, pullNum int, workspace string) *MockWorkingDirLocker_RLockWorkspace_OngoingVerification {
+	_params := []pegomock.Param{repoFullName, pullNum, workspace}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RLockWorkspace", _params, verifier.timeout)
+	return &MockWorkingDirLocker_RLockWorkspace_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockWorkingDirLocker_RLockWorkspace_OngoingVerification struct {
+	mock              *MockWorkingDirLocker
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockWorkingDirLocker_RLockWorkspace_OngoingVerification) GetCapturedArguments() (string, int, string) {
+	repoFullName, pullNum, workspace := c.GetAllCapturedArguments()
+	return repoFullName[len(repoFullName)-1], pullNum[len(pullNum)-1], workspace[len(workspace)-1]
+}
+
+func (c *MockWorkingDirLocker_RLockWorkspace_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []int, _param2 []string) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(string)
+			}
+		}
+		if len(_params) > 1 {
+			_param1 = make([]int, len(c.methodInvocations))
+			for u, param := range _params[1] {
+				_param1[u] = param.(int)
+			}
+		}
+		if len(_params) > 2 {
+			_param2 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[2] {
+				_param2[u] = param.(string)
+			}
+		}
+	}
+	return
+}
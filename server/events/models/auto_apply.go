@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AutoApply is a low-risk plan waiting to be applied automatically.
+type AutoApply struct {
+	Repository  string
+	PullNum     int
+	RepoRelDir  string
+	Workspace   string
+	ProjectName string `json:",omitempty"`
+	// Rules are the names of the auto-approval rules the plan matched.
+	Rules []string
+	// ApplyAt is when the plan is applied.
+	ApplyAt time.Time
+	// Pull, HeadRepo and User are the pull request that was planned and the
+	// user who planned it, who the apply runs as.
+	Pull     PullRequest
+	HeadRepo Repo
+	User     User
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// CanaryRun is the canary of a dir applied in a pull request. It's tracked
+// until the other projects of the dir are promoted, ie. applied after it.
+type CanaryRun struct {
+	Repository string
+	PullNum    int
+	RepoRelDir string
+	// ProjectName and Workspace identify the canary project.
+	ProjectName string `json:",omitempty"`
+	Workspace   string
+	// AppliedAt is when the canary was last applied.
+	AppliedAt time.Time
+	// SoakTime is how long after AppliedAt the other projects are applied
+	// automatically. If zero, they wait until the canary is promoted with a
+	// comment.
+	SoakTime time.Duration
+	// Promoted is true once the other projects may be applied.
+	Promoted bool
+	// Pull, HeadRepo and User are the pull request and the user who applied
+	// the canary, used to apply the other projects once SoakTime elapses.
+	Pull     PullRequest
+	HeadRepo Repo
+	User     User
+	// CommentID is the comment tracking the progress of the dir's projects.
+	// It's 0 if the VCS host can't update comments.
+	CommentID int64 `json:",omitempty"`
+}
+
+// PromoteAt returns when the other projects are applied automatically or
+// the zero time if they wait for a comment.
+func (c CanaryRun) PromoteAt() time.Time {
+	if c.SoakTime == 0 {
+		return time.Time{}
+	}
+	return c.AppliedAt.Add(c.SoakTime)
+}
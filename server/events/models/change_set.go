@@ -0,0 +1,37 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChangeSet groups pull requests, usually of different repos, whose changes
+// must be rolled out together. Their plans are run together and they're
+// applied in order once all of them can be applied.
+type ChangeSet struct {
+	Name string
+	// Members are the pull requests of the change set in the order they're
+	// applied.
+	Members   []ChangeSetMember
+	CreatedAt time.Time
+}
+
+// ChangeSetMember is a pull request of a change set.
+type ChangeSetMember struct {
+	// Repository is the full name of the repo, ex. owner/repo.
+	Repository string
+	// Type is the VCS host of the repo, ex. Github. See NewVCSHostType.
+	Type    string
+	PullNum int
+}
+
+// String returns the member as repo#pull, ex. owner/repo#1.
+func (m ChangeSetMember) String() string {
+	return fmt.Sprintf("%s#%d", m.Repository, m.PullNum)
+}
+
+// Is returns true if the member is the pull request pullNum of the repo
+// repoFullName.
+func (m ChangeSetMember) Is(repoFullName string, pullNum int) bool {
+	return m.Repository == repoFullName && m.PullNum == pullNum
+}
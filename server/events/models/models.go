@@ -149,6 +149,21 @@ type ApprovalStatus struct {
 	IsApproved bool
 	ApprovedBy string
 	Date       time.Time
+	// Approvers are all the users who approved the pull request. It's only
+	// set by VCS clients that list them, otherwise ApprovedBy is the only
+	// known approver.
+	Approvers []string
+}
+
+// ApprovalCount returns the number of approvals of the pull request.
+func (a ApprovalStatus) ApprovalCount() int {
+	if len(a.Approvers) > 0 {
+		return len(a.Approvers)
+	}
+	if a.IsApproved {
+		return 1
+	}
+	return 0
 }
 
 // PullRequest is a VCS pull request.
@@ -172,12 +187,18 @@ type PullRequest struct {
 	BaseBranch string
 	// Author is the username of the pull request author.
 	Author string
+	// Title is the title of the pull request.
+	Title string
 	// State will be one of Open or Closed.
 	// Gitlab supports an additional "merged" state but Github doesn't so we map
 	// merged to Closed.
 	State PullRequestState
 	// BaseRepo is the repository that the pull request will be merged into.
 	BaseRepo Repo
+	// IsIssue is true if this models an issue that a command was run from in
+	// issue-ops mode rather than an actual pull request. Such pulls have no
+	// head commit and their head and base branches are the default branch.
+	IsIssue bool
 }
 
 // PullRequestOptions is used to set optional paralmeters for PullRequest
@@ -242,6 +263,9 @@ type ProjectLock struct {
 	Workspace string
 	// Time is the time at which the lock was first created.
 	Time time.Time
+	// Command is the command that created the lock, ex. plan. It's empty
+	// for locks created before it was recorded.
+	Command string
 }
 
 // Project represents a Terraform project. Since there may be multiple
@@ -378,6 +402,62 @@ type PlanSuccess struct {
 	// branch we're merging into had been updated, and we had to merge again
 	// before planning
 	MergedAgain bool
+	// Targets are the resource addresses the plan was limited to. If set then
+	// applying the plan is a partial apply.
+	Targets []string
+	// ProtectedDestroys are the addresses of the resources protected by the
+	// destroy guard that the plan deletes or replaces. If set then applying
+	// the plan must be confirmed with --confirm-destroy.
+	ProtectedDestroys []string
+	// Risk is the risk score of the plan. It's nil if the repo doesn't score
+	// plans.
+	Risk *PlanRisk
+	// AutoApproval is set if the plan is low risk so it can be applied
+	// without approval.
+	AutoApproval *PlanAutoApproval
+	// Delta is how the plan differs from the previous plan of the project in
+	// the same pull request. It's nil if the project wasn't planned before.
+	Delta *PlanDelta
+}
+
+// PlanRisk is the risk score of a plan and the approvals required to apply
+// it.
+type PlanRisk struct {
+	Score int
+	// Level is the name of the highest threshold the score reaches.
+	Level string
+	// Approvals is the number of approvals required to apply the plan.
+	Approvals int
+	// Reviewers are the users and teams one of the approvals must come from.
+	Reviewers []string
+}
+
+// PlanAutoApproval is how a low-risk plan is auto-approved.
+type PlanAutoApproval struct {
+	// Rules are the names of the auto-approval rules the plan's changes
+	// matched.
+	Rules []string
+	// ApplyAt is when the plan is applied automatically. It's zero if it's
+	// only applied with a comment.
+	ApplyAt time.Time
+}
+
+// PlanDelta is how a plan differs from the previous plan of the same project.
+type PlanDelta struct {
+	// PreviousCommit is the commit the previous plan was made on.
+	PreviousCommit string
+	// Added are the resource changes the previous plan didn't make.
+	Added []PlannedResourceChange
+	// Removed are the resource changes of the previous plan that the plan
+	// doesn't make anymore.
+	Removed []PlannedResourceChange
+}
+
+// PlannedResourceChange is a change a plan makes to a resource.
+type PlannedResourceChange struct {
+	Address string
+	// Action is create, update, delete or replace.
+	Action string
 }
 
 type PolicySetResult struct {
@@ -400,8 +480,26 @@ var (
 	reChangesOutside = regexp.MustCompile(`Note: Objects have changed outside of Terraform`)
 	rePlanChanges    = regexp.MustCompile(`Plan: (?:(\d+) to import, )?(\d+) to add, (\d+) to change, (\d+) to destroy.`)
 	reNoChanges      = regexp.MustCompile(`No changes. (Infrastructure is up-to-date|Your infrastructure matches the configuration).`)
+	// reAnsibleRecap matches the PLAY RECAP line of a host in the output of
+	// ansible-playbook.
+	reAnsibleRecap = regexp.MustCompile(`(?m)^\S+\s+:\s+ok=\d+\s+changed=(\d+)\s+unreachable=(\d+)\s+failed=(\d+)`)
 )
 
+// ansibleRecap sums up the PLAY RECAP of ansible-playbook output. ok is
+// false if the output doesn't have one.
+func ansibleRecap(output string) (hosts int, changed int, unreachable int, failed int, ok bool) {
+	for _, match := range reAnsibleRecap.FindAllStringSubmatch(output, -1) {
+		hosts++
+		c, _ := strconv.Atoi(match[1])
+		u, _ := strconv.Atoi(match[2])
+		f, _ := strconv.Atoi(match[3])
+		changed += c
+		unreachable += u
+		failed += f
+	}
+	return hosts, changed, unreachable, failed, hosts > 0
+}
+
 // Summary extracts summaries of plan changes from TerraformOutput.
 func (p *PlanSuccess) Summary() string {
 	note := ""
@@ -413,15 +511,46 @@ func (p *PlanSuccess) Summary() string {
 
 // DiffSummary extracts one line summary of plan changes from TerraformOutput.
 func (p *PlanSuccess) DiffSummary() string {
+	if matches := rePlanChanges.FindAllStringSubmatch(p.TerraformOutput, -1); len(matches) > 1 {
+		return sumPlanChanges(matches)
+	}
 	if match := rePlanChanges.FindString(p.TerraformOutput); match != "" {
 		return match
 	}
-	return reNoChanges.FindString(p.TerraformOutput)
+	if match := reNoChanges.FindString(p.TerraformOutput); match != "" {
+		return match
+	}
+	if hosts, changed, unreachable, failed, ok := ansibleRecap(p.TerraformOutput); ok {
+		return fmt.Sprintf("Check: %d hosts, %d changed, %d unreachable, %d failed.", hosts, changed, unreachable, failed)
+	}
+	return ""
+}
+
+// sumPlanChanges sums up the changes of several plans, ex. of the components
+// of a Terraform stack deployment.
+func sumPlanChanges(matches [][]string) string {
+	var sums [4]int
+	for _, match := range matches {
+		for i, count := range match[1:] {
+			n, _ := strconv.Atoi(count)
+			sums[i] += n
+		}
+	}
+	imports := ""
+	if sums[0] > 0 {
+		imports = fmt.Sprintf("%d to import, ", sums[0])
+	}
+	return fmt.Sprintf("Plan: %s%d to add, %d to change, %d to destroy across %d plans.", imports, sums[1], sums[2], sums[3], len(matches))
 }
 
-// NoChanges returns true if the plan has no changes.
+// NoChanges returns true if the plan has no changes. Ansible check runs have
+// no changes if no task changed or failed on any host.
 func (p *PlanSuccess) NoChanges() bool {
-	return reNoChanges.MatchString(p.TerraformOutput)
+	if reNoChanges.MatchString(p.TerraformOutput) {
+		return true
+	}
+	_, changed, unreachable, failed, ok := ansibleRecap(p.TerraformOutput)
+	return ok && changed == 0 && unreachable == 0 && failed == 0
 }
 
 // Diff Markdown regexes
@@ -471,6 +600,14 @@ type ImportSuccess struct {
 	Output string
 	// RePlanCmd is the command that users should run to re-plan this project.
 	RePlanCmd string
+	// Plan is the plan of the project after the import if it was planned
+	// again.
+	Plan *PlanSuccess
+	// PlanFailure is why planning the project again after the import failed.
+	PlanFailure string
+	// UnmatchedImports are the imported addresses the plan still creates,
+	// ex. because the address doesn't match the configuration.
+	UnmatchedImports []string
 }
 
 // StateRmSuccess is the result of a successful state rm run.
@@ -562,6 +699,9 @@ type ProjectStatus struct {
 	PolicyStatus []PolicySetStatus
 	// Status is the status of where this project is at in the planning cycle.
 	Status ProjectPlanStatus
+	// Labels are the labels of the project when it was last planned or
+	// applied, ex. tier: prod.
+	Labels map[string]string
 }
 
 // ProjectPlanStatus is the status of where this project is at in the planning
@@ -15,6 +15,7 @@ package models_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -432,6 +433,14 @@ func TestPlanSuccess_DiffSummary(t *testing.T) {
 			"dummy\nNo changes. Your infrastructure matches the configuration.",
 			"No changes. Your infrastructure matches the configuration.",
 		},
+		{
+			ansibleCheckOutput,
+			"Check: 2 hosts, 1 changed, 0 unreachable, 0 failed.",
+		},
+		{
+			"component.vpc\nPlan: 2 to add, 0 to change, 0 to destroy.\ncomponent.db\nPlan: 1 to import, 1 to add, 3 to change, 1 to destroy.",
+			"Plan: 1 to import, 3 to add, 3 to change, 1 to destroy across 2 plans.",
+		},
 	}
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("summary %d", i), func(t *testing.T) {
@@ -443,6 +452,42 @@ func TestPlanSuccess_DiffSummary(t *testing.T) {
 	}
 }
 
+const ansibleCheckOutput = `PLAY [webservers] **************************************************************
+
+TASK [Configure nginx] *********************************************************
+--- before: /etc/nginx/nginx.conf
++++ after: /etc/nginx/nginx.conf
+@@ -1 +1 @@
+-worker_processes 2;
++worker_processes 4;
+changed: [web1]
+ok: [web2]
+
+PLAY RECAP *********************************************************************
+web1                       : ok=2    changed=1    unreachable=0    failed=0    skipped=0    rescued=0    ignored=0
+web2                       : ok=2    changed=0    unreachable=0    failed=0    skipped=0    rescued=0    ignored=0
+`
+
+func TestPlanSuccess_NoChanges(t *testing.T) {
+	cases := []struct {
+		description string
+		input       string
+		exp         bool
+	}{
+		{"terraform no changes", "dummy\nNo changes. Your infrastructure matches the configuration.", true},
+		{"terraform changes", "dummy\nPlan: 1 to add, 0 to change, 0 to destroy.", false},
+		{"ansible changes", ansibleCheckOutput, false},
+		{"ansible no changes", strings.ReplaceAll(ansibleCheckOutput, "changed=1", "changed=0"), true},
+		{"unknown output", "dummy", false},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			pcs := models.PlanSuccess{TerraformOutput: c.input}
+			Equals(t, c.exp, pcs.NoChanges())
+		})
+	}
+}
+
 func TestPolicyCheckResults_Summary(t *testing.T) {
 	cases := []struct {
 		description      string
@@ -726,3 +771,11 @@ func TestPlanSuccessStats(t *testing.T) {
 		})
 	}
 }
+
+func TestSilencedProject_Matches(t *testing.T) {
+	Equals(t, true, models.SilencedProject{ProjectName: "app"}.Matches("app", "app", "default"))
+	Equals(t, false, models.SilencedProject{ProjectName: "app"}.Matches("db", "app", "default"))
+	Equals(t, true, models.SilencedProject{RepoRelDir: "app"}.Matches("", "app", "staging"))
+	Equals(t, false, models.SilencedProject{RepoRelDir: "app", Workspace: "default"}.Matches("", "app", "staging"))
+	Equals(t, true, models.SilencedProject{Workspace: "staging"}.Matches("", "db", "staging"))
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PlanJSON is the machine-readable plan of a project, the output of
+// terraform show -json on its planfile.
+type PlanJSON struct {
+	RepoFullName string
+	PullNum      int
+	// HeadCommit is the commit that was planned. If it isn't the head of the
+	// pull request anymore, the plan is stale.
+	HeadCommit  string
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	// Labels are the labels of the project, ex. tier: prod.
+	Labels    map[string]string
+	PlannedAt time.Time
+	Plan      json.RawMessage
+}
+
+// PlannedChanges are the resource changes of the latest plan of a project,
+// kept to show what changed when the project is planned again.
+type PlannedChanges struct {
+	RepoFullName string
+	PullNum      int
+	// HeadCommit is the commit that was planned.
+	HeadCommit  string
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	Changes     []PlannedResourceChange
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ApplyProvenance records what an apply of a project applied so audits can
+// verify it later.
+type ApplyProvenance struct {
+	// JobID is the ID of the apply job.
+	JobID        string
+	RepoFullName string
+	PullNum      int
+	HeadCommit   string
+	ProjectName  string
+	RepoRelDir   string
+	Workspace    string
+	// User is the username of who ran the apply.
+	User      string
+	AppliedAt time.Time
+	// PlanSHA256 is the hex encoded SHA-256 hash of the applied planfile. It's
+	// empty if the project's tool doesn't have planfiles.
+	PlanSHA256 string
+	// JobURL links to the output of the apply.
+	JobURL string
+	// PolicyResults are the policy sets the plan was checked against.
+	PolicyResults []PolicySetStatus
+}
+
+// Provenance is the record of the applies of a pull request that was merged
+// after they succeeded.
+type Provenance struct {
+	RepoFullName string
+	PullNum      int
+	HeadCommit   string
+	// MergeCommit is the commit the pull request was merged as. It's empty if
+	// the VCS host doesn't report it.
+	MergeCommit string
+	MergedAt    time.Time
+	// MergedBy is the username of who ran the apply that merged the pull
+	// request.
+	MergedBy string
+	// Applies are the latest applies of each project at HeadCommit.
+	Applies []ApplyProvenance
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// SBOM is the software bill of materials of an apply. It records the exact
+// tool, providers and modules that were used to change infrastructure so
+// they can be audited later.
+type SBOM struct {
+	// JobID is the ID of the apply job the SBOM was generated for.
+	JobID        string
+	RepoFullName string
+	PullNum      int
+	HeadCommit   string
+	ProjectName  string
+	RepoRelDir   string
+	Workspace    string
+	// User is the username of who ran the apply.
+	User      string
+	AppliedAt time.Time
+	Tool      SBOMTool
+	Providers []SBOMProvider
+	Modules   []SBOMModule
+}
+
+// SBOMTool is the Terraform distribution that ran the apply.
+type SBOMTool struct {
+	// Name is the name of the binary, terraform or tofu.
+	Name    string
+	Version string
+}
+
+// SBOMProvider is a provider locked in .terraform.lock.hcl.
+type SBOMProvider struct {
+	// Source is the fully qualified source address, ex.
+	// registry.terraform.io/hashicorp/aws.
+	Source      string
+	Version     string
+	Constraints string
+	Hashes      []string
+}
+
+// SBOMModule is a module installed by terraform init.
+type SBOMModule struct {
+	// Key is the module's path in the config, ex. vpc.subnets.
+	Key string
+	// Source is the module source, including the ref for VCS sources.
+	Source string
+	// Version is the resolved version of registry modules.
+	Version string
+}
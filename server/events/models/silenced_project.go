@@ -0,0 +1,22 @@
+package models
+
+// SilencedProject is a project whose results aren't commented on a pull
+// request since it was silenced with the silence command. Projects are
+// identified by name or, if ProjectName is empty, by dir and workspace,
+// either of which may be empty to match any.
+type SilencedProject struct {
+	Repository  string
+	PullNum     int
+	ProjectName string `json:",omitempty"`
+	RepoRelDir  string `json:",omitempty"`
+	Workspace   string `json:",omitempty"`
+}
+
+// Matches returns true if the project named projectName in dir repoRelDir
+// and workspace workspace is silenced by s.
+func (s SilencedProject) Matches(projectName string, repoRelDir string, workspace string) bool {
+	if s.ProjectName != "" {
+		return s.ProjectName == projectName
+	}
+	return (s.RepoRelDir == "" || s.RepoRelDir == repoRelDir) && (s.Workspace == "" || s.Workspace == workspace)
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StateSnapshot is the state of a project recorded after a successful apply
+// so the apply can be rolled back.
+type StateSnapshot struct {
+	RepoFullName string
+	PullNum      int
+	// Commit is the commit whose configuration was applied. For applies of
+	// rollback plans it's the commit that was rolled back to.
+	Commit      string
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	User        string
+	AppliedAt   time.Time
+	// Serial and Lineage identify the state. Terraform increments the serial
+	// each time the state changes.
+	Serial  int
+	Lineage string
+	State   json.RawMessage
+	// Plan is the planfile that was applied.
+	Plan []byte
+}
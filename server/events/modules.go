@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/hashicorp/terraform-config-inspect/tfconfig"
@@ -118,27 +119,41 @@ func (m moduleInfo) load(files fs.FS, dir string, projects ...string) (_ *module
 }
 
 // FindModuleProjects returns a mapping of modules to projects that depend on them.
-func FindModuleProjects(absRepoDir string, autoplanModuleDependants string) (ModuleProjects, error) {
-	return findModuleDependants(os.DirFS(absRepoDir), autoplanModuleDependants)
+// Directories matching sharedModuleDirs are never considered projects.
+func FindModuleProjects(absRepoDir string, autoplanModuleDependants string, sharedModuleDirs []string) (ModuleProjects, error) {
+	if autoplanModuleDependants == "" {
+		return moduleInfo{}, nil
+	}
+	links, err := findRepoSymlinks(absRepoDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve symlinks: %w", err)
+	}
+	return findModuleDependants(os.DirFS(absRepoDir), autoplanModuleDependants, sharedModuleDirs, links.paths()...)
 }
 
-func findModuleDependants(files fs.FS, autoplanModuleDependants string) (ModuleProjects, error) {
+// findModuleDependants indexes the projects matching autoplanModuleDependants
+// by the modules they depend on. Symlinked directories aren't followed while
+// walking files, so the symlinks listed in linkDirs are walked separately and
+// the projects found through them are indexed under the symlinked path.
+func findModuleDependants(files fs.FS, autoplanModuleDependants string, sharedModuleDirs []string, linkDirs ...string) (ModuleProjects, error) {
 	if autoplanModuleDependants == "" {
 		return moduleInfo{}, nil
 	}
 	// find all the projects matching autoplanModuleDependants
 	filter, _ := patternmatcher.New(strings.Split(autoplanModuleDependants, ","))
 	var projects []string
-	err := fs.WalkDir(files, ".", func(rel string, info fs.DirEntry, err error) error {
-		if match, _ := filter.MatchesOrParentMatches(rel); match {
-			if projectDir := getProjectDirFromFs(files, rel); projectDir != "" {
-				projects = append(projects, projectDir)
+	for _, root := range append([]string{"."}, linkDirs...) {
+		err := fs.WalkDir(files, root, func(rel string, info fs.DirEntry, err error) error {
+			if match, _ := filter.MatchesOrParentMatches(rel); match {
+				if projectDir := getProjectDirFromFs(files, rel, sharedModuleDirs); projectDir != "" && !slices.Contains(projects, projectDir) {
+					projects = append(projects, projectDir)
+				}
 			}
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("find projects for module dependants: %w", err)
 		}
-		return err
-	})
-	if err != nil {
-		return nil, fmt.Errorf("find projects for module dependants: %w", err)
 	}
 
 	result := make(moduleInfo)
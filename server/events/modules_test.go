@@ -4,6 +4,8 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 
@@ -58,7 +60,7 @@ func Test_findModuleDependants(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := findModuleDependants(tt.args.files, tt.args.autoplanModuleDependants)
+			got, err := findModuleDependants(tt.args.files, tt.args.autoplanModuleDependants, nil)
 			if !tt.wantErr(t, err, fmt.Sprintf("findModuleDependants(%v, %v)", tt.args.files, tt.args.autoplanModuleDependants)) {
 				return
 			}
@@ -70,3 +72,40 @@ func Test_findModuleDependants(t *testing.T) {
 		})
 	}
 }
+
+func TestFindModuleProjects_SymlinksAndSharedModuleDirs(t *testing.T) {
+	// modules/
+	//   vpc/
+	//     main.tf
+	// stacks/
+	//   app/
+	//     main.tf # uses ../../modules/vpc
+	// envs/
+	//   prod -> ../stacks/app
+	// vendor/
+	//   vpc/
+	//     main.tf
+	// live/
+	//   main.tf # uses ../vendor/vpc
+	repoDir := t.TempDir()
+	files := map[string]string{
+		"modules/vpc/main.tf": "",
+		"stacks/app/main.tf":  `module "vpc" { source = "../../modules/vpc" }`,
+		"vendor/vpc/main.tf":  "",
+		"live/main.tf":        `module "vpc" { source = "../vendor/vpc" }`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, filepath.Dir(name)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0600))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "envs"), 0700))
+	require.NoError(t, os.Symlink(filepath.Join("..", "stacks", "app"), filepath.Join(repoDir, "envs", "prod")))
+
+	got, err := FindModuleProjects(repoDir, "**/*.tf", []string{"vendor/**"})
+	require.NoError(t, err)
+
+	projects := got.DependentProjects("modules/vpc")
+	sort.Strings(projects)
+	assert.Equal(t, []string{"envs/prod", "stacks/app"}, projects)
+	assert.Equal(t, []string{"live"}, got.DependentProjects("vendor/vpc"))
+}
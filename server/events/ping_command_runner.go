@@ -0,0 +1,58 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+)
+
+func NewPingCommandRunner(
+	vcsClient vcs.Client,
+	commitStatusUpdater CommitStatusUpdater,
+	atlantisVersion string,
+) *PingCommandRunner {
+	return &PingCommandRunner{
+		vcsClient:           vcsClient,
+		commitStatusUpdater: commitStatusUpdater,
+		atlantisVersion:     atlantisVersion,
+	}
+}
+
+// PingCommandRunner answers ping commands so operators can check that
+// webhooks are received and that Atlantis can comment and set commit statuses
+// on a repo. It doesn't clone the repo or run Terraform.
+type PingCommandRunner struct {
+	vcsClient           vcs.Client
+	commitStatusUpdater CommitStatusUpdater
+	atlantisVersion     string
+}
+
+func (p *PingCommandRunner) Run(ctx *command.Context, _ *CommentCommand) {
+	if err := p.commitStatusUpdater.UpdateCombined(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, models.PendingCommitStatus, command.Ping); err != nil {
+		ctx.Log.Warn("unable to update ping commit status: %s", err)
+	}
+
+	status := models.SuccessCommitStatus
+	if err := p.vcsClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, p.pongComment(ctx), command.Ping.String()); err != nil {
+		ctx.Log.Err("unable to comment: %s", err)
+		status = models.FailedCommitStatus
+	}
+
+	if err := p.commitStatusUpdater.UpdateCombined(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, status, command.Ping); err != nil {
+		ctx.Log.Warn("unable to update ping commit status: %s", err)
+	}
+}
+
+// pongComment describes the event Atlantis received.
+func (p *PingCommandRunner) pongComment(ctx *command.Context) string {
+	return fmt.Sprintf("**Pong** :ping_pong:\n\n"+
+		"Atlantis %s received the command of @%s on %s #%d at %s.\n\n"+
+		"* Head commit: `%s`\n"+
+		"* Base branch: `%s`\n\n"+
+		"Comments and commit statuses work on this repo. Terraform wasn't run.",
+		p.atlantisVersion, ctx.User.Username, ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, time.Now().UTC().Format(time.RFC3339),
+		ctx.Pull.HeadCommit, ctx.Pull.BaseBranch)
+}
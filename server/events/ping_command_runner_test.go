@@ -0,0 +1,66 @@
+package events_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/testdata"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPingCommandRunner_Run(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+
+	tests := []struct {
+		name       string
+		commentErr error
+		expStatus  models.CommitStatus
+	}{
+		{
+			name:      "comments and sets a successful status",
+			expStatus: models.SuccessCommitStatus,
+		},
+		{
+			name:       "sets a failed status if it can't comment",
+			commentErr: errors.New("forbidden"),
+			expStatus:  models.FailedCommitStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcsClient := vcsmocks.NewMockClient()
+			commitStatusUpdater := mocks.NewMockCommitStatusUpdater()
+			When(vcsClient.CreateComment(Any[logging.SimpleLogging](), Any[models.Repo](), Any[int](), Any[string](), Any[string]())).ThenReturn(tt.commentErr)
+
+			pull := models.PullRequest{BaseRepo: testdata.GithubRepo, State: models.OpenPullState, Num: testdata.Pull.Num, HeadCommit: "abc123", BaseBranch: "main"}
+			ctx := &command.Context{
+				User:     testdata.User,
+				Log:      logger,
+				Pull:     pull,
+				HeadRepo: testdata.GithubRepo,
+				Trigger:  command.CommentTrigger,
+			}
+			runner := events.NewPingCommandRunner(vcsClient, commitStatusUpdater, "1.2.3")
+			runner.Run(ctx, &events.CommentCommand{Name: command.Ping})
+
+			_, _, _, body, _ := vcsClient.VerifyWasCalledOnce().CreateComment(
+				Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(pull.Num), Any[string](), Eq("ping")).GetCapturedArguments()
+			for _, want := range []string{"**Pong**", "Atlantis 1.2.3", "@lkysow", "`abc123`", "Terraform wasn't run"} {
+				Assert(t, strings.Contains(body, want), "expected comment to contain %q, got %q", want, body)
+			}
+			commitStatusUpdater.VerifyWasCalledOnce().UpdateCombined(
+				Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(pull), Eq(models.PendingCommitStatus), Eq(command.Ping))
+			commitStatusUpdater.VerifyWasCalledOnce().UpdateCombined(
+				Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(pull), Eq(tt.expStatus), Eq(command.Ping))
+		})
+	}
+}
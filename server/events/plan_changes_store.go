@@ -0,0 +1,84 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// PlanChangesStore stores the resource changes of the latest plan of each
+// project of pull requests.
+type PlanChangesStore interface {
+	// Save stores changes, replacing the previous changes of the same project.
+	Save(changes models.PlannedChanges) error
+	// Get returns the changes of the latest plan of a project or nil if it
+	// wasn't planned.
+	Get(repoFullName string, pullNum int, repoRelDir string, workspace string, projectName string) (*models.PlannedChanges, error)
+	// DeleteForPull deletes the changes of a pull request.
+	DeleteForPull(repoFullName string, pullNum int) error
+}
+
+// FilePlanChangesStore stores planned changes as JSON files in Dir.
+type FilePlanChangesStore struct {
+	Dir string
+}
+
+func (f *FilePlanChangesStore) Save(changes models.PlannedChanges) error {
+	path, err := f.path(changes.RepoFullName, changes.PullNum, changes.RepoRelDir, changes.Workspace, changes.ProjectName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrap(err, "creating plan changes dir")
+	}
+	contents, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, contents, 0600)
+}
+
+func (f *FilePlanChangesStore) Get(repoFullName string, pullNum int, repoRelDir string, workspace string, projectName string) (*models.PlannedChanges, error) {
+	path, err := f.path(repoFullName, pullNum, repoRelDir, workspace, projectName)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var changes models.PlannedChanges
+	if err := json.Unmarshal(contents, &changes); err != nil {
+		return nil, errors.Wrapf(err, "parsing plan changes %s", path)
+	}
+	return &changes, nil
+}
+
+func (f *FilePlanChangesStore) DeleteForPull(repoFullName string, pullNum int) error {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(pullDir)
+}
+
+// path returns the file the changes of a project are stored in. Project names
+// and dirs can contain any character so the file is named after a hash of
+// what identifies the project.
+func (f *FilePlanChangesStore) path(repoFullName string, pullNum int, repoRelDir string, workspace string, projectName string) (string, error) {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return "", err
+	}
+	id := sha256.Sum256([]byte(strings.Join([]string{repoRelDir, workspace, projectName}, "\x00")))
+	return filepath.Join(pullDir, hex.EncodeToString(id[:])+".json"), nil
+}
@@ -0,0 +1,42 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFilePlanChangesStore(t *testing.T) {
+	store := &events.FilePlanChangesStore{Dir: t.TempDir()}
+	changes := models.PlannedChanges{
+		RepoFullName: "owner/repo",
+		PullNum:      1,
+		HeadCommit:   "abc123",
+		RepoRelDir:   "dir",
+		Workspace:    "default",
+		Changes:      []models.PlannedResourceChange{{Address: "aws_instance.web", Action: "update"}},
+	}
+	got, err := store.Get("owner/repo", 1, "dir", "default", "")
+	Ok(t, err)
+	Assert(t, got == nil, "expected no changes before the first plan")
+
+	Ok(t, store.Save(changes))
+	got, err = store.Get("owner/repo", 1, "dir", "default", "")
+	Ok(t, err)
+	Equals(t, &changes, got)
+
+	// Other projects of the pull request have their own changes.
+	got, err = store.Get("owner/repo", 1, "dir", "default", "project")
+	Ok(t, err)
+	Assert(t, got == nil, "expected no changes for another project")
+
+	Ok(t, store.DeleteForPull("owner/repo", 1))
+	got, err = store.Get("owner/repo", 1, "dir", "default", "")
+	Ok(t, err)
+	Assert(t, got == nil, "expected changes to be deleted")
+
+	_, err = store.Get("../repo", 1, "dir", "default", "")
+	ErrContains(t, "invalid repo name", err)
+}
@@ -1,6 +1,9 @@
 package events
 
 import (
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -77,6 +80,24 @@ type PlanCommandRunner struct {
 	DiscardApprovalOnPlan bool
 	pullReqStatusFetcher  vcs.PullReqStatusFetcher
 	SilencePRComments     []string
+	// PlanFreezer, if set, is checked for plan freezes before planning.
+	PlanFreezer locking.PlanFreezer
+	// Scheduler, if set, runs parallel plans by their dependencies and
+	// durations instead of in a plain pool.
+	Scheduler *PlanScheduler
+	// PlanFreezeMessage is commented when plans are frozen by a freeze
+	// without a message.
+	PlanFreezeMessage string
+	// PreviewEnvironmentDeployer applies the plans of preview environments
+	// once they're planned.
+	PreviewEnvironmentDeployer CommentCommandRunner
+	// GlobalCfgStore holds the server-side repo config, read for the autoplan
+	// triggers of each repo.
+	GlobalCfgStore *valid.GlobalCfgStore
+	// AutoApplyStore stores the low-risk plans applied automatically after
+	// the delay of their repo's auto-approval. If it's nil, plans aren't
+	// applied automatically.
+	AutoApplyStore AutoApplyStore
 }
 
 func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
@@ -93,9 +114,27 @@ func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
 	}
 
 	projectCmds, policyCheckCmds := p.partitionProjectCmds(ctx, projectCmds)
+	projectCmds, policyCheckCmds, frozenResults := p.partitionFrozenProjectCmds(ctx, projectCmds, policyCheckCmds)
+	projectCmds, policyCheckCmds, impactPreviewCmds := partitionImpactPreviewCmds(projectCmds, policyCheckCmds)
+
+	autoplanTriggers := p.GlobalCfgStore.Load().AutoplanTriggers(baseRepo.ID())
+	if autoplanTriggers.TooManyProjects(len(projectCmds)) {
+		ctx.Log.Info("not running autoplan since %d projects were modified, more than the maximum of %d", len(projectCmds), autoplanTriggers.MaxProjects)
+		// The plan status stays pending until the projects are planned by
+		// commenting.
+		if err := p.vcsClient.CreateComment(ctx.Log, baseRepo, pull.Num, autoplanTooManyProjectsComment(len(projectCmds), autoplanTriggers.MaxProjects), command.Plan.String()); err != nil {
+			ctx.Log.Err("unable to comment on pull request: %s", err)
+		}
+		return
+	}
 
-	if len(projectCmds) == 0 {
+	if len(projectCmds) == 0 && len(frozenResults) == 0 {
 		ctx.Log.Info("determined there was no project to run plan in")
+		if ctx.RepoCfgReview != nil {
+			// The review of the modified repo config is commented even
+			// though nothing was planned.
+			p.pullUpdater.updatePull(ctx, AutoplanCommand{}, command.Result{})
+		}
 		if !(p.silenceVCSStatusNoPlans || p.silenceVCSStatusNoProjects) {
 			// If there were no projects modified, we set successful commit statuses
 			// with 0/0 projects planned/policy_checked/applied successfully because some users require
@@ -126,10 +165,12 @@ func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
 	var result command.Result
 	if p.isParallelEnabled(projectCmds) {
 		ctx.Log.Info("Running plans in parallel")
-		result = runProjectCmdsParallelGroups(ctx, projectCmds, p.prjCmdRunner.Plan, p.parallelPoolSize)
+		result = p.runParallel(ctx, projectCmds)
 	} else {
 		result = runProjectCmds(projectCmds, p.prjCmdRunner.Plan)
 	}
+	result.ProjectResults = append(result.ProjectResults, frozenResults...)
+	result.ImpactPreviews = p.runImpactPreviews(ctx, impactPreviewCmds)
 
 	if p.autoMerger.automergeEnabled(projectCmds) && result.HasErrors() {
 		ctx.Log.Info("deleting plans because there were errors and automerge requires all plans succeed")
@@ -161,6 +202,9 @@ func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
 
 		p.policyCheckCommandRunner.Run(ctx, policyCheckCmds)
 	}
+
+	p.deployPreviewEnvironments(ctx, projectCmds, result)
+	p.scheduleAutoApplies(ctx, result)
 }
 
 func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
@@ -191,6 +235,7 @@ func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
 		p.pullUpdater.updatePull(ctx, cmd, command.Result{Error: err})
 		return
 	}
+	skipReviewRequirements(cmd, projectCmds)
 
 	if len(projectCmds) == 0 && p.SilenceNoProjects {
 		ctx.Log.Info("determined there was no project to run plan in")
@@ -233,6 +278,8 @@ func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
 	}
 
 	projectCmds, policyCheckCmds := p.partitionProjectCmds(ctx, projectCmds)
+	projectCmds, policyCheckCmds, frozenResults := p.partitionFrozenProjectCmds(ctx, projectCmds, policyCheckCmds)
+	projectCmds, policyCheckCmds, impactPreviewCmds := partitionImpactPreviewCmds(projectCmds, policyCheckCmds)
 
 	// if the plan is generic, new plans will be generated based on changes
 	// discard previous plans that might not be relevant anymore
@@ -249,10 +296,12 @@ func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
 	var result command.Result
 	if p.isParallelEnabled(projectCmds) {
 		ctx.Log.Info("Running plans in parallel")
-		result = runProjectCmdsParallelGroups(ctx, projectCmds, p.prjCmdRunner.Plan, p.parallelPoolSize)
+		result = p.runParallel(ctx, projectCmds)
 	} else {
 		result = runProjectCmds(projectCmds, p.prjCmdRunner.Plan)
 	}
+	result.ProjectResults = append(result.ProjectResults, frozenResults...)
+	result.ImpactPreviews = p.runImpactPreviews(ctx, impactPreviewCmds)
 	ctx.CommandHasErrors = result.HasErrors()
 
 	if p.autoMerger.automergeEnabled(projectCmds) && result.HasErrors() {
@@ -290,9 +339,27 @@ func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
 			ctx.Log.Warn("unable to update commit status: %s", err)
 		}
 	}
+
+	// Plans run to destroy workspaces are applied by whatever ran them.
+	if !cmd.previewEnvironment && !cmd.destroyOnClose {
+		p.deployPreviewEnvironments(ctx, projectCmds, result)
+		p.scheduleAutoApplies(ctx, result)
+	}
 }
 
 func (p *PlanCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
+	if freeze := locking.FindPlanFreeze(p.planFreezes(ctx), ctx.Pull.BaseRepo.FullName, "", ""); freeze != nil {
+		ctx.Log.Info("ignoring plan since plans are frozen")
+		// The plan status was set to pending before the plan started.
+		if err := p.commitStatusUpdater.UpdateCombined(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, models.FailedCommitStatus, command.Plan); err != nil {
+			ctx.Log.Warn("unable to update commit status: %s", err)
+		}
+		if err := p.vcsClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, p.planFrozenComment(*freeze), command.Plan.String()); err != nil {
+			ctx.Log.Err("unable to comment on pull request: %s", err)
+		}
+		return
+	}
+
 	if ctx.Trigger == command.AutoTrigger {
 		p.runAutoplan(ctx)
 	} else {
@@ -371,6 +438,118 @@ func (p *PlanCommandRunner) partitionProjectCmds(
 	return
 }
 
+// planFreezes returns the active plan freezes.
+func (p *PlanCommandRunner) planFreezes(ctx *command.Context) []locking.PlanFreeze {
+	if p.PlanFreezer == nil {
+		return nil
+	}
+	freezes, err := p.PlanFreezer.List()
+	if err != nil {
+		// Like with the global apply lock, we'd rather plan than block all
+		// plans because the freezes can't be read.
+		ctx.Log.Warn("checking plan freezes: %s", err)
+		return nil
+	}
+	return freezes
+}
+
+// partitionFrozenProjectCmds removes the commands of the projects that plans
+// are frozen for and returns a failed result for each of them.
+func (p *PlanCommandRunner) partitionFrozenProjectCmds(
+	ctx *command.Context,
+	cmds []command.ProjectContext,
+	policyCheckCmds []command.ProjectContext,
+) (
+	unfrozenCmds []command.ProjectContext,
+	unfrozenPolicyCheckCmds []command.ProjectContext,
+	frozenResults []command.ProjectResult,
+) {
+	freezes := p.planFreezes(ctx)
+	frozen := make(map[string]bool)
+	for _, cmd := range cmds {
+		freeze := locking.FindPlanFreeze(freezes, ctx.Pull.BaseRepo.FullName, cmd.ProjectName, cmd.RepoRelDir)
+		if freeze == nil {
+			unfrozenCmds = append(unfrozenCmds, cmd)
+			continue
+		}
+		ctx.Log.Info("not planning %s in workspace %s since plans are frozen", cmd.RepoRelDir, cmd.Workspace)
+		frozen[cmd.ProjectName+"/"+cmd.RepoRelDir+"/"+cmd.Workspace] = true
+		frozenResults = append(frozenResults, command.ProjectResult{
+			Command:     command.Plan,
+			RepoRelDir:  cmd.RepoRelDir,
+			Workspace:   cmd.Workspace,
+			ProjectName: cmd.ProjectName,
+			Failure:     p.planFrozenComment(*freeze),
+		})
+	}
+	for _, cmd := range policyCheckCmds {
+		if !frozen[cmd.ProjectName+"/"+cmd.RepoRelDir+"/"+cmd.Workspace] {
+			unfrozenPolicyCheckCmds = append(unfrozenPolicyCheckCmds, cmd)
+		}
+	}
+	return
+}
+
+// partitionImpactPreviewCmds removes the commands of the projects that are
+// only planned to preview the impact of the pull request and returns their
+// plan commands. Their plans aren't policy checked.
+func partitionImpactPreviewCmds(
+	cmds []command.ProjectContext,
+	policyCheckCmds []command.ProjectContext,
+) (
+	projectCmds []command.ProjectContext,
+	projectPolicyCheckCmds []command.ProjectContext,
+	impactPreviewCmds []command.ProjectContext,
+) {
+	for _, cmd := range cmds {
+		if cmd.ImpactPreview {
+			impactPreviewCmds = append(impactPreviewCmds, cmd)
+		} else {
+			projectCmds = append(projectCmds, cmd)
+		}
+	}
+	for _, cmd := range policyCheckCmds {
+		if !cmd.ImpactPreview {
+			projectPolicyCheckCmds = append(projectPolicyCheckCmds, cmd)
+		}
+	}
+	return
+}
+
+// runImpactPreviews plans the projects of impactPreviewCmds one at a time
+// after the modified projects were planned.
+func (p *PlanCommandRunner) runImpactPreviews(ctx *command.Context, impactPreviewCmds []command.ProjectContext) []command.ProjectResult {
+	if len(impactPreviewCmds) == 0 {
+		return nil
+	}
+	ctx.Log.Info("planning %d projects to preview the impact of the pull request", len(impactPreviewCmds))
+	return runProjectCmds(impactPreviewCmds, p.prjCmdRunner.Plan).ProjectResults
+}
+
+// planFrozenComment returns the message of freeze or the default freeze
+// message.
+func (p *PlanCommandRunner) planFrozenComment(freeze locking.PlanFreeze) string {
+	if freeze.Message != "" {
+		return freeze.Message
+	}
+	return p.PlanFreezeMessage
+}
+
+// autoplanTooManyProjectsComment is posted instead of autoplanning pull
+// requests that modify more projects than allowed.
+func autoplanTooManyProjectsComment(numProjects int, maxProjects int) string {
+	return fmt.Sprintf("Autoplan was skipped since this pull request modifies %d projects, more than the %d that are autoplanned. Comment `atlantis plan` to plan all of them, or `atlantis plan -d <dir>` to plan them one at a time.", numProjects, maxProjects)
+}
+
 func (p *PlanCommandRunner) isParallelEnabled(projectCmds []command.ProjectContext) bool {
 	return len(projectCmds) > 0 && projectCmds[0].ParallelPlanEnabled
 }
+
+// runParallel runs projectCmds with the scheduler if it's set or in a pool
+// otherwise.
+func (p *PlanCommandRunner) runParallel(ctx *command.Context, projectCmds []command.ProjectContext) command.Result {
+	if p.Scheduler != nil {
+		return p.Scheduler.RunGroups(ctx, projectCmds, p.prjCmdRunner.Plan, p.parallelPoolSize)
+	}
+	return runProjectCmdsParallelGroups(ctx, projectCmds, p.prjCmdRunner.Plan, p.parallelPoolSize)
+}
@@ -2,11 +2,15 @@ package events_test
 
 import (
 	"errors"
+	"regexp"
+	"slices"
 	"testing"
 
 	"github.com/google/go-github/v68/github"
 	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -826,3 +830,193 @@ func TestPlanCommandRunner_AtlantisApplyStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanCommandRunner_PlanFreeze(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+
+	cases := []struct {
+		Description string
+		Freeze      locking.PlanFreeze
+		// ExpPlanned is the names of the projects expected to be planned.
+		ExpPlanned []string
+		ExpComment string
+	}{
+		{
+			Description: "When plans are frozen globally, comment the default message",
+			Freeze:      locking.PlanFreeze{},
+			ExpComment:  "plans are frozen",
+		},
+		{
+			Description: "When plans are frozen for the repo, comment the freeze's message",
+			Freeze:      locking.PlanFreeze{Repo: testdata.GithubRepo.FullName, Message: "incident in progress"},
+			ExpComment:  "incident in progress",
+		},
+		{
+			Description: "When plans are frozen for another repo, plan as usual",
+			Freeze:      locking.PlanFreeze{Repo: "owner/other"},
+			ExpPlanned:  []string{"First", "Second"},
+		},
+		{
+			Description: "When plans are frozen for a project, only plan the other projects",
+			Freeze:      locking.PlanFreeze{Repo: testdata.GithubRepo.FullName, Project: "First"},
+			ExpPlanned:  []string{"Second"},
+		},
+		{
+			Description: "When plans are frozen for a dir, only plan the other projects",
+			Freeze:      locking.PlanFreeze{Repo: testdata.GithubRepo.FullName, Dir: "./second"},
+			ExpPlanned:  []string{"First"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			tmp := t.TempDir()
+			db, err := db.New(tmp)
+			t.Cleanup(func() {
+				db.Close()
+			})
+			Ok(t, err)
+
+			vcsClient := setup(t, func(tc *TestConfig) {
+				tc.backend = db
+			})
+			planFreezer := locking.NewPlanFreezer(db)
+			_, err = planFreezer.Freeze(c.Freeze)
+			Ok(t, err)
+			planCommandRunner.PlanFreezer = planFreezer
+			planCommandRunner.PlanFreezeMessage = "plans are frozen"
+
+			scopeNull, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+			modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, State: models.OpenPullState, Num: testdata.Pull.Num}
+			cmd := &events.CommentCommand{Name: command.Plan}
+			ctx := &command.Context{
+				User:     testdata.User,
+				Log:      logging.NewNoopLogger(t),
+				Scope:    scopeNull,
+				Pull:     modelPull,
+				HeadRepo: testdata.GithubRepo,
+				Trigger:  command.CommentTrigger,
+			}
+
+			projectCtxs := []command.ProjectContext{
+				{CommandName: command.Plan, ProjectName: "First", RepoRelDir: "first", Workspace: "default"},
+				{CommandName: command.Plan, ProjectName: "Second", RepoRelDir: "second", Workspace: "default"},
+			}
+			When(projectCommandBuilder.BuildPlanCommands(ctx, cmd)).ThenReturn(projectCtxs, nil)
+			for _, projectCtx := range projectCtxs {
+				When(projectCommandRunner.Plan(projectCtx)).ThenReturn(command.ProjectResult{
+					Command:     command.Plan,
+					ProjectName: projectCtx.ProjectName,
+					RepoRelDir:  projectCtx.RepoRelDir,
+					Workspace:   projectCtx.Workspace,
+					PlanSuccess: &models.PlanSuccess{TerraformOutput: "true"},
+				})
+			}
+
+			planCommandRunner.Run(ctx, cmd)
+
+			if c.ExpComment != "" {
+				projectCommandBuilder.VerifyWasCalled(Never()).BuildPlanCommands(Any[*command.Context](), Any[*events.CommentCommand]())
+				vcsClient.VerifyWasCalledOnce().CreateComment(
+					Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull.Num), Eq(c.ExpComment), Eq("plan"),
+				)
+				return
+			}
+			for _, projectCtx := range projectCtxs {
+				times := Never()
+				if slices.Contains(c.ExpPlanned, projectCtx.ProjectName) {
+					times = Once()
+				}
+				projectCommandRunner.VerifyWasCalled(times).Plan(projectCtx)
+			}
+			require.Equal(t, len(c.ExpPlanned) < len(projectCtxs), ctx.CommandHasErrors)
+		})
+	}
+}
+
+func TestPlanCommandRunner_AutoplanMaxProjects(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+
+	cases := []struct {
+		Description string
+		MaxProjects int
+		ExpPlanned  bool
+	}{
+		{
+			Description: "When fewer projects than the maximum are modified, autoplan",
+			MaxProjects: 2,
+			ExpPlanned:  true,
+		},
+		{
+			Description: "When more projects than the maximum are modified, comment instead of autoplanning",
+			MaxProjects: 1,
+			ExpPlanned:  false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			tmp := t.TempDir()
+			db, err := db.New(tmp)
+			t.Cleanup(func() {
+				db.Close()
+			})
+			Ok(t, err)
+
+			vcsClient := setup(t, func(tc *TestConfig) {
+				tc.backend = db
+			})
+			globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+			globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
+				IDRegex:          regexp.MustCompile(".*"),
+				AutoplanTriggers: &valid.AutoplanTriggers{MaxProjects: c.MaxProjects},
+			})
+			planCommandRunner.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
+
+			scopeNull, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+			modelPull := models.PullRequest{BaseRepo: testdata.GithubRepo, State: models.OpenPullState, Num: testdata.Pull.Num}
+			ctx := &command.Context{
+				User:     testdata.User,
+				Log:      logging.NewNoopLogger(t),
+				Scope:    scopeNull,
+				Pull:     modelPull,
+				HeadRepo: testdata.GithubRepo,
+				Trigger:  command.AutoTrigger,
+			}
+
+			projectCtxs := []command.ProjectContext{
+				{CommandName: command.Plan, ProjectName: "First", RepoRelDir: "first", Workspace: "default"},
+				{CommandName: command.Plan, ProjectName: "Second", RepoRelDir: "second", Workspace: "default"},
+			}
+			When(projectCommandBuilder.BuildAutoplanCommands(ctx)).ThenReturn(projectCtxs, nil)
+			for _, projectCtx := range projectCtxs {
+				When(projectCommandRunner.Plan(projectCtx)).ThenReturn(command.ProjectResult{
+					Command:     command.Plan,
+					ProjectName: projectCtx.ProjectName,
+					RepoRelDir:  projectCtx.RepoRelDir,
+					Workspace:   projectCtx.Workspace,
+					PlanSuccess: &models.PlanSuccess{TerraformOutput: "true"},
+				})
+			}
+
+			planCommandRunner.Run(ctx, &events.CommentCommand{Name: command.Plan})
+
+			times := Never()
+			if c.ExpPlanned {
+				times = Once()
+			}
+			for _, projectCtx := range projectCtxs {
+				projectCommandRunner.VerifyWasCalled(times).Plan(projectCtx)
+			}
+			if !c.ExpPlanned {
+				vcsClient.VerifyWasCalledOnce().CreateComment(
+					Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull.Num),
+					Eq("Autoplan was skipped since this pull request modifies 2 projects, more than the 1 that are autoplanned. Comment `atlantis plan` to plan all of them, or `atlantis plan -d <dir>` to plan them one at a time."),
+					Eq("plan"),
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// plannedResourceChanges returns the managed resources a plan changes, sorted
+// by address. planJSON is the output of terraform show -json on the planfile.
+func plannedResourceChanges(planJSON string) ([]models.PlannedResourceChange, error) {
+	var plan struct {
+		ResourceChanges []struct {
+			Address string `json:"address"`
+			Mode    string `json:"mode"`
+			Change  struct {
+				Actions []string `json:"actions"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return nil, fmt.Errorf("parsing plan json: %w", err)
+	}
+	var changes []models.PlannedResourceChange
+	for _, rc := range plan.ResourceChanges {
+		action := riskAction(rc.Change.Actions)
+		if rc.Mode != "managed" || action == "" {
+			continue
+		}
+		changes = append(changes, models.PlannedResourceChange{Address: rc.Address, Action: action})
+	}
+	slices.SortFunc(changes, func(a, b models.PlannedResourceChange) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return changes, nil
+}
+
+// diffPlannedChanges returns how changes differ from the changes of the
+// previous plan.
+func diffPlannedChanges(previous models.PlannedChanges, changes []models.PlannedResourceChange) *models.PlanDelta {
+	delta := &models.PlanDelta{PreviousCommit: previous.HeadCommit}
+	for _, change := range changes {
+		if !slices.Contains(previous.Changes, change) {
+			delta.Added = append(delta.Added, change)
+		}
+	}
+	for _, change := range previous.Changes {
+		if !slices.Contains(changes, change) {
+			delta.Removed = append(delta.Removed, change)
+		}
+	}
+	return delta
+}
+
+// planDelta stores the resource changes of the project's plan and returns
+// how they differ from the previous plan of the project, or nil if it wasn't
+// planned before. Remote operations and projects of other tools than
+// terraform don't have a planfile to show so they're skipped.
+func (p *DefaultProjectCommandRunner) planDelta(ctx command.ProjectContext, absPath string) (*models.PlanDelta, error) {
+	if p.PlanChangesStore == nil || !valid.UsesTerraform(ctx.Tool) {
+		return nil, nil
+	}
+	out, err := p.showPlan(ctx, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("showing plan: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	changes, err := plannedResourceChanges(out)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := p.PlanChangesStore.Get(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+	if err != nil {
+		return nil, fmt.Errorf("getting changes of previous plan: %w", err)
+	}
+	err = p.PlanChangesStore.Save(models.PlannedChanges{
+		RepoFullName: ctx.Pull.BaseRepo.FullName,
+		PullNum:      ctx.Pull.Num,
+		HeadCommit:   ctx.Pull.HeadCommit,
+		ProjectName:  ctx.ProjectName,
+		RepoRelDir:   ctx.RepoRelDir,
+		Workspace:    ctx.Workspace,
+		Changes:      changes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving plan changes: %w", err)
+	}
+	if previous == nil {
+		return nil, nil
+	}
+	return diffPlannedChanges(*previous, changes), nil
+}
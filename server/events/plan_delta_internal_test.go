@@ -0,0 +1,75 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPlannedResourceChanges(t *testing.T) {
+	changes, err := plannedResourceChanges(`{
+	"resource_changes": [
+		{"address": "aws_s3_bucket.logs", "mode": "managed", "change": {"actions": ["delete"]}},
+		{"address": "aws_db_instance.main", "mode": "managed", "change": {"actions": ["delete", "create"]}},
+		{"address": "random_id.suffix", "mode": "managed", "change": {"actions": ["no-op"]}},
+		{"address": "data.aws_iam_policy_document.assume", "mode": "data", "change": {"actions": ["read"]}}
+	]
+}`)
+	Ok(t, err)
+	Equals(t, []models.PlannedResourceChange{
+		{Address: "aws_db_instance.main", Action: "replace"},
+		{Address: "aws_s3_bucket.logs", Action: "delete"},
+	}, changes)
+
+	_, err = plannedResourceChanges("not json")
+	ErrContains(t, "parsing plan json", err)
+}
+
+func TestPlanDelta(t *testing.T) {
+	store := &FilePlanChangesStore{Dir: t.TempDir()}
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Pull:       models.PullRequest{Num: 1, HeadCommit: "abc1234567", BaseRepo: models.Repo{FullName: "owner/repo"}},
+		RepoRelDir: "dir",
+		Workspace:  "default",
+	}
+	runner := &DefaultProjectCommandRunner{
+		PlanChangesStore: store,
+		ShowStepRunner: staticShowRunner{out: `{"resource_changes": [
+			{"address": "aws_instance.web", "mode": "managed", "change": {"actions": ["update"]}},
+			{"address": "aws_s3_bucket.logs", "mode": "managed", "change": {"actions": ["create"]}}
+		]}`},
+	}
+
+	// The first plan has nothing to compare to.
+	delta, err := runner.planDelta(ctx, t.TempDir())
+	Ok(t, err)
+	Assert(t, delta == nil, "expected no delta for the first plan, got %v", delta)
+
+	ctx.Pull.HeadCommit = "def4567890"
+	runner.ShowStepRunner = staticShowRunner{out: `{"resource_changes": [
+		{"address": "aws_instance.web", "mode": "managed", "change": {"actions": ["delete", "create"]}},
+		{"address": "aws_s3_bucket.logs", "mode": "managed", "change": {"actions": ["create"]}}
+	]}`}
+	delta, err = runner.planDelta(ctx, t.TempDir())
+	Ok(t, err)
+	Equals(t, &models.PlanDelta{
+		PreviousCommit: "abc1234567",
+		Added:          []models.PlannedResourceChange{{Address: "aws_instance.web", Action: "replace"}},
+		Removed:        []models.PlannedResourceChange{{Address: "aws_instance.web", Action: "update"}},
+	}, delta)
+
+	// Planning again without changes shows an empty delta.
+	delta, err = runner.planDelta(ctx, t.TempDir())
+	Ok(t, err)
+	Equals(t, &models.PlanDelta{PreviousCommit: "def4567890"}, delta)
+
+	// Other projects are compared to their own plans.
+	ctx.ProjectName = "other"
+	delta, err = runner.planDelta(ctx, t.TempDir())
+	Ok(t, err)
+	Assert(t, delta == nil, "expected no delta for another project, got %v", delta)
+}
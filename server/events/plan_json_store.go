@@ -0,0 +1,96 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// PlanJSONStore stores the machine-readable plans of pull requests so
+// external tools can consume them without running Terraform.
+type PlanJSONStore interface {
+	// Save stores plan, replacing the previous plan of the same project.
+	Save(plan models.PlanJSON) error
+	// List returns the latest plan of each project of a pull request.
+	List(repoFullName string, pullNum int) ([]models.PlanJSON, error)
+	// DeleteForPull deletes the plans of a pull request.
+	DeleteForPull(repoFullName string, pullNum int) error
+}
+
+// FilePlanJSONStore stores plans as JSON files in Dir.
+type FilePlanJSONStore struct {
+	Dir string
+}
+
+func (f *FilePlanJSONStore) Save(plan models.PlanJSON) error {
+	pullDir, err := storePullDir(f.Dir, plan.RepoFullName, plan.PullNum)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(pullDir, 0700); err != nil {
+		return errors.Wrap(err, "creating plan json dir")
+	}
+	contents, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+	// Project names and dirs can contain any character so the file is named
+	// after a hash of what identifies the project.
+	id := sha256.Sum256([]byte(strings.Join([]string{plan.RepoRelDir, plan.Workspace, plan.ProjectName}, "\x00")))
+	return os.WriteFile(filepath.Join(pullDir, hex.EncodeToString(id[:])+".json"), contents, 0600)
+}
+
+func (f *FilePlanJSONStore) List(repoFullName string, pullNum int) ([]models.PlanJSON, error) {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(pullDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var plans []models.PlanJSON
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(pullDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var plan models.PlanJSON
+		if err := json.Unmarshal(contents, &plan); err != nil {
+			return nil, errors.Wrapf(err, "parsing plan json %s", entry.Name())
+		}
+		plans = append(plans, plan)
+	}
+	slices.SortFunc(plans, func(a, b models.PlanJSON) int {
+		if c := strings.Compare(a.RepoRelDir, b.RepoRelDir); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Workspace, b.Workspace); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ProjectName, b.ProjectName)
+	})
+	return plans, nil
+}
+
+func (f *FilePlanJSONStore) DeleteForPull(repoFullName string, pullNum int) error {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(pullDir)
+}
@@ -0,0 +1,47 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFilePlanJSONStore(t *testing.T) {
+	store := &events.FilePlanJSONStore{Dir: t.TempDir()}
+	dirProject := models.PlanJSON{
+		RepoFullName: "owner/repo",
+		PullNum:      1,
+		RepoRelDir:   "dir",
+		Workspace:    "default",
+		Plan:         json.RawMessage(`{"format_version":"1.2"}`),
+	}
+	namedProject := models.PlanJSON{
+		RepoFullName: "owner/repo",
+		PullNum:      1,
+		RepoRelDir:   "dir",
+		Workspace:    "default",
+		ProjectName:  "project",
+		Plan:         json.RawMessage(`{"format_version":"1.1"}`),
+	}
+	Ok(t, store.Save(namedProject))
+	Ok(t, store.Save(dirProject))
+
+	// Saving a project again replaces its plan.
+	dirProject.HeadCommit = "abc123"
+	Ok(t, store.Save(dirProject))
+
+	plans, err := store.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, []models.PlanJSON{dirProject, namedProject}, plans)
+
+	Ok(t, store.DeleteForPull("owner/repo", 1))
+	plans, err = store.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, 0, len(plans))
+
+	// Deleting a pull without plans is a no-op.
+	Ok(t, store.DeleteForPull("owner/repo", 2))
+}
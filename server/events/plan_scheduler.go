@@ -0,0 +1,178 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// PlanScheduler runs the plans of a command in parallel like
+// runProjectCmdsParallelGroups, but within each execution order group it:
+//   - starts a project only once the projects it depends on have planned,
+//   - starts the projects that planned the fastest last time first so a slow
+//     project doesn't hold the pool while small ones queue,
+//   - limits how many projects of the same repo plan at once across all pull
+//     requests.
+type PlanScheduler struct {
+	// RepoPoolSize is the max number of projects of a repo that plan at the
+	// same time. If 0 there's no limit other than the pool size.
+	RepoPoolSize int
+
+	mux sync.Mutex
+	// durations are how long the last plan of each project took.
+	durations map[string]time.Duration
+	// repoSlots limit the plans running for each repo to RepoPoolSize.
+	repoSlots map[string]chan struct{}
+}
+
+// NewPlanScheduler is a constructor.
+func NewPlanScheduler(repoPoolSize int) *PlanScheduler {
+	return &PlanScheduler{
+		RepoPoolSize: repoPoolSize,
+		durations:    make(map[string]time.Duration),
+		repoSlots:    make(map[string]chan struct{}),
+	}
+}
+
+// RunGroups runs cmds one execution order group after another, stopping
+// early if a group fails and aborts on failure.
+func (s *PlanScheduler) RunGroups(ctx *command.Context, cmds []command.ProjectContext, runnerFunc prjCmdRunnerFunc, poolSize int) command.Result {
+	var results []command.ProjectResult
+	for _, group := range splitByExecutionOrderGroup(cmds) {
+		res := s.run(group, runnerFunc, poolSize)
+		results = append(results, res.ProjectResults...)
+		if res.HasErrors() && group[0].AbortOnExecutionOrderFail {
+			ctx.Log.Info("abort on execution order when failed")
+			break
+		}
+	}
+	return command.Result{ProjectResults: results}
+}
+
+// run runs at most poolSize of cmds at a time. If the remaining projects all
+// wait on each other, e.g. because of a dependency cycle, they're run in
+// order instead of deadlocking.
+func (s *PlanScheduler) run(cmds []command.ProjectContext, runnerFunc prjCmdRunnerFunc, poolSize int) command.Result {
+	pending := s.byLastDuration(cmds)
+	// unfinished counts the projects of each name that haven't planned yet.
+	unfinished := make(map[string]int)
+	for _, cmd := range cmds {
+		if cmd.ProjectName != "" {
+			unfinished[cmd.ProjectName]++
+		}
+	}
+
+	var results []command.ProjectResult
+	done := make(chan command.ProjectResult)
+	running := 0
+	for len(pending) > 0 || running > 0 {
+		for len(pending) > 0 && (poolSize <= 0 || running < poolSize) {
+			i := nextReady(pending, unfinished)
+			if i < 0 {
+				if running > 0 {
+					break
+				}
+				i = 0
+			}
+			pCmd := pending[i]
+			pending = append(pending[:i], pending[i+1:]...)
+			running++
+			go func() {
+				done <- s.runProject(pCmd, runnerFunc)
+			}()
+		}
+
+		res := <-done
+		running--
+		if res.ProjectName != "" {
+			unfinished[res.ProjectName]--
+		}
+		results = append(results, res)
+	}
+	return command.Result{ProjectResults: results}
+}
+
+// nextReady returns the index of the first of pending whose dependencies have
+// all planned or -1 if there's none. Dependencies that aren't part of the
+// command are ignored.
+func nextReady(pending []command.ProjectContext, unfinished map[string]int) int {
+	for i, cmd := range pending {
+		ready := true
+		for _, dep := range cmd.DependsOn {
+			if dep != cmd.ProjectName && unfinished[dep] > 0 {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			return i
+		}
+	}
+	return -1
+}
+
+// runProject runs ctx once its repo has a free slot and records how long it
+// took.
+func (s *PlanScheduler) runProject(ctx command.ProjectContext, runnerFunc prjCmdRunnerFunc) command.ProjectResult {
+	if slots := s.slots(ctx.BaseRepo.FullName); slots != nil {
+		slots <- struct{}{}
+		defer func() { <-slots }()
+	}
+
+	start := time.Now()
+	res := runnerFunc(ctx)
+
+	s.mux.Lock()
+	s.durations[scheduledProjectKey(ctx)] = time.Since(start)
+	s.mux.Unlock()
+	return res
+}
+
+// slots returns the channel limiting the plans of repoFullName or nil if
+// there's no limit.
+func (s *PlanScheduler) slots(repoFullName string) chan struct{} {
+	if s.RepoPoolSize <= 0 {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	slots, ok := s.repoSlots[repoFullName]
+	if !ok {
+		slots = make(chan struct{}, s.RepoPoolSize)
+		s.repoSlots[repoFullName] = slots
+	}
+	return slots
+}
+
+// byLastDuration returns a copy of cmds sorted by how long they took to plan
+// last time. Projects that haven't planned yet come first so new projects
+// get feedback quickly.
+func (s *PlanScheduler) byLastDuration(cmds []command.ProjectContext) []command.ProjectContext {
+	s.mux.Lock()
+	durations := make([]time.Duration, len(cmds))
+	for i, cmd := range cmds {
+		durations[i] = s.durations[scheduledProjectKey(cmd)]
+	}
+	s.mux.Unlock()
+
+	indexes := make([]int, len(cmds))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return durations[indexes[i]] < durations[indexes[j]]
+	})
+	sorted := make([]command.ProjectContext, len(cmds))
+	for i, index := range indexes {
+		sorted[i] = cmds[index]
+	}
+	return sorted
+}
+
+// scheduledProjectKey identifies a project across pull requests.
+func scheduledProjectKey(ctx command.ProjectContext) string {
+	return fmt.Sprintf("%s/%s/%s/%s", ctx.BaseRepo.FullName, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// recordingRunner returns a runner that records the order projects start in.
+func recordingRunner(started *[]string, mux *sync.Mutex) prjCmdRunnerFunc {
+	return func(ctx command.ProjectContext) command.ProjectResult {
+		mux.Lock()
+		*started = append(*started, ctx.ProjectName)
+		mux.Unlock()
+		return command.ProjectResult{ProjectName: ctx.ProjectName, PlanSuccess: &models.PlanSuccess{}}
+	}
+}
+
+func TestPlanScheduler_Dependencies(t *testing.T) {
+	var started []string
+	mux := &sync.Mutex{}
+	cmds := []command.ProjectContext{
+		{ProjectName: "app", DependsOn: []string{"network", "iam"}},
+		{ProjectName: "dns", DependsOn: []string{"missing"}},
+		{ProjectName: "network", DependsOn: []string{"iam"}},
+		{ProjectName: "iam"},
+	}
+
+	s := NewPlanScheduler(0)
+	result := s.RunGroups(&command.Context{Log: logging.NewNoopLogger(t)}, cmds, recordingRunner(&started, mux), 10)
+
+	Equals(t, 4, len(result.ProjectResults))
+	Assert(t, !result.HasErrors(), "exp no errors")
+	index := make(map[string]int)
+	for i, name := range started {
+		index[name] = i
+	}
+	Assert(t, index["iam"] < index["network"], "exp iam before network, got %v", started)
+	Assert(t, index["network"] < index["app"], "exp network before app, got %v", started)
+}
+
+// Projects that depend on each other are run in order instead of waiting
+// forever.
+func TestPlanScheduler_DependencyCycle(t *testing.T) {
+	var started []string
+	mux := &sync.Mutex{}
+	cmds := []command.ProjectContext{
+		{ProjectName: "a", DependsOn: []string{"b"}},
+		{ProjectName: "b", DependsOn: []string{"a"}},
+	}
+
+	s := NewPlanScheduler(0)
+	result := s.RunGroups(&command.Context{Log: logging.NewNoopLogger(t)}, cmds, recordingRunner(&started, mux), 10)
+
+	Equals(t, 2, len(result.ProjectResults))
+	Equals(t, []string{"a", "b"}, started)
+}
+
+func TestPlanScheduler_FastestFirst(t *testing.T) {
+	var started []string
+	mux := &sync.Mutex{}
+	cmds := []command.ProjectContext{
+		{ProjectName: "slow"},
+		{ProjectName: "fast"},
+		{ProjectName: "new"},
+		{ProjectName: "medium"},
+	}
+
+	s := NewPlanScheduler(0)
+	s.durations[scheduledProjectKey(cmds[0])] = 10 * time.Minute
+	s.durations[scheduledProjectKey(cmds[1])] = time.Second
+	s.durations[scheduledProjectKey(cmds[3])] = time.Minute
+	s.RunGroups(&command.Context{Log: logging.NewNoopLogger(t)}, cmds, recordingRunner(&started, mux), 1)
+
+	Equals(t, []string{"new", "fast", "medium", "slow"}, started)
+	for _, cmd := range cmds {
+		_, ok := s.durations[scheduledProjectKey(cmd)]
+		Assert(t, ok, "exp duration of %s to be recorded", cmd.ProjectName)
+	}
+}
+
+func TestPlanScheduler_RepoPoolSize(t *testing.T) {
+	repo := models.Repo{FullName: "owner/repo"}
+	otherRepo := models.Repo{FullName: "owner/other"}
+	var cmds []command.ProjectContext
+	for _, name := range []string{"a", "b", "c", "d"} {
+		cmds = append(cmds, command.ProjectContext{ProjectName: name, BaseRepo: repo})
+	}
+	otherCmds := []command.ProjectContext{{ProjectName: "e", BaseRepo: otherRepo}}
+
+	mux := &sync.Mutex{}
+	running := make(map[string]int)
+	maxRunning := make(map[string]int)
+	runner := func(ctx command.ProjectContext) command.ProjectResult {
+		mux.Lock()
+		running[ctx.BaseRepo.FullName]++
+		if running[ctx.BaseRepo.FullName] > maxRunning[ctx.BaseRepo.FullName] {
+			maxRunning[ctx.BaseRepo.FullName] = running[ctx.BaseRepo.FullName]
+		}
+		mux.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mux.Lock()
+		running[ctx.BaseRepo.FullName]--
+		mux.Unlock()
+		return command.ProjectResult{ProjectName: ctx.ProjectName}
+	}
+
+	// The limit applies across commands.
+	s := NewPlanScheduler(2)
+	wg := sync.WaitGroup{}
+	for _, c := range [][]command.ProjectContext{cmds, cmds, otherCmds} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.RunGroups(&command.Context{Log: logging.NewNoopLogger(t)}, c, runner, 10)
+		}()
+	}
+	wg.Wait()
+
+	Equals(t, 2, maxRunning["owner/repo"])
+	Equals(t, 1, maxRunning["owner/other"])
+}
@@ -30,7 +30,7 @@ type DefaultPostWorkflowHooksCommandRunner struct {
 	VCSClient              vcs.Client                     `validate:"required"`
 	WorkingDirLocker       WorkingDirLocker               `validate:"required"`
 	WorkingDir             WorkingDir                     `validate:"required"`
-	GlobalCfg              valid.GlobalCfg                `validate:"required"`
+	GlobalCfgStore         *valid.GlobalCfgStore          `validate:"required"`
 	PostWorkflowHookRunner runtime.PostWorkflowHookRunner `validate:"required"`
 	CommitStatusUpdater    CommitStatusUpdater            `validate:"required"`
 	Router                 PostWorkflowHookURLGenerator   `validate:"required"`
@@ -39,7 +39,8 @@ type DefaultPostWorkflowHooksCommandRunner struct {
 // RunPostHooks runs post_workflow_hooks after a plan/apply has completed
 func (w *DefaultPostWorkflowHooksCommandRunner) RunPostHooks(ctx *command.Context, cmd *CommentCommand) error {
 	postWorkflowHooks := make([]*valid.WorkflowHook, 0)
-	for _, repo := range w.GlobalCfg.Repos {
+	globalCfg := w.GlobalCfgStore.Load()
+	for _, repo := range globalCfg.Repos {
 		if repo.IDMatches(ctx.Pull.BaseRepo.ID()) && repo.BranchMatches(ctx.Pull.BaseBranch) && len(repo.PostWorkflowHooks) > 0 {
 			postWorkflowHooks = append(postWorkflowHooks, repo.PostWorkflowHooks...)
 		}
@@ -52,18 +53,12 @@ func (w *DefaultPostWorkflowHooksCommandRunner) RunPostHooks(ctx *command.Contex
 
 	ctx.Log.Info("Post-workflow hooks configured, running...")
 
-	unlockFn, err := w.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, DefaultWorkspace, DefaultRepoRelDir)
+	repoDir, unlockFn, err := cloneWorkspace(ctx, w.WorkingDirLocker, w.WorkingDir, DefaultWorkspace)
 	if err != nil {
 		return err
 	}
-	ctx.Log.Debug("got workspace lock")
 	defer unlockFn()
 
-	repoDir, _, err := w.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, DefaultWorkspace)
-	if err != nil {
-		return err
-	}
-
 	var escapedArgs []string
 	if cmd != nil {
 		escapedArgs = escapeArgs(cmd.Flags)
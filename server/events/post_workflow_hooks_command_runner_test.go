@@ -163,10 +163,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPostWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand), Any[string](),
@@ -198,7 +198,7 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 		ctx.CommandHasErrors = true
 
 		expectedCtx := pCtx
@@ -206,8 +206,8 @@ func TestRunPostHooks_Clone(t *testing.T) {
 		expectedCtx.HookStepName = "post plan #0"
 		expectedCtx.HookDescription = "Post workflow hook #0"
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPostWorkflowHookRunner.Run(
@@ -248,7 +248,7 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
 		err := postWh.RunPostHooks(ctx, planCmd)
 
@@ -256,11 +256,11 @@ func TestRunPostHooks_Clone(t *testing.T) {
 
 		whPostWorkflowHookRunner.VerifyWasCalled(Never()).Run(Any[models.WorkflowHookCommandContext](),
 			Eq(testHook.RunCommand), Eq(defaultShell), Eq(defaultShellArgs), Eq(repoDir))
-		postWhWorkingDirLocker.VerifyWasCalled(Never()).TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace, "path")
+		postWhWorkingDirLocker.VerifyWasCalled(Never()).LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
 		postWhWorkingDir.VerifyWasCalled(Never()).Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))
 	})
-	t.Run("error locking work dir", func(t *testing.T) {
+	t.Run("write locks the work dir only while cloning", func(t *testing.T) {
 		postWorkflowHooksSetup(t)
 
 		globalCfg := valid.GlobalCfg{
@@ -274,17 +274,24 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(func() {}, errors.New("some error"))
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
+		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
+			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
+		When(whPostWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
+			Any[string](), Any[string](), Eq(repoDir))).ThenReturn(result, runtimeDesc, nil)
 
 		err := postWh.RunPostHooks(ctx, planCmd)
 
-		Assert(t, err != nil, "error not nil")
-		postWhWorkingDir.VerifyWasCalled(Never()).Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
+		Ok(t, err)
+		inOrder := new(InOrderContext)
+		postWhWorkingDirLocker.VerifyWasCalledInOrder(Once(), inOrder).LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
+		postWhWorkingDir.VerifyWasCalledInOrder(Once(), inOrder).Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))
-		whPostWorkflowHookRunner.VerifyWasCalled(Never()).Run(Any[models.WorkflowHookCommandContext](),
+		postWhWorkingDirLocker.VerifyWasCalledInOrder(Once(), inOrder).RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
+		whPostWorkflowHookRunner.VerifyWasCalledInOrder(Once(), inOrder).Run(Any[models.WorkflowHookCommandContext](),
 			Eq(testHook.RunCommand), Eq(defaultShell), Eq(defaultShellArgs), Eq(repoDir))
 	})
 
@@ -307,10 +314,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, errors.New("some error"))
 
@@ -342,10 +349,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPostWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
@@ -384,10 +391,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 		expectedCtx := pCtx
 		expectedCtx.EscapedCommentArgs = []string{"\\c\\o\\m\\m\\e\\n\\t", "\\a\\r\\g\\s"}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPostWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
@@ -420,10 +427,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPostWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithShell.RunCommand),
@@ -456,10 +463,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPostWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
@@ -492,10 +499,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		postWh.GlobalCfg = globalCfg
+		postWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(postWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(postWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(postWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPostWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithShellandShellArgs.RunCommand),
@@ -529,10 +536,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](),
@@ -565,10 +572,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithPlanCommand.RunCommand),
@@ -601,10 +608,10 @@ func TestRunPostHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithPlanApplyCommands.RunCommand),
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+)
+
+// PRMetadataVarsFilename is the tfvars file the pull request's metadata is
+// written to in the project's dir when the project sets
+// pr_metadata_vars: tfvars. Terraform loads it automatically.
+const PRMetadataVarsFilename = "atlantis_pr_metadata.auto.tfvars.json"
+
+// prMetadataVars returns the pull request's metadata as terraform variable
+// values keyed by variable name.
+func prMetadataVars(ctx command.ProjectContext, labels []string) map[string]interface{} {
+	if labels == nil {
+		labels = []string{}
+	}
+	return map[string]interface{}{
+		"atlantis_pull_num":     ctx.Pull.Num,
+		"atlantis_pull_url":     ctx.Pull.URL,
+		"atlantis_pull_author":  ctx.Pull.Author,
+		"atlantis_head_branch":  ctx.Pull.HeadBranch,
+		"atlantis_base_branch":  ctx.Pull.BaseBranch,
+		"atlantis_head_commit":  ctx.Pull.HeadCommit,
+		"atlantis_pull_labels":  labels,
+		"atlantis_repo":         ctx.BaseRepo.FullName,
+		"atlantis_project_name": ctx.ProjectName,
+	}
+}
+
+// addPRMetadataVars passes the pull request's metadata to terraform if the
+// project opted in, either by adding TF_VAR_ env vars to envs or by writing
+// PRMetadataVarsFilename to absPath. Terraform ignores env vars of undeclared
+// variables and only warns about undeclared values in tfvars files, so
+// modules only need to declare the variables they use.
+func addPRMetadataVars(ctx command.ProjectContext, vcsClient vcs.Client, absPath string, envs map[string]string) error {
+	if ctx.PRMetadataVars == "" {
+		return nil
+	}
+
+	var labels []string
+	if vcsClient != nil && !ctx.Pull.IsIssue {
+		var err error
+		labels, err = vcsClient.GetPullLabels(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull)
+		if err != nil {
+			ctx.Log.Warn("unable to get pull request labels for pr_metadata_vars: %s", err)
+		}
+	}
+	vars := prMetadataVars(ctx, labels)
+
+	if ctx.PRMetadataVars == valid.PRMetadataVarsTFVars {
+		contents, err := json.MarshalIndent(vars, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "encoding pull request metadata")
+		}
+		return errors.Wrap(os.WriteFile(filepath.Join(absPath, PRMetadataVarsFilename), contents, 0600), "writing pull request metadata")
+	}
+
+	for name, value := range vars {
+		switch v := value.(type) {
+		case string:
+			envs["TF_VAR_"+name] = v
+		case int:
+			envs["TF_VAR_"+name] = strconv.Itoa(v)
+		default:
+			// Lists are parsed as HCL which JSON is a subset of.
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return errors.Wrapf(err, "encoding %s", name)
+			}
+			envs["TF_VAR_"+name] = string(encoded)
+		}
+	}
+	return nil
+}
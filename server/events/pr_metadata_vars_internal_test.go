@@ -0,0 +1,84 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func prMetadataVarsCtx(t *testing.T, mode string) command.ProjectContext {
+	repo := models.Repo{FullName: "owner/repo"}
+	return command.ProjectContext{
+		Log:            logging.NewNoopLogger(t),
+		BaseRepo:       repo,
+		ProjectName:    "network",
+		PRMetadataVars: mode,
+		Pull: models.PullRequest{
+			Num:        42,
+			URL:        "https://github.com/owner/repo/pull/42",
+			Author:     "alice",
+			HeadBranch: "feature",
+			BaseBranch: "main",
+			HeadCommit: "abc123",
+			BaseRepo:   repo,
+		},
+	}
+}
+
+func TestAddPRMetadataVars_Env(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := prMetadataVarsCtx(t, valid.PRMetadataVarsEnv)
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetPullLabels(Any[logging.SimpleLogging](), Eq(ctx.Pull.BaseRepo), Eq(ctx.Pull))).ThenReturn([]string{"team:network", "urgent"}, nil)
+
+	envs := map[string]string{}
+	Ok(t, addPRMetadataVars(ctx, vcsClient, t.TempDir(), envs))
+	Equals(t, "42", envs["TF_VAR_atlantis_pull_num"])
+	Equals(t, "alice", envs["TF_VAR_atlantis_pull_author"])
+	Equals(t, "feature", envs["TF_VAR_atlantis_head_branch"])
+	Equals(t, `["team:network","urgent"]`, envs["TF_VAR_atlantis_pull_labels"])
+}
+
+func TestAddPRMetadataVars_TFVars(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := prMetadataVarsCtx(t, valid.PRMetadataVarsTFVars)
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetPullLabels(Any[logging.SimpleLogging](), Eq(ctx.Pull.BaseRepo), Eq(ctx.Pull))).ThenReturn(nil, nil)
+
+	dir := t.TempDir()
+	envs := map[string]string{}
+	Ok(t, addPRMetadataVars(ctx, vcsClient, dir, envs))
+	Equals(t, 0, len(envs))
+
+	contents, err := os.ReadFile(filepath.Join(dir, PRMetadataVarsFilename))
+	Ok(t, err)
+	var vars map[string]interface{}
+	Ok(t, json.Unmarshal(contents, &vars))
+	Equals(t, float64(42), vars["atlantis_pull_num"])
+	Equals(t, "main", vars["atlantis_base_branch"])
+	Equals(t, []interface{}{}, vars["atlantis_pull_labels"])
+}
+
+// Projects that didn't opt in don't get any variables.
+func TestAddPRMetadataVars_Disabled(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := prMetadataVarsCtx(t, "")
+	vcsClient := vcsmocks.NewMockClient()
+
+	dir := t.TempDir()
+	envs := map[string]string{}
+	Ok(t, addPRMetadataVars(ctx, vcsClient, dir, envs))
+	Equals(t, 0, len(envs))
+	_, err := os.Stat(filepath.Join(dir, PRMetadataVarsFilename))
+	Assert(t, os.IsNotExist(err), "exp no tfvars file")
+	vcsClient.VerifyWasCalled(Never()).GetPullLabels(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest]())
+}
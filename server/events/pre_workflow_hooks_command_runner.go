@@ -30,7 +30,7 @@ type DefaultPreWorkflowHooksCommandRunner struct {
 	VCSClient             vcs.Client                    `validate:"required"`
 	WorkingDirLocker      WorkingDirLocker              `validate:"required"`
 	WorkingDir            WorkingDir                    `validate:"required"`
-	GlobalCfg             valid.GlobalCfg               `validate:"required"`
+	GlobalCfgStore        *valid.GlobalCfgStore         `validate:"required"`
 	PreWorkflowHookRunner runtime.PreWorkflowHookRunner `validate:"required"`
 	CommitStatusUpdater   CommitStatusUpdater           `validate:"required"`
 	Router                PreWorkflowHookURLGenerator   `validate:"required"`
@@ -39,7 +39,8 @@ type DefaultPreWorkflowHooksCommandRunner struct {
 // RunPreHooks runs pre_workflow_hooks when PR is opened or updated.
 func (w *DefaultPreWorkflowHooksCommandRunner) RunPreHooks(ctx *command.Context, cmd *CommentCommand) error {
 	preWorkflowHooks := make([]*valid.WorkflowHook, 0)
-	for _, repo := range w.GlobalCfg.Repos {
+	globalCfg := w.GlobalCfgStore.Load()
+	for _, repo := range globalCfg.Repos {
 		if repo.IDMatches(ctx.Pull.BaseRepo.ID()) && len(repo.PreWorkflowHooks) > 0 {
 			preWorkflowHooks = append(preWorkflowHooks, repo.PreWorkflowHooks...)
 		}
@@ -52,23 +53,21 @@ func (w *DefaultPreWorkflowHooksCommandRunner) RunPreHooks(ctx *command.Context,
 
 	ctx.Log.Info("Pre-workflow hooks configured, running...")
 
-	unlockFn, err := w.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, DefaultWorkspace, DefaultRepoRelDir)
+	repoDir, unlockFn, err := cloneWorkspace(ctx, w.WorkingDirLocker, w.WorkingDir, DefaultWorkspace)
 	if err != nil {
 		return err
 	}
-	ctx.Log.Debug("got workspace lock")
 	defer unlockFn()
 
-	repoDir, _, err := w.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, DefaultWorkspace)
-	if err != nil {
-		return err
-	}
-
 	var escapedArgs []string
 	if cmd != nil {
 		escapedArgs = escapeArgs(cmd.Flags)
 	}
 
+	if err := runtime.ClearWorkflowHookOutputVars(repoDir); err != nil {
+		return err
+	}
+
 	err = w.runHooks(
 		models.WorkflowHookCommandContext{
 			BaseRepo:           ctx.Pull.BaseRepo,
@@ -88,6 +87,12 @@ func (w *DefaultPreWorkflowHooksCommandRunner) RunPreHooks(ctx *command.Context,
 		return err
 	}
 
+	ctx.HookOutputVars, err = runtime.ReadWorkflowHookOutputVars(repoDir)
+	if err != nil {
+		ctx.Log.Err("Error reading output variables of pre-workflow hooks %s.", err)
+		return err
+	}
+
 	ctx.Log.Info("Pre-workflow hooks completed successfully")
 
 	return nil
@@ -140,12 +145,17 @@ func (w *DefaultPreWorkflowHooksCommandRunner) runHooks(
 			}
 		}
 
-		_, runtimeDesc, err := w.PreWorkflowHookRunner.Run(ctx, hook.RunCommand, shell, shellArgs, repoDir)
+		out, runtimeDesc, err := w.PreWorkflowHookRunner.Run(ctx, hook.RunCommand, shell, shellArgs, repoDir)
 
 		if err != nil {
 			if err := w.CommitStatusUpdater.UpdatePreWorkflowHook(ctx.Log, ctx.Pull, models.FailedCommitStatus, ctx.HookDescription, runtimeDesc, url); err != nil {
 				ctx.Log.Warn("unable to update pre workflow hook status: %s", err)
 			}
+			if hook.CommentOnFailure {
+				if err := w.VCSClient.CreateComment(ctx.Log, ctx.BaseRepo, ctx.Pull.Num, preWorkflowHookFailedComment(ctx.HookDescription, out, url), ctx.CommandName); err != nil {
+					ctx.Log.Warn("unable to comment on pull request: %s", err)
+				}
+			}
 			return err
 		}
 
@@ -159,3 +169,13 @@ func (w *DefaultPreWorkflowHooksCommandRunner) runHooks(
 
 	return nil
 }
+
+// preWorkflowHookFailedComment returns the comment posted when the hook
+// described by description fails with out. url is the job log of the hook.
+func preWorkflowHookFailedComment(description string, out string, url string) string {
+	comment := fmt.Sprintf("**Pre-workflow hook failed:** %s\n\n```\n%s\n```", description, strings.TrimSpace(out))
+	if url != "" {
+		comment += fmt.Sprintf("\n\nSee the [job log](%s) for details.", url)
+	}
+	return comment
+}
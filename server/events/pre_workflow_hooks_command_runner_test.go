@@ -2,10 +2,13 @@ package events_test
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/runtime"
 	runtime_mocks "github.com/runatlantis/atlantis/server/core/runtime/mocks"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
@@ -22,10 +25,11 @@ var preWhWorkingDir *mocks.MockWorkingDir
 var preWhWorkingDirLocker *mocks.MockWorkingDirLocker
 var whPreWorkflowHookRunner *runtime_mocks.MockPreWorkflowHookRunner
 var preCommitStatusUpdater *mocks.MockCommitStatusUpdater
+var preWhVCSClient *vcsmocks.MockClient
 
 func preWorkflowHooksSetup(t *testing.T) {
 	RegisterMockTestingT(t)
-	vcsClient := vcsmocks.NewMockClient()
+	preWhVCSClient = vcsmocks.NewMockClient()
 	preWhWorkingDir = mocks.NewMockWorkingDir()
 	preWhWorkingDirLocker = mocks.NewMockWorkingDirLocker()
 	whPreWorkflowHookRunner = runtime_mocks.NewMockPreWorkflowHookRunner()
@@ -33,7 +37,7 @@ func preWorkflowHooksSetup(t *testing.T) {
 	preWorkflowHookURLGenerator := mocks.NewMockPreWorkflowHookURLGenerator()
 
 	preWh = events.DefaultPreWorkflowHooksCommandRunner{
-		VCSClient:             vcsClient,
+		VCSClient:             preWhVCSClient,
 		WorkingDirLocker:      preWhWorkingDirLocker,
 		WorkingDir:            preWhWorkingDir,
 		PreWorkflowHookRunner: whPreWorkflowHookRunner,
@@ -140,10 +144,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
@@ -176,7 +180,7 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
 		err := preWh.RunPreHooks(ctx, planCmd)
 
@@ -184,12 +188,12 @@ func TestRunPreHooks_Clone(t *testing.T) {
 
 		whPreWorkflowHookRunner.VerifyWasCalled(Never()).Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
 			Eq(defaultShell), Eq(defaultShellArgs), Eq(repoDir))
-		preWhWorkingDirLocker.VerifyWasCalled(Never()).TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace, "")
+		preWhWorkingDirLocker.VerifyWasCalled(Never()).LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
 		preWhWorkingDir.VerifyWasCalled(Never()).Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))
 	})
 
-	t.Run("error locking work dir", func(t *testing.T) {
+	t.Run("write locks the work dir only while cloning", func(t *testing.T) {
 		preWorkflowHooksSetup(t)
 
 		globalCfg := valid.GlobalCfg{
@@ -203,18 +207,25 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(func() {}, errors.New("some error"))
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
+		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
+			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
+		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
+			Any[string](), Any[string](), Eq(repoDir))).ThenReturn(result, runtimeDesc, nil)
 
 		err := preWh.RunPreHooks(ctx, planCmd)
 
-		Assert(t, err != nil, "error not nil")
-		preWhWorkingDir.VerifyWasCalled(Never()).Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
+		Ok(t, err)
+		inOrder := new(InOrderContext)
+		preWhWorkingDirLocker.VerifyWasCalledInOrder(Once(), inOrder).LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
+		preWhWorkingDir.VerifyWasCalledInOrder(Once(), inOrder).Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))
-		whPreWorkflowHookRunner.VerifyWasCalled(Never()).Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
-			Eq(defaultShell), Eq(defaultShellArgs), Eq(repoDir))
+		preWhWorkingDirLocker.VerifyWasCalledInOrder(Once(), inOrder).RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
+		whPreWorkflowHookRunner.VerifyWasCalledInOrder(Once(), inOrder).Run(Any[models.WorkflowHookCommandContext](),
+			Eq(testHook.RunCommand), Eq(defaultShell), Eq(defaultShellArgs), Eq(repoDir))
 	})
 
 	t.Run("error cloning", func(t *testing.T) {
@@ -236,10 +247,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, errors.New("some error"))
 
@@ -271,10 +282,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
@@ -313,10 +324,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 		expectedCtx := pCtx
 		expectedCtx.EscapedCommentArgs = []string{"\\c\\o\\m\\m\\e\\n\\t", "\\a\\r\\g\\s"}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand), Any[string](),
@@ -349,10 +360,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithShell.RunCommand),
@@ -385,10 +396,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHook.RunCommand),
@@ -421,10 +432,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithShellandShellArgs.RunCommand),
@@ -458,10 +469,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithPlanCommand.RunCommand),
@@ -494,10 +505,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithPlanCommand.RunCommand),
@@ -530,10 +541,10 @@ func TestRunPreHooks_Clone(t *testing.T) {
 			},
 		}
 
-		preWh.GlobalCfg = globalCfg
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(globalCfg)
 
-		When(preWhWorkingDirLocker.TryLock(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace,
-			events.DefaultRepoRelDir)).ThenReturn(unlockFn, nil)
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn)
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
 		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
 			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
 		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(testHookWithPlanApplyCommands.RunCommand),
@@ -547,3 +558,80 @@ func TestRunPreHooks_Clone(t *testing.T) {
 		Assert(t, *unlockCalled == true, "unlock function called")
 	})
 }
+
+func TestRunPreHooks_CommentOnFailure(t *testing.T) {
+	log := logging.NewNoopLogger(t)
+	newPull := testdata.Pull
+	newPull.BaseRepo = testdata.GithubRepo
+	ctx := &command.Context{
+		Pull:     newPull,
+		HeadRepo: testdata.GithubRepo,
+		User:     testdata.User,
+		Log:      log,
+	}
+	planCmd := &events.CommentCommand{Name: command.Plan}
+	repoDir := t.TempDir()
+
+	for _, commentOnFailure := range []bool{false, true} {
+		preWorkflowHooksSetup(t)
+		hook := valid.WorkflowHook{
+			StepName:         "test",
+			RunCommand:       "generate-backend",
+			StepDescription:  "Generate backend config",
+			CommentOnFailure: commentOnFailure,
+		}
+		preWh.GlobalCfgStore = valid.NewGlobalCfgStore(valid.GlobalCfg{
+			Repos: []valid.Repo{{ID: testdata.GithubRepo.ID(), PreWorkflowHooks: []*valid.WorkflowHook{&hook}}},
+		})
+		When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
+		When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
+		When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
+			Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
+		When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(hook.RunCommand),
+			Any[string](), Any[string](), Eq(repoDir))).ThenReturn("missing bucket name\n", "", errors.New("exit status 1"))
+
+		err := preWh.RunPreHooks(ctx, planCmd)
+
+		ErrEquals(t, "exit status 1", err)
+		times := Never()
+		if commentOnFailure {
+			times = Once()
+		}
+		preWhVCSClient.VerifyWasCalled(times).CreateComment(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull.Num),
+			Eq("**Pre-workflow hook failed:** Generate backend config\n\n```\nmissing bucket name\n```"), Eq("plan"))
+	}
+}
+
+func TestRunPreHooks_OutputVars(t *testing.T) {
+	preWorkflowHooksSetup(t)
+	newPull := testdata.Pull
+	newPull.BaseRepo = testdata.GithubRepo
+	ctx := &command.Context{
+		Pull:     newPull,
+		HeadRepo: testdata.GithubRepo,
+		User:     testdata.User,
+		Log:      logging.NewNoopLogger(t),
+	}
+	repoDir := t.TempDir()
+	// Variables of a previous command aren't kept.
+	Ok(t, os.WriteFile(filepath.Join(repoDir, runtime.WorkflowHookOutputVarsFile), []byte("STALE=true\n"), 0600))
+
+	hook := valid.WorkflowHook{StepName: "test", RunCommand: "generate-backend"}
+	preWh.GlobalCfgStore = valid.NewGlobalCfgStore(valid.GlobalCfg{
+		Repos: []valid.Repo{{ID: testdata.GithubRepo.ID(), PreWorkflowHooks: []*valid.WorkflowHook{&hook}}},
+	})
+	When(preWhWorkingDirLocker.LockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
+	When(preWhWorkingDirLocker.RLockWorkspace(testdata.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {})
+	When(preWhWorkingDir.Clone(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(newPull),
+		Eq(events.DefaultWorkspace))).ThenReturn(repoDir, false, nil)
+	When(whPreWorkflowHookRunner.Run(Any[models.WorkflowHookCommandContext](), Eq(hook.RunCommand),
+		Any[string](), Any[string](), Eq(repoDir))).Then(func(params []Param) ReturnValues {
+		err := os.WriteFile(filepath.Join(repoDir, runtime.WorkflowHookOutputVarsFile), []byte("# backend\nBUCKET=tf-state\n"), 0600)
+		return ReturnValues{"", "", err}
+	})
+
+	err := preWh.RunPreHooks(ctx, &events.CommentCommand{Name: command.Plan})
+
+	Ok(t, err)
+	Equals(t, map[string]string{"BUCKET": "tf-state"}, ctx.HookOutputVars)
+}
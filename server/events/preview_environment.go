@@ -0,0 +1,139 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// projectCommentCommand returns the command named name that Atlantis runs
+// on its own on a single project. Projects without a name are identified by
+// their dir and workspace.
+func projectCommentCommand(name command.Name, projectName string, repoRelDir string, workspace string) *CommentCommand {
+	cmd := &CommentCommand{
+		Name:              name,
+		ProjectName:       projectName,
+		AutoMergeDisabled: true,
+	}
+	if projectName == "" {
+		cmd.RepoRelDir = repoRelDir
+		cmd.Workspace = workspace
+	}
+	return cmd
+}
+
+// skipReviewRequirements drops the plan and apply requirements of
+// projectCmds that need a review if cmd deploys preview environments, which
+// are deployed as soon as they're planned, or destroys the workspaces of a
+// closed pull request, which can't be reviewed anymore. Their policies must
+// still pass.
+func skipReviewRequirements(cmd *CommentCommand, projectCmds []command.ProjectContext) {
+	if !cmd.previewEnvironment && !cmd.destroyOnClose {
+		return
+	}
+	for i := range projectCmds {
+		if projectCmds[i].PreviewEnvironment == nil && !cmd.destroyOnClose {
+			continue
+		}
+		projectCmds[i].PlanRequirements = nil
+		projectCmds[i].ApplyRequirements = slices.DeleteFunc(slices.Clone(projectCmds[i].ApplyRequirements), func(req string) bool {
+			return req != valid.PoliciesPassedCommandReq
+		})
+	}
+}
+
+// deployPreviewEnvironments applies the plans of the preview environments of
+// projectCmds that were planned successfully.
+func (p *PlanCommandRunner) deployPreviewEnvironments(ctx *command.Context, projectCmds []command.ProjectContext, result command.Result) {
+	if p.PreviewEnvironmentDeployer == nil || result.PlansDeleted {
+		return
+	}
+	var deploy []command.ProjectContext
+	for _, projectCmd := range projectCmds {
+		if projectCmd.PreviewEnvironment == nil {
+			continue
+		}
+		for _, r := range result.ProjectResults {
+			if r.RepoRelDir == projectCmd.RepoRelDir && r.Workspace == projectCmd.Workspace && r.ProjectName == projectCmd.ProjectName && r.PlanSuccess != nil {
+				deploy = append(deploy, projectCmd)
+			}
+		}
+	}
+	if len(deploy) == 0 {
+		return
+	}
+
+	// The plans and policy checks were written to the DB since ctx was
+	// built.
+	pullStatus, err := p.pullStatusFetcher.GetPullStatus(ctx.Pull)
+	if err != nil {
+		ctx.Log.Err("fetching pull status to deploy preview environments: %s", err)
+		return
+	}
+	ctx.PullStatus = pullStatus
+	for _, projectCmd := range deploy {
+		ctx.Log.Info("deploying the preview environment at dir '%s' workspace '%s'", projectCmd.RepoRelDir, projectCmd.Workspace)
+		applyCmd := projectCommentCommand(command.Apply, projectCmd.ProjectName, projectCmd.RepoRelDir, projectCmd.Workspace)
+		applyCmd.previewEnvironment = true
+		p.PreviewEnvironmentDeployer.Run(ctx, applyCmd)
+	}
+}
+
+// previewEnvironmentAnnotations returns the URL of the preview environment
+// of ctx, read from its URL output after it was applied, as an annotation of
+// the apply result. Errors are logged since the apply succeeded.
+func (p *DefaultProjectCommandRunner) previewEnvironmentAnnotations(ctx command.ProjectContext) []string {
+	if ctx.PreviewEnvironment == nil || ctx.PreviewEnvironment.URLOutput == "" || p.OutputStepRunner == nil || !valid.UsesTerraform(ctx.Tool) {
+		return nil
+	}
+	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
+	if err != nil {
+		ctx.Log.Err("finding preview environment URL: %s", err)
+		return nil
+	}
+	out, err := p.OutputStepRunner.Run(ctx, nil, filepath.Join(repoDir, ctx.RepoRelDir), map[string]string{})
+	if err != nil {
+		ctx.Log.Err("finding preview environment URL: %s", err)
+		return nil
+	}
+	url, ok, err := previewEnvironmentURL(out, ctx.PreviewEnvironment.URLOutput)
+	if err != nil {
+		ctx.Log.Err("finding preview environment URL: %s", err)
+		return nil
+	}
+	if !ok {
+		// Destroyed environments don't have outputs.
+		ctx.Log.Debug("preview environment has no output %q", ctx.PreviewEnvironment.URLOutput)
+		return nil
+	}
+	return []string{fmt.Sprintf("**Preview environment:** %s", url)}
+}
+
+// previewEnvironmentURL returns the value of the output named name in
+// outputsJSON, the output of terraform output -json. It returns false if
+// there is no such output.
+func previewEnvironmentURL(outputsJSON string, name string) (string, bool, error) {
+	var outputs map[string]struct {
+		Sensitive bool            `json:"sensitive"`
+		Value     json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(outputsJSON), &outputs); err != nil {
+		return "", false, fmt.Errorf("parsing outputs: %w", err)
+	}
+	output, ok := outputs[name]
+	if !ok {
+		return "", false, nil
+	}
+	if output.Sensitive {
+		return "", false, fmt.Errorf("output %q is sensitive", name)
+	}
+	var url string
+	if err := json.Unmarshal(output.Value, &url); err != nil {
+		return "", false, fmt.Errorf("output %q isn't a string", name)
+	}
+	return url, true, nil
+}
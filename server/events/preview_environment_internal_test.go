@@ -0,0 +1,66 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPreviewEnvironmentURL(t *testing.T) {
+	outputs := `{
+  "url": {"sensitive": false, "type": "string", "value": "https://pr-12.preview.example.com"},
+  "token": {"sensitive": true, "type": "string", "value": "secret"},
+  "ports": {"sensitive": false, "type": ["list", "number"], "value": [80, 443]}
+}`
+
+	url, ok, err := previewEnvironmentURL(outputs, "url")
+	Ok(t, err)
+	Equals(t, true, ok)
+	Equals(t, "https://pr-12.preview.example.com", url)
+
+	_, ok, err = previewEnvironmentURL(outputs, "missing")
+	Ok(t, err)
+	Equals(t, false, ok)
+
+	// Destroyed environments don't have any outputs.
+	_, ok, err = previewEnvironmentURL("{}", "url")
+	Ok(t, err)
+	Equals(t, false, ok)
+
+	_, _, err = previewEnvironmentURL(outputs, "token")
+	ErrEquals(t, `output "token" is sensitive`, err)
+
+	_, _, err = previewEnvironmentURL(outputs, "ports")
+	ErrEquals(t, `output "ports" isn't a string`, err)
+}
+
+func TestSkipReviewRequirements(t *testing.T) {
+	requirements := []string{"approved", valid.PoliciesPassedCommandReq, "mergeable"}
+	projectCmds := []command.ProjectContext{
+		{
+			PreviewEnvironment: &valid.PreviewEnvironment{},
+			PlanRequirements:   []string{"approved"},
+			ApplyRequirements:  requirements,
+		},
+		{
+			PlanRequirements:  []string{"approved"},
+			ApplyRequirements: requirements,
+		},
+	}
+	skipReviewRequirements(&CommentCommand{}, projectCmds)
+	Equals(t, []string{"approved"}, projectCmds[0].PlanRequirements)
+
+	skipReviewRequirements(&CommentCommand{previewEnvironment: true}, projectCmds)
+	Equals(t, 0, len(projectCmds[0].PlanRequirements))
+	Equals(t, []string{valid.PoliciesPassedCommandReq}, projectCmds[0].ApplyRequirements)
+	Equals(t, []string{"approved"}, projectCmds[1].PlanRequirements)
+	Equals(t, []string{"approved", valid.PoliciesPassedCommandReq, "mergeable"}, projectCmds[1].ApplyRequirements)
+
+	// Closed pull requests can't be reviewed so any project is destroyed
+	// without review.
+	skipReviewRequirements(&CommentCommand{destroyOnClose: true}, projectCmds)
+	Equals(t, 0, len(projectCmds[1].PlanRequirements))
+	Equals(t, []string{valid.PoliciesPassedCommandReq}, projectCmds[1].ApplyRequirements)
+}
@@ -7,6 +7,7 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"text/template"
 
 	tally "github.com/uber-go/tally/v4"
 
@@ -44,7 +45,7 @@ func NewInstrumentedProjectCommandBuilder(
 	vcsClient vcs.Client,
 	workingDir WorkingDir,
 	workingDirLocker WorkingDirLocker,
-	globalCfg valid.GlobalCfg,
+	globalCfgStore *valid.GlobalCfgStore,
 	pendingPlanFinder *DefaultPendingPlanFinder,
 	commentBuilder CommentBuilder,
 	skipCloneNoChanges bool,
@@ -75,7 +76,7 @@ func NewInstrumentedProjectCommandBuilder(
 			vcsClient,
 			workingDir,
 			workingDirLocker,
-			globalCfg,
+			globalCfgStore,
 			pendingPlanFinder,
 			commentBuilder,
 			skipCloneNoChanges,
@@ -104,7 +105,7 @@ func NewProjectCommandBuilder(
 	vcsClient vcs.Client,
 	workingDir WorkingDir,
 	workingDirLocker WorkingDirLocker,
-	globalCfg valid.GlobalCfg,
+	globalCfgStore *valid.GlobalCfgStore,
 	pendingPlanFinder *DefaultPendingPlanFinder,
 	commentBuilder CommentBuilder,
 	skipCloneNoChanges bool,
@@ -127,7 +128,7 @@ func NewProjectCommandBuilder(
 		VCSClient:                vcsClient,
 		WorkingDir:               workingDir,
 		WorkingDirLocker:         workingDirLocker,
-		GlobalCfg:                globalCfg,
+		GlobalCfgStore:           globalCfgStore,
 		PendingPlanFinder:        pendingPlanFinder,
 		SkipCloneNoChanges:       skipCloneNoChanges,
 		EnableRegExpCmd:          EnableRegExpCmd,
@@ -190,6 +191,21 @@ type ProjectStateCommandBuilder interface {
 	// comment doesn't specify one project then there may be multiple commands
 	// to be run.
 	BuildStateRmCommands(ctx *command.Context, comment *CommentCommand) ([]command.ProjectContext, error)
+	// BuildStateReadCommands builds project state list and state show
+	// commands for this ctx and comment. If comment doesn't specify one
+	// project then there may be multiple commands to be run.
+	BuildStateReadCommands(ctx *command.Context, comment *CommentCommand) ([]command.ProjectContext, error)
+	// BuildForceUnlockStateCommands builds project force-unlock-state commands
+	// for this ctx and comment. If comment doesn't specify one project then
+	// there may be multiple commands to be run.
+	BuildForceUnlockStateCommands(ctx *command.Context, comment *CommentCommand) ([]command.ProjectContext, error)
+}
+
+type ProjectRollbackCommandBuilder interface {
+	// BuildRollbackCommands builds project rollback commands for this ctx and
+	// comment. If comment doesn't specify one project then there may be
+	// multiple commands to be run.
+	BuildRollbackCommands(ctx *command.Context, comment *CommentCommand) ([]command.ProjectContext, error)
 }
 
 //go:generate pegomock generate github.com/runatlantis/atlantis/server/events --package mocks -o mocks/mock_project_command_builder.go ProjectCommandBuilder
@@ -202,6 +218,7 @@ type ProjectCommandBuilder interface {
 	ProjectVersionCommandBuilder
 	ProjectImportCommandBuilder
 	ProjectStateCommandBuilder
+	ProjectRollbackCommandBuilder
 }
 
 // DefaultProjectCommandBuilder implements ProjectCommandBuilder.
@@ -218,8 +235,8 @@ type DefaultProjectCommandBuilder struct {
 	WorkingDir WorkingDir
 	// Used to prevent multiple commands from executing at the same time for a single repo, pull, and workspace.
 	WorkingDirLocker WorkingDirLocker
-	// The final parsed version of the server-side repo config.
-	GlobalCfg valid.GlobalCfg
+	// Holds the final parsed version of the server-side repo config.
+	GlobalCfgStore *valid.GlobalCfgStore
 	// Finds unapplied plans.
 	PendingPlanFinder *DefaultPendingPlanFinder
 	// Builds project command contexts for Atlantis commands.
@@ -250,6 +267,12 @@ type DefaultProjectCommandBuilder struct {
 	AutoDiscoverMode string
 	// Handles the actual running of Terraform commands.
 	TerraformExecutor tfclient.Client
+	// User config option: Names projects that don't have a name in the repo
+	// config. If nil, they're identified by their dir and workspace.
+	ProjectNameTemplate *template.Template
+	// RepoCfgSuggestionStore records the pull requests an atlantis.yaml was
+	// suggested on. If nil, atlantis.yaml files aren't suggested.
+	RepoCfgSuggestionStore RepoCfgSuggestionStore
 }
 
 // See ProjectCommandBuilder.BuildAutoplanCommands.
@@ -269,23 +292,87 @@ func (p *DefaultProjectCommandBuilder) BuildAutoplanCommands(ctx *command.Contex
 	return autoplanEnabled, nil
 }
 
+// globalCfg returns the current server-side repo config.
+func (p *DefaultProjectCommandBuilder) globalCfg() valid.GlobalCfg {
+	return p.GlobalCfgStore.Load()
+}
+
 // See ProjectCommandBuilder.BuildPlanCommands.
 func (p *DefaultProjectCommandBuilder) BuildPlanCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
 	if !cmd.IsForSpecificProject() {
 		ctx.Log.Debug("Building plan command for all affected projects")
-		return p.buildAllCommandsByCfg(ctx, cmd.CommandName(), cmd.SubName, cmd.Flags, cmd.Verbose)
+		projCtxs, err := p.buildAllCommandsByCfg(ctx, cmd.CommandName(), cmd.SubName, cmd.Flags, cmd.Verbose)
+		if err != nil {
+			return projCtxs, err
+		}
+		return p.withTargets(ctx, projCtxs, cmd.Targets)
+	}
+	if len(cmd.Labels) > 0 {
+		projCtxs, err := p.buildPlanCommandsByLabels(ctx, cmd)
+		if err != nil {
+			return projCtxs, err
+		}
+		return p.withTargets(ctx, projCtxs, cmd.Targets)
 	}
 	ctx.Log.Debug("Building plan command for specific project with directory: '%v', workspace: '%v', project: '%v'",
 		cmd.RepoRelDir, cmd.Workspace, cmd.ProjectName)
-	return p.buildProjectPlanCommand(ctx, cmd)
+	projCtxs, err := p.buildProjectPlanCommand(ctx, cmd)
+	if err != nil {
+		return projCtxs, err
+	}
+	return p.withTargets(ctx, projCtxs, cmd.Targets)
 }
 
 // See ProjectCommandBuilder.BuildApplyCommands.
 func (p *DefaultProjectCommandBuilder) BuildApplyCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
-	if !cmd.IsForSpecificProject() {
-		return p.buildAllProjectCommandsByPlan(ctx, cmd)
+	var projCtxs []command.ProjectContext
+	var err error
+	if len(cmd.Labels) > 0 {
+		projCtxs, err = p.buildAllProjectCommandsByPlan(ctx, cmd)
+		projCtxs = withLabels(projCtxs, cmd.Labels)
+	} else if !cmd.IsForSpecificProject() {
+		projCtxs, err = p.buildAllProjectCommandsByPlan(ctx, cmd)
+	} else {
+		projCtxs, err = p.buildProjectCommand(ctx, cmd)
 	}
-	return p.buildProjectCommand(ctx, cmd)
+	if err != nil {
+		return projCtxs, err
+	}
+	return p.withTargets(ctx, projCtxs, cmd.Targets)
+}
+
+// withTargets limits the project commands to the resource addresses passed
+// with --target. Targeting must be enabled with allow_target in the
+// server-side repo config because a targeted apply leaves the rest of the
+// project unapplied. Addresses are only meaningful within one project so
+// targets can't be used with commands that run on several projects.
+func (p *DefaultProjectCommandBuilder) withTargets(ctx *command.Context, projCtxs []command.ProjectContext, targets []string) ([]command.ProjectContext, error) {
+	if len(targets) == 0 {
+		return projCtxs, nil
+	}
+	if !p.globalCfg().AllowTarget(ctx.Pull.BaseRepo.ID()) {
+		return nil, fmt.Errorf("--target is not allowed for this repo: server-side config needs '%s: true'", valid.AllowTargetKey)
+	}
+	if len(projCtxs) > 1 {
+		return nil, errors.New("--target cannot be used on multiple projects. please specify one project")
+	}
+	var targetFlags string
+	for _, target := range targets {
+		// Single quote the targets since addresses can contain double quotes,
+		// ex. module.app["blue"].
+		targetFlags += fmt.Sprintf(" --target '%s'", target)
+	}
+	for i := range projCtxs {
+		projCtxs[i].Targets = targets
+		// The targets are flags of the comment so they go before any extra
+		// args passed to Terraform after --.
+		replan, extraArgs, found := strings.Cut(projCtxs[i].RePlanCmd, " -- ")
+		projCtxs[i].RePlanCmd = replan + targetFlags
+		if found {
+			projCtxs[i].RePlanCmd += " -- " + extraArgs
+		}
+	}
+	return projCtxs, nil
 }
 
 func (p *DefaultProjectCommandBuilder) BuildApprovePoliciesCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
@@ -318,6 +405,40 @@ func (p *DefaultProjectCommandBuilder) BuildStateRmCommands(ctx *command.Context
 	return p.buildProjectCommand(ctx, cmd)
 }
 
+// BuildStateReadCommands builds the commands for state list and state show.
+// Reading the state can reveal values that aren't in the plan output so it
+// must be enabled with allow_state_read in the server-side repo config.
+func (p *DefaultProjectCommandBuilder) BuildStateReadCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
+	if !p.globalCfg().AllowStateRead(ctx.Pull.BaseRepo.ID()) {
+		return nil, fmt.Errorf("state %s is not allowed for this repo: server-side config needs '%s: true'", cmd.SubName, valid.AllowStateReadKey)
+	}
+	if !cmd.IsForSpecificProject() {
+		// Reading the state doesn't need a plan so use buildAllCommandsByCfg.
+		return p.buildAllCommandsByCfg(ctx, cmd.CommandName(), cmd.SubName, cmd.Flags, cmd.Verbose)
+	}
+	return p.buildProjectCommand(ctx, cmd)
+}
+
+func (p *DefaultProjectCommandBuilder) BuildForceUnlockStateCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
+	if !cmd.IsForSpecificProject() {
+		return p.buildAllCommandsByCfg(ctx, cmd.CommandName(), cmd.SubName, cmd.Flags, cmd.Verbose)
+	}
+	return p.buildProjectCommand(ctx, cmd)
+}
+
+// BuildRollbackCommands builds the commands for rollback. Rolling back must be
+// allowed with rollback in the server-side repo config.
+func (p *DefaultProjectCommandBuilder) BuildRollbackCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
+	if p.globalCfg().Rollback(ctx.Pull.BaseRepo.ID()) == nil {
+		return nil, errors.New("rollback is not allowed for this repo: server-side config needs 'rollback'")
+	}
+	if !cmd.IsForSpecificProject() {
+		// rollback replaces a plan file, so use buildAllCommandsByCfg instead buildAllProjectCommandsByPlan.
+		return p.buildAllCommandsByCfg(ctx, cmd.CommandName(), cmd.SubName, cmd.Flags, cmd.Verbose)
+	}
+	return p.buildProjectCommand(ctx, cmd)
+}
+
 // shouldSkipClone determines whether we should skip cloning for a given context
 func (p *DefaultProjectCommandBuilder) shouldSkipClone(ctx *command.Context, modifiedFiles []string) (bool, error) {
 	// NOTE: We discard this work here and end up doing it again after
@@ -327,21 +448,35 @@ func (p *DefaultProjectCommandBuilder) shouldSkipClone(ctx *command.Context, mod
 	if !p.SkipCloneNoChanges || !p.VCSClient.SupportsSingleFileDownload(ctx.Pull.BaseRepo) {
 		return false, nil
 	}
-	repoCfgFile := p.GlobalCfg.RepoConfigFile(ctx.Pull.BaseRepo.ID())
-	hasRepoCfg, repoCfgData, err := p.VCSClient.GetFileContent(ctx.Log, ctx.Pull, repoCfgFile)
-	if err != nil {
-		return false, errors.Wrapf(err, "downloading %s", repoCfgFile)
+	var repoCfgFile string
+	var hasRepoCfg bool
+	var repoCfgData []byte
+	for _, repoCfgFile = range p.globalCfg().RepoConfigFiles(ctx.Pull.BaseRepo.ID()) {
+		var err error
+		hasRepoCfg, repoCfgData, err = p.VCSClient.GetFileContent(ctx.Log, ctx.Pull, repoCfgFile)
+		if err != nil {
+			return false, errors.Wrapf(err, "downloading %s", repoCfgFile)
+		}
+		if hasRepoCfg {
+			break
+		}
 	}
 	// We can only skip if we determine that none of the modified files belong to projects configured in a repo config
 	if !hasRepoCfg {
 		return false, nil
 	}
-	repoCfg, err := p.ParserValidator.ParseRepoCfgData(repoCfgData, p.GlobalCfg, ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
+	repoCfg, err := p.ParserValidator.ParseRepoCfgData(repoCfgData, p.globalCfg(), ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
 	if err != nil {
 		return false, errors.Wrapf(err, "parsing %s", repoCfgFile)
 	}
 	ctx.Log.Info("successfully parsed remote %s file", repoCfgFile)
 
+	// The project resolver needs the cloned repo to determine the projects.
+	if repoCfg.ProjectResolver != nil {
+		ctx.Log.Info("project resolver configured. Will clone the repo to resolve projects")
+		return false, nil
+	}
+
 	// If auto discover is enabled, we never want to skip cloning
 	if p.autoDiscoverModeEnabled(ctx, repoCfg) {
 		ctx.Log.Info("automatic project discovery enabled. Will resume automatic detection")
@@ -371,18 +506,35 @@ func (p *DefaultProjectCommandBuilder) shouldSkipClone(ctx *command.Context, mod
 // autoDiscoverModeEnabled determines whether to use autodiscover
 func (p *DefaultProjectCommandBuilder) autoDiscoverModeEnabled(ctx *command.Context, repoCfg valid.RepoCfg) bool {
 	defaultAutoDiscoverMode := valid.AutoDiscoverMode(p.AutoDiscoverMode)
-	globalAutoDiscover := p.GlobalCfg.RepoAutoDiscoverCfg(ctx.Pull.BaseRepo.ID())
+	globalAutoDiscover := p.globalCfg().RepoAutoDiscoverCfg(ctx.Pull.BaseRepo.ID())
 	if globalAutoDiscover != nil {
 		defaultAutoDiscoverMode = globalAutoDiscover.Mode
 	}
 	return repoCfg.AutoDiscoverEnabled(defaultAutoDiscoverMode)
 }
 
+// sharedModuleDirs returns the autodiscover shared module dir patterns from
+// the repo config, falling back to the server-side repo config.
+func (p *DefaultProjectCommandBuilder) sharedModuleDirs(ctx *command.Context, repoCfg valid.RepoCfg) []string {
+	if repoCfg.AutoDiscover != nil && repoCfg.AutoDiscover.SharedModuleDirs != nil {
+		return repoCfg.AutoDiscover.SharedModuleDirs
+	}
+	if globalAutoDiscover := p.globalCfg().RepoAutoDiscoverCfg(ctx.Pull.BaseRepo.ID()); globalAutoDiscover != nil {
+		return globalAutoDiscover.SharedModuleDirs
+	}
+	return nil
+}
+
 // getMergedProjectCfgs gets all merged project configs for building commands given a context and a clone repo
 func (p *DefaultProjectCommandBuilder) getMergedProjectCfgs(ctx *command.Context, repoDir string, modifiedFiles []string, repoCfg valid.RepoCfg) ([]valid.MergedProjectCfg, error) {
+	if repoCfg.ProjectResolver != nil {
+		return p.getResolvedProjectCfgs(ctx, repoDir, modifiedFiles, repoCfg)
+	}
+
 	mergedCfgs := make([]valid.MergedProjectCfg, 0)
 
-	moduleInfo, err := FindModuleProjects(repoDir, p.AutoDetectModuleFiles)
+	sharedModuleDirs := p.sharedModuleDirs(ctx, repoCfg)
+	moduleInfo, err := FindModuleProjects(repoDir, p.AutoDetectModuleFiles, sharedModuleDirs)
 	if err != nil {
 		ctx.Log.Warn("error(s) loading project module dependencies: %s", err)
 	}
@@ -397,7 +549,7 @@ func (p *DefaultProjectCommandBuilder) getMergedProjectCfgs(ctx *command.Context
 
 		for _, mp := range matchingProjects {
 			ctx.Log.Debug("determining config for project at dir: '%s' workspace: '%s'", mp.Dir, mp.Workspace)
-			mergedCfg := p.GlobalCfg.MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp, repoCfg)
+			mergedCfg := p.globalCfg().MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp, repoCfg)
 			mergedCfgs = append(mergedCfgs, mergedCfg)
 		}
 	}
@@ -407,7 +559,7 @@ func (p *DefaultProjectCommandBuilder) getMergedProjectCfgs(ctx *command.Context
 
 		// build a module index for projects that are explicitly included
 		allModifiedProjects := p.ProjectFinder.DetermineProjects(
-			ctx.Log, modifiedFiles, ctx.Pull.BaseRepo.FullName, repoDir, p.AutoplanFileList, moduleInfo)
+			ctx.Log, modifiedFiles, ctx.Pull.BaseRepo.FullName, repoDir, p.AutoplanFileList, moduleInfo, sharedModuleDirs)
 		// If a project is already manually configured with the same dir as a discovered project, the manually configured
 		// project should take precedence
 		modifiedProjects := make([]models.Project, 0)
@@ -434,18 +586,92 @@ func (p *DefaultProjectCommandBuilder) getMergedProjectCfgs(ctx *command.Context
 		for _, mp := range modifiedProjects {
 			ctx.Log.Debug("determining config for project at dir: '%s'", mp.Path)
 			absProjectDir := filepath.Join(repoDir, mp.Path)
+			// Each deployment of a Terraform stack is planned as a project.
+			deployments, isStack, err := findStackDeployments(absProjectDir)
+			if err != nil {
+				return nil, errors.Wrapf(err, "looking for Terraform stack deployments in '%s'", absProjectDir)
+			}
+			if isStack {
+				if len(deployments) == 0 {
+					ctx.Log.Warn("ignoring Terraform stack in '%s' because it has no deployments", mp.Path)
+				}
+				for _, deployment := range deployments {
+					pCfg := p.globalCfg().DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp.Path, deployment)
+					useStackTool(&pCfg)
+					mergedCfgs = append(mergedCfgs, pCfg)
+				}
+				continue
+			}
 			pWorkspace, err := p.ProjectFinder.DetermineWorkspaceFromHCL(ctx.Log, absProjectDir)
 			if err != nil {
 				return nil, errors.Wrapf(err, "Looking for Terraform Cloud workspace from configuration in '%s'", absProjectDir)
 			}
 
-			pCfg := p.GlobalCfg.DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp.Path, pWorkspace)
+			pCfg := p.globalCfg().DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp.Path, pWorkspace)
 			mergedCfgs = append(mergedCfgs, pCfg)
 		}
 	}
 	return mergedCfgs, nil
 }
 
+// getResolvedProjectCfgs gets the merged project configs for the projects
+// returned by the repo's project resolver command. Projects returned by name
+// must be configured in the repo config. Projects returned by dir use the
+// configured projects in that dir, if any, and the server defaults otherwise.
+func (p *DefaultProjectCommandBuilder) getResolvedProjectCfgs(ctx *command.Context, repoDir string, modifiedFiles []string, repoCfg valid.RepoCfg) ([]valid.MergedProjectCfg, error) {
+	resolved, err := runProjectResolver(ctx, *repoCfg.ProjectResolver, repoDir, modifiedFiles)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Log.Info("project resolver returned %d project(s)", len(resolved))
+
+	mergedCfgs := make([]valid.MergedProjectCfg, 0)
+	seen := make(map[string]bool)
+	add := func(mergedCfg valid.MergedProjectCfg) {
+		key := mergedCfg.Name + "|" + mergedCfg.RepoRelDir + "|" + mergedCfg.Workspace
+		if !seen[key] {
+			seen[key] = true
+			mergedCfgs = append(mergedCfgs, mergedCfg)
+		}
+	}
+	for _, rp := range resolved {
+		if rp.Name != "" {
+			proj := repoCfg.FindProjectByName(rp.Name)
+			if proj == nil {
+				return nil, fmt.Errorf("project resolver returned project %q which is not defined in the repo config", rp.Name)
+			}
+			add(p.globalCfg().MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), *proj, repoCfg))
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(repoDir, rp.Dir)); err != nil {
+			ctx.Log.Info("ignoring project resolved at dir %q because it does not exist", rp.Dir)
+			continue
+		}
+		var configured []valid.Project
+		if rp.Workspace != "" {
+			configured = repoCfg.FindProjectsByDirWorkspace(rp.Dir, rp.Workspace)
+		} else {
+			configured = repoCfg.FindProjectsByDir(rp.Dir)
+		}
+		for _, proj := range configured {
+			add(p.globalCfg().MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), proj, repoCfg))
+		}
+		if len(configured) > 0 {
+			continue
+		}
+		workspace := rp.Workspace
+		if workspace == "" {
+			workspace, err = p.ProjectFinder.DetermineWorkspaceFromHCL(ctx.Log, filepath.Join(repoDir, rp.Dir))
+			if err != nil {
+				return nil, errors.Wrapf(err, "Looking for Terraform Cloud workspace from configuration in '%s'", rp.Dir)
+			}
+		}
+		add(p.globalCfg().DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), rp.Dir, workspace))
+	}
+	return mergedCfgs, nil
+}
+
 // buildAllCommandsByCfg builds init contexts for all projects we determine were
 // modified in this ctx.
 func (p *DefaultProjectCommandBuilder) buildAllCommandsByCfg(ctx *command.Context, cmdName command.Name, subCmdName string, commentFlags []string, verbose bool) ([]command.ProjectContext, error) {
@@ -471,19 +697,12 @@ func (p *DefaultProjectCommandBuilder) buildAllCommandsByCfg(ctx *command.Contex
 	// Need to lock the workspace we're about to clone to.
 	workspace := DefaultWorkspace
 
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace, DefaultRepoRelDir)
+	repoDir, unlockFn, err := cloneWorkspace(ctx, p.WorkingDirLocker, p.WorkingDir, workspace)
 	if err != nil {
-		ctx.Log.Warn("workspace was locked")
 		return nil, err
 	}
-	ctx.Log.Debug("got workspace lock")
 	defer unlockFn()
 
-	repoDir, _, err := p.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, workspace)
-	if err != nil {
-		return nil, err
-	}
-
 	if p.IncludeGitUntrackedFiles {
 		ctx.Log.Debug(("'include-git-untracked-files' option is set, getting untracked files"))
 		untrackedFiles, err := p.WorkingDir.GetGitUntrackedFiles(ctx.Log, ctx.HeadRepo, ctx.Pull, DefaultWorkspace)
@@ -494,8 +713,7 @@ func (p *DefaultProjectCommandBuilder) buildAllCommandsByCfg(ctx *command.Contex
 	}
 
 	// Parse config file if it exists.
-	repoCfgFile := p.GlobalCfg.RepoConfigFile(ctx.Pull.BaseRepo.ID())
-	hasRepoCfg, err := p.ParserValidator.HasRepoCfg(repoDir, repoCfgFile)
+	repoCfgFile, hasRepoCfg, err := p.ParserValidator.FindRepoCfg(repoDir, p.globalCfg().RepoConfigFiles(ctx.Pull.BaseRepo.ID()))
 	if err != nil {
 		return nil, errors.Wrapf(err, "looking for '%s' file in '%s'", repoCfgFile, repoDir)
 	}
@@ -503,13 +721,23 @@ func (p *DefaultProjectCommandBuilder) buildAllCommandsByCfg(ctx *command.Contex
 	var projCtxs []command.ProjectContext
 	var repoCfg valid.RepoCfg
 
+	// Plans of pull requests modifying the repo config review it.
+	var modifiedRepoCfgFile string
+	if cmdName == command.Plan {
+		modifiedRepoCfgFile = p.modifiedRepoCfgFile(ctx, modifiedFiles)
+	}
+
 	if hasRepoCfg {
 		// If there's a repo cfg with projects then we'll use it to figure out which projects
 		// should be planed.
-		repoCfg, err = p.ParserValidator.ParseRepoCfg(repoDir, p.GlobalCfg, ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
+		repoCfg, err = p.ParserValidator.ParseRepoCfg(repoDir, p.globalCfg(), ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
 		if err != nil {
+			if modifiedRepoCfgFile != "" {
+				ctx.RepoCfgReview = &command.RepoCfgReview{File: modifiedRepoCfgFile, Error: err.Error()}
+			}
 			return nil, errors.Wrapf(err, "parsing %s", repoCfgFile)
 		}
+		repoCfg.UsePreviewWorkspaces(ctx.Pull.Num)
 		ctx.Log.Info("successfully parsed %s file", repoCfgFile)
 	} else {
 		ctx.Log.Info("repo config file %s is absent, using global defaults", repoCfg)
@@ -519,6 +747,43 @@ func (p *DefaultProjectCommandBuilder) buildAllCommandsByCfg(ctx *command.Contex
 	if err != nil {
 		return nil, err
 	}
+	if !hasRepoCfg && len(mergedProjectCfgs) > 0 && cmdName == command.Plan && ctx.Trigger == command.AutoTrigger &&
+		p.RepoCfgSuggestionStore != nil && p.globalCfg().SuggestRepoConfig(ctx.Pull.BaseRepo.ID()) {
+		if err := p.suggestRepoCfg(ctx, repoDir, repoCfg); err != nil {
+			ctx.Log.Warn("unable to suggest an atlantis.yaml: %s", err)
+		}
+	}
+	if modifiedRepoCfgFile != "" {
+		// The projects the modified repo config adds or changes are planned
+		// too to dry-run it.
+		review, changedProjectCfgs := p.reviewRepoCfg(ctx, repoDir, modifiedRepoCfgFile, repoCfg)
+		ctx.RepoCfgReview = review
+		for _, changed := range changedProjectCfgs {
+			if !slices.ContainsFunc(mergedProjectCfgs, func(cfg valid.MergedProjectCfg) bool {
+				return cfg.RepoRelDir == changed.RepoRelDir && cfg.Workspace == changed.Workspace && cfg.Name == changed.Name
+			}) {
+				mergedProjectCfgs = append(mergedProjectCfgs, changed)
+			}
+		}
+	}
+	mergedProjectCfgs, err = nameProjectCfgs(p.ProjectNameTemplate, ctx.Pull.BaseRepo, mergedProjectCfgs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Projects that consume the outputs of the modified projects are planned
+	// too to preview the impact of the pull request.
+	var impactPreviewCfgs []valid.MergedProjectCfg
+	if cmdName == command.Plan && repoCfg.ImpactPreview {
+		for _, proj := range findImpactPreviewProjects(ctx.Log, repoDir, repoCfg, mergedProjectCfgs) {
+			impactPreviewCfgs = append(impactPreviewCfgs, p.globalCfg().MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), proj, repoCfg))
+		}
+		ctx.Log.Info("%d projects consume the outputs of the modified projects and are planned to preview the impact", len(impactPreviewCfgs))
+		impactPreviewCfgs, err = nameProjectCfgs(p.ProjectNameTemplate, ctx.Pull.BaseRepo, impactPreviewCfgs)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	automerge := p.EnableAutoMerge
 	parallelApply := p.EnableParallelApply
@@ -554,6 +819,25 @@ func (p *DefaultProjectCommandBuilder) buildAllCommandsByCfg(ctx *command.Contex
 				p.TerraformExecutor,
 			)...)
 	}
+	for _, impactPreviewCfg := range impactPreviewCfgs {
+		for _, projCtx := range p.ProjectCommandContextBuilder.BuildProjectContext(
+			ctx,
+			cmdName,
+			subCmdName,
+			impactPreviewCfg,
+			commentFlags,
+			repoDir,
+			automerge,
+			parallelApply,
+			parallelPlan,
+			verbose,
+			abortOnExecutionOrderFail,
+			p.TerraformExecutor,
+		) {
+			projCtx.ImpactPreview = true
+			projCtxs = append(projCtxs, projCtx)
+		}
+	}
 
 	sort.Slice(projCtxs, func(i, j int) bool {
 		return projCtxs[i].ExecutionOrderGroup < projCtxs[j].ExecutionOrderGroup
@@ -597,17 +881,12 @@ func (p *DefaultProjectCommandBuilder) buildProjectPlanCommand(ctx *command.Cont
 	var pcc []command.ProjectContext
 
 	ctx.Log.Debug("building plan command")
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace, DefaultRepoRelDir)
-	if err != nil {
-		return pcc, err
-	}
-	defer unlockFn()
-
 	ctx.Log.Debug("cloning repository")
-	_, _, err = p.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, DefaultWorkspace)
+	_, unlockFn, err := cloneWorkspace(ctx, p.WorkingDirLocker, p.WorkingDir, DefaultWorkspace)
 	if err != nil {
 		return pcc, err
 	}
+	defer unlockFn()
 
 	// use the default repository workspace because it is the only one guaranteed to have an atlantis.yaml,
 	// other workspaces will not have the file if they are using pre_workflow_hooks to generate it dynamically
@@ -654,7 +933,7 @@ func (p *DefaultProjectCommandBuilder) buildProjectPlanCommand(ctx *command.Cont
 			var notFoundFiles = []string{}
 			var repoConfig valid.RepoCfg
 
-			repoConfig, err = p.ParserValidator.ParseRepoCfg(defaultRepoDir, p.GlobalCfg, ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
+			repoConfig, err = p.ParserValidator.ParseRepoCfg(defaultRepoDir, p.globalCfg(), ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
 			if err != nil {
 				return pcc, err
 			}
@@ -682,10 +961,13 @@ func (p *DefaultProjectCommandBuilder) buildProjectPlanCommand(ctx *command.Cont
 
 	if DefaultWorkspace != workspace {
 		ctx.Log.Debug("cloning repository with workspace %s", workspace)
-		_, _, err = p.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, workspace)
+		// Only the default workspace is read while building, so the clone of
+		// the workspace isn't kept locked.
+		_, unlockWorkspaceFn, err := cloneWorkspace(ctx, p.WorkingDirLocker, p.WorkingDir, workspace)
 		if err != nil {
 			return pcc, err
 		}
+		unlockWorkspaceFn()
 	}
 
 	repoRelDir := DefaultRepoRelDir
@@ -709,8 +991,7 @@ func (p *DefaultProjectCommandBuilder) buildProjectPlanCommand(ctx *command.Cont
 // getCfg returns the atlantis.yaml config (if it exists) for this project. If
 // there is no config, then projectCfg and repoCfg will be nil.
 func (p *DefaultProjectCommandBuilder) getCfg(ctx *command.Context, projectName string, dir string, workspace string, repoDir string) (projectsCfg []valid.Project, repoCfg *valid.RepoCfg, err error) {
-	repoCfgFile := p.GlobalCfg.RepoConfigFile(ctx.Pull.BaseRepo.ID())
-	hasRepoCfg, err := p.ParserValidator.HasRepoCfg(repoDir, repoCfgFile)
+	repoCfgFile, hasRepoCfg, err := p.ParserValidator.FindRepoCfg(repoDir, p.globalCfg().RepoConfigFiles(ctx.Pull.BaseRepo.ID()))
 	if err != nil {
 		err = errors.Wrapf(err, "looking for '%s' file in '%s'", repoCfgFile, repoDir)
 		return
@@ -724,10 +1005,11 @@ func (p *DefaultProjectCommandBuilder) getCfg(ctx *command.Context, projectName
 	}
 
 	var repoConfig valid.RepoCfg
-	repoConfig, err = p.ParserValidator.ParseRepoCfg(repoDir, p.GlobalCfg, ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
+	repoConfig, err = p.ParserValidator.ParseRepoCfg(repoDir, p.globalCfg(), ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
 	if err != nil {
 		return
 	}
+	repoConfig.UsePreviewWorkspaces(ctx.Pull.Num)
 	repoCfg = &repoConfig
 
 	// If they've specified a project by name we look it up. Otherwise we
@@ -793,7 +1075,13 @@ func (p *DefaultProjectCommandBuilder) buildAllProjectCommandsByPlan(ctx *comman
 
 	var cmds []command.ProjectContext
 	for _, plan := range plans {
-		commentCmds, err := p.buildProjectCommandCtx(ctx, commentCmd.CommandName(), commentCmd.SubName, plan.ProjectName, commentCmd.Flags, defaultRepoDir, plan.RepoRelDir, plan.Workspace, commentCmd.Verbose)
+		// Generated names aren't in the repo config so these projects are
+		// looked up by their dir and workspace.
+		projectName := plan.ProjectName
+		if isGeneratedProjectName(p.ProjectNameTemplate, ctx.Pull.BaseRepo, projectName, plan.RepoRelDir, plan.Workspace) {
+			projectName = ""
+		}
+		commentCmds, err := p.buildProjectCommandCtx(ctx, commentCmd.CommandName(), commentCmd.SubName, projectName, commentCmd.Flags, defaultRepoDir, plan.RepoRelDir, plan.Workspace, commentCmd.Verbose)
 		if err != nil {
 			return nil, errors.Wrapf(err, "building command for dir '%s'", plan.RepoRelDir)
 		}
@@ -816,10 +1104,7 @@ func (p *DefaultProjectCommandBuilder) buildProjectCommand(ctx *command.Context,
 	}
 
 	var projCtx []command.ProjectContext
-	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace, DefaultRepoRelDir)
-	if err != nil {
-		return projCtx, err
-	}
+	unlockFn := p.WorkingDirLocker.RLockWorkspace(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, DefaultWorkspace)
 	defer unlockFn()
 
 	// use the default repository workspace because it is the only one guaranteed to have an atlantis.yaml,
@@ -892,7 +1177,10 @@ func (p *DefaultProjectCommandBuilder) buildProjectCommandCtx(ctx *command.Conte
 		workspace = projCfg.Workspace
 		for _, mp := range matchingProjects {
 			ctx.Log.Debug("Merging config for project at dir: '%s' workspace: '%s'", mp.Dir, mp.Workspace)
-			projCfg = p.GlobalCfg.MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp, *repoCfgPtr)
+			projCfg = p.globalCfg().MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp, *repoCfgPtr)
+			if projCfg, err = p.nameProjectCfg(ctx, projCfg); err != nil {
+				return []command.ProjectContext{}, err
+			}
 
 			projCtxs = append(projCtxs,
 				p.ProjectCommandContextBuilder.BuildProjectContext(
@@ -917,7 +1205,20 @@ func (p *DefaultProjectCommandBuilder) buildProjectCommandCtx(ctx *command.Conte
 			return []command.ProjectContext{}, nil
 		}
 
-		projCfg = p.GlobalCfg.DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), repoRelDir, workspace)
+		projCfg = p.globalCfg().DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), repoRelDir, workspace)
+		deployments, isStack, err := findStackDeployments(filepath.Join(repoDir, repoRelDir))
+		if err != nil && !os.IsNotExist(err) {
+			return []command.ProjectContext{}, errors.Wrapf(err, "looking for Terraform stack deployments in '%s'", repoRelDir)
+		}
+		if isStack {
+			if !slices.Contains(deployments, workspace) {
+				return []command.ProjectContext{}, fmt.Errorf("dir %q is a Terraform stack, run the command for one of its deployments with -w: %s", repoRelDir, strings.Join(deployments, ", "))
+			}
+			useStackTool(&projCfg)
+		}
+		if projCfg, err = p.nameProjectCfg(ctx, projCfg); err != nil {
+			return []command.ProjectContext{}, err
+		}
 		projCtxs = append(projCtxs,
 			p.ProjectCommandContextBuilder.BuildProjectContext(
 				ctx,
@@ -966,6 +1267,16 @@ func (p *DefaultProjectCommandBuilder) buildProjectCommandCtx(ctx *command.Conte
 	return projCtxs, nil
 }
 
+// nameProjectCfg names projCfg with the project name template if it doesn't
+// have a name.
+func (p *DefaultProjectCommandBuilder) nameProjectCfg(ctx *command.Context, projCfg valid.MergedProjectCfg) (valid.MergedProjectCfg, error) {
+	cfgs, err := nameProjectCfgs(p.ProjectNameTemplate, ctx.Pull.BaseRepo, []valid.MergedProjectCfg{projCfg})
+	if err != nil {
+		return projCfg, err
+	}
+	return cfgs[0], nil
+}
+
 // validateWorkspaceAllowed returns an error if repoCfg defines projects in
 // repoRelDir but none of them use workspace. We want this to be an error
 // because if users have gone to the trouble of defining projects in repoRelDir
@@ -657,7 +657,7 @@ projects:
 				vcsClient,
 				workingDir,
 				NewDefaultWorkingDirLocker(),
-				globalCfg,
+				valid.NewGlobalCfgStore(globalCfg),
 				&DefaultPendingPlanFinder{},
 				&CommentParser{ExecutableName: "atlantis"},
 				false,
@@ -874,7 +874,7 @@ projects:
 				vcsClient,
 				workingDir,
 				NewDefaultWorkingDirLocker(),
-				globalCfg,
+				valid.NewGlobalCfgStore(globalCfg),
 				&DefaultPendingPlanFinder{},
 				&CommentParser{ExecutableName: "atlantis"},
 				false,
@@ -1121,7 +1121,7 @@ workflows:
 				vcsClient,
 				workingDir,
 				NewDefaultWorkingDirLocker(),
-				globalCfg,
+				valid.NewGlobalCfgStore(globalCfg),
 				&DefaultPendingPlanFinder{},
 				&CommentParser{ExecutableName: "atlantis"},
 				false,
@@ -1273,7 +1273,7 @@ projects:
 				vcsClient,
 				workingDir,
 				NewDefaultWorkingDirLocker(),
-				globalCfg,
+				valid.NewGlobalCfgStore(globalCfg),
 				&DefaultPendingPlanFinder{},
 				&CommentParser{ExecutableName: "atlantis"},
 				false,
@@ -1415,7 +1415,7 @@ projects:
 				vcsClient,
 				workingDir,
 				NewDefaultWorkingDirLocker(),
-				globalCfg,
+				valid.NewGlobalCfgStore(globalCfg),
 				&DefaultPendingPlanFinder{},
 				&CommentParser{ExecutableName: "atlantis"},
 				false,
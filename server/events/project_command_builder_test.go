@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	. "github.com/petergtz/pegomock/v4"
@@ -21,6 +22,7 @@ import (
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/metrics"
 	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
 )
 
 var defaultUserConfig = struct {
@@ -259,7 +261,7 @@ terraform {
 				vcsClient,
 				workingDir,
 				events.NewDefaultWorkingDirLocker(),
-				valid.NewGlobalCfgFromArgs(globalCfgArgs),
+				valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 				&events.DefaultPendingPlanFinder{},
 				&events.CommentParser{ExecutableName: "atlantis"},
 				userConfig.SkipCloneNoChanges,
@@ -625,7 +627,7 @@ projects:
 					vcsClient,
 					workingDir,
 					events.NewDefaultWorkingDirLocker(),
-					valid.NewGlobalCfgFromArgs(globalCfgArgs),
+					valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 					&events.DefaultPendingPlanFinder{},
 					&events.CommentParser{ExecutableName: "atlantis"},
 					userConfig.SkipCloneNoChanges,
@@ -813,7 +815,7 @@ projects:
 				vcsClient,
 				workingDir,
 				events.NewDefaultWorkingDirLocker(),
-				valid.NewGlobalCfgFromArgs(globalCfgArgs),
+				valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 				&events.DefaultPendingPlanFinder{},
 				&events.CommentParser{ExecutableName: "atlantis"},
 				userConfig.SkipCloneNoChanges,
@@ -1214,7 +1216,7 @@ projects:
 				vcsClient,
 				workingDir,
 				events.NewDefaultWorkingDirLocker(),
-				valid.NewGlobalCfgFromArgs(globalCfgArgs),
+				valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 				&events.DefaultPendingPlanFinder{},
 				&events.CommentParser{ExecutableName: "atlantis"},
 				userConfig.SkipCloneNoChanges,
@@ -1312,7 +1314,7 @@ func TestDefaultProjectCommandBuilder_BuildMultiApply(t *testing.T) {
 		nil,
 		workingDir,
 		events.NewDefaultWorkingDirLocker(),
-		valid.NewGlobalCfgFromArgs(globalCfgArgs),
+		valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 		&events.DefaultPendingPlanFinder{},
 		&events.CommentParser{ExecutableName: "atlantis"},
 		userConfig.SkipCloneNoChanges,
@@ -1398,7 +1400,7 @@ projects:
 		nil,
 		workingDir,
 		events.NewDefaultWorkingDirLocker(),
-		valid.NewGlobalCfgFromArgs(globalCfgArgs),
+		valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 		&events.DefaultPendingPlanFinder{},
 		&events.CommentParser{ExecutableName: "atlantis"},
 		userConfig.SkipCloneNoChanges,
@@ -1434,6 +1436,86 @@ projects:
 	ErrEquals(t, "running commands in workspace \"notconfigured\" is not allowed because this directory is only configured for the following workspaces: default, staging", err)
 }
 
+// Test that commands can be built for a pull request while a project of it
+// runs: commands that don't clone are built right away and a plan waits for
+// the running project to finish before cloning the workspace it runs in.
+func TestDefaultProjectCommandBuilder_BuildWhileProjectRuns(t *testing.T) {
+	RegisterMockTestingT(t)
+	repoDir := DirStructure(t, map[string]interface{}{
+		"project1": map[string]interface{}{"main.tf": nil},
+		"project2": map[string]interface{}{"main.tf": nil},
+	})
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Any[string]())).ThenReturn(repoDir, false, nil)
+	When(workingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).ThenReturn(repoDir, nil)
+
+	logger := logging.NewNoopLogger(t)
+	scope := tally.NewTestScope("atlantis", nil)
+	userConfig := defaultUserConfig
+	locker := events.NewDefaultWorkingDirLocker()
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&config.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		nil,
+		workingDir,
+		locker,
+		valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})),
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{ExecutableName: "atlantis"},
+		userConfig.SkipCloneNoChanges,
+		userConfig.EnableRegExpCmd,
+		userConfig.EnableAutoMerge,
+		userConfig.EnableParallelPlan,
+		userConfig.EnableParallelApply,
+		userConfig.AutoDetectModuleFiles,
+		userConfig.AutoplanFileList,
+		userConfig.RestrictFileList,
+		userConfig.SilenceNoProjects,
+		userConfig.IncludeGitUntrackedFiles,
+		userConfig.AutoDiscoverMode,
+		scope,
+		tfclientmocks.NewMockClient(),
+	)
+	ctx := &command.Context{
+		Pull:  models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+		Log:   logger,
+		Scope: scope,
+	}
+
+	// The plan of project1 runs in the staging workspace.
+	unlockFn, err := locker.TryLock("owner/repo", 1, "staging", "project1")
+	Ok(t, err)
+
+	ctxs, err := builder.BuildApplyCommands(ctx, &events.CommentCommand{RepoRelDir: "project2", Name: command.Apply})
+	Ok(t, err)
+	Equals(t, 1, len(ctxs))
+
+	built := make(chan error)
+	go func() {
+		_, err := builder.BuildPlanCommands(ctx, &events.CommentCommand{RepoRelDir: "project2", Workspace: "staging", Name: command.Plan})
+		built <- err
+	}()
+	workingDir.VerifyWasCalledEventually(Once(), 5*time.Second).Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](), Eq(events.DefaultWorkspace))
+	select {
+	case <-built:
+		t.Fatal("expected the plan to wait for the running project before cloning")
+	case <-time.After(50 * time.Millisecond):
+	}
+	workingDir.VerifyWasCalled(Never()).Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](), Eq("staging"))
+
+	unlockFn()
+	select {
+	case err := <-built:
+		Ok(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the plan to be built once the running project finished")
+	}
+	workingDir.VerifyWasCalledOnce().Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](), Eq(events.DefaultWorkspace))
+	workingDir.VerifyWasCalledOnce().Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](), Eq("staging"))
+}
+
 // Test that extra comment args are escaped.
 func TestDefaultProjectCommandBuilder_EscapeArgs(t *testing.T) {
 	cases := []struct {
@@ -1486,7 +1568,7 @@ func TestDefaultProjectCommandBuilder_EscapeArgs(t *testing.T) {
 				vcsClient,
 				workingDir,
 				events.NewDefaultWorkingDirLocker(),
-				valid.NewGlobalCfgFromArgs(globalCfgArgs),
+				valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 				&events.DefaultPendingPlanFinder{},
 				&events.CommentParser{ExecutableName: "atlantis"},
 				userConfig.SkipCloneNoChanges,
@@ -1648,7 +1730,7 @@ projects:
 				vcsClient,
 				workingDir,
 				events.NewDefaultWorkingDirLocker(),
-				valid.NewGlobalCfgFromArgs(globalCfgArgs),
+				valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 				&events.DefaultPendingPlanFinder{},
 				&events.CommentParser{ExecutableName: "atlantis"},
 				userConfig.SkipCloneNoChanges,
@@ -1772,7 +1854,7 @@ projects:
 			vcsClient,
 			workingDir,
 			events.NewDefaultWorkingDirLocker(),
-			valid.NewGlobalCfgFromArgs(globalCfgArgs),
+			valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 			&events.DefaultPendingPlanFinder{},
 			&events.CommentParser{ExecutableName: "atlantis"},
 			userConfig.SkipCloneNoChanges,
@@ -1841,7 +1923,7 @@ func TestDefaultProjectCommandBuilder_WithPolicyCheckEnabled_BuildAutoplanComman
 		vcsClient,
 		workingDir,
 		events.NewDefaultWorkingDirLocker(),
-		globalCfg,
+		valid.NewGlobalCfgStore(globalCfg),
 		&events.DefaultPendingPlanFinder{},
 		&events.CommentParser{ExecutableName: "atlantis"},
 		userConfig.SkipCloneNoChanges,
@@ -1929,7 +2011,7 @@ func TestDefaultProjectCommandBuilder_BuildVersionCommand(t *testing.T) {
 		nil,
 		workingDir,
 		events.NewDefaultWorkingDirLocker(),
-		valid.NewGlobalCfgFromArgs(globalCfgArgs),
+		valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 		&events.DefaultPendingPlanFinder{},
 		&events.CommentParser{ExecutableName: "atlantis"},
 		userConfig.SkipCloneNoChanges,
@@ -2059,7 +2141,7 @@ func TestDefaultProjectCommandBuilder_BuildPlanCommands_Single_With_RestrictFile
 				vcsClient,
 				workingDir,
 				events.NewDefaultWorkingDirLocker(),
-				valid.NewGlobalCfgFromArgs(globalCfgArgs),
+				valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 				&events.DefaultPendingPlanFinder{},
 				&events.CommentParser{ExecutableName: "atlantis"},
 				userConfig.SkipCloneNoChanges,
@@ -2170,7 +2252,7 @@ func TestDefaultProjectCommandBuilder_BuildPlanCommands_with_IncludeGitUntracked
 				vcsClient,
 				workingDir,
 				events.NewDefaultWorkingDirLocker(),
-				valid.NewGlobalCfgFromArgs(globalCfgArgs),
+				valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(globalCfgArgs)),
 				&events.DefaultPendingPlanFinder{},
 				&events.CommentParser{ExecutableName: "atlantis"},
 				userConfig.SkipCloneNoChanges,
@@ -2206,3 +2288,208 @@ func TestDefaultProjectCommandBuilder_BuildPlanCommands_with_IncludeGitUntracked
 		})
 	}
 }
+
+func TestDefaultProjectCommandBuilder_ProjectResolver(t *testing.T) {
+	resolveScript := `files=$(cat)
+case "$files" in
+  *a/main.tf*) echo '[{"dir": "a"}, {"name": "b-proj"}, {"dir": "a"}]' ;;
+  *) echo '[]' ;;
+esac
+`
+	cases := []struct {
+		Description  string
+		AtlantisYAML string
+		exp          []string
+		expErr       string
+	}{
+		{
+			Description: "resolver replaces when_modified",
+			AtlantisYAML: `
+version: 3
+project_resolver:
+  command: sh resolve.sh
+projects:
+- name: b-proj
+  dir: b
+- dir: c
+`,
+			exp: []string{"b-proj|b|default", "|a|default"},
+		},
+		{
+			Description: "resolver returns unknown project name",
+			AtlantisYAML: `
+version: 3
+project_resolver:
+  command: sh resolve.sh
+`,
+			expErr: `project resolver returned project "b-proj" which is not defined in the repo config`,
+		},
+	}
+
+	logger := logging.NewNoopLogger(t)
+	scope, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+	userConfig := defaultUserConfig
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			dirStructure := map[string]interface{}{
+				"a":          map[string]interface{}{"main.tf": nil},
+				"b":          map[string]interface{}{"main.tf": nil},
+				"c":          map[string]interface{}{"main.tf": nil},
+				"resolve.sh": resolveScript,
+			}
+			tmpDir := DirStructure(t, dirStructure)
+			Ok(t, os.WriteFile(filepath.Join(tmpDir, valid.DefaultAtlantisFile), []byte(c.AtlantisYAML), 0600))
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+				Any[string]())).ThenReturn(tmpDir, false, nil)
+			vcsClient := vcsmocks.NewMockClient()
+			When(vcsClient.GetModifiedFiles(Any[logging.SimpleLogging](), Any[models.Repo](),
+				Any[models.PullRequest]())).ThenReturn(ChangedFiles(dirStructure, ""), nil)
+
+			builder := events.NewProjectCommandBuilder(
+				false,
+				&config.ParserValidator{},
+				&events.DefaultProjectFinder{},
+				vcsClient,
+				workingDir,
+				events.NewDefaultWorkingDirLocker(),
+				valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowAllRepoSettings: true})),
+				&events.DefaultPendingPlanFinder{},
+				&events.CommentParser{ExecutableName: "atlantis"},
+				userConfig.SkipCloneNoChanges,
+				userConfig.EnableRegExpCmd,
+				userConfig.EnableAutoMerge,
+				userConfig.EnableParallelPlan,
+				userConfig.EnableParallelApply,
+				userConfig.AutoDetectModuleFiles,
+				userConfig.AutoplanFileList,
+				userConfig.RestrictFileList,
+				userConfig.SilenceNoProjects,
+				userConfig.IncludeGitUntrackedFiles,
+				userConfig.AutoDiscoverMode,
+				scope,
+				tfclientmocks.NewMockClient(),
+			)
+
+			ctxs, err := builder.BuildAutoplanCommands(&command.Context{
+				PullRequestStatus: models.PullReqStatus{
+					Mergeable: true,
+				},
+				Log:   logger,
+				Scope: scope,
+			})
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			var act []string
+			for _, ctx := range ctxs {
+				act = append(act, strings.Join([]string{ctx.ProjectName, ctx.RepoRelDir, ctx.Workspace}, "|"))
+			}
+			sort.Strings(act)
+			Equals(t, c.exp, act)
+		})
+	}
+}
+
+// Test that plans with --label run on every project with the labels, whether
+// or not the pull request modifies them.
+func TestDefaultProjectCommandBuilder_BuildPlanCommandsByLabels(t *testing.T) {
+	RegisterMockTestingT(t)
+	workingDir := mocks.NewMockWorkingDir()
+
+	repoDir := DirStructure(t, map[string]interface{}{
+		"network": map[string]interface{}{
+			"main.tf": nil,
+		},
+		"app": map[string]interface{}{
+			"main.tf": nil,
+		},
+	})
+	yamlCfg := `version: 3
+projects:
+- name: network-prod
+  dir: network
+  labels:
+    tier: prod
+    team: platform
+- name: network-staging
+  dir: network
+  workspace: staging
+  labels:
+    tier: staging
+    team: platform
+- name: app-prod
+  dir: app
+  labels:
+    tier: prod
+    team: app
+`
+	Ok(t, os.WriteFile(filepath.Join(repoDir, valid.DefaultAtlantisFile), []byte(yamlCfg), 0600))
+
+	When(workingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Any[string]())).ThenReturn(repoDir, false, nil)
+	When(workingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).ThenReturn(repoDir, nil)
+
+	logger := logging.NewNoopLogger(t)
+	scope, _, _ := metrics.NewLoggingScope(logger, "atlantis")
+	userConfig := defaultUserConfig
+
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&config.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		nil,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowAllRepoSettings: true})),
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{ExecutableName: "atlantis"},
+		userConfig.SkipCloneNoChanges,
+		userConfig.EnableRegExpCmd,
+		userConfig.EnableAutoMerge,
+		userConfig.EnableParallelPlan,
+		userConfig.EnableParallelApply,
+		userConfig.AutoDetectModuleFiles,
+		userConfig.AutoplanFileList,
+		userConfig.RestrictFileList,
+		userConfig.SilenceNoProjects,
+		userConfig.IncludeGitUntrackedFiles,
+		userConfig.AutoDiscoverMode,
+		scope,
+		tfclientmocks.NewMockClient(),
+	)
+
+	ctx := &command.Context{
+		Log:   logger,
+		Scope: scope,
+	}
+	ctxs, err := builder.BuildPlanCommands(ctx, &events.CommentCommand{
+		Name:   command.Plan,
+		Labels: map[string]string{"tier": "prod"},
+	})
+	Ok(t, err)
+	Equals(t, 2, len(ctxs))
+	Equals(t, "network-prod", ctxs[0].ProjectName)
+	Equals(t, map[string]string{"tier": "prod", "team": "platform"}, ctxs[0].Labels)
+	Equals(t, "app-prod", ctxs[1].ProjectName)
+
+	ctxs, err = builder.BuildPlanCommands(ctx, &events.CommentCommand{
+		Name:   command.Plan,
+		Labels: map[string]string{"tier": "staging", "team": "platform"},
+	})
+	Ok(t, err)
+	Equals(t, 1, len(ctxs))
+	Equals(t, "network-staging", ctxs[0].ProjectName)
+	Equals(t, "staging", ctxs[0].Workspace)
+
+	ctxs, err = builder.BuildPlanCommands(ctx, &events.CommentCommand{
+		Name:   command.Plan,
+		Labels: map[string]string{"tier": "dev"},
+	})
+	Ok(t, err)
+	Equals(t, 0, len(ctxs))
+}
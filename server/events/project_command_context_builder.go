@@ -8,6 +8,7 @@ import (
 	"github.com/runatlantis/atlantis/server/core/terraform/tfclient"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
 	tally "github.com/uber-go/tally/v4"
 )
 
@@ -114,11 +115,22 @@ func (cb *DefaultProjectCommandContextBuilder) BuildProjectContext(
 		switch subName {
 		case "rm":
 			steps = prjCfg.Workflow.StateRm.Steps
+		case "list":
+			// Setting statically since reading the state isn't configurable in workflows
+			steps = valid.DefaultStateListStage.Steps
+		case "show":
+			steps = valid.DefaultStateShowStage.Steps
 		default:
 			// comment_parser prevent invalid subcommand, so not need to handle this.
 			// if comes here, state_command_runner will respond on PR, so it's enough to do log only.
 			ctx.Log.Err("unknown state subcommand: %s", subName)
 		}
+	case command.ForceUnlockState:
+		// Setting statically since force-unlock-state isn't configurable in workflows
+		steps = valid.DefaultForceUnlockStateStage.Steps
+	case command.Rollback:
+		// A rollback is planned like any other change of the project.
+		steps = prjCfg.Workflow.Plan.Steps
 	}
 
 	// If TerraformVersion not defined in config file look for a
@@ -274,6 +286,11 @@ func newProjectCommandContext(ctx *command.Context,
 		ApprovePoliciesCmd:         approvePoliciesCmd,
 		BaseRepo:                   ctx.Pull.BaseRepo,
 		EscapedCommentArgs:         escapedCommentArgs,
+		ExtraArgs:                  escapeExtraArgs(projCfg.ExtraArgs),
+		PRMetadataVars:             projCfg.PRMetadataVars,
+		ShareOutputs:               projCfg.ShareOutputs,
+		DependencyOutputs:          projCfg.DependencyOutputs,
+		HookOutputVars:             ctx.HookOutputVars,
 		AutomergeEnabled:           automergeEnabled,
 		DeleteSourceBranchOnMerge:  projCfg.DeleteSourceBranchOnMerge,
 		RepoLocksMode:              projCfg.RepoLocks.Mode,
@@ -284,8 +301,29 @@ func newProjectCommandContext(ctx *command.Context,
 		DependsOn:                  projCfg.DependsOn,
 		AutoplanEnabled:            projCfg.AutoplanEnabled,
 		Steps:                      steps,
+		PostPlanHooks:              projCfg.PostPlanHooks,
+		PostApplyHooks:             projCfg.PostApplyHooks,
+		DestroyGuard:               projCfg.DestroyGuard,
+		Notifications:              projCfg.Notifications,
+		Owners:                     projCfg.Owners,
+		CodeOwners:                 projCfg.CodeOwners,
+		InlineReviewComments:       projCfg.InlineReviewComments,
+		CommandAllowlist:           projCfg.CommandAllowlist,
+		ProjectCommandAllowlist:    projCfg.ProjectCommandAllowlist,
+		ConfirmDestroy:             ctx.ConfirmDestroy,
+		CostBudget:                 projCfg.CostBudget,
+		RiskScoring:                projCfg.RiskScoring,
+		AutoApproval:               projCfg.AutoApproval,
+		Rollback:                   projCfg.Rollback,
+		ApplyWindows:               projCfg.ApplyWindows,
+		PreviewEnvironment:         projCfg.PreviewEnvironment,
+		LockKeys:                   projCfg.LockKeys,
+		Labels:                     projCfg.Labels,
+		Canary:                     projCfg.Canary,
+		OverrideApplyWindow:        ctx.OverrideApplyWindow,
+		Tool:                       projCfg.Tool,
 		HeadRepo:                   ctx.HeadRepo,
-		Log:                        ctx.Log,
+		Log:                        ctx.Log.WithHistory(logging.ProjectKey, projCfg.Name, "dir", projCfg.RepoRelDir, "workspace", projCfg.Workspace),
 		Scope:                      scope,
 		ProjectPlanStatus:          projectPlanStatus,
 		ProjectPolicyStatus:        projectPolicyStatus,
@@ -315,6 +353,18 @@ func newProjectCommandContext(ctx *command.Context,
 	}
 }
 
+// escapeExtraArgs escapes the default extra arguments of each step.
+func escapeExtraArgs(extraArgs map[string][]string) map[string][]string {
+	if extraArgs == nil {
+		return nil
+	}
+	escaped := make(map[string][]string, len(extraArgs))
+	for step, args := range extraArgs {
+		escaped[step] = escapeArgs(args)
+	}
+	return escaped
+}
+
 func escapeArgs(args []string) []string {
 	var escaped []string
 	for _, arg := range args {
@@ -1,7 +1,9 @@
 package events
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/remeh/sizedwaitgroup"
@@ -90,3 +92,95 @@ func runProjectCmdsParallelGroups(
 
 	return command.Result{ProjectResults: results}
 }
+
+// runAfterDependencies moves the projects of cmds that depend on other
+// projects of cmds into a later execution order group than their
+// dependencies and sorts cmds by group. Projects of the same group run in
+// parallel, so otherwise skipFailedDependents couldn't know whether the
+// dependencies of a project failed before it starts. The dependency closing a
+// cycle is ignored.
+func runAfterDependencies(cmds []command.ProjectContext) []command.ProjectContext {
+	byName := make(map[string][]int)
+	for i, cmd := range cmds {
+		if cmd.ProjectName != "" {
+			byName[cmd.ProjectName] = append(byName[cmd.ProjectName], i)
+		}
+	}
+
+	groups := make([]int, len(cmds))
+	// visited is 1 while the dependencies of a project are visited and 2
+	// once its group is known.
+	visited := make([]int, len(cmds))
+	var visit func(i int) int
+	visit = func(i int) int {
+		if visited[i] > 0 {
+			return groups[i]
+		}
+		visited[i] = 1
+		groups[i] = cmds[i].ExecutionOrderGroup
+		for _, dep := range cmds[i].DependsOn {
+			if dep == cmds[i].ProjectName {
+				continue
+			}
+			for _, j := range byName[dep] {
+				if visited[j] == 1 {
+					continue
+				}
+				groups[i] = max(groups[i], visit(j)+1)
+			}
+		}
+		visited[i] = 2
+		return groups[i]
+	}
+
+	ordered := make([]command.ProjectContext, len(cmds))
+	for i := range cmds {
+		ordered[i] = cmds[i]
+		ordered[i].ExecutionOrderGroup = visit(i)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].ExecutionOrderGroup < ordered[j].ExecutionOrderGroup
+	})
+	return ordered
+}
+
+// skipFailedDependents wraps runnerFunc so projects that depend on a project
+// that already failed aren't run. It's used to keep applying independent
+// projects after a failure without applying anything built on top of it. The
+// dependencies must have finished before a project starts, see
+// runAfterDependencies.
+func skipFailedDependents(runnerFunc prjCmdRunnerFunc) prjCmdRunnerFunc {
+	failed := make(map[string]bool)
+	mux := &sync.Mutex{}
+	return func(ctx command.ProjectContext) command.ProjectResult {
+		mux.Lock()
+		var failedDeps []string
+		for _, dep := range ctx.DependsOn {
+			if failed[dep] {
+				failedDeps = append(failedDeps, dep)
+			}
+		}
+		mux.Unlock()
+
+		var res command.ProjectResult
+		if len(failedDeps) > 0 {
+			res = command.ProjectResult{
+				Command:           ctx.CommandName,
+				Failure:           fmt.Sprintf("Skipped because its dependencies failed: [%s]", strings.Join(failedDeps, ", ")),
+				RepoRelDir:        ctx.RepoRelDir,
+				Workspace:         ctx.Workspace,
+				ProjectName:       ctx.ProjectName,
+				SilencePRComments: ctx.SilencePRComments,
+			}
+		} else {
+			res = runnerFunc(ctx)
+		}
+
+		if !res.IsSuccessful() && ctx.ProjectName != "" {
+			mux.Lock()
+			failed[ctx.ProjectName] = true
+			mux.Unlock()
+		}
+		return res
+	}
+}
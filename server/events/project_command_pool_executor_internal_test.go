@@ -0,0 +1,100 @@
+package events
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestSkipFailedDependents(t *testing.T) {
+	var ran []string
+	runnerFunc := skipFailedDependents(func(ctx command.ProjectContext) command.ProjectResult {
+		ran = append(ran, ctx.ProjectName)
+		if ctx.ProjectName == "network" {
+			return command.ProjectResult{ProjectName: ctx.ProjectName, Failure: "failed"}
+		}
+		return command.ProjectResult{ProjectName: ctx.ProjectName, ApplySuccess: "success"}
+	})
+
+	cmds := []command.ProjectContext{
+		{ProjectName: "network", ExecutionOrderGroup: 0},
+		{ProjectName: "iam", ExecutionOrderGroup: 0},
+		{ProjectName: "app", ExecutionOrderGroup: 1, DependsOn: []string{"network", "iam"}},
+		{ProjectName: "dns", ExecutionOrderGroup: 1, DependsOn: []string{"iam"}},
+	}
+	result := runProjectCmds(cmds, runnerFunc)
+
+	Equals(t, []string{"network", "iam", "dns"}, ran)
+	Equals(t, "Skipped because its dependencies failed: [network]", result.ProjectResults[2].Failure)
+	Equals(t, "app", result.ProjectResults[2].ProjectName)
+	Equals(t, "success", result.ProjectResults[3].ApplySuccess)
+}
+
+// Test that a project in the same execution order group as its failed
+// dependency is skipped, whether the group runs in parallel or not.
+func TestSkipFailedDependents_SameGroup(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		var ran []string
+		mux := &sync.Mutex{}
+		runnerFunc := skipFailedDependents(func(ctx command.ProjectContext) command.ProjectResult {
+			if ctx.ProjectName == "network" {
+				// Give the dependent the time to start if it doesn't wait.
+				time.Sleep(50 * time.Millisecond)
+			}
+			mux.Lock()
+			ran = append(ran, ctx.ProjectName)
+			mux.Unlock()
+			if ctx.ProjectName == "network" {
+				return command.ProjectResult{ProjectName: ctx.ProjectName, Failure: "failed"}
+			}
+			return command.ProjectResult{ProjectName: ctx.ProjectName, ApplySuccess: "success"}
+		})
+
+		cmds := runAfterDependencies([]command.ProjectContext{
+			{ProjectName: "app", DependsOn: []string{"network"}},
+			{ProjectName: "network"},
+			{ProjectName: "iam"},
+		})
+		var result command.Result
+		if parallel {
+			result = runProjectCmdsParallelGroups(&command.Context{Log: logging.NewNoopLogger(t)}, cmds, runnerFunc, 3)
+		} else {
+			result = runProjectCmds(cmds, runnerFunc)
+		}
+
+		slices.Sort(ran)
+		Equals(t, []string{"iam", "network"}, ran)
+		for _, res := range result.ProjectResults {
+			if res.ProjectName == "app" {
+				Equals(t, "Skipped because its dependencies failed: [network]", res.Failure)
+			}
+		}
+	}
+}
+
+func TestRunAfterDependencies(t *testing.T) {
+	cmds := runAfterDependencies([]command.ProjectContext{
+		{ProjectName: "app", ExecutionOrderGroup: 0, DependsOn: []string{"network", "iam"}},
+		{ProjectName: "network", ExecutionOrderGroup: 0, DependsOn: []string{"vpc"}},
+		{ProjectName: "vpc", ExecutionOrderGroup: 0},
+		{ProjectName: "iam", ExecutionOrderGroup: 2},
+		{ProjectName: "dns", ExecutionOrderGroup: 1, DependsOn: []string{"external"}},
+		// The dependency closing the cycle is ignored.
+		{ProjectName: "a", ExecutionOrderGroup: 5, DependsOn: []string{"b"}},
+		{ProjectName: "b", ExecutionOrderGroup: 5, DependsOn: []string{"a"}},
+	})
+
+	var order []string
+	groups := make(map[string]int)
+	for _, cmd := range cmds {
+		order = append(order, cmd.ProjectName)
+		groups[cmd.ProjectName] = cmd.ExecutionOrderGroup
+	}
+	Equals(t, []string{"vpc", "network", "dns", "iam", "app", "b", "a"}, order)
+	Equals(t, map[string]int{"vpc": 0, "network": 1, "dns": 1, "iam": 2, "app": 3, "a": 6, "b": 5}, groups)
+}
@@ -17,9 +17,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/runtime"
@@ -27,6 +32,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -102,6 +108,20 @@ type MultiEnvStepRunner interface {
 	) (string, error)
 }
 
+// WasmStepRunner runs wasm steps.
+type WasmStepRunner interface {
+	// Run module with args in path.
+	Run(
+		ctx command.ProjectContext,
+		module string,
+		args []string,
+		declaredEnv []string,
+		path string,
+		envs map[string]string,
+		postProcessOutput valid.PostProcessRunOutputOption,
+	) (string, error)
+}
+
 //go:generate pegomock generate --package mocks -o mocks/mock_webhooks_sender.go WebhooksSender
 
 // WebhooksSender sends webhook.
@@ -142,9 +162,21 @@ type ProjectImportCommandRunner interface {
 	Import(ctx command.ProjectContext) command.ProjectResult
 }
 
+type ProjectRollbackCommandRunner interface {
+	// Rollback plans a return to the state recorded before the last apply
+	// of the project described by ctx.
+	Rollback(ctx command.ProjectContext) command.ProjectResult
+}
+
 type ProjectStateCommandRunner interface {
 	// StateRm runs terraform state rm for the project described by ctx.
 	StateRm(ctx command.ProjectContext) command.ProjectResult
+	// StateList runs terraform state list for the project described by ctx.
+	StateList(ctx command.ProjectContext) command.ProjectResult
+	// StateShow runs terraform state show for the project described by ctx.
+	StateShow(ctx command.ProjectContext) command.ProjectResult
+	// ForceUnlockState runs terraform force-unlock for the project described by ctx.
+	ForceUnlockState(ctx command.ProjectContext) command.ProjectResult
 }
 
 // ProjectCommandRunner runs project commands. A project command is a command
@@ -157,6 +189,7 @@ type ProjectCommandRunner interface {
 	ProjectVersionCommandRunner
 	ProjectImportCommandRunner
 	ProjectStateCommandRunner
+	ProjectRollbackCommandRunner
 }
 
 //go:generate pegomock generate --package mocks -o mocks/mock_job_url_setter.go JobURLSetter
@@ -193,6 +226,12 @@ func (p *ProjectOutputWrapper) Apply(ctx command.ProjectContext) command.Project
 	return result
 }
 
+func (p *ProjectOutputWrapper) Rollback(ctx command.ProjectContext) command.ProjectResult {
+	result := p.updateProjectPRStatus(command.Plan, ctx, p.ProjectCommandRunner.Rollback)
+	p.JobMessageSender.Send(ctx, "", OperationComplete)
+	return result
+}
+
 func (p *ProjectOutputWrapper) updateProjectPRStatus(commandName command.Name, ctx command.ProjectContext, execute func(ctx command.ProjectContext) command.ProjectResult) command.ProjectResult {
 	// Create a PR status to track project's plan status. The status will
 	// include a link to view the progress of atlantis plan command in real
@@ -221,32 +260,80 @@ func (p *ProjectOutputWrapper) updateProjectPRStatus(commandName command.Name, c
 
 // DefaultProjectCommandRunner implements ProjectCommandRunner.
 type DefaultProjectCommandRunner struct {
-	VcsClient                 vcs.Client
-	Locker                    ProjectLocker
-	LockURLGenerator          LockURLGenerator
-	Logger                    logging.SimpleLogging
-	InitStepRunner            StepRunner
-	PlanStepRunner            StepRunner
-	ShowStepRunner            StepRunner
-	ApplyStepRunner           StepRunner
-	PolicyCheckStepRunner     StepRunner
-	VersionStepRunner         StepRunner
-	ImportStepRunner          StepRunner
-	StateRmStepRunner         StepRunner
-	RunStepRunner             CustomStepRunner
-	EnvStepRunner             EnvStepRunner
-	MultiEnvStepRunner        MultiEnvStepRunner
-	PullApprovedChecker       runtime.PullApprovedChecker
-	WorkingDir                WorkingDir
-	Webhooks                  WebhooksSender
-	WorkingDirLocker          WorkingDirLocker
-	CommandRequirementHandler CommandRequirementHandler
+	VcsClient                  vcs.Client
+	Locker                     ProjectLocker
+	LockURLGenerator           LockURLGenerator
+	Logger                     logging.SimpleLogging
+	InitStepRunner             StepRunner
+	PlanStepRunner             StepRunner
+	ShowStepRunner             StepRunner
+	ApplyStepRunner            StepRunner
+	PolicyCheckStepRunner      StepRunner
+	VersionStepRunner          StepRunner
+	ImportStepRunner           StepRunner
+	StateRmStepRunner          StepRunner
+	StateListStepRunner        StepRunner
+	StateShowStepRunner        StepRunner
+	ForceUnlockStateStepRunner StepRunner
+	PinCheckStepRunner         StepRunner
+	ProvidersSchemaStepRunner  StepRunner
+	OutputStepRunner           StepRunner
+	RunStepRunner              CustomStepRunner
+	EnvStepRunner              EnvStepRunner
+	MultiEnvStepRunner         MultiEnvStepRunner
+	WasmStepRunner             WasmStepRunner
+	PullApprovedChecker        runtime.PullApprovedChecker
+	WorkingDir                 WorkingDir
+	Webhooks                   WebhooksSender
+	WorkingDirLocker           WorkingDirLocker
+	CommandRequirementHandler  CommandRequirementHandler
+	// SBOMGenerator and SBOMStore generate and store an SBOM after each
+	// successful apply. If either is nil, no SBOMs are generated.
+	SBOMGenerator *runtime.SBOMGenerator
+	SBOMStore     SBOMStore
+	// PlanJSONStore stores the output of terraform show -json after each
+	// successful plan. If it's nil, plans aren't stored.
+	PlanJSONStore PlanJSONStore
+	// PlanChangesStore stores the resource changes of each plan to show what
+	// changed when a project is planned again. If it's nil, they aren't.
+	PlanChangesStore PlanChangesStore
+	// SharedOutputsStore stores the outputs of the projects that share them
+	// after each successful apply. If it's nil, outputs aren't shared.
+	SharedOutputsStore SharedOutputsStore
+	// AutoApprovalAuditLog records the applies approved by the auto-approval
+	// rules of their repo. If it's nil, they aren't recorded.
+	AutoApprovalAuditLog AutoApprovalAuditLog
+	// ApplyWindowAdmins are the usernames allowed to apply projects outside
+	// their apply windows with --override-apply-window.
+	ApplyWindowAdmins []string
+	// StateSnapshotStore stores the state of projects after each successful
+	// apply, pulled with StatePullStepRunner, so they can be rolled back. If
+	// it's nil, snapshots aren't recorded and rollback isn't possible.
+	StateSnapshotStore  StateSnapshotStore
+	StatePullStepRunner StepRunner
+	// ProvenanceStore stores what each successful apply applied. If it's nil,
+	// provenance isn't recorded.
+	ProvenanceStore ProvenanceStore
+	// JobURLGenerator generates the links to the apply jobs recorded in the
+	// provenance.
+	JobURLGenerator jobs.ProjectJobURLGenerator
+	// ReviewCommenters comment on the diffs of pull requests, by VCS host
+	// type, for repos with inline review comments.
+	ReviewCommenters map[models.VCSHostType]vcs.ReviewCommenter
+	// AuthFailureHint renders the hint posted when a plan or apply fails
+	// because the provider rejected Atlantis's credentials. If it's nil,
+	// DefaultAuthFailureHint is used.
+	AuthFailureHint *template.Template
+
+	// shownPlans holds the plan json of the projects being planned or
+	// applied, by planfile, so it's only shown once per run.
+	shownPlans sync.Map
 }
 
 // Plan runs terraform plan for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Plan(ctx command.ProjectContext) command.ProjectResult {
 	planSuccess, failure, err := p.doPlan(ctx)
-	return command.ProjectResult{
+	result := command.ProjectResult{
 		Command:           command.Plan,
 		PlanSuccess:       planSuccess,
 		Error:             err,
@@ -255,7 +342,10 @@ func (p *DefaultProjectCommandRunner) Plan(ctx command.ProjectContext) command.P
 		Workspace:         ctx.Workspace,
 		ProjectName:       ctx.ProjectName,
 		SilencePRComments: ctx.SilencePRComments,
+		Labels:            ctx.Labels,
 	}
+	p.annotateAuthFailure(ctx, &result)
+	return result
 }
 
 // PolicyCheck evaluates policies defined with Rego for the project described by ctx.
@@ -269,13 +359,14 @@ func (p *DefaultProjectCommandRunner) PolicyCheck(ctx command.ProjectContext) co
 		RepoRelDir:         ctx.RepoRelDir,
 		Workspace:          ctx.Workspace,
 		ProjectName:        ctx.ProjectName,
+		Labels:             ctx.Labels,
 	}
 }
 
 // Apply runs terraform apply for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Apply(ctx command.ProjectContext) command.ProjectResult {
 	applyOut, failure, err := p.doApply(ctx)
-	return command.ProjectResult{
+	result := command.ProjectResult{
 		Command:           command.Apply,
 		Failure:           failure,
 		Error:             err,
@@ -284,7 +375,13 @@ func (p *DefaultProjectCommandRunner) Apply(ctx command.ProjectContext) command.
 		Workspace:         ctx.Workspace,
 		ProjectName:       ctx.ProjectName,
 		SilencePRComments: ctx.SilencePRComments,
+		Labels:            ctx.Labels,
 	}
+	if failure == "" && err == nil {
+		result.Annotations = p.previewEnvironmentAnnotations(ctx)
+	}
+	p.annotateAuthFailure(ctx, &result)
+	return result
 }
 
 func (p *DefaultProjectCommandRunner) ApprovePolicies(ctx command.ProjectContext) command.ProjectResult {
@@ -342,9 +439,50 @@ func (p *DefaultProjectCommandRunner) StateRm(ctx command.ProjectContext) comman
 	}
 }
 
+// StateList runs terraform state list for the project described by ctx.
+func (p *DefaultProjectCommandRunner) StateList(ctx command.ProjectContext) command.ProjectResult {
+	return p.stateRead(ctx, "list")
+}
+
+// StateShow runs terraform state show for the project described by ctx.
+func (p *DefaultProjectCommandRunner) StateShow(ctx command.ProjectContext) command.ProjectResult {
+	return p.stateRead(ctx, "show")
+}
+
+func (p *DefaultProjectCommandRunner) stateRead(ctx command.ProjectContext, subCommand string) command.ProjectResult {
+	stateReadSuccess, err := p.doStateRead(ctx)
+	return command.ProjectResult{
+		Command:          command.State,
+		SubCommand:       subCommand,
+		StateReadSuccess: stateReadSuccess,
+		Error:            err,
+		RepoRelDir:       ctx.RepoRelDir,
+		Workspace:        ctx.Workspace,
+		ProjectName:      ctx.ProjectName,
+	}
+}
+
+// ForceUnlockState runs terraform force-unlock for the project described by ctx.
+func (p *DefaultProjectCommandRunner) ForceUnlockState(ctx command.ProjectContext) command.ProjectResult {
+	forceUnlockStateSuccess, failure, err := p.doForceUnlockState(ctx)
+	return command.ProjectResult{
+		Command:                 command.ForceUnlockState,
+		ForceUnlockStateSuccess: forceUnlockStateSuccess,
+		Error:                   err,
+		Failure:                 failure,
+		RepoRelDir:              ctx.RepoRelDir,
+		Workspace:               ctx.Workspace,
+		ProjectName:             ctx.ProjectName,
+	}
+}
+
 func (p *DefaultProjectCommandRunner) doApprovePolicies(ctx command.ProjectContext) (*models.PolicyCheckResults, string, error) {
+	if failure, err := p.checkCommandAllowlist(ctx); failure != "" || err != nil {
+		return nil, failure, err
+	}
+
 	// Acquire Atlantis lock for this repo/dir/workspace.
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnPlanMode)
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnPlanMode, ctx.CommandName)
 	if err != nil {
 		return nil, "", fmt.Errorf("acquiring lock: %w", err)
 	}
@@ -445,7 +583,7 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext)
 	// we will attempt to capture the lock here but fail to get the working directory
 	// at which point we will unlock again to preserve functionality
 	// If we fail to capture the lock here (super unlikely) then we error out and the user is forced to replan
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnPlanMode)
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnPlanMode, ctx.CommandName)
 
 	if err != nil {
 		return nil, "", fmt.Errorf("acquiring lock: %w", err)
@@ -563,8 +701,23 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext)
 }
 
 func (p *DefaultProjectCommandRunner) doPlan(ctx command.ProjectContext) (*models.PlanSuccess, string, error) {
+	return p.doPlanWithSetup(ctx, nil)
+}
+
+// planSetupFunc prepares the project in absPath of the clone in repoDir
+// before its plan steps run. It returns a function undoing the preparation
+// once they've run or a failure if the project can't be planned.
+type planSetupFunc func(repoDir string, absPath string) (cleanup func(), failure string, err error)
+
+// doPlanWithSetup plans the project like doPlan, running setup, if it isn't
+// nil, once the project is locked and cloned.
+func (p *DefaultProjectCommandRunner) doPlanWithSetup(ctx command.ProjectContext, setup planSetupFunc) (*models.PlanSuccess, string, error) {
+	if failure, err := p.checkCommandAllowlist(ctx); failure != "" || err != nil {
+		return nil, failure, err
+	}
+
 	// Acquire Atlantis lock for this repo/dir/workspace.
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnPlanMode)
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnPlanMode, ctx.CommandName)
 	if err != nil {
 		return nil, "", fmt.Errorf("acquiring lock: %w", err)
 	}
@@ -599,25 +752,106 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx command.ProjectContext) (*model
 		return nil, failure, err
 	}
 
+	failure, err = p.checkOwners(ctx, command.Plan)
+	if failure != "" || err != nil {
+		return nil, failure, err
+	}
+
+	// Remove the cost estimate of the previous plan so apply can't check the
+	// budget against it.
+	if err = os.Remove(filepath.Join(projAbsPath, ctx.GetCostEstimateFileName())); err != nil && !os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("removing cost estimate: %w", err)
+	}
+
+	if setup != nil {
+		cleanup, failure, err := setup(repoDir, projAbsPath)
+		if failure != "" || err != nil {
+			return nil, failure, err
+		}
+		defer cleanup()
+	}
+
 	outputs, err := p.runSteps(ctx.Steps, ctx, projAbsPath)
 
 	if err != nil {
+		p.runPostHooks(ctx, ctx.PostPlanHooks, projAbsPath, 1)
 		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
 			ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
 		}
 		return nil, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
 	}
 
+	if ctx.ImpactPreview {
+		return p.discardImpactPreviewPlan(ctx, projAbsPath, lockAttempt.UnlockFn, outputs)
+	}
+
+	// The new plan replaced any rollback plan. The rollback command marks
+	// its plans again once they succeed.
+	if err = removeRollbackPlanMarker(ctx, projAbsPath); err != nil {
+		return nil, "", err
+	}
+
+	defer p.cachePlanJSON(ctx, projAbsPath)()
+	p.savePlanJSON(ctx, projAbsPath)
+	protectedDestroys, err := p.planProtectedDestroys(ctx, projAbsPath)
+	if err != nil {
+		// The guard is checked again on apply so the plan doesn't fail.
+		ctx.Log.Err("checking destroy guard: %s", err)
+	}
+	risk, err := p.planRisk(ctx, projAbsPath)
+	if err != nil {
+		// The risk is scored again on apply so the plan doesn't fail.
+		ctx.Log.Err("scoring plan risk: %s", err)
+	}
+	autoApproval, err := p.planAutoApproval(ctx, projAbsPath)
+	if err != nil {
+		// The plan is checked again on apply so the plan doesn't fail.
+		ctx.Log.Err("checking if the plan is low risk: %s", err)
+	}
+	delta, err := p.planDelta(ctx, projAbsPath)
+	if err != nil {
+		ctx.Log.Err("comparing plan to the previous plan: %s", err)
+	}
+	p.commentRiskyChanges(ctx, repoDir, projAbsPath, delta)
+	if len(ctx.PostPlanHooks) > 0 {
+		p.writePlanJSON(ctx, projAbsPath)
+		p.runPostHooks(ctx, ctx.PostPlanHooks, projAbsPath, 0)
+	}
+	return &models.PlanSuccess{
+		LockURL:           p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey),
+		TerraformOutput:   strings.Join(outputs, "\n"),
+		RePlanCmd:         ctx.RePlanCmd,
+		ApplyCmd:          ctx.ApplyCmd,
+		MergedAgain:       mergedAgain,
+		Targets:           ctx.Targets,
+		ProtectedDestroys: protectedDestroys,
+		Risk:              risk,
+		AutoApproval:      autoApproval,
+		Delta:             delta,
+	}, "", nil
+}
+
+// discardImpactPreviewPlan deletes the plan of a project that was only planned
+// to preview the impact of the pull request and releases its lock so it
+// can't be applied.
+func (p *DefaultProjectCommandRunner) discardImpactPreviewPlan(ctx command.ProjectContext, projAbsPath string, unlockFn func() error, outputs []string) (*models.PlanSuccess, string, error) {
+	if err := os.Remove(filepath.Join(projAbsPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))); err != nil && !os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("deleting impact preview plan: %w", err)
+	}
+	if err := unlockFn(); err != nil {
+		ctx.Log.Err("error unlocking after impact preview plan: %v", err)
+	}
 	return &models.PlanSuccess{
-		LockURL:         p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey),
 		TerraformOutput: strings.Join(outputs, "\n"),
-		RePlanCmd:       ctx.RePlanCmd,
-		ApplyCmd:        ctx.ApplyCmd,
-		MergedAgain:     mergedAgain,
 	}, "", nil
 }
 
 func (p *DefaultProjectCommandRunner) doApply(ctx command.ProjectContext) (applyOut string, failure string, err error) {
+	failure, err = p.checkCommandAllowlist(ctx)
+	if failure != "" || err != nil {
+		return "", failure, err
+	}
+
 	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -630,18 +864,33 @@ func (p *DefaultProjectCommandRunner) doApply(ctx command.ProjectContext) (apply
 		return "", "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
 	}
 
+	defer p.cachePlanJSON(ctx, absPath)()
+	ctx, autoApprovalRules, err := p.autoApprove(ctx, absPath)
+	if err != nil {
+		return "", "", err
+	}
+
 	failure, err = p.CommandRequirementHandler.ValidateApplyProject(repoDir, ctx)
 	if failure != "" || err != nil {
 		return "", failure, err
 	}
 
+	failure, err = p.checkOwners(ctx, command.Apply)
+	if failure != "" || err != nil {
+		return "", failure, err
+	}
+
+	if failure = p.checkApplyWindow(ctx, time.Now()); failure != "" {
+		return "", failure, nil
+	}
+
 	failure, err = p.CommandRequirementHandler.ValidateProjectDependencies(ctx)
 	if failure != "" || err != nil {
 		return "", failure, err
 	}
 
 	// Acquire Atlantis lock for this repo/dir/workspace.
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnApplyMode)
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnApplyMode, ctx.CommandName)
 	if err != nil {
 		return "", "", fmt.Errorf("acquiring lock: %w", err)
 	}
@@ -657,26 +906,243 @@ func (p *DefaultProjectCommandRunner) doApply(ctx command.ProjectContext) (apply
 	}
 	defer unlockFn()
 
+	// Hold the lock keys shared with projects of other repos for the whole
+	// apply.
+	if len(ctx.LockKeys) > 0 {
+		keysAttempt, err := p.Locker.TryLockKeys(ctx.Log, ctx.Pull, ctx.User, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.LockKeys, ctx.CommandName)
+		if err != nil {
+			return "", "", err
+		}
+		if !keysAttempt.LockAcquired {
+			return "", keysAttempt.LockFailureReason, nil
+		}
+		defer func() {
+			if err := keysAttempt.UnlockFn(); err != nil {
+				ctx.Log.Err("releasing lock keys: %s", err)
+			}
+		}()
+	}
+
+	failure, err = p.checkDestroyGuard(ctx, absPath)
+	if failure != "" || err != nil {
+		return "", failure, err
+	}
+
+	failure, err = p.checkCostBudget(ctx, absPath)
+	if failure != "" || err != nil {
+		return "", failure, err
+	}
+
+	failure, err = p.checkRiskApprovals(ctx, absPath)
+	if failure != "" || err != nil {
+		return "", failure, err
+	}
+
+	failure, err = p.checkRollbackApprovals(ctx, absPath)
+	if failure != "" || err != nil {
+		return "", failure, err
+	}
+	rollbackCommit, err := readRollbackPlan(ctx, absPath)
+	if err != nil {
+		return "", "", err
+	}
+	plan := p.readAppliedPlan(ctx, absPath)
+	// The auto-approval is only recorded once every check passed and the
+	// apply runs.
+	if err := p.recordAutoApproval(ctx, autoApprovalRules); err != nil {
+		return "", "", err
+	}
+
 	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
 
 	p.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
-		Workspace:   ctx.Workspace,
-		User:        ctx.User,
-		Repo:        ctx.Pull.BaseRepo,
-		Pull:        ctx.Pull,
-		Success:     err == nil,
-		Directory:   ctx.RepoRelDir,
-		ProjectName: ctx.ProjectName,
+		Workspace:     ctx.Workspace,
+		User:          ctx.User,
+		Repo:          ctx.Pull.BaseRepo,
+		Pull:          ctx.Pull,
+		Success:       err == nil,
+		Directory:     ctx.RepoRelDir,
+		ProjectName:   ctx.ProjectName,
+		Notifications: ctx.Notifications,
 	})
 
+	if len(ctx.PostApplyHooks) > 0 {
+		exitCode := 0
+		if err != nil {
+			exitCode = 1
+		}
+		p.writeOutputsJSON(ctx, absPath)
+		p.runPostHooks(ctx, ctx.PostApplyHooks, absPath, exitCode)
+	}
+
 	if err != nil {
 		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
 	}
 
+	p.saveSBOM(ctx, absPath)
+	p.saveSharedOutputs(ctx, absPath)
+	p.saveStateSnapshot(ctx, absPath, plan, rollbackCommit)
+	p.saveApplyProvenance(ctx, plan)
+	if err := removeRollbackPlanMarker(ctx, absPath); err != nil {
+		ctx.Log.Err("%s", err)
+	}
 	return strings.Join(outputs, "\n"), "", nil
 }
 
+// savePlanJSON stores the output of terraform show -json on the project's
+// planfile. The plan succeeded so errors are logged instead of failing it.
+func (p *DefaultProjectCommandRunner) savePlanJSON(ctx command.ProjectContext, absPath string) {
+	if p.PlanJSONStore == nil || !valid.UsesTerraform(ctx.Tool) {
+		return
+	}
+	out, err := p.showPlan(ctx, absPath)
+	if err != nil {
+		ctx.Log.Err("generating plan json: %s", err)
+		return
+	}
+	if out == "" {
+		// Remote operations and Terraform versions before 0.12 don't have
+		// a planfile to show.
+		return
+	}
+	err = p.PlanJSONStore.Save(models.PlanJSON{
+		RepoFullName: ctx.Pull.BaseRepo.FullName,
+		PullNum:      ctx.Pull.Num,
+		HeadCommit:   ctx.Pull.HeadCommit,
+		ProjectName:  ctx.ProjectName,
+		RepoRelDir:   ctx.RepoRelDir,
+		Workspace:    ctx.Workspace,
+		Labels:       ctx.Labels,
+		PlannedAt:    time.Now(),
+		Plan:         json.RawMessage(out),
+	})
+	if err != nil {
+		ctx.Log.Err("saving plan json: %s", err)
+	}
+}
+
+// writePlanJSON writes the output of terraform show -json on the project's
+// planfile to its showfile for post plan hooks, unless it was already shown.
+func (p *DefaultProjectCommandRunner) writePlanJSON(ctx command.ProjectContext, absPath string) {
+	if !valid.UsesTerraform(ctx.Tool) {
+		return
+	}
+	if _, err := p.showPlan(ctx, absPath); err != nil {
+		ctx.Log.Err("generating plan json for post plan hooks: %s", err)
+	}
+}
+
+// shownPlan is the output of terraform show -json on a planfile.
+type shownPlan struct {
+	once sync.Once
+	out  string
+	err  error
+}
+
+// cachePlanJSON makes showPlan show the project's planfile at most once until
+// the returned function is called at the end of the run. The show step writes
+// the showfile so it's written once too.
+func (p *DefaultProjectCommandRunner) cachePlanJSON(ctx command.ProjectContext, absPath string) func() {
+	planFile := filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+	plan := &shownPlan{}
+	p.shownPlans.Store(planFile, plan)
+	return func() {
+		p.shownPlans.CompareAndDelete(planFile, plan)
+	}
+}
+
+// showPlan returns the output of terraform show -json on the project's
+// planfile, read by the features checking the plan. terraform show is slow
+// so during a run it's only run once, see cachePlanJSON.
+func (p *DefaultProjectCommandRunner) showPlan(ctx command.ProjectContext, absPath string) (string, error) {
+	show := func() (string, error) {
+		return p.ShowStepRunner.Run(ctx, nil, absPath, map[string]string{})
+	}
+	value, ok := p.shownPlans.Load(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName)))
+	if !ok {
+		return show()
+	}
+	plan := value.(*shownPlan)
+	plan.once.Do(func() {
+		plan.out, plan.err = show()
+	})
+	return plan.out, plan.err
+}
+
+// writeOutputsJSON writes the output of terraform output -json to the
+// project's outputs file for post apply hooks. A failed apply may not have
+// any state so errors are only logged.
+func (p *DefaultProjectCommandRunner) writeOutputsJSON(ctx command.ProjectContext, absPath string) {
+	// Don't pass outputs of a previous apply to the hooks.
+	outputsFile := filepath.Join(absPath, ctx.GetOutputsResultFileName())
+	if err := os.Remove(outputsFile); err != nil && !os.IsNotExist(err) {
+		ctx.Log.Err("removing outputs json: %s", err)
+	}
+	if p.OutputStepRunner == nil || !valid.UsesTerraform(ctx.Tool) {
+		return
+	}
+	if _, err := p.OutputStepRunner.Run(ctx, nil, absPath, map[string]string{}); err != nil {
+		ctx.Log.Err("generating outputs json for post apply hooks: %s", err)
+	}
+}
+
+// runPostHooks runs the post plan or post apply hooks of the project in
+// absPath. exitCode is 0 if the project's steps succeeded and 1 otherwise.
+// The command has already finished so hook errors are logged instead of
+// failing it.
+func (p *DefaultProjectCommandRunner) runPostHooks(ctx command.ProjectContext, hooks []*valid.WorkflowHook, absPath string, exitCode int) {
+	envs := map[string]string{
+		"COMMAND_NAME": ctx.CommandName.String(),
+		"EXIT_CODE":    strconv.Itoa(exitCode),
+		"OUTPUTS_FILE": filepath.Join(absPath, ctx.GetOutputsResultFileName()),
+	}
+	for i, hook := range hooks {
+		description := hook.StepDescription
+		if description == "" {
+			description = fmt.Sprintf("post %s hook #%d", ctx.CommandName, i)
+		}
+		var shell *valid.CommandShell
+		if hook.Shell != "" || hook.ShellArgs != "" {
+			shell = &valid.CommandShell{Shell: hook.Shell, ShellArgs: strings.Split(hook.ShellArgs, " ")}
+			if shell.Shell == "" {
+				shell.Shell = "sh"
+			}
+			if hook.ShellArgs == "" {
+				shell.ShellArgs = []string{"-c"}
+			}
+		}
+		ctx.Log.Debug("running %s", description)
+		if _, err := p.RunStepRunner.Run(ctx, shell, hook.RunCommand, absPath, envs, true, valid.PostProcessRunOutputShow); err != nil {
+			ctx.Log.Err("running %s: %s", description, err)
+		}
+	}
+}
+
+// saveSBOM generates and stores the SBOM of an applied project. The apply
+// has already changed infrastructure so errors are logged instead of
+// failing it.
+func (p *DefaultProjectCommandRunner) saveSBOM(ctx command.ProjectContext, absPath string) {
+	if p.SBOMGenerator == nil || p.SBOMStore == nil || !valid.UsesTerraform(ctx.Tool) {
+		return
+	}
+	sbom, err := p.SBOMGenerator.Generate(ctx, absPath)
+	if err != nil {
+		ctx.Log.Err("generating sbom: %s", err)
+		return
+	}
+	if err := p.SBOMStore.Save(sbom); err != nil {
+		ctx.Log.Err("saving sbom: %s", err)
+		return
+	}
+	ctx.Log.Info("saved sbom for job %s", sbom.JobID)
+}
+
 func (p *DefaultProjectCommandRunner) doVersion(ctx command.ProjectContext) (versionOut string, failure string, err error) {
+	failure, err = p.checkCommandAllowlist(ctx)
+	if failure != "" || err != nil {
+		return "", failure, err
+	}
+
 	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -705,6 +1171,11 @@ func (p *DefaultProjectCommandRunner) doVersion(ctx command.ProjectContext) (ver
 }
 
 func (p *DefaultProjectCommandRunner) doImport(ctx command.ProjectContext) (out *models.ImportSuccess, failure string, err error) {
+	failure, err = p.checkCommandAllowlist(ctx)
+	if failure != "" || err != nil {
+		return nil, failure, err
+	}
+
 	// Clone is idempotent so okay to run even if the repo was already cloned.
 	repoDir, _, cloneErr := p.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, ctx.Workspace)
 	if cloneErr != nil {
@@ -721,7 +1192,7 @@ func (p *DefaultProjectCommandRunner) doImport(ctx command.ProjectContext) (out
 	}
 
 	// Acquire Atlantis lock for this repo/dir/workspace.
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode != valid.RepoLocksDisabledMode)
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode != valid.RepoLocksDisabledMode, ctx.CommandName)
 	if err != nil {
 		return nil, "", fmt.Errorf("acquiring lock: %w", err)
 	}
@@ -751,6 +1222,11 @@ func (p *DefaultProjectCommandRunner) doImport(ctx command.ProjectContext) (out
 }
 
 func (p *DefaultProjectCommandRunner) doStateRm(ctx command.ProjectContext) (out *models.StateRmSuccess, failure string, err error) {
+	failure, err = p.checkCommandAllowlist(ctx)
+	if failure != "" || err != nil {
+		return nil, failure, err
+	}
+
 	// Clone is idempotent so okay to run even if the repo was already cloned.
 	repoDir, _, cloneErr := p.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, ctx.Workspace)
 	if cloneErr != nil {
@@ -762,7 +1238,7 @@ func (p *DefaultProjectCommandRunner) doStateRm(ctx command.ProjectContext) (out
 	}
 
 	// Acquire Atlantis lock for this repo/dir/workspace.
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode != valid.RepoLocksDisabledMode)
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode != valid.RepoLocksDisabledMode, ctx.CommandName)
 	if err != nil {
 		return nil, "", fmt.Errorf("acquiring lock: %w", err)
 	}
@@ -791,30 +1267,133 @@ func (p *DefaultProjectCommandRunner) doStateRm(ctx command.ProjectContext) (out
 	}, "", nil
 }
 
+// doStateRead runs the steps of state list or state show. They don't change
+// the state or the plan so unlike state rm they don't take the project lock.
+func (p *DefaultProjectCommandRunner) doStateRead(ctx command.ProjectContext) (out string, err error) {
+	failure, err := p.checkCommandAllowlist(ctx)
+	if err != nil {
+		return "", err
+	}
+	if failure != "" {
+		return "", errors.New(failure)
+	}
+
+	// Clone is idempotent so okay to run even if the repo was already cloned.
+	repoDir, _, cloneErr := p.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, ctx.Workspace)
+	if cloneErr != nil {
+		return "", cloneErr
+	}
+	projAbsPath := filepath.Join(repoDir, ctx.RepoRelDir)
+	if _, err = os.Stat(projAbsPath); os.IsNotExist(err) {
+		return "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
+	}
+
+	// Acquire internal lock for the directory we're going to operate in.
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
+	if err != nil {
+		return "", err
+	}
+	defer unlockFn()
+
+	outputs, err := p.runSteps(ctx.Steps, ctx, projAbsPath)
+	if err != nil {
+		return "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+	}
+	return strings.Join(outputs, "\n"), nil
+}
+
+func (p *DefaultProjectCommandRunner) doForceUnlockState(ctx command.ProjectContext) (out string, failure string, err error) {
+	failure, err = p.checkCommandAllowlist(ctx)
+	if failure != "" || err != nil {
+		return "", failure, err
+	}
+
+	// Clone is idempotent so okay to run even if the repo was already cloned.
+	repoDir, _, cloneErr := p.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, ctx.Workspace)
+	if cloneErr != nil {
+		return "", "", cloneErr
+	}
+	projAbsPath := filepath.Join(repoDir, ctx.RepoRelDir)
+	if _, err = os.Stat(projAbsPath); os.IsNotExist(err) {
+		return "", "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
+	}
+
+	// Acquire Atlantis lock for this repo/dir/workspace.
+	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode != valid.RepoLocksDisabledMode, ctx.CommandName)
+	if err != nil {
+		return "", "", fmt.Errorf("acquiring lock: %w", err)
+	}
+	if !lockAttempt.LockAcquired {
+		return "", lockAttempt.LockFailureReason, nil
+	}
+	ctx.Log.Debug("acquired lock for project")
+
+	// Acquire internal lock for the directory we're going to operate in.
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace, ctx.RepoRelDir)
+	if err != nil {
+		return "", "", err
+	}
+	defer unlockFn()
+
+	outputs, err := p.runSteps(ctx.Steps, ctx, projAbsPath)
+	if err != nil {
+		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+	}
+	return strings.Join(outputs, "\n"), "", nil
+}
+
+// stepExtraArgs returns the extra arguments of step, the project's default
+// ones for the step followed by the ones set in the workflow.
+func stepExtraArgs(ctx command.ProjectContext, step valid.Step) []string {
+	if len(ctx.ExtraArgs[step.StepName]) == 0 {
+		return step.ExtraArgs
+	}
+	return append(append([]string{}, ctx.ExtraArgs[step.StepName]...), step.ExtraArgs...)
+}
+
 func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx command.ProjectContext, absPath string) ([]string, error) {
 	var outputs []string
 
 	envs := make(map[string]string)
+	// Env steps can override the output variables of pre workflow hooks.
+	maps.Copy(envs, ctx.HookOutputVars)
+	if err := addPRMetadataVars(ctx, p.VcsClient, absPath, envs); err != nil {
+		return nil, err
+	}
+	sensitive, err := addDependencyOutputs(ctx, p.SharedOutputsStore, absPath, envs)
+	if err != nil {
+		return nil, err
+	}
 	for _, step := range steps {
 		var out string
 		var err error
 		switch step.StepName {
 		case "init":
-			out, err = p.InitStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+			out, err = p.InitStepRunner.Run(ctx, stepExtraArgs(ctx, step), absPath, envs)
 		case "plan":
-			out, err = p.PlanStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+			out, err = p.PlanStepRunner.Run(ctx, stepExtraArgs(ctx, step), absPath, envs)
 		case "show":
 			_, err = p.ShowStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
 		case "policy_check":
 			out, err = p.PolicyCheckStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
 		case "apply":
-			out, err = p.ApplyStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+			out, err = p.ApplyStepRunner.Run(ctx, stepExtraArgs(ctx, step), absPath, envs)
 		case "version":
 			out, err = p.VersionStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
 		case "import":
-			out, err = p.ImportStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+			out, err = p.ImportStepRunner.Run(ctx, stepExtraArgs(ctx, step), absPath, envs)
 		case "state_rm":
 			out, err = p.StateRmStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "state_list":
+			out, err = p.StateListStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "state_show":
+			out, err = p.StateShowStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "force_unlock":
+			out, err = p.ForceUnlockStateStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "pin_check":
+			out, err = p.PinCheckStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "providers_schema":
+			_, err = p.ProvidersSchemaStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
 		case "run":
 			out, err = p.RunStepRunner.Run(ctx, step.RunShell, step.RunCommand, absPath, envs, true, step.Output)
 		case "env":
@@ -825,10 +1404,12 @@ func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx command.P
 			out = ""
 		case "multienv":
 			out, err = p.MultiEnvStepRunner.Run(ctx, step.RunShell, step.RunCommand, absPath, envs, step.Output)
+		case "wasm":
+			out, err = p.WasmStepRunner.Run(ctx, step.WasmModule, step.WasmArgs, step.WasmEnv, absPath, envs, step.Output)
 		}
 
 		if out != "" {
-			outputs = append(outputs, out)
+			outputs = append(outputs, maskSensitiveOutputs(out, sensitive))
 		}
 		if err != nil {
 			return outputs, err
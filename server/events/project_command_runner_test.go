@@ -17,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/go-version"
@@ -68,7 +69,7 @@ func TestDefaultProjectCommandRunner_Plan(t *testing.T) {
 	When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
 		Any[string]())).ThenReturn(repoDir, false, nil)
 	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
-		Any[models.Project](), AnyBool())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+		Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
 
 	expEnvs := map[string]string{
 		"name": "value",
@@ -122,6 +123,625 @@ func TestDefaultProjectCommandRunner_Plan(t *testing.T) {
 	}
 }
 
+func TestDefaultProjectCommandRunner_PlanStoresPlanJSON(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockShow := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+	planJSONStore := &events.FilePlanJSONStore{Dir: t.TempDir()}
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:                    mockLocker,
+		LockURLGenerator:          mockURLGenerator{},
+		PlanStepRunner:            mockPlan,
+		ShowStepRunner:            mockShow,
+		WorkingDir:                mockWorkingDir,
+		WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+		CommandRequirementHandler: mocks.NewMockCommandRequirementHandler(),
+		PlanJSONStore:             planJSONStore,
+	}
+
+	repoDir := t.TempDir()
+	When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Any[string]())).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+		Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+
+	ctx := command.ProjectContext{
+		Log:         logging.NewNoopLogger(t),
+		Steps:       []valid.Step{{StepName: "plan"}},
+		Workspace:   "default",
+		RepoRelDir:  ".",
+		ProjectName: "project",
+		Pull: models.PullRequest{
+			Num:        1,
+			HeadCommit: "abc123",
+			BaseRepo:   models.Repo{FullName: "owner/repo"},
+		},
+	}
+	When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+	When(mockShow.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn(`{"format_version":"1.2"}`, nil)
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+
+	plans, err := planJSONStore.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, 1, len(plans))
+	Equals(t, "project", plans[0].ProjectName)
+	Equals(t, "abc123", plans[0].HeadCommit)
+	Equals(t, `{"format_version":"1.2"}`, string(plans[0].Plan))
+}
+
+// Test that the plan is only shown once however many features read its
+// json.
+func TestDefaultProjectCommandRunner_PlanShowsPlanOnce(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockShow := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:                    mockLocker,
+		LockURLGenerator:          mockURLGenerator{},
+		PlanStepRunner:            mockPlan,
+		ShowStepRunner:            mockShow,
+		WorkingDir:                mockWorkingDir,
+		WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+		CommandRequirementHandler: mocks.NewMockCommandRequirementHandler(),
+		PlanJSONStore:             &events.FilePlanJSONStore{Dir: t.TempDir()},
+		PlanChangesStore:          &events.FilePlanChangesStore{Dir: t.TempDir()},
+	}
+
+	repoDir := t.TempDir()
+	When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Any[string]())).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+		Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+
+	ctx := command.ProjectContext{
+		Log:          logging.NewNoopLogger(t),
+		Steps:        []valid.Step{{StepName: "plan"}},
+		Workspace:    "default",
+		RepoRelDir:   ".",
+		DestroyGuard: &valid.DestroyGuard{ResourceTypes: []string{"aws_db_instance"}},
+		RiskScoring:  &valid.RiskScoring{},
+		AutoApproval: &valid.AutoApproval{},
+		Pull: models.PullRequest{
+			Num:      1,
+			BaseRepo: models.Repo{FullName: "owner/repo"},
+		},
+	}
+	planJSON := `{"resource_changes": [{"address": "aws_db_instance.main", "mode": "managed", "type": "aws_db_instance", "change": {"actions": ["delete"]}}]}`
+	When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+	When(mockShow.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn(planJSON, nil)
+	res := runner.Plan(ctx)
+
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	Equals(t, []string{"aws_db_instance.main"}, res.PlanSuccess.ProtectedDestroys)
+	Assert(t, res.PlanSuccess.Risk != nil, "exp the plan to be scored")
+	mockShow.VerifyWasCalledOnce().Run(ctx, nil, repoDir, map[string]string{})
+
+	// The next plan is shown again.
+	res = runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	mockShow.VerifyWasCalled(Times(2)).Run(ctx, nil, repoDir, map[string]string{})
+}
+
+func TestDefaultProjectCommandRunner_PostPlanHooks(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockShow := mocks.NewMockStepRunner()
+	mockRun := mocks.NewMockCustomStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:                    mockLocker,
+		LockURLGenerator:          mockURLGenerator{},
+		PlanStepRunner:            mockPlan,
+		ShowStepRunner:            mockShow,
+		RunStepRunner:             mockRun,
+		WorkingDir:                mockWorkingDir,
+		WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+		CommandRequirementHandler: mocks.NewMockCommandRequirementHandler(),
+	}
+
+	repoDir := t.TempDir()
+	When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Any[string]())).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+		Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+
+	ctx := command.ProjectContext{
+		CommandName:   command.Plan,
+		Log:           logging.NewNoopLogger(t),
+		Steps:         []valid.Step{{StepName: "plan"}},
+		PostPlanHooks: []*valid.WorkflowHook{{RunCommand: "./update-cmdb.sh"}},
+		Workspace:     "default",
+		RepoRelDir:    ".",
+	}
+	expHookEnvs := map[string]string{
+		"COMMAND_NAME": "plan",
+		"EXIT_CODE":    "0",
+		"OUTPUTS_FILE": filepath.Join(repoDir, "default-outputs.json"),
+	}
+	When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+	When(mockRun.Run(ctx, nil, "./update-cmdb.sh", repoDir, expHookEnvs, true, valid.PostProcessRunOutputShow)).
+		ThenReturn("", errors.New("cmdb unavailable"))
+	res := runner.Plan(ctx)
+
+	// Hook failures don't fail the plan.
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	Equals(t, "plan", res.PlanSuccess.TerraformOutput)
+	mockShow.VerifyWasCalledOnce().Run(ctx, nil, repoDir, map[string]string{})
+	mockRun.VerifyWasCalledOnce().Run(ctx, nil, "./update-cmdb.sh", repoDir, expHookEnvs, true, valid.PostProcessRunOutputShow)
+}
+
+func TestDefaultProjectCommandRunner_PostApplyHooks(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockApply := mocks.NewMockStepRunner()
+	mockOutput := mocks.NewMockStepRunner()
+	mockRun := mocks.NewMockCustomStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:                    mockLocker,
+		LockURLGenerator:          mockURLGenerator{},
+		ApplyStepRunner:           mockApply,
+		OutputStepRunner:          mockOutput,
+		RunStepRunner:             mockRun,
+		WorkingDir:                mockWorkingDir,
+		WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+		CommandRequirementHandler: &events.DefaultCommandRequirementHandler{WorkingDir: mockWorkingDir},
+		Webhooks:                  mocks.NewMockWebhooksSender(),
+	}
+	repoDir := t.TempDir()
+	When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).
+		ThenReturn(repoDir, nil)
+	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+		Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+
+	ctx := command.ProjectContext{
+		CommandName: command.Apply,
+		Log:         logging.NewNoopLogger(t),
+		Steps:       []valid.Step{{StepName: "apply"}},
+		PostApplyHooks: []*valid.WorkflowHook{{
+			RunCommand: "./invalidate-cache.sh",
+			Shell:      "bash",
+		}},
+		Workspace:         "default",
+		ProjectName:       "project",
+		ApplyRequirements: []string{},
+		RepoRelDir:        ".",
+	}
+	// Outputs of a previous apply are removed.
+	outputsFile := filepath.Join(repoDir, "project-default-outputs.json")
+	Ok(t, os.WriteFile(outputsFile, []byte("{}"), 0600))
+	expHookEnvs := map[string]string{
+		"COMMAND_NAME": "apply",
+		"EXIT_CODE":    "1",
+		"OUTPUTS_FILE": outputsFile,
+	}
+	expShell := &valid.CommandShell{Shell: "bash", ShellArgs: []string{"-c"}}
+	When(mockApply.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("apply", errors.New("apply failed"))
+	When(mockOutput.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("", errors.New("no state"))
+	res := runner.Apply(ctx)
+
+	ErrContains(t, "apply failed", res.Error)
+	_, err := os.Stat(outputsFile)
+	Assert(t, os.IsNotExist(err), "exp outputs of the previous apply to be removed")
+	mockOutput.VerifyWasCalledOnce().Run(ctx, nil, repoDir, map[string]string{})
+	mockRun.VerifyWasCalledOnce().Run(ctx, expShell, "./invalidate-cache.sh", repoDir, expHookEnvs, true, valid.PostProcessRunOutputShow)
+}
+
+func TestDefaultProjectCommandRunner_ApplyDestroyGuard(t *testing.T) {
+	planJSON := `{"resource_changes": [
+		{"address": "aws_db_instance.db", "mode": "managed", "type": "aws_db_instance", "change": {"actions": ["delete", "create"]}},
+		{"address": "aws_db_parameter_group.db", "mode": "managed", "type": "aws_db_parameter_group", "change": {"actions": ["update"]}},
+		{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "change": {"actions": ["delete"]}}
+	]}`
+	cases := []struct {
+		description    string
+		guard          *valid.DestroyGuard
+		confirmDestroy bool
+		user           string
+		expFailure     string
+	}{
+		{
+			description: "no guard",
+			user:        "author",
+		},
+		{
+			description: "no protected resources destroyed",
+			guard:       &valid.DestroyGuard{ResourceTypes: []string{"aws_s3_bucket"}},
+			user:        "author",
+		},
+		{
+			description: "not confirmed",
+			guard:       &valid.DestroyGuard{ResourceTypes: []string{"aws_db_*"}},
+			user:        "author",
+			expFailure:  "This plan destroys or replaces the protected resources `aws_db_instance.db`. To apply it anyway, comment `atlantis apply -p project --confirm-destroy`.",
+		},
+		{
+			description:    "confirmed",
+			guard:          &valid.DestroyGuard{ResourceTypes: []string{"aws_db_*"}},
+			confirmDestroy: true,
+			user:           "author",
+		},
+		{
+			description: "not confirmed with other user required",
+			guard:       &valid.DestroyGuard{ResourceTypes: []string{"aws_db_*", "aws_instance"}, RequireOtherUser: true},
+			user:        "author",
+			expFailure:  "This plan destroys or replaces the protected resources `aws_db_instance.db`, `aws_instance.web`. To apply it anyway, comment `atlantis apply -p project --confirm-destroy`. The confirmation must come from someone other than the pull request author @author.",
+		},
+		{
+			description:    "confirmed by the author with other user required",
+			guard:          &valid.DestroyGuard{ResourceTypes: []string{"aws_db_*"}, RequireOtherUser: true},
+			confirmDestroy: true,
+			user:           "author",
+			expFailure:     "This plan destroys or replaces the protected resources `aws_db_instance.db`. The --confirm-destroy confirmation must come from someone other than the pull request author @author.",
+		},
+		{
+			description:    "confirmed by another user",
+			guard:          &valid.DestroyGuard{ResourceTypes: []string{"aws_db_*"}, RequireOtherUser: true},
+			confirmDestroy: true,
+			user:           "reviewer",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockApply := mocks.NewMockStepRunner()
+			mockShow := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                    mockLocker,
+				LockURLGenerator:          mockURLGenerator{},
+				ApplyStepRunner:           mockApply,
+				ShowStepRunner:            mockShow,
+				WorkingDir:                mockWorkingDir,
+				WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+				CommandRequirementHandler: &events.DefaultCommandRequirementHandler{WorkingDir: mockWorkingDir},
+				Webhooks:                  mocks.NewMockWebhooksSender(),
+			}
+			repoDir := t.TempDir()
+			When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).
+				ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+				Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+
+			ctx := command.ProjectContext{
+				CommandName:       command.Apply,
+				Log:               logging.NewNoopLogger(t),
+				Steps:             []valid.Step{{StepName: "apply"}},
+				Workspace:         "default",
+				ProjectName:       "project",
+				ApplyRequirements: []string{},
+				RepoRelDir:        ".",
+				ApplyCmd:          "atlantis apply -p project",
+				DestroyGuard:      c.guard,
+				ConfirmDestroy:    c.confirmDestroy,
+				User:              models.User{Username: c.user},
+				Pull:              models.PullRequest{Author: "author"},
+			}
+			When(mockShow.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn(planJSON, nil)
+			When(mockApply.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("apply", nil)
+			res := runner.Apply(ctx)
+
+			Equals(t, c.expFailure, res.Failure)
+			if c.expFailure != "" {
+				mockApply.VerifyWasCalled(Never()).Run(ctx, nil, repoDir, map[string]string{})
+			} else {
+				Ok(t, res.Error)
+				Equals(t, "apply", res.ApplySuccess)
+			}
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_ApplyLockKeys(t *testing.T) {
+	cases := []struct {
+		description string
+		acquired    bool
+		expFailure  string
+	}{
+		{
+			description: "keys acquired",
+			acquired:    true,
+		},
+		{
+			description: "key held by another pull request",
+			expFailure:  "This project shares the lock key `network-core` with project `peering` of pull owner/network#7.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockApply := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                    mockLocker,
+				LockURLGenerator:          mockURLGenerator{},
+				ApplyStepRunner:           mockApply,
+				WorkingDir:                mockWorkingDir,
+				WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+				CommandRequirementHandler: &events.DefaultCommandRequirementHandler{WorkingDir: mockWorkingDir},
+				Webhooks:                  mocks.NewMockWebhooksSender(),
+			}
+			repoDir := t.TempDir()
+			When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).
+				ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+				Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+			released := false
+			When(mockLocker.TryLockKeys(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[models.Project](),
+				Any[[]string](), Any[command.Name]())).ThenReturn(&events.TryLockResponse{
+				LockAcquired:      c.acquired,
+				LockFailureReason: c.expFailure,
+				UnlockFn: func() error {
+					released = true
+					return nil
+				},
+			}, nil)
+
+			ctx := command.ProjectContext{
+				CommandName:       command.Apply,
+				Log:               logging.NewNoopLogger(t),
+				Steps:             []valid.Step{{StepName: "apply"}},
+				Workspace:         "default",
+				ProjectName:       "vpc",
+				ApplyRequirements: []string{},
+				RepoRelDir:        ".",
+				LockKeys:          []string{"network-core"},
+				Pull:              models.PullRequest{BaseRepo: models.Repo{FullName: "owner/app"}},
+			}
+			When(mockApply.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("apply", nil)
+			res := runner.Apply(ctx)
+
+			Equals(t, c.expFailure, res.Failure)
+			mockLocker.VerifyWasCalledOnce().TryLockKeys(ctx.Log, ctx.Pull, ctx.User, models.NewProject("owner/app", ".", "vpc"), []string{"network-core"}, command.Apply)
+			if c.acquired {
+				Equals(t, "apply", res.ApplySuccess)
+				Assert(t, released, "expected the lock keys to be released after the apply")
+			} else {
+				mockApply.VerifyWasCalled(Never()).Run(ctx, nil, repoDir, map[string]string{})
+			}
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_ApplyOwners(t *testing.T) {
+	codeOwners := `
+# Default owners.
+*                @org/admins
+/networking/     @org/network @alice
+`
+	cases := []struct {
+		description string
+		owners      *valid.PolicyOwners
+		codeOwners  bool
+		user        string
+		approvedBy  string
+		expFailure  string
+	}{
+		{
+			description: "no owners",
+			user:        "bob",
+		},
+		{
+			description: "run by owner",
+			owners:      &valid.PolicyOwners{Users: []string{"alice"}},
+			user:        "alice",
+		},
+		{
+			description: "run by owner team member",
+			owners:      &valid.PolicyOwners{Teams: []string{"network"}},
+			user:        "carol",
+		},
+		{
+			description: "approved by owner",
+			owners:      &valid.PolicyOwners{Users: []string{"alice"}},
+			user:        "bob",
+			approvedBy:  "alice",
+		},
+		{
+			description: "not run or approved by owner",
+			owners:      &valid.PolicyOwners{Users: []string{"alice"}},
+			user:        "bob",
+			approvedBy:  "dave",
+			expFailure:  "Only the owners of this project can run apply, or one of them must approve the pull request: @alice.",
+		},
+		{
+			description: "run by codeowner",
+			codeOwners:  true,
+			user:        "alice",
+		},
+		{
+			description: "not run by codeowner",
+			codeOwners:  true,
+			user:        "bob",
+			expFailure:  "Only the owners of this project can run apply, or one of them must approve the pull request: @alice, org/network, network.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockApply := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			mockVcsClient := vcsmocks.NewMockClient()
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                    mockLocker,
+				LockURLGenerator:          mockURLGenerator{},
+				ApplyStepRunner:           mockApply,
+				WorkingDir:                mockWorkingDir,
+				WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+				CommandRequirementHandler: &events.DefaultCommandRequirementHandler{WorkingDir: mockWorkingDir},
+				Webhooks:                  mocks.NewMockWebhooksSender(),
+				VcsClient:                 mockVcsClient,
+			}
+			repoDir := t.TempDir()
+			Ok(t, os.Mkdir(filepath.Join(repoDir, "networking"), 0700))
+			When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).
+				ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+				Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+			When(mockVcsClient.GetTeamNamesForUser(Any[logging.SimpleLogging](), Any[models.Repo](), Eq(models.User{Username: "carol"}))).
+				ThenReturn([]string{"Network", "network"}, nil)
+			When(mockVcsClient.SupportsSingleFileDownload(Any[models.Repo]())).ThenReturn(true)
+			When(mockVcsClient.GetFileContent(Any[logging.SimpleLogging](), Any[models.PullRequest](), Eq(".github/CODEOWNERS"))).
+				ThenReturn(true, []byte(codeOwners), nil)
+
+			ctx := command.ProjectContext{
+				CommandName:       command.Apply,
+				Log:               logging.NewNoopLogger(t),
+				Steps:             []valid.Step{{StepName: "apply"}},
+				Workspace:         "default",
+				ApplyRequirements: []string{},
+				RepoRelDir:        "networking",
+				Owners:            c.owners,
+				CodeOwners:        c.codeOwners,
+				User:              models.User{Username: c.user},
+				Pull: models.PullRequest{
+					Author:     "bob",
+					HeadBranch: "feature",
+					BaseBranch: "main",
+					BaseRepo:   models.Repo{Owner: "org", Name: "repo"},
+				},
+				PullReqStatus: models.PullReqStatus{
+					ApprovalStatus: models.ApprovalStatus{IsApproved: c.approvedBy != "", ApprovedBy: c.approvedBy},
+				},
+			}
+			projDir := filepath.Join(repoDir, "networking")
+			When(mockApply.Run(ctx, nil, projDir, map[string]string{})).ThenReturn("apply", nil)
+			res := runner.Apply(ctx)
+
+			Equals(t, c.expFailure, res.Failure)
+			if c.expFailure != "" {
+				mockApply.VerifyWasCalled(Never()).Run(ctx, nil, projDir, map[string]string{})
+			} else {
+				Ok(t, res.Error)
+				Equals(t, "apply", res.ApplySuccess)
+			}
+			if c.codeOwners {
+				// The CODEOWNERS file is read from the base branch.
+				basePull := ctx.Pull
+				basePull.HeadBranch = "main"
+				mockVcsClient.VerifyWasCalledOnce().GetFileContent(Any[logging.SimpleLogging](), Eq(basePull), Eq(".github/CODEOWNERS"))
+			}
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_ApplyCommandAllowlist(t *testing.T) {
+	sre := valid.CommandAllowlistRule{Commands: []string{"apply"}, Dirs: []string{"prod/**"}, Allowed: valid.PolicyOwners{Teams: []string{"sre"}}}
+	cases := []struct {
+		description      string
+		allowlist        []valid.CommandAllowlistRule
+		projectAllowlist []valid.CommandAllowlistRule
+		user             string
+		expFailure       string
+	}{
+		{
+			description: "no allowlist",
+			user:        "bob",
+		},
+		{
+			description: "rule for another command",
+			allowlist:   []valid.CommandAllowlistRule{{Commands: []string{"state"}, Allowed: valid.PolicyOwners{Users: []string{"alice"}}}},
+			user:        "bob",
+		},
+		{
+			description: "rule for another dir",
+			allowlist:   []valid.CommandAllowlistRule{{Commands: []string{"apply"}, Dirs: []string{"staging/**"}, Allowed: valid.PolicyOwners{Users: []string{"alice"}}}},
+			user:        "bob",
+		},
+		{
+			description: "allowed team member",
+			allowlist:   []valid.CommandAllowlistRule{sre},
+			user:        "carol",
+		},
+		{
+			description: "allowed by one of the matching rules",
+			allowlist:   []valid.CommandAllowlistRule{sre, {Commands: []string{"plan", "apply"}, Allowed: valid.PolicyOwners{Users: []string{"bob"}}}},
+			user:        "bob",
+		},
+		{
+			description: "not allowed",
+			allowlist:   []valid.CommandAllowlistRule{sre},
+			user:        "bob",
+			expFailure:  "@bob is not allowed to run apply on this project, only sre can.",
+		},
+		{
+			description:      "not allowed by the project",
+			allowlist:        []valid.CommandAllowlistRule{sre},
+			projectAllowlist: []valid.CommandAllowlistRule{{Commands: []string{"apply"}, Allowed: valid.PolicyOwners{Users: []string{"alice"}}}},
+			user:             "carol",
+			expFailure:       "@carol is not allowed to run apply on this project, only @alice can.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockApply := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			mockVcsClient := vcsmocks.NewMockClient()
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                    mockLocker,
+				LockURLGenerator:          mockURLGenerator{},
+				ApplyStepRunner:           mockApply,
+				WorkingDir:                mockWorkingDir,
+				WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+				CommandRequirementHandler: &events.DefaultCommandRequirementHandler{WorkingDir: mockWorkingDir},
+				Webhooks:                  mocks.NewMockWebhooksSender(),
+				VcsClient:                 mockVcsClient,
+			}
+			repoDir := t.TempDir()
+			Ok(t, os.MkdirAll(filepath.Join(repoDir, "prod", "vpc"), 0700))
+			When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).
+				ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+				Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+			When(mockVcsClient.GetTeamNamesForUser(Any[logging.SimpleLogging](), Any[models.Repo](), Eq(models.User{Username: "carol"}))).
+				ThenReturn([]string{"sre"}, nil)
+
+			ctx := command.ProjectContext{
+				CommandName:             command.Apply,
+				Log:                     logging.NewNoopLogger(t),
+				Steps:                   []valid.Step{{StepName: "apply"}},
+				Workspace:               "default",
+				ApplyRequirements:       []string{},
+				RepoRelDir:              "prod/vpc",
+				CommandAllowlist:        c.allowlist,
+				ProjectCommandAllowlist: c.projectAllowlist,
+				User:                    models.User{Username: c.user},
+				Pull:                    models.PullRequest{BaseRepo: models.Repo{Owner: "org", Name: "repo"}},
+			}
+			projDir := filepath.Join(repoDir, "prod", "vpc")
+			When(mockApply.Run(ctx, nil, projDir, map[string]string{})).ThenReturn("apply", nil)
+			res := runner.Apply(ctx)
+
+			Equals(t, c.expFailure, res.Failure)
+			if c.expFailure != "" {
+				mockApply.VerifyWasCalled(Never()).Run(ctx, nil, projDir, map[string]string{})
+			} else {
+				Ok(t, res.Error)
+				Equals(t, "apply", res.ApplySuccess)
+			}
+		})
+	}
+}
+
 func TestProjectOutputWrapper(t *testing.T) {
 	RegisterMockTestingT(t)
 	ctx := command.ProjectContext{
@@ -265,6 +885,62 @@ func TestDefaultProjectCommandRunner_ApplyNotApproved(t *testing.T) {
 	Equals(t, "Pull request must be approved according to the project's approval rules before running apply.", res.Failure)
 }
 
+// Test that an auto-approved apply is only recorded in the audit log once it
+// runs, not when a later check like the project lock stops it.
+func TestDefaultProjectCommandRunner_ApplyAutoApprovalAudit(t *testing.T) {
+	lowRiskPlanJSON := `{"resource_changes": [{"mode": "managed", "type": "aws_route53_record", "change": {"actions": ["create"]}}]}`
+	for _, lockAcquired := range []bool{false, true} {
+		t.Run(fmt.Sprintf("lock acquired %t", lockAcquired), func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			mockShow := mocks.NewMockStepRunner()
+			mockApply := mocks.NewMockStepRunner()
+			auditLogPath := filepath.Join(t.TempDir(), "auto-approvals.jsonl")
+			runner := &events.DefaultProjectCommandRunner{
+				Locker:                    mockLocker,
+				LockURLGenerator:          mockURLGenerator{},
+				ShowStepRunner:            mockShow,
+				ApplyStepRunner:           mockApply,
+				WorkingDir:                mockWorkingDir,
+				WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+				CommandRequirementHandler: &events.DefaultCommandRequirementHandler{WorkingDir: mockWorkingDir},
+				Webhooks:                  mocks.NewMockWebhooksSender(),
+				AutoApprovalAuditLog:      &events.FileAutoApprovalAuditLog{Path: auditLogPath},
+			}
+			repoDir := t.TempDir()
+			When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+				Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: lockAcquired, LockFailureReason: "locked"}, nil)
+			When(mockShow.Run(Any[command.ProjectContext](), Any[[]string](), Any[string](), Any[map[string]string]())).ThenReturn(lowRiskPlanJSON, nil)
+			When(mockApply.Run(Any[command.ProjectContext](), Any[[]string](), Any[string](), Any[map[string]string]())).ThenReturn("apply", nil)
+
+			ctx := command.ProjectContext{
+				CommandName:       command.Apply,
+				Log:               logging.NewNoopLogger(t),
+				Steps:             []valid.Step{{StepName: "apply"}},
+				Workspace:         "default",
+				RepoRelDir:        ".",
+				ApplyRequirements: []string{"approved"},
+				AutoApproval: &valid.AutoApproval{
+					Rules: []valid.AutoApprovalRule{{Name: "dns", ResourceTypes: []string{"aws_route53_record"}}},
+				},
+			}
+			res := runner.Apply(ctx)
+
+			_, err := os.Stat(auditLogPath)
+			if !lockAcquired {
+				Equals(t, "locked", res.Failure)
+				Assert(t, os.IsNotExist(err), "exp the auto-approval not to be recorded")
+				return
+			}
+			Ok(t, res.Error)
+			Equals(t, "apply", res.ApplySuccess)
+			Ok(t, err)
+		})
+	}
+}
+
 // Test that if mergeable is required and the PR isn't mergeable we give an error.
 func TestDefaultProjectCommandRunner_ApplyNotMergeable(t *testing.T) {
 	RegisterMockTestingT(t)
@@ -310,7 +986,7 @@ func TestDefaultProjectCommandRunner_ApplyDiverged(t *testing.T) {
 	When(mockWorkingDir.HasDiverged(ctx.Log, tmp)).ThenReturn(true)
 
 	res := runner.Apply(ctx)
-	Equals(t, "Default branch must be rebased onto pull request before running apply.", res.Failure)
+	Equals(t, events.DivergedApplyFailure, res.Failure)
 }
 
 // Test that it runs the expected apply steps.
@@ -433,6 +1109,7 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 				Any[string](),
 				Any[models.Project](),
 				AnyBool(),
+				Any[command.Name](),
 			)).ThenReturn(&events.TryLockResponse{
 				LockAcquired: true,
 				LockKey:      "lock-key",
@@ -515,6 +1192,7 @@ func TestDefaultProjectCommandRunner_ApplyRunStepFailure(t *testing.T) {
 		Any[string](),
 		Any[models.Project](),
 		AnyBool(),
+		Any[command.Name](),
 	)).ThenReturn(&events.TryLockResponse{
 		LockAcquired: true,
 		LockKey:      "lock-key",
@@ -577,7 +1255,7 @@ func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
 	When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
 		Any[string]())).ThenReturn(repoDir, false, nil)
 	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
-		Any[models.Project](), AnyBool())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+		Any[models.Project](), AnyBool(), Any[command.Name]())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
 
 	ctx := command.ProjectContext{
 		Log: logging.NewNoopLogger(t),
@@ -655,6 +1333,7 @@ func TestDefaultProjectCommandRunner_Import(t *testing.T) {
 					Any[string](),
 					Any[models.Project](),
 					AnyBool(),
+					Any[command.Name](),
 				)).ThenReturn(&events.TryLockResponse{
 					LockAcquired: true,
 					LockKey:      "lock-key",
@@ -1263,6 +1942,7 @@ func TestDefaultProjectCommandRunner_ApprovePolicies(t *testing.T) {
 				Any[string](),
 				Any[models.Project](),
 				AnyBool(),
+				Any[command.Name](),
 			)).ThenReturn(&events.TryLockResponse{
 				LockAcquired: true,
 				LockKey:      "lock-key",
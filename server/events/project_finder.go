@@ -24,6 +24,7 @@ import (
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/utils"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/moby/patternmatcher"
 	"github.com/pkg/errors"
 
@@ -41,7 +42,9 @@ type ProjectFinder interface {
 	// DetermineProjects returns the list of projects that were modified based on
 	// the modifiedFiles. The list will be de-duplicated.
 	// absRepoDir is the path to the cloned repo on disk.
-	DetermineProjects(log logging.SimpleLogging, modifiedFiles []string, repoFullName string, absRepoDir string, autoplanFileList string, moduleInfo ModuleProjects) []models.Project
+	// sharedModuleDirs are patterns matching directories that hold shared
+	// modules and so are never considered projects themselves.
+	DetermineProjects(log logging.SimpleLogging, modifiedFiles []string, repoFullName string, absRepoDir string, autoplanFileList string, moduleInfo ModuleProjects, sharedModuleDirs []string) []models.Project
 	// DetermineProjectsViaConfig returns the list of projects that were modified
 	// based on modifiedFiles and the repo's config.
 	// absRepoDir is the path to the cloned repo on disk.
@@ -137,9 +140,10 @@ var ignoredFilenameFragments = []string{"terraform.tfstate", "terraform.tfstate.
 type DefaultProjectFinder struct{}
 
 // See ProjectFinder.DetermineProjects.
-func (p *DefaultProjectFinder) DetermineProjects(log logging.SimpleLogging, modifiedFiles []string, repoFullName string, absRepoDir string, autoplanFileList string, moduleInfo ModuleProjects) []models.Project {
+func (p *DefaultProjectFinder) DetermineProjects(log logging.SimpleLogging, modifiedFiles []string, repoFullName string, absRepoDir string, autoplanFileList string, moduleInfo ModuleProjects, sharedModuleDirs []string) []models.Project {
 	var projects []models.Project
 
+	modifiedFiles = p.withSymlinkAliases(log, modifiedFiles, absRepoDir)
 	modifiedTerraformFiles := p.filterToFileList(log, modifiedFiles, autoplanFileList)
 	if len(modifiedTerraformFiles) == 0 {
 		return projects
@@ -149,7 +153,7 @@ func (p *DefaultProjectFinder) DetermineProjects(log logging.SimpleLogging, modi
 
 	var dirs []string
 	for _, modifiedFile := range modifiedTerraformFiles {
-		projectDir := getProjectDir(modifiedFile, absRepoDir)
+		projectDir := getProjectDir(modifiedFile, absRepoDir, sharedModuleDirs)
 		if projectDir != "" {
 			dirs = append(dirs, projectDir)
 		} else if moduleInfo != nil {
@@ -180,6 +184,7 @@ func (p *DefaultProjectFinder) DetermineProjects(log logging.SimpleLogging, modi
 
 // See ProjectFinder.DetermineProjectsViaConfig.
 func (p *DefaultProjectFinder) DetermineProjectsViaConfig(log logging.SimpleLogging, modifiedFiles []string, config valid.RepoCfg, absRepoDir string, moduleInfo ModuleProjects) ([]valid.Project, error) {
+	modifiedFiles = p.withSymlinkAliases(log, modifiedFiles, absRepoDir)
 
 	// Check moduleInfo for downstream project dependencies
 	var dependentProjects []string
@@ -259,6 +264,23 @@ func (p *DefaultProjectFinder) DetermineProjectsViaConfig(log logging.SimpleLogg
 	return projects, nil
 }
 
+// withSymlinkAliases adds to modifiedFiles the paths through which each
+// modified file is also reachable via a symlinked directory in absRepoDir, so
+// that projects referenced through symlinks are matched. If absRepoDir is empty
+// the repo hasn't been cloned yet and modifiedFiles is returned as-is.
+func (p *DefaultProjectFinder) withSymlinkAliases(log logging.SimpleLogging, modifiedFiles []string, absRepoDir string) []string {
+	links, err := findRepoSymlinks(absRepoDir)
+	if err != nil {
+		log.Warn("unable to resolve symlinks in '%s': %s", absRepoDir, err)
+		return modifiedFiles
+	}
+	aliased := links.withAliases(modifiedFiles)
+	if len(aliased) > len(modifiedFiles) {
+		log.Debug("modified files including paths through symlinks: %v", aliased)
+	}
+	return aliased
+}
+
 // filterToFileList filters out files not included in the file list
 func (p *DefaultProjectFinder) filterToFileList(log logging.SimpleLogging, files []string, fileList string) []string {
 	var filtered []string
@@ -297,13 +319,20 @@ func (p *DefaultProjectFinder) shouldIgnore(fileName string) bool {
 // file, where the root of the Terraform project is. It also attempts to verify
 // if the root is valid by looking for a main.tf file. It returns a relative
 // path to the repo. If the project is at the root returns ".". If modified file
-// doesn't lead to a valid project path, returns an empty string.
-func getProjectDir(modifiedFilePath string, repoDir string) string {
-	return getProjectDirFromFs(os.DirFS(repoDir), modifiedFilePath)
+// doesn't lead to a valid project path, or the file is inside one of the
+// sharedModuleDirs, returns an empty string.
+func getProjectDir(modifiedFilePath string, repoDir string, sharedModuleDirs []string) string {
+	return getProjectDirFromFs(os.DirFS(repoDir), modifiedFilePath, sharedModuleDirs)
 }
 
-func getProjectDirFromFs(files fs.FS, modifiedFilePath string) string {
+func getProjectDirFromFs(files fs.FS, modifiedFilePath string, sharedModuleDirs []string) string {
 	dir := path.Dir(modifiedFilePath)
+	if isSharedModuleDir(dir, sharedModuleDirs) {
+		// Directories such as vendored copies of modules are never projects
+		// themselves. Changes to them are attributed to the projects that use
+		// them via the module dependency index instead.
+		return ""
+	}
 	if path.Base(dir) == "env" {
 		// If the modified file was inside an env/ directory, we treat this
 		// specially and run plan one level up. This supports directory structures
@@ -365,6 +394,20 @@ func isModule(dir string) bool {
 	return strings.Contains("/"+dir+"/", "/modules/")
 }
 
+// isSharedModuleDir returns true if dir, or any of its parents, matches one of
+// the sharedModuleDirs patterns.
+func isSharedModuleDir(dir string, sharedModuleDirs []string) bool {
+	for ; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		for _, pattern := range sharedModuleDirs {
+			// Patterns are validated when the config is parsed.
+			if doublestar.MatchUnvalidated(pattern, dir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // unique de-duplicates strs.
 func (p *DefaultProjectFinder) unique(strs []string) []string {
 	hash := make(map[string]bool)
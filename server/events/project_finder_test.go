@@ -293,7 +293,7 @@ func TestDetermineProjects(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
-			projects := m.DetermineProjects(noopLogger, c.files, modifiedRepo, c.repoDir, c.autoplanFileList, nil)
+			projects := m.DetermineProjects(noopLogger, c.files, modifiedRepo, c.repoDir, c.autoplanFileList, nil, nil)
 
 			// Extract the paths from the projects. We use a slice here instead of a
 			// map so we can test whether there are duplicates returned.
@@ -322,6 +322,68 @@ func TestDetermineProjects(t *testing.T) {
 	}
 }
 
+func TestDetermineProjects_Symlinks(t *testing.T) {
+	noopLogger := logging.NewNoopLogger(t)
+	// Create dir structure:
+	// stacks/
+	//   app/
+	//     main.tf
+	// envs/
+	//   prod -> ../stacks/app
+	tmpDir := DirStructure(t, map[string]interface{}{
+		"stacks": map[string]interface{}{
+			"app": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+		"envs": map[string]interface{}{},
+	})
+	Ok(t, os.Symlink(filepath.Join("..", "stacks", "app"), filepath.Join(tmpDir, "envs", "prod")))
+
+	projects := m.DetermineProjects(noopLogger, []string{"stacks/app/main.tf"}, modifiedRepo, tmpDir, "**/*.tf", nil, nil)
+	var paths []string
+	for _, project := range projects {
+		paths = append(paths, project.Path)
+	}
+	Equals(t, []string{"stacks/app", "envs/prod"}, paths)
+}
+
+func TestDetermineProjects_SharedModuleDirs(t *testing.T) {
+	noopLogger := logging.NewNoopLogger(t)
+	// Create dir structure:
+	// live/
+	//   main.tf # uses ../vendor/vpc
+	// vendor/
+	//   vpc/
+	//     main.tf
+	tmpDir := DirStructure(t, map[string]interface{}{
+		"live": map[string]interface{}{
+			"main.tf": `module "vpc" { source = "../vendor/vpc" }`,
+		},
+		"vendor": map[string]interface{}{
+			"vpc": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+	})
+	modified := []string{"vendor/vpc/main.tf"}
+
+	t.Run("vendored dir is a project without config", func(t *testing.T) {
+		projects := m.DetermineProjects(noopLogger, modified, modifiedRepo, tmpDir, "**/*.tf", nil, nil)
+		Equals(t, 1, len(projects))
+		Equals(t, "vendor/vpc", projects[0].Path)
+	})
+
+	t.Run("shared module dir plans dependent projects", func(t *testing.T) {
+		sharedModuleDirs := []string{"vendor/**"}
+		moduleInfo, err := events.FindModuleProjects(tmpDir, "**/*.tf", sharedModuleDirs)
+		Ok(t, err)
+		projects := m.DetermineProjects(noopLogger, modified, modifiedRepo, tmpDir, "**/*.tf", moduleInfo, sharedModuleDirs)
+		Equals(t, 1, len(projects))
+		Equals(t, "live", projects[0].Path)
+	})
+}
+
 func TestDefaultProjectFinder_DetermineProjectsViaConfig(t *testing.T) {
 	// Create dir structure:
 	// main.tf
@@ -564,3 +626,38 @@ func TestDefaultProjectFinder_DetermineProjectsViaConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultProjectFinder_DetermineProjectsViaConfig_Symlinks(t *testing.T) {
+	// Create dir structure:
+	// stacks/
+	//   app/
+	//     main.tf
+	// envs/
+	//   prod -> ../stacks/app
+	tmpDir := DirStructure(t, map[string]interface{}{
+		"stacks": map[string]interface{}{
+			"app": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+		"envs": map[string]interface{}{},
+	})
+	Ok(t, os.Symlink(filepath.Join("..", "stacks", "app"), filepath.Join(tmpDir, "envs", "prod")))
+
+	config := valid.RepoCfg{
+		Projects: []valid.Project{
+			{
+				Dir: "envs/prod",
+				Autoplan: valid.Autoplan{
+					Enabled:      true,
+					WhenModified: []string{"**/*.tf"},
+				},
+			},
+		},
+	}
+	pf := events.DefaultProjectFinder{}
+	projects, err := pf.DetermineProjectsViaConfig(logging.NewNoopLogger(t), []string{"stacks/app/main.tf"}, config, tmpDir, nil)
+	Ok(t, err)
+	Equals(t, 1, len(projects))
+	Equals(t, "envs/prod", projects[0].Dir)
+}
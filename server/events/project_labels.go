@@ -0,0 +1,92 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// buildPlanCommandsByLabels builds plan contexts for every project of the
+// repo config with the labels of cmd. Unlike a plan of all projects, the
+// projects are planned whether or not the pull request modifies them.
+func (p *DefaultProjectCommandBuilder) buildPlanCommandsByLabels(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
+	ctx.Log.Debug("building plan command for the projects with labels %v", cmd.Labels)
+	ctx.Log.Debug("cloning repository")
+	_, unlockFn, err := cloneWorkspace(ctx, p.WorkingDirLocker, p.WorkingDir, DefaultWorkspace)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockFn()
+
+	// use the default repository workspace because it is the only one guaranteed to have an atlantis.yaml,
+	// other workspaces will not have the file if they are using pre_workflow_hooks to generate it dynamically
+	defaultRepoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, DefaultWorkspace)
+	if err != nil {
+		return nil, err
+	}
+
+	repoCfgFile, hasRepoCfg, err := p.ParserValidator.FindRepoCfg(defaultRepoDir, p.globalCfg().RepoConfigFiles(ctx.Pull.BaseRepo.ID()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "looking for '%s' file in '%s'", repoCfgFile, defaultRepoDir)
+	}
+	if !hasRepoCfg {
+		return nil, fmt.Errorf("cannot specify --%s unless an %s file exists to configure projects", labelFlagLong, repoCfgFile)
+	}
+	repoCfg, err := p.ParserValidator.ParseRepoCfg(defaultRepoDir, p.globalCfg(), ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	repoCfg.UsePreviewWorkspaces(ctx.Pull.Num)
+
+	var projCtxs []command.ProjectContext
+	cloned := map[string]bool{DefaultWorkspace: true}
+	for _, proj := range repoCfg.Projects {
+		if !valid.MatchLabels(proj.Labels, cmd.Labels) {
+			continue
+		}
+		if !cloned[proj.Workspace] {
+			ctx.Log.Debug("cloning repository with workspace %s", proj.Workspace)
+			_, unlockWorkspaceFn, err := cloneWorkspace(ctx, p.WorkingDirLocker, p.WorkingDir, proj.Workspace)
+			if err != nil {
+				return nil, err
+			}
+			unlockWorkspaceFn()
+			cloned[proj.Workspace] = true
+		}
+		cmds, err := p.buildProjectCommandCtx(ctx, command.Plan, "", proj.GetName(), cmd.Flags, defaultRepoDir, proj.Dir, proj.Workspace, cmd.Verbose)
+		if err != nil {
+			return nil, errors.Wrapf(err, "building command for dir '%s'", proj.Dir)
+		}
+		projCtxs = append(projCtxs, cmds...)
+	}
+	return withLabels(projCtxs, cmd.Labels), nil
+}
+
+// withLabels keeps the project contexts with every label of selector once.
+// Looking projects up by name can return projects without the labels when
+// regexp names are enabled, or the same project twice.
+func withLabels(projCtxs []command.ProjectContext, selector map[string]string) []command.ProjectContext {
+	if len(selector) == 0 {
+		return projCtxs
+	}
+	var filtered []command.ProjectContext
+	for _, projCtx := range projCtxs {
+		if !valid.MatchLabels(projCtx.Labels, selector) {
+			continue
+		}
+		if slices.ContainsFunc(filtered, func(c command.ProjectContext) bool {
+			return c.RepoRelDir == projCtx.RepoRelDir && c.Workspace == projCtx.Workspace && c.ProjectName == projCtx.ProjectName
+		}) {
+			continue
+		}
+		filtered = append(filtered, projCtx)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].ExecutionOrderGroup < filtered[j].ExecutionOrderGroup
+	})
+	return filtered
+}
@@ -14,9 +14,12 @@
 package events
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -32,8 +35,14 @@ type ProjectLocker interface {
 	// return value will be a string describing why the lock was not acquired.
 	// The third return value is a function that can be called to unlock the
 	// lock. It will only be set if the lock was acquired. Any errors will set
-	// error.
-	TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, repoLocking bool) (*TryLockResponse, error)
+	// error. cmdName is the command the lock is acquired for, which is
+	// recorded on new locks.
+	TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, repoLocking bool, cmdName command.Name) (*TryLockResponse, error)
+	// TryLockKeys attempts to acquire the lock keys of project. Keys are
+	// shared by every repo so projects declaring the same key can't run cmdName
+	// at the same time. If a key is held by another pull request, none are
+	// acquired and the failure reason says who holds it.
+	TryLockKeys(log logging.SimpleLogging, pull models.PullRequest, user models.User, project models.Project, keys []string, cmdName command.Name) (*TryLockResponse, error)
 }
 
 // DefaultProjectLocker implements ProjectLocker.
@@ -59,13 +68,13 @@ type TryLockResponse struct {
 }
 
 // TryLock implements ProjectLocker.TryLock.
-func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, repoLocking bool) (*TryLockResponse, error) {
+func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, repoLocking bool, cmdName command.Name) (*TryLockResponse, error) {
 	locker := p.Locker
 	if !repoLocking {
 		locker = p.NoOpLocker
 	}
 
-	lockAttempt, err := locker.TryLock(project, workspace, pull, user)
+	lockAttempt, err := locker.TryLock(project, workspace, pull, user, cmdName)
 	if err != nil {
 		return nil, err
 	}
@@ -93,3 +102,66 @@ func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.Pu
 		LockKey: lockAttempt.LockKey,
 	}, nil
 }
+
+// TryLockKeys implements ProjectLocker.TryLockKeys. Keys already held by
+// another project of pull are shared with it.
+func (p *DefaultProjectLocker) TryLockKeys(log logging.SimpleLogging, pull models.PullRequest, user models.User, project models.Project, keys []string, cmdName command.Name) (*TryLockResponse, error) {
+	var acquired []string
+	unlockFn := func() error {
+		var errs []error
+		for _, lockKey := range acquired {
+			if _, err := p.Locker.Unlock(lockKey); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	release := func() {
+		if err := unlockFn(); err != nil {
+			log.Err("releasing lock keys: %s", err)
+		}
+	}
+
+	for _, key := range keys {
+		lockAttempt, err := p.Locker.TryLock(locking.LockKeyProject(key, project.ProjectName), locking.LockKeyWorkspace, pull, user, cmdName)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("acquiring lock key %q: %w", key, err)
+		}
+		if lockAttempt.LockAcquired {
+			acquired = append(acquired, lockAttempt.LockKey)
+			continue
+		}
+		currLock := lockAttempt.CurrLock
+		if currLock.Pull.BaseRepo.FullName == pull.BaseRepo.FullName && currLock.Pull.Num == pull.Num {
+			continue
+		}
+		release()
+		// Keys are shared across repos, where the host's short links don't
+		// resolve.
+		link := fmt.Sprintf("[%s#%d](%s)", currLock.Pull.BaseRepo.FullName, currLock.Pull.Num, currLock.Pull.URL)
+		if currLock.Pull.BaseRepo.FullName == pull.BaseRepo.FullName {
+			if link, err = p.VCSClient.MarkdownPullLink(currLock.Pull); err != nil {
+				return nil, err
+			}
+		}
+		holder := "a project"
+		if currLock.Project.ProjectName != "" {
+			holder = fmt.Sprintf("project `%s`", currLock.Project.ProjectName)
+		}
+		failureMsg := fmt.Sprintf(
+			"This project shares the lock key `%s` with %s of pull %s, which @%s has been running %s on since %s. Projects sharing a lock key are applied one at a time.\n\nOnce it's done, comment `atlantis %s` here to try again.",
+			key, holder, link, currLock.User.Username, currLock.Command, currLock.Time.Format(time.RFC3339), cmdName.String())
+		return &TryLockResponse{
+			LockAcquired:      false,
+			LockFailureReason: failureMsg,
+		}, nil
+	}
+	if len(acquired) > 0 {
+		log.Info("Acquired lock keys %v", keys)
+	}
+	return &TryLockResponse{
+		LockAcquired: true,
+		UnlockFn:     unlockFn,
+	}, nil
+}
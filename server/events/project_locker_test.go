@@ -16,11 +16,13 @@ package events_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -43,7 +45,7 @@ func TestDefaultProjectLocker_TryLockWhenLocked(t *testing.T) {
 	lockingPull := models.PullRequest{
 		Num: 2,
 	}
-	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)).ThenReturn(
 		locking.TryLockResponse{
 			LockAcquired: false,
 			CurrLock: models.ProjectLock{
@@ -53,7 +55,7 @@ func TestDefaultProjectLocker_TryLockWhenLocked(t *testing.T) {
 		},
 		nil,
 	)
-	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject, true)
+	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject, true, command.Plan)
 	link, _ := mockClient.MarkdownPullLink(lockingPull)
 	Ok(t, err)
 	Equals(t, &events.TryLockResponse{
@@ -80,7 +82,7 @@ func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 		Num: 2,
 	}
 	lockKey := "key"
-	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)).ThenReturn(
 		locking.TryLockResponse{
 			LockAcquired: false,
 			CurrLock: models.ProjectLock{
@@ -90,7 +92,7 @@ func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 		},
 		nil,
 	)
-	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject, true)
+	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject, true, command.Plan)
 	Ok(t, err)
 	Equals(t, true, res.LockAcquired)
 
@@ -119,7 +121,7 @@ func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 		Num: 2,
 	}
 	lockKey := "key"
-	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)).ThenReturn(
 		locking.TryLockResponse{
 			LockAcquired: true,
 			CurrLock: models.ProjectLock{
@@ -129,7 +131,7 @@ func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 		},
 		nil,
 	)
-	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject, true)
+	res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject, true, command.Plan)
 	Ok(t, err)
 	Equals(t, true, res.LockAcquired)
 
@@ -159,7 +161,7 @@ func TestDefaultProjectLocker_RepoLocking(t *testing.T) {
 			"enable repo locking",
 			true,
 			func(locker *mocks.MockLocker, noOpLocker *mocks.MockLocker) {
-				When(locker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+				When(locker.TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)).ThenReturn(
 					locking.TryLockResponse{
 						LockAcquired: true,
 						CurrLock:     models.ProjectLock{},
@@ -169,15 +171,15 @@ func TestDefaultProjectLocker_RepoLocking(t *testing.T) {
 				)
 			},
 			func(locker *mocks.MockLocker, noOpLocker *mocks.MockLocker) {
-				locker.VerifyWasCalledOnce().TryLock(expProject, expWorkspace, expPull, expUser)
-				noOpLocker.VerifyWasCalled(Never()).TryLock(expProject, expWorkspace, expPull, expUser)
+				locker.VerifyWasCalledOnce().TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)
+				noOpLocker.VerifyWasCalled(Never()).TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)
 			},
 		},
 		{
 			"disable repo locking",
 			false,
 			func(locker *mocks.MockLocker, noOpLocker *mocks.MockLocker) {
-				When(noOpLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+				When(noOpLocker.TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)).ThenReturn(
 					locking.TryLockResponse{
 						LockAcquired: true,
 						CurrLock:     models.ProjectLock{},
@@ -187,8 +189,8 @@ func TestDefaultProjectLocker_RepoLocking(t *testing.T) {
 				)
 			},
 			func(locker *mocks.MockLocker, noOpLocker *mocks.MockLocker) {
-				locker.VerifyWasCalled(Never()).TryLock(expProject, expWorkspace, expPull, expUser)
-				noOpLocker.VerifyWasCalledOnce().TryLock(expProject, expWorkspace, expPull, expUser)
+				locker.VerifyWasCalled(Never()).TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)
+				noOpLocker.VerifyWasCalledOnce().TryLock(expProject, expWorkspace, expPull, expUser, command.Plan)
 			},
 		},
 	}
@@ -203,10 +205,79 @@ func TestDefaultProjectLocker_RepoLocking(t *testing.T) {
 				VCSClient:  mockClient,
 			}
 			tt.setup(mockLocker, mockNoOpLocker)
-			res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject, tt.repoLocking)
+			res, err := locker.TryLock(logging.NewNoopLogger(t), expPull, expUser, expWorkspace, expProject, tt.repoLocking, command.Plan)
 			Ok(t, err)
 			Equals(t, true, res.LockAcquired)
 			tt.verify(mockLocker, mockNoOpLocker)
 		})
 	}
 }
+
+func TestDefaultProjectLocker_TryLockKeys(t *testing.T) {
+	RegisterMockTestingT(t)
+	var githubClient *vcs.GithubClient
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	logger := logging.NewNoopLogger(t)
+	repo := models.Repo{FullName: "owner/app"}
+	pull := models.PullRequest{Num: 2, BaseRepo: repo}
+	user := models.User{Username: "alice"}
+	project := models.NewProject(repo.FullName, "vpc", "vpc")
+	dnsKey := locking.LockKeyProject("dns", "vpc")
+	networkKey := locking.LockKeyProject("network-core", "vpc")
+
+	t.Run("acquires every key", func(t *testing.T) {
+		mockLocker := mocks.NewMockLocker()
+		locker := events.DefaultProjectLocker{Locker: mockLocker, VCSClient: mockClient}
+		When(mockLocker.TryLock(Any[models.Project](), Eq(locking.LockKeyWorkspace), Eq(pull), Eq(user), Eq(command.Apply))).ThenReturn(
+			locking.TryLockResponse{LockAcquired: true, LockKey: "key"}, nil)
+
+		res, err := locker.TryLockKeys(logger, pull, user, project, []string{"network-core", "dns"}, command.Apply)
+		Ok(t, err)
+		Assert(t, res.LockAcquired, "expected the keys to be acquired")
+		mockLocker.VerifyWasCalledOnce().TryLock(networkKey, locking.LockKeyWorkspace, pull, user, command.Apply)
+		mockLocker.VerifyWasCalledOnce().TryLock(dnsKey, locking.LockKeyWorkspace, pull, user, command.Apply)
+
+		Ok(t, res.UnlockFn())
+		mockLocker.VerifyWasCalled(Times(2)).Unlock("key")
+	})
+
+	t.Run("shares keys held by the same pull request", func(t *testing.T) {
+		mockLocker := mocks.NewMockLocker()
+		locker := events.DefaultProjectLocker{Locker: mockLocker, VCSClient: mockClient}
+		When(mockLocker.TryLock(networkKey, locking.LockKeyWorkspace, pull, user, command.Apply)).ThenReturn(
+			locking.TryLockResponse{LockAcquired: false, CurrLock: models.ProjectLock{Pull: pull}}, nil)
+
+		res, err := locker.TryLockKeys(logger, pull, user, project, []string{"network-core"}, command.Apply)
+		Ok(t, err)
+		Assert(t, res.LockAcquired, "expected the key to be shared")
+		Ok(t, res.UnlockFn())
+		mockLocker.VerifyWasCalled(Never()).Unlock(Any[string]())
+	})
+
+	t.Run("releases the acquired keys if one is held by another repo", func(t *testing.T) {
+		mockLocker := mocks.NewMockLocker()
+		locker := events.DefaultProjectLocker{Locker: mockLocker, VCSClient: mockClient}
+		lockTime := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+		When(mockLocker.TryLock(dnsKey, locking.LockKeyWorkspace, pull, user, command.Apply)).ThenReturn(
+			locking.TryLockResponse{LockAcquired: true, LockKey: "@lock-keys/dns/./default"}, nil)
+		When(mockLocker.TryLock(networkKey, locking.LockKeyWorkspace, pull, user, command.Apply)).ThenReturn(
+			locking.TryLockResponse{
+				LockAcquired: false,
+				CurrLock: models.ProjectLock{
+					Project: locking.LockKeyProject("network-core", "peering"),
+					Pull:    models.PullRequest{Num: 7, BaseRepo: models.Repo{FullName: "owner/network"}, URL: "https://github.com/owner/network/pull/7"},
+					User:    models.User{Username: "bob"},
+					Time:    lockTime,
+					Command: "apply",
+				},
+			}, nil)
+
+		res, err := locker.TryLockKeys(logger, pull, user, project, []string{"dns", "network-core"}, command.Apply)
+		Ok(t, err)
+		Equals(t, &events.TryLockResponse{
+			LockAcquired:      false,
+			LockFailureReason: "This project shares the lock key `network-core` with project `peering` of pull [owner/network#7](https://github.com/owner/network/pull/7), which @bob has been running apply on since 2025-03-04T10:00:00Z. Projects sharing a lock key are applied one at a time.\n\nOnce it's done, comment `atlantis apply` here to try again.",
+		}, res)
+		mockLocker.VerifyWasCalledOnce().Unlock("@lock-keys/dns/./default")
+	})
+}
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ProjectNameTemplateData is the data available to the project name template.
+type ProjectNameTemplateData struct {
+	// Repo is the full name of the base repo, ex. owner/repo.
+	Repo string
+	// RepoName is the name of the base repo without its owner, ex. repo.
+	RepoName string
+	// Dir is the project's dir relative to the repo root, ex. envs/prod/vpc.
+	Dir string
+	// DirBase is the last element of Dir, ex. vpc.
+	DirBase string
+	// DirParts are the elements of Dir, ex. [envs prod vpc].
+	DirParts  []string
+	Workspace string
+}
+
+// NewProjectNameTemplate parses text as a project name template. On top of
+// the standard functions, templates can use lastDirs to keep only the last n
+// elements of a dir, ex. {{ lastDirs 2 .Dir }}.
+func NewProjectNameTemplate(text string) (*template.Template, error) {
+	return template.New("project-name").
+		Funcs(template.FuncMap{"lastDirs": lastDirs}).
+		Option("missingkey=error").
+		Parse(text)
+}
+
+// lastDirs returns the last n elements of dir joined by slashes.
+func lastDirs(n int, dir string) string {
+	parts := splitDir(dir)
+	if n < len(parts) {
+		parts = parts[len(parts)-n:]
+	}
+	return strings.Join(parts, "/")
+}
+
+func splitDir(dir string) []string {
+	dir = path.Clean(dir)
+	if dir == "." || dir == "/" {
+		return []string{}
+	}
+	return strings.Split(strings.Trim(dir, "/"), "/")
+}
+
+// generateProjectName executes tmpl for the project in dir and workspace.
+func generateProjectName(tmpl *template.Template, repo models.Repo, dir string, workspace string) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, ProjectNameTemplateData{
+		Repo:      repo.FullName,
+		RepoName:  repo.Name,
+		Dir:       dir,
+		DirBase:   path.Base(path.Clean(dir)),
+		DirParts:  splitDir(dir),
+		Workspace: workspace,
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing project name template for dir %q workspace %q: %w", dir, workspace, err)
+	}
+	name := strings.TrimSpace(buf.String())
+	if name == "" {
+		return "", fmt.Errorf("project name template generated an empty name for dir %q workspace %q", dir, workspace)
+	}
+	return name, nil
+}
+
+// nameProjectCfgs names the projects in cfgs that don't have a name using
+// tmpl and returns an error if two projects end up with the same name. If
+// tmpl is nil, cfgs are returned as is.
+func nameProjectCfgs(tmpl *template.Template, repo models.Repo, cfgs []valid.MergedProjectCfg) ([]valid.MergedProjectCfg, error) {
+	if tmpl == nil {
+		return cfgs, nil
+	}
+
+	// seen maps each name to the project that has it so a collision can
+	// name both projects.
+	seen := make(map[string]valid.MergedProjectCfg)
+	for i, cfg := range cfgs {
+		if cfg.Name == "" {
+			name, err := generateProjectName(tmpl, repo, cfg.RepoRelDir, cfg.Workspace)
+			if err != nil {
+				return nil, err
+			}
+			cfgs[i].Name = name
+		}
+		if other, ok := seen[cfgs[i].Name]; ok {
+			return nil, fmt.Errorf("projects at dir %q workspace %q and dir %q workspace %q are both named %q; project names must be unique, update --project-name-template or name the projects in the repo config",
+				other.RepoRelDir, other.Workspace, cfg.RepoRelDir, cfg.Workspace, cfgs[i].Name)
+		}
+		seen[cfgs[i].Name] = cfgs[i]
+	}
+	return cfgs, nil
+}
+
+// isGeneratedProjectName returns true if name is the name tmpl generates for
+// the project in dir and workspace.
+func isGeneratedProjectName(tmpl *template.Template, repo models.Repo, name string, dir string, workspace string) bool {
+	if tmpl == nil || name == "" {
+		return false
+	}
+	generated, err := generateProjectName(tmpl, repo, dir, workspace)
+	return err == nil && generated == name
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestGenerateProjectName(t *testing.T) {
+	repo := models.Repo{FullName: "owner/repo", Name: "repo"}
+	cases := []struct {
+		tmpl   string
+		dir    string
+		exp    string
+		expErr string
+	}{
+		{tmpl: "{{ .RepoName }}-{{ .DirBase }}-{{ .Workspace }}", dir: "envs/prod/vpc", exp: "repo-vpc-default"},
+		{tmpl: "{{ lastDirs 2 .Dir }}", dir: "envs/prod/vpc", exp: "prod/vpc"},
+		{tmpl: "{{ lastDirs 5 .Dir }}", dir: "envs/prod/vpc", exp: "envs/prod/vpc"},
+		{tmpl: "{{ index .DirParts 0 }}", dir: "envs/prod/vpc", exp: "envs"},
+		{tmpl: "{{ .Repo }}/{{ .Dir }}", dir: ".", exp: "owner/repo/."},
+		{tmpl: "{{ lastDirs 1 .Dir }}", dir: ".", expErr: `project name template generated an empty name for dir "." workspace "default"`},
+	}
+	for _, c := range cases {
+		t.Run(c.tmpl, func(t *testing.T) {
+			tmpl, err := NewProjectNameTemplate(c.tmpl)
+			Ok(t, err)
+			name, err := generateProjectName(tmpl, repo, c.dir, "default")
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.exp, name)
+		})
+	}
+}
+
+func TestNameProjectCfgs(t *testing.T) {
+	repo := models.Repo{FullName: "owner/repo", Name: "repo"}
+	tmpl, err := NewProjectNameTemplate("{{ .DirBase }}-{{ .Workspace }}")
+	Ok(t, err)
+
+	t.Run("no template", func(t *testing.T) {
+		cfgs, err := nameProjectCfgs(nil, repo, []valid.MergedProjectCfg{{RepoRelDir: "a/vpc", Workspace: "default"}})
+		Ok(t, err)
+		Equals(t, "", cfgs[0].Name)
+	})
+
+	t.Run("names unnamed projects", func(t *testing.T) {
+		cfgs, err := nameProjectCfgs(tmpl, repo, []valid.MergedProjectCfg{
+			{RepoRelDir: "a/vpc", Workspace: "default"},
+			{RepoRelDir: "b/vpc", Workspace: "default", Name: "b-vpc"},
+		})
+		Ok(t, err)
+		Equals(t, "vpc-default", cfgs[0].Name)
+		Equals(t, "b-vpc", cfgs[1].Name)
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		_, err := nameProjectCfgs(tmpl, repo, []valid.MergedProjectCfg{
+			{RepoRelDir: "a/vpc", Workspace: "default"},
+			{RepoRelDir: "b/vpc", Workspace: "default"},
+		})
+		ErrEquals(t, `projects at dir "a/vpc" workspace "default" and dir "b/vpc" workspace "default" are both named "vpc-default"; project names must be unique, update --project-name-template or name the projects in the repo config`, err)
+	})
+
+	t.Run("collision with explicit name", func(t *testing.T) {
+		_, err := nameProjectCfgs(tmpl, repo, []valid.MergedProjectCfg{
+			{RepoRelDir: "a", Workspace: "default", Name: "vpc-default"},
+			{RepoRelDir: "b/vpc", Workspace: "default"},
+		})
+		ErrContains(t, `are both named "vpc-default"`, err)
+	})
+}
+
+func TestIsGeneratedProjectName(t *testing.T) {
+	repo := models.Repo{FullName: "owner/repo", Name: "repo"}
+	tmpl, err := NewProjectNameTemplate("{{ lastDirs 2 .Dir }}")
+	Ok(t, err)
+	Assert(t, isGeneratedProjectName(tmpl, repo, "prod/vpc", "envs/prod/vpc", "default"), "expected generated name")
+	Assert(t, !isGeneratedProjectName(tmpl, repo, "vpc", "envs/prod/vpc", "default"), "expected explicit name")
+	Assert(t, !isGeneratedProjectName(nil, repo, "prod/vpc", "envs/prod/vpc", "default"), "expected no template")
+}
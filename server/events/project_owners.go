@@ -0,0 +1,177 @@
+package events
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// codeOwnersFiles are the paths searched in order for the CODEOWNERS file.
+var codeOwnersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// codeOwnersRule is a line of a CODEOWNERS file.
+type codeOwnersRule struct {
+	pattern string
+	owners  []string
+}
+
+// parseCodeOwners returns the rules of a CODEOWNERS file in order. Sections
+// and comments are skipped.
+func parseCodeOwners(content []byte) []codeOwnersRule {
+	var rules []codeOwnersRule
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		rules = append(rules, codeOwnersRule{pattern: fields[0], owners: fields[1:]})
+	}
+	return rules
+}
+
+// matchesDir returns true if the rule's pattern matches dir, a directory
+// relative to the repo root, or one of its parents. Like in .gitignore,
+// patterns containing a slash other than a trailing one are anchored to the
+// repo root unless they start with **/, others match at any depth.
+func (r codeOwnersRule) matchesDir(dir string) bool {
+	pattern := strings.TrimSuffix(strings.TrimSuffix(r.pattern, "/**"), "/")
+	if pattern == "*" || pattern == "**" || pattern == "" {
+		return true
+	}
+	if dir == "." || dir == "" {
+		return false
+	}
+	anchored := strings.Contains(pattern, "/") && !strings.HasPrefix(pattern, "**/")
+	pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "**/"), "/")
+	segments := strings.Split(dir, "/")
+	for end := 1; end <= len(segments); end++ {
+		for start := 0; start < end; start++ {
+			if anchored && start > 0 {
+				break
+			}
+			if ok, _ := path.Match(pattern, strings.Join(segments[start:end], "/")); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// codeOwnersForDir returns the owners of dir according to the last matching
+// rule or nil if no rule matches or the rule doesn't list owners. @user
+// entries are users and @org/team entries are teams; teams of the repo's
+// organization are also matched by their name alone because that's how
+// GitHub reports them. Email addresses can't be resolved to VCS users so
+// they're ignored.
+func codeOwnersForDir(rules []codeOwnersRule, dir string, repo models.Repo) *valid.PolicyOwners {
+	var matched *codeOwnersRule
+	for i := range rules {
+		if rules[i].matchesDir(dir) {
+			matched = &rules[i]
+		}
+	}
+	if matched == nil || len(matched.owners) == 0 {
+		return nil
+	}
+	owners := valid.PolicyOwners{}
+	for _, owner := range matched.owners {
+		if !strings.HasPrefix(owner, "@") {
+			continue
+		}
+		owner = strings.TrimPrefix(owner, "@")
+		org, team, isTeam := strings.Cut(owner, "/")
+		if !isTeam {
+			owners.Users = append(owners.Users, owner)
+			continue
+		}
+		owners.Teams = append(owners.Teams, owner)
+		if strings.EqualFold(org, repo.Owner) {
+			owners.Teams = append(owners.Teams, team)
+		}
+	}
+	return &owners
+}
+
+// projectOwners returns the owners of the project from its repo config or,
+// if the server-side config enables it, from the CODEOWNERS file of the base
+// branch. The base branch is used so pull requests can't change who owns
+// them. It returns nil if the project doesn't have owners.
+func (p *DefaultProjectCommandRunner) projectOwners(ctx command.ProjectContext) (*valid.PolicyOwners, error) {
+	if ctx.Owners != nil {
+		return ctx.Owners, nil
+	}
+	if !ctx.CodeOwners {
+		return nil, nil
+	}
+	if !p.VcsClient.SupportsSingleFileDownload(ctx.Pull.BaseRepo) {
+		return nil, errors.New("reading the CODEOWNERS file of the base branch is not supported for this VCS")
+	}
+	basePull := ctx.Pull
+	basePull.HeadBranch = ctx.Pull.BaseBranch
+	for _, file := range codeOwnersFiles {
+		found, content, err := p.VcsClient.GetFileContent(ctx.Log, basePull, file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		if found {
+			return codeOwnersForDir(parseCodeOwners(content), ctx.RepoRelDir, ctx.Pull.BaseRepo), nil
+		}
+	}
+	return nil, nil
+}
+
+// isProjectOwner returns true if user is one of owners, looking up the
+// user's teams only if there are team owners.
+func (p *DefaultProjectCommandRunner) isProjectOwner(ctx command.ProjectContext, owners valid.PolicyOwners, user models.User) (bool, error) {
+	if owners.IsOwner(user.Username, nil) {
+		return true, nil
+	}
+	if len(owners.Teams) == 0 {
+		return false, nil
+	}
+	teams, err := p.VcsClient.GetTeamNamesForUser(ctx.Log, ctx.Pull.BaseRepo, user)
+	if err != nil {
+		return false, fmt.Errorf("getting teams of %s: %w", user.Username, err)
+	}
+	return owners.IsOwner(user.Username, teams), nil
+}
+
+// checkOwners returns a failure if the project has owners and cmdName wasn't
+// run by one of them nor was the pull request approved by one of them.
+func (p *DefaultProjectCommandRunner) checkOwners(ctx command.ProjectContext, cmdName command.Name) (string, error) {
+	owners, err := p.projectOwners(ctx)
+	if err != nil || owners == nil {
+		return "", err
+	}
+	isOwner, err := p.isProjectOwner(ctx, *owners, ctx.User)
+	if err != nil || isOwner {
+		return "", err
+	}
+	approval := ctx.PullReqStatus.ApprovalStatus
+	if approval.IsApproved && approval.ApprovedBy != "" {
+		isOwner, err = p.isProjectOwner(ctx, *owners, models.User{Username: approval.ApprovedBy})
+		if err != nil || isOwner {
+			return "", err
+		}
+	}
+	var names []string
+	for _, user := range owners.Users {
+		names = append(names, "@"+user)
+	}
+	names = append(names, owners.Teams...)
+	if len(names) == 0 {
+		return fmt.Sprintf("This project has no owners who can run %s.", cmdName.String()), nil
+	}
+	return fmt.Sprintf("Only the owners of this project can run %s, or one of them must approve the pull request: %s.", cmdName.String(), strings.Join(names, ", ")), nil
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCodeOwnersRule_MatchesDir(t *testing.T) {
+	cases := []struct {
+		pattern string
+		dir     string
+		exp     bool
+	}{
+		{pattern: "*", dir: ".", exp: true},
+		{pattern: "/envs/", dir: "envs/prod", exp: true},
+		{pattern: "/envs/", dir: "modules/envs", exp: false},
+		{pattern: "envs/", dir: "modules/envs", exp: true},
+		{pattern: "/envs/prod/**", dir: "envs/prod/vpc", exp: true},
+		{pattern: "/envs/prod", dir: "envs/staging", exp: false},
+		{pattern: "envs/*/vpc", dir: "envs/prod/vpc", exp: true},
+		{pattern: "**/vpc", dir: "envs/prod/vpc", exp: true},
+		{pattern: "*.tf", dir: "envs", exp: false},
+		{pattern: "/envs/", dir: ".", exp: false},
+	}
+	for _, c := range cases {
+		t.Run(c.pattern+" "+c.dir, func(t *testing.T) {
+			Equals(t, c.exp, codeOwnersRule{pattern: c.pattern}.matchesDir(c.dir))
+		})
+	}
+}
+
+func TestCodeOwnersForDir(t *testing.T) {
+	rules := parseCodeOwners([]byte(`
+# Default owners.
+* @org/admins
+
+[Networking]
+/networking/ @alice @org/network @other-org/network network@example.com # the network team
+/networking/legacy/
+`))
+	repo := models.Repo{Owner: "org", Name: "repo"}
+
+	Equals(t, &valid.PolicyOwners{Teams: []string{"org/admins", "admins"}}, codeOwnersForDir(rules, "billing", repo))
+	Equals(t, &valid.PolicyOwners{
+		Users: []string{"alice"},
+		Teams: []string{"org/network", "network", "other-org/network"},
+	}, codeOwnersForDir(rules, "networking/vpc", repo))
+	// A rule without owners leaves the directory without owners.
+	Equals(t, (*valid.PolicyOwners)(nil), codeOwnersForDir(rules, "networking/legacy", repo))
+	Equals(t, (*valid.PolicyOwners)(nil), codeOwnersForDir(nil, "billing", repo))
+	// Email addresses can't be resolved so nobody owns the directory.
+	Equals(t, &valid.PolicyOwners{}, codeOwnersForDir(parseCodeOwners([]byte("* team@example.com")), "billing", repo))
+}
@@ -0,0 +1,144 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// DefaultReplanInterval is how long the ProjectReplanner waits between the
+// plans it queues if no interval is configured.
+const DefaultReplanInterval = 10 * time.Second
+
+// PullStatusLister lists the statuses of the open pull requests.
+type PullStatusLister interface {
+	ListPullStatuses() ([]models.PullStatus, error)
+}
+
+// ReplanTarget selects the project to replan. Projects are matched by name
+// if ProjectName is set, otherwise by directory and, if set, workspace.
+type ReplanTarget struct {
+	Repository  string
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+}
+
+// matches returns true if project is the target.
+func (t ReplanTarget) matches(project models.ProjectStatus) bool {
+	if t.ProjectName != "" {
+		return project.ProjectName == t.ProjectName
+	}
+	if project.RepoRelDir != strings.TrimRight(t.RepoRelDir, "/") {
+		return false
+	}
+	return t.Workspace == "" || project.Workspace == t.Workspace
+}
+
+// ReplannedPull is a pull request a replan was queued or skipped for.
+type ReplannedPull struct {
+	Num int
+	URL string
+	// Skipped is why the pull request isn't replanned, if it isn't.
+	Skipped string `json:",omitempty"`
+}
+
+// ProjectReplanner replans a project on all the open pull requests that
+// modify it, ex. after a provider upgrade or a policy change, instead of
+// someone commenting atlantis plan on each of them.
+type ProjectReplanner struct {
+	PullStatusLister PullStatusLister
+	CommandRunner    CommandRunner
+	Logger           logging.SimpleLogging
+	// Interval is how long to wait between plans so that replanning many
+	// pull requests doesn't overload Atlantis or the VCS host.
+	Interval time.Duration
+	// mu makes replans run one after the other so they share the rate limit.
+	mu sync.Mutex
+}
+
+// Replan queues a plan of target on each open pull request that modifies it
+// and returns those pull requests. The plans run in the background, one every
+// Interval. Bitbucket pull requests can't be fetched so they're skipped.
+func (r *ProjectReplanner) Replan(target ReplanTarget) ([]ReplannedPull, error) {
+	statuses, err := r.PullStatusLister.ListPullStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	var replanned []ReplannedPull
+	var queued []models.PullRequest
+	for _, status := range statuses {
+		pull := status.Pull
+		if pull.BaseRepo.FullName != target.Repository || !r.modifiesTarget(status, target) {
+			continue
+		}
+		if pull.BaseRepo.VCSHost.Type == models.BitbucketCloud || pull.BaseRepo.VCSHost.Type == models.BitbucketServer {
+			replanned = append(replanned, ReplannedPull{Num: pull.Num, URL: pull.URL, Skipped: "Bitbucket pull requests can only be planned by comments on them"})
+			continue
+		}
+		replanned = append(replanned, ReplannedPull{Num: pull.Num, URL: pull.URL})
+		queued = append(queued, pull)
+	}
+
+	if len(queued) > 0 {
+		r.Logger.Info("queuing replans of %s on %d pull requests", target.description(), len(queued))
+		go r.runPlans(target, queued)
+	}
+	return replanned, nil
+}
+
+// modifiesTarget returns true if target is one of the projects of status.
+func (r *ProjectReplanner) modifiesTarget(status models.PullStatus, target ReplanTarget) bool {
+	for _, project := range status.Projects {
+		if target.matches(project) {
+			return true
+		}
+	}
+	return false
+}
+
+// runPlans plans target on pulls, waiting Interval between plans.
+func (r *ProjectReplanner) runPlans(target ReplanTarget, pulls []models.PullRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interval := r.Interval
+	if interval == 0 {
+		interval = DefaultReplanInterval
+	}
+
+	cmd := target.planCommand()
+	for i, pull := range pulls {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		r.Logger.Info("replanning %s on %s#%d", target.description(), pull.BaseRepo.FullName, pull.Num)
+		planCmd := cmd
+		// The pull request is fetched again since its head may have moved.
+		r.CommandRunner.RunCommentCommand(pull.BaseRepo, nil, nil, models.User{Username: pull.Author}, pull.Num, &planCmd)
+	}
+}
+
+// planCommand returns the command that plans the target.
+func (t ReplanTarget) planCommand() CommentCommand {
+	if t.ProjectName != "" {
+		return CommentCommand{Name: command.Plan, ProjectName: t.ProjectName}
+	}
+	return CommentCommand{Name: command.Plan, RepoRelDir: strings.TrimRight(t.RepoRelDir, "/"), Workspace: t.Workspace}
+}
+
+// description returns the target as shown in logs.
+func (t ReplanTarget) description() string {
+	if t.ProjectName != "" {
+		return "project " + t.ProjectName
+	}
+	if t.Workspace != "" {
+		return "dir " + t.RepoRelDir + " workspace " + t.Workspace
+	}
+	return "dir " + t.RepoRelDir
+}
@@ -0,0 +1,103 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock/v4"
+	lockingmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProjectReplanner_Replan(t *testing.T) {
+	RegisterMockTestingT(t)
+	github := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github}}
+	bitbucket := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.BitbucketCloud}}
+	other := models.Repo{FullName: "owner/other", VCSHost: models.VCSHost{Type: models.Github}}
+	statuses := []models.PullStatus{
+		{Pull: models.PullRequest{Num: 1, Author: "alice", BaseRepo: github}, Projects: []models.ProjectStatus{{ProjectName: "infra", RepoRelDir: "infra", Workspace: "default"}}},
+		{Pull: models.PullRequest{Num: 2, BaseRepo: github}, Projects: []models.ProjectStatus{{ProjectName: "app", RepoRelDir: "app", Workspace: "default"}}},
+		{Pull: models.PullRequest{Num: 3, BaseRepo: other}, Projects: []models.ProjectStatus{{ProjectName: "infra", RepoRelDir: "infra", Workspace: "default"}}},
+		{Pull: models.PullRequest{Num: 4, BaseRepo: bitbucket}, Projects: []models.ProjectStatus{{ProjectName: "infra", RepoRelDir: "infra", Workspace: "default"}}},
+		{Pull: models.PullRequest{Num: 5, Author: "bob", BaseRepo: github}, Projects: []models.ProjectStatus{{ProjectName: "infra", RepoRelDir: "infra", Workspace: "staging"}}},
+	}
+
+	cases := map[string]struct {
+		target     events.ReplanTarget
+		expPulls   []events.ReplannedPull
+		expPlanned []int
+	}{
+		"by project name": {
+			target: events.ReplanTarget{Repository: "owner/repo", ProjectName: "infra"},
+			expPulls: []events.ReplannedPull{
+				{Num: 1},
+				{Num: 4, Skipped: "Bitbucket pull requests can only be planned by comments on them"},
+				{Num: 5},
+			},
+			expPlanned: []int{1, 5},
+		},
+		"by dir and workspace": {
+			target:     events.ReplanTarget{Repository: "owner/repo", RepoRelDir: "infra/", Workspace: "staging"},
+			expPulls:   []events.ReplannedPull{{Num: 5}},
+			expPlanned: []int{5},
+		},
+		"no pulls": {
+			target: events.ReplanTarget{Repository: "owner/repo", ProjectName: "missing"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			backend := lockingmocks.NewMockBackend()
+			When(backend.ListPullStatuses()).ThenReturn(statuses, nil)
+			runner := mocks.NewMockCommandRunner()
+			r := &events.ProjectReplanner{
+				PullStatusLister: backend,
+				CommandRunner:    runner,
+				Logger:           logging.NewNoopLogger(t),
+				Interval:         time.Millisecond,
+			}
+
+			pulls, err := r.Replan(c.target)
+			Ok(t, err)
+			Equals(t, c.expPulls, pulls)
+
+			for _, num := range c.expPlanned {
+				runner.VerifyWasCalledEventually(Once(), time.Second).RunCommentCommand(
+					Eq(github), Any[*models.Repo](), Any[*models.PullRequest](), Any[models.User](), Eq(num), Any[*events.CommentCommand]())
+			}
+			if len(c.expPlanned) == 0 {
+				runner.VerifyWasCalled(Never()).RunCommentCommand(
+					Any[models.Repo](), Any[*models.Repo](), Any[*models.PullRequest](), Any[models.User](), Any[int](), Any[*events.CommentCommand]())
+			}
+		})
+	}
+}
+
+func TestProjectReplanner_ReplanCommand(t *testing.T) {
+	RegisterMockTestingT(t)
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github}}
+	backend := lockingmocks.NewMockBackend()
+	When(backend.ListPullStatuses()).ThenReturn([]models.PullStatus{
+		{Pull: models.PullRequest{Num: 1, Author: "alice", BaseRepo: repo}, Projects: []models.ProjectStatus{{RepoRelDir: "infra", Workspace: "default"}}},
+	}, nil)
+	runner := mocks.NewMockCommandRunner()
+	r := &events.ProjectReplanner{
+		PullStatusLister: backend,
+		CommandRunner:    runner,
+		Logger:           logging.NewNoopLogger(t),
+	}
+
+	_, err := r.Replan(events.ReplanTarget{Repository: "owner/repo", RepoRelDir: "infra"})
+	Ok(t, err)
+
+	_, _, _, user, _, cmd := runner.VerifyWasCalledEventually(Once(), time.Second).RunCommentCommand(
+		Eq(repo), Any[*models.Repo](), Any[*models.PullRequest](), Any[models.User](), Eq(1), Any[*events.CommentCommand]()).GetCapturedArguments()
+	Equals(t, models.User{Username: "alice"}, user)
+	Equals(t, events.CommentCommand{Name: command.Plan, RepoRelDir: "infra"}, *cmd)
+}
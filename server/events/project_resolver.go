@@ -0,0 +1,84 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// resolvedProject is a project returned by a repo's project resolver command.
+// Either Name is set, referring to a project configured in atlantis.yaml, or
+// Dir is set, with an optional Workspace.
+type resolvedProject struct {
+	Name      string `json:"name,omitempty"`
+	Dir       string `json:"dir,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+}
+
+// runProjectResolver runs the repo's project resolver command in repoDir. The
+// modified files are written to the command's stdin, one per line, and the
+// command must write a JSON array of projects to stdout.
+func runProjectResolver(ctx *command.Context, resolver valid.ProjectResolver, repoDir string, modifiedFiles []string) ([]resolvedProject, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), resolver.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(timeoutCtx, "sh", "-c", resolver.Command) // #nosec
+	cmd.Dir = repoDir
+	// Don't wait on processes started by the command that still hold its
+	// output open after it's been killed on timeout.
+	cmd.WaitDelay = time.Second
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("BASE_BRANCH_NAME=%s", ctx.Pull.BaseBranch),
+		fmt.Sprintf("BASE_REPO_NAME=%s", ctx.Pull.BaseRepo.Name),
+		fmt.Sprintf("BASE_REPO_OWNER=%s", ctx.Pull.BaseRepo.Owner),
+		fmt.Sprintf("DIR=%s", repoDir),
+		fmt.Sprintf("HEAD_BRANCH_NAME=%s", ctx.Pull.HeadBranch),
+		fmt.Sprintf("HEAD_COMMIT=%s", ctx.Pull.HeadCommit),
+		fmt.Sprintf("PULL_NUM=%d", ctx.Pull.Num),
+	)
+	cmd.Stdin = strings.NewReader(strings.Join(modifiedFiles, "\n") + "\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if timeoutCtx.Err() != nil {
+			err = fmt.Errorf("timed out after %s", resolver.Timeout)
+		}
+		return nil, fmt.Errorf("running project resolver %q: %w: %s", resolver.Command, err, stderr.String())
+	}
+	if stderr.Len() > 0 {
+		ctx.Log.Debug("project resolver stderr: %s", stderr.String())
+	}
+	return parseResolvedProjects(stdout.Bytes())
+}
+
+// parseResolvedProjects parses and validates the output of a project resolver.
+func parseResolvedProjects(output []byte) ([]resolvedProject, error) {
+	var projects []resolvedProject
+	if err := json.Unmarshal(output, &projects); err != nil {
+		return nil, fmt.Errorf("parsing project resolver output as a JSON array of projects: %w", err)
+	}
+	for i, p := range projects {
+		if p.Name == "" && p.Dir == "" {
+			return nil, fmt.Errorf("project resolver output: project %d must set one of 'name' or 'dir'", i)
+		}
+		if p.Dir != "" {
+			dir := path.Clean(p.Dir)
+			if path.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+				return nil, fmt.Errorf("project resolver output: dir %q must be inside the repo", p.Dir)
+			}
+			projects[i].Dir = dir
+		}
+	}
+	return projects, nil
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestParseResolvedProjects(t *testing.T) {
+	cases := []struct {
+		description string
+		output      string
+		exp         []resolvedProject
+		expErr      string
+	}{
+		{
+			description: "empty array",
+			output:      "[]",
+			exp:         []resolvedProject{},
+		},
+		{
+			description: "projects by name and dir",
+			output:      `[{"name": "proj"}, {"dir": "./a/b/", "workspace": "staging"}]`,
+			exp: []resolvedProject{
+				{Name: "proj"},
+				{Dir: "a/b", Workspace: "staging"},
+			},
+		},
+		{
+			description: "not json",
+			output:      "a/b",
+			expErr:      "parsing project resolver output as a JSON array of projects",
+		},
+		{
+			description: "neither name nor dir",
+			output:      `[{"workspace": "staging"}]`,
+			expErr:      "project 0 must set one of 'name' or 'dir'",
+		},
+		{
+			description: "dir outside of the repo",
+			output:      `[{"dir": "a/../../b"}]`,
+			expErr:      `dir "a/../../b" must be inside the repo`,
+		},
+		{
+			description: "absolute dir",
+			output:      `[{"dir": "/etc"}]`,
+			expErr:      `dir "/etc" must be inside the repo`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			projects, err := parseResolvedProjects([]byte(c.output))
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.exp, projects)
+		})
+	}
+}
+
+func TestRunProjectResolver(t *testing.T) {
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t),
+		Pull: models.PullRequest{
+			Num:      7,
+			BaseRepo: models.Repo{Owner: "owner", Name: "repo"},
+		},
+	}
+	repoDir := t.TempDir()
+
+	t.Run("receives modified files and env vars", func(t *testing.T) {
+		resolver := valid.ProjectResolver{
+			Command: `read -r first; printf '[{"dir": "%s", "workspace": "%s-%s"}]' "$first" "$BASE_REPO_NAME" "$PULL_NUM"`,
+			Timeout: time.Minute,
+		}
+		projects, err := runProjectResolver(ctx, resolver, repoDir, []string{"a/main.tf", "b/main.tf"})
+		Ok(t, err)
+		Equals(t, []resolvedProject{{Dir: "a/main.tf", Workspace: "repo-7"}}, projects)
+	})
+
+	t.Run("command fails", func(t *testing.T) {
+		resolver := valid.ProjectResolver{
+			Command: "echo broken >&2; exit 1",
+			Timeout: time.Minute,
+		}
+		_, err := runProjectResolver(ctx, resolver, repoDir, nil)
+		ErrContains(t, "broken", err)
+	})
+
+	t.Run("command times out", func(t *testing.T) {
+		resolver := valid.ProjectResolver{
+			Command: "sleep 5",
+			Timeout: 10 * time.Millisecond,
+		}
+		_, err := runProjectResolver(ctx, resolver, repoDir, nil)
+		ErrContains(t, "timed out after 10ms", err)
+	})
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// readAppliedPlan returns the planfile of the project so it can be recorded
+// after the apply, which deletes it. It returns nil if neither state
+// snapshots nor provenance are enabled or the project has no planfile.
+func (p *DefaultProjectCommandRunner) readAppliedPlan(ctx command.ProjectContext, absPath string) []byte {
+	if p.StateSnapshotStore == nil && p.ProvenanceStore == nil {
+		return nil
+	}
+	plan, err := os.ReadFile(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName)))
+	if err != nil && !os.IsNotExist(err) {
+		ctx.Log.Err("reading applied plan: %s", err)
+	}
+	return plan
+}
+
+// saveApplyProvenance records what the apply of the project applied. The apply
+// has already changed infrastructure so errors are logged instead of failing
+// it.
+func (p *DefaultProjectCommandRunner) saveApplyProvenance(ctx command.ProjectContext, plan []byte) {
+	if p.ProvenanceStore == nil {
+		return
+	}
+	var planHash string
+	if plan != nil {
+		sum := sha256.Sum256(plan)
+		planHash = hex.EncodeToString(sum[:])
+	}
+	var jobURL string
+	if p.JobURLGenerator != nil {
+		var err error
+		if jobURL, err = p.JobURLGenerator.GenerateProjectJobURL(ctx); err != nil {
+			ctx.Log.Warn("generating job url for provenance: %s", err)
+		}
+	}
+	err := p.ProvenanceStore.SaveApply(models.ApplyProvenance{
+		JobID:         ctx.JobID,
+		RepoFullName:  ctx.Pull.BaseRepo.FullName,
+		PullNum:       ctx.Pull.Num,
+		HeadCommit:    ctx.Pull.HeadCommit,
+		ProjectName:   ctx.ProjectName,
+		RepoRelDir:    ctx.RepoRelDir,
+		Workspace:     ctx.Workspace,
+		User:          ctx.User.Username,
+		AppliedAt:     time.Now(),
+		PlanSHA256:    planHash,
+		JobURL:        jobURL,
+		PolicyResults: ctx.ProjectPolicyStatus,
+	})
+	if err != nil {
+		ctx.Log.Err("saving apply provenance: %s", err)
+	}
+}
+
+// buildProvenance returns the provenance record of the merge of pull. It
+// holds the latest apply at the head commit of each project in pullStatus.
+func buildProvenance(pull models.PullRequest, pullStatus models.PullStatus, applies []models.ApplyProvenance, mergeCommit string, mergedBy string, mergedAt time.Time) models.Provenance {
+	provenance := models.Provenance{
+		RepoFullName: pull.BaseRepo.FullName,
+		PullNum:      pull.Num,
+		HeadCommit:   pull.HeadCommit,
+		MergeCommit:  mergeCommit,
+		MergedAt:     mergedAt,
+		MergedBy:     mergedBy,
+	}
+	for _, project := range pullStatus.Projects {
+		var latest *models.ApplyProvenance
+		for i, apply := range applies {
+			if apply.HeadCommit == pull.HeadCommit &&
+				apply.RepoRelDir == project.RepoRelDir &&
+				apply.Workspace == project.Workspace &&
+				apply.ProjectName == project.ProjectName {
+				latest = &applies[i]
+			}
+		}
+		if latest != nil {
+			provenance.Applies = append(provenance.Applies, *latest)
+		}
+	}
+	return provenance
+}
+
+// provenanceDigest returns the hex encoded SHA-256 hash of the JSON of
+// provenance as it's returned by the provenance API endpoint.
+func provenanceDigest(provenance models.Provenance) (string, error) {
+	contents, err := json.Marshal(provenance)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// provenanceURL returns the link to the provenance API endpoint for pull.
+func provenanceURL(atlantisURL string, pull models.PullRequest) string {
+	return fmt.Sprintf("%s/api/provenance?repository=%s&pull=%d", atlantisURL, url.QueryEscape(pull.BaseRepo.FullName), pull.Num)
+}
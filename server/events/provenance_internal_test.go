@@ -0,0 +1,40 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestBuildProvenance(t *testing.T) {
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "head",
+		BaseRepo:   models.Repo{FullName: "owner/repo"},
+	}
+	pullStatus := models.PullStatus{
+		Projects: []models.ProjectStatus{
+			{RepoRelDir: "a", Workspace: "default"},
+			{RepoRelDir: "b", Workspace: "default"},
+		},
+	}
+	oldCommit := models.ApplyProvenance{JobID: "old-commit", HeadCommit: "old", RepoRelDir: "a", Workspace: "default"}
+	firstA := models.ApplyProvenance{JobID: "first-a", HeadCommit: "head", RepoRelDir: "a", Workspace: "default"}
+	secondA := models.ApplyProvenance{JobID: "second-a", HeadCommit: "head", RepoRelDir: "a", Workspace: "default"}
+	b := models.ApplyProvenance{JobID: "b", HeadCommit: "head", RepoRelDir: "b", Workspace: "default"}
+	otherProject := models.ApplyProvenance{JobID: "c", HeadCommit: "head", RepoRelDir: "c", Workspace: "default"}
+	mergedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	provenance := buildProvenance(pull, pullStatus, []models.ApplyProvenance{oldCommit, firstA, b, secondA, otherProject}, "merge", "jdoe", mergedAt)
+	Equals(t, models.Provenance{
+		RepoFullName: "owner/repo",
+		PullNum:      1,
+		HeadCommit:   "head",
+		MergeCommit:  "merge",
+		MergedAt:     mergedAt,
+		MergedBy:     "jdoe",
+		Applies:      []models.ApplyProvenance{secondA, b},
+	}, provenance)
+}
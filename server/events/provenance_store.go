@@ -0,0 +1,129 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// provenanceFileName is the name of the file a pull request's provenance
+// record is stored in.
+const provenanceFileName = "provenance.json"
+
+// ProvenanceStore stores the provenance of applies and of the merges of the
+// pull requests they were applied for.
+type ProvenanceStore interface {
+	// SaveApply stores apply, replacing any apply with the same job ID.
+	SaveApply(apply models.ApplyProvenance) error
+	// ListApplies returns the applies for a pull request, oldest first.
+	ListApplies(repoFullName string, pullNum int) ([]models.ApplyProvenance, error)
+	// Save stores the provenance record of a merged pull request.
+	Save(provenance models.Provenance) error
+	// Get returns the provenance record of a pull request or nil if it wasn't
+	// merged by Atlantis.
+	Get(repoFullName string, pullNum int) (*models.Provenance, error)
+}
+
+// FileProvenanceStore stores provenance as JSON files in Dir. Like SBOMs they
+// aren't deleted when the pull request is closed since they're kept for
+// audits.
+type FileProvenanceStore struct {
+	Dir string
+}
+
+func (f *FileProvenanceStore) SaveApply(apply models.ApplyProvenance) error {
+	appliesDir, err := f.appliesDir(apply.RepoFullName, apply.PullNum)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(appliesDir, 0700); err != nil {
+		return errors.Wrap(err, "creating provenance dir")
+	}
+	contents, err := json.MarshalIndent(apply, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(appliesDir, apply.JobID+".json"), contents, 0600)
+}
+
+func (f *FileProvenanceStore) ListApplies(repoFullName string, pullNum int) ([]models.ApplyProvenance, error) {
+	appliesDir, err := f.appliesDir(repoFullName, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(appliesDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var applies []models.ApplyProvenance
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(appliesDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var apply models.ApplyProvenance
+		if err := json.Unmarshal(contents, &apply); err != nil {
+			return nil, errors.Wrapf(err, "parsing apply provenance %s", entry.Name())
+		}
+		applies = append(applies, apply)
+	}
+	slices.SortFunc(applies, func(a, b models.ApplyProvenance) int {
+		return a.AppliedAt.Compare(b.AppliedAt)
+	})
+	return applies, nil
+}
+
+func (f *FileProvenanceStore) Save(provenance models.Provenance) error {
+	pullDir, err := storePullDir(f.Dir, provenance.RepoFullName, provenance.PullNum)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(pullDir, 0700); err != nil {
+		return errors.Wrap(err, "creating provenance dir")
+	}
+	contents, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(pullDir, provenanceFileName), contents, 0600)
+}
+
+func (f *FileProvenanceStore) Get(repoFullName string, pullNum int) (*models.Provenance, error) {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(filepath.Join(pullDir, provenanceFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var provenance models.Provenance
+	if err := json.Unmarshal(contents, &provenance); err != nil {
+		return nil, errors.Wrap(err, "parsing provenance")
+	}
+	return &provenance, nil
+}
+
+// appliesDir returns the directory the applies of a pull request are stored
+// in.
+func (f *FileProvenanceStore) appliesDir(repoFullName string, pullNum int) (string, error) {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(pullDir, "applies"), nil
+}
@@ -0,0 +1,59 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFileProvenanceStore_SaveAndListApplies(t *testing.T) {
+	store := &events.FileProvenanceStore{Dir: t.TempDir()}
+	first := models.ApplyProvenance{
+		JobID:        "first",
+		RepoFullName: "owner/repo",
+		PullNum:      1,
+		AppliedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PlanSHA256:   "abc",
+	}
+	second := models.ApplyProvenance{
+		JobID:         "second",
+		RepoFullName:  "owner/repo",
+		PullNum:       1,
+		AppliedAt:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		PolicyResults: []models.PolicySetStatus{{PolicySetName: "policies", Passed: true}},
+	}
+	Ok(t, store.SaveApply(second))
+	Ok(t, store.SaveApply(first))
+
+	applies, err := store.ListApplies("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, []models.ApplyProvenance{first, second}, applies)
+
+	applies, err = store.ListApplies("owner/repo", 2)
+	Ok(t, err)
+	Equals(t, 0, len(applies))
+}
+
+func TestFileProvenanceStore_SaveAndGet(t *testing.T) {
+	store := &events.FileProvenanceStore{Dir: t.TempDir()}
+
+	provenance, err := store.Get("owner/repo", 1)
+	Ok(t, err)
+	Assert(t, provenance == nil, "expected no provenance before the merge")
+
+	exp := models.Provenance{
+		RepoFullName: "owner/repo",
+		PullNum:      1,
+		HeadCommit:   "head",
+		MergeCommit:  "merge",
+		MergedAt:     time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		Applies:      []models.ApplyProvenance{{JobID: "first", PlanSHA256: "abc"}},
+	}
+	Ok(t, store.Save(exp))
+	provenance, err = store.Get("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, exp, *provenance)
+}
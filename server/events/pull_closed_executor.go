@@ -54,6 +54,21 @@ type PullClosedExecutor struct {
 	Backend                  locking.Backend
 	PullClosedTemplate       PullCleanupTemplate
 	LogStreamResourceCleaner ResourceCleaner
+	// PlanJSONStore is nil if plans aren't stored for the plan JSON API.
+	PlanJSONStore PlanJSONStore
+	// PlanChangesStore is nil if the changes of plans aren't stored.
+	PlanChangesStore PlanChangesStore
+	// LifecyclePlugins is nil if no lifecycle plugins are configured.
+	LifecyclePlugins *LifecyclePlugins
+	// SilenceStore is nil if projects can't be silenced.
+	SilenceStore SilenceStore
+	// RepoCfgSuggestionStore is nil if atlantis.yaml files aren't suggested.
+	RepoCfgSuggestionStore RepoCfgSuggestionStore
+	// CanaryStore is nil if the canaries applied in pull requests aren't
+	// stored.
+	CanaryStore CanaryStore
+	// AutoApplyStore is nil if low-risk plans aren't applied automatically.
+	AutoApplyStore AutoApplyStore
 }
 
 type templatedProject struct {
@@ -78,6 +93,11 @@ func (t *PullClosedEventTemplate) Execute(wr io.Writer, data interface{}) error
 
 // CleanUpPull cleans up after a closed pull request.
 func (p *PullClosedExecutor) CleanUpPull(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) error {
+	if p.LifecyclePlugins != nil {
+		// Plugins can't veto a close so failures are only logged.
+		p.LifecyclePlugins.Run(logger, pullLifecyclePayload(pull, PullClosedLifecycleEvent))
+	}
+
 	pullStatus, err := p.Backend.GetPullStatus(pull)
 	if err != nil {
 		// Log and continue to clean up other resources.
@@ -100,6 +120,48 @@ func (p *PullClosedExecutor) CleanUpPull(logger logging.SimpleLogging, repo mode
 		return errors.Wrap(err, "cleaning workspace")
 	}
 
+	if p.PlanJSONStore != nil {
+		if err := p.PlanJSONStore.DeleteForPull(repo.FullName, pull.Num); err != nil {
+			// Log and continue to clean up other resources.
+			logger.Err("deleting plan json: %s", err)
+		}
+	}
+
+	if p.PlanChangesStore != nil {
+		if err := p.PlanChangesStore.DeleteForPull(repo.FullName, pull.Num); err != nil {
+			// Log and continue to clean up other resources.
+			logger.Err("deleting plan changes: %s", err)
+		}
+	}
+
+	if p.SilenceStore != nil {
+		if err := p.SilenceStore.DeleteForPull(repo.FullName, pull.Num); err != nil {
+			// Log and continue to clean up other resources.
+			logger.Err("deleting silenced projects: %s", err)
+		}
+	}
+
+	if p.RepoCfgSuggestionStore != nil {
+		if err := p.RepoCfgSuggestionStore.DeleteForPull(repo.FullName, pull.Num); err != nil {
+			// Log and continue to clean up other resources.
+			logger.Err("deleting repo config suggestion: %s", err)
+		}
+	}
+
+	if p.CanaryStore != nil {
+		if err := p.CanaryStore.DeleteForPull(repo.FullName, pull.Num); err != nil {
+			// Log and continue to clean up other resources.
+			logger.Err("deleting canaries: %s", err)
+		}
+	}
+
+	if p.AutoApplyStore != nil {
+		if err := p.AutoApplyStore.DeleteForPull(repo.FullName, pull.Num); err != nil {
+			// Log and continue to clean up other resources.
+			logger.Err("deleting auto-applies: %s", err)
+		}
+	}
+
 	// Finally, delete locks. We do this last because when someone
 	// unlocks a project, right now we don't actually delete the plan
 	// so we might have plans laying around but no locks.
@@ -1,7 +1,11 @@
 package events
 
 import (
+	"slices"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/utils"
 )
@@ -10,6 +14,15 @@ type PullUpdater struct {
 	HidePrevPlanComments bool
 	VCSClient            vcs.Client
 	MarkdownRenderer     *MarkdownRenderer
+	// CommentReactions are the reactions added to the comment that triggered
+	// a command once it succeeds or fails.
+	CommentReactions CommentReactions
+	// GlobalCfgStore holds the server-side repo config, read for the comment
+	// layout and comment templates of each repo.
+	GlobalCfgStore *valid.GlobalCfgStore
+	// SilenceStore holds the projects silenced with the silence command. If
+	// nil, no project is silenced.
+	SilenceStore SilenceStore
 }
 
 func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res command.Result) {
@@ -20,6 +33,12 @@ func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res comm
 		ctx.Log.Warn(res.Failure)
 	}
 
+	c.reactToComment(ctx, cmd, res)
+
+	if res.RepoCfgReview == nil {
+		res.RepoCfgReview = ctx.RepoCfgReview
+	}
+
 	// HidePrevCommandComments will hide old comments left from previous runs to reduce
 	// clutter in a pull/merge request. This will not delete the comment, since the
 	// comment trail may be useful in auditing or backtracing problems.
@@ -31,12 +50,19 @@ func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res comm
 	}
 
 	if len(res.ProjectResults) > 0 {
+		silenced := c.silencedProjects(ctx)
 		var commentOnProjects []command.ProjectResult
 		for _, result := range res.ProjectResults {
 			if utils.SlicesContains(result.SilencePRComments, cmd.CommandName().String()) {
 				ctx.Log.Debug("silenced command '%s' comment for project '%s'", cmd.CommandName().String(), result.ProjectName)
 				continue
 			}
+			if slices.ContainsFunc(silenced, func(s models.SilencedProject) bool {
+				return s.Matches(result.ProjectName, result.RepoRelDir, result.Workspace)
+			}) {
+				ctx.Log.Debug("project at dir '%s' workspace '%s' was silenced in this pull request", result.RepoRelDir, result.Workspace)
+				continue
+			}
 			commentOnProjects = append(commentOnProjects, result)
 		}
 
@@ -47,8 +73,66 @@ func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res comm
 		res.ProjectResults = commentOnProjects
 	}
 
-	comment := c.MarkdownRenderer.Render(ctx, res, cmd)
+	globalCfg := c.GlobalCfgStore.Load()
+	commentTemplates := globalCfg.CommentTemplates(ctx.Pull.BaseRepo.ID())
+	layout := globalCfg.CommentLayout(ctx.Pull.BaseRepo.ID())
+	if layout == valid.CommentLayoutPerProject && len(res.ProjectResults) > 1 {
+		c.commentPerProject(ctx, cmd, res, commentTemplates)
+		return
+	}
+
+	comment := c.MarkdownRenderer.RenderLayout(ctx, res, cmd, layout)
+	comment = addCommentTemplates(ctx, commentTemplates, cmd, res, comment)
 	if err := c.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment, cmd.CommandName().String()); err != nil {
 		ctx.Log.Err("unable to comment: %s", err)
 	}
 }
+
+// silencedProjects returns the projects silenced in the pull request of ctx.
+// Errors are logged and no project is silenced.
+func (c *PullUpdater) silencedProjects(ctx *command.Context) []models.SilencedProject {
+	if c.SilenceStore == nil {
+		return nil
+	}
+	silenced, err := c.SilenceStore.ListForPull(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	if err != nil {
+		ctx.Log.Warn("unable to list silenced projects: %s", err)
+		return nil
+	}
+	return silenced
+}
+
+// commentPerProject comments the result of each project of res separately.
+// The impact previews are added to the comment of the last project and the
+// review of the modified repo config to the comment of the first one.
+func (c *PullUpdater) commentPerProject(ctx *command.Context, cmd PullCommand, res command.Result, commentTemplates *valid.CommentTemplates) {
+	for i, result := range res.ProjectResults {
+		projectRes := res
+		projectRes.ProjectResults = []command.ProjectResult{result}
+		if i < len(res.ProjectResults)-1 {
+			projectRes.ImpactPreviews = nil
+		}
+		if i > 0 {
+			projectRes.RepoCfgReview = nil
+		}
+		comment := c.MarkdownRenderer.Render(ctx, projectRes, cmd)
+		comment = addCommentTemplates(ctx, commentTemplates, cmd, projectRes, comment)
+		if err := c.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment, cmd.CommandName().String()); err != nil {
+			ctx.Log.Err("unable to comment: %s", err)
+		}
+	}
+}
+
+// reactToComment reacts to the comment that triggered cmd with the success or
+// failure reaction. Autoplans aren't triggered by a comment so they're skipped.
+func (c *PullUpdater) reactToComment(ctx *command.Context, cmd PullCommand, res command.Result) {
+	commentCmd, ok := cmd.(*CommentCommand)
+	if !ok {
+		return
+	}
+	state := ReactionSuccess
+	if res.HasErrors() {
+		state = ReactionFailure
+	}
+	c.CommentReactions.React(ctx.Log, c.VCSClient, ctx.Pull.BaseRepo, ctx.Pull.Num, commentCmd, state)
+}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPullUpdater_ReactToComment(t *testing.T) {
+	cases := map[string]struct {
+		cmd         PullCommand
+		res         command.Result
+		expReaction string
+	}{
+		"success": {
+			cmd:         &CommentCommand{Name: command.Plan, CommentID: 123},
+			res:         command.Result{},
+			expReaction: "rocket",
+		},
+		"error": {
+			cmd:         &CommentCommand{Name: command.Plan, CommentID: 123},
+			res:         command.Result{Error: errors.New("err")},
+			expReaction: "confused",
+		},
+		"failure": {
+			cmd:         &CommentCommand{Name: command.Apply, CommentID: 123},
+			res:         command.Result{Failure: "failure"},
+			expReaction: "confused",
+		},
+		"command success": {
+			cmd:         &CommentCommand{Name: command.Apply, CommentID: 123},
+			res:         command.Result{},
+			expReaction: "tada",
+		},
+		"project error": {
+			cmd: &CommentCommand{Name: command.Apply, CommentID: 123},
+			res: command.Result{
+				ProjectResults: []command.ProjectResult{{Error: errors.New("err")}},
+			},
+			expReaction: "confused",
+		},
+		"no comment id": {
+			cmd: &CommentCommand{Name: command.Plan},
+			res: command.Result{},
+		},
+		"autoplan": {
+			cmd: AutoplanCommand{},
+			res: command.Result{},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			vcsClient := mocks.NewMockClient()
+			reactions, err := ParseCommentReactions("success:rocket,failure:confused,apply.success:tada")
+			Ok(t, err)
+			updater := &PullUpdater{
+				VCSClient:        vcsClient,
+				CommentReactions: reactions,
+			}
+			repo := models.Repo{FullName: "owner/repo"}
+			ctx := &command.Context{
+				Log:  logging.NewNoopLogger(t),
+				Pull: models.PullRequest{BaseRepo: repo, Num: 1},
+			}
+
+			updater.reactToComment(ctx, c.cmd, c.res)
+
+			if c.expReaction == "" {
+				vcsClient.VerifyWasCalled(Never()).ReactToComment(Any[logging.SimpleLogging](), Any[models.Repo](), Any[int](), Any[int64](), Any[string]())
+				return
+			}
+			vcsClient.VerifyWasCalledOnce().ReactToComment(Any[logging.SimpleLogging](), Eq(repo), Eq(1), Eq(int64(123)), Eq(c.expReaction))
+		})
+	}
+}
+
+func TestPullUpdater_CommentPerProject(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := mocks.NewMockClient()
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com", Type: models.Github}}
+	updater := &PullUpdater{
+		VCSClient:        vcsClient,
+		MarkdownRenderer: NewMarkdownRenderer(false, false, false, false, false, false, "", "atlantis", false, false),
+		GlobalCfgStore: valid.NewGlobalCfgStore(valid.GlobalCfg{
+			Repos: []valid.Repo{{IDRegex: regexp.MustCompile(".*"), CommentLayout: valid.CommentLayoutPerProject}},
+		}),
+	}
+	ctx := &command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{BaseRepo: repo, Num: 1},
+	}
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			{RepoRelDir: "network", Workspace: "default", ApplySuccess: "network-output"},
+			{RepoRelDir: "app", Workspace: "default", ApplySuccess: "app-output"},
+		},
+	}
+
+	updater.updatePull(ctx, &CommentCommand{Name: command.Apply}, res)
+
+	_, _, _, bodies, _ := vcsClient.VerifyWasCalled(Times(2)).CreateComment(Any[logging.SimpleLogging](), Eq(repo), Eq(1), Any[string](), Eq("apply")).GetAllCapturedArguments()
+	Assert(t, strings.Contains(bodies[0], "network-output") && !strings.Contains(bodies[0], "app-output"), "exp only network in %q", bodies[0])
+	Assert(t, strings.Contains(bodies[1], "app-output") && !strings.Contains(bodies[1], "network-output"), "exp only app in %q", bodies[1])
+}
+
+func TestPullUpdater_CommentTemplates(t *testing.T) {
+	RegisterMockTestingT(t)
+	oncall := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "alice"}`) // nolint: errcheck
+	}))
+	defer oncall.Close()
+
+	vcsClient := mocks.NewMockClient()
+	repo := models.Repo{FullName: "owner/repo", Name: "repo", VCSHost: models.VCSHost{Hostname: "github.com", Type: models.Github}}
+	updater := &PullUpdater{
+		VCSClient:        vcsClient,
+		MarkdownRenderer: NewMarkdownRenderer(false, false, false, false, false, false, "", "atlantis", false, false),
+		GlobalCfgStore: valid.NewGlobalCfgStore(valid.GlobalCfg{
+			Repos: []valid.Repo{{
+				IDRegex: regexp.MustCompile(".*"),
+				CommentTemplates: &valid.CommentTemplates{
+					Header: "Ran {{ .Command }} for {{ .User.Username }} on #{{ .Pull.Num }}",
+					Footer: fmt.Sprintf(`{{ if not .Succeeded }}Runbook: https://wiki.example.com/{{ .Repo.Name }}. On call: {{ (fetchJSON %q).name }}{{ end }}`, oncall.URL),
+				},
+			}},
+		}),
+	}
+	ctx := &command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{BaseRepo: repo, Num: 1},
+		User: models.User{Username: "bob"},
+	}
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			{RepoRelDir: "network", Workspace: "default", Error: errors.New("apply-error")},
+		},
+	}
+
+	updater.updatePull(ctx, &CommentCommand{Name: command.Apply}, res)
+
+	_, _, _, comment, _ := vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(repo), Eq(1), Any[string](), Eq("apply")).GetCapturedArguments()
+	Assert(t, strings.HasPrefix(comment, "Ran apply for bob on #1\n\n"), "exp header in %q", comment)
+	Assert(t, strings.Contains(comment, "apply-error"), "exp results in %q", comment)
+	Assert(t, strings.HasSuffix(comment, "\n\nRunbook: https://wiki.example.com/repo. On call: alice"), "exp footer in %q", comment)
+}
@@ -15,17 +15,42 @@ package events
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 )
 
 // Wildcard matches 0-n of all characters except commas.
 const Wildcard = "*"
 
+// topicPrefix starts the repo part of allowlist rules that match the repos of
+// an owner with a topic, ex. github.com/myorg/topic:terraform.
+const topicPrefix = "topic:"
+
+// AllowlistTopicRule is an allowlist rule that matches the repos of Owner on
+// Hostname with Topic. Its repos are discovered through the VCS API.
+type AllowlistTopicRule struct {
+	Hostname string
+	Owner    string
+	Topic    string
+}
+
+// String returns the rule as it's written in the allowlist.
+func (t AllowlistTopicRule) String() string {
+	return fmt.Sprintf("%s/%s/%s%s", t.Hostname, t.Owner, topicPrefix, t.Topic)
+}
+
 // RepoAllowlistChecker implements checking if repos are allowlisted to be used with
 // this Atlantis.
 type RepoAllowlistChecker struct {
 	includeRules []string
 	omitRules    []string
+	topicRules   []AllowlistTopicRule
+
+	mu sync.RWMutex
+	// topicRepos are the full names of the repos last discovered for each
+	// topic rule, keyed by the rule.
+	topicRepos map[string][]string
 }
 
 // NewRepoAllowlistChecker constructs a new checker and validates that the
@@ -33,10 +58,21 @@ type RepoAllowlistChecker struct {
 func NewRepoAllowlistChecker(allowlist string) (*RepoAllowlistChecker, error) {
 	includeRules := make([]string, 0)
 	omitRules := make([]string, 0)
+	var topicRules []AllowlistTopicRule
 	for _, rule := range strings.Split(allowlist, ",") {
 		if strings.Contains(rule, "://") {
 			return nil, fmt.Errorf("allowlist %q contained ://", rule)
 		}
+		rulePattern := strings.TrimPrefix(rule, "!")
+		if strings.Contains(rulePattern, topicPrefix) {
+			topicRule, err := parseAllowlistTopicRule(rulePattern)
+			if err != nil {
+				return nil, err
+			}
+			if !slices.Contains(topicRules, topicRule) {
+				topicRules = append(topicRules, topicRule)
+			}
+		}
 		if len(rule) > 1 && rule[0] == '!' {
 			omitRules = append(omitRules, rule[1:])
 		} else {
@@ -46,9 +82,40 @@ func NewRepoAllowlistChecker(allowlist string) (*RepoAllowlistChecker, error) {
 	return &RepoAllowlistChecker{
 		includeRules: includeRules,
 		omitRules:    omitRules,
+		topicRules:   topicRules,
+		topicRepos:   make(map[string][]string),
+	}, nil
+}
+
+// parseAllowlistTopicRule parses a rule of the form
+// {hostname}/{owner}/topic:{topic}.
+func parseAllowlistTopicRule(rule string) (AllowlistTopicRule, error) {
+	parts := strings.Split(rule, "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], topicPrefix) || parts[0] == "" || parts[1] == "" ||
+		strings.Contains(parts[0]+parts[1], Wildcard) || len(parts[2]) == len(topicPrefix) {
+		return AllowlistTopicRule{}, fmt.Errorf("allowlist %q must be of the form {hostname}/{owner}/topic:{topic} without wildcards", rule)
+	}
+	return AllowlistTopicRule{
+		Hostname: strings.ToLower(parts[0]),
+		Owner:    strings.ToLower(parts[1]),
+		Topic:    strings.ToLower(strings.TrimPrefix(parts[2], topicPrefix)),
 	}, nil
 }
 
+// TopicRules returns the rules that match the repos with a topic, which must
+// be discovered with SetTopicRepos.
+func (r *RepoAllowlistChecker) TopicRules() []AllowlistTopicRule {
+	return r.topicRules
+}
+
+// SetTopicRepos sets the full names of the repos matched by rule, replacing
+// the ones previously discovered.
+func (r *RepoAllowlistChecker) SetTopicRepos(rule AllowlistTopicRule, repoFullNames []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.topicRepos[rule.String()] = repoFullNames
+}
+
 // IsAllowlisted returns true if this repo is in our allowlist and false
 // otherwise.
 func (r *RepoAllowlistChecker) IsAllowlisted(repoFullName string, vcsHostname string) bool {
@@ -72,6 +139,10 @@ func (r *RepoAllowlistChecker) matchesRule(rule string, candidate string) bool {
 	rule = strings.ToLower(rule)
 	candidate = strings.ToLower(candidate)
 
+	if strings.Contains(rule, topicPrefix) {
+		return r.matchesTopicRule(rule, candidate)
+	}
+
 	wildcardIdx := strings.Index(rule, Wildcard)
 	if wildcardIdx == -1 {
 		// No wildcard so can do a straight up match.
@@ -107,3 +178,18 @@ func (r *RepoAllowlistChecker) matchesRule(rule string, candidate string) bool {
 	// substr(rule): abc
 	return candidate[:wildcardIdx] == rule[:wildcardIdx]
 }
+
+// matchesTopicRule returns true if candidate is one of the repos last
+// discovered for the topic rule. Before the first discovery it matches
+// nothing.
+func (r *RepoAllowlistChecker) matchesTopicRule(rule string, candidate string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hostname, _, _ := strings.Cut(rule, "/")
+	for _, repoFullName := range r.topicRepos[rule] {
+		if candidate == hostname+"/"+strings.ToLower(repoFullName) {
+			return true
+		}
+	}
+	return false
+}
@@ -189,6 +189,27 @@ func TestRepoAllowlistChecker_IsAllowlisted(t *testing.T) {
 			"github.com",
 			true,
 		},
+		{
+			"should match bitbucket server personal repos by project key",
+			"bitbucket.corp/~JDOE/*",
+			"~jdoe/repo",
+			"bitbucket.corp",
+			true,
+		},
+		{
+			"should match all bitbucket server personal repos",
+			"bitbucket.corp/~*,!bitbucket.corp/~jdoe/*",
+			"~asmith/repo",
+			"bitbucket.corp",
+			true,
+		},
+		{
+			"should omit bitbucket server personal repos",
+			"bitbucket.corp/~*,!bitbucket.corp/~jdoe/*",
+			"~jdoe/repo",
+			"bitbucket.corp",
+			false,
+		},
 	}
 
 	for _, c := range cases {
@@ -223,3 +244,34 @@ func TestRepoAllowlistChecker_ContainsSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestRepoAllowlistChecker_TopicRules(t *testing.T) {
+	checker, err := events.NewRepoAllowlistChecker("github.com/org/topic:Terraform,!github.com/org/topic:legacy,github.com/org/always")
+	Ok(t, err)
+	terraform := events.AllowlistTopicRule{Hostname: "github.com", Owner: "org", Topic: "terraform"}
+	legacy := events.AllowlistTopicRule{Hostname: "github.com", Owner: "org", Topic: "legacy"}
+	Equals(t, []events.AllowlistTopicRule{terraform, legacy}, checker.TopicRules())
+
+	// Nothing is discovered yet.
+	Equals(t, false, checker.IsAllowlisted("org/infra", "github.com"))
+	Equals(t, true, checker.IsAllowlisted("org/always", "github.com"))
+
+	checker.SetTopicRepos(terraform, []string{"org/Infra", "org/old-infra"})
+	checker.SetTopicRepos(legacy, []string{"org/old-infra"})
+	Equals(t, true, checker.IsAllowlisted("org/infra", "github.com"))
+	Equals(t, false, checker.IsAllowlisted("org/old-infra", "github.com"))
+	Equals(t, false, checker.IsAllowlisted("org/infra", "github.example.com"))
+
+	// Repos whose topic was removed are no longer allowlisted.
+	checker.SetTopicRepos(terraform, nil)
+	Equals(t, false, checker.IsAllowlisted("org/infra", "github.com"))
+}
+
+func TestRepoAllowlistChecker_InvalidTopicRule(t *testing.T) {
+	for _, allowlist := range []string{"github.com/org/topic:", "github.com/*/topic:terraform", "github.com/topic:terraform", "github.com/org/repo-topic:x"} {
+		t.Run(allowlist, func(t *testing.T) {
+			_, err := events.NewRepoAllowlistChecker(allowlist)
+			ErrContains(t, "must be of the form {hostname}/{owner}/topic:{topic} without wildcards", err)
+		})
+	}
+}
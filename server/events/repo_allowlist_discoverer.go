@@ -0,0 +1,41 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// GithubRepoDiscoveryClient lists the GitHub repos of an owner with a topic.
+type GithubRepoDiscoveryClient interface {
+	ListReposWithTopic(logger logging.SimpleLogging, owner string, topic string) ([]string, error)
+}
+
+// RepoAllowlistDiscoverer discovers the repos matched by the topic rules of
+// the repo allowlist so repos are allowlisted by adding the topic to them,
+// without changing the server config. It's meant to be run periodically by
+// the scheduled executor.
+type RepoAllowlistDiscoverer struct {
+	Logger  logging.SimpleLogging
+	Client  GithubRepoDiscoveryClient
+	Checker *RepoAllowlistChecker
+	// Hostname is the GitHub hostname. Topic rules for other hosts are
+	// skipped.
+	Hostname string
+}
+
+// Run discovers the repos of each topic rule. If the repos of a rule can't be
+// listed, the ones discovered before are kept.
+func (d *RepoAllowlistDiscoverer) Run() {
+	for _, rule := range d.Checker.TopicRules() {
+		if rule.Hostname != d.Hostname {
+			d.Logger.Debug("skipping repo discovery for allowlist rule %q since it isn't a %s rule", rule, d.Hostname)
+			continue
+		}
+		repos, err := d.Client.ListReposWithTopic(d.Logger, rule.Owner, rule.Topic)
+		if err != nil {
+			d.Logger.Err("discovering repos for allowlist rule %q: %s", rule, err)
+			continue
+		}
+		d.Logger.Debug("discovered %d repos for allowlist rule %q", len(repos), rule)
+		d.Checker.SetTopicRepos(rule, repos)
+	}
+}
@@ -0,0 +1,47 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeRepoDiscoveryClient returns the repos of each owner and topic, or an
+// error if there are none.
+type fakeRepoDiscoveryClient struct {
+	repos map[string][]string
+}
+
+func (f *fakeRepoDiscoveryClient) ListReposWithTopic(_ logging.SimpleLogging, owner string, topic string) ([]string, error) {
+	repos, ok := f.repos[owner+"/"+topic]
+	if !ok {
+		return nil, errors.New("forbidden")
+	}
+	return repos, nil
+}
+
+func TestRepoAllowlistDiscoverer_Run(t *testing.T) {
+	checker, err := events.NewRepoAllowlistChecker("github.com/org/topic:terraform,github.com/other/topic:terraform,gitlab.com/org/topic:terraform")
+	Ok(t, err)
+	client := &fakeRepoDiscoveryClient{repos: map[string][]string{
+		"org/terraform": {"org/infra"},
+	}}
+	discoverer := &events.RepoAllowlistDiscoverer{
+		Logger:   logging.NewNoopLogger(t),
+		Client:   client,
+		Checker:  checker,
+		Hostname: "github.com",
+	}
+
+	discoverer.Run()
+	Equals(t, true, checker.IsAllowlisted("org/infra", "github.com"))
+	Equals(t, false, checker.IsAllowlisted("org/infra", "gitlab.com"))
+
+	// Repos discovered before are kept if they can't be listed.
+	client.repos = map[string][]string{}
+	discoverer.Run()
+	Equals(t, true, checker.IsAllowlisted("org/infra", "github.com"))
+}
@@ -0,0 +1,184 @@
+package events
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// modifiedRepoCfgFile returns the repo config file modified by the pull
+// request of ctx or an empty string if it doesn't modify one.
+func (p *DefaultProjectCommandBuilder) modifiedRepoCfgFile(ctx *command.Context, modifiedFiles []string) string {
+	for _, file := range p.globalCfg().RepoConfigFiles(ctx.Pull.BaseRepo.ID()) {
+		if slices.Contains(modifiedFiles, file) {
+			return file
+		}
+	}
+	return ""
+}
+
+// reviewRepoCfg compares the projects of repoCfg, the repo config file
+// modified by the pull request, to the ones of the repo config of the base
+// branch. It returns the review and the projects repoCfg adds or changes,
+// which are planned to dry-run the change.
+func (p *DefaultProjectCommandBuilder) reviewRepoCfg(ctx *command.Context, repoDir string, file string, repoCfg valid.RepoCfg) (*command.RepoCfgReview, []valid.MergedProjectCfg) {
+	review := &command.RepoCfgReview{File: file}
+	baseCfg, err := p.baseRepoCfg(ctx, repoDir, file)
+	if err != nil {
+		ctx.Log.Warn("unable to read the %s file of the base branch to compare it: %s", file, err)
+		return review, nil
+	}
+	review.Compared = true
+	var changed []valid.MergedProjectCfg
+	review.Changes, changed = repoCfgChanges(p.effectiveProjectCfgs(ctx, baseCfg), p.effectiveProjectCfgs(ctx, repoCfg))
+	return review, changed
+}
+
+// baseRepoCfg parses the repo config file of the base branch of the pull
+// request. It's empty if the base branch doesn't have one.
+func (p *DefaultProjectCommandBuilder) baseRepoCfg(ctx *command.Context, repoDir string, file string) (valid.RepoCfg, error) {
+	data, exists, err := readBaseBranchFile(repoDir, ctx.Pull.BaseBranch, file)
+	if err != nil || !exists {
+		return valid.RepoCfg{}, err
+	}
+	baseCfg, err := p.ParserValidator.ParseRepoCfgData(data, p.globalCfg(), ctx.Pull.BaseRepo.ID(), ctx.Pull.BaseBranch)
+	if err != nil {
+		return valid.RepoCfg{}, errors.Wrapf(err, "parsing %s", file)
+	}
+	baseCfg.UsePreviewWorkspaces(ctx.Pull.Num)
+	return baseCfg, nil
+}
+
+// effectiveProjectCfgs returns the projects of repoCfg merged with the
+// server-side repo config.
+func (p *DefaultProjectCommandBuilder) effectiveProjectCfgs(ctx *command.Context, repoCfg valid.RepoCfg) []valid.MergedProjectCfg {
+	var cfgs []valid.MergedProjectCfg
+	for _, proj := range repoCfg.Projects {
+		cfgs = append(cfgs, p.globalCfg().MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), proj, repoCfg))
+	}
+	return cfgs
+}
+
+// readBaseBranchFile returns the contents of file on baseBranch in the clone
+// at repoDir and false if baseBranch doesn't have it. The base branch is
+// fetched if the clone doesn't have it, ex. with the branch checkout
+// strategy.
+func readBaseBranchFile(repoDir string, baseBranch string, file string) ([]byte, bool, error) {
+	rev := "refs/remotes/origin/" + baseBranch
+	if _, err := runGit(repoDir, "rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+		// Fetch errors aren't returned since they can contain the
+		// credentials in the remote's URL.
+		if _, err := runGit(repoDir, "fetch", "--depth=1", "origin", baseBranch); err != nil {
+			return nil, false, fmt.Errorf("base branch %s can't be fetched", baseBranch)
+		}
+		rev = "FETCH_HEAD"
+	}
+	if _, err := runGit(repoDir, "cat-file", "-e", rev+":"+file); err != nil {
+		return nil, false, nil
+	}
+	contents, err := runGit(repoDir, "show", rev+":"+file)
+	if err != nil {
+		return nil, false, err
+	}
+	return []byte(contents), true, nil
+}
+
+// repoCfgChanges describes how the projects change from base to modified. It
+// returns the descriptions and the projects of modified that are added or
+// changed.
+func repoCfgChanges(base []valid.MergedProjectCfg, modified []valid.MergedProjectCfg) ([]string, []valid.MergedProjectCfg) {
+	key := func(cfg valid.MergedProjectCfg) string {
+		if cfg.Name != "" {
+			return "name:" + cfg.Name
+		}
+		return cfg.RepoRelDir + ":" + cfg.Workspace
+	}
+	baseByKey := make(map[string]valid.MergedProjectCfg)
+	for _, cfg := range base {
+		baseByKey[key(cfg)] = cfg
+	}
+
+	var changes []string
+	var changed []valid.MergedProjectCfg
+	seen := make(map[string]bool)
+	for _, cfg := range modified {
+		seen[key(cfg)] = true
+		baseCfg, ok := baseByKey[key(cfg)]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s added", describeProjectCfg(cfg)))
+			changed = append(changed, cfg)
+			continue
+		}
+		if reflect.DeepEqual(baseCfg, cfg) {
+			continue
+		}
+		changed = append(changed, cfg)
+		switch {
+		case baseCfg.Workflow.Name != cfg.Workflow.Name:
+			changes = append(changes, fmt.Sprintf("%s now uses workflow `%s` instead of `%s`", describeProjectCfg(cfg), cfg.Workflow.Name, baseCfg.Workflow.Name))
+		case !reflect.DeepEqual(baseCfg.Workflow, cfg.Workflow):
+			changes = append(changes, fmt.Sprintf("workflow `%s` of %s changed", cfg.Workflow.Name, describeProjectCfg(cfg)))
+		}
+		if fields := changedProjectCfgFields(baseCfg, cfg); len(fields) > 0 {
+			changes = append(changes, fmt.Sprintf("%s changed: `%s`", describeProjectCfg(cfg), strings.Join(fields, "`, `")))
+		}
+	}
+	for _, cfg := range base {
+		if !seen[key(cfg)] {
+			changes = append(changes, fmt.Sprintf("%s removed", describeProjectCfg(cfg)))
+		}
+	}
+	return changes, changed
+}
+
+// changedProjectCfgFields returns the settings other than the workflow that
+// differ between a and b, named like in the repo config, ex.
+// apply_requirements.
+func changedProjectCfgFields(a valid.MergedProjectCfg, b valid.MergedProjectCfg) []string {
+	var fields []string
+	aVal, bVal := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < aVal.NumField(); i++ {
+		name := aVal.Type().Field(i).Name
+		if name == "Workflow" || name == "Name" {
+			continue
+		}
+		if reflect.DeepEqual(aVal.Field(i).Interface(), bVal.Field(i).Interface()) {
+			continue
+		}
+		if name == "RepoRelDir" {
+			fields = append(fields, "dir")
+		} else {
+			fields = append(fields, snakeCase(name))
+		}
+	}
+	return fields
+}
+
+// describeProjectCfg names cfg in review comments.
+func describeProjectCfg(cfg valid.MergedProjectCfg) string {
+	if cfg.Name != "" {
+		return fmt.Sprintf("project `%s`", cfg.Name)
+	}
+	return fmt.Sprintf("project at dir `%s` workspace `%s`", cfg.RepoRelDir, cfg.Workspace)
+}
+
+// snakeCase converts a field name to snake case, ex. PRMetadataVars to
+// pr_metadata_vars.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
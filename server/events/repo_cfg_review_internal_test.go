@@ -0,0 +1,85 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRepoCfgChanges(t *testing.T) {
+	defaultWorkflow := valid.Workflow{Name: "default"}
+	base := []valid.MergedProjectCfg{
+		{Name: "network", RepoRelDir: "network", Workspace: "default", Workflow: defaultWorkflow},
+		{RepoRelDir: "app", Workspace: "default", Workflow: valid.Workflow{Name: "custom"}},
+		{RepoRelDir: "db", Workspace: "default", Workflow: defaultWorkflow, ApplyRequirements: []string{"approved"}},
+		{RepoRelDir: "old", Workspace: "default", Workflow: defaultWorkflow},
+		{RepoRelDir: "unchanged", Workspace: "default", Workflow: defaultWorkflow},
+	}
+	modified := []valid.MergedProjectCfg{
+		{Name: "network", RepoRelDir: "network", Workspace: "default", Workflow: valid.Workflow{Name: "custom"}},
+		{RepoRelDir: "app", Workspace: "default", Workflow: valid.Workflow{Name: "custom", Plan: valid.DefaultPlanStage}},
+		{RepoRelDir: "db", Workspace: "default", Workflow: defaultWorkflow, ApplyRequirements: []string{"approved", "mergeable"}, PRMetadataVars: "env"},
+		{RepoRelDir: "unchanged", Workspace: "default", Workflow: defaultWorkflow},
+		{Name: "cache", RepoRelDir: "cache", Workspace: "default", Workflow: defaultWorkflow},
+	}
+
+	changes, changed := repoCfgChanges(base, modified)
+	Equals(t, []string{
+		"project `network` now uses workflow `custom` instead of `default`",
+		"workflow `custom` of project at dir `app` workspace `default` changed",
+		"project at dir `db` workspace `default` changed: `apply_requirements`, `pr_metadata_vars`",
+		"project `cache` added",
+		"project at dir `old` workspace `default` removed",
+	}, changes)
+	var changedDirs []string
+	for _, cfg := range changed {
+		changedDirs = append(changedDirs, cfg.RepoRelDir)
+	}
+	Equals(t, []string{"network", "app", "db", "cache"}, changedDirs)
+
+	changes, changed = repoCfgChanges(base, base)
+	Equals(t, 0, len(changes))
+	Equals(t, 0, len(changed))
+}
+
+func TestReadBaseBranchFile(t *testing.T) {
+	originDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--initial-branch=main"},
+		{"config", "--local", "user.email", "atlantisbot@runatlantis.io"},
+		{"config", "--local", "user.name", "atlantisbot"},
+		{"config", "--local", "commit.gpgsign", "false"},
+	} {
+		_, err := runGit(originDir, args...)
+		Ok(t, err)
+	}
+	Ok(t, os.WriteFile(filepath.Join(originDir, "atlantis.yaml"), []byte("version: 3\n"), 0600))
+	_, err := runGit(originDir, "add", "atlantis.yaml")
+	Ok(t, err)
+	_, err = runGit(originDir, "commit", "-m", "add atlantis.yaml")
+	Ok(t, err)
+	_, err = runGit(originDir, "branch", "other")
+	Ok(t, err)
+
+	// The clone only has the branch of the pull request so the base branch
+	// is fetched.
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	_, err = runGit(originDir, "clone", "--single-branch", "--branch", "other", originDir, repoDir)
+	Ok(t, err)
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "atlantis.yaml"), []byte("version: 3\nprojects: []\n"), 0600))
+
+	contents, exists, err := readBaseBranchFile(repoDir, "main", "atlantis.yaml")
+	Ok(t, err)
+	Assert(t, exists, "expected atlantis.yaml to exist on main")
+	Equals(t, "version: 3\n", string(contents))
+
+	_, exists, err = readBaseBranchFile(repoDir, "main", "atlantis.yml")
+	Ok(t, err)
+	Assert(t, !exists, "expected atlantis.yml not to exist on main")
+
+	_, _, err = readBaseBranchFile(repoDir, "missing", "atlantis.yaml")
+	ErrEquals(t, "base branch missing can't be fetched", err)
+}
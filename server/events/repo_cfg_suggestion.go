@@ -0,0 +1,152 @@
+package events
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// RepoCfgSuggestionStore records the pull requests an atlantis.yaml was
+// suggested on so it's only suggested once per pull request.
+type RepoCfgSuggestionStore interface {
+	// MarkSuggested records that an atlantis.yaml was suggested on the pull
+	// request pullNum of the repo repoFullName. It returns false if it
+	// already was.
+	MarkSuggested(repoFullName string, pullNum int) (bool, error)
+	// DeleteForPull deletes the record of the pull request once it's closed.
+	DeleteForPull(repoFullName string, pullNum int) error
+}
+
+// FileRepoCfgSuggestionStore records suggestions as empty files in Dir.
+type FileRepoCfgSuggestionStore struct {
+	Dir string
+}
+
+func (f *FileRepoCfgSuggestionStore) MarkSuggested(repoFullName string, pullNum int) (bool, error) {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(pullDir, 0700); err != nil {
+		return false, errors.Wrap(err, "creating repo config suggestions dir")
+	}
+	// Creating the file fails if it exists so concurrent autoplans of the
+	// same pull request can't both suggest.
+	file, err := os.OpenFile(filepath.Join(pullDir, "suggested"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, file.Close()
+}
+
+func (f *FileRepoCfgSuggestionStore) DeleteForPull(repoFullName string, pullNum int) error {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(pullDir)
+}
+
+// suggestRepoCfg comments an atlantis.yaml capturing the projects discovered
+// in the repo on the pull request, if it wasn't suggested on it before. It's
+// called when a pull request of a repo without a repo config is autoplanned.
+func (p *DefaultProjectCommandBuilder) suggestRepoCfg(ctx *command.Context, repoDir string, repoCfg valid.RepoCfg) error {
+	repoCfgFile, err := p.suggestedRepoCfg(ctx, repoDir, repoCfg)
+	if err != nil || len(repoCfgFile.Projects) == 0 {
+		return err
+	}
+	suggest, err := p.RepoCfgSuggestionStore.MarkSuggested(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	if err != nil || !suggest {
+		return err
+	}
+	comment, err := repoCfgSuggestionComment(repoCfgFile)
+	if err != nil {
+		return err
+	}
+	return p.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment, command.Plan.String())
+}
+
+// suggestedRepoCfg returns a repo config with a project for each project
+// autodiscover finds in the whole repo, not only the projects modified in
+// the pull request.
+func (p *DefaultProjectCommandBuilder) suggestedRepoCfg(ctx *command.Context, repoDir string, repoCfg valid.RepoCfg) (raw.RepoCfg, error) {
+	var files []string
+	err := filepath.WalkDir(repoDir, func(absPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(repoDir, absPath)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+	if err != nil {
+		return raw.RepoCfg{}, errors.Wrap(err, "listing repo files")
+	}
+
+	projects := p.ProjectFinder.DetermineProjects(ctx.Log, files, ctx.Pull.BaseRepo.FullName, repoDir, p.AutoplanFileList, nil, p.sharedModuleDirs(ctx, repoCfg))
+	slices.SortFunc(projects, func(a, b models.Project) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	version := 3
+	suggested := raw.RepoCfg{Version: &version}
+	for _, project := range projects {
+		dir := filepath.Clean(project.Path)
+		absDir := filepath.Join(repoDir, dir)
+		deployments, isStack, err := findStackDeployments(absDir)
+		if err != nil {
+			return raw.RepoCfg{}, errors.Wrapf(err, "looking for Terraform stack deployments in '%s'", absDir)
+		}
+		if isStack {
+			tool := valid.ToolStacks
+			for _, deployment := range deployments {
+				suggested.Projects = append(suggested.Projects, raw.Project{Dir: &dir, Workspace: &deployment, Tool: &tool})
+			}
+			continue
+		}
+		workspace, err := p.ProjectFinder.DetermineWorkspaceFromHCL(ctx.Log, absDir)
+		if err != nil {
+			return raw.RepoCfg{}, errors.Wrapf(err, "Looking for Terraform Cloud workspace from configuration in '%s'", absDir)
+		}
+		proj := raw.Project{Dir: &dir}
+		if workspace != DefaultWorkspace {
+			proj.Workspace = &workspace
+		}
+		suggested.Projects = append(suggested.Projects, proj)
+	}
+	return suggested, nil
+}
+
+// repoCfgSuggestionComment is the comment suggesting repoCfg.
+func repoCfgSuggestionComment(repoCfg raw.RepoCfg) (string, error) {
+	contents, err := yaml.Marshal(repoCfg)
+	if err != nil {
+		return "", errors.Wrap(err, "rendering suggested repo config")
+	}
+	return fmt.Sprintf("This repo doesn't have an `atlantis.yaml` file so Atlantis discovered its projects automatically."+
+		" Here's an `atlantis.yaml` with the %d project(s) it found, as a starting point to review and commit to the root of the repo:\n\n"+
+		"```yaml\n%s```\n\n"+
+		"See https://www.runatlantis.io/docs/repo-level-atlantis-yaml.html for the other settings of projects. This is only commented once per pull request.",
+		len(repoCfg.Projects), contents), nil
+}
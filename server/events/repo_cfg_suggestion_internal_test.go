@@ -0,0 +1,86 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFileRepoCfgSuggestionStore(t *testing.T) {
+	store := &FileRepoCfgSuggestionStore{Dir: t.TempDir()}
+
+	suggest, err := store.MarkSuggested("owner/repo", 1)
+	Ok(t, err)
+	Assert(t, suggest, "expected the first suggestion to be marked")
+	suggest, err = store.MarkSuggested("owner/repo", 1)
+	Ok(t, err)
+	Assert(t, !suggest, "expected the second suggestion to be skipped")
+	suggest, err = store.MarkSuggested("owner/repo", 2)
+	Ok(t, err)
+	Assert(t, suggest, "expected a suggestion on another pull request to be marked")
+
+	Ok(t, store.DeleteForPull("owner/repo", 1))
+	suggest, err = store.MarkSuggested("owner/repo", 1)
+	Ok(t, err)
+	Assert(t, suggest, "expected a suggestion to be marked once the pull request was deleted")
+
+	_, err = store.MarkSuggested("../repo", 1)
+	Assert(t, err != nil, "expected an error for a repo escaping the dir")
+}
+
+func TestDefaultProjectCommandBuilder_SuggestedRepoCfg(t *testing.T) {
+	repoDir := t.TempDir()
+	for path, contents := range map[string]string{
+		"prod/main.tf":                   "",
+		"staging/main.tf":                "",
+		"modules/vpc/variables.tf":       "",
+		"stack/components.tfstack.hcl":   "",
+		"stack/deployments.tfdeploy.hcl": `deployment "dev" {}` + "\n" + `deployment "prod" {}`,
+		".git/main.tf":                   "",
+		"README.md":                      "",
+	} {
+		Ok(t, os.MkdirAll(filepath.Dir(filepath.Join(repoDir, path)), 0700))
+		Ok(t, os.WriteFile(filepath.Join(repoDir, path), []byte(contents), 0600))
+	}
+	builder := &DefaultProjectCommandBuilder{
+		ProjectFinder:    &DefaultProjectFinder{},
+		AutoplanFileList: "**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl,**/.terraform.lock.hcl,**/*.tfstack.hcl,**/*.tfdeploy.hcl",
+		GlobalCfgStore:   valid.NewGlobalCfgStore(valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})),
+	}
+	ctx := &command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+	}
+
+	suggested, err := builder.suggestedRepoCfg(ctx, repoDir, valid.RepoCfg{})
+	Ok(t, err)
+	version := 3
+	prod, stack, staging := "prod", "stack", "staging"
+	dev, stacks := "dev", valid.ToolStacks
+	Equals(t, raw.RepoCfg{
+		Version: &version,
+		Projects: []raw.Project{
+			{Dir: &prod},
+			{Dir: &stack, Workspace: &dev, Tool: &stacks},
+			{Dir: &stack, Workspace: &prod, Tool: &stacks},
+			{Dir: &staging},
+		},
+	}, suggested)
+}
+
+func TestRepoCfgSuggestionComment(t *testing.T) {
+	version := 3
+	dir := "prod"
+	comment, err := repoCfgSuggestionComment(raw.RepoCfg{Version: &version, Projects: []raw.Project{{Dir: &dir}}})
+	Ok(t, err)
+	Assert(t, strings.Contains(comment, "with the 1 project(s) it found"), "unexpected comment: %s", comment)
+	Assert(t, strings.Contains(comment, "```yaml\nversion: 3\nprojects:\n    - dir: prod\n```"), "unexpected comment: %s", comment)
+}
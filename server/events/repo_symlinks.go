@@ -0,0 +1,82 @@
+package events
+
+import (
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// repoSymlinks maps the repo-relative path of each symlinked directory in a
+// repo to the repo-relative path of the directory it resolves to.
+type repoSymlinks map[string]string
+
+// findRepoSymlinks returns the symlinked directories in absRepoDir that
+// resolve to a directory inside the repo. Links that can't be resolved or
+// that point outside of the repo are ignored.
+func findRepoSymlinks(absRepoDir string) (repoSymlinks, error) {
+	links := make(repoSymlinks)
+	if absRepoDir == "" {
+		return links, nil
+	}
+	realRepoDir, err := filepath.EvalSymlinks(absRepoDir)
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.WalkDir(absRepoDir, func(absPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		target, err := filepath.EvalSymlinks(absPath)
+		if err != nil {
+			return nil
+		}
+		if info, err := os.Stat(target); err != nil || !info.IsDir() {
+			return nil
+		}
+		realRel, err := filepath.Rel(realRepoDir, target)
+		if err != nil || realRel == "." || realRel == ".." || strings.HasPrefix(realRel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+		rel, err := filepath.Rel(absRepoDir, absPath)
+		if err != nil {
+			return nil
+		}
+		links[filepath.ToSlash(rel)] = filepath.ToSlash(realRel)
+		return nil
+	})
+	return links, err
+}
+
+// paths returns the repo-relative paths of the symlinks in a stable order.
+func (r repoSymlinks) paths() []string {
+	return slices.Sorted(maps.Keys(r))
+}
+
+// withAliases returns files along with, for every file inside the target of
+// a symlinked directory, the same file addressed through the symlink. This
+// lets changes to a directory's real path match projects and modules that
+// are referenced through a symlink.
+func (r repoSymlinks) withAliases(files []string) []string {
+	if len(r) == 0 {
+		return files
+	}
+	aliased := slices.Clone(files)
+	for _, file := range files {
+		for _, link := range r.paths() {
+			if rest, ok := strings.CutPrefix(file, r[link]+"/"); ok {
+				aliased = append(aliased, path.Join(link, rest))
+			}
+		}
+	}
+	return aliased
+}
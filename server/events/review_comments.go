@@ -0,0 +1,167 @@
+package events
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// reviewCommentLocation is a line of a file, relative to the repo root, that
+// review comments are attached to.
+type reviewCommentLocation struct {
+	Path string
+	Line int
+}
+
+// riskyChanges returns the changes of the plan in planJSON, the output of
+// terraform show -json on the planfile, that delete or replace resources.
+func riskyChanges(planJSON string) ([]models.PlannedResourceChange, error) {
+	changes, err := plannedResourceChanges(planJSON)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(changes, func(c models.PlannedResourceChange) bool {
+		return c.Action != valid.RiskActionDelete && c.Action != valid.RiskActionReplace
+	}), nil
+}
+
+// declarationPos returns where the resource at address is declared in
+// module, the root module of the project. Resources of child modules are
+// attributed to the module block that calls them since their files usually
+// aren't part of the pull request. ok is false if the declaration isn't
+// found, ex. because the resource was removed from the config.
+func declarationPos(module *tfconfig.Module, address string) (pos tfconfig.SourcePos, ok bool) {
+	if rest, isModule := strings.CutPrefix(address, "module."); isModule {
+		name, _, _ := strings.Cut(rest, ".")
+		name, _, _ = strings.Cut(name, "[")
+		call, ok := module.ModuleCalls[name]
+		if !ok {
+			return tfconfig.SourcePos{}, false
+		}
+		return call.Pos, true
+	}
+	key, _, _ := strings.Cut(address, "[")
+	resource, ok := module.ManagedResources[key]
+	if !ok {
+		return tfconfig.SourcePos{}, false
+	}
+	return resource.Pos, true
+}
+
+// renderReviewComment returns the review comment on the declaration of
+// changes.
+func renderReviewComment(ctx command.ProjectContext, changes []models.PlannedResourceChange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Atlantis:** the plan of %s ", projectDescription(ctx))
+	if len(changes) == 1 {
+		fmt.Fprintf(&b, "%s `%s`.", riskyActionVerb(changes[0].Action), changes[0].Address)
+	} else {
+		b.WriteString("changes these resources:\n")
+		for _, change := range changes {
+			fmt.Fprintf(&b, "\n* %s `%s`", riskyActionVerb(change.Action), change.Address)
+		}
+	}
+	return b.String()
+}
+
+// riskyActionVerb returns action as shown in review comments.
+func riskyActionVerb(action string) string {
+	if action == valid.RiskActionReplace {
+		return "**replaces**"
+	}
+	return "**destroys**"
+}
+
+// projectDescription returns the project of ctx as shown in review comments.
+func projectDescription(ctx command.ProjectContext) string {
+	if ctx.ProjectName != "" {
+		return fmt.Sprintf("project `%s`", ctx.ProjectName)
+	}
+	return fmt.Sprintf("dir `%s` workspace `%s`", ctx.RepoRelDir, ctx.Workspace)
+}
+
+// commentRiskyChanges comments on the lines of the pull request's diff that
+// declare the resources the project's plan deletes or replaces, if the repo
+// has inline review comments. If the project was planned before, only the
+// changes the previous plan didn't make are commented so replans don't
+// repeat the comments. Only declarations in files modified by the pull
+// request can be commented on. The plan succeeded so errors are logged
+// instead of failing it.
+func (p *DefaultProjectCommandRunner) commentRiskyChanges(ctx command.ProjectContext, repoDir string, absPath string, delta *models.PlanDelta) {
+	if !ctx.InlineReviewComments || !valid.UsesTerraform(ctx.Tool) {
+		return
+	}
+	commenter, ok := p.ReviewCommenters[ctx.Pull.BaseRepo.VCSHost.Type]
+	if !ok {
+		ctx.Log.Debug("not commenting on the diff since %s doesn't support review comments", ctx.Pull.BaseRepo.VCSHost.Type.String())
+		return
+	}
+
+	out, err := p.showPlan(ctx, absPath)
+	if err != nil {
+		ctx.Log.Err("showing plan for review comments: %s", err)
+		return
+	}
+	if out == "" {
+		return
+	}
+	changes, err := riskyChanges(out)
+	if err != nil {
+		ctx.Log.Err("finding changes for review comments: %s", err)
+		return
+	}
+	if delta != nil {
+		changes = slices.DeleteFunc(changes, func(c models.PlannedResourceChange) bool {
+			return !slices.Contains(delta.Added, c)
+		})
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	modifiedFiles, err := p.VcsClient.GetModifiedFiles(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull)
+	if err != nil {
+		ctx.Log.Err("getting modified files for review comments: %s", err)
+		return
+	}
+	module, diags := tfconfig.LoadModule(absPath)
+	if diags.HasErrors() {
+		ctx.Log.Err("loading config for review comments: %s", diags.Err())
+		return
+	}
+
+	var locations []reviewCommentLocation
+	byLocation := make(map[reviewCommentLocation][]models.PlannedResourceChange)
+	for _, change := range changes {
+		pos, ok := declarationPos(module, change.Address)
+		if !ok {
+			continue
+		}
+		path, err := filepath.Rel(repoDir, pos.Filename)
+		if err != nil {
+			continue
+		}
+		location := reviewCommentLocation{Path: filepath.ToSlash(path), Line: pos.Line}
+		if !slices.Contains(modifiedFiles, location.Path) {
+			continue
+		}
+		if _, ok := byLocation[location]; !ok {
+			locations = append(locations, location)
+		}
+		byLocation[location] = append(byLocation[location], change)
+	}
+
+	for _, location := range locations {
+		comment := renderReviewComment(ctx, byLocation[location])
+		if err := commenter.CreateReviewComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, location.Path, location.Line, comment); err != nil {
+			// The line may not be part of the diff.
+			ctx.Log.Warn("unable to comment on %s:%d: %s", location.Path, location.Line, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRiskyChanges(t *testing.T) {
+	changes, err := riskyChanges(`{
+	"resource_changes": [
+		{"address": "aws_iam_role.a", "mode": "managed", "change": {"actions": ["update"]}},
+		{"address": "aws_db_instance.b", "mode": "managed", "change": {"actions": ["delete", "create"]}},
+		{"address": "aws_s3_bucket.a", "mode": "managed", "change": {"actions": ["delete"]}},
+		{"address": "data.aws_iam_policy_document.a", "mode": "data", "change": {"actions": ["delete"]}}
+	]
+}`)
+	Ok(t, err)
+	Equals(t, []models.PlannedResourceChange{
+		{Address: "aws_db_instance.b", Action: valid.RiskActionReplace},
+		{Address: "aws_s3_bucket.a", Action: valid.RiskActionDelete},
+	}, changes)
+}
+
+func TestDeclarationPos(t *testing.T) {
+	dir := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "aws_s3_bucket" "a" {
+  count = 2
+}
+
+module "network" {
+  source = "./network"
+}
+`), 0600))
+	module, diags := tfconfig.LoadModule(dir)
+	Assert(t, !diags.HasErrors(), "unexpected diagnostics: %s", diags.Err())
+
+	cases := []struct {
+		address string
+		expLine int
+		expOk   bool
+	}{
+		{"aws_s3_bucket.a[1]", 1, true},
+		{"module.network.aws_subnet.a", 5, true},
+		{"module.network[\"a\"].aws_subnet.a", 5, true},
+		{"aws_s3_bucket.removed", 0, false},
+		{"module.removed.aws_subnet.a", 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.address, func(t *testing.T) {
+			pos, ok := declarationPos(module, c.address)
+			Equals(t, c.expOk, ok)
+			if ok {
+				Equals(t, filepath.Join(dir, "main.tf"), pos.Filename)
+				Equals(t, c.expLine, pos.Line)
+			}
+		})
+	}
+}
+
+func TestRenderReviewComment(t *testing.T) {
+	ctx := command.ProjectContext{RepoRelDir: "dir", Workspace: "default"}
+	Equals(t, "**Atlantis:** the plan of dir `dir` workspace `default` **replaces** `aws_db_instance.a`.",
+		renderReviewComment(ctx, []models.PlannedResourceChange{{Address: "aws_db_instance.a", Action: valid.RiskActionReplace}}))
+
+	ctx.ProjectName = "db"
+	Equals(t, "**Atlantis:** the plan of project `db` changes these resources:\n\n* **destroys** `aws_s3_bucket.a[0]`\n* **destroys** `aws_s3_bucket.a[1]`",
+		renderReviewComment(ctx, []models.PlannedResourceChange{
+			{Address: "aws_s3_bucket.a[0]", Action: valid.RiskActionDelete},
+			{Address: "aws_s3_bucket.a[1]", Action: valid.RiskActionDelete},
+		}))
+}
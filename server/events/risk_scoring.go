@@ -0,0 +1,137 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// riskResourceChange is a resource change in the terraform show -json output.
+type riskResourceChange struct {
+	Mode         string `json:"mode"`
+	Type         string `json:"type"`
+	ProviderName string `json:"provider_name"`
+	Change       struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+// riskAction returns the risk action of a change's terraform actions or ""
+// if the resource doesn't change.
+func riskAction(actions []string) string {
+	switch {
+	case slices.Contains(actions, "delete") && slices.Contains(actions, "create"):
+		return valid.RiskActionReplace
+	case slices.Contains(actions, "delete"):
+		return valid.RiskActionDelete
+	case slices.Contains(actions, "create"):
+		return valid.RiskActionCreate
+	case slices.Contains(actions, "update"):
+		return valid.RiskActionUpdate
+	}
+	return ""
+}
+
+// scorePlan returns the risk score of a plan. Each resource change adds the
+// score of every rule it matches, and each provider whose resources change
+// adds the provider score. planJSON is the output of terraform show -json on
+// the planfile.
+func scorePlan(scoring valid.RiskScoring, planJSON string) (int, error) {
+	var plan struct {
+		ResourceChanges []riskResourceChange `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return 0, fmt.Errorf("parsing plan json: %w", err)
+	}
+	score := 0
+	providers := make(map[string]bool)
+	for _, rc := range plan.ResourceChanges {
+		action := riskAction(rc.Change.Actions)
+		if rc.Mode != "managed" || action == "" {
+			continue
+		}
+		providers[rc.ProviderName] = true
+		for _, rule := range scoring.Rules {
+			if rule.Matches(rc.Type, action) {
+				score += rule.Score
+			}
+		}
+	}
+	return score + len(providers)*scoring.ProviderScore, nil
+}
+
+// planRisk returns the risk of the project's plan or nil if the repo doesn't
+// score plans. Remote operations and projects of other tools than terraform
+// don't have a planfile to show so they can't be scored.
+func (p *DefaultProjectCommandRunner) planRisk(ctx command.ProjectContext, absPath string) (*models.PlanRisk, error) {
+	if ctx.RiskScoring == nil || !valid.UsesTerraform(ctx.Tool) {
+		return nil, nil
+	}
+	out, err := p.showPlan(ctx, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("showing plan to score its risk: %w", err)
+	}
+	if out == "" {
+		ctx.Log.Warn("unable to score the risk of the plan because it can't be shown")
+		return nil, nil
+	}
+	score, err := scorePlan(*ctx.RiskScoring, out)
+	if err != nil {
+		return nil, err
+	}
+	risk := &models.PlanRisk{Score: score}
+	if threshold := ctx.RiskScoring.Threshold(score); threshold != nil {
+		risk.Level = threshold.Name
+		risk.Approvals = threshold.Approvals
+		for _, user := range threshold.Reviewers.Users {
+			risk.Reviewers = append(risk.Reviewers, "@"+user)
+		}
+		risk.Reviewers = append(risk.Reviewers, threshold.Reviewers.Teams...)
+	}
+	return risk, nil
+}
+
+// checkRiskApprovals returns a failure if the project's plan reaches a risk
+// threshold and the pull request doesn't have the approvals it requires.
+func (p *DefaultProjectCommandRunner) checkRiskApprovals(ctx command.ProjectContext, absPath string) (string, error) {
+	risk, err := p.planRisk(ctx, absPath)
+	if err != nil || risk == nil {
+		return "", err
+	}
+	threshold := ctx.RiskScoring.Threshold(risk.Score)
+	if threshold == nil {
+		return "", nil
+	}
+	scored := fmt.Sprintf("This plan has a risk score of %d", risk.Score)
+	if risk.Level != "" {
+		scored += fmt.Sprintf(" (%s)", risk.Level)
+	}
+
+	approval := ctx.PullReqStatus.ApprovalStatus
+	if count := approval.ApprovalCount(); count < threshold.Approvals {
+		approvals := fmt.Sprintf("%d approvals", threshold.Approvals)
+		if threshold.Approvals == 1 {
+			approvals = "an approval"
+		}
+		return fmt.Sprintf("%s and requires %s to apply, the pull request has %d.", scored, approvals, count), nil
+	}
+	if len(risk.Reviewers) == 0 {
+		return "", nil
+	}
+	approvers := approval.Approvers
+	if len(approvers) == 0 && approval.IsApproved && approval.ApprovedBy != "" {
+		approvers = []string{approval.ApprovedBy}
+	}
+	for _, approver := range approvers {
+		isReviewer, err := p.isProjectOwner(ctx, threshold.Reviewers, models.User{Username: approver})
+		if err != nil || isReviewer {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s and requires an approval from one of %s to apply.", scored, strings.Join(risk.Reviewers, ", ")), nil
+}
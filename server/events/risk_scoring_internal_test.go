@@ -0,0 +1,113 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const riskPlanJSON = `{
+	"resource_changes": [
+		{"mode": "managed", "type": "aws_iam_role", "provider_name": "registry.terraform.io/hashicorp/aws", "change": {"actions": ["update"]}},
+		{"mode": "managed", "type": "aws_db_instance", "provider_name": "registry.terraform.io/hashicorp/aws", "change": {"actions": ["delete", "create"]}},
+		{"mode": "managed", "type": "aws_s3_bucket", "provider_name": "registry.terraform.io/hashicorp/aws", "change": {"actions": ["delete"]}},
+		{"mode": "managed", "type": "random_id", "provider_name": "registry.terraform.io/hashicorp/random", "change": {"actions": ["no-op"]}},
+		{"mode": "data", "type": "aws_iam_policy_document", "provider_name": "registry.terraform.io/hashicorp/aws", "change": {"actions": ["read"]}}
+	]
+}`
+
+var testRiskScoring = valid.RiskScoring{
+	Rules: []valid.RiskRule{
+		{Actions: []string{valid.RiskActionDelete}, Score: 10},
+		{Actions: []string{valid.RiskActionReplace}, Score: 5},
+		{ResourceTypes: []string{"aws_iam_*", "aws_security_group*"}, Score: 3},
+	},
+	ProviderScore: 1,
+	Thresholds: []valid.RiskThreshold{
+		{Score: 10, Name: "medium", Approvals: 1},
+		{Score: 15, Name: "high", Approvals: 2, Reviewers: valid.PolicyOwners{Users: []string{"alice"}}},
+	},
+}
+
+func TestScorePlan(t *testing.T) {
+	score, err := scorePlan(testRiskScoring, riskPlanJSON)
+	Ok(t, err)
+	// Delete 10, replace 5, IAM update 3, one changed provider 1.
+	Equals(t, 19, score)
+
+	score, err = scorePlan(testRiskScoring, `{"resource_changes": []}`)
+	Ok(t, err)
+	Equals(t, 0, score)
+
+	_, err = scorePlan(testRiskScoring, "not json")
+	ErrContains(t, "parsing plan json", err)
+}
+
+// staticShowRunner returns the same plan json for any project.
+type staticShowRunner struct {
+	out string
+}
+
+func (r staticShowRunner) Run(_ command.ProjectContext, _ []string, _ string, _ map[string]string) (string, error) {
+	return r.out, nil
+}
+
+func TestCheckRiskApprovals(t *testing.T) {
+	cases := []struct {
+		description string
+		scoring     *valid.RiskScoring
+		approval    models.ApprovalStatus
+		expRisk     *models.PlanRisk
+		expFailure  string
+	}{
+		{
+			description: "no risk scoring",
+		},
+		{
+			description: "not enough approvals",
+			scoring:     &testRiskScoring,
+			approval:    models.ApprovalStatus{IsApproved: true, ApprovedBy: "bob"},
+			expRisk:     &models.PlanRisk{Score: 19, Level: "high", Approvals: 2, Reviewers: []string{"@alice"}},
+			expFailure:  "This plan has a risk score of 19 (high) and requires 2 approvals to apply, the pull request has 1.",
+		},
+		{
+			description: "no approval from reviewers",
+			scoring:     &testRiskScoring,
+			approval:    models.ApprovalStatus{IsApproved: true, ApprovedBy: "bob", Approvers: []string{"bob", "carol"}},
+			expRisk:     &models.PlanRisk{Score: 19, Level: "high", Approvals: 2, Reviewers: []string{"@alice"}},
+			expFailure:  "This plan has a risk score of 19 (high) and requires an approval from one of @alice to apply.",
+		},
+		{
+			description: "approved by reviewer",
+			scoring:     &testRiskScoring,
+			approval:    models.ApprovalStatus{IsApproved: true, ApprovedBy: "bob", Approvers: []string{"bob", "alice"}},
+			expRisk:     &models.PlanRisk{Score: 19, Level: "high", Approvals: 2, Reviewers: []string{"@alice"}},
+		},
+		{
+			description: "below thresholds",
+			scoring:     &valid.RiskScoring{Rules: testRiskScoring.Rules, ProviderScore: 1, Thresholds: []valid.RiskThreshold{{Score: 50, Approvals: 3}}},
+			expRisk:     &models.PlanRisk{Score: 19},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			runner := &DefaultProjectCommandRunner{ShowStepRunner: staticShowRunner{out: riskPlanJSON}}
+			ctx := command.ProjectContext{
+				Log:           logging.NewNoopLogger(t),
+				RiskScoring:   c.scoring,
+				PullReqStatus: models.PullReqStatus{ApprovalStatus: c.approval},
+			}
+			risk, err := runner.planRisk(ctx, t.TempDir())
+			Ok(t, err)
+			Equals(t, c.expRisk, risk)
+
+			failure, err := runner.checkRiskApprovals(ctx, t.TempDir())
+			Ok(t, err)
+			Equals(t, c.expFailure, failure)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// rollbackPlanSuffix is appended to the path of a planfile for the file that
+// marks it as a rollback plan. The file contains the commit rolled back to.
+const rollbackPlanSuffix = ".rollback"
+
+// stateIdentity is what identifies a version of a terraform state.
+type stateIdentity struct {
+	Serial  int    `json:"serial"`
+	Lineage string `json:"lineage"`
+}
+
+// parseStateIdentity returns the identity of state, the output of terraform
+// state pull.
+func parseStateIdentity(state string) (stateIdentity, error) {
+	var identity stateIdentity
+	if err := json.Unmarshal([]byte(state), &identity); err != nil {
+		return stateIdentity{}, fmt.Errorf("parsing state: %w", err)
+	}
+	return identity, nil
+}
+
+// rollbackTarget returns the snapshot a rollback of a project returns to: the
+// one before the latest. Rolling back is only feasible if the current state is
+// still the one of the latest snapshot, otherwise a failure is returned.
+func rollbackTarget(snapshots []models.StateSnapshot, current stateIdentity) (models.StateSnapshot, string) {
+	if len(snapshots) < 2 {
+		return models.StateSnapshot{}, "There is no earlier state snapshot of this project to roll back to. Snapshots are recorded after each apply."
+	}
+	latest := snapshots[len(snapshots)-1]
+	if latest.Lineage != current.Lineage || latest.Serial != current.Serial {
+		return models.StateSnapshot{}, fmt.Sprintf("The state changed since it was recorded after the last apply (serial %d, recorded serial %d) so it can't be rolled back safely.", current.Serial, latest.Serial)
+	}
+	target := snapshots[len(snapshots)-2]
+	if target.Lineage != current.Lineage {
+		return models.StateSnapshot{}, "The state was replaced since the previous apply so it can't be rolled back."
+	}
+	if target.Commit == "" {
+		return models.StateSnapshot{}, "The previous state snapshot doesn't record the commit that was applied so it can't be rolled back to."
+	}
+	return target, ""
+}
+
+// Rollback plans a return of the project described by ctx to the state
+// recorded before its last apply.
+func (p *DefaultProjectCommandRunner) Rollback(ctx command.ProjectContext) command.ProjectResult {
+	planSuccess, failure, err := p.doRollback(ctx)
+	return command.ProjectResult{
+		Command:           command.Rollback,
+		PlanSuccess:       planSuccess,
+		Error:             err,
+		Failure:           failure,
+		RepoRelDir:        ctx.RepoRelDir,
+		Workspace:         ctx.Workspace,
+		ProjectName:       ctx.ProjectName,
+		SilencePRComments: ctx.SilencePRComments,
+	}
+}
+
+// doRollback plans the project with the configuration of the commit of the
+// previous state snapshot. Terraform keeps the configuration in the planfile
+// so the project's files are restored once it's planned.
+func (p *DefaultProjectCommandRunner) doRollback(ctx command.ProjectContext) (*models.PlanSuccess, string, error) {
+	if ctx.Rollback == nil {
+		return nil, "Rollback is not allowed for this repo: server-side config needs 'rollback'.", nil
+	}
+	if p.StateSnapshotStore == nil {
+		return nil, "Rollback needs state snapshots, which aren't enabled on this Atlantis server.", nil
+	}
+	if !valid.UsesTerraform(ctx.Tool) {
+		return nil, fmt.Sprintf("Rollback is only supported for terraform projects, not %s.", ctx.Tool), nil
+	}
+
+	var target models.StateSnapshot
+	planSuccess, failure, err := p.doPlanWithSetup(ctx, func(repoDir string, absPath string) (func(), string, error) {
+		state, err := p.StatePullStepRunner.Run(ctx, nil, absPath, map[string]string{})
+		if err != nil {
+			return nil, "", err
+		}
+		current, err := parseStateIdentity(state)
+		if err != nil {
+			return nil, "", err
+		}
+		snapshots, err := p.StateSnapshotStore.List(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+		if err != nil {
+			return nil, "", fmt.Errorf("listing state snapshots: %w", err)
+		}
+		var failure string
+		target, failure = rollbackTarget(snapshots, current)
+		if failure != "" {
+			return nil, failure, nil
+		}
+		restore, err := checkoutProjectConfig(ctx.Log, repoDir, ctx.RepoRelDir, target.Commit)
+		return restore, "", err
+	})
+	if failure != "" || err != nil {
+		return nil, failure, err
+	}
+
+	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
+	if err != nil {
+		return nil, "", err
+	}
+	planFile := filepath.Join(repoDir, ctx.RepoRelDir, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+	if err := os.WriteFile(planFile+rollbackPlanSuffix, []byte(target.Commit), 0600); err != nil {
+		return nil, "", fmt.Errorf("marking rollback plan: %w", err)
+	}
+	ctx.Log.Info("planned rollback to state serial %d applied from commit %s", target.Serial, target.Commit)
+	return planSuccess, "", nil
+}
+
+// readRollbackPlan returns the commit the project's plan rolls back to or an
+// empty string if it isn't a rollback plan.
+func readRollbackPlan(ctx command.ProjectContext, absPath string) (string, error) {
+	planFile := filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+	commit, err := os.ReadFile(planFile + rollbackPlanSuffix)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading rollback plan marker: %w", err)
+	}
+	return strings.TrimSpace(string(commit)), nil
+}
+
+// removeRollbackPlanMarker removes the file marking the project's plan as a
+// rollback plan, if any.
+func removeRollbackPlanMarker(ctx command.ProjectContext, absPath string) error {
+	planFile := filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+	if err := os.Remove(planFile + rollbackPlanSuffix); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing rollback plan marker: %w", err)
+	}
+	return nil
+}
+
+// checkRollbackApprovals returns a failure if the project's plan is a
+// rollback plan and the pull request doesn't have the approvals the repo
+// requires to apply one. They're required on top of the apply requirements.
+func (p *DefaultProjectCommandRunner) checkRollbackApprovals(ctx command.ProjectContext, absPath string) (string, error) {
+	commit, err := readRollbackPlan(ctx, absPath)
+	if err != nil || commit == "" {
+		return "", err
+	}
+	if ctx.Rollback == nil {
+		return "This is a rollback plan but rollback is no longer allowed for this repo.", nil
+	}
+	if count := ctx.PullReqStatus.ApprovalStatus.ApprovalCount(); count < ctx.Rollback.Approvals {
+		return fmt.Sprintf("This plan rolls back to the state applied from commit %s and requires %d approvals to apply, the pull request has %d.", commit, ctx.Rollback.Approvals, count), nil
+	}
+	return "", nil
+}
+
+// saveStateSnapshot records the state of an applied project and the planfile
+// that was applied. commit is the commit rolled back to if a rollback plan was
+// applied. The apply has already changed infrastructure so errors are logged
+// instead of failing it.
+func (p *DefaultProjectCommandRunner) saveStateSnapshot(ctx command.ProjectContext, absPath string, plan []byte, commit string) {
+	if p.StateSnapshotStore == nil || !valid.UsesTerraform(ctx.Tool) {
+		return
+	}
+	state, err := p.StatePullStepRunner.Run(ctx, nil, absPath, map[string]string{})
+	if err != nil {
+		ctx.Log.Err("pulling state for snapshot: %s", err)
+		return
+	}
+	identity, err := parseStateIdentity(state)
+	if err != nil {
+		ctx.Log.Err("recording state snapshot: %s", err)
+		return
+	}
+	if commit == "" {
+		commit = ctx.Pull.HeadCommit
+	}
+	err = p.StateSnapshotStore.Save(models.StateSnapshot{
+		RepoFullName: ctx.Pull.BaseRepo.FullName,
+		PullNum:      ctx.Pull.Num,
+		Commit:       commit,
+		ProjectName:  ctx.ProjectName,
+		RepoRelDir:   ctx.RepoRelDir,
+		Workspace:    ctx.Workspace,
+		User:         ctx.User.Username,
+		AppliedAt:    time.Now(),
+		Serial:       identity.Serial,
+		Lineage:      identity.Lineage,
+		State:        json.RawMessage(state),
+		Plan:         plan,
+	})
+	if err != nil {
+		ctx.Log.Err("saving state snapshot: %s", err)
+		return
+	}
+	ctx.Log.Info("saved state snapshot with serial %d", identity.Serial)
+}
+
+// checkoutProjectConfig replaces the files of the project at repoRelDir in
+// repoDir with the ones of commit, fetching it if the clone doesn't have it.
+// Files of the project that commit doesn't have are removed. It returns a
+// function restoring the project's files.
+func checkoutProjectConfig(logger logging.SimpleLogging, repoDir string, repoRelDir string, commit string) (func(), error) {
+	if _, err := runGit(repoDir, "cat-file", "-e", commit+"^{commit}"); err != nil {
+		// Clones are usually shallow so the commit has to be fetched. The
+		// head remote only exists with the merge checkout strategy.
+		// Fetch errors aren't returned since they can contain the
+		// credentials in the remote's URL.
+		fetched := false
+		for _, remote := range []string{"origin", "head"} {
+			if _, err := runGit(repoDir, "fetch", "--depth=1", remote, commit); err == nil {
+				fetched = true
+				break
+			}
+		}
+		if !fetched {
+			return nil, fmt.Errorf("commit %s of the previous state snapshot can't be fetched", commit)
+		}
+	}
+	// The index is updated too so restoring removes the files commit has that
+	// the checked out commit doesn't.
+	if _, err := runGit(repoDir, "restore", "--source", commit, "--staged", "--worktree", "--", repoRelDir); err != nil {
+		return nil, err
+	}
+	return func() {
+		if _, err := runGit(repoDir, "restore", "--source", "HEAD", "--staged", "--worktree", "--", repoRelDir); err != nil {
+			logger.Err("restoring project files after planning rollback: %s", err)
+		}
+	}, nil
+}
+
+// runGit runs git with args in dir.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...) // nolint: gosec
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("running git %s: %s: %w", strings.Join(args, " "), strings.TrimSpace(string(out)), err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+)
+
+func NewRollbackCommandRunner(
+	pullUpdater *PullUpdater,
+	dbUpdater *DBUpdater,
+	pullReqStatusFetcher vcs.PullReqStatusFetcher,
+	prjCmdBuilder ProjectRollbackCommandBuilder,
+	prjCmdRunner ProjectRollbackCommandRunner,
+) *RollbackCommandRunner {
+	return &RollbackCommandRunner{
+		pullUpdater:          pullUpdater,
+		dbUpdater:            dbUpdater,
+		pullReqStatusFetcher: pullReqStatusFetcher,
+		prjCmdBuilder:        prjCmdBuilder,
+		prjCmdRunner:         prjCmdRunner,
+	}
+}
+
+// RollbackCommandRunner plans the return of a project to the state recorded
+// before its last apply. The plan is applied with the apply command.
+type RollbackCommandRunner struct {
+	pullUpdater          *PullUpdater
+	dbUpdater            *DBUpdater
+	pullReqStatusFetcher vcs.PullReqStatusFetcher
+	prjCmdBuilder        ProjectRollbackCommandBuilder
+	prjCmdRunner         ProjectRollbackCommandRunner
+}
+
+func (r *RollbackCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
+	var err error
+	// Get the mergeable status before we set any build statuses of our own
+	// since the plan requirements may rely on it.
+	ctx.PullRequestStatus, err = r.pullReqStatusFetcher.FetchPullStatus(ctx.Log, ctx.Pull)
+	if err != nil {
+		ctx.Log.Warn("unable to get pull request status: %s. Continuing with mergeable and approved assumed false", err)
+	}
+
+	projectCmds, err := r.prjCmdBuilder.BuildRollbackCommands(ctx, cmd)
+	if err != nil {
+		ctx.Log.Warn("Error %s", err)
+		r.pullUpdater.updatePull(ctx, cmd, command.Result{Error: err})
+		return
+	}
+
+	var result command.Result
+	switch len(projectCmds) {
+	case 0:
+		result = command.Result{Failure: "no project to roll back. please specify one project."}
+	case 1:
+		result = runProjectCmds(projectCmds, r.prjCmdRunner.Rollback)
+		if _, err := r.dbUpdater.updateDB(ctx, ctx.Pull, result.ProjectResults); err != nil {
+			ctx.Log.Err("writing results: %s", err)
+		}
+	default:
+		// Rollbacks are reviewed one at a time so they aren't run in
+		// multiple projects.
+		result = command.Result{Failure: "rollback cannot run on multiple projects. please specify one project."}
+	}
+	r.pullUpdater.updatePull(ctx, cmd, result)
+}
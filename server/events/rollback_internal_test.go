@@ -0,0 +1,118 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRollbackTarget(t *testing.T) {
+	first := models.StateSnapshot{Commit: "abc", Serial: 3, Lineage: "lineage"}
+	second := models.StateSnapshot{Commit: "def", Serial: 5, Lineage: "lineage"}
+
+	cases := []struct {
+		description string
+		snapshots   []models.StateSnapshot
+		current     stateIdentity
+		expTarget   models.StateSnapshot
+		expFailure  string
+	}{
+		{
+			description: "no snapshots",
+			current:     stateIdentity{Serial: 5, Lineage: "lineage"},
+			expFailure:  "There is no earlier state snapshot of this project to roll back to. Snapshots are recorded after each apply.",
+		},
+		{
+			description: "one snapshot",
+			snapshots:   []models.StateSnapshot{second},
+			current:     stateIdentity{Serial: 5, Lineage: "lineage"},
+			expFailure:  "There is no earlier state snapshot of this project to roll back to. Snapshots are recorded after each apply.",
+		},
+		{
+			description: "state unchanged",
+			snapshots:   []models.StateSnapshot{first, second},
+			current:     stateIdentity{Serial: 5, Lineage: "lineage"},
+			expTarget:   first,
+		},
+		{
+			description: "state changed since last apply",
+			snapshots:   []models.StateSnapshot{first, second},
+			current:     stateIdentity{Serial: 6, Lineage: "lineage"},
+			expFailure:  "The state changed since it was recorded after the last apply (serial 6, recorded serial 5) so it can't be rolled back safely.",
+		},
+		{
+			description: "state replaced",
+			snapshots:   []models.StateSnapshot{first, {Commit: "def", Serial: 1, Lineage: "other"}},
+			current:     stateIdentity{Serial: 1, Lineage: "other"},
+			expFailure:  "The state was replaced since the previous apply so it can't be rolled back.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			target, failure := rollbackTarget(c.snapshots, c.current)
+			Equals(t, c.expFailure, failure)
+			Equals(t, c.expTarget, target)
+		})
+	}
+}
+
+func TestCheckRollbackApprovals(t *testing.T) {
+	cases := []struct {
+		description string
+		commit      string
+		rollback    *valid.Rollback
+		approvers   []string
+		expFailure  string
+	}{
+		{
+			description: "not a rollback plan",
+			rollback:    &valid.Rollback{Approvals: 2},
+		},
+		{
+			description: "enough approvals",
+			commit:      "abc",
+			rollback:    &valid.Rollback{Approvals: 2},
+			approvers:   []string{"alice", "bob"},
+		},
+		{
+			description: "too few approvals",
+			commit:      "abc",
+			rollback:    &valid.Rollback{Approvals: 2},
+			approvers:   []string{"alice"},
+			expFailure:  "This plan rolls back to the state applied from commit abc and requires 2 approvals to apply, the pull request has 1.",
+		},
+		{
+			description: "rollback no longer allowed",
+			commit:      "abc",
+			approvers:   []string{"alice", "bob"},
+			expFailure:  "This is a rollback plan but rollback is no longer allowed for this repo.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			absPath := t.TempDir()
+			ctx := command.ProjectContext{
+				Workspace: "default",
+				Rollback:  c.rollback,
+				PullReqStatus: models.PullReqStatus{
+					ApprovalStatus: models.ApprovalStatus{Approvers: c.approvers},
+				},
+			}
+			if c.commit != "" {
+				planFile := filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+				Ok(t, os.WriteFile(planFile+rollbackPlanSuffix, []byte(c.commit), 0600))
+			}
+
+			p := &DefaultProjectCommandRunner{}
+			failure, err := p.checkRollbackApprovals(ctx, absPath)
+			Ok(t, err)
+			Equals(t, c.expFailure, failure)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// SBOMStore stores the software bill of materials of each apply.
+type SBOMStore interface {
+	// Save stores sbom, replacing any SBOM with the same job ID.
+	Save(sbom models.SBOM) error
+	// List returns the SBOMs of the applies for a pull request, oldest first.
+	List(repoFullName string, pullNum int) ([]models.SBOM, error)
+}
+
+// FileSBOMStore stores SBOMs as JSON files in Dir. Unlike the working
+// directories they aren't deleted when the pull request is closed since
+// they're kept for audits.
+type FileSBOMStore struct {
+	Dir string
+}
+
+func (f *FileSBOMStore) Save(sbom models.SBOM) error {
+	pullDir, err := storePullDir(f.Dir, sbom.RepoFullName, sbom.PullNum)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(pullDir, 0700); err != nil {
+		return errors.Wrap(err, "creating sbom dir")
+	}
+	contents, err := json.MarshalIndent(sbom, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(pullDir, sbom.JobID+".json"), contents, 0600)
+}
+
+func (f *FileSBOMStore) List(repoFullName string, pullNum int) ([]models.SBOM, error) {
+	pullDir, err := storePullDir(f.Dir, repoFullName, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(pullDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sboms []models.SBOM
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(pullDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var sbom models.SBOM
+		if err := json.Unmarshal(contents, &sbom); err != nil {
+			return nil, errors.Wrapf(err, "parsing sbom %s", entry.Name())
+		}
+		sboms = append(sboms, sbom)
+	}
+	slices.SortFunc(sboms, func(a, b models.SBOM) int {
+		return a.AppliedAt.Compare(b.AppliedAt)
+	})
+	return sboms, nil
+}
+
+// storePullDir returns the directory in dir that the files of a pull request
+// are stored in.
+func storePullDir(dir string, repoFullName string, pullNum int) (string, error) {
+	repoDir, err := storeRepoDir(dir, repoFullName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(repoDir, strconv.Itoa(pullNum)), nil
+}
+
+// storeRepoDir returns the directory in dir that the files of a repo are
+// stored in. repoFullName can come from an API request so it must not escape
+// dir.
+func storeRepoDir(dir string, repoFullName string) (string, error) {
+	if repoFullName == "" || slices.Contains(strings.Split(repoFullName, "/"), "..") {
+		return "", fmt.Errorf("invalid repo name %q", repoFullName)
+	}
+	return filepath.Join(dir, filepath.FromSlash(repoFullName)), nil
+}
@@ -0,0 +1,50 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFileSBOMStore_SaveAndList(t *testing.T) {
+	store := &events.FileSBOMStore{Dir: t.TempDir()}
+	first := models.SBOM{
+		JobID:        "first",
+		RepoFullName: "group/subgroup/repo",
+		PullNum:      1,
+		AppliedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Tool:         models.SBOMTool{Name: "terraform", Version: "1.7.0"},
+	}
+	second := models.SBOM{
+		JobID:        "second",
+		RepoFullName: "group/subgroup/repo",
+		PullNum:      1,
+		AppliedAt:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Providers:    []models.SBOMProvider{{Source: "registry.terraform.io/hashicorp/aws", Version: "5.31.0"}},
+	}
+	otherPull := models.SBOM{
+		JobID:        "other",
+		RepoFullName: "group/subgroup/repo",
+		PullNum:      2,
+	}
+	Ok(t, store.Save(second))
+	Ok(t, store.Save(first))
+	Ok(t, store.Save(otherPull))
+
+	sboms, err := store.List("group/subgroup/repo", 1)
+	Ok(t, err)
+	Equals(t, []models.SBOM{first, second}, sboms)
+
+	sboms, err = store.List("group/subgroup/repo", 3)
+	Ok(t, err)
+	Equals(t, 0, len(sboms))
+}
+
+func TestFileSBOMStore_InvalidRepoName(t *testing.T) {
+	store := &events.FileSBOMStore{Dir: t.TempDir()}
+	_, err := store.List("owner/../../etc", 1)
+	ErrEquals(t, `invalid repo name "owner/../../etc"`, err)
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+)
+
+func NewSilenceCommandRunner(
+	vcsClient vcs.Client,
+	silenceStore SilenceStore,
+) *SilenceCommandRunner {
+	return &SilenceCommandRunner{
+		vcsClient:    vcsClient,
+		silenceStore: silenceStore,
+	}
+}
+
+// SilenceCommandRunner silences the comments of projects on a pull request.
+// Their commit statuses and job logs are still updated.
+type SilenceCommandRunner struct {
+	vcsClient    vcs.Client
+	silenceStore SilenceStore
+}
+
+func (s *SilenceCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
+	s.comment(ctx, s.run(ctx, cmd))
+}
+
+// run silences or unsilences the project of cmd and returns the comment to
+// respond with.
+func (s *SilenceCommandRunner) run(ctx *command.Context, cmd *CommentCommand) string {
+	if !cmd.IsForSpecificProject() {
+		return "**Silence Failed**: select the project to silence with the -d, -w or -p flags."
+	}
+	project := models.SilencedProject{
+		Repository:  ctx.Pull.BaseRepo.FullName,
+		PullNum:     ctx.Pull.Num,
+		ProjectName: cmd.ProjectName,
+		RepoRelDir:  cmd.RepoRelDir,
+		Workspace:   cmd.Workspace,
+	}
+
+	if cmd.Undo {
+		unsilenced, err := s.silenceStore.Unsilence(project)
+		if err != nil {
+			return fmt.Sprintf("**Silence Failed**: %s", err)
+		}
+		if !unsilenced {
+			return fmt.Sprintf("%s wasn't silenced.", silencedProjectDescription(project))
+		}
+		return fmt.Sprintf("%s is no longer silenced.", silencedProjectDescription(project))
+	}
+
+	if err := s.silenceStore.Silence(project); err != nil {
+		return fmt.Sprintf("**Silence Failed**: %s", err)
+	}
+	return fmt.Sprintf("%s is silenced in this pull request. Its results won't be commented but commit statuses and job logs are still updated.", silencedProjectDescription(project))
+}
+
+func (s *SilenceCommandRunner) comment(ctx *command.Context, comment string) {
+	if err := s.vcsClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment, command.Silence.String()); err != nil {
+		ctx.Log.Err("unable to comment: %s", err)
+	}
+}
+
+// silencedProjectDescription describes the projects silenced by project.
+func silencedProjectDescription(project models.SilencedProject) string {
+	if project.ProjectName != "" {
+		return fmt.Sprintf("Project `%s`", project.ProjectName)
+	}
+	switch {
+	case project.RepoRelDir != "" && project.Workspace != "":
+		return fmt.Sprintf("Dir `%s` workspace `%s`", project.RepoRelDir, project.Workspace)
+	case project.RepoRelDir != "":
+		return fmt.Sprintf("Dir `%s`", project.RepoRelDir)
+	default:
+		return fmt.Sprintf("Workspace `%s`", project.Workspace)
+	}
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// SilenceStore stores the projects silenced in pull requests with the
+// silence command.
+type SilenceStore interface {
+	// Silence silences project. Silencing a project twice is a no-op.
+	Silence(project models.SilencedProject) error
+	// Unsilence stops silencing project. It returns false if project wasn't
+	// silenced.
+	Unsilence(project models.SilencedProject) (bool, error)
+	// ListForPull returns the projects silenced in the pull request pullNum
+	// of the repo repoFullName.
+	ListForPull(repoFullName string, pullNum int) ([]models.SilencedProject, error)
+	// DeleteForPull stops silencing the projects of the pull request pullNum
+	// of the repo repoFullName once it's closed.
+	DeleteForPull(repoFullName string, pullNum int) error
+}
+
+// DefaultSilenceStore stores silenced projects in the locking backend so
+// they survive restarts and are shared by the Atlantis instances.
+type DefaultSilenceStore struct {
+	projects *locking.RecordStore[models.SilencedProject]
+}
+
+// NewSilenceStore returns a SilenceStore that stores silenced projects in
+// backend.
+func NewSilenceStore(backend locking.RecordBackend) *DefaultSilenceStore {
+	return &DefaultSilenceStore{projects: locking.NewRecordStore[models.SilencedProject](backend, "silenced-projects")}
+}
+
+func (f *DefaultSilenceStore) Silence(project models.SilencedProject) error {
+	return f.projects.Put(project, func(p models.SilencedProject) bool {
+		return p == project
+	})
+}
+
+func (f *DefaultSilenceStore) Unsilence(project models.SilencedProject) (bool, error) {
+	deleted, err := f.projects.DeleteFunc(func(p models.SilencedProject) bool {
+		return p == project
+	})
+	return deleted > 0, err
+}
+
+func (f *DefaultSilenceStore) ListForPull(repoFullName string, pullNum int) ([]models.SilencedProject, error) {
+	projects, err := f.projects.List()
+	if err != nil {
+		return nil, err
+	}
+	var forPull []models.SilencedProject
+	for _, p := range projects {
+		if p.Repository == repoFullName && p.PullNum == pullNum {
+			forPull = append(forPull, p)
+		}
+	}
+	return forPull, nil
+}
+
+func (f *DefaultSilenceStore) DeleteForPull(repoFullName string, pullNum int) error {
+	_, err := f.projects.DeleteFunc(func(p models.SilencedProject) bool {
+		return p.Repository == repoFullName && p.PullNum == pullNum
+	})
+	return err
+}
@@ -0,0 +1,40 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestSilenceStore_SilenceUnsilence(t *testing.T) {
+	store := events.NewSilenceStore(newTestRecordBackend(t))
+	lockfiles := models.SilencedProject{Repository: "owner/repo", PullNum: 1, ProjectName: "lockfiles"}
+	network := models.SilencedProject{Repository: "owner/repo", PullNum: 1, RepoRelDir: "network"}
+	other := models.SilencedProject{Repository: "owner/repo", PullNum: 2, ProjectName: "lockfiles"}
+	Ok(t, store.Silence(lockfiles))
+	Ok(t, store.Silence(network))
+	Ok(t, store.Silence(other))
+	// Silencing twice is a no-op.
+	Ok(t, store.Silence(lockfiles))
+
+	projects, err := store.ListForPull("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, []models.SilencedProject{lockfiles, network}, projects)
+
+	unsilenced, err := store.Unsilence(lockfiles)
+	Ok(t, err)
+	Equals(t, true, unsilenced)
+	unsilenced, err = store.Unsilence(lockfiles)
+	Ok(t, err)
+	Equals(t, false, unsilenced)
+
+	Ok(t, store.DeleteForPull("owner/repo", 1))
+	projects, err = store.ListForPull("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, 0, len(projects))
+	projects, err = store.ListForPull("owner/repo", 2)
+	Ok(t, err)
+	Equals(t, []models.SilencedProject{other}, projects)
+}
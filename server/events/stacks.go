@@ -0,0 +1,69 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// Terraform stacks are configured in .tfstack.hcl files and deployed by the
+// deployment blocks of .tfdeploy.hcl files.
+const (
+	stackFileSuffix       = ".tfstack.hcl"
+	stackDeployFileSuffix = ".tfdeploy.hcl"
+)
+
+var stackDeployBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "deployment",
+			LabelNames: []string{"name"},
+		},
+	},
+}
+
+// findStackDeployments returns the names of the deployments of the Terraform
+// stack in absDir in the order they're defined. isStack is false if absDir
+// doesn't have a .tfstack.hcl file.
+func findStackDeployments(absDir string) (deployments []string, isStack bool, err error) {
+	entries, err := os.ReadDir(absDir)
+	if err != nil {
+		return nil, false, err
+	}
+	parser := hclparse.NewParser()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(name, stackFileSuffix) {
+			isStack = true
+		}
+		if !strings.HasSuffix(name, stackDeployFileSuffix) {
+			continue
+		}
+		file, diags := parser.ParseHCLFile(filepath.Join(absDir, name))
+		if diags.HasErrors() {
+			return nil, true, fmt.Errorf("parsing %s: %s", name, diags.Error())
+		}
+		content, _, _ := file.Body.PartialContent(stackDeployBlockSchema)
+		for _, block := range content.Blocks {
+			deployments = append(deployments, block.Labels[0])
+		}
+	}
+	if !isStack {
+		return nil, false, nil
+	}
+	return deployments, true, nil
+}
+
+// useStackTool makes projCfg a project of a stack deployment.
+func useStackTool(projCfg *valid.MergedProjectCfg) {
+	projCfg.Tool = valid.ToolStacks
+	projCfg.Workflow = valid.ToolWorkflow(valid.ToolStacks, projCfg.Workflow)
+}
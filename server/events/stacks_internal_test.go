@@ -0,0 +1,71 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFindStackDeployments(t *testing.T) {
+	tmp := DirStructure(t, map[string]interface{}{
+		"stack": map[string]interface{}{
+			"components.tfstack.hcl": nil,
+			"deployments.tfdeploy.hcl": `
+deployment "staging" {
+  inputs = { region = "eu-west-1" }
+}
+
+deployment "production" {
+  inputs = { region = "us-east-1" }
+}
+
+orchestrate "auto_approve" "staging" {
+  check {
+    condition = context.plan.deployment == deployment.staging
+    reason    = "Only staging is auto approved."
+  }
+}
+`,
+		},
+		"terraform": map[string]interface{}{
+			"main.tf": nil,
+		},
+		"empty-stack": map[string]interface{}{
+			"components.tfstack.hcl": nil,
+		},
+	})
+
+	deployments, isStack, err := findStackDeployments(filepath.Join(tmp, "stack"))
+	Ok(t, err)
+	Equals(t, true, isStack)
+	Equals(t, []string{"staging", "production"}, deployments)
+
+	deployments, isStack, err = findStackDeployments(filepath.Join(tmp, "terraform"))
+	Ok(t, err)
+	Equals(t, false, isStack)
+	Equals(t, 0, len(deployments))
+
+	deployments, isStack, err = findStackDeployments(filepath.Join(tmp, "empty-stack"))
+	Ok(t, err)
+	Equals(t, true, isStack)
+	Equals(t, 0, len(deployments))
+
+	Ok(t, os.WriteFile(filepath.Join(tmp, "stack", "deployments.tfdeploy.hcl"), []byte(`deployment "staging" {`), 0600))
+	_, _, err = findStackDeployments(filepath.Join(tmp, "stack"))
+	ErrContains(t, "parsing deployments.tfdeploy.hcl", err)
+}
+
+func TestUseStackTool(t *testing.T) {
+	projCfg := valid.MergedProjectCfg{Workflow: valid.Workflow{Name: valid.DefaultWorkflowName}}
+	useStackTool(&projCfg)
+	Equals(t, valid.ToolStacks, projCfg.Tool)
+	Equals(t, valid.Tools[valid.ToolStacks].Workflow, projCfg.Workflow)
+
+	// Server-side workflows other than the default one are kept.
+	projCfg = valid.MergedProjectCfg{Workflow: valid.Workflow{Name: "custom"}}
+	useStackTool(&projCfg)
+	Equals(t, "custom", projCfg.Workflow.Name)
+}
@@ -0,0 +1,184 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// StalePullReaper is a scheduled job that reminds pull requests whose plans
+// hold locks but haven't been planned again or applied in RemindAfter. If
+// DiscardAfter is set, the plans of reminded pull requests are discarded and
+// their locks released once it has passed, so abandoned pull requests don't
+// block shared projects.
+type StalePullReaper struct {
+	Locker            locking.Locker
+	DeleteLockCommand DeleteLockCommand
+	WorkingDir        WorkingDir
+	VCSClient         vcs.Client
+	Logger            logging.SimpleLogging
+	// RemindAfter is how long the plans of a pull request can go without
+	// progress before it's reminded.
+	RemindAfter time.Duration
+	// DiscardAfter is how long after the reminder the plans are discarded.
+	// If 0, they're never discarded.
+	DiscardAfter time.Duration
+	// DisableUnlockLabel, if set, stops the plans of pull requests with this
+	// label from being discarded, like it does for atlantis unlock.
+	DisableUnlockLabel string
+
+	mu sync.Mutex
+	// reminded holds when each stale pull request was reminded, keyed by
+	// stalePullKey. Reminders aren't persisted so after a restart pull
+	// requests are reminded again before their plans are discarded.
+	reminded map[string]time.Time
+}
+
+// stalePull is the lock activity of a pull request.
+type stalePull struct {
+	pull         models.PullRequest
+	numLocks     int
+	lastActivity time.Time
+}
+
+func stalePullKey(pull models.PullRequest) string {
+	return fmt.Sprintf("%s#%d", pull.BaseRepo.FullName, pull.Num)
+}
+
+// Run reminds or cleans up the pull requests with stale plans.
+func (r *StalePullReaper) Run() {
+	locks, err := r.Locker.List()
+	if err != nil {
+		r.Logger.Err("listing locks to find stale pull requests: %s", err)
+		return
+	}
+
+	pulls := make(map[string]*stalePull)
+	for _, lock := range locks {
+		if locking.IsLockKey(lock.Project) {
+			continue
+		}
+		key := stalePullKey(lock.Pull)
+		p, ok := pulls[key]
+		if !ok {
+			p = &stalePull{pull: lock.Pull}
+			pulls[key] = p
+		}
+		p.numLocks++
+		if activity := r.lastActivity(lock); activity.After(p.lastActivity) {
+			p.lastActivity = activity
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reminded == nil {
+		r.reminded = make(map[string]time.Time)
+	}
+	// Forget pull requests whose locks were released.
+	for key := range r.reminded {
+		if _, ok := pulls[key]; !ok {
+			delete(r.reminded, key)
+		}
+	}
+	now := time.Now()
+	for key, p := range pulls {
+		r.check(key, p, now)
+	}
+}
+
+// lastActivity returns when the project of lock was last planned. Plans
+// rewrite the planfile, which is deleted by an apply, so if there is no
+// planfile the time the lock was created is used.
+func (r *StalePullReaper) lastActivity(lock models.ProjectLock) time.Time {
+	activity := lock.Time
+	repoDir, err := r.WorkingDir.GetWorkingDir(lock.Pull.BaseRepo, lock.Pull, lock.Workspace)
+	if err != nil {
+		return activity
+	}
+	planFile := filepath.Join(repoDir, lock.Project.Path, runtime.GetPlanFilename(lock.Workspace, lock.Project.ProjectName))
+	if info, err := os.Stat(planFile); err == nil && info.ModTime().After(activity) {
+		activity = info.ModTime()
+	}
+	return activity
+}
+
+// check reminds p if it's stale and discards its plans once DiscardAfter has
+// passed since the reminder. r.mu must be held.
+func (r *StalePullReaper) check(key string, p *stalePull, now time.Time) {
+	if now.Sub(p.lastActivity) < r.RemindAfter {
+		// The pull request was planned again since it was reminded.
+		delete(r.reminded, key)
+		return
+	}
+
+	remindedAt, ok := r.reminded[key]
+	if !ok {
+		r.Logger.Info("reminding pull request %s of its stale plans", key)
+		if err := r.VCSClient.CreateComment(r.Logger, p.pull.BaseRepo, p.pull.Num, r.reminderComment(p), ""); err != nil {
+			r.Logger.Err("unable to comment on pull request %s: %s", key, err)
+			return
+		}
+		r.reminded[key] = now
+		return
+	}
+
+	if r.DiscardAfter == 0 || now.Sub(remindedAt) < r.DiscardAfter {
+		return
+	}
+	if r.DisableUnlockLabel != "" {
+		labels, err := r.VCSClient.GetPullLabels(r.Logger, p.pull.BaseRepo, p.pull)
+		if err != nil {
+			r.Logger.Err("unable to get labels of pull request %s: %s", key, err)
+			return
+		}
+		if slices.Contains(labels, r.DisableUnlockLabel) {
+			r.Logger.Debug("not discarding stale plans of pull request %s with %s label", key, r.DisableUnlockLabel)
+			return
+		}
+	}
+
+	r.Logger.Info("discarding stale plans of pull request %s", key)
+	if _, err := r.DeleteLockCommand.DeleteLocksByPull(r.Logger, p.pull.BaseRepo.FullName, p.pull.Num); err != nil {
+		r.Logger.Err("unable to discard stale plans of pull request %s: %s", key, err)
+		return
+	}
+	delete(r.reminded, key)
+	comment := fmt.Sprintf(stalePullDiscardedComment, formatDays(now.Sub(p.lastActivity)))
+	if err := r.VCSClient.CreateComment(r.Logger, p.pull.BaseRepo, p.pull.Num, comment, command.Unlock.String()); err != nil {
+		r.Logger.Err("unable to comment on pull request %s: %s", key, err)
+	}
+}
+
+func (r *StalePullReaper) reminderComment(p *stalePull) string {
+	comment := fmt.Sprintf(stalePullReminderComment, formatDays(time.Since(p.lastActivity)), p.numLocks)
+	if r.DiscardAfter > 0 {
+		comment += fmt.Sprintf(" Otherwise they'll be discarded and their locks released in %s.", formatDays(r.DiscardAfter))
+	}
+	return comment
+}
+
+// formatDays formats d as a number of whole days.
+func formatDays(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+var stalePullReminderComment = "**Stale plans:** The Atlantis plans of this pull request haven't been updated in %s and hold locks on %d project(s)," +
+	" which stops other pull requests from planning them. Apply them, comment `atlantis plan` to plan again or comment `atlantis unlock` to discard them."
+
+var stalePullDiscardedComment = "The Atlantis plans of this pull request were discarded and their locks released since they hadn't been updated in %s." +
+	" Comment `atlantis plan` to plan again."
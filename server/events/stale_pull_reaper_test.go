@@ -0,0 +1,120 @@
+package events_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock/v4"
+	lockingmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestStalePullReaper(t *testing.T) {
+	RegisterMockTestingT(t)
+	repo := models.Repo{FullName: "owner/repo"}
+	stalePull := models.PullRequest{BaseRepo: repo, Num: 1}
+	activePull := models.PullRequest{BaseRepo: repo, Num: 2}
+	locker := lockingmocks.NewMockLocker()
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{
+		"owner/repo/a/default": {
+			Project:   models.Project{RepoFullName: "owner/repo", Path: "a"},
+			Pull:      stalePull,
+			Workspace: "default",
+			Time:      time.Now().Add(-10 * 24 * time.Hour),
+		},
+		"owner/repo/b/default": {
+			Project:   models.Project{RepoFullName: "owner/repo", Path: "b"},
+			Pull:      stalePull,
+			Workspace: "default",
+			Time:      time.Now().Add(-8 * 24 * time.Hour),
+		},
+		"owner/repo/c/default": {
+			Project:   models.Project{RepoFullName: "owner/repo", Path: "c"},
+			Pull:      activePull,
+			Workspace: "default",
+			Time:      time.Now().Add(-10 * 24 * time.Hour),
+		},
+	}, nil)
+
+	// The project of the active pull was planned again just now.
+	activeDir := t.TempDir()
+	Ok(t, os.MkdirAll(filepath.Join(activeDir, "c"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(activeDir, "c", "default.tfplan"), nil, 0600))
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.GetWorkingDir(repo, stalePull, "default")).ThenReturn("", errors.New("not cloned"))
+	When(workingDir.GetWorkingDir(repo, activePull, "default")).ThenReturn(activeDir, nil)
+
+	vcsClient := vcsmocks.NewMockClient()
+	deleteLockCommand := mocks.NewMockDeleteLockCommand()
+	reaper := &events.StalePullReaper{
+		Locker:            locker,
+		DeleteLockCommand: deleteLockCommand,
+		WorkingDir:        workingDir,
+		VCSClient:         vcsClient,
+		Logger:            logging.NewNoopLogger(t),
+		RemindAfter:       7 * 24 * time.Hour,
+		DiscardAfter:      time.Nanosecond,
+	}
+
+	reaper.Run()
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(repo), Eq(1),
+		Eq("**Stale plans:** The Atlantis plans of this pull request haven't been updated in 8 days and hold locks on 2 project(s),"+
+			" which stops other pull requests from planning them. Apply them, comment `atlantis plan` to plan again or comment `atlantis unlock` to discard them."+
+			" Otherwise they'll be discarded and their locks released in 0 days."), Eq(""))
+	deleteLockCommand.VerifyWasCalled(Never()).DeleteLocksByPull(Any[logging.SimpleLogging](), Any[string](), Any[int]())
+
+	// The grace period has passed on the next run.
+	reaper.Run()
+	deleteLockCommand.VerifyWasCalledOnce().DeleteLocksByPull(Any[logging.SimpleLogging](), Eq("owner/repo"), Eq(1))
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(repo), Eq(1),
+		Eq("The Atlantis plans of this pull request were discarded and their locks released since they hadn't been updated in 8 days."+
+			" Comment `atlantis plan` to plan again."), Eq("unlock"))
+	vcsClient.VerifyWasCalled(Never()).CreateComment(Any[logging.SimpleLogging](), Any[models.Repo](), Eq(2), Any[string](), Any[string]())
+}
+
+func TestStalePullReaper_DisableUnlockLabel(t *testing.T) {
+	RegisterMockTestingT(t)
+	repo := models.Repo{FullName: "owner/repo"}
+	pull := models.PullRequest{BaseRepo: repo, Num: 1}
+	locker := lockingmocks.NewMockLocker()
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{
+		"owner/repo/./default": {
+			Project:   models.Project{RepoFullName: "owner/repo", Path: "."},
+			Pull:      pull,
+			Workspace: "default",
+			Time:      time.Now().Add(-3 * 24 * time.Hour),
+		},
+	}, nil)
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.GetWorkingDir(repo, pull, "default")).ThenReturn("", errors.New("not cloned"))
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetPullLabels(Any[logging.SimpleLogging](), Eq(repo), Eq(pull))).ThenReturn([]string{"do-not-unlock"}, nil)
+	deleteLockCommand := mocks.NewMockDeleteLockCommand()
+	reaper := &events.StalePullReaper{
+		Locker:             locker,
+		DeleteLockCommand:  deleteLockCommand,
+		WorkingDir:         workingDir,
+		VCSClient:          vcsClient,
+		Logger:             logging.NewNoopLogger(t),
+		RemindAfter:        24 * time.Hour,
+		DisableUnlockLabel: "do-not-unlock",
+	}
+
+	// Without a grace period the plans are never discarded.
+	reaper.Run()
+	reaper.Run()
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(repo), Eq(1), Any[string](), Eq(""))
+	vcsClient.VerifyWasCalled(Never()).GetPullLabels(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest]())
+
+	reaper.DiscardAfter = time.Nanosecond
+	reaper.Run()
+	deleteLockCommand.VerifyWasCalled(Never()).DeleteLocksByPull(Any[logging.SimpleLogging](), Any[string](), Any[int]())
+}
@@ -29,6 +29,10 @@ func (v *StateCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 	switch cmd.SubName {
 	case "rm":
 		result = v.runRm(ctx, cmd)
+	case "list":
+		result = v.runRead(ctx, cmd, v.prjCmdRunner.StateList)
+	case "show":
+		result = v.runRead(ctx, cmd, v.prjCmdRunner.StateShow)
 	default:
 		result = command.Result{
 			Failure: fmt.Sprintf("unknown state subcommand %s", cmd.SubName),
@@ -44,3 +48,15 @@ func (v *StateCommandRunner) runRm(ctx *command.Context, cmd *CommentCommand) co
 	}
 	return runProjectCmds(projectCmds, v.prjCmdRunner.StateRm)
 }
+
+// runRead runs state list or state show. Unlike for state rm, errors building
+// the commands are commented since reading the state may not be allowed for
+// the repo.
+func (v *StateCommandRunner) runRead(ctx *command.Context, cmd *CommentCommand, runnerFunc func(command.ProjectContext) command.ProjectResult) command.Result {
+	projectCmds, err := v.prjCmdBuilder.BuildStateReadCommands(ctx, cmd)
+	if err != nil {
+		ctx.Log.Warn("Error %s", err)
+		return command.Result{Error: err}
+	}
+	return runProjectCmds(projectCmds, runnerFunc)
+}
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// stateSnapshotsKept is how many snapshots are kept for each project. Older
+// ones are deleted when a new one is saved.
+const stateSnapshotsKept = 10
+
+// StateSnapshotStore stores the state of projects after each successful apply
+// so the rollback command can plan a return to the previous one.
+type StateSnapshotStore interface {
+	// Save stores snapshot and deletes the oldest snapshots of its project.
+	Save(snapshot models.StateSnapshot) error
+	// List returns the snapshots of a project, oldest first. Unlike other
+	// stores they're listed by project since a project's previous state may
+	// have been applied from another pull request.
+	List(repoFullName string, repoRelDir string, workspace string, projectName string) ([]models.StateSnapshot, error)
+}
+
+// FileStateSnapshotStore stores snapshots as JSON files in Dir. They aren't
+// deleted when the pull request is closed.
+type FileStateSnapshotStore struct {
+	Dir string
+}
+
+func (f *FileStateSnapshotStore) Save(snapshot models.StateSnapshot) error {
+	projectDir, err := f.projectDir(snapshot.RepoFullName, snapshot.RepoRelDir, snapshot.Workspace, snapshot.ProjectName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(projectDir, 0700); err != nil {
+		return errors.Wrap(err, "creating state snapshot dir")
+	}
+	contents, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d.json", snapshot.AppliedAt.UnixNano())
+	if err := os.WriteFile(filepath.Join(projectDir, name), contents, 0600); err != nil {
+		return err
+	}
+
+	snapshots, err := f.List(snapshot.RepoFullName, snapshot.RepoRelDir, snapshot.Workspace, snapshot.ProjectName)
+	if err != nil {
+		return err
+	}
+	for len(snapshots) > stateSnapshotsKept {
+		name := fmt.Sprintf("%d.json", snapshots[0].AppliedAt.UnixNano())
+		if err := os.Remove(filepath.Join(projectDir, name)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "deleting old state snapshot")
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+func (f *FileStateSnapshotStore) List(repoFullName string, repoRelDir string, workspace string, projectName string) ([]models.StateSnapshot, error) {
+	projectDir, err := f.projectDir(repoFullName, repoRelDir, workspace, projectName)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(projectDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []models.StateSnapshot
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(projectDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var snapshot models.StateSnapshot
+		if err := json.Unmarshal(contents, &snapshot); err != nil {
+			return nil, errors.Wrapf(err, "parsing state snapshot %s", entry.Name())
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	slices.SortFunc(snapshots, func(a, b models.StateSnapshot) int {
+		return a.AppliedAt.Compare(b.AppliedAt)
+	})
+	return snapshots, nil
+}
+
+// projectDir returns the directory the snapshots of a project are stored in.
+// Project names and dirs can contain any character so it's named after a hash
+// of what identifies the project.
+func (f *FileStateSnapshotStore) projectDir(repoFullName string, repoRelDir string, workspace string, projectName string) (string, error) {
+	repoDir, err := storeRepoDir(f.Dir, repoFullName)
+	if err != nil {
+		return "", err
+	}
+	id := sha256.Sum256([]byte(strings.Join([]string{repoRelDir, workspace, projectName}, "\x00")))
+	return filepath.Join(repoDir, hex.EncodeToString(id[:])), nil
+}
@@ -0,0 +1,55 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFileStateSnapshotStore(t *testing.T) {
+	store := &events.FileStateSnapshotStore{Dir: t.TempDir()}
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var saved []models.StateSnapshot
+	for serial := 1; serial <= 12; serial++ {
+		snapshot := models.StateSnapshot{
+			RepoFullName: "owner/repo",
+			PullNum:      serial,
+			RepoRelDir:   "dir",
+			Workspace:    "default",
+			AppliedAt:    appliedAt.Add(time.Duration(serial) * time.Hour),
+			Serial:       serial,
+			State:        json.RawMessage(`{"version":4}`),
+			Plan:         []byte("planfile"),
+		}
+		Ok(t, store.Save(snapshot))
+		saved = append(saved, snapshot)
+	}
+	// Snapshots of other projects are listed separately.
+	Ok(t, store.Save(models.StateSnapshot{
+		RepoFullName: "owner/repo",
+		RepoRelDir:   "dir",
+		Workspace:    "staging",
+		AppliedAt:    appliedAt,
+	}))
+
+	// Only the 10 latest snapshots are kept.
+	snapshots, err := store.List("owner/repo", "dir", "default", "")
+	Ok(t, err)
+	Equals(t, 10, len(snapshots))
+	for i := range snapshots {
+		Assert(t, snapshots[i].AppliedAt.Equal(saved[i+2].AppliedAt), "expected snapshot %d to be applied at %s", i, saved[i+2].AppliedAt)
+		Equals(t, saved[i+2].Serial, snapshots[i].Serial)
+		Equals(t, saved[i+2].Plan, snapshots[i].Plan)
+	}
+
+	snapshots, err = store.List("owner/repo", "other", "default", "")
+	Ok(t, err)
+	Equals(t, 0, len(snapshots))
+
+	_, err = store.List("../repo", "dir", "default", "")
+	ErrContains(t, "invalid repo name", err)
+}
@@ -0,0 +1,168 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// Results of a command in a usage record.
+const (
+	UsageResultSuccess = "success"
+	UsageResultFailure = "failure"
+	UsageResultError   = "error"
+)
+
+// usageLogPruneInterval is how often records older than the retention are
+// deleted.
+const usageLogPruneInterval = 24 * time.Hour
+
+// UsageRecord is a command run on a project, kept for usage reports.
+type UsageRecord struct {
+	Time      time.Time `json:"time"`
+	Repo      string    `json:"repo"`
+	PullNum   int       `json:"pull_num"`
+	Project   string    `json:"project,omitempty"`
+	Dir       string    `json:"dir"`
+	Workspace string    `json:"workspace"`
+	Command   string    `json:"command"`
+	User      string    `json:"user"`
+	// Teams are the VCS teams of the user. They're only known if the
+	// server-side config has team authz.
+	Teams  []string `json:"teams,omitempty"`
+	Result string   `json:"result"`
+	// Duration is how long the command took.
+	Duration time.Duration `json:"duration"`
+	// PolicyFailed is true if a policy set failed.
+	PolicyFailed bool `json:"policy_failed,omitempty"`
+}
+
+// NewUsageRecord returns the record of the command of ctx that started at
+// start and returned result.
+func NewUsageRecord(ctx command.ProjectContext, result command.ProjectResult, start time.Time) UsageRecord {
+	record := UsageRecord{
+		Time:      start,
+		Repo:      ctx.BaseRepo.FullName,
+		PullNum:   ctx.Pull.Num,
+		Project:   ctx.ProjectName,
+		Dir:       ctx.RepoRelDir,
+		Workspace: ctx.Workspace,
+		Command:   ctx.CommandName.String(),
+		User:      ctx.User.Username,
+		Teams:     ctx.User.Teams,
+		Result:    UsageResultSuccess,
+		Duration:  time.Since(start),
+	}
+	switch {
+	case result.Error != nil:
+		record.Result = UsageResultError
+	case result.Failure != "":
+		record.Result = UsageResultFailure
+	}
+	if result.PolicyCheckResults != nil {
+		for _, policySet := range result.PolicyCheckResults.PolicySetResults {
+			if !policySet.Passed {
+				record.PolicyFailed = true
+			}
+		}
+	}
+	return record
+}
+
+// UsageLog keeps the commands run on projects for usage reports.
+type UsageLog interface {
+	Record(record UsageRecord) error
+	// List returns the records of the commands that started between from and
+	// to, oldest first. Zero times don't bound the records.
+	List(from time.Time, to time.Time) ([]UsageRecord, error)
+}
+
+// FileUsageLog appends records as JSON lines to the file at Path.
+type FileUsageLog struct {
+	Path string
+	// Retention is how long records are kept. Records are kept forever if 0.
+	Retention time.Duration
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func (f *FileUsageLog) Record(record UsageRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Retention > 0 && time.Since(f.lastPrune) > usageLogPruneInterval {
+		f.lastPrune = time.Now()
+		if err := f.prune(f.lastPrune.Add(-f.Retention)); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "opening usage log")
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close() // nolint: errcheck
+		return errors.Wrap(err, "writing usage log")
+	}
+	return file.Close()
+}
+
+func (f *FileUsageLog) List(from time.Time, to time.Time) ([]UsageRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var records []UsageRecord
+	err := f.read(func(record UsageRecord, _ []byte) {
+		if (from.IsZero() || !record.Time.Before(from)) && (to.IsZero() || !record.Time.After(to)) {
+			records = append(records, record)
+		}
+	})
+	return records, err
+}
+
+// read calls fn with each record and its line.
+func (f *FileUsageLog) read(fn func(record UsageRecord, line []byte)) error {
+	file, err := os.Open(f.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "opening usage log")
+	}
+	defer file.Close() // nolint: errcheck
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record UsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return errors.Wrap(err, "parsing usage log")
+		}
+		fn(record, scanner.Bytes())
+	}
+	return errors.Wrap(scanner.Err(), "reading usage log")
+}
+
+// prune rewrites the log without the records of commands that started
+// before cutoff.
+func (f *FileUsageLog) prune(cutoff time.Time) error {
+	var kept bytes.Buffer
+	err := f.read(func(record UsageRecord, line []byte) {
+		if !record.Time.Before(cutoff) {
+			kept.Write(line)
+			kept.WriteByte('\n')
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.WriteFile(f.Path, kept.Bytes(), 0600), "pruning usage log")
+}
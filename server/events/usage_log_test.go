@@ -0,0 +1,75 @@
+package events_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFileUsageLog(t *testing.T) {
+	usageLog := &events.FileUsageLog{Path: filepath.Join(t.TempDir(), "usage.jsonl")}
+	records, err := usageLog.List(time.Time{}, time.Time{})
+	Ok(t, err)
+	Equals(t, 0, len(records))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := 3; i >= 1; i-- {
+		Ok(t, usageLog.Record(events.UsageRecord{Time: now.AddDate(0, 0, -i), Repo: "owner/repo", PullNum: i, Command: "plan", Result: events.UsageResultSuccess}))
+	}
+
+	records, err = usageLog.List(now.AddDate(0, 0, -2), time.Time{})
+	Ok(t, err)
+	Equals(t, 2, len(records))
+	Equals(t, 2, records[0].PullNum)
+	Equals(t, 1, records[1].PullNum)
+
+	records, err = usageLog.List(time.Time{}, now.AddDate(0, 0, -2))
+	Ok(t, err)
+	Equals(t, 2, len(records))
+	Equals(t, 3, records[0].PullNum)
+}
+
+func TestFileUsageLog_Prunes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	now := time.Now()
+	Ok(t, (&events.FileUsageLog{Path: path}).Record(events.UsageRecord{Time: now.AddDate(0, 0, -10), PullNum: 1}))
+
+	usageLog := &events.FileUsageLog{Path: path, Retention: 7 * 24 * time.Hour}
+	Ok(t, usageLog.Record(events.UsageRecord{Time: now, PullNum: 2}))
+	records, err := usageLog.List(time.Time{}, time.Time{})
+	Ok(t, err)
+	Equals(t, 1, len(records))
+	Equals(t, 2, records[0].PullNum)
+}
+
+func TestNewUsageRecord(t *testing.T) {
+	ctx := command.ProjectContext{
+		BaseRepo:    models.Repo{FullName: "owner/repo"},
+		Pull:        models.PullRequest{Num: 1},
+		User:        models.User{Username: "alice", Teams: []string{"platform"}},
+		CommandName: command.PolicyCheck,
+		ProjectName: "db",
+		RepoRelDir:  "db",
+		Workspace:   "default",
+	}
+	start := time.Now().Add(-time.Minute)
+
+	record := events.NewUsageRecord(ctx, command.ProjectResult{
+		PolicyCheckResults: &models.PolicyCheckResults{PolicySetResults: []models.PolicySetResult{{PolicySetName: "a", Passed: true}, {PolicySetName: "b"}}},
+	}, start)
+	Equals(t, "owner/repo", record.Repo)
+	Equals(t, "policy_check", record.Command)
+	Equals(t, []string{"platform"}, record.Teams)
+	Equals(t, events.UsageResultSuccess, record.Result)
+	Equals(t, true, record.PolicyFailed)
+	Assert(t, record.Duration >= time.Minute, "expected the duration to be measured from the start")
+
+	Equals(t, events.UsageResultFailure, events.NewUsageRecord(ctx, command.ProjectResult{Failure: "failed"}, start).Result)
+	Equals(t, events.UsageResultError, events.NewUsageRecord(ctx, command.ProjectResult{Error: errors.New("err")}, start).Result)
+}
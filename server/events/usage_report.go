@@ -0,0 +1,218 @@
+package events
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// Dimensions usage reports can be grouped by.
+const (
+	UsageGroupByRepo = "repo"
+	UsageGroupByTeam = "team"
+	UsageGroupByWeek = "week"
+)
+
+// UsageNoTeam is the team of the commands run by users without known teams.
+const UsageNoTeam = "(none)"
+
+// UsageReportRow aggregates the commands of a group. Only the fields of the
+// dimensions the report is grouped by are set.
+type UsageReportRow struct {
+	Repo string `json:",omitempty"`
+	Team string `json:",omitempty"`
+	// Week is the ISO week the commands started in, ex. 2025-W07.
+	Week string `json:",omitempty"`
+
+	Plans                   int
+	PlanFailures            int
+	PlanFailureRate         float64
+	MeanPlanDurationSeconds float64
+	Applies                 int
+	ApplyFailures           int
+	ApplyFailureRate        float64
+	PolicyChecks            int
+	PolicyFailures          int
+	PolicyFailureRate       float64
+
+	planDuration time.Duration
+}
+
+// ParseUsageGroupBy parses a comma-separated list of the dimensions a usage
+// report is grouped by, ex. repo,week.
+func ParseUsageGroupBy(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var groupBy []string
+	for _, dimension := range strings.Split(value, ",") {
+		dimension = strings.TrimSpace(dimension)
+		switch dimension {
+		case UsageGroupByRepo, UsageGroupByTeam, UsageGroupByWeek:
+		default:
+			return nil, fmt.Errorf("invalid dimension %q: must be one of %s, %s or %s", dimension, UsageGroupByRepo, UsageGroupByTeam, UsageGroupByWeek)
+		}
+		if !slices.Contains(groupBy, dimension) {
+			groupBy = append(groupBy, dimension)
+		}
+	}
+	return groupBy, nil
+}
+
+// BuildUsageReport aggregates the plans, applies and policy checks of records
+// by the dimensions of groupBy. A command run by a user in several teams
+// counts for each of them when grouping by team. The rows are sorted by the
+// dimensions in order. Without dimensions, the report has one row.
+func BuildUsageReport(records []UsageRecord, groupBy []string) []UsageReportRow {
+	rows := map[usageReportKey]*UsageReportRow{}
+	for _, record := range records {
+		var isPlan, isApply, isPolicyCheck bool
+		switch record.Command {
+		case command.Plan.String():
+			isPlan = true
+		case command.Apply.String():
+			isApply = true
+		case command.PolicyCheck.String():
+			isPolicyCheck = true
+		default:
+			continue
+		}
+		failed := record.Result != UsageResultSuccess
+
+		for _, key := range usageReportKeys(record, groupBy) {
+			row, ok := rows[key]
+			if !ok {
+				row = &UsageReportRow{Repo: key.Repo, Team: key.Team, Week: key.Week}
+				rows[key] = row
+			}
+			switch {
+			case isPlan:
+				row.Plans++
+				row.planDuration += record.Duration
+				if failed {
+					row.PlanFailures++
+				}
+			case isApply:
+				row.Applies++
+				if failed {
+					row.ApplyFailures++
+				}
+			case isPolicyCheck:
+				row.PolicyChecks++
+				if failed || record.PolicyFailed {
+					row.PolicyFailures++
+				}
+			}
+		}
+	}
+
+	report := make([]UsageReportRow, 0, len(rows))
+	for _, row := range rows {
+		row.PlanFailureRate = usageRate(row.PlanFailures, row.Plans)
+		row.ApplyFailureRate = usageRate(row.ApplyFailures, row.Applies)
+		row.PolicyFailureRate = usageRate(row.PolicyFailures, row.PolicyChecks)
+		if row.Plans > 0 {
+			row.MeanPlanDurationSeconds = row.planDuration.Seconds() / float64(row.Plans)
+		}
+		report = append(report, *row)
+	}
+	slices.SortFunc(report, func(a, b UsageReportRow) int {
+		for _, dimension := range groupBy {
+			if c := strings.Compare(a.dimension(dimension), b.dimension(dimension)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return report
+}
+
+// WriteUsageReportCSV writes report as CSV with a header, including a column
+// for each dimension of groupBy.
+func WriteUsageReportCSV(w io.Writer, report []UsageReportRow, groupBy []string) error {
+	header := append(slices.Clone(groupBy),
+		"plans", "plan_failures", "plan_failure_rate", "mean_plan_duration_seconds",
+		"applies", "apply_failures", "apply_failure_rate",
+		"policy_checks", "policy_failures", "policy_failure_rate")
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range report {
+		var record []string
+		for _, dimension := range groupBy {
+			record = append(record, row.dimension(dimension))
+		}
+		record = append(record,
+			strconv.Itoa(row.Plans), strconv.Itoa(row.PlanFailures), formatUsageFloat(row.PlanFailureRate), formatUsageFloat(row.MeanPlanDurationSeconds),
+			strconv.Itoa(row.Applies), strconv.Itoa(row.ApplyFailures), formatUsageFloat(row.ApplyFailureRate),
+			strconv.Itoa(row.PolicyChecks), strconv.Itoa(row.PolicyFailures), formatUsageFloat(row.PolicyFailureRate))
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// usageReportKey identifies the row of a group.
+type usageReportKey struct {
+	Repo string
+	Team string
+	Week string
+}
+
+// usageReportKeys returns the keys of the rows record counts for.
+func usageReportKeys(record UsageRecord, groupBy []string) []usageReportKey {
+	key := usageReportKey{}
+	teams := []string{""}
+	for _, dimension := range groupBy {
+		switch dimension {
+		case UsageGroupByRepo:
+			key.Repo = record.Repo
+		case UsageGroupByWeek:
+			year, week := record.Time.ISOWeek()
+			key.Week = fmt.Sprintf("%d-W%02d", year, week)
+		case UsageGroupByTeam:
+			teams = record.Teams
+			if len(teams) == 0 {
+				teams = []string{UsageNoTeam}
+			}
+		}
+	}
+	keys := make([]usageReportKey, 0, len(teams))
+	for _, team := range teams {
+		key.Team = team
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (r UsageReportRow) dimension(dimension string) string {
+	switch dimension {
+	case UsageGroupByRepo:
+		return r.Repo
+	case UsageGroupByTeam:
+		return r.Team
+	case UsageGroupByWeek:
+		return r.Week
+	}
+	return ""
+}
+
+func usageRate(count int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+func formatUsageFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 3, 64)
+}
@@ -0,0 +1,68 @@
+package events_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func usageRecords() []events.UsageRecord {
+	// 2025-02-10 is a Monday of week 7.
+	week7 := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	week8 := week7.AddDate(0, 0, 7)
+	return []events.UsageRecord{
+		{Time: week7, Repo: "owner/network", Command: "plan", Teams: []string{"platform"}, Result: events.UsageResultSuccess, Duration: 10 * time.Second},
+		{Time: week7, Repo: "owner/network", Command: "plan", Teams: []string{"platform"}, Result: events.UsageResultError, Duration: 30 * time.Second},
+		{Time: week7, Repo: "owner/network", Command: "policy_check", Teams: []string{"platform"}, Result: events.UsageResultSuccess, PolicyFailed: true},
+		{Time: week7, Repo: "owner/network", Command: "apply", Teams: []string{"platform", "network"}, Result: events.UsageResultSuccess},
+		{Time: week8, Repo: "owner/dns", Command: "plan", Result: events.UsageResultSuccess, Duration: 20 * time.Second},
+		{Time: week8, Repo: "owner/dns", Command: "apply", Result: events.UsageResultFailure},
+		{Time: week8, Repo: "owner/dns", Command: "unlock", Result: events.UsageResultSuccess},
+	}
+}
+
+func TestBuildUsageReport(t *testing.T) {
+	Equals(t, []events.UsageReportRow{
+		{
+			Plans: 3, PlanFailures: 1, PlanFailureRate: 1.0 / 3, MeanPlanDurationSeconds: 20,
+			Applies: 2, ApplyFailures: 1, ApplyFailureRate: 0.5,
+			PolicyChecks: 1, PolicyFailures: 1, PolicyFailureRate: 1,
+		},
+	}, events.BuildUsageReport(usageRecords(), nil))
+
+	Equals(t, []events.UsageReportRow{
+		{Repo: "owner/dns", Week: "2025-W08", Plans: 1, MeanPlanDurationSeconds: 20, Applies: 1, ApplyFailures: 1, ApplyFailureRate: 1},
+		{Repo: "owner/network", Week: "2025-W07", Plans: 2, PlanFailures: 1, PlanFailureRate: 0.5, MeanPlanDurationSeconds: 20, Applies: 1, PolicyChecks: 1, PolicyFailures: 1, PolicyFailureRate: 1},
+	}, events.BuildUsageReport(usageRecords(), []string{events.UsageGroupByRepo, events.UsageGroupByWeek}))
+
+	Equals(t, []events.UsageReportRow{
+		{Team: "(none)", Plans: 1, MeanPlanDurationSeconds: 20, Applies: 1, ApplyFailures: 1, ApplyFailureRate: 1},
+		{Team: "network", Applies: 1},
+		{Team: "platform", Plans: 2, PlanFailures: 1, PlanFailureRate: 0.5, MeanPlanDurationSeconds: 20, Applies: 1, PolicyChecks: 1, PolicyFailures: 1, PolicyFailureRate: 1},
+	}, events.BuildUsageReport(usageRecords(), []string{events.UsageGroupByTeam}))
+}
+
+func TestWriteUsageReportCSV(t *testing.T) {
+	groupBy := []string{events.UsageGroupByRepo}
+	var buf bytes.Buffer
+	Ok(t, events.WriteUsageReportCSV(&buf, events.BuildUsageReport(usageRecords(), groupBy), groupBy))
+	Equals(t, "repo,plans,plan_failures,plan_failure_rate,mean_plan_duration_seconds,applies,apply_failures,apply_failure_rate,policy_checks,policy_failures,policy_failure_rate\n"+
+		"owner/dns,1,0,0.000,20.000,1,1,1.000,0,0,0.000\n"+
+		"owner/network,2,1,0.500,20.000,1,0,0.000,1,1,1.000\n", buf.String())
+}
+
+func TestParseUsageGroupBy(t *testing.T) {
+	groupBy, err := events.ParseUsageGroupBy("repo, week,repo")
+	Ok(t, err)
+	Equals(t, []string{"repo", "week"}, groupBy)
+
+	groupBy, err = events.ParseUsageGroupBy("")
+	Ok(t, err)
+	Equals(t, 0, len(groupBy))
+
+	_, err = events.ParseUsageGroupBy("repo,user")
+	ErrEquals(t, `invalid dimension "user": must be one of repo, team or week`, err)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,13 +22,17 @@ type AzureDevopsClient struct {
 	Client   *azuredevops.Client
 	ctx      context.Context
 	UserName string
+	// CommentSplit overrides how long comments are split.
+	CommentSplit common.SplitConfig
 }
 
-// NewAzureDevopsClient returns a valid Azure DevOps client.
-func NewAzureDevopsClient(hostname string, userName string, token string) (*AzureDevopsClient, error) {
+// NewAzureDevopsClient returns a valid Azure DevOps client. If transport is
+// nil, http.DefaultTransport is used.
+func NewAzureDevopsClient(hostname string, userName string, token string, transport http.RoundTripper) (*AzureDevopsClient, error) {
 	tp := azuredevops.BasicAuthTransport{
-		Username: "",
-		Password: strings.TrimSpace(token),
+		Username:  "",
+		Password:  strings.TrimSpace(token),
+		Transport: transport,
 	}
 	httpClient := tp.Client()
 	httpClient.Timeout = time.Second * 10
@@ -122,7 +127,8 @@ func (g *AzureDevopsClient) CreateComment(logger logging.SimpleLogging, repo mod
 	// or tested limit in Azure DevOps.
 	const maxCommentLength = 150000
 
-	comments := common.SplitComment(comment, maxCommentLength, sepEnd, sepStart, 0, "")
+	split := g.CommentSplit.WithDefaults(common.SplitConfig{MaxSize: maxCommentLength, SepEnd: sepEnd, SepStart: sepStart})
+	comments := common.SplitComment(comment, split.MaxSize, split.SepEnd, split.SepStart, 0, "")
 	owner, project, repoName := SplitAzureDevopsRepoFullName(repo.FullName)
 
 	for i := range comments {
@@ -150,7 +156,53 @@ func (g *AzureDevopsClient) ReactToComment(logger logging.SimpleLogging, repo mo
 	return nil
 }
 
-func (g *AzureDevopsClient) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error { //nolint: revive
+// HidePrevCommandComments closes the threads of the previous command comments
+// on the pull request. Azure DevOps collapses closed threads.
+func (g *AzureDevopsClient) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error {
+	owner, project, repoName := SplitAzureDevopsRepoFullName(repo.FullName)
+	threadsURL := fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads", owner, project, repoName, pullNum)
+
+	req, err := g.Client.NewRequest("GET", threadsURL+"?api-version=5.1", nil)
+	if err != nil {
+		return err
+	}
+	var threads struct {
+		Value []*azuredevops.GitPullRequestCommentThread `json:"value"`
+	}
+	if _, err := g.Client.Execute(g.ctx, req, &threads); err != nil {
+		return errors.Wrap(err, "listing comment threads")
+	}
+
+	for _, thread := range threads.Value {
+		// Atlantis creates a new thread for each comment and the threads
+		// of code comments have a context.
+		if len(thread.Comments) == 0 || thread.PullRequestThreadContext != nil || thread.GetStatus() == "closed" {
+			continue
+		}
+		comment := thread.Comments[0]
+		if comment.GetCommentType() != "text" || !strings.EqualFold(comment.GetAuthor().GetUniqueName(), g.UserName) {
+			continue
+		}
+		firstLine := strings.ToLower(strings.Split(comment.GetContent(), "\n")[0])
+		if !strings.Contains(firstLine, strings.ToLower(command)) {
+			continue
+		}
+		// If dir was specified, skip comments that don't contain the dir in the first line
+		if dir != "" && !strings.Contains(firstLine, strings.ToLower(dir)) {
+			continue
+		}
+
+		logger.Debug("Closing comment thread %d", thread.GetID())
+		req, err := g.Client.NewRequest("PATCH", fmt.Sprintf("%s/%d?api-version=5.1", threadsURL, thread.GetID()), &azuredevops.GitPullRequestCommentThread{
+			Status: azuredevops.String("closed"),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := g.Client.Execute(g.ctx, req, nil); err != nil {
+			return errors.Wrapf(err, "closing comment thread %d", thread.GetID())
+		}
+	}
 	return nil
 }
 
@@ -252,6 +304,53 @@ func (g *AzureDevopsClient) GetPullRequest(logger logging.SimpleLogging, repo mo
 	return pull, err
 }
 
+// GetPullWorkItemIDs returns the ids of the work items linked to the pull
+// request.
+func (g *AzureDevopsClient) GetPullWorkItemIDs(logger logging.SimpleLogging, repo models.Repo, num int) ([]int, error) {
+	pull, err := g.GetPullRequest(logger, repo, num)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting pull request")
+	}
+	var ids []int
+	for _, ref := range pull.WorkItemRefs {
+		id, err := strconv.Atoi(ref.GetID())
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing work item id %q", ref.GetID())
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// CreateWorkItemComment posts comment, which is HTML, on the work item with
+// id in the repo's project.
+func (g *AzureDevopsClient) CreateWorkItemComment(logger logging.SimpleLogging, repo models.Repo, id int, comment string) error {
+	owner, project, _ := SplitAzureDevopsRepoFullName(repo.FullName)
+	_, resp, err := g.Client.WorkItems.CreateComment(g.ctx, owner, project, id, &azuredevops.WorkItemComment{Text: &comment})
+	if resp != nil {
+		logger.Debug("POST /%s/%s/_apis/wit/workItems/%d/comments returned: %d", owner, project, id, resp.StatusCode)
+	}
+	return err
+}
+
+// UpdateWorkItemState moves the work item with id in the repo's project to
+// state, ex. Resolved.
+func (g *AzureDevopsClient) UpdateWorkItemState(logger logging.SimpleLogging, repo models.Repo, id int, state string) error {
+	owner, project, _ := SplitAzureDevopsRepoFullName(repo.FullName)
+	u := fmt.Sprintf("%s/%s/_apis/wit/workitems/%d?api-version=5.1", owner, project, id)
+	patch := []map[string]string{{"op": "add", "path": "/fields/System.State", "value": state}}
+	req, err := g.Client.NewRequest(http.MethodPatch, u, patch)
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	resp, err := g.Client.Execute(g.ctx, req, nil)
+	if resp != nil {
+		logger.Debug("PATCH /%s/%s/_apis/wit/workitems/%d returned: %d", owner, project, id, resp.StatusCode)
+	}
+	return err
+}
+
 // UpdateStatus updates the build status of a commit.
 func (g *AzureDevopsClient) UpdateStatus(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
 	adState := azuredevops.GitError.String()
@@ -102,7 +102,7 @@ func TestAzureDevopsClient_MergePull(t *testing.T) {
 
 			testServerURL, err := url.Parse(testServer.URL)
 			Ok(t, err)
-			client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token")
+			client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token", nil)
 			client.Client.VsaexBaseURL = *testServerURL
 			Ok(t, err)
 			defer disableSSLVerification()()
@@ -219,7 +219,7 @@ func TestAzureDevopsClient_UpdateStatus(t *testing.T) {
 
 			testServerURL, err := url.Parse(testServer.URL)
 			Ok(t, err)
-			client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token")
+			client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token", nil)
 			Ok(t, err)
 			defer disableSSLVerification()()
 
@@ -242,6 +242,48 @@ func TestAzureDevopsClient_UpdateStatus(t *testing.T) {
 	}
 }
 
+func TestAzureDevopsClient_HidePrevCommandComments(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	threads := `{"count": 5, "value": [
+		{"id": 1, "status": "active", "comments": [{"id": 1, "commentType": "text", "author": {"uniqueName": "user"}, "content": "Ran Plan for dir: ` + "`dir1`" + `\nmore"}]},
+		{"id": 2, "status": "closed", "comments": [{"id": 1, "commentType": "text", "author": {"uniqueName": "user"}, "content": "Ran Plan for dir: ` + "`dir1`" + `"}]},
+		{"id": 3, "status": "active", "comments": [{"id": 1, "commentType": "text", "author": {"uniqueName": "user"}, "content": "Ran Apply for dir: ` + "`dir1`" + `"}]},
+		{"id": 4, "status": "active", "comments": [{"id": 1, "commentType": "text", "author": {"uniqueName": "someone"}, "content": "atlantis plan"}]},
+		{"id": 5, "status": "active", "comments": [{"id": 1, "commentType": "system", "author": {"uniqueName": "user"}, "content": "Plan policy"}]}
+	]}`
+	var closed []string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == "GET" && r.RequestURI == "/owner/project/_apis/git/repositories/repo/pullrequests/22/threads?api-version=5.1":
+				w.Write([]byte(threads)) // nolint: errcheck
+			case r.Method == "PATCH":
+				body, err := io.ReadAll(r.Body)
+				Ok(t, err)
+				Equals(t, `{"status":"closed"}`+"\n", string(body))
+				closed = append(closed, r.RequestURI)
+				w.Write([]byte("{}")) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token", nil)
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{
+		FullName: "owner/project/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}
+	Ok(t, client.HidePrevCommandComments(logger, repo, 22, "plan", ""))
+	Equals(t, []string{"/owner/project/_apis/git/repositories/repo/pullrequests/22/threads/1?api-version=5.1"}, closed)
+}
+
 // GetModifiedFiles should make multiple requests if more than one page
 // and concat results.
 func TestAzureDevopsClient_GetModifiedFiles(t *testing.T) {
@@ -286,7 +328,7 @@ func TestAzureDevopsClient_GetModifiedFiles(t *testing.T) {
 
 	testServerURL, err := url.Parse(testServer.URL)
 	Ok(t, err)
-	client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token")
+	client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token", nil)
 	Ok(t, err)
 	defer disableSSLVerification()()
 
@@ -409,7 +451,7 @@ func TestAzureDevopsClient_PullIsMergeable(t *testing.T) {
 			testServerURL, err := url.Parse(testServer.URL)
 			Ok(t, err)
 
-			client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token")
+			client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token", nil)
 			Ok(t, err)
 
 			defer disableSSLVerification()()
@@ -506,7 +548,7 @@ func TestAzureDevopsClient_PullIsApproved(t *testing.T) {
 			testServerURL, err := url.Parse(testServer.URL)
 			Ok(t, err)
 
-			client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token")
+			client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token", nil)
 			Ok(t, err)
 
 			defer disableSSLVerification()()
@@ -554,7 +596,7 @@ func TestAzureDevopsClient_GetPullRequest(t *testing.T) {
 			}))
 		testServerURL, err := url.Parse(testServer.URL)
 		Ok(t, err)
-		client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token")
+		client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token", nil)
 		Ok(t, err)
 		defer disableSSLVerification()()
 
@@ -575,8 +617,53 @@ func TestAzureDevopsClient_GetPullRequest(t *testing.T) {
 	})
 }
 
+func TestAzureDevopsClient_WorkItems(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	var comment, patch, contentType string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Ok(t, err)
+			switch r.Method + " " + r.RequestURI {
+			case "GET /owner/project/_apis/git/repositories/repo/pullrequests/1?api-version=5.1-preview.1&includeWorkItemRefs=true":
+				w.Write([]byte(`{"pullRequestId": 1, "workItemRefs": [{"id": "12"}, {"id": "34"}]}`)) // nolint: errcheck
+			case "POST /owner/project/_apis/wit/workItems/12/comments?api-version=5.1-preview.3":
+				comment = string(body)
+				w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+			case "PATCH /owner/project/_apis/wit/workitems/12?api-version=5.1":
+				patch = string(body)
+				contentType = r.Header.Get("Content-Type")
+				w.Write([]byte(`{"id": 12}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "user", "token", nil)
+	Ok(t, err)
+	defer disableSSLVerification()()
+	repo := models.Repo{
+		FullName: "owner/project/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}
+
+	ids, err := client.GetPullWorkItemIDs(logger, repo, 1)
+	Ok(t, err)
+	Equals(t, []int{12, 34}, ids)
+
+	Ok(t, client.CreateWorkItemComment(logger, repo, 12, "<p>applied</p>"))
+	Equals(t, "{\"text\":\"<p>applied</p>\"}\n", comment)
+
+	Ok(t, client.UpdateWorkItemState(logger, repo, 12, "Resolved"))
+	Equals(t, "[{\"op\":\"add\",\"path\":\"/fields/System.State\",\"value\":\"Resolved\"}]\n", patch)
+	Equals(t, "application/json-patch+json", contentType)
+}
+
 func TestAzureDevopsClient_MarkdownPullLink(t *testing.T) {
-	client, err := vcs.NewAzureDevopsClient("hostname", "user", "token")
+	client, err := vcs.NewAzureDevopsClient("hostname", "user", "token", nil)
 	Ok(t, err)
 	pull := models.PullRequest{Num: 1}
 	s, _ := client.MarkdownPullLink(pull)
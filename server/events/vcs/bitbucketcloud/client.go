@@ -12,6 +12,7 @@ import (
 	validator "github.com/go-playground/validator/v10"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -21,6 +22,9 @@ type Client struct {
 	Password    string
 	BaseURL     string
 	AtlantisURL string
+	// CommentSplit configures splitting long comments. Comments aren't split
+	// unless its MaxSize is set.
+	CommentSplit common.SplitConfig
 }
 
 // NewClient builds a bitbucket cloud client. atlantisURL is the
@@ -91,17 +95,29 @@ func (b *Client) GetModifiedFiles(logger logging.SimpleLogging, repo models.Repo
 // CreateComment creates a comment on the merge request.
 func (b *Client) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, _ string) error {
 	// NOTE: I tried to find the maximum size of a comment for bitbucket.org but
-	// I got up to 200k chars without issue so for now I'm not going to bother
-	// to detect this.
-	bodyBytes, err := json.Marshal(map[string]map[string]string{"content": {
-		"raw": comment,
-	}})
-	if err != nil {
-		return errors.Wrap(err, "json encoding")
+	// I got up to 200k chars without issue so comments are only split if a
+	// max size is configured.
+	comments := []string{comment}
+	if b.CommentSplit.MaxSize > 0 {
+		split := b.CommentSplit.WithDefaults(common.SplitConfig{
+			SepEnd:   "\n```\n**Warning**: Output length greater than max comment size. Continued in next comment.",
+			SepStart: "Continued from previous comment.\n```diff\n",
+		})
+		comments = common.SplitComment(comment, split.MaxSize, split.SepEnd, split.SepStart, 0, "")
 	}
-	path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d/comments", b.BaseURL, repo.FullName, pullNum)
-	_, err = b.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
-	return err
+	for _, c := range comments {
+		bodyBytes, err := json.Marshal(map[string]map[string]string{"content": {
+			"raw": c,
+		}})
+		if err != nil {
+			return errors.Wrap(err, "json encoding")
+		}
+		path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d/comments", b.BaseURL, repo.FullName, pullNum)
+		if _, err = b.makeRequest("POST", path, bytes.NewBuffer(bodyBytes)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // UpdateComment updates the body of a comment on the merge request.
@@ -110,7 +126,7 @@ func (b *Client) ReactToComment(_ logging.SimpleLogging, _ models.Repo, _ int, _
 	return nil
 }
 
-func (b *Client) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, _ string) error {
+func (b *Client) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error {
 	// there is no way to hide comment, so delete them instead
 	me, err := b.GetMyUUID()
 	if err != nil {
@@ -133,6 +149,9 @@ func (b *Client) HidePrevCommandComments(logger logging.SimpleLogging, repo mode
 				continue
 			}
 			firstLine := strings.ToLower(body[0])
+			if dir != "" && !strings.Contains(firstLine, strings.ToLower(dir)) {
+				continue
+			}
 			if strings.Contains(firstLine, strings.ToLower(command)) {
 				// we found our old comment that references that command
 				logger.Debug("Deleting comment with id %s", *c.ID)
@@ -75,6 +75,7 @@ type PullRequestComments struct {
 
 type PullRequest struct {
 	ID           *int          `json:"id,omitempty" validate:"required"`
+	Title        *string       `json:"title,omitempty"`
 	Source       *BranchMeta   `json:"source,omitempty" validate:"required"`
 	Destination  *BranchMeta   `json:"destination,omitempty" validate:"required"`
 	Participants []Participant `json:"participants,omitempty" validate:"required"`
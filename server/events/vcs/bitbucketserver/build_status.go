@@ -0,0 +1,88 @@
+package bitbucketserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// BuildStatus is a build status of the build status API of Bitbucket Server
+// 7.4 and later. Unlike the legacy commit build statuses, they're attached to
+// the ref of the pull request and can have a parent and a duration.
+type BuildStatus struct {
+	Key         string `json:"key"`
+	State       string `json:"state"`
+	URL         string `json:"url"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Parent is the key of the status this one is part of, ex. the status of
+	// a command for the status of one of its projects.
+	Parent string `json:"parent,omitempty"`
+	Ref    string `json:"ref,omitempty"`
+	// Duration is how long the command ran in milliseconds.
+	Duration int64 `json:"duration,omitempty"`
+}
+
+// newBuildStatus returns the build status of the status src of pull. src is
+// either the status of a command, ex. atlantis/plan, or of one of its
+// projects, ex. atlantis/plan: project1, whose parent is the command's status.
+func (b *Client) newBuildStatus(pull models.PullRequest, state string, src string, description string, url string) BuildStatus {
+	status := BuildStatus{
+		Key:         src,
+		State:       state,
+		URL:         url,
+		Name:        src,
+		Description: description,
+		Ref:         "refs/heads/" + pull.HeadBranch,
+	}
+	if parent, _, ok := strings.Cut(src, ": "); ok {
+		status.Parent = parent
+	}
+
+	startKey := pull.HeadCommit + "\x00" + src
+	b.buildStartsMux.Lock()
+	defer b.buildStartsMux.Unlock()
+	if b.buildStarts == nil {
+		b.buildStarts = make(map[string]time.Time)
+	}
+	if state == "INPROGRESS" {
+		b.buildStarts[startKey] = time.Now()
+	} else if start, ok := b.buildStarts[startKey]; ok {
+		status.Duration = time.Since(start).Milliseconds()
+		delete(b.buildStarts, startKey)
+	}
+	return status
+}
+
+// postBuildStatus posts status to the head commit of pull with the build
+// status API of Bitbucket Server 7.4 and later. It returns false if the server
+// doesn't support it.
+func (b *Client) postBuildStatus(pull models.PullRequest, status BuildStatus) (bool, error) {
+	projectKey, err := b.GetProjectKey(pull.BaseRepo.Name, pull.BaseRepo.SanitizedCloneURL)
+	if err != nil {
+		return true, err
+	}
+	bodyBytes, err := json.Marshal(status)
+	if err != nil {
+		return true, errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/rest/api/latest/projects/%s/repos/%s/commits/%s/builds", b.BaseURL, projectKey, pull.BaseRepo.Name, pull.HeadCommit)
+	statusCode, respBody, err := b.do("POST", path, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return true, err
+	}
+	switch statusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return true, nil
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		// Servers before 7.4 don't have the endpoint.
+		return false, nil
+	}
+	return true, fmt.Errorf("making request %q unexpected status code: %d, body: %s", "POST "+path, statusCode, string(respBody))
+}
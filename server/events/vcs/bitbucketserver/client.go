@@ -8,12 +8,18 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/events/vcs/common"
 	"github.com/runatlantis/atlantis/server/logging"
 
 	validator "github.com/go-playground/validator/v10"
+	"github.com/jpillora/backoff"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/models"
 )
@@ -22,12 +28,60 @@ import (
 // single comment.
 const maxCommentLength = 32768
 
+// DefaultMaxRetryDelay is the longest delay between the attempts of a request
+// if Client.MaxRetryDelay isn't set.
+const DefaultMaxRetryDelay = 30 * time.Second
+
+// minRetryDelay is the delay before the first retry of a request, before
+// jitter.
+const minRetryDelay = time.Second
+
+// DefaultLabelPattern matches the labels of pull requests in their title and
+// description, ex. [label:no-autoplan], since Bitbucket Server doesn't have
+// labels.
+const DefaultLabelPattern = `\[label:\s*([^\]\s]+)\s*\]`
+
+var defaultLabelPattern = regexp.MustCompile(DefaultLabelPattern)
+
+// NewLabelPattern parses pattern as a LabelPattern. It must have a group
+// capturing the label.
+func NewLabelPattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if re.NumSubexp() == 0 {
+		return nil, errors.New("must have a group capturing the label")
+	}
+	return re, nil
+}
+
 type Client struct {
 	HTTPClient  *http.Client
 	Username    string
 	Password    string
 	BaseURL     string
 	AtlantisURL string
+	// CommentSplit overrides how long comments are split.
+	CommentSplit common.SplitConfig
+	// LabelPattern matches the labels of pull requests in their title and
+	// description. Its first group is the label. If nil, DefaultLabelPattern
+	// is used.
+	LabelPattern *regexp.Regexp
+	// MaxAttempts is how many times requests answered with 429 are
+	// attempted. If it's below 2, they aren't retried.
+	MaxAttempts int
+	// MaxRetryDelay caps the delay between attempts, including the delays
+	// asked by Retry-After headers. If 0, DefaultMaxRetryDelay is used.
+	MaxRetryDelay time.Duration
+
+	// legacyBuildStatus is set once the server turns out not to support the
+	// build status API of Bitbucket Server 7.4 and later.
+	legacyBuildStatus atomic.Bool
+	buildStartsMux    sync.Mutex
+	// buildStarts are when the in progress statuses were posted, to report
+	// the duration of the command once it finishes.
+	buildStarts map[string]time.Time
 }
 
 type DeleteSourceBranch struct {
@@ -119,8 +173,10 @@ func (b *Client) GetModifiedFiles(logger logging.SimpleLogging, repo models.Repo
 func (b *Client) GetProjectKey(repoName string, cloneURL string) (string, error) {
 	// Get the project key out of the repo clone URL.
 	// Given http://bitbucket.corp:7990/scm/at/atlantis-example.git
-	// we want to get 'at'.
-	expr := fmt.Sprintf(".*/(.*?)/%s\\.git", repoName)
+	// we want to get 'at'. Personal repos have the key of their user's
+	// personal project, ex. '~jdoe' from
+	// http://bitbucket.corp:7990/scm/~jdoe/atlantis-example.git.
+	expr := fmt.Sprintf(".*/(.*?)/%s\\.git", regexp.QuoteMeta(repoName))
 	capture, err := regexp.Compile(expr)
 	if err != nil {
 		return "", errors.Wrapf(err, "constructing regex from %q", expr)
@@ -137,7 +193,8 @@ func (b *Client) GetProjectKey(repoName string, cloneURL string) (string, error)
 func (b *Client) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, _ string) error {
 	sepEnd := "\n```\n**Warning**: Output length greater than max comment size. Continued in next comment."
 	sepStart := "Continued from previous comment.\n```diff\n"
-	comments := common.SplitComment(comment, maxCommentLength, sepEnd, sepStart, 0, "")
+	split := b.CommentSplit.WithDefaults(common.SplitConfig{MaxSize: maxCommentLength, SepEnd: sepEnd, SepStart: sepStart})
+	comments := common.SplitComment(comment, split.MaxSize, split.SepEnd, split.SepStart, 0, "")
 	for _, c := range comments {
 		if err := b.postComment(repo, pullNum, c); err != nil {
 			return err
@@ -150,7 +207,68 @@ func (b *Client) ReactToComment(_ logging.SimpleLogging, _ models.Repo, _ int, _
 	return nil
 }
 
-func (b *Client) HidePrevCommandComments(_ logging.SimpleLogging, _ models.Repo, _ int, _ string, _ string) error {
+// HidePrevCommandComments deletes the previous command comments from the
+// pull request since Bitbucket Server can't hide or collapse comments.
+func (b *Client) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return err
+	}
+	pullURL := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d", b.BaseURL, projectKey, repo.Name, pullNum)
+
+	var comments []ActivityComment
+	nextPageStart := 0
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", fmt.Sprintf("%s/activities?start=%d", pullURL, nextPageStart), nil)
+		if err != nil {
+			return err
+		}
+		var activities Activities
+		if err := json.Unmarshal(resp, &activities); err != nil {
+			return errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(activities); err != nil {
+			return errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		for _, a := range activities.Values {
+			// Edits create more activities for the same comment so only
+			// the activity for adding it is used.
+			if a.Action != nil && *a.Action == "COMMENTED" && a.CommentAction != nil && *a.CommentAction == "ADDED" && a.Comment != nil {
+				comments = append(comments, *a.Comment)
+			}
+		}
+		if *activities.IsLastPage || activities.NextPageStart == nil {
+			break
+		}
+		nextPageStart = *activities.NextPageStart
+	}
+
+	for _, c := range comments {
+		// The activities aren't validated so skip comments missing the fields
+		// needed to delete them.
+		if c.ID == nil || c.Version == nil || c.Text == nil {
+			continue
+		}
+		if c.Author == nil || c.Author.Username == nil || !strings.EqualFold(*c.Author.Username, b.Username) {
+			continue
+		}
+		firstLine := strings.ToLower(strings.Split(*c.Text, "\n")[0])
+		if !strings.Contains(firstLine, strings.ToLower(command)) {
+			continue
+		}
+		// If dir was specified, skip comments that don't contain the dir in the first line
+		if dir != "" && !strings.Contains(firstLine, strings.ToLower(dir)) {
+			continue
+		}
+
+		logger.Debug("Deleting comment with id %d", *c.ID)
+		if _, err := b.makeRequest("DELETE", fmt.Sprintf("%s/comments/%d?version=%d", pullURL, *c.ID, *c.Version), nil); err != nil {
+			// Comments with replies can't be deleted, so keep going.
+			logger.Warn("failed to delete comment %d: %s", *c.ID, err)
+		}
+	}
 	return nil
 }
 
@@ -246,6 +364,15 @@ func (b *Client) UpdateStatus(logger logging.SimpleLogging, _ models.Repo, pull
 		url = b.AtlantisURL
 	}
 
+	if !b.legacyBuildStatus.Load() {
+		supported, err := b.postBuildStatus(pull, b.newBuildStatus(pull, bbState, src, description, url))
+		if supported {
+			return err
+		}
+		logger.Info("Bitbucket Server doesn't support the build status API of 7.4 and later, falling back to commit build statuses")
+		b.legacyBuildStatus.Store(true)
+	}
+
 	bodyBytes, err := json.Marshal(map[string]string{
 		"key":         src,
 		"url":         url,
@@ -327,31 +454,126 @@ func (b *Client) prepRequest(method string, path string, body io.Reader) (*http.
 }
 
 func (b *Client) makeRequest(method string, path string, reqBody io.Reader) ([]byte, error) {
-	req, err := b.prepRequest(method, path, reqBody)
-	if err != nil {
-		return nil, errors.Wrap(err, "constructing request")
-	}
-	resp, err := b.HTTPClient.Do(req)
+	statusCode, respBody, err := b.do(method, path, reqBody)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close() // nolint: errcheck
+	if statusCode != http.StatusOK && statusCode != http.StatusCreated && statusCode != http.StatusNoContent {
+		return nil, fmt.Errorf("making request %q unexpected status code: %d, body: %s", fmt.Sprintf("%s %s", method, path), statusCode, string(respBody))
+	}
+	return respBody, nil
+}
+
+// do sends a request and returns the status code and body of the response.
+// Requests answered with 429 are attempted up to MaxAttempts times, backing
+// off exponentially with jitter or waiting as long as the Retry-After header
+// asks, up to MaxRetryDelay. Other failures aren't retried here: the
+// vcs.MiddlewareClient retries failed reads, and a write answered with a 5xx
+// status may have succeeded.
+func (b *Client) do(method string, path string, reqBody io.Reader) (int, []byte, error) {
+	// The body is read once so that retries can send it again.
+	var body []byte
+	if reqBody != nil {
+		var err error
+		if body, err = io.ReadAll(reqBody); err != nil {
+			return 0, nil, errors.Wrap(err, "reading request body")
+		}
+	}
+	maxDelay := b.MaxRetryDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxRetryDelay
+	}
+	retryer := &backoff.Backoff{Min: minRetryDelay, Max: maxDelay, Factor: 2, Jitter: true}
 	requestStr := fmt.Sprintf("%s %s", method, path)
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != 204 {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("making request %q unexpected status code: %d, body: %s", requestStr, resp.StatusCode, string(respBody))
+	for attempt := 1; ; attempt++ {
+		var bodyReader io.Reader
+		if reqBody != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+		req, err := b.prepRequest(method, path, bodyReader)
+		if err != nil {
+			return 0, nil, errors.Wrap(err, "constructing request")
+		}
+		resp, err := b.HTTPClient.Do(req)
+		if err != nil {
+			return 0, nil, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close() // nolint: errcheck
+		if attempt < b.MaxAttempts && retryableStatus(resp.StatusCode) {
+			time.Sleep(retryDelay(resp.Header, retryer, maxDelay, time.Now()))
+			continue
+		}
+		if err != nil {
+			return 0, nil, errors.Wrapf(err, "reading response from request %q", requestStr)
+		}
+		return resp.StatusCode, respBody, nil
 	}
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.Wrapf(err, "reading response from request %q", requestStr)
+}
+
+// retryableStatus returns true if a request answered with statusCode can be
+// sent again: Bitbucket Server rate limited it before handling it, so even
+// writes are safe to retry.
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests
+}
+
+// retryDelay returns how long to wait before the next attempt: the delay
+// asked by the Retry-After header, in seconds or as a date, or else the next
+// delay of retryer. It's capped at maxDelay.
+func retryDelay(header http.Header, retryer *backoff.Backoff, maxDelay time.Duration, now time.Time) time.Duration {
+	delay := retryer.Duration()
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			delay = max(date.Sub(now), 0)
+		}
 	}
-	return respBody, nil
+	return min(delay, maxDelay)
 }
 
-// GetTeamNamesForUser returns the names of the teams or groups that the user belongs to (in the organization the repository belongs to).
-func (b *Client) GetTeamNamesForUser(_ logging.SimpleLogging, _ models.Repo, _ models.User) ([]string, error) {
-	return nil, nil
+// GetTeamNamesForUser returns the names of the Bitbucket Server groups that
+// the user belongs to. Groups are global to the Bitbucket Server instance and
+// are listed with the admin API, so the user of the client needs the Admin
+// global permission.
+func (b *Client) GetTeamNamesForUser(logger logging.SimpleLogging, _ models.Repo, user models.User) ([]string, error) {
+	logger.Debug("Getting Bitbucket Server group names for user '%s'", user.Username)
+	var groups []string
+	nextPageStart := 0
+	baseURL := fmt.Sprintf("%s/rest/api/1.0/admin/users/more-members?context=%s", b.BaseURL, url.QueryEscape(user.Username))
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		path := fmt.Sprintf("%s&start=%d", baseURL, nextPageStart)
+		statusCode, resp, err := b.do("GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		switch statusCode {
+		case http.StatusOK:
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return nil, fmt.Errorf("listing the groups of user %q needs the Bitbucket Server user of Atlantis to have the Admin global permission, got status code: %d, body: %s", user.Username, statusCode, string(resp))
+		default:
+			return nil, fmt.Errorf("making request %q unexpected status code: %d, body: %s", fmt.Sprintf("GET %s", path), statusCode, string(resp))
+		}
+		var page Groups
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(page); err != nil {
+			return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		for _, v := range page.Values {
+			groups = append(groups, *v.Name)
+		}
+		if *page.IsLastPage || page.NextPageStart == nil {
+			break
+		}
+		nextPageStart = *page.NextPageStart
+	}
+	return groups, nil
 }
 
 func (b *Client) SupportsSingleFileDownload(_ models.Repo) bool {
@@ -369,6 +591,37 @@ func (b *Client) GetCloneURL(_ logging.SimpleLogging, _ models.VCSHostType, _ st
 	return "", fmt.Errorf("not yet implemented")
 }
 
-func (b *Client) GetPullLabels(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest) ([]string, error) {
-	return nil, fmt.Errorf("not yet implemented")
+// GetPullLabels returns the labels tagged in the title and description of the
+// pull request, see LabelPattern.
+func (b *Client) GetPullLabels(_ logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d", b.BaseURL, projectKey, repo.Name, pull.Num)
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var pullResp PullRequest
+	if err := json.Unmarshal(resp, &pullResp); err != nil {
+		return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+
+	pattern := b.LabelPattern
+	if pattern == nil {
+		pattern = defaultLabelPattern
+	}
+	var labels []string
+	for _, text := range []*string{pullResp.Title, pullResp.Description} {
+		if text == nil {
+			continue
+		}
+		for _, match := range pattern.FindAllStringSubmatch(*text, -1) {
+			if len(match) > 1 && match[1] != "" && !slices.Contains(labels, match[1]) {
+				labels = append(labels, match[1])
+			}
+		}
+	}
+	return labels, nil
 }
@@ -0,0 +1,62 @@
+package bitbucketserver
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jpillora/backoff"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		description string
+		retryAfter  string
+		exp         time.Duration
+	}{
+		{
+			description: "backoff",
+			exp:         time.Second,
+		},
+		{
+			description: "retry after seconds",
+			retryAfter:  "5",
+			exp:         5 * time.Second,
+		},
+		{
+			description: "retry after date",
+			retryAfter:  now.Add(10 * time.Second).Format(http.TimeFormat),
+			exp:         10 * time.Second,
+		},
+		{
+			description: "retry after longer than max delay",
+			retryAfter:  "120",
+			exp:         30 * time.Second,
+		},
+		{
+			description: "invalid retry after",
+			retryAfter:  "soon",
+			exp:         time.Second,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			header := http.Header{}
+			if c.retryAfter != "" {
+				header.Set("Retry-After", c.retryAfter)
+			}
+			retryer := &backoff.Backoff{Min: time.Second, Max: 30 * time.Second, Factor: 2}
+			Equals(t, c.exp, retryDelay(header, retryer, 30*time.Second, now))
+		})
+	}
+
+	// Backoff delays double up to the max delay.
+	retryer := &backoff.Backoff{Min: time.Second, Max: 3 * time.Second, Factor: 2}
+	var delays []time.Duration
+	for i := 0; i < 3; i++ {
+		delays = append(delays, retryDelay(http.Header{}, retryer, 3*time.Second, now))
+	}
+	Equals(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, delays)
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
@@ -264,3 +265,373 @@ func TestClient_MarkdownPullLink(t *testing.T) {
 	exp := "#1"
 	Equals(t, exp, s)
 }
+
+func TestClient_GetProjectKey(t *testing.T) {
+	client, err := bitbucketserver.NewClient(nil, "u", "p", "https://base-url", "atlantis-url")
+	Ok(t, err)
+	cases := []struct {
+		repoName string
+		cloneURL string
+		expKey   string
+		expErr   string
+	}{
+		{
+			repoName: "atlantis-example",
+			cloneURL: "http://user:<redacted>@bitbucket.corp:7990/scm/at/atlantis-example.git",
+			expKey:   "at",
+		},
+		{
+			repoName: "atlantis-example",
+			cloneURL: "http://user:<redacted>@bitbucket.corp:7990/scm/~jdoe/atlantis-example.git",
+			expKey:   "~jdoe",
+		},
+		{
+			repoName: "c++",
+			cloneURL: "http://user:<redacted>@bitbucket.corp:7990/scm/~jdoe/c++.git",
+			expKey:   "~jdoe",
+		},
+		{
+			repoName: "atlantis-example",
+			cloneURL: "http://user:<redacted>@bitbucket.corp:7990/scm/at/other.git",
+			expErr:   `could not extract project key from "http://user:<redacted>@bitbucket.corp:7990/scm/at/other.git"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.cloneURL, func(t *testing.T) {
+			key, err := client.GetProjectKey(c.repoName, c.cloneURL)
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expKey, key)
+		})
+	}
+}
+
+func TestClient_HidePrevCommandComments(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	activities := `
+{
+  "values": [
+    {"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 1, "version": 0, "text": "Ran Plan for dir: ` + "`dir1`" + ` workspace: ` + "`default`" + `\nmore", "author": {"name": "user"}}},
+    {"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 2, "version": 3, "text": "Ran Plan for dir: ` + "`dir2`" + ` workspace: ` + "`default`" + `", "author": {"name": "user"}}},
+    {"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 3, "version": 0, "text": "Ran Apply for dir: ` + "`dir1`" + ` workspace: ` + "`default`" + `", "author": {"name": "user"}}},
+    {"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 4, "version": 0, "text": "atlantis plan", "author": {"name": "someone"}}},
+    {"action": "COMMENTED", "commentAction": "EDITED", "comment": {"id": 1, "version": 1, "text": "Ran Plan for dir: ` + "`dir1`" + `", "author": {"name": "user"}}},
+    {"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 5, "text": "Ran Plan without a version", "author": {"name": "user"}}},
+    {"action": "COMMENTED", "commentAction": "ADDED", "comment": {"version": 0, "text": "Ran Plan without an id", "author": {"name": "user"}}},
+    {"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 6, "version": 0, "author": {"name": "user"}}},
+    {"commentAction": "ADDED", "comment": {"id": 7, "version": 0, "text": "Ran Plan without an action", "author": {"name": "user"}}},
+    {"action": "APPROVED"}
+  ],
+  "isLastPage": true
+}
+`
+	var deleted []string
+	var serverURL string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.RequestURI == "/rest/api/1.0/projects/ow/repos/repo/pull-requests/1/activities?start=0":
+			w.Write([]byte(activities)) // nolint: errcheck
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.RequestURI)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	serverURL = testServer.URL
+	client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", serverURL, "runatlantis.io")
+	Ok(t, err)
+	repo := models.Repo{
+		FullName:          "owner/repo",
+		Owner:             "owner",
+		Name:              "repo",
+		SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", serverURL),
+		VCSHost: models.VCSHost{
+			Type:     models.BitbucketServer,
+			Hostname: "bitbucket.corp",
+		},
+	}
+
+	Ok(t, client.HidePrevCommandComments(logger, repo, 1, "plan", ""))
+	Equals(t, []string{
+		"/rest/api/1.0/projects/ow/repos/repo/pull-requests/1/comments/1?version=0",
+		"/rest/api/1.0/projects/ow/repos/repo/pull-requests/1/comments/2?version=3",
+	}, deleted)
+
+	deleted = nil
+	Ok(t, client.HidePrevCommandComments(logger, repo, 1, "plan", "dir2"))
+	Equals(t, []string{"/rest/api/1.0/projects/ow/repos/repo/pull-requests/1/comments/2?version=3"}, deleted)
+}
+
+func TestClient_UpdateStatus(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	var statuses []bitbucketserver.BuildStatus
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "POST", r.Method)
+		Equals(t, "/rest/api/latest/projects/ow/repos/repo/commits/abc123/builds", r.RequestURI)
+		var status bitbucketserver.BuildStatus
+		Ok(t, json.NewDecoder(r.Body).Decode(&status))
+		statuses = append(statuses, status)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "abc123",
+		HeadBranch: "feature",
+		BaseRepo: models.Repo{
+			Name:              "repo",
+			SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL),
+		},
+	}
+	Ok(t, client.UpdateStatus(logger, pull.BaseRepo, pull, models.PendingCommitStatus, "atlantis/plan: project1", "Plan in progress...", ""))
+	Ok(t, client.UpdateStatus(logger, pull.BaseRepo, pull, models.SuccessCommitStatus, "atlantis/plan: project1", "Plan succeeded.", "https://atlantis/jobs/1"))
+
+	Equals(t, 2, len(statuses))
+	Equals(t, bitbucketserver.BuildStatus{
+		Key:         "atlantis/plan: project1",
+		State:       "INPROGRESS",
+		URL:         "runatlantis.io",
+		Name:        "atlantis/plan: project1",
+		Description: "Plan in progress...",
+		Parent:      "atlantis/plan",
+		Ref:         "refs/heads/feature",
+	}, statuses[0])
+	Equals(t, "SUCCESSFUL", statuses[1].State)
+	Equals(t, "https://atlantis/jobs/1", statuses[1].URL)
+	Assert(t, statuses[1].Duration >= 0, "expected a duration, got %d", statuses[1].Duration)
+}
+
+// Test that servers before 7.4 fall back to the legacy commit build status
+// API.
+func TestClient_UpdateStatusLegacy(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	var requests []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.RequestURI)
+		switch r.RequestURI {
+		case "/rest/build-status/1.0/commits/abc123":
+			body, err := io.ReadAll(r.Body)
+			Ok(t, err)
+			Equals(t, `{"description":"Plan succeeded.","key":"atlantis/plan","state":"SUCCESSFUL","url":"runatlantis.io"}`, string(body))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "abc123",
+		BaseRepo: models.Repo{
+			Name:              "repo",
+			SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL),
+		},
+	}
+	Ok(t, client.UpdateStatus(logger, pull.BaseRepo, pull, models.SuccessCommitStatus, "atlantis/plan", "Plan succeeded.", ""))
+	Ok(t, client.UpdateStatus(logger, pull.BaseRepo, pull, models.SuccessCommitStatus, "atlantis/plan", "Plan succeeded.", ""))
+
+	// The new API is only tried once.
+	Equals(t, []string{
+		"/rest/api/latest/projects/ow/repos/repo/commits/abc123/builds",
+		"/rest/build-status/1.0/commits/abc123",
+		"/rest/build-status/1.0/commits/abc123",
+	}, requests)
+}
+
+func TestClient_GetPullLabels(t *testing.T) {
+	cases := []struct {
+		description string
+		pattern     string
+		title       string
+		desc        string
+		expLabels   []string
+	}{
+		{
+			description: "no labels",
+			title:       "Add bucket",
+			expLabels:   nil,
+		},
+		{
+			description: "labels in title and description",
+			title:       "[label:no-autoplan] Add bucket",
+			desc:        "Adds a bucket.\n\n[label:no-autoplan] [label: hotfix ]",
+			expLabels:   []string{"no-autoplan", "hotfix"},
+		},
+		{
+			description: "custom pattern",
+			pattern:     `#(\w[\w-]*)`,
+			title:       "Add bucket #hotfix",
+			desc:        "[label:no-autoplan]",
+			expLabels:   []string{"hotfix"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Equals(t, "/rest/api/1.0/projects/ow/repos/repo/pull-requests/1", r.RequestURI)
+				resp := map[string]any{"id": 1, "version": 3, "title": c.title}
+				if c.desc != "" {
+					resp["description"] = c.desc
+				}
+				Ok(t, json.NewEncoder(w).Encode(resp))
+			}))
+			defer testServer.Close()
+
+			client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+			if c.pattern != "" {
+				client.LabelPattern, err = bitbucketserver.NewLabelPattern(c.pattern)
+				Ok(t, err)
+			}
+			repo := models.Repo{
+				FullName:          "owner/repo",
+				Name:              "repo",
+				SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL),
+			}
+			labels, err := client.GetPullLabels(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 1})
+			Ok(t, err)
+			Equals(t, c.expLabels, labels)
+		})
+	}
+}
+
+func TestNewLabelPattern(t *testing.T) {
+	_, err := bitbucketserver.NewLabelPattern(bitbucketserver.DefaultLabelPattern)
+	Ok(t, err)
+	_, err = bitbucketserver.NewLabelPattern("#label")
+	ErrEquals(t, "must have a group capturing the label", err)
+	_, err = bitbucketserver.NewLabelPattern("(")
+	Assert(t, err != nil, "expected an invalid regexp to fail")
+}
+
+func TestClient_GetTeamNamesForUser(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/rest/api/1.0/admin/users/more-members?context=jdoe%40corp&start=0":
+			w.Write([]byte(`{"values": [{"name": "devops"}, {"name": "sre"}], "isLastPage": false, "nextPageStart": 2}`)) // nolint: errcheck
+		case "/rest/api/1.0/admin/users/more-members?context=jdoe%40corp&start=2":
+			w.Write([]byte(`{"values": [{"name": "stash-users"}], "isLastPage": true}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	groups, err := client.GetTeamNamesForUser(logging.NewNoopLogger(t), models.Repo{}, models.User{Username: "jdoe@corp"})
+	Ok(t, err)
+	Equals(t, []string{"devops", "sre", "stash-users"}, groups)
+}
+
+// Test that listing groups without the Admin global permission, or getting a
+// group without a name, is an error.
+func TestClient_GetTeamNamesForUserErrors(t *testing.T) {
+	cases := map[string]struct {
+		status int
+		body   string
+		expErr string
+	}{
+		"forbidden": {
+			status: http.StatusForbidden,
+			body:   `{"errors": [{"message": "You are not permitted to access this resource"}]}`,
+			expErr: `listing the groups of user "jdoe" needs the Bitbucket Server user of Atlantis to have the Admin global permission, got status code: 403`,
+		},
+		"unauthorized": {
+			status: http.StatusUnauthorized,
+			body:   `{}`,
+			expErr: "Admin global permission, got status code: 401",
+		},
+		"server error": {
+			status: http.StatusInternalServerError,
+			body:   `{}`,
+			expErr: "unexpected status code: 500",
+		},
+		"group without name": {
+			status: http.StatusOK,
+			body:   `{"values": [{"name": "devops"}, {}], "isLastPage": true}`,
+			expErr: "was missing fields",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(c.status)
+				w.Write([]byte(c.body)) // nolint: errcheck
+			}))
+			defer testServer.Close()
+
+			client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+			_, err = client.GetTeamNamesForUser(logging.NewNoopLogger(t), models.Repo{}, models.User{Username: "jdoe"})
+			ErrContains(t, c.expErr, err)
+		})
+	}
+}
+
+// Test that requests answered with 429 are sent again with the same body
+// until they succeed or run out of attempts, and that other failures aren't
+// retried.
+func TestClient_MakeRequestRetries(t *testing.T) {
+	var bodies []string
+	statuses := []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusNoContent}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "/rest/api/1.0/projects/ow/repos/repo/pull-requests/1/comments", r.RequestURI)
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		bodies = append(bodies, string(body))
+		status := statuses[0]
+		statuses = statuses[1:]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		w.WriteHeader(status)
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	client.MaxAttempts = 3
+	client.MaxRetryDelay = time.Millisecond
+	repo := models.Repo{
+		FullName:          "owner/repo",
+		Owner:             "owner",
+		Name:              "repo",
+		SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL),
+	}
+	Ok(t, client.CreateComment(logging.NewNoopLogger(t), repo, 1, "comment", ""))
+	Equals(t, []string{`{"text":"comment"}`, `{"text":"comment"}`, `{"text":"comment"}`}, bodies)
+
+	// Once out of attempts, the last response is an error.
+	bodies = nil
+	statuses = []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests}
+	ErrContains(t, "unexpected status code: 429", client.CreateComment(logging.NewNoopLogger(t), repo, 1, "comment", ""))
+	Equals(t, 3, len(bodies))
+
+	// A write failing with a 5xx status may have succeeded so it isn't
+	// retried.
+	bodies = nil
+	statuses = []int{http.StatusServiceUnavailable, http.StatusNoContent}
+	ErrContains(t, "unexpected status code: 503", client.CreateComment(logging.NewNoopLogger(t), repo, 1, "comment", ""))
+	Equals(t, 1, len(bodies))
+
+	// Other errors aren't retried.
+	bodies = nil
+	statuses = []int{http.StatusBadRequest, http.StatusNoContent}
+	ErrContains(t, "unexpected status code: 400", client.CreateComment(logging.NewNoopLogger(t), repo, 1, "comment", ""))
+	Equals(t, 1, len(bodies))
+}
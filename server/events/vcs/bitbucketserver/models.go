@@ -1,5 +1,7 @@
 package bitbucketserver
 
+import "strings"
+
 const (
 	DiagnosticsPingHeader    = "diagnostics:ping"
 	PullCreatedHeader        = "pr:opened"
@@ -25,12 +27,16 @@ type CommonEventData struct {
 }
 
 type PullRequest struct {
-	Version   *int    `json:"version,omitempty" validate:"required"`
-	ID        *int    `json:"id,omitempty" validate:"required"`
-	FromRef   *Ref    `json:"fromRef,omitempty" validate:"required"`
-	ToRef     *Ref    `json:"toRef,omitempty" validate:"required"`
-	State     *string `json:"state,omitempty" validate:"required"`
-	Reviewers []struct {
+	Version *int    `json:"version,omitempty" validate:"required"`
+	ID      *int    `json:"id,omitempty" validate:"required"`
+	Title   *string `json:"title,omitempty"`
+	// Description is the markdown description of the pull request, missing
+	// if it's empty.
+	Description *string `json:"description,omitempty"`
+	FromRef     *Ref    `json:"fromRef,omitempty" validate:"required"`
+	ToRef       *Ref    `json:"toRef,omitempty" validate:"required"`
+	State       *string `json:"state,omitempty" validate:"required"`
+	Reviewers   []struct {
 		Approved *bool `json:"approved,omitempty" validate:"required"`
 	} `json:"reviewers,omitempty" validate:"required"`
 }
@@ -51,6 +57,16 @@ type Project struct {
 	Key  *string `json:"key,omitempty" validate:"required"`
 }
 
+// PersonalProjectKeyPrefix starts the keys of personal projects, ex. ~JDOE
+// for the personal project of the user jdoe.
+const PersonalProjectKeyPrefix = "~"
+
+// IsPersonal returns true if p is the personal project of a user rather than
+// a shared project.
+func (p *Project) IsPersonal() bool {
+	return strings.HasPrefix(*p.Key, PersonalProjectKeyPrefix)
+}
+
 type Actor struct {
 	Username *string `json:"name,omitempty" validate:"required"`
 }
@@ -59,6 +75,23 @@ type Comment struct {
 	Text *string `json:"text,omitempty" validate:"required"`
 }
 
+type Activities struct {
+	Values []struct {
+		Action        *string          `json:"action,omitempty" validate:"required"`
+		CommentAction *string          `json:"commentAction,omitempty"`
+		Comment       *ActivityComment `json:"comment,omitempty"`
+	} `json:"values,omitempty" validate:"required"`
+	NextPageStart *int  `json:"nextPageStart,omitempty"`
+	IsLastPage    *bool `json:"isLastPage,omitempty" validate:"required"`
+}
+
+type ActivityComment struct {
+	ID      *int    `json:"id,omitempty" validate:"required"`
+	Version *int    `json:"version,omitempty" validate:"required"`
+	Text    *string `json:"text,omitempty" validate:"required"`
+	Author  *Actor  `json:"author,omitempty" validate:"required"`
+}
+
 type Changes struct {
 	Values []struct {
 		Path struct {
@@ -76,3 +109,11 @@ type MergeStatus struct {
 	CanMerge   *bool `json:"canMerge,omitempty" validate:"required"`
 	Conflicted *bool `json:"conflicted,omitempty" validate:"required"`
 }
+
+type Groups struct {
+	Values []struct {
+		Name *string `json:"name,omitempty" validate:"required"`
+	} `json:"values,omitempty" validate:"required,dive"`
+	NextPageStart *int  `json:"nextPageStart,omitempty"`
+	IsLastPage    *bool `json:"isLastPage,omitempty" validate:"required"`
+}
@@ -54,3 +54,23 @@ type Client interface {
 	// GetPullLabels returns the labels of a pull request
 	GetPullLabels(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error)
 }
+
+// ReviewCommenter comments on lines of the diff of a pull request. Only
+// GitHub and GitLab support it.
+type ReviewCommenter interface {
+	// CreateReviewComment comments on line of the file at path, relative to
+	// the repo root, in the head commit of pull. The line must be part of
+	// the pull request's diff.
+	CreateReviewComment(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, path string, line int, comment string) error
+}
+
+// CommentUpdater creates comments and updates them afterwards, ex. to track
+// the progress of a command in a single comment. Only GitHub and GitLab
+// support it.
+type CommentUpdater interface {
+	// CreateUpdatableComment creates comment on the pull request pullNum and
+	// returns its ID. The comment isn't split so it must be short.
+	CreateUpdatableComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string) (int64, error)
+	// UpdateComment replaces the body of the comment commentID.
+	UpdateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, commentID int64, comment string) error
+}
@@ -4,14 +4,82 @@ package common
 
 import (
 	"fmt"
-	"math"
+	"regexp"
+	"strings"
 )
 
+// UploadSummarySize is the maximum number of chars of the output shown in the
+// comment linking to an uploaded comment.
+const UploadSummarySize = 4096
+
+// boundaryWindow is the fraction of a comment that's searched for a boundary
+// to split at. Splitting earlier than that would create too many comments.
+const boundaryWindow = 4
+
+// resourceBoundaryRegex matches the header Terraform prints before each
+// resource in a plan, ex. "  # aws_instance.web will be created".
+var resourceBoundaryRegex = regexp.MustCompile(`\n[ +~!-]*# [^\n]+(will|must|has|is)[^\n]*\n`)
+
+// SplitConfig configures how long comments are split for a VCS host. Empty
+// fields fall back to the host's defaults.
+type SplitConfig struct {
+	// MaxSize is the maximum number of chars in a single comment.
+	MaxSize int `json:"max-size"`
+	// SepEnd is appended to every comment that's continued in the next one.
+	SepEnd string `json:"sep-end"`
+	// SepStart is prepended to every comment that continues the previous one.
+	SepStart string `json:"sep-start"`
+	// Upload is true if comments longer than MaxSize are uploaded in full,
+	// ex. as a gist, and linked from a single comment instead of being split.
+	Upload bool `json:"upload"`
+}
+
+// Validate returns an error if the separators don't leave room for any of
+// the comment.
+func (c SplitConfig) Validate() error {
+	if c.MaxSize < 0 {
+		return fmt.Errorf("max-size must be positive, got %d", c.MaxSize)
+	}
+	if c.MaxSize > 0 && c.MaxSize <= len(c.SepEnd)+len(c.SepStart) {
+		return fmt.Errorf("max-size %d must be greater than the length of sep-start and sep-end", c.MaxSize)
+	}
+	return nil
+}
+
+// WithDefaults returns c with its empty fields set from defaults.
+func (c SplitConfig) WithDefaults(defaults SplitConfig) SplitConfig {
+	if c.MaxSize == 0 {
+		c.MaxSize = defaults.MaxSize
+	}
+	if c.SepEnd == "" {
+		c.SepEnd = defaults.SepEnd
+	}
+	if c.SepStart == "" {
+		c.SepStart = defaults.SepStart
+	}
+	return c
+}
+
 // AutomergeCommitMsg returns the commit message to use when automerging.
 func AutomergeCommitMsg(pullNum int) string {
 	return fmt.Sprintf("[Atlantis] Automatically merging after successful apply: PR #%d", pullNum)
 }
 
+// UploadSummary returns the comment that links to comment uploaded in full
+// to url. It shows the end of comment, which usually contains the plan
+// summary, warnings and errors, and is at most maxSize chars. kind describes
+// the upload, ex. "gist".
+func UploadSummary(comment string, url string, kind string, maxSize int) string {
+	header := fmt.Sprintf("**Note**: Output length greater than max comment size. See the full output in [this %s](%s).\n", kind, url) +
+		"<details><summary>Show End Of Output</summary>\n\n```diff\n"
+	keep := max(0, min(UploadSummarySize, maxSize-len(header)))
+	downFrom := max(0, len(comment)-keep)
+	if downFrom > 0 {
+		downFrom = splitBoundary(comment, downFrom, len(comment))
+	}
+	return header + comment[downFrom:]
+}
+
 /*
 SplitComment splits comment into a slice of comments that are under maxSize.
 - It appends sepEnd to all comments that have a following comment.
@@ -20,6 +88,8 @@ SplitComment splits comment into a slice of comments that are under maxSize.
 comments, and it truncates the beginning of the comment to preserve the end of the comment string,
 which usually contains more important information, such as warnings, errors, and the plan summary.
 - SplitComment appends the truncationHeader to the first comment if it would have produced more comments.
+- It splits at the start of a Terraform resource or, failing that, at the end of a line if there's one near
+the maximum size so continued comments don't start in the middle of a resource or line.
 */
 func SplitComment(comment string, maxSize int, sepEnd string, sepStart string, maxCommentsPerCommand int, truncationHeader string) []string {
 	if len(comment) <= maxSize {
@@ -29,28 +99,43 @@ func SplitComment(comment string, maxSize int, sepEnd string, sepStart string, m
 	// No comment contains both sepEnd and truncationHeader, so we only have to count their max.
 	maxWithSep := maxSize - max(len(sepEnd), len(truncationHeader)) - len(sepStart)
 	var comments []string
-	numPotentialComments := int(math.Ceil(float64(len(comment)) / float64(maxWithSep)))
-	var numComments int
-	if maxCommentsPerCommand == 0 {
-		numComments = numPotentialComments
-	} else {
-		numComments = min(numPotentialComments, maxCommentsPerCommand)
-	}
-	isTruncated := numComments < numPotentialComments
 	upTo := len(comment)
-	for len(comments) < numComments {
+	for upTo > 0 {
 		downFrom := max(0, upTo-maxWithSep)
+		if downFrom > 0 {
+			downFrom = splitBoundary(comment, downFrom, upTo)
+		}
+		isLast := maxCommentsPerCommand != 0 && len(comments)+1 == maxCommentsPerCommand
 		portion := comment[downFrom:upTo]
-		if len(comments)+1 != numComments {
-			portion = sepStart + portion
-		} else if len(comments)+1 == numComments && isTruncated {
+		if downFrom > 0 && isLast {
 			portion = truncationHeader + portion
+		} else if downFrom > 0 {
+			portion = sepStart + portion
 		}
 		if len(comments) != 0 {
 			portion = portion + sepEnd
 		}
 		comments = append([]string{portion}, comments...)
 		upTo = downFrom
+		if isLast {
+			break
+		}
 	}
 	return comments
 }
+
+// splitBoundary returns where to split comment so the part from there to upTo
+// fits in a comment. downFrom is the earliest it can split. It prefers the
+// start of a Terraform resource, then the start of a line, and otherwise
+// splits at downFrom.
+func splitBoundary(comment string, downFrom int, upTo int) int {
+	window := comment[downFrom : downFrom+(upTo-downFrom)/boundaryWindow]
+	if loc := resourceBoundaryRegex.FindStringIndex(window); loc != nil {
+		// Split after the newline so the resource starts the next comment.
+		return downFrom + loc[0] + 1
+	}
+	if i := strings.Index(window, "\n"); i != -1 {
+		return downFrom + i + 1
+	}
+	return downFrom
+}
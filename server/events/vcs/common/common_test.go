@@ -78,6 +78,54 @@ func TestSplitComment_Limited(t *testing.T) {
 		sepStart + comment[len(comment)-expMax:]}, split)
 }
 
+// If there's a resource near the split point, the comment should be split
+// before it rather than in the middle of the previous resource.
+func TestSplitComment_ResourceBoundary(t *testing.T) {
+	first := "Terraform will perform the following actions:\n\n" +
+		"  # aws_instance.web will be created\n" +
+		"  + resource \"aws_instance\" \"web\" {\n" +
+		strings.Repeat("      + ami = \"ami-123\"\n", 20) +
+		"    }\n"
+	second := "  # aws_instance.db will be created\n" +
+		"  + resource \"aws_instance\" \"db\" {\n" +
+		strings.Repeat("      + ami = \"ami-456\"\n", 20) +
+		"    }\n"
+	comment := first + second
+	// Leave room for the second resource plus a few lines of the first.
+	maxSize := len(second) + 60
+
+	split := common.SplitComment(comment, maxSize, "", "", 0, "")
+	Equals(t, second, split[len(split)-1])
+	Equals(t, comment, strings.Join(split, ""))
+}
+
+// If there's no resource near the split point, the comment should be split at
+// the start of a line.
+func TestSplitComment_LineBoundary(t *testing.T) {
+	comment := strings.Repeat("line of output\n", 100)
+	split := common.SplitComment(comment, 200, "", "", 0, "")
+	for _, c := range split {
+		Assert(t, strings.HasSuffix(c, "\n"), "expected %q to end with a newline", c)
+		Assert(t, len(c) <= 200, "expected %q to be at most 200 chars", c)
+	}
+	Equals(t, comment, strings.Join(split, ""))
+}
+
+func TestSplitConfig_WithDefaults(t *testing.T) {
+	defaults := common.SplitConfig{MaxSize: 100, SepEnd: "end", SepStart: "start"}
+	Equals(t, defaults, common.SplitConfig{}.WithDefaults(defaults))
+	Equals(t, common.SplitConfig{MaxSize: 50, SepEnd: "end", SepStart: "start"},
+		common.SplitConfig{MaxSize: 50}.WithDefaults(defaults))
+}
+
+func TestSplitConfig_Validate(t *testing.T) {
+	Ok(t, common.SplitConfig{}.Validate())
+	Ok(t, common.SplitConfig{MaxSize: 100, SepEnd: "end"}.Validate())
+	ErrEquals(t, "max-size must be positive, got -1", common.SplitConfig{MaxSize: -1}.Validate())
+	ErrEquals(t, "max-size 5 must be greater than the length of sep-start and sep-end",
+		common.SplitConfig{MaxSize: 5, SepEnd: "end", SepStart: "start"}.Validate())
+}
+
 func TestAutomergeCommitMsg(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -98,3 +146,23 @@ func TestAutomergeCommitMsg(t *testing.T) {
 		})
 	}
 }
+
+// The upload summary should link to the upload and keep the end of the
+// comment.
+func TestUploadSummary(t *testing.T) {
+	comment := strings.Repeat("a\n", 5000) + "Plan: 1 to add, 0 to change, 0 to destroy."
+	summary := common.UploadSummary(comment, "https://gist.github.com/abc", "gist", 65536)
+
+	Assert(t, strings.HasPrefix(summary, "**Note**: Output length greater than max comment size. See the full output in [this gist](https://gist.github.com/abc).\n"),
+		"summary should link to the gist but was %q", summary[:100])
+	Assert(t, strings.HasSuffix(summary, "Plan: 1 to add, 0 to change, 0 to destroy."), "summary should keep the end of the comment")
+	Assert(t, len(summary) < len(comment), "summary should be shorter than the comment")
+}
+
+// The upload summary should fit in small comments.
+func TestUploadSummary_SmallMaxSize(t *testing.T) {
+	comment := strings.Repeat("a", 1000)
+	summary := common.UploadSummary(comment, "https://gist.github.com/abc", "gist", 300)
+	Assert(t, len(summary) <= 300, "summary should be at most 300 chars but was %d", len(summary))
+	Assert(t, strings.HasSuffix(summary, "aaa"), "summary should keep the end of the comment")
+}
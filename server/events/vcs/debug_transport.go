@@ -0,0 +1,135 @@
+package vcs
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// debugBodyLimit is the number of bytes of a body that are logged.
+	debugBodyLimit = 4096
+	redacted       = "REDACTED"
+)
+
+var (
+	// sensitiveNameRegex matches the names of query parameters and JSON
+	// fields whose values must not be logged.
+	sensitiveNameRegex = regexp.MustCompile(`(?i)(token|secret|password|passwd|private_key|api_key|apikey|authorization|credential)`)
+	// jsonStringFieldRegex matches a JSON field with a string value.
+	jsonStringFieldRegex = regexp.MustCompile(`"([^"\\]+)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// DebugLogging toggles logging of VCS API calls at runtime. It's safe for
+// concurrent use.
+type DebugLogging struct {
+	enabled atomic.Bool
+}
+
+func (d *DebugLogging) Enabled() bool {
+	return d.enabled.Load()
+}
+
+func (d *DebugLogging) SetEnabled(enabled bool) {
+	d.enabled.Store(enabled)
+}
+
+// DebugTransport logs the method, URL, status and bodies of the requests to
+// a VCS host while debug logging is enabled. Credentials are redacted from
+// URLs and bodies and headers aren't logged at all. Each request and its
+// response are logged with the same request ID so they can be matched up.
+type DebugTransport struct {
+	// Transport is the underlying transport. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	Host      models.VCSHostType
+	Debug     *DebugLogging
+	Logger    logging.SimpleLogging
+}
+
+func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := transportOrDefault(t.Transport)
+	if !t.Debug.Enabled() {
+		return transport.RoundTrip(req)
+	}
+
+	logger := t.Logger.With("vcs-host", t.Host.String(), "vcs-request-id", uuid.NewString())
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		// GetBody returns a copy so the request body isn't consumed.
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(io.LimitReader(body, debugBodyLimit)) // nolint: errcheck
+			body.Close()                                                  // nolint: errcheck
+		}
+	}
+	logger.Info("VCS request: %s %s %s", req.Method, redactURL(req.URL), redactBody(reqBody))
+
+	start := time.Now()
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		logger.Info("VCS request failed after %s: %s", time.Since(start), err)
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close() // nolint: errcheck
+	// Replace the body since it was consumed.
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		logger.Info("VCS response: %s after %s, reading body failed: %s", resp.Status, time.Since(start), err)
+		return resp, nil
+	}
+	if len(respBody) > debugBodyLimit {
+		respBody = respBody[:debugBodyLimit]
+	}
+	logger.Info("VCS response: %s after %s %s", resp.Status, time.Since(start), redactBody(respBody))
+	return resp, nil
+}
+
+// redactURL returns u with its password and sensitive query parameters
+// redacted.
+func redactURL(u *url.URL) string {
+	redactedURL := *u
+	if _, ok := redactedURL.User.Password(); ok {
+		redactedURL.User = url.UserPassword(redactedURL.User.Username(), redacted)
+	}
+	query := redactedURL.Query()
+	for name := range query {
+		if sensitiveNameRegex.MatchString(name) {
+			query.Set(name, redacted)
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.String()
+}
+
+// redactBody returns body with the values of sensitive JSON string fields
+// redacted. Bodies that aren't JSON are replaced entirely if they mention a
+// sensitive name since their format is unknown.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	trimmed := strings.TrimSpace(string(body))
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		if sensitiveNameRegex.MatchString(trimmed) {
+			return redacted
+		}
+		return trimmed
+	}
+	return jsonStringFieldRegex.ReplaceAllStringFunc(trimmed, func(field string) string {
+		match := jsonStringFieldRegex.FindStringSubmatch(field)
+		if !sensitiveNameRegex.MatchString(match[1]) {
+			return field
+		}
+		return `"` + match[1] + `"` + match[2] + `"` + redacted + `"`
+	})
+}
@@ -0,0 +1,43 @@
+package vcs
+
+import (
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// DryRunClient passes reads through to the underlying client but only logs
+// writes like comments, statuses and merges. It lets a staging Atlantis
+// process production webhooks without changing pull requests.
+type DryRunClient struct {
+	Client
+}
+
+func (c *DryRunClient) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, command string) error {
+	logger.Info("[dry-run] would comment on %s#%d for command %q:\n%s", repo.FullName, pullNum, command, comment)
+	return nil
+}
+
+func (c *DryRunClient) ReactToComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, commentID int64, reaction string) error {
+	logger.Info("[dry-run] would react with %q to comment %d on %s#%d", reaction, commentID, repo.FullName, pullNum)
+	return nil
+}
+
+func (c *DryRunClient) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error {
+	logger.Info("[dry-run] would hide previous %q comments for dir %q on %s#%d", command, dir, repo.FullName, pullNum)
+	return nil
+}
+
+func (c *DryRunClient) UpdateStatus(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
+	logger.Info("[dry-run] would set status %q to %s on %s#%d: %s", src, state.String(), repo.FullName, pull.Num, description)
+	return nil
+}
+
+func (c *DryRunClient) DiscardReviews(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) error {
+	logger.Info("[dry-run] would discard reviews on %s#%d", repo.FullName, pull.Num)
+	return nil
+}
+
+func (c *DryRunClient) MergePull(logger logging.SimpleLogging, pull models.PullRequest, _ models.PullRequestOptions) error {
+	logger.Info("[dry-run] would merge %s#%d", pull.BaseRepo.FullName, pull.Num)
+	return nil
+}
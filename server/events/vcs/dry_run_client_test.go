@@ -0,0 +1,41 @@
+package vcs_test
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDryRunClient(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	underlying := mocks.NewMockClient()
+	repo := models.Repo{FullName: "owner/repo"}
+	pull := models.PullRequest{Num: 1, BaseRepo: repo}
+	When(underlying.GetModifiedFiles(Any[logging.SimpleLogging](), Eq(repo), Eq(pull))).ThenReturn([]string{"main.tf"}, nil)
+	client := &vcs.DryRunClient{Client: underlying}
+
+	// Reads go through.
+	files, err := client.GetModifiedFiles(logger, repo, pull)
+	Ok(t, err)
+	Equals(t, []string{"main.tf"}, files)
+
+	// Writes don't.
+	Ok(t, client.CreateComment(logger, repo, 1, "comment", "plan"))
+	Ok(t, client.ReactToComment(logger, repo, 1, 2, "eyes"))
+	Ok(t, client.HidePrevCommandComments(logger, repo, 1, "plan", ""))
+	Ok(t, client.UpdateStatus(logger, repo, pull, models.SuccessCommitStatus, "atlantis/plan", "Plan succeeded.", ""))
+	Ok(t, client.DiscardReviews(logger, repo, pull))
+	Ok(t, client.MergePull(logger, pull, models.PullRequestOptions{}))
+	underlying.VerifyWasCalled(Never()).CreateComment(Any[logging.SimpleLogging](), Any[models.Repo](), Any[int](), Any[string](), Any[string]())
+	underlying.VerifyWasCalled(Never()).ReactToComment(Any[logging.SimpleLogging](), Any[models.Repo](), Any[int](), Any[int64](), Any[string]())
+	underlying.VerifyWasCalled(Never()).HidePrevCommandComments(Any[logging.SimpleLogging](), Any[models.Repo](), Any[int](), Any[string](), Any[string]())
+	underlying.VerifyWasCalled(Never()).UpdateStatus(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](), Any[models.CommitStatus](), Any[string](), Any[string](), Any[string]())
+	underlying.VerifyWasCalled(Never()).DiscardReviews(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest]())
+	underlying.VerifyWasCalled(Never()).MergePull(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.PullRequestOptions]())
+}
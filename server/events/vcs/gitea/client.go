@@ -18,12 +18,14 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"code.gitea.io/sdk/gitea"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -38,6 +40,9 @@ type GiteaClient struct {
 	token       string
 	pageSize    int
 	ctx         context.Context
+	// CommentSplit configures splitting long comments. Comments aren't split
+	// unless its MaxSize is set.
+	CommentSplit common.SplitConfig
 }
 
 type GiteaPRReviewSummary struct {
@@ -60,10 +65,11 @@ type GiteaPullGetter interface {
 // client to use to make the requests, username and password are used as basic
 // auth in the requests, baseURL is the API's baseURL, ex. https://corp.com:7990.
 // Don't include the API version, ex. '/1.0'.
-func NewClient(baseURL string, username string, token string, pagesize int, logger logging.SimpleLogging) (*GiteaClient, error) {
+func NewClient(httpClient *http.Client, baseURL string, username string, token string, pagesize int, logger logging.SimpleLogging) (*GiteaClient, error) {
 	logger.Debug("Creating new Gitea client for: %s", baseURL)
 
 	giteaClient, err := gitea.NewClient(baseURL,
+		gitea.SetHTTPClient(httpClient),
 		gitea.SetToken(token),
 		gitea.SetUserAgent("atlantis"),
 	)
@@ -110,7 +116,7 @@ func (c *GiteaClient) GetModifiedFiles(logger logging.SimpleLogging, repo models
 	}
 
 	for page < nextPage {
-		page = +1
+		page++
 		listOptions.ListOptions.Page = page
 		files, resp, err := c.giteaClient.ListPullRequestFiles(repo.Owner, repo.Name, int64(pull.Num), listOptions)
 		if err != nil {
@@ -133,19 +139,33 @@ func (c *GiteaClient) GetModifiedFiles(logger logging.SimpleLogging, repo models
 	return changedFiles, nil
 }
 
-// CreateComment creates a comment on the merge request. As far as we're aware, Gitea has no built in max comment length right now.
+// CreateComment creates a comment on the merge request. As far as we're aware, Gitea has no built in max comment length right now
+// so comments are only split if a max size is configured.
 func (c *GiteaClient) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, command string) error {
 	logger.Debug("Creating comment on Gitea pull request %d", pullNum)
 
-	opt := gitea.CreateIssueCommentOption{
-		Body: comment,
+	comments := []string{comment}
+	if c.CommentSplit.MaxSize > 0 {
+		split := c.CommentSplit.WithDefaults(common.SplitConfig{
+			SepEnd: "\n```\n</details>" +
+				"\n<br>\n\n**Warning**: Output length greater than max comment size. Continued in next comment.",
+			SepStart: "Continued from previous comment.\n<details><summary>Show Output</summary>\n\n" +
+				"```diff\n",
+		})
+		comments = common.SplitComment(comment, split.MaxSize, split.SepEnd, split.SepStart, 0, "")
 	}
 
-	_, resp, err := c.giteaClient.CreateIssueComment(repo.Owner, repo.Name, int64(pullNum), opt)
+	for _, body := range comments {
+		opt := gitea.CreateIssueCommentOption{
+			Body: body,
+		}
 
-	if err != nil {
-		logger.Debug("POST /repos/%v/%v/issues/%d/comments returned: %v", repo.Owner, repo.Name, pullNum, resp.StatusCode)
-		return err
+		_, resp, err := c.giteaClient.CreateIssueComment(repo.Owner, repo.Name, int64(pullNum), opt)
+
+		if err != nil {
+			logger.Debug("POST /repos/%v/%v/issues/%d/comments returned: %v", repo.Owner, repo.Name, pullNum, resp.StatusCode)
+			return err
+		}
 	}
 
 	logger.Debug("Added comment to Gitea pull request %d: %s", pullNum, comment)
@@ -215,7 +235,16 @@ func (c *GiteaClient) HidePrevCommandComments(logger logging.SimpleLogging, repo
 		}
 
 		body := strings.Split(comment.Body, "\n")
-		if len(body) == 0 || (!strings.Contains(strings.ToLower(body[0]), strings.ToLower(command)) && dir != "" && !strings.Contains(strings.ToLower(body[0]), strings.ToLower(dir))) {
+		if len(body) == 0 {
+			continue
+		}
+		firstLine := strings.ToLower(body[0])
+		// Skip comments that don't contain the command or were already hidden
+		if !strings.Contains(firstLine, strings.ToLower(command)) || firstLine == strings.ToLower(summaryHeader) {
+			continue
+		}
+		// If dir was specified, skip comments that don't contain the dir in the first line
+		if dir != "" && !strings.Contains(firstLine, strings.ToLower(dir)) {
 			continue
 		}
 
@@ -252,7 +281,7 @@ func (c *GiteaClient) PullIsApproved(logger logging.SimpleLogging, repo models.R
 	}
 
 	for page < nextPage {
-		page = +1
+		page++
 		listOptions.ListOptions.Page = page
 		pullReviews, resp, err := c.giteaClient.ListPullReviews(repo.Owner, repo.Name, int64(pull.Num), listOptions)
 
@@ -353,7 +382,7 @@ func (c *GiteaClient) DiscardReviews(_ logging.SimpleLogging, repo models.Repo,
 	}
 
 	for page < nextPage {
-		page = +1
+		page++
 		listOptions.ListOptions.Page = page
 		pullReviews, resp, err := c.giteaClient.ListPullReviews(repo.Owner, repo.Name, int64(pull.Num), listOptions)
 
@@ -413,9 +442,53 @@ func (c *GiteaClient) MarkdownPullLink(pull models.PullRequest) (string, error)
 }
 
 // GetTeamNamesForUser returns the names of the teams or groups that the user belongs to (in the organization the repository belongs to).
-func (c *GiteaClient) GetTeamNamesForUser(_ logging.SimpleLogging, _ models.Repo, _ models.User) ([]string, error) {
-	// TODO: implement
-	return nil, errors.New("GetTeamNamesForUser not (yet) implemented for Gitea client")
+// Repositories owned by a user rather than an organization don't have teams.
+func (c *GiteaClient) GetTeamNamesForUser(logger logging.SimpleLogging, repo models.Repo, user models.User) ([]string, error) {
+	logger.Debug("Getting teams of %s in Gitea organization %s", user.Username, repo.Owner)
+
+	page := 0
+	nextPage := 1
+	teamNames := make([]string, 0)
+
+	opts := gitea.ListTeamsOptions{
+		ListOptions: gitea.ListOptions{
+			Page:     1,
+			PageSize: c.pageSize,
+		},
+	}
+
+	for page < nextPage {
+		page++
+		opts.ListOptions.Page = page
+
+		teams, resp, err := c.giteaClient.ListOrgTeams(repo.Owner, opts)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return teamNames, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing teams of organization %s", repo.Owner)
+		}
+
+		for _, team := range teams {
+			_, resp, err := c.giteaClient.GetTeamMember(team.ID, user.Username)
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "checking membership of %s in team %s", user.Username, team.Name)
+			}
+			teamNames = append(teamNames, team.Name)
+		}
+
+		nextPage = resp.NextPage
+
+		// Emergency break after giteaPaginationEBreak pages
+		if page >= giteaPaginationEBreak {
+			break
+		}
+	}
+
+	return teamNames, nil
 }
 
 // GetFileContent a repository file content from VCS (which support fetch a single file from repository)
@@ -479,7 +552,7 @@ func (c *GiteaClient) GetPullLabels(logger logging.SimpleLogging, repo models.Re
 	}
 
 	for page < nextPage {
-		page = +1
+		page++
 		opts.ListOptions.Page = page
 
 		labels, resp, err := c.giteaClient.GetIssueLabels(repo.Owner, repo.Name, int64(pull.Num), opts)
@@ -0,0 +1,123 @@
+package vcs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// UpdateCheckRun reports state as a check run named name on the head commit
+// of pull, the way UpdateStatus reports it as a commit status. The latest check
+// run with that name is updated if there is one. Check runs can only be
+// created when authenticated as a GitHub App.
+func (g *GithubClient) UpdateCheckRun(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, name string, description string, url string) error {
+	status := "completed"
+	var conclusion *string
+	switch state {
+	case models.PendingCommitStatus:
+		status = "in_progress"
+	case models.SuccessCommitStatus:
+		conclusion = github.Ptr("success")
+	default:
+		conclusion = github.Ptr("failure")
+	}
+	output := &github.CheckRunOutput{
+		Title:   github.Ptr(name),
+		Summary: github.Ptr(description),
+	}
+	var detailsURL *string
+	if url != "" {
+		detailsURL = github.Ptr(url)
+	}
+
+	logger.Info("Updating GitHub check run '%s' to '%s'", name, status)
+	existing, _, err := g.client.Checks.ListCheckRunsForRef(g.ctx, repo.Owner, repo.Name, pull.HeadCommit, &github.ListCheckRunsOptions{
+		CheckName: github.Ptr(name),
+		Filter:    github.Ptr("latest"),
+	})
+	if err != nil {
+		return fmt.Errorf("listing check runs: %w", err)
+	}
+	if len(existing.CheckRuns) > 0 {
+		_, resp, err := g.client.Checks.UpdateCheckRun(g.ctx, repo.Owner, repo.Name, existing.CheckRuns[0].GetID(), github.UpdateCheckRunOptions{
+			Name:       name,
+			DetailsURL: detailsURL,
+			Status:     github.Ptr(status),
+			Conclusion: conclusion,
+			Output:     output,
+		})
+		if resp != nil {
+			logger.Debug("PATCH /repos/%v/%v/check-runs/%d returned: %v", repo.Owner, repo.Name, existing.CheckRuns[0].GetID(), resp.StatusCode)
+		}
+		return err
+	}
+	_, resp, err := g.client.Checks.CreateCheckRun(g.ctx, repo.Owner, repo.Name, github.CreateCheckRunOptions{
+		Name:       name,
+		HeadSHA:    pull.HeadCommit,
+		DetailsURL: detailsURL,
+		Status:     github.Ptr(status),
+		Conclusion: conclusion,
+		Output:     output,
+	})
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/check-runs returned: %v", repo.Owner, repo.Name, resp.StatusCode)
+	}
+	return err
+}
+
+// RequiredStatusCheck is a check the branch protection of a branch requires
+// to pass before pull requests can be merged.
+type RequiredStatusCheck struct {
+	Branch  string
+	Context string
+	// AppID is the ID of the GitHub App that must provide the check. It's nil
+	// if GitHub picks the app that recently provided it and -1 if any source
+	// can provide it.
+	AppID *int64
+}
+
+// ListRequiredStatusChecks returns the checks required by the branch
+// protection of the protected branches of repo.
+func (g *GithubClient) ListRequiredStatusChecks(logger logging.SimpleLogging, repo models.Repo) ([]RequiredStatusCheck, error) {
+	logger.Debug("Listing required status checks of GitHub repo %s", repo.FullName)
+	opts := &github.BranchListOptions{
+		Protected:   github.Ptr(true),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var checks []RequiredStatusCheck
+	for {
+		branches, resp, err := g.client.Repositories.ListBranches(g.ctx, repo.Owner, repo.Name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("listing protected branches: %w", err)
+		}
+		for _, branch := range branches {
+			protection, _, err := g.client.Repositories.GetBranchProtection(g.ctx, repo.Owner, repo.Name, branch.GetName())
+			if errors.Is(err, github.ErrBranchNotProtected) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("getting protection of branch %s: %w", branch.GetName(), err)
+			}
+			required := protection.GetRequiredStatusChecks()
+			if required == nil {
+				continue
+			}
+			if required.Checks != nil {
+				for _, check := range *required.Checks {
+					checks = append(checks, RequiredStatusCheck{Branch: branch.GetName(), Context: check.Context, AppID: check.AppID})
+				}
+			} else if required.Contexts != nil {
+				for _, context := range *required.Contexts {
+					checks = append(checks, RequiredStatusCheck{Branch: branch.GetName(), Context: context})
+				}
+			}
+		}
+		if resp.NextPage == 0 {
+			return checks, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
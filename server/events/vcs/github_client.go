@@ -87,6 +87,8 @@ type GithubClient struct {
 	config                GithubConfig
 	maxCommentsPerCommand int
 	repoIdCache           GitHubRepoIdCache
+	// CommentSplit overrides how long comments are split.
+	CommentSplit common.SplitConfig
 }
 
 // GithubAppTemporarySecrets holds app credentials obtained from github after creation.
@@ -249,7 +251,16 @@ func (g *GithubClient) CreateComment(logger logging.SimpleLogging, repo models.R
 		"> **Warning**: Command output is larger than the maximum number of comments per command. Output truncated.\n<details><summary>Show Output</summary>\n\n" +
 		"```diff\n"
 
-	comments := common.SplitComment(comment, maxCommentLength, sepEnd, sepStart, g.maxCommentsPerCommand, truncationHeader)
+	split := g.CommentSplit.WithDefaults(common.SplitConfig{MaxSize: maxCommentLength, SepEnd: sepEnd, SepStart: sepStart})
+	if split.Upload && len(comment) > split.MaxSize {
+		gistURL, err := g.createGist(logger, repo, pullNum, comment, command)
+		if err != nil {
+			logger.Warn("unable to upload output as a gist, splitting it into several comments: %s", err)
+		} else {
+			comment = common.UploadSummary(comment, gistURL, "gist", split.MaxSize)
+		}
+	}
+	comments := common.SplitComment(comment, split.MaxSize, split.SepEnd, split.SepStart, g.maxCommentsPerCommand, truncationHeader)
 	for i := range comments {
 		_, resp, err := g.client.Issues.CreateComment(g.ctx, repo.Owner, repo.Name, pullNum, &github.IssueComment{Body: &comments[i]})
 		if resp != nil {
@@ -262,6 +273,71 @@ func (g *GithubClient) CreateComment(logger logging.SimpleLogging, repo models.R
 	return nil
 }
 
+// CreateUpdatableComment creates comment on the pull request and returns its
+// ID.
+func (g *GithubClient) CreateUpdatableComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string) (int64, error) {
+	logger.Debug("Creating updatable comment on GitHub pull request %d", pullNum)
+	created, resp, err := g.client.Issues.CreateComment(g.ctx, repo.Owner, repo.Name, pullNum, &github.IssueComment{Body: &comment})
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/issues/%d/comments returned: %v", repo.Owner, repo.Name, pullNum, resp.StatusCode)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return created.GetID(), nil
+}
+
+// UpdateComment replaces the body of the comment commentID.
+func (g *GithubClient) UpdateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, commentID int64, comment string) error {
+	logger.Debug("Updating comment %d on GitHub pull request %d", commentID, pullNum)
+	_, resp, err := g.client.Issues.EditComment(g.ctx, repo.Owner, repo.Name, commentID, &github.IssueComment{Body: &comment})
+	if resp != nil {
+		logger.Debug("PATCH /repos/%v/%v/issues/comments/%d returned: %v", repo.Owner, repo.Name, commentID, resp.StatusCode)
+	}
+	return err
+}
+
+// CreateReviewComment comments on line of the file at path in the head
+// commit of pull.
+func (g *GithubClient) CreateReviewComment(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, path string, line int, comment string) error {
+	logger.Debug("Creating review comment on %s:%d of GitHub pull request %d", path, line, pull.Num)
+	_, resp, err := g.client.PullRequests.CreateComment(g.ctx, repo.Owner, repo.Name, pull.Num, &github.PullRequestComment{
+		Body:     github.Ptr(comment),
+		CommitID: github.Ptr(pull.HeadCommit),
+		Path:     github.Ptr(path),
+		Line:     github.Ptr(line),
+		Side:     github.Ptr("RIGHT"),
+	})
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/pulls/%d/comments returned: %v", repo.Owner, repo.Name, pull.Num, resp.StatusCode)
+	}
+	return err
+}
+
+// createGist uploads comment as a secret gist and returns its URL. Secret
+// gists aren't listed but anyone with the URL can read them. GitHub Apps
+// can't create gists.
+func (g *GithubClient) createGist(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, command string) (string, error) {
+	description := fmt.Sprintf("Atlantis output for %s#%d", repo.FullName, pullNum)
+	if command != "" {
+		description = fmt.Sprintf("Atlantis %s output for %s#%d", command, repo.FullName, pullNum)
+	}
+	gist, resp, err := g.client.Gists.Create(g.ctx, &github.Gist{
+		Description: &description,
+		Public:      github.Ptr(false),
+		Files: map[github.GistFilename]github.GistFile{
+			"atlantis-output.md": {Content: &comment},
+		},
+	})
+	if resp != nil {
+		logger.Debug("POST /gists returned: %v", resp.StatusCode)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "creating gist")
+	}
+	return gist.GetHTMLURL(), nil
+}
+
 // ReactToComment adds a reaction to a comment.
 func (g *GithubClient) ReactToComment(logger logging.SimpleLogging, repo models.Repo, _ int, commentID int64, reaction string) error {
 	logger.Debug("Adding reaction to GitHub pull request comment %d", commentID)
@@ -413,12 +489,20 @@ func (g *GithubClient) PullIsApproved(logger logging.SimpleLogging, repo models.
 			return approvalStatus, errors.Wrap(err, "getting reviews")
 		}
 		for _, review := range pageReviews {
-			if review != nil && review.GetState() == "APPROVED" {
-				return models.ApprovalStatus{
+			if review == nil || review.GetState() != "APPROVED" {
+				continue
+			}
+			login := review.GetUser().GetLogin()
+			if !approvalStatus.IsApproved {
+				approvalStatus = models.ApprovalStatus{
 					IsApproved: true,
-					ApprovedBy: *review.User.Login,
-					Date:       review.SubmittedAt.Time,
-				}, nil
+					ApprovedBy: login,
+					Date:       review.GetSubmittedAt().Time,
+				}
+			}
+			// Users who approved more than once count once.
+			if !slices.Contains(approvalStatus.Approvers, login) {
+				approvalStatus.Approvers = append(approvalStatus.Approvers, login)
 			}
 		}
 		if resp.NextPage == 0 {
@@ -908,6 +992,16 @@ func (g *GithubClient) GetPullRequest(logger logging.SimpleLogging, repo models.
 	return pull, err
 }
 
+// GetBranchHeadCommit returns the SHA of the commit at the head of branch.
+func (g *GithubClient) GetBranchHeadCommit(logger logging.SimpleLogging, repo models.Repo, branch string) (string, error) {
+	logger.Debug("Getting head commit of GitHub branch %s", branch)
+	sha, resp, err := g.client.Repositories.GetCommitSHA1(g.ctx, repo.Owner, repo.Name, branch, "")
+	if resp != nil {
+		logger.Debug("GET /repos/%v/%v/commits/%s returned: %v", repo.Owner, repo.Name, branch, resp.StatusCode)
+	}
+	return sha, err
+}
+
 // UpdateStatus updates the status badge on the pull request.
 // See https://github.com/blog/1227-commit-status-api.
 func (g *GithubClient) UpdateStatus(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
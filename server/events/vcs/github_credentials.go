@@ -23,11 +23,14 @@ type GithubCredentials interface {
 }
 
 // GithubAnonymousCredentials expose no credentials.
-type GithubAnonymousCredentials struct{}
+type GithubAnonymousCredentials struct {
+	// Transport is the underlying transport. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
 
 // Client returns a client with no credentials.
 func (c *GithubAnonymousCredentials) Client() (*http.Client, error) {
-	tr := http.DefaultTransport
+	tr := transportOrDefault(c.Transport)
 	return &http.Client{Transport: tr}, nil
 }
 
@@ -46,6 +49,8 @@ type GithubUserCredentials struct {
 	User      string
 	Token     string
 	TokenFile string
+	// Transport is the underlying transport. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
 }
 
 type GitHubUserTransport struct {
@@ -76,8 +81,9 @@ func (c *GithubUserCredentials) Client() (*http.Client, error) {
 		Transport: &GitHubUserTransport{
 			Credentials: c,
 			Transport: &github.BasicAuthTransport{
-				Username: strings.TrimSpace(c.User),
-				Password: strings.TrimSpace(password),
+				Username:  strings.TrimSpace(c.User),
+				Password:  strings.TrimSpace(password),
+				Transport: c.Transport,
 			},
 		},
 	}
@@ -112,6 +118,8 @@ type GithubAppCredentials struct {
 	InstallationID int64
 	tr             *ghinstallation.Transport
 	AppSlug        string
+	// Transport is the underlying transport. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
 }
 
 // Client returns a github app installation client.
@@ -164,7 +172,7 @@ func (c *GithubAppCredentials) getInstallationID() (int64, error) {
 		return c.InstallationID, nil
 	}
 
-	tr := http.DefaultTransport
+	tr := transportOrDefault(c.Transport)
 	// A non-installation transport
 	t, err := ghinstallation.NewAppsTransport(tr, c.AppID, c.Key)
 	if err != nil {
@@ -200,7 +208,7 @@ func (c *GithubAppCredentials) transport() (*ghinstallation.Transport, error) {
 		return nil, err
 	}
 
-	tr := http.DefaultTransport
+	tr := transportOrDefault(c.Transport)
 	itr, err := ghinstallation.New(tr, c.AppID, installationID, c.Key)
 	if err == nil {
 		apiURL := c.getAPIURL()
@@ -0,0 +1,30 @@
+package vcs
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// ListReposWithTopic returns the full names of the repos of owner, a user or
+// an org, that have topic. Archived repos are left out.
+func (g *GithubClient) ListReposWithTopic(logger logging.SimpleLogging, owner string, topic string) ([]string, error) {
+	logger.Debug("Listing GitHub repos of %s with topic %s", owner, topic)
+	query := fmt.Sprintf("user:%s topic:%s archived:false", owner, topic)
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var repos []string
+	for {
+		result, resp, err := g.client.Search.Repositories(g.ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("searching repos: %w", err)
+		}
+		for _, repo := range result.Repositories {
+			repos = append(repos, repo.GetFullName())
+		}
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
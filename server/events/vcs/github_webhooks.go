@@ -0,0 +1,144 @@
+package vcs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// GithubWebhookEvents are the events Atlantis needs its GitHub webhooks to
+// send.
+var GithubWebhookEvents = []string{"issue_comment", "pull_request", "pull_request_review", "push"}
+
+// Webhook is the config of a webhook that sends events to Atlantis.
+type Webhook struct {
+	// URL is where events are sent, ex. https://atlantis.example.com/events.
+	URL string
+	// Secret signs the events.
+	Secret string
+	// Events are the events the webhook must send. It may send others.
+	Events []string
+}
+
+// githubHooks lists, creates and edits the webhooks of a repo or an org.
+type githubHooks struct {
+	list   func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error)
+	create func(hook *github.Hook) (*github.Hook, *github.Response, error)
+	edit   func(id int64, hook *github.Hook) (*github.Hook, *github.Response, error)
+}
+
+// EnsureRepoWebhook checks that the repo owner/repo has a webhook that sends
+// the events of hook to hook.URL and returns its discrepancies. If fix is
+// true, the webhook is created or updated to fix them.
+func (g *GithubClient) EnsureRepoWebhook(logger logging.SimpleLogging, owner string, repo string, hook Webhook, fix bool) ([]string, error) {
+	logger.Debug("Checking webhook of GitHub repo %s/%s", owner, repo)
+	return g.ensureWebhook(hook, fix, githubHooks{
+		list: func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+			return g.client.Repositories.ListHooks(g.ctx, owner, repo, opts)
+		},
+		create: func(h *github.Hook) (*github.Hook, *github.Response, error) {
+			return g.client.Repositories.CreateHook(g.ctx, owner, repo, h)
+		},
+		edit: func(id int64, h *github.Hook) (*github.Hook, *github.Response, error) {
+			return g.client.Repositories.EditHook(g.ctx, owner, repo, id, h)
+		},
+	})
+}
+
+// EnsureOrgWebhook is like EnsureRepoWebhook for a webhook of the org, which
+// sends the events of all its repos.
+func (g *GithubClient) EnsureOrgWebhook(logger logging.SimpleLogging, org string, hook Webhook, fix bool) ([]string, error) {
+	logger.Debug("Checking webhook of GitHub org %s", org)
+	return g.ensureWebhook(hook, fix, githubHooks{
+		list: func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+			return g.client.Organizations.ListHooks(g.ctx, org, opts)
+		},
+		create: func(h *github.Hook) (*github.Hook, *github.Response, error) {
+			return g.client.Organizations.CreateHook(g.ctx, org, h)
+		},
+		edit: func(id int64, h *github.Hook) (*github.Hook, *github.Response, error) {
+			return g.client.Organizations.EditHook(g.ctx, org, id, h)
+		},
+	})
+}
+
+func (g *GithubClient) ensureWebhook(hook Webhook, fix bool, hooks githubHooks) ([]string, error) {
+	existing, err := findGithubHook(hook.URL, hooks)
+	if err != nil {
+		return nil, err
+	}
+
+	want := &github.Hook{
+		Config: &github.HookConfig{
+			URL:         github.Ptr(hook.URL),
+			ContentType: github.Ptr("json"),
+			Secret:      github.Ptr(hook.Secret),
+		},
+		Events: hook.Events,
+		Active: github.Ptr(true),
+	}
+
+	if existing == nil {
+		discrepancies := []string{fmt.Sprintf("no webhook sends events to %s", hook.URL)}
+		if fix {
+			if _, _, err := hooks.create(want); err != nil {
+				return discrepancies, fmt.Errorf("creating webhook: %w", err)
+			}
+		}
+		return discrepancies, nil
+	}
+
+	var discrepancies []string
+	if !existing.GetActive() {
+		discrepancies = append(discrepancies, "webhook is inactive")
+	}
+	if contentType := existing.GetConfig().GetContentType(); contentType != "json" {
+		discrepancies = append(discrepancies, fmt.Sprintf("webhook content type is %q instead of \"json\"", contentType))
+	}
+	var missing []string
+	for _, event := range hook.Events {
+		if !slices.Contains(existing.Events, event) && !slices.Contains(existing.Events, "*") {
+			missing = append(missing, event)
+		}
+	}
+	if len(missing) > 0 {
+		discrepancies = append(discrepancies, fmt.Sprintf("webhook doesn't send %s events", strings.Join(missing, ", ")))
+		// Keep the events it already sends.
+		for _, event := range existing.Events {
+			if !slices.Contains(want.Events, event) {
+				want.Events = append(want.Events, event)
+			}
+		}
+	}
+
+	if len(discrepancies) > 0 && fix {
+		if _, _, err := hooks.edit(existing.GetID(), want); err != nil {
+			return discrepancies, fmt.Errorf("updating webhook %d: %w", existing.GetID(), err)
+		}
+	}
+	return discrepancies, nil
+}
+
+// findGithubHook returns the webhook that sends events to url or nil if there
+// isn't one.
+func findGithubHook(url string, hooks githubHooks) (*github.Hook, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := hooks.list(opts)
+		if err != nil {
+			return nil, fmt.Errorf("listing webhooks: %w", err)
+		}
+		for _, h := range page {
+			if h.GetConfig().GetURL() == url {
+				return h, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,108 @@
+package vcs_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestGithubClient_EnsureRepoWebhook(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	hook := vcs.Webhook{
+		URL:    "https://atlantis.example.com/events",
+		Secret: "secret",
+		Events: vcs.GithubWebhookEvents,
+	}
+
+	cases := []struct {
+		description      string
+		existing         string
+		fix              bool
+		expDiscrepancies []string
+		expMethod        string
+		expEvents        []string
+	}{
+		{
+			description: "webhook is up to date",
+			existing: `[{"id": 1, "active": true, "events": ["issue_comment", "pull_request", "pull_request_review", "push"],
+				"config": {"url": "https://atlantis.example.com/events", "content_type": "json"}}]`,
+			fix: true,
+		},
+		{
+			description:      "missing webhook is reported",
+			existing:         `[{"id": 1, "active": true, "events": ["*"], "config": {"url": "https://other.example.com"}}]`,
+			expDiscrepancies: []string{"no webhook sends events to https://atlantis.example.com/events"},
+		},
+		{
+			description:      "missing webhook is created",
+			existing:         `[]`,
+			fix:              true,
+			expDiscrepancies: []string{"no webhook sends events to https://atlantis.example.com/events"},
+			expMethod:        "POST",
+			expEvents:        vcs.GithubWebhookEvents,
+		},
+		{
+			description: "outdated webhook is updated",
+			existing: `[{"id": 1, "active": false, "events": ["push", "status"],
+				"config": {"url": "https://atlantis.example.com/events", "content_type": "form"}}]`,
+			fix: true,
+			expDiscrepancies: []string{
+				"webhook is inactive",
+				`webhook content type is "form" instead of "json"`,
+				"webhook doesn't send issue_comment, pull_request, pull_request_review events",
+			},
+			expMethod: "PATCH",
+			expEvents: []string{"issue_comment", "pull_request", "pull_request_review", "push", "status"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var gotMethod string
+			var gotHook struct {
+				Events []string          `json:"events"`
+				Active bool              `json:"active"`
+				Config map[string]string `json:"config"`
+			}
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.Method + " " + r.RequestURI {
+					case "GET /api/v3/repos/owner/repo/hooks?per_page=100":
+						w.Write([]byte(c.existing)) // nolint: errcheck
+					case "POST /api/v3/repos/owner/repo/hooks", "PATCH /api/v3/repos/owner/repo/hooks/1":
+						gotMethod = r.Method
+						body, err := io.ReadAll(r.Body)
+						Ok(t, err)
+						Ok(t, json.Unmarshal(body, &gotHook))
+						w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+					default:
+						t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+			defer testServer.Close()
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass", "", nil}, vcs.GithubConfig{}, 0, logger)
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			discrepancies, err := client.EnsureRepoWebhook(logger, "owner", "repo", hook, c.fix)
+			Ok(t, err)
+			Equals(t, c.expDiscrepancies, discrepancies)
+			Equals(t, c.expMethod, gotMethod)
+			if c.expMethod != "" {
+				Equals(t, c.expEvents, gotHook.Events)
+				Equals(t, true, gotHook.Active)
+				Equals(t, map[string]string{"url": hook.URL, "content_type": "json", "secret": "secret"}, gotHook.Config)
+			}
+		})
+	}
+}
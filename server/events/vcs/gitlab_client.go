@@ -19,6 +19,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -43,10 +44,15 @@ type GitlabClient struct {
 	Version *version.Version
 	// All GitLab groups configured in allowlists and policies
 	ConfiguredGroups []string
+	// PolicyGroups, if set, returns the groups of the policy owners, which
+	// are checked along with ConfiguredGroups.
+	PolicyGroups func() []string
 	// PollingInterval is the time between successive polls, where applicable.
 	PollingInterval time.Duration
 	// PollingInterval is the total duration for which to poll, where applicable.
 	PollingTimeout time.Duration
+	// CommentSplit overrides how long comments are split.
+	CommentSplit common.SplitConfig
 }
 
 // commonMarkSupported is a version constraint that is true when this version of
@@ -57,8 +63,9 @@ var commonMarkSupported = MustConstraint(">=11.1")
 // gitlabClientUnderTest is true if we're running under go test.
 var gitlabClientUnderTest = false
 
-// NewGitlabClient returns a valid GitLab client.
-func NewGitlabClient(hostname string, token string, configuredGroups []string, logger logging.SimpleLogging) (*GitlabClient, error) {
+// NewGitlabClient returns a valid GitLab client. If transport is nil,
+// http.DefaultTransport is used.
+func NewGitlabClient(hostname string, token string, configuredGroups []string, transport http.RoundTripper, logger logging.SimpleLogging) (*GitlabClient, error) {
 	logger.Debug("Creating new GitLab client for %s", hostname)
 	client := &GitlabClient{
 		ConfiguredGroups: configuredGroups,
@@ -66,9 +73,14 @@ func NewGitlabClient(hostname string, token string, configuredGroups []string, l
 		PollingTimeout:   time.Second * 30,
 	}
 
+	var options []gitlab.ClientOptionFunc
+	if transport != nil {
+		options = append(options, gitlab.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+
 	// Create the client differently depending on the base URL.
 	if hostname == "gitlab.com" {
-		glClient, err := gitlab.NewClient(token)
+		glClient, err := gitlab.NewClient(token, options...)
 		if err != nil {
 			return nil, err
 		}
@@ -97,7 +109,7 @@ func NewGitlabClient(hostname string, token string, configuredGroups []string, l
 		// Now we're ready to construct the client.
 		absoluteURL = strings.TrimSuffix(absoluteURL, "/")
 		apiURL := fmt.Sprintf("%s/api/v4/", absoluteURL)
-		glClient, err := gitlab.NewClient(token, gitlab.WithBaseURL(apiURL))
+		glClient, err := gitlab.NewClient(token, append(options, gitlab.WithBaseURL(apiURL))...)
 		if err != nil {
 			return nil, err
 		}
@@ -180,7 +192,16 @@ func (g *GitlabClient) CreateComment(logger logging.SimpleLogging, repo models.R
 		"\n<br>\n\n**Warning**: Output length greater than max comment size. Continued in next comment."
 	sepStart := "Continued from previous comment.\n<details><summary>Show Output</summary>\n\n" +
 		"```diff\n"
-	comments := common.SplitComment(comment, gitlabMaxCommentLength, sepEnd, sepStart, 0, "")
+	split := g.CommentSplit.WithDefaults(common.SplitConfig{MaxSize: gitlabMaxCommentLength, SepEnd: sepEnd, SepStart: sepStart})
+	if split.Upload && len(comment) > split.MaxSize {
+		snippetURL, err := g.createSnippet(logger, repo, pullNum, comment)
+		if err != nil {
+			logger.Warn("unable to upload output as a snippet, splitting it into several comments: %s", err)
+		} else {
+			comment = common.UploadSummary(comment, snippetURL, "snippet", split.MaxSize)
+		}
+	}
+	comments := common.SplitComment(comment, split.MaxSize, split.SepEnd, split.SepStart, 0, "")
 	for _, c := range comments {
 		_, resp, err := g.Client.Notes.CreateMergeRequestNote(repo.FullName, pullNum, &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.Ptr(c)})
 		if resp != nil {
@@ -193,6 +214,74 @@ func (g *GitlabClient) CreateComment(logger logging.SimpleLogging, repo models.R
 	return nil
 }
 
+// CreateUpdatableComment creates comment on the merge request and returns
+// its ID.
+func (g *GitlabClient) CreateUpdatableComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string) (int64, error) {
+	logger.Debug("Creating updatable comment on GitLab merge request %d", pullNum)
+	note, resp, err := g.Client.Notes.CreateMergeRequestNote(repo.FullName, pullNum, &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.Ptr(comment)})
+	if resp != nil {
+		logger.Debug("POST /projects/%s/merge_requests/%d/notes returned: %d", repo.FullName, pullNum, resp.StatusCode)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(note.ID), nil
+}
+
+// UpdateComment replaces the body of the comment commentID.
+func (g *GitlabClient) UpdateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, commentID int64, comment string) error {
+	logger.Debug("Updating comment %d on GitLab merge request %d", commentID, pullNum)
+	_, resp, err := g.Client.Notes.UpdateMergeRequestNote(repo.FullName, pullNum, int(commentID), &gitlab.UpdateMergeRequestNoteOptions{Body: gitlab.Ptr(comment)})
+	if resp != nil {
+		logger.Debug("PUT /projects/%s/merge_requests/%d/notes/%d returned: %d", repo.FullName, pullNum, commentID, resp.StatusCode)
+	}
+	return err
+}
+
+// CreateReviewComment starts a discussion on line of the file at path in the
+// latest version of the merge request's diff.
+func (g *GitlabClient) CreateReviewComment(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, path string, line int, comment string) error {
+	logger.Debug("Creating review comment on %s:%d of GitLab merge request %d", path, line, pull.Num)
+	mr, err := g.GetMergeRequest(logger, repo.FullName, pull.Num)
+	if err != nil {
+		return err
+	}
+	_, resp, err := g.Client.Discussions.CreateMergeRequestDiscussion(repo.FullName, pull.Num, &gitlab.CreateMergeRequestDiscussionOptions{
+		Body: gitlab.Ptr(comment),
+		Position: &gitlab.PositionOptions{
+			BaseSHA:      gitlab.Ptr(mr.DiffRefs.BaseSha),
+			StartSHA:     gitlab.Ptr(mr.DiffRefs.StartSha),
+			HeadSHA:      gitlab.Ptr(mr.DiffRefs.HeadSha),
+			PositionType: gitlab.Ptr("text"),
+			NewPath:      gitlab.Ptr(path),
+			OldPath:      gitlab.Ptr(path),
+			NewLine:      gitlab.Ptr(line),
+		},
+	})
+	if resp != nil {
+		logger.Debug("POST /projects/%s/merge_requests/%d/discussions returned: %d", repo.FullName, pull.Num, resp.StatusCode)
+	}
+	return err
+}
+
+// createSnippet uploads comment as a private snippet of the project, which
+// only its members can read, and returns its URL.
+func (g *GitlabClient) createSnippet(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string) (string, error) {
+	snippet, resp, err := g.Client.ProjectSnippets.CreateSnippet(repo.FullName, &gitlab.CreateProjectSnippetOptions{
+		Title:      gitlab.Ptr(fmt.Sprintf("Atlantis output for !%d", pullNum)),
+		FileName:   gitlab.Ptr("atlantis-output.md"),
+		Content:    gitlab.Ptr(comment),
+		Visibility: gitlab.Ptr(gitlab.PrivateVisibility),
+	})
+	if resp != nil {
+		logger.Debug("POST /projects/%s/snippets returned: %d", repo.FullName, resp.StatusCode)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "creating snippet")
+	}
+	return snippet.WebURL, nil
+}
+
 // ReactToComment adds a reaction to a comment.
 func (g *GitlabClient) ReactToComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, commentID int64, reaction string) error {
 	logger.Debug("Adding reaction '%s' to comment %d on GitLab merge request %d", reaction, commentID, pullNum)
@@ -655,7 +744,13 @@ func (g *GitlabClient) GetTeamNamesForUser(logger logging.SimpleLogging, _ model
 		return nil, errors.New("GET /users returned more than 1 user")
 	}
 	userID := users[0].ID
-	for _, groupName := range g.ConfiguredGroups {
+	groupNames := slices.Clone(g.ConfiguredGroups)
+	if g.PolicyGroups != nil {
+		groupNames = append(groupNames, g.PolicyGroups()...)
+		slices.Sort(groupNames)
+		groupNames = slices.Compact(groupNames)
+	}
+	for _, groupName := range groupNames {
 		membership, resp, err := g.Client.GroupMembers.GetGroupMember(groupName, userID)
 		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
 			continue
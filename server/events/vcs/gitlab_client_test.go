@@ -94,7 +94,7 @@ func TestNewGitlabClient_BaseURL(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.Hostname, func(t *testing.T) {
 			log := logging.NewNoopLogger(t)
-			client, err := NewGitlabClient(c.Hostname, "token", []string{}, log)
+			client, err := NewGitlabClient(c.Hostname, "token", []string{}, nil, log)
 			Ok(t, err)
 			Equals(t, c.ExpBaseURL, client.Client.BaseURL().String())
 		})
@@ -887,7 +887,7 @@ func TestGitlabClient_MarkdownPullLink(t *testing.T) {
 	logger := logging.NewNoopLogger(t)
 	gitlabClientUnderTest = true
 	defer func() { gitlabClientUnderTest = false }()
-	client, err := NewGitlabClient("gitlab.com", "token", []string{}, logger)
+	client, err := NewGitlabClient("gitlab.com", "token", []string{}, nil, logger)
 	Ok(t, err)
 	pull := models.PullRequest{Num: 1}
 	s, _ := client.MarkdownPullLink(pull)
@@ -1112,6 +1112,66 @@ func TestGitlabClient_GetPullLabels_EmptyResponse(t *testing.T) {
 }
 
 // GetTeamNamesForUser returns the names of the GitLab groups that the user belongs to.
+func TestGitlabClient_CreateReviewComment(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	mergeSuccess, err := os.ReadFile("testdata/gitlab-merge-success.json")
+	Ok(t, err)
+	var discussion struct {
+		Body     string `json:"body"`
+		Position struct {
+			BaseSHA      string `json:"base_sha"`
+			StartSHA     string `json:"start_sha"`
+			HeadSHA      string `json:"head_sha"`
+			PositionType string `json:"position_type"`
+			NewPath      string `json:"new_path"`
+			NewLine      int    `json:"new_line"`
+		} `json:"position"`
+	}
+
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.RequestURI {
+			case "GET /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1":
+				w.WriteHeader(http.StatusOK)
+				w.Write(mergeSuccess) // nolint: errcheck
+			case "POST /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/discussions":
+				Ok(t, json.NewDecoder(r.Body).Decode(&discussion))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id": "abc"}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+	Ok(t, err)
+	client := &GitlabClient{
+		Client:  internalClient,
+		Version: nil,
+	}
+
+	err = client.CreateReviewComment(
+		logger,
+		models.Repo{
+			FullName: "runatlantis/atlantis",
+		},
+		models.PullRequest{
+			Num: 1,
+		},
+		"dir/main.tf",
+		3,
+		"comment",
+	)
+	Ok(t, err)
+	Equals(t, "comment", discussion.Body)
+	Equals(t, "text", discussion.Position.PositionType)
+	Equals(t, "dir/main.tf", discussion.Position.NewPath)
+	Equals(t, 3, discussion.Position.NewLine)
+	Equals(t, "67cb91d3f6198189f433c045154a885784ba6977", discussion.Position.BaseSHA)
+	Equals(t, "cb86d70f464632bdfbe1bb9bc0f2f9d847a774a0", discussion.Position.HeadSHA)
+}
+
 func TestGitlabClient_GetTeamNamesForUser(t *testing.T) {
 	logger := logging.NewNoopLogger(t)
 
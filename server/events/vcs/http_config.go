@@ -0,0 +1,125 @@
+package vcs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// tlsVersions maps the TLS versions that can be set as a minimum to their
+// crypto/tls constants.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// HTTPConfig configures the HTTP client used to call a single VCS host. It
+// lets a host be reached through its own proxy and trust its own CA without
+// changing the environment of the whole process, which Terraform inherits.
+type HTTPConfig struct {
+	// ProxyURL is the HTTP(S) proxy to call the host through, ex.
+	// http://proxy.corp:3128. If empty, the proxy environment variables are
+	// used.
+	ProxyURL string `json:"proxy-url"`
+	// CABundle is the path to a PEM file of CA certificates that are
+	// trusted in addition to the system's.
+	CABundle string `json:"ca-bundle"`
+	// TLSMinVersion is the minimum TLS version, 1.2 or 1.3.
+	TLSMinVersion string `json:"tls-min-version"`
+	// ClientCert and ClientKey are the paths to the PEM certificate and key
+	// presented to hosts that require mutual TLS. Both or neither must be set.
+	ClientCert string `json:"client-cert"`
+	ClientKey  string `json:"client-key"`
+}
+
+// Validate returns an error if the proxy URL, TLS version or client
+// certificate settings are invalid.
+func (c HTTPConfig) Validate() error {
+	if c.ProxyURL != "" {
+		u, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return errors.Wrapf(err, "parsing proxy-url %q", c.ProxyURL)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("proxy-url %q must use http or https", c.ProxyURL)
+		}
+	}
+	if _, ok := tlsVersions[c.TLSMinVersion]; c.TLSMinVersion != "" && !ok {
+		return fmt.Errorf("tls-min-version %q is not supported, use 1.2 or 1.3", c.TLSMinVersion)
+	}
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return errors.New("client-cert and client-key must be set together")
+	}
+	return nil
+}
+
+// Transport returns the transport to call the host with. If nothing is
+// configured, it returns nil so clients fall back to http.DefaultTransport.
+func (c HTTPConfig) Transport() (http.RoundTripper, error) {
+	if c == (HTTPConfig{}) {
+		return nil, nil
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.ProxyURL != "" {
+		proxyURL, _ := url.Parse(c.ProxyURL) // nolint: errcheck
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSMinVersion != "" {
+		tlsConfig.MinVersion = tlsVersions[c.TLSMinVersion]
+	}
+	if c.CABundle != "" {
+		pem, err := os.ReadFile(c.CABundle)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading ca-bundle %q", c.CABundle)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca-bundle %q contains no PEM certificates", c.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading client-cert %q and client-key %q", c.ClientCert, c.ClientKey)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// Client returns an HTTP client that uses Transport. If nothing is
+// configured, it returns http.DefaultClient.
+func (c HTTPConfig) Client() (*http.Client, error) {
+	transport, err := c.Transport()
+	if err != nil {
+		return nil, err
+	}
+	if transport == nil {
+		return http.DefaultClient, nil
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// transportOrDefault returns transport or http.DefaultTransport if it's nil.
+func transportOrDefault(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		return http.DefaultTransport
+	}
+	return transport
+}
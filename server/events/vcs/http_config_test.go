@@ -0,0 +1,89 @@
+package vcs_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestHTTPConfig_TransportEmpty(t *testing.T) {
+	transport, err := vcs.HTTPConfig{}.Transport()
+	Ok(t, err)
+	Assert(t, transport == nil, "expected a nil transport, got %v", transport)
+
+	client, err := vcs.HTTPConfig{}.Client()
+	Ok(t, err)
+	Assert(t, client == http.DefaultClient, "expected http.DefaultClient")
+}
+
+func TestHTTPConfig_Transport(t *testing.T) {
+	transport, err := vcs.HTTPConfig{
+		ProxyURL:      "http://proxy.corp:3128",
+		TLSMinVersion: "1.3",
+	}.Transport()
+	Ok(t, err)
+
+	httpTransport := transport.(*http.Transport)
+	Equals(t, uint16(tls.VersionTLS13), httpTransport.TLSClientConfig.MinVersion)
+	req, _ := http.NewRequest("GET", "https://bitbucket.corp/rest/api/1.0/projects", nil)
+	proxyURL, err := httpTransport.Proxy(req)
+	Ok(t, err)
+	Equals(t, "http://proxy.corp:3128", proxyURL.String())
+}
+
+func TestHTTPConfig_TransportCABundle(t *testing.T) {
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	Ok(t, os.WriteFile(caBundle, []byte("not a certificate"), 0600))
+
+	_, err := vcs.HTTPConfig{CABundle: caBundle}.Transport()
+	ErrEquals(t, "ca-bundle \""+caBundle+"\" contains no PEM certificates", err)
+
+	_, err = vcs.HTTPConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")}.Transport()
+	ErrContains(t, "reading ca-bundle", err)
+}
+
+func TestHTTPConfig_TransportClientCert(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ok(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "atlantis"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Ok(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Ok(t, err)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	Ok(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	Ok(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	transport, err := vcs.HTTPConfig{ClientCert: certFile, ClientKey: keyFile}.Transport()
+	Ok(t, err)
+	certs := transport.(*http.Transport).TLSClientConfig.Certificates
+	Equals(t, 1, len(certs))
+	Equals(t, der, certs[0].Certificate[0])
+
+	_, err = vcs.HTTPConfig{ClientCert: certFile, ClientKey: filepath.Join(dir, "missing.key")}.Transport()
+	ErrContains(t, "loading client-cert", err)
+
+	_, err = vcs.HTTPConfig{ClientCert: certFile}.Transport()
+	ErrEquals(t, "client-cert and client-key must be set together", err)
+}
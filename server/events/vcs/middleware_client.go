@@ -0,0 +1,246 @@
+package vcs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/metrics"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// MiddlewareConfig configures the behaviour shared by every VCS client.
+type MiddlewareConfig struct {
+	// MaxRetries is the number of times a failed read is retried. Writes,
+	// ex. creating a comment, aren't retried since they may have succeeded.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry. It doubles after every
+	// attempt.
+	RetryDelay time.Duration
+	// BreakerThreshold is the number of consecutive failures after which the
+	// circuit breaker opens. If 0, the circuit breaker is disabled.
+	BreakerThreshold int
+	// BreakerCooldown is how long the circuit breaker stays open before
+	// calls are let through again.
+	BreakerCooldown time.Duration
+}
+
+// MiddlewareClient wraps the client of a single VCS host. It records the
+// latency and errors of every endpoint, retries failed reads and stops
+// calling the host while it's erroring so requests fail fast.
+type MiddlewareClient struct {
+	Client
+	Host   models.VCSHostType
+	config MiddlewareConfig
+	scope  tally.Scope
+
+	// now and sleep are overridden in tests.
+	now   func() time.Time
+	sleep func(time.Duration)
+
+	mutex               sync.Mutex
+	consecutiveFailures int
+	openUntil           time.Time
+}
+
+func NewMiddlewareClient(client Client, host models.VCSHostType, config MiddlewareConfig, statsScope tally.Scope) *MiddlewareClient {
+	return &MiddlewareClient{
+		Client: client,
+		Host:   host,
+		config: config,
+		scope:  statsScope.SubScope("vcs").Tagged(map[string]string{"host": host.String()}),
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// Degraded returns true if the host has failed at least BreakerThreshold
+// times in a row.
+func (c *MiddlewareClient) Degraded() bool {
+	if c.config.BreakerThreshold <= 0 {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.consecutiveFailures >= c.config.BreakerThreshold
+}
+
+// call runs fn, recording metrics under endpoint. If retry is true, fn is
+// retried up to MaxRetries times.
+func (c *MiddlewareClient) call(logger logging.SimpleLogging, endpoint string, retry bool, fn func() error) error {
+	scope := c.scope.SubScope(endpoint)
+	executionTime := scope.Timer(metrics.ExecutionTimeMetric).Start()
+	defer executionTime.Stop()
+
+	if err := c.allow(); err != nil {
+		scope.Counter(metrics.ExecutionErrorMetric).Inc(1)
+		return err
+	}
+
+	attempts := 1
+	if retry {
+		attempts += c.config.MaxRetries
+	}
+	delay := c.config.RetryDelay
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			break
+		}
+		if attempt < attempts {
+			logger.Debug("%s %s failed, retrying in %s: %s", c.Host, endpoint, delay, err)
+			scope.Counter(metrics.ExecutionRetryMetric).Inc(1)
+			c.sleep(delay)
+			delay *= 2
+		}
+	}
+	c.record(err)
+
+	if err != nil {
+		scope.Counter(metrics.ExecutionErrorMetric).Inc(1)
+		return err
+	}
+	scope.Counter(metrics.ExecutionSuccessMetric).Inc(1)
+	return nil
+}
+
+// allow returns an error if the circuit breaker is open.
+func (c *MiddlewareClient) allow() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.now().Before(c.openUntil) {
+		return fmt.Errorf("%s is degraded, not calling it until %s after %d consecutive failures", c.Host, c.openUntil.Format(time.RFC3339), c.consecutiveFailures)
+	}
+	return nil
+}
+
+// record updates the circuit breaker with the result of a call. Once the
+// cooldown passes, calls are let through again and the next failure reopens
+// the breaker while a success closes it.
+func (c *MiddlewareClient) record(err error) {
+	if c.config.BreakerThreshold <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err == nil {
+		c.consecutiveFailures = 0
+		return
+	}
+	c.consecutiveFailures++
+	if c.consecutiveFailures >= c.config.BreakerThreshold {
+		c.openUntil = c.now().Add(c.config.BreakerCooldown)
+		c.scope.Counter("circuit_opened").Inc(1)
+	}
+}
+
+func (c *MiddlewareClient) GetModifiedFiles(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	var files []string
+	err := c.call(logger, "get_modified_files", true, func() error {
+		var err error
+		files, err = c.Client.GetModifiedFiles(logger, repo, pull)
+		return err
+	})
+	return files, err
+}
+
+func (c *MiddlewareClient) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, command string) error {
+	return c.call(logger, "create_comment", false, func() error {
+		return c.Client.CreateComment(logger, repo, pullNum, comment, command)
+	})
+}
+
+func (c *MiddlewareClient) ReactToComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, commentID int64, reaction string) error {
+	return c.call(logger, "react_to_comment", false, func() error {
+		return c.Client.ReactToComment(logger, repo, pullNum, commentID, reaction)
+	})
+}
+
+func (c *MiddlewareClient) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error {
+	return c.call(logger, "hide_prev_command_comments", false, func() error {
+		return c.Client.HidePrevCommandComments(logger, repo, pullNum, command, dir)
+	})
+}
+
+func (c *MiddlewareClient) PullIsApproved(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) (models.ApprovalStatus, error) {
+	var status models.ApprovalStatus
+	err := c.call(logger, "pull_is_approved", true, func() error {
+		var err error
+		status, err = c.Client.PullIsApproved(logger, repo, pull)
+		return err
+	})
+	return status, err
+}
+
+func (c *MiddlewareClient) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, vcsstatusname string, ignoreVCSStatusNames []string) (bool, error) {
+	var mergeable bool
+	err := c.call(logger, "pull_is_mergeable", true, func() error {
+		var err error
+		mergeable, err = c.Client.PullIsMergeable(logger, repo, pull, vcsstatusname, ignoreVCSStatusNames)
+		return err
+	})
+	return mergeable, err
+}
+
+// UpdateStatus is retried since setting a status to the same state twice is
+// harmless.
+func (c *MiddlewareClient) UpdateStatus(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
+	return c.call(logger, "update_status", true, func() error {
+		return c.Client.UpdateStatus(logger, repo, pull, state, src, description, url)
+	})
+}
+
+func (c *MiddlewareClient) DiscardReviews(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) error {
+	return c.call(logger, "discard_reviews", false, func() error {
+		return c.Client.DiscardReviews(logger, repo, pull)
+	})
+}
+
+func (c *MiddlewareClient) MergePull(logger logging.SimpleLogging, pull models.PullRequest, pullOptions models.PullRequestOptions) error {
+	return c.call(logger, "merge_pull", false, func() error {
+		return c.Client.MergePull(logger, pull, pullOptions)
+	})
+}
+
+func (c *MiddlewareClient) GetTeamNamesForUser(logger logging.SimpleLogging, repo models.Repo, user models.User) ([]string, error) {
+	var teams []string
+	err := c.call(logger, "get_team_names_for_user", true, func() error {
+		var err error
+		teams, err = c.Client.GetTeamNamesForUser(logger, repo, user)
+		return err
+	})
+	return teams, err
+}
+
+func (c *MiddlewareClient) GetFileContent(logger logging.SimpleLogging, pull models.PullRequest, fileName string) (bool, []byte, error) {
+	var found bool
+	var content []byte
+	err := c.call(logger, "get_file_content", true, func() error {
+		var err error
+		found, content, err = c.Client.GetFileContent(logger, pull, fileName)
+		return err
+	})
+	return found, content, err
+}
+
+func (c *MiddlewareClient) GetCloneURL(logger logging.SimpleLogging, VCSHostType models.VCSHostType, repo string) (string, error) {
+	var url string
+	err := c.call(logger, "get_clone_url", true, func() error {
+		var err error
+		url, err = c.Client.GetCloneURL(logger, VCSHostType, repo)
+		return err
+	})
+	return url, err
+}
+
+func (c *MiddlewareClient) GetPullLabels(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	var labels []string
+	err := c.call(logger, "get_pull_labels", true, func() error {
+		var err error
+		labels, err = c.Client.GetPullLabels(logger, repo, pull)
+		return err
+	})
+	return labels, err
+}
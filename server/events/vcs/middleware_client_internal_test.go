@@ -0,0 +1,113 @@
+package vcs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// flakyClient fails the first failures calls.
+type flakyClient struct {
+	NotConfiguredVCSClient
+	failures int
+	calls    int
+}
+
+func (f *flakyClient) GetModifiedFiles(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest) ([]string, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("bad gateway")
+	}
+	return []string{"main.tf"}, nil
+}
+
+func (f *flakyClient) CreateComment(_ logging.SimpleLogging, _ models.Repo, _ int, _ string, _ string) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("bad gateway")
+	}
+	return nil
+}
+
+func newTestMiddlewareClient(client Client, config MiddlewareConfig) (*MiddlewareClient, *time.Time, *[]time.Duration) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	middlewareClient := NewMiddlewareClient(client, models.Github, config, tally.NoopScope)
+	middlewareClient.now = func() time.Time { return now }
+	middlewareClient.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	return middlewareClient, &now, &sleeps
+}
+
+func TestMiddlewareClient_RetriesReads(t *testing.T) {
+	client := &flakyClient{failures: 2}
+	middlewareClient, _, sleeps := newTestMiddlewareClient(client, MiddlewareConfig{
+		MaxRetries: 3,
+		RetryDelay: time.Second,
+	})
+
+	files, err := middlewareClient.GetModifiedFiles(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{})
+	Ok(t, err)
+	Equals(t, []string{"main.tf"}, files)
+	Equals(t, 3, client.calls)
+	Equals(t, []time.Duration{time.Second, 2 * time.Second}, *sleeps)
+}
+
+func TestMiddlewareClient_RetriesExhausted(t *testing.T) {
+	client := &flakyClient{failures: 5}
+	middlewareClient, _, _ := newTestMiddlewareClient(client, MiddlewareConfig{MaxRetries: 2})
+
+	_, err := middlewareClient.GetModifiedFiles(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{})
+	ErrEquals(t, "bad gateway", err)
+	Equals(t, 3, client.calls)
+}
+
+func TestMiddlewareClient_DoesNotRetryWrites(t *testing.T) {
+	client := &flakyClient{failures: 1}
+	middlewareClient, _, _ := newTestMiddlewareClient(client, MiddlewareConfig{MaxRetries: 3})
+
+	err := middlewareClient.CreateComment(logging.NewNoopLogger(t), models.Repo{}, 1, "comment", "plan")
+	ErrEquals(t, "bad gateway", err)
+	Equals(t, 1, client.calls)
+}
+
+func TestMiddlewareClient_CircuitBreaker(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	client := &flakyClient{failures: 3}
+	middlewareClient, now, _ := newTestMiddlewareClient(client, MiddlewareConfig{
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	})
+
+	_, err := middlewareClient.GetModifiedFiles(logger, models.Repo{}, models.PullRequest{})
+	ErrEquals(t, "bad gateway", err)
+	Equals(t, false, middlewareClient.Degraded())
+
+	_, err = middlewareClient.GetModifiedFiles(logger, models.Repo{}, models.PullRequest{})
+	ErrEquals(t, "bad gateway", err)
+	Equals(t, true, middlewareClient.Degraded())
+
+	// While the breaker is open the host isn't called.
+	_, err = middlewareClient.GetModifiedFiles(logger, models.Repo{}, models.PullRequest{})
+	ErrEquals(t, "Github is degraded, not calling it until 2024-01-01T00:01:00Z after 2 consecutive failures", err)
+	Equals(t, 2, client.calls)
+
+	// After the cooldown a failure reopens the breaker.
+	*now = now.Add(time.Minute)
+	_, err = middlewareClient.GetModifiedFiles(logger, models.Repo{}, models.PullRequest{})
+	ErrEquals(t, "bad gateway", err)
+	_, err = middlewareClient.GetModifiedFiles(logger, models.Repo{}, models.PullRequest{})
+	ErrContains(t, "Github is degraded", err)
+	Equals(t, 3, client.calls)
+
+	// And a success closes it.
+	*now = now.Add(time.Minute)
+	files, err := middlewareClient.GetModifiedFiles(logger, models.Repo{}, models.PullRequest{})
+	Ok(t, err)
+	Equals(t, []string{"main.tf"}, files)
+	Equals(t, false, middlewareClient.Degraded())
+}
@@ -0,0 +1,94 @@
+package vcs
+
+import (
+	"cmp"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// Translator replaces the English phrases of comments and statuses with
+// their translations.
+type Translator struct {
+	replacer *strings.Replacer
+}
+
+// NewTranslator returns a translator that replaces each key of translations
+// with its value. Longer phrases are replaced first so a phrase can be
+// translated differently from the phrases it contains.
+func NewTranslator(translations map[string]string) *Translator {
+	phrases := make([]string, 0, len(translations))
+	for phrase := range translations {
+		if phrase != "" {
+			phrases = append(phrases, phrase)
+		}
+	}
+	slices.SortFunc(phrases, func(a, b string) int {
+		if c := cmp.Compare(len(b), len(a)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	oldnew := make([]string, 0, 2*len(phrases))
+	for _, phrase := range phrases {
+		oldnew = append(oldnew, phrase, translations[phrase])
+	}
+	return &Translator{replacer: strings.NewReplacer(oldnew...)}
+}
+
+// LoadTranslator reads the translations from the YAML file at path. The file
+// maps the phrases to replace to their translations, ex.
+//
+//	"Plan Failed": "Plan fehlgeschlagen"
+func LoadTranslator(path string) (*Translator, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading translations")
+	}
+	var translations map[string]string
+	if err := yaml.Unmarshal(raw, &translations); err != nil {
+		return nil, errors.Wrapf(err, "parsing translations %s", path)
+	}
+	return NewTranslator(translations), nil
+}
+
+// Translate returns s with its phrases translated.
+func (t *Translator) Translate(s string) string {
+	return t.replacer.Replace(s)
+}
+
+// TranslatingClient translates the comments and commit status descriptions
+// sent by the underlying client.
+type TranslatingClient struct {
+	Client
+	Translator *Translator
+}
+
+func (c *TranslatingClient) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, command string) error {
+	return c.Client.CreateComment(logger, repo, pullNum, c.Translator.Translate(comment), command)
+}
+
+func (c *TranslatingClient) UpdateStatus(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
+	return c.Client.UpdateStatus(logger, repo, pull, state, src, c.Translator.Translate(description), url)
+}
+
+// CheckRunClient reports the status of commands as GitHub check runs.
+type CheckRunClient interface {
+	UpdateCheckRun(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, name string, description string, url string) error
+}
+
+// TranslatingCheckRunClient translates the descriptions of the check runs
+// sent by the underlying client.
+type TranslatingCheckRunClient struct {
+	CheckRunClient
+	Translator *Translator
+}
+
+func (c *TranslatingCheckRunClient) UpdateCheckRun(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, name string, description string, url string) error {
+	return c.CheckRunClient.UpdateCheckRun(logger, repo, pull, state, name, c.Translator.Translate(description), url)
+}
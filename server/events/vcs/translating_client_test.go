@@ -0,0 +1,59 @@
+package vcs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestTranslator_Translate(t *testing.T) {
+	translator := vcs.NewTranslator(map[string]string{
+		"Plan":        "Plan",
+		"Plan Failed": "Plan fehlgeschlagen",
+		"Ran":         "Ausgeführt:",
+		"":            "ignored",
+	})
+	Equals(t, "Ausgeführt: Plan for dir: `.`", translator.Translate("Ran Plan for dir: `.`"))
+	Equals(t, "**Plan fehlgeschlagen**: locked", translator.Translate("**Plan Failed**: locked"))
+	Equals(t, "unchanged", translator.Translate("unchanged"))
+}
+
+func TestLoadTranslator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "translations.yaml")
+	Ok(t, os.WriteFile(path, []byte("\"Plan Failed\": Plan fehlgeschlagen\nView help.: Hilfe anzeigen.\n"), 0600))
+	translator, err := vcs.LoadTranslator(path)
+	Ok(t, err)
+	Equals(t, "help     Hilfe anzeigen.", translator.Translate("help     View help."))
+
+	Ok(t, os.WriteFile(path, []byte("- not a map\n"), 0600))
+	_, err = vcs.LoadTranslator(path)
+	ErrContains(t, "parsing translations", err)
+
+	_, err = vcs.LoadTranslator(filepath.Join(t.TempDir(), "missing.yaml"))
+	ErrContains(t, "reading translations", err)
+}
+
+func TestTranslatingClient(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	underlying := mocks.NewMockClient()
+	repo := models.Repo{FullName: "owner/repo"}
+	pull := models.PullRequest{Num: 1, BaseRepo: repo}
+	client := &vcs.TranslatingClient{
+		Client:     underlying,
+		Translator: vcs.NewTranslator(map[string]string{"Plan Failed": "Plan fehlgeschlagen", "Plan succeeded.": "Plan erfolgreich."}),
+	}
+
+	Ok(t, client.CreateComment(logger, repo, 1, "**Plan Failed**: locked", "plan"))
+	underlying.VerifyWasCalledOnce().CreateComment(logger, repo, 1, "**Plan fehlgeschlagen**: locked", "plan")
+
+	Ok(t, client.UpdateStatus(logger, repo, pull, models.SuccessCommitStatus, "atlantis/plan", "Plan succeeded.", "https://atlantis"))
+	underlying.VerifyWasCalledOnce().UpdateStatus(logger, repo, pull, models.SuccessCommitStatus, "atlantis/plan", "Plan erfolgreich.", "https://atlantis")
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// WebhookRegistrationReport only reports webhook discrepancies.
+	WebhookRegistrationReport = "report"
+	// WebhookRegistrationSync creates and updates webhooks to fix their
+	// discrepancies.
+	WebhookRegistrationSync = "sync"
+)
+
+// GithubWebhookClient checks and fixes the webhooks of GitHub repos and orgs.
+type GithubWebhookClient interface {
+	EnsureRepoWebhook(logger logging.SimpleLogging, owner string, repo string, hook vcs.Webhook, fix bool) ([]string, error)
+	EnsureOrgWebhook(logger logging.SimpleLogging, org string, hook vcs.Webhook, fix bool) ([]string, error)
+}
+
+// WebhookRegistrar checks that the repos and orgs in the repo allowlist have
+// webhooks that send events to Atlantis.
+type WebhookRegistrar struct {
+	Logger logging.SimpleLogging
+	Client GithubWebhookClient
+	// Hostname is the GitHub hostname. Allowlist rules for other hosts are
+	// skipped.
+	Hostname string
+	// Allowlist is the value of --repo-allowlist.
+	Allowlist string
+	Webhook   vcs.Webhook
+	// Mode is WebhookRegistrationReport or WebhookRegistrationSync.
+	Mode string
+}
+
+// Register checks the webhook of each repo and org in the allowlist and logs
+// their discrepancies. In sync mode the webhooks are also created or updated.
+// It returns the number of repos and orgs whose webhook had discrepancies.
+//
+// Rules of the form host/owner/repo are checked on the repo and rules of the
+// form host/owner/* on the org. Other rules with wildcards, topic rules and
+// omit rules can't be mapped to a webhook and are skipped.
+func (w *WebhookRegistrar) Register() int {
+	fix := w.Mode == WebhookRegistrationSync
+	withDiscrepancies := 0
+	for _, rule := range strings.Split(w.Allowlist, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" || strings.HasPrefix(rule, "!") {
+			continue
+		}
+		if strings.Contains(rule, topicPrefix) {
+			w.Logger.Debug("skipping webhook registration for allowlist rule %q since its repos are discovered, use an org webhook", rule)
+			continue
+		}
+		parts := strings.Split(rule, "/")
+		if len(parts) != 3 || parts[0] != w.Hostname || strings.Contains(parts[0]+parts[1], Wildcard) {
+			w.Logger.Debug("skipping webhook registration for allowlist rule %q since it isn't a %s repo or org", rule, w.Hostname)
+			continue
+		}
+		owner, repo := parts[1], parts[2]
+
+		var discrepancies []string
+		var err error
+		target := owner + "/" + repo
+		switch {
+		case repo == Wildcard:
+			target = owner
+			discrepancies, err = w.Client.EnsureOrgWebhook(w.Logger, owner, w.Webhook, fix)
+		case strings.Contains(repo, Wildcard):
+			w.Logger.Debug("skipping webhook registration for allowlist rule %q since it matches part of an org", rule)
+			continue
+		default:
+			discrepancies, err = w.Client.EnsureRepoWebhook(w.Logger, owner, repo, w.Webhook, fix)
+		}
+
+		if len(discrepancies) > 0 {
+			withDiscrepancies++
+			verb := "found"
+			if fix && err == nil {
+				verb = "fixed"
+			}
+			w.Logger.Warn("%s webhook discrepancies for %s: %s", verb, target, strings.Join(discrepancies, "; "))
+		}
+		if err != nil {
+			w.Logger.Err("registering webhook for %s: %s", target, err)
+		}
+	}
+	return withDiscrepancies
+}
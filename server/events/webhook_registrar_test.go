@@ -0,0 +1,53 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeWebhookClient records the webhooks it's asked to check and reports a
+// discrepancy for each.
+type fakeWebhookClient struct {
+	checked []string
+	fix     bool
+}
+
+func (f *fakeWebhookClient) EnsureRepoWebhook(_ logging.SimpleLogging, owner string, repo string, _ vcs.Webhook, fix bool) ([]string, error) {
+	f.checked = append(f.checked, "repo:"+owner+"/"+repo)
+	f.fix = fix
+	if repo == "broken" {
+		return nil, errors.New("forbidden")
+	}
+	return []string{"webhook is inactive"}, nil
+}
+
+func (f *fakeWebhookClient) EnsureOrgWebhook(_ logging.SimpleLogging, org string, _ vcs.Webhook, fix bool) ([]string, error) {
+	f.checked = append(f.checked, "org:"+org)
+	f.fix = fix
+	return nil, nil
+}
+
+func TestWebhookRegistrar_Register(t *testing.T) {
+	client := &fakeWebhookClient{}
+	registrar := &events.WebhookRegistrar{
+		Logger:   logging.NewNoopLogger(t),
+		Client:   client,
+		Hostname: "github.com",
+		Allowlist: "github.com/owner/repo,github.com/org/*,!github.com/org/secret,github.com/owner/broken," +
+			"github.com/owner/prefix-*,github.com/*,gitlab.com/owner/repo,github.com/owner/topic:terraform,*",
+		Mode: events.WebhookRegistrationSync,
+	}
+
+	Equals(t, 1, registrar.Register())
+	Equals(t, []string{"repo:owner/repo", "org:org", "repo:owner/broken"}, client.checked)
+	Equals(t, true, client.fix)
+
+	registrar.Mode = events.WebhookRegistrationReport
+	registrar.Register()
+	Equals(t, false, client.fix)
+}
@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// DefaultDatadogURL is the API of the US1 Datadog site.
+const DefaultDatadogURL = "https://api.datadoghq.com"
+
+// datadogEventsPath is the path of the Datadog API to post events.
+const datadogEventsPath = "/api/v1/events"
+
+// DatadogClient posts events to the Datadog API.
+type DatadogClient struct {
+	Client *http.Client
+	APIKey string
+}
+
+// DatadogWebhook sends a deployment event to Datadog for each apply.
+type DatadogWebhook struct {
+	Client         *DatadogClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	// URL is the API of the Datadog site, ex. https://api.datadoghq.eu.
+	URL string
+}
+
+// datadogEvent is the body of a request to post an event.
+type datadogEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	AlertType      string   `json:"alert_type"`
+	AggregationKey string   `json:"aggregation_key"`
+	SourceTypeName string   `json:"source_type_name"`
+	Tags           []string `json:"tags"`
+}
+
+// Send posts an event to Datadog if workspace and branch matches their
+// respective regex.
+func (d *DatadogWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !d.WorkspaceRegex.MatchString(applyResult.Workspace) || !d.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	if err := d.doSend(applyResult); err != nil {
+		return errors.Wrap(err, "sending event to Datadog")
+	}
+	return nil
+}
+
+func (d *DatadogWebhook) doSend(applyResult ApplyResult) error {
+	body, err := json.Marshal(newDatadogEvent(applyResult))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(d.URL, "/")+datadogEventsPath, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", d.Client.APIKey)
+	resp, err := d.Client.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+func newDatadogEvent(applyResult ApplyResult) datadogEvent {
+	project := applyResult.ProjectName
+	if project == "" {
+		project = applyResult.Directory
+	}
+	status, alertType := "succeeded", "success"
+	if !applyResult.Success {
+		status, alertType = "failed", "error"
+	}
+	tags := []string{
+		"repo:" + applyResult.Repo.FullName,
+		"pull:" + strconv.Itoa(applyResult.Pull.Num),
+		"workspace:" + applyResult.Workspace,
+		"dir:" + applyResult.Directory,
+		"branch:" + applyResult.Pull.BaseBranch,
+		"user:" + applyResult.User.Username,
+	}
+	if applyResult.ProjectName != "" {
+		tags = append(tags, "project:"+applyResult.ProjectName)
+	}
+	return datadogEvent{
+		Title: fmt.Sprintf("Atlantis apply of %s %s in %s", project, status, applyResult.Repo.FullName),
+		Text: fmt.Sprintf("@%s applied %s in workspace %s of %s from %s",
+			applyResult.User.Username, project, applyResult.Workspace, applyResult.Repo.FullName, applyResult.Pull.URL),
+		AlertType: alertType,
+		// Groups the events of the applies of a pull request.
+		AggregationKey: fmt.Sprintf("%s#%d", applyResult.Repo.FullName, applyResult.Pull.Num),
+		SourceTypeName: "atlantis",
+		Tags:           tags,
+	}
+}
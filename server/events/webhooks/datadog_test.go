@@ -0,0 +1,73 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDatadogWebhook(t *testing.T) {
+	var event map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "/api/v1/events", r.URL.Path)
+		Equals(t, "api-key", r.Header.Get("DD-API-KEY"))
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		Ok(t, json.Unmarshal(body, &event))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.DatadogWebhook{
+		Client:         &webhooks.DatadogClient{Client: http.DefaultClient, APIKey: "api-key"},
+		URL:            server.URL + "/",
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	result := httpApplyResult
+	result.ProjectName = "infra"
+	result.Directory = "infra"
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), result))
+
+	Equals(t, "Atlantis apply of infra succeeded in runatlantis/atlantis", event["title"])
+	Equals(t, "success", event["alert_type"])
+	Equals(t, "runatlantis/atlantis#1", event["aggregation_key"])
+	Equals(t, []interface{}{
+		"repo:runatlantis/atlantis",
+		"pull:1",
+		"workspace:production",
+		"dir:infra",
+		"branch:main",
+		"user:lkysow",
+		"project:infra",
+	}, event["tags"])
+}
+
+func TestDatadogWebhook_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors": ["Forbidden"]}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.DatadogWebhook{
+		Client:         &webhooks.DatadogClient{Client: http.DefaultClient, APIKey: "invalid"},
+		URL:            server.URL,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	err := webhook.Send(logging.NewNoopLogger(t), httpApplyResult)
+	ErrContains(t, "sending event to Datadog: returned status code 403", err)
+}
+
+func TestNewWebhooksManager_DatadogWithoutAPIKey(t *testing.T) {
+	configs := []webhooks.Config{{Event: webhooks.ApplyEvent, Kind: webhooks.DatadogKind}}
+	_, err := webhooks.NewMultiWebhookSender(configs, webhooks.Clients{})
+	ErrEquals(t, "must specify top-level \"datadog-api-key\" if using a webhook of \"kind: datadog\"", err)
+}
@@ -0,0 +1,72 @@
+package webhooks
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// EmailClient sends emails through an SMTP server.
+type EmailClient struct {
+	// Addr is the host:port of the SMTP server.
+	Addr     string
+	From     string
+	Username string
+	Password string
+	// SendMail sends the email. It's smtp.SendMail if nil and is replaced in
+	// tests.
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// IsConfigured returns true if the SMTP server and sender are set.
+func (e *EmailClient) IsConfigured() bool {
+	return e != nil && e.Addr != "" && e.From != ""
+}
+
+// Send sends an email with subject and body to the addresses in to.
+func (e *EmailClient) Send(to []string, subject string, body string) error {
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, err := net.SplitHostPort(e.Addr)
+		if err != nil {
+			return errors.Wrapf(err, "parsing SMTP address %q", e.Addr)
+		}
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		e.From, strings.Join(to, ", "), subject, strings.ReplaceAll(body, "\n", "\r\n"))
+	sendMail := e.SendMail
+	if sendMail == nil {
+		sendMail = smtp.SendMail
+	}
+	return sendMail(e.Addr, auth, e.From, to, []byte(msg))
+}
+
+// EmailWebhook emails apply results.
+type EmailWebhook struct {
+	Client         *EmailClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	To             []string
+}
+
+// Send emails the apply result if workspace and branch matches their
+// respective regex.
+func (e *EmailWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !e.WorkspaceRegex.MatchString(applyResult.Workspace) || !e.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	body := applyResult.Pull.URL + "\n"
+	for _, field := range applyResultFields(applyResult) {
+		body += fmt.Sprintf("\n%s: %s", field[0], field[1])
+	}
+	if err := e.Client.Send(e.To, "Atlantis: "+applyResultTitle(applyResult), body); err != nil {
+		return errors.Wrapf(err, "emailing %s", strings.Join(e.To, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package webhooks_test
+
+import (
+	"errors"
+	"net/smtp"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestEmailWebhook(t *testing.T) {
+	var sentAddr, sentFrom string
+	var sentTo []string
+	var sentMsg string
+	var sentAuth smtp.Auth
+	client := &webhooks.EmailClient{
+		Addr:     "smtp.example.com:587",
+		From:     "atlantis@example.com",
+		Username: "atlantis",
+		Password: "password",
+		SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			sentAddr, sentAuth, sentFrom, sentTo, sentMsg = addr, a, from, to, string(msg)
+			return nil
+		},
+	}
+	webhook := webhooks.EmailWebhook{
+		Client:         client,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+		To:             []string{"db-team@example.com", "oncall@example.com"},
+	}
+	result := httpApplyResult
+	result.Directory = "db"
+	result.ProjectName = "db"
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), result))
+
+	Equals(t, "smtp.example.com:587", sentAddr)
+	Assert(t, sentAuth != nil, "exp auth to be set")
+	Equals(t, "atlantis@example.com", sentFrom)
+	Equals(t, []string{"db-team@example.com", "oncall@example.com"}, sentTo)
+	Equals(t, "From: atlantis@example.com\r\n"+
+		"To: db-team@example.com, oncall@example.com\r\n"+
+		"Subject: Atlantis: Apply succeeded for runatlantis/atlantis#1\r\n"+
+		"Content-Type: text/plain; charset=UTF-8\r\n"+
+		"\r\n"+
+		"url\r\n"+
+		"\r\n"+
+		"Workspace: production\r\n"+
+		"Branch: main\r\n"+
+		"User: lkysow\r\n"+
+		"Directory: db\r\n"+
+		"Project: db\r\n", sentMsg)
+}
+
+func TestEmailWebhook_Error(t *testing.T) {
+	client := &webhooks.EmailClient{
+		Addr: "smtp.example.com:25",
+		From: "atlantis@example.com",
+		SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			Assert(t, a == nil, "exp no auth without a username")
+			return errors.New("connection refused")
+		},
+	}
+	webhook := webhooks.EmailWebhook{
+		Client:         client,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+		To:             []string{"db-team@example.com"},
+	}
+	ErrContains(t, "emailing db-team@example.com: connection refused", webhook.Send(logging.NewNoopLogger(t), httpApplyResult))
+}
@@ -0,0 +1,130 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	teamsSuccessColour = "2EB886"
+	teamsFailureColour = "A30200"
+)
+
+// TeamsWebhook posts a message card to a Microsoft Teams incoming webhook.
+type TeamsWebhook struct {
+	Client         *http.Client
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	URL            string
+}
+
+// teamsMessageCard is the body of a request to a Teams incoming webhook.
+type teamsMessageCard struct {
+	Type       string              `json:"@type"`
+	Context    string              `json:"@context"`
+	ThemeColor string              `json:"themeColor"`
+	Summary    string              `json:"summary"`
+	Sections   []teamsMessageFacts `json:"sections"`
+}
+
+type teamsMessageFacts struct {
+	ActivityTitle string      `json:"activityTitle"`
+	Facts         []teamsFact `json:"facts"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Send posts a message to Teams if workspace and branch matches their
+// respective regex.
+func (t *TeamsWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !t.WorkspaceRegex.MatchString(applyResult.Workspace) || !t.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	if err := t.doSend(applyResult); err != nil {
+		return errors.Wrap(err, "sending message to Teams")
+	}
+	return nil
+}
+
+func (t *TeamsWebhook) doSend(applyResult ApplyResult) error {
+	body, err := json.Marshal(newTeamsMessageCard(applyResult))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", t.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+func newTeamsMessageCard(applyResult ApplyResult) teamsMessageCard {
+	colour := teamsSuccessColour
+	if !applyResult.Success {
+		colour = teamsFailureColour
+	}
+	var facts []teamsFact
+	for _, field := range applyResultFields(applyResult) {
+		facts = append(facts, teamsFact{Name: field[0], Value: field[1]})
+	}
+	return teamsMessageCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: colour,
+		Summary:    applyResultTitle(applyResult),
+		Sections: []teamsMessageFacts{{
+			ActivityTitle: fmt.Sprintf("[%s](%s)", applyResultTitle(applyResult), applyResult.Pull.URL),
+			Facts:         facts,
+		}},
+	}
+}
+
+// applyResultTitle returns a one line summary of applyResult for messages
+// that aren't formatted by the receiver, ex. Teams messages and emails.
+func applyResultTitle(applyResult ApplyResult) string {
+	status := "succeeded"
+	if !applyResult.Success {
+		status = "failed"
+	}
+	return fmt.Sprintf("Apply %s for %s#%d", status, applyResult.Repo.FullName, applyResult.Pull.Num)
+}
+
+// applyResultFields returns the name and value of the details of applyResult
+// in the same order as Slack messages.
+func applyResultFields(applyResult ApplyResult) [][2]string {
+	directory := applyResult.Directory
+	// Since "." looks weird, replace it with "/" to make it clear this is the root.
+	if directory == "." {
+		directory = "/"
+	}
+	fields := [][2]string{
+		{"Workspace", applyResult.Workspace},
+		{"Branch", applyResult.Pull.BaseBranch},
+		{"User", applyResult.User.Username},
+		{"Directory", directory},
+	}
+	if applyResult.ProjectName != "" {
+		fields = append(fields, [2]string{"Project", applyResult.ProjectName})
+	}
+	return fields
+}
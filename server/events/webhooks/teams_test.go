@@ -0,0 +1,76 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestTeamsWebhook(t *testing.T) {
+	var card map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		Ok(t, json.Unmarshal(body, &card))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.TeamsWebhook{
+		Client:         http.DefaultClient,
+		URL:            server.URL,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	result := httpApplyResult
+	result.Directory = "."
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), result))
+
+	Equals(t, "MessageCard", card["@type"])
+	Equals(t, "2EB886", card["themeColor"])
+	Equals(t, "Apply succeeded for runatlantis/atlantis#1", card["summary"])
+	Equals(t, []interface{}{
+		map[string]interface{}{
+			"activityTitle": "[Apply succeeded for runatlantis/atlantis#1](url)",
+			"facts": []interface{}{
+				map[string]interface{}{"name": "Workspace", "value": "production"},
+				map[string]interface{}{"name": "Branch", "value": "main"},
+				map[string]interface{}{"name": "User", "value": "lkysow"},
+				map[string]interface{}{"name": "Directory", "value": "/"},
+			},
+		},
+	}, card["sections"])
+}
+
+func TestTeamsWebhook_NoMatch(t *testing.T) {
+	webhook := webhooks.TeamsWebhook{
+		Client:         http.DefaultClient,
+		URL:            "http://localhost:0",
+		WorkspaceRegex: regexp.MustCompile("staging"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), httpApplyResult))
+}
+
+func TestTeamsWebhook_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Bad payload received by generic incoming webhook.", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.TeamsWebhook{
+		Client:         http.DefaultClient,
+		URL:            server.URL,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	ErrContains(t, "returned status code 400", webhook.Send(logging.NewNoopLogger(t), httpApplyResult))
+}
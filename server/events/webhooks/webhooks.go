@@ -15,16 +15,21 @@ package webhooks
 
 import (
 	"fmt"
+	"net/http"
 	"regexp"
 
 	"errors"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
 const SlackKind = "slack"
 const HttpKind = "http"
+const DatadogKind = "datadog"
+const TeamsKind = "teams"
+const EmailKind = "email"
 const ApplyEvent = "apply"
 
 //go:generate pegomock generate --package mocks -o mocks/mock_sender.go Sender
@@ -44,11 +49,16 @@ type ApplyResult struct {
 	Success     bool
 	Directory   string
 	ProjectName string
+	// Notifications are the project's own notifications. The result is sent
+	// to them in addition to the configured webhooks.
+	Notifications []valid.Notification `json:"-"`
 }
 
 // MultiWebhookSender sends multiple webhooks for each one it's configured for.
 type MultiWebhookSender struct {
 	Webhooks []Sender
+	// Clients are used to send the notifications of projects.
+	Clients Clients
 }
 
 type Config struct {
@@ -58,11 +68,15 @@ type Config struct {
 	Kind           string
 	Channel        string
 	URL            string
+	// To are the email addresses. It only applies to email webhooks.
+	To []string
 }
 
 type Clients struct {
-	Slack SlackClient
-	Http  *HttpClient
+	Slack   SlackClient
+	Http    *HttpClient
+	Datadog *DatadogClient
+	Email   *EmailClient
 }
 
 func NewMultiWebhookSender(configs []Config, clients Clients) (*MultiWebhookSender, error) {
@@ -106,22 +120,132 @@ func NewMultiWebhookSender(configs []Config, clients Clients) (*MultiWebhookSend
 				URL:            c.URL,
 			}
 			webhooks = append(webhooks, httpWebhook)
+		case DatadogKind:
+			if clients.Datadog == nil || clients.Datadog.APIKey == "" {
+				return nil, errors.New("must specify top-level \"datadog-api-key\" if using a webhook of \"kind: datadog\"")
+			}
+			url := c.URL
+			if url == "" {
+				url = DefaultDatadogURL
+			}
+			webhooks = append(webhooks, &DatadogWebhook{
+				Client:         clients.Datadog,
+				WorkspaceRegex: wr,
+				BranchRegex:    br,
+				URL:            url,
+			})
+		case TeamsKind:
+			if c.URL == "" {
+				return nil, errors.New("must specify \"url\" if using a webhook of \"kind: teams\"")
+			}
+			webhooks = append(webhooks, &TeamsWebhook{
+				Client:         plainHTTPClient(clients),
+				WorkspaceRegex: wr,
+				BranchRegex:    br,
+				URL:            c.URL,
+			})
+		case EmailKind:
+			if !clients.Email.IsConfigured() {
+				return nil, errors.New("must specify top-level \"smtp-addr\" and \"smtp-from\" if using a webhook of \"kind: email\"")
+			}
+			if len(c.To) == 0 {
+				return nil, errors.New("must specify \"to\" if using a webhook of \"kind: email\"")
+			}
+			webhooks = append(webhooks, &EmailWebhook{
+				Client:         clients.Email,
+				WorkspaceRegex: wr,
+				BranchRegex:    br,
+				To:             c.To,
+			})
 		default:
-			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\" and \"kind: %s\" are supported right now", c.Kind, SlackKind, HttpKind)
+			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\", \"kind: %s\", \"kind: %s\", \"kind: %s\" and \"kind: %s\" are supported right now", c.Kind, SlackKind, HttpKind, DatadogKind, TeamsKind, EmailKind)
 		}
 	}
 
 	return &MultiWebhookSender{
 		Webhooks: webhooks,
+		Clients:  clients,
 	}, nil
 }
 
-// Send sends the webhook using its Webhooks.
+// plainHTTPClient returns the client of http webhooks without their headers,
+// which may hold credentials meant for the receivers of the http webhooks.
+func plainHTTPClient(clients Clients) *http.Client {
+	if clients.Http != nil && clients.Http.Client != nil {
+		return clients.Http.Client
+	}
+	return http.DefaultClient
+}
+
+// Send sends the webhook using its Webhooks and to the project's
+// notifications.
 func (w *MultiWebhookSender) Send(log logging.SimpleLogging, result ApplyResult) error {
 	for _, w := range w.Webhooks {
 		if err := w.Send(log, result); err != nil {
 			log.Warn("error sending webhook: %s", err)
 		}
 	}
+	for _, n := range result.Notifications {
+		sender, err := w.notificationSender(n)
+		if err != nil {
+			log.Warn("unable to send %s notification of project: %s", n.Kind, err)
+			continue
+		}
+		if err := sender.Send(log, result); err != nil {
+			log.Warn("error sending %s notification of project: %s", n.Kind, err)
+		}
+	}
 	return nil
 }
+
+// matchAll matches every workspace and branch since the notifications of a
+// project apply to all of its applies.
+var matchAll = regexp.MustCompile(".*")
+
+// notificationSender returns the sender for a project's notification. The
+// notifications come from repo configs so the clients they need may not be
+// configured on the server.
+func (w *MultiWebhookSender) notificationSender(n valid.Notification) (Sender, error) {
+	switch n.Kind {
+	case SlackKind:
+		if w.Clients.Slack == nil || !w.Clients.Slack.TokenIsSet() {
+			return nil, errors.New("slack-token isn't set")
+		}
+		// Unlike NewSlack the token isn't tested since that would call
+		// the Slack API on every apply.
+		return &SlackWebhook{
+			Client:         w.Clients.Slack,
+			WorkspaceRegex: matchAll,
+			BranchRegex:    matchAll,
+			Channel:        n.Channel,
+		}, nil
+	case HttpKind:
+		// The URL comes from the repo config so the headers of the
+		// server's http webhooks aren't sent to it.
+		return &HttpWebhook{
+			Client:         &HttpClient{Client: plainHTTPClient(w.Clients)},
+			WorkspaceRegex: matchAll,
+			BranchRegex:    matchAll,
+			URL:            n.URL,
+		}, nil
+	case TeamsKind:
+		return &TeamsWebhook{
+			Client:         plainHTTPClient(w.Clients),
+			WorkspaceRegex: matchAll,
+			BranchRegex:    matchAll,
+			URL:            n.URL,
+		}, nil
+	case EmailKind:
+		if !w.Clients.Email.IsConfigured() {
+			return nil, errors.New("smtp-addr and smtp-from aren't set")
+		}
+		return &EmailWebhook{
+			Client:         w.Clients.Email,
+			WorkspaceRegex: matchAll,
+			BranchRegex:    matchAll,
+			To:             n.To,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", n.Kind)
+	}
+}
@@ -15,10 +15,12 @@ package webhooks_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -136,7 +138,26 @@ func TestNewWebhooksManager_UnsupportedKind(t *testing.T) {
 	configs[0].Kind = unsupportedKind
 	_, err := webhooks.NewMultiWebhookSender(configs, clients)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\" and \"kind: http\" are supported right now", err.Error())
+	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\", \"kind: http\", \"kind: datadog\", \"kind: teams\" and \"kind: email\" are supported right now", err.Error())
+}
+
+func TestNewWebhooksManager_EmailNotConfigured(t *testing.T) {
+	RegisterMockTestingT(t)
+	configs := []webhooks.Config{{
+		Event:          validEvent,
+		WorkspaceRegex: validRegex,
+		BranchRegex:    validRegex,
+		Kind:           webhooks.EmailKind,
+		To:             []string{"db-team@example.com"},
+	}}
+	_, err := webhooks.NewMultiWebhookSender(configs, validClients())
+	ErrEquals(t, "must specify top-level \"smtp-addr\" and \"smtp-from\" if using a webhook of \"kind: email\"", err)
+
+	clients := validClients()
+	clients.Email = &webhooks.EmailClient{Addr: "smtp.example.com:25", From: "atlantis@example.com"}
+	m, err := webhooks.NewMultiWebhookSender(configs, clients)
+	Ok(t, err)
+	Equals(t, 1, len(m.Webhooks)) // nolint: staticcheck
 }
 
 func TestNewWebhooksManager_NoConfigSuccess(t *testing.T) {
@@ -217,3 +238,44 @@ func TestSend_MultipleSuccess(t *testing.T) {
 		s.VerifyWasCalledOnce().Send(logger, result)
 	}
 }
+
+func TestSend_ProjectNotifications(t *testing.T) {
+	RegisterMockTestingT(t)
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path)
+		Equals(t, "", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	slackClient := mocks.NewMockSlackClient()
+	When(slackClient.TokenIsSet()).ThenReturn(true)
+	sender := mocks.NewMockSender()
+	manager := webhooks.MultiWebhookSender{
+		Webhooks: []webhooks.Sender{sender},
+		Clients: webhooks.Clients{
+			Slack: slackClient,
+			Http: &webhooks.HttpClient{
+				Client:  http.DefaultClient,
+				Headers: map[string][]string{"Authorization": {"Bearer secret"}},
+			},
+		},
+	}
+	logger := logging.NewNoopLogger(t)
+	result := webhooks.ApplyResult{
+		Workspace: "default",
+		Notifications: []valid.Notification{
+			{Kind: "slack", Channel: "db-team"},
+			{Kind: "http", URL: server.URL + "/http"},
+			{Kind: "teams", URL: server.URL + "/teams"},
+			// Not sent since SMTP isn't configured.
+			{Kind: "email", To: []string{"db-team@example.com"}},
+		},
+	}
+	Ok(t, manager.Send(logger, result))
+
+	sender.VerifyWasCalledOnce().Send(logger, result)
+	slackClient.VerifyWasCalledOnce().PostMessage("db-team", result)
+	Equals(t, []string{"/http", "/teams"}, received)
+}
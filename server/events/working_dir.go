@@ -31,8 +31,14 @@ import (
 
 const workingDirPrefix = "repos"
 
+// cloneCachePrefix is the dir in the data dir holding the clone cache.
+const cloneCachePrefix = "clone-cache"
+
 var cloneLocks sync.Map
 
+// cloneCacheLocks serialize updates to each repo's clone cache.
+var cloneCacheLocks sync.Map
+
 //go:generate pegomock generate github.com/runatlantis/atlantis/server/events --package mocks -o mocks/mock_working_dir.go WorkingDir
 //go:generate pegomock generate github.com/runatlantis/atlantis/server/events --package events WorkingDir
 
@@ -87,6 +93,13 @@ type FileWorkspace struct {
 	GpgNoSigningEnabled bool
 	// flag indicating if we have to merge with potential new changes upstream (directly after grabbing project lock)
 	CheckForUpstreamChanges bool
+	// CloneCache is true if we should keep a bare clone of each base repo in
+	// the data dir, fetch it incrementally and clone pull requests with it as
+	// a reference so only the objects it doesn't have are downloaded.
+	CloneCache bool
+	// CloneLimiter limits the clones and fetches running against each VCS
+	// host at the same time. If nil there's no limit.
+	CloneLimiter *CloneLimiter
 }
 
 // Clone git clones headRepo, checks out the branch and then returns the absolute
@@ -111,7 +124,7 @@ func (w *FileWorkspace) Clone(logger logging.SimpleLogging, headRepo models.Repo
 		// because we'll already have performed a merge. Instead, we'll check
 		// HEAD^2 since that will be the commit before our merge.
 		pullHead := "HEAD"
-		if w.CheckoutMerge {
+		if w.checkoutMerge(p) {
 			pullHead = "HEAD^2"
 		}
 		revParseCmd := exec.Command("git", "rev-parse", pullHead) // #nosec
@@ -126,7 +139,7 @@ func (w *FileWorkspace) Clone(logger logging.SimpleLogging, headRepo models.Repo
 		// We're prefix matching here because BitBucket doesn't give us the full
 		// commit, only a 12 character prefix.
 		if strings.HasPrefix(currCommit, p.HeadCommit) {
-			if w.CheckForUpstreamChanges && w.CheckoutMerge && w.recheckDiverged(logger, p, headRepo, cloneDir) {
+			if w.CheckForUpstreamChanges && w.checkoutMerge(p) && w.recheckDiverged(logger, p, headRepo, cloneDir) {
 				logger.Info("base branch has been updated, using merge strategy and will clone again")
 				return cloneDir, true, w.mergeAgain(logger, c)
 			}
@@ -142,6 +155,13 @@ func (w *FileWorkspace) Clone(logger logging.SimpleLogging, headRepo models.Repo
 	return cloneDir, false, w.forceClone(logger, c)
 }
 
+// checkoutMerge returns true if p is checked out with the merge strategy. An
+// issue in issue-ops mode has no pull request to merge, its head and base are
+// the default branch, so it's always checked out with the branch strategy.
+func (w *FileWorkspace) checkoutMerge(p models.PullRequest) bool {
+	return w.CheckoutMerge && !p.IsIssue
+}
+
 // recheckDiverged returns true if the branch we're merging into has diverged
 // from what we currently have checked out.
 // This matters in the case of the merge checkout strategy because after
@@ -175,16 +195,19 @@ func (w *FileWorkspace) recheckDiverged(logger logging.SimpleLogging, p models.P
 		},
 	}
 
+	release := w.CloneLimiter.Acquire(logger, cloneHost(p.BaseRepo.CloneURL))
 	for _, args := range cmds {
 		cmd := exec.Command(args[0], args[1:]...) // nolint: gosec
 		cmd.Dir = cloneDir
 
 		output, err := cmd.CombinedOutput()
 		if err != nil {
+			release()
 			logger.Warn("getting remote update failed: %s", string(output))
 			return false
 		}
 	}
+	release()
 
 	return w.HasDiverged(logger, cloneDir)
 }
@@ -196,9 +219,11 @@ func (w *FileWorkspace) HasDiverged(logger logging.SimpleLogging, cloneDir strin
 		return false
 	}
 
+	release := w.CloneLimiter.Acquire(logger, w.originHost(cloneDir))
 	statusFetchCmd := exec.Command("git", "fetch")
 	statusFetchCmd.Dir = cloneDir
 	outputStatusFetch, err := statusFetchCmd.CombinedOutput()
+	release()
 	if err != nil {
 		logger.Warn("fetching repo has failed: %s", string(outputStatusFetch))
 		return false
@@ -216,6 +241,23 @@ func (w *FileWorkspace) HasDiverged(logger logging.SimpleLogging, cloneDir strin
 	return hasDiverged
 }
 
+// originHost returns the host of the origin remote of cloneDir to limit its
+// fetches. With the merge strategy origin is the base repo, so its fetches
+// share the pool of the clones and merges of the pull request. It returns an
+// empty string if clones aren't limited or the remote can't be read.
+func (w *FileWorkspace) originHost(cloneDir string) string {
+	if w.CloneLimiter == nil || w.CloneLimiter.MaxPerHost <= 0 {
+		return ""
+	}
+	cmd := exec.Command("git", "remote", "get-url", "origin") // #nosec
+	cmd.Dir = cloneDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return cloneHost(string(output))
+}
+
 func (w *FileWorkspace) forceClone(logger logging.SimpleLogging, c wrappedGitContext) error {
 	value, _ := cloneLocks.LoadOrStore(c.dir, new(sync.Mutex))
 	mutex := value.(*sync.Mutex)
@@ -247,20 +289,31 @@ func (w *FileWorkspace) forceClone(logger logging.SimpleLogging, c wrappedGitCon
 		baseCloneURL = w.TestingOverrideBaseCloneURL
 	}
 
+	cloneArgs := []string{"clone"}
+	if w.CloneCache {
+		if cacheDir, err := w.updateCloneCache(logger, c, baseCloneURL); err != nil {
+			logger.Warn("cloning without the clone cache: %s", err)
+		} else {
+			// --dissociate copies the objects we need from the cache so the
+			// clone keeps working if the cache is deleted.
+			cloneArgs = append(cloneArgs, "--reference-if-able", cacheDir, "--dissociate")
+		}
+	}
+
 	// if branch strategy, use depth=1
-	if !w.CheckoutMerge {
-		return w.wrappedGit(logger, c, "clone", "--depth=1", "--branch", c.pr.HeadBranch, "--single-branch", headCloneURL, c.dir)
+	if !w.checkoutMerge(c.pr) {
+		return w.wrappedGit(logger, c, append(cloneArgs, "--depth=1", "--branch", c.pr.HeadBranch, "--single-branch", headCloneURL, c.dir)...)
 	}
 
 	// if merge strategy...
 
 	// if no checkout depth, omit depth arg
 	if w.CheckoutDepth == 0 {
-		if err := w.wrappedGit(logger, c, "clone", "--branch", c.pr.BaseBranch, "--single-branch", baseCloneURL, c.dir); err != nil {
+		if err := w.wrappedGit(logger, c, append(cloneArgs, "--branch", c.pr.BaseBranch, "--single-branch", baseCloneURL, c.dir)...); err != nil {
 			return err
 		}
 	} else {
-		if err := w.wrappedGit(logger, c, "clone", "--depth", fmt.Sprint(w.CheckoutDepth), "--branch", c.pr.BaseBranch, "--single-branch", baseCloneURL, c.dir); err != nil {
+		if err := w.wrappedGit(logger, c, append(cloneArgs, "--depth", fmt.Sprint(w.CheckoutDepth), "--branch", c.pr.BaseBranch, "--single-branch", baseCloneURL, c.dir)...); err != nil {
 			return err
 		}
 	}
@@ -277,6 +330,40 @@ func (w *FileWorkspace) forceClone(logger logging.SimpleLogging, c wrappedGitCon
 	return w.mergeToBaseBranch(logger, c)
 }
 
+// updateCloneCache creates the bare clone of the base repo used as a
+// reference when cloning or fetches the branches that changed since it was
+// last updated. It returns the path to the bare clone. The clone URL is passed
+// on every fetch rather than stored because credentials in it can expire.
+func (w *FileWorkspace) updateCloneCache(logger logging.SimpleLogging, c wrappedGitContext, baseCloneURL string) (string, error) {
+	if baseCloneURL == "" || c.pr.BaseRepo.FullName == "" {
+		return "", errors.New("base repo is unknown")
+	}
+	cacheDir := w.cloneCacheDir(c.pr.BaseRepo)
+
+	value, _ := cloneCacheLocks.LoadOrStore(cacheDir, new(sync.Mutex))
+	mutex := value.(*sync.Mutex)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	cacheCtx := wrappedGitContext{dir: cacheDir, head: c.head, pr: c.pr}
+	if _, err := os.Stat(cacheDir); err != nil {
+		logger.Info("creating clone cache '%s'", cacheDir)
+		if err := os.MkdirAll(filepath.Dir(cacheDir), 0700); err != nil {
+			return "", errors.Wrap(err, "creating clone cache dir")
+		}
+		cacheCtx.dir = filepath.Dir(cacheDir)
+		if err := w.wrappedGit(logger, cacheCtx, "clone", "--bare", baseCloneURL, cacheDir); err != nil {
+			os.RemoveAll(cacheDir) // nolint: errcheck
+			return "", err
+		}
+		// A bare clone doesn't need to remember where it came from.
+		cacheCtx.dir = cacheDir
+		return cacheDir, w.wrappedGit(logger, cacheCtx, "remote", "remove", "origin")
+	}
+	logger.Debug("fetching clone cache '%s'", cacheDir)
+	return cacheDir, w.wrappedGit(logger, cacheCtx, "fetch", "--prune", baseCloneURL, "+refs/heads/*:refs/heads/*")
+}
+
 // There is a new upstream update that we need, and we want to update to it
 // without deleting any existing plans
 func (w *FileWorkspace) mergeAgain(logger logging.SimpleLogging, c wrappedGitContext) error {
@@ -306,8 +393,12 @@ type wrappedGitContext struct {
 }
 
 // wrappedGit runs git with additional environment settings required for git merge,
-// and with sanitized error logging to avoid leaking git credentials
+// and with sanitized error logging to avoid leaking git credentials. Clones and
+// fetches wait for the CloneLimiter of the host of the base repo's clone URL.
 func (w *FileWorkspace) wrappedGit(logger logging.SimpleLogging, c wrappedGitContext, args ...string) error {
+	if len(args) > 0 && (args[0] == "clone" || args[0] == "fetch") {
+		defer w.CloneLimiter.Acquire(logger, cloneHost(c.pr.BaseRepo.CloneURL))()
+	}
 	cmd := exec.Command("git", args...) // nolint: gosec
 	cmd.Dir = c.dir
 	// The git merge command requires these env vars are set.
@@ -411,6 +502,10 @@ func (w *FileWorkspace) cloneDir(r models.Repo, p models.PullRequest, workspace
 	return filepath.Join(w.repoPullDir(r, p), workspace)
 }
 
+func (w *FileWorkspace) cloneCacheDir(r models.Repo) string {
+	return filepath.Join(w.DataDir, cloneCachePrefix, r.FullName+".git")
+}
+
 // sanitizeGitCredentials replaces any git clone urls that contain credentials
 // in s with the sanitized versions.
 func (w *FileWorkspace) sanitizeGitCredentials(s string, base models.Repo, head models.Repo) string {
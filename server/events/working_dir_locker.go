@@ -15,8 +15,9 @@ package events
 
 import (
 	"fmt"
-	"strings"
 	"sync"
+
+	"github.com/runatlantis/atlantis/server/events/command"
 )
 
 //go:generate pegomock generate --package mocks -o mocks/mock_working_dir_locker.go WorkingDirLocker
@@ -26,12 +27,29 @@ import (
 // this from happening because a specific repo/pull/workspace has a single workspace
 // on disk and we haven't written Atlantis (yet) to handle concurrent execution
 // within this workspace.
+//
+// Locks are held on a whole pull, on one of its workspaces or on a path in a
+// workspace. Commands for different paths of a workspace can run at the same
+// time but not while the workspace is locked for writing, which is only done
+// while cloning it. Workspace locks wait for the conflicting locks to be
+// released instead of failing, since the commands running in the paths of
+// the workspace can take long.
 type WorkingDirLocker interface {
 	// TryLock tries to acquire a lock for this repo, pull, workspace, and path.
+	// It waits while the workspace is locked for writing.
 	// It returns a function that should be used to unlock the workspace and
-	// an error if the workspace is already locked. The error is expected to
+	// an error if the path is already locked. The error is expected to
 	// be printed to the pull request.
 	TryLock(repoFullName string, pullNum int, workspace string, path string) (func(), error)
+	// LockWorkspace waits until it acquires a write lock for all the paths in
+	// this repo, pull and workspace, e.g. to clone the workspace, and returns
+	// a function that should be used to unlock the workspace.
+	LockWorkspace(repoFullName string, pullNum int, workspace string) func()
+	// RLockWorkspace waits until it acquires a read lock for this repo, pull
+	// and workspace and returns a function that should be used to unlock the
+	// workspace. Read locks can be held together with other read locks and
+	// path locks but not with a write lock of the workspace.
+	RLockWorkspace(repoFullName string, pullNum int, workspace string) func()
 	// TryLockPull tries to acquire a lock for all the workspaces in this repo
 	// and pull.
 	// It returns a function that should be used to unlock the workspace and
@@ -40,15 +58,55 @@ type WorkingDirLocker interface {
 	TryLockPull(repoFullName string, pullNum int) (func(), error)
 }
 
+// workingDirLock is a lock held on a pull, on one of its workspaces if path
+// is empty or on a path in a workspace.
+type workingDirLock struct {
+	pullKey   string
+	workspace string
+	path      string
+	// read is true for workspace read locks.
+	read bool
+}
+
+// conflicts returns true if l and other can't be held at the same time.
+func (l *workingDirLock) conflicts(other *workingDirLock) bool {
+	if l.pullKey != other.pullKey {
+		return false
+	}
+	if l.workspace == "" || other.workspace == "" {
+		return true
+	}
+	if l.workspace != other.workspace {
+		return false
+	}
+	switch {
+	case l.path == "" && other.path == "":
+		return !l.read || !other.read
+	case l.path == "":
+		return !l.read
+	case other.path == "":
+		return !other.read
+	default:
+		return l.path == other.path
+	}
+}
+
+// isWorkspaceWrite returns true if l is a write lock of a workspace.
+func (l *workingDirLock) isWorkspaceWrite() bool {
+	return l.workspace != "" && l.path == "" && !l.read
+}
+
 // DefaultWorkingDirLocker implements WorkingDirLocker.
 type DefaultWorkingDirLocker struct {
 	// mutex prevents against multiple threads calling functions on this struct
 	// concurrently. It's only used for entry/exit to each function.
 	mutex sync.Mutex
-	// locks is a list of the keys that are locked. We then use prefix
-	// matching to determine if something is locked. It's naive but that's okay
-	// because there won't be many locks at one time.
-	locks []string
+	// locks is a list of the locks that are held. It's naive to check each
+	// of them but that's okay because there won't be many locks at one time.
+	locks []*workingDirLock
+	// released is closed when a lock is released to wake up the callers
+	// waiting for a lock. It's nil if no caller is waiting.
+	released chan struct{}
 }
 
 // NewDefaultWorkingDirLocker is a constructor.
@@ -57,49 +115,91 @@ func NewDefaultWorkingDirLocker() *DefaultWorkingDirLocker {
 }
 
 func (d *DefaultWorkingDirLocker) TryLockPull(repoFullName string, pullNum int) (func(), error) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
+	return d.lock(&workingDirLock{pullKey: d.pullKey(repoFullName, pullNum)},
+		func(*workingDirLock) bool { return false },
+		fmt.Errorf("the Atlantis working dir is currently locked by another"+
+			" command that is running for this pull request.\n"+
+			"Wait until the previous command is complete and try again"))
+}
 
-	pullKey := d.pullKey(repoFullName, pullNum)
-	for _, l := range d.locks {
-		if l == pullKey || strings.HasPrefix(l, pullKey+"/") {
-			return func() {}, fmt.Errorf("the Atlantis working dir is currently locked by another" +
-				" command that is running for this pull request.\n" +
-				"Wait until the previous command is complete and try again")
+func (d *DefaultWorkingDirLocker) LockWorkspace(repoFullName string, pullNum int, workspace string) func() {
+	unlockFn, _ := d.lock(&workingDirLock{pullKey: d.pullKey(repoFullName, pullNum), workspace: workspace},
+		func(*workingDirLock) bool { return true }, nil)
+	return unlockFn
+}
+
+func (d *DefaultWorkingDirLocker) RLockWorkspace(repoFullName string, pullNum int, workspace string) func() {
+	unlockFn, _ := d.lock(&workingDirLock{pullKey: d.pullKey(repoFullName, pullNum), workspace: workspace, read: true},
+		func(*workingDirLock) bool { return true }, nil)
+	return unlockFn
+}
+
+func (d *DefaultWorkingDirLocker) TryLock(repoFullName string, pullNum int, workspace string, path string) (func(), error) {
+	return d.lock(&workingDirLock{pullKey: d.pullKey(repoFullName, pullNum), workspace: workspace, path: path},
+		(*workingDirLock).isWorkspaceWrite,
+		fmt.Errorf("the %s workspace at path %s is currently locked by another"+
+			" command that is running for this pull request.\n"+
+			"Wait until the previous command is complete and try again", workspace, path))
+}
+
+// lock adds lock once it doesn't conflict with a held lock. It waits for the
+// conflicting locks for which wait returns true to be released and returns
+// lockedErr if another lock conflicts. The returned function removes lock
+// and can be called more than once.
+func (d *DefaultWorkingDirLocker) lock(lock *workingDirLock, wait func(held *workingDirLock) bool, lockedErr error) (func(), error) {
+	d.mutex.Lock()
+	for {
+		waiting := false
+		for _, l := range d.locks {
+			if !l.conflicts(lock) {
+				continue
+			}
+			if !wait(l) {
+				d.mutex.Unlock()
+				return func() {}, lockedErr
+			}
+			waiting = true
 		}
+		if !waiting {
+			break
+		}
+		if d.released == nil {
+			d.released = make(chan struct{})
+		}
+		released := d.released
+		d.mutex.Unlock()
+		<-released
+		d.mutex.Lock()
 	}
-	d.locks = append(d.locks, pullKey)
+	d.locks = append(d.locks, lock)
+	d.mutex.Unlock()
 	return func() {
-		d.UnlockPull(repoFullName, pullNum)
+		d.unlock(lock)
 	}, nil
 }
 
-func (d *DefaultWorkingDirLocker) TryLock(repoFullName string, pullNum int, workspace string, path string) (func(), error) {
+// unlock removes lock.
+func (d *DefaultWorkingDirLocker) unlock(lock *workingDirLock) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	pullKey := d.pullKey(repoFullName, pullNum)
-	workspaceKey := d.workspaceKey(repoFullName, pullNum, workspace, path)
+	var newLocks []*workingDirLock
 	for _, l := range d.locks {
-		if l == pullKey || l == workspaceKey {
-			return func() {}, fmt.Errorf("the %s workspace at path %s is currently locked by another"+
-				" command that is running for this pull request.\n"+
-				"Wait until the previous command is complete and try again", workspace, path)
+		if l != lock {
+			newLocks = append(newLocks, l)
 		}
 	}
-	d.locks = append(d.locks, workspaceKey)
-	return func() {
-		d.unlock(repoFullName, pullNum, workspace, path)
-	}, nil
+	d.locks = newLocks
+	d.wakeWaiting()
 }
 
-// Unlock unlocks the workspace for this pull.
-func (d *DefaultWorkingDirLocker) unlock(repoFullName string, pullNum int, workspace string, path string) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
-	workspaceKey := d.workspaceKey(repoFullName, pullNum, workspace, path)
-	d.removeLock(workspaceKey)
+// wakeWaiting wakes up the callers waiting for a lock so they check again if
+// they can get it. It must be called with the mutex held.
+func (d *DefaultWorkingDirLocker) wakeWaiting() {
+	if d.released != nil {
+		close(d.released)
+		d.released = nil
+	}
 }
 
 // Unlock unlocks all workspaces for this pull.
@@ -108,21 +208,29 @@ func (d *DefaultWorkingDirLocker) UnlockPull(repoFullName string, pullNum int) {
 	defer d.mutex.Unlock()
 
 	pullKey := d.pullKey(repoFullName, pullNum)
-	d.removeLock(pullKey)
-}
-
-func (d *DefaultWorkingDirLocker) removeLock(key string) {
-	var newLocks []string
+	var newLocks []*workingDirLock
 	for _, l := range d.locks {
-		if l != key {
+		if l.pullKey != pullKey || l.workspace != "" {
 			newLocks = append(newLocks, l)
 		}
 	}
 	d.locks = newLocks
+	d.wakeWaiting()
 }
 
-func (d *DefaultWorkingDirLocker) workspaceKey(repo string, pull int, workspace string, path string) string {
-	return fmt.Sprintf("%s/%s/%s", d.pullKey(repo, pull), workspace, path)
+// cloneWorkspace clones workspace for the pull request of ctx holding the
+// write lock of the workspace only while cloning, so the commands running in
+// its paths hold up the clone but not what follows it. It returns the clone
+// dir and a function to release the read lock of the workspace it holds.
+func cloneWorkspace(ctx *command.Context, locker WorkingDirLocker, workingDir WorkingDir, workspace string) (string, func(), error) {
+	unlockFn := locker.LockWorkspace(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace)
+	ctx.Log.Debug("got workspace lock")
+	repoDir, _, err := workingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, workspace)
+	unlockFn()
+	if err != nil {
+		return "", func() {}, err
+	}
+	return repoDir, locker.RLockWorkspace(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace), nil
 }
 
 func (d *DefaultWorkingDirLocker) pullKey(repo string, pull int) string {
@@ -15,6 +15,7 @@ package events_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/events"
 	. "github.com/runatlantis/atlantis/testing"
@@ -214,3 +215,80 @@ func TestLockPull_WorkspaceFirst(t *testing.T) {
 	_, err = locker.TryLockPull("owner/repo", 1)
 	Ok(t, err)
 }
+
+func TestLockWorkspace(t *testing.T) {
+	locker := events.NewDefaultWorkingDirLocker()
+	unlock := locker.LockWorkspace(repo, 1, workspace)
+
+	// Locks of the paths of the workspace and other locks of the workspace
+	// wait for it to be unlocked, the pull lock fails.
+	pathLocked := lockAsync(func() { _, err := locker.TryLock(repo, 1, workspace, "dir"); Ok(t, err) })
+	readLocked := lockAsync(func() { locker.RLockWorkspace(repo, 1, workspace) })
+	_, err := locker.TryLockPull(repo, 1)
+	Assert(t, err != nil, "exp err")
+
+	// Other workspaces of the pull can be locked.
+	locker.LockWorkspace(repo, 1, "new-workspace")
+	_, err = locker.TryLock(repo, 1, "other-workspace", path)
+	Ok(t, err)
+
+	assertWaiting(t, pathLocked)
+	assertWaiting(t, readLocked)
+	unlock()
+	assertLocked(t, pathLocked)
+	assertLocked(t, readLocked)
+}
+
+// Paths of a workspace can be locked while the workspace is read, and the
+// write lock of the workspace waits for the reads and paths to be unlocked.
+func TestRLockWorkspace(t *testing.T) {
+	locker := events.NewDefaultWorkingDirLocker()
+	unlockRead := locker.RLockWorkspace(repo, 1, workspace)
+	unlockRead2 := locker.RLockWorkspace(repo, 1, workspace)
+
+	unlockPath, err := locker.TryLock(repo, 1, workspace, "dir1")
+	Ok(t, err)
+	unlockPath2, err := locker.TryLock(repo, 1, workspace, "dir2")
+	Ok(t, err)
+
+	writeLocked := lockAsync(func() { locker.LockWorkspace(repo, 1, workspace) })
+	locker.LockWorkspace(repo, 2, workspace)
+
+	// Unlocking one read lock twice keeps the other one.
+	unlockRead()
+	unlockRead()
+	unlockPath()
+	unlockRead2()
+	assertWaiting(t, writeLocked)
+	unlockPath2()
+	assertLocked(t, writeLocked)
+}
+
+// lockAsync calls lock in a goroutine and returns a channel closed once it
+// returned.
+func lockAsync(lock func()) chan struct{} {
+	locked := make(chan struct{})
+	go func() {
+		lock()
+		close(locked)
+	}()
+	return locked
+}
+
+func assertWaiting(t *testing.T, locked chan struct{}) {
+	t.Helper()
+	select {
+	case <-locked:
+		t.Fatal("expected the lock to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func assertLocked(t *testing.T, locked chan struct{}) {
+	t.Helper()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the lock to be acquired")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
 )
 
 // disableSSLVerification disables ssl verification for the global http client
@@ -116,8 +117,122 @@ func TestClone_CheckoutMergeNoneExisting(t *testing.T) {
 	Equals(t, expLsOutput, actLsOutput)
 }
 
+// Test that an issue in issue-ops mode is checked out with the branch method
+// even if the merge method is used since there's no pull request to fetch.
+func TestClone_CheckoutMergeIssue(t *testing.T) {
+	repoDir := initRepo(t)
+	mainCommit := runCmd(t, repoDir, "git", "rev-parse", "main")
+
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     t.TempDir(),
+		CheckoutMerge:               true,
+		GithubAppEnabled:            true,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+		GpgNoSigningEnabled:         true,
+	}
+
+	cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{
+		Num:        5,
+		BaseRepo:   models.Repo{},
+		HeadBranch: "main",
+		BaseBranch: "main",
+		IsIssue:    true,
+	}, "default")
+	Ok(t, err)
+	Equals(t, mainCommit, runCmd(t, cloneDir, "git", "rev-parse", "HEAD"))
+}
+
 // Test that if we're using the merge method and the repo is already cloned at
 // the right commit, then we don't reclone.
+// The clone cache is created by the first clone of a repo and fetched by
+// later clones.
+func TestClone_CloneCache(t *testing.T) {
+	repoDir := initRepo(t)
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	runCmd(t, repoDir, "touch", "branch-file")
+	runCmd(t, repoDir, "git", "add", "branch-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "branch-commit")
+	branchCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+	runCmd(t, repoDir, "git", "checkout", "main")
+
+	logger := logging.NewNoopLogger(t)
+	dataDir := t.TempDir()
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutMerge:               true,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+		GpgNoSigningEnabled:         true,
+		CloneCache:                  true,
+	}
+	baseRepo := models.Repo{FullName: "owner/repo"}
+	cacheDir := filepath.Join(dataDir, "clone-cache", "owner", "repo.git")
+
+	cloneDir, _, err := wd.Clone(logger, models.Repo{}, models.PullRequest{
+		BaseRepo:   baseRepo,
+		Num:        1,
+		HeadBranch: "branch",
+		BaseBranch: "main",
+	}, "default")
+	Ok(t, err)
+	Equals(t, branchCommit, runCmd(t, cloneDir, "git", "rev-parse", "HEAD^2"))
+	Equals(t, runCmd(t, repoDir, "git", "rev-parse", "main"), runCmd(t, cacheDir, "git", "rev-parse", "main"))
+	// The clone doesn't depend on the cache.
+	_, err = os.Stat(filepath.Join(cloneDir, ".git", "objects", "info", "alternates"))
+	Assert(t, os.IsNotExist(err), "exp no alternates, got %v", err)
+
+	// Advance main, the cache should be fetched by the next clone.
+	runCmd(t, repoDir, "touch", "main-file")
+	runCmd(t, repoDir, "git", "add", "main-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "main-commit")
+	mainCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+
+	cloneDir, _, err = wd.Clone(logger, models.Repo{}, models.PullRequest{
+		BaseRepo:   baseRepo,
+		Num:        2,
+		HeadBranch: "branch",
+		BaseBranch: "main",
+	}, "default")
+	Ok(t, err)
+	Equals(t, mainCommit, runCmd(t, cacheDir, "git", "rev-parse", "main"))
+	Equals(t, mainCommit, runCmd(t, cloneDir, "git", "rev-parse", "HEAD~1"))
+	Equals(t, branchCommit, runCmd(t, cloneDir, "git", "rev-parse", "HEAD^2"))
+}
+
+// The clone of the clone cache, the clone and the fetch of the head branch
+// each wait for the clone limiter, without holding it while waiting for it,
+// and the fetch checking if the base branch diverged waits in the same pool.
+// A file clone URL has no host so its clones share the default pool.
+func TestClone_CloneLimiter(t *testing.T) {
+	repoDir := initRepo(t)
+	cloneURL := fmt.Sprintf("file://%s", repoDir)
+	scope := tally.NewTestScope("test", nil)
+	wd := &events.FileWorkspace{
+		DataDir:                     t.TempDir(),
+		CheckoutMerge:               true,
+		TestingOverrideHeadCloneURL: cloneURL,
+		GpgNoSigningEnabled:         true,
+		CloneCache:                  true,
+		CloneLimiter:                events.NewCloneLimiter(1, scope),
+	}
+	logger := logging.NewNoopLogger(t)
+
+	cloneDir, _, err := wd.Clone(logger, models.Repo{}, models.PullRequest{
+		BaseRepo:   models.Repo{FullName: "owner/repo", CloneURL: cloneURL, VCSHost: models.VCSHost{Hostname: "github.com"}},
+		Num:        1,
+		HeadBranch: "branch",
+		BaseBranch: "main",
+	}, "default")
+	Ok(t, err)
+	Equals(t, 3, len(scope.Snapshot().Timers()["test.git_clone.wait_time+host=default"].Values()))
+
+	Equals(t, false, wd.HasDiverged(logger, cloneDir))
+	Equals(t, 4, len(scope.Snapshot().Timers()["test.git_clone.wait_time+host=default"].Values()))
+}
+
 func TestClone_CheckoutMergeNoReclone(t *testing.T) {
 	// Initialize the git repo.
 	repoDir := initRepo(t)
@@ -0,0 +1,225 @@
+package jobs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultJobLogSearchLimit is the number of jobs returned by a search
+	// without a limit.
+	DefaultJobLogSearchLimit = 50
+	// maxJobLogMatchingLines is the maximum number of lines matching the text
+	// of a search returned for each job.
+	maxJobLogMatchingLines = 20
+	// jobLogPruneInterval is how often completed jobs older than the
+	// retention are deleted.
+	jobLogPruneInterval = time.Hour
+)
+
+// JobLog is the output of a completed job, kept so it can be searched after
+// its pull request is closed.
+type JobLog struct {
+	JobID string
+	PullInfo
+	HeadCommit     string
+	JobStep        string
+	JobDescription string
+	StartTime      time.Time
+	EndTime        time.Time
+	Lines          []string
+}
+
+// JobLogQuery filters the jobs returned by a search. Empty fields match every
+// job.
+type JobLogQuery struct {
+	// Repo is contained in the full name of the repo, ex. owner/repo.
+	Repo string
+	// Project is contained in the project name or the dir of the job.
+	Project string
+	// Step is the job step, ex. apply.
+	Step string
+	// Text is contained in a line of the output of the job. The search is
+	// case-insensitive.
+	Text string
+	// From and To bound the time the job completed.
+	From time.Time
+	To   time.Time
+	// Limit is the maximum number of jobs returned, DefaultJobLogSearchLimit
+	// if 0.
+	Limit int
+}
+
+// JobLogStore keeps the output of completed jobs.
+type JobLogStore interface {
+	Save(log JobLog) error
+	// Get returns the job with jobID or false if it isn't kept.
+	Get(jobID string) (JobLog, bool, error)
+	// Search returns the jobs matching query, the most recently completed
+	// first. If the query has text, the lines of the jobs are only the lines
+	// that contain it.
+	Search(query JobLogQuery) ([]JobLog, error)
+}
+
+// FileJobLogStore keeps each job as a JSON file in Dir.
+type FileJobLogStore struct {
+	Dir string
+	// Retention is how long jobs are kept after they complete. Jobs are kept
+	// forever if 0.
+	Retention time.Duration
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewFileJobLogStore returns a store that keeps jobs in dir, which is created
+// if it doesn't exist.
+func NewFileJobLogStore(dir string, retention time.Duration) (*FileJobLogStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "creating job log dir")
+	}
+	return &FileJobLogStore{Dir: dir, Retention: retention}, nil
+}
+
+func (f *FileJobLogStore) Save(log JobLog) error {
+	path, ok := f.path(log.JobID)
+	if !ok {
+		return errors.Errorf("invalid job id %q", log.JobID)
+	}
+	data, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.Wrap(err, "writing job log")
+	}
+	if f.Retention > 0 && time.Since(f.lastPrune) > jobLogPruneInterval {
+		f.lastPrune = time.Now()
+		return f.prune(f.lastPrune.Add(-f.Retention))
+	}
+	return nil
+}
+
+func (f *FileJobLogStore) Get(jobID string) (JobLog, bool, error) {
+	var log JobLog
+	path, ok := f.path(jobID)
+	if !ok {
+		return log, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return log, false, nil
+	}
+	if err != nil {
+		return log, false, errors.Wrap(err, "reading job log")
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return log, false, errors.Wrapf(err, "parsing job log %s", jobID)
+	}
+	return log, true, nil
+}
+
+func (f *FileJobLogStore) Search(query JobLogQuery) ([]JobLog, error) {
+	entries, err := os.ReadDir(f.Dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing job logs")
+	}
+	text := strings.ToLower(query.Text)
+
+	var found []JobLog
+	for _, entry := range entries {
+		jobID, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		log, ok, err := f.Get(jobID)
+		if err != nil {
+			return nil, err
+		}
+		// The job may have been pruned since the dir was listed.
+		if !ok || !log.matches(query) {
+			continue
+		}
+		if text != "" {
+			var lines []string
+			for _, line := range log.Lines {
+				if strings.Contains(strings.ToLower(line), text) {
+					lines = append(lines, line)
+				}
+			}
+			if len(lines) == 0 {
+				continue
+			}
+			log.Lines = lines[:min(len(lines), maxJobLogMatchingLines)]
+		}
+		found = append(found, log)
+	}
+
+	slices.SortFunc(found, func(a, b JobLog) int {
+		return b.EndTime.Compare(a.EndTime)
+	})
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultJobLogSearchLimit
+	}
+	return found[:min(len(found), limit)], nil
+}
+
+// matches returns true if the job matches all the fields of query other than
+// its text.
+func (l JobLog) matches(query JobLogQuery) bool {
+	if query.Repo != "" && !strings.Contains(strings.ToLower(l.RepoFullName), strings.ToLower(query.Repo)) {
+		return false
+	}
+	if query.Project != "" && !strings.Contains(l.ProjectName, query.Project) && !strings.Contains(l.Path, query.Project) {
+		return false
+	}
+	if query.Step != "" && l.JobStep != query.Step {
+		return false
+	}
+	if !query.From.IsZero() && l.EndTime.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && l.EndTime.After(query.To) {
+		return false
+	}
+	return true
+}
+
+// path returns the file of jobID or false if jobID could escape Dir.
+func (f *FileJobLogStore) path(jobID string) (string, bool) {
+	if jobID == "" || jobID != filepath.Base(jobID) || strings.HasPrefix(jobID, ".") {
+		return "", false
+	}
+	return filepath.Join(f.Dir, jobID+".json"), true
+}
+
+// prune deletes the jobs last written before cutoff.
+func (f *FileJobLogStore) prune(cutoff time.Time) error {
+	entries, err := os.ReadDir(f.Dir)
+	if err != nil {
+		return errors.Wrap(err, "listing job logs")
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(filepath.Join(f.Dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "deleting job log")
+			}
+		}
+	}
+	return nil
+}
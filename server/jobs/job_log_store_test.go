@@ -0,0 +1,134 @@
+package jobs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/jobs"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFileJobLogStore_Search(t *testing.T) {
+	store, err := jobs.NewFileJobLogStore(t.TempDir(), 0)
+	Ok(t, err)
+	now := time.Now()
+	for _, log := range []jobs.JobLog{
+		{
+			JobID:    "1",
+			PullInfo: jobs.PullInfo{RepoFullName: "owner/network", PullNum: 1, ProjectName: "vpc", Path: "vpc", Workspace: "default"},
+			JobStep:  "plan",
+			EndTime:  now.Add(-48 * time.Hour),
+			Lines:    []string{"  # aws_vpc.main will be created", "Plan: 1 to add, 0 to change, 0 to destroy."},
+		},
+		{
+			JobID:    "2",
+			PullInfo: jobs.PullInfo{RepoFullName: "owner/network", PullNum: 1, ProjectName: "vpc", Path: "vpc", Workspace: "default"},
+			JobStep:  "apply",
+			EndTime:  now.Add(-24 * time.Hour),
+			Lines:    []string{"aws_vpc.main: Creating...", "Apply complete! Resources: 1 added, 0 changed, 0 destroyed."},
+		},
+		{
+			JobID:    "3",
+			PullInfo: jobs.PullInfo{RepoFullName: "owner/dns", PullNum: 2, Path: "modules/zone", Workspace: "default"},
+			JobStep:  "apply",
+			EndTime:  now,
+			Lines:    []string{"Apply complete! Resources: 0 added, 1 changed, 0 destroyed."},
+		},
+	} {
+		Ok(t, store.Save(log))
+	}
+
+	cases := map[string]struct {
+		query     jobs.JobLogQuery
+		expJobIDs []string
+		expLines  []string
+	}{
+		"all, newest first": {
+			expJobIDs: []string{"3", "2", "1"},
+		},
+		"repo": {
+			query:     jobs.JobLogQuery{Repo: "NETWORK"},
+			expJobIDs: []string{"2", "1"},
+		},
+		"project name": {
+			query:     jobs.JobLogQuery{Project: "vpc"},
+			expJobIDs: []string{"2", "1"},
+		},
+		"dir": {
+			query:     jobs.JobLogQuery{Project: "modules/"},
+			expJobIDs: []string{"3"},
+		},
+		"step": {
+			query:     jobs.JobLogQuery{Step: "apply"},
+			expJobIDs: []string{"3", "2"},
+		},
+		"text": {
+			query:     jobs.JobLogQuery{Repo: "owner/network", Text: "aws_vpc.MAIN"},
+			expJobIDs: []string{"2", "1"},
+			expLines:  []string{"aws_vpc.main: Creating..."},
+		},
+		"time range": {
+			query:     jobs.JobLogQuery{From: now.Add(-36 * time.Hour), To: now.Add(-time.Hour)},
+			expJobIDs: []string{"2"},
+		},
+		"limit": {
+			query:     jobs.JobLogQuery{Limit: 1},
+			expJobIDs: []string{"3"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			found, err := store.Search(c.query)
+			Ok(t, err)
+			var jobIDs []string
+			for _, log := range found {
+				jobIDs = append(jobIDs, log.JobID)
+			}
+			Equals(t, c.expJobIDs, jobIDs)
+			if c.expLines != nil {
+				Equals(t, c.expLines, found[0].Lines)
+			}
+		})
+	}
+}
+
+func TestFileJobLogStore_Get(t *testing.T) {
+	store, err := jobs.NewFileJobLogStore(t.TempDir(), 0)
+	Ok(t, err)
+	Ok(t, store.Save(jobs.JobLog{JobID: "1", Lines: []string{"line"}}))
+
+	log, ok, err := store.Get("1")
+	Ok(t, err)
+	Assert(t, ok, "expected job 1 to be found")
+	Equals(t, []string{"line"}, log.Lines)
+
+	for _, jobID := range []string{"2", "../1", ".", ""} {
+		_, ok, err = store.Get(jobID)
+		Ok(t, err)
+		Assert(t, !ok, "expected job %q not to be found", jobID)
+	}
+	ErrEquals(t, `invalid job id "../1"`, store.Save(jobs.JobLog{JobID: "../1"}))
+}
+
+func TestFileJobLogStore_Prunes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := jobs.NewFileJobLogStore(dir, 24*time.Hour)
+	Ok(t, err)
+	Ok(t, store.Save(jobs.JobLog{JobID: "old"}))
+	old := time.Now().Add(-48 * time.Hour)
+	Ok(t, os.Chtimes(filepath.Join(dir, "old.json"), old, old))
+
+	// The first save after starting prunes the jobs older than the retention.
+	store, err = jobs.NewFileJobLogStore(dir, 24*time.Hour)
+	Ok(t, err)
+	Ok(t, store.Save(jobs.JobLog{JobID: "new"}))
+
+	_, ok, err := store.Get("old")
+	Ok(t, err)
+	Assert(t, !ok, "expected the old job to be pruned")
+	_, ok, err = store.Get("new")
+	Ok(t, err)
+	Assert(t, ok, "expected the new job to be kept")
+}
@@ -1,6 +1,7 @@
 package jobs
 
 import (
+	"slices"
 	"sync"
 	"time"
 
@@ -66,6 +67,14 @@ type AsyncProjectCommandOutputHandler struct {
 
 	// Tracks all the jobs for a pull request which is used for clean up after a pull request is closed.
 	pullToJobMapping sync.Map
+
+	// jobLogStore keeps the output of completed jobs if set.
+	jobLogStore JobLogStore
+	// runningJobs are the jobs that wrote output and haven't completed, and
+	// cancels the cancel functions of the processes they're running.
+	runningJobs     map[string]*RunningJob
+	cancels         map[string]*jobCancel
+	runningJobsLock sync.Mutex
 }
 
 //go:generate pegomock generate --package mocks -o mocks/mock_project_command_output_handler.go ProjectCommandOutputHandler
@@ -95,9 +104,12 @@ type ProjectCommandOutputHandler interface {
 	GetPullToJobMapping() []PullInfoWithJobIDs
 }
 
+// NewAsyncProjectCommandOutputHandler returns a handler that keeps the output
+// of completed jobs in jobLogStore, unless it's nil.
 func NewAsyncProjectCommandOutputHandler(
 	projectCmdOutput chan *ProjectCmdOutputLine,
 	logger logging.SimpleLogging,
+	jobLogStore JobLogStore,
 ) ProjectCommandOutputHandler {
 	return &AsyncProjectCommandOutputHandler{
 		projectCmdOutput:     projectCmdOutput,
@@ -105,6 +117,9 @@ func NewAsyncProjectCommandOutputHandler(
 		receiverBuffers:      map[string]map[chan string]bool{},
 		projectOutputBuffers: map[string]OutputBuffer{},
 		pullToJobMapping:     sync.Map{},
+		jobLogStore:          jobLogStore,
+		runningJobs:          map[string]*RunningJob{},
+		cancels:              map[string]*jobCancel{},
 	}
 }
 
@@ -186,9 +201,11 @@ func (p *AsyncProjectCommandOutputHandler) Register(jobID string, receiver chan
 func (p *AsyncProjectCommandOutputHandler) Handle() {
 	for msg := range p.projectCmdOutput {
 		if msg.OperationComplete {
-			p.completeJob(msg.JobID)
+			lines := p.completeJob(msg.JobID)
+			p.saveJobLog(msg, lines)
 			continue
 		}
+		p.trackRunningJob(msg)
 
 		// Add job to pullToJob mapping
 		if _, ok := p.pullToJobMapping.Load(msg.JobInfo.PullInfo); !ok {
@@ -208,7 +225,8 @@ func (p *AsyncProjectCommandOutputHandler) Handle() {
 	}
 }
 
-func (p *AsyncProjectCommandOutputHandler) completeJob(jobID string) {
+// completeJob marks the job as complete and returns its output.
+func (p *AsyncProjectCommandOutputHandler) completeJob(jobID string) []string {
 	p.projectOutputBuffersLock.Lock()
 	p.receiverBuffersLock.Lock()
 	defer func() {
@@ -217,7 +235,8 @@ func (p *AsyncProjectCommandOutputHandler) completeJob(jobID string) {
 	}()
 
 	// Update operation status to complete
-	if outputBuffer, ok := p.projectOutputBuffers[jobID]; ok {
+	outputBuffer, ok := p.projectOutputBuffers[jobID]
+	if ok {
 		outputBuffer.OperationComplete = true
 		p.projectOutputBuffers[jobID] = outputBuffer
 	}
@@ -229,6 +248,29 @@ func (p *AsyncProjectCommandOutputHandler) completeJob(jobID string) {
 		}
 	}
 
+	return outputBuffer.Buffer
+}
+
+// saveJobLog keeps the output of the job completed by msg in the job log
+// store. Jobs without output aren't kept.
+func (p *AsyncProjectCommandOutputHandler) saveJobLog(msg *ProjectCmdOutputLine, lines []string) {
+	startTime, ok := p.completeRunningJob(msg.JobID)
+	if p.jobLogStore == nil || !ok {
+		return
+	}
+	err := p.jobLogStore.Save(JobLog{
+		JobID:          msg.JobID,
+		PullInfo:       msg.JobInfo.PullInfo,
+		HeadCommit:     msg.JobInfo.HeadCommit,
+		JobStep:        msg.JobInfo.JobStep,
+		JobDescription: msg.JobInfo.JobDescription,
+		StartTime:      startTime,
+		EndTime:        time.Now(),
+		Lines:          slices.Clone(lines),
+	})
+	if err != nil {
+		p.logger.Err("saving output of job %s: %s", msg.JobID, err)
+	}
 }
 
 func (p *AsyncProjectCommandOutputHandler) addChan(ch chan string, jobID string) {
@@ -48,6 +48,7 @@ func createProjectCommandOutputHandler(t *testing.T) jobs.ProjectCommandOutputHa
 	prjCmdOutputHandler := jobs.NewAsyncProjectCommandOutputHandler(
 		prjCmdOutputChan,
 		logger,
+		nil,
 	)
 
 	go func() {
@@ -252,3 +253,38 @@ func TestProjectCommandOutputHandler(t *testing.T) {
 		assert.True(t, <-opComplete)
 	})
 }
+
+func TestProjectCommandOutputHandler_SavesJobLogs(t *testing.T) {
+	store, err := jobs.NewFileJobLogStore(t.TempDir(), 0)
+	Ok(t, err)
+	prjCmdOutputChan := make(chan *jobs.ProjectCmdOutputLine)
+	projectOutputHandler := jobs.NewAsyncProjectCommandOutputHandler(prjCmdOutputChan, logging.NewNoopLogger(t), store)
+	go projectOutputHandler.Handle()
+
+	ctx := createTestProjectCmdContext(t)
+	ctx.BaseRepo.FullName = "test-org/test-repo"
+	ctx.CommandName = command.Apply
+	projectOutputHandler.Send(ctx, "aws_s3_bucket.a: Destroying...", false)
+	projectOutputHandler.Send(ctx, "Apply complete! Resources: 0 added, 0 changed, 1 destroyed.", false)
+	projectOutputHandler.Send(ctx, "", true)
+	// The channel is unbuffered, so the completion has been handled once the
+	// next message is received.
+	other := createTestProjectCmdContext(t)
+	other.JobID = "5678"
+	projectOutputHandler.Send(other, "running", false)
+
+	log, ok, err := store.Get(ctx.JobID)
+	Ok(t, err)
+	Assert(t, ok, "expected the output of the job to be kept")
+	Equals(t, "test-org/test-repo", log.RepoFullName)
+	Equals(t, "test-project", log.ProjectName)
+	Equals(t, "apply", log.JobStep)
+	Equals(t, "234r232432", log.HeadCommit)
+	Equals(t, []string{"aws_s3_bucket.a: Destroying...", "Apply complete! Resources: 0 added, 0 changed, 1 destroyed."}, log.Lines)
+	Assert(t, !log.StartTime.After(log.EndTime), "expected the job to start before it completed")
+
+	// Jobs that are still running aren't kept.
+	_, ok, err = store.Get(other.JobID)
+	Ok(t, err)
+	Assert(t, !ok, "expected the running job not to be kept")
+}
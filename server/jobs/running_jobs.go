@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"sort"
+	"time"
+)
+
+// RunningJob is a job that's writing output and hasn't completed.
+type RunningJob struct {
+	JobID string
+	JobInfo
+	StartTime time.Time
+	// LastOutputTime is when the job last wrote a line, to spot jobs that
+	// are stuck.
+	LastOutputTime time.Time
+	// Lines is the number of lines the job wrote so far.
+	Lines int
+	// LastLine is the last line the job wrote, ex. the resource it's
+	// applying.
+	LastLine string
+	// Cancelable is true if the job is running a process that can be
+	// canceled.
+	Cancelable bool
+	// Canceled is true once the job was asked to cancel.
+	Canceled bool
+}
+
+// JobBoard lists the running jobs and cancels them, for the running jobs view
+// of the web UI.
+type JobBoard interface {
+	// RunningJobs returns the running jobs, the oldest first.
+	RunningJobs() []RunningJob
+	// Cancel cancels the process the job is running and the ones it starts
+	// next. It returns false if the job isn't running.
+	Cancel(jobID string) bool
+}
+
+// JobCanceler lets the processes run by jobs be canceled from the job board.
+type JobCanceler interface {
+	// RegisterCancel registers cancel as the function canceling the process
+	// the job is running. It's called right away if the job was already
+	// canceled. The returned function must be called once the process exits.
+	RegisterCancel(jobID string, cancel func()) (deregister func())
+}
+
+// jobCancel is the registered cancel function of a job's process.
+type jobCancel struct {
+	cancel func()
+}
+
+// trackRunningJob records the output line msg of a running job.
+func (p *AsyncProjectCommandOutputHandler) trackRunningJob(msg *ProjectCmdOutputLine) {
+	p.runningJobsLock.Lock()
+	defer p.runningJobsLock.Unlock()
+	now := time.Now()
+	job, ok := p.runningJobs[msg.JobID]
+	if !ok {
+		job = &RunningJob{JobID: msg.JobID, JobInfo: msg.JobInfo, StartTime: now}
+		p.runningJobs[msg.JobID] = job
+	}
+	job.LastOutputTime = now
+	job.Lines++
+	job.LastLine = msg.Line
+}
+
+// completeRunningJob stops tracking a job once it completes and returns when
+// it started. It returns false if the job never wrote output.
+func (p *AsyncProjectCommandOutputHandler) completeRunningJob(jobID string) (time.Time, bool) {
+	p.runningJobsLock.Lock()
+	defer p.runningJobsLock.Unlock()
+	job, ok := p.runningJobs[jobID]
+	if !ok {
+		return time.Time{}, false
+	}
+	delete(p.runningJobs, jobID)
+	return job.StartTime, true
+}
+
+func (p *AsyncProjectCommandOutputHandler) RunningJobs() []RunningJob {
+	p.runningJobsLock.Lock()
+	defer p.runningJobsLock.Unlock()
+	running := make([]RunningJob, 0, len(p.runningJobs))
+	for _, job := range p.runningJobs {
+		j := *job
+		j.Cancelable = p.cancels[job.JobID] != nil
+		running = append(running, j)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].StartTime.Before(running[j].StartTime)
+	})
+	return running
+}
+
+func (p *AsyncProjectCommandOutputHandler) Cancel(jobID string) bool {
+	p.runningJobsLock.Lock()
+	defer p.runningJobsLock.Unlock()
+	job, ok := p.runningJobs[jobID]
+	if !ok {
+		return false
+	}
+	job.Canceled = true
+	if c := p.cancels[jobID]; c != nil {
+		c.cancel()
+	}
+	return true
+}
+
+func (p *AsyncProjectCommandOutputHandler) RegisterCancel(jobID string, cancel func()) func() {
+	p.runningJobsLock.Lock()
+	defer p.runningJobsLock.Unlock()
+	c := &jobCancel{cancel: cancel}
+	p.cancels[jobID] = c
+	if job, ok := p.runningJobs[jobID]; ok && job.Canceled {
+		cancel()
+	}
+	return func() {
+		p.runningJobsLock.Lock()
+		defer p.runningJobsLock.Unlock()
+		if p.cancels[jobID] == c {
+			delete(p.cancels, jobID)
+		}
+	}
+}
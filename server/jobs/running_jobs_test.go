@@ -0,0 +1,63 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAsyncProjectCommandOutputHandler_RunningJobs(t *testing.T) {
+	prjCmdOutputChan := make(chan *jobs.ProjectCmdOutputLine)
+	projectOutputHandler := jobs.NewAsyncProjectCommandOutputHandler(prjCmdOutputChan, logging.NewNoopLogger(t), nil)
+	go projectOutputHandler.Handle()
+	board := projectOutputHandler.(jobs.JobBoard)
+	canceler := projectOutputHandler.(jobs.JobCanceler)
+
+	ctx := createTestProjectCmdContext(t)
+	ctx.BaseRepo.FullName = "test-org/test-repo"
+	ctx.CommandName = command.Apply
+	other := createTestProjectCmdContext(t)
+	other.JobID = "5678"
+	projectOutputHandler.Send(ctx, "aws_s3_bucket.a: Creating...", false)
+	projectOutputHandler.Send(ctx, "aws_s3_bucket.a: Still creating... [10s elapsed]", false)
+	// The channel is unbuffered, so the previous line has been handled once
+	// the next message is received.
+	projectOutputHandler.Send(other, "running", false)
+	projectOutputHandler.Send(other, "still running", false)
+
+	running := board.RunningJobs()
+	Equals(t, 2, len(running))
+	job := running[0]
+	Equals(t, ctx.JobID, job.JobID)
+	Equals(t, "test-org/test-repo", job.RepoFullName)
+	Equals(t, "apply", job.JobStep)
+	Equals(t, 2, job.Lines)
+	Equals(t, "aws_s3_bucket.a: Still creating... [10s elapsed]", job.LastLine)
+	Assert(t, !job.Cancelable, "expected the job not to be cancelable without a registered cancel")
+	Assert(t, !job.LastOutputTime.Before(job.StartTime), "expected the last output after the start")
+
+	canceled := 0
+	deregister := canceler.RegisterCancel(ctx.JobID, func() { canceled++ })
+	Assert(t, board.RunningJobs()[0].Cancelable, "expected the job to be cancelable")
+	Assert(t, board.Cancel(ctx.JobID), "expected the running job to be canceled")
+	Equals(t, 1, canceled)
+	Assert(t, board.RunningJobs()[0].Canceled, "expected the job to be marked canceled")
+	deregister()
+	Assert(t, !board.RunningJobs()[0].Cancelable, "expected the job not to be cancelable once deregistered")
+
+	// The processes the job starts after it was canceled are canceled right
+	// away.
+	canceler.RegisterCancel(ctx.JobID, func() { canceled++ })()
+	Equals(t, 2, canceled)
+
+	Assert(t, !board.Cancel("missing"), "expected a job that isn't running not to be canceled")
+
+	projectOutputHandler.Send(ctx, "", true)
+	projectOutputHandler.Send(other, "still running", false)
+	running = board.RunningJobs()
+	Equals(t, 1, len(running))
+	Equals(t, other.JobID, running[0].JobID)
+}
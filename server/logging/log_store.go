@@ -0,0 +1,201 @@
+package logging
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys of the fields added to the logs of commands so all the logs of a pull
+// request, command or project can be correlated.
+const (
+	RepoKey    = "repo"
+	PullKey    = "pull"
+	CommandKey = "command"
+	ProjectKey = "project"
+	TraceIDKey = "trace-id"
+)
+
+// maxCommandLogEntries is the maximum number of log entries kept for a
+// command. Later entries are dropped.
+const maxCommandLogEntries = 10000
+
+// LogEntry is a log line written while running a command.
+type LogEntry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	// Fields are the structured fields of the entry other than the ones of
+	// its command, ex. the project.
+	Fields map[string]interface{}
+}
+
+// CommandLogs are the logs of a run of a command on a pull request.
+type CommandLogs struct {
+	TraceID string
+	Repo    string
+	Pull    int
+	Command string
+	Entries []LogEntry
+	// Truncated is true if entries were dropped since the command logged more
+	// than maxCommandLogEntries.
+	Truncated bool
+}
+
+// LogStore keeps the logs of the last commands in memory so they can be
+// fetched by pull request or trace ID. Logs are correlated with the TraceIDKey
+// field, so only loggers created with it are kept.
+type LogStore struct {
+	// Size is the number of commands whose logs are kept.
+	Size int
+
+	mu       sync.Mutex
+	commands []*CommandLogs
+}
+
+// NewLogStore returns a store that keeps the logs of the last size commands.
+func NewLogStore(size int) *LogStore {
+	return &LogStore{Size: size}
+}
+
+// Find returns the logs of the commands run on pull of repo, newest first.
+// If traceID is set, only the logs of that command are returned and repo and
+// pull may be empty. If command is set, only the logs of that command name are
+// returned.
+func (s *LogStore) Find(repo string, pull int, command string, traceID string) []CommandLogs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found []CommandLogs
+	for _, logs := range slices.Backward(s.commands) {
+		if traceID != "" && logs.TraceID != traceID {
+			continue
+		}
+		if traceID == "" && (logs.Repo != repo || logs.Pull != pull) {
+			continue
+		}
+		if command != "" && logs.Command != command {
+			continue
+		}
+		found = append(found, CommandLogs{
+			TraceID:   logs.TraceID,
+			Repo:      logs.Repo,
+			Pull:      logs.Pull,
+			Command:   logs.Command,
+			Entries:   slices.Clone(logs.Entries),
+			Truncated: logs.Truncated,
+		})
+	}
+	return found
+}
+
+func (s *LogStore) add(entry zapcore.Entry, fields map[string]interface{}) {
+	traceID, _ := fields[TraceIDKey].(string)
+	if traceID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var logs *CommandLogs
+	for _, l := range slices.Backward(s.commands) {
+		if l.TraceID == traceID {
+			logs = l
+			break
+		}
+	}
+	if logs == nil {
+		logs = &CommandLogs{TraceID: traceID}
+		logs.Repo, _ = fields[RepoKey].(string)
+		logs.Command, _ = fields[CommandKey].(string)
+		// The pull is logged as a string.
+		pull, _ := fields[PullKey].(string)
+		logs.Pull, _ = strconv.Atoi(pull)
+		s.commands = append(s.commands, logs)
+		if len(s.commands) > s.Size {
+			s.commands = slices.Delete(s.commands, 0, len(s.commands)-s.Size)
+		}
+	}
+	if len(logs.Entries) >= maxCommandLogEntries {
+		logs.Truncated = true
+		return
+	}
+
+	for _, key := range []string{TraceIDKey, RepoKey, PullKey, CommandKey} {
+		delete(fields, key)
+	}
+	logs.Entries = append(logs.Entries, LogEntry{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  fields,
+	})
+}
+
+func (s *LogStore) core(enabler zapcore.LevelEnabler) zapcore.Core {
+	return &logStoreCore{LevelEnabler: enabler, store: s}
+}
+
+// logStoreCore is a zap core that adds the entries of loggers with a trace ID
+// to a LogStore.
+type logStoreCore struct {
+	zapcore.LevelEnabler
+	store  *LogStore
+	fields []zapcore.Field
+	traced bool
+}
+
+func (c *logStoreCore) With(fields []zapcore.Field) zapcore.Core {
+	traced := c.traced || slices.ContainsFunc(fields, func(f zapcore.Field) bool {
+		return f.Key == TraceIDKey
+	})
+	return &logStoreCore{
+		LevelEnabler: c.LevelEnabler,
+		store:        c.store,
+		fields:       append(slices.Clip(c.fields), fields...),
+		traced:       traced,
+	}
+}
+
+func (c *logStoreCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.traced && c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *logStoreCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	c.store.add(entry, flatten(enc.Fields))
+	return nil
+}
+
+func (c *logStoreCore) Sync() error {
+	return nil
+}
+
+// flatten moves the fields of namespaces, like the "json" namespace of the
+// structured logger, to the top level.
+func flatten(fields map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if namespace, ok := value.(map[string]interface{}); ok {
+			for k, v := range flatten(namespace) {
+				flat[k] = v
+			}
+			continue
+		}
+		flat[key] = value
+	}
+	return flat
+}
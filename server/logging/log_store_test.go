@@ -0,0 +1,66 @@
+package logging_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogStore(t *testing.T) {
+	store := logging.NewLogStore(2)
+	logger, err := logging.NewStructuredLoggerWithLogStore(logging.Info, store)
+	assert.NoError(t, err)
+
+	logger.Info("not logged by a command")
+	plan := logger.WithHistory(logging.RepoKey, "owner/repo", logging.PullKey, "1", logging.CommandKey, "plan", logging.TraceIDKey, "trace-1")
+	plan.Info("running plan")
+	plan.Debug("below the log level")
+	plan.WithHistory(logging.ProjectKey, "infra").With("duration", "1s").Warn("plan of %s failed", "infra")
+	apply := logger.WithHistory(logging.RepoKey, "owner/repo", logging.PullKey, "1", logging.CommandKey, "apply", logging.TraceIDKey, "trace-2")
+	apply.Info("running apply")
+
+	commands := store.Find("owner/repo", 1, "", "")
+	assert.Equal(t, 2, len(commands))
+	assert.Equal(t, "trace-2", commands[0].TraceID)
+	planLogs := commands[1]
+	assert.Equal(t, "trace-1", planLogs.TraceID)
+	assert.Equal(t, "owner/repo", planLogs.Repo)
+	assert.Equal(t, 1, planLogs.Pull)
+	assert.Equal(t, "plan", planLogs.Command)
+	assert.Equal(t, 2, len(planLogs.Entries))
+	assert.Equal(t, "running plan", planLogs.Entries[0].Message)
+	assert.Equal(t, "info", planLogs.Entries[0].Level)
+	assert.Equal(t, map[string]interface{}{}, planLogs.Entries[0].Fields)
+	assert.Equal(t, "plan of infra failed", planLogs.Entries[1].Message)
+	assert.Equal(t, "warn", planLogs.Entries[1].Level)
+	assert.Equal(t, map[string]interface{}{"project": "infra", "duration": "1s"}, planLogs.Entries[1].Fields)
+
+	assert.Equal(t, []string{"trace-1"}, traceIDs(store.Find("owner/repo", 1, "plan", "")))
+	assert.Equal(t, []string{"trace-2"}, traceIDs(store.Find("", 0, "", "trace-2")))
+	assert.Empty(t, store.Find("owner/repo", 2, "", ""))
+
+	// Only the logs of the last 2 commands are kept.
+	logger.WithHistory(logging.RepoKey, "owner/repo", logging.PullKey, "2", logging.CommandKey, "plan", logging.TraceIDKey, "trace-3").Info("running plan")
+	assert.Equal(t, []string{"trace-2"}, traceIDs(store.Find("owner/repo", 1, "", "")))
+	assert.Equal(t, []string{"trace-3"}, traceIDs(store.Find("owner/repo", 2, "", "")))
+}
+
+func TestStructuredLoggerSharesHistory(t *testing.T) {
+	logger := logging.NewNoopLogger(t).WithHistory()
+	projectLogger := logger.WithHistory(logging.ProjectKey, "infra")
+
+	logger.Info("running plan")
+	projectLogger.Info("planning infra")
+
+	assert.Equal(t, "[INFO] running plan\n[INFO] planning infra\n", logger.GetHistory())
+	assert.Equal(t, "", logging.NewNoopLogger(t).GetHistory())
+}
+
+func traceIDs(commands []logging.CommandLogs) []string {
+	var ids []string
+	for _, c := range commands {
+		ids = append(ids, c.TraceID)
+	}
+	return ids
+}
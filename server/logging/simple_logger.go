@@ -19,6 +19,7 @@ package logging
 import (
 	"bytes"
 	"fmt"
+	"sync"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -69,7 +70,14 @@ type StructuredLogger struct {
 	// This doesn't really make sense to keep given that structured logging
 	// gives us the ability to query our logs across multiple dimensions
 	// I don't believe we should mix this in with atlantis commands and expose this to the user
-	history bytes.Buffer
+	history *history
+}
+
+// history is shared by the loggers of a command, including the loggers of
+// projects that run in parallel.
+type history struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
 }
 
 func NewStructuredLoggerFromLevel(lvl LogLevel) (SimpleLogging, error) {
@@ -86,8 +94,19 @@ func NewStructuredLogger() (SimpleLogging, error) {
 	return newStructuredLogger(cfg)
 }
 
-func newStructuredLogger(cfg zap.Config) (*StructuredLogger, error) {
-	baseLogger, err := cfg.Build()
+// NewStructuredLoggerWithLogStore returns a logger that also keeps the logs of
+// commands in store.
+func NewStructuredLoggerWithLogStore(lvl LogLevel, store *LogStore) (SimpleLogging, error) {
+	cfg := zap.NewProductionConfig()
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.Level = zap.NewAtomicLevelAt(lvl.zLevel)
+	return newStructuredLogger(cfg, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, store.core(cfg.Level))
+	}))
+}
+
+func newStructuredLogger(cfg zap.Config, opts ...zap.Option) (*StructuredLogger, error) {
+	baseLogger, err := cfg.Build(opts...)
 
 	baseLogger = baseLogger.
 		// ensures that the caller doesn't just say logging/simple_logger each time
@@ -122,12 +141,20 @@ func (l *StructuredLogger) WithHistory(a ...interface{}) SimpleLogging {
 	// ensure that the history is kept across loggers.
 	logger.keepHistory = true
 	logger.history = l.history
+	if logger.history == nil {
+		logger.history = &history{}
+	}
 
 	return logger
 }
 
 func (l *StructuredLogger) GetHistory() string {
-	return l.history.String()
+	if l.history == nil {
+		return ""
+	}
+	l.history.mu.Lock()
+	defer l.history.mu.Unlock()
+	return l.history.buf.String()
 }
 
 func (l *StructuredLogger) Debug(format string, a ...interface{}) {
@@ -178,7 +205,9 @@ func (l *StructuredLogger) saveToHistory(lvl LogLevel, format string, a ...inter
 		return
 	}
 	msg := fmt.Sprintf(format, a...)
-	l.history.WriteString(fmt.Sprintf("[%s] %s\n", lvl.shortStr, msg))
+	l.history.mu.Lock()
+	defer l.history.mu.Unlock()
+	l.history.buf.WriteString(fmt.Sprintf("[%s] %s\n", lvl.shortStr, msg))
 }
 
 // NewNoopLogger creates a logger instance that discards all logs and never
@@ -5,4 +5,8 @@ const (
 	ExecutionSuccessMetric = "execution_success"
 	ExecutionErrorMetric   = "execution_error"
 	ExecutionFailureMetric = "execution_failure"
+	ExecutionRetryMetric   = "execution_retry"
+	// ExecutionAuthFailureMetric counts the commands that failed because the
+	// provider rejected the credentials, tagged with the provider.
+	ExecutionAuthFailureMetric = "execution_auth_failure"
 )
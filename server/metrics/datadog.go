@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cactus/go-statsd-client/v5/statsd"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// datadogReporter is a tally reporter that sends metrics to a DogStatsD agent
+// with their tags, which the statsd reporter drops.
+type datadogReporter struct {
+	client datadogClient
+	// tags are added to every metric.
+	tags map[string]string
+}
+
+// datadogClient is the statsd client of the reporter. statsd.Statter doesn't
+// include float gauges but the clients it's implemented by send them.
+type datadogClient interface {
+	statsd.Statter
+	GaugeFloat(stat string, value float64, rate float32, tags ...statsd.Tag) error
+}
+
+func newDatadogReporter(cfg valid.Metrics) (tally.StatsReporter, error) {
+	client, err := statsd.NewClientWithConfig(&statsd.ClientConfig{
+		Address:   strings.Join([]string{cfg.Datadog.Host, cfg.Datadog.Port}, ":"),
+		TagFormat: statsd.SuffixOctothorpe,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing dogstatsd client")
+	}
+	dogstatsd, ok := client.(datadogClient)
+	if !ok {
+		return nil, errors.New("dogstatsd client doesn't support float gauges")
+	}
+	return &datadogReporter{client: dogstatsd, tags: cfg.Datadog.Tags}, nil
+}
+
+// Capabilities interface.
+
+func (r *datadogReporter) Reporting() bool {
+	return true
+}
+
+func (r *datadogReporter) Tagging() bool {
+	return true
+}
+
+func (r *datadogReporter) Capabilities() tally.Capabilities {
+	return r
+}
+
+// Reporter interface.
+
+func (r *datadogReporter) Flush() {
+	// The client isn't buffered.
+}
+
+func (r *datadogReporter) Close() error {
+	return r.client.Close()
+}
+
+func (r *datadogReporter) ReportCounter(name string, tags map[string]string, value int64) {
+	r.client.Inc(name, value, 1.0, r.statsdTags(tags)...) // nolint: errcheck
+}
+
+func (r *datadogReporter) ReportGauge(name string, tags map[string]string, value float64) {
+	r.client.GaugeFloat(name, value, 1.0, r.statsdTags(tags)...) // nolint: errcheck
+}
+
+func (r *datadogReporter) ReportTimer(name string, tags map[string]string, interval time.Duration) {
+	r.client.TimingDuration(name, interval, 1.0, r.statsdTags(tags)...) // nolint: errcheck
+}
+
+// Histogram samples are counted with the upper bound of their bucket as the
+// bucket tag.
+
+func (r *datadogReporter) ReportHistogramValueSamples(name string, tags map[string]string, _ tally.Buckets, _, bucketUpperBound float64, samples int64) {
+	bucket := strconv.FormatFloat(bucketUpperBound, 'g', -1, 64)
+	r.client.Inc(name, samples, 1.0, append(r.statsdTags(tags), statsd.Tag{"bucket", bucket})...) // nolint: errcheck
+}
+
+func (r *datadogReporter) ReportHistogramDurationSamples(name string, tags map[string]string, _ tally.Buckets, _, bucketUpperBound time.Duration, samples int64) {
+	r.client.Inc(name, samples, 1.0, append(r.statsdTags(tags), statsd.Tag{"bucket", bucketUpperBound.String()})...) // nolint: errcheck
+}
+
+// statsdTags returns the reporter's tags and tags, sorted by name. tags take
+// precedence.
+func (r *datadogReporter) statsdTags(tags map[string]string) []statsd.Tag {
+	merged := make(map[string]string, len(r.tags)+len(tags))
+	for k, v := range r.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	statsdTags := make([]statsd.Tag, 0, len(merged))
+	for _, k := range sortedKeys(merged) {
+		statsdTags = append(statsdTags, statsd.Tag{k, merged[k]})
+	}
+	return statsdTags
+}
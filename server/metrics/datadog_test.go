@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDatadogReporter(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Ok(t, err)
+	defer agent.Close() // nolint: errcheck
+	_, port, err := net.SplitHostPort(agent.LocalAddr().String())
+	Ok(t, err)
+
+	reporter, err := newDatadogReporter(valid.Metrics{Datadog: &valid.Datadog{
+		Host: "127.0.0.1",
+		Port: port,
+		Tags: map[string]string{"env": "prod", "base_repo": "overridden"},
+	}})
+	Ok(t, err)
+	defer reporter.(*datadogReporter).Close() // nolint: errcheck
+
+	read := func() string {
+		Ok(t, agent.SetReadDeadline(time.Now().Add(5*time.Second)))
+		buf := make([]byte, 1024)
+		n, _, err := agent.ReadFrom(buf)
+		Ok(t, err)
+		return string(buf[:n])
+	}
+
+	reporter.ReportCounter("atlantis.cmd.plan.success", map[string]string{"base_repo": "owner/repo"}, 2)
+	Equals(t, "atlantis.cmd.plan.success:2|c|#base_repo:owner/repo,env:prod", read())
+	reporter.ReportTimer("atlantis.cmd.plan.execution_time", nil, 1500*time.Millisecond)
+	Equals(t, "atlantis.cmd.plan.execution_time:1500|ms|#base_repo:overridden,env:prod", read())
+}
@@ -0,0 +1,418 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// otlpMetricsPath is the path of the metrics endpoint of an OTLP/HTTP
+// receiver.
+const otlpMetricsPath = "/v1/metrics"
+
+// otlpCumulative is the AGGREGATION_TEMPORALITY_CUMULATIVE enum value.
+const otlpCumulative = 2
+
+// otlpTimerBounds are the histogram bucket bounds in seconds of timers. They
+// range from API calls to long Terraform runs.
+var otlpTimerBounds = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+
+// otlpReporter is a tally reporter that exports metrics to an OpenTelemetry
+// collector with OTLP/HTTP and JSON encoding. Metrics are aggregated in
+// memory and exported with cumulative temporality every interval, and when
+// it's closed.
+type otlpReporter struct {
+	endpoint string
+	headers  map[string]string
+	interval time.Duration
+	client   *http.Client
+	logger   logging.SimpleLogging
+	now      func() time.Time
+
+	mu         sync.Mutex
+	start      time.Time
+	lastExport time.Time
+	counters   map[string]*otlpCounter
+	gauges     map[string]*otlpGauge
+	histograms map[string]*otlpHistogram
+}
+
+type otlpCounter struct {
+	name  string
+	tags  map[string]string
+	value int64
+}
+
+type otlpGauge struct {
+	name  string
+	tags  map[string]string
+	value float64
+}
+
+type otlpHistogram struct {
+	name   string
+	tags   map[string]string
+	unit   string
+	bounds []float64
+	counts []uint64
+	sum    *float64
+}
+
+func newOTLPReporter(cfg *valid.OTLP, logger logging.SimpleLogging) *otlpReporter {
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = valid.DefaultOTLPInterval
+	}
+	now := time.Now()
+	return &otlpReporter{
+		endpoint:   strings.TrimSuffix(cfg.Endpoint, "/") + otlpMetricsPath,
+		headers:    cfg.Headers,
+		interval:   interval,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+		now:        time.Now,
+		start:      now,
+		lastExport: now,
+		counters:   make(map[string]*otlpCounter),
+		gauges:     make(map[string]*otlpGauge),
+		histograms: make(map[string]*otlpHistogram),
+	}
+}
+
+// Capabilities interface.
+
+func (r *otlpReporter) Reporting() bool {
+	return true
+}
+
+func (r *otlpReporter) Tagging() bool {
+	return true
+}
+
+func (r *otlpReporter) Capabilities() tally.Capabilities {
+	return r
+}
+
+// Reporter interface.
+
+// Flush is called by tally after each report. Metrics are only exported once
+// the interval elapsed.
+func (r *otlpReporter) Flush() {
+	r.mu.Lock()
+	due := r.now().Sub(r.lastExport) >= r.interval
+	r.mu.Unlock()
+	if due {
+		r.export() // nolint: errcheck
+	}
+}
+
+// Close exports the metrics that weren't exported yet.
+func (r *otlpReporter) Close() error {
+	return r.export()
+}
+
+func (r *otlpReporter) ReportCounter(name string, tags map[string]string, value int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := otlpKey(name, tags)
+	c, ok := r.counters[key]
+	if !ok {
+		c = &otlpCounter{name: name, tags: tags}
+		r.counters[key] = c
+	}
+	// tally reports the change since the last report.
+	c.value += value
+}
+
+func (r *otlpReporter) ReportGauge(name string, tags map[string]string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[otlpKey(name, tags)] = &otlpGauge{name: name, tags: tags, value: value}
+}
+
+func (r *otlpReporter) ReportTimer(name string, tags map[string]string, interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := r.histogram(name, tags, "s", otlpTimerBounds)
+	seconds := interval.Seconds()
+	h.counts[sort.SearchFloat64s(h.bounds, seconds)]++
+	if h.sum == nil {
+		h.sum = new(float64)
+	}
+	*h.sum += seconds
+}
+
+func (r *otlpReporter) ReportHistogramValueSamples(name string, tags map[string]string, buckets tally.Buckets, _, bucketUpperBound float64, samples int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := r.histogram(name, tags, "", finiteBounds(buckets.AsValues()))
+	h.counts[sort.SearchFloat64s(h.bounds, bucketUpperBound)] += uint64(samples)
+}
+
+func (r *otlpReporter) ReportHistogramDurationSamples(name string, tags map[string]string, buckets tally.Buckets, _, bucketUpperBound time.Duration, samples int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var bounds []float64
+	for _, d := range buckets.AsDurations() {
+		bounds = append(bounds, d.Seconds())
+	}
+	h := r.histogram(name, tags, "s", finiteBounds(bounds))
+	h.counts[sort.SearchFloat64s(h.bounds, bucketUpperBound.Seconds())] += uint64(samples)
+}
+
+// histogram returns the histogram of name and tags, creating it with bounds
+// if it doesn't exist. r.mu must be held.
+func (r *otlpReporter) histogram(name string, tags map[string]string, unit string, bounds []float64) *otlpHistogram {
+	key := otlpKey(name, tags)
+	h, ok := r.histograms[key]
+	if !ok {
+		// The last bucket counts the values above the last bound.
+		h = &otlpHistogram{name: name, tags: tags, unit: unit, bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+		r.histograms[key] = h
+	}
+	return h
+}
+
+// finiteBounds drops the bounds tally adds for the buckets of infinite
+// values.
+func finiteBounds(bounds []float64) []float64 {
+	var finite []float64
+	for _, b := range bounds {
+		if b != math.MaxFloat64 && b != -math.MaxFloat64 && !math.IsInf(b, 0) {
+			finite = append(finite, b)
+		}
+	}
+	return finite
+}
+
+func (r *otlpReporter) export() error {
+	r.mu.Lock()
+	now := r.now()
+	r.lastExport = now
+	request := r.request(now)
+	r.mu.Unlock()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	if err := r.send(body); err != nil {
+		r.logger.Warn("unable to export metrics to %s: %s", r.endpoint, err)
+		return err
+	}
+	return nil
+}
+
+func (r *otlpReporter) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range r.headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("collector returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// request builds an ExportMetricsServiceRequest in the JSON encoding of
+// OTLP. r.mu must be held.
+func (r *otlpReporter) request(now time.Time) otlpRequest {
+	start := strconv.FormatInt(r.start.UnixNano(), 10)
+	end := strconv.FormatInt(now.UnixNano(), 10)
+
+	var metrics []otlpMetric
+	for _, key := range sortedKeys(r.counters) {
+		c := r.counters[key]
+		metrics = append(metrics, otlpMetric{
+			Name: c.name,
+			Sum: &otlpSum{
+				DataPoints: []otlpDataPoint{{
+					Attributes:        otlpAttributes(c.tags),
+					StartTimeUnixNano: start,
+					TimeUnixNano:      end,
+					AsInt:             strconv.FormatInt(c.value, 10),
+				}},
+				AggregationTemporality: otlpCumulative,
+				IsMonotonic:            true,
+			},
+		})
+	}
+	for _, key := range sortedKeys(r.gauges) {
+		g := r.gauges[key]
+		value := g.value
+		metrics = append(metrics, otlpMetric{
+			Name: g.name,
+			Gauge: &otlpGaugeData{
+				DataPoints: []otlpDataPoint{{
+					Attributes:   otlpAttributes(g.tags),
+					TimeUnixNano: end,
+					AsDouble:     &value,
+				}},
+			},
+		})
+	}
+	for _, key := range sortedKeys(r.histograms) {
+		h := r.histograms[key]
+		var count uint64
+		bucketCounts := make([]string, len(h.counts))
+		for i, c := range h.counts {
+			count += c
+			bucketCounts[i] = strconv.FormatUint(c, 10)
+		}
+		var sum *float64
+		if h.sum != nil {
+			s := *h.sum
+			sum = &s
+		}
+		metrics = append(metrics, otlpMetric{
+			Name: h.name,
+			Unit: h.unit,
+			Histogram: &otlpHistogramData{
+				DataPoints: []otlpHistogramDataPoint{{
+					Attributes:        otlpAttributes(h.tags),
+					StartTimeUnixNano: start,
+					TimeUnixNano:      end,
+					Count:             strconv.FormatUint(count, 10),
+					Sum:               sum,
+					BucketCounts:      bucketCounts,
+					ExplicitBounds:    h.bounds,
+				}},
+				AggregationTemporality: otlpCumulative,
+			},
+		})
+	}
+
+	return otlpRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: otlpResource{
+				Attributes: otlpAttributes(map[string]string{"service.name": "atlantis"}),
+			},
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: "github.com/runatlantis/atlantis"},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
+
+func otlpKey(name string, tags map[string]string) string {
+	key := name
+	for _, k := range sortedKeys(tags) {
+		key += "," + k + "=" + tags[k]
+	}
+	return key
+}
+
+func otlpAttributes(tags map[string]string) []otlpKeyValue {
+	attributes := []otlpKeyValue{}
+	for _, k := range sortedKeys(tags) {
+		attributes = append(attributes, otlpKeyValue{Key: k, Value: otlpAnyValue{StringValue: tags[k]}})
+	}
+	return attributes
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// The types below are the parts of the OTLP metrics protobuf messages that are
+// exported, in their JSON encoding. 64-bit integers are encoded as strings.
+
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpMetric struct {
+	Name      string             `json:"name"`
+	Unit      string             `json:"unit,omitempty"`
+	Sum       *otlpSum           `json:"sum,omitempty"`
+	Gauge     *otlpGaugeData     `json:"gauge,omitempty"`
+	Histogram *otlpHistogramData `json:"histogram,omitempty"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+}
+
+type otlpGaugeData struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpHistogramData struct {
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsInt             string         `json:"asInt,omitempty"`
+	AsDouble          *float64       `json:"asDouble,omitempty"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	Count             string         `json:"count"`
+	Sum               *float64       `json:"sum,omitempty"`
+	BucketCounts      []string       `json:"bucketCounts"`
+	ExplicitBounds    []float64      `json:"explicitBounds"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestOTLPReporter(t *testing.T) {
+	var requests []otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "/v1/metrics", r.URL.Path)
+		Equals(t, "application/json", r.Header.Get("Content-Type"))
+		Equals(t, "secret", r.Header.Get("Api-Key"))
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		var request otlpRequest
+		Ok(t, json.Unmarshal(body, &request))
+		requests = append(requests, request)
+	}))
+	defer collector.Close()
+
+	now := time.Unix(100, 0)
+	reporter := newOTLPReporter(&valid.OTLP{
+		Endpoint: collector.URL + "/",
+		Headers:  map[string]string{"Api-Key": "secret"},
+		Interval: time.Minute,
+	}, logging.NewNoopLogger(t))
+	reporter.now = func() time.Time { return now }
+	reporter.start = now
+	reporter.lastExport = now
+
+	tags := map[string]string{"base_repo": "owner/repo"}
+	reporter.ReportCounter("atlantis.cmd.plan.success", tags, 2)
+	reporter.ReportGauge("atlantis.locks", nil, 3)
+	reporter.ReportTimer("atlantis.cmd.plan.execution_time", tags, 2*time.Second)
+	reporter.ReportTimer("atlantis.cmd.plan.execution_time", tags, 2*time.Hour)
+
+	// Nothing is exported before the interval elapsed.
+	reporter.Flush()
+	Equals(t, 0, len(requests))
+
+	now = now.Add(time.Minute)
+	reporter.ReportCounter("atlantis.cmd.plan.success", tags, 1)
+	reporter.Flush()
+	Equals(t, 1, len(requests))
+
+	metrics := requests[0].ResourceMetrics[0].ScopeMetrics[0].Metrics
+	Equals(t, 3, len(metrics))
+	Equals(t, "atlantis.cmd.plan.success", metrics[0].Name)
+	Equals(t, []otlpKeyValue{{Key: "base_repo", Value: otlpAnyValue{StringValue: "owner/repo"}}}, metrics[0].Sum.DataPoints[0].Attributes)
+	// Counters are cumulative.
+	Equals(t, "3", metrics[0].Sum.DataPoints[0].AsInt)
+	Equals(t, "100000000000", metrics[0].Sum.DataPoints[0].StartTimeUnixNano)
+	Equals(t, "160000000000", metrics[0].Sum.DataPoints[0].TimeUnixNano)
+	Equals(t, 3.0, *metrics[1].Gauge.DataPoints[0].AsDouble)
+	histogram := metrics[2].Histogram.DataPoints[0]
+	Equals(t, "s", metrics[2].Unit)
+	Equals(t, "2", histogram.Count)
+	Equals(t, 7202.0, *histogram.Sum)
+	Equals(t, otlpTimerBounds, histogram.ExplicitBounds)
+	Equals(t, "1", histogram.BucketCounts[8])
+	Equals(t, "1", histogram.BucketCounts[len(otlpTimerBounds)])
+
+	// The remaining metrics are exported when the scope is closed.
+	reporter.ReportCounter("atlantis.cmd.plan.success", tags, 1)
+	Ok(t, reporter.Close())
+	Equals(t, 2, len(requests))
+	Equals(t, "4", requests[1].ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Sum.DataPoints[0].AsInt)
+}
+
+func TestOTLPReporter_CollectorError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer collector.Close()
+
+	reporter := newOTLPReporter(&valid.OTLP{Endpoint: collector.URL}, logging.NewNoopLogger(t))
+	ErrEquals(t, "collector returned 401: unauthorized", reporter.Close())
+}
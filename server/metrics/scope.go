@@ -54,6 +54,16 @@ func newReporter(cfg valid.Metrics, logger logging.SimpleLogging) (tally.BaseSta
 		return tallyprom.NewReporter(tallyprom.Options{}), nil
 	}
 
+	// return DogStatsD metrics if configured
+	if cfg.Datadog != nil {
+		return newDatadogReporter(cfg)
+	}
+
+	// return OTLP metrics if configured
+	if cfg.OTLP != nil {
+		return newOTLPReporter(cfg.OTLP, logger), nil
+	}
+
 	// return logging reporter and proceed
 	return newLoggingReporter(logger), nil
 
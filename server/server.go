@@ -19,6 +19,7 @@ import (
 	"context"
 	"crypto/tls"
 	"embed"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -32,6 +33,7 @@ import (
 	"sort"
 	"strings"
 	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -43,6 +45,7 @@ import (
 	cfg "github.com/runatlantis/atlantis/server/core/config"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/kubernetes"
 	"github.com/runatlantis/atlantis/server/core/redis"
 	"github.com/runatlantis/atlantis/server/core/terraform/tfclient"
 	"github.com/runatlantis/atlantis/server/jobs"
@@ -87,6 +90,50 @@ const (
 	// terraformPluginCacheDir is the name of the dir inside our data dir
 	// where we tell terraform to cache plugins and modules.
 	TerraformPluginCacheDirName = "plugin-cache"
+	// SBOMDirName is the name of the directory inside our data dir where
+	// we store the SBOMs of applies.
+	SBOMDirName = "sboms"
+	// PlanJSONDirName is the name of the directory inside our data dir where
+	// we store the terraform show -json output of plans.
+	PlanJSONDirName = "plan-json"
+	// PlanChangesDirName is the name of the directory inside our data dir
+	// where we store the resource changes of plans.
+	PlanChangesDirName = "plan-changes"
+	// SharedOutputsDirName is the name of the directory inside our data dir
+	// where we store the outputs projects share with their dependents.
+	SharedOutputsDirName = "shared-outputs"
+	// RepoConfigSuggestionsDirName is the name of the directory inside our
+	// data dir where we record the pull requests an atlantis.yaml was
+	// suggested on.
+	RepoConfigSuggestionsDirName = "repo-config-suggestions"
+	// ProvenanceDirName is the name of the directory inside our data dir where
+	// we store the provenance of applies and automerges.
+	ProvenanceDirName = "provenance"
+	// StateSnapshotDirName is the name of the directory inside our data dir
+	// where we store the state snapshots recorded after applies.
+	StateSnapshotDirName = "state-snapshots"
+	// RepoConfigDirName is the name of the directory inside our data dir where
+	// we clone the git repo of the server-side repo config.
+	RepoConfigDirName = "repo-config"
+	// ProvidersSchemaCacheDirName is the name of the directory inside our
+	// data dir where we cache the output of terraform providers schema -json.
+	ProvidersSchemaCacheDirName = "providers-schemas"
+	// AutoApprovalAuditLogFileName is the name of the file inside our data
+	// dir where we record the plans applied because they're low risk.
+	AutoApprovalAuditLogFileName = "auto-approval-audit.jsonl"
+	// LockAuditLogFileName is the name of the file inside our data dir where
+	// we record who deleted locks through the UI.
+	LockAuditLogFileName = "lock-audit.jsonl"
+	// JobLogsDirName is the name of the dir inside our data dir where we keep
+	// the output of completed jobs.
+	JobLogsDirName = "job-logs"
+	// UsageLogFileName is the name of the file inside our data dir where we
+	// record the commands run on projects for usage reports.
+	UsageLogFileName = "usage.jsonl"
+
+	// lockAuditEntriesShown is how many of the most recent lock deletions
+	// are shown on the index page.
+	lockAuditEntriesShown = 10
 )
 
 // Server runs the Atlantis web server.
@@ -104,6 +151,8 @@ type Server struct {
 	StatsCloser                    io.Closer
 	Locker                         locking.Locker
 	ApplyLocker                    locking.ApplyLocker
+	PlanFreezer                    locking.PlanFreezer
+	LockAuditLog                   events.LockAuditLog
 	VCSEventsController            *events_controllers.VCSEventsController
 	GithubAppController            *controllers.GithubAppController
 	LocksController                *controllers.LocksController
@@ -120,12 +169,20 @@ type Server struct {
 	KeyLastRefreshTime             time.Time
 	SSLCert                        *tls.Certificate
 	Drainer                        *events.Drainer
+	VCSClients                     []*vcs.MiddlewareClient
 	WebAuthentication              bool
 	WebUsername                    string
 	WebPassword                    string
 	ProjectCmdOutputHandler        jobs.ProjectCommandOutputHandler
 	ScheduledExecutorService       *scheduled.ExecutorService
 	DisableGlobalApplyLock         bool
+	// WebhookRegistrar checks the webhooks of allowlisted repos at startup if
+	// it isn't nil.
+	WebhookRegistrar *events.WebhookRegistrar
+	// RepoAllowlistDiscoverer discovers the repos of the topic rules of the
+	// repo allowlist at startup if it isn't nil. They're then rediscovered
+	// periodically by the scheduled executor.
+	RepoAllowlistDiscoverer *events.RepoAllowlistDiscoverer
 }
 
 // Config holds config for server that isn't passed in by the user.
@@ -157,8 +214,11 @@ type WebhookConfig struct {
 	// slack webhooks. Should be without '#'.
 	Channel string `mapstructure:"channel"`
 	// URL is the URL where to deliver this webhook. It only applies to
-	// http webhooks.
+	// http, datadog and teams webhooks.
 	URL string `mapstructure:"url"`
+	// To are the email addresses to send this webhook to. It only applies
+	// to email webhooks.
+	To []string `mapstructure:"to"`
 }
 
 //go:embed static
@@ -169,14 +229,26 @@ var staticAssets embed.FS
 // for the server CLI command because it injects all the dependencies.
 func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	logging.SuppressDefaultLogging()
-	logger, err := logging.NewStructuredLoggerFromLevel(userConfig.ToLogLevel())
-
+	var logStore *logging.LogStore
+	var logger logging.SimpleLogging
+	var err error
+	if userConfig.CommandLogHistorySize > 0 {
+		logStore = logging.NewLogStore(userConfig.CommandLogHistorySize)
+		logger, err = logging.NewStructuredLoggerWithLogStore(userConfig.ToLogLevel(), logStore)
+	} else {
+		logger, err = logging.NewStructuredLoggerFromLevel(userConfig.ToLogLevel())
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	var supportedVCSHosts []models.VCSHostType
 	var githubClient vcs.IGithubClient
+	var githubWebhookClient events.GithubWebhookClient
+	var githubRepoDiscoveryClient events.GithubRepoDiscoveryClient
+	var githubBranchGetter events.GithubBranchGetter
+	var githubCheckRunClient events.GithubCheckRunClient
+	var githubStatusChecksLister controllers.GithubStatusChecksLister
 	var githubAppEnabled bool
 	var githubConfig vcs.GithubConfig
 	var githubCredentials vcs.GithubCredentials
@@ -185,6 +257,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	var bitbucketServerClient *bitbucketserver.Client
 	var azuredevopsClient *vcs.AzureDevopsClient
 	var giteaClient *gitea.GiteaClient
+	reviewCommenters := make(map[models.VCSHostType]vcs.ReviewCommenter)
+	commentUpdaters := make(map[models.VCSHostType]vcs.CommentUpdater)
 
 	policyChecksEnabled := false
 	if userConfig.EnablePolicyChecksFlag {
@@ -196,6 +270,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	issueOpsCommands, err := userConfig.ToIssueOpsCommandNames()
+	if err != nil {
+		return nil, err
+	}
 	disableApply := true
 	for _, allowCommand := range allowCommands {
 		if allowCommand == command.Apply {
@@ -204,30 +282,78 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		}
 	}
 
-	parserValidator := &cfg.ParserValidator{}
+	parserValidator := &cfg.ParserValidator{InstanceName: userConfig.InstanceName}
 
-	globalCfg := valid.NewGlobalCfgFromArgs(
+	defaultGlobalCfg := valid.NewGlobalCfgFromArgs(
 		valid.GlobalCfgArgs{
 			PolicyCheckEnabled: userConfig.EnablePolicyChecksFlag,
+			RepoConfigFiles:    userConfig.ToRepoConfigPaths(),
 		})
-	if userConfig.RepoConfig != "" {
-		globalCfg, err = parserValidator.ParseGlobalCfg(userConfig.RepoConfig, globalCfg)
+	// repoConfigPath is the path of the repo config file, which is in a clone
+	// of its git repo if --repo-config is a git URL.
+	repoConfigPath := userConfig.RepoConfig
+	var repoConfigGitSource *cfg.GitSource
+	repoConfigGitDir := filepath.Join(userConfig.DataDir, RepoConfigDirName)
+	if cfg.IsGitSource(userConfig.RepoConfig) {
+		source, err := cfg.ParseGitSource(userConfig.RepoConfig)
 		if err != nil {
-			return nil, errors.Wrapf(err, "parsing %s file", userConfig.RepoConfig)
+			return nil, errors.Wrap(err, "parsing --repo-config")
+		}
+		repoConfigPath, err = source.Sync(repoConfigGitDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "cloning repo config")
+		}
+		repoConfigGitSource = &source
+	}
+	globalCfg := defaultGlobalCfg
+	if repoConfigPath != "" {
+		globalCfg, err = parserValidator.ParseGlobalCfg(repoConfigPath, defaultGlobalCfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s file", repoConfigPath)
 		}
 	} else if userConfig.RepoConfigJSON != "" {
-		globalCfg, err = parserValidator.ParseGlobalCfgJSON(userConfig.RepoConfigJSON, globalCfg)
+		globalCfg, err = parserValidator.ParseGlobalCfgJSON(userConfig.RepoConfigJSON, defaultGlobalCfg)
 		if err != nil {
 			return nil, errors.Wrapf(err, "parsing --%s", config.RepoConfigJSONFlag)
 		}
 	}
 
+	// globalCfgStore holds the server-side repo config. Consumers read the
+	// current config from it since it may be reloaded.
+	globalCfgStore := valid.NewGlobalCfgStore(globalCfg)
+
 	statsScope, statsReporter, closer, err := metrics.NewScope(globalCfg.Metrics, logger, userConfig.StatsNamespace)
 
 	if err != nil {
 		return nil, errors.Wrapf(err, "instantiating metrics scope")
 	}
 
+	vcsHTTPConfigs, err := userConfig.ToVCSHTTPConfigs()
+	if err != nil {
+		return nil, err
+	}
+	vcsCommentSplits, err := userConfig.ToVCSCommentSplitConfigs()
+	if err != nil {
+		return nil, err
+	}
+	vcsDebugLogging := &vcs.DebugLogging{}
+	vcsDebugLogging.SetEnabled(userConfig.VCSDebugLogging)
+	// vcsTransports holds the transport of each host. Hosts without an HTTP
+	// config use http.DefaultTransport.
+	vcsTransports := make(map[models.VCSHostType]http.RoundTripper)
+	for _, host := range []models.VCSHostType{models.Github, models.Gitlab, models.BitbucketCloud, models.BitbucketServer, models.AzureDevops, models.Gitea} {
+		transport, err := vcsHTTPConfigs[host].Transport()
+		if err != nil {
+			return nil, errors.Wrapf(err, "configuring HTTP client for %s", host)
+		}
+		vcsTransports[host] = &vcs.DebugTransport{
+			Transport: transport,
+			Host:      host,
+			Debug:     vcsDebugLogging,
+			Logger:    logger,
+		}
+	}
+
 	if userConfig.GithubUser != "" || userConfig.GithubAppID != 0 {
 		if userConfig.GithubAllowMergeableBypassApply {
 			githubConfig = vcs.GithubConfig{
@@ -240,6 +366,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				User:      userConfig.GithubUser,
 				Token:     userConfig.GithubToken,
 				TokenFile: userConfig.GithubTokenFile,
+				Transport: vcsTransports[models.Github],
 			}
 		} else if userConfig.GithubAppID != 0 && userConfig.GithubAppKeyFile != "" {
 			privateKey, err := os.ReadFile(userConfig.GithubAppKeyFile)
@@ -252,6 +379,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				Key:            privateKey,
 				Hostname:       userConfig.GithubHostname,
 				AppSlug:        userConfig.GithubAppSlug,
+				Transport:      vcsTransports[models.Github],
 			}
 			githubAppEnabled = true
 		} else if userConfig.GithubAppID != 0 && userConfig.GithubAppKey != "" {
@@ -261,6 +389,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				Key:            []byte(userConfig.GithubAppKey),
 				Hostname:       userConfig.GithubHostname,
 				AppSlug:        userConfig.GithubAppSlug,
+				Transport:      vcsTransports[models.Github],
 			}
 			githubAppEnabled = true
 		}
@@ -270,6 +399,16 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		if err != nil {
 			return nil, err
 		}
+		rawGithubClient.CommentSplit = vcsCommentSplits[models.Github]
+		githubWebhookClient = rawGithubClient
+		githubRepoDiscoveryClient = rawGithubClient
+		githubBranchGetter = rawGithubClient
+		githubStatusChecksLister = rawGithubClient
+		if !userConfig.VCSDryRun {
+			githubCheckRunClient = rawGithubClient
+			reviewCommenters[models.Github] = rawGithubClient
+			commentUpdaters[models.Github] = rawGithubClient
+		}
 
 		githubClient = vcs.NewInstrumentedGithubClient(rawGithubClient, statsScope, logger)
 	}
@@ -282,26 +421,36 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			return nil, err
 		}
 
-		gitlabGroups := slices.Concat(gitlabGroupAllowlistChecker.AllTeams(), globalCfg.PolicySets.AllTeams())
-		slices.Sort(gitlabGroups)
-		gitlabClient, err = vcs.NewGitlabClient(userConfig.GitlabHostname, userConfig.GitlabToken, slices.Compact(gitlabGroups), logger)
+		gitlabClient, err = vcs.NewGitlabClient(userConfig.GitlabHostname, userConfig.GitlabToken, gitlabGroupAllowlistChecker.AllTeams(), vcsTransports[models.Gitlab], logger)
 		if err != nil {
 			return nil, err
 		}
+		// The policy owner groups are read when they're checked since the
+		// config may be reloaded.
+		gitlabClient.PolicyGroups = func() []string {
+			policySets := globalCfgStore.Load().PolicySets
+			return policySets.AllTeams()
+		}
+		gitlabClient.CommentSplit = vcsCommentSplits[models.Gitlab]
+		if !userConfig.VCSDryRun {
+			reviewCommenters[models.Gitlab] = gitlabClient
+			commentUpdaters[models.Gitlab] = gitlabClient
+		}
 	}
 	if userConfig.BitbucketUser != "" {
 		if userConfig.BitbucketBaseURL == bitbucketcloud.BaseURL {
 			supportedVCSHosts = append(supportedVCSHosts, models.BitbucketCloud)
 			bitbucketCloudClient = bitbucketcloud.NewClient(
-				http.DefaultClient,
+				&http.Client{Transport: vcsTransports[models.BitbucketCloud]},
 				userConfig.BitbucketUser,
 				userConfig.BitbucketToken,
 				userConfig.AtlantisURL)
+			bitbucketCloudClient.CommentSplit = vcsCommentSplits[models.BitbucketCloud]
 		} else {
 			supportedVCSHosts = append(supportedVCSHosts, models.BitbucketServer)
 			var err error
 			bitbucketServerClient, err = bitbucketserver.NewClient(
-				http.DefaultClient,
+				&http.Client{Transport: vcsTransports[models.BitbucketServer]},
 				userConfig.BitbucketUser,
 				userConfig.BitbucketToken,
 				userConfig.BitbucketBaseURL,
@@ -309,27 +458,38 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			if err != nil {
 				return nil, errors.Wrapf(err, "setting up Bitbucket Server client")
 			}
+			bitbucketServerClient.CommentSplit = vcsCommentSplits[models.BitbucketServer]
+			bitbucketServerClient.MaxAttempts = userConfig.BitbucketMaxAttempts
+			bitbucketServerClient.MaxRetryDelay = time.Duration(userConfig.BitbucketMaxDelaySeconds) * time.Second
+			if userConfig.BitbucketLabelPattern != "" {
+				bitbucketServerClient.LabelPattern, err = bitbucketserver.NewLabelPattern(userConfig.BitbucketLabelPattern)
+				if err != nil {
+					return nil, errors.Wrap(err, "parsing Bitbucket label pattern")
+				}
+			}
 		}
 	}
 	if userConfig.AzureDevopsUser != "" {
 		supportedVCSHosts = append(supportedVCSHosts, models.AzureDevops)
 
 		var err error
-		azuredevopsClient, err = vcs.NewAzureDevopsClient(userConfig.AzureDevOpsHostname, userConfig.AzureDevopsUser, userConfig.AzureDevopsToken)
+		azuredevopsClient, err = vcs.NewAzureDevopsClient(userConfig.AzureDevOpsHostname, userConfig.AzureDevopsUser, userConfig.AzureDevopsToken, vcsTransports[models.AzureDevops])
 		if err != nil {
 			return nil, err
 		}
+		azuredevopsClient.CommentSplit = vcsCommentSplits[models.AzureDevops]
 	}
 	if userConfig.GiteaToken != "" {
 		supportedVCSHosts = append(supportedVCSHosts, models.Gitea)
 
-		giteaClient, err = gitea.NewClient(userConfig.GiteaBaseURL, userConfig.GiteaUser, userConfig.GiteaToken, userConfig.GiteaPageSize, logger)
+		giteaClient, err = gitea.NewClient(&http.Client{Transport: vcsTransports[models.Gitea]}, userConfig.GiteaBaseURL, userConfig.GiteaUser, userConfig.GiteaToken, userConfig.GiteaPageSize, logger)
 		if err != nil {
 			fmt.Println("error setting up gitea client", "error", err)
 			return nil, errors.Wrapf(err, "setting up Gitea client")
 		} else {
 			logger.Info("gitea client configured successfully")
 		}
+		giteaClient.CommentSplit = vcsCommentSplits[models.Gitea]
 	}
 
 	var supportedVCSHostsStr []string
@@ -393,6 +553,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			Kind:           c.Kind,
 			WorkspaceRegex: c.WorkspaceRegex,
 			URL:            c.URL,
+			To:             c.To,
 		}
 		webhooksConfig = append(webhooksConfig, config)
 	}
@@ -403,15 +564,78 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	webhooksManager, err := webhooks.NewMultiWebhookSender(
 		webhooksConfig,
 		webhooks.Clients{
-			Slack: webhooks.NewSlackClient(userConfig.SlackToken),
-			Http:  &webhooks.HttpClient{Client: http.DefaultClient, Headers: webhookHeaders},
+			Slack:   webhooks.NewSlackClient(userConfig.SlackToken),
+			Http:    &webhooks.HttpClient{Client: http.DefaultClient, Headers: webhookHeaders},
+			Datadog: &webhooks.DatadogClient{Client: http.DefaultClient, APIKey: userConfig.DatadogAPIKey},
+			Email: &webhooks.EmailClient{
+				Addr:     userConfig.SMTPAddr,
+				From:     userConfig.SMTPFrom,
+				Username: userConfig.SMTPUsername,
+				Password: userConfig.SMTPPassword,
+			},
 		},
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "initializing webhooks")
 	}
-	vcsClient := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient, giteaClient)
-	commitStatusUpdater := &events.DefaultCommitStatusUpdater{Client: vcsClient, StatusName: userConfig.VCSStatusName}
+	vcsMiddlewareConfig := vcs.MiddlewareConfig{
+		MaxRetries:       userConfig.VCSMaxRetries,
+		RetryDelay:       time.Second,
+		BreakerThreshold: userConfig.VCSBreakerThreshold,
+		BreakerCooldown:  time.Duration(userConfig.VCSBreakerCooldownSeconds) * time.Second,
+	}
+	var vcsMiddlewareClients []*vcs.MiddlewareClient
+	var translator *vcs.Translator
+	if userConfig.CommentTranslationsFile != "" {
+		translator, err = vcs.LoadTranslator(userConfig.CommentTranslationsFile)
+		if err != nil {
+			return nil, err
+		}
+		if githubCheckRunClient != nil {
+			githubCheckRunClient = &vcs.TranslatingCheckRunClient{CheckRunClient: githubCheckRunClient, Translator: translator}
+		}
+	}
+	if userConfig.VCSDryRun {
+		logger.Warn("running in VCS dry-run mode, comments, statuses and merges will be logged instead of sent to VCS hosts")
+	}
+	// withMiddleware wraps the clients of the configured hosts. Hosts that
+	// aren't configured get a nil client so the proxy falls back to
+	// NotConfiguredVCSClient.
+	withMiddleware := func(host models.VCSHostType, client vcs.Client) vcs.Client {
+		if !slices.Contains(supportedVCSHosts, host) {
+			return nil
+		}
+		if userConfig.VCSDryRun {
+			client = &vcs.DryRunClient{Client: client}
+		}
+		if translator != nil {
+			client = &vcs.TranslatingClient{Client: client, Translator: translator}
+		}
+		middlewareClient := vcs.NewMiddlewareClient(client, host, vcsMiddlewareConfig, statsScope)
+		vcsMiddlewareClients = append(vcsMiddlewareClients, middlewareClient)
+		return middlewareClient
+	}
+	vcsClient := vcs.NewClientProxy(
+		withMiddleware(models.Github, githubClient),
+		withMiddleware(models.Gitlab, gitlabClient),
+		withMiddleware(models.BitbucketCloud, bitbucketCloudClient),
+		withMiddleware(models.BitbucketServer, bitbucketServerClient),
+		withMiddleware(models.AzureDevops, azuredevopsClient),
+		withMiddleware(models.Gitea, giteaClient),
+	)
+	commitStatusUpdater := &events.DefaultCommitStatusUpdater{
+		Client:            vcsClient,
+		StatusName:        userConfig.VCSStatusName,
+		AggregateStatuses: userConfig.AggregateCommitStatuses,
+		CheckRunClient:    githubCheckRunClient,
+		GlobalCfgStore:    globalCfgStore,
+	}
+	if userConfig.ProjectStatusTemplate != "" {
+		commitStatusUpdater.ProjectStatusTemplate, err = template.New("project-status").Parse(userConfig.ProjectStatusTemplate)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing project status template")
+		}
+	}
 
 	binDir, err := mkSubDir(userConfig.DataDir, BinDirName)
 
@@ -425,11 +649,87 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		return nil, err
 	}
 
+	// The stores below keep their records as files in the data dir so,
+	// unlike the records in the locking database, they're only shared by
+	// Atlantis instances sharing the data dir.
+	var sbomStore events.SBOMStore
+	if userConfig.EnableSBOM {
+		sbomDir, err := mkSubDir(userConfig.DataDir, SBOMDirName)
+		if err != nil {
+			return nil, err
+		}
+		sbomStore = &events.FileSBOMStore{Dir: sbomDir}
+	}
+
+	var provenanceStore events.ProvenanceStore
+	if userConfig.EnableApplyProvenance {
+		provenanceDir, err := mkSubDir(userConfig.DataDir, ProvenanceDirName)
+		if err != nil {
+			return nil, err
+		}
+		provenanceStore = &events.FileProvenanceStore{Dir: provenanceDir}
+	}
+
+	var stateSnapshotStore events.StateSnapshotStore
+	if userConfig.EnableStateSnapshots {
+		stateSnapshotDir, err := mkSubDir(userConfig.DataDir, StateSnapshotDirName)
+		if err != nil {
+			return nil, err
+		}
+		stateSnapshotStore = &events.FileStateSnapshotStore{Dir: stateSnapshotDir}
+	}
+
+	var planJSONStore events.PlanJSONStore
+	if userConfig.EnablePlanJSONAPI {
+		planJSONDir, err := mkSubDir(userConfig.DataDir, PlanJSONDirName)
+		if err != nil {
+			return nil, err
+		}
+		planJSONStore = &events.FilePlanJSONStore{Dir: planJSONDir}
+	}
+
+	planChangesDir, err := mkSubDir(userConfig.DataDir, PlanChangesDirName)
+	if err != nil {
+		return nil, err
+	}
+	planChangesStore := &events.FilePlanChangesStore{Dir: planChangesDir}
+	sharedOutputsDir, err := mkSubDir(userConfig.DataDir, SharedOutputsDirName)
+	if err != nil {
+		return nil, err
+	}
+	sharedOutputsStore := &events.FileSharedOutputsStore{Dir: sharedOutputsDir}
+	repoCfgSuggestionsDir, err := mkSubDir(userConfig.DataDir, RepoConfigSuggestionsDirName)
+	if err != nil {
+		return nil, err
+	}
+	repoCfgSuggestionStore := &events.FileRepoCfgSuggestionStore{Dir: repoCfgSuggestionsDir}
+
 	parsedURL, err := ParseAtlantisURL(userConfig.AtlantisURL)
 	if err != nil {
 		return nil, errors.Wrapf(err, "parsing --%s flag %q", config.AtlantisURLFlag, userConfig.AtlantisURL)
 	}
 
+	var webhookRegistrar *events.WebhookRegistrar
+	if userConfig.WebhookRegistration != "" && githubWebhookClient != nil {
+		mode := userConfig.WebhookRegistration
+		if userConfig.VCSDryRun && mode == events.WebhookRegistrationSync {
+			logger.Info("only reporting webhook discrepancies since --vcs-dry-run is set")
+			mode = events.WebhookRegistrationReport
+		}
+		webhookRegistrar = &events.WebhookRegistrar{
+			Logger:    logger,
+			Client:    githubWebhookClient,
+			Hostname:  userConfig.GithubHostname,
+			Allowlist: userConfig.RepoAllowlist,
+			Webhook: vcs.Webhook{
+				URL:    parsedURL.String() + "/events",
+				Secret: userConfig.GithubWebhookSecret,
+				Events: vcs.GithubWebhookEvents,
+			},
+			Mode: mode,
+		}
+	}
+
 	underlyingRouter := mux.NewRouter()
 	router := &Router{
 		AtlantisURL:               parsedURL,
@@ -439,6 +739,17 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		Underlying:                underlyingRouter,
 	}
 
+	var jobLogStore jobs.JobLogStore
+	if userConfig.JobLogHistoryDays > 0 {
+		jobLogStore, err = jobs.NewFileJobLogStore(
+			filepath.Join(userConfig.DataDir, JobLogsDirName),
+			time.Duration(userConfig.JobLogHistoryDays)*24*time.Hour,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var projectCmdOutputHandler jobs.ProjectCommandOutputHandler
 
 	if userConfig.TFEToken != "" && !userConfig.TFELocalExecutionMode {
@@ -449,6 +760,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		projectCmdOutputHandler = jobs.NewAsyncProjectCommandOutputHandler(
 			projectCmdOutput,
 			logger,
+			jobLogStore,
 		)
 	}
 
@@ -473,6 +785,28 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if err != nil && flag.Lookup("test.v") == nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("initializing %s", userConfig.DefaultTFDistribution))
 	}
+	tfModuleMirrors, err := userConfig.ToTFModuleMirrors()
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing --tf-module-mirrors")
+	}
+	tfCLIConfig := tfclient.CLIConfig{
+		ProviderMirrorURL: userConfig.TFProviderMirrorURL,
+		ModuleMirrors:     tfModuleMirrors,
+		TFEHostname:       userConfig.TFEHostname,
+		TFEToken:          userConfig.TFEToken,
+	}
+	if terraformClient != nil && !tfCLIConfig.IsEmpty() {
+		if err := terraformClient.UseCLIConfig(filepath.Join(userConfig.DataDir, tfclient.CLIConfigFilename), tfCLIConfig); err != nil {
+			return nil, err
+		}
+	}
+	tfRegistryCredentials, err := userConfig.ToTFRegistryCredentials()
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing --tf-registry-credentials")
+	}
+	if terraformClient != nil && len(tfRegistryCredentials) > 0 {
+		terraformClient.UseRegistryCredentials(tfRegistryCredentials)
+	}
 	markdownRenderer := events.NewMarkdownRenderer(
 		gitlabClient.SupportsCommonMark(),
 		userConfig.DisableApplyAll,
@@ -489,20 +823,25 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	var lockingClient locking.Locker
 	var applyLockingClient locking.ApplyLocker
 	var backend locking.Backend
+	// recordBackend stores the records shared by the Atlantis instances, ex.
+	// the plan freezes, next to the locks.
+	var recordBackend locking.RecordBackend
 
 	switch dbtype := userConfig.LockingDBType; dbtype {
 	case "redis":
 		logger.Info("Utilizing Redis DB")
-		backend, err = redis.New(userConfig.RedisHost, userConfig.RedisPort, userConfig.RedisPassword, userConfig.RedisTLSEnabled, userConfig.RedisInsecureSkipVerify, userConfig.RedisDB)
+		redisDB, err := redis.New(userConfig.RedisHost, userConfig.RedisPort, userConfig.RedisPassword, userConfig.RedisTLSEnabled, userConfig.RedisInsecureSkipVerify, userConfig.RedisDB)
 		if err != nil {
 			return nil, err
 		}
+		backend, recordBackend = redisDB, redisDB
 	case "boltdb":
 		logger.Info("Utilizing BoltDB")
-		backend, err = db.New(userConfig.DataDir)
+		boltDB, err := db.New(userConfig.DataDir)
 		if err != nil {
 			return nil, err
 		}
+		backend, recordBackend = boltDB, boltDB
 	}
 
 	noOpLocker := locking.NewNoOpLocker()
@@ -518,6 +857,11 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	}
 
 	applyLockingClient = locking.NewApplyClient(backend, disableApply, disableGlobalApplyLock)
+	planFreezer := locking.NewPlanFreezer(recordBackend)
+	changeSets := events.NewChangeSetStore(recordBackend)
+	silenceStore := events.NewSilenceStore(recordBackend)
+	canaryStore := events.NewCanaryStore(recordBackend)
+	autoApplyStore := events.NewAutoApplyStore(recordBackend)
 	workingDirLocker := events.NewDefaultWorkingDirLocker()
 
 	var workingDir events.WorkingDir = &events.FileWorkspace{
@@ -525,6 +869,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		CheckoutMerge:    userConfig.CheckoutStrategy == "merge",
 		CheckoutDepth:    userConfig.CheckoutDepth,
 		GithubAppEnabled: githubAppEnabled,
+		CloneCache:       userConfig.CloneCache,
+		CloneLimiter:     events.NewCloneLimiter(userConfig.MaxConcurrentClonesPerHost, statsScope),
 	}
 
 	scheduledExecutorService := scheduled.NewExecutorService(
@@ -532,6 +878,43 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		logger,
 	)
 
+	var repoConfigReloader controllers.RepoConfigReloader
+	reloadPeriod := time.Duration(userConfig.RepoConfigReloadSeconds) * time.Second
+	if userConfig.KubernetesOperator {
+		kubernetesClient, err := kubernetes.NewInClusterClient()
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing Kubernetes client")
+		}
+		namespaces, err := kubernetes.ParseNamespaces(userConfig.KubernetesNamespaces)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing kubernetes namespaces")
+		}
+		operator := &kubernetes.Operator{
+			Client:          kubernetesClient,
+			Namespaces:      namespaces,
+			RepoConfig:      repoConfigPath,
+			DefaultCfg:      defaultGlobalCfg,
+			ParserValidator: parserValidator,
+			Store:           globalCfgStore,
+			Logger:          logger,
+			Scope:           statsScope.SubScope("kubernetes_operator"),
+		}
+		// Don't start without the config from the custom resources since it
+		// may add apply requirements.
+		if err := operator.Sync(); err != nil {
+			return nil, errors.Wrap(err, "syncing Kubernetes custom resources")
+		}
+		globalCfg = globalCfgStore.Load()
+		repoConfigReloader = operator
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{Job: operator, Period: reloadPeriod})
+	} else if userConfig.RepoConfigReloadSeconds > 0 {
+		reloader := cfg.NewGlobalCfgReloader(repoConfigPath, defaultGlobalCfg, globalCfgStore, logger, statsScope)
+		reloader.GitSource = repoConfigGitSource
+		reloader.GitDir = repoConfigGitDir
+		repoConfigReloader = reloader
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{Job: reloader, Period: reloadPeriod})
+	}
+
 	// provide fresh tokens before clone from the GitHub Apps integration, proxy workingDir
 	if githubAppEnabled {
 		if !userConfig.WriteGitCreds {
@@ -571,6 +954,26 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WorkingDirLocker: workingDirLocker,
 		Backend:          backend,
 	}
+	if userConfig.StalePlanReminderDays > 0 {
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job: &events.StalePullReaper{
+				Locker:             lockingClient,
+				DeleteLockCommand:  deleteLockCommand,
+				WorkingDir:         workingDir,
+				VCSClient:          vcsClient,
+				Logger:             logger,
+				RemindAfter:        time.Duration(userConfig.StalePlanReminderDays) * 24 * time.Hour,
+				DiscardAfter:       time.Duration(userConfig.StalePlanDiscardDays) * 24 * time.Hour,
+				DisableUnlockLabel: userConfig.DisableUnlockLabel,
+			},
+			Period: time.Hour,
+		})
+	}
+
+	var lifecyclePlugins *events.LifecyclePlugins
+	if paths := userConfig.ToLifecyclePlugins(); len(paths) > 0 {
+		lifecyclePlugins = &events.LifecyclePlugins{Paths: paths}
+	}
 
 	pullClosedExecutor := events.NewInstrumentedPullClosedExecutor(
 		statsScope,
@@ -582,6 +985,13 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			PullClosedTemplate:       &events.PullClosedEventTemplate{},
 			LogStreamResourceCleaner: projectCmdOutputHandler,
 			VCSClient:                vcsClient,
+			PlanJSONStore:            planJSONStore,
+			PlanChangesStore:         planChangesStore,
+			LifecyclePlugins:         lifecyclePlugins,
+			SilenceStore:             silenceStore,
+			RepoCfgSuggestionStore:   repoCfgSuggestionStore,
+			CanaryStore:              canaryStore,
+			AutoApplyStore:           autoApplyStore,
 		},
 	)
 
@@ -609,6 +1019,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.ExecutableName,
 		allowCommands,
 	)
+	commentParser.InstanceName = userConfig.InstanceName
 	defaultTfDistribution := terraformClient.DefaultDistribution()
 	defaultTfVersion := terraformClient.DefaultVersion()
 	pendingPlanFinder := &events.DefaultPendingPlanFinder{}
@@ -627,7 +1038,6 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	}
 	preWorkflowHooksCommandRunner := &events.DefaultPreWorkflowHooksCommandRunner{
 		VCSClient:        vcsClient,
-		GlobalCfg:        globalCfg,
 		WorkingDirLocker: workingDirLocker,
 		WorkingDir:       workingDir,
 		PreWorkflowHookRunner: runtime.DefaultPreWorkflowHookRunner{
@@ -635,10 +1045,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		},
 		CommitStatusUpdater: commitStatusUpdater,
 		Router:              router,
+		GlobalCfgStore:      globalCfgStore,
 	}
 	postWorkflowHooksCommandRunner := &events.DefaultPostWorkflowHooksCommandRunner{
 		VCSClient:        vcsClient,
-		GlobalCfg:        globalCfg,
 		WorkingDirLocker: workingDirLocker,
 		WorkingDir:       workingDir,
 		PostWorkflowHookRunner: runtime.DefaultPostWorkflowHookRunner{
@@ -646,6 +1056,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		},
 		CommitStatusUpdater: commitStatusUpdater,
 		Router:              router,
+		GlobalCfgStore:      globalCfgStore,
 	}
 	projectCommandBuilder := events.NewInstrumentedProjectCommandBuilder(
 		logger,
@@ -655,7 +1066,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		vcsClient,
 		workingDir,
 		workingDirLocker,
-		globalCfg,
+		globalCfgStore,
 		pendingPlanFinder,
 		commentParser,
 		userConfig.SkipCloneNoChanges,
@@ -672,6 +1083,16 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		statsScope,
 		terraformClient,
 	)
+	if builder, ok := projectCommandBuilder.ProjectCommandBuilder.(*events.DefaultProjectCommandBuilder); ok {
+		builder.RepoCfgSuggestionStore = repoCfgSuggestionStore
+		if userConfig.ProjectNameTemplate != "" {
+			projectNameTemplate, err := events.NewProjectNameTemplate(userConfig.ProjectNameTemplate)
+			if err != nil {
+				return nil, errors.Wrap(err, "parsing project name template")
+			}
+			builder.ProjectNameTemplate = projectNameTemplate
+		}
+	}
 
 	showStepRunner, err := runtime.NewShowStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion)
 
@@ -690,9 +1111,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	}
 
 	applyRequirementHandler := &events.DefaultCommandRequirementHandler{
-		WorkingDir: workingDir,
+		WorkingDir:     workingDir,
+		GlobalCfgStore: globalCfgStore,
 	}
 
+	// Commands that touch the Terraform state retry for a while if the state
+	// is locked by another operation.
+	stateLockRetryExec := runtime.NewStateLockRetryExec(terraformClient, time.Duration(userConfig.StateLockRetrySeconds)*time.Second)
+	pinCheckStepRunner := runtime.NewPinCheckStepRunner(globalCfgStore)
 	projectCommandRunner := &events.DefaultProjectCommandRunner{
 		VcsClient:        vcsClient,
 		Locker:           projectLocker,
@@ -703,11 +1129,11 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			DefaultTFDistribution: defaultTfDistribution,
 			DefaultTFVersion:      defaultTfVersion,
 		},
-		PlanStepRunner:        runtime.NewPlanStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion, commitStatusUpdater, terraformClient),
+		PlanStepRunner:        runtime.NewPlanStepRunner(stateLockRetryExec, defaultTfDistribution, defaultTfVersion, commitStatusUpdater, terraformClient),
 		ShowStepRunner:        showStepRunner,
 		PolicyCheckStepRunner: policyCheckStepRunner,
 		ApplyStepRunner: &runtime.ApplyStepRunner{
-			TerraformExecutor:     terraformClient,
+			TerraformExecutor:     stateLockRetryExec,
 			DefaultTFDistribution: defaultTfDistribution,
 			DefaultTFVersion:      defaultTfVersion,
 			CommitStatusUpdater:   commitStatusUpdater,
@@ -720,42 +1146,106 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		MultiEnvStepRunner: &runtime.MultiEnvStepRunner{
 			RunStepRunner: runStepRunner,
 		},
+		WasmStepRunner: &runtime.WasmStepRunner{
+			RuntimePath: userConfig.WasmRuntime,
+		},
 		VersionStepRunner: &runtime.VersionStepRunner{
 			TerraformExecutor: terraformClient,
 			DefaultTFVersion:  defaultTfVersion,
 		},
-		ImportStepRunner:          runtime.NewImportStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion),
-		StateRmStepRunner:         runtime.NewStateRmStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion),
-		WorkingDir:                workingDir,
-		Webhooks:                  webhooksManager,
-		WorkingDirLocker:          workingDirLocker,
-		CommandRequirementHandler: applyRequirementHandler,
+		ImportStepRunner:           runtime.NewImportStepRunner(stateLockRetryExec, defaultTfDistribution, defaultTfVersion),
+		StateRmStepRunner:          runtime.NewStateRmStepRunner(stateLockRetryExec, defaultTfDistribution, defaultTfVersion),
+		StateListStepRunner:        runtime.NewStateListStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion),
+		StateShowStepRunner:        runtime.NewStateShowStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion),
+		ForceUnlockStateStepRunner: runtime.NewForceUnlockStateStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion),
+		PinCheckStepRunner:         pinCheckStepRunner,
+		ProvidersSchemaStepRunner:  runtime.NewProvidersSchemaStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion, filepath.Join(userConfig.DataDir, ProvidersSchemaCacheDirName)),
+		OutputStepRunner:           runtime.NewOutputStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion),
+		WorkingDir:                 workingDir,
+		Webhooks:                   webhooksManager,
+		WorkingDirLocker:           workingDirLocker,
+		CommandRequirementHandler:  applyRequirementHandler,
+		PlanJSONStore:              planJSONStore,
+		PlanChangesStore:           planChangesStore,
+		SharedOutputsStore:         sharedOutputsStore,
+		AutoApprovalAuditLog:       &events.FileAutoApprovalAuditLog{Path: filepath.Join(userConfig.DataDir, AutoApprovalAuditLogFileName)},
+		ApplyWindowAdmins:          userConfig.ToApplyWindowAdmins(),
+	}
+	if userConfig.AuthFailureHint != "" {
+		projectCommandRunner.AuthFailureHint, err = events.NewAuthFailureHint(userConfig.AuthFailureHint)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing auth failure hint")
+		}
+	}
+	if sbomStore != nil {
+		projectCommandRunner.SBOMGenerator = &runtime.SBOMGenerator{
+			DefaultTFDistribution: defaultTfDistribution,
+			DefaultTFVersion:      defaultTfVersion,
+		}
+		projectCommandRunner.SBOMStore = sbomStore
+	}
+	if provenanceStore != nil {
+		projectCommandRunner.ProvenanceStore = provenanceStore
+		projectCommandRunner.JobURLGenerator = router
+	}
+	projectCommandRunner.ReviewCommenters = reviewCommenters
+	if stateSnapshotStore != nil {
+		projectCommandRunner.StateSnapshotStore = stateSnapshotStore
+		projectCommandRunner.StatePullStepRunner = runtime.NewStatePullStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion)
 	}
 
 	dbUpdater := &events.DBUpdater{
 		Backend: backend,
 	}
 
+	commentReactions, err := events.ParseCommentReactions(userConfig.EmojiReactions)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing emoji reactions")
+	}
 	pullUpdater := &events.PullUpdater{
 		HidePrevPlanComments: userConfig.HidePrevPlanComments,
 		VCSClient:            vcsClient,
 		MarkdownRenderer:     markdownRenderer,
+		CommentReactions:     commentReactions,
+		GlobalCfgStore:       globalCfgStore,
+		SilenceStore:         silenceStore,
 	}
 
 	autoMerger := &events.AutoMerger{
-		VCSClient:       vcsClient,
-		GlobalAutomerge: userConfig.Automerge,
+		VCSClient:        vcsClient,
+		GlobalAutomerge:  userConfig.Automerge,
+		ProvenanceStore:  provenanceStore,
+		GithubPullGetter: githubClient,
+		AtlantisURL:      parsedURL.String(),
+		StatusName:       userConfig.VCSStatusName,
+	}
+
+	var pluginProjectCommandRunner events.ProjectCommandRunner = projectCommandRunner
+	if lifecyclePlugins != nil {
+		pluginProjectCommandRunner = &events.LifecyclePluginProjectCommandRunner{
+			ProjectCommandRunner: projectCommandRunner,
+			Plugins:              lifecyclePlugins,
+		}
 	}
 
 	projectOutputWrapper := &events.ProjectOutputWrapper{
 		JobMessageSender:     projectCmdOutputHandler,
-		ProjectCommandRunner: projectCommandRunner,
+		ProjectCommandRunner: pluginProjectCommandRunner,
 		JobURLSetter:         jobs.NewJobURLSetter(router, commitStatusUpdater),
 	}
 	instrumentedProjectCmdRunner := events.NewInstrumentedProjectCommandRunner(
 		statsScope,
 		projectOutputWrapper,
 	)
+	instrumentedProjectCmdRunner.MetricsLabels = globalCfg.Metrics.ProjectLabels
+	var usageLog events.UsageLog
+	if userConfig.UsageHistoryDays > 0 {
+		usageLog = &events.FileUsageLog{
+			Path:      filepath.Join(userConfig.DataDir, UsageLogFileName),
+			Retention: time.Duration(userConfig.UsageHistoryDays) * 24 * time.Hour,
+		}
+		instrumentedProjectCmdRunner.UsageLog = usageLog
+	}
 
 	policyCheckCommandRunner := events.NewPolicyCheckCommandRunner(
 		dbUpdater,
@@ -788,6 +1278,13 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.DiscardApprovalOnPlanFlag,
 		pullReqStatusFetcher,
 	)
+	planCommandRunner.PlanFreezer = planFreezer
+	if userConfig.ParallelPlanScheduling {
+		planCommandRunner.Scheduler = events.NewPlanScheduler(userConfig.ParallelRepoPoolSize)
+	}
+	planCommandRunner.PlanFreezeMessage = userConfig.PlanFreezeMessage
+	planCommandRunner.GlobalCfgStore = globalCfgStore
+	planCommandRunner.AutoApplyStore = autoApplyStore
 
 	applyCommandRunner := events.NewApplyCommandRunner(
 		vcsClient,
@@ -805,6 +1302,19 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.SilenceVCSStatusNoProjects,
 		pullReqStatusFetcher,
 	)
+	applyCommandRunner.AutoReplanDiverged = userConfig.AutoReplanDiverged
+	applyCommandRunner.Replanner = planCommandRunner
+	planCommandRunner.PreviewEnvironmentDeployer = applyCommandRunner
+	applyCommandRunner.RequirementHandler = applyRequirementHandler
+	applyCommandRunner.WorkingDir = workingDir
+	applyCommandRunner.CanaryStore = canaryStore
+	applyCommandRunner.CommentUpdaters = commentUpdaters
+	if userConfig.AzureDevopsWorkItems && azuredevopsClient != nil {
+		applyCommandRunner.WorkItemUpdater = &events.WorkItemUpdater{
+			Client:       azuredevopsClient,
+			AppliedState: userConfig.AzureDevopsAppliedState,
+		}
+	}
 
 	approvePoliciesCommandRunner := events.NewApprovePoliciesCommandRunner(
 		commitStatusUpdater,
@@ -839,6 +1349,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		instrumentedProjectCmdRunner,
 		userConfig.SilenceNoProjects,
 	)
+	importCommandRunner.Replan = userConfig.ImportReplan
+	importCommandRunner.PlanCommandBuilder = projectCommandBuilder
+	importCommandRunner.PlanCommandRunner = instrumentedProjectCmdRunner
+	importCommandRunner.DBUpdater = dbUpdater
 
 	stateCommandRunner := events.NewStateCommandRunner(
 		pullUpdater,
@@ -846,34 +1360,65 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		instrumentedProjectCmdRunner,
 	)
 
+	forceUnlockStateCommandRunner := events.NewForceUnlockStateCommandRunner(
+		pullUpdater,
+		projectCommandBuilder,
+		instrumentedProjectCmdRunner,
+		userConfig.ToForceUnlockStateAdmins(),
+	)
+
+	rollbackCommandRunner := events.NewRollbackCommandRunner(
+		pullUpdater,
+		dbUpdater,
+		pullReqStatusFetcher,
+		projectCommandBuilder,
+		projectOutputWrapper,
+	)
+
+	silenceCommandRunner := events.NewSilenceCommandRunner(
+		vcsClient,
+		silenceStore,
+	)
+
+	pingCommandRunner := events.NewPingCommandRunner(
+		vcsClient,
+		commitStatusUpdater,
+		config.AtlantisVersion,
+	)
+
 	commentCommandRunnerByCmd := map[command.Name]events.CommentCommandRunner{
-		command.Plan:            planCommandRunner,
-		command.Apply:           applyCommandRunner,
-		command.ApprovePolicies: approvePoliciesCommandRunner,
-		command.Unlock:          unlockCommandRunner,
-		command.Version:         versionCommandRunner,
-		command.Import:          importCommandRunner,
-		command.State:           stateCommandRunner,
-	}
-
-	var teamAllowlistChecker command.TeamAllowlistChecker
-	if globalCfg.TeamAuthz.Command != "" {
-		teamAllowlistChecker = &events.ExternalTeamAllowlistChecker{
-			Command:                     globalCfg.TeamAuthz.Command,
-			ExtraArgs:                   globalCfg.TeamAuthz.Args,
-			ExternalTeamAllowlistRunner: &runtime.DefaultExternalTeamAllowlistRunner{},
-		}
-	} else if userConfig.GitlabUser != "" {
-		teamAllowlistChecker, err = command.NewTeamAllowlistChecker(userConfig.GitlabGroupAllowlist)
+		command.Plan:             planCommandRunner,
+		command.Apply:            applyCommandRunner,
+		command.ApprovePolicies:  approvePoliciesCommandRunner,
+		command.Unlock:           unlockCommandRunner,
+		command.Version:          versionCommandRunner,
+		command.Import:           importCommandRunner,
+		command.State:            stateCommandRunner,
+		command.ForceUnlockState: forceUnlockStateCommandRunner,
+		command.Rollback:         rollbackCommandRunner,
+		command.Silence:          silenceCommandRunner,
+		command.Ping:             pingCommandRunner,
+	}
+
+	// The team_authz command is read from the current config when commands
+	// are checked, the allowlist flags are used if it isn't set.
+	var allowlistFallback command.TeamAllowlistChecker
+	if userConfig.GitlabUser != "" {
+		allowlistFallback, err = command.NewTeamAllowlistChecker(userConfig.GitlabGroupAllowlist)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		teamAllowlistChecker, err = command.NewTeamAllowlistChecker(userConfig.GithubTeamAllowlist)
+		allowlistFallback, err = command.NewTeamAllowlistChecker(userConfig.GithubTeamAllowlist)
 		if err != nil {
 			return nil, err
 		}
 	}
+	teamAllowlistChecker := &events.GlobalCfgTeamAllowlistChecker{
+		GlobalCfgStore:              globalCfgStore,
+		Fallback:                    allowlistFallback,
+		ExternalTeamAllowlistRunner: &runtime.DefaultExternalTeamAllowlistRunner{},
+	}
 
 	varFileAllowlistChecker, err := events.NewVarFileAllowlistChecker(userConfig.VarFileAllowlist)
 	if err != nil {
@@ -883,6 +1428,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	commandRunner := &events.DefaultCommandRunner{
 		VCSClient:                      vcsClient,
 		GithubPullGetter:               githubClient,
+		GithubBranchGetter:             githubBranchGetter,
 		GitlabMergeRequestGetter:       gitlabClient,
 		AzureDevopsPullGetter:          azuredevopsClient,
 		GiteaPullGetter:                giteaClient,
@@ -890,7 +1436,6 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		EventParser:                    eventParser,
 		FailOnPreWorkflowHookError:     userConfig.FailOnPreWorkflowHookError,
 		Logger:                         logger,
-		GlobalCfg:                      globalCfg,
 		StatsScope:                     statsScope.SubScope("cmd"),
 		AllowForkPRs:                   userConfig.AllowForkPRs,
 		AllowForkPRsFlag:               config.AllowForkPRsFlag,
@@ -905,11 +1450,48 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		TeamAllowlistChecker:           teamAllowlistChecker,
 		VarFileAllowlistChecker:        varFileAllowlistChecker,
 		CommitStatusUpdater:            commitStatusUpdater,
-	}
+		IssueOpsCleaner:                pullClosedExecutor,
+		GlobalCfgStore:                 globalCfgStore,
+		ChangeSets:                     changeSets,
+		ApplyRequirementsChecker:       applyCommandRunner,
+		Deduplicator:                   events.NewCommandDeduplicator(),
+		CommentReactions:               commentReactions,
+	}
+	scheduledExecutorService.AddJob(scheduled.JobDefinition{
+		Job: &events.CanaryPromoter{
+			CanaryStore:   canaryStore,
+			CommandRunner: commandRunner,
+			Logger:        logger,
+		},
+		Period: time.Minute,
+	})
+	scheduledExecutorService.AddJob(scheduled.JobDefinition{
+		Job: &events.AutoApplier{
+			AutoApplyStore:    autoApplyStore,
+			CommandRunner:     commandRunner,
+			PullStatusFetcher: backend,
+			Logger:            logger,
+		},
+		Period: time.Minute,
+	})
 	repoAllowlist, err := events.NewRepoAllowlistChecker(userConfig.RepoAllowlist)
 	if err != nil {
 		return nil, err
 	}
+	var repoAllowlistDiscoverer *events.RepoAllowlistDiscoverer
+	if len(repoAllowlist.TopicRules()) > 0 && githubRepoDiscoveryClient != nil {
+		repoAllowlistDiscoverer = &events.RepoAllowlistDiscoverer{
+			Logger:   logger,
+			Client:   githubRepoDiscoveryClient,
+			Checker:  repoAllowlist,
+			Hostname: userConfig.GithubHostname,
+		}
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    repoAllowlistDiscoverer,
+			Period: time.Duration(userConfig.RepoAllowlistRefreshSeconds) * time.Second,
+		})
+	}
+	lockAuditLog := &events.FileLockAuditLog{Path: filepath.Join(userConfig.DataDir, LockAuditLogFileName)}
 	locksController := &controllers.LocksController{
 		AtlantisVersion:    config.AtlantisVersion,
 		AtlantisURL:        parsedURL,
@@ -922,6 +1504,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WorkingDirLocker:   workingDirLocker,
 		Backend:            backend,
 		DeleteLockCommand:  deleteLockCommand,
+		PlanFreezer:        planFreezer,
+		AuditLog:           lockAuditLog,
 	}
 
 	wsMux := websocket.NewMultiplexor(
@@ -930,6 +1514,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		projectCmdOutputHandler,
 		userConfig.WebsocketCheckOrigin,
 	)
+	// The job board is disabled if the output of jobs isn't streamed.
+	jobBoard, _ := projectCmdOutputHandler.(jobs.JobBoard)
 
 	jobsController := &controllers.JobsController{
 		AtlantisVersion:          config.AtlantisVersion,
@@ -941,6 +1527,11 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WsMux:                    wsMux,
 		KeyGenerator:             controllers.JobIDKeyGenerator{},
 		StatsScope:               statsScope.SubScope("api"),
+		JobLogStore:              jobLogStore,
+		JobLogSearchTemplate:     web_templates.JobLogSearchTemplate,
+		JobBoard:                 jobBoard,
+		RunningJobsTemplate:      web_templates.RunningJobsTemplate,
+		WsWriter:                 websocket.NewWriter(logger, userConfig.WebsocketCheckOrigin),
 	}
 
 	apiController := &controllers.APIController{
@@ -960,11 +1551,31 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WorkingDir:                     workingDir,
 		WorkingDirLocker:               workingDirLocker,
 		CommitStatusUpdater:            commitStatusUpdater,
+		SBOMStore:                      sbomStore,
+		ProvenanceStore:                provenanceStore,
+		PlanJSONStore:                  planJSONStore,
+		VCSDebugLogging:                vcsDebugLogging,
+		RepoConfigReloader:             repoConfigReloader,
+		LogStore:                       logStore,
+		JobLogStore:                    jobLogStore,
+		UsageLog:                       usageLog,
+		PlanFreezer:                    planFreezer,
+		ChangeSets:                     changeSets,
+		ProjectReplanner: &events.ProjectReplanner{
+			PullStatusLister: backend,
+			CommandRunner:    commandRunner,
+			Logger:           logger,
+			Interval:         events.DefaultReplanInterval,
+		},
+		GithubStatusChecks: githubStatusChecksLister,
+		StatusName:         userConfig.VCSStatusName,
+		GlobalCfgStore:     globalCfgStore,
 	}
 
 	eventsController := &events_controllers.VCSEventsController{
 		CommandRunner:                   commandRunner,
 		PullCleaner:                     pullClosedExecutor,
+		ClosedPullDestroyer:             commandRunner,
 		Parser:                          eventParser,
 		CommentParser:                   commentParser,
 		Logger:                          logger,
@@ -977,7 +1588,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		RepoAllowlistChecker:            repoAllowlist,
 		SilenceAllowlistErrors:          userConfig.SilenceAllowlistErrors,
 		EmojiReaction:                   userConfig.EmojiReaction,
+		CommentReactions:                commentReactions,
 		ExecutableName:                  userConfig.ExecutableName,
+		IssueOpsCommands:                issueOpsCommands,
 		SupportedVCSHosts:               supportedVCSHosts,
 		VCSClient:                       vcsClient,
 		BitbucketWebhookSecret:          []byte(userConfig.BitbucketWebhookSecret),
@@ -986,6 +1599,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		AzureDevopsRequestValidator:     &events_controllers.DefaultAzureDevopsRequestValidator{},
 		GiteaWebhookSecret:              []byte(userConfig.GiteaWebhookSecret),
 	}
+	if userConfig.WebhookHistorySize > 0 {
+		eventsController.WebhookHistory = events_controllers.NewWebhookHistory(userConfig.WebhookHistorySize)
+		apiController.Webhooks = eventsController
+	}
 	githubAppController := &controllers.GithubAppController{
 		AtlantisURL:         parsedURL,
 		Logger:              logger,
@@ -1008,6 +1625,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		StatsCloser:                    closer,
 		Locker:                         lockingClient,
 		ApplyLocker:                    applyLockingClient,
+		PlanFreezer:                    planFreezer,
+		LockAuditLog:                   lockAuditLog,
 		VCSEventsController:            eventsController,
 		GithubAppController:            githubAppController,
 		LocksController:                locksController,
@@ -1022,11 +1641,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		SSLCertFile:                    userConfig.SSLCertFile,
 		DisableGlobalApplyLock:         userConfig.DisableGlobalApplyLock,
 		Drainer:                        drainer,
+		VCSClients:                     vcsMiddlewareClients,
 		ProjectCmdOutputHandler:        projectCmdOutputHandler,
 		WebAuthentication:              userConfig.WebBasicAuth,
 		WebUsername:                    userConfig.WebUsername,
 		WebPassword:                    userConfig.WebPassword,
 		ScheduledExecutorService:       scheduledExecutorService,
+		WebhookRegistrar:               webhookRegistrar,
+		RepoAllowlistDiscoverer:        repoAllowlistDiscoverer,
 	}
 
 	validate := validator.New(validator.WithRequiredStructEnabled())
@@ -1051,17 +1673,44 @@ func (s *Server) Start() error {
 	s.Router.HandleFunc("/api/plan", s.APIController.Plan).Methods("POST")
 	s.Router.HandleFunc("/api/apply", s.APIController.Apply).Methods("POST")
 	s.Router.HandleFunc("/api/locks", s.APIController.ListLocks).Methods("GET")
+	s.Router.HandleFunc("/api/sboms", s.APIController.ListSBOMs).Methods("GET")
+	s.Router.HandleFunc("/api/provenance", s.APIController.GetProvenance).Methods("GET")
+	s.Router.HandleFunc("/api/plans", s.APIController.ListPlans).Methods("GET")
+	s.Router.HandleFunc("/api/vcs-debug-logging", s.APIController.GetVCSDebugLogging).Methods("GET")
+	s.Router.HandleFunc("/api/vcs-debug-logging", s.APIController.SetVCSDebugLogging).Methods("PUT")
+	s.Router.HandleFunc("/api/repo-config/reload", s.APIController.ReloadRepoConfig).Methods("POST")
+	s.Router.HandleFunc("/api/webhooks", s.APIController.ListWebhooks).Methods("GET")
+	s.Router.HandleFunc("/api/webhooks/replay", s.APIController.ReplayWebhook).Methods("POST")
+	s.Router.HandleFunc("/api/logs", s.APIController.ListCommandLogs).Methods("GET")
+	s.Router.HandleFunc("/api/jobs", s.APIController.SearchJobLogs).Methods("GET")
+	s.Router.HandleFunc("/api/usage", s.APIController.UsageReport).Methods("GET")
+	s.Router.HandleFunc("/api/plan-freezes", s.APIController.ListPlanFreezes).Methods("GET")
+	s.Router.HandleFunc("/api/plan-freezes", s.APIController.FreezePlans).Methods("POST")
+	s.Router.HandleFunc("/api/plan-freezes", s.APIController.UnfreezePlans).Methods("DELETE")
+	s.Router.HandleFunc("/api/changesets", s.APIController.ListChangeSets).Methods("GET")
+	s.Router.HandleFunc("/api/changesets", s.APIController.CreateChangeSet).Methods("POST")
+	s.Router.HandleFunc("/api/changesets", s.APIController.DeleteChangeSet).Methods("DELETE")
+	s.Router.HandleFunc("/api/replan", s.APIController.Replan).Methods("POST")
+	s.Router.HandleFunc("/api/github/status-migration", s.APIController.GithubStatusMigration).Methods("GET")
 	s.Router.HandleFunc("/github-app/exchange-code", s.GithubAppController.ExchangeCode).Methods("GET")
 	s.Router.HandleFunc("/github-app/setup", s.GithubAppController.New).Methods("GET")
+	s.Router.HandleFunc("/plan-freezes", s.LocksController.FreezePlans).Methods("POST")
+	s.Router.HandleFunc("/plan-freezes", s.LocksController.UnfreezePlans).Methods("DELETE")
 	s.Router.HandleFunc("/locks", s.LocksController.DeleteLock).Methods("DELETE").Queries("id", "{id:.*}")
+	s.Router.HandleFunc("/locks/unlock", s.LocksController.DeleteLocks).Methods("POST")
 	s.Router.HandleFunc("/lock", s.LocksController.GetLock).Methods("GET").
 		Queries(LockViewRouteIDQueryParam, fmt.Sprintf("{%s}", LockViewRouteIDQueryParam)).Name(LockViewRouteName)
+	s.Router.HandleFunc("/jobs/search", s.JobsController.SearchJobLogs).Methods("GET")
+	s.Router.HandleFunc("/jobs/history/{job-id}", s.JobsController.GetJobLog).Methods("GET")
+	s.Router.HandleFunc("/jobs/running", s.JobsController.GetRunningJobs).Methods("GET")
+	s.Router.HandleFunc("/jobs/running/ws", s.JobsController.GetRunningJobsWS).Methods("GET")
+	s.Router.HandleFunc("/jobs/{job-id}/cancel", s.JobsController.CancelJob).Methods("POST")
 	s.Router.HandleFunc("/jobs/{job-id}", s.JobsController.GetProjectJobs).Methods("GET").Name(ProjectJobsViewRouteName)
 	s.Router.HandleFunc("/jobs/{job-id}/ws", s.JobsController.GetProjectJobsWS).Methods("GET")
 
 	r, ok := s.StatsReporter.(prometheus.Reporter)
 	if ok {
-		s.Router.Handle(s.CommandRunner.GlobalCfg.Metrics.Prometheus.Endpoint, r.HTTPHandler())
+		s.Router.Handle(s.CommandRunner.GlobalCfgStore.Load().Metrics.Prometheus.Endpoint, r.HTTPHandler())
 	}
 	if !s.DisableGlobalApplyLock {
 		s.Router.HandleFunc("/apply/lock", s.LocksController.LockApply).Methods("POST").Queries()
@@ -1085,6 +1734,14 @@ func (s *Server) Start() error {
 
 	go s.ScheduledExecutorService.Run()
 
+	if s.WebhookRegistrar != nil {
+		go s.WebhookRegistrar.Register()
+	}
+
+	if s.RepoAllowlistDiscoverer != nil {
+		go s.RepoAllowlistDiscoverer.Run()
+	}
+
 	go func() {
 		s.ProjectCmdOutputHandler.Handle()
 	}()
@@ -1143,6 +1800,34 @@ func (s *Server) waitForDrain() {
 	}
 }
 
+// lockLabels returns the labels of the project locked by lock from the status
+// of its pull request. statuses caches the statuses of the pull requests
+// since a pull request can hold several locks.
+func (s *Server) lockLabels(statuses map[string]*models.PullStatus, lock models.ProjectLock) map[string]string {
+	if s.LocksController == nil || s.LocksController.Backend == nil {
+		return nil
+	}
+	key := fmt.Sprintf("%s#%d", lock.Project.RepoFullName, lock.Pull.Num)
+	status, ok := statuses[key]
+	if !ok {
+		var err error
+		status, err = s.LocksController.Backend.GetPullStatus(lock.Pull)
+		if err != nil {
+			s.Logger.Warn("getting the status of %s: %s", key, err)
+		}
+		statuses[key] = status
+	}
+	if status == nil {
+		return nil
+	}
+	for _, project := range status.Projects {
+		if project.RepoRelDir == lock.Project.Path && project.Workspace == lock.Workspace && project.ProjectName == lock.Project.ProjectName {
+			return project.Labels
+		}
+	}
+	return nil
+}
+
 // Index is the / route.
 func (s *Server) Index(w http.ResponseWriter, _ *http.Request) {
 	locks, err := s.Locker.List()
@@ -1152,23 +1837,52 @@ func (s *Server) Index(w http.ResponseWriter, _ *http.Request) {
 		return
 	}
 
+	now := time.Now()
 	var lockResults []web_templates.LockIndexData
+	pullStatuses := make(map[string]*models.PullStatus)
 	for id, v := range locks {
 		lockURL, _ := s.Router.Get(LockViewRouteName).URL("id", url.QueryEscape(id))
 		lockResults = append(lockResults, web_templates.LockIndexData{
 			// NOTE: must use .String() instead of .Path because we need the
 			// query params as part of the lock URL.
 			LockPath:      lockURL.String(),
+			LockID:        id,
 			RepoFullName:  v.Project.RepoFullName,
 			LockedBy:      v.Pull.Author,
+			User:          v.User.Username,
 			PullNum:       v.Pull.Num,
+			PullURL:       v.Pull.URL,
+			ProjectName:   v.Project.ProjectName,
 			Path:          v.Project.Path,
 			Workspace:     v.Workspace,
+			Command:       v.Command,
 			Time:          v.Time,
 			TimeFormatted: v.Time.Format("2006-01-02 15:04:05"),
+			Age:           formatLockAge(now.Sub(v.Time)),
+			Labels:        s.lockLabels(pullStatuses, v),
 		})
 	}
 
+	var lockAuditResults []web_templates.LockAuditIndexData
+	if s.LockAuditLog != nil {
+		entries, err := s.LockAuditLog.Recent(lockAuditEntriesShown)
+		if err != nil {
+			// The locks can still be managed without the audit log.
+			s.Logger.Err("reading lock audit log: %s", err)
+		}
+		for _, e := range entries {
+			lockAuditResults = append(lockAuditResults, web_templates.LockAuditIndexData{
+				RepoFullName:  e.RepoFullName,
+				PullNum:       e.PullNum,
+				Path:          e.Path,
+				Workspace:     e.Workspace,
+				LockedBy:      e.LockedBy,
+				Actor:         e.Actor,
+				TimeFormatted: e.Time.Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+
 	applyCmdLock, err := s.ApplyLocker.CheckApplyLock()
 	s.Logger.Debug("Apply Lock: %v", applyCmdLock)
 	if err != nil {
@@ -1183,21 +1897,58 @@ func (s *Server) Index(w http.ResponseWriter, _ *http.Request) {
 		GlobalApplyLockEnabled: applyCmdLock.GlobalApplyLockEnabled,
 		TimeFormatted:          applyCmdLock.Time.Format("2006-01-02 15:04:05"),
 	}
+	planFreezes, err := s.PlanFreezer.List()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "Could not retrieve plan freezes: %s", err)
+		return
+	}
+	var planFreezeResults []web_templates.PlanFreezeIndexData
+	plansFrozenGlobally := false
+	for _, f := range planFreezes {
+		plansFrozenGlobally = plansFrozenGlobally || f.IsGlobal()
+		planFreezeResults = append(planFreezeResults, web_templates.PlanFreezeIndexData{
+			Repo:          f.Repo,
+			Project:       f.Project,
+			Dir:           f.Dir,
+			Message:       f.Message,
+			TimeFormatted: f.Time.Format("2006-01-02 15:04:05"),
+		})
+	}
+
 	//Sort by date - newest to oldest.
 	sort.SliceStable(lockResults, func(i, j int) bool { return lockResults[i].Time.After(lockResults[j].Time) })
 
 	err = s.IndexTemplate.Execute(w, web_templates.IndexData{
-		Locks:            lockResults,
-		PullToJobMapping: preparePullToJobMappings(s),
-		ApplyLock:        applyLockData,
-		AtlantisVersion:  s.AtlantisVersion,
-		CleanedBasePath:  s.AtlantisURL.Path,
+		Locks:               lockResults,
+		LockAudit:           lockAuditResults,
+		PullToJobMapping:    preparePullToJobMappings(s),
+		JobLogSearchEnabled: s.JobsController != nil && s.JobsController.JobLogStore != nil,
+		JobBoardEnabled:     s.JobsController != nil && s.JobsController.JobBoard != nil,
+		ApplyLock:           applyLockData,
+		PlanFreezes:         planFreezeResults,
+		PlansFrozenGlobally: plansFrozenGlobally,
+		AtlantisVersion:     s.AtlantisVersion,
+		CleanedBasePath:     s.AtlantisURL.Path,
 	})
 	if err != nil {
 		s.Logger.Err(err.Error())
 	}
 }
 
+// formatLockAge returns the age of a lock as shown on the index page, ex. 3h
+// or 2d.
+func formatLockAge(age time.Duration) string {
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
 func preparePullToJobMappings(s *Server) []jobs.PullInfoWithJobIDs {
 
 	pullToJobMappings := s.ProjectCmdOutputHandler.GetPullToJobMapping()
@@ -1241,10 +1992,39 @@ func mkSubDir(parentDir string, subDir string) (string, error) {
 	return fullDir, nil
 }
 
-// Healthz returns the health check response. It always returns a 200 currently.
+// Healthz returns the health check response. It always returns a 200 so
+// Atlantis isn't restarted while a VCS host is down, but the status is
+// "degraded" if any VCS host's circuit breaker is open.
 func (s *Server) Healthz(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Write(healthzData) // nolint: errcheck
+
+	var degraded []string
+	for _, client := range s.VCSClients {
+		if client.Degraded() {
+			degraded = append(degraded, client.Host.String())
+		}
+	}
+	if len(degraded) == 0 {
+		w.Write(healthzData) // nolint: errcheck
+		return
+	}
+	data, err := json.MarshalIndent(&HealthzResponse{
+		Status:           "degraded",
+		DegradedVCSHosts: degraded,
+	}, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Error creating healthz json response: %s", err)
+		return
+	}
+	w.Write(data) // nolint: errcheck
+}
+
+// HealthzResponse is the response of /healthz.
+type HealthzResponse struct {
+	Status string `json:"status"`
+	// DegradedVCSHosts are the VCS hosts whose circuit breaker is open.
+	DegradedVCSHosts []string `json:"degraded_vcs_hosts,omitempty"`
 }
 
 var healthzData = []byte(`{
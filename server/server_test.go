@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -30,11 +31,16 @@ import (
 	"github.com/runatlantis/atlantis/server"
 	"github.com/runatlantis/atlantis/server/controllers/web_templates"
 	tMocks "github.com/runatlantis/atlantis/server/controllers/web_templates/mocks"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
 )
 
 const (
@@ -108,6 +114,14 @@ func TestIndex_Success(t *testing.T) {
 		},
 	}
 	When(l.List()).ThenReturn(locks, nil)
+	boltDB, err := db.New(t.TempDir())
+	Ok(t, err)
+	defer boltDB.Close()
+	planFreezer := locking.NewPlanFreezer(boltDB)
+	_, err = planFreezer.Freeze(locking.PlanFreeze{Repo: "lkysow/atlantis-example", Message: "incident", Time: now})
+	Ok(t, err)
+	auditLog := &events.FileLockAuditLog{Path: filepath.Join(t.TempDir(), "lock-audit.jsonl")}
+	Ok(t, auditLog.Record(events.LockAuditEntry{RepoFullName: "lkysow/atlantis-example", PullNum: 8, Path: ".", Workspace: "default", LockedBy: "lkysow", Actor: "admin", Time: now}))
 	it := tMocks.NewMockTemplateWriter()
 	r := mux.NewRouter()
 	atlantisVersion := "0.3.1"
@@ -119,6 +133,8 @@ func TestIndex_Success(t *testing.T) {
 	s := server.Server{
 		Locker:                  l,
 		ApplyLocker:             al,
+		PlanFreezer:             planFreezer,
+		LockAuditLog:            auditLog,
 		IndexTemplate:           it,
 		Router:                  r,
 		AtlantisVersion:         atlantisVersion,
@@ -138,10 +154,30 @@ func TestIndex_Success(t *testing.T) {
 		Locks: []web_templates.LockIndexData{
 			{
 				LockPath:      "/lock?id=lkysow%252Fatlantis-example%252F.%252Fdefault",
+				LockID:        "lkysow/atlantis-example/./default",
 				RepoFullName:  "lkysow/atlantis-example",
 				PullNum:       9,
 				Time:          now,
 				TimeFormatted: now.Format("2006-01-02 15:04:05"),
+				Age:           "0m",
+			},
+		},
+		LockAudit: []web_templates.LockAuditIndexData{
+			{
+				RepoFullName:  "lkysow/atlantis-example",
+				PullNum:       8,
+				Path:          ".",
+				Workspace:     "default",
+				LockedBy:      "lkysow",
+				Actor:         "admin",
+				TimeFormatted: now.Format("2006-01-02 15:04:05"),
+			},
+		},
+		PlanFreezes: []web_templates.PlanFreezeIndexData{
+			{
+				Repo:          "lkysow/atlantis-example",
+				Message:       "incident",
+				TimeFormatted: now.Format("2006-01-02 15:04:05"),
 			},
 		},
 		PullToJobMapping: []jobs.PullInfoWithJobIDs{},
@@ -167,6 +203,37 @@ func TestHealthz(t *testing.T) {
 }`, string(body))
 }
 
+func TestHealthz_DegradedVCSHost(t *testing.T) {
+	gitlabClient := vcs.NewMiddlewareClient(&vcs.NotConfiguredVCSClient{Host: models.Gitlab}, models.Gitlab, vcs.MiddlewareConfig{
+		BreakerThreshold: 1,
+		BreakerCooldown:  time.Minute,
+	}, tally.NoopScope)
+	s := server.Server{
+		VCSClients: []*vcs.MiddlewareClient{
+			vcs.NewMiddlewareClient(&vcs.NotConfiguredVCSClient{Host: models.Github}, models.Github, vcs.MiddlewareConfig{BreakerThreshold: 1}, tally.NoopScope),
+			gitlabClient,
+		},
+	}
+	_, err := gitlabClient.GetModifiedFiles(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{})
+	Assert(t, err != nil, "expected an error from the gitlab client")
+
+	req, _ := http.NewRequest("GET", "/healthz", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	s.Healthz(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	Equals(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	Equals(t,
+		`{
+  "status": "degraded",
+  "degraded_vcs_hosts": [
+    "Gitlab"
+  ]
+}`, string(body))
+}
+
 type mockRW struct{}
 
 var _ http.ResponseWriter = mockRW{}
@@ -2,22 +2,32 @@ package server
 
 import (
 	"encoding/json"
+	"net/url"
 	"strings"
 
 	"github.com/pkg/errors"
 
+	"github.com/runatlantis/atlantis/server/core/terraform/tfclient"
 	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/utils"
 )
 
 // UserConfig holds config values passed in by the user.
 // The mapstructure tags correspond to flags in cmd/server.go and are used when
 // the config is parsed from a YAML file.
 type UserConfig struct {
+	AggregateCommitStatuses     bool   `mapstructure:"aggregate-commit-statuses"`
 	AllowForkPRs                bool   `mapstructure:"allow-fork-prs"`
 	AllowCommands               string `mapstructure:"allow-commands"`
+	ApplyWindowAdmins           string `mapstructure:"apply-window-admins"`
 	AtlantisURL                 string `mapstructure:"atlantis-url"`
+	AuthFailureHint             string `mapstructure:"auth-failure-hint"`
 	AutoDiscoverModeFlag        string `mapstructure:"autodiscover-mode"`
+	AutoReplanDiverged          bool   `mapstructure:"auto-replan-diverged"`
 	Automerge                   bool   `mapstructure:"automerge"`
 	AutoplanFileList            string `mapstructure:"autoplan-file-list"`
 	AutoplanModules             bool   `mapstructure:"autoplan-modules"`
@@ -27,12 +37,21 @@ type UserConfig struct {
 	AzureDevopsWebhookPassword  string `mapstructure:"azuredevops-webhook-password"`
 	AzureDevopsWebhookUser      string `mapstructure:"azuredevops-webhook-user"`
 	AzureDevOpsHostname         string `mapstructure:"azuredevops-hostname"`
+	AzureDevopsWorkItems        bool   `mapstructure:"azuredevops-work-items"`
+	AzureDevopsAppliedState     string `mapstructure:"azuredevops-work-item-applied-state"`
 	BitbucketBaseURL            string `mapstructure:"bitbucket-base-url"`
+	BitbucketLabelPattern       string `mapstructure:"bitbucket-label-pattern"`
+	BitbucketMaxAttempts        int    `mapstructure:"bitbucket-max-attempts"`
+	BitbucketMaxDelaySeconds    int    `mapstructure:"bitbucket-max-delay-seconds"`
 	BitbucketToken              string `mapstructure:"bitbucket-token"`
 	BitbucketUser               string `mapstructure:"bitbucket-user"`
 	BitbucketWebhookSecret      string `mapstructure:"bitbucket-webhook-secret"`
 	CheckoutDepth               int    `mapstructure:"checkout-depth"`
 	CheckoutStrategy            string `mapstructure:"checkout-strategy"`
+	CloneCache                  bool   `mapstructure:"clone-cache"`
+	CommandLogHistorySize       int    `mapstructure:"command-log-history-size"`
+	CommentTranslationsFile     string `mapstructure:"comment-translations-file"`
+	DatadogAPIKey               string `mapstructure:"datadog-api-key"`
 	DataDir                     string `mapstructure:"data-dir"`
 	DisableApplyAll             bool   `mapstructure:"disable-apply-all"`
 	DisableAutoplan             bool   `mapstructure:"disable-autoplan"`
@@ -43,15 +62,22 @@ type UserConfig struct {
 	DisableUnlockLabel          string `mapstructure:"disable-unlock-label"`
 	DiscardApprovalOnPlanFlag   bool   `mapstructure:"discard-approval-on-plan"`
 	EmojiReaction               string `mapstructure:"emoji-reaction"`
+	EmojiReactions              string `mapstructure:"emoji-reactions"`
+	EnableApplyProvenance       bool   `mapstructure:"enable-apply-provenance"`
+	EnablePlanJSONAPI           bool   `mapstructure:"enable-plan-json-api"`
 	EnablePolicyChecksFlag      bool   `mapstructure:"enable-policy-checks"`
 	EnableRegExpCmd             bool   `mapstructure:"enable-regexp-cmd"`
+	EnableSBOM                  bool   `mapstructure:"enable-sbom"`
+	EnableStateSnapshots        bool   `mapstructure:"enable-state-snapshots"`
 	EnableDiffMarkdownFormat    bool   `mapstructure:"enable-diff-markdown-format"`
 	ExecutableName              string `mapstructure:"executable-name"`
 	// Fail and do not run the Atlantis command request if any of the pre workflow hooks error.
 	FailOnPreWorkflowHookError      bool   `mapstructure:"fail-on-pre-workflow-hook-error"`
+	ForceUnlockStateAdmins          string `mapstructure:"force-unlock-state-admins"`
 	HideUnchangedPlanComments       bool   `mapstructure:"hide-unchanged-plan-comments"`
 	GithubAllowMergeableBypassApply bool   `mapstructure:"gh-allow-mergeable-bypass-apply"`
 	GithubHostname                  string `mapstructure:"gh-hostname"`
+	GithubIssueOpsCommands          string `mapstructure:"gh-issue-ops-commands"`
 	GithubToken                     string `mapstructure:"gh-token"`
 	GithubTokenFile                 string `mapstructure:"gh-token-file"`
 	GithubUser                      string `mapstructure:"gh-user"`
@@ -74,18 +100,30 @@ type UserConfig struct {
 	GitlabUser                      string `mapstructure:"gitlab-user"`
 	GitlabWebhookSecret             string `mapstructure:"gitlab-webhook-secret"`
 	IncludeGitUntrackedFiles        bool   `mapstructure:"include-git-untracked-files"`
+	ImportReplan                    bool   `mapstructure:"import-replan"`
+	JobLogHistoryDays               int    `mapstructure:"job-log-history-days"`
 	APISecret                       string `mapstructure:"api-secret"`
 	HidePrevPlanComments            bool   `mapstructure:"hide-prev-plan-comments"`
+	KubernetesNamespaces            string `mapstructure:"kubernetes-namespaces"`
+	KubernetesOperator              bool   `mapstructure:"kubernetes-operator"`
+	LifecyclePlugins                string `mapstructure:"lifecycle-plugins"`
 	LockingDBType                   string `mapstructure:"locking-db-type"`
 	LogLevel                        string `mapstructure:"log-level"`
 	MarkdownTemplateOverridesDir    string `mapstructure:"markdown-template-overrides-dir"`
 	MaxCommentsPerCommand           int    `mapstructure:"max-comments-per-command"`
+	MaxConcurrentClonesPerHost      int    `mapstructure:"max-concurrent-clones-per-host"`
 	IgnoreVCSStatusNames            string `mapstructure:"ignore-vcs-status-names"`
+	InstanceName                    string `mapstructure:"instance-name"`
 	ParallelPoolSize                int    `mapstructure:"parallel-pool-size"`
+	ParallelPlanScheduling          bool   `mapstructure:"parallel-plan-scheduling"`
+	ParallelRepoPoolSize            int    `mapstructure:"parallel-repo-pool-size"`
 	ParallelPlan                    bool   `mapstructure:"parallel-plan"`
 	ParallelApply                   bool   `mapstructure:"parallel-apply"`
 	StatsNamespace                  string `mapstructure:"stats-namespace"`
 	PlanDrafts                      bool   `mapstructure:"allow-draft-prs"`
+	PlanFreezeMessage               string `mapstructure:"plan-freeze-message"`
+	ProjectNameTemplate             string `mapstructure:"project-name-template"`
+	ProjectStatusTemplate           string `mapstructure:"project-status-template"`
 	Port                            int    `mapstructure:"port"`
 	QuietPolicyChecks               bool   `mapstructure:"quiet-policy-checks"`
 	RedisDB                         int    `mapstructure:"redis-db"`
@@ -95,8 +133,11 @@ type UserConfig struct {
 	RedisTLSEnabled                 bool   `mapstructure:"redis-tls-enabled"`
 	RedisInsecureSkipVerify         bool   `mapstructure:"redis-insecure-skip-verify"`
 	RepoConfig                      string `mapstructure:"repo-config"`
+	RepoConfigPaths                 string `mapstructure:"repo-config-paths"`
+	RepoConfigReloadSeconds         int    `mapstructure:"repo-config-reload-seconds"`
 	RepoConfigJSON                  string `mapstructure:"repo-config-json"`
 	RepoAllowlist                   string `mapstructure:"repo-allowlist"`
+	RepoAllowlistRefreshSeconds     int    `mapstructure:"repo-allowlist-refresh-seconds"`
 
 	// SilenceNoProjects is whether Atlantis should respond to a PR if no projects are found.
 	SilenceNoProjects   bool `mapstructure:"silence-no-projects"`
@@ -110,8 +151,15 @@ type UserConfig struct {
 	SilenceAllowlistErrors     bool            `mapstructure:"silence-allowlist-errors"`
 	SkipCloneNoChanges         bool            `mapstructure:"skip-clone-no-changes"`
 	SlackToken                 string          `mapstructure:"slack-token"`
+	SMTPAddr                   string          `mapstructure:"smtp-addr"`
+	SMTPFrom                   string          `mapstructure:"smtp-from"`
+	SMTPPassword               string          `mapstructure:"smtp-password"`
+	SMTPUsername               string          `mapstructure:"smtp-username"`
 	SSLCertFile                string          `mapstructure:"ssl-cert-file"`
 	SSLKeyFile                 string          `mapstructure:"ssl-key-file"`
+	StalePlanDiscardDays       int             `mapstructure:"stale-plan-discard-days"`
+	StalePlanReminderDays      int             `mapstructure:"stale-plan-reminder-days"`
+	StateLockRetrySeconds      int             `mapstructure:"state-lock-retry-seconds"`
 	RestrictFileList           bool            `mapstructure:"restrict-file-list"`
 	TFDistribution             string          `mapstructure:"tf-distribution"` // deprecated in favor of DefaultTFDistribution
 	TFDownload                 bool            `mapstructure:"tf-download"`
@@ -119,18 +167,32 @@ type UserConfig struct {
 	TFEHostname                string          `mapstructure:"tfe-hostname"`
 	TFELocalExecutionMode      bool            `mapstructure:"tfe-local-execution-mode"`
 	TFEToken                   string          `mapstructure:"tfe-token"`
+	TFModuleMirrors            string          `mapstructure:"tf-module-mirrors"`
+	TFProviderMirrorURL        string          `mapstructure:"tf-provider-mirror-url"`
+	TFRegistryCredentials      string          `mapstructure:"tf-registry-credentials"`
 	VarFileAllowlist           string          `mapstructure:"var-file-allowlist"`
 	VCSStatusName              string          `mapstructure:"vcs-status-name"`
+	VCSHTTPConfig              string          `mapstructure:"vcs-http-config"`
+	VCSCommentSplit            string          `mapstructure:"vcs-comment-split"`
+	VCSBreakerCooldownSeconds  int             `mapstructure:"vcs-circuit-breaker-cooldown-seconds"`
+	VCSBreakerThreshold        int             `mapstructure:"vcs-circuit-breaker-threshold"`
+	VCSDebugLogging            bool            `mapstructure:"vcs-debug-logging"`
+	VCSDryRun                  bool            `mapstructure:"vcs-dry-run"`
+	VCSMaxRetries              int             `mapstructure:"vcs-max-retries"`
+	WasmRuntime                string          `mapstructure:"wasm-runtime"`
 	DefaultTFDistribution      string          `mapstructure:"default-tf-distribution"`
 	DefaultTFVersion           string          `mapstructure:"default-tf-version"`
 	Webhooks                   []WebhookConfig `mapstructure:"webhooks" flag:"false"`
+	WebhookHistorySize         int             `mapstructure:"webhook-history-size"`
 	WebhookHttpHeaders         string          `mapstructure:"webhook-http-headers"`
+	WebhookRegistration        string          `mapstructure:"webhook-registration"`
 	WebBasicAuth               bool            `mapstructure:"web-basic-auth"`
 	WebUsername                string          `mapstructure:"web-username"`
 	WebPassword                string          `mapstructure:"web-password"`
 	WriteGitCreds              bool            `mapstructure:"write-git-creds"`
 	WebsocketCheckOrigin       bool            `mapstructure:"websocket-check-origin"`
 	UseTFPluginCache           bool            `mapstructure:"use-tf-plugin-cache"`
+	UsageHistoryDays           int             `mapstructure:"usage-history-days"`
 }
 
 // ToAllowCommandNames parse AllowCommands into a slice of CommandName
@@ -157,6 +219,84 @@ func (u UserConfig) ToAllowCommandNames() ([]command.Name, error) {
 	return allowCommands, nil
 }
 
+// IssueOpsAllowedCommands are the commands that may be enabled for GitHub
+// issue-ops mode. They must not modify infrastructure since there is no pull
+// request to review.
+var IssueOpsAllowedCommands = []string{command.Plan.String(), command.Version.String()}
+
+// ToIssueOpsCommandNames parses GithubIssueOpsCommands into a slice of
+// CommandName. An empty result means issue-ops mode is disabled.
+func (u UserConfig) ToIssueOpsCommandNames() ([]command.Name, error) {
+	var issueOpsCommands []command.Name
+	for _, input := range strings.Split(u.GithubIssueOpsCommands, ",") {
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		if !utils.SlicesContains(IssueOpsAllowedCommands, input) {
+			return nil, errors.Errorf("command %q is not supported in issue-ops mode, supported commands are %s", input, strings.Join(IssueOpsAllowedCommands, ", "))
+		}
+		cmd, err := command.ParseCommandName(input)
+		if err != nil {
+			return nil, err
+		}
+		issueOpsCommands = append(issueOpsCommands, cmd)
+	}
+	return issueOpsCommands, nil
+}
+
+// ToForceUnlockStateAdmins parses ForceUnlockStateAdmins into a slice of
+// usernames.
+func (u UserConfig) ToForceUnlockStateAdmins() []string {
+	return splitUsernames(u.ForceUnlockStateAdmins)
+}
+
+// ToApplyWindowAdmins parses ApplyWindowAdmins into a slice of usernames.
+func (u UserConfig) ToApplyWindowAdmins() []string {
+	return splitUsernames(u.ApplyWindowAdmins)
+}
+
+// splitUsernames splits a comma separated list of usernames.
+func splitUsernames(list string) []string {
+	var usernames []string
+	for _, input := range strings.Split(list, ",") {
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		usernames = append(usernames, input)
+	}
+	return usernames
+}
+
+// ToRepoConfigPaths parses RepoConfigPaths into a slice of repo config file
+// paths in priority order.
+func (u UserConfig) ToRepoConfigPaths() []string {
+	var paths []string
+	for _, input := range strings.Split(u.RepoConfigPaths, ",") {
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		paths = append(paths, input)
+	}
+	return paths
+}
+
+// ToLifecyclePlugins parses LifecyclePlugins into a slice of executable
+// paths.
+func (u UserConfig) ToLifecyclePlugins() []string {
+	var plugins []string
+	for _, input := range strings.Split(u.LifecyclePlugins, ",") {
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		plugins = append(plugins, input)
+	}
+	return plugins
+}
+
 // ToWebhookHttpHeaders parses WebhookHttpHeaders into a map of HTTP headers.
 func (u UserConfig) ToWebhookHttpHeaders() (map[string][]string, error) {
 	if u.WebhookHttpHeaders == "" {
@@ -188,6 +328,130 @@ func (u UserConfig) ToWebhookHttpHeaders() (map[string][]string, error) {
 	return headers, nil
 }
 
+// ToVCSHTTPConfigs parses VCSHTTPConfig into the HTTP config of each VCS
+// host. The JSON keys are host types, ex. Github or BitbucketServer.
+func (u UserConfig) ToVCSHTTPConfigs() (map[models.VCSHostType]vcs.HTTPConfig, error) {
+	if u.VCSHTTPConfig == "" {
+		return nil, nil
+	}
+
+	var m map[string]vcs.HTTPConfig
+	decoder := json.NewDecoder(strings.NewReader(u.VCSHTTPConfig))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&m); err != nil {
+		return nil, err
+	}
+	configs := make(map[models.VCSHostType]vcs.HTTPConfig)
+	for name, config := range m {
+		host, err := models.NewVCSHostType(name)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing VCS host")
+		}
+		if err := config.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "validating %s config", name)
+		}
+		configs[host] = config
+	}
+	return configs, nil
+}
+
+// ToVCSCommentSplitConfigs parses VCSCommentSplit into the comment splitting
+// config of each VCS host. The JSON keys are host types, ex. Github.
+func (u UserConfig) ToVCSCommentSplitConfigs() (map[models.VCSHostType]common.SplitConfig, error) {
+	if u.VCSCommentSplit == "" {
+		return nil, nil
+	}
+
+	var m map[string]common.SplitConfig
+	decoder := json.NewDecoder(strings.NewReader(u.VCSCommentSplit))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&m); err != nil {
+		return nil, err
+	}
+	configs := make(map[models.VCSHostType]common.SplitConfig)
+	for name, config := range m {
+		host, err := models.NewVCSHostType(name)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing VCS host")
+		}
+		if err := config.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "validating %s config", name)
+		}
+		if config.Upload && host != models.Github && host != models.Gitlab {
+			return nil, errors.Errorf("validating %s config: upload is only supported for Github and Gitlab", name)
+		}
+		configs[host] = config
+	}
+	return configs, nil
+}
+
+// ToTFModuleMirrors parses the JSON object mapping registry hostnames to the
+// URLs of the module APIs that proxy them.
+func (u UserConfig) ToTFModuleMirrors() (map[string]string, error) {
+	if u.TFModuleMirrors == "" {
+		return nil, nil
+	}
+
+	var mirrors map[string]string
+	decoder := json.NewDecoder(strings.NewReader(u.TFModuleMirrors))
+	if err := decoder.Decode(&mirrors); err != nil {
+		return nil, err
+	}
+	for host, mirror := range mirrors {
+		if host == "" || strings.ContainsAny(host, "/:") {
+			return nil, errors.Errorf("%q must be a registry hostname", host)
+		}
+		if err := validateMirrorURL(mirror); err != nil {
+			return nil, errors.Wrapf(err, "validating %s mirror", host)
+		}
+	}
+	return mirrors, nil
+}
+
+// validateMirrorURL returns an error if mirror isn't an absolute http(s) URL.
+func validateMirrorURL(mirror string) error {
+	parsed, err := url.Parse(mirror)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return errors.Errorf("%q must be an absolute http or https URL", mirror)
+	}
+	return nil
+}
+
+// ValidateTFProviderMirrorURL returns an error if the provider mirror is set
+// but isn't an absolute http(s) URL.
+func (u UserConfig) ValidateTFProviderMirrorURL() error {
+	if u.TFProviderMirrorURL == "" {
+		return nil
+	}
+	return validateMirrorURL(u.TFProviderMirrorURL)
+}
+
+// ToTFRegistryCredentials parses the JSON object mapping registry hostnames to
+// the sources of their API tokens.
+func (u UserConfig) ToTFRegistryCredentials() (map[string]string, error) {
+	if u.TFRegistryCredentials == "" {
+		return nil, nil
+	}
+
+	var credentials map[string]string
+	decoder := json.NewDecoder(strings.NewReader(u.TFRegistryCredentials))
+	if err := decoder.Decode(&credentials); err != nil {
+		return nil, err
+	}
+	for host, source := range credentials {
+		if host == "" || strings.ContainsAny(host, "/:") {
+			return nil, errors.Errorf("%q must be a registry hostname", host)
+		}
+		if err := tfclient.ValidateSecretSource(source); err != nil {
+			return nil, errors.Wrapf(err, "validating %s token", host)
+		}
+	}
+	return credentials, nil
+}
+
 // ToLogLevel returns the LogLevel object corresponding to the user-passed
 // log level.
 func (u UserConfig) ToLogLevel() logging.LogLevel {
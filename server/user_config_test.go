@@ -7,6 +7,9 @@ import (
 
 	"github.com/runatlantis/atlantis/server"
 	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 	"github.com/stretchr/testify/assert"
@@ -31,14 +34,14 @@ func TestUserConfig_ToAllowCommandNames(t *testing.T) {
 			name:          "all",
 			allowCommands: "all",
 			want: []command.Name{
-				command.Version, command.Plan, command.Apply, command.Unlock, command.ApprovePolicies, command.Import, command.State,
+				command.Version, command.Plan, command.Apply, command.Unlock, command.ApprovePolicies, command.Import, command.State, command.ForceUnlockState, command.Rollback, command.Silence, command.Ping,
 			},
 		},
 		{
 			name:          "all with others returns same with all result",
 			allowCommands: "all,plan",
 			want: []command.Name{
-				command.Version, command.Plan, command.Apply, command.Unlock, command.ApprovePolicies, command.Import, command.State,
+				command.Version, command.Plan, command.Apply, command.Unlock, command.ApprovePolicies, command.Import, command.State, command.ForceUnlockState, command.Rollback, command.Silence, command.Ping,
 			},
 		},
 		{
@@ -71,6 +74,72 @@ func TestUserConfig_ToAllowCommandNames(t *testing.T) {
 	}
 }
 
+func TestUserConfig_ToIssueOpsCommandNames(t *testing.T) {
+	tests := []struct {
+		name             string
+		issueOpsCommands string
+		want             []command.Name
+		wantErr          string
+	}{
+		{
+			name:             "read-only commands can be parsed by comma",
+			issueOpsCommands: "plan, version",
+			want:             []command.Name{command.Plan, command.Version},
+		},
+		{
+			name:             "empty",
+			issueOpsCommands: "",
+			want:             nil,
+		},
+		{
+			name:             "apply is not supported",
+			issueOpsCommands: "plan,apply",
+			wantErr:          `command "apply" is not supported in issue-ops mode`,
+		},
+		{
+			name:             "unlock is not supported",
+			issueOpsCommands: "plan,unlock",
+			wantErr:          `command "unlock" is not supported in issue-ops mode`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := server.UserConfig{
+				GithubIssueOpsCommands: tt.issueOpsCommands,
+			}
+			got, err := u.ToIssueOpsCommandNames()
+			if err != nil {
+				require.ErrorContains(t, err, tt.wantErr, "ToIssueOpsCommandNames()")
+			}
+			assert.Equalf(t, tt.want, got, "ToIssueOpsCommandNames()")
+		})
+	}
+}
+
+func TestUserConfig_ToForceUnlockStateAdmins(t *testing.T) {
+	u := server.UserConfig{
+		ForceUnlockStateAdmins: "alice, bob,,",
+	}
+	assert.Equal(t, []string{"alice", "bob"}, u.ToForceUnlockStateAdmins())
+	assert.Nil(t, server.UserConfig{}.ToForceUnlockStateAdmins())
+}
+
+func TestUserConfig_ToLifecyclePlugins(t *testing.T) {
+	u := server.UserConfig{
+		LifecyclePlugins: "/plugins/a, /plugins/b,",
+	}
+	assert.Equal(t, []string{"/plugins/a", "/plugins/b"}, u.ToLifecyclePlugins())
+	assert.Nil(t, server.UserConfig{}.ToLifecyclePlugins())
+}
+
+func TestUserConfig_ToRepoConfigPaths(t *testing.T) {
+	u := server.UserConfig{
+		RepoConfigPaths: ".atlantis/atlantis.yaml, .config/atlantis.yaml,",
+	}
+	assert.Equal(t, []string{".atlantis/atlantis.yaml", ".config/atlantis.yaml"}, u.ToRepoConfigPaths())
+	assert.Nil(t, server.UserConfig{}.ToRepoConfigPaths())
+}
+
 func TestUserConfig_ToWebhookHttpHeaders(t *testing.T) {
 	tcs := []struct {
 		name  string
@@ -114,6 +183,229 @@ func TestUserConfig_ToWebhookHttpHeaders(t *testing.T) {
 	}
 }
 
+func TestUserConfig_ToVCSHTTPConfigs(t *testing.T) {
+	tcs := []struct {
+		name   string
+		given  string
+		want   map[models.VCSHostType]vcs.HTTPConfig
+		expErr string
+	}{
+		{
+			name:  "empty",
+			given: "",
+			want:  nil,
+		},
+		{
+			name:  "happy path",
+			given: `{"Github":{"tls-min-version":"1.3"},"BitbucketServer":{"proxy-url":"http://proxy.corp:3128","ca-bundle":"/etc/ssl/corp-ca.pem"}}`,
+			want: map[models.VCSHostType]vcs.HTTPConfig{
+				models.Github: {TLSMinVersion: "1.3"},
+				models.BitbucketServer: {
+					ProxyURL: "http://proxy.corp:3128",
+					CABundle: "/etc/ssl/corp-ca.pem",
+				},
+			},
+		},
+		{
+			name:  "client certificate",
+			given: `{"Gitlab":{"ca-bundle":"/etc/ssl/corp-ca.pem","client-cert":"/etc/atlantis/gitlab.crt","client-key":"/etc/atlantis/gitlab.key"}}`,
+			want: map[models.VCSHostType]vcs.HTTPConfig{
+				models.Gitlab: {
+					CABundle:   "/etc/ssl/corp-ca.pem",
+					ClientCert: "/etc/atlantis/gitlab.crt",
+					ClientKey:  "/etc/atlantis/gitlab.key",
+				},
+			},
+		},
+		{
+			name:   "client certificate without key",
+			given:  `{"Gitlab":{"client-cert":"/etc/atlantis/gitlab.crt"}}`,
+			expErr: "validating Gitlab config: client-cert and client-key must be set together",
+		},
+		{
+			name:   "unknown host",
+			given:  `{"Bitbucket":{}}`,
+			expErr: "parsing VCS host: \"Bitbucket\" is not a valid type",
+		},
+		{
+			name:   "unknown field",
+			given:  `{"Github":{"proxy":"http://proxy.corp:3128"}}`,
+			expErr: "json: unknown field \"proxy\"",
+		},
+		{
+			name:   "invalid proxy",
+			given:  `{"Gitlab":{"proxy-url":"socks5://proxy.corp:1080"}}`,
+			expErr: "validating Gitlab config: proxy-url \"socks5://proxy.corp:1080\" must use http or https",
+		},
+		{
+			name:   "invalid tls version",
+			given:  `{"Gitlab":{"tls-min-version":"1.1"}}`,
+			expErr: "validating Gitlab config: tls-min-version \"1.1\" is not supported, use 1.2 or 1.3",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			u := server.UserConfig{
+				VCSHTTPConfig: tc.given,
+			}
+			got, err := u.ToVCSHTTPConfigs()
+			if tc.expErr != "" {
+				ErrEquals(t, tc.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, tc.want, got)
+		})
+	}
+}
+
+func TestUserConfig_ToVCSCommentSplitConfigs(t *testing.T) {
+	tcs := []struct {
+		name   string
+		given  string
+		want   map[models.VCSHostType]common.SplitConfig
+		expErr string
+	}{
+		{
+			name:  "empty",
+			given: "",
+			want:  nil,
+		},
+		{
+			name:  "happy path",
+			given: `{"BitbucketServer":{"max-size":30000},"Gitea":{"max-size":50000,"sep-start":"Continued:\n"}}`,
+			want: map[models.VCSHostType]common.SplitConfig{
+				models.BitbucketServer: {MaxSize: 30000},
+				models.Gitea:           {MaxSize: 50000, SepStart: "Continued:\n"},
+			},
+		},
+		{
+			name:   "unknown field",
+			given:  `{"Github":{"max":30000}}`,
+			expErr: "json: unknown field \"max\"",
+		},
+		{
+			name:   "invalid max size",
+			given:  `{"Gitlab":{"max-size":-1}}`,
+			expErr: "validating Gitlab config: max-size must be positive, got -1",
+		},
+		{
+			name:  "upload",
+			given: `{"Github":{"upload":true}}`,
+			want: map[models.VCSHostType]common.SplitConfig{
+				models.Github: {Upload: true},
+			},
+		},
+		{
+			name:   "upload unsupported",
+			given:  `{"BitbucketServer":{"upload":true}}`,
+			expErr: "validating BitbucketServer config: upload is only supported for Github and Gitlab",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			u := server.UserConfig{
+				VCSCommentSplit: tc.given,
+			}
+			got, err := u.ToVCSCommentSplitConfigs()
+			if tc.expErr != "" {
+				ErrEquals(t, tc.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, tc.want, got)
+		})
+	}
+}
+
+func TestUserConfig_ToTFModuleMirrors(t *testing.T) {
+	tcs := []struct {
+		name   string
+		given  string
+		want   map[string]string
+		expErr string
+	}{
+		{
+			name:  "empty",
+			given: "",
+			want:  nil,
+		},
+		{
+			name:  "happy path",
+			given: `{"registry.terraform.io":"https://mirror.example.com/v1/modules/"}`,
+			want:  map[string]string{"registry.terraform.io": "https://mirror.example.com/v1/modules/"},
+		},
+		{
+			name:   "not a hostname",
+			given:  `{"https://registry.terraform.io":"https://mirror.example.com/v1/modules/"}`,
+			expErr: "\"https://registry.terraform.io\" must be a registry hostname",
+		},
+		{
+			name:   "relative url",
+			given:  `{"registry.terraform.io":"/v1/modules/"}`,
+			expErr: "validating registry.terraform.io mirror: \"/v1/modules/\" must be an absolute http or https URL",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			u := server.UserConfig{
+				TFModuleMirrors: tc.given,
+			}
+			got, err := u.ToTFModuleMirrors()
+			if tc.expErr != "" {
+				ErrEquals(t, tc.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, tc.want, got)
+		})
+	}
+}
+
+func TestUserConfig_ToTFRegistryCredentials(t *testing.T) {
+	tcs := []struct {
+		name   string
+		given  string
+		want   map[string]string
+		expErr string
+	}{
+		{
+			name:  "empty",
+			given: "",
+			want:  nil,
+		},
+		{
+			name:  "happy path",
+			given: `{"app.terraform.io":"env:TFC_TOKEN","registry.example.com":"file:/etc/token"}`,
+			want:  map[string]string{"app.terraform.io": "env:TFC_TOKEN", "registry.example.com": "file:/etc/token"},
+		},
+		{
+			name:   "not a hostname",
+			given:  `{"https://app.terraform.io":"env:TFC_TOKEN"}`,
+			expErr: "\"https://app.terraform.io\" must be a registry hostname",
+		},
+		{
+			name:   "plain token",
+			given:  `{"app.terraform.io":"abc123"}`,
+			expErr: "validating app.terraform.io token: \"abc123\" must be env:NAME or file:PATH",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			u := server.UserConfig{
+				TFRegistryCredentials: tc.given,
+			}
+			got, err := u.ToTFRegistryCredentials()
+			if tc.expErr != "" {
+				ErrEquals(t, tc.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, tc.want, got)
+		})
+	}
+}
+
 func TestUserConfig_ToLogLevel(t *testing.T) {
 	cases := []struct {
 		userLvl string